@@ -0,0 +1,140 @@
+// Package main provides a tool that emulates Elgato lights on the local
+// machine, for testing keylightd's discovery and control end to end without
+// hardware. Each emulated light serves the Elgato HTTP API on its own port
+// and, with -mdns, announces itself like a real light.
+//
+// Usage:
+//
+//	go run ./cmd/keylight-emulator -count 3
+//	go run ./cmd/keylight-emulator -count 4 -product "Key Light MK.2,Light Strip" -mdns
+//	go run ./cmd/keylight-emulator -port 9200 -latency 200ms
+//
+// Without -mdns the lights can be added to keylightd as static lights, for
+// example 127.0.0.1:9123.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/grandcat/zeroconf"
+
+	"github.com/jmylchreest/keylightd/internal/emulator"
+)
+
+var (
+	// version is set via ldflags at build time.
+	version = "dev"
+)
+
+func main() {
+	count := flag.Int("count", 1, "Number of lights to emulate")
+	port := flag.Int("port", 9123, "Port of the first light; the others use the ports after it")
+	listen := flag.String("listen", "", "Address to listen on (default: 127.0.0.1, or all addresses with -mdns)")
+	products := flag.String("product", "Key Light", "Products to emulate, comma separated and used in turn: "+strings.Join(emulator.ProductNames(), ", "))
+	name := flag.String("name", "Emulated Light", "Display name; lights after the first are numbered")
+	mdns := flag.Bool("mdns", false, "Announce the lights over mDNS so keylightd discovers them")
+	latency := flag.Duration("latency", 0, "Delay added to every response")
+	debug := flag.Bool("debug", false, "Log every request")
+	showVersion := flag.Bool("version", false, "Print version and exit")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version)
+		return
+	}
+
+	level := slog.LevelInfo
+	if *debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	if err := run(logger, *count, *port, *listen, *products, *name, *mdns, *latency); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger, count, port int, listen, products, name string, mdns bool, latency time.Duration) error {
+	if count < 1 {
+		return errors.New("-count must be at least 1")
+	}
+	if port < 1 || port+count-1 > 65535 {
+		return fmt.Errorf("-port must leave room for %d lights below 65536", count)
+	}
+	if latency < 0 {
+		return errors.New("-latency cannot be negative")
+	}
+	var productNames []string
+	for p := range strings.SplitSeq(products, ",") {
+		product, ok := emulator.ProductName(p)
+		if !ok {
+			return fmt.Errorf("unknown product %q, expected one of: %s", strings.TrimSpace(p), strings.Join(emulator.ProductNames(), ", "))
+		}
+		productNames = append(productNames, product)
+	}
+	if listen == "" {
+		listen = "127.0.0.1"
+		if mdns {
+			listen = ""
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, count)
+	for i := range count {
+		displayName := name
+		if count > 1 {
+			displayName = fmt.Sprintf("%s %d", name, i+1)
+		}
+		device := emulator.NewDevice(productNames[i%len(productNames)], fmt.Sprintf("EMU%07d", i+1), displayName, logger)
+		device.SetLatency(latency)
+
+		lightPort := port + i
+		listener, err := net.Listen("tcp", net.JoinHostPort(listen, strconv.Itoa(lightPort)))
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+		server := &http.Server{Handler: device, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+		defer server.Close()
+
+		info := device.Info()
+		if mdns {
+			txt := []string{"mf=Elgato", "dt=" + strconv.Itoa(info.HardwareBoardType), "id=" + info.SerialNumber, "md=" + info.ProductName}
+			announcer, err := zeroconf.Register(displayName, "_elg._tcp", "local.", lightPort, txt, nil)
+			if err != nil {
+				return fmt.Errorf("failed to announce %s over mDNS: %w", displayName, err)
+			}
+			defer announcer.Shutdown()
+		}
+		logger.Info("emulator: light started", "name", displayName, "product", info.ProductName,
+			"serial", info.SerialNumber, "address", listener.Addr().String(), "mdns", mdns)
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("emulator: stopping")
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
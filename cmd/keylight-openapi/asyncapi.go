@@ -0,0 +1,268 @@
+package main
+
+import (
+	"net/url"
+	"reflect"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/internal/server"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// asyncAPIVersion is the AsyncAPI specification version of the generated document.
+const asyncAPIVersion = "3.0.0"
+
+// eventPayloads describes each event type and the type of its data.
+var eventPayloads = map[events.EventType]struct {
+	summary string
+	data    any
+}{
+	events.LightStateChanged: {"A light's state changed", keylight.Light{}},
+	events.LightDiscovered:   {"A light was discovered", keylight.Light{}},
+	events.LightRemoved:      {"A light was removed after going unreachable", keylight.Light{}},
+	events.GroupCreated:      {"A group was created", group.Group{}},
+	events.GroupDeleted:      {"A group was deleted", group.Group{}},
+	events.GroupUpdated:      {"A group's name, lights or defaults changed", group.Group{}},
+	events.JobCompleted:      {"A job finished, successfully or not", jobs.Job{}},
+}
+
+// AsyncAPI document types, covering the parts of AsyncAPI 3 the generator uses.
+type (
+	asyncAPIDocument struct {
+		AsyncAPI   string                       `json:"asyncapi"`
+		Info       asyncAPIInfo                 `json:"info"`
+		Servers    map[string]asyncAPIServer    `json:"servers,omitempty"`
+		Channels   map[string]asyncAPIChannel   `json:"channels"`
+		Operations map[string]asyncAPIOperation `json:"operations"`
+		Components asyncAPIComponents           `json:"components"`
+	}
+
+	asyncAPIInfo struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+
+	asyncAPIServer struct {
+		Host        string `json:"host"`
+		Protocol    string `json:"protocol"`
+		Description string `json:"description,omitempty"`
+	}
+
+	asyncAPIChannel struct {
+		Address     *string                `json:"address"`
+		Description string                 `json:"description,omitempty"`
+		Servers     []asyncAPIRef          `json:"servers,omitempty"`
+		Messages    map[string]asyncAPIRef `json:"messages"`
+	}
+
+	asyncAPIOperation struct {
+		Action   string         `json:"action"`
+		Channel  asyncAPIRef    `json:"channel"`
+		Summary  string         `json:"summary,omitempty"`
+		Messages []asyncAPIRef  `json:"messages"`
+		Reply    *asyncAPIReply `json:"reply,omitempty"`
+	}
+
+	asyncAPIReply struct {
+		Channel  asyncAPIRef   `json:"channel"`
+		Messages []asyncAPIRef `json:"messages"`
+	}
+
+	asyncAPIMessage struct {
+		Name        string       `json:"name"`
+		Title       string       `json:"title,omitempty"`
+		Summary     string       `json:"summary,omitempty"`
+		ContentType string       `json:"contentType"`
+		Payload     *huma.Schema `json:"payload"`
+	}
+
+	asyncAPIComponents struct {
+		Messages map[string]asyncAPIMessage `json:"messages"`
+		Schemas  map[string]*huma.Schema    `json:"schemas"`
+	}
+
+	asyncAPIRef struct {
+		Ref string `json:"$ref"`
+	}
+)
+
+// Channel and message names in the generated document.
+const (
+	wsChannel       = "websocket"
+	socketChannel   = "socket"
+	commandMessage  = "command"
+	responseMessage = "response"
+)
+
+// buildAsyncAPI builds an AsyncAPI document describing the events pushed over
+// the WebSocket and socket event streams, and the WebSocket commands.
+func buildAsyncAPI(version, baseURL string) *asyncAPIDocument {
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	proto := server.Protocol()
+
+	doc := &asyncAPIDocument{
+		AsyncAPI: asyncAPIVersion,
+		Info: asyncAPIInfo{
+			Title:       "keylightd events",
+			Version:     version,
+			Description: "Events pushed by keylightd over the WebSocket API and the Unix socket's subscribe_events stream, and commands accepted over the WebSocket API.",
+		},
+		Channels:   map[string]asyncAPIChannel{},
+		Operations: map[string]asyncAPIOperation{},
+		Components: asyncAPIComponents{Messages: map[string]asyncAPIMessage{}},
+	}
+
+	var eventNames []string
+	for _, t := range events.Types {
+		event := eventPayloads[t]
+		name := string(t)
+		doc.Components.Messages[name] = asyncAPIMessage{
+			Name:        name,
+			Title:       name,
+			Summary:     event.summary,
+			ContentType: "application/json",
+			Payload: &huma.Schema{
+				Type:     huma.TypeObject,
+				Required: []string{"type", "timestamp", "data"},
+				Properties: map[string]*huma.Schema{
+					"type":      {Type: huma.TypeString, Enum: []any{name}},
+					"timestamp": {Type: huma.TypeString, Format: "date-time"},
+					"data":      registry.Schema(reflect.TypeOf(event.data), true, ""),
+				},
+			},
+		}
+		eventNames = append(eventNames, name)
+	}
+
+	var wsActions []any
+	for _, action := range proto.Actions {
+		if action.WebSocket {
+			wsActions = append(wsActions, action.Name)
+		}
+	}
+	doc.Components.Messages[commandMessage] = asyncAPIMessage{
+		Name:        commandMessage,
+		Title:       "Command",
+		Summary:     "A command sent by a WebSocket client, with the same data as the socket action",
+		ContentType: "application/json",
+		Payload: &huma.Schema{
+			Type:     huma.TypeObject,
+			Required: []string{"action"},
+			Properties: map[string]*huma.Schema{
+				"id":         {Type: huma.TypeString, Description: "Echoed in the response"},
+				"request_id": {Type: huma.TypeString, Description: "ID to log the command under; generated if absent or invalid"},
+				"action":     {Type: huma.TypeString, Enum: wsActions},
+				"data":       {Type: huma.TypeObject},
+			},
+		},
+	}
+
+	var codes []any
+	for _, code := range proto.ErrorCodes {
+		codes = append(codes, string(code))
+	}
+	doc.Components.Messages[responseMessage] = asyncAPIMessage{
+		Name:        responseMessage,
+		Title:       "Command response",
+		Summary:     "The response to a command; other fields are those of the socket action's response",
+		ContentType: "application/json",
+		Payload: &huma.Schema{
+			Type:     huma.TypeObject,
+			Required: []string{"type", "request_id"},
+			Properties: map[string]*huma.Schema{
+				"type":       {Type: huma.TypeString, Enum: []any{"response"}},
+				"id":         {Type: huma.TypeString},
+				"request_id": {Type: huma.TypeString},
+				"status":     {Type: huma.TypeString, Enum: []any{"ok"}},
+				"error":      {Type: huma.TypeString},
+				"code":       {Type: huma.TypeString, Enum: codes},
+				"details":    {Type: huma.TypeObject},
+			},
+		},
+	}
+	doc.Components.Schemas = registry.Map()
+
+	wsMessages := map[string]asyncAPIRef{commandMessage: messageRef(commandMessage), responseMessage: messageRef(responseMessage)}
+	socketMessages := map[string]asyncAPIRef{}
+	for _, name := range eventNames {
+		wsMessages[name] = messageRef(name)
+		socketMessages[name] = messageRef(name)
+	}
+
+	wsAddress := "/api/v1/ws"
+	doc.Channels[wsChannel] = asyncAPIChannel{
+		Address:     &wsAddress,
+		Description: "The WebSocket API. Authenticate the upgrade request with an API key. The types, lights and groups query parameters, comma-separated lists, limit the events sent to the client.",
+		Messages:    wsMessages,
+	}
+	doc.Channels[socketChannel] = asyncAPIChannel{
+		Description: "A Unix socket connection after a subscribe_events request, with one JSON event per line. The request's types, lights and groups fields limit the events sent.",
+		Messages:    socketMessages,
+	}
+	if srv, ok := wsServer(baseURL); ok {
+		doc.Servers = map[string]asyncAPIServer{"api": srv}
+		ch := doc.Channels[wsChannel]
+		ch.Servers = []asyncAPIRef{{Ref: "#/servers/api"}}
+		doc.Channels[wsChannel] = ch
+	}
+
+	doc.Operations["sendEvents"] = asyncAPIOperation{
+		Action:   "send",
+		Channel:  channelRef(wsChannel),
+		Summary:  "Events pushed to WebSocket clients whose filters they pass",
+		Messages: channelMessageRefs(wsChannel, eventNames...),
+	}
+	doc.Operations["receiveCommand"] = asyncAPIOperation{
+		Action:   "receive",
+		Channel:  channelRef(wsChannel),
+		Summary:  "Commands sent by WebSocket clients",
+		Messages: channelMessageRefs(wsChannel, commandMessage),
+		Reply: &asyncAPIReply{
+			Channel:  channelRef(wsChannel),
+			Messages: channelMessageRefs(wsChannel, responseMessage),
+		},
+	}
+	doc.Operations["sendSocketEvents"] = asyncAPIOperation{
+		Action:   "send",
+		Channel:  channelRef(socketChannel),
+		Summary:  "Events streamed to socket clients that sent subscribe_events",
+		Messages: channelMessageRefs(socketChannel, eventNames...),
+	}
+	return doc
+}
+
+// wsServer returns the WebSocket server for the API server's base URL, if
+// there is one.
+func wsServer(baseURL string) (asyncAPIServer, bool) {
+	u, err := url.Parse(baseURL)
+	if baseURL == "" || err != nil || u.Host == "" {
+		return asyncAPIServer{}, false
+	}
+	protocol := "ws"
+	if u.Scheme == "https" {
+		protocol = "wss"
+	}
+	return asyncAPIServer{Host: u.Host, Protocol: protocol, Description: "API Server"}, true
+}
+
+func messageRef(name string) asyncAPIRef {
+	return asyncAPIRef{Ref: "#/components/messages/" + name}
+}
+
+func channelRef(name string) asyncAPIRef {
+	return asyncAPIRef{Ref: "#/channels/" + name}
+}
+
+// channelMessageRefs returns references to a channel's messages.
+func channelMessageRefs(channel string, names ...string) []asyncAPIRef {
+	refs := make([]asyncAPIRef, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, asyncAPIRef{Ref: "#/channels/" + channel + "/messages/" + name})
+	}
+	return refs
+}
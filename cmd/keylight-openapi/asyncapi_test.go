@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+func TestBuildAsyncAPI(t *testing.T) {
+	doc := buildAsyncAPI("1.2.3", "https://lights.example:9123")
+
+	assert.Equal(t, "1.2.3", doc.Info.Version)
+	for _, eventType := range events.Types {
+		assert.Contains(t, eventPayloads, eventType)
+		msg, ok := doc.Components.Messages[string(eventType)]
+		require.True(t, ok, eventType)
+		assert.Contains(t, msg.Payload.Properties["data"].Ref, "#/components/schemas/")
+		assert.Contains(t, doc.Channels[wsChannel].Messages, string(eventType))
+		assert.Contains(t, doc.Channels[socketChannel].Messages, string(eventType))
+	}
+	assert.Contains(t, doc.Components.Schemas, "Light")
+	assert.Contains(t, doc.Components.Schemas, "Group")
+	assert.Contains(t, doc.Components.Messages[commandMessage].Payload.Properties["action"].Enum, "set_light_state")
+	assert.Equal(t, asyncAPIServer{Host: "lights.example:9123", Protocol: "wss", Description: "API Server"}, doc.Servers["api"])
+
+	assert.Empty(t, buildAsyncAPI("dev", "").Servers)
+}
+
+func TestMarshalDocument(t *testing.T) {
+	doc := buildAsyncAPI("dev", "")
+
+	data, err := marshalDocument(doc, false)
+	require.NoError(t, err)
+	var fromJSON map[string]any
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+
+	data, err = marshalDocument(doc, true)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "asyncapi: 3.0.0\n")
+	var fromYAML map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &fromYAML))
+	assert.Equal(t, fromJSON["channels"], fromYAML["channels"])
+}
@@ -2,24 +2,29 @@
 // This binary uses the shared route definitions with stub handlers to produce an accurate
 // OpenAPI spec without requiring any real services or dependencies.
 //
+// It can also generate an AsyncAPI document for the WebSocket and socket event
+// streams, and a description of the Unix socket actions.
+//
 // Usage:
 //
 //	go run ./cmd/keylight-openapi > openapi.json
 //	go run ./cmd/keylight-openapi -yaml > openapi.yaml
 //	go run ./cmd/keylight-openapi -output openapi.json
+//	go run ./cmd/keylight-openapi -asyncapi -yaml -output asyncapi.yaml
+//	go run ./cmd/keylight-openapi -socket -output socket-protocol.json
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 
-	"github.com/danielgtaylor/huma/v2/adapters/humachi"
-	"github.com/go-chi/chi/v5"
 	"gopkg.in/yaml.v3"
 
 	"github.com/jmylchreest/keylightd/internal/http/routes"
+	"github.com/jmylchreest/keylightd/internal/server"
 )
 
 var (
@@ -31,6 +36,8 @@ func main() {
 	outputFile := flag.String("output", "", "Output file path (default: stdout)")
 	outputYAML := flag.Bool("yaml", false, "Output as YAML instead of JSON")
 	baseURL := flag.String("base-url", "", "Base URL for the API server")
+	asyncAPI := flag.Bool("asyncapi", false, "Generate the AsyncAPI document for the event streams instead")
+	socket := flag.Bool("socket", false, "Generate the Unix socket protocol description instead")
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	flag.Parse()
 
@@ -39,31 +46,34 @@ func main() {
 		return
 	}
 
-	// Create a minimal chi router — we won't actually serve requests
-	router := chi.NewRouter()
-
-	// Create Huma API with shared config
-	cfg := routes.NewHumaConfig(version, *baseURL)
-	api := humachi.New(router, cfg)
-
-	// Register all routes with stub handlers
-	routes.Register(api, routes.StubHandlers())
-
-	// Get the OpenAPI spec
-	spec := api.OpenAPI()
+	var (
+		data []byte
+		err  error
+		kind = "OpenAPI spec"
+	)
+	switch {
+	case *asyncAPI && *socket:
+		fmt.Fprintln(os.Stderr, "-asyncapi and -socket can't be used together")
+		os.Exit(2)
+	case *asyncAPI:
+		kind = "AsyncAPI document"
+		data, err = marshalDocument(buildAsyncAPI(version, *baseURL), *outputYAML)
+	case *socket:
+		kind = "Socket protocol description"
+		data, err = marshalDocument(server.Protocol(), *outputYAML)
+	default:
+		spec := routes.StubSpec(version, *baseURL)
 
-	// Marshal the spec
-	var data []byte
-	var err error
-
-	if *outputYAML {
-		data, err = yaml.Marshal(spec)
-	} else {
-		data, err = json.MarshalIndent(spec, "", "  ")
+		// Marshal the spec
+		if *outputYAML {
+			data, err = yaml.Marshal(spec)
+		} else {
+			data, err = json.MarshalIndent(spec, "", "  ")
+		}
 	}
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error marshaling OpenAPI spec: %v\n", err)
+		fmt.Fprintf(os.Stderr, "error marshaling %s: %v\n", kind, err)
 		os.Exit(1)
 	}
 
@@ -73,8 +83,40 @@ func main() {
 			fmt.Fprintf(os.Stderr, "error writing to file: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "OpenAPI spec written to %s\n", *outputFile)
+		fmt.Fprintf(os.Stderr, "%s written to %s\n", kind, *outputFile)
 	} else {
 		fmt.Print(string(data))
 	}
 }
+
+// marshalDocument marshals a document as indented JSON, or as YAML with the
+// same field names and order.
+func marshalDocument(doc any, asYAML bool) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil || !asYAML {
+		return data, err
+	}
+	// JSON is valid YAML, so decoding it into a node keeps the field names
+	// and order; clearing the styles turns it into block YAML.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearYAMLStyle(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clearYAMLStyle resets the style of a node and its children to the default
+// block style.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
@@ -0,0 +1,77 @@
+// Package main provides a CLI tool to generate clients for the keylightd HTTP API.
+// Like keylight-openapi, it builds the OpenAPI spec from the shared route definitions
+// with stub handlers, and generates code from the spec's operations and schemas.
+//
+// The generated clients are committed; regenerate them with go generate ./...
+// (or make generate) after changing the API.
+//
+// Usage:
+//
+//	go run ./cmd/keylight-sdkgen -lang typescript -output sdk/typescript/src/client.ts
+//	go run ./cmd/keylight-sdkgen -lang python -output sdk/python/keylightd_client/__init__.py
+//	go run ./cmd/keylight-sdkgen -lang go -package api -output pkg/api/types.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jmylchreest/keylightd/internal/http/routes"
+)
+
+var (
+	// version is set via ldflags at build time.
+	version = "dev"
+)
+
+func main() {
+	lang := flag.String("lang", "", "Language to generate: typescript, python or go")
+	outputFile := flag.String("output", "", "Output file path (default: stdout)")
+	pkg := flag.String("package", "api", "Package name of generated Go code")
+	showVersion := flag.Bool("version", false, "Print version and exit")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version)
+		return
+	}
+
+	code, err := generate(*lang, *pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error generating client: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Output to file or stdout
+	if *outputFile != "" {
+		if err := os.MkdirAll(filepath.Dir(*outputFile), 0750); err != nil {
+			fmt.Fprintf(os.Stderr, "error creating output directory: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(*outputFile, []byte(code), 0600); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing to file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "%s client written to %s\n", *lang, *outputFile)
+	} else {
+		fmt.Print(code)
+	}
+}
+
+// generate generates the client for a language. The spec is built without a
+// version, so the output doesn't change between releases.
+func generate(lang, pkg string) (string, error) {
+	spec := routes.StubSpec("", "")
+	switch lang {
+	case "typescript":
+		return generateTypeScript(spec), nil
+	case "python":
+		return generatePython(spec), nil
+	case "go":
+		return generateGo(spec, pkg)
+	default:
+		return "", fmt.Errorf("unknown language %q (want typescript, python or go)", lang)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/http/routes"
+)
+
+// TestGeneratedClientsUpToDate fails if the committed clients weren't
+// regenerated after an API change.
+func TestGeneratedClientsUpToDate(t *testing.T) {
+	for lang, path := range map[string]string{
+		"typescript": "../../sdk/typescript/src/client.ts",
+		"python":     "../../sdk/python/keylightd_client/__init__.py",
+		"go":         "../../pkg/api/types.go",
+	} {
+		t.Run(lang, func(t *testing.T) {
+			want, err := generate(lang, "api")
+			require.NoError(t, err)
+			got, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got), "%s is out of date; run make generate", path)
+		})
+	}
+}
+
+func TestGenerateUnknownLanguage(t *testing.T) {
+	_, err := generate("rust", "api")
+	assert.ErrorContains(t, err, "unknown language")
+}
+
+func TestOperations(t *testing.T) {
+	ops := operations(routes.StubSpec("", ""))
+
+	byID := map[string]operation{}
+	for _, op := range ops {
+		require.NotEmpty(t, op.ID, "%s %s", op.Method, op.Path)
+		require.NotContains(t, byID, op.ID)
+		byID[op.ID] = op
+	}
+
+	setState := byID["setLightState"]
+	assert.Equal(t, "POST", setState.Method)
+	assert.Equal(t, []string{"id"}, setState.PathParams)
+	require.NotNil(t, setState.Body)
+	name, ok := refName(setState.Body)
+	assert.True(t, ok)
+	assert.Equal(t, "SetLightStateInputBody", name)
+
+	exportConfig := byID["exportConfig"]
+	require.Len(t, exportConfig.Query, 1)
+	assert.Equal(t, "include_secrets", exportConfig.Query[0].Name)
+	assert.Nil(t, exportConfig.Body)
+	assert.NotNil(t, exportConfig.Result)
+
+	assert.Nil(t, byID["deleteGroup"].Result)
+}
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"setLightState":   {"set", "light", "state"},
+		"set_light_state": {"set", "light", "state"},
+		"include-secrets": {"include", "secrets"},
+		"APIKeyResponse":  {"api", "key", "response"},
+		"id":              {"id"},
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, words(in), in)
+	}
+	assert.Equal(t, "create_api_key", snakeCase("createApiKey"))
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "ID", goName("id"))
+	assert.Equal(t, "LastUsedAt", goName("last_used_at"))
+	assert.Equal(t, "APIKeyID", goName("api_key_id"))
+	assert.Equal(t, "TransitionMS", goName("transition_ms"))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// schemaPrefix is the prefix of references to the spec's component schemas.
+const schemaPrefix = "#/components/schemas/"
+
+// operation is an API operation, reduced to what the generators need.
+type operation struct {
+	ID         string // operationId, e.g. setLightState
+	Summary    string
+	Method     string
+	Path       string
+	PathParams []string
+	Query      []param
+	Body       *huma.Schema // nil if the operation takes no body
+	Result     *huma.Schema // nil if the operation returns no body
+}
+
+// param is a query parameter.
+type param struct {
+	Name   string
+	Schema *huma.Schema
+}
+
+// namedSchema is a component schema and its name.
+type namedSchema struct {
+	Name   string
+	Schema *huma.Schema
+}
+
+// operations returns the spec's operations, sorted by ID so the generated
+// code doesn't change with map order.
+func operations(spec *huma.OpenAPI) []operation {
+	var ops []operation
+	for path, item := range spec.Paths {
+		for _, m := range []struct {
+			method string
+			op     *huma.Operation
+		}{
+			{http.MethodGet, item.Get},
+			{http.MethodPost, item.Post},
+			{http.MethodPut, item.Put},
+			{http.MethodPatch, item.Patch},
+			{http.MethodDelete, item.Delete},
+		} {
+			if m.op == nil {
+				continue
+			}
+			op := operation{ID: m.op.OperationID, Summary: m.op.Summary, Method: m.method, Path: path}
+			for _, p := range m.op.Parameters {
+				switch p.In {
+				case "path":
+					op.PathParams = append(op.PathParams, p.Name)
+				case "query":
+					op.Query = append(op.Query, param{Name: p.Name, Schema: p.Schema})
+				}
+			}
+			if m.op.RequestBody != nil {
+				if mt := m.op.RequestBody.Content["application/json"]; mt != nil {
+					op.Body = mt.Schema
+				}
+			}
+			op.Result = successSchema(m.op)
+			ops = append(ops, op)
+		}
+	}
+	slices.SortFunc(ops, func(a, b operation) int { return strings.Compare(a.ID, b.ID) })
+	return ops
+}
+
+// successSchema returns the schema of an operation's 2xx JSON response.
+func successSchema(op *huma.Operation) *huma.Schema {
+	for status, resp := range op.Responses {
+		if !strings.HasPrefix(status, "2") || resp == nil {
+			continue
+		}
+		if mt := resp.Content["application/json"]; mt != nil && mt.Schema != nil {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// schemas returns the spec's component schemas, sorted by name.
+func schemas(spec *huma.OpenAPI) []namedSchema {
+	var result []namedSchema
+	for name, schema := range spec.Components.Schemas.Map() {
+		result = append(result, namedSchema{Name: name, Schema: schema})
+	}
+	slices.SortFunc(result, func(a, b namedSchema) int { return strings.Compare(a.Name, b.Name) })
+	return result
+}
+
+// refName returns the name of the component schema s refers to, if it is a
+// reference.
+func refName(s *huma.Schema) (string, bool) {
+	if s == nil || !strings.HasPrefix(s.Ref, schemaPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(s.Ref, schemaPrefix), true
+}
+
+// sortedProperties returns the names of a schema's properties, sorted.
+func sortedProperties(s *huma.Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// additionalSchema returns the schema of a map's values, if s is a map.
+func additionalSchema(s *huma.Schema) (*huma.Schema, bool) {
+	switch ap := s.AdditionalProperties.(type) {
+	case *huma.Schema:
+		return ap, true
+	case bool:
+		return &huma.Schema{}, ap && len(s.Properties) == 0
+	}
+	return nil, false
+}
+
+// words splits an identifier such as set_light_state, setLightState or
+// include-secrets into lower-case words.
+func words(s string) []string {
+	var result []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return result
+}
+
+// snakeCase converts an identifier to snake_case.
+func snakeCase(s string) string {
+	return strings.Join(words(s), "_")
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// pyHeader is the hand-written start of the Python client: imports, the error
+// type and the request helper the generated methods call.
+const pyHeader = `"""Client for the keylightd HTTP API.
+
+Only the standard library is used. Errors returned by the daemon are raised
+as APIError, with the error code shared by all of keylightd's APIs.
+"""
+
+from __future__ import annotations
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Any, Dict, List, Literal, NotRequired, Optional, TypedDict
+
+`
+
+// pyClient is the hand-written part of the Python client class.
+const pyClient = `
+class APIError(Exception):
+    """An error returned by the daemon."""
+
+    def __init__(self, status: int, code: str, message: str, details: Optional[Dict[str, Any]] = None):
+        super().__init__(message)
+        self.status = status
+        self.code = code
+        self.message = message
+        self.details = details
+
+
+class Client:
+    """A client for the keylightd HTTP API."""
+
+    def __init__(self, base_url: str, api_key: Optional[str] = None, timeout: float = 10.0):
+        self.base_url = base_url.rstrip("/")
+        self.api_key = api_key
+        self.timeout = timeout
+
+    def _request(self, method: str, path: str, query: Optional[Dict[str, Any]] = None, body: Any = None) -> Any:
+        url = self.base_url + path
+        params = {k: _query_value(v) for k, v in (query or {}).items() if v is not None}
+        if params:
+            url += "?" + urllib.parse.urlencode(params)
+        headers = {"Accept": "application/json"}
+        data = None
+        if body is not None:
+            headers["Content-Type"] = "application/json"
+            data = json.dumps(body).encode()
+        if self.api_key:
+            headers["X-API-Key"] = self.api_key
+        request = urllib.request.Request(url, data=data, headers=headers, method=method)
+        try:
+            with urllib.request.urlopen(request, timeout=self.timeout) as response:
+                raw = response.read()
+        except urllib.error.HTTPError as err:
+            raw = err.read()
+            try:
+                problem = json.loads(raw)
+            except ValueError:
+                problem = {}
+            if not isinstance(problem, dict):
+                problem = {}
+            message = problem.get("detail") or raw.decode(errors="replace") or f"HTTP error {err.code}"
+            raise APIError(err.code, problem.get("code", "internal"), message, problem.get("details")) from None
+        return json.loads(raw) if raw else None
+`
+
+// pyFooter is the hand-written end of the Python client.
+const pyFooter = `
+
+def _quote(value: str) -> str:
+    return urllib.parse.quote(value, safe="")
+
+
+def _query_value(value: Any) -> str:
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    return str(value)
+`
+
+// generatePython generates the Python client.
+func generatePython(spec *huma.OpenAPI) string {
+	var b strings.Builder
+	b.WriteString("# Code generated by keylight-sdkgen from the keylightd OpenAPI spec. DO NOT EDIT.\n\n")
+	b.WriteString(pyHeader)
+
+	// Annotations aren't evaluated, so classes can refer to ones defined
+	// later; aliases are evaluated, so they come last.
+	var aliases []namedSchema
+	for _, s := range schemas(spec) {
+		if s.Schema.Type != huma.TypeObject || len(s.Schema.Properties) == 0 {
+			aliases = append(aliases, s)
+			continue
+		}
+		pyClass(&b, s)
+	}
+	for _, s := range aliases {
+		fmt.Fprintf(&b, "%s = %s\n\n", s.Name, pyType(s.Schema))
+	}
+	b.WriteString(pyClient)
+
+	for _, op := range operations(spec) {
+		args := []string{"self"}
+		path := "\"" + op.Path + "\""
+		if len(op.PathParams) > 0 {
+			path = "f" + path
+		}
+		for _, p := range op.PathParams {
+			name := snakeCase(p)
+			args = append(args, name+": str")
+			path = strings.ReplaceAll(path, "{"+p+"}", "{_quote("+name+")}")
+		}
+		body := "None"
+		if op.Body != nil {
+			args = append(args, "body: "+pyType(op.Body))
+			body = "body"
+		}
+		query := "None"
+		if len(op.Query) > 0 {
+			args = append(args, "*")
+			var entries []string
+			for _, q := range op.Query {
+				name := snakeCase(q.Name)
+				args = append(args, name+": Optional["+pyType(q.Schema)+"] = None")
+				entries = append(entries, strconv.Quote(q.Name)+": "+name)
+			}
+			query = "{" + strings.Join(entries, ", ") + "}"
+		}
+		result := "None"
+		if op.Result != nil {
+			result = pyType(op.Result)
+		}
+		fmt.Fprintf(&b, "\n    def %s(%s) -> %s:\n", snakeCase(op.ID), strings.Join(args, ", "), result)
+		if op.Summary != "" {
+			fmt.Fprintf(&b, "        %s\n", pyDocstring(op.Summary))
+		}
+		fmt.Fprintf(&b, "        return self._request(%q, %s, %s, %s)\n", op.Method, path, query, body)
+	}
+	b.WriteString(pyFooter)
+	return b.String()
+}
+
+// pyClass writes an object schema as a TypedDict.
+func pyClass(b *strings.Builder, s namedSchema) {
+	fmt.Fprintf(b, "class %s(TypedDict):\n", s.Name)
+	if s.Schema.Description != "" {
+		fmt.Fprintf(b, "    %s\n\n", pyDocstring(s.Schema.Description))
+	}
+	for _, name := range sortedProperties(s.Schema) {
+		t := pyType(s.Schema.Properties[name])
+		if !isRequired(s.Schema, name) {
+			t = "NotRequired[" + t + "]"
+		}
+		fmt.Fprintf(b, "    %s: %s\n", name, t)
+	}
+	b.WriteString("\n\n")
+}
+
+// pyType returns the Python type of a schema.
+func pyType(s *huma.Schema) string {
+	if s == nil {
+		return "Any"
+	}
+	if name, ok := refName(s); ok {
+		return name
+	}
+	var t string
+	switch s.Type {
+	case huma.TypeString:
+		t = "str"
+		if len(s.Enum) > 0 {
+			var values []string
+			for _, v := range s.Enum {
+				values = append(values, strconv.Quote(fmt.Sprint(v)))
+			}
+			t = "Literal[" + strings.Join(values, ", ") + "]"
+		}
+	case huma.TypeInteger:
+		t = "int"
+	case huma.TypeNumber:
+		t = "float"
+	case huma.TypeBoolean:
+		t = "bool"
+	case huma.TypeArray:
+		t = "List[" + pyType(s.Items) + "]"
+	case huma.TypeObject:
+		if values, ok := additionalSchema(s); ok {
+			t = "Dict[str, " + pyType(values) + "]"
+		} else {
+			t = "Dict[str, Any]"
+		}
+	default:
+		t = "Any"
+	}
+	if s.Nullable {
+		t = "Optional[" + t + "]"
+	}
+	return t
+}
+
+// pyDocstring returns text as a one-line docstring.
+func pyDocstring(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	return `"""` + strings.ReplaceAll(text, `"""`, `\"\"\"`) + `"""`
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// tsRuntime is the hand-written part of the TypeScript client: the transport,
+// error type and request helper the generated methods call.
+const tsRuntime = `/** An HTTP request made by the client. */
+export interface HTTPRequest {
+  method: string;
+  url: string;
+  headers: Record<string, string>;
+  body?: string;
+}
+
+/** The response to an HTTPRequest. */
+export interface HTTPResponse {
+  status: number;
+  body: string;
+}
+
+/**
+ * Sends requests for the client. The default uses fetch; environments
+ * without it, such as GNOME Shell, can pass their own.
+ */
+export type Transport = (request: HTTPRequest) => Promise<HTTPResponse>;
+
+type FetchLike = (
+  url: string,
+  init: { method: string; headers: Record<string, string>; body?: string },
+) => Promise<{ status: number; text(): Promise<string> }>;
+
+/** Returns a transport that sends requests with fetch. */
+export function fetchTransport(fetchFn?: FetchLike): Transport {
+  const send = fetchFn ?? ((globalThis as unknown as { fetch: FetchLike }).fetch);
+  return async (request) => {
+    const response = await send(request.url, {
+      method: request.method,
+      headers: request.headers,
+      body: request.body,
+    });
+    return { status: response.status, body: await response.text() };
+  };
+}
+
+/** An error returned by the daemon, with the code shared by all its APIs. */
+export class APIError extends Error {
+  constructor(
+    readonly status: number,
+    readonly code: string,
+    message: string,
+    readonly details?: Record<string, unknown>,
+  ) {
+    super(message);
+    this.name = "APIError";
+  }
+}
+
+export interface ClientOptions {
+  /** Base URL of the API, e.g. http://localhost:9123 */
+  baseURL: string;
+  apiKey?: string;
+  transport?: Transport;
+}
+
+type Query = Record<string, string | number | boolean | undefined>;
+
+export class KeylightdClient {
+  private readonly baseURL: string;
+  private readonly apiKey?: string;
+  private readonly transport: Transport;
+
+  constructor(options: ClientOptions) {
+    this.baseURL = options.baseURL.replace(/\/+$/, "");
+    this.apiKey = options.apiKey;
+    this.transport = options.transport ?? fetchTransport();
+  }
+
+  private async request<T>(method: string, path: string, query?: Query, body?: unknown): Promise<T> {
+    let url = this.baseURL + path;
+    const params: string[] = [];
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined) {
+        params.push(encodeURIComponent(key) + "=" + encodeURIComponent(String(value)));
+      }
+    }
+    if (params.length > 0) {
+      url += "?" + params.join("&");
+    }
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    if (this.apiKey) {
+      headers["X-API-Key"] = this.apiKey;
+    }
+    const response = await this.transport({
+      method,
+      url,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (response.status >= 400) {
+      let problem: { code?: string; detail?: string; details?: Record<string, unknown> } = {};
+      try {
+        problem = JSON.parse(response.body);
+      } catch {
+        // Not a problem details body
+      }
+      throw new APIError(
+        response.status,
+        problem.code ?? "internal",
+        problem.detail ?? (response.body || "HTTP error " + response.status),
+        problem.details,
+      );
+    }
+    return (response.body ? JSON.parse(response.body) : undefined) as T;
+  }
+`
+
+// generateTypeScript generates the TypeScript client.
+func generateTypeScript(spec *huma.OpenAPI) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by keylight-sdkgen from the keylightd OpenAPI spec. DO NOT EDIT.\n\n")
+
+	for _, s := range schemas(spec) {
+		tsInterface(&b, s)
+	}
+	b.WriteString(tsRuntime)
+
+	for _, op := range operations(spec) {
+		b.WriteString("\n")
+		if op.Summary != "" {
+			fmt.Fprintf(&b, "  /** %s */\n", tsComment(op.Summary))
+		}
+		var args []string
+		path := strconv.Quote(op.Path)
+		for _, p := range op.PathParams {
+			name := tsIdent(p)
+			args = append(args, name+": string")
+			path = strings.ReplaceAll(path, "{"+p+"}", `" + encodeURIComponent(`+name+`) + "`)
+		}
+		path = strings.TrimSuffix(path, ` + ""`)
+		body := "undefined"
+		if op.Body != nil {
+			args = append(args, "body: "+tsType(op.Body))
+			body = "body"
+		}
+		query := "undefined"
+		if len(op.Query) > 0 {
+			var fields []string
+			for _, q := range op.Query {
+				fields = append(fields, tsIdent(q.Name)+"?: "+tsType(q.Schema))
+			}
+			args = append(args, "query: { "+strings.Join(fields, "; ")+" } = {}")
+			query = "query"
+		}
+		result := "void"
+		if op.Result != nil {
+			result = tsType(op.Result)
+		}
+		fmt.Fprintf(&b, "  %s(%s): Promise<%s> {\n", op.ID, strings.Join(args, ", "), result)
+		fmt.Fprintf(&b, "    return this.request(%q, %s, %s, %s);\n", op.Method, path, query, body)
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// tsInterface writes a component schema as a TypeScript interface or type.
+func tsInterface(b *strings.Builder, s namedSchema) {
+	if s.Schema.Description != "" {
+		fmt.Fprintf(b, "/** %s */\n", tsComment(s.Schema.Description))
+	}
+	if s.Schema.Type != huma.TypeObject || len(s.Schema.Properties) == 0 {
+		fmt.Fprintf(b, "export type %s = %s;\n\n", s.Name, tsType(s.Schema))
+		return
+	}
+	fmt.Fprintf(b, "export interface %s {\n", s.Name)
+	for _, name := range sortedProperties(s.Schema) {
+		prop := s.Schema.Properties[name]
+		if prop.Description != "" {
+			fmt.Fprintf(b, "  /** %s */\n", tsComment(prop.Description))
+		}
+		optional := "?"
+		if isRequired(s.Schema, name) {
+			optional = ""
+		}
+		fmt.Fprintf(b, "  %s%s: %s;\n", tsIdent(name), optional, tsType(prop))
+	}
+	b.WriteString("}\n\n")
+}
+
+// tsType returns the TypeScript type of a schema.
+func tsType(s *huma.Schema) string {
+	if s == nil {
+		return "unknown"
+	}
+	if name, ok := refName(s); ok {
+		return name
+	}
+	var t string
+	switch s.Type {
+	case huma.TypeString:
+		t = "string"
+		if len(s.Enum) > 0 {
+			var values []string
+			for _, v := range s.Enum {
+				values = append(values, strconv.Quote(fmt.Sprint(v)))
+			}
+			t = strings.Join(values, " | ")
+		}
+	case huma.TypeInteger, huma.TypeNumber:
+		t = "number"
+	case huma.TypeBoolean:
+		t = "boolean"
+	case huma.TypeArray:
+		t = "Array<" + tsType(s.Items) + ">"
+	case huma.TypeObject:
+		if values, ok := additionalSchema(s); ok {
+			t = "Record<string, " + tsType(values) + ">"
+		} else {
+			t = "Record<string, unknown>"
+		}
+	default:
+		t = "unknown"
+	}
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+// tsIdent quotes a property name if it isn't a valid identifier.
+func tsIdent(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')) {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+// tsComment escapes text for use in a block comment.
+func tsComment(text string) string {
+	return strings.ReplaceAll(text, "*/", "*\\/")
+}
+
+// isRequired reports whether a property of an object schema is required.
+func isRequired(s *huma.Schema, name string) bool {
+	return slices.Contains(s.Required, name)
+}
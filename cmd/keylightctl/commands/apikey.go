@@ -41,7 +41,6 @@ func obfuscateAPIKey(key string) string {
 }
 
 func newAPIKeyListCommand(_ *slog.Logger) *cobra.Command {
-	var parseable bool
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all API keys",
@@ -56,63 +55,37 @@ func newAPIKeyListCommand(_ *slog.Logger) *cobra.Command {
 				return fmt.Errorf("failed to list API keys: %w", err)
 			}
 
+			format := outputFormat(cmd)
+			if format == OutputJSON {
+				if keys == nil {
+					keys = []client.APIKey{}
+				}
+				return printJSON(keys)
+			}
+
 			if len(keys) == 0 {
-				pterm.Info.Println("No API keys found.")
+				if format == OutputTable {
+					pterm.Info.Println("No API keys found.")
+				}
 				return nil
 			}
 
-			if parseable {
-				for _, keyMap := range keys {
-					keyStr, _ := keyMap["key"].(string) // Full key for parseable output
-					name, _ := keyMap["name"].(string)
-					disabledBool, _ := keyMap["disabled"].(bool)
-					enabledBool := !disabledBool // New: reflect enabled status
-
-					createdAt, _ := keyMap["created_at"].(time.Time)
-					expiresAt, _ := keyMap["expires_at"].(time.Time)
-					lastUsedAt, _ := keyMap["last_used_at"].(time.Time)
-
-					// Format time.Time objects for parseable output, handle zero times
-					createdAtOutput := ""
-					if !createdAt.IsZero() {
-						createdAtOutput = createdAt.Format(time.RFC3339Nano)
-					}
-					expiresAtOutput := ""
-					if !expiresAt.IsZero() {
-						expiresAtOutput = expiresAt.Format(time.RFC3339Nano)
-					}
-					lastUsedAtOutput := ""
-					if !lastUsedAt.IsZero() {
-						lastUsedAtOutput = lastUsedAt.Format(time.RFC3339Nano)
-					}
-
-					fmt.Printf("name=%s key=%s created_at=%s expires_at=%s last_used_at=%s enabled=%t\n",
-						strconv.Quote(name), strconv.Quote(keyStr), createdAtOutput, expiresAtOutput, lastUsedAtOutput, enabledBool)
+			if format == OutputParseable {
+				for i := range keys {
+					fmt.Println(apiKeyParseable(&keys[i]))
 				}
 				return nil
 			}
 
 			table := pterm.TableData{{"Name", "Key (Partial)", "Created At", "Expires At", "Last Used", "Enabled"}}
-			for _, keyMap := range keys {
-				keyStr, _ := keyMap["key"].(string)
-				name, _ := keyMap["name"].(string)
-				disabledBool, _ := keyMap["disabled"].(bool)
-				enabledBool := !disabledBool // New: reflect enabled status
-
-				// Assert directly to time.Time as the client now ensures this type
-				createdAt, _ := keyMap["created_at"].(time.Time)
-				expiresAt, _ := keyMap["expires_at"].(time.Time)
-				lastUsedAt, _ := keyMap["last_used_at"].(time.Time)
-
-				partialKey := obfuscateAPIKey(keyStr)
-
+			for _, key := range keys {
 				table = append(table, []string{
-					name,
-					partialKey,
-					formatTimeForDisplay(createdAt),
-					formatTimeForDisplay(expiresAt),
-					formatTimeForDisplay(lastUsedAt),
-					strconv.FormatBool(enabledBool),
+					key.Name,
+					obfuscateAPIKey(key.Key),
+					formatTimeForDisplay(key.CreatedAt),
+					formatTimeForDisplay(key.ExpiresAt),
+					formatTimeForDisplay(key.LastUsedAt),
+					strconv.FormatBool(!key.Disabled),
 				})
 			}
 			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
@@ -121,7 +94,7 @@ func newAPIKeyListCommand(_ *slog.Logger) *cobra.Command {
 			return nil
 		},
 	}
-	cmd.Flags().BoolVarP(&parseable, "parseable", "p", false, "Output in parseable format")
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format, same as --output parseable")
 	return cmd
 }
 
@@ -183,31 +156,30 @@ func newAPIKeyAddCommand(_ *slog.Logger) *cobra.Command {
 				}
 			}
 
+			format := outputFormat(cmd)
 			createdKey, err := apiClient.AddAPIKey(name, expiresInDuration.Seconds())
 			if err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to add API key: %w", err)
+				}
 				PrintPromptResult("error", "Failed to Add API Key", "", [][2]string{{"Name", name}, {"Error", err.Error()}})
 				return nil
 			}
 
-			keyStr, _ := createdKey["key"].(string)
-			keyName, _ := createdKey["name"].(string)
-			expiresAtStr, _ := createdKey["expires_at"].(string)
+			if format == OutputJSON {
+				return printJSON(createdKey)
+			}
+			if format == OutputParseable {
+				fmt.Println(apiKeyParseable(createdKey))
+				return nil
+			}
 
 			// Prepare fields for output
 			fields := [][2]string{
-				{"Name", keyName},
-			}
-			expiresVal := "Never"
-			if expiresAtStr != "" && expiresAtStr != "0001-01-01T00:00:00Z" {
-				expiresAt, errParse := time.Parse(time.RFC3339, expiresAtStr)
-				if errParse == nil {
-					expiresVal = expiresAt.Format(time.RFC1123)
-				} else {
-					expiresVal = expiresAtStr + " (raw)"
-				}
+				{"Name", createdKey.Name},
+				{"Expires", formatTimeForDisplay(createdKey.ExpiresAt)},
+				{"Key", createdKey.Key},
 			}
-			fields = append(fields, [2]string{"Expires", expiresVal})
-			fields = append(fields, [2]string{"Key", keyStr})
 
 			PrintPromptResult(
 				"success",
@@ -228,9 +200,10 @@ func newAPIKeyAddCommand(_ *slog.Logger) *cobra.Command {
 func newAPIKeyDeleteCommand(_ *slog.Logger) *cobra.Command {
 	var yes bool
 	cmd := &cobra.Command{
-		Use:   "delete [key_string]",
-		Short: "Delete an API key",
-		Args:  cobra.MaximumNArgs(1), // Key string is optional
+		Use:               "delete [key_or_name]",
+		Short:             "Delete an API key",
+		Args:              cobra.MaximumNArgs(1), // Key string is optional
+		ValidArgsFunction: completeAPIKeyName,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			apiClient, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
 			if !ok {
@@ -239,7 +212,7 @@ func newAPIKeyDeleteCommand(_ *slog.Logger) *cobra.Command {
 
 			var keyToDelete string
 			if len(args) > 0 {
-				keyToDelete = args[0]
+				keyToDelete = resolveAPIKey(apiClient, args[0])
 			} else {
 				// No key provided, fetch and let user select
 				keys, err := apiClient.ListAPIKeys()
@@ -255,11 +228,9 @@ func newAPIKeyDeleteCommand(_ *slog.Logger) *cobra.Command {
 				keyMapForSelection := make(map[string]string) // map display string to actual key
 
 				for _, apiKey := range keys {
-					name, _ := apiKey["name"].(string)
-					fullKey, _ := apiKey["key"].(string)
-					displayString := fmt.Sprintf("%s (%s)", name, obfuscateAPIKey(fullKey))
+					displayString := fmt.Sprintf("%s (%s)", apiKey.Name, obfuscateAPIKey(apiKey.Key))
 					options = append(options, displayString)
-					keyMapForSelection[displayString] = fullKey
+					keyMapForSelection[displayString] = apiKey.Key
 				}
 
 				selectedOption, err := pterm.DefaultInteractiveSelect.
@@ -289,11 +260,19 @@ func newAPIKeyDeleteCommand(_ *slog.Logger) *cobra.Command {
 				}
 			}
 
+			format := outputFormat(cmd)
 			if err := apiClient.DeleteAPIKey(keyToDelete); err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to delete API key: %w", err)
+				}
 				PrintPromptResult("error", "Failed to Delete API Key", "", [][2]string{{"Key", obfuscateAPIKey(keyToDelete)}, {"Error", err.Error()}})
 				return nil
 			}
 
+			if format != OutputTable {
+				return printResult(format, resultField{"key", obfuscateAPIKey(keyToDelete)})
+			}
+
 			pterm.Success.Printf("API Key '%s' deleted successfully.\n", obfuscateAPIKey(keyToDelete))
 			return nil
 		},
@@ -304,10 +283,11 @@ func newAPIKeyDeleteCommand(_ *slog.Logger) *cobra.Command {
 
 func newAPIKeySetEnabledCommand(_ *slog.Logger) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "set-enabled [key_or_name] [true|false]",
-		Short: "Set the enabled status of an API key (true for enabled, false for disabled).",
-		Long:  "Set the enabled status of an API key. \nIf key_or_name is not provided, an interactive selection will be shown. \nIf the boolean status (true/false or enabled/disabled) is not provided, an interactive selection for enabled/disabled will be shown.",
-		Args:  cobra.MaximumNArgs(2),
+		Use:               "set-enabled [key_or_name] [true|false]",
+		Short:             "Set the enabled status of an API key (true for enabled, false for disabled).",
+		Long:              "Set the enabled status of an API key. \nIf key_or_name is not provided, an interactive selection will be shown. \nIf the boolean status (true/false or enabled/disabled) is not provided, an interactive selection for enabled/disabled will be shown.",
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completeAPIKeyStatus,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			apiClient, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
 			if !ok {
@@ -347,13 +327,9 @@ func newAPIKeySetEnabledCommand(_ *slog.Logger) *cobra.Command {
 				options := []string{}
 				keyMapForSelection := make(map[string]string)
 				for _, apiKey := range keys {
-					name, _ := apiKey["name"].(string)
-					fullKey, _ := apiKey["key"].(string)
-					disabledStatus, _ := apiKey["disabled"].(bool)
-					enabledStatus := !disabledStatus
-					displayString := fmt.Sprintf("%s (%s) - Enabled: %t", name, obfuscateAPIKey(fullKey), enabledStatus)
+					displayString := fmt.Sprintf("%s (%s) - Enabled: %t", apiKey.Name, obfuscateAPIKey(apiKey.Key), !apiKey.Disabled)
 					options = append(options, displayString)
-					keyMapForSelection[displayString] = fullKey
+					keyMapForSelection[displayString] = apiKey.Key
 				}
 				selectedOption, err := pterm.DefaultInteractiveSelect.WithOptions(options).WithDefaultText("Select API key to update").Show()
 				if err != nil {
@@ -383,19 +359,52 @@ func newAPIKeySetEnabledCommand(_ *slog.Logger) *cobra.Command {
 				return fmt.Errorf("failed to set API key enabled status: %w", err)
 			}
 
-			updatedName, _ := updatedKey["name"].(string)
-			// The returned 'updatedKey' map contains the 'disabled' field.
-			// To show 'enabled' status, we invert it.
-			returnedDisabledStatus, _ := updatedKey["disabled"].(bool)
-			finalEnabledStatus := !returnedDisabledStatus
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"name", updatedKey.Name}, resultField{"key", obfuscateAPIKey(keyToUpdate)}, resultField{"enabled", !updatedKey.Disabled})
+			}
 
-			pterm.Success.Printf("API key '%s' (%s) status set to: Enabled=%t\n", updatedName, obfuscateAPIKey(keyToUpdate), finalEnabledStatus)
+			pterm.Success.Printf("API key '%s' (%s) status set to: Enabled=%t\n", updatedKey.Name, obfuscateAPIKey(keyToUpdate), !updatedKey.Disabled)
 			return nil
 		},
 	}
 	return cmd
 }
 
+// resolveAPIKey returns the key of the API key named keyOrName, or keyOrName
+// itself if no key has that name.
+func resolveAPIKey(apiClient client.ClientInterface, keyOrName string) string {
+	keys, err := apiClient.ListAPIKeys()
+	if err != nil {
+		return keyOrName
+	}
+	for _, key := range keys {
+		if key.Name == keyOrName {
+			return key.Key
+		}
+	}
+	return keyOrName
+}
+
+// apiKeyParseable returns the parseable key=value string for an API key,
+// including the full key.
+func apiKeyParseable(key *client.APIKey) string {
+	// Format timestamps for parseable output, handle zero times
+	createdAtOutput := ""
+	if !key.CreatedAt.IsZero() {
+		createdAtOutput = key.CreatedAt.Format(time.RFC3339Nano)
+	}
+	expiresAtOutput := ""
+	if !key.ExpiresAt.IsZero() {
+		expiresAtOutput = key.ExpiresAt.Format(time.RFC3339Nano)
+	}
+	lastUsedAtOutput := ""
+	if !key.LastUsedAt.IsZero() {
+		lastUsedAtOutput = key.LastUsedAt.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("name=%s key=%s created_at=%s expires_at=%s last_used_at=%s enabled=%t",
+		strconv.Quote(key.Name), strconv.Quote(key.Key), createdAtOutput, expiresAtOutput, lastUsedAtOutput, !key.Disabled)
+}
+
 // formatTimeForDisplay helper for consistent time formatting.
 // Handles zero time and RFC3339 parsing errors gracefully for display.
 func formatTimeForDisplay(t time.Time) string {
@@ -17,16 +17,16 @@ type mockAPIKeyClient struct {
 	client.ClientInterface
 	failAdd              bool
 	failDelete           bool
-	apiKeys              map[string]map[string]any
+	apiKeys              map[string]*client.APIKey
 	lastExpiresInSeconds float64
 }
 
-func (m *mockAPIKeyClient) AddAPIKey(name string, expiresInSeconds float64) (map[string]any, error) {
+func (m *mockAPIKeyClient) AddAPIKey(name string, expiresInSeconds float64) (*client.APIKey, error) {
 	if m.failAdd || m.apiKeys[name] != nil {
 		return nil, errors.New("duplicate or failed to add API key")
 	}
 	m.lastExpiresInSeconds = expiresInSeconds
-	key := map[string]any{"key": name + "-key", "name": name}
+	key := &client.APIKey{Key: name + "-key", Name: name}
 	m.apiKeys[name] = key
 	return key, nil
 }
@@ -39,10 +39,10 @@ func (m *mockAPIKeyClient) DeleteAPIKey(key string) error {
 	return nil
 }
 
-func (m *mockAPIKeyClient) ListAPIKeys() ([]map[string]any, error) {
-	var out []map[string]any
+func (m *mockAPIKeyClient) ListAPIKeys() ([]client.APIKey, error) {
+	var out []client.APIKey
 	for _, v := range m.apiKeys {
-		out = append(out, v)
+		out = append(out, *v)
 	}
 	return out, nil
 }
@@ -62,8 +62,8 @@ func parseKeyValueOutput(out string) map[string]string {
 }
 
 func TestAPIKeyAddCommand_Duplicate(t *testing.T) {
-	mock := &mockAPIKeyClient{apiKeys: map[string]map[string]any{}}
-	mock.apiKeys["dupe"] = map[string]any{"key": "dupe-key", "name": "dupe"}
+	mock := &mockAPIKeyClient{apiKeys: map[string]*client.APIKey{}}
+	mock.apiKeys["dupe"] = &client.APIKey{Key: "dupe-key", Name: "dupe"}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newAPIKeyAddCommand(logger)
@@ -79,7 +79,7 @@ func TestAPIKeyAddCommand_Duplicate(t *testing.T) {
 }
 
 func TestAPIKeyDeleteCommand_NotFound(t *testing.T) {
-	mock := &mockAPIKeyClient{apiKeys: map[string]map[string]any{}}
+	mock := &mockAPIKeyClient{apiKeys: map[string]*client.APIKey{}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newAPIKeyDeleteCommand(logger)
@@ -95,7 +95,7 @@ func TestAPIKeyDeleteCommand_NotFound(t *testing.T) {
 }
 
 func TestAPIKeyAddCommand_AcceptsDayDuration(t *testing.T) {
-	mock := &mockAPIKeyClient{apiKeys: map[string]map[string]any{}}
+	mock := &mockAPIKeyClient{apiKeys: map[string]*client.APIKey{}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newAPIKeyAddCommand(logger)
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// NewCircadianCommand creates the circadian command group.
+func NewCircadianCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "circadian",
+		Short: "Control circadian mode, which shifts color temperature across the day",
+		Long: "Control circadian mode, which shifts the color temperature (and optionally\n" +
+			"brightness) of lights that are on across the day. The curve, and the lights\n" +
+			"it applies to, are set in the circadian block of the daemon config.",
+	}
+
+	cmd.AddCommand(
+		newCircadianStatusCommand(logger),
+		newCircadianSetCommand(logger, "enable", true),
+		newCircadianSetCommand(logger, "disable", false),
+	)
+
+	return cmd
+}
+
+func newCircadianStatusCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether circadian mode is enabled and what it sets lights to",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			status, err := apiClient.GetCircadian()
+			if err != nil {
+				return fmt.Errorf("failed to get circadian mode: %w", err)
+			}
+			return printCircadian(outputFormat(cmd), status)
+		},
+	}
+}
+
+func newCircadianSetCommand(_ *slog.Logger, use string, enabled bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: strings.ToUpper(use[:1]) + use[1:] + " circadian mode",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			status, err := apiClient.SetCircadian(enabled)
+			if err != nil {
+				return fmt.Errorf("failed to %s circadian mode: %w", use, err)
+			}
+			format := outputFormat(cmd)
+			if format != OutputTable {
+				return printCircadian(format, status)
+			}
+			pterm.Success.Printf("Circadian mode %sd\n", use)
+			return nil
+		},
+	}
+}
+
+// circadianFields returns the circadian status as result fields
+func circadianFields(status *client.CircadianStatus) []resultField {
+	fields := []resultField{
+		{"enabled", status.Enabled},
+		{"mode", status.Mode},
+	}
+	if status.Target != nil {
+		fields = append(fields,
+			resultField{"temperature", status.Target.Temperature},
+			resultField{"brightness", status.Target.Brightness})
+	}
+	if !status.Sunrise.IsZero() {
+		fields = append(fields,
+			resultField{"sunrise", status.Sunrise.Unix()},
+			resultField{"sunset", status.Sunset.Unix()})
+	}
+	return append(fields, resultField{"lights", status.Lights})
+}
+
+// printCircadian prints the circadian status in the given output format
+func printCircadian(format string, status *client.CircadianStatus) error {
+	switch format {
+	case OutputJSON:
+		return printJSON(status)
+	case OutputParseable:
+		fmt.Println(parseableLine(circadianFields(status)))
+		return nil
+	}
+
+	mode := "not configured"
+	switch status.Mode {
+	case "sun":
+		mode = "following the sun"
+	case "points":
+		mode = "following curve points"
+	}
+	table := pterm.TableData{
+		{pterm.Bold.Sprint("Circadian"), pterm.Bold.Sprint(enabledString(status.Enabled))},
+		{"Mode", mode},
+	}
+	if t := status.Target; t != nil {
+		target := fmt.Sprintf("%dK", t.Temperature)
+		if t.Brightness != 0 {
+			target += ", " + strconv.Itoa(t.Brightness) + "% brightness"
+		}
+		table = append(table, []string{"Target", target})
+	}
+	if !status.Sunrise.IsZero() {
+		table = append(table,
+			[]string{"Sunrise", status.Sunrise.Local().Format(time.Kitchen)},
+			[]string{"Sunset", status.Sunset.Local().Format(time.Kitchen)})
+	}
+	lights := "none"
+	if len(status.Lights) > 0 {
+		lights = strings.Join(status.Lights, ", ")
+	}
+	table = append(table, []string{"Lights", lights})
+	if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	return nil
+}
+
+// enabledString returns "enabled" or "disabled"
+func enabledString(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+func TestCircadianCommands(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{}
+
+	cmd := newTestRootCommand(mock)
+	cmd.SetArgs([]string{"circadian", "enable"})
+	require.NoError(t, cmd.Execute())
+	require.True(t, mock.circadian)
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"circadian", "status", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	var status client.CircadianStatus
+	require.NoError(t, json.Unmarshal([]byte(out), &status))
+	require.True(t, status.Enabled)
+	require.Equal(t, 4700, status.Target.Temperature)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"circadian", "disable", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.False(t, mock.circadian)
+	require.Contains(t, out, `enabled=false mode="sun" temperature=4700 brightness=60 sunrise=1698303600 sunset=1698342300 lights="light-1"`)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"circadian", "status"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, "following the sun")
+	require.Contains(t, out, "4700K, 60% brightness")
+}
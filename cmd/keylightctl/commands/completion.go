@@ -0,0 +1,220 @@
+package commands
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// Shell completion functions. They query the daemon through the client in the
+// command's context, and complete nothing if it can't be reached.
+
+// lightProperties are the light properties that can be set
+var lightProperties = []string{"on", "brightness", "temperature", "hue", "saturation"}
+
+// completionClient returns the client from the command's context
+func completionClient(cmd *cobra.Command) (client.ClientInterface, bool) {
+	if cmd.Context() == nil {
+		return nil, false
+	}
+	c, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+	return c, ok
+}
+
+// lightIDCompletions returns the IDs of discovered lights, described by their
+// names, excluding any in exclude.
+func lightIDCompletions(cmd *cobra.Command, exclude []string) []cobra.Completion {
+	c, ok := completionClient(cmd)
+	if !ok {
+		return nil
+	}
+	lights, err := c.GetLights()
+	if err != nil {
+		return nil
+	}
+	completions := make([]cobra.Completion, 0, len(lights))
+	for id, light := range lights {
+		if slices.Contains(exclude, id) {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(id, cmp.Or(light.Name, light.ProductName)))
+	}
+	slices.Sort(completions)
+	return completions
+}
+
+// groupCompletions returns the names of all groups, described by their IDs.
+// Commands accept either.
+func groupCompletions(cmd *cobra.Command) []cobra.Completion {
+	c, ok := completionClient(cmd)
+	if !ok {
+		return nil
+	}
+	groups, err := c.GetGroups()
+	if err != nil {
+		return nil
+	}
+	completions := make([]cobra.Completion, 0, len(groups))
+	for _, group := range groups {
+		completions = append(completions, cobra.CompletionWithDesc(group.Name, group.ID))
+	}
+	slices.Sort(completions)
+	return completions
+}
+
+// apiKeyNameCompletions returns the names of all API keys
+func apiKeyNameCompletions(cmd *cobra.Command) []cobra.Completion {
+	c, ok := completionClient(cmd)
+	if !ok {
+		return nil
+	}
+	keys, err := c.ListAPIKeys()
+	if err != nil {
+		return nil
+	}
+	completions := make([]cobra.Completion, 0, len(keys))
+	for _, key := range keys {
+		completions = append(completions, key.Name)
+	}
+	slices.Sort(completions)
+	return completions
+}
+
+// scheduleCompletions returns the IDs of all schedules, described by their names
+func scheduleCompletions(cmd *cobra.Command) []cobra.Completion {
+	c, ok := completionClient(cmd)
+	if !ok {
+		return nil
+	}
+	schedules, err := c.ListSchedules()
+	if err != nil {
+		return nil
+	}
+	completions := make([]cobra.Completion, 0, len(schedules))
+	for _, s := range schedules {
+		id, _ := s["id"].(string)
+		name, _ := s["name"].(string)
+		completions = append(completions, cobra.CompletionWithDesc(id, name))
+	}
+	slices.Sort(completions)
+	return completions
+}
+
+// completeLightID completes a light ID as the first argument
+// sceneCompletions returns the IDs of all scenes, described by their names
+func sceneCompletions(cmd *cobra.Command) []cobra.Completion {
+	c, ok := completionClient(cmd)
+	if !ok {
+		return nil
+	}
+	scenes, err := c.ListScenes()
+	if err != nil {
+		return nil
+	}
+	completions := make([]cobra.Completion, 0, len(scenes))
+	for _, s := range scenes {
+		completions = append(completions, cobra.CompletionWithDesc(s.ID, s.Name))
+	}
+	slices.Sort(completions)
+	return completions
+}
+
+func completeLightID(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return lightIDCompletions(cmd, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLightProperty completes "<light> <property> [value]" arguments
+func completeLightProperty(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return lightIDCompletions(cmd, nil), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return lightProperties, cobra.ShellCompDirectiveNoFileComp
+	case 2:
+		if strings.ToLower(args[1]) == "on" {
+			return []cobra.Completion{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroup completes a group as the first argument
+func completeGroup(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return groupCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroupProperty completes "<group> <property> [value]" arguments
+func completeGroupProperty(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return groupCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeLightProperty(cmd, append([]string{""}, args[1:]...), toComplete)
+}
+
+// completeGroupLights completes a group followed by the lights to put in it
+func completeGroupLights(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return groupCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+	}
+	return lightIDCompletions(cmd, args[1:]), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAPIKeyName completes an API key name as the first argument
+func completeAPIKeyName(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return apiKeyNameCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAPIKeyStatus completes "<key name> <enabled|disabled>" arguments
+func completeAPIKeyStatus(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return apiKeyNameCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return []cobra.Completion{"enabled", "disabled"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeScene completes a scene ID as the first argument
+func completeScene(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sceneCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSchedule completes a schedule ID as the first argument
+func completeSchedule(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return scheduleCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLightFlag completes a flag that takes a light ID
+func completeLightFlag(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return lightIDCompletions(cmd, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroupFlag completes a flag that takes a group
+func completeGroupFlag(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return groupCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSceneFlag completes a flag that takes a scene
+func completeSceneFlag(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return sceneCompletions(cmd), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// complete runs cobra's hidden completion command and returns the completions
+func complete(t *testing.T, args ...string) []string {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := newTestRootCommand(&mockClient{})
+	cmd.SetOut(&out)
+	cmd.SetArgs(append([]string{"__complete"}, args...))
+	require.NoError(t, cmd.Execute())
+
+	// The last line is the completion directive
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	return lines[:len(lines)-1]
+}
+
+func TestCompletion(t *testing.T) {
+	require.Equal(t, []string{"light1\tLight 1", "light2\tLight 2"}, complete(t, "light", "toggle", ""))
+	require.Equal(t, lightProperties, complete(t, "light", "set", "light1", ""))
+	require.Equal(t, []string{"true", "false"}, complete(t, "light", "set", "light1", "on", ""))
+	require.Empty(t, complete(t, "light", "toggle", "light1", ""))
+	require.Equal(t, []string{"table", "json", "parseable"}, complete(t, "--output", ""))
+}
@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// Export document formats
+const (
+	exportFormatYAML = "yaml"
+	exportFormatJSON = "json"
+)
+
+// NewConfigCommand creates the config command group.
+func NewConfigCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Export and import groups, schedules, API keys and light names",
+		Long: "Export the daemon's groups, schedules, API keys and light names as a portable\n" +
+			"YAML or JSON document, and import it again on this or another host.",
+	}
+
+	cmd.AddCommand(
+		newConfigExportCommand(logger),
+		newConfigImportCommand(logger),
+	)
+
+	return cmd
+}
+
+func newConfigExportCommand(_ *slog.Logger) *cobra.Command {
+	var (
+		file           string
+		format         string
+		includeSecrets bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export groups, schedules, API keys and light names",
+		Long: "Export groups, schedules, API key metadata and light names to stdout or a file.\n" +
+			"API key secrets are left out unless --include-secrets is given; keys without\n" +
+			"their secret are skipped on import.",
+		Example: "  keylightctl config export > keylightd-backup.yaml\n" +
+			"  keylightctl config export --include-secrets --file keylightd-backup.json",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			if !cmd.Flags().Changed("format") && strings.EqualFold(filepath.Ext(file), ".json") {
+				format = exportFormatJSON
+			}
+
+			doc, err := apiClient.ExportConfig(includeSecrets)
+			if err != nil {
+				return fmt.Errorf("failed to export config: %w", err)
+			}
+			data, err := encodeExport(doc, format)
+			if err != nil {
+				return err
+			}
+
+			if file == "" || file == "-" {
+				_, err := os.Stdout.Write(data)
+				return err
+			}
+			// Secrets may be included, so keep the file private
+			if err := os.WriteFile(file, data, 0o600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			if outputFormat(cmd) == OutputTable {
+				pterm.Success.Printf("Exported %s to %s\n", exportSummary(len(doc.Groups), len(doc.Schedules), len(doc.APIKeys), len(doc.LightNames)), file)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "File to write to instead of stdout")
+	cmd.Flags().StringVar(&format, "format", exportFormatYAML, "Document format (yaml, json); json if --file ends in .json")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{exportFormatYAML, exportFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Include API key secrets")
+	return cmd
+}
+
+func newConfigImportCommand(_ *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import an exported document",
+		Long: "Import a document written by 'keylightctl config export', in YAML or JSON.\n" +
+			"Groups and schedules are created or replaced by ID, and API keys and light\n" +
+			"names are added. Nothing is deleted. Use - to read from stdin.",
+		Example: "  keylightctl config import keylightd-backup.yaml",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			var (
+				data []byte
+				err  error
+			)
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+			doc, err := decodeExport(data)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", args[0], err)
+			}
+
+			result, err := apiClient.ImportConfig(doc)
+			if err != nil {
+				return fmt.Errorf("failed to import config: %w", err)
+			}
+
+			switch format := outputFormat(cmd); format {
+			case OutputJSON:
+				return printJSON(result)
+			case OutputParseable:
+				return printResult(format,
+					resultField{"groups", result.Groups},
+					resultField{"schedules", result.Schedules},
+					resultField{"api_keys", result.APIKeys},
+					resultField{"light_names", result.LightNames},
+					resultField{"skipped", len(result.Skipped)})
+			}
+
+			pterm.Success.Printf("Imported %s\n", exportSummary(result.Groups, result.Schedules, result.APIKeys, result.LightNames))
+			for _, skipped := range result.Skipped {
+				pterm.Warning.Printf("Skipped %s\n", skipped)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// encodeExport formats an export document as YAML or JSON.
+func encodeExport(doc *client.ExportDocument, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	switch strings.ToLower(format) {
+	case exportFormatJSON:
+		return append(data, '\n'), nil
+	case exportFormatYAML:
+		// JSON is valid YAML, so decoding it into a node keeps the field
+		// names and order; clearing the styles turns it into block YAML.
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("failed to encode document: %w", err)
+		}
+		clearYAMLStyle(&node)
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return nil, fmt.Errorf("failed to encode document: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("invalid format %q: must be %s or %s", format, exportFormatYAML, exportFormatJSON)
+}
+
+// clearYAMLStyle resets the style of a node and its children to the default
+// block style.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// decodeExport parses an export document in YAML or JSON.
+func decodeExport(data []byte) (*client.ExportDocument, error) {
+	// Decode generically and convert through JSON, so the document's JSON
+	// field names apply to both formats.
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, errors.New("document is empty")
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var doc client.ExportDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// exportSummary describes how many of each kind of entry were exported or imported.
+func exportSummary(groups, schedules, apiKeys, lightNames int) string {
+	count := func(n int, noun string) string {
+		if n != 1 {
+			noun += "s"
+		}
+		return strconv.Itoa(n) + " " + noun
+	}
+	return strings.Join([]string{
+		count(groups, "group"),
+		count(schedules, "schedule"),
+		count(apiKeys, "API key"),
+		count(lightNames, "light name"),
+	}, ", ")
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+func TestConfigExport(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"config", "export"})
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Contains(t, out, "version: 1\n")
+	assert.Contains(t, out, "groups:\n  - id: group-1\n    name: Office\n")
+	assert.Contains(t, out, "light_names:\n  light-1: Left\n")
+	assert.NotContains(t, out, "secret")
+
+	// A .json file gets JSON, and secrets only when asked for
+	file := filepath.Join(t.TempDir(), "backup.json")
+	cmd := newTestRootCommand(&mockClient{})
+	cmd.SetArgs([]string{"config", "export", "--include-secrets", "--file", file})
+	require.NoError(t, cmd.Execute())
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	var doc client.ExportDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "secret", doc.APIKeys[0].Key)
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	cmd = newTestRootCommand(&mockClient{})
+	cmd.SetArgs([]string{"config", "export", "--format", "toml"})
+	assert.Error(t, cmd.Execute())
+}
+
+func TestExportSummary(t *testing.T) {
+	assert.Equal(t, "1 group, 0 schedules, 2 API keys, 1 light name", exportSummary(1, 0, 2, 1))
+}
+
+func TestConfigImport(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+
+	// Round trip an exported YAML document
+	file := filepath.Join(t.TempDir(), "backup.yaml")
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"config", "export"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.NoError(t, os.WriteFile(file, []byte(out), 0o600))
+
+	mock := &mockClient{}
+	cmd := newTestRootCommand(mock)
+	cmd.SetArgs([]string{"config", "import", file})
+	require.NoError(t, cmd.Execute())
+	require.NotNil(t, mock.imported)
+	assert.Equal(t, 1, mock.imported.Version)
+	require.Len(t, mock.imported.Groups, 1)
+	assert.Equal(t, []string{"light-1"}, mock.imported.Groups[0].Lights)
+	assert.Equal(t, "Left", mock.imported.LightNames["light-1"])
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"config", "import", file, "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Contains(t, out, "groups=1 schedules=0 api_keys=0 light_names=1 skipped=1")
+
+	empty := filepath.Join(t.TempDir(), "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	cmd = newTestRootCommand(mock)
+	cmd.SetArgs([]string{"config", "import", empty})
+	assert.Error(t, cmd.Execute())
+}
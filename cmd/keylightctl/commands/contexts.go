@@ -0,0 +1,329 @@
+package commands
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// APIKeyEnvVar is the environment variable that sets the API key when
+// --api-key isn't given.
+const APIKeyEnvVar = "KEYLIGHT_API_KEY"
+
+// ContextEnvVar is the environment variable that selects a context when
+// --context isn't given.
+const ContextEnvVar = "KEYLIGHT_CONTEXT"
+
+// DefaultContextName is the context that talks to the daemon on this machine,
+// found through its Unix socket.
+const DefaultContextName = "default"
+
+// selectClient returns the client the command line or client config asks for:
+// --socket, --api-url, --context or the current context, in that order. It
+// returns nil when none is set, leaving the daemon found at startup.
+func selectClient(cmd *cobra.Command, logger *slog.Logger) (client.ClientInterface, error) {
+	flags := cmd.Flags()
+	if socket, _ := flags.GetString("socket"); socket != "" {
+		return client.New(logger, socket), nil
+	}
+	apiKey, _ := flags.GetString("api-key")
+	apiKey = cmp.Or(apiKey, os.Getenv(APIKeyEnvVar))
+	if apiURL, _ := flags.GetString("api-url"); apiURL != "" {
+		if apiKey == "" {
+			return nil, fmt.Errorf("--api-url needs an API key; set --api-key or $%s", APIKeyEnvVar)
+		}
+		return client.NewHTTP(logger, apiURL, apiKey), nil
+	}
+
+	name, _ := flags.GetString("context")
+	name = cmp.Or(name, os.Getenv(ContextEnvVar))
+	cfg, err := loadClientConfig(config.GetClientConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	if name == "" || name == DefaultContextName {
+		if name == "" && cfg.CurrentContext != "" {
+			if ctx, ok := cfg.Context(cfg.CurrentContext); ok {
+				return contextClient(logger, ctx, apiKey), nil
+			}
+			// A stale current context shouldn't lock keylightctl out
+			logger.Warn("Current context not found, using the local socket", "context", cfg.CurrentContext)
+		}
+		return nil, nil
+	}
+	ctx, ok := cfg.Context(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown context %q; see keylightctl context list", name)
+	}
+	return contextClient(logger, ctx, apiKey), nil
+}
+
+// contextClient returns a client for ctx. apiKey, from --api-key or the
+// environment, overrides the context's key.
+func contextClient(logger *slog.Logger, ctx config.ClientContext, apiKey string) client.ClientInterface {
+	if ctx.APIURL != "" {
+		return client.NewHTTP(logger, ctx.APIURL, cmp.Or(apiKey, ctx.APIKey))
+	}
+	return client.New(logger, ctx.Socket)
+}
+
+// loadClientConfig reads keylightctl's contexts from its config file. A
+// missing file has none.
+func loadClientConfig(path string) (config.ClientConfig, error) {
+	var doc struct {
+		Config struct {
+			Client config.ClientConfig `yaml:"client"`
+		} `yaml:"config"`
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: the user's own client config
+	if errors.Is(err, os.ErrNotExist) {
+		return config.ClientConfig{}, nil
+	}
+	if err != nil {
+		return config.ClientConfig{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return config.ClientConfig{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return doc.Config.Client, nil
+}
+
+// saveClientConfig replaces the contexts in keylightctl's config file,
+// keeping its other settings.
+func saveClientConfig(path string, contexts config.ClientConfig) error {
+	return updateClientConfig(path, func(cfg map[string]any) {
+		if contexts.CurrentContext == "" && len(contexts.Contexts) == 0 {
+			delete(cfg, "client")
+			return
+		}
+		cfg["client"] = contexts
+	})
+}
+
+// NewContextCommand creates the context command
+func NewContextCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage the daemons keylightctl talks to",
+		Long: "Contexts name daemons keylightctl can talk to, over the HTTP API of another machine or a Unix socket. " +
+			"The current context is used unless --context, --api-url or --socket is given; the " + DefaultContextName +
+			" context is the daemon on this machine.",
+		// Managing contexts doesn't talk to a daemon, so skip picking one; a
+		// bad --context or current context can then still be fixed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateOutputFormat(outputFormat(cmd))
+		},
+	}
+	cmd.AddCommand(newContextListCommand(logger))
+	cmd.AddCommand(newContextAddCommand(logger))
+	cmd.AddCommand(newContextUseCommand(logger))
+	cmd.AddCommand(newContextRemoveCommand(logger))
+	return cmd
+}
+
+// contextTarget describes where a context connects to for display
+func contextTarget(ctx config.ClientContext) string {
+	switch {
+	case ctx.APIURL != "":
+		return ctx.APIURL
+	case ctx.Socket != "":
+		return "unix://" + ctx.Socket
+	default:
+		return "local socket"
+	}
+}
+
+func newContextListCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List contexts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadClientConfig(config.GetClientConfigPath())
+			if err != nil {
+				return err
+			}
+			current := cmp.Or(cfg.CurrentContext, DefaultContextName)
+			contexts := append([]config.ClientContext{{Name: DefaultContextName}}, cfg.Contexts...)
+
+			if format := outputFormat(cmd); format != OutputTable {
+				results := make([][]resultField, 0, len(contexts))
+				for _, ctx := range contexts {
+					results = append(results, []resultField{
+						{"name", ctx.Name},
+						{"current", ctx.Name == current},
+						{"target", contextTarget(ctx)},
+						{"api_key", obfuscateAPIKey(ctx.APIKey)},
+					})
+				}
+				return printResults(format, results)
+			}
+
+			table := pterm.TableData{{"", "Name", "Target", "API Key"}}
+			for _, ctx := range contexts {
+				marker := ""
+				if ctx.Name == current {
+					marker = "*"
+				}
+				table = append(table, []string{marker, ctx.Name, contextTarget(ctx), obfuscateAPIKey(ctx.APIKey)})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+func newContextAddCommand(_ *slog.Logger) *cobra.Command {
+	var ctx config.ClientContext
+	var use bool
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add or replace a context",
+		Example: "  keylightctl context add studio-pc --api-url https://studio-pc:9123 --api-key $KEY --use\n" +
+			"  keylightctl context add system --socket /run/keylightd/keylightd.sock",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.Name = args[0]
+			if ctx.Name == DefaultContextName {
+				return fmt.Errorf("%q is the daemon on this machine and can't be replaced", DefaultContextName)
+			}
+			switch {
+			case (ctx.APIURL == "") == (ctx.Socket == ""):
+				return errors.New("set one of --api-url or --socket")
+			case ctx.APIURL != "":
+				u, err := url.Parse(ctx.APIURL)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("invalid API URL %q, expected http(s)://host:port", ctx.APIURL)
+				}
+				if ctx.APIKey == "" {
+					return errors.New("--api-url needs --api-key")
+				}
+			case ctx.APIKey != "":
+				return errors.New("--api-key is only used with --api-url")
+			}
+
+			path := config.GetClientConfigPath()
+			cfg, err := loadClientConfig(path)
+			if err != nil {
+				return err
+			}
+			if i := slices.IndexFunc(cfg.Contexts, func(c config.ClientContext) bool { return c.Name == ctx.Name }); i >= 0 {
+				cfg.Contexts[i] = ctx
+			} else {
+				cfg.Contexts = append(cfg.Contexts, ctx)
+			}
+			if use {
+				cfg.CurrentContext = ctx.Name
+			}
+			if err := saveClientConfig(path, cfg); err != nil {
+				return err
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"name", ctx.Name}, resultField{"target", contextTarget(ctx)}, resultField{"current", use})
+			}
+			pterm.Success.Printf("Context %s saved (%s)\n", ctx.Name, contextTarget(ctx))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&ctx.APIURL, "api-url", "", "Base URL of the daemon's HTTP API")
+	cmd.Flags().StringVar(&ctx.APIKey, "api-key", "", "API key for the HTTP API")
+	cmd.Flags().StringVar(&ctx.Socket, "socket", "", "Path to the daemon's Unix socket")
+	cmd.Flags().BoolVar(&use, "use", false, "Make it the current context")
+	return cmd
+}
+
+func newContextUseCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:               "use <name>",
+		Short:             "Set the current context",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContexts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			path := config.GetClientConfigPath()
+			cfg, err := loadClientConfig(path)
+			if err != nil {
+				return err
+			}
+			if name == DefaultContextName {
+				cfg.CurrentContext = ""
+			} else if _, ok := cfg.Context(name); !ok {
+				return fmt.Errorf("unknown context %q; see keylightctl context list", name)
+			} else {
+				cfg.CurrentContext = name
+			}
+			if err := saveClientConfig(path, cfg); err != nil {
+				return err
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"current", name})
+			}
+			pterm.Success.Printf("Switched to context %s\n", name)
+			return nil
+		},
+	}
+}
+
+func newContextRemoveCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:               "remove <name>",
+		Short:             "Remove a context",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeContexts,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			path := config.GetClientConfigPath()
+			cfg, err := loadClientConfig(path)
+			if err != nil {
+				return err
+			}
+			i := slices.IndexFunc(cfg.Contexts, func(c config.ClientContext) bool { return c.Name == name })
+			if i < 0 {
+				return fmt.Errorf("unknown context %q; see keylightctl context list", name)
+			}
+			cfg.Contexts = slices.Delete(cfg.Contexts, i, i+1)
+			if cfg.CurrentContext == name {
+				cfg.CurrentContext = ""
+			}
+			if err := saveClientConfig(path, cfg); err != nil {
+				return err
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"removed", name})
+			}
+			pterm.Success.Printf("Context %s removed\n", name)
+			return nil
+		},
+	}
+}
+
+// completeContexts completes context names
+func completeContexts(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := loadClientConfig(config.GetClientConfigPath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := []string{DefaultContextName}
+	for _, ctx := range cfg.Contexts {
+		names = append(names, ctx.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+func TestContextCommands(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(OutputEnvVar, "")
+	run := func(args ...string) error {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	require.NoError(t, run("context", "add", "studio-pc", "--api-url", "https://studio-pc:9123", "--api-key", "0123456789abcdef", "--use"))
+	require.NoError(t, run("context", "add", "system", "--socket", "/run/keylightd/keylightd.sock"))
+	assert.Error(t, run("context", "add", "broken", "--api-url", "https://studio-pc:9123"), "an HTTP context needs a key")
+	assert.Error(t, run("context", "add", "broken", "--api-url", "studio-pc:9123", "--api-key", "k"), "the URL needs a scheme")
+	assert.Error(t, run("context", "add", DefaultContextName, "--socket", "/tmp/x.sock"))
+
+	cfg, err := loadClientConfig(config.GetClientConfigPath())
+	require.NoError(t, err)
+	assert.Equal(t, "studio-pc", cfg.CurrentContext)
+	require.Len(t, cfg.Contexts, 2)
+
+	out := captureStdout(func() {
+		require.NoError(t, run("context", "list", "--output", "json"))
+	})
+	var contexts []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &contexts))
+	require.Len(t, contexts, 3)
+	assert.Equal(t, DefaultContextName, contexts[0]["name"])
+	assert.Equal(t, true, contexts[1]["current"])
+	assert.NotContains(t, out, "0123456789abcdef", "keys are obfuscated")
+
+	require.NoError(t, run("context", "use", "system"))
+	assert.Error(t, run("context", "use", "missing"))
+	require.NoError(t, run("context", "remove", "system"))
+	cfg, err = loadClientConfig(config.GetClientConfigPath())
+	require.NoError(t, err)
+	assert.Empty(t, cfg.CurrentContext, "removing the current context falls back to the local socket")
+	assert.Len(t, cfg.Contexts, 1)
+}
+
+func TestSelectClient(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	require.NoError(t, saveClientConfig(config.GetClientConfigPath(), config.ClientConfig{
+		CurrentContext: "studio-pc",
+		Contexts: []config.ClientContext{
+			{Name: "studio-pc", APIURL: "https://studio-pc:9123", APIKey: "key"},
+			{Name: "system", Socket: "/run/keylightd/keylightd.sock"},
+		},
+	}))
+	selected := func(args ...string) (client.ClientInterface, error) {
+		cmd := NewRootCommand(nil, "dev", "unknown", "unknown")
+		require.NoError(t, cmd.ParseFlags(args))
+		return selectClient(cmd, slog.Default())
+	}
+
+	c, err := selected()
+	require.NoError(t, err)
+	assert.IsType(t, &client.HTTPClient{}, c, "the current context is used")
+
+	c, err = selected("--context", "system")
+	require.NoError(t, err)
+	assert.IsType(t, &client.Client{}, c)
+
+	c, err = selected("--context", DefaultContextName)
+	require.NoError(t, err)
+	assert.Nil(t, c, "the default context keeps the daemon found at startup")
+
+	c, err = selected("--socket", "/tmp/keylightd.sock", "--context", "studio-pc")
+	require.NoError(t, err)
+	assert.IsType(t, &client.Client{}, c, "--socket wins over contexts")
+
+	_, err = selected("--api-url", "http://localhost:9123")
+	assert.Error(t, err, "--api-url needs a key")
+	t.Setenv(APIKeyEnvVar, "key")
+	c, err = selected("--api-url", "http://localhost:9123")
+	require.NoError(t, err)
+	assert.IsType(t, &client.HTTPClient{}, c)
+
+	_, err = selected("--context", "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,370 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/crash"
+	"github.com/jmylchreest/keylightd/internal/launchd"
+	"github.com/jmylchreest/keylightd/internal/systemd"
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// DefaultBundleLogLines is the number of recent daemon log lines collected
+// in a debug bundle.
+const DefaultBundleLogLines = 2000
+
+// maxLogTail is the most read from the end of a log file for a bundle.
+const maxLogTail = 4 << 20
+
+// NewDebugCommand creates the debug command group.
+func NewDebugCommand(logger *slog.Logger, version, commit, buildDate string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Collect diagnostics for bug reports",
+	}
+
+	cmd.AddCommand(newDebugBundleCommand(logger, version, commit, buildDate))
+
+	return cmd
+}
+
+func newDebugBundleCommand(_ *slog.Logger, version, commit, buildDate string) *cobra.Command {
+	var (
+		file       string
+		configPath string
+		crashDir   string
+		logLines   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Write a tarball of diagnostics to attach to a GitHub issue",
+		Long: "Collect the daemon's version and discovery status, the light and group\n" +
+			"inventory, its logging settings, the config files, recent daemon logs and\n" +
+			"crash reports into a .tar.gz file to attach to a GitHub issue.\n\n" +
+			"API keys, passwords, the HomeKit setup code, the circadian location and hook\n" +
+			"commands are redacted from the config files. Logs, light names and addresses\n" +
+			"are included as they are, so look through the bundle before sharing it.\n\n" +
+			"What can't be collected, such as the daemon's details when it isn't running,\n" +
+			"is listed in the bundle's manifest.json.",
+		Example: "  keylightctl debug bundle\n" +
+			"  keylightctl debug bundle --file /tmp/keylightd-debug.tar.gz --log-lines 10000",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			now := time.Now().UTC()
+			if file == "" {
+				file = "keylightd-debug-" + now.Format("20060102T150405Z") + ".tar.gz"
+			}
+
+			b := newDebugBundle(now, version, commit, buildDate)
+			collectDaemon(b, apiClient)
+			b.addConfigFile("config/"+config.DaemonConfigFilename, cmp.Or(configPath, config.GetDaemonConfigPath()))
+			b.addConfigFile("config/"+config.ClientConfigFilename, config.GetClientConfigPath())
+			if logLines > 0 {
+				if name, logs, err := recentLogs(logLines); err != nil {
+					b.fail("logs", err)
+				} else {
+					b.add("logs/"+name, logs)
+				}
+			}
+			b.addCrashReports(cmp.Or(crashDir, config.GetCrashReportDir()))
+
+			f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", file, err)
+			}
+			if err := b.write(f); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format,
+					resultField{"file", file},
+					resultField{"files", len(b.manifest.Files)},
+					resultField{"errors", b.manifest.Errors},
+				)
+			}
+			for _, msg := range b.manifest.Errors {
+				pterm.Warning.Printf("Not collected: %s\n", msg)
+			}
+			pterm.Success.Printf("Wrote debug bundle to %s\n", file)
+			pterm.Info.Println("Secrets are redacted from config files; review the bundle before sharing it.")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "File to write (default: keylightd-debug-<time>.tar.gz)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Daemon config file to include (default: "+config.GetDaemonConfigPath()+")")
+	cmd.Flags().StringVar(&crashDir, "crash-dir", "", "Directory of the daemon's crash reports (default: "+config.GetCrashReportDir()+")")
+	cmd.Flags().IntVar(&logLines, "log-lines", DefaultBundleLogLines, "Recent daemon log lines to include; 0 leaves logs out")
+	return cmd
+}
+
+// debugManifest describes a debug bundle and what couldn't be collected.
+type debugManifest struct {
+	CreatedAt time.Time     `json:"created_at"`
+	Client    debugVersion  `json:"client"`
+	Daemon    *debugVersion `json:"daemon,omitempty"`
+	OS        string        `json:"os"`
+	Arch      string        `json:"arch"`
+	GoVersion string        `json:"go_version"`
+	Files     []string      `json:"files"`
+	Errors    []string      `json:"errors,omitempty"`
+}
+
+type debugVersion struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+}
+
+type debugFile struct {
+	name string
+	data []byte
+}
+
+// debugBundle gathers the files of a debug bundle in memory.
+type debugBundle struct {
+	dir      string // directory the files are placed in within the tarball
+	manifest debugManifest
+	files    []debugFile
+}
+
+func newDebugBundle(now time.Time, version, commit, buildDate string) *debugBundle {
+	return &debugBundle{
+		dir: "keylightd-debug-" + now.Format("20060102T150405Z"),
+		manifest: debugManifest{
+			CreatedAt: now,
+			Client:    debugVersion{Version: version, Commit: commit, BuildDate: buildDate},
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			GoVersion: runtime.Version(),
+		},
+	}
+}
+
+func (b *debugBundle) add(name string, data []byte) {
+	b.files = append(b.files, debugFile{name: name, data: data})
+	b.manifest.Files = append(b.manifest.Files, name)
+}
+
+// addJSON adds v as indented JSON, or records err if getting it failed.
+func (b *debugBundle) addJSON(name string, v any, err error) {
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	b.add(name, append(data, '\n'))
+}
+
+// fail records something that couldn't be collected.
+func (b *debugBundle) fail(what string, err error) {
+	b.manifest.Errors = append(b.manifest.Errors, fmt.Sprintf("%s: %v", what, err))
+}
+
+// addConfigFile adds a config file with its secrets redacted. A missing
+// file isn't an error, as the defaults are used in its place.
+func (b *debugBundle) addConfigFile(name, path string) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: config file chosen by the user
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	sanitized, err := config.Sanitize(data)
+	if err != nil {
+		// Without parsing it, secrets can't be told apart
+		b.fail(name, fmt.Errorf("left out as it isn't valid YAML: %w", err))
+		return
+	}
+	b.add(name, sanitized)
+}
+
+// addCrashReports adds the daemon's crash reports, if any.
+func (b *debugBundle) addCrashReports(dir string) {
+	reports, err := crash.Reports(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		b.fail("crashes", err)
+		return
+	}
+	for _, path := range reports {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: report listed by crash.Reports
+		if err != nil {
+			b.fail("crashes", err)
+			continue
+		}
+		b.add("crashes/"+filepath.Base(path), data)
+	}
+}
+
+// write writes the bundle, with its manifest, as a gzipped tarball.
+func (b *debugBundle) write(w io.Writer) error {
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	files := append([]debugFile{{name: "manifest.json", data: append(manifest, '\n')}}, b.files...)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    b.dir + "/" + f.name,
+			Mode:    0600,
+			Size:    int64(len(f.data)),
+			ModTime: b.manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// collectDaemon adds what the daemon reports about itself, its lights and
+// groups, and its logging settings.
+func collectDaemon(b *debugBundle, c client.ClientInterface) {
+	info, err := daemonInfo(c)
+	if err == nil {
+		b.manifest.Daemon = &debugVersion{Version: info.Version, Commit: info.Commit, BuildDate: info.BuildDate}
+	}
+	b.addJSON("daemon.json", info, err)
+	if err != nil {
+		// The daemon isn't reachable, so the rest would fail the same way
+		return
+	}
+
+	lights, err := c.GetLights()
+	b.addJSON("lights.json", lights, err)
+	groups, err := c.GetGroups()
+	b.addJSON("groups.json", groups, err)
+
+	level, err := c.GetLogLevel()
+	if err != nil {
+		b.fail("logging.json", err)
+		return
+	}
+	filters, err := c.ListLogFilters()
+	b.addJSON("logging.json", map[string]any{"level": level, "filters": filters}, err)
+}
+
+// recentLogs returns the last lines of the daemon's log and a file name for
+// them; replaced in tests. On Linux they are read from the journal of the
+// user service, or the system service if the user has none, and on macOS
+// from the launchd agent's log file.
+var recentLogs = func(lines int) (string, []byte, error) {
+	switch runtime.GOOS {
+	case "linux":
+		n := strconv.Itoa(lines)
+		var lastErr error
+		for _, args := range [][]string{
+			{"--user", "-u", systemd.UnitName, "-n", n, "--no-pager", "-q"},
+			{"-u", systemd.UnitName, "-n", n, "--no-pager", "-q"},
+		} {
+			out, err := exec.Command("journalctl", args...).Output()
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if len(bytes.TrimSpace(out)) > 0 {
+				return "journal.log", out, nil
+			}
+		}
+		if lastErr != nil {
+			return "", nil, fmt.Errorf("failed to read the journal: %w", lastErr)
+		}
+		return "", nil, errors.New("no keylightd entries in the journal")
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil, err
+		}
+		out, err := tailFile(launchd.LogPath(home), lines)
+		if err != nil {
+			return "", nil, err
+		}
+		return "keylightd.log", out, nil
+	}
+	return "", nil, fmt.Errorf("collecting daemon logs isn't supported on %s", runtime.GOOS)
+}
+
+// tailFile returns up to the last lines of the file at path, reading no more
+// than maxLogTail bytes from its end.
+func tailFile(path string, lines int) ([]byte, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: the daemon's log file
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := max(info.Size()-maxLogTail, 0)
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return nil, err
+	}
+	return lastLines(data, lines), nil
+}
+
+// lastLines returns the last n lines of data.
+func lastLines(data []byte, n int) []byte {
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	for i := end - 1; i >= 0; i-- {
+		if data[i] == '\n' {
+			n--
+			if n == 0 {
+				return data[i+1:]
+			}
+		}
+	}
+	return data
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// readBundle returns the files in a debug bundle by their path within it.
+func readBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path) //nolint:gosec // G304: test file
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		dir, name, _ := strings.Cut(hdr.Name, "/")
+		assert.True(t, strings.HasPrefix(dir, "keylightd-debug-"))
+		files[name] = string(data)
+	}
+	return files
+}
+
+func TestDebugBundleCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+
+	configPath := filepath.Join(dir, "keylightd.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("config:\n  mqtt:\n    broker: tcp://broker:1883\n    password: hunter2\n"), 0600))
+	crashDir := filepath.Join(dir, "crashes")
+	require.NoError(t, os.Mkdir(crashDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(crashDir, "panic-20261016T090000.000Z-42.txt"), []byte("panic: boom [recovered]\n"), 0600))
+
+	origLogs := recentLogs
+	defer func() { recentLogs = origLogs }()
+	var gotLines int
+	recentLogs = func(lines int) (string, []byte, error) {
+		gotLines = lines
+		return "journal.log", []byte("Starting keylightd\n"), nil
+	}
+
+	file := filepath.Join(dir, "bundle.tar.gz")
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{logLevel: "debug"})
+		cmd.SetArgs([]string{"debug", "bundle", "--file", file, "--config", configPath, "--crash-dir", crashDir, "--log-lines", "50", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	var result map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Equal(t, file, result["file"])
+	assert.Nil(t, result["errors"])
+	assert.Equal(t, 50, gotLines)
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	files := readBundle(t, file)
+	for _, name := range []string{"manifest.json", "daemon.json", "lights.json", "groups.json", "logging.json",
+		"config/keylightd.yaml", "logs/journal.log", "crashes/panic-20261016T090000.000Z-42.txt"} {
+		assert.Contains(t, files, name)
+	}
+	assert.NotContains(t, files, "config/keylightctl.yaml", "missing config files are skipped")
+
+	var manifest debugManifest
+	require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	assert.Equal(t, "dev", manifest.Client.Version)
+	require.NotNil(t, manifest.Daemon)
+	assert.Equal(t, "1.2.3", manifest.Daemon.Version)
+	assert.Empty(t, manifest.Errors)
+
+	assert.Contains(t, files["daemon.json"], `"socket_path": "/run/keylightd.sock"`)
+	assert.Contains(t, files["lights.json"], `"light1"`)
+	assert.Contains(t, files["logging.json"], `"level": "debug"`)
+	assert.Contains(t, files["config/keylightd.yaml"], "password: REDACTED")
+	assert.NotContains(t, files["config/keylightd.yaml"], "hunter2")
+	assert.Equal(t, "Starting keylightd\n", files["logs/journal.log"])
+}
+
+// unreachableClient fails as a client does when the daemon isn't running.
+type unreachableClient struct {
+	*mockClient
+}
+
+var errUnreachable = errors.New("dial unix /run/keylightd.sock: connect: no such file or directory")
+
+func (unreachableClient) GetDaemonInfo() (*client.DaemonInfo, error) { return nil, errUnreachable }
+
+func (unreachableClient) GetVersion() (map[string]any, error) { return nil, errUnreachable }
+
+func TestDebugBundleCommand_Unreachable(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+
+	origLogs := recentLogs
+	defer func() { recentLogs = origLogs }()
+	recentLogs = func(int) (string, []byte, error) { return "", nil, errors.New("no keylightd entries in the journal") }
+
+	file := filepath.Join(dir, "bundle.tar.gz")
+	out := captureStdout(func() {
+		cmd := NewRootCommand(nil, "dev", "unknown", "unknown")
+		cmd.SetContext(context.WithValue(context.Background(), clientContextKey, client.ClientInterface(unreachableClient{&mockClient{}})))
+		cmd.SetArgs([]string{"debug", "bundle", "--file", file, "--crash-dir", filepath.Join(dir, "none"), "--output", "json"})
+		require.NoError(t, cmd.Execute(), "a bundle is written without the daemon")
+	})
+	var result struct {
+		Errors []string `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Equal(t, []string{
+		"daemon.json: failed to get daemon info: " + errUnreachable.Error(),
+		"logs: no keylightd entries in the journal",
+	}, result.Errors)
+
+	files := readBundle(t, file)
+	assert.Len(t, files, 1)
+	assert.Contains(t, files["manifest.json"], `"logs: no keylightd entries in the journal"`)
+}
+
+func TestLastLines(t *testing.T) {
+	data := []byte("one\ntwo\nthree\n")
+	assert.Equal(t, "two\nthree\n", string(lastLines(data, 2)))
+	assert.Equal(t, "three\n", string(lastLines(data, 1)))
+	assert.Equal(t, "one\ntwo\nthree\n", string(lastLines(data, 5)))
+	assert.Equal(t, "two\nthree", string(lastLines([]byte("one\ntwo\nthree"), 2)))
+}
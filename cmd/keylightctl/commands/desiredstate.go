@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// NewDesiredStateCommand creates the desired-state command group.
+func NewDesiredStateCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "desired-state",
+		Short: "Control desired-state mode, which puts changed lights back",
+		Long: "Control desired-state mode, which checks lights and groups at an interval and\n" +
+			"puts back any power, brightness or temperature changed outside its targets.\n" +
+			"The targets are set in the desired_state block of the daemon config; a target\n" +
+			"is given by its key, such as light:<id> or group:<name>.",
+	}
+
+	cmd.AddCommand(
+		newDesiredStateStatusCommand(logger),
+		newDesiredStateSetCommand(logger, "enable", true),
+		newDesiredStateSetCommand(logger, "disable", false),
+		newDesiredStateTargetCommand(logger, "include", true),
+		newDesiredStateTargetCommand(logger, "exclude", false),
+	)
+
+	return cmd
+}
+
+func newDesiredStateStatusCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether desired-state mode is enabled and the state of its targets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			status, err := apiClient.GetDesiredState()
+			if err != nil {
+				return fmt.Errorf("failed to get desired-state mode: %w", err)
+			}
+			return printDesiredState(outputFormat(cmd), status)
+		},
+	}
+}
+
+func newDesiredStateSetCommand(_ *slog.Logger, use string, enabled bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: strings.ToUpper(use[:1]) + use[1:] + " desired-state mode",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			status, err := apiClient.SetDesiredState(enabled)
+			if err != nil {
+				return fmt.Errorf("failed to %s desired-state mode: %w", use, err)
+			}
+			format := outputFormat(cmd)
+			if format != OutputTable {
+				return printDesiredState(format, status)
+			}
+			pterm.Success.Printf("Desired-state mode %sd\n", use)
+			return nil
+		},
+	}
+}
+
+func newDesiredStateTargetCommand(_ *slog.Logger, use string, enabled bool) *cobra.Command {
+	short := "Start putting a target's lights back"
+	if !enabled {
+		short = "Stop putting a target's lights back, so they can be changed freely"
+	}
+	return &cobra.Command{
+		Use:   use + " <target>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			status, err := apiClient.SetDesiredStateTarget(args[0], enabled)
+			if err != nil {
+				return fmt.Errorf("failed to %s desired-state target %s: %w", use, args[0], err)
+			}
+			format := outputFormat(cmd)
+			if format != OutputTable {
+				return printDesiredState(format, status)
+			}
+			pterm.Success.Printf("Desired-state target %s %sd\n", args[0], use)
+			return nil
+		},
+	}
+}
+
+// desiredStateTargetFields returns a desired-state target as result fields
+func desiredStateTargetFields(t client.DesiredStateTarget) []resultField {
+	fields := []resultField{
+		{"target", t.Target},
+		{"enabled", t.Enabled},
+	}
+	if t.On != nil {
+		fields = append(fields, resultField{"on", *t.On})
+	}
+	if t.Brightness != nil {
+		fields = append(fields, resultField{"brightness", *t.Brightness})
+	}
+	if t.Temperature != nil {
+		fields = append(fields, resultField{"temperature", *t.Temperature})
+	}
+	fields = append(fields,
+		resultField{"lights", t.Lights},
+		resultField{"corrections", t.Corrections})
+	if !t.LastCorrection.IsZero() {
+		fields = append(fields, resultField{"last_correction", t.LastCorrection.Unix()})
+	}
+	return fields
+}
+
+// desiredStateSummary describes the state a target holds its lights in
+func desiredStateSummary(t client.DesiredStateTarget) string {
+	var parts []string
+	if t.On != nil && *t.On {
+		parts = append(parts, "on")
+	} else if t.On != nil {
+		parts = append(parts, "off")
+	}
+	if t.Brightness != nil {
+		parts = append(parts, strconv.Itoa(*t.Brightness)+"% brightness")
+	}
+	if t.Temperature != nil {
+		parts = append(parts, strconv.Itoa(*t.Temperature)+"K")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printDesiredState prints the desired-state status in the given output format
+func printDesiredState(format string, status *client.DesiredStateStatus) error {
+	switch format {
+	case OutputJSON:
+		return printJSON(status)
+	case OutputParseable:
+		fmt.Println(parseableLine([]resultField{
+			{"enabled", status.Enabled},
+			{"interval", status.Interval},
+		}))
+		for _, t := range status.Targets {
+			fmt.Println(parseableLine(desiredStateTargetFields(t)))
+		}
+		return nil
+	}
+
+	table := pterm.TableData{
+		{pterm.Bold.Sprint("Desired state"), pterm.Bold.Sprint(enabledString(status.Enabled)), "", "", ""},
+		{"Interval", (time.Duration(status.Interval) * time.Second).String(), "", "", ""},
+	}
+	if len(status.Targets) > 0 {
+		table = append(table, []string{pterm.Bold.Sprint("Target"), pterm.Bold.Sprint("State"), pterm.Bold.Sprint("Enforced"), pterm.Bold.Sprint("Lights"), pterm.Bold.Sprint("Corrections")})
+	}
+	for _, t := range status.Targets {
+		lights := "none"
+		if len(t.Lights) > 0 {
+			lights = strings.Join(t.Lights, ", ")
+		}
+		corrections := strconv.Itoa(t.Corrections)
+		if !t.LastCorrection.IsZero() {
+			corrections += " (last " + t.LastCorrection.Local().Format(time.Kitchen) + ")"
+		}
+		table = append(table, []string{t.Target, desiredStateSummary(t), enabledString(t.Enabled), lights, corrections})
+	}
+	if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+func TestDesiredStateCommands(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{}
+
+	cmd := newTestRootCommand(mock)
+	cmd.SetArgs([]string{"desired-state", "enable"})
+	require.NoError(t, cmd.Execute())
+	require.True(t, mock.desired)
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"desired-state", "status", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	var status client.DesiredStateStatus
+	require.NoError(t, json.Unmarshal([]byte(out), &status))
+	require.True(t, status.Enabled)
+	require.Len(t, status.Targets, 1)
+	require.Equal(t, 40, *status.Targets[0].Brightness)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"desired-state", "exclude", "group:office", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.True(t, mock.excluded["group:office"])
+	require.Contains(t, out, "enabled=true interval=30\n")
+	require.Contains(t, out, `target="group:office" enabled=false on=true brightness=40 lights="light-1,light-2" corrections=2 last_correction=1698312600`)
+
+	cmd = newTestRootCommand(mock)
+	cmd.SetArgs([]string{"desired-state", "include", "group:missing"})
+	require.Error(t, cmd.Execute())
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"desired-state", "status"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, "on, 40% brightness")
+	require.Contains(t, out, "light-1, light-2")
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// NewDiscoverCommand creates the discover command, which asks the daemon to
+// run a discovery pass now rather than waiting for the next interval.
+func NewDiscoverCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "discover",
+		Short: "Look for new lights now",
+		Long: "Ask the daemon to run a discovery pass now instead of waiting for the next\n" +
+			"discovery interval, and list any lights it found that it didn't already know.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			format := outputFormat(cmd)
+			if format != OutputTable {
+				result, err := apiClient.DiscoverNow()
+				if err != nil {
+					return fmt.Errorf("failed to discover lights: %w", err)
+				}
+				return printDiscovery(format, result)
+			}
+
+			spinner, _ := pterm.DefaultSpinner.Start("Looking for lights...")
+			result, err := apiClient.DiscoverNow()
+			if err != nil {
+				_ = spinner.Stop()
+				return fmt.Errorf("failed to discover lights: %w", err)
+			}
+			if len(result.Found) == 0 {
+				spinner.Info(fmt.Sprintf("No new lights found (%d known)", result.Lights))
+				return nil
+			}
+			spinner.Success(fmt.Sprintf("Found %d new light(s) (%d known)", len(result.Found), result.Lights))
+			return printDiscovery(format, result)
+		},
+	}
+}
+
+// printDiscovery prints the lights found by a discovery pass in the given
+// output format
+func printDiscovery(format string, result *client.DiscoveryResult) error {
+	switch format {
+	case OutputJSON:
+		found := make([]LightJSON, 0, len(result.Found))
+		for _, light := range result.Found {
+			found = append(found, LightToJSON(light))
+		}
+		return printJSON(map[string]any{
+			"found":       found,
+			"lights":      result.Lights,
+			"duration_ms": result.DurationMS,
+		})
+	case OutputParseable:
+		for _, light := range result.Found {
+			fmt.Println(LightParseable(light))
+		}
+		return nil
+	}
+
+	for _, light := range result.Found {
+		if err := pterm.DefaultTable.WithData(LightTableData(light)).Render(); err != nil {
+			return fmt.Errorf("failed to render table: %w", err)
+		}
+		pterm.Println()
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{}
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"discover", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	var result struct {
+		Found      []LightJSON `json:"found"`
+		Lights     int         `json:"lights"`
+		DurationMS int64       `json:"duration_ms"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Len(t, result.Found, 1)
+	require.Equal(t, "light-3", result.Found[0].ID)
+	require.Equal(t, 3, result.Lights)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"discover", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, `id="light-3"`)
+}
@@ -3,12 +3,15 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pterm/pterm"
 
+	"github.com/jmylchreest/keylightd/pkg/client"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
@@ -22,139 +25,113 @@ type WaybarOutput struct {
 
 // LightJSON represents a light in JSON format
 type LightJSON struct {
-	ID              string `json:"id"`
-	ProductName     string `json:"product_name"`
-	SerialNumber    string `json:"serial_number"`
-	FirmwareVersion string `json:"firmware_version"`
-	FirmwareBuild   int    `json:"firmware_build"`
-	On              bool   `json:"on"`
-	Brightness      int    `json:"brightness"`
-	Temperature     int    `json:"temperature"`
-	TemperatureK    int    `json:"temperature_kelvin"`
-	IP              string `json:"ip"`
-	Port            int    `json:"port"`
-	LastSeen        int64  `json:"last_seen"`
+	ID              string               `json:"id"`
+	ProductName     string               `json:"product_name"`
+	SerialNumber    string               `json:"serial_number"`
+	FirmwareVersion string               `json:"firmware_version"`
+	FirmwareBuild   int                  `json:"firmware_build"`
+	On              bool                 `json:"on"`
+	Brightness      int                  `json:"brightness"`
+	Temperature     int                  `json:"temperature"`
+	TemperatureK    int                  `json:"temperature_kelvin"`
+	Hue             *float64             `json:"hue,omitempty"`
+	Saturation      *float64             `json:"saturation,omitempty"`
+	ColorMode       string               `json:"color_mode,omitempty"`
+	Capabilities    *client.Capabilities `json:"capabilities,omitempty"`
+	Tags            map[string]string    `json:"tags,omitempty"`
+	IP              string               `json:"ip"`
+	Port            int                  `json:"port"`
+	LastSeen        int64                `json:"last_seen"`
+	Status          string               `json:"status,omitempty"`
 }
 
 // GroupJSON represents a group in JSON format
 type GroupJSON struct {
-	ID     string   `json:"id"`
-	Name   string   `json:"name"`
-	Lights []string `json:"lights"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Lights      []string `json:"lights"`
+	Description string   `json:"description,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Color       string   `json:"color,omitempty"`
+	CreatedAt   int64    `json:"created_at,omitempty"` // Unix time; unset for groups created before it was recorded
+	UpdatedAt   int64    `json:"updated_at,omitempty"` // Unix time
 }
 
 // StatusJSON represents the overall status in JSON format
 type StatusJSON struct {
-	Lights   []LightJSON `json:"lights"`
-	Groups   []GroupJSON `json:"groups"`
-	OnCount  int         `json:"on_count"`
-	OffCount int         `json:"off_count"`
-	Total    int         `json:"total"`
+	Daemon   *client.DaemonInfo `json:"daemon,omitempty"`
+	Lights   []LightJSON        `json:"lights"`
+	Groups   []GroupJSON        `json:"groups"`
+	OnCount  int                `json:"on_count"`
+	OffCount int                `json:"off_count"`
+	Total    int                `json:"total"`
 }
 
-// LightToJSON converts a light map to LightJSON struct
-func LightToJSON(id string, light map[string]any) LightJSON {
-	id = keylight.UnescapeRFC6763Label(id)
-
-	tempDevice := 0
-	if v, ok := light["temperature"].(int); ok {
-		tempDevice = v
-	}
-	tempKelvin := keylight.ConvertDeviceToTemperature(tempDevice)
-
-	firmwareBuild := 0
-	if v, ok := light["firmwarebuild"].(int); ok {
-		firmwareBuild = v
-	}
-
-	port := 0
-	if v, ok := light["port"].(int); ok {
-		port = v
-	}
-
-	brightness := 0
-	if v, ok := light["brightness"].(int); ok {
-		brightness = v
-	}
-
-	on := false
-	if v, ok := light["on"].(bool); ok {
-		on = v
-	}
-
+// LightToJSON converts a light to LightJSON struct
+func LightToJSON(light *client.Light) LightJSON {
 	lastSeen := int64(0)
-	if t, ok := light["lastseen"].(time.Time); ok && !t.IsZero() {
-		lastSeen = t.Unix()
+	if !light.LastSeen.IsZero() {
+		lastSeen = light.LastSeen.Unix()
 	}
 
 	return LightJSON{
-		ID:              id,
-		ProductName:     fmt.Sprintf("%v", light["productname"]),
-		SerialNumber:    fmt.Sprintf("%v", light["serialnumber"]),
-		FirmwareVersion: fmt.Sprintf("%v", light["firmwareversion"]),
-		FirmwareBuild:   firmwareBuild,
-		On:              on,
-		Brightness:      brightness,
-		Temperature:     tempDevice,
-		TemperatureK:    tempKelvin,
-		IP:              fmt.Sprintf("%v", light["ip"]),
-		Port:            port,
+		ID:              keylight.UnescapeRFC6763Label(light.ID),
+		ProductName:     light.ProductName,
+		SerialNumber:    light.SerialNumber,
+		FirmwareVersion: light.FirmwareVersion,
+		FirmwareBuild:   light.FirmwareBuild,
+		On:              light.On,
+		Brightness:      light.Brightness,
+		Temperature:     light.Temperature,
+		TemperatureK:    keylight.ConvertDeviceToTemperature(light.Temperature),
+		Hue:             light.Hue,
+		Saturation:      light.Saturation,
+		ColorMode:       light.ColorMode,
+		Capabilities:    light.Capabilities,
+		Tags:            light.Tags,
+		IP:              light.IP.String(),
+		Port:            light.Port,
 		LastSeen:        lastSeen,
+		Status:          string(light.Status),
 	}
 }
 
-// GroupToJSON converts a group map to GroupJSON struct
-func GroupToJSON(group map[string]any) GroupJSON {
-	id, _ := group["id"].(string)
-	name, _ := group["name"].(string)
-
-	var lightIDs []string
-	if lights, ok := group["lights"].([]any); ok {
-		lightIDs = make([]string, len(lights))
-		for i, light := range lights {
-			lightIDs[i], _ = light.(string)
-		}
+// GroupToJSON converts a group to GroupJSON struct
+func GroupToJSON(group *client.Group) GroupJSON {
+	g := GroupJSON{
+		ID:          group.ID,
+		Name:        group.Name,
+		Lights:      group.Lights,
+		Description: group.Description,
+		Icon:        group.Icon,
+		Color:       group.Color,
 	}
-
-	return GroupJSON{
-		ID:     id,
-		Name:   name,
-		Lights: lightIDs,
+	if !group.CreatedAt.IsZero() {
+		g.CreatedAt = group.CreatedAt.Unix()
+	}
+	if !group.UpdatedAt.IsZero() {
+		g.UpdatedAt = group.UpdatedAt.Unix()
 	}
+	return g
 }
 
 // FormatWaybarOutput creates waybar-compatible JSON output
-func FormatWaybarOutput(lights map[string]any) string {
+func FormatWaybarOutput(lights map[string]*client.Light) string {
 	onCount := 0
 	offCount := 0
 	totalBrightness := 0
 
 	var tooltipLines []string
 
-	for id, light := range lights {
-		lightMap, _ := light.(map[string]any)
+	for _, id := range slices.Sorted(maps.Keys(lights)) {
+		light := lights[id]
 		name := keylight.UnescapeRFC6763Label(id)
+		tempKelvin := keylight.ConvertDeviceToTemperature(light.Temperature)
 
-		on := false
-		if v, ok := lightMap["on"].(bool); ok {
-			on = v
-		}
-
-		brightness := 0
-		if v, ok := lightMap["brightness"].(int); ok {
-			brightness = v
-		}
-
-		tempDevice := 0
-		if v, ok := lightMap["temperature"].(int); ok {
-			tempDevice = v
-		}
-		tempKelvin := keylight.ConvertDeviceToTemperature(tempDevice)
-
-		if on {
+		if light.On {
 			onCount++
-			totalBrightness += brightness
-			tooltipLines = append(tooltipLines, fmt.Sprintf("%s: %d%% @ %dK", name, brightness, tempKelvin))
+			totalBrightness += light.Brightness
+			tooltipLines = append(tooltipLines, fmt.Sprintf("%s: %d%% @ %dK", name, light.Brightness, tempKelvin))
 		} else {
 			offCount++
 			tooltipLines = append(tooltipLines, name+": off")
@@ -193,75 +170,141 @@ func FormatWaybarOutput(lights map[string]any) string {
 }
 
 // LightTableData returns the table data for a light, with bold ID and value
-func LightTableData(id string, light map[string]any) pterm.TableData {
-	id = keylight.UnescapeRFC6763Label(id)
-	tempDevice := 0
-	if v, ok := light["temperature"].(int); ok {
-		tempDevice = v
-	}
-	tempKelvin := keylight.ConvertDeviceToTemperature(tempDevice)
-	return pterm.TableData{
+func LightTableData(light *client.Light) pterm.TableData {
+	id := keylight.UnescapeRFC6763Label(light.ID)
+	tempKelvin := keylight.ConvertDeviceToTemperature(light.Temperature)
+	data := pterm.TableData{
 		[]string{pterm.Bold.Sprint("ID"), pterm.Bold.Sprint(id)},
-		[]string{"Product", fmt.Sprintf("%v", light["productname"])},
-		[]string{"Serial", fmt.Sprintf("%v", light["serialnumber"])},
-		[]string{"Firmware", fmt.Sprintf("%v (build %v)", light["firmwareversion"], light["firmwarebuild"])},
-		[]string{"On", fmt.Sprintf("%v", light["on"])},
-		[]string{"Temperature", fmt.Sprintf("%v (%dK)", tempDevice, tempKelvin)},
-		[]string{"Brightness", fmt.Sprintf("%v", light["brightness"])},
-		[]string{"IP", fmt.Sprintf("%v", light["ip"])},
-		[]string{"Port", fmt.Sprintf("%v", light["port"])},
-		[]string{"Last Seen", formatLastSeen(light["lastseen"])},
+		[]string{"Product", light.ProductName},
+		[]string{"Serial", light.SerialNumber},
+		[]string{"Firmware", fmt.Sprintf("%s (build %d)", light.FirmwareVersion, light.FirmwareBuild)},
+		[]string{"On", strconv.FormatBool(light.On)},
+		[]string{"Temperature", fmt.Sprintf("%d (%dK)", light.Temperature, tempKelvin)},
+	}
+	if light.Hue != nil && light.Saturation != nil {
+		data = append(data, []string{"Color", fmt.Sprintf("hue %g, saturation %g%%", *light.Hue, *light.Saturation)})
+	}
+	data = append(data,
+		[]string{"Brightness", strconv.Itoa(light.Brightness)},
+		[]string{"IP", light.IP.String()},
+		[]string{"Port", strconv.Itoa(light.Port)},
+		[]string{"Last Seen", formatLastSeen(light.LastSeen)},
+		[]string{"Status", lightStatusOrUnknown(light)},
+	)
+	if light.Capabilities != nil {
+		data = append(data, []string{"Capabilities", formatCapabilities(*light.Capabilities)})
+		r := light.Range()
+		data = append(data, []string{"Range", fmt.Sprintf("brightness %d-%d, temperature %dK-%dK",
+			r.MinBrightness, r.MaxBrightness, r.MinTemperature, r.MaxTemperature)})
 	}
+	if len(light.Tags) > 0 {
+		data = append(data, []string{"Tags", formatTags(light.Tags)})
+	}
+	return data
+}
+
+// formatTags lists a light's tags as key=value pairs sorted by key
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// formatCapabilities lists the optional properties a light supports for display
+func formatCapabilities(caps client.Capabilities) string {
+	var supported []string
+	if caps.Temperature {
+		supported = append(supported, "temperature")
+	}
+	if caps.Color {
+		supported = append(supported, "color")
+	}
+	if caps.Battery {
+		supported = append(supported, "battery")
+	}
+	if len(supported) == 0 {
+		return "none"
+	}
+	return strings.Join(supported, ", ")
+}
+
+// lightStatusOrUnknown returns the reachability of a light for display
+func lightStatusOrUnknown(light *client.Light) string {
+	if light.Status != "" {
+		return string(light.Status)
+	}
+	return "unknown"
 }
 
 // formatLastSeen formats the LastSeen time for display
-func formatLastSeen(lastSeen any) string {
-	if t, ok := lastSeen.(time.Time); ok && !t.IsZero() {
-		// Format the time in a human-readable format, e.g., RFC1123Z
-		return t.Format(time.RFC1123Z)
+func formatLastSeen(t time.Time) string {
+	if t.IsZero() {
+		return "N/A"
 	}
-	return "N/A"
+	// Format the time in a human-readable format, e.g., RFC1123Z
+	return t.Format(time.RFC1123Z)
+}
+
+// formatStatsTime formats an optional statistics time for display
+func formatStatsTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+// unixOrZero returns an optional time as a Unix timestamp, or 0 if unset
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
 }
 
 // LightParseable returns the parseable key=value string for a light
-func LightParseable(id string, light map[string]any) string {
-	id = keylight.UnescapeRFC6763Label(id)
+func LightParseable(light *client.Light) string {
 	lastSeenUnix := "0"
-	if t, ok := light["lastseen"].(time.Time); ok && !t.IsZero() {
-		lastSeenUnix = strconv.FormatInt(t.Unix(), 10)
+	if !light.LastSeen.IsZero() {
+		lastSeenUnix = strconv.FormatInt(light.LastSeen.Unix(), 10)
 	}
-	tempDevice := 0
-	if v, ok := light["temperature"].(int); ok {
-		tempDevice = v
-	}
-	tempKelvin := keylight.ConvertDeviceToTemperature(tempDevice)
 	return fmt.Sprintf(
-		"id=\"%s\" productname=\"%v\" serialnumber=\"%v\" firmwareversion=\"%v\" firmwarebuild=%v on=%v brightness=%v temperature=%v temperature_kelvin=%v ip=\"%v\" port=%v lastseen=%s",
-		id,
-		light["productname"],
-		light["serialnumber"],
-		light["firmwareversion"],
-		light["firmwarebuild"],
-		light["on"],
-		light["brightness"],
-		light["temperature"],
-		tempKelvin,
-		light["ip"],
-		light["port"],
+		"id=\"%s\" productname=\"%s\" serialnumber=\"%s\" firmwareversion=\"%s\" firmwarebuild=%d on=%t brightness=%d temperature=%d temperature_kelvin=%d ip=\"%s\" port=%d lastseen=%s status=%s",
+		keylight.UnescapeRFC6763Label(light.ID),
+		light.ProductName,
+		light.SerialNumber,
+		light.FirmwareVersion,
+		light.FirmwareBuild,
+		light.On,
+		light.Brightness,
+		light.Temperature,
+		keylight.ConvertDeviceToTemperature(light.Temperature),
+		light.IP,
+		light.Port,
 		lastSeenUnix,
+		lightStatusOrUnknown(light),
 	)
 }
 
-// GroupParseable returns the parseable string for a group (id, name, lights as comma-separated)
-func GroupParseable(group map[string]any) string {
-	id, _ := group["id"].(string)
-	name, _ := group["name"].(string)
-	lights, _ := group["lights"].([]any)
-	lightIDs := make([]string, len(lights))
-	for i, light := range lights {
-		lightIDs[i], _ = light.(string)
+// lightProperty returns a single property of a light by its JSON name, as
+// used by "light get [id] [property]".
+func lightProperty(light *client.Light, property string) (any, bool) {
+	b, err := json.Marshal(light)
+	if err != nil {
+		return nil, false
+	}
+	var properties map[string]any
+	if err := json.Unmarshal(b, &properties); err != nil {
+		return nil, false
 	}
-	return fmt.Sprintf("id=\"%s\" name=\"%s\" lights=\"%s\"", id, name, strings.Join(lightIDs, ","))
+	value, ok := properties[property]
+	return value, ok
+}
+
+// GroupParseable returns the parseable string for a group (id, name, lights as comma-separated)
+func GroupParseable(group *client.Group) string {
+	return fmt.Sprintf("id=\"%s\" name=\"%s\" lights=\"%s\"", group.ID, group.Name, strings.Join(group.Lights, ","))
 }
 
 // PrintPromptResult prints a colored, bold header, an optional warning banner (bold, red, no prefix), and a space-aligned key/value list.
@@ -1,12 +1,14 @@
 package commands
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
@@ -28,7 +30,11 @@ func NewGroupCommand(logger *slog.Logger) *cobra.Command {
 		newGroupDeleteCommand(logger),
 		newGroupGetCommand(logger),
 		newGroupSetCommand(logger),
+		newGroupToggleCommand(logger),
 		newGroupEditCommand(logger),
+		newGroupRenameCommand(logger),
+		newGroupDefaultsCommand(logger),
+		newGroupStatsCommand(logger),
 	)
 
 	return cmd
@@ -36,9 +42,6 @@ func NewGroupCommand(logger *slog.Logger) *cobra.Command {
 
 // newGroupListCommand creates the group list command
 func newGroupListCommand(_ *slog.Logger) *cobra.Command {
-	var parseable bool
-	var jsonOutput bool
-
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all light groups",
@@ -53,32 +56,16 @@ func newGroupListCommand(_ *slog.Logger) *cobra.Command {
 				return fmt.Errorf("failed to get groups: %w", err)
 			}
 
-			if jsonOutput {
-				var groupsList []GroupJSON
+			switch outputFormat(cmd) {
+			case OutputJSON:
+				groupsList := make([]GroupJSON, 0, len(groups))
 				for _, group := range groups {
 					groupsList = append(groupsList, GroupToJSON(group))
 				}
-				jsonBytes, err := json.MarshalIndent(groupsList, "", "  ")
-				if err != nil {
-					return fmt.Errorf("failed to marshal JSON: %w", err)
-				}
-				fmt.Println(string(jsonBytes))
-				return nil
-			}
-
-			if parseable {
+				return printJSON(groupsList)
+			case OutputParseable:
 				for _, group := range groups {
-					id, _ := group["id"].(string)
-					groupName, _ := group["name"].(string)
-					var lights []any
-					if lightsVal, ok := group["lights"].([]any); ok && lightsVal != nil {
-						lights = lightsVal
-					}
-					lightIDs := make([]string, len(lights))
-					for i, light := range lights {
-						lightIDs[i], _ = light.(string)
-					}
-					fmt.Printf("id=\"%s\" name=\"%s\" lights=\"%s\"\n", id, groupName, strings.Join(lightIDs, ","))
+					fmt.Println(GroupParseable(group))
 				}
 				return nil
 			}
@@ -88,21 +75,10 @@ func newGroupListCommand(_ *slog.Logger) *cobra.Command {
 			}
 
 			for _, group := range groups {
-				var lights []any
-				if lightsVal, ok := group["lights"].([]any); ok && lightsVal != nil {
-					lights = lightsVal
-				}
-				lightIDs := make([]string, len(lights))
-				for i, light := range lights {
-					lightIDs[i], _ = light.(string)
-				}
-
-				id, _ := group["id"].(string)
-				groupName, _ := group["name"].(string)
 				table = append(table, []string{
-					id,
-					groupName,
-					strings.Join(lightIDs, ", "),
+					group.ID,
+					group.Name,
+					strings.Join(group.Lights, ", "),
 				})
 			}
 
@@ -113,8 +89,8 @@ func newGroupListCommand(_ *slog.Logger) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().BoolVarP(&parseable, "parseable", "p", false, "Output in parseable format")
-	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format, same as --output parseable")
+	cmd.Flags().BoolP("json", "j", false, "Output in JSON format, same as --output json")
 	return cmd
 }
 
@@ -149,11 +125,19 @@ func newGroupAddCommand(_ *slog.Logger) *cobra.Command {
 				}
 			}
 
+			format := outputFormat(cmd)
 			if err := client.CreateGroup(name); err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to create group: %w", err)
+				}
 				PrintPromptResult("error", "Failed to Create Group", "", [][2]string{{"Name", name}, {"Error", err.Error()}})
 				return nil
 			}
 
+			if format != OutputTable {
+				return printResult(format, resultField{"name", name})
+			}
+
 			fields := [][2]string{
 				{"Name", name},
 			}
@@ -172,8 +156,9 @@ func newGroupDeleteCommand(_ *slog.Logger) *cobra.Command {
 	var yes bool
 
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Delete a light group",
+		Use:               "delete",
+		Short:             "Delete a light group",
+		ValidArgsFunction: completeGroup,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
 			if !ok {
@@ -199,9 +184,7 @@ func newGroupDeleteCommand(_ *slog.Logger) *cobra.Command {
 				}
 				options := make([]string, len(groups))
 				for i, group := range groups {
-					id, _ := group["id"].(string)
-					groupName, _ := group["name"].(string)
-					options[i] = fmt.Sprintf("%s (%s)", id, groupName)
+					options[i] = fmt.Sprintf("%s (%s)", group.ID, group.Name)
 				}
 				selected, err := pterm.DefaultInteractiveSelect.WithOptions(options).Show("Select a group to delete")
 				if err != nil {
@@ -217,6 +200,9 @@ func newGroupDeleteCommand(_ *slog.Logger) *cobra.Command {
 				return fmt.Errorf("failed to delete group: %w", err)
 			}
 
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"id", name})
+			}
 			pterm.Success.Printf("Deleted group: %s\n", name)
 			return nil
 		},
@@ -224,17 +210,18 @@ func newGroupDeleteCommand(_ *slog.Logger) *cobra.Command {
 
 	cmd.Flags().StringVar(&name, "name", "", "Name or ID of the group")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	_ = cmd.RegisterFlagCompletionFunc("name", completeGroupFlag)
 	return cmd
 }
 
 // newGroupGetCommand creates the group get command
 func newGroupGetCommand(_ *slog.Logger) *cobra.Command {
 	var name string
-	var parseable bool
 
 	cmd := &cobra.Command{
-		Use:   "get",
-		Short: "Get a light group",
+		Use:               "get",
+		Short:             "Get a light group",
+		ValidArgsFunction: completeGroup,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
 			if !ok {
@@ -265,9 +252,7 @@ func newGroupGetCommand(_ *slog.Logger) *cobra.Command {
 				// Create options for dropdown
 				options := make([]string, len(groups))
 				for i, group := range groups {
-					id, _ := group["id"].(string)
-					groupName, _ := group["name"].(string)
-					options[i] = fmt.Sprintf("%s (%s)", id, groupName)
+					options[i] = fmt.Sprintf("%s (%s)", group.ID, group.Name)
 				}
 
 				selected, err := pterm.DefaultInteractiveSelect.
@@ -289,29 +274,20 @@ func newGroupGetCommand(_ *slog.Logger) *cobra.Command {
 				return fmt.Errorf("failed to get group: %w", err)
 			}
 
-			if parseable {
-				id, _ := group["id"].(string)
-				groupName, _ := group["name"].(string)
-				var lights []any
-				if lightsVal, ok := group["lights"].([]any); ok && lightsVal != nil {
-					lights = lightsVal
-				}
-				lightIDs := make([]string, len(lights))
-				for i, light := range lights {
-					lightIDs[i], _ = light.(string)
-				}
-				fmt.Printf("id=\"%s\" name=\"%s\" lights=\"%s\"\n", id, groupName, strings.Join(lightIDs, ","))
+			switch outputFormat(cmd) {
+			case OutputJSON:
+				return printJSON(GroupToJSON(group))
+			case OutputParseable:
+				fmt.Println(GroupParseable(group))
 				return nil
 			}
 
-			lights, _ := group["lights"].([]any)
-			if len(lights) == 0 {
+			if len(group.Lights) == 0 {
 				pterm.Info.Println("No lights in group.")
 				return nil
 			}
 
-			for _, lightID := range lights {
-				id, _ := lightID.(string)
+			for _, id := range group.Lights {
 				light, err := client.GetLight(id)
 				if err != nil {
 					pterm.Warning.Printf("Could not fetch light %s: %v\n", id, err)
@@ -319,14 +295,14 @@ func newGroupGetCommand(_ *slog.Logger) *cobra.Command {
 				}
 				table := pterm.TableData{
 					[]string{pterm.Bold.Sprint("ID"), pterm.Bold.Sprint(id)},
-					[]string{"Product", fmt.Sprintf("%v", light["productname"])},
-					[]string{"Serial", fmt.Sprintf("%v", light["serialnumber"])},
-					[]string{"Firmware", fmt.Sprintf("%v (build %v)", light["firmwareversion"], light["firmwarebuild"])},
-					[]string{"On", fmt.Sprintf("%v", light["on"])},
-					[]string{"Temperature", fmt.Sprintf("%v", light["temperature"])},
-					[]string{"Brightness", fmt.Sprintf("%v", light["brightness"])},
-					[]string{"IP", fmt.Sprintf("%v", light["ip"])},
-					[]string{"Port", fmt.Sprintf("%v", light["port"])},
+					[]string{"Product", light.ProductName},
+					[]string{"Serial", light.SerialNumber},
+					[]string{"Firmware", fmt.Sprintf("%s (build %d)", light.FirmwareVersion, light.FirmwareBuild)},
+					[]string{"On", strconv.FormatBool(light.On)},
+					[]string{"Temperature", strconv.Itoa(light.Temperature)},
+					[]string{"Brightness", strconv.Itoa(light.Brightness)},
+					[]string{"IP", light.IP.String()},
+					[]string{"Port", strconv.Itoa(light.Port)},
 				}
 				if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
 					return fmt.Errorf("failed to render table: %w", err)
@@ -338,27 +314,80 @@ func newGroupGetCommand(_ *slog.Logger) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "Name or ID of the group")
-	cmd.Flags().BoolVarP(&parseable, "parseable", "p", false, "Output in parseable format (key=value)")
+	_ = cmd.RegisterFlagCompletionFunc("name", completeGroupFlag)
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format (key=value), same as --output parseable")
 	return cmd
 }
 
 // newGroupSetCommand creates the group set command
+// newGroupToggleCommand creates the group toggle command
+func newGroupToggleCommand(_ *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "toggle <name>",
+		Short:             "Toggle all lights in a group on or off",
+		ValidArgsFunction: completeGroup,
+		Long: `Toggle all lights in a group. If any light in the group is on, every light is
+turned off; otherwise every light is turned on. Comma-separated group IDs or
+names toggle each group independently, as do "all" and glob patterns such as
+'office-*', which select every matching group.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			name := keylight.UnescapeRFC6763Label(args[0])
+			states, err := apiClient.ToggleGroup(name)
+			if err != nil {
+				return fmt.Errorf("failed to toggle group: %w", err)
+			}
+
+			ids := slices.Sorted(maps.Keys(states))
+			if format := outputFormat(cmd); format != OutputTable {
+				results := make([][]resultField, 0, len(ids))
+				for _, id := range ids {
+					results = append(results, []resultField{{"id", id}, {"on", states[id]}})
+				}
+				return printResults(format, results)
+			}
+			for _, id := range ids {
+				pterm.Success.Printf("Turned group %s %s\n", id, onOffString(states[id]))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
 func newGroupSetCommand(_ *slog.Logger) *cobra.Command {
 	var name string
 	var property string
 	var value any
+	var transition time.Duration
 
 	cmd := &cobra.Command{
-		Use:   "set",
-		Short: "Set properties for all lights in a group",
+		Use:               "set",
+		Short:             "Set properties for all lights in a group",
+		ValidArgsFunction: completeGroupProperty,
+		Long: `Set properties for all lights in a group. Brightness and temperature also
+accept values relative to each light's current state, such as +10, -10 or +200K.
+
+Hue (0-360 degrees) and saturation (0-100) are applied to the group's lights
+that support color and leave the others unchanged.
+
+Several groups can be set at once with comma-separated IDs or names, "all" for
+every group, or a glob pattern such as 'office-*' matching group IDs or names:
+
+  keylightctl group set all on false`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
 			if !ok {
 				return errors.New("client not found in context")
 			}
 
 			// Get groups for selection
-			groups, err := client.GetGroups()
+			groups, err := apiClient.GetGroups()
 			if err != nil {
 				return fmt.Errorf("failed to get groups: %w", err)
 			}
@@ -377,9 +406,7 @@ func newGroupSetCommand(_ *slog.Logger) *cobra.Command {
 				// Create options for dropdown
 				options := make([]string, len(groups))
 				for i, group := range groups {
-					id, _ := group["id"].(string)
-					groupName, _ := group["name"].(string)
-					options[i] = fmt.Sprintf("%s (%s)", id, groupName)
+					options[i] = fmt.Sprintf("%s (%s)", group.ID, group.Name)
 				}
 
 				selected, err := pterm.DefaultInteractiveSelect.
@@ -395,30 +422,42 @@ func newGroupSetCommand(_ *slog.Logger) *cobra.Command {
 
 			// Normalize user-provided group ID if it might be escaped
 			name = keylight.UnescapeRFC6763Label(name)
+			format := outputFormat(cmd)
 
 			// Use property from args if provided
 			if len(args) > 1 {
 				property = args[1]
 				// Validate property
 				switch strings.ToLower(property) {
-				case "on", "brightness", "temperature":
+				case "on", "brightness", "temperature", "hue", "saturation":
 					// Valid property
 				default:
-					return fmt.Errorf("invalid property: %s. Must be one of: on, brightness, temperature", property)
+					return fmt.Errorf("invalid property: %s. Must be one of: on, brightness, temperature, hue, saturation", property)
 				}
 			}
 
 			// Prompt for property if not provided
 			if property == "" {
 				var err error
-				property, err = pterm.DefaultInteractiveSelect.WithOptions([]string{"on", "brightness", "temperature"}).Show("Select property to set")
+				property, err = pterm.DefaultInteractiveSelect.WithOptions([]string{"on", "brightness", "temperature", "hue", "saturation"}).Show("Select property to set")
 				if err != nil {
 					return fmt.Errorf("failed to select property: %w", err)
 				}
 			}
 
-			// Use value from args if provided
+			// Relative brightness and temperature values are sent unchanged
 			if len(args) > 2 {
+				relative, err := relativeValue(property, args[2])
+				if err != nil {
+					return err
+				}
+				if relative {
+					value = args[2]
+				}
+			}
+
+			// Use value from args if provided
+			if len(args) > 2 && value == nil {
 				switch property {
 				case "on":
 					value = args[2] == "true" || args[2] == "on"
@@ -427,34 +466,30 @@ func newGroupSetCommand(_ *slog.Logger) *cobra.Command {
 					if err != nil {
 						return fmt.Errorf("invalid brightness value: %w", err)
 					}
-					// Clamp brightness to valid range (0-100)
-					if brightness < 0 {
-						brightness = 0
-					} else if brightness > 100 {
-						brightness = 100
-					}
 					value = brightness
 				case "temperature":
 					temp, err := strconv.Atoi(args[2])
 					if err != nil {
 						return fmt.Errorf("invalid temperature value: %w", err)
 					}
-					// Clamp temperature to valid range
-					if temp < 2900 {
-						temp = 2900
-					} else if temp > 7000 {
-						temp = 7000
-					}
-					// Convert to mireds for display
-					mireds := 1000000 / temp
-					if mireds > 344 {
-						mireds = 344
-					} else if mireds < 143 {
-						mireds = 143
+					if format == OutputTable {
+						pterm.Info.Printf("Setting temperature to %dK\n", temp)
 					}
-					pterm.Info.Printf("Setting temperature to %dK (%d mireds)\n", temp, mireds)
 					value = temp
+				case "hue", "saturation":
+					v, err := strconv.ParseFloat(args[2], 64)
+					if err != nil {
+						return fmt.Errorf("invalid %s value: %w", property, err)
+					}
+					value = v
 				}
+			} else if str, ok := value.(string); ok && (property == "hue" || property == "saturation") {
+				// --value keeps fractional numbers as strings
+				v, err := strconv.ParseFloat(str, 64)
+				if err != nil {
+					return fmt.Errorf("invalid %s value: %w", property, err)
+				}
+				value = v
 			}
 
 			// Prompt for value if not provided
@@ -478,12 +513,6 @@ func newGroupSetCommand(_ *slog.Logger) *cobra.Command {
 					if err != nil {
 						return fmt.Errorf("invalid brightness value: %w", err)
 					}
-					// Clamp brightness to valid range (0-100)
-					if brightnessVal < 0 {
-						brightnessVal = 0
-					} else if brightnessVal > 100 {
-						brightnessVal = 100
-					}
 					value = brightnessVal
 
 				case "temperature":
@@ -495,38 +524,51 @@ func newGroupSetCommand(_ *slog.Logger) *cobra.Command {
 					if err != nil {
 						return fmt.Errorf("invalid temperature value: %w", err)
 					}
-					// Clamp temperature to valid range
-					if tempVal < 2900 {
-						tempVal = 2900
-					} else if tempVal > 7000 {
-						tempVal = 7000
+					if format == OutputTable {
+						pterm.Info.Printf("Setting temperature to %dK\n", tempVal)
 					}
-					// Convert to mireds for display
-					mireds := 1000000 / tempVal
-					if mireds > 344 {
-						mireds = 344
-					} else if mireds < 143 {
-						mireds = 143
-					}
-					pterm.Info.Printf("Setting temperature to %dK (%d mireds)\n", tempVal, mireds)
 					value = tempVal
+
+				case "hue", "saturation":
+					prompt := "Enter hue (0-360 degrees)"
+					if property == "saturation" {
+						prompt = "Enter saturation (0-100)"
+					}
+					str, err := pterm.DefaultInteractiveTextInput.WithMultiLine(false).Show(prompt)
+					if err != nil {
+						return fmt.Errorf("failed to get %s value: %w", property, err)
+					}
+					v, err := strconv.ParseFloat(str, 64)
+					if err != nil {
+						return fmt.Errorf("invalid %s value: %w", property, err)
+					}
+					value = v
 				}
 			}
 
-			if err := client.SetGroupState(name, property, value); err != nil {
+			if err := apiClient.SetGroupState(name, property, value, client.WithTransition(transition)); err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to set group state: %w", err)
+				}
 				// Print all backend errors for multi-group operations
 				fmt.Printf("Failed to set group state: %v\n", err)
 				return nil
 			}
 
+			if format != OutputTable {
+				return printResult(format, resultField{"group", name}, resultField{"property", property}, resultField{"value", value})
+			}
 			pterm.Success.Printf("Updated group(s) %s: %s = %v\n", name, property, value)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "Name or ID of the group")
-	cmd.Flags().StringVar(&property, "property", "", "Property to set (on, brightness, temperature)")
+	cmd.Flags().StringVar(&property, "property", "", "Property to set (on, brightness, temperature, hue, saturation)")
 	cmd.Flags().Var(newValueFlag(&value), "value", "Value to set")
+	cmd.Flags().DurationVar(&transition, "transition", 0, "Ramp to the new value over this duration (e.g. 2s, 500ms)")
+	_ = cmd.RegisterFlagCompletionFunc("name", completeGroupFlag)
+	_ = cmd.RegisterFlagCompletionFunc("property", cobra.FixedCompletions(lightProperties, cobra.ShellCompDirectiveNoFileComp))
 	return cmd
 }
 
@@ -571,8 +613,9 @@ func (f *valueFlag) Type() string {
 // newGroupEditCommand creates the group edit command
 func newGroupEditCommand(_ *slog.Logger) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "edit [groupid] [lightid...]",
-		Short: "Edit the lights in a group",
+		Use:               "edit [groupid] [lightid...]",
+		Short:             "Edit the lights in a group",
+		ValidArgsFunction: completeGroupLights,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
 			if !ok {
@@ -603,9 +646,7 @@ func newGroupEditCommand(_ *slog.Logger) *cobra.Command {
 				// Create options for group selection
 				options := make([]string, len(groups))
 				for i, group := range groups {
-					id, _ := group["id"].(string)
-					groupName, _ := group["name"].(string)
-					options[i] = fmt.Sprintf("%s (%s)", id, groupName)
+					options[i] = fmt.Sprintf("%s (%s)", group.ID, group.Name)
 				}
 
 				selected, err := pterm.DefaultInteractiveSelect.
@@ -630,11 +671,8 @@ func newGroupEditCommand(_ *slog.Logger) *cobra.Command {
 
 			// Get current lights in group
 			currentLights := make(map[string]bool)
-			if lights, ok := group["lights"].([]any); ok {
-				for _, light := range lights {
-					lightStr, _ := light.(string)
-					currentLights[lightStr] = true
-				}
+			for _, id := range group.Lights {
+				currentLights[id] = true
 			}
 
 			// If light IDs are provided as arguments, use those
@@ -643,6 +681,9 @@ func newGroupEditCommand(_ *slog.Logger) *cobra.Command {
 				if err := client.SetGroupLights(groupID, newLightIDs); err != nil {
 					return fmt.Errorf("failed to set group lights: %w", err)
 				}
+				if format := outputFormat(cmd); format != OutputTable {
+					return printResult(format, resultField{"id", groupID}, resultField{"lights", newLightIDs})
+				}
 				pterm.Success.Printf("Updated group %s with lights: %s\n", groupID, strings.Join(newLightIDs, ", "))
 				return nil
 			}
@@ -650,12 +691,11 @@ func newGroupEditCommand(_ *slog.Logger) *cobra.Command {
 			// Create options for light selection
 			options := make([]string, 0, len(lights))
 			for id, light := range lights {
-				lightMap, _ := light.(map[string]any)
 				selected := ""
 				if currentLights[id] {
 					selected = " ✓"
 				}
-				options = append(options, fmt.Sprintf("%s (%v)%s", id, lightMap["productname"], selected))
+				options = append(options, fmt.Sprintf("%s (%s)%s", id, light.ProductName, selected))
 			}
 
 			// Show multi-select for lights
@@ -677,6 +717,9 @@ func newGroupEditCommand(_ *slog.Logger) *cobra.Command {
 				return fmt.Errorf("failed to set group lights: %w", err)
 			}
 
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"id", groupID}, resultField{"lights", newLightIDs})
+			}
 			pterm.Success.Printf("Updated group %s with lights: %s\n", groupID, strings.Join(newLightIDs, ", "))
 			return nil
 		},
@@ -685,6 +728,225 @@ func newGroupEditCommand(_ *slog.Logger) *cobra.Command {
 	return cmd
 }
 
+// newGroupRenameCommand creates the group rename command
+func newGroupRenameCommand(_ *slog.Logger) *cobra.Command {
+	var description, icon, color string
+
+	cmd := &cobra.Command{
+		Use:   "rename <group> [name]",
+		Short: "Rename a group or change its description, icon or color",
+		Long: `Rename a group, or change the description, icon and color that UIs show
+for it. Only the name and flags given are changed; an empty --description,
+--icon or --color clears it.`,
+		Example: `  keylightctl group rename office "Office Lights"
+  keylightctl group rename office --icon video-display-symbolic --color "#ff8800"
+  keylightctl group rename office --description ""`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeGroup,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			var update client.GroupUpdate
+			if len(args) == 2 {
+				if strings.TrimSpace(args[1]) == "" {
+					return errors.New("name cannot be empty")
+				}
+				update.Name = &args[1]
+			}
+			if cmd.Flags().Changed("description") {
+				update.Description = &description
+			}
+			if cmd.Flags().Changed("icon") {
+				update.Icon = &icon
+			}
+			if cmd.Flags().Changed("color") {
+				update.Color = &color
+			}
+			if update.IsEmpty() {
+				return errors.New("specify a new name, --description, --icon or --color")
+			}
+
+			groupID, err := resolveGroupIdentifier(apiClient, args[0])
+			if err != nil {
+				return err
+			}
+			group, err := apiClient.UpdateGroup(groupID, update)
+			if err != nil {
+				return fmt.Errorf("failed to update group: %w", err)
+			}
+
+			switch format := outputFormat(cmd); format {
+			case OutputJSON:
+				return printJSON(GroupToJSON(group))
+			case OutputParseable:
+				fmt.Println(GroupParseable(group))
+				return nil
+			}
+			if update.Name != nil {
+				pterm.Success.Printf("Renamed group %s to %q\n", group.ID, group.Name)
+			} else {
+				pterm.Success.Printf("Updated group %s\n", group.ID)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&description, "description", "", "Group description")
+	cmd.Flags().StringVar(&icon, "icon", "", "Icon name shown for the group in UIs")
+	cmd.Flags().StringVar(&color, "color", "", "Color shown for the group in UIs, as #rrggbb")
+	return cmd
+}
+
+// newGroupDefaultsCommand creates the group defaults command
+func newGroupDefaultsCommand(_ *slog.Logger) *cobra.Command {
+	var brightness, temperature int
+	var applyOnJoin, clearDefaults bool
+
+	cmd := &cobra.Command{
+		Use:   "defaults <group>",
+		Short: "Set the default brightness and temperature of a group",
+		Long: `Set the default brightness and temperature of a group.
+
+With --apply-on-join, the defaults are applied to member lights as soon as
+they are discovered, e.g. after being power cycled. Use --clear to remove
+the defaults.`,
+		Example: `  keylightctl group defaults office --brightness 40 --temperature 4500 --apply-on-join
+  keylightctl group defaults office --clear`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeGroup,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			groupID, err := resolveGroupIdentifier(apiClient, args[0])
+			if err != nil {
+				return err
+			}
+
+			var brightnessPtr, temperaturePtr *int
+			if cmd.Flags().Changed("brightness") {
+				brightnessPtr = &brightness
+			}
+			if cmd.Flags().Changed("temperature") {
+				temperaturePtr = &temperature
+			}
+			if clearDefaults {
+				if brightnessPtr != nil || temperaturePtr != nil || applyOnJoin {
+					return errors.New("--clear cannot be combined with other flags")
+				}
+			} else if brightnessPtr == nil && temperaturePtr == nil {
+				return errors.New("specify --brightness and/or --temperature, or --clear")
+			}
+
+			if err := apiClient.SetGroupDefaults(groupID, brightnessPtr, temperaturePtr, applyOnJoin); err != nil {
+				return fmt.Errorf("failed to set group defaults: %w", err)
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				fields := []resultField{{"id", groupID}, {"cleared", clearDefaults}}
+				if brightnessPtr != nil {
+					fields = append(fields, resultField{"brightness", brightness})
+				}
+				if temperaturePtr != nil {
+					fields = append(fields, resultField{"temperature", temperature})
+				}
+				fields = append(fields, resultField{"apply_on_join", applyOnJoin})
+				return printResult(format, fields...)
+			}
+
+			if clearDefaults {
+				pterm.Success.Printf("Cleared defaults for group %s\n", groupID)
+			} else {
+				pterm.Success.Printf("Updated defaults for group %s\n", groupID)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&brightness, "brightness", 0, "Default brightness (3-100)")
+	cmd.Flags().IntVar(&temperature, "temperature", 0, "Default color temperature in Kelvin (2900-7000)")
+	cmd.Flags().BoolVar(&applyOnJoin, "apply-on-join", false, "Apply the defaults to member lights when they are discovered")
+	cmd.Flags().BoolVar(&clearDefaults, "clear", false, "Remove the group's defaults")
+	return cmd
+}
+
+// newGroupStatsCommand creates the group stats command
+func newGroupStatsCommand(_ *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats <group>",
+		Short: "Show the usage statistics of a group's lights",
+		Long: `Show the combined usage statistics of a group's lights: their total on-time
+and number of toggles, and when any of them last changed, followed by each
+light's own statistics.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeGroup,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			groupID, err := resolveGroupIdentifier(apiClient, args[0])
+			if err != nil {
+				return err
+			}
+			stats, err := apiClient.GetGroupStats(groupID)
+			if err != nil {
+				return fmt.Errorf("failed to get group stats: %w", err)
+			}
+
+			switch format := outputFormat(cmd); format {
+			case OutputJSON:
+				return printJSON(stats)
+			case OutputParseable:
+				results := [][]resultField{{
+					{"id", stats.ID},
+					{"on_seconds", stats.OnSeconds},
+					{"toggles", stats.Toggles},
+					{"last_change", unixOrZero(stats.LastChange)},
+					{"last_toggle", unixOrZero(stats.LastToggle)},
+				}}
+				for i := range stats.Lights {
+					results = append(results, lightStatsFields(&stats.Lights[i]))
+				}
+				return printResults(format, results)
+			}
+
+			summary := pterm.TableData{
+				[]string{pterm.Bold.Sprint("ID"), pterm.Bold.Sprint(stats.ID)},
+				[]string{"On time", stats.OnTime().String()},
+				[]string{"Toggles", strconv.Itoa(stats.Toggles)},
+				[]string{"Last change", formatStatsTime(stats.LastChange)},
+				[]string{"Last toggle", formatStatsTime(stats.LastToggle)},
+			}
+			if err := pterm.DefaultTable.WithData(summary).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			if len(stats.Lights) == 0 {
+				return nil
+			}
+			fmt.Println()
+			lights := pterm.TableData{{"Light", "On", "On time", "Toggles", "Last change"}}
+			for _, l := range stats.Lights {
+				lights = append(lights, []string{
+					keylight.UnescapeRFC6763Label(l.ID), onOffString(l.On), l.OnTime().String(),
+					strconv.Itoa(l.Toggles), formatStatsTime(l.LastChange),
+				})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(lights).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
 // resolveGroupIdentifier takes either a group name or ID and returns the group ID
 func resolveGroupIdentifier(client client.ClientInterface, identifier string) (string, error) {
 	groups, err := client.GetGroups()
@@ -694,19 +956,19 @@ func resolveGroupIdentifier(client client.ClientInterface, identifier string) (s
 
 	// First try to find by name
 	for _, group := range groups {
-		name, _ := group["name"].(string)
-		if name == identifier {
-			id, _ := group["id"].(string)
-			return id, nil
+		if group.Name == identifier {
+			return group.ID, nil
 		}
 	}
 
 	// If not found by name, check if it's a valid ID
 	for _, group := range groups {
-		id, _ := group["id"].(string)
-		if id == identifier {
+		if group.ID == identifier {
 			return identifier, nil
 		}
+		if group.LegacyID != "" && group.LegacyID == identifier {
+			return group.ID, nil
+		}
 	}
 
 	return "", fmt.Errorf("no group found with name or ID: %s", identifier)
@@ -3,8 +3,10 @@ package commands
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -16,30 +18,75 @@ import (
 // var clientContextKey = &struct{}{} // already defined in light.go
 
 type mockGroupClient struct {
-	groups map[string]map[string]any
-	fail   bool
+	groups   map[string]*client.Group
+	fail     bool
+	defaults map[string]any // last SetGroupDefaults call
 }
 
 var _ client.ClientInterface = (*mockGroupClient)(nil)
 
-func (m *mockGroupClient) GetVersion() (map[string]any, error)        { return nil, nil }
-func (m *mockGroupClient) GetLights() (map[string]any, error)         { return nil, nil }
-func (m *mockGroupClient) GetLight(id string) (map[string]any, error) { return nil, nil }
-func (m *mockGroupClient) SetLightState(id string, property string, value any) error {
+func (m *mockGroupClient) GetVersion() (map[string]any, error) { return nil, nil }
+func (m *mockGroupClient) GetDaemonInfo() (*client.DaemonInfo, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetLights() (map[string]*client.Light, error) { return nil, nil }
+func (m *mockGroupClient) GetLight(id string) (*client.Light, error)    { return nil, nil }
+func (m *mockGroupClient) SetLightState(id string, property string, value any, _ ...client.StateOption) error {
 	return nil
 }
-func (m *mockGroupClient) SetGroupState(name string, property string, value any) error {
+func (m *mockGroupClient) SetLightsState(updates []client.LightStateUpdate) error { return nil }
+func (m *mockGroupClient) ToggleLight(id string) (bool, error)                    { return false, nil }
+func (m *mockGroupClient) SetLightName(id, name string) (string, error)           { return name, nil }
+func (m *mockGroupClient) SetLightTags(id string, tags map[string]string) (map[string]string, error) {
+	return tags, nil
+}
+func (m *mockGroupClient) SetDeviceName(id, name string) (string, error) { return name, nil }
+func (m *mockGroupClient) RawRequest(id, method, path string, body json.RawMessage) (*client.RawResponse, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetLightSettings(id string) (*client.LightSettings, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) SetLightSettings(id string, update client.LightSettingsUpdate) (*client.LightSettings, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetLightStats(id string) (*client.LightStats, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetGroupState(groupID string) (*client.GroupState, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetGroupStats(groupID string) (*client.GroupStats, error) {
+	if _, ok := m.groups[groupID]; !ok {
+		return nil, errors.New("group not found")
+	}
+	return &client.GroupStats{ID: groupID, OnSeconds: 90, Toggles: 3, Lights: []client.LightStats{
+		{ID: "light1", On: true, OnSeconds: 60, Toggles: 2},
+		{ID: "light2", OnSeconds: 30, Toggles: 1},
+	}}, nil
+}
+func (m *mockGroupClient) ToggleGroup(name string) (map[string]bool, error) {
+	if m.fail {
+		return nil, errors.New("toggle group failed")
+	}
+	return map[string]bool{name: true}, nil
+}
+func (m *mockGroupClient) SetGroupState(name string, property string, value any, _ ...client.StateOption) error {
 	return nil
 }
 func (m *mockGroupClient) SetGroupLights(groupID string, lightIDs []string) error { return nil }
+func (m *mockGroupClient) SetGroupDefaults(groupID string, brightness, temperature *int, applyOnJoin bool) error {
+	m.defaults = map[string]any{"id": groupID, "brightness": brightness, "temperature": temperature, "apply_on_join": applyOnJoin}
+	return nil
+}
 func (m *mockGroupClient) CreateGroup(name string) error {
 	if m.fail {
 		return errors.New("create group failed")
 	}
-	m.groups[name] = map[string]any{"name": name, "id": name, "lights": []string{"light1"}}
+	m.groups[name] = &client.Group{ID: name, Name: name, Lights: []string{"light1"}}
 	return nil
 }
-func (m *mockGroupClient) GetGroup(name string) (map[string]any, error) {
+func (m *mockGroupClient) GetGroup(name string) (*client.Group, error) {
 	if m.fail {
 		return nil, errors.New("get group failed")
 	}
@@ -49,16 +96,35 @@ func (m *mockGroupClient) GetGroup(name string) (map[string]any, error) {
 	}
 	return g, nil
 }
-func (m *mockGroupClient) GetGroups() ([]map[string]any, error) {
+func (m *mockGroupClient) GetGroups() ([]*client.Group, error) {
 	if m.fail {
 		return nil, errors.New("get groups failed")
 	}
-	var out []map[string]any
+	var out []*client.Group
 	for _, g := range m.groups {
 		out = append(out, g)
 	}
 	return out, nil
 }
+func (m *mockGroupClient) UpdateGroup(groupID string, update client.GroupUpdate) (*client.Group, error) {
+	g, ok := m.groups[groupID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	if update.Name != nil {
+		g.Name = *update.Name
+	}
+	if update.Description != nil {
+		g.Description = *update.Description
+	}
+	if update.Icon != nil {
+		g.Icon = *update.Icon
+	}
+	if update.Color != nil {
+		g.Color = *update.Color
+	}
+	return g, nil
+}
 func (m *mockGroupClient) DeleteGroup(name string) error {
 	if m.fail {
 		return errors.New("delete group failed")
@@ -68,19 +134,19 @@ func (m *mockGroupClient) DeleteGroup(name string) error {
 }
 
 // API Key Management Mocks (satisfy client.ClientInterface)
-func (m *mockGroupClient) AddAPIKey(name string, expiresInSeconds float64) (map[string]any, error) {
+func (m *mockGroupClient) AddAPIKey(name string, expiresInSeconds float64) (*client.APIKey, error) {
 	if m.fail {
 		return nil, errors.New("add api key failed")
 	}
 	// Simple mock: doesn't actually store/return a real key structure for group tests
-	return map[string]any{"key": "mockapikey", "name": name}, nil
+	return &client.APIKey{Key: "mockapikey", Name: name}, nil
 }
 
-func (m *mockGroupClient) ListAPIKeys() ([]map[string]any, error) {
+func (m *mockGroupClient) ListAPIKeys() ([]client.APIKey, error) {
 	if m.fail {
 		return nil, errors.New("list api keys failed")
 	}
-	return []map[string]any{}, nil // Return empty list for group tests
+	return []client.APIKey{}, nil // Return empty list for group tests
 }
 
 func (m *mockGroupClient) DeleteAPIKey(key string) error {
@@ -90,16 +156,83 @@ func (m *mockGroupClient) DeleteAPIKey(key string) error {
 	return nil
 }
 
-func (m *mockGroupClient) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (map[string]any, error) {
+func (m *mockGroupClient) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (*client.APIKey, error) {
 	if m.fail {
 		return nil, errors.New("set api key disabled status failed")
 	}
-	return map[string]any{"key": keyOrName, "disabled": disabled}, nil
+	return &client.APIKey{Key: keyOrName, Disabled: disabled}, nil
+}
+
+func (m *mockGroupClient) ListSchedules() ([]map[string]any, error) { return nil, nil }
+func (m *mockGroupClient) ListUpcomingSchedules(limit, days int) ([]client.ScheduleExecution, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) CreateSchedule(schedule map[string]any) (map[string]any, error) {
+	return schedule, nil
+}
+func (m *mockGroupClient) DeleteSchedule(id string) error                    { return nil }
+func (m *mockGroupClient) ListScenes() ([]client.Scene, error)               { return nil, nil }
+func (m *mockGroupClient) CreateScene(s client.Scene) (*client.Scene, error) { return &s, nil }
+func (m *mockGroupClient) DeleteScene(id string) error                       { return nil }
+func (m *mockGroupClient) ApplyScene(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetSceneRun(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) CancelSceneRun(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) ListJobs() ([]client.Job, error) { return nil, nil }
+func (m *mockGroupClient) GetJob(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) CancelJob(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) DiscoverNow() (*client.DiscoveryResult, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) ListPendingDevices() ([]client.PendingDevice, error) {
+	return nil, client.ErrUnsupported
 }
+func (m *mockGroupClient) AdoptDevice(id string) (*client.Light, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetCircadian() (*client.CircadianStatus, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) SetCircadian(enabled bool) (*client.CircadianStatus, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetDesiredState() (*client.DesiredStateStatus, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) SetDesiredState(enabled bool) (*client.DesiredStateStatus, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) SetDesiredStateTarget(target string, enabled bool) (*client.DesiredStateStatus, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) ExportConfig(includeSecrets bool) (*client.ExportDocument, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) ImportConfig(doc *client.ExportDocument) (*client.ImportResult, error) {
+	return nil, client.ErrUnsupported
+}
+func (m *mockGroupClient) GetLogLevel() (string, error)   { return "info", nil }
+func (m *mockGroupClient) SetLogLevel(level string) error { return nil }
+func (m *mockGroupClient) ListLogFilters() ([]map[string]any, error) {
+	return nil, nil
+}
+func (m *mockGroupClient) AddLogFilter(filter map[string]any) error         { return nil }
+func (m *mockGroupClient) RemoveLogFilter(filterType, pattern string) error { return nil }
+
+func (m *mockGroupClient) GetLogs(q client.LogQuery) ([]client.LogEntry, error) { return nil, nil }
 
 func TestGroupListCommand(t *testing.T) {
-	mock := &mockGroupClient{groups: map[string]map[string]any{
-		"group1": {"id": "group1", "name": "Group 1", "lights": []any{"light1"}},
+	mock := &mockGroupClient{groups: map[string]*client.Group{
+		"group1": {ID: "group1", Name: "Group 1", Lights: []string{"light1"}},
 	}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
@@ -110,7 +243,7 @@ func TestGroupListCommand(t *testing.T) {
 }
 
 func TestGroupAddCommand(t *testing.T) {
-	mock := &mockGroupClient{groups: make(map[string]map[string]any)}
+	mock := &mockGroupClient{groups: make(map[string]*client.Group)}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newGroupAddCommand(logger)
@@ -121,8 +254,8 @@ func TestGroupAddCommand(t *testing.T) {
 }
 
 func TestGroupAddCommand_Duplicate(t *testing.T) {
-	mock := &mockGroupClient{groups: map[string]map[string]any{}}
-	mock.groups["dupe"] = map[string]any{"id": "dupe", "name": "dupe", "lights": []any{}}
+	mock := &mockGroupClient{groups: map[string]*client.Group{}}
+	mock.groups["dupe"] = &client.Group{ID: "dupe", Name: "dupe", Lights: []string{}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newGroupAddCommand(logger)
@@ -137,7 +270,7 @@ func TestGroupAddCommand_Duplicate(t *testing.T) {
 }
 
 func TestGroupDeleteCommand(t *testing.T) {
-	mock := &mockGroupClient{groups: map[string]map[string]any{"group1": {"id": "group1", "name": "Group 1", "lights": []any{}}}}
+	mock := &mockGroupClient{groups: map[string]*client.Group{"group1": {ID: "group1", Name: "Group 1", Lights: []string{}}}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newGroupDeleteCommand(logger)
@@ -148,7 +281,7 @@ func TestGroupDeleteCommand(t *testing.T) {
 }
 
 func TestGroupDeleteCommand_GroupNotFound(t *testing.T) {
-	mock := &mockGroupClient{groups: map[string]map[string]any{}}
+	mock := &mockGroupClient{groups: map[string]*client.Group{}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newGroupDeleteCommand(logger)
@@ -163,7 +296,7 @@ func TestGroupDeleteCommand_GroupNotFound(t *testing.T) {
 }
 
 func TestGroupGetCommand(t *testing.T) {
-	mock := &mockGroupClient{groups: map[string]map[string]any{"group1": {"id": "group1", "name": "Group 1", "lights": []any{}}}}
+	mock := &mockGroupClient{groups: map[string]*client.Group{"group1": {ID: "group1", Name: "Group 1", Lights: []string{}}}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newGroupGetCommand(logger)
@@ -174,7 +307,7 @@ func TestGroupGetCommand(t *testing.T) {
 }
 
 func TestGroupSetCommand(t *testing.T) {
-	mock := &mockGroupClient{groups: map[string]map[string]any{"group1": {"id": "group1", "name": "Group 1", "lights": []any{}}}}
+	mock := &mockGroupClient{groups: map[string]*client.Group{"group1": {ID: "group1", Name: "Group 1", Lights: []string{}}}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newGroupSetCommand(logger)
@@ -185,8 +318,24 @@ func TestGroupSetCommand(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGroupToggleCommand(t *testing.T) {
+	mock := &mockGroupClient{groups: map[string]*client.Group{}}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	cmd := newGroupToggleCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1"})
+	require.NoError(t, cmd.Execute())
+
+	mock.fail = true
+	cmd = newGroupToggleCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1"})
+	require.Error(t, cmd.Execute())
+}
+
 func TestGroupEditCommand(t *testing.T) {
-	mock := &mockGroupClient{groups: map[string]map[string]any{"group1": {"id": "group1", "name": "Group 1", "lights": []any{"light1"}}}}
+	mock := &mockGroupClient{groups: map[string]*client.Group{"group1": {ID: "group1", Name: "Group 1", Lights: []string{"light1"}}}}
 	ctx := context.WithValue(context.Background(), clientContextKey, mock)
 	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	cmd := newGroupEditCommand(logger)
@@ -196,3 +345,84 @@ func TestGroupEditCommand(t *testing.T) {
 	err := cmd.Execute()
 	require.NoError(t, err)
 }
+
+func TestGroupDefaultsCommand(t *testing.T) {
+	mock := &mockGroupClient{groups: map[string]*client.Group{"group1": {ID: "group1", Name: "Group 1", Lights: []string{}}}}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	cmd := newGroupDefaultsCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1", "--brightness", "40", "--apply-on-join"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "group1", mock.defaults["id"])
+	require.Equal(t, 40, *mock.defaults["brightness"].(*int))
+	require.Nil(t, mock.defaults["temperature"])
+	require.Equal(t, true, mock.defaults["apply_on_join"])
+
+	cmd = newGroupDefaultsCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1"})
+	require.Error(t, cmd.Execute())
+
+	cmd = newGroupDefaultsCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1", "--clear", "--brightness", "40"})
+	require.Error(t, cmd.Execute())
+}
+
+func TestGroupRenameCommand(t *testing.T) {
+	mock := &mockGroupClient{groups: map[string]*client.Group{"group1": {ID: "group1", Name: "Group 1", Description: "Desk", Lights: []string{}}}}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	cmd := newGroupRenameCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"Group 1", "Office", "--color", "#ff8800"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "Office", mock.groups["group1"].Name)
+	require.Equal(t, "#ff8800", mock.groups["group1"].Color)
+	require.Equal(t, "Desk", mock.groups["group1"].Description, "flags not given are left unchanged")
+
+	cmd = newGroupRenameCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1", "--description", ""})
+	require.NoError(t, cmd.Execute())
+	require.Empty(t, mock.groups["group1"].Description)
+	require.Equal(t, "Office", mock.groups["group1"].Name)
+
+	cmd = newGroupRenameCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1"})
+	require.Error(t, cmd.Execute())
+
+	cmd = newGroupRenameCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"group1", " "})
+	require.Error(t, cmd.Execute())
+}
+
+func TestGroupStatsCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, OutputParseable)
+	mock := &mockGroupClient{groups: map[string]*client.Group{"group1": {ID: "group1", Name: "Group 1", Lights: []string{"light1", "light2"}}}}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	out := captureStdout(func() {
+		cmd := newGroupStatsCommand(logger)
+		cmd.SetContext(ctx)
+		cmd.SetArgs([]string{"Group 1"})
+		require.NoError(t, cmd.Execute())
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[0], `id="group1"`)
+	require.Contains(t, lines[0], "on_seconds=90")
+	require.Contains(t, lines[1], `id="light1"`)
+	require.Contains(t, lines[2], "last_change=0")
+
+	cmd := newGroupStatsCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"missing"})
+	require.Error(t, cmd.Execute())
+}
@@ -0,0 +1,361 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// initPollInterval is how often the daemon is asked for lights while waiting
+// for discovery.
+var initPollInterval = time.Second
+
+// NewInitCommand creates the init command, a first-run setup wizard.
+func NewInitCommand(_ *slog.Logger) *cobra.Command {
+	var (
+		wait       time.Duration
+		configPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up keylightd for the first time",
+		Long: "Walk through setting up keylightd: connect to the daemon (starting it if it\n" +
+			"isn't running), wait for lights to be discovered, name them, create a first\n" +
+			"group and optionally create an API key for the GNOME extension or tray.\n" +
+			"The client config file is written at the end.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			if configPath == "" {
+				configPath = config.GetClientConfigPath()
+			}
+
+			pterm.DefaultSection.Println("Connecting to keylightd")
+			info, err := connectDaemon(apiClient)
+			if err != nil {
+				return err
+			}
+			pterm.Success.Printf("Connected to keylightd %s\n", info.Version)
+
+			pterm.DefaultSection.Println("Discovering lights")
+			spinner, _ := pterm.DefaultSpinner.Start("Waiting for lights to be discovered...")
+			lights, err := waitForLights(apiClient, wait, initPollInterval)
+			if err != nil {
+				_ = spinner.Stop()
+				return fmt.Errorf("failed to get lights: %w", err)
+			}
+			if len(lights) == 0 {
+				spinner.Warning("No lights found yet")
+				pterm.Info.Println("Check that your lights are on the same network, or add them as static lights in the daemon config.")
+			} else {
+				spinner.Success(fmt.Sprintf("Found %d light(s)", len(lights)))
+				if err := nameLights(apiClient, lights); err != nil {
+					return err
+				}
+				if err := createInitialGroup(apiClient, lights); err != nil {
+					return err
+				}
+			}
+
+			if err := createDesktopAPIKey(apiClient); err != nil {
+				return err
+			}
+
+			socket := info.SocketPath
+			if socket == "" {
+				socket = config.GetRuntimeSocketPath()
+			}
+			if err := writeClientConfig(configPath, socket); err != nil {
+				return err
+			}
+			pterm.Success.Printf("Wrote %s\n", configPath)
+			pterm.Info.Println("Setup complete. Run 'keylightctl status' to see your lights.")
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&wait, "wait", 10*time.Second, "How long to wait for lights to be discovered")
+	cmd.Flags().StringVar(&configPath, "config", "", "Client config file to write (default "+config.GetClientConfigPath()+")")
+	return cmd
+}
+
+// connectDaemon checks that the daemon answers, offering to start its user
+// service with systemctl if it doesn't.
+func connectDaemon(c client.ClientInterface) (*client.DaemonInfo, error) {
+	info, err := daemonInfo(c)
+	if err == nil {
+		return info, nil
+	}
+	pterm.Warning.Printf("keylightd is not reachable: %v\n", err)
+
+	if _, lookErr := exec.LookPath("systemctl"); lookErr != nil {
+		return nil, errors.New("keylightd is not running; start it with 'keylightd' and run 'keylightctl init' again")
+	}
+	start, _ := pterm.DefaultInteractiveConfirm.
+		WithDefaultText("Start the keylightd user service now?").
+		WithDefaultValue(true).
+		Show()
+	if !start {
+		return nil, errors.New("keylightd is not running; start it and run 'keylightctl init' again")
+	}
+	if out, err := exec.Command("systemctl", "--user", "start", "keylightd").CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to start keylightd: %w: %s", err, out)
+	}
+
+	// Give the daemon a moment to open its socket
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := daemonInfo(c)
+		if err == nil {
+			return info, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("keylightd was started but is not reachable: %w", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// waitForLights polls the daemon for lights until none have been added for
+// three polls in a row, or wait has passed.
+func waitForLights(c client.ClientInterface, wait, interval time.Duration) (map[string]*client.Light, error) {
+	deadline := time.Now().Add(wait)
+	var lights map[string]*client.Light
+	stable := 0
+	for {
+		found, err := c.GetLights()
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 && len(found) == len(lights) {
+			stable++
+		} else {
+			stable = 0
+		}
+		lights = found
+		if stable >= 3 || !time.Now().Add(interval).Before(deadline) {
+			return lights, nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+// nameLights offers to rename each light, keeping its name if the input is
+// left unchanged.
+func nameLights(c client.ClientInterface, lights map[string]*client.Light) error {
+	rename, _ := pterm.DefaultInteractiveConfirm.
+		WithDefaultText("Name your lights?").
+		WithDefaultValue(true).
+		Show()
+	if !rename {
+		return nil
+	}
+	for _, id := range slices.Sorted(maps.Keys(lights)) {
+		light := lights[id]
+		current := displayName(light)
+		name, err := pterm.DefaultInteractiveTextInput.
+			WithMultiLine(false).
+			WithDefaultValue(current).
+			Show(fmt.Sprintf("Name for %s (%s)", id, light.ProductName))
+		if err != nil {
+			return fmt.Errorf("failed to get light name: %w", err)
+		}
+		if name == "" || name == current {
+			continue
+		}
+		if _, err := c.SetLightName(id, name); err != nil {
+			return fmt.Errorf("failed to rename %s: %w", id, err)
+		}
+		light.Name = name
+		pterm.Success.Printf("Named %s %q\n", id, name)
+	}
+	return nil
+}
+
+// createInitialGroup offers to create a group of some or all of the lights.
+func createInitialGroup(c client.ClientInterface, lights map[string]*client.Light) error {
+	create, _ := pterm.DefaultInteractiveConfirm.
+		WithDefaultText("Create a group to control lights together?").
+		WithDefaultValue(len(lights) > 1).
+		Show()
+	if !create {
+		return nil
+	}
+	name, err := pterm.DefaultInteractiveTextInput.
+		WithMultiLine(false).
+		WithDefaultValue("All Lights").
+		Show("Group name")
+	if err != nil {
+		return fmt.Errorf("failed to get group name: %w", err)
+	}
+	if name == "" {
+		return errors.New("group name cannot be empty")
+	}
+
+	ids := slices.Sorted(maps.Keys(lights))
+	options := make([]string, len(ids))
+	for i, id := range ids {
+		options[i] = fmt.Sprintf("%s (%s)", displayName(lights[id]), id)
+	}
+	selected, err := pterm.DefaultInteractiveMultiselect.
+		WithOptions(options).
+		WithDefaultOptions(options).
+		Show("Select lights for the group")
+	if err != nil {
+		return fmt.Errorf("failed to select lights: %w", err)
+	}
+	members := make([]string, 0, len(selected))
+	for _, option := range selected {
+		members = append(members, ids[slices.Index(options, option)])
+	}
+
+	groupID, err := createGroup(c, name)
+	if err != nil {
+		return err
+	}
+	if err := c.SetGroupLights(groupID, members); err != nil {
+		return fmt.Errorf("failed to set group lights: %w", err)
+	}
+	pterm.Success.Printf("Created group %q with %d light(s)\n", name, len(members))
+	return nil
+}
+
+// createGroup creates a group and returns its ID, found as the group with the
+// name that didn't exist before.
+func createGroup(c client.ClientInterface, name string) (string, error) {
+	before, err := c.GetGroups()
+	if err != nil {
+		return "", fmt.Errorf("failed to get groups: %w", err)
+	}
+	existing := make(map[string]bool, len(before))
+	for _, g := range before {
+		existing[g.ID] = true
+	}
+	if err := c.CreateGroup(name); err != nil {
+		return "", fmt.Errorf("failed to create group: %w", err)
+	}
+	after, err := c.GetGroups()
+	if err != nil {
+		return "", fmt.Errorf("failed to get groups: %w", err)
+	}
+	for _, g := range after {
+		if g.Name == name && !existing[g.ID] {
+			return g.ID, nil
+		}
+	}
+	return "", fmt.Errorf("group %q was created but could not be found", name)
+}
+
+// createDesktopAPIKey offers to create an API key for the GNOME extension or
+// tray, which use the HTTP API.
+func createDesktopAPIKey(c client.ClientInterface) error {
+	create, _ := pterm.DefaultInteractiveConfirm.
+		WithDefaultText("Create an API key for the GNOME extension or tray application?").
+		WithDefaultValue(false).
+		Show()
+	if !create {
+		return nil
+	}
+	name, err := pterm.DefaultInteractiveTextInput.
+		WithMultiLine(false).
+		WithDefaultValue("desktop").
+		Show("API key name")
+	if err != nil {
+		return fmt.Errorf("failed to get API key name: %w", err)
+	}
+	key, err := c.AddAPIKey(name, 0)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	PrintPromptResult("success", "API Key Created", "Copy the key now; it is not shown again in full.",
+		[][2]string{{"Name", key.Name}, {"Key", key.Key}})
+	pterm.Info.Println("Enter the key in the GNOME extension's preferences or the tray's settings.")
+	return nil
+}
+
+// displayName returns a light's name, or its product name if it has none.
+func displayName(light *client.Light) string {
+	if light.Name != "" {
+		return light.Name
+	}
+	return light.ProductName
+}
+
+// writeClientConfig writes the socket path to the client config file at path,
+// keeping any other settings already in it.
+func writeClientConfig(path, socket string) error {
+	return updateClientConfig(path, func(cfg map[string]any) {
+		section(cfg, "server")["unix_socket"] = socket
+	})
+}
+
+// updateClientConfig has update change the config section of the client
+// config file at path, keeping any other settings already in it.
+func updateClientConfig(path string, update func(cfg map[string]any)) error {
+	doc := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	update(section(doc, "config"))
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode client config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	// The file may hold an API key, so it is replaced rather than rewritten:
+	// WriteFile only applies the mode to new files
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// section returns the map under key in parent, adding an empty one if there
+// isn't one.
+func section(parent map[string]any, key string) map[string]any {
+	child, ok := parent[key].(map[string]any)
+	if !ok {
+		child = map[string]any{}
+		parent[key] = child
+	}
+	return child
+}
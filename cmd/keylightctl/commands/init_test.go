@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// discoveringClient returns one more light on each of its first calls to
+// GetLights, as if discovery were finding them.
+type discoveringClient struct {
+	mockClient
+	calls int
+}
+
+func (m *discoveringClient) GetLights() (map[string]*client.Light, error) {
+	m.calls++
+	lights := map[string]*client.Light{}
+	for i := 1; i <= min(m.calls, 2); i++ {
+		id := "light" + string(rune('0'+i))
+		lights[id] = &client.Light{ID: id}
+	}
+	return lights, nil
+}
+
+func TestWaitForLights(t *testing.T) {
+	c := &discoveringClient{}
+	lights, err := waitForLights(c, time.Second, time.Millisecond)
+	require.NoError(t, err)
+	assert.Len(t, lights, 2)
+	assert.Equal(t, 5, c.calls, "waits until the lights stop changing")
+
+	empty := &mockGroupClient{}
+	lights, err = waitForLights(empty, 20*time.Millisecond, 5*time.Millisecond)
+	require.NoError(t, err)
+	assert.Empty(t, lights, "gives up after the wait")
+}
+
+func TestCreateGroup(t *testing.T) {
+	m := &mockGroupClient{groups: map[string]*client.Group{
+		"group-1": {ID: "group-1", Name: "Office"},
+	}}
+	id, err := createGroup(m, "Office")
+	require.NoError(t, err)
+	assert.Equal(t, "Office", id, "the new group is found, not the existing one of the same name")
+
+	m.fail = true
+	_, err = createGroup(m, "Desk")
+	assert.Error(t, err)
+}
+
+func TestWriteClientConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keylightd", "keylightctl.yaml")
+	require.NoError(t, writeClientConfig(path, "/run/user/1000/keylightd.sock"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "config:\n  server:\n    unix_socket: /run/user/1000/keylightd.sock\n", string(data))
+
+	// Other settings are kept
+	require.NoError(t, os.WriteFile(path, []byte("config:\n  logging:\n    level: debug\n"), 0o600))
+	require.NoError(t, writeClientConfig(path, "/run/keylightd/keylightd.sock"))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "level: debug")
+	assert.Contains(t, string(data), "unix_socket: /run/keylightd/keylightd.sock")
+}
+
+func TestWriteClientConfig_RestrictsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keylightctl.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("config: {}\n"), 0o600))
+	require.NoError(t, os.Chmod(path, 0o644))
+
+	require.NoError(t, writeClientConfig(path, "/run/keylightd/keylightd.sock"))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.NoFileExists(t, path+".tmp")
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// jobPollInterval is how often --wait checks whether a job has finished.
+const jobPollInterval = 250 * time.Millisecond
+
+// NewJobCommand creates the job command group.
+func NewJobCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "job",
+		Short:   "Follow and cancel long-running operations",
+		Long:    "Jobs are long-running operations, such as applying a scene, that carry on in the\nbackground after the command that started them returns.",
+		Aliases: []string{"jobs"},
+	}
+
+	cmd.AddCommand(
+		newJobListCommand(logger),
+		newJobGetCommand(logger),
+		newJobCancelCommand(logger),
+	)
+
+	return cmd
+}
+
+// waitForJob polls a job until it has finished, then prints it. If the job
+// didn't complete, it returns an error in the machine-readable formats so
+// scripts can tell.
+func waitForJob(cmd *cobra.Command, apiClient client.ClientInterface, title string, job *client.Job) error {
+	for !job.Done() {
+		time.Sleep(jobPollInterval)
+		var err error
+		if job, err = apiClient.GetJob(job.ID); err != nil {
+			return fmt.Errorf("failed to get job: %w", err)
+		}
+	}
+	if err := printJob(cmd, title, job); err != nil {
+		return err
+	}
+	if outputFormat(cmd) != OutputTable && job.Status != "completed" {
+		return fmt.Errorf("job %s %s", job.ID, job.Status)
+	}
+	return nil
+}
+
+// jobProgress returns a job's progress as a map, or nil if it has none.
+func jobProgress(job *client.Job) map[string]any {
+	var progress map[string]any
+	if err := client.DecodeProgress(job, &progress); err != nil {
+		return nil
+	}
+	return progress
+}
+
+// jobFields returns a job as result fields, with its progress last.
+func jobFields(job *client.Job) []resultField {
+	fields := []resultField{
+		{"id", job.ID},
+		{"kind", job.Kind},
+		{"subject", job.Subject},
+		{"status", string(job.Status)},
+		{"error", job.Error},
+		{"started", job.StartedAt.Unix()},
+	}
+	if !job.FinishedAt.IsZero() {
+		fields = append(fields, resultField{"finished", job.FinishedAt.Unix()})
+	}
+	progress := jobProgress(job)
+	for _, key := range slices.Sorted(maps.Keys(progress)) {
+		value := progress[key]
+		if list, ok := value.([]any); ok {
+			strs := make([]string, len(list))
+			for i, v := range list {
+				strs[i] = fmt.Sprint(v)
+			}
+			value = strs
+		}
+		fields = append(fields, resultField{key, value})
+	}
+	return fields
+}
+
+// printJob prints a job in the command's output format.
+func printJob(cmd *cobra.Command, title string, job *client.Job) error {
+	format := outputFormat(cmd)
+	switch format {
+	case OutputJSON:
+		return printJSON(job)
+	case OutputParseable:
+		return printResult(format, jobFields(job)...)
+	}
+
+	status := "info"
+	switch job.Status {
+	case "completed":
+		status = "success"
+	case "failed":
+		status = "error"
+	case "cancelled":
+		status = "warn"
+	}
+	fields := [][2]string{
+		{"ID", job.ID},
+		{"Kind", job.Kind},
+		{"Subject", job.Subject},
+		{"Status", string(job.Status)},
+		{"Started", formatTimeForDisplay(job.StartedAt)},
+	}
+	if !job.FinishedAt.IsZero() {
+		fields = append(fields, [2]string{"Finished", formatTimeForDisplay(job.FinishedAt)})
+	}
+	if job.Error != "" {
+		fields = append(fields, [2]string{"Error", job.Error})
+	}
+	var progress client.SceneProgress
+	if job.Kind == "scene.apply" && client.DecodeProgress(job, &progress) == nil {
+		fields = append(fields,
+			[2]string{"Step", fmt.Sprintf("%d of %d", progress.Step, progress.Steps)},
+			[2]string{"Applied", fmt.Sprintf("%d of %d entries", progress.Applied, progress.Entries)},
+		)
+		for _, e := range progress.Errors {
+			fields = append(fields, [2]string{"Entry error", e})
+		}
+	}
+	PrintPromptResult(status, title, "", fields)
+	return nil
+}
+
+func newJobListCommand(_ *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List running and recently finished jobs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			jobs, err := apiClient.ListJobs()
+			if err != nil {
+				return fmt.Errorf("failed to list jobs: %w", err)
+			}
+
+			format := outputFormat(cmd)
+			switch format {
+			case OutputJSON:
+				return printJSON(jobs)
+			case OutputParseable:
+				results := make([][]resultField, len(jobs))
+				for i := range jobs {
+					results[i] = jobFields(&jobs[i])
+				}
+				return printResults(format, results)
+			}
+
+			if len(jobs) == 0 {
+				pterm.Info.Println("No jobs found.")
+				return nil
+			}
+			table := pterm.TableData{{"ID", "Kind", "Subject", "Status", "Started", "Error"}}
+			for _, j := range jobs {
+				table = append(table, []string{j.ID, j.Kind, j.Subject, string(j.Status), formatTimeForDisplay(j.StartedAt), j.Error})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format, same as --output parseable")
+	return cmd
+}
+
+func newJobGetCommand(_ *slog.Logger) *cobra.Command {
+	var wait bool
+	cmd := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show a job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			job, err := apiClient.GetJob(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get job: %w", err)
+			}
+			if wait {
+				return waitForJob(cmd, apiClient, "Job", job)
+			}
+			return printJob(cmd, "Job", job)
+		},
+	}
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait for the job to finish")
+	return cmd
+}
+
+func newJobCancelCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <id>",
+		Short: "Cancel a running job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			job, err := apiClient.CancelJob(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to cancel job: %w", err)
+			}
+			return printJob(cmd, "Job Cancelled", job)
+		},
+	}
+}
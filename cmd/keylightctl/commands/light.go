@@ -1,13 +1,18 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
@@ -32,6 +37,15 @@ func NewLightCommand(logger *slog.Logger) *cobra.Command {
 		newLightListCommand(),
 		newLightGetCommand(),
 		newLightSetCommand(logger),
+		newLightSetManyCommand(),
+		newLightToggleCommand(),
+		newLightRenameCommand(),
+		newLightTagCommand(),
+		newLightRawCommand(),
+		newLightSettingsCommand(),
+		newLightStatsCommand(),
+		newLightPendingCommand(),
+		newLightAdoptCommand(),
 	)
 
 	return cmd
@@ -39,8 +53,6 @@ func NewLightCommand(logger *slog.Logger) *cobra.Command {
 
 // newLightListCommand creates the light list command
 func newLightListCommand() *cobra.Command {
-	var parseable bool
-	var jsonOutput bool
 	var waybar bool
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -55,52 +67,34 @@ func newLightListCommand() *cobra.Command {
 				return fmt.Errorf("failed to get lights: %w", err)
 			}
 
-			if len(lights) == 0 {
-				if parseable || jsonOutput {
-					if jsonOutput {
-						fmt.Println("[]")
-					}
-					return nil
-				}
-				if waybar {
-					fmt.Println(FormatWaybarOutput(lights))
-					return nil
-				}
-				pterm.Info.Println("No lights discovered")
-				return nil
-			}
-
 			if waybar {
 				fmt.Println(FormatWaybarOutput(lights))
 				return nil
 			}
 
-			if jsonOutput {
-				var lightsList []LightJSON
-				for id, light := range lights {
-					lightMap, _ := light.(map[string]any)
-					lightsList = append(lightsList, LightToJSON(id, lightMap))
+			switch outputFormat(cmd) {
+			case OutputJSON:
+				lightsList := make([]LightJSON, 0, len(lights))
+				for _, light := range lights {
+					lightsList = append(lightsList, LightToJSON(light))
 				}
-				jsonBytes, err := json.MarshalIndent(lightsList, "", "  ")
-				if err != nil {
-					return fmt.Errorf("failed to marshal JSON: %w", err)
+				slices.SortFunc(lightsList, func(a, b LightJSON) int { return strings.Compare(a.ID, b.ID) })
+				return printJSON(lightsList)
+			case OutputParseable:
+				for _, light := range lights {
+					fmt.Println(LightParseable(light))
 				}
-				fmt.Println(string(jsonBytes))
 				return nil
 			}
 
-			if parseable {
-				for id, light := range lights {
-					lightMap, _ := light.(map[string]any)
-					fmt.Println(LightParseable(id, lightMap))
-				}
+			if len(lights) == 0 {
+				pterm.Info.Println("No lights discovered")
 				return nil
 			}
 
 			// Create a table for each light
-			for id, light := range lights {
-				lightMap, _ := light.(map[string]any)
-				table := LightTableData(id, lightMap)
+			for _, light := range lights {
+				table := LightTableData(light)
 				if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
 					return fmt.Errorf("failed to render table: %w", err)
 				}
@@ -109,18 +103,26 @@ func newLightListCommand() *cobra.Command {
 			return nil
 		},
 	}
-	cmd.Flags().BoolVarP(&parseable, "parseable", "p", false, "Output in parseable format (key=value)")
-	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format (key=value), same as --output parseable")
+	cmd.Flags().BoolP("json", "j", false, "Output in JSON format, same as --output json")
 	cmd.Flags().BoolVarP(&waybar, "waybar", "w", false, "Output in waybar-compatible JSON format")
 	return cmd
 }
 
 // newLightGetCommand creates the light get command
 func newLightGetCommand() *cobra.Command {
-	var parseable bool
 	cmd := &cobra.Command{
 		Use:   "get [id] [property]",
 		Short: "Get information about a light",
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return []cobra.Completion{
+					"id", "productname", "serialnumber", "firmwareversion", "firmwarebuild",
+					"on", "brightness", "temperature", "hue", "saturation", "colormode", "ip", "port", "lastseen",
+				}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeLightID(cmd, args, toComplete)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
 			if !ok {
@@ -145,8 +147,7 @@ func newLightGetCommand() *cobra.Command {
 				// Create options for dropdown
 				options := make([]string, len(ids))
 				for i, id := range ids {
-					lightMap, _ := lights[id].(map[string]any)
-					options[i] = fmt.Sprintf("%s (%v)", id, lightMap["productname"])
+					options[i] = fmt.Sprintf("%s (%s)", id, lights[id].ProductName)
 				}
 
 				selected, err := pterm.DefaultInteractiveSelect.
@@ -168,42 +169,63 @@ func newLightGetCommand() *cobra.Command {
 				return fmt.Errorf("failed to get light: %w", err)
 			}
 
+			format := outputFormat(cmd)
+
 			// If a specific property was requested, only show that
 			if len(args) > 1 {
 				property := strings.ToLower(args[1])
-				value, ok := light[property]
+				value, ok := lightProperty(light, property)
 				if !ok {
 					return fmt.Errorf("invalid property: %s", property)
 				}
-				if parseable {
+				switch format {
+				case OutputJSON:
+					return printJSON(map[string]any{property: value})
+				case OutputParseable:
 					fmt.Printf("%s=%v\n", property, value)
-				} else {
+				default:
 					fmt.Println(value)
 				}
 				return nil
 			}
 
 			// Show all properties
-			if parseable {
-				fmt.Println(LightParseable(lightID, light))
-			} else {
-				table := LightTableData(lightID, light)
-				if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
-					return fmt.Errorf("failed to render table: %w", err)
-				}
+			switch format {
+			case OutputJSON:
+				return printJSON(LightToJSON(light))
+			case OutputParseable:
+				fmt.Println(LightParseable(light))
+				return nil
+			}
+			table := LightTableData(light)
+			if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
 			}
 			return nil
 		},
 	}
-	cmd.Flags().BoolVarP(&parseable, "parseable", "p", false, "Output in parseable format (key=value)")
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format (key=value), same as --output parseable")
 	return cmd
 }
 
 // newLightSetCommand creates the light set command
 func newLightSetCommand(_ *slog.Logger) *cobra.Command {
+	var transition time.Duration
 	cmd := &cobra.Command{
-		Use:   "set [id] [property] [value]",
-		Short: "Set a light property",
+		Use:               "set [id] [property] [value]",
+		Short:             "Set a light property",
+		ValidArgsFunction: completeLightProperty,
+		Long: `Set a light property. Brightness and temperature also accept values
+relative to the light's current state, such as +10, -10 or +200K.
+
+Lights that support color, such as the Elgato Light Strip, also accept hue
+(0-360 degrees) and saturation (0-100). Setting a temperature switches them
+back to white.
+
+Instead of an ID, "all" sets every light, a glob pattern such as 'Desk*' sets
+every light whose ID or name matches, and a tag selector sets every light with
+matching tags, for example tag:side=left or tag:location=desk,side=left. See
+"light tag".`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
 			if !ok {
@@ -229,8 +251,7 @@ func newLightSetCommand(_ *slog.Logger) *cobra.Command {
 				// Create options for dropdown
 				options := make([]string, len(ids))
 				for i, id := range ids {
-					lightMap, _ := lights[id].(map[string]any)
-					options[i] = fmt.Sprintf("%s (%v)", id, lightMap["productname"])
+					options[i] = fmt.Sprintf("%s (%s)", id, lights[id].ProductName)
 				}
 
 				selected, err := pterm.DefaultInteractiveSelect.
@@ -253,15 +274,15 @@ func newLightSetCommand(_ *slog.Logger) *cobra.Command {
 				property = args[1]
 				// Validate property
 				switch strings.ToLower(property) {
-				case "on", "brightness", "temperature":
+				case "on", "brightness", "temperature", "hue", "saturation":
 					// Valid property
 				default:
-					return fmt.Errorf("invalid property: %s. Must be one of: on, brightness, temperature", property)
+					return fmt.Errorf("invalid property: %s. Must be one of: on, brightness, temperature, hue, saturation", property)
 				}
 			} else {
 				// Show dropdown for property selection
 				property, err = pterm.DefaultInteractiveSelect.
-					WithOptions([]string{"On", "Brightness", "Temperature"}).
+					WithOptions([]string{"On", "Brightness", "Temperature", "Hue", "Saturation"}).
 					Show("Select property to set")
 				if err != nil {
 					return fmt.Errorf("failed to select property: %w", err)
@@ -270,11 +291,22 @@ func newLightSetCommand(_ *slog.Logger) *cobra.Command {
 
 			// Convert display property name to lowercase for the API
 			propertyLower := strings.ToLower(property)
+			format := outputFormat(cmd)
 
-			// Get value
+			// Get value. Relative brightness and temperature values are sent unchanged.
 			var value any
-			switch propertyLower {
-			case "on":
+			if len(args) > 2 {
+				relative, err := relativeValue(propertyLower, args[2])
+				if err != nil {
+					return err
+				}
+				if relative {
+					value = args[2]
+				}
+			}
+			switch {
+			case value != nil:
+			case propertyLower == "on":
 				if len(args) > 2 {
 					value = args[2] == "true" || args[2] == "on"
 				} else {
@@ -286,7 +318,7 @@ func newLightSetCommand(_ *slog.Logger) *cobra.Command {
 					}
 					value = selected == "On"
 				}
-			case "brightness":
+			case propertyLower == "brightness":
 				if len(args) > 2 {
 					brightness, err := strconv.Atoi(args[2])
 					if err != nil {
@@ -294,9 +326,10 @@ func newLightSetCommand(_ *slog.Logger) *cobra.Command {
 					}
 					value = brightness
 				} else {
+					r := lightRange(c, lightID)
 					result, err := pterm.DefaultInteractiveTextInput.
 						WithMultiLine(false).
-						Show("Enter brightness (0-100)")
+						Show(fmt.Sprintf("Enter brightness (%d-%d)", r.MinBrightness, r.MaxBrightness))
 					if err != nil {
 						return fmt.Errorf("failed to get brightness value: %w", err)
 					}
@@ -306,31 +339,21 @@ func newLightSetCommand(_ *slog.Logger) *cobra.Command {
 					}
 					value = brightness
 				}
-			case "temperature":
+			case propertyLower == "temperature":
 				if len(args) > 2 {
 					temp, err := strconv.Atoi(args[2])
 					if err != nil {
 						return fmt.Errorf("invalid temperature value: %w", err)
 					}
-					// Clamp temperature to valid range
-					if temp < 2900 {
-						temp = 2900
-					} else if temp > 7000 {
-						temp = 7000
-					}
-					// Convert to mireds for display
-					mireds := 1000000 / temp
-					if mireds > 344 {
-						mireds = 344
-					} else if mireds < 143 {
-						mireds = 143
+					if format == OutputTable {
+						pterm.Info.Printf("Setting temperature to %dK\n", temp)
 					}
-					pterm.Info.Printf("Setting temperature to %dK (%d mireds)\n", temp, mireds)
 					value = temp
 				} else {
+					r := lightRange(c, lightID)
 					result, err := pterm.DefaultInteractiveTextInput.
 						WithMultiLine(false).
-						Show("Enter temperature (2900K-7000K, warm to cool)")
+						Show(fmt.Sprintf("Enter temperature (%dK-%dK, warm to cool)", r.MinTemperature, r.MaxTemperature))
 					if err != nil {
 						return fmt.Errorf("failed to get temperature value: %w", err)
 					}
@@ -338,31 +361,670 @@ func newLightSetCommand(_ *slog.Logger) *cobra.Command {
 					if err != nil {
 						return fmt.Errorf("invalid temperature value: %w", err)
 					}
-					// Clamp temperature to valid range
-					if temp < 2900 {
-						temp = 2900
-					} else if temp > 7000 {
-						temp = 7000
+					if format == OutputTable {
+						pterm.Info.Printf("Setting temperature to %dK\n", temp)
 					}
-					// Convert to mireds for display
-					mireds := 1000000 / temp
-					if mireds > 344 {
-						mireds = 344
-					} else if mireds < 143 {
-						mireds = 143
-					}
-					pterm.Info.Printf("Setting temperature to %dK (%d mireds)\n", temp, mireds)
 					value = temp
 				}
+			case propertyLower == "hue" || propertyLower == "saturation":
+				var input string
+				if len(args) > 2 {
+					input = args[2]
+				} else {
+					prompt := "Enter hue (0-360 degrees)"
+					if propertyLower == "saturation" {
+						prompt = "Enter saturation (0-100)"
+					}
+					input, err = pterm.DefaultInteractiveTextInput.
+						WithMultiLine(false).
+						Show(prompt)
+					if err != nil {
+						return fmt.Errorf("failed to get %s value: %w", propertyLower, err)
+					}
+				}
+				v, err := strconv.ParseFloat(input, 64)
+				if err != nil {
+					return fmt.Errorf("invalid %s value: %w", propertyLower, err)
+				}
+				value = v
 			}
 
-			if err := c.SetLightState(lightID, propertyLower, value); err != nil {
+			if err := c.SetLightState(lightID, propertyLower, value, client.WithTransition(transition)); err != nil {
 				return fmt.Errorf("failed to set light state: %w", err)
 			}
 
+			if format != OutputTable {
+				return printResult(format, resultField{"id", lightID}, resultField{"property", propertyLower}, resultField{"value", value})
+			}
 			pterm.Success.Println("Light state updated successfully")
 			return nil
 		},
 	}
+	cmd.Flags().DurationVar(&transition, "transition", 0, "Ramp to the new value over this duration (e.g. 2s, 500ms)")
+	return cmd
+}
+
+// lightRange returns the brightness and temperature range of a light's model
+// as reported by the daemon, or the default range if the light can't be read.
+func lightRange(c client.ClientInterface, id string) keylight.Limits {
+	light, err := c.GetLight(id)
+	if err != nil {
+		return keylight.DefaultLimits()
+	}
+	return light.Range()
+}
+
+// relativeValue reports whether arg is a relative brightness or temperature
+// value such as "+10" or "-200K".
+func relativeValue(property, arg string) (bool, error) {
+	if property != "brightness" && property != "temperature" {
+		return false, nil
+	}
+	_, relative, err := keylight.ParseDelta(arg)
+	return relative, err
+}
+
+// newLightSetManyCommand creates the light set-many command
+func newLightSetManyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-many <id>:<property>=<value>[,<property>=<value>]...",
+		Short: "Set the state of several lights at once",
+		Long: `Set the state of several lights in a single request. The daemon applies the
+updates concurrently, and rejects the whole batch if any update is invalid.
+
+Example:
+  keylightctl light set-many key-left:on=true,brightness=40 key-right:on=false`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			updates := make([]client.LightStateUpdate, 0, len(args))
+			for _, arg := range args {
+				update, err := parseLightStateUpdate(arg)
+				if err != nil {
+					return err
+				}
+				updates = append(updates, update)
+			}
+
+			if err := c.SetLightsState(updates); err != nil {
+				return fmt.Errorf("failed to set light states: %w", err)
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				results := make([][]resultField, 0, len(updates))
+				for _, update := range updates {
+					results = append(results, lightStateUpdateFields(update))
+				}
+				return printResults(format, results)
+			}
+			pterm.Success.Printf("Updated %d lights\n", len(updates))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newLightToggleCommand creates the light toggle command
+func newLightToggleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "toggle <id>",
+		Short:             "Toggle a light on or off",
+		ValidArgsFunction: completeLightID,
+		Long: `Toggle a light on or off. The daemon reads the light's current power state
+and inverts it in a single request.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			lightID := keylight.UnescapeRFC6763Label(args[0])
+			on, err := c.ToggleLight(lightID)
+			if err != nil {
+				return fmt.Errorf("failed to toggle light: %w", err)
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"id", lightID}, resultField{"on", on})
+			}
+			pterm.Success.Printf("Turned light %s %s\n", lightID, onOffString(on))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newLightRenameCommand creates the light rename command
+func newLightRenameCommand() *cobra.Command {
+	var reset, device bool
+	cmd := &cobra.Command{
+		Use:               "rename <id> [name]",
+		Short:             "Set a light's display name",
+		ValidArgsFunction: completeLightID,
+		Long: `Set a display name for a light that overrides the name reported by the device.
+The name is stored by the daemon and kept across restarts and rediscovery.
+Use --reset to remove the override and restore the device's own name.
+
+With --device the name is written to the light itself instead, so other apps
+such as Elgato Control Center see it too. Only Elgato lights support this.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			var name string
+			switch {
+			case reset && device:
+				return errors.New("--reset and --device cannot be combined")
+			case reset && len(args) == 2:
+				return errors.New("cannot give a name with --reset")
+			case !reset && len(args) == 1:
+				return errors.New("a name is required unless --reset is given")
+			case !reset:
+				name = args[1]
+				if strings.TrimSpace(name) == "" {
+					return errors.New("name cannot be empty, use --reset to restore the device's name")
+				}
+			}
+
+			lightID := keylight.UnescapeRFC6763Label(args[0])
+			format := outputFormat(cmd)
+			if device {
+				newName, err := c.SetDeviceName(lightID, name)
+				if err != nil {
+					return fmt.Errorf("failed to rename device: %w", err)
+				}
+				if format != OutputTable {
+					return printResult(format, resultField{"id", lightID}, resultField{"name", newName}, resultField{"device_name", name})
+				}
+				pterm.Success.Printf("Renamed device %s to %q\n", lightID, name)
+				if newName != name {
+					pterm.Info.Printf("keylightd still shows it as %q because of a name override; use --reset to remove it\n", newName)
+				}
+				return nil
+			}
+
+			newName, err := c.SetLightName(lightID, name)
+			if err != nil {
+				return fmt.Errorf("failed to rename light: %w", err)
+			}
+
+			if format != OutputTable {
+				return printResult(format, resultField{"id", lightID}, resultField{"name", newName})
+			}
+			if reset {
+				pterm.Success.Printf("Restored name of light %s to %q\n", lightID, newName)
+			} else {
+				pterm.Success.Printf("Renamed light %s to %q\n", lightID, newName)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&reset, "reset", false, "Remove the name override and use the device's own name")
+	cmd.Flags().BoolVar(&device, "device", false, "Write the name to the light itself instead of storing an override")
 	return cmd
 }
+
+// newLightTagCommand creates the light tag command
+func newLightTagCommand() *cobra.Command {
+	var remove []string
+	var clearAll bool
+	cmd := &cobra.Command{
+		Use:               "tag <id> [key=value...]",
+		Short:             "Show or change a light's tags",
+		ValidArgsFunction: completeLightID,
+		Long: `Show or change the tags of a light, such as location=desk or side=left.
+Tags are stored by the daemon and kept across restarts and rediscovery.
+
+Given key=value pairs, the tags are added to the light's existing tags,
+replacing any with the same key. Use --remove to remove tags by key or
+--clear to remove them all. With no changes the light's tags are shown.
+
+Tagged lights can be targeted together with a tag selector in place of a
+light ID, for example: keylightctl light set tag:side=left brightness 40`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			lightID := keylight.UnescapeRFC6763Label(args[0])
+			light, err := c.GetLight(lightID)
+			if err != nil {
+				return fmt.Errorf("failed to get light: %w", err)
+			}
+
+			tags := maps.Clone(light.Tags)
+			if clearAll {
+				tags = nil
+			}
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			for _, key := range remove {
+				delete(tags, key)
+			}
+			for _, arg := range args[1:] {
+				key, value, found := strings.Cut(arg, "=")
+				if !found || key == "" {
+					return fmt.Errorf("invalid tag %q, expected key=value", arg)
+				}
+				tags[key] = value
+			}
+
+			if clearAll || len(remove) > 0 || len(args) > 1 {
+				if tags, err = c.SetLightTags(lightID, tags); err != nil {
+					return fmt.Errorf("failed to set light tags: %w", err)
+				}
+			}
+
+			switch outputFormat(cmd) {
+			case OutputJSON:
+				return printJSON(map[string]any{"id": lightID, "tags": tags})
+			case OutputParseable:
+				fmt.Printf("id=\"%s\" tags=\"%s\"\n", lightID, formatTags(tags))
+				return nil
+			}
+			if len(tags) == 0 {
+				pterm.Info.Printf("Light %s has no tags\n", lightID)
+				return nil
+			}
+			pterm.Info.Printf("Light %s tags: %s\n", lightID, formatTags(tags))
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&remove, "remove", nil, "Remove the tags with these keys")
+	cmd.Flags().BoolVar(&clearAll, "clear", false, "Remove all of the light's tags")
+	return cmd
+}
+
+// newLightRawCommand creates the light raw command
+func newLightRawCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "raw <id> <method> <path> [body]",
+		Short:             "Send a raw request to a light's own API",
+		ValidArgsFunction: completeLightID,
+		Long: `Pass a request straight through to a light's own API, for device settings
+keylightd doesn't model yet. Only GET, PUT and POST to paths under /elgato/
+are allowed, and only Elgato lights support this. The body, if given, must be JSON.
+
+Changes made this way bypass keylightd, which picks them up the next time it
+reads the light's state.`,
+		Example: `  keylightctl light raw <id> GET /elgato/lights/settings
+  keylightctl light raw <id> PUT /elgato/lights/settings '{"powerOnBehavior": 2}'
+  keylightctl light raw <id> GET /elgato/battery-info`,
+		Args: cobra.RangeArgs(3, 4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			var body json.RawMessage
+			if len(args) == 4 {
+				body = json.RawMessage(args[3])
+				if !json.Valid(body) {
+					return errors.New("body must be valid JSON")
+				}
+			}
+
+			lightID := keylight.UnescapeRFC6763Label(args[0])
+			resp, err := c.RawRequest(lightID, strings.ToUpper(args[1]), args[2], body)
+			if err != nil {
+				return fmt.Errorf("raw request failed: %w", err)
+			}
+
+			switch format := outputFormat(cmd); format {
+			case OutputJSON:
+				if err := printJSON(resp); err != nil {
+					return err
+				}
+			case OutputParseable:
+				if err := printResult(format, resultField{"status_code", resp.StatusCode}, resultField{"body", string(resp.Body)}); err != nil {
+					return err
+				}
+			default:
+				if len(resp.Body) > 0 {
+					var out bytes.Buffer
+					if err := json.Indent(&out, resp.Body, "", "  "); err != nil {
+						return fmt.Errorf("failed to format response: %w", err)
+					}
+					fmt.Println(out.String())
+				}
+			}
+			if resp.StatusCode >= 400 {
+				return fmt.Errorf("light returned status %d", resp.StatusCode)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newLightSettingsCommand creates the light settings command
+func newLightSettingsCommand() *cobra.Command {
+	var behavior string
+	var brightness, temperature int
+	cmd := &cobra.Command{
+		Use:               "settings <id>",
+		Short:             "Show or change a light's power-on settings",
+		ValidArgsFunction: completeLightID,
+		Long: `Show or change what a light does when it gets power back, such as after a
+power cut. With --power-on restore the light returns to its last state; with
+--power-on default it turns on at the power-on brightness and temperature.
+The settings are stored on the light itself. Only Elgato lights support this.
+
+Without flags the current settings are shown.`,
+		Example: `  keylightctl light settings <id>
+  keylightctl light settings <id> --power-on default --brightness 40 --temperature 4500
+  keylightctl light settings <id> --power-on restore`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			var update client.LightSettingsUpdate
+			if cmd.Flags().Changed("power-on") {
+				update.PowerOnBehavior = &behavior
+			}
+			if cmd.Flags().Changed("brightness") {
+				update.PowerOnBrightness = &brightness
+			}
+			if cmd.Flags().Changed("temperature") {
+				update.PowerOnTemperature = &temperature
+			}
+
+			lightID := keylight.UnescapeRFC6763Label(args[0])
+			var settings *client.LightSettings
+			var err error
+			if update.IsEmpty() {
+				settings, err = c.GetLightSettings(lightID)
+			} else {
+				settings, err = c.SetLightSettings(lightID, update)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to access light settings: %w", err)
+			}
+
+			switch format := outputFormat(cmd); format {
+			case OutputJSON:
+				return printJSON(settings)
+			case OutputParseable:
+				return printResult(format,
+					resultField{"id", lightID},
+					resultField{"power_on_behavior", settings.PowerOnBehavior},
+					resultField{"power_on_brightness", settings.PowerOnBrightness},
+					resultField{"power_on_temperature", settings.PowerOnTemperature},
+				)
+			}
+			if !update.IsEmpty() {
+				pterm.Success.Printf("Updated settings for light %s\n", lightID)
+			}
+			table := pterm.TableData{
+				[]string{pterm.Bold.Sprint("ID"), pterm.Bold.Sprint(lightID)},
+				[]string{"Power-on behavior", settings.PowerOnBehavior},
+				[]string{"Power-on brightness", strconv.Itoa(settings.PowerOnBrightness)},
+				[]string{"Power-on temperature", fmt.Sprintf("%dK", settings.PowerOnTemperature)},
+			}
+			if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&behavior, "power-on", "", "What the light does when it gets power back (restore, default)")
+	cmd.Flags().IntVar(&brightness, "brightness", 0, "Brightness used when the power-on behavior is default (3-100)")
+	cmd.Flags().IntVar(&temperature, "temperature", 0, "Color temperature in Kelvin used when the power-on behavior is default (2900-7000)")
+	_ = cmd.RegisterFlagCompletionFunc("power-on", cobra.FixedCompletions([]string{keylight.PowerOnRestore, keylight.PowerOnDefault}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// newLightStatsCommand creates the light stats command
+func newLightStatsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "stats <id>",
+		Short:             "Show a light's usage statistics",
+		ValidArgsFunction: completeLightID,
+		Long: `Show how long a light has been on in total, how often it was turned on or
+off, and when its state last changed. The daemon tracks these from when it
+first sees a light and keeps them across restarts.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			lightID := keylight.UnescapeRFC6763Label(args[0])
+			stats, err := c.GetLightStats(lightID)
+			if err != nil {
+				return fmt.Errorf("failed to get light stats: %w", err)
+			}
+
+			switch format := outputFormat(cmd); format {
+			case OutputJSON:
+				return printJSON(stats)
+			case OutputParseable:
+				return printResult(format, lightStatsFields(stats)...)
+			}
+			table := pterm.TableData{
+				[]string{pterm.Bold.Sprint("ID"), pterm.Bold.Sprint(lightID)},
+				[]string{"On", strconv.FormatBool(stats.On)},
+				[]string{"On time", stats.OnTime().String()},
+				[]string{"Toggles", strconv.Itoa(stats.Toggles)},
+				[]string{"Last change", formatStatsTime(stats.LastChange)},
+				[]string{"Last toggle", formatStatsTime(stats.LastToggle)},
+				[]string{"Tracked since", stats.Since.Format(time.RFC1123Z)},
+			}
+			if err := pterm.DefaultTable.WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// newLightPendingCommand creates the light pending command
+func newLightPendingCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "List devices discovery found that aren't supported lights",
+		Long: `List devices that answered during discovery but aren't lights keylightd
+accepts, such as Elgato models released after this version. Use
+"keylightctl light adopt <id>" to add one as a light anyway.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			devices, err := c.ListPendingDevices()
+			if err != nil {
+				return fmt.Errorf("failed to list pending devices: %w", err)
+			}
+
+			format := outputFormat(cmd)
+			if format != OutputTable {
+				results := make([][]resultField, 0, len(devices))
+				for _, d := range devices {
+					results = append(results, pendingDeviceFields(d))
+				}
+				return printResults(format, results)
+			}
+			if len(devices) == 0 {
+				pterm.Info.Println("No pending devices")
+				return nil
+			}
+			table := pterm.TableData{{"ID", "Product", "Address", "Source", "Last Seen"}}
+			for _, d := range devices {
+				table = append(table, []string{d.ID, d.ProductName, net.JoinHostPort(d.IP, strconv.Itoa(d.Port)), d.Source, formatLastSeen(d.LastSeen)})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// newLightAdoptCommand creates the light adopt command
+func newLightAdoptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "adopt <id>",
+		Short: "Add a pending device as a light",
+		Long: `Add a device listed by "keylightctl light pending" as a light, although it
+isn't one keylightd recognises. The daemon remembers the adoption, so the
+device stays a light across restarts and rediscovery.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			id := keylight.UnescapeRFC6763Label(args[0])
+			light, err := c.AdoptDevice(id)
+			if err != nil {
+				return fmt.Errorf("failed to adopt device: %w", err)
+			}
+
+			switch outputFormat(cmd) {
+			case OutputJSON:
+				return printJSON(LightToJSON(light))
+			case OutputParseable:
+				fmt.Println(LightParseable(light))
+				return nil
+			}
+			pterm.Success.Printf("Adopted %s as a light\n", light.ID)
+			return nil
+		},
+	}
+}
+
+// pendingDeviceFields returns a pending device as result fields, with its
+// last sighting as a Unix timestamp.
+func pendingDeviceFields(d client.PendingDevice) []resultField {
+	return []resultField{
+		{"id", d.ID},
+		{"name", d.Name},
+		{"productname", d.ProductName},
+		{"ip", d.IP},
+		{"port", d.Port},
+		{"source", d.Source},
+		{"lastseen", d.LastSeen.Unix()},
+	}
+}
+
+// lightStatsFields returns a light's usage statistics as result fields, with
+// times as Unix timestamps (0 if never).
+func lightStatsFields(stats *client.LightStats) []resultField {
+	return []resultField{
+		{"id", stats.ID},
+		{"on", stats.On},
+		{"on_seconds", stats.OnSeconds},
+		{"toggles", stats.Toggles},
+		{"last_change", unixOrZero(stats.LastChange)},
+		{"last_toggle", unixOrZero(stats.LastToggle)},
+		{"since", stats.Since.Unix()},
+	}
+}
+
+// lightStateUpdateFields returns the properties set by a state update as result fields.
+func lightStateUpdateFields(update client.LightStateUpdate) []resultField {
+	fields := []resultField{{"id", update.ID}}
+	if update.On != nil {
+		fields = append(fields, resultField{"on", *update.On})
+	}
+	if update.Brightness != nil {
+		fields = append(fields, resultField{"brightness", *update.Brightness})
+	}
+	if update.Temperature != nil {
+		fields = append(fields, resultField{"temperature", *update.Temperature})
+	}
+	if update.Hue != nil {
+		fields = append(fields, resultField{"hue", *update.Hue})
+	}
+	if update.Saturation != nil {
+		fields = append(fields, resultField{"saturation", *update.Saturation})
+	}
+	return fields
+}
+
+// onOffString formats a power state for display.
+func onOffString(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// parseLightStateUpdate parses an "<id>:<property>=<value>[,...]" argument.
+// The ID ends at the last colon before the first "=", so IDs may contain colons.
+func parseLightStateUpdate(arg string) (client.LightStateUpdate, error) {
+	var update client.LightStateUpdate
+	eq := strings.Index(arg, "=")
+	if eq < 0 {
+		return update, fmt.Errorf("invalid update %q: expected <id>:<property>=<value>", arg)
+	}
+	colon := strings.LastIndex(arg[:eq], ":")
+	if colon <= 0 {
+		return update, fmt.Errorf("invalid update %q: expected <id>:<property>=<value>", arg)
+	}
+	update.ID = keylight.UnescapeRFC6763Label(arg[:colon])
+
+	for assignment := range strings.SplitSeq(arg[colon+1:], ",") {
+		property, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return update, fmt.Errorf("invalid update %q: expected <property>=<value>, got %q", arg, assignment)
+		}
+		switch strings.ToLower(property) {
+		case "on":
+			var on bool
+			switch strings.ToLower(value) {
+			case "true", "on":
+				on = true
+			case "false", "off":
+				on = false
+			default:
+				return update, fmt.Errorf("invalid on value for %s: %s", update.ID, value)
+			}
+			update.On = &on
+		case "brightness":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return update, fmt.Errorf("invalid brightness value for %s: %w", update.ID, err)
+			}
+			update.Brightness = &n
+		case "temperature":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return update, fmt.Errorf("invalid temperature value for %s: %w", update.ID, err)
+			}
+			update.Temperature = &n
+		case "hue", "saturation":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return update, fmt.Errorf("invalid %s value for %s: %w", strings.ToLower(property), update.ID, err)
+			}
+			if strings.EqualFold(property, "hue") {
+				update.Hue = &n
+			} else {
+				update.Saturation = &n
+			}
+		default:
+			return update, fmt.Errorf("invalid property: %s. Must be one of: on, brightness, temperature, hue, saturation", property)
+		}
+	}
+	return update, nil
+}
@@ -2,11 +2,15 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/jmylchreest/keylightd/internal/backup"
 	"github.com/jmylchreest/keylightd/pkg/client"
 )
 
@@ -15,87 +19,207 @@ import (
 
 // mockClient implements client.ClientInterface for CLI tests
 // and returns static data for testing.
-type mockClient struct{}
+type mockClient struct {
+	batch     []client.LightStateUpdate
+	toggled   []string
+	renamed   map[string]string
+	device    map[string]string
+	tags      map[string]map[string]string
+	raw       []string
+	settings  *client.LightSettingsUpdate
+	logLevel  string
+	filters   []map[string]any
+	logs      []client.LogEntry
+	logQuery  client.LogQuery
+	circadian bool
+	desired   bool
+	excluded  map[string]bool
+	imported  *client.ExportDocument
+	adopted   []string
+}
 
 var _ client.ClientInterface = (*mockClient)(nil)
 
 func (m *mockClient) GetVersion() (map[string]any, error) { return nil, nil }
 
-func (m *mockClient) GetLight(id string) (map[string]any, error) {
+func (m *mockClient) GetDaemonInfo() (*client.DaemonInfo, error) {
+	return &client.DaemonInfo{
+		Version:           "1.2.3",
+		Commit:            "abc123",
+		StartedAt:         time.Date(2023, time.October, 26, 9, 0, 0, 0, time.UTC),
+		UptimeSeconds:     3600,
+		SocketPath:        "/run/keylightd.sock",
+		APIListenAddress:  ":9123",
+		DiscoveryInterval: 30,
+		Lights:            2,
+		Clients:           client.DaemonClients{Socket: 2, EventSubscribers: 1},
+		Discovery: &client.DiscoveryStats{
+			Completed:      true,
+			Runs:           4,
+			BrowseAttempts: 4,
+			LastRun:        time.Date(2023, time.October, 26, 9, 55, 0, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (m *mockClient) GetLight(id string) (*client.Light, error) {
 	// Use a fixed time for predictable test output
 	lastSeenTime := time.Date(2023, time.October, 26, 10, 0, 0, 0, time.UTC)
-	light := map[string]any{
-		"id":              id,
-		"productname":     "Test Light",
-		"serialnumber":    "123456",
-		"hardware_board":  1,
-		"firmwarebuild":   1,
-		"firmwareversion": "1.0.0",
-		"name":            "Test Light",
-		"on":              true,
-		"brightness":      50,
-		"temperature":     5000,
-		"ip":              "192.168.1.1",
-		"port":            9123,
-		"lastseen":        lastSeenTime,
+	light := &client.Light{
+		ID:                id,
+		ProductName:       "Test Light",
+		SerialNumber:      "123456",
+		HardwareBoardType: 1,
+		FirmwareBuild:     1,
+		FirmwareVersion:   "1.0.0",
+		Name:              "Test Light",
+		On:                true,
+		Brightness:        50,
+		Temperature:       200,
+		IP:                net.ParseIP("192.168.1.1"),
+		Port:              9123,
+		LastSeen:          lastSeenTime,
+		Tags:              m.tags[id],
 	}
 	return light, nil
 }
 
-func (m *mockClient) GetLights() (map[string]any, error) {
+func (m *mockClient) GetLights() (map[string]*client.Light, error) {
 	// Use a fixed time for predictable test output
 	lastSeenTime1 := time.Date(2023, time.October, 26, 10, 0, 0, 0, time.UTC)
 	lastSeenTime2 := time.Date(2023, time.October, 26, 10, 5, 0, 0, time.UTC)
 
 	// Return a map of id -> light object
-	lights := map[string]any{
-		"light1": map[string]any{
-			"id":              "light1",
-			"productname":     "Light 1",
-			"serialnumber":    "SN1",
-			"firmwareversion": "1.0.0",
-			"firmwarebuild":   1,
-			"on":              true,
-			"brightness":      50,
-			"temperature":     5000,
-			"ip":              "192.168.1.1",
-			"port":            9123,
-			"lastseen":        lastSeenTime1,
+	lights := map[string]*client.Light{
+		"light1": {
+			ID:              "light1",
+			ProductName:     "Light 1",
+			SerialNumber:    "SN1",
+			FirmwareVersion: "1.0.0",
+			FirmwareBuild:   1,
+			On:              true,
+			Brightness:      50,
+			Temperature:     200,
+			IP:              net.ParseIP("192.168.1.1"),
+			Port:            9123,
+			LastSeen:        lastSeenTime1,
 		},
-		"light2": map[string]any{
-			"id":              "light2",
-			"productname":     "Light 2",
-			"serialnumber":    "SN2",
-			"firmwareversion": "1.0.0",
-			"firmwarebuild":   1,
-			"on":              false,
-			"brightness":      0,
-			"temperature":     3000,
-			"ip":              "192.168.1.2",
-			"port":            9123,
-			"lastseen":        lastSeenTime2,
+		"light2": {
+			ID:              "light2",
+			ProductName:     "Light 2",
+			SerialNumber:    "SN2",
+			FirmwareVersion: "1.0.0",
+			FirmwareBuild:   1,
+			On:              false,
+			Brightness:      0,
+			Temperature:     333,
+			IP:              net.ParseIP("192.168.1.2"),
+			Port:            9123,
+			LastSeen:        lastSeenTime2,
 		},
 	}
 	return lights, nil
 }
 
-func (m *mockClient) SetLightState(id string, property string, value any) error {
+func (m *mockClient) SetLightState(id string, property string, value any, _ ...client.StateOption) error {
+	return nil
+}
+
+func (m *mockClient) SetLightsState(updates []client.LightStateUpdate) error {
+	m.batch = updates
 	return nil
 }
 
+func (m *mockClient) ToggleLight(id string) (bool, error) {
+	m.toggled = append(m.toggled, id)
+	return true, nil
+}
+
+func (m *mockClient) SetLightTags(id string, tags map[string]string) (map[string]string, error) {
+	if m.tags == nil {
+		m.tags = make(map[string]map[string]string)
+	}
+	if len(tags) == 0 {
+		delete(m.tags, id)
+		return nil, nil
+	}
+	m.tags[id] = tags
+	return tags, nil
+}
+
+func (m *mockClient) SetLightName(id, name string) (string, error) {
+	if m.renamed == nil {
+		m.renamed = make(map[string]string)
+	}
+	m.renamed[id] = name
+	if name == "" {
+		return "Device Name", nil
+	}
+	return name, nil
+}
+
+func (m *mockClient) SetDeviceName(id, name string) (string, error) {
+	if m.device == nil {
+		m.device = make(map[string]string)
+	}
+	m.device[id] = name
+	return name, nil
+}
+
+func (m *mockClient) RawRequest(id, method, path string, body json.RawMessage) (*client.RawResponse, error) {
+	m.raw = append(m.raw, id+" "+method+" "+path+" "+string(body))
+	if path == "/elgato/battery-info" {
+		return &client.RawResponse{StatusCode: 404}, nil
+	}
+	return &client.RawResponse{StatusCode: 200, Body: json.RawMessage(`{"powerOnBehavior":1}`)}, nil
+}
+
+func (m *mockClient) GetLightSettings(id string) (*client.LightSettings, error) {
+	return &client.LightSettings{PowerOnBehavior: "restore", PowerOnBrightness: 20, PowerOnTemperature: 4700}, nil
+}
+
+func (m *mockClient) GetLightStats(id string) (*client.LightStats, error) {
+	if id != "light1" {
+		return nil, errors.New("light not found")
+	}
+	lastToggle := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	return &client.LightStats{ID: id, On: true, OnSeconds: 3600, Toggles: 4, LastChange: &lastToggle, LastToggle: &lastToggle,
+		Since: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)}, nil
+}
+
+func (m *mockClient) SetLightSettings(id string, update client.LightSettingsUpdate) (*client.LightSettings, error) {
+	m.settings = &update
+	settings, _ := m.GetLightSettings(id)
+	if update.PowerOnBehavior != nil {
+		settings.PowerOnBehavior = *update.PowerOnBehavior
+	}
+	if update.PowerOnBrightness != nil {
+		settings.PowerOnBrightness = *update.PowerOnBrightness
+	}
+	if update.PowerOnTemperature != nil {
+		settings.PowerOnTemperature = *update.PowerOnTemperature
+	}
+	return settings, nil
+}
+
+func (m *mockClient) ToggleGroup(name string) (map[string]bool, error) {
+	m.toggled = append(m.toggled, name)
+	return map[string]bool{name: false}, nil
+}
+
 func (m *mockClient) CreateGroup(name string) error {
 	return nil
 }
 
-func (m *mockClient) GetGroup(name string) (map[string]any, error) {
-	return map[string]any{"id": name}, nil
+func (m *mockClient) GetGroup(name string) (*client.Group, error) {
+	return &client.Group{ID: name}, nil
 }
 
-func (m *mockClient) GetGroups() ([]map[string]any, error) {
-	return []map[string]any{}, nil
+func (m *mockClient) GetGroups() ([]*client.Group, error) {
+	return []*client.Group{}, nil
 }
 
-func (m *mockClient) SetGroupState(name string, property string, value any) error {
+func (m *mockClient) SetGroupState(name string, property string, value any, _ ...client.StateOption) error {
 	return nil
 }
 
@@ -107,22 +231,183 @@ func (m *mockClient) SetGroupLights(groupID string, lightIDs []string) error {
 	return nil
 }
 
+func (m *mockClient) SetGroupDefaults(groupID string, brightness, temperature *int, applyOnJoin bool) error {
+	return nil
+}
+
+func (m *mockClient) UpdateGroup(groupID string, update client.GroupUpdate) (*client.Group, error) {
+	return nil, client.ErrUnsupported
+}
+
+func (m *mockClient) GetGroupState(groupID string) (*client.GroupState, error) {
+	return nil, client.ErrUnsupported
+}
+
+func (m *mockClient) GetGroupStats(groupID string) (*client.GroupStats, error) {
+	return nil, client.ErrUnsupported
+}
+
 // API Key Management Mocks (satisfy client.ClientInterface)
-func (m *mockClient) AddAPIKey(name string, expiresInSeconds float64) (map[string]any, error) {
+func (m *mockClient) AddAPIKey(name string, expiresInSeconds float64) (*client.APIKey, error) {
 	// Simple mock: doesn't actually store/return a real key structure for light tests
-	return map[string]any{"key": "mockapikey", "name": name}, nil
+	return &client.APIKey{Key: "mockapikey", Name: name}, nil
 }
 
-func (m *mockClient) ListAPIKeys() ([]map[string]any, error) {
-	return []map[string]any{}, nil // Return empty list for light tests
+func (m *mockClient) ListAPIKeys() ([]client.APIKey, error) {
+	return []client.APIKey{}, nil // Return empty list for light tests
 }
 
 func (m *mockClient) DeleteAPIKey(key string) error {
 	return nil
 }
 
-func (m *mockClient) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (map[string]any, error) {
-	return map[string]any{"key": keyOrName, "disabled": disabled}, nil
+func (m *mockClient) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (*client.APIKey, error) {
+	return &client.APIKey{Key: keyOrName, Disabled: disabled}, nil
+}
+
+func (m *mockClient) ListSchedules() ([]map[string]any, error) {
+	return []map[string]any{}, nil
+}
+
+func (m *mockClient) ListUpcomingSchedules(limit, days int) ([]client.ScheduleExecution, error) {
+	return []client.ScheduleExecution{}, nil
+}
+
+func (m *mockClient) CreateSchedule(schedule map[string]any) (map[string]any, error) {
+	return schedule, nil
+}
+
+func (m *mockClient) DeleteSchedule(id string) error {
+	return nil
+}
+
+func (m *mockClient) ListScenes() ([]client.Scene, error) {
+	return []client.Scene{}, nil
+}
+
+func (m *mockClient) CreateScene(s client.Scene) (*client.Scene, error) {
+	return &s, nil
+}
+
+func (m *mockClient) DeleteScene(id string) error {
+	return nil
+}
+
+func (m *mockClient) ApplyScene(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+
+func (m *mockClient) GetSceneRun(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+
+func (m *mockClient) CancelSceneRun(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+
+func (m *mockClient) ListJobs() ([]client.Job, error) {
+	return []client.Job{}, nil
+}
+
+func (m *mockClient) GetJob(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+
+func (m *mockClient) CancelJob(id string) (*client.Job, error) {
+	return nil, client.ErrUnsupported
+}
+
+func (m *mockClient) DiscoverNow() (*client.DiscoveryResult, error) {
+	light, _ := m.GetLight("light-3")
+	return &client.DiscoveryResult{Found: []*client.Light{light}, Lights: 3, DurationMS: 1500}, nil
+}
+
+func (m *mockClient) ListPendingDevices() ([]client.PendingDevice, error) {
+	return []client.PendingDevice{{
+		ID:          "Elgato Key Light Neo 1A2B",
+		ProductName: "Elgato Key Light Neo",
+		IP:          "192.168.1.50",
+		Port:        9123,
+		Source:      "mdns",
+		LastSeen:    time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+func (m *mockClient) AdoptDevice(id string) (*client.Light, error) {
+	m.adopted = append(m.adopted, id)
+	return &client.Light{ID: id, ProductName: "Elgato Key Light Neo"}, nil
+}
+
+func (m *mockClient) GetCircadian() (*client.CircadianStatus, error) {
+	return &client.CircadianStatus{
+		Enabled: m.circadian,
+		Mode:    "sun",
+		Target:  &client.CircadianTarget{Temperature: 4700, Brightness: 60},
+		Sunrise: time.Date(2023, time.October, 26, 7, 0, 0, 0, time.UTC),
+		Sunset:  time.Date(2023, time.October, 26, 17, 45, 0, 0, time.UTC),
+		Lights:  []string{"light-1"},
+	}, nil
+}
+
+func (m *mockClient) SetCircadian(enabled bool) (*client.CircadianStatus, error) {
+	m.circadian = enabled
+	return m.GetCircadian()
+}
+
+func (m *mockClient) GetDesiredState() (*client.DesiredStateStatus, error) {
+	on, brightness := true, 40
+	return &client.DesiredStateStatus{
+		Enabled:  m.desired,
+		Interval: 30,
+		Targets: []client.DesiredStateTarget{{
+			Target:         "group:office",
+			On:             &on,
+			Brightness:     &brightness,
+			Enabled:        !m.excluded["group:office"],
+			Lights:         []string{"light-1", "light-2"},
+			Corrections:    2,
+			LastCorrection: time.Date(2023, time.October, 26, 9, 30, 0, 0, time.UTC),
+		}},
+	}, nil
+}
+
+func (m *mockClient) SetDesiredState(enabled bool) (*client.DesiredStateStatus, error) {
+	m.desired = enabled
+	return m.GetDesiredState()
+}
+
+func (m *mockClient) SetDesiredStateTarget(target string, enabled bool) (*client.DesiredStateStatus, error) {
+	if target != "group:office" {
+		return nil, errors.New("desired-state target " + target + " not found")
+	}
+	if m.excluded == nil {
+		m.excluded = map[string]bool{}
+	}
+	m.excluded[target] = !enabled
+	return m.GetDesiredState()
+}
+
+func (m *mockClient) ExportConfig(includeSecrets bool) (*client.ExportDocument, error) {
+	doc := &client.ExportDocument{
+		Version:    1,
+		Groups:     []backup.ExportedGroup{{ID: "group-1", Name: "Office", Lights: []string{"light-1"}}},
+		APIKeys:    []backup.ExportedAPIKey{{Name: "tray"}},
+		LightNames: map[string]string{"light-1": "Left"},
+	}
+	if includeSecrets {
+		doc.APIKeys[0].Key = "secret"
+	}
+	return doc, nil
+}
+
+func (m *mockClient) ImportConfig(doc *client.ExportDocument) (*client.ImportResult, error) {
+	m.imported = doc
+	return &client.ImportResult{
+		Groups:     len(doc.Groups),
+		Schedules:  len(doc.Schedules),
+		LightNames: len(doc.LightNames),
+		Skipped:    []string{`API key "tray": not exported with its secret`},
+	}, nil
 }
 
 func TestLightGetCommandParseable(t *testing.T) {
@@ -190,7 +475,8 @@ func TestLightGetCommand(t *testing.T) {
 	require.Contains(t, outParseable, "firmwarebuild=1")
 	require.Contains(t, outParseable, "on=true")
 	require.Contains(t, outParseable, "brightness=50")
-	require.Contains(t, outParseable, "temperature=5000")
+	require.Contains(t, outParseable, "temperature=200")
+	require.Contains(t, outParseable, "temperature_kelvin=5000")
 	require.Contains(t, outParseable, "ip=\"192.168.1.1\"")
 	require.Contains(t, outParseable, "port=9123")
 	// Check for Unix timestamp of fixed time
@@ -232,3 +518,270 @@ func TestLightListCommand(t *testing.T) {
 	require.Contains(t, outParseable, "serialnumber=\"SN2\"")
 	require.Contains(t, outParseable, "lastseen=1698314700") // Unix timestamp for 2023-10-26 10:05:00 UTC
 }
+
+func TestLightSetManyCommand(t *testing.T) {
+	mock := &mockClient{}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	cmd := newLightSetManyCommand()
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"key-left:on=true,brightness=40", "Key Light\\032Air:temperature=4500,on=off"})
+	require.NoError(t, cmd.Execute())
+
+	require.Len(t, mock.batch, 2)
+	require.Equal(t, "key-left", mock.batch[0].ID)
+	require.NotNil(t, mock.batch[0].On)
+	require.True(t, *mock.batch[0].On)
+	require.NotNil(t, mock.batch[0].Brightness)
+	require.Equal(t, 40, *mock.batch[0].Brightness)
+	require.Nil(t, mock.batch[0].Temperature)
+
+	require.Equal(t, "Key Light Air", mock.batch[1].ID)
+	require.NotNil(t, mock.batch[1].On)
+	require.False(t, *mock.batch[1].On)
+	require.NotNil(t, mock.batch[1].Temperature)
+	require.Equal(t, 4500, *mock.batch[1].Temperature)
+}
+
+func TestParseLightStateUpdate_Color(t *testing.T) {
+	update, err := parseLightStateUpdate("strip:hue=210.5,saturation=80")
+	require.NoError(t, err)
+	require.Equal(t, "strip", update.ID)
+	require.NotNil(t, update.Hue)
+	require.InDelta(t, 210.5, *update.Hue, 0.001)
+	require.NotNil(t, update.Saturation)
+	require.InDelta(t, 80, *update.Saturation, 0.001)
+
+	_, err = parseLightStateUpdate("strip:hue=red")
+	require.Error(t, err)
+}
+
+func TestLightToggleCommand(t *testing.T) {
+	mock := &mockClient{}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	cmd := newLightToggleCommand()
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"Key Light\\032Air"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, []string{"Key Light Air"}, mock.toggled)
+
+	cmd = newLightToggleCommand()
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{})
+	require.Error(t, cmd.Execute())
+}
+
+func TestLightRenameCommand(t *testing.T) {
+	mock := &mockClient{}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	cmd := newLightRenameCommand()
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"Key Light\\032Air", "Desk Left"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "Desk Left", mock.renamed["Key Light Air"])
+
+	cmd = newLightRenameCommand()
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"light1", "--reset"})
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, mock.renamed, "light1")
+	require.Empty(t, mock.renamed["light1"])
+
+	cmd = newLightRenameCommand()
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"light2", "Key Light Left", "--device"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "Key Light Left", mock.device["light2"])
+	require.NotContains(t, mock.renamed, "light2")
+
+	for _, args := range [][]string{
+		{"light1"},
+		{"light1", "  "},
+		{"light1", "name", "--reset"},
+		{"light1", "--reset", "--device"},
+	} {
+		cmd = newLightRenameCommand()
+		cmd.SetContext(ctx)
+		cmd.SetArgs(args)
+		require.Error(t, cmd.Execute(), args)
+	}
+}
+
+func TestLightTagCommand(t *testing.T) {
+	mock := &mockClient{}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+	run := func(args ...string) error {
+		cmd := newLightTagCommand()
+		cmd.SetContext(ctx)
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	require.NoError(t, run("light1", "location=desk", "side=left"))
+	require.Equal(t, map[string]string{"location": "desk", "side": "left"}, mock.tags["light1"])
+
+	require.NoError(t, run("light1", "side=right", "--remove", "location"))
+	require.Equal(t, map[string]string{"side": "right"}, mock.tags["light1"])
+
+	require.NoError(t, run("light1", "--clear"))
+	require.NotContains(t, mock.tags, "light1")
+
+	require.Error(t, run("light1", "location"))
+	require.Error(t, run("light1", "=desk"))
+}
+
+func TestLightRawCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{}
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"light", "raw", "light1", "get", "/elgato/lights/settings"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Equal(t, "light1 GET /elgato/lights/settings ", mock.raw[0])
+	require.Contains(t, out, `"powerOnBehavior": 1`)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"light", "raw", "light1", "PUT", "/elgato/lights/settings", `{"powerOnBehavior":2}`, "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Equal(t, `light1 PUT /elgato/lights/settings {"powerOnBehavior":2}`, mock.raw[1])
+	var resp client.RawResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &resp))
+	require.Equal(t, 200, resp.StatusCode)
+
+	cmd := newTestRootCommand(mock)
+	cmd.SetArgs([]string{"light", "raw", "light1", "GET", "/elgato/battery-info"})
+	require.ErrorContains(t, cmd.Execute(), "status 404")
+
+	cmd = newTestRootCommand(mock)
+	cmd.SetArgs([]string{"light", "raw", "light1", "PUT", "/elgato/lights/settings", "{"})
+	require.ErrorContains(t, cmd.Execute(), "valid JSON")
+	require.Len(t, mock.raw, 3)
+}
+
+func TestLightSettingsCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{}
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"light", "settings", "light1", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Nil(t, mock.settings, "no flags should only read the settings")
+	require.Contains(t, out, `power_on_behavior="restore"`)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"light", "settings", "light1", "--power-on", "default", "--brightness", "40", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.NotNil(t, mock.settings)
+	require.Equal(t, "default", *mock.settings.PowerOnBehavior)
+	require.Equal(t, 40, *mock.settings.PowerOnBrightness)
+	require.Nil(t, mock.settings.PowerOnTemperature)
+	var settings client.LightSettings
+	require.NoError(t, json.Unmarshal([]byte(out), &settings))
+	require.Equal(t, client.LightSettings{PowerOnBehavior: "default", PowerOnBrightness: 40, PowerOnTemperature: 4700}, settings)
+}
+
+func TestLightPendingCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"light", "pending", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, `id="Elgato Key Light Neo 1A2B"`)
+	require.Contains(t, out, `productname="Elgato Key Light Neo"`)
+	require.Contains(t, out, "lastseen=1767268800")
+}
+
+func TestLightAdoptCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{}
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"light", "adopt", "Elgato Key Light Neo\\0321A2B", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Equal(t, []string{"Elgato Key Light Neo 1A2B"}, mock.adopted)
+	var light LightJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &light))
+	require.Equal(t, "Elgato Key Light Neo 1A2B", light.ID)
+}
+
+func TestLightStatsCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{}
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"light", "stats", "light1", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, "on_seconds=3600")
+	require.Contains(t, out, "toggles=4")
+	require.Contains(t, out, "last_toggle=1767268800")
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"light", "stats", "light1", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	var stats client.LightStats
+	require.NoError(t, json.Unmarshal([]byte(out), &stats))
+	require.Equal(t, time.Hour, stats.OnTime())
+
+	cmd := newTestRootCommand(mock)
+	cmd.SetArgs([]string{"light", "stats", "missing"})
+	require.Error(t, cmd.Execute())
+}
+
+func TestParseLightStateUpdate_Invalid(t *testing.T) {
+	for _, arg := range []string{
+		"no-assignment",
+		"=on",
+		"light:on",
+		"light:on=maybe",
+		"light:brightness=lots",
+		"light:colour=red",
+	} {
+		_, err := parseLightStateUpdate(arg)
+		require.Error(t, err, arg)
+	}
+}
+
+func (m *mockClient) GetLogLevel() (string, error) { return m.logLevel, nil }
+
+func (m *mockClient) SetLogLevel(level string) error {
+	m.logLevel = level
+	return nil
+}
+
+func (m *mockClient) ListLogFilters() ([]map[string]any, error) { return m.filters, nil }
+
+func (m *mockClient) AddLogFilter(filter map[string]any) error {
+	m.filters = append(m.filters, filter)
+	return nil
+}
+
+func (m *mockClient) RemoveLogFilter(filterType, pattern string) error {
+	for i, f := range m.filters {
+		if f["type"] == filterType && f["pattern"] == pattern {
+			m.filters = append(m.filters[:i], m.filters[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (m *mockClient) GetLogs(q client.LogQuery) ([]client.LogEntry, error) {
+	m.logQuery = q
+	return m.logs, nil
+}
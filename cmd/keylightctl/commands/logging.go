@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// NewLoggingCommand creates the logging command group.
+func NewLoggingCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logging",
+		Short: "Manage the daemon's log level and log filters at runtime",
+		Long: "Manage the daemon's log level and log filters at runtime.\n" +
+			"Changes take effect immediately and last until the daemon restarts.",
+	}
+
+	cmd.AddCommand(
+		newLoggingGetLevelCommand(logger),
+		newLoggingSetLevelCommand(logger),
+		newLoggingFiltersCommand(logger),
+		newLoggingAddFilterCommand(logger),
+		newLoggingRemoveFilterCommand(logger),
+	)
+
+	return cmd
+}
+
+func newLoggingGetLevelCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-level",
+		Short: "Show the daemon's log level",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			level, err := apiClient.GetLogLevel()
+			if err != nil {
+				return fmt.Errorf("failed to get log level: %w", err)
+			}
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"level", level})
+			}
+			fmt.Println(level)
+			return nil
+		},
+	}
+}
+
+func newLoggingSetLevelCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:       "set-level <level>",
+		Short:     "Change the daemon's log level",
+		Example:   "  keylightctl logging set-level debug",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"debug", "info", "warn", "error"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			if err := apiClient.SetLogLevel(args[0]); err != nil {
+				return fmt.Errorf("failed to set log level: %w", err)
+			}
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"level", args[0]})
+			}
+			pterm.Success.Printf("Log level set to %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newLoggingFiltersCommand(_ *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "filters",
+		Short: "List the daemon's log filters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			filters, err := apiClient.ListLogFilters()
+			if err != nil {
+				return fmt.Errorf("failed to list log filters: %w", err)
+			}
+
+			format := outputFormat(cmd)
+			if format == OutputJSON {
+				if filters == nil {
+					filters = []map[string]any{}
+				}
+				return printJSON(filters)
+			}
+
+			if len(filters) == 0 {
+				if format == OutputTable {
+					pterm.Info.Println("No log filters configured.")
+				}
+				return nil
+			}
+
+			if format == OutputParseable {
+				for _, f := range filters {
+					filterType, _ := f["type"].(string)
+					pattern, _ := f["pattern"].(string)
+					level, _ := f["level"].(string)
+					outputLevel, _ := f["output_level"].(string)
+					enabled, _ := f["enabled"].(bool)
+					expiresAt, _ := f["expires_at"].(string)
+					fmt.Printf("type=%s pattern=%s level=%s output_level=%s enabled=%t expires_at=%s\n",
+						strconv.Quote(filterType), strconv.Quote(pattern), level, outputLevel, enabled, expiresAt)
+				}
+				return nil
+			}
+
+			table := pterm.TableData{{"Type", "Pattern", "Level", "Output Level", "Enabled", "Expires"}}
+			for _, f := range filters {
+				filterType, _ := f["type"].(string)
+				pattern, _ := f["pattern"].(string)
+				level, _ := f["level"].(string)
+				outputLevel, _ := f["output_level"].(string)
+				enabled, _ := f["enabled"].(bool)
+				expiresAt, _ := f["expires_at"].(string)
+				expires, _ := time.Parse(time.RFC3339Nano, expiresAt)
+				table = append(table, []string{
+					filterType,
+					pattern,
+					level,
+					outputLevel,
+					strconv.FormatBool(enabled),
+					formatTimeForDisplay(expires),
+				})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format, same as --output parseable")
+	return cmd
+}
+
+func newLoggingAddFilterCommand(_ *slog.Logger) *cobra.Command {
+	var (
+		outputLevel string
+		expires     time.Duration
+		disabled    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "add-filter <type> <pattern> <level>",
+		Short: "Add a log filter",
+		Long: "Add a log filter. The type is source:file, source:function, context:<key>, or a\n" +
+			"plain attribute key; the pattern is a glob (exact, prefix*, *suffix or *contains*).\n" +
+			"A filter with the same type and pattern as an existing one replaces it.",
+		Example: "  keylightctl logging add-filter source:file \"internal/server/*\" debug --expires 30m",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			filter := map[string]any{
+				"type":    args[0],
+				"pattern": args[1],
+				"level":   args[2],
+				"enabled": !disabled,
+			}
+			if outputLevel != "" {
+				filter["output_level"] = outputLevel
+			}
+			if expires > 0 {
+				filter["expires_at"] = time.Now().Add(expires).UTC().Format(time.RFC3339Nano)
+			}
+
+			if err := apiClient.AddLogFilter(filter); err != nil {
+				return fmt.Errorf("failed to add log filter: %w", err)
+			}
+			switch format := outputFormat(cmd); format {
+			case OutputJSON:
+				return printJSON(filter)
+			case OutputParseable:
+				return printResult(format, resultField{"type", args[0]}, resultField{"pattern", args[1]}, resultField{"level", args[2]})
+			}
+			pterm.Success.Printf("Log filter %s=%s added at level %s\n", args[0], args[1], args[2])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&outputLevel, "output-level", "", "Level to emit matching records at")
+	cmd.Flags().DurationVar(&expires, "expires", 0, "Stop applying the filter after this duration (e.g. 30m)")
+	cmd.Flags().BoolVar(&disabled, "disabled", false, "Add the filter disabled")
+	return cmd
+}
+
+func newLoggingRemoveFilterCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove-filter <type> <pattern>",
+		Short: "Remove a log filter",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			if err := apiClient.RemoveLogFilter(args[0], args[1]); err != nil {
+				return fmt.Errorf("failed to remove log filter: %w", err)
+			}
+			if format := outputFormat(cmd); format != OutputTable {
+				return printResult(format, resultField{"type", args[0]}, resultField{"pattern", args[1]})
+			}
+			pterm.Success.Printf("Log filter %s=%s removed\n", args[0], args[1])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingSetLevelCommand(t *testing.T) {
+	mock := &mockClient{logLevel: "info"}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	cmd := newLoggingSetLevelCommand(slog.New(slog.DiscardHandler))
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"debug"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "debug", mock.logLevel)
+
+	cmd = newLoggingGetLevelCommand(slog.New(slog.DiscardHandler))
+	cmd.SetContext(ctx)
+	out := captureStdout(func() {
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, "debug")
+}
+
+func TestLoggingFilterCommands(t *testing.T) {
+	mock := &mockClient{}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+	logger := slog.New(slog.DiscardHandler)
+
+	cmd := newLoggingAddFilterCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"source:file", "internal/server/*", "debug", "--expires", "30m"})
+	require.NoError(t, cmd.Execute())
+	require.Len(t, mock.filters, 1)
+	require.Equal(t, "source:file", mock.filters[0]["type"])
+	require.Equal(t, "internal/server/*", mock.filters[0]["pattern"])
+	require.Equal(t, "debug", mock.filters[0]["level"])
+	require.Equal(t, true, mock.filters[0]["enabled"])
+	require.NotEmpty(t, mock.filters[0]["expires_at"])
+
+	cmd = newLoggingFiltersCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"--parseable"})
+	out := captureStdout(func() {
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, `type="source:file" pattern="internal/server/*" level=debug`)
+
+	cmd = newLoggingRemoveFilterCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"source:file", "internal/server/*"})
+	require.NoError(t, cmd.Execute())
+	require.Empty(t, mock.filters)
+
+	cmd = newLoggingRemoveFilterCommand(logger)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"source:file", "internal/server/*"})
+	require.Error(t, cmd.Execute())
+}
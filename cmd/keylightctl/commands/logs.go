@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// NewLogsCommand creates the logs command.
+func NewLogsCommand(_ *slog.Logger) *cobra.Command {
+	var (
+		q      client.LogQuery
+		follow bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the daemon's recent log lines",
+		Long: "Show the most recent lines of the daemon's log, which it keeps in memory\n" +
+			"(config.logging.buffer_size lines), without needing access to journald or\n" +
+			"the service's log file.\n\n" +
+			"Lines are printed as the daemon wrote them. With --output json, each line\n" +
+			"is printed as an object with its time, level and message; when following,\n" +
+			"one object per line.\n\n" +
+			"--follow keeps printing new lines as they are logged until interrupted, and\n" +
+			"needs a connection to the daemon's socket.",
+		Example: "  keylightctl logs\n" +
+			"  keylightctl logs --level warn --since 1h\n" +
+			"  keylightctl logs -n 20 --follow",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			format := outputFormat(cmd)
+
+			if !follow {
+				entries, err := apiClient.GetLogs(q)
+				if err != nil {
+					return fmt.Errorf("failed to get logs: %w", err)
+				}
+				if format == OutputJSON {
+					if entries == nil {
+						entries = []client.LogEntry{}
+					}
+					return printJSON(entries)
+				}
+				for _, e := range entries {
+					fmt.Println(e.Line)
+				}
+				return nil
+			}
+
+			follower, ok := apiClient.(client.LogFollower)
+			if !ok {
+				return errors.New("--follow requires a connection to the daemon's socket")
+			}
+			entries, err := follower.FollowLogs(cmd.Context(), q)
+			if err != nil {
+				return fmt.Errorf("failed to follow logs: %w", err)
+			}
+			for e := range entries {
+				if format == OutputJSON {
+					data, err := json.Marshal(e)
+					if err != nil {
+						return fmt.Errorf("failed to marshal JSON: %w", err)
+					}
+					fmt.Println(string(data))
+					continue
+				}
+				fmt.Println(e.Line)
+			}
+			if cmd.Context().Err() == nil {
+				return errors.New("lost the connection to the daemon")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&q.Level, "level", "l", "", "Only show lines at or above this level (debug, info, warn, error)")
+	cmd.Flags().StringVar(&q.Since, "since", "", "Only show lines since this RFC 3339 time, or this long ago, such as 10m")
+	cmd.Flags().IntVarP(&q.Limit, "lines", "n", 0, "Only show this many of the most recent lines; 0 for all kept")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new lines as they are logged")
+	_ = cmd.RegisterFlagCompletionFunc("level", cobra.FixedCompletions([]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
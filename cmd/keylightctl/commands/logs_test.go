@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+var testLogs = []client.LogEntry{
+	{Time: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), Level: "info", Message: "Starting keylightd", Line: `level=INFO msg="Starting keylightd"`},
+	{Time: time.Date(2026, 10, 16, 9, 0, 1, 0, time.UTC), Level: "warn", Message: "Light offline", Line: `level=WARN msg="Light offline"`},
+}
+
+func TestLogsCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{logs: testLogs}
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(mock)
+		cmd.SetArgs([]string{"logs", "--level", "warn", "--since", "10m", "-n", "50"})
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Equal(t, testLogs[0].Line+"\n"+testLogs[1].Line+"\n", out)
+	assert.Equal(t, client.LogQuery{Level: "warn", Since: "10m", Limit: 50}, mock.logQuery)
+}
+
+func TestLogsCommand_JSON(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"logs", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Equal(t, "[]", strings.TrimSpace(out), "no lines is an empty list")
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{logs: testLogs})
+		cmd.SetArgs([]string{"logs", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	var entries []client.LogEntry
+	require.NoError(t, json.Unmarshal([]byte(out), &entries))
+	assert.Equal(t, testLogs, entries)
+}
+
+// followingClient streams its logs to followers, as the socket client does.
+type followingClient struct {
+	*mockClient
+}
+
+func (c followingClient) FollowLogs(_ context.Context, q client.LogQuery) (<-chan client.LogEntry, error) {
+	c.logQuery = q
+	ch := make(chan client.LogEntry, len(c.logs))
+	for _, e := range c.logs {
+		ch <- e
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestLogsCommand_Follow(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+	mock := &mockClient{logs: testLogs}
+	var err error
+	out := captureStdout(func() {
+		cmd := NewRootCommand(nil, "dev", "unknown", "unknown")
+		cmd.SetContext(context.WithValue(context.Background(), clientContextKey, client.ClientInterface(followingClient{mock})))
+		cmd.SetArgs([]string{"logs", "--follow", "--level", "info", "--output", "json"})
+		err = cmd.Execute()
+	})
+	assert.EqualError(t, err, "lost the connection to the daemon")
+	assert.Equal(t, client.LogQuery{Level: "info"}, mock.logQuery)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2, "one JSON object per line")
+	var entry client.LogEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, testLogs[1], entry)
+}
+
+func TestLogsCommand_FollowNeedsSocket(t *testing.T) {
+	cmd := newTestRootCommand(&mockClient{})
+	cmd.SetArgs([]string{"logs", "--follow"})
+	assert.EqualError(t, cmd.Execute(), "--follow requires a connection to the daemon's socket")
+}
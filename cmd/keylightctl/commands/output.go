@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats, selected with --output or the KEYLIGHT_OUTPUT environment variable
+const (
+	OutputTable     = "table"
+	OutputJSON      = "json"
+	OutputParseable = "parseable"
+)
+
+// OutputEnvVar is the environment variable that sets the output format when
+// --output isn't given.
+const OutputEnvVar = "KEYLIGHT_OUTPUT"
+
+// outputFormats lists the valid output formats
+var outputFormats = []string{OutputTable, OutputJSON, OutputParseable}
+
+// outputFormat returns the output format for a command. A command's own --json
+// or --parseable flag takes precedence over --output, which takes precedence
+// over KEYLIGHT_OUTPUT.
+func outputFormat(cmd *cobra.Command) string {
+	if set, _ := cmd.Flags().GetBool("json"); set {
+		return OutputJSON
+	}
+	if set, _ := cmd.Flags().GetBool("parseable"); set {
+		return OutputParseable
+	}
+	if flag := cmd.Flag("output"); flag != nil && flag.Changed {
+		return strings.ToLower(flag.Value.String())
+	}
+	if env := os.Getenv(OutputEnvVar); env != "" {
+		return strings.ToLower(env)
+	}
+	return OutputTable
+}
+
+// validateOutputFormat returns an error if format isn't a known output format
+func validateOutputFormat(format string) error {
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("invalid output format %q: must be one of %s", format, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v any) error {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(jsonBytes))
+	return nil
+}
+
+// resultField is a named value in the result of a command
+type resultField struct {
+	Key   string
+	Value any
+}
+
+// printResult prints the result of a command as a JSON object or a line of
+// key=value pairs. Commands print their own messages in table format.
+func printResult(format string, fields ...resultField) error {
+	if format == OutputJSON {
+		return printJSON(resultObject(fields))
+	}
+	fmt.Println(parseableLine(fields))
+	return nil
+}
+
+// printResults prints several results as a JSON array or one line of
+// key=value pairs per result.
+func printResults(format string, results [][]resultField) error {
+	if format == OutputJSON {
+		objects := make([]map[string]any, 0, len(results))
+		for _, fields := range results {
+			objects = append(objects, resultObject(fields))
+		}
+		return printJSON(objects)
+	}
+	for _, fields := range results {
+		fmt.Println(parseableLine(fields))
+	}
+	return nil
+}
+
+func resultObject(fields []resultField) map[string]any {
+	object := make(map[string]any, len(fields))
+	for _, field := range fields {
+		object[field.Key] = field.Value
+	}
+	return object
+}
+
+// parseableLine formats fields as key=value pairs, quoting strings
+func parseableLine(fields []resultField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		var value string
+		switch v := field.Value.(type) {
+		case string:
+			value = strconv.Quote(v)
+		case []string:
+			value = strconv.Quote(strings.Join(v, ","))
+		default:
+			value = fmt.Sprint(v)
+		}
+		parts = append(parts, field.Key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
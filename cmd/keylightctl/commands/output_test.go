@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRootCommand returns a root command whose context carries mock.
+func newTestRootCommand(mock *mockClient) *cobra.Command {
+	cmd := NewRootCommand(nil, "dev", "unknown", "unknown")
+	cmd.SetContext(context.WithValue(context.Background(), clientContextKey, mock))
+	return cmd
+}
+
+func TestOutputFlag(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"--output", "json", "light", "toggle", "light1"})
+		require.NoError(t, cmd.Execute())
+	})
+	var result map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Equal(t, map[string]any{"id": "light1", "on": true}, result)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"-o", "parseable", "light", "toggle", "light1"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Equal(t, "id=\"light1\" on=true\n", out)
+
+	cmd := newTestRootCommand(&mockClient{})
+	cmd.SetArgs([]string{"--output", "yaml", "light", "toggle", "light1"})
+	cmd.SilenceUsage = true
+	require.ErrorContains(t, cmd.Execute(), "invalid output format")
+}
+
+func TestOutputEnv(t *testing.T) {
+	t.Setenv(OutputEnvVar, "json")
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"light", "list"})
+		require.NoError(t, cmd.Execute())
+	})
+	var lights []LightJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &lights))
+	require.Len(t, lights, 2)
+	require.Equal(t, "light1", lights[0].ID)
+	require.Equal(t, 5000, lights[0].TemperatureK)
+
+	// A command's own flag wins over the environment
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"light", "list", "--parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, "id=\"light1\"")
+}
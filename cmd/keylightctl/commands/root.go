@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
@@ -18,18 +19,52 @@ func NewRootCommand(logger *slog.Logger, version, commit, buildDate string) *cob
 	cmd := &cobra.Command{
 		Use:   "keylightctl",
 		Short: "Control Key Lights",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(outputFormat(cmd)); err != nil {
+				return err
+			}
+			// --socket, --api-url and contexts replace the daemon found at startup
+			c, err := selectClient(cmd, cmp.Or(logger, slog.Default()))
+			if err != nil {
+				return err
+			}
+			if c != nil {
+				cmd.SetContext(context.WithValue(cmd.Context(), ClientContextKey, c))
+			}
+			return nil
+		},
 	}
 
 	// Add global flags
 	cmd.PersistentFlags().String("socket", "", "Path to keylightd socket")
+	cmd.PersistentFlags().String("api-url", "", "Base URL of a daemon's HTTP API to use instead of the socket")
+	cmd.PersistentFlags().String("api-key", "", "API key for --api-url or an HTTP context; defaults to $"+APIKeyEnvVar+" if set")
+	cmd.PersistentFlags().String("context", "", "Context to use (see keylightctl context); defaults to $"+ContextEnvVar+" or the current context")
+	_ = cmd.RegisterFlagCompletionFunc("context", completeContexts)
 	cmd.PersistentFlags().String("log-level", "info", "Log level (debug, info, warn, error)")
 	cmd.PersistentFlags().String("log-format", "text", "Log format (text, json)")
+	cmd.PersistentFlags().StringP("output", "o", OutputTable, "Output format (table, json, parseable); defaults to $"+OutputEnvVar+" if set")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp))
 
 	// Add commands
 	cmd.AddCommand(newVersionCommand(version, commit, buildDate))
+	cmd.AddCommand(NewStatusCommand(logger))
+	cmd.AddCommand(NewWaybarCommand(logger))
 	cmd.AddCommand(NewLightCommand(logger))
+	cmd.AddCommand(NewDiscoverCommand(logger))
 	cmd.AddCommand(NewGroupCommand(logger))
 	cmd.AddCommand(NewAPIKeyCommand(logger))
+	cmd.AddCommand(NewScheduleCommand(logger))
+	cmd.AddCommand(NewSceneCommand(logger))
+	cmd.AddCommand(NewJobCommand(logger))
+	cmd.AddCommand(NewCircadianCommand(logger))
+	cmd.AddCommand(NewDesiredStateCommand(logger))
+	cmd.AddCommand(NewLoggingCommand(logger))
+	cmd.AddCommand(NewLogsCommand(logger))
+	cmd.AddCommand(NewConfigCommand(logger))
+	cmd.AddCommand(NewInitCommand(logger))
+	cmd.AddCommand(NewContextCommand(logger))
+	cmd.AddCommand(NewDebugCommand(logger, version, commit, buildDate))
 
 	if logger != nil {
 		parent := cmd.Context()
@@ -47,30 +82,54 @@ func newVersionCommand(version, commit, buildDate string) *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Try to query the daemon for its version
+			var daemon *client.DaemonInfo
+			if c, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface); ok {
+				if info, err := daemonInfo(c); err == nil {
+					daemon = info
+				}
+			}
+
+			if format := outputFormat(cmd); format != OutputTable {
+				fields := []resultField{
+					{"client_version", version},
+					{"client_commit", commit},
+					{"client_build_date", buildDate},
+				}
+				if daemon != nil {
+					fields = append(fields,
+						resultField{"daemon_version", daemon.Version},
+						resultField{"daemon_commit", daemon.Commit},
+						resultField{"daemon_build_date", daemon.BuildDate},
+					)
+					if !daemon.StartedAt.IsZero() {
+						fields = append(fields, resultField{"daemon_uptime", daemon.UptimeSeconds})
+					}
+				}
+				return printResult(format, fields...)
+			}
+
 			fmt.Printf("Client:\n")
 			fmt.Printf("  Version:    %s\n", version)
 			fmt.Printf("  Commit:     %s\n", commit)
 			fmt.Printf("  Build Date: %s\n", buildDate)
 
-			// Try to query the daemon for its version
-			if c, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface); ok {
-				resp, err := c.GetVersion()
-				if err == nil {
-					fmt.Printf("\nDaemon:\n")
-					if v, ok := resp["version"].(string); ok {
-						fmt.Printf("  Version:    %s\n", v)
-					}
-					if c, ok := resp["commit"].(string); ok {
-						fmt.Printf("  Commit:     %s\n", c)
-					}
-					if d, ok := resp["build_date"].(string); ok {
-						fmt.Printf("  Build Date: %s\n", d)
-					}
-				} else {
-					fmt.Printf("\nDaemon: not reachable\n")
-				}
+			if daemon == nil {
+				fmt.Printf("\nDaemon: not reachable\n")
+				return nil
+			}
+			fmt.Printf("\nDaemon:\n")
+			fmt.Printf("  Version:    %s\n", daemon.Version)
+			fmt.Printf("  Commit:     %s\n", daemon.Commit)
+			fmt.Printf("  Build Date: %s\n", daemon.BuildDate)
+			// Daemons without get_daemon_info only report their version
+			if !daemon.StartedAt.IsZero() {
+				fmt.Printf("  Uptime:     %s\n", daemon.Uptime())
+				fmt.Printf("  Lights:     %d\n", daemon.Lights)
+				fmt.Printf("  Groups:     %d\n", daemon.Groups)
 			}
+			return nil
 		},
 	}
 }
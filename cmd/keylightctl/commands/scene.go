@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// NewSceneCommand creates the scene command group.
+func NewSceneCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "scene",
+		Short:   "Manage and apply scenes",
+		Aliases: []string{"scenes"},
+	}
+
+	cmd.AddCommand(
+		newSceneListCommand(logger),
+		newSceneAddCommand(logger),
+		newSceneApplyCommand(logger),
+		newSceneStatusCommand(logger),
+		newSceneCancelCommand(logger),
+		newSceneDeleteCommand(logger),
+	)
+
+	return cmd
+}
+
+// parseSceneEntry parses an --entry value: comma separated key=value pairs
+// naming a light or group and the state to give it, e.g.
+// "light=ID,on=true,brightness=60,transition=2s,order=1,delay=500ms".
+func parseSceneEntry(spec string) (client.SceneEntry, error) {
+	var entry client.SceneEntry
+	for part := range strings.SplitSeq(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return entry, fmt.Errorf("invalid entry field %q, expected key=value", part)
+		}
+		var err error
+		switch key {
+		case "light", "group":
+			if entry.Target.Type != "" {
+				return entry, errors.New("entry must name exactly one light or group")
+			}
+			entry.Target = client.SceneTarget{Type: key, ID: value}
+		case "on":
+			var on bool
+			on, err = strconv.ParseBool(value)
+			entry.On = &on
+		case "brightness":
+			var brightness int
+			brightness, err = strconv.Atoi(value)
+			entry.Brightness = &brightness
+		case "temperature":
+			var temperature int
+			temperature, err = strconv.Atoi(strings.TrimSuffix(value, "K"))
+			entry.Temperature = &temperature
+		case "transition", "delay":
+			var d time.Duration
+			if d, err = time.ParseDuration(value); err == nil {
+				if key == "transition" {
+					entry.TransitionMS = int(d.Milliseconds())
+				} else {
+					entry.DelayMS = int(d.Milliseconds())
+				}
+			}
+		case "order":
+			entry.Order, err = strconv.Atoi(value)
+		default:
+			return entry, fmt.Errorf("unknown entry field %q", key)
+		}
+		if err != nil {
+			return entry, fmt.Errorf("invalid %s %q: %w", key, value, err)
+		}
+	}
+	if entry.Target.Type == "" {
+		return entry, errors.New("entry must name a light or group")
+	}
+	return entry, nil
+}
+
+// sceneEntryString returns an entry in the --entry format.
+func sceneEntryString(e client.SceneEntry) string {
+	parts := []string{e.Target.Type + "=" + e.Target.ID}
+	if e.On != nil {
+		parts = append(parts, "on="+strconv.FormatBool(*e.On))
+	}
+	if e.Brightness != nil {
+		parts = append(parts, "brightness="+strconv.Itoa(*e.Brightness))
+	}
+	if e.Temperature != nil {
+		parts = append(parts, "temperature="+strconv.Itoa(*e.Temperature))
+	}
+	if e.TransitionMS > 0 {
+		parts = append(parts, "transition="+(time.Duration(e.TransitionMS)*time.Millisecond).String())
+	}
+	if e.Order != 0 {
+		parts = append(parts, "order="+strconv.Itoa(e.Order))
+	}
+	if e.DelayMS > 0 {
+		parts = append(parts, "delay="+(time.Duration(e.DelayMS)*time.Millisecond).String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func newSceneListCommand(_ *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all scenes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			scenes, err := apiClient.ListScenes()
+			if err != nil {
+				return fmt.Errorf("failed to list scenes: %w", err)
+			}
+
+			format := outputFormat(cmd)
+			if format == OutputJSON {
+				return printJSON(scenes)
+			}
+
+			if len(scenes) == 0 {
+				if format == OutputTable {
+					pterm.Info.Println("No scenes found.")
+				}
+				return nil
+			}
+
+			if format == OutputParseable {
+				for _, s := range scenes {
+					entries := make([]string, len(s.Entries))
+					for i, e := range s.Entries {
+						entries[i] = sceneEntryString(e)
+					}
+					fmt.Println(parseableLine([]resultField{
+						{"id", s.ID},
+						{"name", s.Name},
+						{"entries", strings.Join(entries, ";")},
+					}))
+				}
+				return nil
+			}
+
+			table := pterm.TableData{{"ID", "Name", "Order", "Entry"}}
+			for _, s := range scenes {
+				for i, e := range s.Entries {
+					id, name := "", ""
+					if i == 0 {
+						id, name = s.ID, s.Name
+					}
+					table = append(table, []string{id, name, strconv.Itoa(e.Order), sceneEntryString(e)})
+				}
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format, same as --output parseable")
+	return cmd
+}
+
+func newSceneAddCommand(_ *slog.Logger) *cobra.Command {
+	var (
+		name    string
+		entries []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add a scene",
+		Long: "Add a scene that sets the state of lights and groups together.\n" +
+			"Each --entry names one light or group and the state to give it, as comma separated\n" +
+			"key=value pairs: light or group, on, brightness, temperature (Kelvin), transition,\n" +
+			"order and delay. Entries are applied in steps, lowest order first; a step starts\n" +
+			"once the transitions of the one before it have finished, and delay holds an entry\n" +
+			"back from the start of its step.",
+		Example: "  keylightctl scene add \"Streaming start\" \\\n" +
+			"    --entry \"light=Key Light,on=true,brightness=70,transition=3s\" \\\n" +
+			"    --entry \"group=fill,on=true,brightness=40,order=1,delay=500ms\"",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if name == "" {
+				return errors.New("scene name is required")
+			}
+			if len(entries) == 0 {
+				return errors.New("at least one --entry is required")
+			}
+			s := client.Scene{Name: name}
+			for _, spec := range entries {
+				entry, err := parseSceneEntry(spec)
+				if err != nil {
+					return fmt.Errorf("invalid --entry %q: %w", spec, err)
+				}
+				s.Entries = append(s.Entries, entry)
+			}
+
+			format := outputFormat(cmd)
+			created, err := apiClient.CreateScene(s)
+			if err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to add scene: %w", err)
+				}
+				PrintPromptResult("error", "Failed to Add Scene", "", [][2]string{{"Name", name}, {"Error", err.Error()}})
+				return nil
+			}
+
+			switch format {
+			case OutputJSON:
+				return printJSON(created)
+			case OutputParseable:
+				return printResult(format, resultField{"id", created.ID}, resultField{"name", created.Name})
+			}
+
+			fields := [][2]string{{"ID", created.ID}, {"Name", created.Name}}
+			for _, e := range created.Entries {
+				fields = append(fields, [2]string{"Entry", sceneEntryString(e)})
+			}
+			PrintPromptResult("success", "Scene Created", "", fields)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name for the scene (overridden by positional argument)")
+	cmd.Flags().StringArrayVarP(&entries, "entry", "e", nil, "Light or group state, e.g. \"light=ID,on=true,brightness=60,transition=2s\" (repeatable)")
+	return cmd
+}
+
+// sceneRunCommand creates a command that takes a scene ID or name and
+// prints the job returned by do, waiting for it to finish if wait is set.
+func sceneRunCommand(use, short, title string, do func(client.ClientInterface, string) (*client.Job, error), wait *bool) *cobra.Command {
+	return &cobra.Command{
+		Use:               use + " <scene>",
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeScene,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			job, err := do(apiClient, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to %s scene %s: %w", use, args[0], err)
+			}
+			if wait != nil && *wait {
+				return waitForJob(cmd, apiClient, title, job)
+			}
+			return printJob(cmd, title, job)
+		},
+	}
+}
+
+func newSceneApplyCommand(_ *slog.Logger) *cobra.Command {
+	var wait bool
+	cmd := sceneRunCommand("apply", "Start applying a scene, by ID or name", "Scene Applied", client.ClientInterface.ApplyScene, &wait)
+	cmd.Long = "Start applying a scene, by ID or name. The scene is applied in the background as a\njob; use --wait to wait for it to finish, or follow it with \"keylightctl job get\"."
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait for the scene to finish applying")
+	return cmd
+}
+
+func newSceneStatusCommand(_ *slog.Logger) *cobra.Command {
+	var wait bool
+	cmd := sceneRunCommand("status", "Show the job of a scene's latest run", "Scene Run", client.ClientInterface.GetSceneRun, &wait)
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait for the run to finish")
+	return cmd
+}
+
+func newSceneCancelCommand(_ *slog.Logger) *cobra.Command {
+	return sceneRunCommand("cancel", "Stop applying a scene", "Scene Run Cancelled", client.ClientInterface.CancelSceneRun, nil)
+}
+
+func newSceneDeleteCommand(_ *slog.Logger) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:               "delete <id>",
+		Short:             "Delete a scene",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeScene,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			id := args[0]
+
+			if !yes {
+				confirm, _ := pterm.DefaultInteractiveConfirm.
+					WithDefaultText(fmt.Sprintf("Are you sure you want to delete scene %s?", id)).
+					WithDefaultValue(false).
+					Show()
+				if !confirm {
+					pterm.Info.Println("Scene deletion cancelled.") //nolint:misspell
+					return nil
+				}
+			}
+
+			format := outputFormat(cmd)
+			if err := apiClient.DeleteScene(id); err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to delete scene: %w", err)
+				}
+				PrintPromptResult("error", "Failed to Delete Scene", "", [][2]string{{"ID", id}, {"Error", err.Error()}})
+				return nil
+			}
+
+			if format != OutputTable {
+				return printResult(format, resultField{"id", id})
+			}
+
+			pterm.Success.Printf("Scene %s deleted successfully.\n", id)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	return cmd
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+type mockSceneClient struct {
+	client.ClientInterface
+	created   *client.Scene
+	appliedID string
+	polls     int
+	scenes    []client.Scene
+}
+
+func (m *mockSceneClient) ListScenes() ([]client.Scene, error) {
+	return m.scenes, nil
+}
+
+func (m *mockSceneClient) CreateScene(s client.Scene) (*client.Scene, error) {
+	m.created = &s
+	s.ID = "scene-1"
+	return &s, nil
+}
+
+func (m *mockSceneClient) ApplyScene(id string) (*client.Job, error) {
+	m.appliedID = id
+	return &client.Job{
+		ID:        "job-1",
+		Kind:      "scene.apply",
+		Subject:   "scene-1",
+		Status:    "running",
+		Progress:  map[string]any{"step": 0, "steps": 2, "applied": 0, "entries": 2},
+		StartedAt: time.Now(),
+	}, nil
+}
+
+func (m *mockSceneClient) GetJob(id string) (*client.Job, error) {
+	m.polls++
+	return &client.Job{
+		ID:         id,
+		Kind:       "scene.apply",
+		Subject:    "scene-1",
+		Status:     "failed",
+		Progress:   map[string]any{"step": 2, "steps": 2, "applied": 2, "entries": 2, "errors": []any{"light key: unreachable"}},
+		Error:      "1 of 2 entries could not be applied",
+		StartedAt:  time.Now(),
+		FinishedAt: time.Now(),
+	}, nil
+}
+
+func TestParseSceneEntry(t *testing.T) {
+	entry, err := parseSceneEntry("light=Key Light,on=true,brightness=60,temperature=4000K,transition=2s,order=1,delay=500ms")
+	require.NoError(t, err)
+	require.Equal(t, client.SceneTarget{Type: "light", ID: "Key Light"}, entry.Target)
+	require.True(t, *entry.On)
+	require.Equal(t, 60, *entry.Brightness)
+	require.Equal(t, 4000, *entry.Temperature)
+	require.Equal(t, 2000, entry.TransitionMS)
+	require.Equal(t, 1, entry.Order)
+	require.Equal(t, 500, entry.DelayMS)
+	require.Equal(t, "light=Key Light,on=true,brightness=60,temperature=4000,transition=2s,order=1,delay=500ms", sceneEntryString(entry))
+
+	for _, spec := range []string{
+		"on=true",                   // no target
+		"light=a,group=b,on=true",   // two targets
+		"light=a,brightness=bright", // bad number
+		"light=a,transition=2",      // no unit
+		"light=a,colour=red",        // unknown field
+		"light=a,on",                // no value
+	} {
+		_, err := parseSceneEntry(spec)
+		require.Error(t, err, "spec: %s", spec)
+	}
+}
+
+func TestSceneAddAndApplyCommands(t *testing.T) {
+	mock := &mockSceneClient{}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	cmd := newSceneAddCommand(nil)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"streaming", "--entry", "light=key,on=true,brightness=70,transition=3s", "--entry", "group=fill,on=true,order=1"})
+	captureStdout(func() { require.NoError(t, cmd.Execute()) })
+
+	require.NotNil(t, mock.created)
+	require.Equal(t, "streaming", mock.created.Name)
+	require.Len(t, mock.created.Entries, 2)
+	require.Equal(t, "group", mock.created.Entries[1].Target.Type)
+	require.Equal(t, 3000, mock.created.Entries[0].TransitionMS)
+
+	t.Setenv(OutputEnvVar, OutputParseable)
+	out := captureStdout(func() {
+		cmd := newSceneApplyCommand(nil)
+		cmd.SetContext(ctx)
+		cmd.SetArgs([]string{"streaming"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Equal(t, "streaming", mock.appliedID)
+	require.Contains(t, out, `id="job-1"`)
+	require.Contains(t, out, `status="running"`)
+	require.Contains(t, out, "steps=2")
+	require.Zero(t, mock.polls)
+
+	out = captureStdout(func() {
+		cmd := newSceneApplyCommand(nil)
+		cmd.SetContext(ctx)
+		cmd.SetArgs([]string{"streaming", "--wait"})
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		require.EqualError(t, cmd.Execute(), "job job-1 failed")
+	})
+	require.Equal(t, 1, mock.polls)
+	require.Contains(t, out, `status="failed"`)
+	require.Contains(t, out, `errors="light key: unreachable"`)
+}
+
+func TestSceneListCommand(t *testing.T) {
+	on := true
+	mock := &mockSceneClient{scenes: []client.Scene{{
+		ID:   "scene-1",
+		Name: "streaming",
+		Entries: []client.SceneEntry{
+			{Target: client.SceneTarget{Type: "light", ID: "key"}, On: &on},
+			{Target: client.SceneTarget{Type: "group", ID: "fill"}, On: &on, Order: 1},
+		},
+	}}}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	out := captureStdout(func() {
+		cmd := newSceneListCommand(nil)
+		cmd.SetContext(ctx)
+		cmd.SetArgs([]string{"--parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, `id="scene-1"`)
+	require.Contains(t, out, `entries="light=key,on=true;group=fill,on=true,order=1"`)
+}
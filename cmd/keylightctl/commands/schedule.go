@@ -0,0 +1,447 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// NewScheduleCommand creates the schedule command group.
+func NewScheduleCommand(logger *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "schedule",
+		Short:   "Manage scheduled light and group actions",
+		Aliases: []string{"schedules"},
+	}
+
+	cmd.AddCommand(
+		newScheduleListCommand(logger),
+		newScheduleUpcomingCommand(logger),
+		newScheduleAddCommand(logger),
+		newScheduleDeleteCommand(logger),
+	)
+
+	return cmd
+}
+
+// scheduleWhen returns the human readable trigger of a schedule, with its
+// time zone if it sets one.
+func scheduleWhen(s map[string]any) string {
+	when, _ := s["cron"].(string)
+	if at, _ := s["at"].(string); at != "" {
+		when = "daily " + at
+	}
+	if tz, _ := s["timezone"].(string); tz != "" {
+		when += " " + tz
+	}
+	return when
+}
+
+// scheduleTarget returns a schedule's target as "type:id", or "" for a scene
+// schedule without one.
+func scheduleTarget(s map[string]any) string {
+	target, _ := s["target"].(map[string]any)
+	targetType, _ := target["type"].(string)
+	targetID, _ := target["id"].(string)
+	if targetType == "" && targetID == "" {
+		return ""
+	}
+	return targetType + ":" + targetID
+}
+
+// scheduleAction returns a schedule's action as space separated key=value
+// pairs, or scene=<scene> for a schedule that applies a scene.
+func scheduleAction(s map[string]any) string {
+	if scene, _ := s["scene"].(string); scene != "" {
+		return "scene=" + scene
+	}
+	action, _ := s["action"].(map[string]any)
+	var parts []string
+	for _, key := range []string{"on", "brightness", "temperature"} {
+		if v, ok := action[key]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// scheduleTime parses an RFC3339 timestamp field from a schedule, returning the zero time if absent.
+func scheduleTime(s map[string]any, key string) time.Time {
+	switch v := s[key].(type) {
+	case time.Time:
+		return v
+	case string:
+		t, _ := time.Parse(time.RFC3339, v)
+		return t
+	}
+	return time.Time{}
+}
+
+// scheduleParseable returns the parseable key=value string for a schedule.
+func scheduleParseable(s map[string]any) string {
+	id, _ := s["id"].(string)
+	name, _ := s["name"].(string)
+	enabled, _ := s["enabled"].(bool)
+	nextRun := ""
+	if t := scheduleTime(s, "next_run"); !t.IsZero() {
+		nextRun = strconv.FormatInt(t.Unix(), 10)
+	}
+	return fmt.Sprintf("id=%s name=%s when=%s target=%s action=%s enabled=%t next_run=%s",
+		strconv.Quote(id), strconv.Quote(name), strconv.Quote(scheduleWhen(s)),
+		strconv.Quote(scheduleTarget(s)), strconv.Quote(scheduleAction(s)), enabled, nextRun)
+}
+
+func newScheduleListCommand(_ *slog.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all schedules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			schedules, err := apiClient.ListSchedules()
+			if err != nil {
+				return fmt.Errorf("failed to list schedules: %w", err)
+			}
+
+			format := outputFormat(cmd)
+			if format == OutputJSON {
+				if schedules == nil {
+					schedules = []map[string]any{}
+				}
+				return printJSON(schedules)
+			}
+
+			if len(schedules) == 0 {
+				if format == OutputTable {
+					pterm.Info.Println("No schedules found.")
+				}
+				return nil
+			}
+
+			if format == OutputParseable {
+				for _, s := range schedules {
+					fmt.Println(scheduleParseable(s))
+				}
+				return nil
+			}
+
+			table := pterm.TableData{{"ID", "Name", "When", "Target", "Action", "Enabled", "Next Run"}}
+			for _, s := range schedules {
+				id, _ := s["id"].(string)
+				name, _ := s["name"].(string)
+				enabled, _ := s["enabled"].(bool)
+				table = append(table, []string{
+					id,
+					name,
+					scheduleWhen(s),
+					scheduleTarget(s),
+					scheduleAction(s),
+					strconv.FormatBool(enabled),
+					formatTimeForDisplay(scheduleTime(s, "next_run")),
+				})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format, same as --output parseable")
+	return cmd
+}
+
+func newScheduleUpcomingCommand(_ *slog.Logger) *cobra.Command {
+	var limit, days int
+	cmd := &cobra.Command{
+		Use:   "upcoming",
+		Short: "List when schedules next fire",
+		Long:  "List the next runs of enabled schedules, soonest first. Skip dates are left out.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			upcoming, err := apiClient.ListUpcomingSchedules(limit, days)
+			if err != nil {
+				return fmt.Errorf("failed to list upcoming schedules: %w", err)
+			}
+
+			format := outputFormat(cmd)
+			if format == OutputJSON {
+				if upcoming == nil {
+					upcoming = []client.ScheduleExecution{}
+				}
+				return printJSON(upcoming)
+			}
+
+			if len(upcoming) == 0 {
+				if format == OutputTable {
+					pterm.Info.Println("No upcoming schedule runs.")
+				}
+				return nil
+			}
+
+			if format == OutputParseable {
+				for _, e := range upcoming {
+					s := map[string]any{"target": e.Target, "action": e.Action, "scene": e.Scene}
+					fmt.Printf("at=%d schedule_id=%s name=%s target=%s action=%s\n",
+						e.At.Unix(), strconv.Quote(e.ScheduleID), strconv.Quote(e.Name),
+						strconv.Quote(scheduleTarget(s)), strconv.Quote(scheduleAction(s)))
+				}
+				return nil
+			}
+
+			table := pterm.TableData{{"When", "Schedule", "Name", "Target", "Action"}}
+			for _, e := range upcoming {
+				s := map[string]any{"target": e.Target, "action": e.Action, "scene": e.Scene}
+				table = append(table, []string{
+					formatTimeForDisplay(e.At),
+					e.ScheduleID,
+					e.Name,
+					scheduleTarget(s),
+					scheduleAction(s),
+				})
+			}
+			if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+				return fmt.Errorf("failed to render table: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 0, "Most runs to list (default 10)")
+	cmd.Flags().IntVar(&days, "days", 0, "How many days ahead to look (default 7)")
+	cmd.Flags().BoolP("parseable", "p", false, "Output in parseable format, same as --output parseable")
+	return cmd
+}
+
+func newScheduleAddCommand(_ *slog.Logger) *cobra.Command {
+	var (
+		name        string
+		at          string
+		cron        string
+		lightID     string
+		groupID     string
+		sceneID     string
+		timezone    string
+		skipDates   []string
+		on          bool
+		off         bool
+		brightness  int
+		temperature int
+		disabled    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add a schedule",
+		Long: "Add a schedule that applies a state to a light or group, or applies a scene.\n" +
+			"Use --at HH:MM for a daily time, or --cron for a five-field cron expression (e.g. \"30 8 * * 1-5\").\n" +
+			"With --scene, --light or --group limits the scene to that light or group.",
+		Example: "  keylightctl schedule add morning --group office --at 08:30 --on --brightness 60\n" +
+			"  keylightctl schedule add evening --light \"Elgato Key Light ABC1._elg._tcp.local.\" --cron \"0 18 * * 1-5\" --off\n" +
+			"  keylightctl schedule add studio-evening --scene \"Evening warm\" --group Studio --cron \"0 18 * * 1-5\" --timezone Europe/London --skip 2026-12-25",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if name == "" {
+				return errors.New("schedule name is required")
+			}
+			if (at == "") == (cron == "") {
+				return errors.New("exactly one of --at or --cron is required")
+			}
+			if lightID != "" && groupID != "" {
+				return errors.New("--light and --group are mutually exclusive")
+			}
+			if sceneID == "" && lightID == "" && groupID == "" {
+				return errors.New("one of --light or --group is required without --scene")
+			}
+			if on && off {
+				return errors.New("--on and --off are mutually exclusive")
+			}
+
+			action := map[string]any{}
+			if on || off {
+				action["on"] = on
+			}
+			if cmd.Flags().Changed("brightness") {
+				action["brightness"] = brightness
+			}
+			if cmd.Flags().Changed("temperature") {
+				action["temperature"] = temperature
+			}
+			if sceneID != "" && len(action) > 0 {
+				return errors.New("--scene can't be combined with --on, --off, --brightness or --temperature")
+			}
+			if sceneID == "" && len(action) == 0 {
+				return errors.New("at least one of --on, --off, --brightness, --temperature or --scene is required")
+			}
+
+			schedule := map[string]any{
+				"name":    name,
+				"enabled": !disabled,
+			}
+			switch {
+			case lightID != "":
+				schedule["target"] = map[string]any{"type": "light", "id": lightID}
+			case groupID != "":
+				schedule["target"] = map[string]any{"type": "group", "id": groupID}
+			}
+			if sceneID != "" {
+				schedule["scene"] = sceneID
+			} else {
+				schedule["action"] = action
+			}
+			if at != "" {
+				schedule["at"] = at
+			} else {
+				schedule["cron"] = cron
+			}
+			if timezone != "" {
+				schedule["timezone"] = timezone
+			}
+			if len(skipDates) > 0 {
+				schedule["skip_dates"] = skipDates
+			}
+
+			format := outputFormat(cmd)
+			created, err := apiClient.CreateSchedule(schedule)
+			if err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to add schedule: %w", err)
+				}
+				PrintPromptResult("error", "Failed to Add Schedule", "", [][2]string{{"Name", name}, {"Error", err.Error()}})
+				return nil
+			}
+
+			switch format {
+			case OutputJSON:
+				return printJSON(created)
+			case OutputParseable:
+				fmt.Println(scheduleParseable(created))
+				return nil
+			}
+
+			id, _ := created["id"].(string)
+			PrintPromptResult("success", "Schedule Created", "", [][2]string{
+				{"ID", id},
+				{"Name", name},
+				{"When", scheduleWhen(created)},
+				{"Target", scheduleTarget(created)},
+				{"Action", scheduleAction(created)},
+				{"Next Run", formatTimeForDisplay(scheduleTime(created, "next_run"))},
+			})
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name for the schedule (overridden by positional argument)")
+	cmd.Flags().StringVar(&at, "at", "", "Daily time of day in HH:MM (24h)")
+	cmd.Flags().StringVar(&cron, "cron", "", "Five-field cron expression or @daily/@hourly etc.")
+	cmd.Flags().StringVar(&lightID, "light", "", "ID of the light to control")
+	cmd.Flags().StringVar(&groupID, "group", "", "ID or name of the group(s) to control, comma-separated")
+	cmd.Flags().StringVar(&sceneID, "scene", "", "ID or name of a scene to apply instead of setting a state")
+	cmd.Flags().StringVar(&timezone, "timezone", "", "IANA time zone --at and --cron are in, e.g. Europe/London (default: the daemon's)")
+	cmd.Flags().StringSliceVar(&skipDates, "skip", nil, "Dates (YYYY-MM-DD) not to run on, such as holidays; repeatable or comma-separated")
+	_ = cmd.RegisterFlagCompletionFunc("light", completeLightFlag)
+	_ = cmd.RegisterFlagCompletionFunc("group", completeGroupFlag)
+	_ = cmd.RegisterFlagCompletionFunc("scene", completeSceneFlag)
+	cmd.Flags().BoolVar(&on, "on", false, "Turn the target on")
+	cmd.Flags().BoolVar(&off, "off", false, "Turn the target off")
+	cmd.Flags().IntVar(&brightness, "brightness", 0, "Brightness to set (0-100)")
+	cmd.Flags().IntVar(&temperature, "temperature", 0, "Temperature to set (2900-7000K)")
+	cmd.Flags().BoolVar(&disabled, "disabled", false, "Create the schedule disabled")
+	return cmd
+}
+
+func newScheduleDeleteCommand(_ *slog.Logger) *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:               "delete [id]",
+		Short:             "Delete a schedule",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSchedule,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, ok := cmd.Context().Value(ClientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			var id string
+			if len(args) > 0 {
+				id = args[0]
+			} else {
+				schedules, err := apiClient.ListSchedules()
+				if err != nil {
+					return fmt.Errorf("failed to list schedules for selection: %w", err)
+				}
+				if len(schedules) == 0 {
+					pterm.Info.Println("No schedules found to delete.")
+					return nil
+				}
+
+				options := make([]string, len(schedules))
+				for i, s := range schedules {
+					scheduleID, _ := s["id"].(string)
+					name, _ := s["name"].(string)
+					options[i] = fmt.Sprintf("%s (%s)", scheduleID, name)
+				}
+				selected, err := pterm.DefaultInteractiveSelect.
+					WithDefaultText("Select schedule to delete").
+					WithOptions(options).
+					Show()
+				if err != nil {
+					return fmt.Errorf("schedule selection failed: %w", err)
+				}
+				id = strings.Split(selected, " (")[0]
+			}
+
+			if !yes {
+				confirm, _ := pterm.DefaultInteractiveConfirm.
+					WithDefaultText(fmt.Sprintf("Are you sure you want to delete schedule %s?", id)).
+					WithDefaultValue(false).
+					Show()
+				if !confirm {
+					pterm.Info.Println("Schedule deletion cancelled.") //nolint:misspell
+					return nil
+				}
+			}
+
+			format := outputFormat(cmd)
+			if err := apiClient.DeleteSchedule(id); err != nil {
+				if format != OutputTable {
+					return fmt.Errorf("failed to delete schedule: %w", err)
+				}
+				PrintPromptResult("error", "Failed to Delete Schedule", "", [][2]string{{"ID", id}, {"Error", err.Error()}})
+				return nil
+			}
+
+			if format != OutputTable {
+				return printResult(format, resultField{"id", id})
+			}
+
+			pterm.Success.Printf("Schedule %s deleted successfully.\n", id)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	return cmd
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+type mockScheduleClient struct {
+	client.ClientInterface
+	created   map[string]any
+	deletedID string
+	schedules []map[string]any
+	upcoming  []client.ScheduleExecution
+}
+
+func (m *mockScheduleClient) ListSchedules() ([]map[string]any, error) {
+	return m.schedules, nil
+}
+
+func (m *mockScheduleClient) CreateSchedule(schedule map[string]any) (map[string]any, error) {
+	m.created = schedule
+	out := map[string]any{"id": "schedule-1"}
+	for k, v := range schedule {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (m *mockScheduleClient) ListUpcomingSchedules(limit, days int) ([]client.ScheduleExecution, error) {
+	return m.upcoming, nil
+}
+
+func (m *mockScheduleClient) DeleteSchedule(id string) error {
+	m.deletedID = id
+	return nil
+}
+
+func TestScheduleAddCommand(t *testing.T) {
+	mock := &mockScheduleClient{}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	cmd := newScheduleAddCommand(nil)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"morning", "--group", "office", "--at", "08:30", "--on", "--brightness", "60"})
+	captureStdout(func() { require.NoError(t, cmd.Execute()) })
+
+	require.NotNil(t, mock.created)
+	require.Equal(t, "morning", mock.created["name"])
+	require.Equal(t, "08:30", mock.created["at"])
+	require.Equal(t, map[string]any{"type": "group", "id": "office"}, mock.created["target"])
+	require.Equal(t, map[string]any{"on": true, "brightness": 60}, mock.created["action"])
+	require.Equal(t, true, mock.created["enabled"])
+}
+
+func TestScheduleAddCommandScene(t *testing.T) {
+	mock := &mockScheduleClient{}
+	cmd := newScheduleAddCommand(nil)
+	cmd.SetContext(context.WithValue(context.Background(), clientContextKey, mock))
+	cmd.SetArgs([]string{"studio evening", "--scene", "Evening warm", "--group", "Studio", "--cron", "0 18 * * 1-5",
+		"--timezone", "Europe/London", "--skip", "2026-12-25,2026-12-26"})
+	captureStdout(func() { require.NoError(t, cmd.Execute()) })
+
+	require.NotNil(t, mock.created)
+	require.Equal(t, "Evening warm", mock.created["scene"])
+	require.Equal(t, map[string]any{"type": "group", "id": "Studio"}, mock.created["target"])
+	require.NotContains(t, mock.created, "action")
+	require.Equal(t, "Europe/London", mock.created["timezone"])
+	require.Equal(t, []string{"2026-12-25", "2026-12-26"}, mock.created["skip_dates"])
+}
+
+func TestScheduleAddCommandValidation(t *testing.T) {
+	tests := [][]string{
+		{"morning", "--group", "office", "--on"},                                        // no time
+		{"morning", "--group", "office", "--at", "08:30", "--cron", "@daily", "--on"},   // both times
+		{"morning", "--at", "08:30", "--on"},                                            // no target
+		{"morning", "--group", "office", "--at", "08:30"},                               // no action
+		{"morning", "--group", "office", "--at", "08:30", "--on", "--off"},              // conflicting power
+		{"morning", "--scene", "warm", "--at", "08:30", "--on"},                         // scene and action
+		{"morning", "--scene", "warm", "--light", "l", "--group", "g", "--at", "08:30"}, // two targets
+	}
+	for _, args := range tests {
+		mock := &mockScheduleClient{}
+		cmd := newScheduleAddCommand(nil)
+		cmd.SetContext(context.WithValue(context.Background(), clientContextKey, mock))
+		cmd.SetArgs(args)
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		require.Error(t, cmd.Execute(), "args: %v", args)
+		require.Nil(t, mock.created)
+	}
+}
+
+func TestScheduleListAndDeleteCommands(t *testing.T) {
+	mock := &mockScheduleClient{schedules: []map[string]any{{
+		"id":       "schedule-1",
+		"name":     "morning",
+		"at":       "08:30",
+		"target":   map[string]any{"type": "light", "id": "light1"},
+		"action":   map[string]any{"on": true},
+		"enabled":  true,
+		"next_run": "2026-03-02T08:30:00Z",
+	}}}
+	ctx := context.WithValue(context.Background(), clientContextKey, mock)
+
+	out := captureStdout(func() {
+		cmd := newScheduleListCommand(nil)
+		cmd.SetContext(ctx)
+		cmd.SetArgs([]string{"--parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, `id="schedule-1"`)
+	require.Contains(t, out, `when="daily 08:30"`)
+	require.Contains(t, out, `target="light:light1"`)
+	require.Contains(t, out, `action="on=true"`)
+	require.Contains(t, out, "next_run=1772440200")
+
+	mock.upcoming = []client.ScheduleExecution{{
+		ScheduleID: "schedule-1",
+		Name:       "morning",
+		At:         time.Date(2026, time.March, 2, 8, 30, 0, 0, time.UTC),
+		Target:     map[string]any{"type": "light", "id": "light1"},
+		Action:     map[string]any{"on": true},
+	}}
+	out = captureStdout(func() {
+		cmd := newScheduleUpcomingCommand(nil)
+		cmd.SetContext(ctx)
+		cmd.SetArgs([]string{"--parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Equal(t, `at=1772440200 schedule_id="schedule-1" name="morning" target="light:light1" action="on=true"`+"\n", out)
+
+	cmd := newScheduleDeleteCommand(nil)
+	cmd.SetContext(ctx)
+	cmd.SetArgs([]string{"schedule-1", "--yes"})
+	captureStdout(func() { require.NoError(t, cmd.Execute()) })
+	require.Equal(t, "schedule-1", mock.deletedID)
+}
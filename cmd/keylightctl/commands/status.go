@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// NewStatusCommand creates the status command
+func NewStatusCommand(_ *slog.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the daemon's status and a summary of lights and groups",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+
+			info, err := daemonInfo(c)
+			if err != nil {
+				return err
+			}
+			lights, err := c.GetLights()
+			if err != nil {
+				return fmt.Errorf("failed to get lights: %w", err)
+			}
+			groups, err := c.GetGroups()
+			if err != nil {
+				return fmt.Errorf("failed to get groups: %w", err)
+			}
+
+			status := newStatusJSON(info, lights, groups)
+			switch outputFormat(cmd) {
+			case OutputJSON:
+				return printJSON(status)
+			case OutputParseable:
+				fmt.Println(statusParseable(status))
+				for _, id := range slices.Sorted(maps.Keys(lights)) {
+					fmt.Println(LightParseable(lights[id]))
+				}
+				for _, group := range groups {
+					fmt.Println(GroupParseable(group))
+				}
+				return nil
+			}
+			return renderStatus(status, lights)
+		},
+	}
+}
+
+// daemonInfo returns the daemon's info, falling back to its version alone for
+// daemons that don't support get_daemon_info.
+func daemonInfo(c client.ClientInterface) (*client.DaemonInfo, error) {
+	info, err := c.GetDaemonInfo()
+	if err == nil {
+		return info, nil
+	}
+	if !errors.Is(err, client.ErrUnsupported) {
+		return nil, fmt.Errorf("failed to get daemon info: %w", err)
+	}
+	version, err := c.GetVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daemon version: %w", err)
+	}
+	info = &client.DaemonInfo{}
+	info.Version, _ = version["version"].(string)
+	info.Commit, _ = version["commit"].(string)
+	info.BuildDate, _ = version["build_date"].(string)
+	return info, nil
+}
+
+// newStatusJSON builds the status summary, with lights sorted by ID
+func newStatusJSON(info *client.DaemonInfo, lights map[string]*client.Light, groups []*client.Group) StatusJSON {
+	status := StatusJSON{
+		Daemon: info,
+		Lights: make([]LightJSON, 0, len(lights)),
+		Groups: make([]GroupJSON, 0, len(groups)),
+		Total:  len(lights),
+	}
+	for _, id := range slices.Sorted(maps.Keys(lights)) {
+		light := lights[id]
+		status.Lights = append(status.Lights, LightToJSON(light))
+		if light.On {
+			status.OnCount++
+		} else {
+			status.OffCount++
+		}
+	}
+	for _, group := range groups {
+		status.Groups = append(status.Groups, GroupToJSON(group))
+	}
+	return status
+}
+
+// statusParseable returns the parseable key=value string for the daemon and light counts
+func statusParseable(status StatusJSON) string {
+	fields := []resultField{
+		{"version", status.Daemon.Version},
+		{"commit", status.Daemon.Commit},
+		{"uptime", status.Daemon.UptimeSeconds},
+		{"socket", status.Daemon.SocketPath},
+		{"api", status.Daemon.APIListenAddress},
+		{"clients", status.Daemon.Clients.Socket + status.Daemon.Clients.WebSocket},
+	}
+	if d := status.Daemon.Discovery; d != nil {
+		fields = append(fields, resultField{"discovery_runs", d.Runs})
+	}
+	fields = append(fields,
+		resultField{"lights", status.Total},
+		resultField{"on", status.OnCount},
+		resultField{"off", status.OffCount},
+		resultField{"groups", len(status.Groups)},
+	)
+	return parseableLine(fields)
+}
+
+// renderStatus prints the status as tables
+func renderStatus(status StatusJSON, lights map[string]*client.Light) error {
+	info := status.Daemon
+	daemon := pterm.TableData{
+		{pterm.Bold.Sprint("Daemon"), pterm.Bold.Sprint(info.Version)},
+		{"Commit", info.Commit},
+		{"Build Date", info.BuildDate},
+		{"Uptime", formatUptime(info)},
+		{"Socket", valueOrUnknown(info.SocketPath)},
+		{"HTTP API", httpAPIStatus(info)},
+		{"Clients", clientsStatus(info)},
+		{"Discovery", discoveryStatus(info)},
+	}
+	if err := pterm.DefaultTable.WithData(daemon).Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	pterm.Println()
+
+	pterm.Info.Printf("Lights: %d on, %d off\n", status.OnCount, status.OffCount)
+	if len(status.Lights) > 0 {
+		table := pterm.TableData{{"ID", "Name", "On", "Brightness", "Temperature", "Status"}}
+		for _, id := range slices.Sorted(maps.Keys(lights)) {
+			light := lights[id]
+			table = append(table, []string{
+				keylight.UnescapeRFC6763Label(id),
+				cmp.Or(light.Name, light.ProductName),
+				strconv.FormatBool(light.On),
+				strconv.Itoa(light.Brightness),
+				fmt.Sprintf("%dK", keylight.ConvertDeviceToTemperature(light.Temperature)),
+				lightStatusOrUnknown(light),
+			})
+		}
+		if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+			return fmt.Errorf("failed to render table: %w", err)
+		}
+	}
+	pterm.Println()
+
+	pterm.Info.Printf("Groups: %d\n", len(status.Groups))
+	if len(status.Groups) > 0 {
+		table := pterm.TableData{{"Group ID", "Name", "Lights", "On"}}
+		for _, group := range status.Groups {
+			on := 0
+			for _, id := range group.Lights {
+				if light, ok := lights[id]; ok && light.On {
+					on++
+				}
+			}
+			table = append(table, []string{
+				group.ID,
+				group.Name,
+				strings.Join(group.Lights, ", "),
+				fmt.Sprintf("%d/%d", on, len(group.Lights)),
+			})
+		}
+		if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
+			return fmt.Errorf("failed to render table: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatUptime formats the daemon's uptime and start time for display
+func formatUptime(info *client.DaemonInfo) string {
+	if info.StartedAt.IsZero() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s (since %s)", info.Uptime(), info.StartedAt.Format(time.RFC1123Z))
+}
+
+// httpAPIStatus describes the daemon's HTTP API listen address for display
+func httpAPIStatus(info *client.DaemonInfo) string {
+	switch {
+	case len(info.APIListenAddresses) > 0:
+		return strings.Join(info.APIListenAddresses, ", ")
+	case info.APIListenAddress != "":
+		return info.APIListenAddress
+	case info.StartedAt.IsZero():
+		return "unknown"
+	default:
+		return "disabled"
+	}
+}
+
+// clientsStatus summarises the daemon's connected API clients for display
+func clientsStatus(info *client.DaemonInfo) string {
+	if info.StartedAt.IsZero() {
+		return "unknown"
+	}
+	c := info.Clients
+	return fmt.Sprintf("%d socket (%d streaming events), %d WebSocket", c.Socket, c.EventSubscribers, c.WebSocket)
+}
+
+// discoveryStatus summarises discovery statistics for display
+func discoveryStatus(info *client.DaemonInfo) string {
+	stats := info.Discovery
+	switch {
+	case stats == nil:
+		return "unknown"
+	case !stats.Completed || stats.LastRun.IsZero():
+		return "in progress"
+	}
+	summary := fmt.Sprintf("every %ds, %d passes, %d browse attempts, last %s ago (took %s)",
+		info.DiscoveryInterval, stats.Runs, stats.BrowseAttempts,
+		time.Since(stats.LastRun).Round(time.Second),
+		(time.Duration(stats.LastDurationMS) * time.Millisecond).Round(time.Millisecond))
+	if stats.Idle {
+		summary += ", slowed down as no clients were active"
+	}
+	if stats.LastError != "" {
+		summary += ", last error: " + stats.LastError
+	}
+	return summary
+}
+
+// valueOrUnknown returns s, or "unknown" if it is empty
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusCommand(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"status", "--output", "json"})
+		require.NoError(t, cmd.Execute())
+	})
+	var status StatusJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &status))
+	require.Equal(t, "1.2.3", status.Daemon.Version)
+	require.Equal(t, "/run/keylightd.sock", status.Daemon.SocketPath)
+	require.Equal(t, 4, status.Daemon.Discovery.Runs)
+	require.Equal(t, 2, status.Total)
+	require.Equal(t, 1, status.OnCount)
+	require.Equal(t, 1, status.OffCount)
+	require.Equal(t, "light1", status.Lights[0].ID)
+	require.Empty(t, status.Groups)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"status", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, `version="1.2.3" commit="abc123" uptime=3600 socket="/run/keylightd.sock" api=":9123" clients=2 discovery_runs=4 lights=2 on=1 off=1 groups=0`)
+	require.Contains(t, out, `id="light2"`)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"status"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, "/run/keylightd.sock")
+	require.Contains(t, out, "every 30s, 4 passes")
+	require.Contains(t, out, "2 socket (1 streaming events), 0 WebSocket")
+	require.Contains(t, out, "Light 2")
+}
+
+func TestVersionCommand_DaemonInfo(t *testing.T) {
+	t.Setenv(OutputEnvVar, "")
+
+	out := captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"version", "--output", "parseable"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, `daemon_version="1.2.3" daemon_commit="abc123" daemon_build_date="" daemon_uptime=3600`)
+
+	out = captureStdout(func() {
+		cmd := newTestRootCommand(&mockClient{})
+		cmd.SetArgs([]string{"version"})
+		require.NoError(t, cmd.Execute())
+	})
+	require.Contains(t, out, "Uptime:     1h0m0s")
+	require.Contains(t, out, "Lights:     2")
+}
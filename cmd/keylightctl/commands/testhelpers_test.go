@@ -5,10 +5,26 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"testing"
 
 	"github.com/pterm/pterm"
 )
 
+// TestMain keeps the commands away from the user's client config and
+// environment, which could otherwise select a context other than the mock.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "keylightctl-test")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv("XDG_CONFIG_HOME", dir)
+	_ = os.Unsetenv(ContextEnvVar)
+	_ = os.Unsetenv(APIKeyEnvVar)
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 // captureStdout captures stdout during the execution of f, disables pterm color, and strips ANSI codes from the output.
 func captureStdout(f func()) string {
 	oldStdout := os.Stdout
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// waybarDebounce is how long --follow waits after a light event for more to
+// arrive before refreshing, so a transition produces one update, not dozens.
+var waybarDebounce = 100 * time.Millisecond
+
+// waybarRetryInterval is how long --follow waits before resubscribing after
+// losing its connection to the daemon.
+var waybarRetryInterval = 5 * time.Second
+
+// waybarEventFilter selects the events that can change the waybar output.
+var waybarEventFilter = client.EventFilter{
+	Types: []events.EventType{events.LightStateChanged, events.LightDiscovered, events.LightRemoved},
+}
+
+// NewWaybarCommand creates the waybar command
+func NewWaybarCommand(logger *slog.Logger) *cobra.Command {
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "waybar",
+		Short: "Print the lights' state for a waybar custom module",
+		Long: `Print the lights' state as a line of waybar-compatible JSON.
+
+With --follow, keylightctl keeps running and prints a new line whenever a light
+changes, for use with a waybar module that has no "interval". If the daemon
+goes away, a line with the "disconnected" class is printed and keylightctl
+reconnects once it is back.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ok := cmd.Context().Value(clientContextKey).(client.ClientInterface)
+			if !ok {
+				return errors.New("client not found in context")
+			}
+			if !follow {
+				lights, err := c.GetLights()
+				if err != nil {
+					return fmt.Errorf("failed to get lights: %w", err)
+				}
+				fmt.Println(FormatWaybarOutput(lights))
+				return nil
+			}
+
+			sub, ok := c.(client.EventSubscriber)
+			if !ok {
+				return errors.New("--follow requires a connection to the daemon's socket")
+			}
+			return followWaybar(cmd.Context(), logger, c, sub)
+		},
+	}
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep running and print a new line whenever a light changes")
+	return cmd
+}
+
+// followWaybar prints the waybar output whenever it changes until ctx is
+// cancelled, resubscribing whenever the event stream ends.
+func followWaybar(ctx context.Context, logger *slog.Logger, c client.ClientInterface, sub client.EventSubscriber) error {
+	var last string
+	emit := func(line string) {
+		if line != last {
+			fmt.Println(line)
+			last = line
+		}
+	}
+
+	for {
+		err := streamWaybar(ctx, c, sub, emit)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, client.ErrUnsupported) {
+			return err
+		}
+		if logger != nil {
+			logger.Debug("waybar: event stream ended, retrying", "error", err, "retry", waybarRetryInterval)
+		}
+		emit(waybarDisconnectedOutput())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(waybarRetryInterval):
+		}
+	}
+}
+
+// streamWaybar subscribes to events and prints the waybar output now and
+// after each light event, until the stream ends.
+func streamWaybar(ctx context.Context, c client.ClientInterface, sub client.EventSubscriber, emit func(string)) error {
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Subscribe before reading the lights so no change can be missed between
+	// the two, and only to light events so group and job changes don't wake us
+	stream, err := sub.SubscribeEvents(subCtx, waybarEventFilter)
+	if err != nil {
+		return err
+	}
+	refresh := func() error {
+		lights, err := c.GetLights()
+		if err != nil {
+			return fmt.Errorf("failed to get lights: %w", err)
+		}
+		emit(FormatWaybarOutput(lights))
+		return nil
+	}
+	if err := refresh(); err != nil {
+		return err
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				return errors.New("event stream closed")
+			}
+			if strings.HasPrefix(string(event.Type), "light.") && debounce == nil {
+				debounce = time.After(waybarDebounce)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-debounce:
+			debounce = nil
+			if err := refresh(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// waybarDisconnectedOutput is the waybar output shown while the daemon can't be reached
+func waybarDisconnectedOutput() string {
+	jsonBytes, _ := json.Marshal(WaybarOutput{
+		Text:    "-",
+		Tooltip: "keylightd is not reachable",
+		Class:   "disconnected",
+	})
+	return string(jsonBytes)
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// mockEventClient streams events from a channel and serves lights that the
+// test can change.
+type mockEventClient struct {
+	mockClient
+	mu      sync.Mutex
+	lights  map[string]*client.Light
+	streams chan chan client.Event
+}
+
+func (m *mockEventClient) GetLights() (map[string]*client.Light, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lights := make(map[string]*client.Light, len(m.lights))
+	for id, light := range m.lights {
+		l := *light
+		lights[id] = &l
+	}
+	return lights, nil
+}
+
+func (m *mockEventClient) SubscribeEvents(ctx context.Context, _ client.EventFilter) (<-chan client.Event, error) {
+	select {
+	case ch := <-m.streams:
+		return ch, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		return nil, errors.New("daemon not running")
+	}
+}
+
+func (m *mockEventClient) setOn(id string, on bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lights[id].On = on
+}
+
+func TestWaybarFollow(t *testing.T) {
+	oldDebounce, oldRetry := waybarDebounce, waybarRetryInterval
+	waybarDebounce, waybarRetryInterval = time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { waybarDebounce, waybarRetryInterval = oldDebounce, oldRetry })
+
+	mock := &mockEventClient{
+		lights: map[string]*client.Light{
+			"light1": {ID: "light1", On: false, Brightness: 50, Temperature: 200},
+		},
+		streams: make(chan chan client.Event, 2),
+	}
+	stream := make(chan client.Event)
+	mock.streams <- stream
+
+	out := captureStdout(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- followWaybar(ctx, nil, mock, mock) }()
+
+		// Group events don't change the output; light events do
+		stream <- events.NewEvent(events.GroupCreated, nil)
+		mock.setOn("light1", true)
+		stream <- events.NewEvent(events.LightStateChanged, nil)
+		time.Sleep(50 * time.Millisecond)
+
+		// Losing the daemon prints a disconnected line, then resubscribes
+		close(stream)
+		time.Sleep(50 * time.Millisecond)
+		stream = make(chan client.Event)
+		mock.streams <- stream
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	var classes []string
+	for line := range strings.Lines(out) {
+		var output WaybarOutput
+		require.NoError(t, json.Unmarshal([]byte(line), &output))
+		classes = append(classes, output.Class)
+	}
+	require.Equal(t, []string{"off", "on", "disconnected", "on"}, classes)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"os"
@@ -48,16 +49,20 @@ func main() {
 	logger := utils.SetupLogger(cfg.Config.Logging.Level, cfg.Config.Logging.Format)
 	utils.SetAsDefaultLogger(logger)
 
-	// Set socket path using the new utility function
-	socket := config.GetRuntimeSocketPath()
-	if cfg.Config.Server.UnixSocket != "" {
-		socket = cfg.Config.Server.UnixSocket
-	}
-
 	// Use the NewRootCommand from the commands package
 	rootCmd := commands.NewRootCommand(logger, version, commit, buildDate)
 
-	apiClient := client.New(logger, socket)
+	// Find the daemon's socket, trying the configured one first. Commands
+	// that don't need the daemon still run without it; the others report
+	// that the socket can't be reached.
+	var apiClient client.ClientInterface
+	apiClient, err = client.Discover(context.Background(), logger, client.DiscoverOptions{
+		SocketPaths: []string{cfg.Config.Server.UnixSocket},
+	})
+	if err != nil {
+		logger.Debug("Daemon not found", "error", err)
+		apiClient = client.New(logger, cmp.Or(cfg.Config.Server.UnixSocket, config.GetRuntimeSocketPath()))
+	}
 
 	// Get the context initialized by NewRootCommand (which includes the logger)
 	// and add the apiClient to it.
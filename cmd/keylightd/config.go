@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// newConfigCommand returns the config command, which works with the daemon's
+// config and state files without starting it.
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work with the daemon's config and state files",
+	}
+
+	validate := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config and state files for unknown keys and invalid values",
+		Long: `Check the config file, and the state file if there is one, against their
+schema. Unknown keys, values of the wrong type and settings the daemon would
+ignore or replace with a default are reported with their line. With --file,
+only that file is checked; state files are recognised by their version key.
+
+The exit status is 1 if any problem is found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			var paths []string
+			if file != "" {
+				paths = []string{file}
+			} else {
+				configPath, _ := cmd.Flags().GetString("config")
+				if configPath == "" {
+					configPath = config.GetDaemonConfigPath()
+				}
+				paths = []string{configPath, statePathFor(configPath)}
+			}
+
+			out := cmd.OutOrStdout()
+			invalid := false
+			for _, path := range paths {
+				problems, err := config.ValidateFile(path)
+				if errors.Is(err, os.ErrNotExist) && file == "" {
+					// The daemon starts without it, using the defaults
+					fmt.Fprintf(out, "%s: not found\n", path)
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if len(problems) == 0 {
+					fmt.Fprintf(out, "%s: valid\n", path)
+					continue
+				}
+				invalid = true
+				for _, p := range problems {
+					fmt.Fprintf(out, "%s: %s\n", path, p)
+				}
+			}
+			if invalid {
+				cmd.SilenceUsage = true
+				return errors.New("invalid configuration")
+			}
+			return nil
+		},
+	}
+	validate.Flags().String("file", "", "Check this config or state file only")
+	cmd.AddCommand(validate)
+	return cmd
+}
+
+// statePathFor returns the state file used with the config file at
+// configPath: its server.state_file, or the default state path.
+func statePathFor(configPath string) string {
+	cfg, err := config.Load(config.DaemonConfigFilename, configPath)
+	if err == nil && cfg.Config.Server.StateFile != "" {
+		return cfg.Config.Server.StateFile
+	}
+	return config.GetStatePath()
+}
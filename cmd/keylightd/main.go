@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,7 +18,9 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/crash"
 	"github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/instance"
 	"github.com/jmylchreest/keylightd/internal/logging"
 	"github.com/jmylchreest/keylightd/internal/server"
 	"github.com/jmylchreest/keylightd/internal/utils"
@@ -51,6 +56,23 @@ func main() {
 			if err := v.BindPFlag("config", cmd.PersistentFlags().Lookup("config")); err != nil {
 				return fmt.Errorf("failed to bind flag: %w", err)
 			}
+			if err := v.BindPFlag("strict", cmd.Flags().Lookup("strict")); err != nil {
+				return fmt.Errorf("failed to bind flag: %w", err)
+			}
+
+			// In strict mode an invalid config file stops the daemon instead
+			// of falling back to defaults
+			strict := v.GetBool("strict")
+			if strict {
+				configPath := v.GetString("config")
+				if configPath == "" {
+					configPath = config.GetDaemonConfigPath()
+				}
+				if err := checkStrict(configPath); err != nil {
+					utils.SetupErrorLogger().Error("Refusing to start with an invalid config file", "error", err)
+					os.Exit(1)
+				}
+			}
 
 			// Load configuration
 			cfg, err := config.Load(config.DaemonConfigFilename, v.GetString("config"))
@@ -60,6 +82,13 @@ func main() {
 				os.Exit(1)
 			}
 
+			if err := applySimulationFlags(cmd, &cfg.Config.Simulation); err != nil {
+				return err
+			}
+			if crashReports, _ := cmd.Flags().GetBool("crash-reports"); crashReports {
+				cfg.Config.Debug.CrashReports = true
+			}
+
 			// Validate any configured log filters before applying
 			level := v.GetString("logging.level")
 			format := v.GetString("logging.format")
@@ -77,7 +106,10 @@ func main() {
 			// Set up logging backed by slog-logfilter.
 			// Using SetDefault so the package-level hot-reload functions
 			// (logfilter.SetLevel, logfilter.SetFilters, etc.) work.
-			logger := utils.SetupLoggerWithFilters(level, format, filters)
+			// Recent lines are also kept in memory for the logs API
+			logBuffer := logging.NewBuffer(cfg.Config.Logging.BufferSize)
+			logging.SetDefaultBuffer(logBuffer)
+			logger := utils.SetupLoggerWithFilters(level, format, filters, logBuffer)
 			utils.SetAsDefaultLogger(logger)
 
 			logger.Info("Starting keylightd",
@@ -89,7 +121,82 @@ func main() {
 				logger.Info("Log filters active", "count", len(filters))
 			}
 
+			// Only one daemon may serve a socket, or two would discover and
+			// change the same lights; --takeover stops the one that does
+			lock, err := acquireInstance(cmd, logger, cfg.Config.Server.UnixSocket)
+			if err != nil {
+				return errors.LogErrorAndReturn(logger, err, "Refusing to start")
+			}
+			defer func() {
+				if err := lock.Release(); err != nil {
+					logger.Warn("Failed to release instance lock", "error", err)
+				}
+			}()
+
+			// Panics and fatal errors leave a report with every goroutine's
+			// stack behind, for attaching to bug reports
+			if dbg := cfg.Config.Debug; dbg.CrashReports {
+				stop, err := crash.Enable(dbg.ReportDir(), crash.Info{
+					Version:   version,
+					Commit:    commit,
+					BuildDate: buildDate,
+				})
+				if err != nil {
+					logger.Warn("Failed to enable crash reports", "error", err)
+				} else {
+					defer stop()
+					logger.Info("Crash reports enabled", "dir", dbg.ReportDir())
+				}
+			}
+
+			// State (API keys, groups, schedules, ...) lives in its own file, so
+			// the config file is never rewritten by the daemon
+			statePath := cfg.Config.Server.StateFile
+			if statePath == "" {
+				statePath = config.GetStatePath()
+			}
+			if strict {
+				if err := checkStrict(statePath); err != nil {
+					return errors.LogErrorAndReturn(logger, err, "Refusing to start with an invalid state file")
+				}
+			}
+			if err := cfg.OpenState(statePath); err != nil {
+				return errors.LogErrorAndReturn(logger, err, "Failed to open state file")
+			}
+			defer func() {
+				if err := cfg.CloseState(); err != nil {
+					logger.Warn("Failed to release state file", "error", err)
+				}
+			}()
+			logger.Debug("Using state file", "path", statePath)
+
 			manager := keylight.NewManager(logger)
+			if err := manager.SetStaticLights(staticLightsFromConfig(cfg.Config.Lights.Static)); err != nil {
+				return errors.LogErrorAndReturn(logger, err, "Invalid static light configuration")
+			}
+			if n := len(cfg.Config.Lights.Static); n > 0 {
+				logger.Info("Static lights configured", "count", n)
+			}
+			if ifaces := cfg.Config.Discovery.Interfaces; len(ifaces) > 0 {
+				manager.SetDiscoveryInterfaces(ifaces)
+				logger.Info("Discovery restricted to interfaces", "interfaces", ifaces)
+			}
+			if scan := cfg.Config.Discovery.Scan; len(scan.Subnets) > 0 {
+				if err := manager.SetSubnetScan(scan.Subnets, time.Duration(scan.TimeoutMS)*time.Millisecond, scan.Concurrency); err != nil {
+					return errors.LogErrorAndReturn(logger, err, "Invalid discovery scan configuration")
+				}
+			}
+			if val := cfg.Config.Discovery.Validation; len(val.ProductPrefixes) > 0 || len(val.ProductPatterns) > 0 || val.AcceptAnyLight {
+				if err := manager.SetValidationRules(val.ProductPrefixes, val.ProductPatterns, val.AcceptAnyLight); err != nil {
+					return errors.LogErrorAndReturn(logger, err, "Invalid discovery validation configuration")
+				}
+			}
+			if backends := cfg.Config.Discovery.Backends; len(backends) > 0 {
+				if err := manager.SetDiscoveryBackends(backends); err != nil {
+					return errors.LogErrorAndReturn(logger, err, "Invalid discovery backend configuration")
+				}
+				logger.Info("Discovery backends configured", "backends", backends)
+			}
 			srv := server.New(logger, cfg, manager, server.VersionInfo{
 				Version:   version,
 				Commit:    commit,
@@ -99,14 +206,27 @@ func main() {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
-			go func() {
-				// Convert interval from seconds to duration
-				interval := time.Duration(cfg.Config.Discovery.Interval) * time.Second
-				// Start supervised discovery loop that auto-restarts on panic,
-				// and exits cleanly when ctx is canceled.
-				manager.StartDiscoveryWithRestart(ctx, interval)
-				logger.Debug("Discovery routine terminated")
-			}()
+			if sim := cfg.Config.Simulation; sim.Enabled {
+				// Virtual lights replace discovery, so no real light is touched
+				ids, err := manager.AddVirtualLights(ctx, keylight.VirtualLightOptions{
+					Count:       sim.LightCount(),
+					Latency:     time.Duration(sim.LatencyMS) * time.Millisecond,
+					FailureRate: sim.FailureRate,
+				})
+				if err != nil {
+					return errors.LogErrorAndReturn(logger, err, "Invalid simulation configuration")
+				}
+				logger.Warn("Simulation mode: using virtual lights, discovery is disabled", "lights", ids)
+			} else {
+				go func() {
+					// Convert interval from seconds to duration
+					interval := time.Duration(cfg.Config.Discovery.Interval) * time.Second
+					// Start supervised discovery loop that auto-restarts on panic,
+					// and exits cleanly when ctx is canceled.
+					manager.StartDiscoveryWithRestart(ctx, interval)
+					logger.Debug("Discovery routine terminated")
+				}()
+			}
 
 			if err := srv.Start(); err != nil {
 				return errors.LogErrorAndReturn(logger, err, "Failed to start server")
@@ -126,7 +246,10 @@ func main() {
 
 			sigChan := make(chan os.Signal, 1)
 			signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-			<-sigChan
+			select {
+			case <-sigChan:
+			case <-srv.StopRequested():
+			}
 			logger.Info("Shutting down...")
 			cancel()
 
@@ -141,12 +264,58 @@ func main() {
 	rootCmd.PersistentFlags().String("config", "", "Path to config file")
 	rootCmd.PersistentFlags().Int("discovery-interval", int(config.DefaultDiscoveryInterval.Seconds()),
 		fmt.Sprintf("Discovery interval in seconds (minimum: %d)", int(config.MinDiscoveryInterval.Seconds())))
+	rootCmd.Flags().Bool("dry-run", false, "Simulate virtual lights instead of discovering real ones")
+	rootCmd.Flags().Int("virtual-lights", 0,
+		fmt.Sprintf("Number of virtual lights to simulate, implies --dry-run (default %d)", config.DefaultSimulationLights))
+	rootCmd.Flags().Bool("crash-reports", false, "Write a crash report with stack traces if the daemon panics (see config.debug)")
+	rootCmd.Flags().Bool("strict", false, "Refuse to start if the config or state file is invalid, instead of falling back to defaults")
+	rootCmd.Flags().Bool("takeover", false, "Shut down a keylightd already running on the same socket and take its place")
+	rootCmd.AddCommand(newConfigCommand(), newServiceCommand(), newInstallServiceCommand(), newUninstallServiceCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+// applySimulationFlags enables simulation mode from the --dry-run and
+// --virtual-lights flags, overriding the config file.
+func applySimulationFlags(cmd *cobra.Command, sim *config.SimulationConfig) error {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	count, err := cmd.Flags().GetInt("virtual-lights")
+	if err != nil {
+		return err
+	}
+	if count < 0 {
+		return fmt.Errorf("--virtual-lights cannot be negative")
+	}
+	if dryRun || count > 0 {
+		sim.Enabled = true
+	}
+	if count > 0 {
+		sim.Lights = count
+	}
+	return nil
+}
+
+// staticLightsFromConfig converts configured static lights to manager lights.
+// Invalid IP addresses are left nil so SetStaticLights reports them.
+func staticLightsFromConfig(static []config.StaticLight) []keylight.Light {
+	lights := make([]keylight.Light, 0, len(static))
+	for _, s := range static {
+		lights = append(lights, keylight.Light{
+			ID:     s.ID,
+			Name:   s.Name,
+			IP:     net.ParseIP(s.IP),
+			Port:   s.Port,
+			Driver: s.Driver,
+		})
+	}
+	return lights
+}
+
 // reloadLoggingConfig handles hot-reload of logging level and filters when
 // the config file changes.  It validates filters before applying them; invalid
 // filters are rejected and the existing configuration is kept.
@@ -173,3 +342,41 @@ func reloadLoggingConfig(logger *slog.Logger, v *viper.Viper) {
 	logfilter.SetFilters(loggingCfg.Filters)
 	logger.Info("Log filters reloaded", "count", len(loggingCfg.Filters))
 }
+
+// checkStrict validates the config or state file at path for strict mode. A
+// missing file is valid, as the defaults are used in its place.
+func checkStrict(path string) error {
+	problems, err := config.ValidateFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		msgs := make([]string, len(problems))
+		for i, p := range problems {
+			msgs[i] = p.String()
+		}
+		return fmt.Errorf("%s: %s", path, strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// acquireInstance takes the lock for the daemon's socket. If another daemon
+// holds it, --takeover asks that one to shut down first; otherwise it is an
+// error.
+func acquireInstance(cmd *cobra.Command, logger *slog.Logger, socket string) (*instance.Lock, error) {
+	lock, err := instance.Acquire(socket)
+	var running *instance.RunningError
+	if !stderrors.As(err, &running) {
+		return lock, err
+	}
+	if takeover, _ := cmd.Flags().GetBool("takeover"); !takeover {
+		return nil, fmt.Errorf("%w; stop it first or start with --takeover", err)
+	}
+	logger.Info("Taking over from the running keylightd", "pid", running.PID, "socket", running.Socket)
+	ctx, cancel := context.WithTimeout(context.Background(), instance.DefaultTakeoverTimeout)
+	defer cancel()
+	return instance.Takeover(ctx, logger, running)
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -123,3 +125,102 @@ func TestSignalHandling(t *testing.T) {
 		t.Fatal("Timed out waiting for signal handling simulation")
 	}
 }
+
+func TestApplySimulationFlags(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Bool("dry-run", false, "")
+		cmd.Flags().Int("virtual-lights", 0, "")
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	var sim config.SimulationConfig
+	require.NoError(t, applySimulationFlags(newCmd(), &sim))
+	assert.False(t, sim.Enabled)
+
+	require.NoError(t, applySimulationFlags(newCmd("--dry-run"), &sim))
+	assert.True(t, sim.Enabled)
+	assert.Equal(t, config.DefaultSimulationLights, sim.LightCount())
+
+	sim = config.SimulationConfig{}
+	require.NoError(t, applySimulationFlags(newCmd("--virtual-lights", "5"), &sim))
+	assert.True(t, sim.Enabled)
+	assert.Equal(t, 5, sim.LightCount())
+
+	assert.Error(t, applySimulationFlags(newCmd("--virtual-lights", "-1"), &sim))
+}
+
+func TestConfigValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("config:\n  discovery:\n    interval: 60\n"), 0600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("config:\n  discovery:\n    intervall: 60\n"), 0600))
+
+	run := func(args ...string) (string, error) {
+		cmd := newConfigCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"validate"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--file", valid)
+	require.NoError(t, err)
+	assert.Equal(t, valid+": valid\n", out)
+
+	out, err = run("--file", invalid)
+	assert.Error(t, err)
+	assert.Contains(t, out, "config.discovery.intervall (line 3): unknown key")
+
+	_, err = run("--file", filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestCheckStrict(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, checkStrict(filepath.Join(dir, "missing.yaml")), "a missing file uses the defaults")
+
+	path := filepath.Join(dir, "keylightd.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("config:\n  logging:\n    format: xml\n"), 0600))
+	err := checkStrict(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config.logging.format (line 3)")
+}
+
+func TestInstallServiceCommand_Print(t *testing.T) {
+	root := &cobra.Command{Use: "keylightd"}
+	root.PersistentFlags().String("config", "", "Config path")
+	root.PersistentFlags().String("log-level", "info", "Log level")
+	root.AddCommand(newInstallServiceCommand())
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"install-service", "--print", "--binary", "/opt/homebrew/bin/keylightd",
+		"--config", "/Users/me/keylightd.yaml", "--log-level", "debug"})
+	require.NoError(t, root.Execute())
+
+	plist := out.String()
+	assert.Contains(t, plist, "<string>/opt/homebrew/bin/keylightd</string>\n\t\t<string>--config</string>\n\t\t<string>/Users/me/keylightd.yaml</string>\n\t\t<string>--log-level</string>\n\t\t<string>debug</string>")
+	assert.Contains(t, plist, "Library/Logs/keylightd/keylightd.log")
+}
+
+func TestServiceInstallCommand_Print(t *testing.T) {
+	root := &cobra.Command{Use: "keylightd"}
+	root.PersistentFlags().String("config", "", "Config path")
+	root.PersistentFlags().String("log-level", "info", "Log level")
+	root.AddCommand(newServiceCommand())
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"service", "install", "--print", "--binary", "/usr/local/bin/keylightd", "--config", "/home/me/keylightd.yaml"})
+	require.NoError(t, root.Execute())
+
+	assert.Contains(t, out.String(), `ExecStart="/usr/local/bin/keylightd" "--config" "/home/me/keylightd.yaml"`+"\n")
+	assert.Contains(t, out.String(), "Type=notify")
+}
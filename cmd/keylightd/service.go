@@ -0,0 +1,265 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmylchreest/keylightd/internal/launchd"
+	"github.com/jmylchreest/keylightd/internal/systemd"
+)
+
+// newInstallServiceCommand returns the install-service command, which runs
+// the daemon at login as a launchd agent on macOS.
+func newInstallServiceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install-service",
+		Short: "Run the daemon at login as a launchd agent (macOS)",
+		Long: `Install keylightd as a launchd agent for the current user and start it, so
+the daemon runs at login and is restarted if it crashes. The agent is written
+to ~/Library/LaunchAgents/` + launchd.Label + `.plist and logs to
+~/Library/Logs/keylightd/keylightd.log. Running it again replaces the agent,
+applying a new binary path or config file.
+
+With --print the agent is printed rather than installed, which works on any
+platform.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to find home directory: %w", err)
+			}
+			agent, err := launchdAgent(cmd, home)
+			if err != nil {
+				return err
+			}
+
+			if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+				_, err := cmd.OutOrStdout().Write(agent.Plist())
+				return err
+			}
+			if runtime.GOOS != "darwin" {
+				return errors.New("launchd agents are only supported on macOS; on Linux use keylightd service install")
+			}
+
+			noLoad, _ := cmd.Flags().GetBool("no-load")
+			path := launchd.AgentPath(home)
+			if err := launchd.Install(agent, path, !noLoad); err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Installed %s\n", path)
+			if noLoad {
+				fmt.Fprintln(out, "The daemon will start at the next login")
+			} else {
+				fmt.Fprintf(out, "Started keylightd, logging to %s\n", agent.LogPath)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("print", false, "Print the launchd agent instead of installing it")
+	cmd.Flags().Bool("no-load", false, "Install the agent without starting the daemon now")
+	cmd.Flags().String("binary", "", "Path of the keylightd binary to run (default: this binary)")
+	return cmd
+}
+
+// newUninstallServiceCommand returns the uninstall-service command, which
+// stops the launchd agent and removes it.
+func newUninstallServiceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall-service",
+		Short: "Stop the daemon and remove its launchd agent (macOS)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if runtime.GOOS != "darwin" {
+				return errors.New("launchd agents are only supported on macOS")
+			}
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to find home directory: %w", err)
+			}
+			path := launchd.AgentPath(home)
+			if err := launchd.Uninstall(path); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", path)
+			return nil
+		},
+	}
+}
+
+// newServiceCommand returns the service command, which manages a user-level
+// systemd unit running the daemon on Linux.
+func newServiceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Run the daemon as a user-level systemd service (Linux)",
+		Long: `Manage a systemd unit that runs keylightd as the current user, starting it
+at login. The unit is written to ~/.config/systemd/user/` + systemd.UnitName + `
+(under $XDG_CONFIG_HOME if set) and keeps the socket at the default path in
+the user's runtime directory, so keylightctl finds it without configuration.
+
+To run the daemon at boot, before anyone logs in, use the system-wide unit in
+contrib/systemd instead, or enable lingering with loginctl enable-linger.
+
+On macOS, use keylightd install-service.`,
+	}
+
+	install := &cobra.Command{
+		Use:   "install",
+		Short: "Install, enable and start the user service",
+		Long: `Write the user service, reload systemd, enable the unit and (re)start the
+daemon. Running it again replaces the unit, applying a new binary path or
+config file. With --print the unit is printed rather than installed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			program, daemonArgs, err := serviceCommandLine(cmd)
+			if err != nil {
+				return err
+			}
+			unit := systemd.Unit{Program: program, Args: daemonArgs}
+
+			if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+				_, err := cmd.OutOrStdout().Write(unit.Content())
+				return err
+			}
+			if err := requireSystemd(); err != nil {
+				return err
+			}
+
+			path, err := systemd.UnitPath()
+			if err != nil {
+				return err
+			}
+			noStart, _ := cmd.Flags().GetBool("no-start")
+			if err := systemd.Install(unit, path, !noStart); err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Installed %s\n", path)
+			if noStart {
+				fmt.Fprintf(out, "Start it with: systemctl --user enable --now %s\n", systemd.UnitName)
+			} else {
+				fmt.Fprintf(out, "Started keylightd; see its logs with: journalctl --user -u %s\n", systemd.UnitName)
+			}
+			return nil
+		},
+	}
+	install.Flags().Bool("print", false, "Print the unit instead of installing it")
+	install.Flags().Bool("no-start", false, "Install the unit without enabling or starting it")
+	install.Flags().String("binary", "", "Path of the keylightd binary to run (default: this binary)")
+
+	uninstall := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Stop and disable the user service and remove its unit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := requireSystemd(); err != nil {
+				return err
+			}
+			path, err := systemd.UnitPath()
+			if err != nil {
+				return err
+			}
+			if err := systemd.Uninstall(path); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", path)
+			return nil
+		},
+	}
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the user service is installed, enabled and running",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := requireSystemd(); err != nil {
+				return err
+			}
+			path, err := systemd.UnitPath()
+			if err != nil {
+				return err
+			}
+			st := systemd.Status(path)
+			out := cmd.OutOrStdout()
+			installed := "no"
+			if st.Installed {
+				installed = "yes"
+			}
+			fmt.Fprintf(out, "Unit:      %s\n", st.Path)
+			fmt.Fprintf(out, "Installed: %s\n", installed)
+			fmt.Fprintf(out, "Enabled:   %s\n", cmp.Or(st.Enabled, "unknown"))
+			fmt.Fprintf(out, "Active:    %s\n", cmp.Or(st.Active, "unknown"))
+			return nil
+		},
+	}
+
+	cmd.AddCommand(install, uninstall, status)
+	return cmd
+}
+
+// requireSystemd fails where there is no systemd to manage the service.
+func requireSystemd() error {
+	if runtime.GOOS != "linux" {
+		return errors.New("systemd services are only supported on Linux; on macOS use keylightd install-service")
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return errors.New("systemctl not found; is this system running systemd?")
+	}
+	return nil
+}
+
+// launchdAgent returns the agent running the daemon's command line, logging
+// to the user's Library/Logs.
+func launchdAgent(cmd *cobra.Command, home string) (launchd.Agent, error) {
+	program, args, err := serviceCommandLine(cmd)
+	if err != nil {
+		return launchd.Agent{}, err
+	}
+	return launchd.Agent{Program: program, Args: args, LogPath: launchd.LogPath(home)}, nil
+}
+
+// serviceCommandLine returns the command line a service runs the daemon
+// with: this binary, or the one given with --binary, with the --config and
+// --log-level flags passed on.
+func serviceCommandLine(cmd *cobra.Command) (string, []string, error) {
+	program, _ := cmd.Flags().GetString("binary")
+	if program == "" {
+		// Not resolving symlinks keeps a Homebrew bin/keylightd path, which
+		// survives upgrades
+		exe, err := os.Executable()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to find keylightd binary: %w", err)
+		}
+		program = exe
+	}
+	program, err := filepath.Abs(program)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var args []string
+	if configPath, _ := cmd.Flags().GetString("config"); configPath != "" {
+		configPath, err := filepath.Abs(configPath)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, "--config", configPath)
+	}
+	if f := cmd.Flags().Lookup("log-level"); f != nil && f.Changed {
+		args = append(args, "--log-level", f.Value.String())
+	}
+	return program, args, nil
+}
@@ -11,6 +11,7 @@ import { setSettings, filteredLog } from "./utils.js";
 
 // Import our modules
 import { StateManager } from "./managers/state-manager.js";
+import { EventStream } from "./managers/event-stream.js";
 import { LightsController } from "./controllers/lights-controller.js";
 import { GroupsController } from "./controllers/groups-controller.js";
 import { UIBuilder } from "./ui/ui-builder.js";
@@ -31,6 +32,9 @@ const KeylightdStateUpdateEmitter = GObject.registerClass(
 
 let keylightdStateUpdateEmitter = null;
 
+// Delay in milliseconds used to coalesce bursts of events into one refresh
+const EVENT_REFRESH_DELAY = 100;
+
 function iconExists(iconName) {
   try {
     let icon = new St.Icon({ icon_name: iconName });
@@ -106,6 +110,22 @@ export default class KeylightdControlExtension extends Extension {
       },
     );
 
+    // Subscribe to live updates; background refresh pauses while connected
+    this._eventStream = new EventStream(
+      this._settings,
+      () => this._scheduleEventRefresh(),
+      (connected) => this._handleEventStreamConnected(connected),
+    );
+    this._setupEventStream();
+
+    // Reconnect the event stream when the API settings or toggle change
+    this._eventStreamSignalIds = ["api-url", "api-key", "live-updates"].map(
+      (key) =>
+        this._settings.connect(`changed::${key}`, () => {
+          this._setupEventStream();
+        }),
+    );
+
     // Connect to settings changes for debounce delay
     this._debounceDelaySignalId = this._settings.connect(
       "changed::debounce-delay",
@@ -181,6 +201,22 @@ export default class KeylightdControlExtension extends Extension {
       this._visibleLightsSignalId = null;
     }
 
+    if (this._eventStreamSignalIds && this._settings) {
+      this._eventStreamSignalIds.forEach((id) => this._settings.disconnect(id));
+      this._eventStreamSignalIds = null;
+    }
+
+    // Stop the event stream
+    if (this._eventStream) {
+      this._eventStream.stop();
+      this._eventStream = null;
+    }
+
+    if (this._eventRefreshId) {
+      GLib.source_remove(this._eventRefreshId);
+      this._eventRefreshId = 0;
+    }
+
     // Null out the global emitter
     keylightdStateUpdateEmitter = null;
     this._stateUpdateEmitter = null;
@@ -232,6 +268,11 @@ export default class KeylightdControlExtension extends Extension {
     // Clear any existing timer
     this._clearBackgroundRefresh();
 
+    // Events keep the state current while the stream is connected
+    if (this._eventStream && this._eventStream.connected) {
+      return;
+    }
+
     // Get refresh interval from settings (in seconds)
     const refreshInterval = this._settings.get_int("refresh-interval");
 
@@ -261,6 +302,52 @@ export default class KeylightdControlExtension extends Extension {
     }
   }
 
+  /**
+   * Start or stop the event stream based on preferences
+   */
+  _setupEventStream() {
+    if (!this._eventStream) {
+      return;
+    }
+    if (this._settings.get_boolean("live-updates")) {
+      this._eventStream.start();
+    } else {
+      this._eventStream.stop();
+    }
+  }
+
+  /**
+   * Pause background refresh while the event stream is connected, and
+   * resume it when the stream drops
+   * @param {boolean} connected - Whether the stream is connected
+   */
+  _handleEventStreamConnected(connected) {
+    this._setupBackgroundRefresh();
+    if (connected) {
+      // Catch up on anything missed while disconnected
+      this._scheduleEventRefresh();
+    }
+  }
+
+  /**
+   * Refresh states shortly after an event, coalescing bursts of events
+   * (such as a group change touching several lights) into one refresh
+   */
+  _scheduleEventRefresh() {
+    if (this._eventRefreshId) {
+      return;
+    }
+    this._eventRefreshId = GLib.timeout_add(
+      GLib.PRIORITY_DEFAULT,
+      EVENT_REFRESH_DELAY,
+      () => {
+        this._eventRefreshId = 0;
+        this._performBackgroundRefresh();
+        return GLib.SOURCE_REMOVE;
+      },
+    );
+  }
+
   /**
    * Perform background refresh of light/group states
    */
@@ -0,0 +1,227 @@
+"use strict";
+
+import Gio from "gi://Gio";
+import GLib from "gi://GLib";
+import Soup from "gi://Soup";
+
+import { filteredLog } from "../utils.js";
+
+// Reconnect backoff bounds, in seconds
+const RECONNECT_MIN = 1;
+const RECONNECT_MAX = 60;
+
+// Seconds between pings, so a dead connection is noticed
+const KEEPALIVE_INTERVAL = 30;
+
+/**
+ * EventStream subscribes to the keylightd WebSocket event stream so that
+ * changes made elsewhere (CLI, tray, other clients) show up immediately
+ * instead of on the next background refresh. It reconnects with backoff
+ * while the daemon is unreachable.
+ */
+export class EventStream {
+  /**
+   * @param {Gio.Settings} settings - The extension settings
+   * @param {Function} onEvent - Called with each light or group event
+   * @param {Function} onConnectedChanged - Called with true/false when the
+   *   stream connects or disconnects
+   */
+  constructor(settings, onEvent, onConnectedChanged) {
+    this._settings = settings;
+    this._onEvent = onEvent;
+    this._onConnectedChanged = onConnectedChanged;
+    this._session = null;
+    this._connection = null;
+    this._cancellable = null;
+    this._reconnectId = 0;
+    this._backoff = RECONNECT_MIN;
+    this._connected = false;
+    this._running = false;
+  }
+
+  /**
+   * Whether the stream is currently connected
+   * @returns {boolean}
+   */
+  get connected() {
+    return this._connected;
+  }
+
+  /**
+   * Start (or restart) the stream with the current API settings
+   */
+  start() {
+    this.stop();
+    this._running = true;
+    this._backoff = RECONNECT_MIN;
+    this._connect();
+  }
+
+  /**
+   * Stop the stream and cancel any pending reconnect
+   */
+  stop() {
+    this._running = false;
+
+    if (this._reconnectId) {
+      GLib.source_remove(this._reconnectId);
+      this._reconnectId = 0;
+    }
+
+    if (this._cancellable) {
+      this._cancellable.cancel();
+      this._cancellable = null;
+    }
+
+    if (this._connection) {
+      const connection = this._connection;
+      this._connection = null;
+      if (connection.get_state() === Soup.WebsocketState.OPEN) {
+        connection.close(Soup.WebsocketCloseCode.NORMAL, null);
+      }
+    }
+
+    this._session = null;
+    this._setConnected(false);
+  }
+
+  _connect() {
+    const endpoint = this._settings.get_string("api-url");
+    const apiKey = this._settings.get_string("api-key");
+    if (!endpoint || !apiKey) {
+      filteredLog("debug", "Event stream not started: API not configured");
+      return;
+    }
+
+    const url = `${endpoint.replace(/\/+$/, "")}/api/v1/ws`;
+    let message;
+    try {
+      message = Soup.Message.new("GET", url);
+    } catch (e) {
+      filteredLog("warn", `Invalid API URL for event stream: ${e}`);
+      return;
+    }
+    if (!message) {
+      filteredLog("warn", `Invalid API URL for event stream: ${url}`);
+      return;
+    }
+    message.get_request_headers().append("X-API-Key", apiKey);
+
+    this._session = new Soup.Session();
+    this._cancellable = new Gio.Cancellable();
+    const cancellable = this._cancellable;
+
+    filteredLog("info", `Connecting to event stream at ${url}`);
+    this._session.websocket_connect_async(
+      message,
+      null,
+      null,
+      GLib.PRIORITY_DEFAULT,
+      cancellable,
+      (session, result) => {
+        let connection;
+        try {
+          connection = session.websocket_connect_finish(result);
+        } catch (e) {
+          if (cancellable.is_cancelled()) {
+            return;
+          }
+          // 404 means the daemon predates the event stream; keep polling
+          if (message.get_status() === Soup.Status.NOT_FOUND) {
+            filteredLog(
+              "info",
+              "Daemon does not support the event stream, using background refresh only",
+            );
+            return;
+          }
+          filteredLog("debug", `Event stream connection failed: ${e}`);
+          this._scheduleReconnect();
+          return;
+        }
+        if (cancellable.is_cancelled()) {
+          connection.close(Soup.WebsocketCloseCode.NORMAL, null);
+          return;
+        }
+        this._attach(connection);
+      },
+    );
+  }
+
+  _attach(connection) {
+    this._connection = connection;
+    this._backoff = RECONNECT_MIN;
+    connection.set_keepalive_interval(KEEPALIVE_INTERVAL);
+
+    connection.connect("message", (conn, type, bytes) => {
+      if (type !== Soup.WebsocketDataType.TEXT) {
+        return;
+      }
+      this._handleMessage(bytes);
+    });
+
+    connection.connect("closed", () => {
+      if (this._connection !== connection) {
+        return;
+      }
+      filteredLog("info", "Event stream closed");
+      this._connection = null;
+      this._setConnected(false);
+      this._scheduleReconnect();
+    });
+
+    filteredLog("info", "Event stream connected");
+    this._setConnected(true);
+  }
+
+  _handleMessage(bytes) {
+    let event;
+    try {
+      event = JSON.parse(new TextDecoder().decode(bytes.toArray()));
+    } catch (e) {
+      filteredLog("warn", `Ignoring malformed event: ${e}`);
+      return;
+    }
+    if (!event || typeof event.type !== "string") {
+      return;
+    }
+    // Only light and group events change what the menu shows
+    if (event.type.startsWith("light.") || event.type.startsWith("group.")) {
+      filteredLog("debug", `Received event ${event.type}`);
+      try {
+        this._onEvent(event);
+      } catch (e) {
+        console.error("Error handling event:", e);
+      }
+    }
+  }
+
+  _scheduleReconnect() {
+    if (!this._running || this._reconnectId) {
+      return;
+    }
+    const delay = this._backoff;
+    this._backoff = Math.min(this._backoff * 2, RECONNECT_MAX);
+    filteredLog("debug", `Reconnecting to event stream in ${delay}s`);
+    this._reconnectId = GLib.timeout_add_seconds(
+      GLib.PRIORITY_DEFAULT,
+      delay,
+      () => {
+        this._reconnectId = 0;
+        this._connect();
+        return GLib.SOURCE_REMOVE;
+      },
+    );
+  }
+
+  _setConnected(connected) {
+    if (this._connected === connected) {
+      return;
+    }
+    this._connected = connected;
+    try {
+      this._onConnectedChanged?.(connected);
+    } catch (e) {
+      console.error("Error handling event stream state:", e);
+    }
+  }
+}
@@ -4,10 +4,51 @@ import Adw from "gi://Adw";
 import Gtk from "gi://Gtk";
 import GObject from "gi://GObject";
 import Gio from "gi://Gio";
+import GLib from "gi://GLib";
 
 import { gettext as _ } from "resource:///org/gnome/Shell/Extensions/js/extensions/prefs.js";
 import { SYSTEM_PREFS_GENERAL_ICON } from "../icon-names.js";
 
+// daemonSocketPaths are where keylightd listens: the user service's socket,
+// then the system service's
+const daemonSocketPaths = [
+  GLib.build_filenamev([GLib.get_user_runtime_dir(), "keylightd.sock"]),
+  "/run/keylightd/keylightd.sock",
+];
+
+// requestDaemonKey asks keylightd over its unix socket for an API key that can
+// read and control lights. Asking again replaces the extension's previous key.
+function requestDaemonKey() {
+  const path = daemonSocketPaths.find((p) =>
+    GLib.file_test(p, GLib.FileTest.EXISTS),
+  );
+  if (!path) {
+    throw new Error(_("keylightd's socket was not found"));
+  }
+  const connection = new Gio.SocketClient().connect(
+    Gio.UnixSocketAddress.new(path),
+    null,
+  );
+  try {
+    const request = JSON.stringify({
+      action: "apikey_bootstrap",
+      data: { client: "gnome-extension" },
+    });
+    connection.get_output_stream().write_all(request + "\n", null);
+    const input = new Gio.DataInputStream({
+      base_stream: connection.get_input_stream(),
+    });
+    const [line] = input.read_line_utf8(null);
+    const response = JSON.parse(line);
+    if (response.error) {
+      throw new Error(response.error);
+    }
+    return response;
+  } finally {
+    connection.close(null);
+  }
+}
+
 export var GeneralPage = GObject.registerClass(
   class KeylightdGeneralPage extends Adw.PreferencesPage {
     _init(settings, settingsKey) {
@@ -56,8 +97,36 @@ export var GeneralPage = GObject.registerClass(
         Gio.SettingsBindFlags.DEFAULT,
       );
 
+      // Ask the local daemon for a key over its socket instead of pasting one
+      const daemonKeyRow = new Adw.ActionRow({
+        title: _("Get Key from keylightd"),
+        subtitle: _(
+          "Ask the keylightd running on this computer for an API key and URL",
+        ),
+      });
+      const daemonKeyButton = new Gtk.Button({
+        label: _("Get Key"),
+        valign: Gtk.Align.CENTER,
+      });
+      daemonKeyButton.connect("clicked", () => {
+        try {
+          const { key, api_url: apiURL } = requestDaemonKey();
+          if (!apiURL) {
+            throw new Error(_("keylightd is not serving the HTTP API"));
+          }
+          this._settings.set_string("api-url", apiURL);
+          this._settings.set_string("api-key", key.key);
+          daemonKeyRow.set_subtitle(_("Connected with a new key"));
+        } catch (e) {
+          console.error(`keylightd: failed to get an API key: ${e.message}`);
+          daemonKeyRow.set_subtitle(_("Failed: ") + e.message);
+        }
+      });
+      daemonKeyRow.add_suffix(daemonKeyButton);
+
       keylightdClientGroup.add(httpURL);
       keylightdClientGroup.add(httpAPIKey);
+      keylightdClientGroup.add(daemonKeyRow);
       this.add(keylightdClientGroup);
 
       // Refresh Settings
@@ -91,6 +160,22 @@ export var GeneralPage = GObject.registerClass(
 
       refreshSettingsGroup.add(refreshRow);
 
+      // Live updates over the event stream
+      const liveUpdatesRow = new Adw.SwitchRow({
+        title: _("Live Updates"),
+        subtitle: _(
+          "Receive changes from keylightd as they happen; refresh is paused while connected",
+        ),
+      });
+
+      this._settings.bind(
+        "live-updates",
+        liveUpdatesRow,
+        "active",
+        Gio.SettingsBindFlags.DEFAULT,
+      );
+      refreshSettingsGroup.add(liveUpdatesRow);
+
       // Create an Adw.SpinRow for debounce delay
       const debounceRow = new Adw.SpinRow({
         title: _("Control Update Delay"),
@@ -528,6 +528,8 @@ export const KeylightdControlToggle = GObject.registerClass(
                   powerIconName: SYSTEM_ICON_POWER,
                   brightnessIconName: SYSTEM_ICON_BRIGHTNESS,
                   temperatureIconName: SYSTEM_ICON_TEMPERATURE,
+                  showTemperature:
+                    lightDetails.capabilities?.temperature !== false,
                   toggleCallback: (state) =>
                     this._toggleLightState(light.id, state),
                   brightnessCallback: (value) =>
@@ -1277,6 +1279,7 @@ export const KeylightdControlToggle = GObject.registerClass(
             if (
               section._temperatureRow &&
               !section._temperatureRow.is_finalized &&
+              section._showTemperature !== false &&
               section._temperatureRow.visible !== true
             ) {
               section._temperatureRow.visible = true;
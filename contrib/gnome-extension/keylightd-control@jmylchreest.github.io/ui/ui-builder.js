@@ -156,6 +156,7 @@ export class UIBuilder {
       toggleCallback,
       brightnessCallback,
       temperatureCallback,
+      showTemperature = true,
       id,
       type = "light",
       powerIconName = SYSTEM_ICON_POWER,
@@ -354,6 +355,10 @@ export class UIBuilder {
     section._brightnessRow = brightnessRow;
     section._temperatureRow = temperatureRow;
 
+    // Lights without a color temperature have no temperature slider
+    temperatureRow.visible = showTemperature;
+    section._showTemperature = showTemperature;
+
     // Set initial slider states based on whether light is on
     if (!isOn) {
       brightnessRow.opacity = 120;
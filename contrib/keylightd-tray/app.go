@@ -4,18 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
-	"github.com/jmylchreest/keylightd/internal/config"
 	"github.com/jmylchreest/keylightd/internal/utils"
 	"github.com/jmylchreest/keylightd/pkg/client"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
@@ -33,7 +34,32 @@ type App struct {
 	customCSSPath string
 	cssWatcher    *fsnotify.Watcher
 	watchedDirs   map[string]bool
-}
+	settings      Settings // connection settings, saved to the config directory
+
+	// emit sends an event to the frontend; replaced in tests
+	emit func(name string, data ...any)
+	// stopEvents ends the event stream of the current client
+	stopEvents context.CancelFunc
+	live       atomic.Bool
+}
+
+// Frontend events sent for the daemon's event stream.
+const (
+	// eventStatusChanged carries the new Status after lights or groups change.
+	eventStatusChanged = "status:changed"
+	// eventLiveUpdates reports whether the event stream is connected. While
+	// it is, the frontend stops polling.
+	eventLiveUpdates = "status:live"
+)
+
+// Event stream timings.
+var (
+	// eventCoalesceDelay gathers a burst of events, such as a group change
+	// touching several lights, into one status update.
+	eventCoalesceDelay = 100 * time.Millisecond
+	eventRetryMin      = time.Second
+	eventRetryMax      = 30 * time.Second
+)
 
 // SetTrayManager sets the tray manager reference
 func (a *App) SetTrayManager(tray *TrayManager) {
@@ -92,23 +118,36 @@ type Settings struct {
 
 // Light represents a light for the frontend
 type Light struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	On           bool   `json:"on"`
-	Brightness   int    `json:"brightness"`
-	Temperature  int    `json:"temperature"`
-	ProductName  string `json:"productName"`
-	SerialNumber string `json:"serialNumber"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	On             bool   `json:"on"`
+	Brightness     int    `json:"brightness"`
+	Temperature    int    `json:"temperature"`
+	HasTemperature bool   `json:"hasTemperature"` // false hides the temperature slider
+	ProductName    string `json:"productName"`
+	SerialNumber   string `json:"serialNumber"`
 }
 
 // Group represents a group for the frontend
 type Group struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	LightIDs    []string `json:"lightIds"`
-	On          bool     `json:"on"`
-	Brightness  int      `json:"brightness"`
-	Temperature int      `json:"temperature"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	LightIDs       []string `json:"lightIds"`
+	On             bool     `json:"on"`
+	Brightness     int      `json:"brightness"`
+	Temperature    int      `json:"temperature"`
+	HasTemperature bool     `json:"hasTemperature"` // whether any light in the group has a color temperature
+}
+
+// DaemonInfo describes the running daemon for the About tab
+type DaemonInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	Uptime    string `json:"uptime"`
+	Lights    int    `json:"lights"`
+	Groups    int    `json:"groups"`
+	Clients   int    `json:"clients"`
 }
 
 // Status represents the overall status
@@ -136,51 +175,159 @@ func (a *App) startup(ctx context.Context) {
 	// Set up logging
 	a.logger = utils.SetupLogger("info", "text")
 
-	// Get socket path
-	socket := config.GetRuntimeSocketPath()
+	// Start the tray icon. Without one the window is the only way in, so
+	// show it rather than starting hidden.
+	if a.tray != nil {
+		if err := a.tray.Start(a.logger); err != nil {
+			a.logger.Error("Failed to start tray, showing window", "error", err)
+			a.ShowWindow()
+		}
+	}
+
+	a.emit = func(name string, data ...any) {
+		runtime.EventsEmit(ctx, name, data...)
+	}
 
-	// Create client
-	a.client = client.New(a.logger, socket)
+	// Connect as configured last time
+	a.restoreSettings()
 
 	// Start watching custom.css for changes
 	go a.watchCustomCSS()
 }
 
-// SaveSettings saves the connection settings and reconnects the client
-func (a *App) SaveSettings(settings Settings) error {
-	if settings.ConnectionType == "http" {
-		// Validate HTTP settings
-		if settings.APIUrl == "" {
-			return errors.New("API URL is required for HTTP connection")
+// setClient replaces the daemon client and restarts the event stream on it.
+func (a *App) setClient(c client.ClientInterface) {
+	if a.stopEvents != nil {
+		a.stopEvents()
+	}
+	a.client = c
+	a.setLiveUpdates(false)
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.stopEvents = cancel
+	go a.watchEvents(ctx, c)
+}
+
+// LiveUpdates reports whether status changes are being pushed to the
+// frontend, so it doesn't need to poll.
+func (a *App) LiveUpdates() bool {
+	return a.live.Load()
+}
+
+// setLiveUpdates records whether the event stream is connected and tells the
+// frontend.
+func (a *App) setLiveUpdates(live bool) {
+	if a.live.Swap(live) != live {
+		a.emit(eventLiveUpdates, live)
+	}
+}
+
+// watchEvents subscribes to the daemon's events until ctx is done, pushing
+// the new status to the frontend and tray after every change. A lost stream
+// is resubscribed with backoff; the frontend polls in the meantime. Daemons
+// without an event stream are polled throughout.
+func (a *App) watchEvents(ctx context.Context, c client.ClientInterface) {
+	subscriber, ok := c.(client.EventSubscriber)
+	if !ok {
+		return
+	}
+	// Once ctx is done the stream belongs to a replaced client, whose state
+	// must not override the new one's
+	setLive := func(live bool) {
+		if ctx.Err() == nil {
+			a.setLiveUpdates(live)
 		}
-		if settings.APIKey == "" {
-			return errors.New("API key is required for HTTP connection")
+	}
+
+	retry := eventRetryMin
+	for {
+		events, err := subscriber.SubscribeEvents(ctx, client.EventFilter{})
+		switch {
+		case errors.Is(err, client.ErrUnsupported):
+			a.logger.Info("Daemon doesn't stream events, polling for status instead", "error", err)
+			return
+		case err != nil:
+			a.logger.Debug("Failed to subscribe to events", "error", err, "retry", retry)
+		default:
+			retry = eventRetryMin
+			setLive(true)
+			// Catch up on changes made before the subscription
+			a.emitStatus()
+			a.forwardEvents(ctx, events)
+			setLive(false)
 		}
 
-		// Create HTTP client
-		a.client = client.NewHTTP(a.logger, settings.APIUrl, settings.APIKey)
-	} else {
-		// Use provided socket path or default
-		socketPath := settings.SocketPath
-		if socketPath == "" {
-			socketPath = config.GetRuntimeSocketPath()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
 		}
+		retry = min(retry*2, eventRetryMax)
+	}
+}
 
-		// Create socket client
-		a.client = client.New(a.logger, socketPath)
+// forwardEvents emits the status after each burst of events until the stream
+// ends.
+func (a *App) forwardEvents(ctx context.Context, events <-chan client.Event) {
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			if pending == nil {
+				pending = time.After(eventCoalesceDelay)
+			}
+		case <-pending:
+			pending = nil
+			a.emitStatus()
+		}
 	}
+}
 
+// emitStatus sends the current status to the frontend. GetStatus also
+// updates the tray, which keeps it current while the window is hidden.
+func (a *App) emitStatus() {
+	status, err := a.GetStatus()
+	if err != nil {
+		a.logger.Debug("Failed to get status after event", "error", err)
+		return
+	}
+	a.emit(eventStatusChanged, status)
+}
+
+// SaveSettings reconnects the client with the connection settings and saves
+// them for the next launch
+func (a *App) SaveSettings(settings Settings) error {
+	c, err := a.newClient(settings)
+	if err != nil {
+		return err
+	}
+
+	// The socket client keeps its connection open, so close it before replacing it
+	if closer, ok := a.client.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	a.settings = settings
+	a.setClient(c)
+
+	if err := a.storeSettings(settings); err != nil {
+		a.logger.Error("Failed to save settings", "error", err)
+		return fmt.Errorf("connected, but the settings could not be saved: %w", err)
+	}
 	return nil
 }
 
-// GetSettings returns the current connection settings
+// GetSettings returns the current connection settings. An empty socket path
+// means the default socket.
 func (a *App) GetSettings() Settings {
-	return Settings{
-		ConnectionType: "socket",
-		SocketPath:     config.GetRuntimeSocketPath(),
-		APIUrl:         "",
-		APIKey:         "",
+	settings := a.settings
+	if settings.ConnectionType == "" {
+		settings.ConnectionType = "socket"
 	}
+	return settings
 }
 
 // getConfigDir returns the config directory for keylightd-tray
@@ -368,7 +515,15 @@ func (a *App) watchCustomCSS() {
 
 // shutdown is called when the app closes
 func (a *App) shutdown(ctx context.Context) {
-	// Cleanup if needed
+	if a.tray != nil {
+		a.tray.Close()
+	}
+	if a.stopEvents != nil {
+		a.stopEvents()
+	}
+	if closer, ok := a.client.(io.Closer); ok {
+		_ = closer.Close()
+	}
 }
 
 // GetVersion returns the tray app version
@@ -395,6 +550,28 @@ func (a *App) GetDaemonVersion() string {
 	return fmt.Sprintf("%s, commit: %s, date: %s", v, c, d)
 }
 
+// GetDaemonInfo returns the daemon's version, uptime and what it is managing.
+// Daemons that predate get_daemon_info return an error; use GetDaemonVersion
+// for those.
+func (a *App) GetDaemonInfo() (*DaemonInfo, error) {
+	if a.client == nil {
+		return nil, errors.New("client not initialized")
+	}
+	info, err := a.client.GetDaemonInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daemon info: %w", err)
+	}
+	return &DaemonInfo{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildDate: info.BuildDate,
+		Uptime:    info.Uptime().String(),
+		Lights:    info.Lights,
+		Groups:    info.Groups,
+		Clients:   info.Clients.Socket + info.Clients.WebSocket,
+	}, nil
+}
+
 // GetStatus returns the current status of all lights and groups
 func (a *App) GetStatus() (*Status, error) {
 	if a.client == nil {
@@ -419,11 +596,7 @@ func (a *App) GetStatus() (*Status, error) {
 	// Process lights
 	lightMap := make(map[string]Light)
 	for id, lightData := range lights {
-		lightInfo, ok := lightData.(map[string]any)
-		if !ok {
-			continue
-		}
-		light := a.convertLight(id, lightInfo)
+		light := a.convertLight(id, lightData)
 		lightMap[id] = light
 		status.Lights = append(status.Lights, light)
 
@@ -453,8 +626,7 @@ func (a *App) GetStatus() (*Status, error) {
 
 	// Update tray icon, tooltip, and menu based on light status
 	if a.tray != nil {
-		a.tray.UpdateIconAndTooltip(status)
-		a.tray.UpdateMenu(status)
+		a.tray.Update(status)
 	}
 
 	return status, nil
@@ -469,11 +641,7 @@ func (a *App) GetLights() ([]Light, error) {
 
 	result := make([]Light, 0, len(lights))
 	for id, lightData := range lights {
-		lightInfo, ok := lightData.(map[string]any)
-		if !ok {
-			continue
-		}
-		result = append(result, a.convertLight(id, lightInfo))
+		result = append(result, a.convertLight(id, lightData))
 	}
 
 	// Sort by name (case-insensitive)
@@ -499,11 +667,7 @@ func (a *App) GetGroups() ([]Group, error) {
 	// Build light map
 	lightMap := make(map[string]Light)
 	for id, lightData := range lights {
-		lightInfo, ok := lightData.(map[string]any)
-		if !ok {
-			continue
-		}
-		lightMap[id] = a.convertLight(id, lightInfo)
+		lightMap[id] = a.convertLight(id, lightData)
 	}
 
 	result := make([]Group, 0, len(groups))
@@ -545,70 +709,28 @@ func (a *App) SetGroupLights(groupID string, lightIDs []string) error {
 }
 
 // convertLight converts the API light data to our Light struct
-func (a *App) convertLight(id string, data map[string]any) Light {
+func (a *App) convertLight(id string, data *client.Light) Light {
 	// Use the display name from the light data, fall back to unescaped ID
-	var name string
-	if v, ok := data["name"].(string); ok && v != "" {
-		name = v
-	} else {
+	name := data.Name
+	if name == "" {
 		name = keylight.UnescapeRFC6763Label(id)
 	}
 
-	on := false
-	if v, ok := data["on"].(bool); ok {
-		on = v
-	}
-
-	brightness := 0
-	if v, ok := data["brightness"].(float64); ok {
-		brightness = int(v)
-	} else if v, ok := data["brightness"].(int); ok {
-		brightness = v
-	}
-
-	tempDevice := 0
-	if v, ok := data["temperature"].(float64); ok {
-		tempDevice = int(v)
-	} else if v, ok := data["temperature"].(int); ok {
-		tempDevice = v
-	}
-	tempKelvin := keylight.ConvertDeviceToTemperature(tempDevice)
-
-	productName := ""
-	if v, ok := data["productname"].(string); ok {
-		productName = v
-	}
-
-	serialNumber := ""
-	if v, ok := data["serialnumber"].(string); ok {
-		serialNumber = v
-	}
-
 	return Light{
-		ID:           id,
-		Name:         name,
-		On:           on,
-		Brightness:   brightness,
-		Temperature:  tempKelvin,
-		ProductName:  productName,
-		SerialNumber: serialNumber,
+		ID:             id,
+		Name:           name,
+		On:             data.On,
+		Brightness:     data.Brightness,
+		Temperature:    keylight.ConvertDeviceToTemperature(data.Temperature),
+		ProductName:    data.ProductName,
+		SerialNumber:   data.SerialNumber,
+		HasTemperature: data.Supports(keylight.PropertyTemperature),
 	}
 }
 
 // convertGroup converts the API group data to our Group struct
-func (a *App) convertGroup(data map[string]any, lightMap map[string]Light) Group {
-	id, _ := data["id"].(string)
-	name, _ := data["name"].(string)
-
-	var lightIDs []string
-	if lights, ok := data["lights"].([]any); ok {
-		lightIDs = make([]string, 0, len(lights))
-		for _, l := range lights {
-			if s, ok := l.(string); ok {
-				lightIDs = append(lightIDs, s)
-			}
-		}
-	}
+func (a *App) convertGroup(data *client.Group, lightMap map[string]Light) Group {
+	lightIDs := data.Lights
 
 	// Use first light's values for display (same as GNOME extension)
 	// Group is "on" if any light is on
@@ -616,11 +738,15 @@ func (a *App) convertGroup(data map[string]any, lightMap map[string]Light) Group
 	brightness := 50    // Default
 	temperature := 4500 // Default
 
+	hasTemperature := false
 	for _, lightID := range lightIDs {
 		if light, exists := lightMap[lightID]; exists {
 			if light.On {
 				on = true
 			}
+			hasTemperature = hasTemperature || light.HasTemperature
+		} else {
+			hasTemperature = true // assume the usual white light until it is seen
 		}
 	}
 
@@ -633,12 +759,13 @@ func (a *App) convertGroup(data map[string]any, lightMap map[string]Light) Group
 	}
 
 	return Group{
-		ID:          id,
-		Name:        name,
-		LightIDs:    lightIDs,
-		On:          on,
-		Brightness:  brightness,
-		Temperature: temperature,
+		ID:             data.ID,
+		Name:           data.Name,
+		LightIDs:       lightIDs,
+		On:             on,
+		Brightness:     brightness,
+		Temperature:    temperature,
+		HasTemperature: hasTemperature,
 	}
 }
 
@@ -648,7 +775,8 @@ func (a *App) Ping() error {
 	return err
 }
 
-// GetRefreshInterval returns the suggested refresh interval in milliseconds
+// GetRefreshInterval returns the suggested refresh interval in milliseconds,
+// used while status changes aren't pushed by the event stream
 func (a *App) GetRefreshInterval() int {
 	return 1000 // 1 second
 }
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
 func TestGetConfigDir(t *testing.T) {
@@ -121,3 +128,101 @@ func TestSetCustomCSSPath(t *testing.T) {
 		t.Errorf("SetCustomCSSPath() did not set path correctly, got %s", app.customCSSPath)
 	}
 }
+
+func TestConvertGroupHasTemperature(t *testing.T) {
+	app := &App{}
+	rgb := app.convertLight("rgb", &client.Light{Capabilities: &keylight.Capabilities{Color: true}})
+	key := app.convertLight("key", &client.Light{})
+	if rgb.HasTemperature || !key.HasTemperature {
+		t.Fatalf("HasTemperature = %v, %v, want false, true", rgb.HasTemperature, key.HasTemperature)
+	}
+
+	lights := map[string]Light{"rgb": rgb, "key": key}
+	if g := app.convertGroup(&client.Group{Lights: []string{"rgb"}}, lights); g.HasTemperature {
+		t.Error("group of lights without a temperature should not have one")
+	}
+	if g := app.convertGroup(&client.Group{Lights: []string{"rgb", "key"}}, lights); !g.HasTemperature {
+		t.Error("group with a white light should have a temperature")
+	}
+}
+
+// eventClient is a daemon client with one light whose events are sent on
+// events. Methods the tray doesn't call in these tests are left unimplemented.
+type eventClient struct {
+	client.ClientInterface
+	events chan client.Event
+}
+
+func (c *eventClient) GetLights() (map[string]*client.Light, error) {
+	return map[string]*client.Light{"light-1": {Name: "Desk", On: true}}, nil
+}
+
+func (c *eventClient) GetGroups() ([]*client.Group, error) { return nil, nil }
+
+func (c *eventClient) SubscribeEvents(ctx context.Context, _ client.EventFilter) (<-chan client.Event, error) {
+	return c.events, nil
+}
+
+func TestWatchEvents(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		emitted []string
+	)
+	app := &App{logger: slog.New(slog.DiscardHandler)}
+	app.emit = func(name string, data ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		emitted = append(emitted, name)
+	}
+	count := func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		n := 0
+		for _, e := range emitted {
+			if e == name {
+				n++
+			}
+		}
+		return n
+	}
+	waitFor := func(name string, want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for count(name) < want {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s emitted %d times, want %d", name, count(name), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	c := &eventClient{events: make(chan client.Event)}
+	app.client = c
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go app.watchEvents(ctx, c)
+
+	// Subscribing goes live and catches up with one status
+	waitFor(eventLiveUpdates, 1)
+	waitFor(eventStatusChanged, 1)
+	if !app.LiveUpdates() {
+		t.Error("LiveUpdates() = false after subscribing")
+	}
+
+	// A burst of events is coalesced into one status
+	for range 3 {
+		c.events <- client.Event{Type: "light.state_changed"}
+	}
+	waitFor(eventStatusChanged, 2)
+	time.Sleep(2 * eventCoalesceDelay)
+	if n := count(eventStatusChanged); n != 2 {
+		t.Errorf("status emitted %d times after one burst, want 2", n)
+	}
+
+	// A lost stream falls back to polling
+	close(c.events)
+	waitFor(eventLiveUpdates, 2)
+	if app.LiveUpdates() {
+		t.Error("LiveUpdates() = true after the stream ended")
+	}
+}
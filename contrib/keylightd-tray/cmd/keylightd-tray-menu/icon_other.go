@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "github.com/jmylchreest/keylightd/contrib/keylightd-tray/traymenu"
+
+// iconData returns a tray icon as PNG.
+func iconData(icon traymenu.Icon) []byte {
+	return traymenu.IconPNG(icon)
+}
@@ -0,0 +1,8 @@
+package main
+
+import "github.com/jmylchreest/keylightd/contrib/keylightd-tray/traymenu"
+
+// iconData returns a tray icon in the ICO format the Windows tray requires.
+func iconData(icon traymenu.Icon) []byte {
+	return traymenu.PNGToICO(traymenu.IconPNG(icon))
+}
@@ -0,0 +1,170 @@
+// Command keylightd-tray-menu shows the system tray icon and menu of
+// keylightd-tray. It is started by keylightd-tray, which sends it menus on
+// stdin and reads the actions chosen in the tray from stdout; see the
+// traymenu package.
+package main
+
+import (
+	"log"
+	"os"
+	"slices"
+
+	"fyne.io/systray"
+
+	"github.com/jmylchreest/keylightd/contrib/keylightd-tray/traymenu"
+)
+
+func main() {
+	log.SetPrefix(traymenu.HelperName + ": ")
+	t := &tray{actions: traymenu.NewActionWriter(os.Stdout)}
+	systray.Run(t.onReady, nil)
+}
+
+// tray shows menus with the systray library. Show is only called from the
+// goroutine reading menus, so it needs no locking.
+type tray struct {
+	actions *traymenu.ActionWriter
+
+	icon     traymenu.Icon
+	tooltip  string
+	show     *systray.MenuItem
+	groupIDs []string
+	lightIDs []string
+	items    map[string]*systray.MenuItem // by group or light ID
+	titles   map[*systray.MenuItem]string
+	stop     chan struct{} // ends the click handlers of the current menu
+}
+
+// onReady is called when the systray is ready.
+func (t *tray) onReady() {
+	t.icon = traymenu.IconUnknown
+	systray.SetIcon(iconData(t.icon))
+	systray.SetTitle("Keylight Control")
+	systray.SetTooltip("Keylight Control")
+
+	// Left-click toggles the window; right-click shows the menu
+	systray.SetOnTapped(func() {
+		t.send(traymenu.Action{Type: traymenu.ActionToggleWindow})
+	})
+
+	// Basic Show/Quit menu until the app sends the lights
+	t.rebuild(traymenu.Menu{})
+
+	go func() {
+		if err := traymenu.Serve(os.Stdin, t); err != nil {
+			log.Print(err)
+		}
+		// The app closed stdin or exited
+		systray.Quit()
+	}()
+}
+
+// Show updates the tray to show a menu. The systray library emits a DBus
+// signal on Linux for every change, so only what differs from the last menu
+// is updated, and the menu is only rebuilt when its lights or groups change.
+func (t *tray) Show(m traymenu.Menu) {
+	if m.Icon != t.icon {
+		systray.SetIcon(iconData(m.Icon))
+		t.icon = m.Icon
+	}
+	if m.Tooltip != t.tooltip {
+		systray.SetTooltip(m.Tooltip)
+		t.tooltip = m.Tooltip
+	}
+
+	if !slices.Equal(itemIDs(m.Groups), t.groupIDs) || !slices.Equal(itemIDs(m.Lights), t.lightIDs) {
+		t.rebuild(m)
+		return
+	}
+	t.setTitle(t.show, showTitle(m.WindowShown))
+	for _, item := range slices.Concat(m.Groups, m.Lights) {
+		t.setTitle(t.items[item.ID], item.Title)
+	}
+}
+
+// rebuild replaces the menu.
+func (t *tray) rebuild(m traymenu.Menu) {
+	if t.stop != nil {
+		close(t.stop)
+		systray.ResetMenu()
+	}
+	t.stop = make(chan struct{})
+	t.items = make(map[string]*systray.MenuItem)
+	t.titles = make(map[*systray.MenuItem]string)
+	t.groupIDs = itemIDs(m.Groups)
+	t.lightIDs = itemIDs(m.Lights)
+
+	// 1. Show/Hide at top
+	t.show = t.addItem(showTitle(m.WindowShown), "Show or hide the window",
+		traymenu.Action{Type: traymenu.ActionToggleWindow})
+
+	// 2. Groups section
+	if len(m.Groups) > 0 {
+		systray.AddMenuItem("Groups", "Groups section").Disable()
+		for _, group := range m.Groups {
+			t.items[group.ID] = t.addItem(group.Title, "Toggle group",
+				traymenu.Action{Type: traymenu.ActionToggleGroup, ID: group.ID})
+		}
+	}
+
+	// 3. All lights section
+	if len(m.Lights) > 0 {
+		systray.AddMenuItem("Lights", "Lights section").Disable()
+		for _, light := range m.Lights {
+			t.items[light.ID] = t.addItem(light.Title, "Toggle light",
+				traymenu.Action{Type: traymenu.ActionToggleLight, ID: light.ID})
+		}
+	}
+
+	// 4. Separator + Quit at bottom
+	systray.AddSeparator()
+	t.addItem("Quit", "Quit the application", traymenu.Action{Type: traymenu.ActionQuit})
+}
+
+// addItem adds a menu item that sends an action to the app when clicked.
+func (t *tray) addItem(title, tooltip string, action traymenu.Action) *systray.MenuItem {
+	item := systray.AddMenuItem(title, tooltip)
+	t.titles[item] = title
+	go func(stop <-chan struct{}) {
+		for {
+			select {
+			case <-item.ClickedCh:
+				t.send(action)
+			case <-stop:
+				return
+			}
+		}
+	}(t.stop)
+	return item
+}
+
+// setTitle sets the title of a menu item if it has changed.
+func (t *tray) setTitle(item *systray.MenuItem, title string) {
+	if item != nil && t.titles[item] != title {
+		item.SetTitle(title)
+		t.titles[item] = title
+	}
+}
+
+// send writes an action to the app. If the app has gone the helper exits.
+func (t *tray) send(action traymenu.Action) {
+	if err := t.actions.Write(action); err != nil {
+		log.Printf("failed to send action: %v", err)
+		systray.Quit()
+	}
+}
+
+func showTitle(windowShown bool) string {
+	if windowShown {
+		return "Hide"
+	}
+	return "Show"
+}
+
+func itemIDs(items []traymenu.Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
@@ -10,6 +10,8 @@ export function FormatLastSeen(arg1:number):Promise<string>;
 
 export function GetCustomCSS():Promise<string>;
 
+export function GetDaemonInfo():Promise<main.DaemonInfo>;
+
 export function GetDaemonVersion():Promise<string>;
 
 export function GetGroups():Promise<Array<main.Group>>;
@@ -26,6 +28,8 @@ export function GetVersion():Promise<string>;
 
 export function HideWindow():Promise<void>;
 
+export function LiveUpdates():Promise<boolean>;
+
 export function Ping():Promise<void>;
 
 export function Quit():Promise<void>;
@@ -45,3 +49,5 @@ export function SetLightState(arg1:string,arg2:string,arg3:any):Promise<void>;
 export function SetTrayManager(arg1:main.TrayManager):Promise<void>;
 
 export function ShowWindow():Promise<void>;
+
+export function UseDaemonAPIKey():Promise<main.Settings>;
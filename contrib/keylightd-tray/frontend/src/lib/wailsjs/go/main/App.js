@@ -18,6 +18,10 @@ export function GetCustomCSS() {
   return window['go']['main']['App']['GetCustomCSS']();
 }
 
+export function GetDaemonInfo() {
+  return window['go']['main']['App']['GetDaemonInfo']();
+}
+
 export function GetDaemonVersion() {
   return window['go']['main']['App']['GetDaemonVersion']();
 }
@@ -50,6 +54,10 @@ export function HideWindow() {
   return window['go']['main']['App']['HideWindow']();
 }
 
+export function LiveUpdates() {
+  return window['go']['main']['App']['LiveUpdates']();
+}
+
 export function Ping() {
   return window['go']['main']['App']['Ping']();
 }
@@ -89,3 +97,7 @@ export function SetTrayManager(arg1) {
 export function ShowWindow() {
   return window['go']['main']['App']['ShowWindow']();
 }
+
+export function UseDaemonAPIKey() {
+  return window['go']['main']['App']['UseDaemonAPIKey']();
+}
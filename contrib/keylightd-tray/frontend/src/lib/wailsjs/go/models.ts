@@ -1,5 +1,29 @@
 export namespace main {
 	
+	export class DaemonInfo {
+	    version: string;
+	    commit: string;
+	    buildDate: string;
+	    uptime: string;
+	    lights: number;
+	    groups: number;
+	    clients: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DaemonInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.commit = source["commit"];
+	        this.buildDate = source["buildDate"];
+	        this.uptime = source["uptime"];
+	        this.lights = source["lights"];
+	        this.groups = source["groups"];
+	        this.clients = source["clients"];
+	    }
+	}
 	export class Group {
 	    id: string;
 	    name: string;
@@ -7,6 +31,7 @@ export namespace main {
 	    on: boolean;
 	    brightness: number;
 	    temperature: number;
+	    hasTemperature: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new Group(source);
@@ -20,6 +45,7 @@ export namespace main {
 	        this.on = source["on"];
 	        this.brightness = source["brightness"];
 	        this.temperature = source["temperature"];
+	        this.hasTemperature = source["hasTemperature"];
 	    }
 	}
 	export class Light {
@@ -28,6 +54,7 @@ export namespace main {
 	    on: boolean;
 	    brightness: number;
 	    temperature: number;
+	    hasTemperature: boolean;
 	    productName: string;
 	    serialNumber: string;
 	
@@ -42,6 +69,7 @@ export namespace main {
 	        this.on = source["on"];
 	        this.brightness = source["brightness"];
 	        this.temperature = source["temperature"];
+	        this.hasTemperature = source["hasTemperature"];
 	        this.productName = source["productName"];
 	        this.serialNumber = source["serialNumber"];
 	    }
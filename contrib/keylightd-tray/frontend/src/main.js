@@ -1,10 +1,16 @@
 // Wails runtime bindings
-let GetStatus, GetVersion, GetDaemonVersion, SetLightState, SetGroupState;
+let GetStatus,
+  GetVersion,
+  GetDaemonInfo,
+  GetDaemonVersion,
+  SetLightState,
+  SetGroupState;
 
 // Check if running in Wails or browser
 if (window.go && window.go.main && window.go.main.App) {
   GetStatus = window.go.main.App.GetStatus;
   GetVersion = window.go.main.App.GetVersion;
+  GetDaemonInfo = window.go.main.App.GetDaemonInfo;
   GetDaemonVersion = window.go.main.App.GetDaemonVersion;
   SetLightState = window.go.main.App.SetLightState;
   SetGroupState = window.go.main.App.SetGroupState;
@@ -13,6 +19,15 @@ if (window.go && window.go.main && window.go.main.App) {
   console.warn("Wails runtime not available - using mock data");
 
   GetVersion = async () => "dev (browser)";
+  GetDaemonInfo = async () => ({
+    version: "dev (browser)",
+    commit: "unknown",
+    buildDate: "unknown",
+    uptime: "1h0m0s",
+    lights: 2,
+    groups: 1,
+    clients: 1,
+  });
   GetDaemonVersion = async () => "dev (browser)";
 
   GetStatus = async () => ({
@@ -65,8 +80,10 @@ let CreateGroup,
   GetLights,
   SetInitialWindowHeight,
   SaveSettings,
+  UseDaemonAPIKey,
   GetSettings,
-  GetCustomCSS;
+  GetCustomCSS,
+  LiveUpdates;
 
 if (window.go && window.go.main && window.go.main.App) {
   CreateGroup = window.go.main.App.CreateGroup;
@@ -75,8 +92,10 @@ if (window.go && window.go.main && window.go.main.App) {
   GetLights = window.go.main.App.GetLights;
   SetInitialWindowHeight = window.go.main.App.SetInitialWindowHeight;
   SaveSettings = window.go.main.App.SaveSettings;
+  UseDaemonAPIKey = window.go.main.App.UseDaemonAPIKey;
   GetSettings = window.go.main.App.GetSettings;
   GetCustomCSS = window.go.main.App.GetCustomCSS;
+  LiveUpdates = window.go.main.App.LiveUpdates;
 } else {
   CreateGroup = async (name) => {
     console.log(`CreateGroup: ${name}`);
@@ -111,6 +130,12 @@ if (window.go && window.go.main && window.go.main.App) {
   SaveSettings = async (settings) => {
     console.log(`SaveSettings:`, settings);
   };
+  UseDaemonAPIKey = async () => ({
+    connectionType: "http",
+    socketPath: "",
+    apiUrl: "http://localhost:9123",
+    apiKey: "mock-ui-key",
+  });
   GetSettings = async () => ({
     connectionType: "socket",
     socketPath: "",
@@ -118,6 +143,7 @@ if (window.go && window.go.main && window.go.main.App) {
     apiKey: "",
   });
   GetCustomCSS = async () => "";
+  LiveUpdates = async () => false;
 }
 
 // State
@@ -138,6 +164,10 @@ let pauseReasons = new Set();
 let currentIntervalMs = 2500;
 let lastStatusHash = null;
 
+// While the Go side is subscribed to the daemon's event stream it pushes
+// every status change, so the polling loop is stopped entirely.
+let liveUpdates = false;
+
 // Tracks the *shape* of what's currently rendered (the sorted list of card
 // IDs). When a refresh comes in with the same shape, we update card values
 // in place via updateSliderValues — no innerHTML replacement, so we don't
@@ -154,23 +184,34 @@ function shapeHashFor(items) {
 }
 
 function setPollingPaused(reason, paused) {
+  const wasPaused = pauseReasons.size > 0;
   if (paused) pauseReasons.add(reason);
   else pauseReasons.delete(reason);
+  // Immediate refresh on resume so the user sees current state, including
+  // pushed changes that were ignored while paused
+  if (wasPaused && pauseReasons.size === 0) refresh();
   applyPauseState();
 }
 
 function applyPauseState() {
-  if (pauseReasons.size > 0) {
+  if (pauseReasons.size > 0 || liveUpdates) {
     if (refreshInterval) {
       clearInterval(refreshInterval);
       refreshInterval = null;
     }
   } else if (!refreshInterval) {
-    refresh(); // immediate refresh on resume so the user sees current state
     refreshInterval = setInterval(refresh, currentIntervalMs);
   }
 }
 
+function setLiveUpdates(live) {
+  if (live === liveUpdates) return;
+  liveUpdates = live;
+  // Polling resumes when the stream is lost; catch up on what was missed
+  if (!live) refresh();
+  applyPauseState();
+}
+
 function changePollingInterval(intervalMs) {
   currentIntervalMs = intervalMs;
   if (refreshInterval) {
@@ -190,6 +231,18 @@ function setupVisibilityPause() {
   });
 }
 
+function setupLiveUpdates() {
+  // Go pushes the status after every change from the daemon's event
+  // stream, and reports whether the stream is connected so polling can
+  // stop while it is.
+  if (!window.runtime || !window.runtime.EventsOn) return;
+  window.runtime.EventsOn("status:live", setLiveUpdates);
+  window.runtime.EventsOn("status:changed", (status) => {
+    if (pauseReasons.size > 0) return;
+    applyStatus(status);
+  });
+}
+
 // Icons
 const POWER_ICON = `<svg viewBox="0 0 24 24"><path d="M13 3h-2v10h2V3zm4.83 2.17l-1.42 1.42C17.99 7.86 19 9.81 19 12c0 3.87-3.13 7-7 7s-7-3.13-7-7c0-2.19 1.01-4.14 2.58-5.42L6.17 5.17C4.23 6.82 3 9.26 3 12c0 4.97 4.03 9 9 9s9-4.03 9-9c0-2.74-1.23-5.18-3.17-6.83z"/></svg>`;
 const SUN_ICON = `<svg viewBox="0 0 24 24"><path d="M12 7c-2.76 0-5 2.24-5 5s2.24 5 5 5 5-2.24 5-5-2.24-5-5-5zM2 13h2c.55 0 1-.45 1-1s-.45-1-1-1H2c-.55 0-1 .45-1 1s.45 1 1 1zm18 0h2c.55 0 1-.45 1-1s-.45-1-1-1h-2c-.55 0-1 .45-1 1s.45 1 1 1zM11 2v2c0 .55.45 1 1 1s1-.45 1-1V2c0-.55-.45-1-1-1s-1 .45-1 1zm0 18v2c0 .55.45 1 1 1s1-.45 1-1v-2c0-.55-.45-1-1-1s-1 .45-1 1zM5.99 4.58c-.39-.39-1.03-.39-1.41 0-.39.39-.39 1.03 0 1.41l1.06 1.06c.39.39 1.03.39 1.41 0s.39-1.03 0-1.41L5.99 4.58zm12.37 12.37c-.39-.39-1.03-.39-1.41 0-.39.39-.39 1.03 0 1.41l1.06 1.06c.39.39 1.03.39 1.41 0 .39-.39.39-1.03 0-1.41l-1.06-1.06zm1.06-10.96c.39-.39.39-1.03 0-1.41-.39-.39-1.03-.39-1.41 0l-1.06 1.06c-.39.39-.39 1.03 0 1.41s1.03.39 1.41 0l1.06-1.06zM7.05 18.36c.39-.39.39-1.03 0-1.41-.39-.39-1.03-.39-1.41 0l-1.06 1.06c-.39.39-.39 1.03 0 1.41s1.03.39 1.41 0l1.06-1.06z"/></svg>`;
@@ -216,15 +269,24 @@ document.addEventListener("DOMContentLoaded", async () => {
   }
 
   try {
-    const daemonVersion = await GetDaemonVersion();
+    const info = await GetDaemonInfo();
     document.getElementById("about-daemon-version").textContent =
-      `Daemon: ${daemonVersion || "unavailable"}`;
+      `Daemon: ${info.version}, commit: ${info.commit}, date: ${info.buildDate}`;
+    document.getElementById("about-daemon-info").textContent =
+      `Up ${info.uptime}, managing ${info.lights} lights and ${info.groups} groups for ${info.clients} clients`;
   } catch (e) {
-    console.error("Failed to get daemon version:", e);
+    // Daemons that predate get_daemon_info only report their version
+    try {
+      const daemonVersion = await GetDaemonVersion();
+      document.getElementById("about-daemon-version").textContent =
+        `Daemon: ${daemonVersion || "unavailable"}`;
+    } catch (e) {
+      console.error("Failed to get daemon version:", e);
+    }
   }
 
   // Setup settings panel
-  setupSettings();
+  await setupSettings();
 
   // Setup master toggle
   setupMasterToggle();
@@ -238,6 +300,7 @@ document.addEventListener("DOMContentLoaded", async () => {
   // Hook window-visibility events from Go before first refresh so a
   // window that opens already-visible doesn't miss the initial event.
   setupVisibilityPause();
+  setupLiveUpdates();
 
   // Initial load
   await refresh();
@@ -259,11 +322,16 @@ document.addEventListener("DOMContentLoaded", async () => {
     localStorage.getItem("refreshInterval") || "2500",
   );
   if (currentIntervalMs < 1000) currentIntervalMs = 1000;
+  try {
+    liveUpdates = await LiveUpdates();
+  } catch (e) {
+    liveUpdates = false;
+  }
   applyPauseState();
 });
 
 // Settings panel management
-function setupSettings() {
+async function setupSettings() {
   const settingsBtn = document.getElementById("settings-btn");
   const settingsCloseBtn = document.getElementById("settings-close-btn");
   const settingsPanel = document.getElementById("settings-panel");
@@ -312,13 +380,16 @@ function setupSettings() {
   });
 
   testConnectionBtn.addEventListener("click", testConnection);
+  document
+    .getElementById("daemon-key-btn")
+    .addEventListener("click", useDaemonAPIKey);
   saveSettingsBtn.addEventListener("click", saveSettings);
 
   // Load saved settings
-  loadSettings();
+  const settings = await loadSettings();
 
   // Setup connection type toggle
-  setupConnectionTypeToggle();
+  setupConnectionTypeToggle(settings.connectionType);
 }
 
 // Save settings to backend
@@ -338,14 +409,9 @@ async function saveSettings() {
   };
 
   try {
+    // Go saves the connection settings to the tray's config directory
     await SaveSettings(settings);
 
-    // Also save to localStorage for persistence
-    localStorage.setItem("connectionType", settings.connectionType);
-    localStorage.setItem("socketPath", settings.socketPath);
-    localStorage.setItem("apiUrl", settings.apiUrl);
-    localStorage.setItem("apiKey", settings.apiKey);
-
     saveBtn.textContent = "Saved";
     settingsDirty = false;
 
@@ -374,20 +440,29 @@ async function saveSettings() {
   }
 }
 
-// Load settings from localStorage
-function loadSettings() {
-  const socketPath = localStorage.getItem("socketPath") || "";
+// Load the connection settings saved by Go, and display settings from
+// localStorage. Returns the connection settings.
+async function loadSettings() {
   const refreshMs = localStorage.getItem("refreshInterval") || "2500";
-  const apiUrl = localStorage.getItem("apiUrl") || "";
-  const apiKey = localStorage.getItem("apiKey") || "";
+  let settings = {
+    connectionType: "socket",
+    socketPath: "",
+    apiUrl: "",
+    apiKey: "",
+  };
+  try {
+    settings = await migrateLocalSettings(await GetSettings());
+  } catch (e) {
+    console.error("Failed to load settings:", e);
+  }
 
-  document.getElementById("socket-path").value = socketPath;
+  document.getElementById("socket-path").value = settings.socketPath || "";
   document.getElementById("refresh-interval").value = refreshMs;
   // Only set API URL if there's a saved value, otherwise keep the default
-  if (apiUrl) {
-    document.getElementById("api-url").value = apiUrl;
+  if (settings.apiUrl) {
+    document.getElementById("api-url").value = settings.apiUrl;
   }
-  document.getElementById("api-key").value = apiKey;
+  document.getElementById("api-key").value = settings.apiKey || "";
 
   // Update refresh interval
   const interval = parseInt(refreshMs);
@@ -418,6 +493,44 @@ function loadSettings() {
   document.getElementById("api-key").addEventListener("input", markDirty);
   document.getElementById("conn-socket").addEventListener("change", markDirty);
   document.getElementById("conn-http").addEventListener("change", markDirty);
+
+  return settings;
+}
+
+// Older versions kept the connection settings, API key included, in
+// localStorage and never applied them at startup. Hand them to Go once, which
+// saves them properly, and remove them from localStorage.
+async function migrateLocalSettings(settings) {
+  const keys = ["connectionType", "socketPath", "apiUrl", "apiKey"];
+  const legacy = Object.fromEntries(
+    keys.map((k) => [k, localStorage.getItem(k) || ""]),
+  );
+  const hasSaved =
+    settings.connectionType !== "socket" ||
+    settings.socketPath ||
+    settings.apiUrl;
+  const hasLegacy =
+    legacy.connectionType === "http"
+      ? legacy.apiUrl && legacy.apiKey
+      : legacy.socketPath;
+
+  if (!hasSaved && hasLegacy) {
+    const migrated = {
+      connectionType: legacy.connectionType === "http" ? "http" : "socket",
+      socketPath: legacy.socketPath,
+      apiUrl: legacy.apiUrl,
+      apiKey: legacy.apiKey,
+    };
+    try {
+      await SaveSettings(migrated);
+      settings = migrated;
+    } catch (e) {
+      console.error("Failed to migrate saved settings:", e);
+      return settings;
+    }
+  }
+  keys.forEach((k) => localStorage.removeItem(k));
+  return settings;
 }
 
 // Test connection - tests with currently saved settings
@@ -437,6 +550,29 @@ async function testConnection() {
   }
 }
 
+// Switch to the HTTP API with a key keylightd issues over its socket, so the
+// user doesn't have to create and paste one
+async function useDaemonAPIKey() {
+  const statusEl = document.getElementById("connection-status");
+  statusEl.textContent = "Requesting key...";
+  statusEl.className = "connection-status";
+
+  try {
+    const settings = await UseDaemonAPIKey();
+    document.getElementById("api-url").value = settings.apiUrl;
+    document.getElementById("api-key").value = settings.apiKey;
+    settingsDirty = false;
+    document.getElementById("save-settings-btn").disabled = true;
+    statusEl.textContent = "Connected with a new key";
+    statusEl.className = "connection-status success";
+    await refresh();
+  } catch (e) {
+    console.error("Get key from keylightd error:", e);
+    statusEl.textContent = "Failed";
+    statusEl.className = "connection-status error";
+  }
+}
+
 // Update visibility lists in settings
 async function updateVisibilityLists() {
   try {
@@ -630,7 +766,7 @@ async function loadCustomCss() {
 }
 
 // Setup connection type toggle
-function setupConnectionTypeToggle() {
+function setupConnectionTypeToggle(savedType) {
   const socketRadio = document.getElementById("conn-socket");
   const httpRadio = document.getElementById("conn-http");
   const socketSettings = document.querySelectorAll(".socket-setting");
@@ -642,7 +778,6 @@ function setupConnectionTypeToggle() {
     socketOption.style.display = "none";
     // Force HTTP mode on Windows
     httpRadio.checked = true;
-    localStorage.setItem("connectionType", "http");
   }
 
   function updateConnectionFields() {
@@ -653,7 +788,10 @@ function setupConnectionTypeToggle() {
     httpSettings.forEach(
       (el) => (el.style.display = isSocket ? "none" : "flex"),
     );
-    localStorage.setItem("connectionType", isSocket ? "socket" : "http");
+    // Keys are issued over the socket, which Windows doesn't have
+    if (isWindows) {
+      document.getElementById("daemon-key-row").style.display = "none";
+    }
 
     // Reset test connection result
     const statusEl = document.getElementById("connection-status");
@@ -664,8 +802,7 @@ function setupConnectionTypeToggle() {
   socketRadio.addEventListener("change", updateConnectionFields);
   httpRadio.addEventListener("change", updateConnectionFields);
 
-  // Load saved connection type (or default to HTTP on Windows)
-  const savedType = localStorage.getItem("connectionType") || "socket";
+  // Show the saved connection type (or default to HTTP on Windows)
   if (savedType === "http" || isWindows) {
     httpRadio.checked = true;
   } else {
@@ -747,19 +884,7 @@ async function refresh() {
   if (pauseReasons.size > 0) return;
 
   try {
-    const status = await GetStatus();
-    // Skip the render path entirely when state hasn't changed since
-    // the last poll. JSON.stringify is cheap for the small status
-    // payload and avoids touching the DOM (and webkit's JS heap)
-    // during steady state.
-    const hash = JSON.stringify(status);
-    if (hash === lastStatusHash) return;
-    lastStatusHash = hash;
-
-    updateUI(status);
-    updateStatusBadge(status.onCount, status.total, true);
-    updateMasterToggle(status.onCount);
-    updateSectionVisibility(status);
+    applyStatus(await GetStatus());
   } catch (e) {
     console.error("Failed to refresh:", e);
     updateStatusBadge(0, 0, false);
@@ -768,6 +893,22 @@ async function refresh() {
   }
 }
 
+// Render a status from a poll or a pushed change
+function applyStatus(status) {
+  // Skip the render path entirely when state hasn't changed since
+  // the last update. JSON.stringify is cheap for the small status
+  // payload and avoids touching the DOM (and webkit's JS heap)
+  // during steady state.
+  const hash = JSON.stringify(status);
+  if (hash === lastStatusHash) return;
+  lastStatusHash = hash;
+
+  updateUI(status);
+  updateStatusBadge(status.onCount, status.total, true);
+  updateMasterToggle(status.onCount);
+  updateSectionVisibility(status);
+}
+
 // Update status badge
 function updateStatusBadge(on, total, connected) {
   const badge = document.getElementById("status-badge");
@@ -983,7 +1124,10 @@ function createControlCard(item, type) {
                         <span class="slider-value">${brightness}%</span>
                     </div>
                 </div>
-                <div class="slider-row">
+                ${
+                  item.hasTemperature === false
+                    ? ""
+                    : `<div class="slider-row">
                     <span class="slider-icon">${TEMP_ICON}</span>
                     <div class="slider-container">
                         <input type="range" class="slider temperature-slider" min="2900" max="7000" value="${temperature}"
@@ -998,7 +1142,8 @@ function createControlCard(item, type) {
                             oninput="updateSliderFill(this)">
                         <span class="slider-value">${temperature}K</span>
                     </div>
-                </div>
+                </div>`
+                }
             </div>
         </div>
     `;
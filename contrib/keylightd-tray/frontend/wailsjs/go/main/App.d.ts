@@ -18,6 +18,8 @@ export function GetStatus():Promise<main.Status>;
 
 export function GetVersion():Promise<string>;
 
+export function LiveUpdates():Promise<boolean>;
+
 export function Ping():Promise<void>;
 
 export function SetGroupLights(arg1:string,arg2:Array<string>):Promise<void>;
@@ -34,6 +34,10 @@ export function GetVersion() {
   return window['go']['main']['App']['GetVersion']();
 }
 
+export function LiveUpdates() {
+  return window['go']['main']['App']['LiveUpdates']();
+}
+
 export function Ping() {
   return window['go']['main']['App']['Ping']();
 }
@@ -7,6 +7,7 @@ export namespace main {
 	    on: boolean;
 	    brightness: number;
 	    temperature: number;
+	    hasTemperature: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new Group(source);
@@ -20,6 +21,7 @@ export namespace main {
 	        this.on = source["on"];
 	        this.brightness = source["brightness"];
 	        this.temperature = source["temperature"];
+	        this.hasTemperature = source["hasTemperature"];
 	    }
 	}
 	export class Light {
@@ -28,6 +30,7 @@ export namespace main {
 	    on: boolean;
 	    brightness: number;
 	    temperature: number;
+	    hasTemperature: boolean;
 	    productName: string;
 	    serialNumber: string;
 	
@@ -42,6 +45,7 @@ export namespace main {
 	        this.on = source["on"];
 	        this.brightness = source["brightness"];
 	        this.temperature = source["temperature"];
+	        this.hasTemperature = source["hasTemperature"];
 	        this.productName = source["productName"];
 	        this.serialNumber = source["serialNumber"];
 	    }
@@ -7,7 +7,6 @@ import (
 	"net/http/pprof"
 	"time"
 
-	"fyne.io/systray"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -62,16 +61,13 @@ func main() {
 	tray := NewTrayManager(app)
 	app.SetTrayManager(tray)
 
-	// Run systray in a goroutine
-	go systray.Run(tray.OnReady, tray.OnExit)
-
 	err := wails.Run(&options.App{
 		Title:             "Keylight Control",
 		Width:             380,
 		Height:            600,
 		MinWidth:          320,
 		MinHeight:         400,
-		StartHidden:       true, // Start hidden, show via tray
+		StartHidden:       true, // Start hidden, show via tray (shown on startup if the tray fails)
 		HideWindowOnClose: true, // Close to tray instead of quit
 		AssetServer: &assetserver.Options{
 			Assets: assets,
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+// settingsFileName is the connection settings file in the config directory.
+// It holds the API key, so it is only readable by the user.
+const settingsFileName = "settings.json"
+
+// getSettingsPath returns the path to the connection settings file
+func (a *App) getSettingsPath() string {
+	return filepath.Join(a.getConfigDir(), settingsFileName)
+}
+
+// loadSettings reads the saved connection settings. It returns fs.ErrNotExist
+// if none have been saved.
+func (a *App) loadSettings() (Settings, error) {
+	var settings Settings
+	data, err := os.ReadFile(a.getSettingsPath())
+	if err != nil {
+		return settings, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("invalid settings file %s: %w", a.getSettingsPath(), err)
+	}
+	return settings, nil
+}
+
+// storeSettings saves the connection settings, replacing the file atomically
+// so a crash can't leave it half written.
+func (a *App) storeSettings(settings Settings) error {
+	path := a.getSettingsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	// WriteFile only applies the mode to new files
+	if err := os.Chmod(tmp, 0600); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+// newClient creates a daemon client for the connection settings
+func (a *App) newClient(settings Settings) (client.ClientInterface, error) {
+	if settings.ConnectionType == "http" {
+		if settings.APIUrl == "" {
+			return nil, errors.New("API URL is required for HTTP connection")
+		}
+		if settings.APIKey == "" {
+			return nil, errors.New("API key is required for HTTP connection")
+		}
+		return client.NewHTTP(a.logger, settings.APIUrl, settings.APIKey), nil
+	}
+
+	if settings.SocketPath != "" {
+		return client.New(a.logger, settings.SocketPath), nil
+	}
+	// Look for the daemon's socket in the usual places; if it isn't running
+	// yet, use the default one so the tray connects once it starts
+	c, err := client.Discover(context.Background(), a.logger, client.DiscoverOptions{})
+	if err != nil {
+		a.logger.Debug("Daemon not found, using the default socket", "error", err)
+		return client.New(a.logger, config.GetRuntimeSocketPath()), nil
+	}
+	return c, nil
+}
+
+// uiClientName identifies the tray's API key, which keylightd names "ui-tray".
+const uiClientName = "tray"
+
+// UseDaemonAPIKey switches to the HTTP API with a key issued by keylightd over
+// the socket, so the user doesn't have to create and paste one. The key can
+// read and control lights but not manage keys or configuration.
+func (a *App) UseDaemonAPIKey() (Settings, error) {
+	sc := client.New(a.logger, cmp.Or(a.settings.SocketPath, config.GetRuntimeSocketPath()))
+	defer sc.Close()
+
+	key, err := sc.BootstrapUIKey(uiClientName)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to get an API key from keylightd: %w", err)
+	}
+	if key.APIURL == "" {
+		return Settings{}, errors.New("keylightd is not serving the HTTP API")
+	}
+
+	settings := Settings{
+		ConnectionType: "http",
+		SocketPath:     a.settings.SocketPath,
+		APIUrl:         key.APIURL,
+		APIKey:         key.Key.Key,
+	}
+	if err := a.SaveSettings(settings); err != nil {
+		return Settings{}, err
+	}
+	return settings, nil
+}
+
+// restoreSettings connects with the saved connection settings, falling back
+// to the default socket when there are none or they can't be used.
+func (a *App) restoreSettings() {
+	settings, err := a.loadSettings()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			a.logger.Warn("Failed to load settings, using the default socket", "error", err)
+		}
+		settings = Settings{ConnectionType: "socket"}
+	}
+	c, err := a.newClient(settings)
+	if err != nil {
+		a.logger.Warn("Saved settings are incomplete, using the default socket", "error", err)
+		settings = Settings{ConnectionType: "socket"}
+		c, _ = a.newClient(settings)
+	}
+	a.settings = settings
+	a.setClient(c)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmylchreest/keylightd/pkg/client"
+)
+
+func TestStoreAndLoadSettings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := &App{logger: slog.New(slog.DiscardHandler)}
+
+	if _, err := app.loadSettings(); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("loadSettings() with no file = %v, want fs.ErrNotExist", err)
+	}
+
+	want := Settings{ConnectionType: "http", APIUrl: "http://desk:9123", APIKey: "secret"}
+	if err := app.storeSettings(want); err != nil {
+		t.Fatalf("storeSettings() = %v", err)
+	}
+	got, err := app.loadSettings()
+	if err != nil {
+		t.Fatalf("loadSettings() = %v", err)
+	}
+	if got != want {
+		t.Errorf("loadSettings() = %+v, want %+v", got, want)
+	}
+
+	info, err := os.Stat(app.getSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("settings file mode = %o, want 600 as it holds the API key", perm)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	app := &App{logger: slog.New(slog.DiscardHandler)}
+
+	c, err := app.newClient(Settings{ConnectionType: "http", APIUrl: "http://desk:9123", APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("newClient(http) = %v", err)
+	}
+	if _, ok := c.(*client.HTTPClient); !ok {
+		t.Errorf("newClient(http) = %T, want *client.HTTPClient", c)
+	}
+
+	if _, err := app.newClient(Settings{ConnectionType: "http", APIUrl: "http://desk:9123"}); err == nil {
+		t.Error("newClient(http) without an API key should fail")
+	}
+
+	c, err = app.newClient(Settings{ConnectionType: "socket"})
+	if err != nil {
+		t.Fatalf("newClient(socket) = %v", err)
+	}
+	if _, ok := c.(*client.Client); !ok {
+		t.Errorf("newClient(socket) = %T, want *client.Client", c)
+	}
+}
+
+// serveBootstrap answers apikey_bootstrap requests on a unix socket like
+// keylightd, with a key for the given API URL.
+func serveBootstrap(t *testing.T, apiURL string) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "keylightd.sock")
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var req map[string]any
+					if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+						return
+					}
+					resp := map[string]any{"id": req["id"]}
+					if req["action"] == "apikey_bootstrap" {
+						resp["status"] = "ok"
+						resp["key"] = map[string]any{"name": "ui-tray", "key": "ui-secret", "scopes": []string{"read", "control"}}
+						resp["api_url"] = apiURL
+					} else {
+						resp["error"] = "unknown action: " + req["action"].(string)
+						resp["code"] = "unknown_action"
+					}
+					if err := json.NewEncoder(conn).Encode(resp); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return socketPath
+}
+
+func TestUseDaemonAPIKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	socketPath := serveBootstrap(t, "http://127.0.0.1:9123")
+	app := &App{logger: slog.New(slog.DiscardHandler), ctx: t.Context(), emit: func(string, ...any) {}}
+	app.settings = Settings{ConnectionType: "socket", SocketPath: socketPath}
+
+	settings, err := app.UseDaemonAPIKey()
+	if err != nil {
+		t.Fatalf("UseDaemonAPIKey() = %v", err)
+	}
+	want := Settings{ConnectionType: "http", SocketPath: socketPath, APIUrl: "http://127.0.0.1:9123", APIKey: "ui-secret"}
+	if settings != want {
+		t.Errorf("UseDaemonAPIKey() = %+v, want %+v", settings, want)
+	}
+	if _, ok := app.client.(*client.HTTPClient); !ok {
+		t.Errorf("client = %T, want *client.HTTPClient", app.client)
+	}
+	saved, err := app.loadSettings()
+	if err != nil {
+		t.Fatalf("loadSettings() = %v", err)
+	}
+	if saved != want {
+		t.Errorf("saved settings = %+v, want %+v", saved, want)
+	}
+}
+
+func TestUseDaemonAPIKey_NoHTTPAPI(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := &App{logger: slog.New(slog.DiscardHandler), ctx: t.Context(), emit: func(string, ...any) {}}
+	app.settings = Settings{ConnectionType: "socket", SocketPath: serveBootstrap(t, "")}
+
+	if _, err := app.UseDaemonAPIKey(); err == nil {
+		t.Fatal("UseDaemonAPIKey() should fail when the daemon doesn't serve the HTTP API")
+	}
+	if _, err := app.loadSettings(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loadSettings() = %v, want no settings saved", err)
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"testing"
+
+	"github.com/jmylchreest/keylightd/contrib/keylightd-tray/traymenu"
 )
 
 func TestFormatCount(t *testing.T) {
@@ -29,3 +31,51 @@ func TestFormatCount(t *testing.T) {
 		})
 	}
 }
+
+func TestTrayMenu(t *testing.T) {
+	t.Run("before the first status", func(t *testing.T) {
+		m := trayMenu(nil, true)
+		if m.Icon != traymenu.IconUnknown || m.Tooltip != "Keylight Control" || !m.WindowShown {
+			t.Errorf("trayMenu(nil) = %+v", m)
+		}
+	})
+
+	t.Run("no lights", func(t *testing.T) {
+		m := trayMenu(&Status{}, false)
+		if m.Icon != traymenu.IconUnknown || m.Tooltip != "Keylight Control - No lights" {
+			t.Errorf("trayMenu() = %+v", m)
+		}
+	})
+
+	t.Run("lights on", func(t *testing.T) {
+		status := &Status{
+			Groups:  []Group{{ID: "group-1", Name: "Desk", On: true}},
+			Lights:  []Light{{ID: "light-1", Name: "Left", On: true}, {ID: "light-2", Name: "Right"}},
+			OnCount: 1,
+			Total:   2,
+		}
+		m := trayMenu(status, false)
+		if m.Icon != traymenu.IconEnabled {
+			t.Errorf("Icon = %s, want %s", m.Icon, traymenu.IconEnabled)
+		}
+		wantGroups := []traymenu.Item{{ID: "group-1", Title: "✓ Desk"}}
+		wantLights := []traymenu.Item{{ID: "light-1", Title: "✓ Left"}, {ID: "light-2", Title: "  Right"}}
+		if len(m.Groups) != 1 || m.Groups[0] != wantGroups[0] {
+			t.Errorf("Groups = %+v, want %+v", m.Groups, wantGroups)
+		}
+		if len(m.Lights) != 2 || m.Lights[0] != wantLights[0] || m.Lights[1] != wantLights[1] {
+			t.Errorf("Lights = %+v, want %+v", m.Lights, wantLights)
+		}
+		wantTooltip := "Keylight Control\n\nGroups\n✓ Desk\n\nLights\n✓ Left\n  Right\n"
+		if m.Tooltip != wantTooltip {
+			t.Errorf("Tooltip = %q, want %q", m.Tooltip, wantTooltip)
+		}
+	})
+
+	t.Run("lights off", func(t *testing.T) {
+		m := trayMenu(&Status{Lights: []Light{{ID: "light-1", Name: "Left"}}, Total: 1}, false)
+		if m.Icon != traymenu.IconDisabled {
+			t.Errorf("Icon = %s, want %s", m.Icon, traymenu.IconDisabled)
+		}
+	})
+}
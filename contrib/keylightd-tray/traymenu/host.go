@@ -0,0 +1,214 @@
+package traymenu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Helper restart backoff.
+var (
+	restartMin = time.Second
+	restartMax = 30 * time.Second
+)
+
+// HelperPath returns the path of the helper binary installed next to the
+// running executable. In a macOS app bundle that is Contents/MacOS.
+func HelperPath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find executable: %w", err)
+	}
+	name := HelperName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(filepath.Dir(exe), name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("tray helper not found: %w", err)
+	}
+	return path, nil
+}
+
+// Host runs the helper process for the app, sending it menus and passing the
+// actions chosen in the tray to a callback. If the helper exits it is
+// restarted with backoff and sent the last menu again.
+type Host struct {
+	logger   *slog.Logger
+	command  func() *exec.Cmd
+	onAction func(Action)
+
+	mu      sync.Mutex
+	stdin   io.WriteCloser // nil while the helper isn't running
+	last    []byte         // last menu sent, as a line of JSON
+	closed  bool
+	stopped chan struct{}
+}
+
+// NewHost returns a Host that starts the helper with command and calls
+// onAction for each action chosen in the tray.
+func NewHost(logger *slog.Logger, command func() *exec.Cmd, onAction func(Action)) *Host {
+	return &Host{
+		logger:   logger,
+		command:  command,
+		onAction: onAction,
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start starts the helper. It fails if the helper can't be started; once it
+// has been, later failures are logged and the helper restarted.
+func (h *Host) Start() error {
+	cmd, stdin, stdout, err := h.start()
+	if err != nil {
+		return err
+	}
+	go h.run(cmd, stdin, stdout)
+	return nil
+}
+
+// start starts one helper process.
+func (h *Host) start() (*exec.Cmd, io.WriteCloser, io.ReadCloser, error) {
+	cmd := h.command()
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create tray helper stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create tray helper stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to start tray helper: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		stdin.Close()
+		_ = cmd.Wait()
+		return nil, nil, nil, errors.New("tray closed")
+	}
+	h.stdin = stdin
+	if h.last != nil {
+		if _, err := stdin.Write(h.last); err != nil {
+			h.logger.Debug("tray: failed to send menu", "error", err)
+		}
+	}
+	return cmd, stdin, stdout, nil
+}
+
+// run reads actions from the helper until it exits, restarting it until the
+// host is closed.
+func (h *Host) run(cmd *exec.Cmd, stdin io.WriteCloser, stdout io.ReadCloser) {
+	defer close(h.stopped)
+	retry := restartMin
+	for {
+		started := time.Now()
+		h.readActions(stdout)
+		err := cmd.Wait()
+
+		h.mu.Lock()
+		h.stdin = nil
+		closed := h.closed
+		h.mu.Unlock()
+		stdin.Close()
+		if closed {
+			return
+		}
+
+		h.logger.Warn("tray: helper exited, restarting", "error", err, "retry", retry)
+		if time.Since(started) > restartMax {
+			retry = restartMin
+		}
+		for {
+			time.Sleep(retry)
+			retry = min(retry*2, restartMax)
+			cmd, stdin, stdout, err = h.start()
+			if err == nil {
+				break
+			}
+			if h.isClosed() {
+				return
+			}
+			h.logger.Warn("tray: failed to restart helper", "error", err, "retry", retry)
+		}
+	}
+}
+
+// readActions passes each action read from the helper to the callback.
+func (h *Host) readActions(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		var a Action
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			h.logger.Debug("tray: ignoring invalid action", "error", err)
+			continue
+		}
+		h.onAction(a)
+	}
+}
+
+func (h *Host) isClosed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+// Send shows a menu in the tray. Menus identical to the last one are not
+// sent, as every update makes the desktop redraw the tray.
+func (h *Host) Send(m Menu) {
+	line, err := json.Marshal(m)
+	if err != nil {
+		h.logger.Error("tray: failed to encode menu", "error", err)
+		return
+	}
+	line = append(line, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if bytes.Equal(line, h.last) {
+		return
+	}
+	h.last = line
+	if h.stdin == nil {
+		return
+	}
+	if _, err := h.stdin.Write(line); err != nil {
+		h.logger.Debug("tray: failed to send menu", "error", err)
+	}
+}
+
+// Close stops the helper, which removes the tray icon, and waits for it to
+// exit.
+func (h *Host) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	stdin := h.stdin
+	h.mu.Unlock()
+
+	if stdin == nil {
+		return
+	}
+	stdin.Close()
+	select {
+	case <-h.stopped:
+	case <-time.After(5 * time.Second):
+		h.logger.Warn("tray: helper did not exit")
+	}
+}
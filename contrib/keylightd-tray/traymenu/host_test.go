@@ -0,0 +1,105 @@
+package traymenu
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess isn't a real test; it is the helper process started by
+// the Host tests. It answers each menu with an action carrying the menu's
+// tooltip, and with TRAYMENU_HELPER=exit exits after the first menu.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv("TRAYMENU_HELPER")
+	if mode == "" {
+		return
+	}
+	dec := json.NewDecoder(os.Stdin)
+	w := NewActionWriter(os.Stdout)
+	for {
+		var m Menu
+		if err := dec.Decode(&m); err != nil {
+			os.Exit(0)
+		}
+		_ = w.Write(Action{Type: ActionToggleLight, ID: m.Tooltip})
+		if mode == "exit" {
+			os.Exit(1)
+		}
+	}
+}
+
+func newTestHost(t *testing.T, mode string) (*Host, <-chan Action) {
+	t.Helper()
+	actions := make(chan Action, 10)
+	host := NewHost(slog.New(slog.NewTextHandler(io.Discard, nil)), func() *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$") //nolint:gosec // G204: the test binary
+		cmd.Env = append(os.Environ(), "TRAYMENU_HELPER="+mode)
+		return cmd
+	}, func(a Action) { actions <- a })
+	if err := host.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(host.Close)
+	return host, actions
+}
+
+func receiveAction(t *testing.T, actions <-chan Action) Action {
+	t.Helper()
+	select {
+	case a := <-actions:
+		return a
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an action")
+		return Action{}
+	}
+}
+
+func TestHost(t *testing.T) {
+	host, actions := newTestHost(t, "echo")
+
+	host.Send(Menu{Tooltip: "first"})
+	host.Send(Menu{Tooltip: "first"}) // unchanged, not sent
+	host.Send(Menu{Tooltip: "second"})
+
+	if a := receiveAction(t, actions); a.Type != ActionToggleLight || a.ID != "first" {
+		t.Errorf("first action = %+v", a)
+	}
+	if a := receiveAction(t, actions); a.ID != "second" {
+		t.Errorf("second action = %+v, want the second menu's", a)
+	}
+	select {
+	case a := <-actions:
+		t.Errorf("unexpected action %+v", a)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHost_RestartsHelper(t *testing.T) {
+	oldMin := restartMin
+	restartMin = 10 * time.Millisecond
+	t.Cleanup(func() { restartMin = oldMin })
+
+	host, actions := newTestHost(t, "exit")
+	host.Send(Menu{Tooltip: "menu"})
+
+	// The helper exits after each menu; once restarted it is sent the last
+	// menu again
+	for range 2 {
+		if a := receiveAction(t, actions); a.ID != "menu" {
+			t.Errorf("action = %+v, want the last menu's", a)
+		}
+	}
+}
+
+func TestHost_StartFails(t *testing.T) {
+	host := NewHost(slog.New(slog.NewTextHandler(io.Discard, nil)), func() *exec.Cmd {
+		return exec.Command("/nonexistent/" + HelperName)
+	}, func(Action) {})
+	if err := host.Start(); err == nil {
+		t.Error("Start() should fail when the helper can't be run")
+	}
+}
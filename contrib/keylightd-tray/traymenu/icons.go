@@ -0,0 +1,57 @@
+package traymenu
+
+import (
+	_ "embed"
+	"encoding/binary"
+)
+
+//go:embed icons/light-enabled.png
+var iconEnabled []byte
+
+//go:embed icons/light-disabled.png
+var iconDisabled []byte
+
+//go:embed icons/light-unknown.png
+var iconUnknown []byte
+
+// IconPNG returns the PNG image of a tray icon.
+func IconPNG(icon Icon) []byte {
+	switch icon {
+	case IconEnabled:
+		return iconEnabled
+	case IconDisabled:
+		return iconDisabled
+	default:
+		return iconUnknown
+	}
+}
+
+// PNGToICO wraps a PNG image in an ICO container, which the Windows tray
+// requires. ICO files may hold PNG data directly since Windows Vista.
+func PNGToICO(png []byte) []byte {
+	// Width and height are the first fields of the IHDR chunk
+	var width, height uint32
+	if len(png) >= 24 {
+		width = binary.BigEndian.Uint32(png[16:20])
+		height = binary.BigEndian.Uint32(png[20:24])
+	}
+	// A size of 0 in the directory entry means 256 pixels or more
+	dimension := func(n uint32) byte {
+		if n >= 256 {
+			return 0
+		}
+		return byte(n)
+	}
+
+	const headerSize = 6 + 16 // ICONDIR plus one ICONDIRENTRY
+	ico := make([]byte, headerSize, headerSize+len(png))
+	binary.LittleEndian.PutUint16(ico[2:], 1) // image type: icon
+	binary.LittleEndian.PutUint16(ico[4:], 1) // image count
+	ico[6] = dimension(width)
+	ico[7] = dimension(height)
+	binary.LittleEndian.PutUint16(ico[10:], 1)                // color planes
+	binary.LittleEndian.PutUint16(ico[12:], 32)               // bits per pixel
+	binary.LittleEndian.PutUint32(ico[14:], uint32(len(png))) //nolint:gosec // G115: the icons are a few KiB
+	binary.LittleEndian.PutUint32(ico[18:], headerSize)
+	return append(ico, png...)
+}
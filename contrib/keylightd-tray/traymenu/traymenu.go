@@ -0,0 +1,104 @@
+// Package traymenu runs the tray icon and menu of keylightd-tray in a helper
+// process, keylightd-tray-menu, and defines the messages the two exchange.
+//
+// Wails and the systray library both define an Objective-C AppDelegate on
+// macOS, so they can't be linked into one binary. Keeping the systray in its
+// own process lets the app build on macOS, and gives Windows and Linux the
+// same arrangement. The app writes a Menu to the helper's stdin whenever the
+// lights change, and reads the Actions chosen in the menu from its stdout,
+// one JSON object per line. The helper exits when its stdin is closed.
+package traymenu
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// HelperName is the file name of the helper binary, without the .exe suffix
+// used on Windows. It is installed next to keylightd-tray.
+const HelperName = "keylightd-tray-menu"
+
+// Icon names the tray icon to show.
+type Icon string
+
+// Tray icons.
+const (
+	IconUnknown  Icon = "unknown"  // no lights
+	IconEnabled  Icon = "enabled"  // at least one light on
+	IconDisabled Icon = "disabled" // every light off
+)
+
+// Item is a light or group entry in the menu.
+type Item struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Menu is the state of the tray icon and menu.
+type Menu struct {
+	Icon        Icon   `json:"icon"`
+	Tooltip     string `json:"tooltip"`
+	WindowShown bool   `json:"window_shown"` // the first item reads Hide rather than Show
+	Groups      []Item `json:"groups,omitempty"`
+	Lights      []Item `json:"lights,omitempty"`
+}
+
+// ActionType identifies what was chosen in the tray.
+type ActionType string
+
+// Tray actions.
+const (
+	ActionToggleWindow ActionType = "toggle_window" // the icon or Show/Hide was clicked
+	ActionQuit         ActionType = "quit"
+	ActionToggleGroup  ActionType = "toggle_group"
+	ActionToggleLight  ActionType = "toggle_light"
+)
+
+// Action is something chosen in the tray.
+type Action struct {
+	Type ActionType `json:"type"`
+	ID   string     `json:"id,omitempty"` // light or group ID for toggles
+}
+
+// Tray shows menus in the system tray. keylightd-tray-menu implements it
+// with the systray library.
+type Tray interface {
+	Show(m Menu)
+}
+
+// Serve shows each menu read from r on tray until r is closed, which is how
+// the app tells the helper to exit.
+func Serve(r io.Reader, tray Tray) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var m Menu
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return fmt.Errorf("invalid menu: %w", err)
+		}
+		tray.Show(m)
+	}
+	return scanner.Err()
+}
+
+// ActionWriter writes actions to the app, one per line. It is safe for
+// concurrent use by the menu's click handlers.
+type ActionWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewActionWriter returns an ActionWriter writing to w.
+func NewActionWriter(w io.Writer) *ActionWriter {
+	return &ActionWriter{enc: json.NewEncoder(w)}
+}
+
+// Write sends an action to the app.
+func (w *ActionWriter) Write(a Action) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(a)
+}
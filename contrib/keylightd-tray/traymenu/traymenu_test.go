@@ -0,0 +1,82 @@
+package traymenu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+type recordingTray struct {
+	menus []Menu
+}
+
+func (t *recordingTray) Show(m Menu) {
+	t.menus = append(t.menus, m)
+}
+
+func TestServe(t *testing.T) {
+	input := `{"icon":"enabled","tooltip":"one","lights":[{"id":"l1","title":"✓ Light"}]}
+{"icon":"disabled","tooltip":"two","window_shown":true}
+`
+	tray := &recordingTray{}
+	if err := Serve(strings.NewReader(input), tray); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	if len(tray.menus) != 2 {
+		t.Fatalf("got %d menus, want 2", len(tray.menus))
+	}
+	if m := tray.menus[0]; m.Icon != IconEnabled || len(m.Lights) != 1 || m.Lights[0].ID != "l1" {
+		t.Errorf("first menu = %+v", m)
+	}
+	if m := tray.menus[1]; m.Icon != IconDisabled || !m.WindowShown || len(m.Lights) != 0 {
+		t.Errorf("second menu = %+v", m)
+	}
+}
+
+func TestServe_InvalidMenu(t *testing.T) {
+	if err := Serve(strings.NewReader("not json\n"), &recordingTray{}); err == nil {
+		t.Error("Serve() should fail on an invalid menu")
+	}
+}
+
+func TestActionWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewActionWriter(&buf)
+	if err := w.Write(Action{Type: ActionToggleGroup, ID: "group-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(Action{Type: ActionQuit}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"toggle_group","id":"group-1"}
+{"type":"quit"}
+`
+	if buf.String() != want {
+		t.Errorf("wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPNGToICO(t *testing.T) {
+	for _, icon := range []Icon{IconUnknown, IconEnabled, IconDisabled} {
+		png := IconPNG(icon)
+		ico := PNGToICO(png)
+
+		if got := binary.LittleEndian.Uint16(ico[2:]); got != 1 {
+			t.Errorf("%s: image type = %d, want 1", icon, got)
+		}
+		if got := binary.LittleEndian.Uint16(ico[4:]); got != 1 {
+			t.Errorf("%s: image count = %d, want 1", icon, got)
+		}
+		if ico[6] != 64 || ico[7] != 64 {
+			t.Errorf("%s: size = %dx%d, want 64x64", icon, ico[6], ico[7])
+		}
+		if got := binary.LittleEndian.Uint32(ico[14:]); int(got) != len(png) {
+			t.Errorf("%s: image size = %d, want %d", icon, got, len(png))
+		}
+		offset := binary.LittleEndian.Uint32(ico[18:])
+		if !bytes.Equal(ico[offset:], png) {
+			t.Errorf("%s: image data does not match the PNG", icon)
+		}
+	}
+}
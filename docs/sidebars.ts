@@ -14,6 +14,7 @@ const sidebars: SidebarsConfig = {
       },
       items: [
         'supported-devices/elgato',
+        'supported-devices/wled',
       ],
     },
     {
@@ -34,6 +35,11 @@ const sidebars: SidebarsConfig = {
         'groups/socket',
       ],
     },
+    'schedules',
+    'circadian',
+    'desired-state',
+    'home-assistant',
+    'homekit',
     {
       type: 'category',
       label: 'Desktop Apps',
@@ -57,6 +63,9 @@ const sidebars: SidebarsConfig = {
           items: apiSidebar as any[],
         },
         'api/unix-socket',
+        'api/websocket',
+        'api/grpc',
+        'api/clients',
       ],
     },
   ],
@@ -0,0 +1,181 @@
+// Package advertise announces keylightd's HTTP API on the local network with
+// mDNS/DNS-SD, so the tray app, GNOME extension and keylightctl can find the
+// daemon without a configured URL.
+package advertise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/grandcat/zeroconf"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// ServiceType is the DNS-SD service type the API is announced as.
+const ServiceType = "_keylightd._tcp"
+
+// Domain is the mDNS domain services are announced in.
+const Domain = "local."
+
+// TXT record keys describing the announced API.
+const (
+	TXTVersion = "version" // daemon version
+	TXTPath    = "path"    // base path the API is served under; empty for the root
+	TXTTLS     = "tls"     // "1" when the API is served over HTTPS
+	TXTAuth    = "auth"    // how requests authenticate: api_key or client_cert
+	TXTCaps    = "caps"    // comma-separated optional features: ws, metrics, grpc
+	TXTGRPC    = "grpc"    // port gRPC is served on over TCP, if it is
+)
+
+// Auth values of the TXTAuth record.
+const (
+	AuthAPIKey     = "api_key"
+	AuthClientCert = "client_cert"
+)
+
+// Advertiser announces the HTTP API while it runs.
+type Advertiser struct {
+	logger   *slog.Logger
+	cfg      config.AdvertiseConfig
+	name     string
+	host     string // IP the API listens on; empty when it listens on every address
+	port     int
+	txt      []string
+	hostname string
+}
+
+// New creates an Advertiser for the first API listener reachable from the
+// network. It fails if every listener is a Unix socket or bound to loopback.
+func New(logger *slog.Logger, cfg *config.ConfigBlock, version string) (*Advertiser, error) {
+	host, port, err := networkListener(cfg.API.Listeners())
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	name := cfg.API.Advertise.Name
+	if name == "" {
+		name = "keylightd"
+		if short, _, _ := strings.Cut(hostname, "."); short != "" {
+			name += " on " + short
+		}
+	}
+	return &Advertiser{
+		logger:   logger,
+		cfg:      cfg.API.Advertise,
+		name:     name,
+		host:     host,
+		port:     port,
+		txt:      TXTRecords(cfg, version),
+		hostname: hostname,
+	}, nil
+}
+
+// networkListener returns the host and port of the first TCP listener that
+// isn't bound to loopback. The host is empty for listeners on every address.
+func networkListener(listeners []config.APIListener) (string, int, error) {
+	for _, l := range listeners {
+		if _, unix := l.UnixPath(); unix {
+			continue
+		}
+		host, portStr, err := net.SplitHostPort(l.Address)
+		if err != nil {
+			continue
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port == 0 {
+			continue
+		}
+		if host == "localhost" {
+			continue
+		}
+		ip := net.ParseIP(host)
+		switch {
+		case host == "" || (ip != nil && ip.IsUnspecified()):
+			return "", port, nil
+		case ip != nil && !ip.IsLoopback():
+			return ip.String(), port, nil
+		}
+	}
+	return "", 0, errors.New("api.advertise needs an api listener reachable from the network, not a Unix socket or loopback address")
+}
+
+// TXTRecords describes the API configured in cfg for the announcement.
+func TXTRecords(cfg *config.ConfigBlock, version string) []string {
+	tls := "0"
+	if cfg.API.TLS.Enabled() {
+		tls = "1"
+	}
+	auth := AuthAPIKey
+	if cfg.API.TLS.Enabled() && cfg.API.TLS.ClientCAFile != "" {
+		auth = AuthClientCert
+	}
+	caps := []string{"ws"}
+	if cfg.API.MetricsEnabled {
+		caps = append(caps, "metrics")
+	}
+	txt := []string{
+		TXTVersion + "=" + version,
+		TXTPath + "=" + cfg.API.NormalizedBasePath(),
+		TXTTLS + "=" + tls,
+		TXTAuth + "=" + auth,
+	}
+	if _, port, err := net.SplitHostPort(cfg.GRPC.ListenAddress); err == nil {
+		caps = append(caps, "grpc")
+		txt = append(txt, TXTGRPC+"="+port)
+	}
+	return append(txt, TXTCaps+"="+strings.Join(caps, ","))
+}
+
+// Run announces the API until ctx is cancelled.
+func (a *Advertiser) Run(ctx context.Context) error {
+	ifaces := a.interfaces()
+	if len(a.cfg.Interfaces) > 0 && len(ifaces) == 0 {
+		return fmt.Errorf("none of the api.advertise interfaces are usable: %s", strings.Join(a.cfg.Interfaces, ", "))
+	}
+
+	var (
+		server *zeroconf.Server
+		err    error
+	)
+	if a.host == "" {
+		server, err = zeroconf.Register(a.name, ServiceType, Domain, a.port, a.txt, ifaces)
+	} else {
+		// Only announce the address the API listens on
+		server, err = zeroconf.RegisterProxy(a.name, ServiceType, Domain, a.port, a.hostname, []string{a.host}, a.txt, ifaces)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to announce the API over mDNS: %w", err)
+	}
+	defer server.Shutdown()
+	a.logger.Info("Announcing API over mDNS", "name", a.name, "service", ServiceType, "port", a.port)
+
+	<-ctx.Done()
+	return nil
+}
+
+// interfaces resolves the configured interfaces, skipping with a warning any
+// that don't exist, are down or don't support multicast. It returns nil,
+// letting zeroconf pick, when none are configured.
+func (a *Advertiser) interfaces() []net.Interface {
+	var ifaces []net.Interface
+	for _, name := range a.cfg.Interfaces {
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			a.logger.Warn("advertise: interface not found, skipping", "interface", name, "error", err)
+			continue
+		}
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagMulticast == 0 {
+			a.logger.Warn("advertise: interface is down or does not support multicast, skipping", "interface", name)
+			continue
+		}
+		ifaces = append(ifaces, *iface)
+	}
+	return ifaces
+}
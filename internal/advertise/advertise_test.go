@@ -0,0 +1,77 @@
+package advertise
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+func TestNetworkListener(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []config.APIListener
+		host      string
+		port      int
+		wantErr   bool
+	}{
+		{name: "every address", listeners: []config.APIListener{{Address: ":9123"}}, port: 9123},
+		{name: "unspecified IP", listeners: []config.APIListener{{Address: "0.0.0.0:9123"}}, port: 9123},
+		{name: "specific IP", listeners: []config.APIListener{{Address: "192.168.1.5:9124"}}, host: "192.168.1.5", port: 9124},
+		{
+			name: "skips loopback and sockets",
+			listeners: []config.APIListener{
+				{Address: "127.0.0.1:9123"},
+				{Address: "unix:///run/keylightd-http.sock", Auth: config.ListenerAuthNone},
+				{Address: "[::]:9125"},
+			},
+			port: 9125,
+		},
+		{name: "loopback only", listeners: []config.APIListener{{Address: "localhost:9123"}, {Address: "[::1]:9123"}}, wantErr: true},
+		{name: "no listeners", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port, err := networkListener(tt.listeners)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.port, port)
+		})
+	}
+}
+
+func TestTXTRecords(t *testing.T) {
+	cfg := &config.ConfigBlock{API: config.APIConfig{ListenAddress: ":9123"}}
+	assert.Equal(t, []string{"version=1.2.3", "path=", "tls=0", "auth=api_key", "caps=ws"}, TXTRecords(cfg, "1.2.3"))
+
+	cfg.API.BasePath = "keylight/"
+	cfg.API.MetricsEnabled = true
+	cfg.API.TLS = config.TLSConfig{CertFile: "server.crt", KeyFile: "server.key", ClientCAFile: "ca.crt"}
+	cfg.GRPC.ListenAddress = ":9124"
+	assert.Equal(t, []string{"version=1.2.3", "path=/keylight", "tls=1", "auth=client_cert", "grpc=9124", "caps=ws,metrics,grpc"}, TXTRecords(cfg, "1.2.3"))
+}
+
+func TestNew_Name(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.ConfigBlock{API: config.APIConfig{ListenAddress: ":9123"}}
+	a, err := New(logger, cfg, "dev")
+	require.NoError(t, err)
+	assert.Contains(t, a.name, "keylightd")
+
+	cfg.API.Advertise.Name = "Studio"
+	a, err = New(logger, cfg, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "Studio", a.name)
+
+	cfg.API.ListenAddress = "127.0.0.1:9123"
+	_, err = New(logger, cfg, "dev")
+	assert.Error(t, err)
+}
@@ -39,6 +39,38 @@ func NewManager(cfg *config.Config, logger *slog.Logger) *Manager {
 
 // CreateAPIKey generates a new API key, stores it, and saves the config.
 func (m *Manager) CreateAPIKey(name string, expiresIn time.Duration) (*config.APIKey, error) {
+	return m.CreateScopedAPIKey(name, expiresIn, nil)
+}
+
+// ReissueAPIKey creates a scoped API key in place of any key with the same
+// name, so the old secret stops working. Both are saved together; if saving
+// fails the old key is kept and the new one dropped.
+func (m *Manager) ReissueAPIKey(name string, scopes []string) (*config.APIKey, error) {
+	newKey, err := newAPIKey(name, 0, scopes)
+	if err != nil {
+		return nil, err
+	}
+	replaced := m.cfg.ReplaceAPIKey(newKey)
+	if err := m.cfg.Save(); err != nil {
+		m.cfg.DeleteAPIKey(newKey.Key)
+		for _, old := range replaced {
+			if addErr := m.cfg.AddAPIKey(old); addErr != nil {
+				m.log.Error("failed to restore replaced API key", "name", name, "key_prefix", keyPrefix(old.Key), "error", addErr)
+			}
+		}
+		m.log.Error("failed to save config after reissuing API key", "name", name, "error", err)
+		return nil, fmt.Errorf("failed to save reissued API key: %w", err)
+	}
+	for _, old := range replaced {
+		m.log.Info("replaced API key", "name", name, "key_prefix", keyPrefix(old.Key))
+	}
+	m.log.Info("created API key and saved to config", "name", name, "key_prefix", keyPrefix(newKey.Key))
+	return &newKey, nil
+}
+
+// CreateScopedAPIKey generates a new API key limited to the given scopes, or
+// with every scope if there are none, stores it, and saves the config.
+func (m *Manager) CreateScopedAPIKey(name string, expiresIn time.Duration, scopes []string) (*config.APIKey, error) {
 	existingKeys := m.cfg.GetAPIKeys() // Returns []APIKey
 	for _, existingKey := range existingKeys {
 		if existingKey.Name == name {
@@ -46,19 +78,9 @@ func (m *Manager) CreateAPIKey(name string, expiresIn time.Duration) (*config.AP
 		}
 	}
 
-	keyString, err := config.GenerateKey(config.DefaultKeyLength)
+	newKey, err := newAPIKey(name, expiresIn, scopes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate key string: %w", err)
-	}
-
-	newKey := config.APIKey{
-		Key:       keyString,
-		Name:      name,
-		CreatedAt: time.Now().UTC(),
-	}
-
-	if expiresIn > 0 {
-		newKey.ExpiresAt = time.Now().UTC().Add(expiresIn)
+		return nil, err
 	}
 
 	if err := m.cfg.AddAPIKey(newKey); err != nil {
@@ -73,10 +95,30 @@ func (m *Manager) CreateAPIKey(name string, expiresIn time.Duration) (*config.AP
 		return nil, fmt.Errorf("API key added to memory but failed to save to disk: %w", err)
 	}
 
-	m.log.Info("created API key and saved to config", "name", name, "key_prefix", newKey.Key[:4])
+	m.log.Info("created API key and saved to config", "name", name, "key_prefix", keyPrefix(newKey.Key))
 	return &newKey, nil
 }
 
+// newAPIKey generates a key with a new secret.
+func newAPIKey(name string, expiresIn time.Duration, scopes []string) (config.APIKey, error) {
+	keyString, err := config.GenerateKey(config.DefaultKeyLength)
+	if err != nil {
+		return config.APIKey{}, fmt.Errorf("failed to generate key string: %w", err)
+	}
+
+	newKey := config.APIKey{
+		Key:       keyString,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+		Scopes:    scopes,
+	}
+
+	if expiresIn > 0 {
+		newKey.ExpiresAt = time.Now().UTC().Add(expiresIn)
+	}
+	return newKey, nil
+}
+
 // ListAPIKeys returns all API keys.
 func (m *Manager) ListAPIKeys() []config.APIKey { // No error returned by m.cfg.GetAPIKeys()
 	return m.cfg.GetAPIKeys()
@@ -90,28 +132,29 @@ func (m *Manager) DeleteAPIKey(key string) error {
 
 	// Save the configuration to persist the deletion
 	if err := m.cfg.Save(); err != nil {
-		keyPrefix := key
-		if len(keyPrefix) > 4 {
-			keyPrefix = keyPrefix[:4]
-		}
-		m.log.Error("failed to save config after deleting API key", "key_prefix", keyPrefix, "error", err)
+		m.log.Error("failed to save config after deleting API key", "key_prefix", keyPrefix(key), "error", err)
 		return fmt.Errorf("API key deleted from memory but failed to save to disk: %w", err)
 	}
-	keyPrefix := key
-	if len(keyPrefix) > 4 {
-		keyPrefix = keyPrefix[:4]
-	}
-	m.log.Info("deleted API key and saved to config", "key_prefix", keyPrefix)
+	m.log.Info("deleted API key and saved to config", "key_prefix", keyPrefix(key))
 	return nil
 }
 
+// keyPrefix returns the first 4 characters of a key for safe logging.
+func keyPrefix(key string) string {
+	if len(key) >= 4 {
+		return key[:4]
+	}
+	return key
+}
+
 // ValidateAPIKey checks if an API key is valid (exists, not disabled, not expired).
 // Side effects: updates LastUsedAt on successful validation and persists the change (best-effort).
 // Concurrency: underlying config access is internally locked; the returned pointer must be treated as read-only by callers.
 func (m *Manager) ValidateAPIKey(key string) (*config.APIKey, error) {
 	apiKey, found := m.cfg.FindAPIKey(key) // FindAPIKey returns (*APIKey, bool)
 	if !found {
-		return nil, kerrors.NotFoundf("API key %q not found", key)
+		// The key may be a real one mistyped, so it isn't repeated into the logs
+		return nil, kerrors.NotFoundf("API key not found")
 	}
 
 	if apiKey.IsDisabled() {
@@ -127,7 +170,7 @@ func (m *Manager) ValidateAPIKey(key string) (*config.APIKey, error) {
 	// The timestamp will be persisted next time config is saved for other reasons
 	// (e.g., key creation, deletion, group changes).
 	if err := m.cfg.UpdateAPIKeyLastUsed(key, time.Now().UTC()); err != nil {
-		m.log.Error("failed to update last used timestamp for API key in memory", "key", key, "error", err)
+		m.log.Error("failed to update last used timestamp for API key in memory", "key_prefix", keyPrefix(key), "error", err)
 	}
 
 	return apiKey, nil
@@ -137,15 +180,15 @@ func (m *Manager) ValidateAPIKey(key string) (*config.APIKey, error) {
 func (m *Manager) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (*config.APIKey, error) {
 	updatedKey, err := m.cfg.SetAPIKeyDisabledStatus(keyOrName, disabled) // This modifies in-memory
 	if err != nil {
-		m.log.Error("failed to set API key disabled status in config memory", "key_or_name", keyOrName, "disabled", disabled, "error", err)
+		m.log.Error("failed to set API key disabled status in config memory", "key_prefix", keyPrefix(keyOrName), "disabled", disabled, "error", err)
 		return nil, err
 	}
 
 	// Save the configuration to persist the status change
 	if err := m.cfg.Save(); err != nil {
-		m.log.Error("failed to save config after setting API key disabled status", "key_or_name", keyOrName, "error", err)
+		m.log.Error("failed to save config after setting API key disabled status", "name", updatedKey.Name, "error", err)
 		return nil, fmt.Errorf("API key status updated in memory but failed to save to disk: %w", err)
 	}
-	m.log.Info("set API key disabled status and saved to config", "key_or_name", keyOrName, "disabled", disabled)
+	m.log.Info("set API key disabled status and saved to config", "name", updatedKey.Name, "disabled", disabled)
 	return updatedKey, nil
 }
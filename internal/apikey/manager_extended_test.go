@@ -1,11 +1,15 @@
 package apikey
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/jmylchreest/keylightd/internal/config"
 	kerrors "github.com/jmylchreest/keylightd/internal/errors"
 )
 
@@ -104,3 +108,53 @@ func TestCreateAPIKey_DuplicateName(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "already exists")
 }
+
+func TestReissueAPIKey(t *testing.T) {
+	mgr, _ := newTestManager(t)
+
+	first, err := mgr.ReissueAPIKey("ui-tray", []string{"read", "control"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "control"}, first.Scopes)
+
+	second, err := mgr.ReissueAPIKey("ui-tray", []string{"read", "control"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Key, second.Key)
+
+	_, err = mgr.ValidateAPIKey(first.Key)
+	assert.Error(t, err, "the replaced key should no longer validate")
+	validated, err := mgr.ValidateAPIKey(second.Key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "control"}, validated.Scopes)
+	assert.Len(t, mgr.ListAPIKeys(), 1)
+}
+
+func TestReissueAPIKey_ShortExistingKey(t *testing.T) {
+	mgr, cfg := newTestManager(t)
+	require.NoError(t, cfg.AddAPIKey(config.APIKey{Key: "ab", Name: "ui-tray"}))
+
+	reissued, err := mgr.ReissueAPIKey("ui-tray", []string{"read"})
+	require.NoError(t, err)
+	_, err = mgr.ValidateAPIKey("ab")
+	assert.Error(t, err)
+	_, err = mgr.ValidateAPIKey(reissued.Key)
+	assert.NoError(t, err)
+}
+
+func TestReissueAPIKey_SaveFailureKeepsOldKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg, err := config.Load("config.yaml", path)
+	require.NoError(t, err)
+	mgr := NewManager(cfg, slog.New(slog.DiscardHandler))
+	first, err := mgr.ReissueAPIKey("ui-tray", []string{"read"})
+	require.NoError(t, err)
+
+	// A directory in place of the config file makes saving fail
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.Mkdir(path, 0o700))
+
+	_, err = mgr.ReissueAPIKey("ui-tray", []string{"read"})
+	require.Error(t, err)
+	_, err = mgr.ValidateAPIKey(first.Key)
+	require.NoError(t, err, "the old key should still work")
+	assert.Len(t, mgr.ListAPIKeys(), 1)
+}
@@ -28,6 +28,13 @@ func newTestManager(t *testing.T) (*Manager, *config.Config) {
 	return mgr, cfg
 }
 
+func TestValidateAPIKey_ErrorOmitsKey(t *testing.T) {
+	mgr, _ := newTestManager(t)
+	_, err := mgr.ValidateAPIKey("mistyped-secret")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "mistyped-secret")
+}
+
 func TestValidateAPIKey_DisabledRejected(t *testing.T) {
 	mgr, cfg := newTestManager(t)
 
@@ -0,0 +1,264 @@
+// Package backup exports the daemon's user state (groups, schedules, scenes,
+// API key metadata and light names) as a portable document, and imports it
+// again, for migrating between hosts and for backups.
+//
+// Imports merge into the existing state: groups, schedules and scenes are
+// created or replaced by ID, and API keys and light names are added. Nothing
+// is deleted.
+package backup
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/scene"
+	"github.com/jmylchreest/keylightd/internal/schedule"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Version is the document format version written by Export. Import accepts
+// documents up to this version.
+const Version = 1
+
+// ExportDocument is a portable snapshot of the daemon's user state.
+type ExportDocument struct {
+	Version    int                          `json:"version" minimum:"1" doc:"Document format version"`
+	ExportedAt time.Time                    `json:"exported_at,omitzero" doc:"When the document was exported"`
+	Groups     []ExportedGroup              `json:"groups,omitempty" doc:"Light groups"`
+	Schedules  []ExportedSchedule           `json:"schedules,omitempty" doc:"Schedules"`
+	Scenes     []ExportedScene              `json:"scenes,omitempty" doc:"Scenes"`
+	APIKeys    []ExportedAPIKey             `json:"api_keys,omitempty" doc:"API keys; secrets are only included when requested"`
+	LightNames map[string]string            `json:"light_names,omitempty" doc:"Display names set for lights, keyed by light ID"`
+	LightTags  map[string]map[string]string `json:"light_tags,omitempty" doc:"Tags set on lights, keyed by light ID"`
+}
+
+// ExportedGroup is an exported light group.
+type ExportedGroup struct {
+	ID          string          `json:"id" doc:"Group identifier"`
+	Name        string          `json:"name" doc:"Group name"`
+	Lights      []string        `json:"lights" doc:"Member light IDs"`
+	Defaults    *group.Defaults `json:"defaults,omitempty" doc:"Default state of the group's lights"`
+	Description string          `json:"description,omitempty" doc:"Group description"`
+	Icon        string          `json:"icon,omitempty" doc:"Icon name shown for the group in UIs"`
+	Color       string          `json:"color,omitempty" doc:"Color shown for the group in UIs, as #rrggbb"`
+	LegacyID    string          `json:"legacy_id,omitempty" doc:"ID the group had before it was migrated to a UUID, still accepted in place of its ID"`
+	CreatedAt   time.Time       `json:"created_at,omitzero" doc:"When the group was created"`
+	UpdatedAt   time.Time       `json:"updated_at,omitzero" doc:"When the group's name, metadata, lights or defaults last changed"`
+}
+
+// ExportedSchedule is an exported schedule.
+type ExportedSchedule struct {
+	ID        string          `json:"id" doc:"Schedule identifier"`
+	Name      string          `json:"name" doc:"Schedule name"`
+	At        string          `json:"at,omitempty" doc:"Daily time of day in HH:MM"`
+	Cron      string          `json:"cron,omitempty" doc:"Five-field cron expression"`
+	Target    schedule.Target `json:"target,omitzero" doc:"Light or group the schedule acts upon, or the scene is limited to"`
+	Action    schedule.Action `json:"action,omitzero" doc:"State applied when the schedule fires"`
+	Scene     string          `json:"scene,omitempty" doc:"ID or name of the scene applied when the schedule fires"`
+	Timezone  string          `json:"timezone,omitempty" doc:"IANA time zone at and cron are in"`
+	SkipDates []string        `json:"skip_dates,omitempty" doc:"Dates (YYYY-MM-DD) the schedule doesn't fire on"`
+	Enabled   bool            `json:"enabled" doc:"Whether the schedule is active"`
+}
+
+// ExportedScene is an exported scene.
+type ExportedScene struct {
+	ID      string        `json:"id" doc:"Scene identifier"`
+	Name    string        `json:"name" doc:"Scene name"`
+	Entries []scene.Entry `json:"entries" doc:"States the scene applies, with their order and transitions"`
+}
+
+// ExportedAPIKey is exported API key metadata. Key is only set when secrets are
+// included, and keys without it are skipped on import.
+type ExportedAPIKey struct {
+	Name      string    `json:"name" doc:"Key name"`
+	Key       string    `json:"key,omitempty" doc:"The key secret, if included"`
+	CreatedAt time.Time `json:"created_at,omitzero" doc:"When the key was created"`
+	ExpiresAt time.Time `json:"expires_at,omitzero" doc:"When the key expires; absent if it never does"`
+	Disabled  bool      `json:"disabled,omitempty" doc:"Whether the key is disabled"`
+	Scopes    []string  `json:"scopes,omitempty" doc:"Permission scopes; absent if the key has every scope"`
+}
+
+// ImportResult summarises an import.
+type ImportResult struct {
+	Groups     int      `json:"groups" doc:"Groups created or replaced"`
+	Schedules  int      `json:"schedules" doc:"Schedules created or replaced"`
+	Scenes     int      `json:"scenes" doc:"Scenes created or replaced"`
+	APIKeys    int      `json:"api_keys" doc:"API keys added"`
+	LightNames int      `json:"light_names" doc:"Light names set"`
+	LightTags  int      `json:"light_tags" doc:"Lights whose tags were set"`
+	Skipped    []string `json:"skipped,omitempty" doc:"Entries that were not imported, and why"`
+}
+
+// Service exports and imports the daemon's user state.
+type Service struct {
+	cfg       *config.Config
+	lights    keylight.LightManager
+	groups    *group.Manager
+	schedules *schedule.Manager
+	scenes    *scene.Manager
+}
+
+// NewService creates a backup service over the daemon's managers.
+func NewService(cfg *config.Config, lights keylight.LightManager, groups *group.Manager, schedules *schedule.Manager, scenes *scene.Manager) *Service {
+	return &Service{cfg: cfg, lights: lights, groups: groups, schedules: schedules, scenes: scenes}
+}
+
+// Export returns the current state as a document. API key secrets are only
+// included when includeSecrets is set.
+func (s *Service) Export(includeSecrets bool) *ExportDocument {
+	doc := &ExportDocument{
+		Version:    Version,
+		ExportedAt: time.Now().UTC(),
+		LightNames: s.cfg.GetLightNames(),
+		LightTags:  s.cfg.GetLightTags(),
+	}
+
+	for _, g := range s.groups.GetGroups() {
+		doc.Groups = append(doc.Groups, ExportedGroup{ID: g.ID, Name: g.Name, Lights: g.Lights, Defaults: g.Defaults,
+			Description: g.Description, Icon: g.Icon, Color: g.Color,
+			LegacyID: g.LegacyID, CreatedAt: g.CreatedAt, UpdatedAt: g.UpdatedAt})
+	}
+	slices.SortFunc(doc.Groups, func(a, b ExportedGroup) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+
+	for _, sched := range s.schedules.GetSchedules() {
+		doc.Schedules = append(doc.Schedules, ExportedSchedule{
+			ID:        sched.ID,
+			Name:      sched.Name,
+			At:        sched.At,
+			Cron:      sched.Cron,
+			Target:    sched.Target,
+			Action:    sched.Action,
+			Scene:     sched.Scene,
+			Timezone:  sched.Timezone,
+			SkipDates: sched.SkipDates,
+			Enabled:   sched.Enabled,
+		})
+	}
+
+	for _, sc := range s.scenes.GetScenes() {
+		doc.Scenes = append(doc.Scenes, ExportedScene{ID: sc.ID, Name: sc.Name, Entries: sc.Entries})
+	}
+
+	for _, key := range s.cfg.GetAPIKeys() {
+		exported := ExportedAPIKey{
+			Name:      key.Name,
+			CreatedAt: key.CreatedAt,
+			ExpiresAt: key.ExpiresAt,
+			Disabled:  key.Disabled,
+			Scopes:    key.Scopes,
+		}
+		if includeSecrets {
+			exported.Key = key.Key
+		}
+		doc.APIKeys = append(doc.APIKeys, exported)
+	}
+	return doc
+}
+
+// Import merges a document into the current state. Groups, schedules and
+// scenes are validated and stored first; API keys that already exist or have no secret,
+// and names for lights that haven't been discovered, are skipped and listed
+// in the result.
+func (s *Service) Import(ctx context.Context, doc *ExportDocument) (*ImportResult, error) {
+	if doc.Version < 1 || doc.Version > Version {
+		return nil, kerrors.InvalidInputf("unsupported document version %d, expected 1 to %d", doc.Version, Version)
+	}
+	result := &ImportResult{}
+
+	groups := make([]*group.Group, 0, len(doc.Groups))
+	for _, g := range doc.Groups {
+		groups = append(groups, &group.Group{ID: g.ID, Name: g.Name, Lights: g.Lights, Defaults: g.Defaults,
+			Description: g.Description, Icon: g.Icon, Color: g.Color,
+			LegacyID: g.LegacyID, CreatedAt: g.CreatedAt, UpdatedAt: g.UpdatedAt})
+	}
+	if len(groups) > 0 {
+		if err := s.groups.ImportGroups(groups); err != nil {
+			return nil, fmt.Errorf("failed to import groups: %w", err)
+		}
+		result.Groups = len(groups)
+	}
+
+	schedules := make([]schedule.Schedule, 0, len(doc.Schedules))
+	for _, sched := range doc.Schedules {
+		schedules = append(schedules, schedule.Schedule{
+			ID:        sched.ID,
+			Name:      sched.Name,
+			At:        sched.At,
+			Cron:      sched.Cron,
+			Target:    sched.Target,
+			Action:    sched.Action,
+			Scene:     sched.Scene,
+			Timezone:  sched.Timezone,
+			SkipDates: sched.SkipDates,
+			Enabled:   sched.Enabled,
+		})
+	}
+	if len(schedules) > 0 {
+		if err := s.schedules.ImportSchedules(schedules); err != nil {
+			return result, fmt.Errorf("failed to import schedules: %w", err)
+		}
+		result.Schedules = len(schedules)
+	}
+
+	scenes := make([]scene.Scene, 0, len(doc.Scenes))
+	for _, sc := range doc.Scenes {
+		scenes = append(scenes, scene.Scene{ID: sc.ID, Name: sc.Name, Entries: sc.Entries})
+	}
+	if len(scenes) > 0 {
+		if err := s.scenes.ImportScenes(scenes); err != nil {
+			return result, fmt.Errorf("failed to import scenes: %w", err)
+		}
+		result.Scenes = len(scenes)
+	}
+
+	for _, key := range doc.APIKeys {
+		if key.Key == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("API key %q: not exported with its secret", key.Name))
+			continue
+		}
+		added := config.APIKey{
+			Key:       key.Key,
+			Name:      key.Name,
+			CreatedAt: cmp.Or(key.CreatedAt, time.Now().UTC()),
+			ExpiresAt: key.ExpiresAt,
+			Disabled:  key.Disabled,
+			Scopes:    key.Scopes,
+		}
+		if err := s.cfg.AddAPIKey(added); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("API key %q: a key with this name or secret already exists", key.Name))
+			continue
+		}
+		result.APIKeys++
+	}
+	if result.APIKeys > 0 {
+		if err := s.cfg.Save(); err != nil {
+			return result, fmt.Errorf("failed to save imported API keys: %w", err)
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(doc.LightNames)) {
+		if _, err := s.lights.SetLightName(ctx, id, doc.LightNames[id]); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("light name %q: %s", doc.LightNames[id], err))
+			continue
+		}
+		result.LightNames++
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(doc.LightTags)) {
+		if _, err := s.lights.SetLightTags(ctx, id, doc.LightTags[id]); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("tags of light %s: %s", id, err))
+			continue
+		}
+		result.LightTags++
+	}
+	return result, nil
+}
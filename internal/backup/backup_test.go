@@ -0,0 +1,181 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/internal/scene"
+	"github.com/jmylchreest/keylightd/internal/schedule"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type mockLightManager struct {
+	keylight.LightManager
+	lights map[string]*keylight.Light
+	names  map[string]string
+	tags   map[string]map[string]string
+}
+
+func (m *mockLightManager) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, keylight.ErrLightNotFound
+	}
+	return light, nil
+}
+
+func (m *mockLightManager) SetLightName(_ context.Context, id, name string) (*keylight.Light, error) {
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, kerrors.NotFoundf("light %s not found", id)
+	}
+	m.names[id] = name
+	return light, nil
+}
+
+func (m *mockLightManager) SetLightTags(_ context.Context, id string, tags map[string]string) (*keylight.Light, error) {
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, kerrors.NotFoundf("light %s not found", id)
+	}
+	m.tags[id] = tags
+	return light, nil
+}
+
+func newTestService(t *testing.T) (*Service, *mockLightManager, *config.Config) {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(filepath.Join(t.TempDir(), "test.yaml"))
+	cfg := config.New(v)
+	require.NoError(t, cfg.Save())
+
+	logger := slog.New(slog.DiscardHandler)
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{"light-1": {ID: "light-1"}},
+		names:  map[string]string{},
+		tags:   map[string]map[string]string{},
+	}
+	groups := group.NewManager(logger, lights, cfg)
+	schedules := schedule.NewManager(logger, cfg, lights, groups)
+	scenes := scene.NewManager(logger, cfg, lights, groups, jobs.NewManager(logger))
+	return NewService(cfg, lights, groups, schedules, scenes), lights, cfg
+}
+
+func TestExport(t *testing.T) {
+	svc, _, cfg := newTestService(t)
+	grp, err := svc.groups.CreateGroup(t.Context(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+	on := true
+	_, err = svc.schedules.CreateSchedule(schedule.Schedule{
+		Name:    "morning",
+		At:      "08:00",
+		Target:  schedule.Target{Type: schedule.TargetGroup, ID: grp.ID},
+		Action:  schedule.Action{On: &on},
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, cfg.AddAPIKey(config.APIKey{Key: "secret", Name: "tray"}))
+	cfg.SetLightName("light-1", "Left")
+
+	doc := svc.Export(false)
+	assert.Equal(t, Version, doc.Version)
+	require.Len(t, doc.Groups, 1)
+	assert.Equal(t, grp.ID, doc.Groups[0].ID)
+	assert.Equal(t, []string{"light-1"}, doc.Groups[0].Lights)
+	require.Len(t, doc.Schedules, 1)
+	assert.Equal(t, "08:00", doc.Schedules[0].At)
+	require.Len(t, doc.APIKeys, 1)
+	assert.Equal(t, "tray", doc.APIKeys[0].Name)
+	assert.Empty(t, doc.APIKeys[0].Key, "secrets are left out by default")
+	assert.Equal(t, map[string]string{"light-1": "Left"}, doc.LightNames)
+
+	assert.Equal(t, "secret", svc.Export(true).APIKeys[0].Key)
+}
+
+func TestImport(t *testing.T) {
+	source, _, sourceCfg := newTestService(t)
+	grp, err := source.groups.CreateGroup(t.Context(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+	off := false
+	_, err = source.schedules.CreateSchedule(schedule.Schedule{
+		Name:   "night",
+		Cron:   "0 23 * * *",
+		Target: schedule.Target{Type: schedule.TargetLight, ID: "light-1"},
+		Action: schedule.Action{On: &off},
+	})
+	require.NoError(t, err)
+	brightness := 60
+	_, err = source.scenes.CreateScene(scene.Scene{Name: "Streaming start", Entries: []scene.Entry{
+		{Target: scene.Target{Type: scene.TargetGroup, ID: grp.ID}, Brightness: &brightness, TransitionMS: 2000},
+		{Target: scene.Target{Type: scene.TargetLight, ID: "light-1"}, On: &off, Order: 1, DelayMS: 500},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, sourceCfg.AddAPIKey(config.APIKey{Key: "secret", Name: "tray"}))
+	require.NoError(t, sourceCfg.AddAPIKey(config.APIKey{Key: "other", Name: "taken"}))
+	sourceCfg.SetLightName("light-1", "Left")
+	sourceCfg.SetLightName("light-9", "Gone")
+	sourceCfg.SetLightTags("light-1", map[string]string{"side": "left"})
+
+	// Round trip through JSON, as the CLI and API do
+	data, err := json.Marshal(source.Export(true))
+	require.NoError(t, err)
+	var doc ExportDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	target, lights, targetCfg := newTestService(t)
+	require.NoError(t, targetCfg.AddAPIKey(config.APIKey{Key: "mine", Name: "taken"}))
+
+	result, err := target.Import(t.Context(), &doc)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Groups)
+	assert.Equal(t, 1, result.Schedules)
+	assert.Equal(t, 1, result.Scenes)
+	assert.Equal(t, 1, result.APIKeys)
+	assert.Equal(t, 1, result.LightNames)
+	assert.Equal(t, 1, result.LightTags)
+	assert.Len(t, result.Skipped, 2, "existing key name and unknown light")
+
+	got, err := target.groups.GetGroup(grp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Office", got.Name)
+	assert.Len(t, target.schedules.GetSchedules(), 1)
+	assert.Equal(t, source.scenes.GetScenes(), target.scenes.GetScenes())
+	_, found := targetCfg.FindAPIKey("secret")
+	assert.True(t, found)
+	assert.Equal(t, map[string]string{"light-1": "Left"}, lights.names)
+	assert.Equal(t, map[string]map[string]string{"light-1": {"side": "left"}}, lights.tags)
+
+	// Importing again replaces rather than duplicates
+	_, err = target.Import(t.Context(), &doc)
+	require.NoError(t, err)
+	assert.Len(t, target.groups.GetGroups(), 1)
+	assert.Len(t, target.schedules.GetSchedules(), 1)
+	assert.Len(t, target.scenes.GetScenes(), 1)
+}
+
+func TestImport_Invalid(t *testing.T) {
+	svc, _, _ := newTestService(t)
+
+	_, err := svc.Import(t.Context(), &ExportDocument{Version: Version + 1})
+	assert.True(t, kerrors.IsInvalidInput(err))
+
+	_, err = svc.Import(t.Context(), &ExportDocument{Version: Version, Groups: []ExportedGroup{{ID: "group-1"}}})
+	assert.True(t, kerrors.IsInvalidInput(err))
+	assert.Empty(t, svc.groups.GetGroups())
+}
@@ -0,0 +1,249 @@
+// Package circadian implements circadian mode, which gradually shifts the
+// color temperature (and optionally brightness) of lights across the day.
+//
+// The curve follows the sun at a configured location, or explicit curve
+// points, and is applied by a background worker started alongside the server.
+// Only lights that are on are adjusted, and a light is only updated when its
+// target changes, so a manual change sticks until the curve moves on.
+package circadian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Curve sources.
+const (
+	ModeSun    = "sun"
+	ModePoints = "points"
+)
+
+// Status is the current state of circadian mode.
+type Status struct {
+	Enabled bool      `json:"enabled"`
+	Mode    string    `json:"mode,omitempty"`   // sun or points; empty if neither is configured
+	Target  *Target   `json:"target,omitempty"` // State lights are currently set to
+	Sunrise time.Time `json:"sunrise,omitzero"` // Today's sunrise, when following the sun
+	Sunset  time.Time `json:"sunset,omitzero"`  // Today's sunset, when following the sun
+	Lights  []string  `json:"lights"`           // Lights circadian mode applies to
+}
+
+// Manager runs circadian mode.
+// All access to settings and applied is guarded by mu.
+type Manager struct {
+	logger   *slog.Logger
+	cfg      *config.Config
+	lights   keylight.LightManager
+	groups   *group.Manager
+	settings config.CircadianConfig
+	// applied is the target last applied to each light, so lights are only
+	// updated when their target changes. Lights that are off are dropped so
+	// they are updated as soon as they are turned on.
+	applied map[string]Target
+	wake    chan struct{}
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// NewManager creates a circadian manager from the circadian config.
+func NewManager(logger *slog.Logger, cfg *config.Config, lights keylight.LightManager, groups *group.Manager) *Manager {
+	m := &Manager{
+		logger:   logger,
+		cfg:      cfg,
+		lights:   lights,
+		groups:   groups,
+		settings: cfg.Config.Circadian,
+		applied:  make(map[string]Target),
+		wake:     make(chan struct{}, 1),
+		now:      time.Now,
+	}
+	if enabled := cfg.GetCircadianEnabled(); enabled != nil {
+		m.settings.Enabled = *enabled
+	}
+	if m.settings.Enabled && mode(m.settings) == "" {
+		logger.Warn("Circadian mode needs a location or curve points, leaving it disabled")
+		m.settings.Enabled = false
+	}
+	return m
+}
+
+// mode returns the curve source for the settings, or "" if none is configured.
+func mode(c config.CircadianConfig) string {
+	switch {
+	case len(c.Points) > 0:
+		return ModePoints
+	case c.HasLocation():
+		return ModeSun
+	default:
+		return ""
+	}
+}
+
+// target returns the target at now for the settings.
+func target(c config.CircadianConfig, now time.Time) Target {
+	if mode(c) == ModePoints {
+		return pointsTarget(c.Points, now)
+	}
+	return sunTarget(c, now)
+}
+
+// Status returns the current state of circadian mode.
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	settings := m.settings
+	m.mu.Unlock()
+
+	now := m.now()
+	status := Status{Enabled: settings.Enabled, Mode: mode(settings), Lights: m.targetLights(settings)}
+	if status.Mode == "" {
+		return status
+	}
+	t := target(settings, now)
+	status.Target = &t
+	if status.Mode == ModeSun {
+		status.Sunrise, status.Sunset, _ = sunTimes(now, settings.Latitude, settings.Longitude)
+	}
+	return status
+}
+
+// SetEnabled turns circadian mode on or off and saves the choice with the
+// daemon's state, where it overrides circadian.enabled from the config file.
+// Enabling applies the current target straight away.
+func (m *Manager) SetEnabled(enabled bool) (Status, error) {
+	m.mu.Lock()
+	if enabled && mode(m.settings) == "" {
+		m.mu.Unlock()
+		return Status{}, kerrors.InvalidInputf("circadian mode needs a location or curve points in the config")
+	}
+	previous, previousOverride := m.settings.Enabled, m.cfg.GetCircadianEnabled()
+	m.settings.Enabled = enabled
+	m.cfg.Config.Circadian.Enabled = enabled
+	m.cfg.SetCircadianEnabled(&enabled)
+	if err := m.cfg.Save(); err != nil {
+		m.settings.Enabled = previous
+		m.cfg.Config.Circadian.Enabled = previous
+		m.cfg.SetCircadianEnabled(previousOverride)
+		m.mu.Unlock()
+		return Status{}, fmt.Errorf("failed to save circadian settings: %w", err)
+	}
+	clear(m.applied)
+	m.mu.Unlock()
+
+	m.logger.Info("circadian mode changed", "enabled", enabled)
+	if enabled {
+		select {
+		case m.wake <- struct{}{}:
+		default:
+		}
+	}
+	return m.Status(), nil
+}
+
+// Run applies the circadian curve every interval until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	m.mu.Lock()
+	interval := time.Duration(m.settings.Interval) * time.Second
+	m.mu.Unlock()
+	if interval <= 0 {
+		interval = config.DefaultCircadianInterval
+	}
+
+	m.logger.Info("Starting circadian worker", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	m.evaluate(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Circadian worker stopped")
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+		m.evaluate(ctx)
+	}
+}
+
+// evaluate applies the current target to every targeted light that is on and
+// whose target has changed since it was last applied.
+func (m *Manager) evaluate(ctx context.Context) {
+	m.mu.Lock()
+	settings := m.settings
+	m.mu.Unlock()
+	if !settings.Enabled || mode(settings) == "" {
+		return
+	}
+
+	t := target(settings, m.now())
+	all := m.lights.GetLights()
+	var errs []error
+	for _, id := range m.targetLights(settings) {
+		light, ok := all[id]
+		if !ok {
+			continue
+		}
+		m.mu.Lock()
+		last, applied := m.applied[id]
+		if !light.On {
+			delete(m.applied, id)
+		}
+		m.mu.Unlock()
+		if !light.On || (applied && last == t) {
+			continue
+		}
+
+		if err := m.apply(ctx, id, t); err != nil {
+			errs = append(errs, fmt.Errorf("light %s: %w", id, err))
+			continue
+		}
+		m.mu.Lock()
+		m.applied[id] = t
+		m.mu.Unlock()
+		m.logger.Debug("circadian: applied target", "id", id, "temperature", t.Temperature, "brightness", t.Brightness)
+	}
+	if err := errors.Join(errs...); err != nil {
+		m.logger.Warn("circadian: failed to update lights", "error", err)
+	}
+}
+
+// apply sets a light to the target.
+func (m *Manager) apply(ctx context.Context, id string, t Target) error {
+	var errs []error
+	errs = append(errs, m.lights.SetLightTemperature(ctx, id, t.Temperature))
+	if t.Brightness != 0 {
+		errs = append(errs, m.lights.SetLightBrightness(ctx, id, t.Brightness))
+	}
+	return errors.Join(errs...)
+}
+
+// targetLights returns the sorted IDs of the lights circadian mode applies
+// to: the configured lights and the members of the configured groups, or every
+// light if neither is set.
+func (m *Manager) targetLights(settings config.CircadianConfig) []string {
+	ids := []string{}
+	if len(settings.Lights) == 0 && len(settings.Groups) == 0 {
+		for id := range m.lights.GetLights() {
+			ids = append(ids, id)
+		}
+	} else {
+		ids = append(ids, settings.Lights...)
+		for _, key := range settings.Groups {
+			groups, _ := m.groups.GetGroupsByKeys(key)
+			for _, g := range groups {
+				ids = append(ids, g.Lights...)
+			}
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
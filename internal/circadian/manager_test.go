@@ -0,0 +1,159 @@
+package circadian
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type mockLightManager struct {
+	keylight.LightManager
+	mu     sync.Mutex
+	lights map[string]*keylight.Light
+	calls  []string
+}
+
+func (m *mockLightManager) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, keylight.ErrLightNotFound
+	}
+	return light, nil
+}
+
+func (m *mockLightManager) SetLightTemperature(_ context.Context, id string, temperature int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, id+":temperature")
+	return nil
+}
+
+func (m *mockLightManager) SetLightBrightness(_ context.Context, id string, brightness int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, id+":brightness")
+	return nil
+}
+
+func (m *mockLightManager) takeCalls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := m.calls
+	m.calls = nil
+	return calls
+}
+
+func setupTestManager(t *testing.T, circadian config.CircadianConfig) (*Manager, *mockLightManager, *config.Config) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "test.yaml")
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(configPath)
+	cfg := config.New(v)
+	cfg.Config.Circadian = circadian
+	require.NoError(t, cfg.Save())
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{
+		"light1": {ID: "light1", On: true},
+		"light2": {ID: "light2"},
+	}}
+	groups := group.NewManager(logger, lights, cfg)
+	return NewManager(logger, cfg, lights, groups), lights, cfg
+}
+
+func pointsConfig() config.CircadianConfig {
+	c := config.DefaultCircadian()
+	c.Points = []config.CircadianPoint{
+		{At: "08:00", Temperature: 5000},
+		{At: "20:00", Temperature: 3000},
+	}
+	return c
+}
+
+func TestEvaluate(t *testing.T) {
+	m, lights, _ := setupTestManager(t, pointsConfig())
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Nothing happens while disabled
+	m.evaluate(ctx)
+	assert.Empty(t, lights.takeCalls())
+
+	_, err := m.SetEnabled(true)
+	require.NoError(t, err)
+
+	// Only lights that are on are updated
+	m.evaluate(ctx)
+	assert.Equal(t, []string{"light1:temperature"}, lights.takeCalls())
+
+	// Unchanged targets aren't re-applied
+	m.evaluate(ctx)
+	assert.Empty(t, lights.takeCalls())
+
+	now = now.Add(2 * time.Hour)
+	m.evaluate(ctx)
+	assert.Equal(t, []string{"light1:temperature"}, lights.takeCalls())
+
+	// A light that is turned off and on again is updated
+	lights.lights["light1"].On = false
+	m.evaluate(ctx)
+	lights.lights["light1"].On = true
+	m.evaluate(ctx)
+	assert.Equal(t, []string{"light1:temperature"}, lights.takeCalls())
+}
+
+func TestSetEnabled(t *testing.T) {
+	m, _, cfg := setupTestManager(t, pointsConfig())
+
+	status, err := m.SetEnabled(true)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, ModePoints, status.Mode)
+	require.NotNil(t, status.Target)
+	assert.Equal(t, []string{"light1", "light2"}, status.Lights)
+	assert.True(t, cfg.Config.Circadian.Enabled)
+
+	status, err = m.SetEnabled(false)
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.False(t, cfg.Config.Circadian.Enabled)
+
+	// The choice is kept with the state and overrides the config on restart
+	require.NotNil(t, cfg.GetCircadianEnabled())
+	c := pointsConfig()
+	c.Enabled = true
+	cfg.Config.Circadian = c
+	assert.False(t, NewManager(m.logger, cfg, m.lights, m.groups).Status().Enabled)
+
+	// Without a location or points there is no curve to follow
+	m, _, _ = setupTestManager(t, config.DefaultCircadian())
+	_, err = m.SetEnabled(true)
+	assert.True(t, kerrors.IsInvalidInput(err))
+	assert.Empty(t, m.Status().Mode)
+}
+
+func TestTargetLights(t *testing.T) {
+	c := pointsConfig()
+	c.Lights = []string{"light2"}
+	m, _, _ := setupTestManager(t, c)
+	assert.Equal(t, []string{"light2"}, m.Status().Lights)
+}
@@ -0,0 +1,158 @@
+package circadian
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// j2000 is the Julian date of 2000-01-01 12:00 UTC.
+const j2000 = 2451545.0
+
+// sunTimes returns the sunrise and sunset on the calendar day of day at a
+// location, using the NOAA sunrise equation. polar is 1 if the sun never sets
+// that day and -1 if it never rises, in which case the times are zero.
+func sunTimes(day time.Time, latitude, longitude float64) (sunrise, sunset time.Time, polar int) {
+	noon := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, time.UTC)
+	n := math.Round(julianDate(noon) - j2000 + 0.0008)
+	meanNoon := n - longitude/360
+
+	anomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	m := radians(anomaly)
+	center := 1.9148*math.Sin(m) + 0.02*math.Sin(2*m) + 0.0003*math.Sin(3*m)
+	lambda := radians(math.Mod(anomaly+center+180+102.9372, 360))
+	transit := j2000 + meanNoon + 0.0053*math.Sin(m) - 0.0069*math.Sin(2*lambda)
+
+	sinDeclination := math.Sin(lambda) * math.Sin(radians(23.4397))
+	cosDeclination := math.Cos(math.Asin(sinDeclination))
+	lat := radians(latitude)
+	cosHourAngle := (math.Sin(radians(-0.833)) - math.Sin(lat)*sinDeclination) / (math.Cos(lat) * cosDeclination)
+	switch {
+	case cosHourAngle < -1:
+		return time.Time{}, time.Time{}, 1
+	case cosHourAngle > 1:
+		return time.Time{}, time.Time{}, -1
+	}
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+	loc := day.Location()
+	return fromJulianDate(transit - hourAngle/360).In(loc), fromJulianDate(transit + hourAngle/360).In(loc), 0
+}
+
+func julianDate(t time.Time) float64 {
+	return float64(t.Unix())/86400 + 2440587.5
+}
+
+func fromJulianDate(j float64) time.Time {
+	return time.Unix(int64(math.Round((j-2440587.5)*86400)), 0)
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Target is the state circadian mode sets lights to at a point in time.
+type Target struct {
+	Temperature int `json:"temperature"`          // Kelvin
+	Brightness  int `json:"brightness,omitempty"` // 0 leaves brightness alone
+}
+
+// sunTarget returns the target at now when following the sun: the day values
+// while it is up, the night values while it is down, and a linear shift
+// between them over transition centred on sunrise and sunset.
+func sunTarget(cfg config.CircadianConfig, now time.Time) Target {
+	day := Target{Temperature: cfg.DayTemperature, Brightness: cfg.DayBrightness}
+	night := Target{Temperature: cfg.NightTemperature, Brightness: cfg.NightBrightness}
+	if cfg.DayBrightness == 0 || cfg.NightBrightness == 0 {
+		day.Brightness, night.Brightness = 0, 0
+	}
+
+	sunrise, sunset, polar := sunTimes(now, cfg.Latitude, cfg.Longitude)
+	switch polar {
+	case 1:
+		return day
+	case -1:
+		return night
+	}
+
+	half := time.Duration(cfg.Transition) * time.Minute / 2
+	switch {
+	case now.Before(sunrise.Add(-half)) || !now.Before(sunset.Add(half)):
+		return night
+	case now.Before(sunrise.Add(half)):
+		return blend(night, day, fraction(now, sunrise.Add(-half), sunrise.Add(half)))
+	case !now.Before(sunset.Add(-half)):
+		return blend(day, night, fraction(now, sunset.Add(-half), sunset.Add(half)))
+	default:
+		return day
+	}
+}
+
+// pointsTarget returns the target at now on an explicit curve, interpolating
+// between the points either side of it and wrapping around midnight.
+// Brightness is only set if every point sets it.
+func pointsTarget(points []config.CircadianPoint, now time.Time) Target {
+	type point struct {
+		minute int
+		target Target
+	}
+	curve := make([]point, 0, len(points))
+	setBrightness := true
+	for _, p := range points {
+		at, err := time.Parse("15:04", p.At)
+		if err != nil {
+			continue
+		}
+		curve = append(curve, point{at.Hour()*60 + at.Minute(), Target{p.Temperature, p.Brightness}})
+		setBrightness = setBrightness && p.Brightness != 0
+	}
+	if len(curve) == 0 {
+		return Target{}
+	}
+	slices.SortFunc(curve, func(a, b point) int { return a.minute - b.minute })
+	if !setBrightness {
+		for i := range curve {
+			curve[i].target.Brightness = 0
+		}
+	}
+
+	const day = 24 * 60
+	minute := float64(now.Hour()*60+now.Minute()) + float64(now.Second())/60
+	prev, next := curve[len(curve)-1], curve[0]
+	prevMinute, nextMinute := float64(prev.minute-day), float64(next.minute)
+	for i, p := range curve {
+		if float64(p.minute) > minute {
+			break
+		}
+		prev, prevMinute = p, float64(p.minute)
+		if i+1 < len(curve) {
+			next, nextMinute = curve[i+1], float64(curve[i+1].minute)
+		} else {
+			next, nextMinute = curve[0], float64(curve[0].minute+day)
+		}
+	}
+	if nextMinute == prevMinute {
+		return prev.target
+	}
+	return blend(prev.target, next.target, (minute-prevMinute)/(nextMinute-prevMinute))
+}
+
+// fraction returns how far t is from start to end, between 0 and 1.
+func fraction(t, start, end time.Time) float64 {
+	if !end.After(start) {
+		return 1
+	}
+	return min(1, max(0, float64(t.Sub(start))/float64(end.Sub(start))))
+}
+
+// blend returns the target a fraction f of the way from a to b, with the
+// temperature rounded to 50K so lights aren't updated for imperceptible changes.
+func blend(a, b Target, f float64) Target {
+	temperature := float64(a.Temperature) + (float64(b.Temperature)-float64(a.Temperature))*f
+	t := Target{Temperature: int(math.Round(temperature/50) * 50)}
+	if a.Brightness != 0 && b.Brightness != 0 {
+		t.Brightness = int(math.Round(float64(a.Brightness) + (float64(b.Brightness)-float64(a.Brightness))*f))
+	}
+	return t
+}
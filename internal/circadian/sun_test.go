@@ -0,0 +1,61 @@
+package circadian
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+func TestSunTimes(t *testing.T) {
+	// London on the summer solstice: sunrise about 03:43 UTC, sunset about 20:21 UTC
+	day := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+	sunrise, sunset, polar := sunTimes(day, 51.5074, -0.1278)
+	assert.Equal(t, 0, polar)
+	assert.WithinDuration(t, time.Date(2024, 6, 21, 3, 43, 0, 0, time.UTC), sunrise, 5*time.Minute)
+	assert.WithinDuration(t, time.Date(2024, 6, 21, 20, 21, 0, 0, time.UTC), sunset, 5*time.Minute)
+
+	// Tromsø has midnight sun in June and polar night in December
+	_, _, polar = sunTimes(day, 69.6492, 18.9553)
+	assert.Equal(t, 1, polar)
+	_, _, polar = sunTimes(time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), 69.6492, 18.9553)
+	assert.Equal(t, -1, polar)
+}
+
+func TestSunTarget(t *testing.T) {
+	cfg := config.DefaultCircadian()
+	cfg.Latitude, cfg.Longitude = 51.5074, -0.1278
+	cfg.DayBrightness, cfg.NightBrightness = 80, 30
+
+	day := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+	sunrise, _, _ := sunTimes(day, cfg.Latitude, cfg.Longitude)
+
+	assert.Equal(t, Target{Temperature: 6500, Brightness: 80}, sunTarget(cfg, day.Add(12*time.Hour)))
+	assert.Equal(t, Target{Temperature: 2900, Brightness: 30}, sunTarget(cfg, day.Add(time.Hour)))
+	assert.Equal(t, Target{Temperature: 4700, Brightness: 55}, sunTarget(cfg, sunrise))
+
+	// Brightness is left alone unless both day and night values are set
+	cfg.NightBrightness = 0
+	assert.Equal(t, Target{Temperature: 6500}, sunTarget(cfg, day.Add(12*time.Hour)))
+}
+
+func TestPointsTarget(t *testing.T) {
+	points := []config.CircadianPoint{
+		{At: "20:00", Temperature: 3000, Brightness: 40},
+		{At: "08:00", Temperature: 5000, Brightness: 80},
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	assert.Equal(t, Target{Temperature: 5000, Brightness: 80}, pointsTarget(points, at(8, 0)))
+	assert.Equal(t, Target{Temperature: 4000, Brightness: 60}, pointsTarget(points, at(14, 0)))
+	// Wraps around midnight
+	assert.Equal(t, Target{Temperature: 4000, Brightness: 60}, pointsTarget(points, at(2, 0)))
+	assert.Equal(t, Target{Temperature: 3500, Brightness: 50}, pointsTarget(points, at(23, 0)))
+
+	points[0].Brightness = 0
+	assert.Equal(t, Target{Temperature: 4000}, pointsTarget(points, at(14, 0)))
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -25,12 +28,13 @@ var ErrAPIKeyNotFound = fmt.Errorf("api key not found: %w", kerrors.ErrNotFound)
 
 // APIKey holds the information for an API authentication key.
 type APIKey struct {
-	Key        string    `json:"key" yaml:"key"`                   // The API key string (secret)
-	Name       string    `json:"name" yaml:"name"`                 // A user-friendly name for the key
-	CreatedAt  time.Time `json:"created_at" yaml:"created_at"`     // Timestamp of when the key was created
-	ExpiresAt  time.Time `json:"expires_at" yaml:"expires_at"`     // Timestamp of when the key expires (zero value means never)
-	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"` // Timestamp of when the key was last used (zero value means never)
-	Disabled   bool      `json:"disabled" yaml:"disabled"`         // If true, the key is disabled
+	Key        string    `json:"key" yaml:"key"`                           // The API key string (secret)
+	Name       string    `json:"name" yaml:"name"`                         // A user-friendly name for the key
+	CreatedAt  time.Time `json:"created_at" yaml:"created_at"`             // Timestamp of when the key was created
+	ExpiresAt  time.Time `json:"expires_at" yaml:"expires_at"`             // Timestamp of when the key expires (zero value means never)
+	LastUsedAt time.Time `json:"last_used_at" yaml:"last_used_at"`         // Timestamp of when the key was last used (zero value means never)
+	Disabled   bool      `json:"disabled" yaml:"disabled"`                 // If true, the key is disabled
+	Scopes     []string  `json:"scopes,omitempty" yaml:"scopes,omitempty"` // Permission scopes; empty grants every scope
 }
 
 // IsExpired checks if the API key has expired.
@@ -77,18 +81,61 @@ func GenerateKey(length int) (string, error) {
 // XDG helpers
 // Using path utility functions from utils.go
 
-// State holds persistent data like API keys and groups
+// State holds persistent data like API keys, groups, schedules, scenes, light names and tags
 type State struct {
-	APIKeys []APIKey       `yaml:"api_keys"`
-	Groups  map[string]any `yaml:"groups"`
+	APIKeys          []APIKey                     `yaml:"api_keys"`
+	Groups           map[string]any               `yaml:"groups"`
+	Schedules        map[string]any               `yaml:"schedules"`
+	Scenes           map[string]any               `yaml:"scenes"`
+	LightNames       map[string]string            `yaml:"light_names"`       // User-chosen display names keyed by light ID
+	LightTags        map[string]map[string]string `yaml:"light_tags"`        // Tags keyed by light ID, then tag key
+	LightStates      map[string]SavedLightState   `yaml:"light_states"`      // Last known light states, kept when discovery.restore_state is set
+	CircadianEnabled *bool                        `yaml:"circadian_enabled"` // Circadian mode as last toggled at runtime, overriding circadian.enabled
+	LightStats       map[string]LightStats        `yaml:"light_stats"`       // Usage statistics keyed by light ID
+	AdoptedDevices   []string                     `yaml:"adopted_devices"`   // IDs of devices accepted as lights although discovery validation rejects them
+	// Desired-state mode and its targets as last toggled at runtime, overriding
+	// desired_state.enabled and each target's disabled setting. Targets are
+	// keyed by DesiredState.Key.
+	DesiredStateEnabled *bool           `yaml:"desired_state_enabled"`
+	DesiredStateTargets map[string]bool `yaml:"desired_state_targets"`
+}
+
+// SavedLightState is the last known state of a light, re-applied when the light
+// is rediscovered.
+type SavedLightState struct {
+	On          bool `yaml:"on"`
+	Brightness  int  `yaml:"brightness"`
+	Temperature int  `yaml:"temperature"` // Device mireds
+}
+
+// LightStats is the usage of a light, saved periodically so it survives restarts.
+type LightStats struct {
+	OnSeconds  float64   `yaml:"on_seconds"`            // Cumulative time the light has been on
+	Toggles    int       `yaml:"toggles"`               // Times the light was turned on or off
+	LastChange time.Time `yaml:"last_change,omitempty"` // When its state last changed
+	LastToggle time.Time `yaml:"last_toggle,omitempty"` // When it was last turned on or off
+	Since      time.Time `yaml:"since"`                 // When tracking began
 }
 
 // ConfigBlock holds operational/configuration settings
 type ConfigBlock struct {
-	Server    ServerConfig    `yaml:"server"`
-	Discovery DiscoveryConfig `yaml:"discovery"`
-	Logging   LoggingConfig   `yaml:"logging"`
-	API       APIConfig       `yaml:"api"`
+	Server       ServerConfig       `yaml:"server"`
+	Discovery    DiscoveryConfig    `yaml:"discovery"`
+	Logging      LoggingConfig      `yaml:"logging"`
+	API          APIConfig          `yaml:"api"`
+	Lights       LightsConfig       `yaml:"lights"`
+	Groups       GroupsConfig       `yaml:"groups"`
+	MQTT         MQTTConfig         `yaml:"mqtt"`
+	HomeKit      HomeKitConfig      `yaml:"homekit"`
+	GRPC         GRPCConfig         `yaml:"grpc"`
+	Circadian    CircadianConfig    `yaml:"circadian"`
+	Schedules    SchedulesConfig    `yaml:"schedules"`
+	DesiredState DesiredStateConfig `mapstructure:"desired_state" yaml:"desired_state"`
+	Webcam       WebcamConfig       `yaml:"webcam"`
+	Hooks        []HookConfig       `yaml:"hooks"`
+	Simulation   SimulationConfig   `yaml:"simulation"`
+	Debug        DebugConfig        `yaml:"debug"`
+	Client       ClientConfig       `yaml:"client"` // keylightctl only
 }
 
 // Config represents the application configuration (top-level)
@@ -103,30 +150,501 @@ type ConfigBlock struct {
 //   - Consider renaming saveMutex -> mu for semantic clarity in a future refactor.
 //
 // NOTE: Any new exported method that reads or mutates State should follow the same locking discipline.
+//
+// Persistence: once OpenState has attached a StateStore, Save writes State to
+// the state file only and never touches the config file. Without a store (as
+// for keylightctl and in tests), Save writes both to the config file.
 type Config struct {
 	State  State       `yaml:"state"`
 	Config ConfigBlock `yaml:"config"`
 
 	v         *viper.Viper
+	store     *StateStore
 	saveMutex sync.RWMutex `mapstructure:"-" yaml:"-"`
 }
 
 // APIConfig represents the API specific configuration
 type APIConfig struct {
-	ListenAddress string   `mapstructure:"listen_address" yaml:"listen_address"`
-	APIKeys       []APIKey `mapstructure:"api_keys" yaml:"api_keys"`
+	ListenAddress   string          `mapstructure:"listen_address" yaml:"listen_address"`
+	ListenAddresses []APIListener   `mapstructure:"listen_addresses" yaml:"listen_addresses,omitempty"` // Addresses to serve the API on instead of listen_address
+	APIKeys         []APIKey        `mapstructure:"api_keys" yaml:"api_keys"`
+	MetricsEnabled  bool            `mapstructure:"metrics_enabled" yaml:"metrics_enabled"` // Serve Prometheus metrics at /metrics
+	TLS             TLSConfig       `mapstructure:"tls" yaml:"tls,omitempty"`
+	RateLimit       RateLimitConfig `mapstructure:"rate_limit" yaml:"rate_limit"`
+	BasePath        string          `mapstructure:"base_path" yaml:"base_path,omitempty"`             // Serve every HTTP route under this prefix, e.g. /keylight
+	TrustedProxies  []string        `mapstructure:"trusted_proxies" yaml:"trusted_proxies,omitempty"` // IPs or CIDRs of proxies whose X-Forwarded-For and X-Real-IP headers are trusted
+	CORS            CORSConfig      `mapstructure:"cors" yaml:"cors,omitempty"`                       // Cross-origin access for browser clients; disabled without allowed origins
+	Advertise       AdvertiseConfig `mapstructure:"advertise" yaml:"advertise,omitempty"`             // Announce the API on the local network with mDNS
+	RequestTimeout  int             `mapstructure:"request_timeout" yaml:"request_timeout,omitempty"` // Seconds a single HTTP API request or WebSocket command may take to process
+	AccessLog       AccessLogConfig `mapstructure:"access_log" yaml:"access_log,omitempty"`           // Log every HTTP API request with the API key that made it
+}
+
+// APIListener is an address the HTTP API is served on, with its own
+// authentication requirement.
+type APIListener struct {
+	Address string `mapstructure:"address" yaml:"address"`     // host:port, or unix:///path/to.sock
+	Auth    string `mapstructure:"auth" yaml:"auth,omitempty"` // api_key (default) or none; none is only allowed on Unix sockets
+}
+
+// UnixPath returns the socket path of a unix:// listener.
+func (l APIListener) UnixPath() (string, bool) {
+	return strings.CutPrefix(l.Address, UnixListenerScheme)
+}
+
+// Validate checks that the listener's address can be listened on and that
+// authentication is only turned off for Unix sockets, whose file permissions
+// limit who can connect.
+func (l APIListener) Validate() error {
+	path, unix := l.UnixPath()
+	switch {
+	case unix && !filepath.IsAbs(path):
+		return fmt.Errorf("unix socket path %q must be absolute", path)
+	case !unix:
+		if _, _, err := net.SplitHostPort(l.Address); err != nil {
+			return fmt.Errorf("invalid address %q, expected host:port or unix:///path", l.Address)
+		}
+	}
+	switch l.Auth {
+	case "", ListenerAuthAPIKey:
+	case ListenerAuthNone:
+		if !unix {
+			return fmt.Errorf("auth %q is only allowed on unix:// listeners", l.Auth)
+		}
+	default:
+		return fmt.Errorf("unknown auth %q, expected %s or %s", l.Auth, ListenerAuthAPIKey, ListenerAuthNone)
+	}
+	return nil
+}
+
+// RequiresAuth reports whether requests to the listener need an API key or
+// client certificate.
+func (l APIListener) RequiresAuth() bool {
+	return l.Auth != ListenerAuthNone
+}
+
+// Listeners returns the addresses the HTTP API is served on: every entry in
+// listen_addresses, or listen_address if there are none. It is empty when the
+// API is disabled.
+func (a APIConfig) Listeners() []APIListener {
+	if len(a.ListenAddresses) > 0 {
+		return a.ListenAddresses
+	}
+	if a.ListenAddress == "" {
+		return nil
+	}
+	return []APIListener{{Address: a.ListenAddress}}
+}
+
+// NormalizedBasePath returns BasePath with a leading slash and without a
+// trailing one, or "" if the API is served at the root.
+func (a APIConfig) NormalizedBasePath() string {
+	p := strings.Trim(a.BasePath, "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// RateLimitConfig represents HTTP API rate limiting and brute-force protection settings.
+// Each limit is disabled when set to 0.
+type RateLimitConfig struct {
+	RequestsPerMinute    int `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`         // Sustained requests per client IP
+	KeyRequestsPerMinute int `mapstructure:"key_requests_per_minute" yaml:"key_requests_per_minute"` // Sustained requests per API key
+	Burst                int `mapstructure:"burst" yaml:"burst"`                                     // Requests allowed back to back before the sustained rate applies
+	MaxFailedAuth        int `mapstructure:"max_failed_auth" yaml:"max_failed_auth"`                 // Invalid API keys from one IP before it is locked out
+	FailedAuthWindow     int `mapstructure:"failed_auth_window" yaml:"failed_auth_window"`           // Seconds over which invalid API keys are counted
+	LockoutDuration      int `mapstructure:"lockout_duration" yaml:"lockout_duration"`               // Seconds a locked out IP must wait
+}
+
+// DefaultRateLimit returns the default rate limiting settings.
+func DefaultRateLimit() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerMinute:    DefaultRateLimitRequestsPerMinute,
+		KeyRequestsPerMinute: DefaultRateLimitKeyRequestsPerMinute,
+		Burst:                DefaultRateLimitBurst,
+		MaxFailedAuth:        DefaultMaxFailedAuth,
+		FailedAuthWindow:     int(DefaultFailedAuthWindow.Seconds()),
+		LockoutDuration:      int(DefaultLockoutDuration.Seconds()),
+	}
+}
+
+// CORSConfig represents the cross-origin resource sharing settings of the HTTP API
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins" yaml:"allowed_origins,omitempty"`     // Origins allowed to call the API, or "*" for any
+	AllowedMethods   []string `mapstructure:"allowed_methods" yaml:"allowed_methods,omitempty"`     // Empty allows every method the API uses
+	AllowedHeaders   []string `mapstructure:"allowed_headers" yaml:"allowed_headers,omitempty"`     // Empty allows Authorization, Content-Type and X-API-Key; "*" allows any
+	AllowCredentials bool     `mapstructure:"allow_credentials" yaml:"allow_credentials,omitempty"` // Allow cookies and client certificates; not allowed with "*" origins
+	MaxAge           int      `mapstructure:"max_age" yaml:"max_age,omitempty"`                     // Seconds browsers may cache a preflight response; 0 leaves it to the browser
+}
+
+// Enabled reports whether the API server should answer cross-origin requests.
+func (c CORSConfig) Enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+// AdvertiseConfig represents the mDNS/DNS-SD announcement of the HTTP API,
+// which lets clients on the local network find the daemon without a URL
+type AdvertiseConfig struct {
+	Enabled    bool     `mapstructure:"enabled" yaml:"enabled"`
+	Name       string   `mapstructure:"name" yaml:"name,omitempty"`             // Service instance name (default: keylightd on <hostname>)
+	Interfaces []string `mapstructure:"interfaces" yaml:"interfaces,omitempty"` // Announce only on these interfaces; empty means all
+}
+
+// IsZero reports whether nothing about the announcement is configured.
+func (a AdvertiseConfig) IsZero() bool {
+	return !a.Enabled && a.Name == "" && len(a.Interfaces) == 0
+}
+
+// AccessLogConfig represents the HTTP API access log, one line per request
+// naming the API key or client certificate that made it.
+type AccessLogConfig struct {
+	Enabled    bool   `mapstructure:"enabled" yaml:"enabled"`
+	Format     string `mapstructure:"format" yaml:"format,omitempty"`           // common, combined (default) or json
+	Output     string `mapstructure:"output" yaml:"output,omitempty"`           // stdout (default), stderr or a file path
+	MaxSizeMB  int    `mapstructure:"max_size_mb" yaml:"max_size_mb,omitempty"` // Rotate the file once it reaches this size; 0 never rotates
+	MaxBackups int    `mapstructure:"max_backups" yaml:"max_backups,omitempty"` // Rotated files to keep (default: 5)
+}
+
+// IsZero reports whether nothing about the access log is configured.
+func (a AccessLogConfig) IsZero() bool {
+	return a == AccessLogConfig{}
+}
+
+// TLSConfig represents HTTPS and client certificate settings for the API server
+type TLSConfig struct {
+	CertFile     string `mapstructure:"cert_file" yaml:"cert_file,omitempty"`           // Server certificate; enables HTTPS
+	KeyFile      string `mapstructure:"key_file" yaml:"key_file,omitempty"`             // Server private key
+	ClientCAFile string `mapstructure:"client_ca_file" yaml:"client_ca_file,omitempty"` // Require client certificates signed by this CA
+	// ClientScopes maps a client certificate's common name to the scopes it is granted.
+	ClientScopes map[string][]string `mapstructure:"client_scopes" yaml:"client_scopes,omitempty"`
+	// DefaultClientScopes are granted to verified certificates not listed in ClientScopes; empty grants all scopes.
+	DefaultClientScopes []string `mapstructure:"default_client_scopes" yaml:"default_client_scopes,omitempty"`
+}
+
+// Enabled reports whether the API server should serve HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
 }
 
 // ServerConfig represents the server configuration
 type ServerConfig struct {
-	UnixSocket string `mapstructure:"unix_socket" yaml:"unix_socket"`
+	UnixSocket     string `mapstructure:"unix_socket" yaml:"unix_socket"`
+	StateFile      string `mapstructure:"state_file" yaml:"state_file,omitempty"`             // Defaults to $XDG_STATE_HOME/keylightd/state.yaml
+	MaxRequestSize int    `mapstructure:"max_request_size" yaml:"max_request_size,omitempty"` // Largest socket request line in bytes
+	IdleTimeout    int    `mapstructure:"idle_timeout" yaml:"idle_timeout,omitempty"`         // Seconds a socket connection may take to send its next request
+	RequestTimeout int    `mapstructure:"request_timeout" yaml:"request_timeout,omitempty"`   // Seconds a single socket request may take to process
 }
 
 // DiscoveryConfig represents the discovery configuration
 type DiscoveryConfig struct {
-	Interval        int `mapstructure:"interval" yaml:"interval"`
-	CleanupInterval int `mapstructure:"cleanup_interval" yaml:"cleanup_interval"`
-	CleanupTimeout  int `mapstructure:"cleanup_timeout" yaml:"cleanup_timeout"`
+	Interval         int              `mapstructure:"interval" yaml:"interval"`
+	CleanupInterval  int              `mapstructure:"cleanup_interval" yaml:"cleanup_interval"`
+	CleanupTimeout   int              `mapstructure:"cleanup_timeout" yaml:"cleanup_timeout"`
+	OfflineRetention int              `mapstructure:"offline_retention" yaml:"offline_retention"` // Seconds an offline light is kept before it is forgotten
+	IdleInterval     int              `mapstructure:"idle_interval" yaml:"idle_interval"`         // Seconds between discovery passes while no clients are active; interval or less disables slowing down
+	IdleAfter        int              `mapstructure:"idle_after" yaml:"idle_after"`               // Seconds after the last socket or HTTP request that clients count as idle
+	Interfaces       []string         `mapstructure:"interfaces" yaml:"interfaces,omitempty"`     // Browse only these interfaces; empty means automatic selection
+	Backends         []string         `mapstructure:"backends" yaml:"backends,omitempty"`         // Discovery backends whose results are combined (mdns, ssdp, scan); empty means mdns
+	Scan             ScanConfig       `mapstructure:"scan" yaml:"scan,omitempty"`                 // Subnets probed by the scan backend
+	Validation       ValidationConfig `mapstructure:"validation" yaml:"validation,omitempty"`     // Elgato products accepted as lights besides the known models
+	RestoreState     bool             `mapstructure:"restore_state" yaml:"restore_state"`         // Re-apply the last known state to lights when they are rediscovered
+}
+
+// ValidationConfig extends the Elgato products discovery accepts as lights,
+// for models released after this version of keylightd
+type ValidationConfig struct {
+	ProductPrefixes []string `mapstructure:"product_prefixes" yaml:"product_prefixes,omitempty"` // Accept products whose name starts with one of these
+	ProductPatterns []string `mapstructure:"product_patterns" yaml:"product_patterns,omitempty"` // Accept products whose name matches one of these regular expressions
+	AcceptAnyLight  bool     `mapstructure:"accept_any_light" yaml:"accept_any_light,omitempty"` // Accept any _elg._tcp device whose accessory info lists the lights feature
+}
+
+// ScanConfig configures the subnet scan discovery backend
+type ScanConfig struct {
+	Subnets     []string `mapstructure:"subnets" yaml:"subnets,omitempty"`         // IPv4 CIDR ranges to probe, each no larger than a /20
+	TimeoutMS   int      `mapstructure:"timeout_ms" yaml:"timeout_ms,omitempty"`   // Time each address is given to answer; 0 uses the default
+	Concurrency int      `mapstructure:"concurrency" yaml:"concurrency,omitempty"` // Addresses probed at once; 0 uses the default
+}
+
+// LightsConfig represents light settings that are not discovered automatically
+type LightsConfig struct {
+	Static     []StaticLight   `mapstructure:"static" yaml:"static,omitempty"`
+	Retry      RetryConfig     `mapstructure:"retry" yaml:"retry"`
+	Limits     []LightLimit    `mapstructure:"limits" yaml:"limits,omitempty"`
+	DebounceMS int             `mapstructure:"debounce_ms" yaml:"debounce_ms"` // Shortest time between brightness or temperature updates sent to a light (0 sends every update)
+	TimeoutMS  int             `mapstructure:"timeout_ms" yaml:"timeout_ms"`   // Milliseconds a request to a light may take
+	Overrides  []LightOverride `mapstructure:"overrides" yaml:"overrides,omitempty"`
+	// Seconds between state refreshes that notice changes made outside keylightd; 0 leaves them to discovery
+	PollInterval int            `mapstructure:"poll_interval" yaml:"poll_interval,omitempty"`
+	Shutdown     ShutdownConfig `mapstructure:"shutdown" yaml:"shutdown,omitempty"` // What happens to lights when the daemon stops
+}
+
+// ShutdownConfig sets what happens to lights when the daemon stops. Lights
+// turned off or parked get their previous state back when it starts again.
+type ShutdownConfig struct {
+	Action      string `mapstructure:"action" yaml:"action,omitempty"`           // leave (default) keeps lights as they are, off turns them off, park sets the state below
+	On          bool   `mapstructure:"on" yaml:"on,omitempty"`                   // park: leave the lights on
+	Brightness  int    `mapstructure:"brightness" yaml:"brightness,omitempty"`   // park: brightness to leave the lights at; 0 keeps their own
+	Temperature int    `mapstructure:"temperature" yaml:"temperature,omitempty"` // park: Kelvin to leave the lights at; 0 keeps their own
+}
+
+// ChangesLights reports whether lights are turned off or parked on shutdown.
+func (s ShutdownConfig) ChangesLights() bool {
+	return s.Action == ShutdownOff || s.Action == ShutdownPark
+}
+
+// GroupsConfig represents how changes to a group are applied to its lights
+type GroupsConfig struct {
+	MaxConcurrency int    `mapstructure:"max_concurrency" yaml:"max_concurrency,omitempty"` // Lights changed at once per group operation; 0 changes them all at once
+	FailurePolicy  string `mapstructure:"failure_policy" yaml:"failure_policy,omitempty"`   // best-effort (default) changes every light; fail-fast skips lights not yet started once one fails
+}
+
+// FailFast reports whether group operations stop at the first failed light.
+func (g GroupsConfig) FailFast() bool {
+	return g.FailurePolicy == FailurePolicyFailFast
+}
+
+// LightLimit restricts the brightness and temperature a light, or every light
+// in a group, may be set to. Requests outside the range are clamped to it.
+// Zero values leave that end of the range unrestricted.
+type LightLimit struct {
+	Light          string `mapstructure:"light" yaml:"light,omitempty"`                     // Light ID
+	Group          string `mapstructure:"group" yaml:"group,omitempty"`                     // Group ID or name
+	MinBrightness  int    `mapstructure:"min_brightness" yaml:"min_brightness,omitempty"`   // Lowest brightness (3-100)
+	MaxBrightness  int    `mapstructure:"max_brightness" yaml:"max_brightness,omitempty"`   // Highest brightness (3-100)
+	MinTemperature int    `mapstructure:"min_temperature" yaml:"min_temperature,omitempty"` // Warmest color temperature in Kelvin (2900-7000)
+	MaxTemperature int    `mapstructure:"max_temperature" yaml:"max_temperature,omitempty"` // Coolest color temperature in Kelvin (2900-7000)
+}
+
+// RetryConfig represents how failed requests to lights are retried, and when
+// requests to a light that keeps failing are paused.
+type RetryConfig struct {
+	Attempts         int `mapstructure:"attempts" yaml:"attempts"`                   // Tries per request, including the first; 1 disables retries
+	BackoffMS        int `mapstructure:"backoff_ms" yaml:"backoff_ms"`               // Milliseconds before the first retry; doubles for each retry, with jitter
+	MaxBackoffMS     int `mapstructure:"max_backoff_ms" yaml:"max_backoff_ms"`       // Longest wait between retries in milliseconds
+	TimeoutMS        int `mapstructure:"timeout_ms" yaml:"timeout_ms"`               // Milliseconds each try may take
+	BreakerThreshold int `mapstructure:"breaker_threshold" yaml:"breaker_threshold"` // Failed requests in a row before requests to the light are paused; 0 disables
+	BreakerCooldown  int `mapstructure:"breaker_cooldown" yaml:"breaker_cooldown"`   // Seconds requests are paused before the light is tried again
+}
+
+// DefaultRetry returns the default retry settings.
+func DefaultRetry() RetryConfig {
+	return RetryConfig{
+		Attempts:         DefaultRetryAttempts,
+		BackoffMS:        int(DefaultRetryBackoff.Milliseconds()),
+		MaxBackoffMS:     int(DefaultRetryMaxBackoff.Milliseconds()),
+		TimeoutMS:        int(DefaultDeviceTimeout.Milliseconds()),
+		BreakerThreshold: DefaultBreakerThreshold,
+		BreakerCooldown:  int(DefaultBreakerCooldown.Seconds()),
+	}
+}
+
+// LightOverride changes how a light is reached, for lights behind a
+// port-forward, proxy or VPN
+type LightOverride struct {
+	ID        string `mapstructure:"id" yaml:"id"`                           // Light ID
+	Host      string `mapstructure:"host" yaml:"host,omitempty"`             // Host name or IP address to connect to instead of the light's own
+	Port      int    `mapstructure:"port" yaml:"port,omitempty"`             // Port to connect to instead of the light's own
+	TimeoutMS int    `mapstructure:"timeout_ms" yaml:"timeout_ms,omitempty"` // Milliseconds a request to the light may take; 0 uses config.lights.timeout_ms
+}
+
+// StaticLight declares a light at a fixed address, for networks where mDNS discovery does not work
+type StaticLight struct {
+	ID     string `mapstructure:"id" yaml:"id,omitempty"`         // Light ID (defaults to name, then ip:port)
+	Name   string `mapstructure:"name" yaml:"name,omitempty"`     // Display name (defaults to the name reported by the device)
+	IP     string `mapstructure:"ip" yaml:"ip"`                   // IP address of the light
+	Port   int    `mapstructure:"port" yaml:"port,omitempty"`     // Port (defaults to the driver's standard port)
+	Driver string `mapstructure:"driver" yaml:"driver,omitempty"` // Light driver (elgato, wled; default elgato)
+}
+
+// SimulationConfig replaces light discovery with virtual lights simulated in
+// memory, for developing and testing clients without hardware
+type SimulationConfig struct {
+	Enabled     bool    `mapstructure:"enabled" yaml:"enabled"`
+	Lights      int     `mapstructure:"lights" yaml:"lights,omitempty"`             // Virtual lights to create; 0 uses the default
+	LatencyMS   int     `mapstructure:"latency_ms" yaml:"latency_ms,omitempty"`     // Milliseconds added to every request to a virtual light
+	FailureRate float64 `mapstructure:"failure_rate" yaml:"failure_rate,omitempty"` // Fraction of requests (0-1) that fail at random
+}
+
+// LightCount returns the number of virtual lights to create.
+func (s SimulationConfig) LightCount() int {
+	if s.Lights > 0 {
+		return s.Lights
+	}
+	return DefaultSimulationLights
+}
+
+// ClientConfig holds keylightctl's named connections to daemons, in its own
+// config file.
+type ClientConfig struct {
+	CurrentContext string          `mapstructure:"current_context" yaml:"current_context,omitempty"` // Context used without --context; empty uses the local socket
+	Contexts       []ClientContext `mapstructure:"contexts" yaml:"contexts,omitempty"`
+}
+
+// ClientContext is a named daemon keylightctl can talk to, over the HTTP API
+// or a Unix socket.
+type ClientContext struct {
+	Name   string `mapstructure:"name" yaml:"name"`
+	APIURL string `mapstructure:"api_url" yaml:"api_url,omitempty"` // Base URL of the daemon's HTTP API
+	APIKey string `mapstructure:"api_key" yaml:"api_key,omitempty"` //nolint:gosec // G117: config field, not a hardcoded secret
+	Socket string `mapstructure:"socket" yaml:"socket,omitempty"`   // Unix socket path, used when api_url is empty
+}
+
+// Context returns the context with the given name.
+func (c ClientConfig) Context(name string) (ClientContext, bool) {
+	for _, ctx := range c.Contexts {
+		if ctx.Name == name {
+			return ctx, true
+		}
+	}
+	return ClientContext{}, false
+}
+
+// DebugConfig holds settings that help diagnose problems with the daemon.
+type DebugConfig struct {
+	CrashReports bool   `mapstructure:"crash_reports" yaml:"crash_reports"`   // Write a report with every goroutine's stack when the daemon panics
+	CrashDir     string `mapstructure:"crash_dir" yaml:"crash_dir,omitempty"` // Crash report directory (default: <state dir>/crashes)
+}
+
+// ReportDir returns the crash report directory.
+func (d DebugConfig) ReportDir() string {
+	if d.CrashDir != "" {
+		return d.CrashDir
+	}
+	return GetCrashReportDir()
+}
+
+// MQTTConfig represents the Home Assistant MQTT bridge configuration
+type MQTTConfig struct {
+	Broker          string `mapstructure:"broker" yaml:"broker"`                               // Broker address (tcp://host:1883, ssl://host:8883); empty disables the bridge
+	Username        string `mapstructure:"username" yaml:"username,omitempty"`                 // Broker user name
+	Password        string `mapstructure:"password" yaml:"password,omitempty"`                 // Broker password
+	ClientID        string `mapstructure:"client_id" yaml:"client_id,omitempty"`               // MQTT client ID (defaults to keylightd-<hostname>)
+	TopicPrefix     string `mapstructure:"topic_prefix" yaml:"topic_prefix,omitempty"`         // Prefix for state and command topics (default keylightd)
+	DiscoveryPrefix string `mapstructure:"discovery_prefix" yaml:"discovery_prefix,omitempty"` // Home Assistant discovery prefix (default homeassistant)
+}
+
+// HomeKitConfig represents the HomeKit accessory bridge configuration
+type HomeKitConfig struct {
+	Enabled     bool   `mapstructure:"enabled" yaml:"enabled"`
+	Pin         string `mapstructure:"pin" yaml:"pin"`                             // 8-digit setup code entered in the Home app
+	Port        int    `mapstructure:"port" yaml:"port,omitempty"`                 // HAP server port (default: a free port)
+	StoragePath string `mapstructure:"storage_path" yaml:"storage_path,omitempty"` // Pairing data directory (default: <config dir>/homekit)
+}
+
+// GRPCConfig represents the gRPC API configuration. The gRPC services are
+// always served on the Unix socket; this only controls the TCP listener.
+type GRPCConfig struct {
+	ListenAddress string `mapstructure:"listen_address" yaml:"listen_address"` // TCP address to serve gRPC on (requires an API key; uses api.tls); empty disables
+}
+
+// SchedulesConfig holds settings for schedules.
+type SchedulesConfig struct {
+	Timezone string `mapstructure:"timezone" yaml:"timezone,omitempty"` // IANA time zone schedules run in unless they set their own (default: the host's)
+}
+
+// CircadianConfig represents circadian mode, which shifts the color temperature
+// (and optionally brightness) of lights that are on across the day. The curve
+// follows the sun at Latitude/Longitude unless Points are given.
+type CircadianConfig struct {
+	Enabled          bool             `mapstructure:"enabled" yaml:"enabled"`
+	Latitude         float64          `mapstructure:"latitude" yaml:"latitude,omitempty"`
+	Longitude        float64          `mapstructure:"longitude" yaml:"longitude,omitempty"`
+	DayTemperature   int              `mapstructure:"day_temperature" yaml:"day_temperature"`         // Kelvin while the sun is up
+	NightTemperature int              `mapstructure:"night_temperature" yaml:"night_temperature"`     // Kelvin while the sun is down
+	DayBrightness    int              `mapstructure:"day_brightness" yaml:"day_brightness,omitempty"` // Brightness while the sun is up; 0 leaves brightness alone
+	NightBrightness  int              `mapstructure:"night_brightness" yaml:"night_brightness,omitempty"`
+	Transition       int              `mapstructure:"transition" yaml:"transition"`   // Minutes taken to shift between day and night, centred on sunrise and sunset
+	Points           []CircadianPoint `mapstructure:"points" yaml:"points,omitempty"` // Explicit curve, used instead of the sun
+	Lights           []string         `mapstructure:"lights" yaml:"lights,omitempty"` // Light IDs to adjust
+	Groups           []string         `mapstructure:"groups" yaml:"groups,omitempty"` // Group IDs or names to adjust; all lights when neither is set
+	Interval         int              `mapstructure:"interval" yaml:"interval"`       // Seconds between updates
+}
+
+// CircadianPoint is a point on an explicit circadian curve. Values between
+// points are interpolated.
+type CircadianPoint struct {
+	At          string `mapstructure:"at" yaml:"at"`                           // Time of day (HH:MM, daemon local time)
+	Temperature int    `mapstructure:"temperature" yaml:"temperature"`         // Kelvin
+	Brightness  int    `mapstructure:"brightness" yaml:"brightness,omitempty"` // 0 leaves brightness alone
+}
+
+// HasLocation reports whether a location is configured for following the sun.
+func (c CircadianConfig) HasLocation() bool {
+	return c.Latitude != 0 || c.Longitude != 0
+}
+
+// DefaultCircadian returns the default circadian settings.
+func DefaultCircadian() CircadianConfig {
+	return CircadianConfig{
+		DayTemperature:   DefaultCircadianDayTemperature,
+		NightTemperature: DefaultCircadianNightTemperature,
+		Transition:       int(DefaultCircadianTransition.Minutes()),
+		Interval:         int(DefaultCircadianInterval.Seconds()),
+	}
+}
+
+// DesiredStateConfig represents desired-state mode, in which the daemon keeps
+// lights and groups in a declared state, putting them back when something else
+// changes them.
+type DesiredStateConfig struct {
+	Enabled  bool           `mapstructure:"enabled" yaml:"enabled"`
+	Interval int            `mapstructure:"interval" yaml:"interval"` // Seconds between checks
+	Targets  []DesiredState `mapstructure:"targets" yaml:"targets,omitempty"`
+}
+
+// DesiredState is the state a light, or the lights of a group, are kept in.
+// Properties left unset are not enforced.
+type DesiredState struct {
+	Light       string `mapstructure:"light" yaml:"light,omitempty"` // Light ID
+	Group       string `mapstructure:"group" yaml:"group,omitempty"` // Group ID or name
+	On          *bool  `mapstructure:"on" yaml:"on,omitempty"`
+	Brightness  *int   `mapstructure:"brightness" yaml:"brightness,omitempty"`
+	Temperature *int   `mapstructure:"temperature" yaml:"temperature,omitempty"` // Kelvin
+	Disabled    bool   `mapstructure:"disabled" yaml:"disabled,omitempty"`       // Leave the target alone without removing it
+}
+
+// Key returns the key identifying the target: light:<id> or group:<id or name>.
+func (d DesiredState) Key() string {
+	if d.Group != "" {
+		return "group:" + d.Group
+	}
+	return "light:" + d.Light
+}
+
+// DefaultDesiredState returns the default desired-state settings.
+func DefaultDesiredState() DesiredStateConfig {
+	return DesiredStateConfig{Interval: int(DefaultDesiredStateInterval.Seconds())}
+}
+
+// WebcamConfig represents turning groups on while a webcam is in use and off
+// once it is released. Detection is only supported on Linux.
+type WebcamConfig struct {
+	Enabled      bool     `mapstructure:"enabled" yaml:"enabled"`
+	Groups       []string `mapstructure:"groups" yaml:"groups,omitempty"`     // Group IDs or names to power with the webcam
+	Devices      []string `mapstructure:"devices" yaml:"devices,omitempty"`   // Video devices to watch (default: every /dev/video*)
+	PollInterval int      `mapstructure:"poll_interval" yaml:"poll_interval"` // Seconds between checks
+	OffDelay     int      `mapstructure:"off_delay" yaml:"off_delay"`         // Seconds the webcam must stay released before the groups are turned off
+}
+
+// DefaultWebcam returns the default webcam settings.
+func DefaultWebcam() WebcamConfig {
+	return WebcamConfig{
+		PollInterval: int(DefaultWebcamPollInterval.Seconds()),
+		OffDelay:     int(DefaultWebcamOffDelay.Seconds()),
+	}
+}
+
+// HookConfig represents a shell command run when a matching event occurs.
+// Filters that are left empty match every event.
+type HookConfig struct {
+	Name       string   `mapstructure:"name" yaml:"name,omitempty"`               // Name used in logs (defaults to the command)
+	Events     []string `mapstructure:"events" yaml:"events"`                     // Event types to run on, such as light.state_changed; light.* matches every light event
+	Lights     []string `mapstructure:"lights" yaml:"lights,omitempty"`           // Only events for these light IDs or names
+	Groups     []string `mapstructure:"groups" yaml:"groups,omitempty"`           // Only events for these groups or their lights (IDs or names)
+	On         *bool    `mapstructure:"on" yaml:"on,omitempty"`                   // Only light events where the light is on (true) or off (false)
+	Command    string   `mapstructure:"command" yaml:"command"`                   // Run with sh -c, with the event in KEYLIGHT_* environment variables
+	DebounceMS int      `mapstructure:"debounce_ms" yaml:"debounce_ms,omitempty"` // Wait until events for a light or group stop for this long, then run once for the latest
+	TimeoutMS  int      `mapstructure:"timeout_ms" yaml:"timeout_ms,omitempty"`   // Kill the command after this long (default 10s)
 }
 
 // LoggingConfig represents the logging configuration
@@ -134,6 +652,12 @@ type LoggingConfig struct {
 	Level   string                `mapstructure:"level" yaml:"level"`
 	Format  string                `mapstructure:"format" yaml:"format"`
 	Filters []logfilter.LogFilter `mapstructure:"filters" yaml:"filters,omitempty"`
+	// BufferSize is the number of recent log lines kept in memory for the
+	// logs API and keylightctl logs. 0 uses DefaultLogBufferSize.
+	BufferSize int `mapstructure:"buffer_size" yaml:"buffer_size,omitempty"`
+	// DeviceRequests logs every request to a light and its response, with
+	// component=device. Serial numbers are redacted unless logging at debug.
+	DeviceRequests bool `mapstructure:"device_requests" yaml:"device_requests,omitempty"`
 }
 
 // New creates a new Config with the given viper instance
@@ -149,12 +673,45 @@ func Load(configName, configFile string) (*Config, error) {
 
 	// Set default values
 	v.SetDefault("config.server.unix_socket", GetRuntimeSocketPath())
+	v.SetDefault("config.server.max_request_size", DefaultSocketMaxRequestSize)
+	v.SetDefault("config.server.idle_timeout", int(DefaultSocketIdleTimeout.Seconds()))
+	v.SetDefault("config.server.request_timeout", int(DefaultSocketRequestTimeout.Seconds()))
 	v.SetDefault("config.discovery.interval", int(DefaultDiscoveryInterval.Seconds()))
 	v.SetDefault("config.logging.level", LogLevelInfo)
 	v.SetDefault("config.logging.format", LogFormatText)
+	v.SetDefault("config.logging.buffer_size", DefaultLogBufferSize)
 	v.SetDefault("config.discovery.cleanup_interval", int(DefaultCleanupInterval.Seconds()))
 	v.SetDefault("config.discovery.cleanup_timeout", int(DefaultStateTimeout.Seconds()))
+	v.SetDefault("config.discovery.offline_retention", int(DefaultOfflineRetention.Seconds()))
+	v.SetDefault("config.discovery.idle_interval", int(DefaultDiscoveryIdleInterval.Seconds()))
+	v.SetDefault("config.discovery.idle_after", int(DefaultIdleAfter.Seconds()))
 	v.SetDefault("config.api.listen_address", DefaultAPIListenAddress)
+	v.SetDefault("config.api.request_timeout", int(DefaultAPIRequestTimeout.Seconds()))
+	defaultRateLimit := DefaultRateLimit()
+	v.SetDefault("config.api.rate_limit.requests_per_minute", defaultRateLimit.RequestsPerMinute)
+	v.SetDefault("config.api.rate_limit.key_requests_per_minute", defaultRateLimit.KeyRequestsPerMinute)
+	v.SetDefault("config.api.rate_limit.burst", defaultRateLimit.Burst)
+	v.SetDefault("config.api.rate_limit.max_failed_auth", defaultRateLimit.MaxFailedAuth)
+	v.SetDefault("config.api.rate_limit.failed_auth_window", defaultRateLimit.FailedAuthWindow)
+	v.SetDefault("config.api.rate_limit.lockout_duration", defaultRateLimit.LockoutDuration)
+	defaultRetry := DefaultRetry()
+	v.SetDefault("config.lights.timeout_ms", int(DefaultDeviceTimeout.Milliseconds()))
+	v.SetDefault("config.lights.retry.attempts", defaultRetry.Attempts)
+	v.SetDefault("config.lights.retry.backoff_ms", defaultRetry.BackoffMS)
+	v.SetDefault("config.lights.retry.max_backoff_ms", defaultRetry.MaxBackoffMS)
+	v.SetDefault("config.lights.retry.timeout_ms", defaultRetry.TimeoutMS)
+	v.SetDefault("config.lights.retry.breaker_threshold", defaultRetry.BreakerThreshold)
+	v.SetDefault("config.lights.retry.breaker_cooldown", defaultRetry.BreakerCooldown)
+	v.SetDefault("config.lights.debounce_ms", DefaultDebounceWindow.Milliseconds())
+	defaultCircadian := DefaultCircadian()
+	v.SetDefault("config.circadian.day_temperature", defaultCircadian.DayTemperature)
+	v.SetDefault("config.circadian.night_temperature", defaultCircadian.NightTemperature)
+	v.SetDefault("config.circadian.transition", defaultCircadian.Transition)
+	v.SetDefault("config.circadian.interval", defaultCircadian.Interval)
+	v.SetDefault("config.desired_state.interval", DefaultDesiredState().Interval)
+	defaultWebcam := DefaultWebcam()
+	v.SetDefault("config.webcam.poll_interval", defaultWebcam.PollInterval)
+	v.SetDefault("config.webcam.off_delay", defaultWebcam.OffDelay)
 	v.SetDefault("state.api_keys", []APIKey{})
 
 	// Add config paths
@@ -205,6 +762,18 @@ func Load(configName, configFile string) (*Config, error) {
 	if cfg.Config.Server.UnixSocket == "" {
 		cfg.Config.Server.UnixSocket = GetRuntimeSocketPath()
 	}
+	if cfg.Config.Server.MaxRequestSize <= 0 {
+		cfg.Config.Server.MaxRequestSize = DefaultSocketMaxRequestSize
+	}
+	if cfg.Config.Server.IdleTimeout <= 0 {
+		cfg.Config.Server.IdleTimeout = int(DefaultSocketIdleTimeout.Seconds())
+	}
+	if cfg.Config.Server.RequestTimeout <= 0 {
+		cfg.Config.Server.RequestTimeout = int(DefaultSocketRequestTimeout.Seconds())
+	}
+	if cfg.Config.API.RequestTimeout <= 0 {
+		cfg.Config.API.RequestTimeout = int(DefaultAPIRequestTimeout.Seconds())
+	}
 	if cfg.Config.Discovery.Interval == 0 {
 		cfg.Config.Discovery.Interval = int(DefaultDiscoveryInterval.Seconds())
 	} else {
@@ -216,9 +785,33 @@ func Load(configName, configFile string) (*Config, error) {
 	if cfg.Config.Discovery.CleanupTimeout == 0 {
 		cfg.Config.Discovery.CleanupTimeout = int(DefaultStateTimeout.Seconds())
 	}
+	if cfg.Config.Discovery.OfflineRetention <= 0 {
+		cfg.Config.Discovery.OfflineRetention = int(DefaultOfflineRetention.Seconds())
+	}
+	if cfg.Config.Discovery.IdleInterval <= 0 {
+		cfg.Config.Discovery.IdleInterval = int(DefaultDiscoveryIdleInterval.Seconds())
+	}
+	if cfg.Config.Discovery.IdleInterval < cfg.Config.Discovery.Interval {
+		cfg.Config.Discovery.IdleInterval = cfg.Config.Discovery.Interval
+	}
+	if cfg.Config.Discovery.IdleAfter <= 0 {
+		cfg.Config.Discovery.IdleAfter = int(DefaultIdleAfter.Seconds())
+	}
+	cfg.Config.Lights.PollInterval = ValidatePollInterval(cfg.Config.Lights.PollInterval)
 	if cfg.Config.API.ListenAddress == "" {
 		cfg.Config.API.ListenAddress = DefaultAPIListenAddress
 	}
+	cfg.Config.Lights.Limits = ValidateLightLimits(cfg.Config.Lights.Limits)
+	cfg.Config.Circadian = ValidateCircadian(cfg.Config.Circadian)
+	cfg.Config.DesiredState = ValidateDesiredState(cfg.Config.DesiredState)
+	cfg.Config.Groups = ValidateGroups(cfg.Config.Groups)
+	cfg.Config.Lights.Shutdown = ValidateShutdown(cfg.Config.Lights.Shutdown)
+	if cfg.Config.Webcam.PollInterval <= 0 {
+		cfg.Config.Webcam.PollInterval = int(DefaultWebcamPollInterval.Seconds())
+	}
+	if cfg.Config.Webcam.OffDelay < 0 {
+		cfg.Config.Webcam.OffDelay = 0
+	}
 	// Use default values if logging configuration is invalid
 	if cfg.Config.Logging.Level != LogLevelDebug && cfg.Config.Logging.Level != LogLevelInfo &&
 		cfg.Config.Logging.Level != LogLevelWarn && cfg.Config.Logging.Level != LogLevelError {
@@ -228,28 +821,83 @@ func Load(configName, configFile string) (*Config, error) {
 	if cfg.Config.Logging.Format != LogFormatText && cfg.Config.Logging.Format != LogFormatJSON {
 		cfg.Config.Logging.Format = LogFormatText
 	}
+	if cfg.Config.Logging.BufferSize <= 0 {
+		cfg.Config.Logging.BufferSize = DefaultLogBufferSize
+	}
 	return cfg, nil
 }
 
-// Save saves the configuration to file
+// OpenState opens and locks the state file at path and moves persistence of
+// State to it. If the state file doesn't exist yet, any state read from the
+// config file's legacy state block is migrated and written to it; the config
+// file itself is left alone. Call CloseState on shutdown to release the lock.
+func (c *Config) OpenState(path string) error {
+	store, err := OpenStateStore(path)
+	if err != nil {
+		return err
+	}
+	state, found, err := store.Load()
+	if err != nil {
+		_ = store.Close()
+		return err
+	}
+
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	if found {
+		c.State = state
+	} else if !c.State.isEmpty() {
+		migrated, err := migrateLegacyState(&c.State)
+		if err != nil {
+			_ = store.Close()
+			return fmt.Errorf("error migrating state from the config file: %w", err)
+		}
+		c.State = migrated
+		if err := store.Save(&c.State); err != nil {
+			_ = store.Close()
+			return err
+		}
+		slog.Info("Moved state out of the config file; its state block is no longer read and can be removed",
+			"config", c.v.ConfigFileUsed(), "state", path)
+	}
+	c.store = store
+	return nil
+}
+
+// CloseState releases the state file opened by OpenState. Later saves go to
+// the config file again.
+func (c *Config) CloseState() error {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	if c.store == nil {
+		return nil
+	}
+	err := c.store.Close()
+	c.store = nil
+	return err
+}
+
+// Save persists State to the state file when one is open, and otherwise saves
+// the configuration and state to the config file.
 func (c *Config) Save() error {
 	c.saveMutex.Lock()
 	defer c.saveMutex.Unlock()
 
 	logger := slog.Default()
-	logger.Debug("Saving configuration", "path", c.v.ConfigFileUsed())
+	if c.store != nil {
+		logger.Debug("Saving state", "path", c.store.Path())
+		if err := c.store.Save(&c.State); err != nil {
+			return err
+		}
+		logger.Debug("State saved successfully", "path", c.store.Path())
+		return nil
+	}
 
+	logger.Debug("Saving configuration", "path", c.v.ConfigFileUsed())
 	settings := map[string]any{}
 
-	// Only write state if api_keys or groups are non-empty
-	stateMap := map[string]any{}
-	if len(c.State.APIKeys) > 0 {
-		stateMap["api_keys"] = c.State.APIKeys
-	}
-	if len(c.State.Groups) > 0 {
-		stateMap["groups"] = c.State.Groups
-	}
-	if len(stateMap) > 0 {
+	// Only write state if any section is non-empty
+	if stateMap := c.State.sections(); len(stateMap) > 0 {
 		settings["state"] = stateMap
 	}
 
@@ -264,9 +912,55 @@ func (c *Config) Save() error {
 	if !isDefaultLogging(c.Config.Logging) {
 		configMap["logging"] = c.Config.Logging
 	}
-	if c.Config.API.ListenAddress != DefaultAPIListenAddress {
+	if c.Config.API.ListenAddress != DefaultAPIListenAddress || c.Config.API.MetricsEnabled || c.Config.API.TLS.Enabled() ||
+		c.Config.API.RateLimit != DefaultRateLimit() || c.Config.API.BasePath != "" || len(c.Config.API.TrustedProxies) > 0 ||
+		c.Config.API.CORS.Enabled() || len(c.Config.API.ListenAddresses) > 0 || !c.Config.API.Advertise.IsZero() ||
+		!c.Config.API.AccessLog.IsZero() ||
+		(c.Config.API.RequestTimeout != 0 && c.Config.API.RequestTimeout != int(DefaultAPIRequestTimeout.Seconds())) {
 		configMap["api"] = c.Config.API
 	}
+	if len(c.Config.Lights.Static) > 0 || c.Config.Lights.Retry != DefaultRetry() || len(c.Config.Lights.Limits) > 0 ||
+		int64(c.Config.Lights.DebounceMS) != DefaultDebounceWindow.Milliseconds() ||
+		int64(c.Config.Lights.TimeoutMS) != DefaultDeviceTimeout.Milliseconds() || len(c.Config.Lights.Overrides) > 0 ||
+		c.Config.Lights.PollInterval != 0 || c.Config.Lights.Shutdown != (ShutdownConfig{}) {
+		configMap["lights"] = c.Config.Lights
+	}
+	if c.Config.Groups != (GroupsConfig{}) {
+		configMap["groups"] = c.Config.Groups
+	}
+	if c.Config.MQTT.Broker != "" {
+		configMap["mqtt"] = c.Config.MQTT
+	}
+	if c.Config.HomeKit.Enabled {
+		configMap["homekit"] = c.Config.HomeKit
+	}
+	if c.Config.GRPC.ListenAddress != "" {
+		configMap["grpc"] = c.Config.GRPC
+	}
+	if !isDefaultCircadian(c.Config.Circadian) {
+		configMap["circadian"] = c.Config.Circadian
+	}
+	if c.Config.Schedules != (SchedulesConfig{}) {
+		configMap["schedules"] = c.Config.Schedules
+	}
+	if !isDefaultDesiredState(c.Config.DesiredState) {
+		configMap["desired_state"] = c.Config.DesiredState
+	}
+	if !isDefaultWebcam(c.Config.Webcam) {
+		configMap["webcam"] = c.Config.Webcam
+	}
+	if len(c.Config.Hooks) > 0 {
+		configMap["hooks"] = c.Config.Hooks
+	}
+	if c.Config.Simulation != (SimulationConfig{}) {
+		configMap["simulation"] = c.Config.Simulation
+	}
+	if c.Config.Debug != (DebugConfig{}) {
+		configMap["debug"] = c.Config.Debug
+	}
+	if c.Config.Client.CurrentContext != "" || len(c.Config.Client.Contexts) > 0 {
+		configMap["client"] = c.Config.Client
+	}
 	if len(configMap) > 0 {
 		settings["config"] = configMap
 	}
@@ -280,45 +974,8 @@ func (c *Config) Save() error {
 		return errors.New("no config file path set for saving")
 	}
 
-	// Create the directory if it doesn't exist
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return fmt.Errorf("error creating config directory %s: %w", configDir, err)
-	}
-
-	// Atomic write: write to temp file, fsync, rename over target
-	tmpPath := configPath + ".tmp"
-	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("error creating temp config file: %w", err)
-	}
-
-	if _, err := f.Write(data); err != nil {
-		_ = f.Close()
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("error writing temp config file: %w", err)
-	}
-
-	if err := f.Sync(); err != nil {
-		_ = f.Close()
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("error syncing temp config file: %w", err)
-	}
-
-	if err := f.Close(); err != nil {
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("error closing temp config file: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, configPath); err != nil {
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("error replacing config file: %w", err)
-	}
-
-	// Best-effort directory sync for rename durability
-	if dir, err := os.Open(configDir); err == nil {
-		_ = dir.Sync()
-		_ = dir.Close()
+	if err := writeFileAtomic(configPath, data); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
 	}
 
 	logger.Debug("Configuration saved successfully", "path", configPath)
@@ -326,15 +983,42 @@ func (c *Config) Save() error {
 }
 
 func isDefaultServer(s ServerConfig) bool {
-	return s.UnixSocket == GetRuntimeSocketPath()
+	return s.UnixSocket == GetRuntimeSocketPath() && s.StateFile == "" &&
+		(s.MaxRequestSize == 0 || s.MaxRequestSize == DefaultSocketMaxRequestSize) &&
+		(s.IdleTimeout == 0 || s.IdleTimeout == int(DefaultSocketIdleTimeout.Seconds())) &&
+		(s.RequestTimeout == 0 || s.RequestTimeout == int(DefaultSocketRequestTimeout.Seconds()))
 }
 
 func isDefaultDiscovery(d DiscoveryConfig) bool {
-	return d.Interval == 30 && d.CleanupInterval == 60 && d.CleanupTimeout == 180
+	return d.Interval == 30 && d.CleanupInterval == 60 && d.CleanupTimeout == 180 &&
+		d.OfflineRetention == int(DefaultOfflineRetention.Seconds()) && len(d.Interfaces) == 0 && len(d.Backends) == 0 &&
+		(d.IdleInterval == 0 || d.IdleInterval == int(DefaultDiscoveryIdleInterval.Seconds())) &&
+		(d.IdleAfter == 0 || d.IdleAfter == int(DefaultIdleAfter.Seconds())) &&
+		len(d.Scan.Subnets) == 0 && d.Scan.TimeoutMS == 0 && d.Scan.Concurrency == 0 &&
+		len(d.Validation.ProductPrefixes) == 0 && len(d.Validation.ProductPatterns) == 0 && !d.Validation.AcceptAnyLight &&
+		!d.RestoreState
 }
 
 func isDefaultLogging(l LoggingConfig) bool {
-	return l.Level == LogLevelInfo && l.Format == LogFormatText && len(l.Filters) == 0
+	return l.Level == LogLevelInfo && l.Format == LogFormatText && len(l.Filters) == 0 &&
+		(l.BufferSize == 0 || l.BufferSize == DefaultLogBufferSize) && !l.DeviceRequests
+}
+
+func isDefaultCircadian(c CircadianConfig) bool {
+	d := DefaultCircadian()
+	return !c.Enabled && !c.HasLocation() && c.DayTemperature == d.DayTemperature && c.NightTemperature == d.NightTemperature &&
+		c.DayBrightness == 0 && c.NightBrightness == 0 && c.Transition == d.Transition && c.Interval == d.Interval &&
+		len(c.Points) == 0 && len(c.Lights) == 0 && len(c.Groups) == 0
+}
+
+func isDefaultDesiredState(d DesiredStateConfig) bool {
+	return !d.Enabled && len(d.Targets) == 0 && (d.Interval == 0 || d.Interval == DefaultDesiredState().Interval)
+}
+
+func isDefaultWebcam(w WebcamConfig) bool {
+	d := DefaultWebcam()
+	return !w.Enabled && len(w.Groups) == 0 && len(w.Devices) == 0 &&
+		(w.PollInterval == 0 || w.PollInterval == d.PollInterval) && w.OffDelay == d.OffDelay
 }
 
 // Viper returns the underlying viper instance for config file watching.
@@ -402,6 +1086,24 @@ func (c *Config) DeleteAPIKey(keyString string) bool {
 	return len(c.State.APIKeys) < originalLen
 }
 
+// ReplaceAPIKey adds newKey in place of any API keys with the same name, and
+// returns the keys it replaced.
+func (c *Config) ReplaceAPIKey(newKey APIKey) []APIKey {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	var replaced []APIKey
+	kept := []APIKey{}
+	for _, k := range c.State.APIKeys {
+		if k.Name == newKey.Name {
+			replaced = append(replaced, k)
+		} else {
+			kept = append(kept, k)
+		}
+	}
+	c.State.APIKeys = append(kept, newKey)
+	return replaced
+}
+
 // FindAPIKey retrieves an API key by its key string.
 // NOTE: Returns a pointer to the internal slice element; do not store or modify outside config methods.
 func (c *Config) FindAPIKey(keyString string) (*APIKey, bool) {
@@ -434,6 +1136,161 @@ func (c *Config) UpdateAPIKeyLastUsed(keyString string, lastUsedTime time.Time)
 	return nil
 }
 
+// GetLightNames returns a copy of the light display name overrides.
+func (c *Config) GetLightNames() map[string]string {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	return maps.Clone(c.State.LightNames)
+}
+
+// SetLightName sets the display name override for a light. An empty name removes it.
+func (c *Config) SetLightName(id, name string) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	if name == "" {
+		delete(c.State.LightNames, id)
+		return
+	}
+	if c.State.LightNames == nil {
+		c.State.LightNames = make(map[string]string)
+	}
+	c.State.LightNames[id] = name
+}
+
+// GetLightTags returns a copy of the light tags, keyed by light ID.
+func (c *Config) GetLightTags() map[string]map[string]string {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	tags := make(map[string]map[string]string, len(c.State.LightTags))
+	for id, t := range c.State.LightTags {
+		tags[id] = maps.Clone(t)
+	}
+	return tags
+}
+
+// SetLightTags replaces the tags of a light. Empty tags remove them.
+func (c *Config) SetLightTags(id string, tags map[string]string) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	if len(tags) == 0 {
+		delete(c.State.LightTags, id)
+		return
+	}
+	if c.State.LightTags == nil {
+		c.State.LightTags = make(map[string]map[string]string)
+	}
+	c.State.LightTags[id] = maps.Clone(tags)
+}
+
+// GetLightStates returns a copy of the saved light states.
+func (c *Config) GetLightStates() map[string]SavedLightState {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	return maps.Clone(c.State.LightStates)
+}
+
+// SetLightStates replaces the saved light states.
+func (c *Config) SetLightStates(states map[string]SavedLightState) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	c.State.LightStates = maps.Clone(states)
+}
+
+// GetAdoptedDevices returns a copy of the IDs of adopted devices.
+func (c *Config) GetAdoptedDevices() []string {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	return slices.Clone(c.State.AdoptedDevices)
+}
+
+// SetAdoptedDevices replaces the IDs of adopted devices.
+func (c *Config) SetAdoptedDevices(ids []string) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	c.State.AdoptedDevices = slices.Clone(ids)
+}
+
+// GetLightStats returns a copy of the saved light usage statistics.
+func (c *Config) GetLightStats() map[string]LightStats {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	return maps.Clone(c.State.LightStats)
+}
+
+// SetLightStats replaces the saved light usage statistics.
+func (c *Config) SetLightStats(stats map[string]LightStats) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	c.State.LightStats = maps.Clone(stats)
+}
+
+// GetCircadianEnabled returns circadian mode as last toggled at runtime, or nil
+// if it hasn't been, in which case the circadian.enabled setting applies.
+func (c *Config) GetCircadianEnabled() *bool {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	if c.State.CircadianEnabled == nil {
+		return nil
+	}
+	enabled := *c.State.CircadianEnabled
+	return &enabled
+}
+
+// SetCircadianEnabled records circadian mode as toggled at runtime; nil clears
+// the override. Call Save to persist it.
+func (c *Config) SetCircadianEnabled(enabled *bool) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	if enabled == nil {
+		c.State.CircadianEnabled = nil
+		return
+	}
+	v := *enabled
+	c.State.CircadianEnabled = &v
+}
+
+// GetDesiredStateEnabled returns desired-state mode as last toggled at runtime,
+// or nil if it hasn't been, in which case the desired_state.enabled setting
+// applies.
+func (c *Config) GetDesiredStateEnabled() *bool {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	if c.State.DesiredStateEnabled == nil {
+		return nil
+	}
+	enabled := *c.State.DesiredStateEnabled
+	return &enabled
+}
+
+// SetDesiredStateEnabled records desired-state mode as toggled at runtime; nil
+// clears the override. Call Save to persist it.
+func (c *Config) SetDesiredStateEnabled(enabled *bool) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	if enabled == nil {
+		c.State.DesiredStateEnabled = nil
+		return
+	}
+	v := *enabled
+	c.State.DesiredStateEnabled = &v
+}
+
+// GetDesiredStateTargets returns a copy of the desired-state targets enabled or
+// disabled at runtime, keyed by DesiredState.Key.
+func (c *Config) GetDesiredStateTargets() map[string]bool {
+	c.saveMutex.RLock()
+	defer c.saveMutex.RUnlock()
+	return maps.Clone(c.State.DesiredStateTargets)
+}
+
+// SetDesiredStateTargets replaces the desired-state targets enabled or disabled
+// at runtime. Call Save to persist them.
+func (c *Config) SetDesiredStateTargets(targets map[string]bool) {
+	c.saveMutex.Lock()
+	defer c.saveMutex.Unlock()
+	c.State.DesiredStateTargets = maps.Clone(targets)
+}
+
 // SetAPIKeyDisabledStatus updates the disabled status of an API key.
 func (c *Config) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (*APIKey, error) {
 	c.saveMutex.Lock()
@@ -54,6 +54,56 @@ func TestAPIKeyDisabledPersistence(t *testing.T) {
 	assert.True(t, reloadedKey.IsDisabled(), "expected API key to remain disabled after reload")
 }
 
+func TestLightNamesPersistence(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	cfg, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+
+	cfg.SetLightName("Elgato Key Light ABC1", "Desk Left")
+	cfg.SetLightName("strip", "Shelf")
+	cfg.SetLightName("strip", "")
+	require.NoError(t, cfg.Save())
+
+	cfgReloaded, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Elgato Key Light ABC1": "Desk Left"}, cfgReloaded.GetLightNames())
+}
+
+func TestLightTagsPersistence(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	cfg, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+
+	cfg.SetLightTags("light-1", map[string]string{"side": "left", "location": "desk"})
+	cfg.SetLightTags("light-2", map[string]string{"side": "right"})
+	cfg.SetLightTags("light-2", nil)
+	require.NoError(t, cfg.Save())
+
+	cfgReloaded, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"light-1": {"side": "left", "location": "desk"}}, cfgReloaded.GetLightTags())
+}
+
+func TestAdoptedDevicesPersistence(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	cfg, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.GetAdoptedDevices())
+
+	cfg.SetAdoptedDevices([]string{"Elgato Key Light Neo 1A2B"})
+	require.NoError(t, cfg.Save())
+
+	cfgReloaded, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Elgato Key Light Neo 1A2B"}, cfgReloaded.GetAdoptedDevices())
+}
+
 func TestSaveAndLoadConfig_WithTimeFields(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "test.yaml")
@@ -97,3 +147,129 @@ func TestLoadConfig_InvalidFile(t *testing.T) {
 	_, err := Load("bad.yaml", configPath)
 	assert.Error(t, err)
 }
+
+func TestLoadConfig_StaticLights(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "lights.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`config:
+  lights:
+    static:
+      - name: Desk
+        ip: 192.168.10.20
+      - id: strip
+        ip: 192.168.10.21
+        port: 8080
+        driver: wled
+`), 0600))
+
+	cfg, err := Load("lights.yaml", configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Config.Lights.Static, 2)
+	assert.Equal(t, StaticLight{Name: "Desk", IP: "192.168.10.20"}, cfg.Config.Lights.Static[0])
+	assert.Equal(t, StaticLight{ID: "strip", IP: "192.168.10.21", Port: 8080, Driver: "wled"}, cfg.Config.Lights.Static[1])
+
+	// Static lights survive a save/load round trip
+	require.NoError(t, cfg.Save())
+	reloaded, err := Load("lights.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Config.Lights.Static, reloaded.Config.Lights.Static)
+}
+
+func TestLoadConfig_DiscoveryInterfaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "ifaces.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`config:
+  discovery:
+    interfaces: [eth0, wlan0]
+    backends: [mdns, ssdp, scan]
+    scan:
+      subnets: [192.168.1.0/24]
+      timeout_ms: 250
+`), 0600))
+
+	cfg, err := Load("ifaces.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"eth0", "wlan0"}, cfg.Config.Discovery.Interfaces)
+	assert.Equal(t, []string{"mdns", "ssdp", "scan"}, cfg.Config.Discovery.Backends)
+	assert.Equal(t, ScanConfig{Subnets: []string{"192.168.1.0/24"}, TimeoutMS: 250}, cfg.Config.Discovery.Scan)
+
+	require.NoError(t, cfg.Save())
+	reloaded, err := Load("ifaces.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"eth0", "wlan0"}, reloaded.Config.Discovery.Interfaces)
+	assert.Equal(t, []string{"mdns", "ssdp", "scan"}, reloaded.Config.Discovery.Backends)
+	assert.Equal(t, cfg.Config.Discovery.Scan, reloaded.Config.Discovery.Scan)
+}
+
+func TestLoadConfig_Hooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "hooks.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`config:
+  hooks:
+    - name: on-air
+      events: [light.state_changed]
+      groups: [Office]
+      on: true
+      command: notify-send "Lights on"
+      debounce_ms: 500
+`), 0600))
+
+	cfg, err := Load("hooks.yaml", configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Config.Hooks, 1)
+	hook := cfg.Config.Hooks[0]
+	assert.Equal(t, "on-air", hook.Name)
+	assert.Equal(t, []string{"light.state_changed"}, hook.Events)
+	assert.Equal(t, []string{"Office"}, hook.Groups)
+	require.NotNil(t, hook.On)
+	assert.True(t, *hook.On)
+	assert.Equal(t, `notify-send "Lights on"`, hook.Command)
+	assert.Equal(t, 500, hook.DebounceMS)
+
+	require.NoError(t, cfg.Save())
+	reloaded, err := Load("hooks.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Config.Hooks, reloaded.Config.Hooks)
+}
+
+func TestLoadConfig_Retry(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "retry.yaml")
+
+	cfg, err := Load("retry.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRetry(), cfg.Config.Lights.Retry)
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`config:
+  lights:
+    retry:
+      attempts: 1
+      breaker_threshold: 0
+`), 0600))
+	cfg, err = Load("retry.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cfg.Config.Lights.Retry.Attempts)
+	assert.Equal(t, 0, cfg.Config.Lights.Retry.BreakerThreshold)
+	assert.Equal(t, DefaultRetry().TimeoutMS, cfg.Config.Lights.Retry.TimeoutMS)
+
+	require.NoError(t, cfg.Save())
+	reloaded, err := Load("retry.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Config.Lights.Retry, reloaded.Config.Lights.Retry)
+}
+
+func TestLoadConfig_Debounce(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "debounce.yaml")
+
+	cfg, err := Load("debounce.yaml", configPath)
+	require.NoError(t, err)
+	assert.Equal(t, int(DefaultDebounceWindow.Milliseconds()), cfg.Config.Lights.DebounceMS)
+
+	// Zero turns coalescing off and must survive a save
+	cfg.Config.Lights.DebounceMS = 0
+	require.NoError(t, cfg.Save())
+	reloaded, err := Load("debounce.yaml", configPath)
+	require.NoError(t, err)
+	assert.Zero(t, reloaded.Config.Lights.DebounceMS)
+}
@@ -13,6 +13,12 @@ const (
 	// ClientConfigFilename is the base filename for client config
 	ClientConfigFilename = "keylightctl.yaml"
 
+	// StateFilename is the base filename for the daemon's state file within XDG_STATE_HOME
+	StateFilename = "state.yaml"
+
+	// CrashDirName is the name of the crash report directory within the state directory
+	CrashDirName = "crashes"
+
 	// SocketFilename is the base filename for the Unix socket
 	SocketFilename = "keylightd.sock"
 
@@ -24,6 +30,17 @@ const (
 
 	// DefaultAPIListenAddress is the default HTTP API listen address
 	DefaultAPIListenAddress = ":9123"
+
+	// UnixListenerScheme prefixes API listen addresses that are Unix sockets
+	UnixListenerScheme = "unix://"
+)
+
+// API listener authentication modes
+const (
+	// ListenerAuthAPIKey requires an API key or client certificate (default)
+	ListenerAuthAPIKey = "api_key"
+	// ListenerAuthNone serves every request without authentication
+	ListenerAuthNone = "none"
 )
 
 // Default timeouts and intervals
@@ -37,8 +54,151 @@ const (
 	// DefaultStateTimeout is the default timeout for considering a light stale
 	DefaultStateTimeout = 180 * time.Second
 
+	// DefaultDiscoveryIdleInterval is the default interval for discovery while
+	// no clients are active. It stays below DefaultStateTimeout so idle lights
+	// aren't marked offline.
+	DefaultDiscoveryIdleInterval = 120 * time.Second
+
+	// DefaultIdleAfter is how long after the last request clients count as idle
+	DefaultIdleAfter = 5 * time.Minute
+
+	// DefaultOfflineRetention is how long an offline light is kept before it is forgotten
+	DefaultOfflineRetention = 7 * 24 * time.Hour
+
+	// DefaultStateCacheTTL is how long a light's state read from the device is reused
+	DefaultStateCacheTTL = 2 * time.Second
+
+	// DefaultDebounceWindow is the shortest time between brightness or temperature updates sent to a light
+	DefaultDebounceWindow = 100 * time.Millisecond
+
+	// DefaultRefreshWorkers is the number of lights refreshed at once
+	DefaultRefreshWorkers = 8
+
+	// DefaultStatsSaveInterval is how often light usage statistics are saved
+	DefaultStatsSaveInterval = 5 * time.Minute
+
 	// MinDiscoveryInterval is the minimum allowed discovery interval
 	MinDiscoveryInterval = 5 * time.Second
+
+	// MinPollInterval is the minimum allowed interval for polling light state
+	MinPollInterval = 2 * time.Second
+
+	// DefaultScanTimeout is the default time each address is given to answer during a subnet scan
+	DefaultScanTimeout = 500 * time.Millisecond
+
+	// DefaultScanConcurrency is the default number of addresses probed at once during a subnet scan
+	DefaultScanConcurrency = 32
+
+	// DefaultSimulationLights is the default number of virtual lights created in simulation mode
+	DefaultSimulationLights = 3
+)
+
+// Unix socket limits
+const (
+	// DefaultSocketMaxRequestSize is the default largest socket request line accepted, in bytes
+	DefaultSocketMaxRequestSize = 1 << 20
+
+	// DefaultSocketIdleTimeout is the default time a socket connection may take to send its next request
+	DefaultSocketIdleTimeout = 5 * time.Minute
+
+	// DefaultSocketRequestTimeout is the default time a single socket request may take to process
+	DefaultSocketRequestTimeout = 30 * time.Second
+
+	// DefaultAPIRequestTimeout is the default time a single HTTP API request or WebSocket command may take to process
+	DefaultAPIRequestTimeout = 10 * time.Second
+)
+
+// Device request defaults
+const (
+	// DefaultDeviceTimeout is the default time a single request to a light may take
+	DefaultDeviceTimeout = 5 * time.Second
+
+	// DefaultRetryAttempts is the default number of tries per request to a light
+	DefaultRetryAttempts = 3
+
+	// DefaultRetryBackoff is the default wait before the first retry
+	DefaultRetryBackoff = 200 * time.Millisecond
+
+	// DefaultRetryMaxBackoff is the default longest wait between retries
+	DefaultRetryMaxBackoff = 2 * time.Second
+
+	// DefaultBreakerThreshold is the default number of failed requests in a row before requests to a light are paused
+	DefaultBreakerThreshold = 5
+
+	// DefaultBreakerCooldown is the default time requests to a failing light are paused
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// MQTT bridge defaults
+const (
+	// DefaultMQTTTopicPrefix is the default prefix for MQTT state and command topics
+	DefaultMQTTTopicPrefix = "keylightd"
+
+	// DefaultMQTTDiscoveryPrefix is the default Home Assistant MQTT discovery prefix
+	DefaultMQTTDiscoveryPrefix = "homeassistant"
+)
+
+// Circadian mode defaults
+const (
+	// DefaultCircadianDayTemperature is the default color temperature while the sun is up (Kelvin)
+	DefaultCircadianDayTemperature = 6500
+
+	// DefaultCircadianNightTemperature is the default color temperature while the sun is down (Kelvin)
+	DefaultCircadianNightTemperature = 2900
+
+	// DefaultCircadianTransition is the default time taken to shift between day and night
+	DefaultCircadianTransition = time.Hour
+
+	// DefaultCircadianInterval is the default time between circadian updates
+	DefaultCircadianInterval = time.Minute
+
+	// MinCircadianInterval is the shortest allowed time between circadian updates
+	MinCircadianInterval = 10 * time.Second
+)
+
+// Desired-state mode defaults
+const (
+	// DefaultDesiredStateInterval is the default time between desired-state checks
+	DefaultDesiredStateInterval = 30 * time.Second
+
+	// MinDesiredStateInterval is the shortest allowed time between desired-state checks
+	MinDesiredStateInterval = 5 * time.Second
+)
+
+// Webcam watcher defaults
+const (
+	// DefaultWebcamPollInterval is the default time between checks for a webcam in use
+	DefaultWebcamPollInterval = 2 * time.Second
+
+	// DefaultWebcamOffDelay is the default time a webcam must stay released before its groups are turned off
+	DefaultWebcamOffDelay = 5 * time.Second
+)
+
+// Event hook defaults
+const (
+	// DefaultHookTimeout is the default time a hook command may run before it is killed
+	DefaultHookTimeout = 10 * time.Second
+)
+
+// HTTP API rate limiting defaults
+const (
+	// DefaultRateLimitRequestsPerMinute is the default sustained request rate per client IP
+	DefaultRateLimitRequestsPerMinute = 120
+
+	// DefaultRateLimitKeyRequestsPerMinute is the default sustained request rate per API key
+	DefaultRateLimitKeyRequestsPerMinute = 300
+
+	// DefaultRateLimitBurst is the default number of requests allowed back to back
+	DefaultRateLimitBurst = 30
+
+	// DefaultMaxFailedAuth is the default number of invalid API keys from one IP before lockout
+	DefaultMaxFailedAuth = 10
+
+	// DefaultFailedAuthWindow is the default period over which invalid API keys are counted
+	DefaultFailedAuthWindow = 5 * time.Minute
+
+	// DefaultLockoutDuration is the default time a locked out IP must wait
+	DefaultLockoutDuration = 15 * time.Minute
 )
 
 // Light constraints
@@ -54,6 +214,18 @@ const (
 
 	// MaxTemperature is the maximum allowed temperature value (in Kelvin)
 	MaxTemperature = 7000
+
+	// MaxHue is the maximum allowed hue value (in degrees)
+	MaxHue = 360
+
+	// MaxSaturation is the maximum allowed saturation value (in percent)
+	MaxSaturation = 100
+
+	// MaxTransitionDuration is the longest allowed brightness/temperature transition
+	MaxTransitionDuration = 10 * time.Minute
+
+	// MaxRetainedJobs is how many finished jobs are kept for clients to look up
+	MaxRetainedJobs = 100
 )
 
 // Logging constants
@@ -75,4 +247,39 @@ const (
 
 	// LogFormatJSON represents JSON log format
 	LogFormatJSON = "json"
+
+	// DefaultLogBufferSize is the default number of recent log lines the daemon keeps in memory
+	DefaultLogBufferSize = 1000
+
+	// FailurePolicyBestEffort changes every light in a group, whether or not others fail
+	FailurePolicyBestEffort = "best-effort"
+
+	// FailurePolicyFailFast stops starting changes to a group's lights once one fails
+	FailurePolicyFailFast = "fail-fast"
+
+	// ShutdownLeave leaves lights as they are when the daemon stops
+	ShutdownLeave = "leave"
+
+	// ShutdownOff turns lights off when the daemon stops
+	ShutdownOff = "off"
+
+	// ShutdownPark sets lights to the configured parked state when the daemon stops
+	ShutdownPark = "park"
+
+	// AccessLogCommon is the Common Log Format, with the API key name as the user
+	AccessLogCommon = "common"
+
+	// AccessLogCombined is the Combined Log Format: common plus referer and user agent
+	AccessLogCombined = "combined"
+
+	// AccessLogJSON writes each request as a JSON object
+	AccessLogJSON = "json"
+
+	// AccessLogStdout and AccessLogStderr send the access log to the daemon's
+	// standard output or error instead of a file
+	AccessLogStdout = "stdout"
+	AccessLogStderr = "stderr"
+
+	// DefaultAccessLogMaxBackups is the default number of rotated access log files kept
+	DefaultAccessLogMaxBackups = 5
 )
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package config
+
+import "os"
+
+// lockFile is a no-op where advisory file locks aren't available.
+func lockFile(_ *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op where advisory file locks aren't available.
+func unlockFile(_ *os.File) error {
+	return nil
+}
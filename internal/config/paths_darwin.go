@@ -0,0 +1,39 @@
+package config
+
+import "os"
+
+// systemRuntimeDir is empty as keylightd runs as a per-user launchd agent on
+// macOS, without a system-wide socket.
+const systemRuntimeDir = ""
+
+// appSupportDir returns ~/Library/Application Support/keylightd, which holds
+// the config, state and socket on macOS. macOS has no XDG runtime directory,
+// so the socket lives with the config.
+func appSupportDir() string {
+	return homeDir("Library", "Application Support", ConfigDirName)
+}
+
+// defaultRuntimeDir returns the Application Support directory.
+func defaultRuntimeDir() string {
+	return appSupportDir()
+}
+
+// defaultConfigDir returns the Application Support directory, or
+// ~/.config/keylightd where an earlier version created it.
+func defaultConfigDir() string {
+	return existingOr(homeDir(".config", ConfigDirName), appSupportDir())
+}
+
+// defaultStateDir returns the Application Support directory, or
+// ~/.local/state/keylightd where an earlier version created it.
+func defaultStateDir() string {
+	return existingOr(homeDir(".local", "state", ConfigDirName), appSupportDir())
+}
+
+// existingOr returns dir if it exists, and fallback otherwise.
+func existingOr(dir, fallback string) string {
+	if _, err := os.Stat(dir); err == nil {
+		return dir
+	}
+	return fallback
+}
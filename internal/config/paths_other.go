@@ -0,0 +1,28 @@
+//go:build !darwin && !windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// systemRuntimeDir holds the socket of the system-wide systemd service.
+const systemRuntimeDir = "/run/keylightd"
+
+// defaultRuntimeDir returns the user's runtime directory as systemd-logind
+// creates it.
+func defaultRuntimeDir() string {
+	return filepath.Join("/run/user", strconv.Itoa(os.Getuid()))
+}
+
+// defaultConfigDir returns the XDG default, ~/.config/keylightd.
+func defaultConfigDir() string {
+	return homeDir(".config", ConfigDirName)
+}
+
+// defaultStateDir returns the XDG default, ~/.local/state/keylightd.
+func defaultStateDir() string {
+	return homeDir(".local", "state", ConfigDirName)
+}
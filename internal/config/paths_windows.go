@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// systemRuntimeDir is empty as there is no system-wide service on Windows.
+const systemRuntimeDir = ""
+
+// defaultRuntimeDir returns %LOCALAPPDATA%\keylightd. Windows 10 1803 and
+// later support Unix sockets, so the daemon and its clients use one there
+// as on other platforms.
+func defaultRuntimeDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, ConfigDirName)
+}
+
+// defaultConfigDir returns the XDG default, ~/.config/keylightd.
+func defaultConfigDir() string {
+	return homeDir(".config", ConfigDirName)
+}
+
+// defaultStateDir returns the XDG default, ~/.local/state/keylightd.
+func defaultStateDir() string {
+	return homeDir(".local", "state", ConfigDirName)
+}
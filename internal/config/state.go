@@ -0,0 +1,330 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmylchreest/keylightd/internal/ids"
+)
+
+// StateVersion is the schema version of the state file written by this build.
+const StateVersion = 2
+
+// ErrStateLocked is returned when another process holds the state file lock.
+var ErrStateLocked = errors.New("state file is locked by another process")
+
+// stateMigrations upgrade a raw state document one version at a time: the
+// migration at index i upgrades a version i document to version i+1.
+var stateMigrations = []func(doc map[string]any) error{
+	// 0 -> 1: unversioned state, as kept in the config file's state block.
+	// The sections are unchanged, so only the version is added.
+	func(map[string]any) error { return nil },
+	// 1 -> 2: groups are given group-<UUIDv7> IDs, see migrateGroupIDs.
+	migrateGroupIDs,
+}
+
+// migrateGroupIDs gives every group whose ID isn't group-<UUID>, such as the
+// group-<unixnano> IDs of early versions, a group-<UUIDv7> ID. The old ID is
+// kept as the group's legacy_id, which is still accepted in place of its ID,
+// and schedules and scenes that target it are changed to the new one. Groups
+// with a unixnano ID also get their creation time back as created_at.
+func migrateGroupIDs(doc map[string]any) error {
+	groups, _ := doc["groups"].(map[string]any)
+	renamed := make(map[string]string)
+	for id, raw := range groups {
+		if ids.Valid("group", id) {
+			continue
+		}
+		entry, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid group data for %s", id)
+		}
+		newID := ids.New("group")
+		if nanos, err := strconv.ParseInt(strings.TrimPrefix(id, "group-"), 10, 64); err == nil && nanos > 0 {
+			created := time.Unix(0, nanos).UTC()
+			newID = ids.NewAt("group", created)
+			entry["created_at"] = created
+		}
+		entry["legacy_id"] = id
+		delete(groups, id)
+		groups[newID] = entry
+		renamed[id] = newID
+		slog.Info("Migrated group to a new ID; the old one is still accepted", "old", id, "new", newID)
+	}
+	if len(renamed) == 0 {
+		return nil
+	}
+
+	// retarget changes a group target's IDs, a comma-separated list of group
+	// IDs or names, to the new IDs.
+	retarget := func(raw any) {
+		target, ok := raw.(map[string]any)
+		if !ok || target["type"] != "group" {
+			return
+		}
+		keys, ok := target["id"].(string)
+		if !ok {
+			return
+		}
+		parts := strings.Split(keys, ",")
+		for i, key := range parts {
+			if newID, ok := renamed[strings.TrimSpace(key)]; ok {
+				parts[i] = newID
+			}
+		}
+		target["id"] = strings.Join(parts, ",")
+	}
+	schedules, _ := doc["schedules"].(map[string]any)
+	for _, raw := range schedules {
+		if s, ok := raw.(map[string]any); ok {
+			retarget(s["target"])
+		}
+	}
+	scenes, _ := doc["scenes"].(map[string]any)
+	for _, raw := range scenes {
+		s, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		entries, _ := s["entries"].([]any)
+		for _, e := range entries {
+			if e, ok := e.(map[string]any); ok {
+				retarget(e["target"])
+			}
+		}
+	}
+	return nil
+}
+
+// StateStore persists State in a file of its own, separate from the config
+// file, so that a hand-managed config file is never rewritten by the daemon.
+//
+// The store holds an exclusive lock on <path>.lock while open, so two daemons
+// can't share (and overwrite) the same state. Writes go to a temporary file
+// that is renamed over the state file, so a crash never leaves it truncated.
+type StateStore struct {
+	path string
+	lock *os.File
+}
+
+// OpenStateStore opens the state file at path, creating its directory if
+// needed, and locks it. It returns ErrStateLocked if another process has it
+// open.
+func OpenStateStore(path string) (*StateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("error creating state directory: %w", err)
+	}
+	lock, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening state lock file: %w", err)
+	}
+	if err := lockFile(lock); err != nil {
+		_ = lock.Close()
+		if errors.Is(err, ErrStateLocked) {
+			return nil, fmt.Errorf("%w: %s", ErrStateLocked, path)
+		}
+		return nil, fmt.Errorf("error locking state file: %w", err)
+	}
+	return &StateStore{path: path, lock: lock}, nil
+}
+
+// Path returns the path of the state file.
+func (s *StateStore) Path() string {
+	return s.path
+}
+
+// Load reads the state file, migrating it to the current schema version. It
+// reports false if the file doesn't exist yet.
+func (s *StateStore) Load() (State, bool, error) {
+	var state State
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, fmt.Errorf("error reading state file: %w", err)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return state, false, fmt.Errorf("error parsing state file: %w", err)
+	}
+	if doc == nil {
+		return state, true, nil
+	}
+	if err := migrateState(doc); err != nil {
+		return state, false, fmt.Errorf("error migrating state file: %w", err)
+	}
+	if state, err = stateFromDoc(doc); err != nil {
+		return state, false, fmt.Errorf("error parsing state file: %w", err)
+	}
+	return state, true, nil
+}
+
+// stateFromDoc decodes a raw state document at StateVersion.
+func stateFromDoc(doc map[string]any) (State, error) {
+	var state State
+	delete(doc, "version")
+	stateBytes, err := yaml.Marshal(doc)
+	if err != nil {
+		return state, err
+	}
+	err = yaml.Unmarshal(stateBytes, &state)
+	return state, err
+}
+
+// migrateLegacyState migrates unversioned state, as read from the config
+// file's state block, to StateVersion.
+func migrateLegacyState(state *State) (State, error) {
+	doc := state.sections()
+	if err := migrateState(doc); err != nil {
+		return State{}, err
+	}
+	return stateFromDoc(doc)
+}
+
+// migrateState upgrades a raw state document to StateVersion in place.
+// Documents without a version are treated as version 0.
+func migrateState(doc map[string]any) error {
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		v, ok := raw.(int)
+		if !ok {
+			return fmt.Errorf("invalid state version %v", raw)
+		}
+		version = v
+	}
+	if version < 0 || version > StateVersion {
+		return fmt.Errorf("unsupported state version %d, this build supports up to %d", version, StateVersion)
+	}
+	for ; version < StateVersion; version++ {
+		if err := stateMigrations[version](doc); err != nil {
+			return fmt.Errorf("version %d: %w", version, err)
+		}
+		slog.Debug("Migrated state", "from", version, "to", version+1)
+	}
+	doc["version"] = StateVersion
+	return nil
+}
+
+// Save writes state to the state file.
+func (s *StateStore) Save(state *State) error {
+	doc := state.sections()
+	doc["version"] = StateVersion
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("error marshaling state to YAML: %w", err)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("error saving state: %w", err)
+	}
+	return nil
+}
+
+// Close releases the state file lock.
+func (s *StateStore) Close() error {
+	if err := unlockFile(s.lock); err != nil {
+		_ = s.lock.Close()
+		return fmt.Errorf("error unlocking state file: %w", err)
+	}
+	return s.lock.Close()
+}
+
+// sections returns the non-empty sections of the state, keyed by their YAML names.
+func (s *State) sections() map[string]any {
+	sections := map[string]any{}
+	if len(s.APIKeys) > 0 {
+		sections["api_keys"] = s.APIKeys
+	}
+	if len(s.Groups) > 0 {
+		sections["groups"] = s.Groups
+	}
+	if len(s.Schedules) > 0 {
+		sections["schedules"] = s.Schedules
+	}
+	if len(s.Scenes) > 0 {
+		sections["scenes"] = s.Scenes
+	}
+	if len(s.LightNames) > 0 {
+		sections["light_names"] = s.LightNames
+	}
+	if len(s.LightTags) > 0 {
+		sections["light_tags"] = s.LightTags
+	}
+	if len(s.LightStates) > 0 {
+		sections["light_states"] = s.LightStates
+	}
+	if s.CircadianEnabled != nil {
+		sections["circadian_enabled"] = *s.CircadianEnabled
+	}
+	if len(s.LightStats) > 0 {
+		sections["light_stats"] = s.LightStats
+	}
+	if len(s.AdoptedDevices) > 0 {
+		sections["adopted_devices"] = s.AdoptedDevices
+	}
+	if s.DesiredStateEnabled != nil {
+		sections["desired_state_enabled"] = *s.DesiredStateEnabled
+	}
+	if len(s.DesiredStateTargets) > 0 {
+		sections["desired_state_targets"] = s.DesiredStateTargets
+	}
+	return sections
+}
+
+// isEmpty reports whether the state has nothing worth persisting.
+func (s *State) isEmpty() bool {
+	return len(s.sections()) == 0
+}
+
+// writeFileAtomic writes data to a temporary file next to path, syncs it and
+// renames it over path, so readers see either the old or the new contents.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating directory %s: %w", dir, err)
+	}
+
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error writing temp file: %w", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error syncing temp file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error closing temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error replacing %s: %w", path, err)
+	}
+
+	// Best-effort directory sync for rename durability
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
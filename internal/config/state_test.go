@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "state.yaml")
+	store, err := OpenStateStore(path)
+	require.NoError(t, err)
+
+	_, found, err := store.Load()
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	enabled := true
+	require.NoError(t, store.Save(&State{
+		APIKeys:             []APIKey{{Key: "abc123", Name: "test"}},
+		LightNames:          map[string]string{"light-1": "Desk"},
+		CircadianEnabled:    &enabled,
+		DesiredStateTargets: map[string]bool{"group:office": false},
+	}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "version: 2\n")
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err), "temp file is renamed away")
+
+	state, found, err := store.Load()
+	require.NoError(t, err)
+	assert.True(t, found)
+	require.Len(t, state.APIKeys, 1)
+	assert.Equal(t, "abc123", state.APIKeys[0].Key)
+	assert.Equal(t, map[string]string{"light-1": "Desk"}, state.LightNames)
+	require.NotNil(t, state.CircadianEnabled)
+	assert.True(t, *state.CircadianEnabled)
+	assert.Equal(t, map[string]bool{"group:office": false}, state.DesiredStateTargets)
+	require.NoError(t, store.Close())
+}
+
+func TestStateStore_Lock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("state file locking is not supported on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	store, err := OpenStateStore(path)
+	require.NoError(t, err)
+
+	_, err = OpenStateStore(path)
+	assert.ErrorIs(t, err, ErrStateLocked)
+
+	require.NoError(t, store.Close())
+	store, err = OpenStateStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+}
+
+func TestStateStore_Migrate(t *testing.T) {
+	dir := t.TempDir()
+
+	// Unversioned state, as copied from a config file's state block
+	path := filepath.Join(dir, "old.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("light_names:\n  light-1: Desk\n"), 0600))
+	store, err := OpenStateStore(path)
+	require.NoError(t, err)
+	state, found, err := store.Load()
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]string{"light-1": "Desk"}, state.LightNames)
+	require.NoError(t, store.Close())
+
+	// State written by a newer build is refused rather than overwritten
+	path = filepath.Join(dir, "new.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 99\n"), 0600))
+	store, err = OpenStateStore(path)
+	require.NoError(t, err)
+	_, _, err = store.Load()
+	assert.ErrorContains(t, err, "unsupported state version 99")
+	require.NoError(t, store.Close())
+}
+
+func TestStateStore_MigrateGroupIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`version: 1
+groups:
+  group-1700000000000000000:
+    name: desk
+    lights: [light-1]
+  group-0b3c1a52-7f3e-4d7b-9c55-2f6f1f0f6c1e:
+    name: office
+    lights: [light-2]
+schedules:
+  schedule-1:
+    name: morning
+    target: {type: group, id: "group-1700000000000000000,office"}
+scenes:
+  scene-1:
+    name: stream
+    entries:
+      - target: {type: group, id: group-1700000000000000000}
+      - target: {type: light, id: group-1700000000000000000}
+`), 0600))
+	store, err := OpenStateStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	state, _, err := store.Load()
+	require.NoError(t, err)
+
+	require.Len(t, state.Groups, 2)
+	assert.Contains(t, state.Groups, "group-0b3c1a52-7f3e-4d7b-9c55-2f6f1f0f6c1e", "UUID IDs are kept")
+	var newID string
+	for id := range state.Groups {
+		if id != "group-0b3c1a52-7f3e-4d7b-9c55-2f6f1f0f6c1e" {
+			newID = id
+		}
+	}
+	u, err := uuid.Parse(strings.TrimPrefix(newID, "group-"))
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), u.Version())
+	desk := state.Groups[newID].(map[string]any)
+	assert.Equal(t, "desk", desk["name"])
+	assert.Equal(t, "group-1700000000000000000", desk["legacy_id"])
+	assert.NotNil(t, desk["created_at"])
+
+	schedule := state.Schedules["schedule-1"].(map[string]any)
+	assert.Equal(t, newID+",office", schedule["target"].(map[string]any)["id"])
+	entries := state.Scenes["scene-1"].(map[string]any)["entries"].([]any)
+	assert.Equal(t, newID, entries[0].(map[string]any)["target"].(map[string]any)["id"])
+	assert.Equal(t, "group-1700000000000000000", entries[1].(map[string]any)["target"].(map[string]any)["id"], "light targets are left alone")
+}
+
+func TestOpenState(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	statePath := filepath.Join(dir, "state", "state.yaml")
+	original := []byte(`# Managed by configuration management
+config:
+  discovery:
+    interval: 60
+state:
+  light_names:
+    light-1: Desk
+`)
+	require.NoError(t, os.WriteFile(configPath, original, 0600))
+
+	// State from the config file's legacy state block moves to the state file
+	cfg, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+	require.NoError(t, cfg.OpenState(statePath))
+	assert.Equal(t, map[string]string{"light-1": "Desk"}, cfg.GetLightNames())
+
+	// Saving only writes the state file; the config file is left as it was
+	cfg.SetLightName("light-2", "Shelf")
+	require.NoError(t, cfg.Save())
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, data)
+
+	// A second daemon can't open the same state
+	other, err := Load("config.yaml", configPath)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.ErrorIs(t, other.OpenState(statePath), ErrStateLocked)
+	}
+	require.NoError(t, cfg.CloseState())
+
+	// Once the state file exists, it wins over the legacy block
+	require.NoError(t, other.OpenState(statePath))
+	assert.Equal(t, map[string]string{"light-1": "Desk", "light-2": "Shelf"}, other.GetLightNames())
+	require.NoError(t, other.CloseState())
+}
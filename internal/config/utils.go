@@ -1,38 +1,50 @@
 package config
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
-	"strconv"
+	"time"
 )
 
-// GetRuntimeDir returns the XDG runtime directory
+// GetRuntimeDir returns the XDG runtime directory, or the platform's default
+// when XDG_RUNTIME_DIR is unset
 func GetRuntimeDir() string {
 	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
 		return dir
 	}
-	uid := os.Getuid()
-	return filepath.Join("/run/user", strconv.Itoa(uid))
+	return defaultRuntimeDir()
 }
 
 // GetRuntimeSocketPath returns the full path to the Unix socket
 // It checks the user's runtime directory first, then falls back to system socket
 func GetRuntimeSocketPath() string {
-	userSocket := filepath.Join(GetRuntimeDir(), SocketFilename)
-
-	// If user socket exists, use it
-	if _, err := os.Stat(userSocket); err == nil {
-		return userSocket
+	paths := RuntimeSocketPaths()
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
 	}
 
-	// Fall back to system socket path for systemd service
-	systemSocket := filepath.Join("/run/keylightd", SocketFilename)
-	if _, err := os.Stat(systemSocket); err == nil {
-		return systemSocket
+	// Default to user socket path (original behavior)
+	return paths[0]
+}
+
+// RuntimeSocketPaths returns the places keylightd's socket is created by
+// default, in the order clients look for it: the user's runtime directory,
+// then the system service's.
+func RuntimeSocketPaths() []string {
+	paths := []string{filepath.Join(GetRuntimeDir(), SocketFilename)}
+	if systemRuntimeDir != "" {
+		paths = append(paths, filepath.Join(systemRuntimeDir, SocketFilename))
 	}
+	return paths
+}
 
-	// Default to user socket path (original behavior)
-	return userSocket
+// homeDir joins elem to the user's home directory
+func homeDir(elem ...string) string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(append([]string{home}, elem...)...)
 }
 
 // GetConfigBaseDir returns the base directory for configuration files
@@ -45,8 +57,7 @@ func GetConfigBaseDir() string {
 		}
 		return filepath.Join(dir, ConfigDirName)
 	}
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".config", ConfigDirName)
+	return defaultConfigDir()
 }
 
 // GetConfigPath returns the full path to a configuration file
@@ -64,6 +75,29 @@ func GetClientConfigPath() string {
 	return GetConfigPath(ClientConfigFilename)
 }
 
+// GetStateBaseDir returns the base directory for persistent state
+func GetStateBaseDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		// For system service, XDG_STATE_HOME is set to /var/lib/keylightd
+		// so we return it directly without appending ConfigDirName
+		if dir == "/var/lib/keylightd" {
+			return dir
+		}
+		return filepath.Join(dir, ConfigDirName)
+	}
+	return defaultStateDir()
+}
+
+// GetStatePath returns the full path to the daemon state file
+func GetStatePath() string {
+	return filepath.Join(GetStateBaseDir(), StateFilename)
+}
+
+// GetCrashReportDir returns the directory crash reports are written to
+func GetCrashReportDir() string {
+	return filepath.Join(GetStateBaseDir(), CrashDirName)
+}
+
 // ValidateDiscoveryInterval validates and converts the discovery interval
 // Returns the interval in seconds, clamped to the minimum allowed value
 func ValidateDiscoveryInterval(intervalSeconds int) int {
@@ -73,3 +107,132 @@ func ValidateDiscoveryInterval(intervalSeconds int) int {
 	}
 	return intervalSeconds
 }
+
+// ValidatePollInterval ensures an enabled light state poll interval is not
+// less than MinPollInterval. Zero or less disables polling.
+func ValidatePollInterval(intervalSeconds int) int {
+	if intervalSeconds <= 0 {
+		return 0
+	}
+	return max(intervalSeconds, int(MinPollInterval.Seconds()))
+}
+
+// ValidateLightLimits drops limits that name neither a light nor a group and
+// clamps the rest to the supported ranges, swapping a minimum and maximum given
+// the wrong way round.
+func ValidateLightLimits(limits []LightLimit) []LightLimit {
+	valid := make([]LightLimit, 0, len(limits))
+	for _, l := range limits {
+		if l.Light == "" && l.Group == "" {
+			slog.Warn("Ignoring light limit without a light or group", "limit", l)
+			continue
+		}
+		l.MinBrightness, l.MaxBrightness = clampRange(l.MinBrightness, l.MaxBrightness, MinBrightness, MaxBrightness)
+		l.MinTemperature, l.MaxTemperature = clampRange(l.MinTemperature, l.MaxTemperature, MinTemperature, MaxTemperature)
+		valid = append(valid, l)
+	}
+	return valid
+}
+
+// ValidateGroups resets a negative concurrency limit and an unknown failure
+// policy to their defaults.
+func ValidateGroups(g GroupsConfig) GroupsConfig {
+	if g.MaxConcurrency < 0 {
+		slog.Warn("Ignoring negative group concurrency limit", "max_concurrency", g.MaxConcurrency)
+		g.MaxConcurrency = 0
+	}
+	if g.FailurePolicy != "" && g.FailurePolicy != FailurePolicyBestEffort && g.FailurePolicy != FailurePolicyFailFast {
+		slog.Warn("Unknown group failure policy, using best-effort", "failure_policy", g.FailurePolicy)
+		g.FailurePolicy = ""
+	}
+	return g
+}
+
+// ValidateShutdown resets an unknown shutdown action to leave, and clamps the
+// parked brightness and temperature to the supported range.
+func ValidateShutdown(s ShutdownConfig) ShutdownConfig {
+	if s.Action != "" && s.Action != ShutdownLeave && !s.ChangesLights() {
+		slog.Warn("Unknown shutdown action, leaving lights as they are", "action", s.Action)
+		s.Action = ""
+	}
+	if s.Brightness != 0 {
+		s.Brightness = max(MinBrightness, min(s.Brightness, MaxBrightness))
+	}
+	if s.Temperature != 0 {
+		s.Temperature = max(MinTemperature, min(s.Temperature, MaxTemperature))
+	}
+	return s
+}
+
+// clampRange clamps the set ends of lo-hi into minimum-maximum. Zero ends are
+// left unset.
+func clampRange(lo, hi, minimum, maximum int) (int, int) {
+	if lo != 0 {
+		lo = max(minimum, min(lo, maximum))
+	}
+	if hi != 0 {
+		hi = max(minimum, min(hi, maximum))
+	}
+	if lo != 0 && hi != 0 && lo > hi {
+		lo, hi = hi, lo
+	}
+	return lo, hi
+}
+
+// ValidateCircadian fills in unset circadian settings with their defaults,
+// clamps temperatures and brightness to the supported ranges and drops curve
+// points with an invalid time.
+func ValidateCircadian(c CircadianConfig) CircadianConfig {
+	d := DefaultCircadian()
+	if c.DayTemperature == 0 {
+		c.DayTemperature = d.DayTemperature
+	}
+	if c.NightTemperature == 0 {
+		c.NightTemperature = d.NightTemperature
+	}
+	c.DayTemperature = max(MinTemperature, min(c.DayTemperature, MaxTemperature))
+	c.NightTemperature = max(MinTemperature, min(c.NightTemperature, MaxTemperature))
+	c.DayBrightness, c.NightBrightness = clampBrightness(c.DayBrightness), clampBrightness(c.NightBrightness)
+	if c.Transition < 0 {
+		c.Transition = 0
+	}
+	if c.Interval <= 0 {
+		c.Interval = d.Interval
+	} else if c.Interval < int(MinCircadianInterval.Seconds()) {
+		slog.Warn("Circadian interval too short, using minimum", "interval", c.Interval, "minimum", int(MinCircadianInterval.Seconds()))
+		c.Interval = int(MinCircadianInterval.Seconds())
+	}
+
+	points := make([]CircadianPoint, 0, len(c.Points))
+	for _, p := range c.Points {
+		if _, err := time.Parse("15:04", p.At); err != nil {
+			slog.Warn("Ignoring circadian point with invalid time", "at", p.At)
+			continue
+		}
+		p.Temperature = max(MinTemperature, min(p.Temperature, MaxTemperature))
+		p.Brightness = clampBrightness(p.Brightness)
+		points = append(points, p)
+	}
+	c.Points = points
+	return c
+}
+
+// ValidateDesiredState fills in an unset desired-state interval with its
+// default and raises one that is too short to the minimum.
+func ValidateDesiredState(d DesiredStateConfig) DesiredStateConfig {
+	if d.Interval <= 0 {
+		d.Interval = DefaultDesiredState().Interval
+	} else if minSeconds := int(MinDesiredStateInterval.Seconds()); d.Interval < minSeconds {
+		slog.Warn("Desired-state interval too short, using minimum", "interval", d.Interval, "minimum", minSeconds)
+		d.Interval = minSeconds
+	}
+	return d
+}
+
+// clampBrightness clamps a set brightness to the supported range, leaving 0 unset.
+func clampBrightness(b int) int {
+	if b == 0 {
+		return 0
+	}
+	return max(MinBrightness, min(b, MaxBrightness))
+}
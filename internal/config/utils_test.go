@@ -108,3 +108,105 @@ func TestGetConfigPath(t *testing.T) {
 func endsWithSuffix(path, suffix string) bool {
 	return len(path) >= len(suffix) && path[len(path)-len(suffix):] == suffix
 }
+
+func TestValidateLightLimits(t *testing.T) {
+	limits := ValidateLightLimits([]LightLimit{
+		{MinBrightness: 10},
+		{Light: "light1", MinBrightness: 1, MaxBrightness: 150},
+		{Group: "desk", MinTemperature: 6000, MaxTemperature: 3000},
+	})
+	if len(limits) != 2 {
+		t.Fatalf("ValidateLightLimits() kept %d limits, expected 2", len(limits))
+	}
+	if got := limits[0]; got.MinBrightness != MinBrightness || got.MaxBrightness != MaxBrightness {
+		t.Errorf("brightness range = %d-%d, expected %d-%d", got.MinBrightness, got.MaxBrightness, MinBrightness, MaxBrightness)
+	}
+	if got := limits[1]; got.MinTemperature != 3000 || got.MaxTemperature != 6000 {
+		t.Errorf("temperature range = %d-%d, expected 3000-6000", got.MinTemperature, got.MaxTemperature)
+	}
+}
+
+func TestValidatePollInterval(t *testing.T) {
+	for in, expected := range map[int]int{-5: 0, 0: 0, 1: 2, 2: 2, 10: 10} {
+		if got := ValidatePollInterval(in); got != expected {
+			t.Errorf("ValidatePollInterval(%d) = %d, expected %d", in, got, expected)
+		}
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	g := ValidateGroups(GroupsConfig{MaxConcurrency: -1, FailurePolicy: "sometimes"})
+	if g != (GroupsConfig{}) {
+		t.Errorf("ValidateGroups() = %+v, expected the defaults", g)
+	}
+	g = ValidateGroups(GroupsConfig{MaxConcurrency: 2, FailurePolicy: FailurePolicyFailFast})
+	if g.MaxConcurrency != 2 || !g.FailFast() {
+		t.Errorf("ValidateGroups() = %+v, expected it unchanged", g)
+	}
+}
+
+func TestValidateShutdown(t *testing.T) {
+	s := ValidateShutdown(ShutdownConfig{Action: "explode", Brightness: 150, Temperature: 1000})
+	if s.Action != "" || s.ChangesLights() {
+		t.Errorf("action = %q, expected lights to be left as they are", s.Action)
+	}
+	if s.Brightness != MaxBrightness || s.Temperature != MinTemperature {
+		t.Errorf("parked state = %d/%d, expected %d/%d", s.Brightness, s.Temperature, MaxBrightness, MinTemperature)
+	}
+	s = ValidateShutdown(ShutdownConfig{Action: ShutdownPark, On: true, Brightness: 10})
+	if s != (ShutdownConfig{Action: ShutdownPark, On: true, Brightness: 10}) {
+		t.Errorf("ValidateShutdown() = %+v, expected it unchanged", s)
+	}
+}
+
+func TestValidateCircadian(t *testing.T) {
+	c := ValidateCircadian(CircadianConfig{
+		DayTemperature: 9000,
+		DayBrightness:  1,
+		Interval:       1,
+		Points: []CircadianPoint{
+			{At: "07:00", Temperature: 5000},
+			{At: "25:00", Temperature: 3000},
+		},
+	})
+	if c.DayTemperature != MaxTemperature || c.NightTemperature != DefaultCircadianNightTemperature {
+		t.Errorf("temperatures = %d/%d, expected %d/%d", c.DayTemperature, c.NightTemperature, MaxTemperature, DefaultCircadianNightTemperature)
+	}
+	if c.DayBrightness != MinBrightness || c.NightBrightness != 0 {
+		t.Errorf("brightness = %d/%d, expected %d/0", c.DayBrightness, c.NightBrightness, MinBrightness)
+	}
+	if c.Interval != int(MinCircadianInterval.Seconds()) {
+		t.Errorf("interval = %d, expected %d", c.Interval, int(MinCircadianInterval.Seconds()))
+	}
+	if len(c.Points) != 1 || c.Points[0].At != "07:00" {
+		t.Errorf("points = %+v, expected only the 07:00 point", c.Points)
+	}
+}
+
+func TestValidateDesiredState(t *testing.T) {
+	d := ValidateDesiredState(DesiredStateConfig{})
+	if d.Interval != int(DefaultDesiredStateInterval.Seconds()) {
+		t.Errorf("interval = %d, expected the default %d", d.Interval, int(DefaultDesiredStateInterval.Seconds()))
+	}
+	d = ValidateDesiredState(DesiredStateConfig{Interval: 1})
+	if d.Interval != int(MinDesiredStateInterval.Seconds()) {
+		t.Errorf("interval = %d, expected the minimum %d", d.Interval, int(MinDesiredStateInterval.Seconds()))
+	}
+}
+
+func TestGetStateBaseDir(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/var/lib/keylightd")
+	if got := GetStateBaseDir(); got != "/var/lib/keylightd" {
+		t.Errorf("GetStateBaseDir() = %v, expected /var/lib/keylightd", got)
+	}
+
+	t.Setenv("XDG_STATE_HOME", "/home/user/state")
+	if got := GetStatePath(); got != "/home/user/state/keylightd/state.yaml" {
+		t.Errorf("GetStatePath() = %v, expected /home/user/state/keylightd/state.yaml", got)
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	if got := GetStateBaseDir(); !filepath.IsAbs(got) || !endsWithSuffix(got, "/.local/state/keylightd") {
+		t.Errorf("GetStateBaseDir() = %v, expected to end with /.local/state/keylightd", got)
+	}
+}
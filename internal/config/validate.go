@@ -0,0 +1,479 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmylchreest/keylightd/internal/logging"
+)
+
+// Problem is something wrong with a config or state file, found by Validate.
+type Problem struct {
+	Path    string // Key the problem is at, such as config.discovery.interval; empty for the whole file
+	Line    int    // Line of the key in the file; 0 if it isn't in the file
+	Message string
+}
+
+// String formats the problem as "path (line n): message".
+func (p Problem) String() string {
+	var b strings.Builder
+	b.WriteString(p.Path)
+	if p.Line > 0 {
+		if p.Path != "" {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "(line %d)", p.Line)
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidateFile reads the config or state file at path and checks it with
+// Validate.
+func ValidateFile(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(data), nil
+}
+
+// Validate checks the contents of a config or state file against their
+// schema: it reports keys that aren't part of it, values of the wrong type and
+// settings the daemon would otherwise ignore or replace with a default, such
+// as intervals that are too short or log filters that can't be applied. State
+// files are told apart from config files by their version key.
+func Validate(data []byte) []Problem {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []Problem{{Message: err.Error()}}
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return []Problem{{Line: doc.Line, Message: "expected a mapping of settings"}}
+	}
+
+	v := &validator{root: doc}
+	if findKey(doc, "version") != nil {
+		v.checkState(doc)
+		return v.problems
+	}
+	v.checkNode(doc, reflect.TypeFor[Config](), "")
+	if len(v.problems) > 0 {
+		// The values can't be decoded, so there is nothing more to check
+		return v.problems
+	}
+
+	cfg := viper.New()
+	cfg.SetConfigType("yaml")
+	var c Config
+	if err := cfg.ReadConfig(bytes.NewReader(data)); err != nil {
+		return []Problem{{Message: err.Error()}}
+	}
+	if err := cfg.Unmarshal(&c); err != nil {
+		return []Problem{{Message: err.Error()}}
+	}
+	v.checkConfig(&c.Config)
+	return v.problems
+}
+
+// validator collects the problems found in a document.
+type validator struct {
+	root     *yaml.Node
+	problems []Problem
+}
+
+// add records a problem at the node (or mapping value) found at path.
+func (v *validator) add(path, format string, args ...any) {
+	line := 0
+	if n := lookup(v.root, path); n != nil {
+		line = n.Line
+	}
+	v.problems = append(v.problems, Problem{Path: path, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkState checks a state file, which has a version key besides the
+// sections of State.
+func (v *validator) checkState(doc *yaml.Node) {
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		if key.Value != "version" {
+			continue
+		}
+		var version int
+		if err := value.Decode(&version); err != nil {
+			v.problems = append(v.problems, Problem{Path: "version", Line: key.Line, Message: "expected an integer"})
+		} else if version < 0 || version > StateVersion {
+			v.problems = append(v.problems, Problem{Path: "version", Line: key.Line,
+				Message: fmt.Sprintf("unsupported state version %d, this build supports up to %d", version, StateVersion)})
+		}
+	}
+	v.checkFields(doc, reflect.TypeFor[State](), "", "version")
+}
+
+// checkNode checks that n has the shape of a value of type t.
+func (v *validator) checkNode(n *yaml.Node, t reflect.Type, path string) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Interface:
+		return
+	case t == reflect.TypeFor[time.Time]():
+		v.checkScalar(n, t, path, "a timestamp")
+	case t.Kind() == reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			v.problems = append(v.problems, Problem{Path: path, Line: n.Line, Message: "expected a mapping"})
+			return
+		}
+		v.checkFields(n, t, path)
+	case t.Kind() == reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			v.problems = append(v.problems, Problem{Path: path, Line: n.Line, Message: "expected a mapping"})
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v.checkNode(n.Content[i+1], t.Elem(), joinPath(path, n.Content[i].Value))
+		}
+	case t.Kind() == reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			v.problems = append(v.problems, Problem{Path: path, Line: n.Line, Message: "expected a list"})
+			return
+		}
+		for i, item := range n.Content {
+			v.checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case t.Kind() == reflect.Bool:
+		v.checkScalar(n, t, path, "true or false")
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		v.checkScalar(n, t, path, "an integer")
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		v.checkScalar(n, t, path, "a number")
+	default:
+		v.checkScalar(n, t, path, "a string")
+	}
+}
+
+// checkScalar checks that n is a scalar that decodes as a value of type t.
+func (v *validator) checkScalar(n *yaml.Node, t reflect.Type, path, want string) {
+	if n.Kind != yaml.ScalarNode || n.Decode(reflect.New(t).Interface()) != nil {
+		got := n.Value
+		if n.Kind != yaml.ScalarNode {
+			got = map[yaml.Kind]string{yaml.MappingNode: "a mapping", yaml.SequenceNode: "a list"}[n.Kind]
+		} else {
+			got = strconv.Quote(got)
+		}
+		v.problems = append(v.problems, Problem{Path: path, Line: n.Line, Message: fmt.Sprintf("expected %s, got %s", want, got)})
+	}
+}
+
+// checkFields checks the keys of mapping n against the fields of struct t.
+// Keys are matched as viper matches them, ignoring case.
+func (v *validator) checkFields(n *yaml.Node, t reflect.Type, path string, ignore ...string) {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if name := fieldKey(f); name != "-" {
+			fields[strings.ToLower(name)] = f
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		f, ok := fields[strings.ToLower(key.Value)]
+		if !ok {
+			if !slices.Contains(ignore, key.Value) {
+				v.problems = append(v.problems, Problem{Path: joinPath(path, key.Value), Line: key.Line, Message: "unknown key"})
+			}
+			continue
+		}
+		v.checkNode(value, f.Type, joinPath(path, key.Value))
+	}
+}
+
+// checkConfig reports settings that Load would ignore or replace with a
+// default, and log filters that can't be applied.
+func (v *validator) checkConfig(c *ConfigBlock) {
+	v.checkNotNegative("config.server.max_request_size", c.Server.MaxRequestSize)
+	v.checkNotNegative("config.server.idle_timeout", c.Server.IdleTimeout)
+	v.checkNotNegative("config.server.request_timeout", c.Server.RequestTimeout)
+
+	if minSeconds := int(MinDiscoveryInterval.Seconds()); c.Discovery.Interval != 0 && c.Discovery.Interval < minSeconds {
+		v.add("config.discovery.interval", "must be at least %d seconds", minSeconds)
+	}
+	v.checkNotNegative("config.discovery.cleanup_interval", c.Discovery.CleanupInterval)
+	v.checkNotNegative("config.discovery.cleanup_timeout", c.Discovery.CleanupTimeout)
+	v.checkNotNegative("config.discovery.offline_retention", c.Discovery.OfflineRetention)
+	v.checkNotNegative("config.discovery.idle_interval", c.Discovery.IdleInterval)
+	v.checkNotNegative("config.discovery.idle_after", c.Discovery.IdleAfter)
+	timeout := c.Discovery.CleanupTimeout
+	if timeout == 0 {
+		timeout = int(DefaultStateTimeout.Seconds())
+	}
+	if timeout > 0 && c.Discovery.IdleInterval >= timeout {
+		v.add("config.discovery.idle_interval", "must be less than cleanup_timeout (%d seconds), or lights go offline while clients are idle", timeout)
+	}
+	for i, subnet := range c.Discovery.Scan.Subnets {
+		if _, _, err := net.ParseCIDR(subnet); err != nil {
+			v.add(fmt.Sprintf("config.discovery.scan.subnets[%d]", i), "invalid CIDR range %q", subnet)
+		}
+	}
+	v.checkNotNegative("config.discovery.scan.timeout_ms", c.Discovery.Scan.TimeoutMS)
+	v.checkNotNegative("config.discovery.scan.concurrency", c.Discovery.Scan.Concurrency)
+	for i, pattern := range c.Discovery.Validation.ProductPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.add(fmt.Sprintf("config.discovery.validation.product_patterns[%d]", i), "invalid regular expression %q", pattern)
+		}
+	}
+
+	if c.Logging.Level != "" && c.Logging.Level != LogLevelDebug && c.Logging.Level != LogLevelInfo &&
+		c.Logging.Level != LogLevelWarn && c.Logging.Level != LogLevelError {
+		v.add("config.logging.level", "unknown level %q, expected debug, info, warn or error", c.Logging.Level)
+	}
+	if c.Logging.Format != "" && c.Logging.Format != LogFormatText && c.Logging.Format != LogFormatJSON {
+		v.add("config.logging.format", "unknown format %q, expected text or json", c.Logging.Format)
+	}
+	v.checkNotNegative("config.logging.buffer_size", c.Logging.BufferSize)
+	for _, e := range logging.ValidateFilters(c.Logging.Filters) {
+		v.add(fmt.Sprintf("config.logging.filters[%d].%s", e.Index, e.Field), "%s", e.Message)
+	}
+
+	for i, s := range c.Lights.Static {
+		path := fmt.Sprintf("config.lights.static[%d]", i)
+		if net.ParseIP(s.IP) == nil {
+			v.add(path+".ip", "invalid IP address %q", s.IP)
+		}
+		if s.Port < 0 || s.Port > 65535 {
+			v.add(path+".port", "must be between 0 and 65535")
+		}
+	}
+	r := c.Lights.Retry
+	v.checkNotNegative("config.lights.retry.attempts", r.Attempts)
+	v.checkNotNegative("config.lights.retry.backoff_ms", r.BackoffMS)
+	v.checkNotNegative("config.lights.retry.max_backoff_ms", r.MaxBackoffMS)
+	v.checkNotNegative("config.lights.retry.timeout_ms", r.TimeoutMS)
+	v.checkNotNegative("config.lights.retry.breaker_threshold", r.BreakerThreshold)
+	v.checkNotNegative("config.lights.retry.breaker_cooldown", r.BreakerCooldown)
+	v.checkNotNegative("config.lights.debounce_ms", c.Lights.DebounceMS)
+	v.checkNotNegative("config.lights.timeout_ms", c.Lights.TimeoutMS)
+	v.checkNotNegative("config.lights.poll_interval", c.Lights.PollInterval)
+	if minSeconds := int(MinPollInterval.Seconds()); c.Lights.PollInterval > 0 && c.Lights.PollInterval < minSeconds {
+		v.add("config.lights.poll_interval", "must be at least %d seconds", minSeconds)
+	}
+	seenOverrides := make(map[string]bool, len(c.Lights.Overrides))
+	for i, o := range c.Lights.Overrides {
+		path := fmt.Sprintf("config.lights.overrides[%d]", i)
+		switch {
+		case o.ID == "":
+			v.add(path, "needs a light id")
+		case seenOverrides[o.ID]:
+			v.add(path+".id", "duplicate override for light %q", o.ID)
+		}
+		seenOverrides[o.ID] = true
+		if o.Port < 0 || o.Port > 65535 {
+			v.add(path+".port", "must be between 0 and 65535")
+		}
+		v.checkNotNegative(path+".timeout_ms", o.TimeoutMS)
+	}
+	for i, l := range c.Lights.Limits {
+		path := fmt.Sprintf("config.lights.limits[%d]", i)
+		if l.Light == "" && l.Group == "" {
+			v.add(path, "needs a light or a group")
+		}
+		v.checkRange(path+".min_brightness", l.MinBrightness, MinBrightness, MaxBrightness)
+		v.checkRange(path+".max_brightness", l.MaxBrightness, MinBrightness, MaxBrightness)
+		v.checkRange(path+".min_temperature", l.MinTemperature, MinTemperature, MaxTemperature)
+		v.checkRange(path+".max_temperature", l.MaxTemperature, MinTemperature, MaxTemperature)
+	}
+
+	v.checkNotNegative("config.groups.max_concurrency", c.Groups.MaxConcurrency)
+	if p := c.Groups.FailurePolicy; p != "" && p != FailurePolicyBestEffort && p != FailurePolicyFailFast {
+		v.add("config.groups.failure_policy", "unknown policy %q, expected %s or %s", p, FailurePolicyBestEffort, FailurePolicyFailFast)
+	}
+
+	sd := c.Lights.Shutdown
+	if sd.Action != "" && sd.Action != ShutdownLeave && !sd.ChangesLights() {
+		v.add("config.lights.shutdown.action", "unknown action %q, expected %s, %s or %s", sd.Action, ShutdownLeave, ShutdownOff, ShutdownPark)
+	}
+	v.checkRange("config.lights.shutdown.brightness", sd.Brightness, MinBrightness, MaxBrightness)
+	v.checkRange("config.lights.shutdown.temperature", sd.Temperature, MinTemperature, MaxTemperature)
+
+	ci := c.Circadian
+	v.checkRange("config.circadian.day_temperature", ci.DayTemperature, MinTemperature, MaxTemperature)
+	v.checkRange("config.circadian.night_temperature", ci.NightTemperature, MinTemperature, MaxTemperature)
+	v.checkRange("config.circadian.day_brightness", ci.DayBrightness, MinBrightness, MaxBrightness)
+	v.checkRange("config.circadian.night_brightness", ci.NightBrightness, MinBrightness, MaxBrightness)
+	v.checkNotNegative("config.circadian.transition", ci.Transition)
+	if minSeconds := int(MinCircadianInterval.Seconds()); ci.Interval != 0 && ci.Interval < minSeconds {
+		v.add("config.circadian.interval", "must be at least %d seconds", minSeconds)
+	}
+	for i, p := range ci.Points {
+		path := fmt.Sprintf("config.circadian.points[%d]", i)
+		if _, err := time.Parse("15:04", p.At); err != nil {
+			v.add(path+".at", "invalid time %q, expected HH:MM", p.At)
+		}
+		v.checkRange(path+".temperature", p.Temperature, MinTemperature, MaxTemperature)
+		v.checkRange(path+".brightness", p.Brightness, MinBrightness, MaxBrightness)
+	}
+
+	ds := c.DesiredState
+	if minSeconds := int(MinDesiredStateInterval.Seconds()); ds.Interval != 0 && ds.Interval < minSeconds {
+		v.add("config.desired_state.interval", "must be at least %d seconds", minSeconds)
+	}
+	desiredKeys := map[string]bool{}
+	for i, t := range ds.Targets {
+		path := fmt.Sprintf("config.desired_state.targets[%d]", i)
+		switch {
+		case (t.Light == "") == (t.Group == ""):
+			v.add(path, "needs either a light or a group")
+		case desiredKeys[t.Key()]:
+			v.add(path, "duplicate target %s", t.Key())
+		default:
+			desiredKeys[t.Key()] = true
+		}
+		if !t.Disabled && t.On == nil && t.Brightness == nil && t.Temperature == nil {
+			v.add(path, "needs at least one of on, brightness or temperature")
+		}
+		if t.Brightness != nil && (*t.Brightness < MinBrightness || *t.Brightness > MaxBrightness) {
+			v.add(path+".brightness", "must be between %d and %d", MinBrightness, MaxBrightness)
+		}
+		if t.Temperature != nil && (*t.Temperature < MinTemperature || *t.Temperature > MaxTemperature) {
+			v.add(path+".temperature", "must be between %d and %d", MinTemperature, MaxTemperature)
+		}
+	}
+
+	v.checkNotNegative("config.api.request_timeout", c.API.RequestTimeout)
+	al := c.API.AccessLog
+	switch al.Format {
+	case "", AccessLogCommon, AccessLogCombined, AccessLogJSON:
+	default:
+		v.add("config.api.access_log.format", "unknown format %q, expected %s, %s or %s", al.Format, AccessLogCommon, AccessLogCombined, AccessLogJSON)
+	}
+	v.checkNotNegative("config.api.access_log.max_size_mb", al.MaxSizeMB)
+	v.checkNotNegative("config.api.access_log.max_backups", al.MaxBackups)
+	for i, l := range c.API.ListenAddresses {
+		if err := l.Validate(); err != nil {
+			v.add(fmt.Sprintf("config.api.listen_addresses[%d]", i), "%s", err)
+		}
+	}
+
+	if c.Schedules.Timezone != "" {
+		if _, err := time.LoadLocation(c.Schedules.Timezone); err != nil {
+			v.add("config.schedules.timezone", "unknown time zone %q", c.Schedules.Timezone)
+		}
+	}
+
+	v.checkNotNegative("config.webcam.poll_interval", c.Webcam.PollInterval)
+	v.checkNotNegative("config.webcam.off_delay", c.Webcam.OffDelay)
+
+	for i, h := range c.Hooks {
+		path := fmt.Sprintf("config.hooks[%d]", i)
+		if h.Command == "" {
+			v.add(path, "needs a command")
+		}
+		v.checkNotNegative(path+".debounce_ms", h.DebounceMS)
+		v.checkNotNegative(path+".timeout_ms", h.TimeoutMS)
+	}
+
+	v.checkNotNegative("config.simulation.lights", c.Simulation.Lights)
+	v.checkNotNegative("config.simulation.latency_ms", c.Simulation.LatencyMS)
+	if c.Simulation.FailureRate < 0 || c.Simulation.FailureRate > 1 {
+		v.add("config.simulation.failure_rate", "must be between 0 and 1")
+	}
+}
+
+// checkNotNegative reports a negative value, which Load replaces with a default.
+func (v *validator) checkNotNegative(path string, value int) {
+	if value < 0 {
+		v.add(path, "must not be negative")
+	}
+}
+
+// checkRange reports a set value outside minimum-maximum, which Load clamps.
+// Zero is left unset.
+func (v *validator) checkRange(path string, value, minimum, maximum int) {
+	if value != 0 && (value < minimum || value > maximum) {
+		v.add(path, "must be between %d and %d", minimum, maximum)
+	}
+}
+
+// fieldKey returns the key a struct field is read from: its mapstructure
+// name, falling back to its YAML name and then the field name.
+func fieldKey(f reflect.StructField) string {
+	for _, tag := range []string{"mapstructure", "yaml"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// lookup returns the value node at a path such as config.lights.static[0].ip,
+// or nil if it isn't in the document.
+func lookup(n *yaml.Node, path string) *yaml.Node {
+	if path == "" {
+		return n
+	}
+	for part := range strings.SplitSeq(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if n = findKey(n, name); n == nil {
+			return nil
+		}
+		for rest != "" {
+			index, after, _ := strings.Cut(rest, "]")
+			i, err := strconv.Atoi(index)
+			if err != nil || n.Kind != yaml.SequenceNode || i >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[i]
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return n
+}
+
+// findKey returns the value of key in mapping n, ignoring case.
+func findKey(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if strings.EqualFold(n.Content[i].Value, key) {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_Valid(t *testing.T) {
+	problems := Validate([]byte(`
+config:
+  discovery:
+    interval: 60
+  logging:
+    level: debug
+    filters:
+      - type: source:file
+        pattern: "*keylight*"
+        level: debug
+        enabled: true
+  lights:
+    static:
+      - ip: 192.168.1.50
+        port: 9123
+  circadian:
+    points:
+      - at: "07:00"
+        temperature: 5000
+  desired_state:
+    enabled: true
+    targets:
+      - group: office
+        on: true
+        brightness: 40
+      - light: light-1
+        temperature: 4000
+        disabled: true
+`))
+	assert.Empty(t, problems)
+	assert.Empty(t, Validate(nil))
+}
+
+func TestValidate_Schema(t *testing.T) {
+	problems := Validate([]byte(`config:
+  discovery:
+    intervall: 60
+    interval: 30s
+  lights:
+    static: 192.168.1.50
+  api:
+    metrics_enabled: maybe
+`))
+	require.Len(t, problems, 4)
+	assert.Equal(t, Problem{Path: "config.discovery.intervall", Line: 3, Message: "unknown key"}, problems[0])
+	assert.Equal(t, Problem{Path: "config.discovery.interval", Line: 4, Message: `expected an integer, got "30s"`}, problems[1])
+	assert.Equal(t, "config.lights.static (line 6): expected a list", problems[2].String())
+	assert.Equal(t, "config.api.metrics_enabled", problems[3].Path)
+}
+
+func TestValidate_Values(t *testing.T) {
+	problems := Validate([]byte(`config:
+  discovery:
+    interval: 2
+    idle_interval: 300
+  logging:
+    level: verbose
+    filters:
+      - type: source:file
+        level: debug
+  lights:
+    static:
+      - ip: not-an-ip
+    limits:
+      - min_brightness: 200
+    overrides:
+      - port: 70000
+    poll_interval: 1
+    shutdown:
+      action: explode
+      brightness: 150
+  circadian:
+    points:
+      - at: "7am"
+  desired_state:
+    targets:
+      - light: light-1
+        group: office
+        brightness: 150
+      - group: office
+        on: true
+      - group: office
+  groups:
+    failure_policy: sometimes
+  schedules:
+    timezone: Mars/Olympus_Mons
+  api:
+    listen_addresses:
+      - address: 127.0.0.1:9123
+      - address: 0.0.0.0:9124
+        auth: none
+      - address: unix://relative.sock
+    access_log:
+      enabled: true
+      format: apache
+      max_backups: -1
+`))
+	var paths []string
+	for _, p := range problems {
+		paths = append(paths, p.Path)
+	}
+	assert.ElementsMatch(t, []string{
+		"config.discovery.interval",
+		"config.discovery.idle_interval",
+		"config.logging.level",
+		"config.logging.filters[0].pattern",
+		"config.lights.static[0].ip",
+		"config.lights.limits[0]",
+		"config.lights.limits[0].min_brightness",
+		"config.lights.overrides[0]",
+		"config.lights.overrides[0].port",
+		"config.lights.poll_interval",
+		"config.lights.shutdown.action",
+		"config.lights.shutdown.brightness",
+		"config.circadian.points[0].at",
+		"config.desired_state.targets[0]",
+		"config.desired_state.targets[0].brightness",
+		"config.desired_state.targets[2]",
+		"config.desired_state.targets[2]",
+		"config.groups.failure_policy",
+		"config.schedules.timezone",
+		"config.api.listen_addresses[1]",
+		"config.api.listen_addresses[2]",
+		"config.api.access_log.format",
+		"config.api.access_log.max_backups",
+	}, paths)
+	assert.Equal(t, "config.discovery.interval (line 3): must be at least 5 seconds", problems[0].String())
+	assert.Equal(t, 12, problems[4].Line)
+}
+
+func TestValidate_State(t *testing.T) {
+	assert.Empty(t, Validate([]byte(`version: 2
+api_keys:
+  - key: abc
+    name: test
+    created_at: 2024-03-20T10:00:00Z
+groups:
+  group-1:
+    name: Office
+light_names:
+  light-1: Desk
+`)))
+
+	problems := Validate([]byte(`version: 99
+api_keys:
+  - key: abc
+    created_at: yesterday
+light_colours: {}
+`))
+	require.Len(t, problems, 3)
+	assert.Equal(t, "version", problems[0].Path)
+	assert.Equal(t, Problem{Path: "api_keys[0].created_at", Line: 4, Message: `expected a timestamp, got "yesterday"`}, problems[1])
+	assert.Equal(t, "light_colours", problems[2].Path)
+}
+
+func TestValidate_Unparsable(t *testing.T) {
+	problems := Validate([]byte("config:\n  discovery: [\n"))
+	require.Len(t, problems, 1)
+	assert.Empty(t, problems[0].Path)
+}
+
+func TestValidate_ProductPatterns(t *testing.T) {
+	problems := Validate([]byte(`config:
+  discovery:
+    validation:
+      product_prefixes: ["Elgato Key"]
+      product_patterns: ["^Key Light (Neo|Mini)$", "Key Light ("]
+`))
+	require.Len(t, problems, 1)
+	assert.Equal(t, "config.discovery.validation.product_patterns[1]", problems[0].Path)
+	assert.Contains(t, problems[0].Message, `invalid regular expression "Key Light ("`)
+}
@@ -0,0 +1,206 @@
+// Package crash writes crash reports for the daemon, so that a panic or
+// fatal error leaves the stack traces of every goroutine behind in a file
+// that can be attached to a bug report, rather than only in a log that may
+// have been rotated away.
+//
+// Reporting is opt-in (config.debug.crash_reports). Once enabled, the Go
+// runtime writes unrecovered panics and fatal errors to a report file, and
+// panics recovered by the daemon's workers are written to reports of their
+// own with Recovered.
+package crash
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxReports is the number of reports kept in the report directory; older
+// ones are removed when reporting is enabled.
+const MaxReports = 10
+
+// Report file names are prefixed by kind and end in ext.
+const (
+	prefixCrash     = "crash-"
+	prefixRecovered = "panic-"
+	ext             = ".txt"
+)
+
+// Info identifies the daemon build in reports.
+type Info struct {
+	Version   string
+	Commit    string
+	BuildDate string
+}
+
+var (
+	mu      sync.Mutex
+	dir     string // report directory; empty while reporting is disabled
+	info    Info
+	pending *os.File // report the runtime writes to if the daemon crashes
+)
+
+// Enable starts writing crash reports to reportDir, creating it if needed.
+// The returned function disables reporting again and removes the report
+// prepared for a crash that didn't happen; call it as the daemon exits.
+func Enable(reportDir string, i Info) (func(), error) {
+	if err := os.MkdirAll(reportDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+	prune(reportDir)
+
+	f, err := os.OpenFile(reportPath(reportDir, prefixCrash), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create crash report: %w", err)
+	}
+	if _, err := f.Write(header(i, "")); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write crash report: %w", err)
+	}
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to set crash output: %w", err)
+	}
+	// Trace every goroutine rather than only the one that panicked
+	debug.SetTraceback("all")
+
+	mu.Lock()
+	dir, info, pending = reportDir, i, f
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if pending == nil {
+			return
+		}
+		_ = debug.SetCrashOutput(nil, debug.CrashOptions{})
+		_ = pending.Close()
+		_ = os.Remove(pending.Name())
+		dir, pending = "", nil
+	}, nil
+}
+
+// Recovered writes a report for a panic recovered in the named part of the
+// daemon, with the stack of the goroutine that panicked, and returns its
+// path. Call it from the deferred function that recovered. It does nothing
+// and returns "" while reporting is disabled.
+func Recovered(where string, v any) string {
+	mu.Lock()
+	reportDir, i := dir, info
+	mu.Unlock()
+	if reportDir == "" {
+		return ""
+	}
+
+	var b bytes.Buffer
+	b.Write(header(i, where))
+	fmt.Fprintf(&b, "panic: %v [recovered]\n\n", v)
+	b.Write(debug.Stack())
+	path := reportPath(reportDir, prefixRecovered)
+	if err := os.WriteFile(path, b.Bytes(), 0600); err != nil {
+		return ""
+	}
+	return path
+}
+
+// header describes the daemon and, for recovered panics, where it panicked.
+func header(i Info, where string) []byte {
+	var b bytes.Buffer
+	b.WriteString("keylightd crash report\n\n")
+	fmt.Fprintf(&b, "Version:    %s\n", cmp.Or(i.Version, "unknown"))
+	fmt.Fprintf(&b, "Commit:     %s\n", cmp.Or(i.Commit, "unknown"))
+	fmt.Fprintf(&b, "Build date: %s\n", cmp.Or(i.BuildDate, "unknown"))
+	fmt.Fprintf(&b, "Go:         %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "PID:        %d\n", os.Getpid())
+	if where == "" {
+		fmt.Fprintf(&b, "Started:    %s\n", time.Now().Format(time.RFC3339))
+	} else {
+		fmt.Fprintf(&b, "Time:       %s\n", time.Now().Format(time.RFC3339))
+		fmt.Fprintf(&b, "Panicked:   %s\n", where)
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// reportPath returns a new report's path, named by kind, time and process.
+func reportPath(reportDir, prefix string) string {
+	name := fmt.Sprintf("%s%s-%d%s", prefix, time.Now().UTC().Format("20060102T150405.000Z"), os.Getpid(), ext)
+	return filepath.Join(reportDir, name)
+}
+
+// Reports returns the paths of the reports in reportDir, newest first.
+// Reports prepared for crashes that didn't happen, left behind by a daemon
+// that was killed, are skipped.
+func Reports(reportDir string) ([]string, error) {
+	entries, err := os.ReadDir(reportDir)
+	if err != nil {
+		return nil, err
+	}
+	var reports []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ext) ||
+			!(strings.HasPrefix(name, prefixCrash) || strings.HasPrefix(name, prefixRecovered)) {
+			continue
+		}
+		path := filepath.Join(reportDir, name)
+		if strings.HasPrefix(name, prefixCrash) && !crashed(path) {
+			continue
+		}
+		reports = append(reports, path)
+	}
+	// Names sort by time after the prefix
+	slices.SortFunc(reports, func(a, b string) int {
+		return strings.Compare(reportTime(b), reportTime(a))
+	})
+	return reports, nil
+}
+
+// reportTime returns the time part of a report's name, for sorting.
+func reportTime(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimPrefix(strings.TrimPrefix(name, prefixCrash), prefixRecovered)
+}
+
+// crashed reports whether the runtime wrote a traceback to a crash report.
+func crashed(path string) bool {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: a report in the report directory
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(data, []byte("\ngoroutine "))
+}
+
+// prune removes reports prepared for crashes that didn't happen and all but
+// the newest MaxReports-1 others, making room for the next.
+func prune(reportDir string) {
+	entries, err := os.ReadDir(reportDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(reportDir, name)
+		if strings.HasPrefix(name, prefixCrash) && strings.HasSuffix(name, ext) && !crashed(path) {
+			_ = os.Remove(path)
+		}
+	}
+	reports, err := Reports(reportDir)
+	if err != nil || len(reports) < MaxReports {
+		return
+	}
+	for _, path := range reports[MaxReports-1:] {
+		_ = os.Remove(path)
+	}
+}
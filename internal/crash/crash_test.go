@@ -0,0 +1,125 @@
+package crash
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testInfo = Info{Version: "1.2.3", Commit: "abc123", BuildDate: "2026-10-16"}
+
+// TestHelperProcess is run as a separate process by TestEnable_Crash, and
+// panics with crash reports enabled.
+func TestHelperProcess(t *testing.T) {
+	dir := os.Getenv("CRASH_HELPER_DIR")
+	if dir == "" {
+		return
+	}
+	if _, err := Enable(dir, testInfo); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		panic("light on fire")
+	}()
+	<-done
+}
+
+func TestEnable_Crash(t *testing.T) {
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$") //nolint:gosec // G204: the test binary
+	cmd.Env = append(os.Environ(), "CRASH_HELPER_DIR="+dir)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode())
+
+	reports, err := Reports(dir)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.True(t, strings.HasPrefix(filepath.Base(reports[0]), "crash-"))
+
+	data, err := os.ReadFile(reports[0])
+	require.NoError(t, err)
+	report := string(data)
+	assert.Contains(t, report, "Version:    1.2.3\n")
+	assert.Contains(t, report, "panic: light on fire")
+	assert.Contains(t, report, "TestHelperProcess")
+}
+
+func TestEnable_Stop(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "crashes")
+	stop, err := Enable(dir, testInfo)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "report is prepared")
+	reports, err := Reports(dir)
+	require.NoError(t, err)
+	assert.Empty(t, reports, "a report without a crash isn't listed")
+
+	stop()
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Empty(t, Recovered("worker", "boom"), "reporting is disabled after stop")
+}
+
+func TestRecovered(t *testing.T) {
+	assert.Empty(t, Recovered("worker", "boom"), "reporting is disabled")
+
+	dir := t.TempDir()
+	stop, err := Enable(dir, testInfo)
+	require.NoError(t, err)
+	defer stop()
+
+	var path string
+	func() {
+		defer func() {
+			path = Recovered("schedule worker", recover())
+		}()
+		panic("boom")
+	}()
+	require.NotEmpty(t, path)
+	assert.Equal(t, dir, filepath.Dir(path))
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: test file
+	require.NoError(t, err)
+	report := string(data)
+	assert.Contains(t, report, "Panicked:   schedule worker\n")
+	assert.Contains(t, report, "panic: boom [recovered]\n")
+	assert.Contains(t, report, "TestRecovered")
+
+	reports, err := Reports(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, reports)
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for i := range MaxReports + 2 {
+		name := fmt.Sprintf("panic-20260101T0000%02d.000Z-1.txt", i)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("report"), 0600))
+	}
+	// Left behind by a daemon that was killed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crash-20260102T000000.000Z-1.txt"), []byte("keylightd crash report\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine"), 0600))
+
+	prune(dir)
+
+	reports, err := Reports(dir)
+	require.NoError(t, err)
+	require.Len(t, reports, MaxReports-1)
+	assert.Equal(t, fmt.Sprintf("panic-20260101T0000%02d.000Z-1.txt", MaxReports+1), filepath.Base(reports[0]), "newest first")
+	assert.NoFileExists(t, filepath.Join(dir, "crash-20260102T000000.000Z-1.txt"))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+}
@@ -0,0 +1,329 @@
+// Package desiredstate implements desired-state mode, which keeps lights and
+// groups in a state declared in the config.
+//
+// A background worker started alongside the server reads the targeted lights
+// every interval and puts back any property that has drifted, whether it was
+// changed by another app, the light's own buttons or a keylightd API call.
+// Targets can be disabled in the config or at runtime so their lights can be
+// changed freely.
+package desiredstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Status is the current state of desired-state mode.
+type Status struct {
+	Enabled  bool           `json:"enabled"`
+	Interval int            `json:"interval"` // Seconds between checks
+	Targets  []TargetStatus `json:"targets"`
+}
+
+// TargetStatus is the state of one desired-state target.
+type TargetStatus struct {
+	Target         string    `json:"target"` // light:<id> or group:<id or name>
+	On             *bool     `json:"on,omitempty"`
+	Brightness     *int      `json:"brightness,omitempty"`
+	Temperature    *int      `json:"temperature,omitempty"` // Kelvin
+	Enabled        bool      `json:"enabled"`
+	Lights         []string  `json:"lights"`      // Lights the target currently applies to
+	Corrections    int       `json:"corrections"` // Times a light was put back since the daemon started
+	LastCorrection time.Time `json:"last_correction,omitzero"`
+}
+
+// corrections counts the times a target's lights were put back.
+type corrections struct {
+	count int
+	last  time.Time
+}
+
+// Manager runs desired-state mode.
+// All access to settings, targets and corrected is guarded by mu.
+type Manager struct {
+	logger   *slog.Logger
+	cfg      *config.Config
+	lights   keylight.LightManager
+	groups   *group.Manager
+	settings config.DesiredStateConfig
+	// targets are the targets enabled or disabled at runtime, keyed by
+	// DesiredState.Key, overriding their disabled setting.
+	targets   map[string]bool
+	corrected map[string]corrections
+	wake      chan struct{}
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// NewManager creates a desired-state manager from the desired_state config.
+func NewManager(logger *slog.Logger, cfg *config.Config, lights keylight.LightManager, groups *group.Manager) *Manager {
+	m := &Manager{
+		logger:    logger,
+		cfg:       cfg,
+		lights:    lights,
+		groups:    groups,
+		settings:  cfg.Config.DesiredState,
+		targets:   cfg.GetDesiredStateTargets(),
+		corrected: make(map[string]corrections),
+		wake:      make(chan struct{}, 1),
+		now:       time.Now,
+	}
+	if m.targets == nil {
+		m.targets = make(map[string]bool)
+	}
+	if enabled := cfg.GetDesiredStateEnabled(); enabled != nil {
+		m.settings.Enabled = *enabled
+	}
+	return m
+}
+
+// targetEnabled reports whether a target is enforced. Callers must hold mu.
+func (m *Manager) targetEnabled(t config.DesiredState) bool {
+	if enabled, ok := m.targets[t.Key()]; ok {
+		return enabled
+	}
+	return !t.Disabled
+}
+
+// Status returns the current state of desired-state mode.
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	settings := m.settings
+	targets := make([]TargetStatus, len(settings.Targets))
+	for i, t := range settings.Targets {
+		c := m.corrected[t.Key()]
+		targets[i] = TargetStatus{
+			Target:         t.Key(),
+			On:             t.On,
+			Brightness:     t.Brightness,
+			Temperature:    t.Temperature,
+			Enabled:        m.targetEnabled(t),
+			Corrections:    c.count,
+			LastCorrection: c.last,
+		}
+	}
+	m.mu.Unlock()
+
+	for i, t := range settings.Targets {
+		targets[i].Lights = m.targetLights(t)
+	}
+	return Status{Enabled: settings.Enabled, Interval: settings.Interval, Targets: targets}
+}
+
+// SetEnabled turns desired-state mode on or off and saves the choice with the
+// daemon's state, where it overrides desired_state.enabled from the config
+// file. Enabling checks the targets straight away.
+func (m *Manager) SetEnabled(enabled bool) (Status, error) {
+	m.mu.Lock()
+	previous, previousOverride := m.settings.Enabled, m.cfg.GetDesiredStateEnabled()
+	m.settings.Enabled = enabled
+	m.cfg.Config.DesiredState.Enabled = enabled
+	m.cfg.SetDesiredStateEnabled(&enabled)
+	if err := m.cfg.Save(); err != nil {
+		m.settings.Enabled = previous
+		m.cfg.Config.DesiredState.Enabled = previous
+		m.cfg.SetDesiredStateEnabled(previousOverride)
+		m.mu.Unlock()
+		return Status{}, fmt.Errorf("failed to save desired-state settings: %w", err)
+	}
+	m.mu.Unlock()
+
+	m.logger.Info("desired-state mode changed", "enabled", enabled)
+	if enabled {
+		m.wakeUp()
+	}
+	return m.Status(), nil
+}
+
+// SetTargetEnabled enables or disables enforcing one target, given by its key,
+// and saves the choice with the daemon's state, where it overrides the
+// target's disabled setting.
+func (m *Manager) SetTargetEnabled(key string, enabled bool) (Status, error) {
+	m.mu.Lock()
+	i := slices.IndexFunc(m.settings.Targets, func(t config.DesiredState) bool { return t.Key() == key })
+	if i < 0 {
+		m.mu.Unlock()
+		return Status{}, kerrors.NotFoundf("desired-state target %s not found", key)
+	}
+	previous, hadPrevious := m.targets[key]
+	if enabled == !m.settings.Targets[i].Disabled {
+		delete(m.targets, key)
+	} else {
+		m.targets[key] = enabled
+	}
+	m.cfg.SetDesiredStateTargets(m.targets)
+	if err := m.cfg.Save(); err != nil {
+		if hadPrevious {
+			m.targets[key] = previous
+		} else {
+			delete(m.targets, key)
+		}
+		m.cfg.SetDesiredStateTargets(m.targets)
+		m.mu.Unlock()
+		return Status{}, fmt.Errorf("failed to save desired-state settings: %w", err)
+	}
+	m.mu.Unlock()
+
+	m.logger.Info("desired-state target changed", "target", key, "enabled", enabled)
+	if enabled {
+		m.wakeUp()
+	}
+	return m.Status(), nil
+}
+
+// wakeUp asks the worker to check the targets now.
+func (m *Manager) wakeUp() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run checks the targets every interval until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	m.mu.Lock()
+	interval := time.Duration(m.settings.Interval) * time.Second
+	m.mu.Unlock()
+	if interval <= 0 {
+		interval = config.DefaultDesiredStateInterval
+	}
+
+	m.logger.Info("Starting desired-state worker", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	m.evaluate(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Desired-state worker stopped")
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+		m.evaluate(ctx)
+	}
+}
+
+// evaluate puts every light of an enabled target back into its desired state.
+// A light with a target of its own is not held to the targets of its groups,
+// even while its own target is disabled, so disabling it opts the light out.
+func (m *Manager) evaluate(ctx context.Context) {
+	m.mu.Lock()
+	if !m.settings.Enabled {
+		m.mu.Unlock()
+		return
+	}
+	var targets []config.DesiredState
+	direct := map[string]bool{}
+	for _, t := range m.settings.Targets {
+		if m.targetEnabled(t) {
+			targets = append(targets, t)
+		}
+		if t.Light != "" {
+			direct[t.Light] = true
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, t := range targets {
+		for _, id := range m.targetLights(t) {
+			if t.Group != "" && direct[id] {
+				continue
+			}
+			corrected, err := m.reconcile(ctx, id, t)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("light %s: %w", id, err))
+			}
+			if len(corrected) == 0 {
+				continue
+			}
+			m.logger.Info("desired state: put light back", "id", id, "target", t.Key(), "properties", corrected)
+			m.mu.Lock()
+			c := m.corrected[t.Key()]
+			c.count++
+			c.last = m.now()
+			m.corrected[t.Key()] = c
+			m.mu.Unlock()
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		m.logger.Warn("desired state: failed to update lights", "error", err)
+	}
+}
+
+// reconcile reads a light and sets any property that differs from the target,
+// returning the properties it set. Brightness and temperature are only
+// enforced while the light is on.
+func (m *Manager) reconcile(ctx context.Context, id string, t config.DesiredState) ([]string, error) {
+	light, err := m.lights.GetLight(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	limits := keylight.DefaultLimits()
+	if light.Limits != nil {
+		limits = *light.Limits
+	}
+
+	var corrected []string
+	on := light.On
+	if t.On != nil && *t.On != light.On {
+		if err := m.lights.SetLightPower(ctx, id, *t.On); err != nil {
+			return corrected, err
+		}
+		corrected = append(corrected, "on")
+		on = *t.On
+	}
+	if !on {
+		return corrected, nil
+	}
+	if t.Brightness != nil {
+		if want := limits.ClampBrightness(*t.Brightness); want != light.Brightness {
+			if err := m.lights.SetLightBrightness(ctx, id, want); err != nil {
+				return corrected, err
+			}
+			corrected = append(corrected, "brightness")
+		}
+	}
+	if t.Temperature != nil {
+		want := limits.ClampTemperature(*t.Temperature)
+		if light.ColorMode == keylight.ColorModeColor || !sameTemperature(light.Temperature, want) {
+			if err := m.lights.SetLightTemperature(ctx, id, want); err != nil {
+				return corrected, err
+			}
+			corrected = append(corrected, "temperature")
+		}
+	}
+	return corrected, nil
+}
+
+// sameTemperature reports whether a light at mireds is at kelvin, allowing for
+// the rounding of converting between the two.
+func sameTemperature(mireds, kelvin int) bool {
+	diff := mireds - 1000000/kelvin
+	return diff >= -1 && diff <= 1
+}
+
+// targetLights returns the sorted IDs of the lights a target applies to.
+func (m *Manager) targetLights(t config.DesiredState) []string {
+	if t.Light != "" {
+		return []string{t.Light}
+	}
+	ids := []string{}
+	groups, _ := m.groups.GetGroupsByKeys(t.Group)
+	for _, g := range groups {
+		ids = append(ids, g.Lights...)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -0,0 +1,181 @@
+package desiredstate
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type mockLightManager struct {
+	keylight.LightManager
+	mu     sync.Mutex
+	lights map[string]*keylight.Light
+	calls  []string
+}
+
+func (m *mockLightManager) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, keylight.ErrLightNotFound
+	}
+	l := *light
+	return &l, nil
+}
+
+func (m *mockLightManager) SetLightPower(_ context.Context, id string, on bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, id+":on")
+	m.lights[id].On = on
+	return nil
+}
+
+func (m *mockLightManager) SetLightBrightness(_ context.Context, id string, brightness int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, id+":brightness")
+	m.lights[id].Brightness = brightness
+	return nil
+}
+
+func (m *mockLightManager) SetLightTemperature(_ context.Context, id string, temperature int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, id+":temperature")
+	m.lights[id].Temperature = 1000000 / temperature
+	return nil
+}
+
+func (m *mockLightManager) takeCalls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := m.calls
+	m.calls = nil
+	return calls
+}
+
+func setupTestManager(t *testing.T, desired config.DesiredStateConfig) (*Manager, *mockLightManager, *config.Config) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "test.yaml")
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(configPath)
+	cfg := config.New(v)
+	cfg.Config.DesiredState = desired
+	require.NoError(t, cfg.Save())
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{
+		"light1": {ID: "light1", On: true, Brightness: 40, Temperature: 250},
+		"light2": {ID: "light2", Brightness: 40, Temperature: 250},
+	}}
+	groups := group.NewManager(logger, lights, cfg)
+	_, err := groups.CreateGroup(context.Background(), "office", []string{"light1", "light2"})
+	require.NoError(t, err)
+	return NewManager(logger, cfg, lights, groups), lights, cfg
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func officeConfig() config.DesiredStateConfig {
+	d := config.DefaultDesiredState()
+	d.Targets = []config.DesiredState{{Group: "office", On: ptr(true), Brightness: ptr(60), Temperature: ptr(4000)}}
+	return d
+}
+
+func TestEvaluate(t *testing.T) {
+	m, lights, _ := setupTestManager(t, officeConfig())
+	ctx := context.Background()
+
+	// Nothing happens while disabled
+	m.evaluate(ctx)
+	assert.Empty(t, lights.takeCalls())
+
+	_, err := m.SetEnabled(true)
+	require.NoError(t, err)
+
+	// Only the properties that differ are set
+	m.evaluate(ctx)
+	assert.Equal(t, []string{"light1:brightness", "light2:on", "light2:brightness"}, lights.takeCalls())
+
+	m.evaluate(ctx)
+	assert.Empty(t, lights.takeCalls())
+
+	// A light changed by something else is put back
+	lights.lights["light1"].On = false
+	m.evaluate(ctx)
+	assert.Equal(t, []string{"light1:on"}, lights.takeCalls())
+
+	status := m.Status()
+	require.Len(t, status.Targets, 1)
+	assert.Equal(t, "group:office", status.Targets[0].Target)
+	assert.Equal(t, []string{"light1", "light2"}, status.Targets[0].Lights)
+	assert.Equal(t, 3, status.Targets[0].Corrections)
+	assert.False(t, status.Targets[0].LastCorrection.IsZero())
+}
+
+func TestEvaluate_LightTarget(t *testing.T) {
+	d := officeConfig()
+	d.Enabled = true
+	d.Targets = append(d.Targets, config.DesiredState{Light: "light2", On: ptr(false)})
+	m, lights, _ := setupTestManager(t, d)
+	ctx := context.Background()
+
+	// A light's own target wins over its group's
+	m.evaluate(ctx)
+	assert.Equal(t, []string{"light1:brightness"}, lights.takeCalls())
+
+	// and disabling it opts the light out altogether
+	_, err := m.SetTargetEnabled("light:light2", false)
+	require.NoError(t, err)
+	lights.lights["light2"].On = true
+	m.evaluate(ctx)
+	assert.Empty(t, lights.takeCalls())
+}
+
+func TestSetTargetEnabled(t *testing.T) {
+	m, lights, cfg := setupTestManager(t, officeConfig())
+	_, err := m.SetEnabled(true)
+	require.NoError(t, err)
+
+	status, err := m.SetTargetEnabled("group:office", false)
+	require.NoError(t, err)
+	assert.False(t, status.Targets[0].Enabled)
+	m.evaluate(context.Background())
+	assert.Empty(t, lights.takeCalls())
+
+	// The choices are kept with the state and override the config on restart
+	assert.Equal(t, map[string]bool{"group:office": false}, cfg.GetDesiredStateTargets())
+	require.NotNil(t, cfg.GetDesiredStateEnabled())
+	restarted := NewManager(m.logger, cfg, m.lights, m.groups).Status()
+	assert.True(t, restarted.Enabled)
+	assert.False(t, restarted.Targets[0].Enabled)
+
+	// Going back to the configured setting drops the override
+	_, err = m.SetTargetEnabled("group:office", true)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.GetDesiredStateTargets())
+
+	_, err = m.SetTargetEnabled("group:studio", false)
+	assert.True(t, kerrors.IsNotFound(err))
+}
@@ -0,0 +1,242 @@
+// Package emulator serves the Elgato light HTTP API from an in-memory device,
+// so discovery and control can be tested end to end without hardware.
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Products lists the Elgato products a Device can emulate, with the hardware
+// board type each reports.
+var Products = map[string]int{
+	"Elgato Key Light":      53,
+	"Elgato Key Light Air":  200,
+	"Elgato Key Light MK.2": 53,
+	"Elgato Ring Light":     75,
+	"Elgato Light Strip":    70,
+}
+
+// ProductName returns the full name of a product, accepting names without the
+// "Elgato " prefix such as "Key Light MK.2". ok is false for unknown products.
+func ProductName(name string) (product string, ok bool) {
+	name = strings.TrimSpace(name)
+	if !strings.HasPrefix(strings.ToLower(name), "elgato ") {
+		name = "Elgato " + name
+	}
+	for product := range Products {
+		if strings.EqualFold(product, name) {
+			return product, true
+		}
+	}
+	return "", false
+}
+
+// ProductNames returns the names of the products a Device can emulate, sorted.
+func ProductNames() []string {
+	names := make([]string, 0, len(Products))
+	for name := range Products {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Device is an emulated Elgato light. It serves /elgato/accessory-info,
+// /elgato/lights, /elgato/lights/settings and /elgato/identify like the real
+// device, keeping its state in memory.
+type Device struct {
+	mu       sync.Mutex
+	info     keylight.AccessoryInfo
+	state    keylight.LightStateEntry
+	settings keylight.DeviceSettings
+	latency  time.Duration
+	logger   *slog.Logger
+}
+
+// NewDevice creates an emulated light of the given product, which must be
+// one of Products. It starts off at 50% brightness and 4700K.
+func NewDevice(product, serial, displayName string, logger *slog.Logger) *Device {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Device{
+		info: keylight.AccessoryInfo{
+			ProductName:         product,
+			HardwareBoardType:   Products[product],
+			FirmwareBuildNumber: 218,
+			FirmwareVersion:     "1.0.3",
+			SerialNumber:        serial,
+			DisplayName:         displayName,
+			Features:            []string{keylight.FeatureLights},
+		},
+		state: keylight.LightStateEntry{Brightness: 50, Temperature: 213},
+		settings: keylight.DeviceSettings{
+			PowerOnBehavior:       1,
+			PowerOnBrightness:     20,
+			PowerOnTemperature:    213,
+			SwitchOnDurationMs:    100,
+			SwitchOffDurationMs:   300,
+			ColorChangeDurationMs: 100,
+		},
+		logger: logger,
+	}
+}
+
+// SetLatency delays every response by d, as a device on a slow network would.
+func (d *Device) SetLatency(latency time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.latency = latency
+}
+
+// Info returns the device's accessory info.
+func (d *Device) Info() keylight.AccessoryInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.info
+}
+
+// State returns the device's light state.
+func (d *Device) State() keylight.LightStateEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state
+}
+
+// ServeHTTP implements http.Handler.
+func (d *Device) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	latency := d.latency
+	d.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(latency):
+		}
+	}
+
+	d.logger.Debug("emulator: request", "serial", d.Info().SerialNumber, "method", r.Method, "path", r.URL.Path)
+	switch r.Method + " " + r.URL.Path {
+	case "GET /elgato/accessory-info":
+		writeJSON(w, d.Info())
+	case "PUT /elgato/accessory-info":
+		var body struct {
+			DisplayName *string `json:"displayName"`
+		}
+		if !readJSON(w, r, &body) {
+			return
+		}
+		d.mu.Lock()
+		if body.DisplayName != nil {
+			d.info.DisplayName = *body.DisplayName
+		}
+		info := d.info
+		d.mu.Unlock()
+		writeJSON(w, info)
+	case "GET /elgato/lights":
+		writeJSON(w, d.lights())
+	case "PUT /elgato/lights":
+		d.putLights(w, r)
+	case "GET /elgato/lights/settings":
+		d.mu.Lock()
+		settings := d.settings
+		d.mu.Unlock()
+		writeJSON(w, settings)
+	case "PUT /elgato/lights/settings":
+		var settings keylight.DeviceSettings
+		if !readJSON(w, r, &settings) {
+			return
+		}
+		d.mu.Lock()
+		d.settings = settings
+		d.mu.Unlock()
+		writeJSON(w, settings)
+	case "POST /elgato/identify":
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// lights returns the device's state as reported by GET /elgato/lights.
+func (d *Device) lights() keylight.LightState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return keylight.LightState{NumberOfLights: 1, Lights: []keylight.LightStateEntry{d.state}}
+}
+
+// putLights applies the fields given in a PUT /elgato/lights, leaving the
+// others unchanged as the device does. Values are clamped to the device's
+// ranges, and setting a temperature switches a color light back to white.
+func (d *Device) putLights(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Lights []struct {
+			On          *int     `json:"on"`
+			Brightness  *int     `json:"brightness"`
+			Temperature *int     `json:"temperature"`
+			Hue         *float64 `json:"hue"`
+			Saturation  *float64 `json:"saturation"`
+		} `json:"lights"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if len(body.Lights) == 0 {
+		http.Error(w, "no lights in request", http.StatusBadRequest)
+		return
+	}
+
+	entry := body.Lights[0]
+	d.mu.Lock()
+	if entry.On != nil {
+		d.state.On = min(max(*entry.On, 0), 1)
+	}
+	if entry.Brightness != nil {
+		d.state.Brightness = min(max(*entry.Brightness, 3), 100)
+	}
+	if entry.Temperature != nil {
+		d.state.Temperature = min(max(*entry.Temperature, 143), 344)
+		d.state.Hue, d.state.Saturation = nil, nil
+	}
+	if keylight.CapabilitiesFromInfo(&d.info).Color && (entry.Hue != nil || entry.Saturation != nil) {
+		hue, saturation := 0.0, 0.0
+		if d.state.Hue != nil {
+			hue, saturation = *d.state.Hue, *d.state.Saturation
+		}
+		if entry.Hue != nil {
+			hue = min(max(*entry.Hue, 0), 360)
+		}
+		if entry.Saturation != nil {
+			saturation = min(max(*entry.Saturation, 0), 100)
+		}
+		d.state.Hue, d.state.Saturation = &hue, &saturation
+		d.state.Temperature = 0
+	}
+	d.mu.Unlock()
+	writeJSON(w, d.lights())
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,136 @@
+package emulator
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func startDevice(t *testing.T, product string) (*Device, string, int) {
+	t.Helper()
+	device := NewDevice(product, "EMU0000001", "Emulated Light", discardLogger())
+	srv := httptest.NewServer(device)
+	t.Cleanup(srv.Close)
+	host, portStr, err := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+	return device, host, port
+}
+
+func TestProductName(t *testing.T) {
+	product, ok := ProductName("Key Light MK.2")
+	require.True(t, ok)
+	assert.Equal(t, "Elgato Key Light MK.2", product)
+
+	product, ok = ProductName(" elgato light strip ")
+	require.True(t, ok)
+	assert.Equal(t, "Elgato Light Strip", product)
+
+	_, ok = ProductName("Key Light Mini")
+	assert.False(t, ok)
+}
+
+func TestDevice_Client(t *testing.T) {
+	device, host, port := startDevice(t, "Elgato Key Light MK.2")
+	client := keylight.NewKeyLightClient(host, port, discardLogger())
+	ctx := context.Background()
+
+	info, err := client.GetAccessoryInfo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Elgato Key Light MK.2", info.ProductName)
+	assert.Equal(t, "EMU0000001", info.SerialNumber)
+
+	require.NoError(t, client.SetLightState(ctx, true, 80, 250))
+	state, err := client.GetLightState(ctx)
+	require.NoError(t, err)
+	require.Len(t, state.Lights, 1)
+	assert.Equal(t, keylight.LightStateEntry{On: 1, Brightness: 80, Temperature: 250}, state.Lights[0])
+
+	// Out of range values are clamped as the device does
+	require.NoError(t, client.SetLightState(ctx, true, 0, 500))
+	assert.Equal(t, 3, device.State().Brightness)
+	assert.Equal(t, 344, device.State().Temperature)
+
+	require.NoError(t, client.SetDisplayName(ctx, "Desk"))
+	assert.Equal(t, "Desk", device.Info().DisplayName)
+
+	settings, err := client.GetDeviceSettings(ctx)
+	require.NoError(t, err)
+	settings.PowerOnBrightness = 60
+	require.NoError(t, client.SetDeviceSettings(ctx, *settings))
+	settings, err = client.GetDeviceSettings(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 60, settings.PowerOnBrightness)
+}
+
+func TestDevice_PartialUpdate(t *testing.T) {
+	device, host, port := startDevice(t, "Elgato Key Light")
+	body := strings.NewReader(`{"numberOfLights":1,"lights":[{"on":1}]}`)
+	req, err := http.NewRequest(http.MethodPut, "http://"+net.JoinHostPort(host, strconv.Itoa(port))+"/elgato/lights", body)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, keylight.LightStateEntry{On: 1, Brightness: 50, Temperature: 213}, device.State())
+}
+
+func TestDevice_Color(t *testing.T) {
+	device, host, port := startDevice(t, "Elgato Light Strip")
+	client := keylight.NewKeyLightClient(host, port, discardLogger())
+	ctx := context.Background()
+
+	require.NoError(t, client.SetLightColor(ctx, true, 70, 120, 90))
+	state := device.State()
+	require.NotNil(t, state.Hue)
+	assert.Equal(t, 120.0, *state.Hue)
+	assert.Equal(t, 90.0, *state.Saturation)
+
+	require.NoError(t, client.SetLightState(ctx, true, 70, 200))
+	assert.Nil(t, device.State().Hue, "setting a temperature leaves color mode")
+}
+
+func TestDevice_Latency(t *testing.T) {
+	device, host, port := startDevice(t, "Elgato Key Light")
+	device.SetLatency(50 * time.Millisecond)
+	client := keylight.NewKeyLightClient(host, port, discardLogger())
+
+	start := time.Now()
+	_, err := client.GetLightState(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestDevice_Manager(t *testing.T) {
+	device, host, port := startDevice(t, "Elgato Key Light MK.2")
+	m := keylight.NewManager(discardLogger())
+	ctx := context.Background()
+	m.AddLight(ctx, keylight.Light{ID: "desk", IP: net.ParseIP(host), Port: port, Static: true})
+
+	light, err := m.GetLight(ctx, "desk")
+	require.NoError(t, err)
+	assert.Equal(t, "Elgato Key Light MK.2", light.ProductName)
+
+	require.NoError(t, m.SetLightPower(ctx, "desk", true))
+	require.NoError(t, m.SetLightBrightness(ctx, "desk", 75))
+	assert.Equal(t, 1, device.State().On)
+	assert.Equal(t, 75, device.State().Brightness)
+}
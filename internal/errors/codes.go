@@ -0,0 +1,174 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrUnauthorized is returned when a request lacks valid credentials
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrForbidden is returned when the credentials don't allow a request
+var ErrForbidden = errors.New("forbidden")
+
+// ErrRateLimited is returned when a client has sent too many requests
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrConflict is returned when a change was made against a stale version of
+// the state it changes
+var ErrConflict = errors.New("conflict")
+
+// Code identifies the kind of an error in API responses. The socket and HTTP
+// APIs return the same codes, and pkg/client decodes them back into the
+// sentinel errors above, so callers never have to match on messages.
+type Code string
+
+// Error codes.
+const (
+	CodeNotFound          Code = "not_found"          // ErrNotFound; HTTP 404
+	CodeInvalidInput      Code = "invalid_input"      // ErrInvalidInput; HTTP 400
+	CodeDeviceUnavailable Code = "device_unavailable" // ErrDeviceUnavailable; HTTP 503
+	CodeTimeout           Code = "timeout"            // context.DeadlineExceeded; HTTP 504
+	CodeUnauthorized      Code = "unauthorized"       // ErrUnauthorized; HTTP 401
+	CodeForbidden         Code = "forbidden"          // ErrForbidden; HTTP 403
+	CodeRateLimited       Code = "rate_limited"       // ErrRateLimited; HTTP 429
+	CodeConflict          Code = "conflict"           // ErrConflict; HTTP 409
+	CodeInternal          Code = "internal"           // ErrInternal and anything unclassified; HTTP 500
+
+	// Socket protocol codes, for errors about a request itself rather than
+	// the action it asked for.
+	CodeInvalidRequest  Code = "invalid_request"   // not a JSON object, or no action
+	CodeRequestTooLarge Code = "request_too_large" // over server.max_request_size; the connection is closed
+	CodeUnknownAction   Code = "unknown_action"    // the action isn't supported by this daemon
+)
+
+// Codes returns every error code, in the order they are documented.
+func Codes() []Code {
+	return []Code{
+		CodeNotFound, CodeInvalidInput, CodeDeviceUnavailable, CodeTimeout,
+		CodeUnauthorized, CodeForbidden, CodeRateLimited, CodeConflict, CodeInternal,
+		CodeInvalidRequest, CodeRequestTooLarge, CodeUnknownAction,
+	}
+}
+
+// codeSentinels maps codes to the sentinel errors they stand for.
+var codeSentinels = map[Code]error{
+	CodeNotFound:          ErrNotFound,
+	CodeInvalidInput:      ErrInvalidInput,
+	CodeDeviceUnavailable: ErrDeviceUnavailable,
+	CodeTimeout:           context.DeadlineExceeded,
+	CodeUnauthorized:      ErrUnauthorized,
+	CodeForbidden:         ErrForbidden,
+	CodeRateLimited:       ErrRateLimited,
+	CodeConflict:          ErrConflict,
+	CodeInternal:          ErrInternal,
+	CodeInvalidRequest:    ErrInvalidInput,
+	CodeRequestTooLarge:   ErrInvalidInput,
+}
+
+// codeStatuses maps codes to HTTP status codes.
+var codeStatuses = map[Code]int{
+	CodeNotFound:          http.StatusNotFound,
+	CodeInvalidInput:      http.StatusBadRequest,
+	CodeDeviceUnavailable: http.StatusServiceUnavailable,
+	CodeTimeout:           http.StatusGatewayTimeout,
+	CodeUnauthorized:      http.StatusUnauthorized,
+	CodeForbidden:         http.StatusForbidden,
+	CodeRateLimited:       http.StatusTooManyRequests,
+	CodeConflict:          http.StatusConflict,
+	CodeInternal:          http.StatusInternalServerError,
+	CodeInvalidRequest:    http.StatusBadRequest,
+	CodeRequestTooLarge:   http.StatusRequestEntityTooLarge,
+	CodeUnknownAction:     http.StatusNotFound,
+}
+
+// HTTPStatus returns the HTTP status code for the code.
+func (c Code) HTTPStatus() int {
+	if status, ok := codeStatuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeForStatus returns the code for an HTTP status code, for errors that
+// were raised with a status only.
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidInput
+	case http.StatusRequestEntityTooLarge:
+		return CodeRequestTooLarge
+	}
+	for _, code := range []Code{
+		CodeNotFound, CodeDeviceUnavailable, CodeTimeout, CodeUnauthorized, CodeForbidden, CodeRateLimited, CodeConflict,
+	} {
+		if codeStatuses[code] == status {
+			return code
+		}
+	}
+	return CodeInternal
+}
+
+// CodeOf returns the code for err: the code of an *Error it wraps, or the code
+// of the sentinel error it wraps. Anything else is CodeInternal.
+func CodeOf(err error) Code {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	for _, code := range []Code{
+		CodeNotFound, CodeInvalidInput, CodeDeviceUnavailable, CodeTimeout, CodeUnauthorized, CodeForbidden, CodeRateLimited, CodeConflict,
+	} {
+		if errors.Is(err, codeSentinels[code]) {
+			return code
+		}
+	}
+	return CodeInternal
+}
+
+// Error is an error as returned by the socket and HTTP APIs: a code, a message
+// and optional details, such as the lights or groups that weren't found.
+// It wraps the sentinel error for its code, so errors.Is works on errors
+// decoded from a response.
+type Error struct {
+	Code    Code           `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel error for the error's code.
+func (e *Error) Unwrap() error {
+	return codeSentinels[e.Code]
+}
+
+// Errorf returns an *Error with the given code and formatted message. Unlike
+// NotFoundf and friends, the message is used as is.
+func Errorf(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// WithDetails sets the error's details and returns it.
+func (e *Error) WithDetails(details map[string]any) *Error {
+	e.Details = details
+	return e
+}
+
+// FromError converts err into an *Error for an API response. The message is
+// err's message, and the code and details come from any *Error it wraps, or
+// else from CodeOf.
+func FromError(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		if apiErr == err {
+			return apiErr
+		}
+		return &Error{Code: apiErr.Code, Message: err.Error(), Details: apiErr.Details}
+	}
+	return &Error{Code: CodeOf(err), Message: err.Error()}
+}
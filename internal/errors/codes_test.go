@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"not found", NotFoundf("light %s", "l1"), CodeNotFound},
+		{"wrapped not found", fmt.Errorf("get: %w", NotFoundf("light")), CodeNotFound},
+		{"invalid input", InvalidInputf("bad"), CodeInvalidInput},
+		{"device unavailable", DeviceUnavailablef("timeout"), CodeDeviceUnavailable},
+		{"deadline", fmt.Errorf("send: %w", context.DeadlineExceeded), CodeTimeout},
+		{"unauthorized", ErrUnauthorized, CodeUnauthorized},
+		{"conflict", Conflictf("light %s changed", "l1"), CodeConflict},
+		{"coded", Errorf(CodeUnknownAction, "unknown action: x"), CodeUnknownAction},
+		{"plain", errors.New("boom"), CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromError(t *testing.T) {
+	coded := Errorf(CodeNotFound, "no groups found").WithDetails(map[string]any{"not_found": []string{"g1"}})
+	if got := FromError(coded); got != coded {
+		t.Errorf("FromError() of an *Error = %v, want it unchanged", got)
+	}
+
+	wrapped := FromError(fmt.Errorf("set group: %w", coded))
+	if wrapped.Code != CodeNotFound || wrapped.Message != "set group: no groups found" || wrapped.Details == nil {
+		t.Errorf("FromError() of a wrapped *Error = %+v", wrapped)
+	}
+
+	plain := FromError(fmt.Errorf("light l1: %w", ErrDeviceUnavailable))
+	if plain.Code != CodeDeviceUnavailable || plain.Message != "light l1: device unavailable" {
+		t.Errorf("FromError() of a sentinel = %+v", plain)
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	err := error(&Error{Code: CodeNotFound, Message: "light not found"})
+	if !errors.Is(err, ErrNotFound) || !IsNotFound(err) {
+		t.Error("an *Error with CodeNotFound should match ErrNotFound")
+	}
+	if errors.Is(err, ErrInvalidInput) {
+		t.Error("an *Error with CodeNotFound should not match ErrInvalidInput")
+	}
+	if errors.Unwrap(&Error{Code: "something_new"}) != nil {
+		t.Error("an unknown code should unwrap to nil")
+	}
+}
+
+func TestCodeHTTPStatus(t *testing.T) {
+	for _, code := range []Code{
+		CodeNotFound, CodeInvalidInput, CodeDeviceUnavailable, CodeTimeout,
+		CodeUnauthorized, CodeForbidden, CodeRateLimited, CodeConflict, CodeInternal,
+	} {
+		if got := CodeForStatus(code.HTTPStatus()); got != code {
+			t.Errorf("CodeForStatus(%d) = %q, want %q", code.HTTPStatus(), got, code)
+		}
+	}
+	if got := CodeForStatus(http.StatusUnprocessableEntity); got != CodeInvalidInput {
+		t.Errorf("CodeForStatus(422) = %q, want %q", got, CodeInvalidInput)
+	}
+	if got := Code("something_new").HTTPStatus(); got != http.StatusInternalServerError {
+		t.Errorf("HTTPStatus() of an unknown code = %d, want 500", got)
+	}
+}
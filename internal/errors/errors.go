@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -20,13 +21,19 @@ var ErrInternal = errors.New("internal error")
 
 // LogErrorAndReturn logs an error with structured context and returns it
 func LogErrorAndReturn(logger *slog.Logger, err error, message string, args ...any) error {
+	return LogErrorAndReturnContext(context.Background(), logger, err, message, args...)
+}
+
+// LogErrorAndReturnContext is LogErrorAndReturn for code handling a request,
+// passing ctx to the logger so the line carries the request's ID
+func LogErrorAndReturnContext(ctx context.Context, logger *slog.Logger, err error, message string, args ...any) error {
 	// Don't modify nil errors
 	if err == nil {
 		return nil
 	}
 
 	// Log the error with the provided context
-	logger.Error(message, append([]any{"error", err}, args...)...)
+	logger.ErrorContext(ctx, message, append([]any{"error", err}, args...)...)
 	return err
 }
 
@@ -68,6 +75,16 @@ func DeviceUnavailablef(format string, args ...any) error {
 	return fmt.Errorf(format+": %w", append(args, ErrDeviceUnavailable)...)
 }
 
+// IsConflict checks if an error is a conflict error
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// Conflictf returns a formatted ErrConflict error
+func Conflictf(format string, args ...any) error {
+	return fmt.Errorf(format+": %w", append(args, ErrConflict)...)
+}
+
 // Internalf returns a formatted ErrInternal error
 func Internalf(format string, args ...any) error {
 	return fmt.Errorf(format+": %w", append(args, ErrInternal)...)
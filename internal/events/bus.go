@@ -21,8 +21,18 @@ const (
 	GroupCreated EventType = "group.created"
 	GroupDeleted EventType = "group.deleted"
 	GroupUpdated EventType = "group.updated"
+
+	// Job events
+	JobCompleted EventType = "job.completed"
 )
 
+// Types lists every event type, in the order they are documented.
+var Types = []EventType{
+	LightStateChanged, LightDiscovered, LightRemoved,
+	GroupCreated, GroupDeleted, GroupUpdated,
+	JobCompleted,
+}
+
 // Event is a single event emitted by a producer.
 type Event struct {
 	Type      EventType       `json:"type"`
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Filter selects the events a subscriber receives. Empty fields match
+// everything, so the zero Filter passes every event.
+//
+// Types limits events to the listed types. Lights and Groups limit light and
+// group events to the ones about the given lights and groups, where a light
+// event passes if it is about a listed light or a light in a listed group.
+// Other events, such as job events, are not about a light or group and only
+// filtered by type.
+type Filter struct {
+	Types  []EventType `json:"types,omitempty"`
+	Lights []string    `json:"lights,omitempty"`
+	Groups []string    `json:"groups,omitempty"` // Group IDs, names or patterns
+}
+
+// GroupResolver resolves group IDs, names or patterns to the groups they
+// match, returning the IDs of the lights in each, keyed by group ID.
+type GroupResolver func(keys []string) map[string][]string
+
+// Validate reports an error for unknown event types.
+func (f Filter) Validate() error {
+	for _, t := range f.Types {
+		if !slices.Contains(Types, t) {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether the filter passes every event.
+func (f Filter) IsZero() bool {
+	return len(f.Types) == 0 && len(f.Lights) == 0 && len(f.Groups) == 0
+}
+
+// subject is the part of a light or group event's data that filters look at.
+type subject struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Lights []string `json:"lights"`
+}
+
+// Match reports whether an event passes the filter. groups resolves the
+// filter's groups when it has any; it may be nil otherwise.
+func (f Filter) Match(e Event, groups GroupResolver) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if len(f.Lights) == 0 && len(f.Groups) == 0 {
+		return true
+	}
+
+	light := strings.HasPrefix(string(e.Type), "light.")
+	group := strings.HasPrefix(string(e.Type), "group.")
+	if !light && !group {
+		return true
+	}
+	var s subject
+	if err := json.Unmarshal(e.Data, &s); err != nil || s.ID == "" {
+		return false
+	}
+
+	if light && slices.Contains(f.Lights, s.ID) {
+		return true
+	}
+	// A deleted group can no longer be resolved, so match it by ID and name too
+	if group && (slices.Contains(f.Groups, s.ID) || (s.Name != "" && slices.Contains(f.Groups, s.Name))) {
+		return true
+	}
+	if len(f.Groups) == 0 || groups == nil {
+		return false
+	}
+	for id, lights := range groups(f.Groups) {
+		if group && id == s.ID || light && slices.Contains(lights, s.ID) {
+			return true
+		}
+	}
+	return false
+}
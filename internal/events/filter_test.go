@@ -0,0 +1,48 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterMatch(t *testing.T) {
+	groups := func(keys []string) map[string][]string {
+		if len(keys) == 1 && keys[0] == "office" {
+			return map[string][]string{"group-1": {"light-2", "light-3"}}
+		}
+		return nil
+	}
+	light := func(id string) Event { return NewEvent(LightStateChanged, map[string]string{"id": id}) }
+
+	tests := []struct {
+		name   string
+		filter Filter
+		event  Event
+		want   bool
+	}{
+		{"zero filter", Filter{}, light("light-1"), true},
+		{"type listed", Filter{Types: []EventType{LightStateChanged}}, light("light-1"), true},
+		{"type not listed", Filter{Types: []EventType{LightRemoved}}, light("light-1"), false},
+		{"light listed", Filter{Lights: []string{"light-1"}}, light("light-1"), true},
+		{"light not listed", Filter{Lights: []string{"light-1"}}, light("light-2"), false},
+		{"light in group", Filter{Groups: []string{"office"}}, light("light-3"), true},
+		{"light not in group", Filter{Groups: []string{"office"}}, light("light-1"), false},
+		{"group resolved", Filter{Groups: []string{"office"}}, NewEvent(GroupUpdated, map[string]string{"id": "group-1"}), true},
+		{"deleted group by name", Filter{Groups: []string{"studio"}}, NewEvent(GroupDeleted, map[string]string{"id": "group-2", "name": "studio"}), true},
+		{"group not listed", Filter{Lights: []string{"light-2"}}, NewEvent(GroupUpdated, map[string]string{"id": "group-1"}), false},
+		{"job events ignore ids", Filter{Lights: []string{"light-1"}}, NewEvent(JobCompleted, map[string]string{"id": "job-1"}), true},
+		{"job events by type", Filter{Types: []EventType{LightStateChanged}, Lights: []string{"light-1"}}, NewEvent(JobCompleted, nil), false},
+		{"no data", Filter{Lights: []string{"light-1"}}, NewEvent(LightStateChanged, nil), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Match(tt.event, groups))
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	assert.NoError(t, Filter{Types: []EventType{LightStateChanged, JobCompleted}}.Validate())
+	assert.ErrorContains(t, Filter{Types: []EventType{"light.exploded"}}.Validate(), `unknown event type "light.exploded"`)
+}
@@ -0,0 +1,66 @@
+package group
+
+import (
+	"slices"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// LightLimits returns the limits for a light: those configured for the light
+// itself combined with those of every group it belongs to. Intended for
+// keylight.Manager.SetLimitsResolver.
+func (m *Manager) LightLimits(lightID string) keylight.Limits {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lightLimitsLocked(lightID)
+}
+
+// GroupLimits returns the range that every light in a group can be set to,
+// so a single slider can control them all.
+func (m *Manager) GroupLimits(id string) (keylight.Limits, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	group, ok := m.lookupLocked(id)
+	if !ok {
+		return keylight.Limits{}, kerrors.NotFoundf("group %s not found", id)
+	}
+	return m.groupLimitsLocked(group), nil
+}
+
+func (m *Manager) lightLimitsLocked(lightID string) keylight.Limits {
+	limits := keylight.DefaultLimits()
+	for _, l := range m.cfg.Config.Lights.Limits {
+		if l.Light == lightID {
+			limits = limits.Intersect(keylight.LimitsFromConfig(l))
+		}
+	}
+	for _, group := range m.groups {
+		if slices.Contains(group.Lights, lightID) {
+			limits = limits.Intersect(m.ownLimitsLocked(group))
+		}
+	}
+	return limits
+}
+
+// groupLimitsLocked returns the limits configured for the group combined with
+// those of its lights.
+func (m *Manager) groupLimitsLocked(group *Group) keylight.Limits {
+	limits := m.ownLimitsLocked(group)
+	for _, lightID := range group.Lights {
+		limits = limits.Intersect(m.lightLimitsLocked(lightID))
+	}
+	return limits
+}
+
+// ownLimitsLocked returns the limits configured for the group itself, by ID or name.
+func (m *Manager) ownLimitsLocked(group *Group) keylight.Limits {
+	limits := keylight.DefaultLimits()
+	for _, l := range m.cfg.Config.Lights.Limits {
+		if l.Group != "" && (l.Group == group.ID || l.Group == group.Name || l.Group == group.LegacyID) {
+			limits = limits.Intersect(keylight.LimitsFromConfig(l))
+		}
+	}
+	return limits
+}
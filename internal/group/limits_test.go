@@ -0,0 +1,47 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+func TestLightLimits(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"light1": {ID: "light1"},
+			"light2": {ID: "light2"},
+		},
+	}
+	cfg := setupTestConfig(t)
+	cfg.Config.Lights.Limits = []config.LightLimit{
+		{Light: "light1", MaxBrightness: 70},
+		{Group: "desk", MinBrightness: 20, MaxTemperature: 5000},
+	}
+	manager := NewManager(logger, lights, cfg)
+	grp, err := manager.CreateGroup(context.Background(), "desk", []string{"light1", "light2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, keylight.Limits{MinBrightness: 20, MaxBrightness: 70, MinTemperature: 2900, MaxTemperature: 5000},
+		manager.LightLimits("light1"))
+	assert.Equal(t, keylight.Limits{MinBrightness: 20, MaxBrightness: 100, MinTemperature: 2900, MaxTemperature: 5000},
+		manager.LightLimits("light2"))
+	assert.Equal(t, keylight.DefaultLimits(), manager.LightLimits("light3"))
+
+	// A group's limits cover every member light
+	limits, err := manager.GroupLimits(grp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 70, limits.MaxBrightness)
+
+	_, err = manager.GroupLimits("missing")
+	assert.True(t, kerrors.IsNotFound(err))
+}
@@ -6,14 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
-
-	"github.com/google/uuid"
+	"time"
 
 	"github.com/jmylchreest/keylightd/internal/config"
 	kerrors "github.com/jmylchreest/keylightd/internal/errors"
 	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/ids"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
@@ -21,7 +23,7 @@ import (
 // Concurrency contract:
 //   - All access to m.groups is protected by mu (RWMutex).
 //   - Read methods (GetGroup, GetGroups, GetGroupsByName) acquire RLock.
-//   - Mutating methods (CreateGroup, DeleteGroup, SetGroupLights, SetGroupState, SetGroupBrightness, SetGroupTemperature)
+//   - Mutating methods (CreateGroup, DeleteGroup, ImportGroups, UpdateGroup, SetGroupLights, SetGroupDefaults, SetGroupState, SetGroupBrightness, SetGroupTemperature, AdjustGroup, ToggleGroup)
 //     hold Lock only for in-memory modifications and release it before persistence.
 //   - Persistence (saveGroups) snapshots groups under a read lock, then updates config & saves outside the write path.
 //   - Returned *Group pointers must be treated as read-only by callers; mutating them directly risks data races.
@@ -40,9 +42,44 @@ type Manager struct {
 
 // Group represents a group of lights that can be controlled together
 type Group struct {
-	ID     string   `json:"id"`
-	Name   string   `json:"name"`
-	Lights []string `json:"lights"` // Store light IDs instead of pointers
+	ID          string    `json:"id"` // group-<UUIDv7>, or group-<UUID> for groups created before UUIDv7 IDs
+	Name        string    `json:"name"`
+	Lights      []string  `json:"lights"` // Store light IDs instead of pointers
+	Defaults    *Defaults `json:"defaults,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Icon        string    `json:"icon,omitempty"`      // Icon name for UIs, such as a Material or GNOME symbolic icon name
+	Color       string    `json:"color,omitempty"`     // #rrggbb, for UIs
+	LegacyID    string    `json:"legacy_id,omitempty"` // ID the group had before it was migrated to a UUID, still accepted in place of ID
+	CreatedAt   time.Time `json:"created_at,omitzero"` // Unset for groups created before it was recorded
+	UpdatedAt   time.Time `json:"updated_at,omitzero"` // When the group's name, metadata, lights or defaults last changed
+}
+
+// Defaults is the default state of a group's lights. When ApplyOnJoin is set,
+// it is applied to member lights as soon as they are discovered.
+type Defaults struct {
+	Brightness  *int `json:"brightness,omitempty"`
+	Temperature *int `json:"temperature,omitempty"` // Kelvin
+	ApplyOnJoin bool `json:"apply_on_join"`
+}
+
+// Validate checks that the default values are within the supported ranges.
+func (d *Defaults) Validate() error {
+	if d.Brightness != nil {
+		if err := keylight.BrightnessValue(*d.Brightness).Validate(); err != nil {
+			return kerrors.InvalidInputf("invalid default brightness: %v", err)
+		}
+	}
+	if d.Temperature != nil {
+		if err := keylight.TemperatureValue(*d.Temperature).Validate(); err != nil {
+			return kerrors.InvalidInputf("invalid default temperature: %v", err)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether no default values are set.
+func (d *Defaults) IsEmpty() bool {
+	return d.Brightness == nil && d.Temperature == nil
 }
 
 // MarshalJSON ensures that Lights is always marshaled as [] instead of null
@@ -128,6 +165,25 @@ func (m *Manager) loadGroups() error {
 			group.Lights[i] = s
 		}
 
+		if raw, ok := groupMap["defaults"]; ok && raw != nil {
+			defaults, err := defaultsFromMap(raw)
+			if err != nil {
+				return fmt.Errorf("invalid defaults for group %s: %w", id, err)
+			}
+			group.Defaults = defaults
+		}
+		group.Description, _ = groupMap["description"].(string)
+		group.Icon, _ = groupMap["icon"].(string)
+		group.Color, _ = groupMap["color"].(string)
+		group.LegacyID, _ = groupMap["legacy_id"].(string)
+		for key, dst := range map[string]*time.Time{"created_at": &group.CreatedAt, "updated_at": &group.UpdatedAt} {
+			t, err := timeFromState(groupMap[key])
+			if err != nil {
+				return fmt.Errorf("invalid %s for group %s: %w", key, id, err)
+			}
+			*dst = t
+		}
+
 		groups[id] = group
 	}
 
@@ -143,10 +199,25 @@ func (m *Manager) loadGroups() error {
 func (m *Manager) saveGroupsLocked() error {
 	groupsMap := make(map[string]any)
 	for id, group := range m.groups {
-		groupsMap[id] = map[string]any{
+		entry := map[string]any{
 			"name":   group.Name,
 			"lights": append([]string{}, group.Lights...),
 		}
+		if group.Defaults != nil {
+			entry["defaults"] = defaultsToMap(group.Defaults)
+		}
+		for key, value := range map[string]string{"description": group.Description, "icon": group.Icon, "color": group.Color, "legacy_id": group.LegacyID} {
+			if value != "" {
+				entry[key] = value
+			}
+		}
+		if !group.CreatedAt.IsZero() {
+			entry["created_at"] = group.CreatedAt
+		}
+		if !group.UpdatedAt.IsZero() {
+			entry["updated_at"] = group.UpdatedAt
+		}
+		groupsMap[id] = entry
 	}
 
 	m.logger.Debug("Updating config with groups", "count", len(groupsMap), "groups", groupsMap)
@@ -175,10 +246,13 @@ func (m *Manager) CreateGroup(ctx context.Context, name string, lightIDs []strin
 	}
 
 	m.mu.Lock()
+	now := time.Now().UTC()
 	group := &Group{
-		ID:     "group-" + uuid.New().String(),
-		Name:   name,
-		Lights: lightIDs,
+		ID:        ids.New("group"),
+		Name:      name,
+		Lights:    lightIDs,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	m.groups[group.ID] = group
@@ -199,18 +273,18 @@ func (m *Manager) CreateGroup(ctx context.Context, name string, lightIDs []strin
 // DeleteGroup removes a light group
 func (m *Manager) DeleteGroup(id string) error {
 	m.mu.Lock()
-	group, exists := m.groups[id]
+	group, exists := m.lookupLocked(id)
 	if !exists {
 		m.mu.Unlock()
 		return kerrors.NotFoundf("group %s not found", id)
 	}
 
 	groupCopy := *group
-	delete(m.groups, id)
-	m.logger.Info("deleted light group", "id", id)
+	delete(m.groups, group.ID)
+	m.logger.Info("deleted light group", "id", group.ID)
 
 	if err := m.saveGroupsLocked(); err != nil {
-		m.groups[id] = &groupCopy
+		m.groups[group.ID] = &groupCopy
 		m.mu.Unlock()
 		m.logger.Error("failed to save groups, rolled back deletion", "error", err)
 		return fmt.Errorf("failed to persist group deletion: %w", err)
@@ -221,18 +295,31 @@ func (m *Manager) DeleteGroup(id string) error {
 	return nil
 }
 
-// GetGroup returns a group by ID
+// GetGroup returns a group by ID or legacy ID
 func (m *Manager) GetGroup(id string) (*Group, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	group, exists := m.groups[id]
+	group, exists := m.lookupLocked(id)
 	if !exists {
 		return nil, kerrors.NotFoundf("group %s not found", id)
 	}
 	return cloneGroup(group), nil
 }
 
+// lookupLocked returns a group by ID or legacy ID. Caller must hold m.mu.
+func (m *Manager) lookupLocked(id string) (*Group, bool) {
+	if group, ok := m.groups[id]; ok {
+		return group, true
+	}
+	for _, group := range m.groups {
+		if group.LegacyID != "" && group.LegacyID == id {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
 // GetGroups returns all groups
 func (m *Manager) GetGroups() []*Group {
 	m.mu.RLock()
@@ -258,19 +345,20 @@ func (m *Manager) SetGroupLights(ctx context.Context, id string, lightIDs []stri
 	}
 
 	m.mu.Lock()
-	group, exists := m.groups[id]
+	group, exists := m.lookupLocked(id)
 	if !exists {
 		m.mu.Unlock()
 		return kerrors.NotFoundf("group %s not found", id)
 	}
 
-	oldLights := group.Lights
+	oldLights, oldUpdated := group.Lights, group.UpdatedAt
 	group.Lights = lightIDs
+	group.UpdatedAt = time.Now().UTC()
 	groupCopy := *group
-	m.logger.Info("updated group lights", "id", id, "lights", lightIDs)
+	m.logger.Info("updated group lights", "id", group.ID, "lights", lightIDs)
 
 	if err := m.saveGroupsLocked(); err != nil {
-		group.Lights = oldLights
+		group.Lights, group.UpdatedAt = oldLights, oldUpdated
 		m.mu.Unlock()
 		m.logger.Error("failed to save groups, rolled back light update", "error", err)
 		return fmt.Errorf("failed to persist group light update: %w", err)
@@ -281,33 +369,208 @@ func (m *Manager) SetGroupLights(ctx context.Context, id string, lightIDs []stri
 	return nil
 }
 
-// applyToGroupLights runs fn concurrently on every light in the group,
-// collecting and returning any errors.
+// SetGroupDefaults sets the default state of a group. A nil or empty defaults clears them.
+func (m *Manager) SetGroupDefaults(id string, defaults *Defaults) error {
+	if defaults != nil {
+		if err := defaults.Validate(); err != nil {
+			return err
+		}
+		if defaults.IsEmpty() {
+			defaults = nil
+		} else {
+			defaults = cloneDefaults(defaults)
+		}
+	}
+
+	m.mu.Lock()
+	group, exists := m.lookupLocked(id)
+	if !exists {
+		m.mu.Unlock()
+		return kerrors.NotFoundf("group %s not found", id)
+	}
+
+	oldDefaults, oldUpdated := group.Defaults, group.UpdatedAt
+	group.Defaults = defaults
+	group.UpdatedAt = time.Now().UTC()
+	groupCopy := cloneGroup(group)
+	m.logger.Info("updated group defaults", "id", group.ID, "defaults", defaults)
+
+	if err := m.saveGroupsLocked(); err != nil {
+		group.Defaults, group.UpdatedAt = oldDefaults, oldUpdated
+		m.mu.Unlock()
+		m.logger.Error("failed to save groups, rolled back defaults update", "error", err)
+		return fmt.Errorf("failed to persist group defaults update: %w", err)
+	}
+	m.mu.Unlock()
+
+	m.emit(events.GroupUpdated, groupCopy)
+	return nil
+}
+
+// ImportGroups creates or replaces groups by ID, for restoring a backup. Unlike
+// CreateGroup, member lights are not checked, since they may not have been
+// discovered yet on this host. Groups without an ID are given a new one, and
+// groups without a creation time are taken to be created now. Groups with a
+// legacy ID, from backups made before group IDs were UUIDs, replace the group
+// migrated from them or are migrated themselves. All groups are validated
+// before any are stored.
+func (m *Manager) ImportGroups(groups []*Group) error {
+	now := time.Now().UTC()
+	imported := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		c := cloneGroup(g)
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			return kerrors.InvalidInputf("group %q has no name", c.ID)
+		}
+		if err := (Update{Description: &c.Description, Icon: &c.Icon, Color: &c.Color}).Validate(); err != nil {
+			return err
+		}
+		if c.Defaults != nil {
+			if err := c.Defaults.Validate(); err != nil {
+				return err
+			}
+			if c.Defaults.IsEmpty() {
+				c.Defaults = nil
+			}
+		}
+		if c.ID == "" {
+			c.ID = ids.New("group")
+		}
+		if c.CreatedAt.IsZero() {
+			c.CreatedAt = now
+		}
+		if c.UpdatedAt.IsZero() {
+			c.UpdatedAt = c.CreatedAt
+		}
+		imported = append(imported, c)
+	}
+
+	m.mu.Lock()
+	previous := maps.Clone(m.groups)
+	created := make(map[string]bool, len(imported))
+	for _, g := range imported {
+		if !ids.Valid("group", g.ID) {
+			if existing, ok := m.lookupLocked(g.ID); ok {
+				g.ID, g.LegacyID = existing.ID, existing.LegacyID
+			} else {
+				g.ID, g.LegacyID = ids.New("group"), g.ID
+			}
+		}
+		_, exists := m.groups[g.ID]
+		created[g.ID] = !exists
+		m.groups[g.ID] = g
+	}
+	if err := m.saveGroupsLocked(); err != nil {
+		m.groups = previous
+		m.mu.Unlock()
+		m.logger.Error("failed to save groups, rolled back import", "error", err)
+		return fmt.Errorf("failed to persist imported groups: %w", err)
+	}
+	m.mu.Unlock()
+
+	m.logger.Info("imported groups", "count", len(imported))
+	for _, g := range imported {
+		if created[g.ID] {
+			m.emit(events.GroupCreated, cloneGroup(g))
+		} else {
+			m.emit(events.GroupUpdated, cloneGroup(g))
+		}
+	}
+	return nil
+}
+
+// HandleLightAdded applies the defaults of every group that contains the light
+// and has ApplyOnJoin set. Groups are applied in ID order, so if several groups
+// set the same property the last one wins. Intended for keylight.Manager.SetLightAddedHandler.
+func (m *Manager) HandleLightAdded(ctx context.Context, light keylight.Light) {
+	m.mu.RLock()
+	var matched []*Group
+	for _, group := range m.groups {
+		if group.Defaults == nil || !group.Defaults.ApplyOnJoin || !slices.Contains(group.Lights, light.ID) {
+			continue
+		}
+		matched = append(matched, cloneGroup(group))
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(matched, func(a, b *Group) int { return strings.Compare(a.ID, b.ID) })
+	for _, group := range matched {
+		m.logger.Info("applying group defaults to new light", "group", group.ID, "light", light.ID)
+		if group.Defaults.Brightness != nil {
+			if err := m.lights.SetLightState(ctx, light.ID, keylight.BrightnessValue(*group.Defaults.Brightness)); err != nil {
+				m.logger.Warn("failed to apply default brightness", "group", group.ID, "light", light.ID, "error", err)
+			}
+		}
+		if group.Defaults.Temperature != nil {
+			if err := m.lights.SetLightState(ctx, light.ID, keylight.TemperatureValue(*group.Defaults.Temperature)); err != nil {
+				m.logger.Warn("failed to apply default temperature", "group", group.ID, "light", light.ID, "error", err)
+			}
+		}
+	}
+}
+
+// applyToGroupLights runs fn concurrently on every light in the group, at
+// most groups.max_concurrency at a time, collecting and returning any errors.
+// Under the fail-fast policy, lights not yet started when one fails are
+// skipped. Each light is changed under its version check if ctx has one for
+// the group, see WithVersionCheck, and its result is recorded if ctx has
+// Results, see WithResults.
 func (m *Manager) applyToGroupLights(ctx context.Context, groupID string, fn func(ctx context.Context, lightID string) error) error {
 	group, err := m.GetGroup(groupID)
 	if err != nil {
 		return err
 	}
-
-	errCh := make(chan error, len(group.Lights))
-	var wg sync.WaitGroup
+	policy := m.cfg.Config.Groups
+	limit := len(group.Lights)
+	if policy.MaxConcurrency > 0 {
+		limit = min(limit, policy.MaxConcurrency)
+	}
+	results := resultsFrom(ctx)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		skipped int
+	)
+	slots := make(chan struct{}, max(limit, 1))
 	for _, id := range group.Lights {
-		wg.Add(1)
-		go func(lightID string) {
-			defer wg.Done()
-			if err := fn(ctx, lightID); err != nil {
-				errCh <- fmt.Errorf("light %s: %w", lightID, err)
+		slots <- struct{}{}
+		mu.Lock()
+		skip := policy.FailFast() && len(errs) > 0
+		mu.Unlock()
+		if skip {
+			<-slots
+			skipped++
+			results.record(group.ID, id, ResultSkipped, nil)
+			continue
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			ctx, err := lightContext(ctx, group.ID, id)
+			if err == nil {
+				err = fn(ctx, id)
 			}
-		}(id)
+			switch {
+			case errors.Is(err, errUnsupported):
+				results.record(group.ID, id, ResultUnsupported, nil)
+			case err != nil:
+				results.record(group.ID, id, ResultFailed, err)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("light %s: %w", id, err))
+				mu.Unlock()
+			default:
+				results.record(group.ID, id, ResultOK, nil)
+			}
+		})
 	}
 	wg.Wait()
-	close(errCh)
 
-	var errs []error
-	for err := range errCh {
-		errs = append(errs, err)
-	}
-	if len(errs) > 0 {
+	switch {
+	case len(errs) > 0 && skipped > 0:
+		return fmt.Errorf("errors occurred: %v (%d lights skipped)", errs, skipped)
+	case len(errs) > 0:
 		return fmt.Errorf("errors occurred: %v", errs)
 	}
 	return nil
@@ -320,6 +583,25 @@ func (m *Manager) SetGroupState(ctx context.Context, groupID string, on bool) er
 	})
 }
 
+// ToggleGroup turns every light in a group off if any of them is on, and on
+// otherwise. Lights that cannot be reached are ignored when deciding. It
+// returns the power state that was applied.
+func (m *Manager) ToggleGroup(ctx context.Context, groupID string) (bool, error) {
+	group, err := m.GetGroup(groupID)
+	if err != nil {
+		return false, err
+	}
+	anyOn := false
+	for _, id := range group.Lights {
+		if light, err := m.lights.GetLight(ctx, id); err == nil && light.On {
+			anyOn = true
+			break
+		}
+	}
+	on := !anyOn
+	return on, m.SetGroupState(ctx, groupID, on)
+}
+
 // SetGroupBrightness sets the brightness for all lights in a group
 func (m *Manager) SetGroupBrightness(ctx context.Context, groupID string, brightness int) error {
 	return m.applyToGroupLights(ctx, groupID, func(ctx context.Context, lightID string) error {
@@ -327,13 +609,83 @@ func (m *Manager) SetGroupBrightness(ctx context.Context, groupID string, bright
 	})
 }
 
-// SetGroupTemperature sets the color temperature for all lights in a group
+// SetGroupTemperature sets the color temperature of the lights in a group
+// that support one; other lights are left unchanged.
 func (m *Manager) SetGroupTemperature(ctx context.Context, groupID string, temperature int) error {
-	return m.applyToGroupLights(ctx, groupID, func(ctx context.Context, lightID string) error {
+	return m.applyToSupportingLights(ctx, groupID, keylight.PropertyTemperature, func(ctx context.Context, lightID string) error {
 		return m.lights.SetLightTemperature(ctx, lightID, temperature)
 	})
 }
 
+// SetGroupColor sets the hue and/or saturation of the lights in a group that
+// support color; other lights are left unchanged.
+func (m *Manager) SetGroupColor(ctx context.Context, groupID string, hue, saturation *float64) error {
+	color, ok := keylight.ColorChange(hue, saturation)
+	if !ok {
+		return kerrors.InvalidInputf("missing hue or saturation")
+	}
+	return m.applyToSupportingLights(ctx, groupID, keylight.PropertyColor, func(ctx context.Context, lightID string) error {
+		return m.lights.SetLightState(ctx, lightID, color)
+	})
+}
+
+// applyToSupportingLights is applyToGroupLights for a property only some lights
+// support. Lights known not to support it are skipped, and it fails if that
+// leaves none.
+func (m *Manager) applyToSupportingLights(ctx context.Context, groupID string, property keylight.PropertyName, fn func(ctx context.Context, lightID string) error) error {
+	group, err := m.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+	lights := m.lights.GetLights()
+	unsupported := func(id string) bool {
+		light, ok := lights[id]
+		return ok && !light.Supports(property)
+	}
+	if len(group.Lights) > 0 && !slices.ContainsFunc(group.Lights, func(id string) bool { return !unsupported(id) }) {
+		return kerrors.InvalidInputf("no light in group %s supports %s", group.Name, property)
+	}
+	return m.applyToGroupLights(ctx, groupID, func(ctx context.Context, lightID string) error {
+		if unsupported(lightID) {
+			return errUnsupported
+		}
+		return fn(ctx, lightID)
+	})
+}
+
+// AdjustGroup changes the brightness and temperature of all lights in a group
+// relative to each light's current values. Lights without a color temperature
+// only have their brightness changed.
+func (m *Manager) AdjustGroup(ctx context.Context, groupID string, adj keylight.Adjustment) error {
+	lights := m.lights.GetLights()
+	return m.applyToGroupLights(ctx, groupID, func(ctx context.Context, lightID string) error {
+		adj := adj
+		if light, ok := lights[lightID]; ok && !light.Supports(keylight.PropertyTemperature) {
+			adj.Temperature = 0
+			if adj.IsZero() {
+				return errUnsupported
+			}
+		}
+		return m.lights.AdjustLight(ctx, lightID, adj)
+	})
+}
+
+// TransitionGroup ramps all lights in a group to the given state over
+// duration. Lights without a color temperature ignore the temperature.
+func (m *Manager) TransitionGroup(ctx context.Context, groupID string, change keylight.StateChange, duration time.Duration) error {
+	lights := m.lights.GetLights()
+	return m.applyToGroupLights(ctx, groupID, func(ctx context.Context, lightID string) error {
+		change := change
+		if light, ok := lights[lightID]; ok && !light.Supports(keylight.PropertyTemperature) {
+			change.Temperature = nil
+			if change.IsEmpty() {
+				return errUnsupported
+			}
+		}
+		return m.lights.Transition(ctx, lightID, change, duration)
+	})
+}
+
 // GetGroupsByName returns all groups with the given name
 func (m *Manager) GetGroupsByName(name string) []*Group {
 	m.mu.RLock()
@@ -349,6 +701,8 @@ func (m *Manager) GetGroupsByName(name string) []*Group {
 
 // GetGroupsByKeys returns all groups matching the given comma-separated list of IDs or names.
 // It matches by ID first, then by name (allowing multiple matches for names), and deduplicates results.
+// A key that matches no group by ID or name may be "all", matching every group, or a glob
+// pattern such as "office-*", matching the groups whose ID or name it matches.
 func (m *Manager) GetGroupsByKeys(keys string) ([]*Group, []string) {
 	keyList := strings.Split(keys, ",")
 	var matchedGroups []*Group
@@ -365,8 +719,11 @@ func (m *Manager) GetGroupsByKeys(keys string) ([]*Group, []string) {
 			}
 			continue
 		}
-		// Try by name (could be multiple)
+		// Try by name (could be multiple), then as "all" or a pattern
 		byName := m.GetGroupsByName(key)
+		if len(byName) == 0 {
+			byName = m.getGroupsByPattern(key)
+		}
 		if len(byName) > 0 {
 			for _, g := range byName {
 				if !groupSeen[g.ID] {
@@ -381,12 +738,126 @@ func (m *Manager) GetGroupsByKeys(keys string) ([]*Group, []string) {
 	return matchedGroups, notFound
 }
 
+// getGroupsByPattern returns the groups selected by "all" or a glob pattern,
+// sorted by ID, or nil if key is neither.
+func (m *Manager) getGroupsByPattern(key string) []*Group {
+	if key != keylight.TargetAll && !keylight.IsTargetPattern(key) {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*Group
+	for _, group := range m.groups {
+		if key == keylight.TargetAll || keylight.MatchTargetPattern(key, group.ID, group.Name) {
+			result = append(result, cloneGroup(group))
+		}
+	}
+	slices.SortFunc(result, func(a, b *Group) int { return strings.Compare(a.ID, b.ID) })
+	return result
+}
+
 func cloneGroup(group *Group) *Group {
 	lights := make([]string, len(group.Lights))
 	copy(lights, group.Lights)
 	return &Group{
-		ID:     group.ID,
-		Name:   group.Name,
-		Lights: lights,
+		ID:          group.ID,
+		Name:        group.Name,
+		Lights:      lights,
+		Defaults:    cloneDefaults(group.Defaults),
+		Description: group.Description,
+		Icon:        group.Icon,
+		Color:       group.Color,
+		LegacyID:    group.LegacyID,
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+}
+
+func cloneDefaults(d *Defaults) *Defaults {
+	if d == nil {
+		return nil
+	}
+	c := &Defaults{ApplyOnJoin: d.ApplyOnJoin}
+	if d.Brightness != nil {
+		v := *d.Brightness
+		c.Brightness = &v
+	}
+	if d.Temperature != nil {
+		v := *d.Temperature
+		c.Temperature = &v
+	}
+	return c
+}
+
+// defaultsToMap converts defaults to the map form stored in config state.
+func defaultsToMap(d *Defaults) map[string]any {
+	m := map[string]any{"apply_on_join": d.ApplyOnJoin}
+	if d.Brightness != nil {
+		m["brightness"] = *d.Brightness
+	}
+	if d.Temperature != nil {
+		m["temperature"] = *d.Temperature
+	}
+	return m
+}
+
+// defaultsFromMap parses defaults stored in config state.
+func defaultsFromMap(raw any) (*Defaults, error) {
+	data, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a map, got %T", raw)
+	}
+	d := &Defaults{}
+	if v, ok := data["apply_on_join"]; ok {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid apply_on_join %v", v)
+		}
+		d.ApplyOnJoin = b
+	}
+	for key, dst := range map[string]**int{"brightness": &d.Brightness, "temperature": &d.Temperature} {
+		v, ok := data[key]
+		if !ok || v == nil {
+			continue
+		}
+		n, ok := toInt(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %v", key, v)
+		}
+		*dst = &n
+	}
+	return d, nil
+}
+
+// timeFromState parses a time stored in config state, which is a time.Time or,
+// depending on how the state was read, an RFC 3339 string. Missing times are
+// zero.
+func timeFromState(raw any) (time.Time, error) {
+	switch t := raw.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return t.UTC(), nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		return parsed.UTC(), err
+	default:
+		return time.Time{}, fmt.Errorf("expected a time, got %T", raw)
+	}
+}
+
+// toInt converts a numeric value decoded from YAML or JSON to an int.
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	case float64:
+		return int(n), n == float64(int(n))
+	default:
+		return 0, false
 	}
 }
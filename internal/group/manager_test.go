@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/jmylchreest/keylightd/internal/config"
 	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/ids"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
@@ -32,13 +34,31 @@ func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Lig
 }
 
 func (m *mockLightManager) SetLightState(_ context.Context, id string, propertyValue keylight.LightPropertyValue) error {
-	_, exists := m.lights[id]
+	light, exists := m.lights[id]
 	if !exists {
 		return keylight.ErrLightNotFound
 	}
+	switch v := propertyValue.(type) {
+	case keylight.OnValue:
+		light.On = bool(v)
+	case keylight.TemperatureValue:
+		light.Temperature = int(v)
+	case keylight.ColorValue:
+		hue, saturation := float64(v.Hue), float64(v.Saturation)
+		light.Hue, light.Saturation = &hue, &saturation
+	}
 	return nil
 }
 
+func (m *mockLightManager) ToggleLight(_ context.Context, id string) (bool, error) {
+	light, exists := m.lights[id]
+	if !exists {
+		return false, keylight.ErrLightNotFound
+	}
+	light.On = !light.On
+	return light.On, nil
+}
+
 func (m *mockLightManager) SetLightBrightness(ctx context.Context, id string, brightness int) error {
 	return m.SetLightState(ctx, id, keylight.BrightnessValue(brightness))
 }
@@ -51,6 +71,16 @@ func (m *mockLightManager) SetLightPower(ctx context.Context, id string, on bool
 	return m.SetLightState(ctx, id, keylight.OnValue(on))
 }
 
+func (m *mockLightManager) AdjustLight(_ context.Context, id string, adj keylight.Adjustment) error {
+	light, exists := m.lights[id]
+	if !exists {
+		return keylight.ErrLightNotFound
+	}
+	light.Brightness += adj.Brightness
+	light.Temperature += adj.Temperature
+	return nil
+}
+
 func (m *mockLightManager) GetLights() map[string]*keylight.Light {
 	return m.lights
 }
@@ -192,6 +222,113 @@ func TestGroupOperations(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestToggleGroup(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"light1": {ID: "light1", On: true},
+			"light2": {ID: "light2", On: false},
+		},
+	}
+	manager := NewManager(logger, lights, setupTestConfig(t))
+
+	group, err := manager.CreateGroup(context.Background(), "desk", []string{"light1", "light2"})
+	require.NoError(t, err)
+
+	// Any light on turns the whole group off
+	on, err := manager.ToggleGroup(context.Background(), group.ID)
+	require.NoError(t, err)
+	assert.False(t, on)
+	assert.False(t, lights.lights["light1"].On)
+	assert.False(t, lights.lights["light2"].On)
+
+	on, err = manager.ToggleGroup(context.Background(), group.ID)
+	require.NoError(t, err)
+	assert.True(t, on)
+	assert.True(t, lights.lights["light1"].On)
+	assert.True(t, lights.lights["light2"].On)
+
+	_, err = manager.ToggleGroup(context.Background(), "non-existent")
+	assert.Error(t, err)
+}
+
+func TestAdjustGroup(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"light1": {ID: "light1", Brightness: 20, Temperature: 4000},
+			"light2": {ID: "light2", Brightness: 50, Temperature: 5000},
+		},
+	}
+	manager := NewManager(logger, lights, setupTestConfig(t))
+
+	group, err := manager.CreateGroup(context.Background(), "desk", []string{"light1", "light2"})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.AdjustGroup(context.Background(), group.ID, keylight.Adjustment{Brightness: 10, Temperature: -200}))
+	assert.Equal(t, 30, lights.lights["light1"].Brightness)
+	assert.Equal(t, 3800, lights.lights["light1"].Temperature)
+	assert.Equal(t, 60, lights.lights["light2"].Brightness)
+	assert.Equal(t, 4800, lights.lights["light2"].Temperature)
+
+	assert.Error(t, manager.AdjustGroup(context.Background(), "non-existent", keylight.Adjustment{Brightness: 10}))
+}
+
+func TestSetGroupColor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"strip": {ID: "strip", Capabilities: &keylight.Capabilities{Temperature: true, Color: true}},
+			"key":   {ID: "key"},
+		},
+	}
+	manager := NewManager(logger, lights, setupTestConfig(t))
+
+	group, err := manager.CreateGroup(context.Background(), "desk", []string{"strip", "key"})
+	require.NoError(t, err)
+	white, err := manager.CreateGroup(context.Background(), "white", []string{"key"})
+	require.NoError(t, err)
+
+	hue, saturation := 200.0, 80.0
+	require.NoError(t, manager.SetGroupColor(context.Background(), group.ID, &hue, &saturation))
+	require.NotNil(t, lights.lights["strip"].Hue)
+	assert.InDelta(t, 200, *lights.lights["strip"].Hue, 0.001)
+	assert.Nil(t, lights.lights["key"].Hue, "lights without color support are skipped")
+
+	err = manager.SetGroupColor(context.Background(), white.ID, &hue, nil)
+	assert.True(t, kerrors.IsInvalidInput(err))
+	err = manager.SetGroupColor(context.Background(), group.ID, nil, nil)
+	assert.True(t, kerrors.IsInvalidInput(err))
+}
+
+func TestSetGroupTemperature_SkipsUnsupported(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"rgb": {ID: "rgb", Brightness: 20, Temperature: 4000, Capabilities: &keylight.Capabilities{Color: true}},
+			"key": {ID: "key", Brightness: 20, Temperature: 4000},
+		},
+	}
+	manager := NewManager(logger, lights, setupTestConfig(t))
+
+	group, err := manager.CreateGroup(context.Background(), "desk", []string{"rgb", "key"})
+	require.NoError(t, err)
+	rgb, err := manager.CreateGroup(context.Background(), "rgb", []string{"rgb"})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.SetGroupTemperature(context.Background(), group.ID, 5000))
+	assert.Equal(t, 5000, lights.lights["key"].Temperature)
+	assert.Equal(t, 4000, lights.lights["rgb"].Temperature)
+
+	require.NoError(t, manager.AdjustGroup(context.Background(), group.ID, keylight.Adjustment{Brightness: 10, Temperature: -200}))
+	assert.Equal(t, 30, lights.lights["rgb"].Brightness)
+	assert.Equal(t, 4000, lights.lights["rgb"].Temperature)
+	assert.Equal(t, 4800, lights.lights["key"].Temperature)
+
+	err = manager.SetGroupTemperature(context.Background(), rgb.ID, 5000)
+	assert.True(t, kerrors.IsInvalidInput(err))
+}
+
 func TestGroupLightsJSONAlwaysArray(t *testing.T) {
 	cases := []struct {
 		name  string
@@ -252,6 +389,53 @@ func TestGetGroupReturnsCopy(t *testing.T) {
 	assert.Equal(t, []string{"light1"}, gotAgain.Lights)
 }
 
+func TestGroupIDsAndTimestamps(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"light1": {ID: "light1"},
+			"light2": {ID: "light2"},
+		},
+	}
+	cfg := setupTestConfig(t)
+	manager := NewManager(logger, lights, cfg)
+
+	created, err := manager.CreateGroup(context.Background(), "desk", []string{"light1"})
+	require.NoError(t, err)
+	assert.True(t, ids.Valid("group", created.ID))
+	createdAt, updatedAt := created.CreatedAt, created.UpdatedAt
+	assert.False(t, createdAt.IsZero())
+	assert.Equal(t, createdAt, updatedAt)
+
+	time.Sleep(time.Millisecond)
+	require.NoError(t, manager.SetGroupLights(context.Background(), created.ID, []string{"light1", "light2"}))
+	got, err := manager.GetGroup(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, createdAt, got.CreatedAt)
+	assert.True(t, got.UpdatedAt.After(updatedAt))
+
+	// Groups migrated from legacy IDs are still found by them
+	cfg.State.Groups = map[string]any{
+		created.ID: map[string]any{
+			"name":       "desk",
+			"lights":     []any{"light1"},
+			"legacy_id":  "group-1700000000000000000",
+			"created_at": "2023-11-14T22:13:20Z",
+			"updated_at": updatedAt,
+		},
+	}
+	reloaded := NewManager(logger, lights, cfg)
+	got, err = reloaded.GetGroup("group-1700000000000000000")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, got.ID)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), got.CreatedAt)
+	assert.True(t, updatedAt.Equal(got.UpdatedAt))
+	groups, _ := reloaded.GetGroupsByKeys("group-1700000000000000000")
+	assert.Len(t, groups, 1)
+	require.NoError(t, reloaded.DeleteGroup("group-1700000000000000000"))
+	assert.Empty(t, reloaded.GetGroups())
+}
+
 func TestGetGroupsReturnsCopies(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -320,4 +504,202 @@ func TestGetGroupsByKeys_MultiGroupAndByName(t *testing.T) {
 	assert.Contains(t, ids, g2.ID)
 	assert.Contains(t, ids, g3.ID)
 	assert.Equal(t, []string{"notfound"}, notFound)
+
+	// "all" and glob patterns
+	groups, notFound = manager.GetGroupsByKeys("all")
+	assert.Len(t, groups, 3)
+	assert.Empty(t, notFound)
+
+	groups, notFound = manager.GetGroupsByKeys("off*,stud?o")
+	assert.Len(t, groups, 3)
+	assert.Empty(t, notFound)
+
+	groups, notFound = manager.GetGroupsByKeys("lab-*,[")
+	assert.Empty(t, groups)
+	assert.Equal(t, []string{"lab-*", "["}, notFound)
+
+	// A group named "all" is matched by name
+	g4, err := manager.CreateGroup(context.Background(), "all", []string{"light2"})
+	require.NoError(t, err)
+	groups, _ = manager.GetGroupsByKeys("all")
+	require.Len(t, groups, 1)
+	assert.Equal(t, g4.ID, groups[0].ID)
+}
+
+type recordingLightManager struct {
+	mockLightManager
+	calls []keylight.LightPropertyValue
+}
+
+func (m *recordingLightManager) SetLightState(ctx context.Context, id string, propertyValue keylight.LightPropertyValue) error {
+	m.calls = append(m.calls, propertyValue)
+	return m.mockLightManager.SetLightState(ctx, id, propertyValue)
+}
+
+func TestSetGroupDefaults(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{"light1": {ID: "light1"}}}
+	cfg := setupTestConfig(t)
+	manager := NewManager(logger, lights, cfg)
+
+	group, err := manager.CreateGroup(context.Background(), "office", []string{"light1"})
+	require.NoError(t, err)
+
+	brightness, temperature := 40, 4500
+	require.NoError(t, manager.SetGroupDefaults(group.ID, &Defaults{Brightness: &brightness, Temperature: &temperature, ApplyOnJoin: true}))
+
+	got, err := manager.GetGroup(group.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Defaults)
+	assert.Equal(t, 40, *got.Defaults.Brightness)
+	assert.True(t, got.Defaults.ApplyOnJoin)
+
+	// Defaults survive a reload from disk
+	reloaded, err := config.Load("config", cfg.Viper().ConfigFileUsed())
+	require.NoError(t, err)
+	got, err = NewManager(logger, lights, reloaded).GetGroup(group.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Defaults)
+	assert.Equal(t, 40, *got.Defaults.Brightness)
+	assert.Equal(t, 4500, *got.Defaults.Temperature)
+	assert.True(t, got.Defaults.ApplyOnJoin)
+
+	// Out of range values are rejected
+	bad := 200
+	err = manager.SetGroupDefaults(group.ID, &Defaults{Brightness: &bad})
+	assert.True(t, kerrors.IsInvalidInput(err))
+
+	// Empty defaults clear them
+	require.NoError(t, manager.SetGroupDefaults(group.ID, &Defaults{ApplyOnJoin: true}))
+	got, err = manager.GetGroup(group.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Defaults)
+
+	err = manager.SetGroupDefaults("missing", nil)
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestUpdateGroup(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{"light1": {ID: "light1"}}}
+	cfg := setupTestConfig(t)
+	manager := NewManager(logger, lights, cfg)
+
+	group, err := manager.CreateGroup(context.Background(), "office", []string{"light1"})
+	require.NoError(t, err)
+	createdUpdatedAt := group.UpdatedAt
+
+	name, description, icon, color := " Office Lights ", "Desk and shelf", "video-display-symbolic", "#FF8800"
+	got, err := manager.UpdateGroup(group.ID, Update{Name: &name, Description: &description, Icon: &icon, Color: &color})
+	require.NoError(t, err)
+	assert.Equal(t, "Office Lights", got.Name)
+	assert.Equal(t, "Desk and shelf", got.Description)
+	assert.Equal(t, "video-display-symbolic", got.Icon)
+	assert.Equal(t, "#ff8800", got.Color)
+	assert.Equal(t, []string{"light1"}, got.Lights)
+	assert.False(t, got.UpdatedAt.Before(createdUpdatedAt))
+
+	// Metadata survives a reload from disk
+	reloaded, err := config.Load("config", cfg.Viper().ConfigFileUsed())
+	require.NoError(t, err)
+	got, err = NewManager(logger, lights, reloaded).GetGroup(group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Office Lights", got.Name)
+	assert.Equal(t, "Desk and shelf", got.Description)
+	assert.Equal(t, "video-display-symbolic", got.Icon)
+	assert.Equal(t, "#ff8800", got.Color)
+
+	// Fields left out are unchanged; empty ones are cleared
+	empty := ""
+	got, err = manager.UpdateGroup(group.ID, Update{Icon: &empty})
+	require.NoError(t, err)
+	assert.Empty(t, got.Icon)
+	assert.Equal(t, "Office Lights", got.Name)
+	assert.Equal(t, "#ff8800", got.Color)
+
+	for _, update := range []Update{
+		{},
+		{Name: &empty},
+		{Color: &name},
+	} {
+		_, err = manager.UpdateGroup(group.ID, update)
+		assert.True(t, kerrors.IsInvalidInput(err), "%+v", update)
+	}
+
+	_, err = manager.UpdateGroup("missing", Update{Name: &name})
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestImportGroups(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{"light1": {ID: "light1"}}}
+	cfg := setupTestConfig(t)
+	manager := NewManager(logger, lights, cfg)
+
+	existing, err := manager.CreateGroup(context.Background(), "office", []string{"light1"})
+	require.NoError(t, err)
+
+	// Invalid groups reject the whole import
+	bad := 200
+	err = manager.ImportGroups([]*Group{{Name: "ok"}, {Name: "bad", Defaults: &Defaults{Brightness: &bad}}})
+	assert.True(t, kerrors.IsInvalidInput(err))
+	err = manager.ImportGroups([]*Group{{ID: "group-x", Name: "  "}})
+	assert.True(t, kerrors.IsInvalidInput(err))
+	assert.Len(t, manager.GetGroups(), 1)
+
+	// Existing IDs are replaced, unknown lights are kept, and missing IDs are generated
+	require.NoError(t, manager.ImportGroups([]*Group{
+		{ID: existing.ID, Name: "studio", Lights: []string{"light1", "elsewhere"}},
+		{Name: "desk"},
+	}))
+	got, err := manager.GetGroup(existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "studio", got.Name)
+	assert.Equal(t, []string{"light1", "elsewhere"}, got.Lights)
+	desk := manager.GetGroupsByName("desk")
+	require.Len(t, desk, 1)
+	assert.True(t, ids.Valid("group", desk[0].ID))
+	assert.False(t, desk[0].CreatedAt.IsZero())
+
+	// Groups with legacy IDs are migrated, and replace their migrated group
+	require.NoError(t, manager.ImportGroups([]*Group{{ID: "group-1700000000000000000", Name: "shelf"}}))
+	shelf, err := manager.GetGroup("group-1700000000000000000")
+	require.NoError(t, err)
+	assert.True(t, ids.Valid("group", shelf.ID))
+	assert.Equal(t, "group-1700000000000000000", shelf.LegacyID)
+	require.NoError(t, manager.ImportGroups([]*Group{{ID: "group-1700000000000000000", Name: "bookshelf"}}))
+	got, err = manager.GetGroup(shelf.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bookshelf", got.Name)
+	require.NoError(t, manager.DeleteGroup(shelf.ID))
+
+	reloaded, err := config.Load("config", cfg.Viper().ConfigFileUsed())
+	require.NoError(t, err)
+	assert.Len(t, NewManager(logger, lights, reloaded).GetGroups(), 2)
+}
+
+func TestHandleLightAdded(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &recordingLightManager{mockLightManager: mockLightManager{lights: map[string]*keylight.Light{
+		"light1": {ID: "light1"},
+		"light2": {ID: "light2"},
+	}}}
+	cfg := setupTestConfig(t)
+	manager := NewManager(logger, lights, cfg)
+
+	brightness, temperature := 40, 4500
+	withDefaults, err := manager.CreateGroup(context.Background(), "office", []string{"light1"})
+	require.NoError(t, err)
+	require.NoError(t, manager.SetGroupDefaults(withDefaults.ID, &Defaults{Brightness: &brightness, Temperature: &temperature, ApplyOnJoin: true}))
+
+	noJoin, err := manager.CreateGroup(context.Background(), "studio", []string{"light2"})
+	require.NoError(t, err)
+	require.NoError(t, manager.SetGroupDefaults(noJoin.ID, &Defaults{Brightness: &brightness}))
+
+	manager.HandleLightAdded(context.Background(), keylight.Light{ID: "light1"})
+	assert.Equal(t, []keylight.LightPropertyValue{keylight.BrightnessValue(40), keylight.TemperatureValue(4500)}, lights.calls)
+
+	lights.calls = nil
+	manager.HandleLightAdded(context.Background(), keylight.Light{ID: "light2"})
+	assert.Empty(t, lights.calls, "defaults without apply_on_join must not be applied")
 }
@@ -0,0 +1,92 @@
+package group
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ResultStatus is what a group operation did to one of the group's lights.
+type ResultStatus string
+
+// Light result statuses, from least to most significant. A light changed by
+// several operations reports the most significant status among them.
+const (
+	ResultUnsupported ResultStatus = "unsupported" // The light doesn't support the property and was left unchanged
+	ResultOK          ResultStatus = "ok"
+	ResultSkipped     ResultStatus = "skipped" // Not attempted, as another light failed under the fail-fast policy
+	ResultFailed      ResultStatus = "failed"
+)
+
+var resultRank = map[ResultStatus]int{ResultUnsupported: 0, ResultOK: 1, ResultSkipped: 2, ResultFailed: 3}
+
+// LightResult is the result of a group operation for one of the group's lights.
+type LightResult struct {
+	Group  string       `json:"group"`
+	Light  string       `json:"light"`
+	Status ResultStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// errUnsupported is returned by the work of an operation for a light that
+// doesn't support what it changes.
+var errUnsupported = errors.New("unsupported")
+
+// Results collects a result for each light changed by the group operations
+// run under a context from WithResults.
+type Results struct {
+	mu     sync.Mutex
+	lights []LightResult
+}
+
+type resultsKey struct{}
+
+// WithResults returns a copy of ctx under which group operations record what
+// they did to each light in the returned Results.
+func WithResults(ctx context.Context) (context.Context, *Results) {
+	r := &Results{}
+	return context.WithValue(ctx, resultsKey{}, r), r
+}
+
+// resultsFrom returns the Results of ctx, or nil if it has none.
+func resultsFrom(ctx context.Context) *Results {
+	r, _ := ctx.Value(resultsKey{}).(*Results)
+	return r
+}
+
+// record records the result of an operation for a group's light, merging it
+// with any earlier result for the light. It does nothing on a nil Results.
+func (r *Results) record(groupID, lightID string, status ResultStatus, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.lights, func(l LightResult) bool { return l.Group == groupID && l.Light == lightID })
+	if i < 0 {
+		r.lights = append(r.lights, LightResult{Group: groupID, Light: lightID, Status: status})
+		i = len(r.lights) - 1
+	} else if resultRank[status] > resultRank[r.lights[i].Status] {
+		r.lights[i].Status = status
+	}
+	if err != nil {
+		if r.lights[i].Error != "" {
+			r.lights[i].Error += "; "
+		}
+		r.lights[i].Error += err.Error()
+	}
+}
+
+// Lights returns the result for each light, sorted by group and light ID.
+func (r *Results) Lights() []LightResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lights := append([]LightResult{}, r.lights...)
+	slices.SortFunc(lights, func(a, b LightResult) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Light, b.Light))
+	})
+	return lights
+}
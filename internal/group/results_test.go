@@ -0,0 +1,122 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// concurrencyLightManager fails requests to the light "bad" and records the
+// most requests it has seen in flight at once.
+type concurrencyLightManager struct {
+	mockLightManager
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (m *concurrencyLightManager) SetLightState(ctx context.Context, id string, propertyValue keylight.LightPropertyValue) error {
+	m.mu.Lock()
+	m.inFlight++
+	m.peak = max(m.peak, m.inFlight)
+	m.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	if id == "bad" {
+		return errors.New("unreachable")
+	}
+	return m.mockLightManager.SetLightState(ctx, id, propertyValue)
+}
+
+func newConcurrencyTest(t *testing.T, groups config.GroupsConfig, lightIDs ...string) (*Manager, *concurrencyLightManager, *Group) {
+	t.Helper()
+	lights := &concurrencyLightManager{mockLightManager: mockLightManager{lights: map[string]*keylight.Light{}}}
+	for _, id := range lightIDs {
+		lights.lights[id] = &keylight.Light{ID: id}
+	}
+	cfg := setupTestConfig(t)
+	cfg.Config.Groups = groups
+	manager := NewManager(slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil)), lights, cfg)
+	group, err := manager.CreateGroup(context.Background(), "desk", lightIDs)
+	require.NoError(t, err)
+	return manager, lights, group
+}
+
+func TestGroupMaxConcurrency(t *testing.T) {
+	manager, lights, group := newConcurrencyTest(t, config.GroupsConfig{MaxConcurrency: 2}, "a", "b", "c", "d", "e")
+
+	require.NoError(t, manager.SetGroupState(context.Background(), group.ID, true))
+	assert.Equal(t, 2, lights.peak)
+	for _, light := range lights.lights {
+		assert.True(t, light.On)
+	}
+}
+
+func TestGroupBestEffort(t *testing.T) {
+	manager, lights, group := newConcurrencyTest(t, config.GroupsConfig{MaxConcurrency: 1}, "bad", "a", "b")
+
+	ctx, results := WithResults(context.Background())
+	err := manager.SetGroupState(ctx, group.ID, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "light bad: unreachable")
+	assert.True(t, lights.lights["a"].On)
+	assert.True(t, lights.lights["b"].On)
+	assert.Equal(t, []LightResult{
+		{Group: group.ID, Light: "a", Status: ResultOK},
+		{Group: group.ID, Light: "b", Status: ResultOK},
+		{Group: group.ID, Light: "bad", Status: ResultFailed, Error: "unreachable"},
+	}, results.Lights())
+}
+
+func TestGroupFailFast(t *testing.T) {
+	manager, lights, group := newConcurrencyTest(t,
+		config.GroupsConfig{MaxConcurrency: 1, FailurePolicy: config.FailurePolicyFailFast}, "bad", "a", "b")
+
+	ctx, results := WithResults(context.Background())
+	err := manager.SetGroupState(ctx, group.ID, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "(2 lights skipped)")
+	assert.False(t, lights.lights["a"].On)
+	assert.False(t, lights.lights["b"].On)
+	assert.Equal(t, []LightResult{
+		{Group: group.ID, Light: "a", Status: ResultSkipped},
+		{Group: group.ID, Light: "b", Status: ResultSkipped},
+		{Group: group.ID, Light: "bad", Status: ResultFailed, Error: "unreachable"},
+	}, results.Lights())
+}
+
+func TestResultsMerged(t *testing.T) {
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"rgb": {ID: "rgb", Capabilities: &keylight.Capabilities{Color: true}},
+			"key": {ID: "key"},
+		},
+	}
+	manager := NewManager(slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil)), lights, setupTestConfig(t))
+	group, err := manager.CreateGroup(context.Background(), "desk", []string{"rgb", "key"})
+	require.NoError(t, err)
+
+	ctx, results := WithResults(context.Background())
+	require.NoError(t, manager.SetGroupTemperature(ctx, group.ID, 5000))
+	assert.Equal(t, []LightResult{
+		{Group: group.ID, Light: "key", Status: ResultOK},
+		{Group: group.ID, Light: "rgb", Status: ResultUnsupported},
+	}, results.Lights())
+
+	require.NoError(t, manager.SetGroupState(ctx, group.ID, true))
+	assert.Equal(t, ResultOK, results.Lights()[1].Status, "a change the light supports outranks one it doesn't")
+}
@@ -0,0 +1,67 @@
+package group
+
+import (
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Summary is the aggregate state of a group's lights, so a UI can show a
+// group without fetching each of its lights. It is computed from the lights'
+// cached states, so no light is contacted.
+//
+// Only reachable lights count towards the aggregate values: lights that are
+// offline, or that haven't been discovered, are listed in Unreachable.
+type Summary struct {
+	ID          string
+	Name        string
+	Lights      int      // Number of lights in the group
+	On          int      // Number of reachable lights that are on
+	AllOn       bool     // Whether every reachable light is on; false if none is reachable
+	AnyOn       bool     // Whether any reachable light is on
+	Brightness  int      // Average brightness of the reachable lights; 0 if none is reachable
+	MinKelvin   int      // Lowest color temperature of the reachable lights, in Kelvin; 0 if none has one
+	MaxKelvin   int      // Highest color temperature of the reachable lights, in Kelvin; 0 if none has one
+	Unreachable []string // Lights that are offline or not discovered, in group order
+	Version     uint64   // State version of the group, see WithVersionCheck
+}
+
+// Summary returns the aggregate state of a group's lights.
+func (m *Manager) Summary(id string) (Summary, error) {
+	group, err := m.GetGroup(id)
+	if err != nil {
+		return Summary{}, err
+	}
+	return summarize(group, m.lights.GetLights()), nil
+}
+
+// summarize computes the summary of a group from the states of lights.
+func summarize(group *Group, lights map[string]*keylight.Light) Summary {
+	s := Summary{ID: group.ID, Name: group.Name, Lights: len(group.Lights), Unreachable: []string{}, Version: groupVersion(group, lights)}
+	var brightness, reachable int
+	for _, id := range group.Lights {
+		light, ok := lights[id]
+		if !ok || light.Status == keylight.ReachabilityOffline {
+			s.Unreachable = append(s.Unreachable, id)
+			continue
+		}
+		reachable++
+		brightness += light.Brightness
+		if light.On {
+			s.On++
+		}
+		if light.Supports(keylight.PropertyTemperature) && light.Temperature > 0 {
+			kelvin := keylight.ConvertDeviceToTemperature(light.Temperature)
+			if s.MinKelvin == 0 || kelvin < s.MinKelvin {
+				s.MinKelvin = kelvin
+			}
+			if kelvin > s.MaxKelvin {
+				s.MaxKelvin = kelvin
+			}
+		}
+	}
+	if reachable > 0 {
+		s.Brightness = brightness / reachable
+		s.AllOn = s.On == reachable
+		s.AnyOn = s.On > 0
+	}
+	return s
+}
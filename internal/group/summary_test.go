@@ -0,0 +1,55 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+func TestSummary(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"light1":  {ID: "light1", On: true, Brightness: 40, Temperature: 250},                                 // 4000K
+			"light2":  {ID: "light2", Brightness: 80, Temperature: 200},                                           // 5000K
+			"light3":  {ID: "light3", On: true, Brightness: 100, Status: keylight.ReachabilityOffline},            // not counted
+			"strip":   {ID: "strip", On: true, Brightness: 60, Capabilities: &keylight.Capabilities{Color: true}}, // no temperature
+			"missing": {ID: "missing"},
+		},
+	}
+	manager := NewManager(logger, lights, setupTestConfig(t))
+	grp, err := manager.CreateGroup(context.Background(), "desk", []string{"light1", "light2", "light3", "strip", "missing"})
+	require.NoError(t, err)
+	delete(lights.lights, "missing") // forgotten since joining the group
+
+	s, err := manager.Summary(grp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, grp.ID, s.ID)
+	assert.Equal(t, "desk", s.Name)
+	assert.Equal(t, 5, s.Lights)
+	assert.Equal(t, 2, s.On)
+	assert.True(t, s.AnyOn)
+	assert.False(t, s.AllOn)
+	assert.Equal(t, 60, s.Brightness)
+	assert.Equal(t, 4000, s.MinKelvin)
+	assert.Equal(t, 5000, s.MaxKelvin)
+	assert.Equal(t, []string{"light3", "missing"}, s.Unreachable)
+
+	_, err = manager.Summary("nope")
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestSummary_NoReachableLights(t *testing.T) {
+	s := summarize(&Group{ID: "g1", Name: "empty", Lights: []string{"gone"}}, map[string]*keylight.Light{})
+	assert.False(t, s.AllOn, "a group with no reachable lights isn't all on")
+	assert.False(t, s.AnyOn)
+	assert.Zero(t, s.Brightness)
+	assert.Equal(t, []string{"gone"}, s.Unreachable)
+}
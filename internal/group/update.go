@@ -0,0 +1,102 @@
+package group
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// Limits on a group's descriptive fields, so they stay usable as labels in UIs.
+const (
+	MaxDescriptionLength = 256
+	MaxIconLength        = 64
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Update changes a group's name and descriptive fields. Nil fields are left as
+// they are; an empty description, icon or color clears it.
+type Update struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Icon        *string `json:"icon,omitempty"`
+	Color       *string `json:"color,omitempty"` // #rrggbb
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u Update) IsEmpty() bool {
+	return u.Name == nil && u.Description == nil && u.Icon == nil && u.Color == nil
+}
+
+// Validate checks the update's values.
+func (u Update) Validate() error {
+	if u.IsEmpty() {
+		return kerrors.InvalidInputf("nothing to change")
+	}
+	if u.Name != nil && strings.TrimSpace(*u.Name) == "" {
+		return kerrors.InvalidInputf("group name cannot be empty")
+	}
+	if u.Description != nil && utf8.RuneCountInString(*u.Description) > MaxDescriptionLength {
+		return kerrors.InvalidInputf("description is longer than %d characters", MaxDescriptionLength)
+	}
+	if u.Icon != nil && utf8.RuneCountInString(*u.Icon) > MaxIconLength {
+		return kerrors.InvalidInputf("icon is longer than %d characters", MaxIconLength)
+	}
+	if u.Color != nil && *u.Color != "" && !colorPattern.MatchString(*u.Color) {
+		return kerrors.InvalidInputf("color must be a hex color such as #ff8800, got %q", *u.Color)
+	}
+	return nil
+}
+
+// apply changes group g with the update.
+func (u Update) apply(g *Group) {
+	if u.Name != nil {
+		g.Name = strings.TrimSpace(*u.Name)
+	}
+	if u.Description != nil {
+		g.Description = strings.TrimSpace(*u.Description)
+	}
+	if u.Icon != nil {
+		g.Icon = strings.TrimSpace(*u.Icon)
+	}
+	if u.Color != nil {
+		g.Color = strings.ToLower(*u.Color)
+	}
+}
+
+// UpdateGroup renames a group or changes its description, icon or color, and
+// returns the group as updated.
+func (m *Manager) UpdateGroup(id string, update Update) (*Group, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	group, exists := m.lookupLocked(id)
+	if !exists {
+		m.mu.Unlock()
+		return nil, kerrors.NotFoundf("group %s not found", id)
+	}
+
+	old := *group
+	update.apply(group)
+	group.UpdatedAt = time.Now().UTC()
+	groupCopy := cloneGroup(group)
+	m.logger.Info("updated group", "id", group.ID, "name", group.Name)
+
+	if err := m.saveGroupsLocked(); err != nil {
+		*group = old
+		m.mu.Unlock()
+		m.logger.Error("failed to save groups, rolled back group update", "error", err)
+		return nil, fmt.Errorf("failed to persist group update: %w", err)
+	}
+	m.mu.Unlock()
+
+	m.emit(events.GroupUpdated, groupCopy)
+	return cloneGroup(groupCopy), nil
+}
@@ -0,0 +1,77 @@
+package group
+
+import (
+	"context"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// A group's state version is the latest state version of any of its lights.
+// As light versions come from one counter, it changes whenever any of the
+// group's lights changes. See keylight.VersionCheck.
+
+// groupVersion returns the state version of group given the lights' states.
+// Lights that haven't been discovered don't count.
+func groupVersion(group *Group, lights map[string]*keylight.Light) uint64 {
+	var version uint64
+	for _, id := range group.Lights {
+		if light, ok := lights[id]; ok {
+			version = max(version, light.Version)
+		}
+	}
+	return version
+}
+
+// versionCheck is a group's version check: one check for each of its lights
+// at the versions they had when the group's version was checked.
+type versionCheck struct {
+	group  string
+	lights map[string]*keylight.VersionCheck
+}
+
+type versionCheckKey struct{}
+
+// WithVersionCheck returns a copy of ctx under which changes to a group's
+// lights are only made if nothing changed the group since it was at version.
+// It fails with ErrConflict if the group isn't at version now.
+//
+// Each of the group's lights is then checked against the version it had when
+// the group was checked, as it is changed, so a light changed in the meantime
+// fails with ErrConflict on its own. So does a light that joined the group
+// since.
+func (m *Manager) WithVersionCheck(ctx context.Context, id string, version uint64) (context.Context, error) {
+	group, err := m.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	lights := m.lights.GetLights()
+	if current := groupVersion(group, lights); current != version {
+		return nil, kerrors.Conflictf("group %s is at state version %d, not %d", group.ID, current, version)
+	}
+
+	check := &versionCheck{group: group.ID, lights: make(map[string]*keylight.VersionCheck, len(group.Lights))}
+	for _, lightID := range group.Lights {
+		var v uint64
+		if light, ok := lights[lightID]; ok {
+			v = light.Version
+		}
+		check.lights[lightID] = keylight.ExpectVersion(v)
+	}
+	return context.WithValue(ctx, versionCheckKey{}, check), nil
+}
+
+// lightContext returns the context to change one of a group's lights under:
+// ctx with the light's version check, if ctx has a version check for the
+// group.
+func lightContext(ctx context.Context, groupID, lightID string) (context.Context, error) {
+	check, _ := ctx.Value(versionCheckKey{}).(*versionCheck)
+	if check == nil || check.group != groupID {
+		return ctx, nil
+	}
+	lightCheck, ok := check.lights[lightID]
+	if !ok {
+		return nil, kerrors.Conflictf("light %s joined group %s after its state version was read", lightID, groupID)
+	}
+	return keylight.WithVersionCheck(ctx, lightCheck), nil
+}
@@ -0,0 +1,50 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+func TestWithVersionCheck(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{
+		lights: map[string]*keylight.Light{
+			"light1": {ID: "light1", Version: 5},
+			"light2": {ID: "light2", Version: 7},
+			"light3": {ID: "light3", Version: 3},
+		},
+	}
+	manager := NewManager(logger, lights, setupTestConfig(t))
+	ctx := context.Background()
+	grp, err := manager.CreateGroup(ctx, "desk", []string{"light1", "light2"})
+	require.NoError(t, err)
+
+	s, err := manager.Summary(grp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), s.Version, "a group's version is its lights' latest")
+
+	_, err = manager.WithVersionCheck(ctx, grp.ID, 5)
+	assert.True(t, kerrors.IsConflict(err))
+	_, err = manager.WithVersionCheck(ctx, "nope", 7)
+	assert.True(t, kerrors.IsNotFound(err))
+
+	checked, err := manager.WithVersionCheck(ctx, grp.ID, 7)
+	require.NoError(t, err)
+	require.NoError(t, manager.SetGroupState(checked, grp.ID, true))
+
+	// A light that joined since the check isn't changed under it
+	require.NoError(t, manager.SetGroupLights(ctx, grp.ID, []string{"light1", "light2", "light3"}))
+	lights.lights["light3"].On = true
+	err = manager.SetGroupState(checked, grp.ID, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "light3 joined group")
+	assert.True(t, lights.lights["light3"].On)
+}
@@ -0,0 +1,107 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/jmylchreest/keylightd/internal/apikey"
+	"github.com/jmylchreest/keylightd/internal/http/mw"
+	keylightdv1 "github.com/jmylchreest/keylightd/pkg/proto/keylightd/v1"
+)
+
+// methodScopes is the scope each RPC needs, matching the scopes of the
+// equivalent HTTP routes. RPCs not listed, including every APIKeyService
+// call, need mw.ScopeWrite.
+var methodScopes = map[string]string{
+	keylightdv1.LightService_ListLights_FullMethodName:    mw.ScopeRead,
+	keylightdv1.LightService_GetLight_FullMethodName:      mw.ScopeRead,
+	keylightdv1.LightService_SetLightState_FullMethodName: mw.ScopeControl,
+	keylightdv1.GroupService_ListGroups_FullMethodName:    mw.ScopeRead,
+	keylightdv1.GroupService_GetGroup_FullMethodName:      mw.ScopeRead,
+	keylightdv1.GroupService_SetGroupState_FullMethodName: mw.ScopeControl,
+	keylightdv1.EventService_StreamEvents_FullMethodName:  mw.ScopeRead,
+}
+
+// methodScope returns the scope needed to call method.
+func methodScope(method string) string {
+	if scope, ok := methodScopes[method]; ok {
+		return scope
+	}
+	return mw.ScopeWrite
+}
+
+// AuthOptions returns server options that require an API key on every call,
+// sent like in the HTTP API as "authorization: Bearer <key>" or "x-api-key"
+// metadata. They are used for TCP listeners; callers on the Unix socket are
+// trusted through the socket's file permissions.
+func AuthOptions(logger *slog.Logger, keys *apikey.Manager) []grpc.ServerOption {
+	a := &authenticator{logger: logger, keys: keys}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := a.authenticate(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := a.authenticate(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+type authenticator struct {
+	logger *slog.Logger
+	keys   *apikey.Manager
+}
+
+func (a *authenticator) authenticate(ctx context.Context, method string) error {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	key := apiKeyFromMetadata(ctx)
+	if key == "" {
+		a.logger.Warn("API key missing", "method", method, "remote_addr", remoteAddr)
+		return status.Error(codes.Unauthenticated, "API key required")
+	}
+	validKey, err := a.keys.ValidateAPIKey(key)
+	if err != nil {
+		a.logger.Warn("Invalid API key used", "error", err, "method", method, "remote_addr", remoteAddr)
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if len(validKey.Scopes) > 0 && !mw.HasScope(validKey.Scopes, methodScope(method)) {
+		a.logger.Warn("API key lacks required scope", "name", validKey.Name, "method", method, "remote_addr", remoteAddr)
+		return status.Error(codes.PermissionDenied, "API key lacks the required scope")
+	}
+	a.logger.Debug("Authenticated API key", "name", validKey.Name, "method", method)
+	return nil
+}
+
+// apiKeyFromMetadata returns the API key sent with a call, if any.
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	const bearerPrefix = "Bearer "
+	for _, v := range md.Get("authorization") {
+		if strings.HasPrefix(v, bearerPrefix) {
+			return v[len(bearerPrefix):]
+		}
+	}
+	if v := md.Get("x-api-key"); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
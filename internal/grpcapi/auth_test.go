@@ -0,0 +1,59 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/jmylchreest/keylightd/internal/apikey"
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/http/mw"
+	keylightdv1 "github.com/jmylchreest/keylightd/pkg/proto/keylightd/v1"
+)
+
+func TestAuthenticate_Scopes(t *testing.T) {
+	cfg, err := config.Load("config.yaml", filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	logger := slog.New(slog.DiscardHandler)
+	keys := apikey.NewManager(cfg, logger)
+	a := &authenticator{logger: logger, keys: keys}
+
+	keyCtx := func(name string, scopes ...string) context.Context {
+		t.Helper()
+		key, err := keys.CreateScopedAPIKey(name, 0, scopes)
+		require.NoError(t, err)
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key.Key))
+	}
+	readOnly := keyCtx("read", mw.ScopeRead)
+	readControl := keyCtx("ui-tray", mw.ScopeRead, mw.ScopeControl)
+	unscoped := keyCtx("admin")
+
+	writeMethods := []string{
+		keylightdv1.APIKeyService_ListAPIKeys_FullMethodName,
+		keylightdv1.APIKeyService_CreateAPIKey_FullMethodName,
+		keylightdv1.APIKeyService_DeleteAPIKey_FullMethodName,
+		keylightdv1.APIKeyService_SetAPIKeyDisabled_FullMethodName,
+		keylightdv1.GroupService_CreateGroup_FullMethodName,
+		keylightdv1.GroupService_DeleteGroup_FullMethodName,
+		keylightdv1.GroupService_SetGroupLights_FullMethodName,
+		"/keylightd.v1.Unknown/Method",
+	}
+	for _, method := range writeMethods {
+		assert.Equal(t, codes.PermissionDenied, status.Code(a.authenticate(readOnly, method)), method)
+		assert.Equal(t, codes.PermissionDenied, status.Code(a.authenticate(readControl, method)), method)
+		assert.NoError(t, a.authenticate(unscoped, method), method)
+	}
+
+	assert.NoError(t, a.authenticate(readOnly, keylightdv1.LightService_ListLights_FullMethodName))
+	assert.NoError(t, a.authenticate(readOnly, keylightdv1.EventService_StreamEvents_FullMethodName))
+	assert.Equal(t, codes.PermissionDenied, status.Code(a.authenticate(readOnly, keylightdv1.LightService_SetLightState_FullMethodName)))
+	assert.NoError(t, a.authenticate(readControl, keylightdv1.LightService_SetLightState_FullMethodName))
+	assert.NoError(t, a.authenticate(readControl, keylightdv1.GroupService_SetGroupState_FullMethodName))
+}
@@ -0,0 +1,115 @@
+// Package grpcapi implements the keylightd gRPC API defined in
+// pkg/proto/keylightd/v1, on top of the same managers as the socket and
+// HTTP APIs.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/jmylchreest/keylightd/internal/apikey"
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+	keylightdv1 "github.com/jmylchreest/keylightd/pkg/proto/keylightd/v1"
+)
+
+// Services holds the managers the gRPC services are implemented on.
+type Services struct {
+	Lights  keylight.LightManager
+	Groups  *group.Manager
+	APIKeys *apikey.Manager
+	Events  *events.Bus
+}
+
+// NewServer returns a gRPC server with all keylightd services registered.
+func NewServer(logger *slog.Logger, svc Services, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	keylightdv1.RegisterLightServiceServer(s, &lightService{lights: svc.Lights})
+	keylightdv1.RegisterGroupServiceServer(s, &groupService{groups: svc.Groups})
+	keylightdv1.RegisterAPIKeyServiceServer(s, &apiKeyService{keys: svc.APIKeys})
+	keylightdv1.RegisterEventServiceServer(s, &eventService{logger: logger, bus: svc.Events})
+	return s
+}
+
+// statusError converts an error from the managers into a gRPC status error.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case kerrors.IsNotFound(err), errors.Is(err, keylight.ErrLightNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case kerrors.IsInvalidInput(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case kerrors.IsDeviceUnavailable(err):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// timestamp converts a time to a protobuf timestamp, leaving zero times unset.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func lightToProto(l *keylight.Light) *keylightdv1.Light {
+	light := &keylightdv1.Light{
+		Id:              l.ID,
+		Name:            l.Name,
+		Port:            int32(l.Port), //nolint:gosec // G115: ports fit in int32
+		Driver:          l.Driver,
+		Static:          l.Static,
+		On:              l.On,
+		Brightness:      int32(l.Brightness),  //nolint:gosec // G115: percentage
+		Temperature:     int32(l.Temperature), //nolint:gosec // G115: mireds
+		ProductName:     l.ProductName,
+		SerialNumber:    l.SerialNumber,
+		FirmwareVersion: l.FirmwareVersion,
+		FirmwareBuild:   int32(l.FirmwareBuild), //nolint:gosec // G115: build numbers fit in int32
+		LastSeen:        timestamp(l.LastSeen),
+		Status:          string(l.Status),
+	}
+	if l.IP != nil {
+		light.Ip = l.IP.String()
+	}
+	if l.Temperature > 0 {
+		light.TemperatureKelvin = int32(keylight.ConvertDeviceToTemperature(l.Temperature)) //nolint:gosec // G115: Kelvin
+	}
+	return light
+}
+
+func groupToProto(g *group.Group) *keylightdv1.Group {
+	return &keylightdv1.Group{Id: g.ID, Name: g.Name, LightIds: g.Lights}
+}
+
+func apiKeyToProto(k *config.APIKey) *keylightdv1.APIKey {
+	return &keylightdv1.APIKey{
+		Name:       k.Name,
+		Key:        k.Key,
+		CreatedAt:  timestamp(k.CreatedAt),
+		ExpiresAt:  timestamp(k.ExpiresAt),
+		LastUsedAt: timestamp(k.LastUsedAt),
+		Disabled:   k.IsDisabled(),
+	}
+}
@@ -0,0 +1,47 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{kerrors.NotFoundf("group %s not found", "g"), codes.NotFound},
+		{fmt.Errorf("light not found: %w", keylight.ErrLightNotFound), codes.NotFound},
+		{kerrors.InvalidInputf("brightness out of range"), codes.InvalidArgument},
+		{keylight.ErrCircuitOpen, codes.Unavailable},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{assert.AnError, codes.Internal},
+		{status.Error(codes.PermissionDenied, "no"), codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, status.Code(statusError(tt.err)), tt.err.Error())
+	}
+	assert.NoError(t, statusError(nil))
+}
+
+func TestAPIKeyFromMetadata(t *testing.T) {
+	assert.Empty(t, apiKeyFromMetadata(context.Background()))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret"))
+	assert.Equal(t, "secret", apiKeyFromMetadata(ctx))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "other"))
+	assert.Equal(t, "other", apiKeyFromMetadata(ctx))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic abc"))
+	assert.Empty(t, apiKeyFromMetadata(ctx))
+}
@@ -0,0 +1,252 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jmylchreest/keylightd/internal/apikey"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+	keylightdv1 "github.com/jmylchreest/keylightd/pkg/proto/keylightd/v1"
+)
+
+// eventBufferSize is the number of events buffered for a slow stream before
+// further events are dropped.
+const eventBufferSize = 64
+
+type lightService struct {
+	keylightdv1.UnimplementedLightServiceServer
+	lights keylight.LightManager
+}
+
+func (s *lightService) ListLights(ctx context.Context, _ *keylightdv1.ListLightsRequest) (*keylightdv1.ListLightsResponse, error) {
+	lights := s.lights.RefreshLights(ctx)
+	resp := &keylightdv1.ListLightsResponse{Lights: make([]*keylightdv1.Light, 0, len(lights))}
+	for _, light := range lights {
+		resp.Lights = append(resp.Lights, lightToProto(light))
+	}
+	sort.Slice(resp.Lights, func(i, j int) bool { return resp.Lights[i].Id < resp.Lights[j].Id })
+	return resp, nil
+}
+
+func (s *lightService) GetLight(ctx context.Context, req *keylightdv1.GetLightRequest) (*keylightdv1.GetLightResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing light ID")
+	}
+	light, err := s.lights.GetLight(ctx, req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.GetLightResponse{Light: lightToProto(light)}, nil
+}
+
+func (s *lightService) SetLightState(ctx context.Context, req *keylightdv1.SetLightStateRequest) (*keylightdv1.SetLightStateResponse, error) {
+	id := req.GetId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing light ID")
+	}
+	if req.On == nil && req.Brightness == nil && req.TemperatureKelvin == nil {
+		return nil, status.Error(codes.InvalidArgument, "at least one of on, brightness or temperature_kelvin is required")
+	}
+	if req.On != nil {
+		if err := s.lights.SetLightPower(ctx, id, req.GetOn()); err != nil {
+			return nil, statusError(err)
+		}
+	}
+	if req.Brightness != nil {
+		if err := s.lights.SetLightBrightness(ctx, id, int(req.GetBrightness())); err != nil {
+			return nil, statusError(err)
+		}
+	}
+	if req.TemperatureKelvin != nil {
+		if err := s.lights.SetLightTemperature(ctx, id, int(req.GetTemperatureKelvin())); err != nil {
+			return nil, statusError(err)
+		}
+	}
+	light, err := s.lights.GetLight(ctx, id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.SetLightStateResponse{Light: lightToProto(light)}, nil
+}
+
+type groupService struct {
+	keylightdv1.UnimplementedGroupServiceServer
+	groups *group.Manager
+}
+
+func (s *groupService) ListGroups(_ context.Context, _ *keylightdv1.ListGroupsRequest) (*keylightdv1.ListGroupsResponse, error) {
+	groups := s.groups.GetGroups()
+	resp := &keylightdv1.ListGroupsResponse{Groups: make([]*keylightdv1.Group, 0, len(groups))}
+	for _, g := range groups {
+		resp.Groups = append(resp.Groups, groupToProto(g))
+	}
+	sort.Slice(resp.Groups, func(i, j int) bool { return resp.Groups[i].Id < resp.Groups[j].Id })
+	return resp, nil
+}
+
+func (s *groupService) GetGroup(_ context.Context, req *keylightdv1.GetGroupRequest) (*keylightdv1.GetGroupResponse, error) {
+	g, err := s.groups.GetGroup(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.GetGroupResponse{Group: groupToProto(g)}, nil
+}
+
+func (s *groupService) CreateGroup(ctx context.Context, req *keylightdv1.CreateGroupRequest) (*keylightdv1.CreateGroupResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing group name")
+	}
+	g, err := s.groups.CreateGroup(ctx, req.GetName(), req.GetLightIds())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.CreateGroupResponse{Group: groupToProto(g)}, nil
+}
+
+func (s *groupService) DeleteGroup(_ context.Context, req *keylightdv1.DeleteGroupRequest) (*keylightdv1.DeleteGroupResponse, error) {
+	if err := s.groups.DeleteGroup(req.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.DeleteGroupResponse{}, nil
+}
+
+func (s *groupService) SetGroupLights(ctx context.Context, req *keylightdv1.SetGroupLightsRequest) (*keylightdv1.SetGroupLightsResponse, error) {
+	if err := s.groups.SetGroupLights(ctx, req.GetId(), req.GetLightIds()); err != nil {
+		return nil, statusError(err)
+	}
+	g, err := s.groups.GetGroup(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.SetGroupLightsResponse{Group: groupToProto(g)}, nil
+}
+
+// SetGroupState applies each field that is set to every light in the group.
+// Failures for individual lights are reported in the response rather than
+// failing the call, like the 207 response of the HTTP API.
+func (s *groupService) SetGroupState(ctx context.Context, req *keylightdv1.SetGroupStateRequest) (*keylightdv1.SetGroupStateResponse, error) {
+	if req.On == nil && req.Brightness == nil && req.TemperatureKelvin == nil {
+		return nil, status.Error(codes.InvalidArgument, "at least one of on, brightness or temperature_kelvin is required")
+	}
+	if _, err := s.groups.GetGroup(req.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &keylightdv1.SetGroupStateResponse{}
+	apply := func(property string, err error) {
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", property, err))
+		}
+	}
+	if req.On != nil {
+		apply("on", s.groups.SetGroupState(ctx, req.GetId(), req.GetOn()))
+	}
+	if req.Brightness != nil {
+		apply("brightness", s.groups.SetGroupBrightness(ctx, req.GetId(), int(req.GetBrightness())))
+	}
+	if req.TemperatureKelvin != nil {
+		apply("temperature", s.groups.SetGroupTemperature(ctx, req.GetId(), int(req.GetTemperatureKelvin())))
+	}
+	return resp, nil
+}
+
+type apiKeyService struct {
+	keylightdv1.UnimplementedAPIKeyServiceServer
+	keys *apikey.Manager
+}
+
+func (s *apiKeyService) ListAPIKeys(_ context.Context, _ *keylightdv1.ListAPIKeysRequest) (*keylightdv1.ListAPIKeysResponse, error) {
+	keys := s.keys.ListAPIKeys()
+	resp := &keylightdv1.ListAPIKeysResponse{Keys: make([]*keylightdv1.APIKey, 0, len(keys))}
+	for i := range keys {
+		resp.Keys = append(resp.Keys, apiKeyToProto(&keys[i]))
+	}
+	return resp, nil
+}
+
+func (s *apiKeyService) CreateAPIKey(_ context.Context, req *keylightdv1.CreateAPIKeyRequest) (*keylightdv1.CreateAPIKeyResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing API key name")
+	}
+	expiresIn := req.GetExpiresIn().AsDuration()
+	if expiresIn < 0 {
+		return nil, status.Error(codes.InvalidArgument, "expires_in must not be negative")
+	}
+	key, err := s.keys.CreateAPIKey(req.GetName(), expiresIn)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.CreateAPIKeyResponse{Key: apiKeyToProto(key)}, nil
+}
+
+func (s *apiKeyService) DeleteAPIKey(_ context.Context, req *keylightdv1.DeleteAPIKeyRequest) (*keylightdv1.DeleteAPIKeyResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing API key")
+	}
+	if err := s.keys.DeleteAPIKey(req.GetKey()); err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.DeleteAPIKeyResponse{}, nil
+}
+
+func (s *apiKeyService) SetAPIKeyDisabled(_ context.Context, req *keylightdv1.SetAPIKeyDisabledRequest) (*keylightdv1.SetAPIKeyDisabledResponse, error) {
+	if req.GetKeyOrName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing key_or_name")
+	}
+	key, err := s.keys.SetAPIKeyDisabledStatus(req.GetKeyOrName(), req.GetDisabled())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &keylightdv1.SetAPIKeyDisabledResponse{Key: apiKeyToProto(key)}, nil
+}
+
+type eventService struct {
+	keylightdv1.UnimplementedEventServiceServer
+	logger *slog.Logger
+	bus    *events.Bus
+}
+
+// StreamEvents sends events until the client cancels the stream or the
+// server stops. Events are dropped rather than blocking publishers when the
+// client falls behind.
+func (s *eventService) StreamEvents(_ *keylightdv1.StreamEventsRequest, stream grpc.ServerStreamingServer[keylightdv1.Event]) error {
+	eventCh := make(chan events.Event, eventBufferSize)
+	unsub := s.bus.Subscribe(func(e events.Event) {
+		select {
+		case eventCh <- e:
+		default:
+			s.logger.Warn("grpc events: client buffer full, dropping event")
+		}
+	})
+	defer unsub()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-eventCh:
+			data := e.Data
+			if data == nil {
+				data = json.RawMessage("null")
+			}
+			err := stream.Send(&keylightdv1.Event{
+				Type:      string(e.Type),
+				Timestamp: timestamp(e.Timestamp),
+				DataJson:  string(data),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
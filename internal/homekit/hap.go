@@ -0,0 +1,119 @@
+//go:build homekit
+
+package homekit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/brutella/hap"
+	"github.com/brutella/hap/accessory"
+	"github.com/brutella/hap/characteristic"
+	"github.com/brutella/hap/service"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// lightbulb is a light's HomeKit accessory and the characteristics kept in
+// sync with it.
+type lightbulb struct {
+	accessory   *accessory.A
+	on          *characteristic.On
+	brightness  *characteristic.Brightness
+	temperature *characteristic.ColorTemperature
+}
+
+// serve runs a HAP server exposing the given lights until ctx is cancelled,
+// applying state changes received on updates to the accessories.
+func (b *Bridge) serve(ctx context.Context, lights []*keylight.Light, updates <-chan keylight.Light) error {
+	if err := os.MkdirAll(b.cfg.StoragePath, 0700); err != nil {
+		return fmt.Errorf("failed to create HomeKit storage directory: %w", err)
+	}
+
+	bridge := accessory.NewBridge(accessory.Info{
+		Name:         "keylightd",
+		Manufacturer: "keylightd",
+	})
+
+	bulbs := make(map[string]*lightbulb, len(lights))
+	accessories := make([]*accessory.A, 0, len(lights))
+	for _, light := range lights {
+		bulb := b.newLightbulb(ctx, light)
+		bulbs[light.ID] = bulb
+		accessories = append(accessories, bulb.accessory)
+	}
+
+	server, err := hap.NewServer(hap.NewFsStore(b.cfg.StoragePath), bridge.A, accessories...)
+	if err != nil {
+		return fmt.Errorf("failed to create HAP server: %w", err)
+	}
+	server.Pin = strings.ReplaceAll(b.cfg.Pin, "-", "")
+	if b.cfg.Port > 0 {
+		server.Addr = ":" + strconv.Itoa(b.cfg.Port)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case light := <-updates:
+				if bulb, ok := bulbs[light.ID]; ok {
+					bulb.on.SetValue(light.On)
+					bulb.brightness.SetValue(light.Brightness)
+					bulb.temperature.SetValue(clampMireds(light.Temperature))
+				}
+			}
+		}
+	}()
+
+	return server.ListenAndServe(ctx)
+}
+
+// newLightbulb builds a lightbulb accessory for a light, wiring changes made in
+// the Home app to the light manager.
+func (b *Bridge) newLightbulb(ctx context.Context, light *keylight.Light) *lightbulb {
+	name := light.Name
+	if name == "" {
+		name = light.ID
+	}
+	manufacturer := "Elgato"
+	if light.Driver == "wled" {
+		manufacturer = "WLED"
+	}
+
+	a := accessory.New(accessory.Info{
+		Name:         name,
+		SerialNumber: light.SerialNumber,
+		Manufacturer: manufacturer,
+		Model:        light.ProductName,
+		Firmware:     light.FirmwareVersion,
+	}, accessory.TypeLightbulb)
+	a.Id = AccessoryID(light.ID)
+
+	svc := service.NewLightbulb()
+	bulb := &lightbulb{
+		accessory:   a,
+		on:          svc.On,
+		brightness:  characteristic.NewBrightness(),
+		temperature: characteristic.NewColorTemperature(),
+	}
+	bulb.temperature.SetMinValue(minMireds)
+	bulb.temperature.SetMaxValue(maxMireds)
+	svc.AddC(bulb.brightness.C)
+	svc.AddC(bulb.temperature.C)
+	a.AddS(svc.S)
+
+	bulb.on.SetValue(light.On)
+	bulb.brightness.SetValue(light.Brightness)
+	bulb.temperature.SetValue(clampMireds(light.Temperature))
+
+	id := light.ID
+	bulb.on.OnValueRemoteUpdate(func(on bool) { b.setPower(ctx, id, on) })
+	bulb.brightness.OnValueRemoteUpdate(func(v int) { b.setBrightness(ctx, id, v) })
+	bulb.temperature.OnValueRemoteUpdate(func(v int) { b.setColorTemperature(ctx, id, v) })
+	return bulb
+}
@@ -0,0 +1,14 @@
+//go:build !homekit
+
+package homekit
+
+import (
+	"context"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// serve reports that the HAP server was not compiled in.
+func (b *Bridge) serve(_ context.Context, _ []*keylight.Light, _ <-chan keylight.Light) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,21 @@
+//go:build !homekit
+
+package homekit
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+func TestRun_NotSupported(t *testing.T) {
+	b, err := NewBridge(slog.New(slog.DiscardHandler), config.HomeKitConfig{Enabled: true, Pin: "03145154"}, &mockLights{}, events.NewBus())
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, b.Run(t.Context()), ErrNotSupported)
+}
@@ -0,0 +1,220 @@
+// Package homekit exposes lights to Apple HomeKit as a bridge accessory, so
+// they can be controlled from the Home app and Siri.
+//
+// The HAP server is only compiled in with the homekit build tag; without it,
+// Run reports that HomeKit support is unavailable.
+package homekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// restartDelay collects light discoveries and removals into a single restart
+// of the HAP server, since its accessory set is fixed. Kept as a variable so
+// tests can shorten it.
+var restartDelay = 5 * time.Second
+
+// retryDelay is how long to wait before restarting a failed HAP server.
+const retryDelay = 30 * time.Second
+
+// Device temperature limits in mireds, the unit of the HomeKit ColorTemperature characteristic.
+const (
+	minMireds = 143 // 7000K
+	maxMireds = 344 // 2900K
+)
+
+// ErrNotSupported is returned by Run when keylightd was built without the homekit tag.
+var ErrNotSupported = errors.New("HomeKit support is not included in this build; rebuild with -tags homekit")
+
+// trivialPins are setup codes HomeKit refuses to pair with.
+var trivialPins = []string{
+	"00000000", "11111111", "22222222", "33333333", "44444444",
+	"55555555", "66666666", "77777777", "88888888", "99999999",
+	"12345678", "87654321",
+}
+
+// Bridge serves lights as HomeKit lightbulb accessories.
+type Bridge struct {
+	logger *slog.Logger
+	cfg    config.HomeKitConfig
+	lights keylight.LightManager
+	bus    *events.Bus
+}
+
+// NewBridge creates a Bridge, validating the setup code. An empty storage path
+// defaults to a homekit directory next to the config file.
+func NewBridge(logger *slog.Logger, cfg config.HomeKitConfig, lights keylight.LightManager, bus *events.Bus) (*Bridge, error) {
+	if err := ValidatePin(cfg.Pin); err != nil {
+		return nil, err
+	}
+	if cfg.StoragePath == "" {
+		cfg.StoragePath = filepath.Join(config.GetConfigBaseDir(), "homekit")
+	}
+	return &Bridge{logger: logger, cfg: cfg, lights: lights, bus: bus}, nil
+}
+
+// ValidatePin checks that a setup code has eight digits and is not one HomeKit rejects.
+// Dashes, as in 123-45-678, are ignored.
+func ValidatePin(pin string) error {
+	digits := strings.ReplaceAll(pin, "-", "")
+	if len(digits) != 8 || strings.Trim(digits, "0123456789") != "" {
+		return fmt.Errorf("homekit pin must be 8 digits, got %q", pin)
+	}
+	if slices.Contains(trivialPins, digits) {
+		return fmt.Errorf("homekit pin %s is too simple; HomeKit will refuse it", pin)
+	}
+	return nil
+}
+
+// Run serves the known lights until ctx is cancelled. The HAP server is
+// restarted when lights are discovered or removed, and after failures.
+func (b *Bridge) Run(ctx context.Context) error {
+	// Buffer events so the bus is never blocked by the HAP server.
+	evCh := make(chan events.Event, 64)
+	unsub := b.bus.Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.LightDiscovered, events.LightRemoved, events.LightStateChanged:
+			select {
+			case evCh <- e:
+			default:
+				b.logger.Warn("HomeKit bridge dropped event, buffer full", "type", e.Type)
+			}
+		}
+	})
+	defer unsub()
+
+	for {
+		lights := b.snapshot()
+		sctx, cancel := context.WithCancel(ctx)
+		updates := make(chan keylight.Light, 16)
+		errCh := make(chan error, 1)
+		go func() { errCh <- b.serve(sctx, lights, updates) }()
+		b.logger.Info("HomeKit bridge started", "lights", len(lights))
+
+		err := b.forward(ctx, lights, evCh, updates, errCh)
+		cancel()
+		if err == nil {
+			if err = <-errCh; errors.Is(err, context.Canceled) {
+				err = nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrNotSupported) {
+			return err
+		}
+		if err != nil {
+			b.logger.Error("HomeKit bridge failed", "error", err, "retry_in", retryDelay)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+// forward passes state changes for the served lights to the HAP server until
+// the set of lights changes, the server fails, or ctx is cancelled. It returns
+// nil when the server should be restarted with a new set of lights.
+func (b *Bridge) forward(ctx context.Context, lights []*keylight.Light, evCh <-chan events.Event, updates chan<- keylight.Light, errCh <-chan error) error {
+	served := make(map[string]bool, len(lights))
+	for _, l := range lights {
+		served[l.ID] = true
+	}
+
+	var restart <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			if err == nil {
+				err = errors.New("HAP server stopped")
+			}
+			return err
+		case <-restart:
+			b.logger.Info("Lights changed, restarting HomeKit bridge")
+			return nil
+		case e := <-evCh:
+			var light keylight.Light
+			if err := json.Unmarshal(e.Data, &light); err != nil || light.ID == "" {
+				continue
+			}
+			switch {
+			case e.Type != events.LightRemoved && served[light.ID]:
+				// Rediscoveries carry fresh state too
+				select {
+				case updates <- light:
+				default:
+					b.logger.Debug("HomeKit update dropped, server busy", "light", light.ID)
+				}
+			case e.Type == events.LightDiscovered && !served[light.ID],
+				e.Type == events.LightRemoved && served[light.ID]:
+				if restart == nil {
+					restart = time.After(restartDelay)
+				}
+			}
+		}
+	}
+}
+
+// snapshot returns the known lights sorted by ID.
+func (b *Bridge) snapshot() []*keylight.Light {
+	lights := b.lights.GetLights()
+	out := make([]*keylight.Light, 0, len(lights))
+	for _, id := range slices.Sorted(maps.Keys(lights)) {
+		out = append(out, lights[id])
+	}
+	return out
+}
+
+// setPower handles an on/off change made in the Home app.
+func (b *Bridge) setPower(ctx context.Context, id string, on bool) {
+	if err := b.lights.SetLightPower(ctx, id, on); err != nil {
+		b.logger.Warn("HomeKit: failed to set light power", "light", id, "error", err)
+	}
+}
+
+// setBrightness handles a brightness change made in the Home app.
+func (b *Bridge) setBrightness(ctx context.Context, id string, brightness int) {
+	brightness = min(max(brightness, config.MinBrightness), config.MaxBrightness)
+	if err := b.lights.SetLightBrightness(ctx, id, brightness); err != nil {
+		b.logger.Warn("HomeKit: failed to set light brightness", "light", id, "error", err)
+	}
+}
+
+// setColorTemperature handles a colour temperature change, in mireds, made in the Home app.
+func (b *Bridge) setColorTemperature(ctx context.Context, id string, mireds int) {
+	if err := b.lights.SetLightTemperature(ctx, id, keylight.ConvertDeviceToTemperature(mireds)); err != nil {
+		b.logger.Warn("HomeKit: failed to set light temperature", "light", id, "error", err)
+	}
+}
+
+// AccessoryID returns a stable HomeKit accessory ID for a light, so pairings
+// and room assignments survive restarts. IDs 0 and 1 are reserved (1 is the bridge).
+func AccessoryID(lightID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(lightID))
+	return max(h.Sum64(), 2)
+}
+
+// clampMireds limits a light temperature to the range advertised to HomeKit.
+func clampMireds(mireds int) int {
+	return min(max(mireds, minMireds), maxMireds)
+}
@@ -0,0 +1,97 @@
+package homekit
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// mockLights implements the parts of keylight.LightManager used by the bridge.
+type mockLights struct {
+	keylight.LightManager
+	lights map[string]*keylight.Light
+}
+
+func (m *mockLights) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+func TestValidatePin(t *testing.T) {
+	assert.NoError(t, ValidatePin("03145154"))
+	assert.NoError(t, ValidatePin("031-45-154"))
+	assert.ErrorContains(t, ValidatePin("1234"), "must be 8 digits")
+	assert.ErrorContains(t, ValidatePin("0314515a"), "must be 8 digits")
+	assert.ErrorContains(t, ValidatePin("12345678"), "too simple")
+	assert.ErrorContains(t, ValidatePin(""), "must be 8 digits")
+}
+
+func TestNewBridge(t *testing.T) {
+	_, err := NewBridge(slog.New(slog.DiscardHandler), config.HomeKitConfig{Enabled: true}, &mockLights{}, events.NewBus())
+	assert.Error(t, err)
+
+	b, err := NewBridge(slog.New(slog.DiscardHandler), config.HomeKitConfig{Enabled: true, Pin: "03145154"}, &mockLights{}, events.NewBus())
+	require.NoError(t, err)
+	assert.NotEmpty(t, b.cfg.StoragePath)
+}
+
+func TestAccessoryID(t *testing.T) {
+	id := AccessoryID("Elgato Key Light ABC1._elg._tcp.local.")
+	assert.Equal(t, id, AccessoryID("Elgato Key Light ABC1._elg._tcp.local."))
+	assert.NotEqual(t, id, AccessoryID("Elgato Key Light XYZ2._elg._tcp.local."))
+	assert.GreaterOrEqual(t, id, uint64(2))
+}
+
+func TestClampMireds(t *testing.T) {
+	assert.Equal(t, minMireds, clampMireds(100))
+	assert.Equal(t, 200, clampMireds(200))
+	assert.Equal(t, maxMireds, clampMireds(500))
+}
+
+func TestForward(t *testing.T) {
+	orig := restartDelay
+	restartDelay = 10 * time.Millisecond
+	t.Cleanup(func() { restartDelay = orig })
+
+	b := &Bridge{logger: slog.New(slog.DiscardHandler)}
+	served := []*keylight.Light{{ID: "light-1"}}
+
+	run := func(evs ...events.Event) (chan keylight.Light, error) {
+		evCh := make(chan events.Event, len(evs))
+		for _, e := range evs {
+			evCh <- e
+		}
+		updates := make(chan keylight.Light, len(evs))
+		done := make(chan error, 1)
+		go func() { done <- b.forward(t.Context(), served, evCh, updates, make(chan error)) }()
+		select {
+		case err := <-done:
+			return updates, err
+		case <-time.After(time.Second):
+			t.Fatal("forward did not return")
+			return nil, nil
+		}
+	}
+
+	// State changes of served lights are forwarded; a new light triggers a restart
+	updates, err := run(
+		events.NewEvent(events.LightStateChanged, &keylight.Light{ID: "light-1", On: true}),
+		events.NewEvent(events.LightStateChanged, &keylight.Light{ID: "other"}),
+		events.NewEvent(events.LightDiscovered, &keylight.Light{ID: "light-1", Brightness: 20}),
+		events.NewEvent(events.LightDiscovered, &keylight.Light{ID: "light-2"}),
+	)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	assert.True(t, (<-updates).On)
+	assert.Equal(t, 20, (<-updates).Brightness)
+
+	// Removing a served light triggers a restart
+	_, err = run(events.NewEvent(events.LightRemoved, &keylight.Light{ID: "light-1"}))
+	require.NoError(t, err)
+}
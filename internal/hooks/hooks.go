@@ -0,0 +1,378 @@
+// Package hooks runs user-configured shell commands when events matching
+// their filters are published on the event bus, passing the event to the
+// command in KEYLIGHT_* environment variables.
+//
+// Environment variables set for every event:
+//
+//	KEYLIGHT_HOOK        hook name
+//	KEYLIGHT_EVENT_TYPE  event type, such as light.state_changed
+//	KEYLIGHT_EVENT_TIME  event time (RFC 3339)
+//	KEYLIGHT_EVENT_DATA  event data as JSON
+//
+// For light events:
+//
+//	KEYLIGHT_LIGHT_ID, KEYLIGHT_LIGHT_NAME, KEYLIGHT_LIGHT_ON (true or false),
+//	KEYLIGHT_LIGHT_BRIGHTNESS, KEYLIGHT_LIGHT_TEMPERATURE (Kelvin),
+//	KEYLIGHT_LIGHT_STATUS
+//
+// For group events:
+//
+//	KEYLIGHT_GROUP_ID, KEYLIGHT_GROUP_NAME,
+//	KEYLIGHT_GROUP_LIGHTS (comma-separated light IDs)
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+const (
+	// eventBuffer is the number of events queued for matching before new
+	// ones are dropped.
+	eventBuffer = 64
+	// maxOutput caps the command output kept for logging.
+	maxOutput = 4 << 10
+	// waitDelay is how long a killed command's output is waited for.
+	waitDelay = time.Second
+)
+
+// Groups looks up groups, to match light events against group filters.
+type Groups interface {
+	GetGroups() []*group.Group
+}
+
+// Runner runs the configured hooks for events on the bus.
+type Runner struct {
+	logger *slog.Logger
+	bus    *events.Bus
+	groups Groups
+	hooks  []*hook
+	wg     sync.WaitGroup
+}
+
+// hook is a validated hook and its pending debounced runs.
+type hook struct {
+	cfg      config.HookConfig
+	name     string
+	debounce time.Duration
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer // debounce timers by light or group ID
+}
+
+// subject is the light or group an event is about.
+type subject struct {
+	light *keylight.Light
+	group *group.Group
+}
+
+// id returns the ID of the light or group.
+func (s subject) id() string {
+	switch {
+	case s.light != nil:
+		return s.light.ID
+	case s.group != nil:
+		return s.group.ID
+	}
+	return ""
+}
+
+// NewRunner validates the hook configs and creates a Runner. groups may be
+// nil if no hook filters on groups.
+func NewRunner(logger *slog.Logger, cfgs []config.HookConfig, bus *events.Bus, groups Groups) (*Runner, error) {
+	r := &Runner{logger: logger, bus: bus, groups: groups}
+	for i, cfg := range cfgs {
+		name := cfg.Name
+		if name == "" {
+			name = cfg.Command
+		}
+		if strings.TrimSpace(cfg.Command) == "" {
+			return nil, fmt.Errorf("hook %d: command is required", i+1)
+		}
+		if len(cfg.Events) == 0 {
+			return nil, fmt.Errorf("hook %q: at least one event type is required", name)
+		}
+		for _, pattern := range cfg.Events {
+			if !validPattern(pattern) {
+				return nil, fmt.Errorf("hook %q: unknown event type %q", name, pattern)
+			}
+		}
+		if cfg.DebounceMS < 0 || cfg.TimeoutMS < 0 {
+			return nil, fmt.Errorf("hook %q: debounce_ms and timeout_ms cannot be negative", name)
+		}
+		if len(cfg.Groups) > 0 && groups == nil {
+			return nil, fmt.Errorf("hook %q: group filters are not available", name)
+		}
+		h := &hook{
+			cfg:      cfg,
+			name:     name,
+			debounce: time.Duration(cfg.DebounceMS) * time.Millisecond,
+			timeout:  time.Duration(cfg.TimeoutMS) * time.Millisecond,
+			pending:  make(map[string]*time.Timer),
+		}
+		if h.timeout == 0 {
+			h.timeout = config.DefaultHookTimeout
+		}
+		r.hooks = append(r.hooks, h)
+	}
+	return r, nil
+}
+
+// Run runs hooks for events until ctx is cancelled, then waits for running
+// commands, which are killed by the cancellation, to finish.
+func (r *Runner) Run(ctx context.Context) {
+	// Buffer events so the bus is never blocked by matching.
+	evCh := make(chan events.Event, eventBuffer)
+	unsub := r.bus.Subscribe(func(e events.Event) {
+		select {
+		case evCh <- e:
+		default:
+			r.logger.Warn("hooks: dropped event, buffer full", "type", e.Type)
+		}
+	})
+	defer unsub()
+	defer r.wg.Wait()
+	defer r.stopPending()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-evCh:
+			r.handle(ctx, e)
+		}
+	}
+}
+
+// handle runs or schedules every hook matching e.
+func (r *Runner) handle(ctx context.Context, e events.Event) {
+	subj := decodeSubject(e)
+	for _, h := range r.hooks {
+		if !r.matches(h, e, subj) {
+			continue
+		}
+		if h.debounce == 0 {
+			r.start(ctx, h, e, subj)
+			continue
+		}
+		// Restart the wait for this light or group, keeping the latest event.
+		// Pending runs are counted in wg so Run waits for them.
+		h.mu.Lock()
+		key := subj.id()
+		if t, ok := h.pending[key]; ok && t.Stop() {
+			r.wg.Done()
+		}
+		r.wg.Add(1)
+		var t *time.Timer
+		t = time.AfterFunc(h.debounce, func() {
+			defer r.wg.Done()
+			h.mu.Lock()
+			if h.pending[key] == t {
+				delete(h.pending, key)
+			}
+			h.mu.Unlock()
+			if ctx.Err() == nil {
+				r.run(ctx, h, e, subj)
+			}
+		})
+		h.pending[key] = t
+		h.mu.Unlock()
+	}
+}
+
+// stopPending cancels debounced runs that haven't started.
+func (r *Runner) stopPending() {
+	for _, h := range r.hooks {
+		h.mu.Lock()
+		for key, t := range h.pending {
+			if t.Stop() {
+				r.wg.Done()
+			}
+			delete(h.pending, key)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// matches reports whether e passes every filter of h.
+func (r *Runner) matches(h *hook, e events.Event, subj subject) bool {
+	if !slices.ContainsFunc(h.cfg.Events, func(pattern string) bool { return matchType(pattern, e.Type) }) {
+		return false
+	}
+	if len(h.cfg.Lights) > 0 {
+		if subj.light == nil || !containsIDOrName(h.cfg.Lights, subj.light.ID, subj.light.Name) {
+			return false
+		}
+	}
+	if h.cfg.On != nil {
+		if subj.light == nil || subj.light.On != *h.cfg.On {
+			return false
+		}
+	}
+	if len(h.cfg.Groups) > 0 {
+		switch {
+		case subj.group != nil:
+			if !containsIDOrName(h.cfg.Groups, subj.group.ID, subj.group.Name) {
+				return false
+			}
+		case subj.light != nil:
+			if !r.inGroups(subj.light.ID, h.cfg.Groups) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// inGroups reports whether a light belongs to any of the named groups.
+func (r *Runner) inGroups(lightID string, keys []string) bool {
+	for _, grp := range r.groups.GetGroups() {
+		if containsIDOrName(keys, grp.ID, grp.Name) && slices.Contains(grp.Lights, lightID) {
+			return true
+		}
+	}
+	return false
+}
+
+// start runs h's command for e in the background.
+func (r *Runner) start(ctx context.Context, h *hook, e events.Event, subj subject) {
+	r.wg.Go(func() {
+		r.run(ctx, h, e, subj)
+	})
+}
+
+// run runs h's command for e, logging its outcome.
+func (r *Runner) run(ctx context.Context, h *hook, e events.Event, subj subject) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", h.cfg.Command) //nolint:gosec // G204: the command comes from the daemon's own config
+	cmd.Env = append(os.Environ(), environment(h.name, e, subj)...)
+	cmd.WaitDelay = waitDelay
+	var output limitedBuffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	started := time.Now()
+	err := cmd.Run()
+	attrs := []any{"hook", h.name, "event", e.Type, "duration", time.Since(started).Round(time.Millisecond)}
+	if id := subj.id(); id != "" {
+		attrs = append(attrs, "id", id)
+	}
+	if out := strings.TrimSpace(output.String()); out != "" {
+		attrs = append(attrs, "output", out)
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s: %w", h.timeout, err)
+		}
+		r.logger.Warn("hooks: command failed", append(attrs, "error", err)...)
+		return
+	}
+	r.logger.Debug("hooks: command finished", attrs...)
+}
+
+// environment returns the KEYLIGHT_* variables describing e.
+func environment(name string, e events.Event, subj subject) []string {
+	env := []string{
+		"KEYLIGHT_HOOK=" + name,
+		"KEYLIGHT_EVENT_TYPE=" + string(e.Type),
+		"KEYLIGHT_EVENT_TIME=" + e.Timestamp.Format(time.RFC3339),
+		"KEYLIGHT_EVENT_DATA=" + string(e.Data),
+	}
+	if l := subj.light; l != nil {
+		env = append(env,
+			"KEYLIGHT_LIGHT_ID="+l.ID,
+			"KEYLIGHT_LIGHT_NAME="+l.Name,
+			"KEYLIGHT_LIGHT_ON="+strconv.FormatBool(l.On),
+			"KEYLIGHT_LIGHT_BRIGHTNESS="+strconv.Itoa(l.Brightness),
+			"KEYLIGHT_LIGHT_TEMPERATURE="+strconv.Itoa(keylight.ConvertDeviceToTemperature(l.Temperature)),
+			"KEYLIGHT_LIGHT_STATUS="+string(l.Status),
+		)
+	}
+	if g := subj.group; g != nil {
+		env = append(env,
+			"KEYLIGHT_GROUP_ID="+g.ID,
+			"KEYLIGHT_GROUP_NAME="+g.Name,
+			"KEYLIGHT_GROUP_LIGHTS="+strings.Join(g.Lights, ","),
+		)
+	}
+	return env
+}
+
+// decodeSubject decodes the light or group an event is about. Events whose
+// data can't be decoded have no subject.
+func decodeSubject(e events.Event) subject {
+	switch {
+	case strings.HasPrefix(string(e.Type), "light."):
+		var l keylight.Light
+		if json.Unmarshal(e.Data, &l) == nil && l.ID != "" {
+			return subject{light: &l}
+		}
+	case strings.HasPrefix(string(e.Type), "group."):
+		var g group.Group
+		if json.Unmarshal(e.Data, &g) == nil && g.ID != "" {
+			return subject{group: &g}
+		}
+	}
+	return subject{}
+}
+
+// validPattern reports whether pattern matches at least one event type.
+func validPattern(pattern string) bool {
+	return slices.ContainsFunc(events.Types, func(t events.EventType) bool { return matchType(pattern, t) })
+}
+
+// matchType reports whether an event type matches a pattern: an exact type,
+// a prefix ending in * such as light.*, or * for every event.
+func matchType(pattern string, t events.EventType) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(string(t), prefix)
+	}
+	return pattern == string(t)
+}
+
+// containsIDOrName reports whether keys contains id or name.
+func containsIDOrName(keys []string, id, name string) bool {
+	return slices.Contains(keys, id) || (name != "" && slices.Contains(keys, name))
+}
+
+// limitedBuffer keeps the first maxOutput bytes written to it and discards
+// the rest. It is safe for the concurrent writes of stdout and stderr.
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := maxOutput - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,166 @@
+package hooks
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type staticGroups []*group.Group
+
+func (g staticGroups) GetGroups() []*group.Group { return g }
+
+func boolPtr(b bool) *bool { return &b }
+
+func lightEvent(id, name string, on bool) events.Event {
+	return events.NewEvent(events.LightStateChanged, &keylight.Light{ID: id, Name: name, On: on, Brightness: 40, Temperature: 200})
+}
+
+func TestNewRunner_Validation(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	tests := []struct {
+		name string
+		cfg  config.HookConfig
+		want string
+	}{
+		{"no command", config.HookConfig{Events: []string{"light.*"}}, "command is required"},
+		{"no events", config.HookConfig{Command: "true"}, "at least one event type"},
+		{"unknown event", config.HookConfig{Command: "true", Events: []string{"light.exploded"}}, "unknown event type"},
+		{"negative debounce", config.HookConfig{Command: "true", Events: []string{"*"}, DebounceMS: -1}, "cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRunner(logger, []config.HookConfig{tt.cfg}, events.NewBus(), staticGroups{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	r, err := NewRunner(logger, []config.HookConfig{{Command: "true", Events: []string{"light.*", "group.created"}}}, events.NewBus(), staticGroups{})
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultHookTimeout, r.hooks[0].timeout)
+	assert.Equal(t, "true", r.hooks[0].name, "name defaults to the command")
+}
+
+func TestRunner_Matches(t *testing.T) {
+	groups := staticGroups{{ID: "g1", Name: "Office", Lights: []string{"light-1"}}}
+	r, err := NewRunner(slog.New(slog.DiscardHandler), []config.HookConfig{
+		{Command: "true", Events: []string{"light.state_changed"}},
+		{Command: "true", Events: []string{"light.*"}, Lights: []string{"Left"}},
+		{Command: "true", Events: []string{"*"}, Groups: []string{"Office"}},
+		{Command: "true", Events: []string{"light.state_changed"}, On: boolPtr(true)},
+	}, events.NewBus(), groups)
+	require.NoError(t, err)
+
+	matches := func(hook int, e events.Event) bool {
+		return r.matches(r.hooks[hook], e, decodeSubject(e))
+	}
+	groupEvent := events.NewEvent(events.GroupUpdated, &group.Group{ID: "g1", Name: "Office"})
+
+	assert.True(t, matches(0, lightEvent("light-1", "Left", true)))
+	assert.False(t, matches(0, groupEvent), "event type filter")
+
+	assert.True(t, matches(1, lightEvent("light-1", "Left", true)), "light filter matches names")
+	assert.True(t, matches(1, lightEvent("Left", "Key Light", true)), "light filter matches IDs")
+	assert.False(t, matches(1, lightEvent("light-2", "Right", true)))
+
+	assert.True(t, matches(2, lightEvent("light-1", "Left", true)), "group filter matches member lights")
+	assert.False(t, matches(2, lightEvent("light-2", "Right", true)))
+	assert.True(t, matches(2, groupEvent))
+
+	assert.True(t, matches(3, lightEvent("light-1", "Left", true)))
+	assert.False(t, matches(3, lightEvent("light-1", "Left", false)), "on filter")
+}
+
+func TestEnvironment(t *testing.T) {
+	e := lightEvent("light-1", "Left", true)
+	env := environment("notify", e, decodeSubject(e))
+	assert.Contains(t, env, "KEYLIGHT_HOOK=notify")
+	assert.Contains(t, env, "KEYLIGHT_EVENT_TYPE=light.state_changed")
+	assert.Contains(t, env, "KEYLIGHT_LIGHT_ID=light-1")
+	assert.Contains(t, env, "KEYLIGHT_LIGHT_NAME=Left")
+	assert.Contains(t, env, "KEYLIGHT_LIGHT_ON=true")
+	assert.Contains(t, env, "KEYLIGHT_LIGHT_BRIGHTNESS=40")
+	assert.Contains(t, env, "KEYLIGHT_LIGHT_TEMPERATURE=5000")
+
+	e = events.NewEvent(events.GroupCreated, &group.Group{ID: "g1", Name: "Office", Lights: []string{"a", "b"}})
+	env = environment("notify", e, decodeSubject(e))
+	assert.Contains(t, env, "KEYLIGHT_GROUP_ID=g1")
+	assert.Contains(t, env, "KEYLIGHT_GROUP_LIGHTS=a,b")
+}
+
+func TestRunner_Run(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+	dir := t.TempDir()
+	out, ready := filepath.Join(dir, "out"), filepath.Join(dir, "ready")
+	bus := events.NewBus()
+	r, err := NewRunner(slog.New(slog.DiscardHandler), []config.HookConfig{
+		{Command: `echo "$KEYLIGHT_LIGHT_ID $KEYLIGHT_LIGHT_BRIGHTNESS" >> ` + out, Events: []string{"light.state_changed"}, DebounceMS: 50},
+		{Command: "touch " + ready, Events: []string{"light.discovered"}},
+	}, bus, staticGroups{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	// Wait for Run to subscribe
+	require.Eventually(t, func() bool {
+		bus.Publish(events.NewEvent(events.LightDiscovered, &keylight.Light{ID: "probe"}))
+		_, err := os.Stat(ready)
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// A burst for one light runs once, with the latest state
+	for brightness := 10; brightness <= 30; brightness += 10 {
+		bus.Publish(events.NewEvent(events.LightStateChanged, &keylight.Light{ID: "light-1", Brightness: brightness}))
+	}
+	bus.Publish(events.NewEvent(events.LightStateChanged, &keylight.Light{ID: "light-2", Brightness: 5}))
+
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(out)
+		return strings.Count(string(data), "\n") == 2
+	}, 5*time.Second, 20*time.Millisecond)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"light-1 30", "light-2 5"}, strings.Split(strings.TrimSpace(string(data)), "\n"))
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+	r, err := NewRunner(slog.New(slog.DiscardHandler), []config.HookConfig{
+		{Command: "sleep 10", Events: []string{"*"}, TimeoutMS: 50},
+	}, events.NewBus(), staticGroups{})
+	require.NoError(t, err)
+
+	e := lightEvent("light-1", "Left", true)
+	started := time.Now()
+	r.run(t.Context(), r.hooks[0], e, decodeSubject(e))
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
@@ -78,31 +78,20 @@ func (h *APIKeyHandler) CreateAPIKey(_ context.Context, input *CreateAPIKeyInput
 
 	newKey, err := h.Manager.CreateAPIKey(input.Body.Name, expiresInDuration)
 	if err != nil {
-		return nil, huma.Error500InternalServerError(fmt.Sprintf("Failed to create API key: %s", err))
+		return nil, errorResponse(err, "Failed to create API key: %s", err)
 	}
 
-	return &CreateAPIKeyOutput{
-		Body: APIKeyResponse{
-			ID:        newKey.Key,
-			Name:      newKey.Name,
-			Key:       newKey.Key, // Full key shown only on creation
-			CreatedAt: newKey.CreatedAt,
-			ExpiresAt: newKey.ExpiresAt,
-		},
-	}, nil
+	resp := APIKeyFromConfig(newKey)
+	resp.Key = newKey.Key // Full key shown only on creation
+	return &CreateAPIKeyOutput{Body: resp}, nil
 }
 
 // ListAPIKeys lists all API keys.
 func (h *APIKeyHandler) ListAPIKeys(_ context.Context, _ *ListAPIKeysInput) (*ListAPIKeysOutput, error) {
 	keys := h.Manager.ListAPIKeys()
 	responseKeys := make([]APIKeyResponse, len(keys))
-	for i, k := range keys {
-		responseKeys[i] = APIKeyResponse{
-			ID:        k.Key,
-			Name:      k.Name,
-			CreatedAt: k.CreatedAt,
-			ExpiresAt: k.ExpiresAt,
-		}
+	for i := range keys {
+		responseKeys[i] = APIKeyFromConfig(&keys[i])
 	}
 	return &ListAPIKeysOutput{Body: responseKeys}, nil
 }
@@ -113,7 +102,7 @@ func (h *APIKeyHandler) DeleteAPIKey(_ context.Context, input *DeleteAPIKeyInput
 		if kerrors.IsNotFound(err) {
 			return nil, huma.Error404NotFound("API key not found")
 		}
-		return nil, huma.Error500InternalServerError(fmt.Sprintf("Failed to delete API key: %s", err))
+		return nil, errorResponse(err, "Failed to delete API key: %s", err)
 	}
 	return &DeleteAPIKeyOutput{}, nil
 }
@@ -125,17 +114,10 @@ func (h *APIKeyHandler) SetAPIKeyDisabled(_ context.Context, input *SetAPIKeyDis
 		if kerrors.IsNotFound(err) {
 			return nil, huma.Error404NotFound("API key not found")
 		}
-		return nil, huma.Error500InternalServerError(fmt.Sprintf("Failed to update API key: %s", err))
+		return nil, errorResponse(err, "Failed to update API key: %s", err)
 	}
 
-	return &SetAPIKeyDisabledOutput{
-		Body: APIKeyResponse{
-			ID:        updatedKey.Key,
-			Name:      updatedKey.Name,
-			CreatedAt: updatedKey.CreatedAt,
-			ExpiresAt: updatedKey.ExpiresAt,
-		},
-	}, nil
+	return &SetAPIKeyDisabledOutput{Body: APIKeyFromConfig(updatedKey)}, nil
 }
 
 // Ensure APIKeyHandler implements the interface at compile time.
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jmylchreest/keylightd/internal/backup"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/http/mw"
+)
+
+// --- Export Config ---
+
+// ExportConfigInput is the input for exporting groups, schedules, API keys and light names.
+type ExportConfigInput struct {
+	IncludeSecrets bool `query:"include_secrets" doc:"Include API key secrets, so the keys can be imported elsewhere"`
+}
+
+// ExportConfigOutput is the output for exporting the daemon's state.
+type ExportConfigOutput struct {
+	Body backup.ExportDocument
+}
+
+// --- Import Config ---
+
+// ImportConfigInput is the input for importing a previously exported document.
+type ImportConfigInput struct {
+	Body backup.ExportDocument
+}
+
+// ImportConfigOutput is the output for importing a document.
+type ImportConfigOutput struct {
+	Body backup.ImportResult
+}
+
+// BackupHandler implements the export and import HTTP handlers.
+type BackupHandler struct {
+	Backup *backup.Service
+}
+
+// ExportConfig returns the daemon's groups, schedules, API key metadata and
+// light names as a portable document. Including the secrets needs the write
+// scope, as creating keys does, although the route itself only needs read.
+func (h *BackupHandler) ExportConfig(ctx context.Context, input *ExportConfigInput) (*ExportConfigOutput, error) {
+	if input.IncludeSecrets && !mw.RequestHasScope(ctx, mw.ScopeWrite) {
+		return nil, huma.Error403Forbidden("Forbidden: include_secrets requires the write scope")
+	}
+	return &ExportConfigOutput{Body: *h.Backup.Export(input.IncludeSecrets)}, nil
+}
+
+// ImportConfig merges an exported document into the daemon's state.
+func (h *BackupHandler) ImportConfig(ctx context.Context, input *ImportConfigInput) (*ImportConfigOutput, error) {
+	result, err := h.Backup.Import(ctx, &input.Body)
+	if err != nil {
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Failed to import config: %s", err)
+	}
+	return &ImportConfigOutput{Body: *result}, nil
+}
+
+// Ensure BackupHandler implements the interface at compile time.
+var _ BackupHandlers = (*BackupHandler)(nil)
+
+// BackupHandlers defines the interface for export and import operations.
+type BackupHandlers interface {
+	ExportConfig(ctx context.Context, input *ExportConfigInput) (*ExportConfigOutput, error)
+	ImportConfig(ctx context.Context, input *ImportConfigInput) (*ImportConfigOutput, error)
+}
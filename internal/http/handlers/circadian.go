@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jmylchreest/keylightd/internal/circadian"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// CircadianResponse is the API representation of circadian mode's state.
+type CircadianResponse struct {
+	Enabled bool             `json:"enabled" doc:"Whether circadian mode is adjusting lights"`
+	Mode    string           `json:"mode,omitempty" enum:"sun,points" doc:"Whether the curve follows the sun or explicit curve points; absent if neither is configured"`
+	Target  *CircadianTarget `json:"target,omitempty" doc:"State lights are currently set to"`
+	Sunrise *time.Time       `json:"sunrise,omitempty" doc:"Today's sunrise, when following the sun"`
+	Sunset  *time.Time       `json:"sunset,omitempty" doc:"Today's sunset, when following the sun"`
+	Lights  []string         `json:"lights" doc:"IDs of the lights circadian mode applies to"`
+}
+
+// CircadianTarget is the state circadian mode sets lights to.
+type CircadianTarget struct {
+	Temperature int `json:"temperature" doc:"Color temperature in Kelvin"`
+	Brightness  int `json:"brightness,omitempty" doc:"Brightness level (3-100); absent if brightness is left alone"`
+}
+
+// CircadianFromInternal converts a circadian.Status to a CircadianResponse.
+func CircadianFromInternal(s circadian.Status) CircadianResponse {
+	resp := CircadianResponse{Enabled: s.Enabled, Mode: s.Mode, Lights: s.Lights}
+	if s.Target != nil {
+		resp.Target = &CircadianTarget{Temperature: s.Target.Temperature, Brightness: s.Target.Brightness}
+	}
+	if !s.Sunrise.IsZero() {
+		resp.Sunrise = &s.Sunrise
+	}
+	if !s.Sunset.IsZero() {
+		resp.Sunset = &s.Sunset
+	}
+	return resp
+}
+
+// --- Get Circadian ---
+
+// GetCircadianInput is the input for getting circadian mode's state.
+type GetCircadianInput struct{}
+
+// GetCircadianOutput is the output for getting circadian mode's state.
+type GetCircadianOutput struct {
+	Body CircadianResponse
+}
+
+// --- Set Circadian ---
+
+// SetCircadianInput is the input for enabling or disabling circadian mode.
+type SetCircadianInput struct {
+	Body struct {
+		Enabled bool `json:"enabled" doc:"Whether circadian mode should adjust lights"`
+	}
+}
+
+// SetCircadianOutput is the output for enabling or disabling circadian mode.
+type SetCircadianOutput struct {
+	Body CircadianResponse
+}
+
+// CircadianHandler implements circadian mode HTTP handlers.
+type CircadianHandler struct {
+	Circadian *circadian.Manager
+}
+
+// GetCircadian returns circadian mode's state.
+func (h *CircadianHandler) GetCircadian(_ context.Context, _ *GetCircadianInput) (*GetCircadianOutput, error) {
+	return &GetCircadianOutput{Body: CircadianFromInternal(h.Circadian.Status())}, nil
+}
+
+// SetCircadian enables or disables circadian mode.
+func (h *CircadianHandler) SetCircadian(_ context.Context, input *SetCircadianInput) (*SetCircadianOutput, error) {
+	status, err := h.Circadian.SetEnabled(input.Body.Enabled)
+	if err != nil {
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Failed to set circadian mode: %s", err)
+	}
+	return &SetCircadianOutput{Body: CircadianFromInternal(status)}, nil
+}
+
+// Ensure CircadianHandler implements the interface at compile time.
+var _ CircadianHandlers = (*CircadianHandler)(nil)
+
+// CircadianHandlers defines the interface for circadian mode operations.
+type CircadianHandlers interface {
+	GetCircadian(ctx context.Context, input *GetCircadianInput) (*GetCircadianOutput, error)
+	SetCircadian(ctx context.Context, input *SetCircadianInput) (*SetCircadianOutput, error)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/desiredstate"
+)
+
+// DesiredStateResponse is the API representation of desired-state mode's state.
+type DesiredStateResponse struct {
+	Enabled  bool                         `json:"enabled" doc:"Whether desired-state mode is putting lights back"`
+	Interval int                          `json:"interval" doc:"Seconds between checks of the targeted lights"`
+	Targets  []DesiredStateTargetResponse `json:"targets" doc:"Configured desired-state targets"`
+}
+
+// DesiredStateTargetResponse is the API representation of one desired-state target.
+type DesiredStateTargetResponse struct {
+	Target         string     `json:"target" doc:"Target key, light:<id> or group:<id or name>"`
+	On             *bool      `json:"on,omitempty" doc:"Desired power state; absent if power is left alone"`
+	Brightness     *int       `json:"brightness,omitempty" doc:"Desired brightness (3-100); absent if brightness is left alone"`
+	Temperature    *int       `json:"temperature,omitempty" doc:"Desired color temperature in Kelvin; absent if temperature is left alone"`
+	Enabled        bool       `json:"enabled" doc:"Whether the target is enforced"`
+	Lights         []string   `json:"lights" doc:"IDs of the lights the target currently applies to"`
+	Corrections    int        `json:"corrections" doc:"Times a light was put back since the daemon started"`
+	LastCorrection *time.Time `json:"last_correction,omitempty" doc:"When a light was last put back"`
+}
+
+// DesiredStateFromInternal converts a desiredstate.Status to a DesiredStateResponse.
+func DesiredStateFromInternal(s desiredstate.Status) DesiredStateResponse {
+	resp := DesiredStateResponse{
+		Enabled:  s.Enabled,
+		Interval: s.Interval,
+		Targets:  make([]DesiredStateTargetResponse, 0, len(s.Targets)),
+	}
+	for _, t := range s.Targets {
+		target := DesiredStateTargetResponse{
+			Target:      t.Target,
+			On:          t.On,
+			Brightness:  t.Brightness,
+			Temperature: t.Temperature,
+			Enabled:     t.Enabled,
+			Lights:      t.Lights,
+			Corrections: t.Corrections,
+		}
+		if !t.LastCorrection.IsZero() {
+			target.LastCorrection = &t.LastCorrection
+		}
+		resp.Targets = append(resp.Targets, target)
+	}
+	return resp
+}
+
+// --- Get Desired State ---
+
+// GetDesiredStateInput is the input for getting desired-state mode's state.
+type GetDesiredStateInput struct{}
+
+// GetDesiredStateOutput is the output for getting desired-state mode's state.
+type GetDesiredStateOutput struct {
+	Body DesiredStateResponse
+}
+
+// --- Set Desired State ---
+
+// SetDesiredStateInput is the input for enabling or disabling desired-state mode.
+type SetDesiredStateInput struct {
+	Body struct {
+		Enabled bool `json:"enabled" doc:"Whether desired-state mode should put lights back"`
+	}
+}
+
+// SetDesiredStateOutput is the output for enabling or disabling desired-state mode.
+type SetDesiredStateOutput struct {
+	Body DesiredStateResponse
+}
+
+// --- Set Desired State Target ---
+
+// SetDesiredStateTargetInput is the input for enabling or disabling one target.
+type SetDesiredStateTargetInput struct {
+	Target string `path:"target" doc:"Target key, light:<id> or group:<id or name>"`
+	Body   struct {
+		Enabled bool `json:"enabled" doc:"Whether the target should be enforced"`
+	}
+}
+
+// SetDesiredStateTargetOutput is the output for enabling or disabling one target.
+type SetDesiredStateTargetOutput struct {
+	Body DesiredStateResponse
+}
+
+// DesiredStateHandler implements desired-state mode HTTP handlers.
+type DesiredStateHandler struct {
+	DesiredState *desiredstate.Manager
+}
+
+// GetDesiredState returns desired-state mode's state.
+func (h *DesiredStateHandler) GetDesiredState(_ context.Context, _ *GetDesiredStateInput) (*GetDesiredStateOutput, error) {
+	return &GetDesiredStateOutput{Body: DesiredStateFromInternal(h.DesiredState.Status())}, nil
+}
+
+// SetDesiredState enables or disables desired-state mode.
+func (h *DesiredStateHandler) SetDesiredState(_ context.Context, input *SetDesiredStateInput) (*SetDesiredStateOutput, error) {
+	status, err := h.DesiredState.SetEnabled(input.Body.Enabled)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to set desired-state mode: %s", err)
+	}
+	return &SetDesiredStateOutput{Body: DesiredStateFromInternal(status)}, nil
+}
+
+// SetDesiredStateTarget enables or disables enforcing one target.
+func (h *DesiredStateHandler) SetDesiredStateTarget(_ context.Context, input *SetDesiredStateTargetInput) (*SetDesiredStateTargetOutput, error) {
+	status, err := h.DesiredState.SetTargetEnabled(input.Target, input.Body.Enabled)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to set desired-state target: %s", err)
+	}
+	return &SetDesiredStateTargetOutput{Body: DesiredStateFromInternal(status)}, nil
+}
+
+// Ensure DesiredStateHandler implements the interface at compile time.
+var _ DesiredStateHandlers = (*DesiredStateHandler)(nil)
+
+// DesiredStateHandlers defines the interface for desired-state mode operations.
+type DesiredStateHandlers interface {
+	GetDesiredState(ctx context.Context, input *GetDesiredStateInput) (*GetDesiredStateOutput, error)
+	SetDesiredState(ctx context.Context, input *SetDesiredStateInput) (*SetDesiredStateOutput, error)
+	SetDesiredStateTarget(ctx context.Context, input *SetDesiredStateTargetInput) (*SetDesiredStateTargetOutput, error)
+}
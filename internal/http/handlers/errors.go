@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/http/mw"
+)
+
+// errorResponse returns the error response for err, with the status, code
+// and details taken from the error taxonomy: a light that can't be reached is
+// a 503, for example, rather than a 500.
+func errorResponse(err error, format string, args ...any) huma.StatusError {
+	apiErr := kerrors.FromError(err)
+	status := apiErr.Code.HTTPStatus()
+	return &mw.ErrorModel{
+		ErrorModel: huma.ErrorModel{Status: status, Title: http.StatusText(status), Detail: fmt.Sprintf(format, args...)},
+		Code:       apiErr.Code,
+		Details:    apiErr.Details,
+	}
+}
@@ -2,11 +2,9 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log/slog"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 
@@ -54,6 +52,37 @@ type GetGroupOutput struct {
 	Body GroupResponse
 }
 
+// --- Update Group ---
+
+// UpdateGroupInput is the input for renaming a group or changing its metadata.
+type UpdateGroupInput struct {
+	ID   string `path:"id" doc:"Group identifier"`
+	Body struct {
+		Name        *string `json:"name,omitempty" minLength:"1" doc:"New display name for the group"`
+		Description *string `json:"description,omitempty" maxLength:"256" doc:"Group description; empty to clear it"`
+		Icon        *string `json:"icon,omitempty" maxLength:"64" doc:"Icon name shown for the group in UIs; empty to clear it"`
+		Color       *string `json:"color,omitempty" pattern:"^(#[0-9a-fA-F]{6})?$" doc:"Color shown for the group in UIs, as #rrggbb; empty to clear it"`
+	}
+}
+
+// UpdateGroupOutput is the output for updating a group.
+type UpdateGroupOutput struct {
+	Body GroupResponse
+}
+
+// --- Get Group State ---
+
+// GetGroupStateInput is the input for getting the aggregate state of a group.
+type GetGroupStateInput struct {
+	ID string `path:"id" doc:"Group identifier"`
+}
+
+// GetGroupStateOutput is the output for getting the aggregate state of a group.
+type GetGroupStateOutput struct {
+	ETag string `header:"ETag" doc:"The group's state version"`
+	Body GroupStateResponse
+}
+
 // --- Delete Group ---
 
 // DeleteGroupInput is the input for deleting a group.
@@ -79,25 +108,59 @@ type SetGroupLightsOutput struct {
 	Body StatusResponse
 }
 
+// --- Set Group Defaults ---
+
+// SetGroupDefaultsInput is the input for setting a group's default state.
+type SetGroupDefaultsInput struct {
+	ID   string `path:"id" doc:"Group identifier"`
+	Body GroupDefaultsBody
+}
+
+// SetGroupDefaultsOutput is the output for setting group defaults.
+type SetGroupDefaultsOutput struct {
+	Body StatusResponse
+}
+
 // --- Set Group State ---
 
 // SetGroupStateInput is the input for setting a group's state.
 // The ID path parameter supports comma-separated IDs/names for multi-group targeting.
 type SetGroupStateInput struct {
-	ID   string `path:"id" doc:"Group identifier(s), comma-separated for multi-target"`
-	Body struct {
-		On          *bool `json:"on,omitempty" doc:"Power state for all lights in the group"`
-		Brightness  *int  `json:"brightness,omitempty" doc:"Brightness level (0-100) for all lights"`
-		Temperature *int  `json:"temperature,omitempty" doc:"Color temperature for all lights"`
+	ID      string `path:"id" doc:"Group identifier(s) or name(s), comma-separated for multi-target; \"all\" or a glob pattern such as office-* selects every matching group"`
+	IfMatch string `header:"If-Match" doc:"Only change the group if it is still at this state version (the ETag of its state); otherwise fail with 409. Requires a single group"`
+	Body    struct {
+		On               *bool    `json:"on,omitempty" doc:"Power state for all lights in the group"`
+		Brightness       *int     `json:"brightness,omitempty" doc:"Brightness level (0-100) for all lights"`
+		Temperature      *int     `json:"temperature,omitempty" doc:"Color temperature for all lights, in Kelvin unless units is mired"`
+		Units            string   `json:"units,omitempty" enum:"kelvin,mired" doc:"Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin"`
+		Hue              *float64 `json:"hue,omitempty" minimum:"0" maximum:"360" doc:"Hue in degrees for the group's color lights; other lights are left unchanged"`
+		Saturation       *float64 `json:"saturation,omitempty" minimum:"0" maximum:"100" doc:"Saturation percentage for the group's color lights; other lights are left unchanged"`
+		BrightnessDelta  *int     `json:"brightness_delta,omitempty" doc:"Change each light's brightness by this many percentage points, relative to its current value and clamped to the valid range"`
+		TemperatureDelta *int     `json:"temperature_delta,omitempty" doc:"Change each light's color temperature by this many Kelvin, relative to its current value and clamped to the valid range"`
+		TransitionMS     *int     `json:"transition_ms,omitempty" minimum:"0" doc:"Ramp brightness and temperature over this many milliseconds instead of applying instantly"`
 	}
 }
 
 // SetGroupStateOutput is the output for setting group state.
-// On success returns 200 with {"status": "ok"}.
-// On partial failure returns 207 with {"status": "partial", "errors": [...]}.
-// This uses a raw writer because Huma doesn't natively support 207 Multi-Status.
+// On success returns 200 with {"status": "ok", "lights": [...]}.
+// On partial failure returns 207 with {"status": "partial", "errors": [...], "lights": [...]}.
 type SetGroupStateOutput struct {
-	Body any // Either StatusResponse or PartialStatusResponse
+	Status int
+	Body   GroupChangeResponse
+}
+
+// --- Toggle Group ---
+
+// ToggleGroupInput is the input for toggling one or more groups.
+type ToggleGroupInput struct {
+	ID string `path:"id" doc:"Group identifier(s) or name(s), comma-separated for multi-target; \"all\" or a glob pattern such as office-* selects every matching group"`
+}
+
+// ToggleGroupOutput is the output for toggling groups.
+// On success returns 200; if some groups fail returns 207 with per-group errors.
+type ToggleGroupOutput struct {
+	Status int
+	Body   GroupToggleResponse
 }
 
 // GroupHandler implements group-related HTTP handlers.
@@ -109,9 +172,18 @@ type GroupHandler struct {
 // ListGroups returns all groups as an array.
 func (h *GroupHandler) ListGroups(_ context.Context, _ *ListGroupsInput) (*ListGroupsOutput, error) {
 	groups := h.Groups.GetGroups()
-	return &ListGroupsOutput{
-		Body: GroupsFromInternal(groups),
-	}, nil
+	body := GroupsFromInternal(groups)
+	for i := range body {
+		h.setLimits(&body[i])
+	}
+	return &ListGroupsOutput{Body: body}, nil
+}
+
+// setLimits fills in the group's effective limits.
+func (h *GroupHandler) setLimits(resp *GroupResponse) {
+	if limits, err := h.Groups.GroupLimits(resp.ID); err == nil {
+		resp.Limits = &limits
+	}
 }
 
 // CreateGroup creates a new group and returns it with HTTP 201.
@@ -122,12 +194,12 @@ func (h *GroupHandler) CreateGroup(ctx context.Context, input *CreateGroupInput)
 
 	grp, err := h.Groups.CreateGroup(ctx, input.Body.Name, input.Body.LightIDs)
 	if err != nil {
-		return nil, huma.Error500InternalServerError(fmt.Sprintf("Failed to create group: %s", err))
+		return nil, errorResponse(err, "Failed to create group: %s", err)
 	}
 
-	return &CreateGroupOutput{
-		Body: GroupFromInternal(grp),
-	}, nil
+	body := GroupFromInternal(grp)
+	h.setLimits(&body)
+	return &CreateGroupOutput{Body: body}, nil
 }
 
 // GetGroup returns a single group by ID.
@@ -136,7 +208,41 @@ func (h *GroupHandler) GetGroup(_ context.Context, input *GetGroupInput) (*GetGr
 	if err != nil {
 		return nil, huma.Error404NotFound(fmt.Sprintf("Group not found: %s", err))
 	}
-	return &GetGroupOutput{Body: GroupFromInternal(grp)}, nil
+	body := GroupFromInternal(grp)
+	h.setLimits(&body)
+	return &GetGroupOutput{Body: body}, nil
+}
+
+// UpdateGroup renames a group or changes its description, icon or color.
+// Fields left out are unchanged.
+func (h *GroupHandler) UpdateGroup(_ context.Context, input *UpdateGroupInput) (*UpdateGroupOutput, error) {
+	grp, err := h.Groups.UpdateGroup(input.ID, group.Update{
+		Name:        input.Body.Name,
+		Description: input.Body.Description,
+		Icon:        input.Body.Icon,
+		Color:       input.Body.Color,
+	})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound("Group not found")
+		}
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Failed to update group: %s", err)
+	}
+	body := GroupFromInternal(grp)
+	h.setLimits(&body)
+	return &UpdateGroupOutput{Body: body}, nil
+}
+
+// GetGroupState returns the aggregate state of a group's lights.
+func (h *GroupHandler) GetGroupState(_ context.Context, input *GetGroupStateInput) (*GetGroupStateOutput, error) {
+	summary, err := h.Groups.Summary(input.ID)
+	if err != nil {
+		return nil, huma.Error404NotFound(fmt.Sprintf("Group not found: %s", err))
+	}
+	return &GetGroupStateOutput{ETag: versionETag(summary.Version), Body: GroupStateFromInternal(summary)}, nil
 }
 
 // DeleteGroup deletes a group and returns HTTP 204.
@@ -145,7 +251,7 @@ func (h *GroupHandler) DeleteGroup(_ context.Context, input *DeleteGroupInput) (
 		if kerrors.IsNotFound(err) {
 			return nil, huma.Error404NotFound("Group not found")
 		}
-		return nil, huma.Error500InternalServerError(fmt.Sprintf("Failed to delete group: %s", err))
+		return nil, errorResponse(err, "Failed to delete group: %s", err)
 	}
 	return &DeleteGroupOutput{}, nil
 }
@@ -156,178 +262,160 @@ func (h *GroupHandler) SetGroupLights(ctx context.Context, input *SetGroupLights
 		if kerrors.IsNotFound(err) {
 			return nil, huma.Error404NotFound("Group or light not found")
 		}
-		return nil, huma.Error500InternalServerError(fmt.Sprintf("Failed to set group lights: %s", err))
+		return nil, errorResponse(err, "Failed to set group lights: %s", err)
 	}
 	return &SetGroupLightsOutput{
 		Body: StatusResponse{Status: "ok"},
 	}, nil
 }
 
-// SetGroupState sets the state for one or more groups (comma-separated IDs/names).
-// Returns 200 on full success, 207 on partial failure.
-// This is implemented as a raw handler because Huma doesn't support 207.
-func (h *GroupHandler) SetGroupState(ctx context.Context, input *SetGroupStateInput) (*SetGroupStateOutput, error) {
-	// Parse comma-separated group keys
-	groupKeys := strings.Split(input.ID, ",")
-	var matchedGroups []*group.Group
-	var notFound []string
-	groupSeen := make(map[string]bool)
-
-	for _, key := range groupKeys {
-		key = strings.TrimSpace(key)
-		// Try by ID
-		grp, err := h.Groups.GetGroup(key)
-		if err == nil {
-			if !groupSeen[grp.ID] {
-				matchedGroups = append(matchedGroups, grp)
-				groupSeen[grp.ID] = true
-			}
-			continue
+// SetGroupDefaults sets the default state of a group. Omitting both brightness
+// and temperature clears the defaults.
+func (h *GroupHandler) SetGroupDefaults(_ context.Context, input *SetGroupDefaultsInput) (*SetGroupDefaultsOutput, error) {
+	if err := h.Groups.SetGroupDefaults(input.ID, input.Body.toInternal()); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound("Group not found")
 		}
-		// Try by name
-		byName := h.Groups.GetGroupsByName(key)
-		if len(byName) > 0 {
-			for _, g := range byName {
-				if !groupSeen[g.ID] {
-					matchedGroups = append(matchedGroups, g)
-					groupSeen[g.ID] = true
-				}
-			}
-		} else {
-			notFound = append(notFound, key)
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
 		}
+		return nil, errorResponse(err, "Failed to set group defaults: %s", err)
 	}
+	return &SetGroupDefaultsOutput{
+		Body: StatusResponse{Status: "ok"},
+	}, nil
+}
 
+// SetGroupState sets the state for one or more groups (comma-separated IDs,
+// names, "all" or glob patterns).
+// Returns 200 on full success, 207 on partial failure.
+func (h *GroupHandler) SetGroupState(ctx context.Context, input *SetGroupStateInput) (*SetGroupStateOutput, error) {
+	matchedGroups, notFound := h.Groups.GetGroupsByKeys(input.ID)
+	if len(matchedGroups) == 0 {
+		err := kerrors.Errorf(kerrors.CodeNotFound, "no groups found").WithDetails(map[string]any{"not_found": notFound})
+		return nil, errorResponse(err, "No groups found for: %v", notFound)
+	}
+
+	temperature, err := kelvinFromBody(input.Body.Temperature, input.Body.Units)
+	if err != nil {
+		return nil, err
+	}
+	input.Body.Temperature = temperature
+	adj, err := adjustmentFromBody(input.Body.Brightness, input.Body.Temperature,
+		input.Body.BrightnessDelta, input.Body.TemperatureDelta, input.Body.TransitionMS)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = h.checkGroupVersion(ctx, matchedGroups, input.IfMatch)
+	if err != nil {
+		return nil, errorResponse(err, "%s", err)
+	}
+	change := keylight.StateChange{On: input.Body.On, Brightness: input.Body.Brightness, Temperature: input.Body.Temperature,
+		Hue: input.Body.Hue, Saturation: input.Body.Saturation}
+	ctx, results := group.WithResults(ctx)
+	errs := h.applyGroupState(ctx, matchedGroups, change, adj, input.Body.TransitionMS)
+
+	status := http.StatusOK
+	if len(errs) > 0 {
+		status = http.StatusMultiStatus
+	}
+	return &SetGroupStateOutput{Status: status, Body: groupChangeResponse(errs, results)}, nil
+}
+
+// groupChangeResponse returns the response to a change to groups that failed
+// with errs.
+func groupChangeResponse(errs []string, results *group.Results) GroupChangeResponse {
+	resp := GroupChangeResponse{Status: "ok", Lights: GroupLightResultsFromInternal(results.Lights())}
+	if len(errs) > 0 {
+		resp.Status, resp.Errors = "partial", errs
+	}
+	return resp
+}
+
+// ToggleGroup toggles each matched group independently. A group with any light
+// on is turned off; otherwise all of its lights are turned on.
+func (h *GroupHandler) ToggleGroup(ctx context.Context, input *ToggleGroupInput) (*ToggleGroupOutput, error) {
+	matchedGroups, notFound := h.Groups.GetGroupsByKeys(input.ID)
 	if len(matchedGroups) == 0 {
 		return nil, huma.Error404NotFound(fmt.Sprintf("No groups found for: %v", notFound))
 	}
 
+	ctx, results := group.WithResults(ctx)
+	states := make(map[string]bool, len(matchedGroups))
 	var errs []string
 	for _, grp := range matchedGroups {
-		if input.Body.On != nil {
-			if err := h.Groups.SetGroupState(ctx, grp.ID, *input.Body.On); err != nil {
-				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
-			}
-		}
-		if input.Body.Brightness != nil {
-			if err := h.Groups.SetGroupBrightness(ctx, grp.ID, *input.Body.Brightness); err != nil {
-				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
-			}
-		}
-		if input.Body.Temperature != nil {
-			if err := h.Groups.SetGroupTemperature(ctx, grp.ID, *input.Body.Temperature); err != nil {
-				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
-			}
+		on, err := h.Groups.ToggleGroup(ctx, grp.ID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
 		}
+		states[grp.ID] = on
 	}
 
+	lights := GroupLightResultsFromInternal(results.Lights())
 	if len(errs) > 0 {
-		return &SetGroupStateOutput{
-			Body: PartialStatusResponse{Status: "partial", Errors: errs},
+		return &ToggleGroupOutput{
+			Status: http.StatusMultiStatus,
+			Body:   GroupToggleResponse{Status: "partial", Groups: states, Errors: errs, Lights: lights},
 		}, nil
 	}
-
-	return &SetGroupStateOutput{
-		Body: StatusResponse{Status: "ok"},
+	return &ToggleGroupOutput{
+		Status: http.StatusOK,
+		Body:   GroupToggleResponse{Status: "ok", Groups: states, Lights: lights},
 	}, nil
 }
 
-// SetGroupStateRaw is the raw HTTP handler for SetGroupState.
-// This is needed because Huma doesn't natively support 207 Multi-Status.
-// It wraps the typed handler and writes the appropriate status code.
-func (h *GroupHandler) SetGroupStateRaw(api huma.API) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
-
-		// Parse path parameter - Chi uses {id}
-		groupParam := r.PathValue("id")
-		if groupParam == "" {
-			// Try Chi's URL param
-			groupParam = chiURLParam(r, "id")
-		}
+// checkGroupVersion returns ctx with a version check for the group if the
+// If-Match header asks for one. A version is only meaningful for one group.
+func (h *GroupHandler) checkGroupVersion(ctx context.Context, groups []*group.Group, ifMatch string) (context.Context, error) {
+	version, strict, err := versionFromIfMatch(ifMatch)
+	if err != nil || !strict {
+		return ctx, err
+	}
+	if len(groups) != 1 {
+		return nil, kerrors.InvalidInputf("If-Match requires a single group, not %d", len(groups))
+	}
+	return h.Groups.WithVersionCheck(ctx, groups[0].ID, version)
+}
 
-		groupKeys := strings.Split(groupParam, ",")
-		var matchedGroups []*group.Group
-		var notFound []string
-		groupSeen := make(map[string]bool)
-
-		for _, key := range groupKeys {
-			key = strings.TrimSpace(key)
-			grp, err := h.Groups.GetGroup(key)
-			if err == nil {
-				if !groupSeen[grp.ID] {
-					matchedGroups = append(matchedGroups, grp)
-					groupSeen[grp.ID] = true
-				}
-				continue
-			}
-			byName := h.Groups.GetGroupsByName(key)
-			if len(byName) > 0 {
-				for _, g := range byName {
-					if !groupSeen[g.ID] {
-						matchedGroups = append(matchedGroups, g)
-						groupSeen[g.ID] = true
-					}
-				}
-			} else {
-				notFound = append(notFound, key)
+// applyGroupState applies change and then the relative adjustment adj to each
+// group, ramping over transitionMS milliseconds when it is set and positive.
+// It returns per-group error messages.
+func (h *GroupHandler) applyGroupState(ctx context.Context, groups []*group.Group, change keylight.StateChange, adj keylight.Adjustment, transitionMS *int) []string {
+	var errs []string
+	for _, grp := range groups {
+		if transitionMS != nil && *transitionMS > 0 {
+			if err := h.Groups.TransitionGroup(ctx, grp.ID, change, time.Duration(*transitionMS)*time.Millisecond); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
 			}
+			continue
 		}
-
-		if len(matchedGroups) == 0 {
-			http.Error(w, fmt.Sprintf("No groups found for: %v", notFound), http.StatusNotFound)
-			return
-		}
-
-		var reqBody struct {
-			On          *bool `json:"on,omitempty"`
-			Brightness  *int  `json:"brightness,omitempty"`
-			Temperature *int  `json:"temperature,omitempty"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-
-		var errs []string
-		for _, grp := range matchedGroups {
-			if reqBody.On != nil {
-				if err := h.Groups.SetGroupState(r.Context(), grp.ID, *reqBody.On); err != nil {
-					errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
-				}
+		if change.On != nil {
+			if err := h.Groups.SetGroupState(ctx, grp.ID, *change.On); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
 			}
-			if reqBody.Brightness != nil {
-				if err := h.Groups.SetGroupBrightness(r.Context(), grp.ID, *reqBody.Brightness); err != nil {
-					errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
-				}
+		}
+		if change.Brightness != nil {
+			if err := h.Groups.SetGroupBrightness(ctx, grp.ID, *change.Brightness); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
 			}
-			if reqBody.Temperature != nil {
-				if err := h.Groups.SetGroupTemperature(r.Context(), grp.ID, *reqBody.Temperature); err != nil {
-					errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
-				}
+		}
+		if change.Temperature != nil {
+			if err := h.Groups.SetGroupTemperature(ctx, grp.ID, *change.Temperature); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
 			}
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		if len(errs) > 0 {
-			w.WriteHeader(http.StatusMultiStatus) // 207
-			if err := json.NewEncoder(w).Encode(PartialStatusResponse{Status: "partial", Errors: errs}); err != nil {
-				slog.Error("Failed to encode partial status response", "error", err)
+		if change.HasColor() {
+			if err := h.Groups.SetGroupColor(ctx, grp.ID, change.Hue, change.Saturation); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
 			}
-			return
 		}
-		if err := json.NewEncoder(w).Encode(StatusResponse{Status: "ok"}); err != nil {
-			slog.Error("Failed to encode status response", "error", err)
+		if !adj.IsZero() {
+			if err := h.Groups.AdjustGroup(ctx, grp.ID, adj); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
+			}
 		}
 	}
-}
-
-// chiURLParam extracts a URL parameter from a Chi request.
-// This is a helper to avoid importing chi directly in handlers.
-func chiURLParam(r *http.Request, key string) string {
-	// Chi stores URL params in the request context via chi.URLParam
-	// but we can use PathValue which Chi also supports in Go 1.22+
-	return r.PathValue(key)
+	return errs
 }
 
 // Ensure GroupHandler implements the interface at compile time.
@@ -338,8 +426,11 @@ type GroupHandlers interface {
 	ListGroups(ctx context.Context, input *ListGroupsInput) (*ListGroupsOutput, error)
 	CreateGroup(ctx context.Context, input *CreateGroupInput) (*CreateGroupOutput, error)
 	GetGroup(ctx context.Context, input *GetGroupInput) (*GetGroupOutput, error)
+	UpdateGroup(ctx context.Context, input *UpdateGroupInput) (*UpdateGroupOutput, error)
+	GetGroupState(ctx context.Context, input *GetGroupStateInput) (*GetGroupStateOutput, error)
 	DeleteGroup(ctx context.Context, input *DeleteGroupInput) (*DeleteGroupOutput, error)
 	SetGroupLights(ctx context.Context, input *SetGroupLightsInput) (*SetGroupLightsOutput, error)
+	SetGroupDefaults(ctx context.Context, input *SetGroupDefaultsInput) (*SetGroupDefaultsOutput, error)
 	SetGroupState(ctx context.Context, input *SetGroupStateInput) (*SetGroupStateOutput, error)
-	SetGroupStateRaw(api huma.API) http.HandlerFunc
+	ToggleGroup(ctx context.Context, input *ToggleGroupInput) (*ToggleGroupOutput, error)
 }
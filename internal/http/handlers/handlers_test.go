@@ -2,21 +2,37 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	logfilter "github.com/jmylchreest/slog-logfilter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/jmylchreest/keylightd/internal/apikey"
+	"github.com/jmylchreest/keylightd/internal/backup"
 	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/desiredstate"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
 	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/http/mw"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/internal/logging"
+	"github.com/jmylchreest/keylightd/internal/scene"
+	"github.com/jmylchreest/keylightd/internal/schedule"
+	"github.com/jmylchreest/keylightd/internal/stats"
+	"github.com/jmylchreest/keylightd/internal/webcam"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
@@ -32,6 +48,10 @@ func (m *mockLightManager) AddLight(_ context.Context, _ keylight.Light) {}
 func (m *mockLightManager) StartCleanupWorker(_ context.Context, _ time.Duration, _ time.Duration) {
 }
 
+func (m *mockLightManager) RefreshLights(_ context.Context) map[string]*keylight.Light {
+	return m.lights
+}
+
 func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
 	l, ok := m.lights[id]
 	if !ok {
@@ -48,6 +68,9 @@ func (m *mockLightManager) SetLightState(_ context.Context, id string, pv keylig
 	if err := pv.Validate(); err != nil {
 		return err
 	}
+	if !l.Supports(pv.PropertyName()) {
+		return kerrors.InvalidInputf("light %s does not support %s", id, pv.PropertyName())
+	}
 	switch pv.PropertyName() {
 	case keylight.PropertyOn:
 		l.On = pv.Value().(bool)
@@ -55,6 +78,10 @@ func (m *mockLightManager) SetLightState(_ context.Context, id string, pv keylig
 		l.Brightness = pv.Value().(int)
 	case keylight.PropertyTemperature:
 		l.Temperature = pv.Value().(int)
+	case keylight.PropertyColor:
+		color := pv.(keylight.ColorValue)
+		hue, saturation := float64(color.Hue), float64(color.Saturation)
+		l.Hue, l.Saturation = &hue, &saturation
 	}
 	return nil
 }
@@ -68,6 +95,109 @@ func (m *mockLightManager) SetLightTemperature(ctx context.Context, id string, t
 func (m *mockLightManager) SetLightPower(ctx context.Context, id string, on bool) error {
 	return m.SetLightState(ctx, id, keylight.OnValue(on))
 }
+func (m *mockLightManager) Transition(_ context.Context, id string, c keylight.StateChange, _ time.Duration) error {
+	l, ok := m.lights[id]
+	if !ok {
+		return fmt.Errorf("light %s not found", id)
+	}
+	if c.On != nil {
+		l.On = *c.On
+	}
+	if c.Brightness != nil {
+		l.Brightness = *c.Brightness
+	}
+	if c.Temperature != nil {
+		l.Temperature = *c.Temperature
+	}
+	return nil
+}
+
+func (m *mockLightManager) ToggleLight(_ context.Context, id string) (bool, error) {
+	l, ok := m.lights[id]
+	if !ok {
+		return false, kerrors.NotFoundf("light %s not found", id)
+	}
+	l.On = !l.On
+	return l.On, nil
+}
+
+func (m *mockLightManager) SetLightName(_ context.Context, id, name string) (*keylight.Light, error) {
+	l, ok := m.lights[id]
+	if !ok {
+		return nil, kerrors.NotFoundf("light %s not found", id)
+	}
+	if len(name) > keylight.MaxLightNameLength {
+		return nil, kerrors.InvalidInputf("light name too long")
+	}
+	l.Name = name
+	return l, nil
+}
+
+func (m *mockLightManager) SetLightTags(_ context.Context, id string, tags map[string]string) (*keylight.Light, error) {
+	l, ok := m.lights[id]
+	if !ok {
+		return nil, kerrors.NotFoundf("light %s not found", id)
+	}
+	if err := keylight.ValidateTags(tags); err != nil {
+		return nil, err
+	}
+	l.Tags = tags
+	return l, nil
+}
+
+func (m *mockLightManager) SetDeviceName(ctx context.Context, id, name string) (*keylight.Light, error) {
+	if name == "" {
+		return nil, kerrors.InvalidInputf("device name cannot be empty")
+	}
+	return m.SetLightName(ctx, id, name)
+}
+
+func (m *mockLightManager) RawRequest(_ context.Context, id, method, path string, _ []byte) (*keylight.RawResponse, error) {
+	if _, ok := m.lights[id]; !ok {
+		return nil, kerrors.NotFoundf("light %s not found", id)
+	}
+	if method == "DELETE" {
+		return nil, kerrors.InvalidInputf("method must be GET, PUT or POST")
+	}
+	return &keylight.RawResponse{StatusCode: 200, Body: json.RawMessage(`{"path":"` + path + `"}`)}, nil
+}
+
+func (m *mockLightManager) GetLightSettings(_ context.Context, id string) (*keylight.LightSettings, error) {
+	if _, ok := m.lights[id]; !ok {
+		return nil, kerrors.NotFoundf("light %s not found", id)
+	}
+	return &keylight.LightSettings{PowerOnBehavior: keylight.PowerOnRestore, PowerOnBrightness: 20, PowerOnTemperature: 4700}, nil
+}
+
+func (m *mockLightManager) SetLightSettings(ctx context.Context, id string, update keylight.LightSettingsUpdate) (*keylight.LightSettings, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	settings, err := m.GetLightSettings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if update.PowerOnBehavior != nil {
+		settings.PowerOnBehavior = *update.PowerOnBehavior
+	}
+	if update.PowerOnBrightness != nil {
+		settings.PowerOnBrightness = *update.PowerOnBrightness
+	}
+	if update.PowerOnTemperature != nil {
+		settings.PowerOnTemperature = *update.PowerOnTemperature
+	}
+	return settings, nil
+}
+
+func (m *mockLightManager) AdjustLight(_ context.Context, id string, adj keylight.Adjustment) error {
+	l, ok := m.lights[id]
+	if !ok {
+		return fmt.Errorf("light %s not found", id)
+	}
+	l.Brightness = max(config.MinBrightness, min(l.Brightness+adj.Brightness, config.MaxBrightness))
+	l.Temperature = max(config.MinTemperature, min(l.Temperature+adj.Temperature, config.MaxTemperature))
+	return nil
+}
 
 var _ keylight.LightManager = (*mockLightManager)(nil)
 
@@ -98,6 +228,24 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, "ok", out.Body.Status)
 }
 
+func TestReadyCheck(t *testing.T) {
+	ready, reason := false, "initial discovery has not completed"
+	check := NewReadyCheck(func() (bool, string) { return ready, reason })
+
+	out, err := check(context.Background(), &ReadyInput{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, out.Status)
+	assert.Equal(t, "not ready", out.Body.Status)
+	assert.Equal(t, reason, out.Body.Reason)
+
+	ready, reason = true, ""
+	out, err = check(context.Background(), &ReadyInput{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, out.Status)
+	assert.Equal(t, "ready", out.Body.Status)
+	assert.Empty(t, out.Body.Reason)
+}
+
 // === Light Handler Tests ===
 
 func TestLightHandler_ListLights(t *testing.T) {
@@ -150,9 +298,15 @@ func TestLightHandler_SetLightState_On(t *testing.T) {
 	out, err := handler.SetLightState(context.Background(), &SetLightStateInput{
 		ID: "light-1",
 		Body: struct {
-			On          *bool `json:"on,omitempty" doc:"Power state"`
-			Brightness  *int  `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
-			Temperature *int  `json:"temperature,omitempty" doc:"Color temperature in Kelvin"`
+			On               *bool    `json:"on,omitempty" doc:"Power state"`
+			Brightness       *int     `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+			Temperature      *int     `json:"temperature,omitempty" doc:"Color temperature, in Kelvin unless units is mired; on a color light this switches back to white"`
+			Units            string   `json:"units,omitempty" enum:"kelvin,mired" doc:"Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin"`
+			Hue              *float64 `json:"hue,omitempty" minimum:"0" maximum:"360" doc:"Hue in degrees, for lights that support color"`
+			Saturation       *float64 `json:"saturation,omitempty" minimum:"0" maximum:"100" doc:"Saturation percentage, for lights that support color"`
+			BrightnessDelta  *int     `json:"brightness_delta,omitempty" doc:"Change brightness by this many percentage points, relative to the current value and clamped to the valid range"`
+			TemperatureDelta *int     `json:"temperature_delta,omitempty" doc:"Change color temperature by this many Kelvin, relative to the current value and clamped to the valid range"`
+			TransitionMS     *int     `json:"transition_ms,omitempty" minimum:"0" doc:"Ramp brightness and temperature over this many milliseconds instead of applying instantly"`
 		}{On: &on},
 	})
 	require.NoError(t, err)
@@ -168,9 +322,15 @@ func TestLightHandler_SetLightState_Brightness(t *testing.T) {
 	out, err := handler.SetLightState(context.Background(), &SetLightStateInput{
 		ID: "light-1",
 		Body: struct {
-			On          *bool `json:"on,omitempty" doc:"Power state"`
-			Brightness  *int  `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
-			Temperature *int  `json:"temperature,omitempty" doc:"Color temperature in Kelvin"`
+			On               *bool    `json:"on,omitempty" doc:"Power state"`
+			Brightness       *int     `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+			Temperature      *int     `json:"temperature,omitempty" doc:"Color temperature, in Kelvin unless units is mired; on a color light this switches back to white"`
+			Units            string   `json:"units,omitempty" enum:"kelvin,mired" doc:"Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin"`
+			Hue              *float64 `json:"hue,omitempty" minimum:"0" maximum:"360" doc:"Hue in degrees, for lights that support color"`
+			Saturation       *float64 `json:"saturation,omitempty" minimum:"0" maximum:"100" doc:"Saturation percentage, for lights that support color"`
+			BrightnessDelta  *int     `json:"brightness_delta,omitempty" doc:"Change brightness by this many percentage points, relative to the current value and clamped to the valid range"`
+			TemperatureDelta *int     `json:"temperature_delta,omitempty" doc:"Change color temperature by this many Kelvin, relative to the current value and clamped to the valid range"`
+			TransitionMS     *int     `json:"transition_ms,omitempty" minimum:"0" doc:"Ramp brightness and temperature over this many milliseconds instead of applying instantly"`
 		}{Brightness: &brightness},
 	})
 	require.NoError(t, err)
@@ -178,6 +338,263 @@ func TestLightHandler_SetLightState_Brightness(t *testing.T) {
 	assert.Equal(t, 80, lights.lights["light-1"].Brightness)
 }
 
+func TestLightHandler_SetLightState_Relative(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	input := &SetLightStateInput{ID: "light-1"}
+	delta, tempDelta := -20, 300
+	input.Body.BrightnessDelta = &delta
+	input.Body.TemperatureDelta = &tempDelta
+	out, err := handler.SetLightState(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out.Body.Status)
+	assert.Equal(t, 30, lights.lights["light-1"].Brightness)
+	assert.Equal(t, 5300, lights.lights["light-1"].Temperature)
+
+	brightness := 40
+	input.Body.Brightness = &brightness
+	_, err = handler.SetLightState(context.Background(), input)
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+
+	transition := 1000
+	input.Body.Brightness = nil
+	input.Body.TransitionMS = &transition
+	_, err = handler.SetLightState(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+}
+
+func TestLightHandler_SetLightState_TagSelector(t *testing.T) {
+	lights := newMockLights()
+	lights.lights["light-1"].Tags = map[string]string{"location": "desk", "side": "left"}
+	lights.lights["light-2"].Tags = map[string]string{"location": "desk", "side": "right"}
+	handler := &LightHandler{Lights: lights}
+
+	brightness := 40
+	input := &SetLightStateInput{ID: "tag:location=desk"}
+	input.Body.Brightness = &brightness
+	_, err := handler.SetLightState(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 40, lights.lights["light-1"].Brightness)
+	assert.Equal(t, 40, lights.lights["light-2"].Brightness)
+
+	brightness = 10
+	input.ID = "tag:side=left"
+	_, err = handler.SetLightState(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 10, lights.lights["light-1"].Brightness)
+	assert.Equal(t, 40, lights.lights["light-2"].Brightness)
+
+	var se huma.StatusError
+	input.ID = "tag:side=middle"
+	_, err = handler.SetLightState(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusNotFound, se.GetStatus())
+
+	input.ID = "tag:side=left"
+	input.IfMatch = `"1"`
+	_, err = handler.SetLightState(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+}
+
+func TestLightHandler_SetLightState_Mireds(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	temperature := 200
+	input := &SetLightStateInput{ID: "light-1"}
+	input.Body.Temperature = &temperature
+	input.Body.Units = keylight.UnitMired
+	_, err := handler.SetLightState(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 5000, lights.lights["light-1"].Temperature)
+
+	temperature = 100 // 10000K
+	input.Body.Temperature = &temperature
+	_, err = handler.SetLightState(context.Background(), input)
+	require.Error(t, err)
+
+	var se huma.StatusError
+	input.Body.Units = "celsius"
+	_, err = handler.SetLightState(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+}
+
+func TestLightHandler_SetLightTags(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	input := &SetLightTagsInput{ID: "light-1"}
+	input.Body.Tags = map[string]string{"side": "left"}
+	out, err := handler.SetLightTags(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"side": "left"}, out.Body.Tags)
+
+	var se huma.StatusError
+	input.Body.Tags = map[string]string{"bad key": "x"}
+	_, err = handler.SetLightTags(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+
+	input.ID = "nope"
+	input.Body.Tags = nil
+	_, err = handler.SetLightTags(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusNotFound, se.GetStatus())
+}
+
+func TestLightHandler_ToggleLight(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	wasOn := lights.lights["light-1"].On
+	out, err := handler.ToggleLight(context.Background(), &ToggleLightInput{ID: "light-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out.Body.Status)
+	assert.Equal(t, !wasOn, out.Body.On)
+	assert.Equal(t, !wasOn, lights.lights["light-1"].On)
+
+	_, err = handler.ToggleLight(context.Background(), &ToggleLightInput{ID: "nope"})
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusNotFound, se.GetStatus())
+}
+
+func TestLightHandler_SetLightName(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	input := &SetLightNameInput{ID: "light-1"}
+	input.Body.Name = "Desk Left"
+	out, err := handler.SetLightName(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "Desk Left", out.Body.Name)
+	assert.Equal(t, "Desk Left", lights.lights["light-1"].Name)
+
+	_, err = handler.SetLightName(context.Background(), &SetLightNameInput{ID: "nope"})
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusNotFound, se.GetStatus())
+
+	input.Body.Name = strings.Repeat("x", keylight.MaxLightNameLength+1)
+	_, err = handler.SetLightName(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+}
+
+func TestLightHandler_SetDeviceName(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	input := &SetDeviceNameInput{ID: "light-1"}
+	input.Body.Name = "Key Light Left"
+	out, err := handler.SetDeviceName(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "Key Light Left", out.Body.Name)
+
+	input.Body.Name = ""
+	_, err = handler.SetDeviceName(context.Background(), input)
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+}
+
+func TestLightHandler_RawRequest(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	input := &RawRequestInput{ID: "light-1"}
+	input.Body.Method = "GET"
+	input.Body.Path = "/elgato/lights/settings"
+	out, err := handler.RawRequest(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 200, out.Body.StatusCode)
+	assert.JSONEq(t, `{"path":"/elgato/lights/settings"}`, string(out.Body.Body))
+
+	input.Body.Method = "DELETE"
+	_, err = handler.RawRequest(context.Background(), input)
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+
+	input.ID = "nope"
+	input.Body.Method = "GET"
+	_, err = handler.RawRequest(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusNotFound, se.GetStatus())
+}
+
+func TestLightHandler_LightSettings(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	out, err := handler.GetLightSettings(context.Background(), &GetLightSettingsInput{ID: "light-1"})
+	require.NoError(t, err)
+	assert.Equal(t, keylight.PowerOnRestore, out.Body.PowerOnBehavior)
+
+	input := &SetLightSettingsInput{ID: "light-1"}
+	behavior, temperature := keylight.PowerOnDefault, 5000
+	input.Body.PowerOnBehavior = &behavior
+	input.Body.PowerOnTemperature = &temperature
+	out, err = handler.SetLightSettings(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, keylight.LightSettings{PowerOnBehavior: keylight.PowerOnDefault, PowerOnBrightness: 20, PowerOnTemperature: 5000}, out.Body)
+
+	_, err = handler.SetLightSettings(context.Background(), &SetLightSettingsInput{ID: "light-1"})
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+
+	_, err = handler.GetLightSettings(context.Background(), &GetLightSettingsInput{ID: "nope"})
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusNotFound, se.GetStatus())
+}
+
+func TestLightHandler_SetLightsState(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	on, brightness := true, 30
+	input := &SetLightsStateInput{}
+	input.Body.Lights = []LightStateUpdate{
+		{ID: "light-1", Brightness: &brightness},
+		{ID: "light-2", On: &on},
+	}
+	out, err := handler.SetLightsState(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, out.Status)
+	assert.Equal(t, "ok", out.Body.Status)
+	assert.Equal(t, 30, lights.lights["light-1"].Brightness)
+	assert.True(t, lights.lights["light-2"].On)
+}
+
+func TestLightHandler_SetLightsState_Rejected(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	on, brightness := false, 500
+	input := &SetLightsStateInput{}
+	input.Body.Lights = []LightStateUpdate{
+		{ID: "light-1", On: &on},
+		{ID: "light-2", Brightness: &brightness},
+	}
+	_, err := handler.SetLightsState(context.Background(), input)
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+	assert.True(t, lights.lights["light-1"].On, "no update should be applied")
+
+	input.Body.Lights = []LightStateUpdate{{ID: "missing", On: &on}}
+	_, err = handler.SetLightsState(context.Background(), input)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusNotFound, se.GetStatus())
+}
+
 func TestLightHandler_SetLightState_MultipleProperties(t *testing.T) {
 	lights := newMockLights()
 	handler := &LightHandler{Lights: lights}
@@ -187,9 +604,15 @@ func TestLightHandler_SetLightState_MultipleProperties(t *testing.T) {
 	out, err := handler.SetLightState(context.Background(), &SetLightStateInput{
 		ID: "light-2",
 		Body: struct {
-			On          *bool `json:"on,omitempty" doc:"Power state"`
-			Brightness  *int  `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
-			Temperature *int  `json:"temperature,omitempty" doc:"Color temperature in Kelvin"`
+			On               *bool    `json:"on,omitempty" doc:"Power state"`
+			Brightness       *int     `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+			Temperature      *int     `json:"temperature,omitempty" doc:"Color temperature, in Kelvin unless units is mired; on a color light this switches back to white"`
+			Units            string   `json:"units,omitempty" enum:"kelvin,mired" doc:"Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin"`
+			Hue              *float64 `json:"hue,omitempty" minimum:"0" maximum:"360" doc:"Hue in degrees, for lights that support color"`
+			Saturation       *float64 `json:"saturation,omitempty" minimum:"0" maximum:"100" doc:"Saturation percentage, for lights that support color"`
+			BrightnessDelta  *int     `json:"brightness_delta,omitempty" doc:"Change brightness by this many percentage points, relative to the current value and clamped to the valid range"`
+			TemperatureDelta *int     `json:"temperature_delta,omitempty" doc:"Change color temperature by this many Kelvin, relative to the current value and clamped to the valid range"`
+			TransitionMS     *int     `json:"transition_ms,omitempty" minimum:"0" doc:"Ramp brightness and temperature over this many milliseconds instead of applying instantly"`
 		}{On: &on, Brightness: &brightness},
 	})
 	require.NoError(t, err)
@@ -198,6 +621,37 @@ func TestLightHandler_SetLightState_MultipleProperties(t *testing.T) {
 	assert.Equal(t, 90, lights.lights["light-2"].Brightness)
 }
 
+func TestLightHandler_SetLightState_Color(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	lights.lights["light-1"].Capabilities = &keylight.Capabilities{Color: true}
+	input := &SetLightStateInput{ID: "light-1"}
+	hue, saturation := 200.0, 75.0
+	input.Body.Hue, input.Body.Saturation = &hue, &saturation
+	out, err := handler.SetLightState(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out.Body.Status)
+	require.NotNil(t, lights.lights["light-1"].Hue)
+	assert.InDelta(t, 200, *lights.lights["light-1"].Hue, 0.001)
+	assert.InDelta(t, 75, *lights.lights["light-1"].Saturation, 0.001)
+}
+
+func TestLightHandler_SetLightState_Unsupported(t *testing.T) {
+	lights := newMockLights()
+	handler := &LightHandler{Lights: lights}
+
+	input := &SetLightStateInput{ID: "light-1"}
+	hue := 200.0
+	input.Body.Hue = &hue
+	_, err := handler.SetLightState(context.Background(), input)
+	require.Error(t, err)
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.GetStatus())
+	assert.Contains(t, err.Error(), "does not support hue")
+}
+
 func TestLightHandler_SetLightState_NotFound(t *testing.T) {
 	lights := newMockLights()
 	handler := &LightHandler{Lights: lights}
@@ -206,9 +660,15 @@ func TestLightHandler_SetLightState_NotFound(t *testing.T) {
 	_, err := handler.SetLightState(context.Background(), &SetLightStateInput{
 		ID: "no-such",
 		Body: struct {
-			On          *bool `json:"on,omitempty" doc:"Power state"`
-			Brightness  *int  `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
-			Temperature *int  `json:"temperature,omitempty" doc:"Color temperature in Kelvin"`
+			On               *bool    `json:"on,omitempty" doc:"Power state"`
+			Brightness       *int     `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+			Temperature      *int     `json:"temperature,omitempty" doc:"Color temperature, in Kelvin unless units is mired; on a color light this switches back to white"`
+			Units            string   `json:"units,omitempty" enum:"kelvin,mired" doc:"Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin"`
+			Hue              *float64 `json:"hue,omitempty" minimum:"0" maximum:"360" doc:"Hue in degrees, for lights that support color"`
+			Saturation       *float64 `json:"saturation,omitempty" minimum:"0" maximum:"100" doc:"Saturation percentage, for lights that support color"`
+			BrightnessDelta  *int     `json:"brightness_delta,omitempty" doc:"Change brightness by this many percentage points, relative to the current value and clamped to the valid range"`
+			TemperatureDelta *int     `json:"temperature_delta,omitempty" doc:"Change color temperature by this many Kelvin, relative to the current value and clamped to the valid range"`
+			TransitionMS     *int     `json:"transition_ms,omitempty" minimum:"0" doc:"Ramp brightness and temperature over this many milliseconds instead of applying instantly"`
 		}{On: &on},
 	})
 	assert.Error(t, err)
@@ -235,6 +695,8 @@ func TestLightFromKeylight(t *testing.T) {
 	assert.Equal(t, "10.0.0.1", resp.IP)
 	assert.Equal(t, 9123, resp.Port)
 	assert.Equal(t, 42, resp.Brightness)
+	assert.Equal(t, 300, resp.Temperature)
+	assert.Equal(t, 3333, resp.TemperatureKelvin)
 	assert.True(t, resp.On)
 	assert.Equal(t, "Key Light", resp.ProductName)
 	assert.Equal(t, "SN123", resp.SerialNumber)
@@ -262,6 +724,14 @@ func TestGroupFromInternal(t *testing.T) {
 	assert.Equal(t, "g1", resp.ID)
 	assert.Equal(t, "Office", resp.Name)
 	assert.Equal(t, []string{"l1", "l2"}, resp.Lights)
+	assert.Nil(t, resp.CreatedAt, "unrecorded times are left out")
+
+	created := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	g.CreatedAt, g.UpdatedAt, g.LegacyID = created, created, "group-1700000000000000000"
+	resp = GroupFromInternal(g)
+	assert.Equal(t, created, *resp.CreatedAt)
+	assert.Equal(t, created, *resp.UpdatedAt)
+	assert.Equal(t, "group-1700000000000000000", resp.LegacyID)
 }
 
 func TestGroupFromInternal_NilLights(t *testing.T) {
@@ -281,6 +751,70 @@ func TestGroupsFromInternal(t *testing.T) {
 	assert.Equal(t, []string{}, result[1].Lights)
 }
 
+func TestGroupStateFromInternal(t *testing.T) {
+	resp := GroupStateFromInternal(group.Summary{ID: "g1", Name: "desk", Lights: 2, On: 1, AnyOn: true, Brightness: 40, MinKelvin: 4000, MaxKelvin: 5000, Unreachable: []string{"l2"}})
+	assert.Equal(t, 2, resp.LightsTotal)
+	assert.Equal(t, 1, resp.LightsOn)
+	assert.Equal(t, 4000, resp.MinTemperature)
+	assert.Equal(t, 5000, resp.MaxTemperature)
+	assert.Equal(t, []string{"l2"}, resp.Unreachable)
+}
+
+func TestGroupChangeResponse(t *testing.T) {
+	_, results := group.WithResults(context.Background())
+	resp := groupChangeResponse(nil, results)
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, []GroupLightResult{}, resp.Lights, "lights is always an array")
+
+	resp = groupChangeResponse([]string{"group g1: errors occurred"}, results)
+	assert.Equal(t, "partial", resp.Status)
+	assert.Equal(t, []string{"group g1: errors occurred"}, resp.Errors)
+
+	lights := GroupLightResultsFromInternal([]group.LightResult{{Group: "g1", Light: "l1", Status: group.ResultFailed, Error: "timeout"}})
+	assert.Equal(t, []GroupLightResult{{Group: "g1", Light: "l1", Status: "failed", Error: "timeout"}}, lights)
+}
+
+func TestVersionFromIfMatch(t *testing.T) {
+	version, strict, err := versionFromIfMatch(versionETag(42))
+	require.NoError(t, err)
+	assert.True(t, strict)
+	assert.Equal(t, uint64(42), version)
+
+	_, strict, err = versionFromIfMatch("*")
+	require.NoError(t, err)
+	assert.False(t, strict, "* matches any version")
+
+	_, strict, err = versionFromIfMatch("")
+	require.NoError(t, err)
+	assert.False(t, strict)
+
+	_, _, err = versionFromIfMatch(`W/"abc"`)
+	assert.True(t, kerrors.IsInvalidInput(err))
+}
+
+func TestLightStatsFromInternal(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	resp := LightStatsFromInternal(stats.Stats{ID: "l1", On: true, OnTime: 90*time.Second + 500*time.Millisecond, Toggles: 2, Since: since})
+	assert.Equal(t, int64(90), resp.OnSeconds)
+	assert.Nil(t, resp.LastChange, "zero times should be left out")
+	assert.Equal(t, since, resp.Since)
+}
+
+func TestGroupStatsFromInternal(t *testing.T) {
+	toggled := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	resp := GroupStatsFromInternal("g1", stats.GroupStats{OnTime: time.Minute, Toggles: 3, LastToggle: toggled,
+		Lights: []stats.Stats{{ID: "l1", OnTime: time.Minute, Toggles: 3, LastToggle: toggled}}})
+	assert.Equal(t, "g1", resp.ID)
+	assert.Equal(t, int64(60), resp.OnSeconds)
+	require.NotNil(t, resp.LastToggle)
+	assert.Equal(t, toggled, *resp.LastToggle)
+	require.Len(t, resp.Lights, 1)
+	assert.Equal(t, "l1", resp.Lights[0].ID)
+
+	empty := GroupStatsFromInternal("g2", stats.GroupStats{})
+	assert.Equal(t, []LightStatsResponse{}, empty.Lights, "no lights should become empty slice")
+}
+
 // === Logging Handler Tests ===
 
 func TestLevelToString(t *testing.T) {
@@ -302,6 +836,60 @@ func TestLevelToString(t *testing.T) {
 	}
 }
 
+func TestLoggingHandler_AddDeleteFilter(t *testing.T) {
+	logfilter.New(logfilter.WithOutput(io.Discard))
+	t.Cleanup(func() { logfilter.SetFilters(nil) })
+	handler := &LoggingHandler{Logger: slog.New(slog.DiscardHandler)}
+
+	input := &AddFilterInput{Body: LogFilterResponse{Type: "component", Pattern: "http*", Level: "debug", Enabled: true}}
+	out, err := handler.AddFilter(context.Background(), input)
+	require.NoError(t, err)
+	require.Len(t, out.Body.Filters, 1)
+	assert.Equal(t, "http*", out.Body.Filters[0].Pattern)
+
+	input.Body.Level = "loud"
+	_, err = handler.AddFilter(context.Background(), input)
+	assertStatusCode(t, err, 400)
+
+	level, err := handler.GetLevel(context.Background(), &GetLevelInput{})
+	require.NoError(t, err)
+	assert.Equal(t, LevelToString(logfilter.GetLevel()), level.Body.Level)
+
+	_, err = handler.DeleteFilter(context.Background(), &DeleteFilterInput{Type: "component", Pattern: "http*"})
+	require.NoError(t, err)
+	assert.Empty(t, logfilter.GetFilters())
+
+	_, err = handler.DeleteFilter(context.Background(), &DeleteFilterInput{Type: "component", Pattern: "http*"})
+	assertStatusCode(t, err, 404)
+}
+
+func TestLoggingHandler_GetLogs(t *testing.T) {
+	buf := logging.NewBuffer(10)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger.Debug("debug line")
+	logger.Warn("warn line")
+	handler := &LoggingHandler{Logger: slog.New(slog.DiscardHandler), Buffer: buf}
+
+	out, err := handler.GetLogs(context.Background(), &GetLogsInput{})
+	require.NoError(t, err)
+	require.Len(t, out.Body.Entries, 2)
+	assert.Equal(t, "debug", out.Body.Entries[0].Level)
+
+	out, err = handler.GetLogs(context.Background(), &GetLogsInput{Level: "warn", Since: "1m"})
+	require.NoError(t, err)
+	require.Len(t, out.Body.Entries, 1)
+	assert.Equal(t, "warn line", out.Body.Entries[0].Message)
+	assert.Contains(t, out.Body.Entries[0].Line, `"msg":"warn line"`)
+
+	_, err = handler.GetLogs(context.Background(), &GetLogsInput{Since: "yesterday"})
+	assertStatusCode(t, err, 400)
+
+	out, err = (&LoggingHandler{}).GetLogs(context.Background(), &GetLogsInput{})
+	require.NoError(t, err)
+	assert.NotNil(t, out.Body.Entries, "no buffer is an empty list")
+	assert.Empty(t, out.Body.Entries)
+}
+
 // === joinStrings Tests ===
 
 func TestJoinStrings(t *testing.T) {
@@ -373,14 +961,97 @@ func TestGroupHandler_SetGroupLights_NotFound(t *testing.T) {
 	assertStatusCode(t, err, 404)
 }
 
+func TestGroupHandler_SetGroupState(t *testing.T) {
+	lights := newMockLights()
+	groups := newHandlerTestGroupManagerWith(t, lights)
+	handler := &GroupHandler{Groups: groups, Lights: lights}
+	_, err := groups.CreateGroup(context.Background(), "office-desk", []string{"light-2"})
+	require.NoError(t, err)
+
+	on := true
+	input := &SetGroupStateInput{ID: "office-*"}
+	input.Body.On = &on
+	out, err := handler.SetGroupState(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, out.Status)
+	assert.Equal(t, "ok", out.Body.Status)
+	assert.True(t, lights.lights["light-2"].On)
+
+	input.ID = "no-such-group"
+	_, err = handler.SetGroupState(context.Background(), input)
+	assertStatusCode(t, err, 404)
+	assert.Equal(t, map[string]any{"not_found": []string{"no-such-group"}}, err.(*mw.ErrorModel).Details)
+}
+
+func TestGroupHandler_SetGroupDefaults(t *testing.T) {
+	groups := newHandlerTestGroupManager(t)
+	handler := &GroupHandler{Groups: groups, Lights: newMockLights()}
+	grp, err := groups.CreateGroup(context.Background(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+
+	brightness := 40
+	input := &SetGroupDefaultsInput{ID: grp.ID}
+	input.Body.Brightness = &brightness
+	input.Body.ApplyOnJoin = true
+	_, err = handler.SetGroupDefaults(context.Background(), input)
+	require.NoError(t, err)
+
+	out, err := handler.GetGroup(context.Background(), &GetGroupInput{ID: grp.ID})
+	require.NoError(t, err)
+	require.NotNil(t, out.Body.Defaults)
+	assert.Equal(t, 40, *out.Body.Defaults.Brightness)
+	assert.Nil(t, out.Body.Defaults.Temperature)
+	assert.True(t, out.Body.Defaults.ApplyOnJoin)
+
+	bad := 9000
+	input.Body.Brightness = nil
+	input.Body.Temperature = &bad
+	_, err = handler.SetGroupDefaults(context.Background(), input)
+	assertStatusCode(t, err, 400)
+
+	_, err = handler.SetGroupDefaults(context.Background(), &SetGroupDefaultsInput{ID: "no-such-group"})
+	assertStatusCode(t, err, 404)
+}
+
+func TestGroupHandler_UpdateGroup(t *testing.T) {
+	groups := newHandlerTestGroupManager(t)
+	handler := &GroupHandler{Groups: groups, Lights: newMockLights()}
+	grp, err := groups.CreateGroup(context.Background(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+
+	name, color := "Studio", "#00aaff"
+	input := &UpdateGroupInput{ID: grp.ID}
+	input.Body.Name = &name
+	input.Body.Color = &color
+	out, err := handler.UpdateGroup(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "Studio", out.Body.Name)
+	assert.Equal(t, "#00aaff", out.Body.Color)
+	assert.Equal(t, []string{"light-1"}, out.Body.Lights)
+
+	_, err = handler.UpdateGroup(context.Background(), &UpdateGroupInput{ID: grp.ID})
+	assertStatusCode(t, err, 400)
+
+	_, err = handler.UpdateGroup(context.Background(), &UpdateGroupInput{ID: "no-such-group"})
+	assertStatusCode(t, err, 400)
+
+	input.ID = "no-such-group"
+	_, err = handler.UpdateGroup(context.Background(), input)
+	assertStatusCode(t, err, 404)
+}
+
 func newHandlerTestGroupManager(t *testing.T) *group.Manager {
+	return newHandlerTestGroupManagerWith(t, newMockLights())
+}
+
+func newHandlerTestGroupManagerWith(t *testing.T, lights keylight.LightManager) *group.Manager {
 	t.Helper()
 	tmpDir := t.TempDir()
 	cfgPath := filepath.Join(tmpDir, "config.yaml")
 	cfg, err := config.Load("config.yaml", cfgPath)
 	require.NoError(t, err)
 
-	return group.NewManager(slog.New(slog.DiscardHandler), newMockLights(), cfg)
+	return group.NewManager(slog.New(slog.DiscardHandler), lights, cfg)
 }
 
 func assertStatusCode(t *testing.T, err error, want int) {
@@ -390,3 +1061,213 @@ func assertStatusCode(t *testing.T, err error, want int) {
 	require.True(t, errors.As(err, &statusErr), "expected huma.StatusError, got %T", err)
 	assert.Equal(t, want, statusErr.GetStatus())
 }
+
+func TestStreamDeckHandler_GetState(t *testing.T) {
+	lights := newMockLights()
+	lights.lights["light-1"].Temperature = 200 // 5000K
+	lights.lights["light-2"].Temperature = 250 // 4000K
+	groups := newHandlerTestGroupManagerWith(t, lights)
+	_, err := groups.CreateGroup(context.Background(), "Office", []string{"light-1", "light-2"})
+	require.NoError(t, err)
+	handler := &StreamDeckHandler{Groups: groups, Lights: lights}
+
+	out, err := handler.GetState(context.Background(), &GetStreamDeckStateInput{})
+	require.NoError(t, err)
+	require.Len(t, out.Body.Lights, 2)
+	assert.Equal(t, "Test Light 1", out.Body.Lights[0].Name)
+	assert.Equal(t, 5000, out.Body.Lights[0].Temperature)
+	assert.Equal(t, "Test Light 2", out.Body.Lights[1].Name)
+
+	var office *StreamDeckItem
+	for i := range out.Body.Groups {
+		if out.Body.Groups[i].Name == "Office" {
+			office = &out.Body.Groups[i]
+		}
+	}
+	require.NotNil(t, office)
+	assert.True(t, office.On, "a group with any light on is on")
+	assert.Equal(t, 62, office.Brightness)
+	assert.Equal(t, 4500, office.Temperature)
+	assert.Empty(t, office.Status)
+}
+
+func TestStreamDeckHandler_Action(t *testing.T) {
+	lights := newMockLights()
+	groups := newHandlerTestGroupManagerWith(t, lights)
+	grp, err := groups.CreateGroup(context.Background(), "Office", []string{"light-1", "light-2"})
+	require.NoError(t, err)
+	handler := &StreamDeckHandler{Groups: groups, Lights: lights}
+
+	action := func(target, id, action string, value, step *int) (*StreamDeckActionOutput, error) {
+		input := &StreamDeckActionInput{}
+		input.Body.Target = target
+		input.Body.ID = id
+		input.Body.Action = action
+		input.Body.Value = value
+		input.Body.Step = step
+		return handler.Action(context.Background(), input)
+	}
+	intPtr := func(v int) *int { return &v }
+
+	out, err := action("light", "light-1", StreamDeckToggle, nil, nil)
+	require.NoError(t, err)
+	assert.False(t, out.Body.On)
+	assert.Equal(t, "light-1", out.Body.ID)
+
+	out, err = action("light", "light-2", StreamDeckBrightness, nil, intPtr(-10))
+	require.NoError(t, err)
+	assert.Equal(t, 65, out.Body.Brightness)
+
+	out, err = action("group", grp.ID, StreamDeckOn, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, out.Body.On)
+	assert.True(t, lights.lights["light-1"].On)
+	assert.True(t, lights.lights["light-2"].On)
+
+	out, err = action("group", grp.ID, StreamDeckBrightness, intPtr(30), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 30, out.Body.Brightness)
+
+	_, err = action("light", "light-1", StreamDeckBrightness, nil, nil)
+	assertStatusCode(t, err, 400)
+	_, err = action("light", "light-1", StreamDeckBrightness, intPtr(30), intPtr(5))
+	assertStatusCode(t, err, 400)
+	_, err = action("light", "light-1", StreamDeckToggle, nil, intPtr(5))
+	assertStatusCode(t, err, 400)
+	_, err = action("light", "no-such-light", StreamDeckToggle, nil, nil)
+	assertStatusCode(t, err, 404)
+	_, err = action("group", "no-such-group", StreamDeckOff, nil, nil)
+	assertStatusCode(t, err, 404)
+}
+
+func TestBackupHandler_ExportImport(t *testing.T) {
+	cfg, err := config.Load("config.yaml", filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	logger := slog.New(slog.DiscardHandler)
+	lights := newMockLights()
+	groups := group.NewManager(logger, lights, cfg)
+	handler := &BackupHandler{Backup: backup.NewService(cfg, lights, groups, schedule.NewManager(logger, cfg, lights, groups), scene.NewManager(logger, cfg, lights, groups, jobs.NewManager(logger)))}
+
+	grp, err := groups.CreateGroup(context.Background(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+
+	exported, err := handler.ExportConfig(context.Background(), &ExportConfigInput{})
+	require.NoError(t, err)
+	assert.Equal(t, backup.Version, exported.Body.Version)
+	require.Len(t, exported.Body.Groups, 1)
+	assert.Equal(t, grp.ID, exported.Body.Groups[0].ID)
+
+	doc := exported.Body
+	doc.Groups[0].Name = "Studio"
+	imported, err := handler.ImportConfig(context.Background(), &ImportConfigInput{Body: doc})
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported.Body.Groups)
+	got, err := groups.GetGroup(grp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Studio", got.Name)
+
+	_, err = handler.ImportConfig(context.Background(), &ImportConfigInput{Body: backup.ExportDocument{Version: 99}})
+	assertStatusCode(t, err, 400)
+}
+
+func TestBackupHandler_ExportSecretsNeedsWriteScope(t *testing.T) {
+	cfg, err := config.Load("config.yaml", filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	logger := slog.New(slog.DiscardHandler)
+	lights := newMockLights()
+	groups := group.NewManager(logger, lights, cfg)
+	handler := &BackupHandler{Backup: backup.NewService(cfg, lights, groups, schedule.NewManager(logger, cfg, lights, groups), scene.NewManager(logger, cfg, lights, groups, jobs.NewManager(logger)))}
+	keys := apikey.NewManager(cfg, logger)
+	readKey, err := keys.CreateScopedAPIKey("reader", 0, []string{mw.ScopeRead, mw.ScopeControl})
+	require.NoError(t, err)
+	adminKey, err := keys.CreateAPIKey("admin", 0)
+	require.NoError(t, err)
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(mw.HumaAuth(api, logger, keys, nil, nil))
+	mw.ProtectedGet(api, "/api/v1/config/export", handler.ExportConfig)
+
+	resp := api.Get("/api/v1/config/export", "X-API-Key: "+readKey.Key)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), readKey.Key)
+
+	resp = api.Get("/api/v1/config/export?include_secrets=true", "X-API-Key: "+readKey.Key)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.NotContains(t, resp.Body.String(), adminKey.Key)
+
+	resp = api.Get("/api/v1/config/export?include_secrets=true", "X-API-Key: "+adminKey.Key)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), adminKey.Key)
+}
+
+func TestErrorResponse(t *testing.T) {
+	err := errorResponse(kerrors.DeviceUnavailablef("light-1 did not respond"), "Error toggling light: %s", "light-1 did not respond")
+	assert.Equal(t, http.StatusServiceUnavailable, err.GetStatus())
+	model, ok := err.(*mw.ErrorModel)
+	require.True(t, ok)
+	assert.Equal(t, kerrors.CodeDeviceUnavailable, model.Code)
+	assert.Equal(t, "Error toggling light: light-1 did not respond", model.Detail)
+
+	err = errorResponse(errors.New("disk full"), "Error saving group: %s", "disk full")
+	assert.Equal(t, http.StatusInternalServerError, err.GetStatus())
+	assert.Equal(t, kerrors.CodeInternal, err.(*mw.ErrorModel).Code)
+}
+
+func TestDesiredStateHandler(t *testing.T) {
+	cfg, err := config.Load("config.yaml", filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	on := true
+	cfg.Config.DesiredState.Targets = []config.DesiredState{{Light: "light-1", On: &on}}
+	logger := slog.New(slog.DiscardHandler)
+	lights := newMockLights()
+	handler := &DesiredStateHandler{DesiredState: desiredstate.NewManager(logger, cfg, lights, group.NewManager(logger, lights, cfg))}
+
+	got, err := handler.GetDesiredState(context.Background(), &GetDesiredStateInput{})
+	require.NoError(t, err)
+	assert.False(t, got.Body.Enabled)
+	require.Len(t, got.Body.Targets, 1)
+	assert.Equal(t, "light:light-1", got.Body.Targets[0].Target)
+	assert.True(t, got.Body.Targets[0].Enabled)
+	assert.Nil(t, got.Body.Targets[0].LastCorrection)
+
+	setInput := &SetDesiredStateInput{}
+	setInput.Body.Enabled = true
+	set, err := handler.SetDesiredState(context.Background(), setInput)
+	require.NoError(t, err)
+	assert.True(t, set.Body.Enabled)
+
+	targetInput := &SetDesiredStateTargetInput{Target: "light:light-1"}
+	target, err := handler.SetDesiredStateTarget(context.Background(), targetInput)
+	require.NoError(t, err)
+	assert.False(t, target.Body.Targets[0].Enabled)
+
+	targetInput.Target = "group:missing"
+	_, err = handler.SetDesiredStateTarget(context.Background(), targetInput)
+	assertStatusCode(t, err, 404)
+}
+
+func TestWebcamHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := &WebcamHandler{Webcam: webcam.NewWatcher(logger, config.WebcamConfig{Enabled: true, Groups: []string{"office"}}, nil)}
+
+	got, err := handler.GetWebcam(context.Background(), &GetWebcamInput{})
+	require.NoError(t, err)
+	assert.True(t, got.Body.Enabled)
+	assert.Equal(t, "auto", got.Body.Override)
+	assert.Equal(t, []string{"office"}, got.Body.Groups)
+
+	input := &SetWebcamOverrideInput{}
+	input.Body.Override = "on"
+	set, err := handler.SetWebcamOverride(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "on", set.Body.Override)
+
+	input.Body.Override = "sometimes"
+	_, err = handler.SetWebcamOverride(context.Background(), input)
+	assertStatusCode(t, err, 400)
+
+	disabled := &WebcamHandler{Webcam: webcam.NewWatcher(logger, config.WebcamConfig{}, nil)}
+	input.Body.Override = "on"
+	_, err = disabled.SetWebcamOverride(context.Background(), input)
+	assertStatusCode(t, err, 400)
+}
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"context"
+	"net/http"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
 // --- Health Check ---
@@ -24,6 +27,40 @@ func HealthCheck(_ context.Context, _ *HealthInput) (*HealthOutput, error) {
 	return out, nil
 }
 
+// --- Readiness ---
+
+// ReadyInput is the input for the readiness endpoint.
+type ReadyInput struct{}
+
+// ReadyOutput is the output for the readiness endpoint.
+type ReadyOutput struct {
+	Status int
+	Body   struct {
+		Status string `json:"status" doc:"Service readiness status"`
+		Reason string `json:"reason,omitempty" doc:"Why the service is not ready"`
+	}
+}
+
+// NewReadyCheck returns a readiness handler. The ready function reports
+// whether the daemon can serve requests and, if not, why. Not-ready responses
+// use 503 so probes and supervisors can poll the status code alone.
+// This is a public endpoint (no auth required).
+func NewReadyCheck(ready func() (bool, string)) func(context.Context, *ReadyInput) (*ReadyOutput, error) {
+	return func(_ context.Context, _ *ReadyInput) (*ReadyOutput, error) {
+		out := &ReadyOutput{}
+		ok, reason := ready()
+		if !ok {
+			out.Status = http.StatusServiceUnavailable
+			out.Body.Status = "not ready"
+			out.Body.Reason = reason
+			return out, nil
+		}
+		out.Status = http.StatusOK
+		out.Body.Status = "ready"
+		return out, nil
+	}
+}
+
 // --- Version ---
 
 // VersionInput is the input for the version endpoint.
@@ -48,3 +85,89 @@ func NewVersionCheck(version, commit, buildDate string) func(context.Context, *V
 		return out, nil
 	}
 }
+
+// --- Daemon Info ---
+
+// DaemonInfoInput is the input for the daemon info endpoint.
+type DaemonInfoInput struct{}
+
+// DaemonInfoOutput is the output for the daemon info endpoint.
+type DaemonInfoOutput struct {
+	Body DaemonInfoResponse
+}
+
+// NewDaemonInfo returns a daemon info handler that reports the result of info.
+func NewDaemonInfo(info func() DaemonInfoResponse) func(context.Context, *DaemonInfoInput) (*DaemonInfoOutput, error) {
+	return func(_ context.Context, _ *DaemonInfoInput) (*DaemonInfoOutput, error) {
+		return &DaemonInfoOutput{Body: info()}, nil
+	}
+}
+
+// --- Discovery Scan ---
+
+// DiscoveryScanInput is the input for the discovery scan endpoint.
+type DiscoveryScanInput struct{}
+
+// DiscoveryScanOutput is the output for the discovery scan endpoint.
+type DiscoveryScanOutput struct {
+	Body DiscoveryScanResponse
+}
+
+// NewDiscoveryScan returns a handler that runs a discovery pass with scan and
+// reports what it found.
+func NewDiscoveryScan(scan func(context.Context) (DiscoveryScanResponse, error)) func(context.Context, *DiscoveryScanInput) (*DiscoveryScanOutput, error) {
+	return func(ctx context.Context, _ *DiscoveryScanInput) (*DiscoveryScanOutput, error) {
+		result, err := scan(ctx)
+		if err != nil {
+			return nil, errorResponse(err, "Discovery failed: %v", err)
+		}
+		return &DiscoveryScanOutput{Body: result}, nil
+	}
+}
+
+// --- Pending Devices ---
+
+// PendingDevicesInput is the input for listing pending devices.
+type PendingDevicesInput struct{}
+
+// PendingDevicesOutput is the output for listing pending devices.
+type PendingDevicesOutput struct {
+	Body struct {
+		Devices []PendingDeviceResponse `json:"devices" doc:"Devices that failed validation, sorted by ID"`
+	}
+}
+
+// NewPendingDevices returns a handler that lists the devices reported by list.
+func NewPendingDevices(list func() ([]PendingDeviceResponse, error)) func(context.Context, *PendingDevicesInput) (*PendingDevicesOutput, error) {
+	return func(_ context.Context, _ *PendingDevicesInput) (*PendingDevicesOutput, error) {
+		devices, err := list()
+		if err != nil {
+			return nil, errorResponse(err, "Failed to list pending devices: %v", err)
+		}
+		out := &PendingDevicesOutput{}
+		out.Body.Devices = devices
+		return out, nil
+	}
+}
+
+// AdoptDeviceInput is the input for adopting a pending device.
+type AdoptDeviceInput struct {
+	ID string `path:"id" doc:"ID of the pending device"`
+}
+
+// AdoptDeviceOutput is the output for adopting a pending device.
+type AdoptDeviceOutput struct {
+	Body LightResponse
+}
+
+// NewAdoptDevice returns a handler that adopts a pending device with adopt
+// and returns the light it became.
+func NewAdoptDevice(adopt func(context.Context, string) (*keylight.Light, error)) func(context.Context, *AdoptDeviceInput) (*AdoptDeviceOutput, error) {
+	return func(ctx context.Context, input *AdoptDeviceInput) (*AdoptDeviceOutput, error) {
+		light, err := adopt(ctx, input.ID)
+		if err != nil {
+			return nil, errorResponse(err, "Failed to adopt device %s: %v", input.ID, err)
+		}
+		return &AdoptDeviceOutput{Body: LightFromKeylight(light)}, nil
+	}
+}
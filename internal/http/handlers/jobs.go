@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/jobs"
+)
+
+// JobResponse is the API representation of a job.
+type JobResponse struct {
+	ID         string     `json:"id" doc:"Unique job identifier"`
+	Kind       string     `json:"kind" doc:"What the job does, e.g. scene.apply"`
+	Subject    string     `json:"subject,omitempty" doc:"What the job acts upon, e.g. a scene ID"`
+	Status     string     `json:"status" enum:"running,completed,failed,cancelled" doc:"Where the job has got to"`
+	Progress   any        `json:"progress,omitempty" doc:"Progress specific to the kind of job. For scene.apply jobs: step, steps, applied, entries and errors."`
+	Error      string     `json:"error,omitempty" doc:"Why the job failed"`
+	StartedAt  time.Time  `json:"started_at" doc:"When the job started"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" doc:"When the job finished"`
+}
+
+// JobFromInternal converts a jobs.Job to a JobResponse.
+func JobFromInternal(j *jobs.Job) JobResponse {
+	resp := JobResponse{
+		ID:        j.ID,
+		Kind:      j.Kind,
+		Subject:   j.Subject,
+		Status:    string(j.Status),
+		Progress:  j.Progress,
+		Error:     j.Error,
+		StartedAt: j.StartedAt,
+	}
+	if !j.FinishedAt.IsZero() {
+		resp.FinishedAt = &j.FinishedAt
+	}
+	return resp
+}
+
+// --- List Jobs ---
+
+// ListJobsInput is the input for listing jobs.
+type ListJobsInput struct{}
+
+// ListJobsOutput is the output for listing jobs.
+type ListJobsOutput struct {
+	Body []JobResponse
+}
+
+// --- Get / Cancel Job ---
+
+// JobInput is the input for getting or cancelling a job.
+type JobInput struct {
+	ID string `path:"id" doc:"Job identifier"`
+}
+
+// JobOutput is the output for operations returning a single job.
+type JobOutput struct {
+	Body JobResponse
+}
+
+// JobHandler implements job-related HTTP handlers.
+type JobHandler struct {
+	Jobs *jobs.Manager
+}
+
+// ListJobs returns running and recently finished jobs, most recent first.
+func (h *JobHandler) ListJobs(_ context.Context, _ *ListJobsInput) (*ListJobsOutput, error) {
+	all := h.Jobs.GetAll()
+	result := make([]JobResponse, len(all))
+	for i, j := range all {
+		result[i] = JobFromInternal(j)
+	}
+	return &ListJobsOutput{Body: result}, nil
+}
+
+// GetJob returns a single job by ID.
+func (h *JobHandler) GetJob(_ context.Context, input *JobInput) (*JobOutput, error) {
+	j, err := h.Jobs.Get(input.ID)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to get job: %s", err)
+	}
+	return &JobOutput{Body: JobFromInternal(j)}, nil
+}
+
+// CancelJob cancels a running job and returns it once it has stopped.
+func (h *JobHandler) CancelJob(ctx context.Context, input *JobInput) (*JobOutput, error) {
+	if err := h.Jobs.Cancel(input.ID); err != nil {
+		return nil, errorResponse(err, "Failed to cancel job: %s", err)
+	}
+	j, err := h.Jobs.Wait(ctx, input.ID)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to cancel job: %s", err)
+	}
+	return &JobOutput{Body: JobFromInternal(j)}, nil
+}
+
+// Ensure JobHandler implements the interface at compile time.
+var _ JobHandlers = (*JobHandler)(nil)
+
+// JobHandlers defines the interface for job operations.
+type JobHandlers interface {
+	ListJobs(ctx context.Context, input *ListJobsInput) (*ListJobsOutput, error)
+	GetJob(ctx context.Context, input *JobInput) (*JobOutput, error)
+	CancelJob(ctx context.Context, input *JobInput) (*JobOutput, error)
+}
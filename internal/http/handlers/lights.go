@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
@@ -31,6 +37,7 @@ type GetLightInput struct {
 
 // GetLightOutput is the output for getting a single light.
 type GetLightOutput struct {
+	ETag string `header:"ETag" doc:"The light's state version"`
 	Body LightResponse
 }
 
@@ -38,11 +45,18 @@ type GetLightOutput struct {
 
 // SetLightStateInput is the input for setting a light's state.
 type SetLightStateInput struct {
-	ID   string `path:"id" doc:"Light identifier"`
-	Body struct {
-		On          *bool `json:"on,omitempty" doc:"Power state"`
-		Brightness  *int  `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
-		Temperature *int  `json:"temperature,omitempty" doc:"Color temperature in Kelvin"`
+	ID      string `path:"id" doc:"Light identifier; \"all\" for every light; a glob pattern such as Desk* matching light IDs or names; or a tag selector such as tag:side=left or tag:location=desk,side=left to change every light with those tags"`
+	IfMatch string `header:"If-Match" doc:"Only change the light if it is still at this state version (its ETag); otherwise fail with 409. Only for a single light"`
+	Body    struct {
+		On               *bool    `json:"on,omitempty" doc:"Power state"`
+		Brightness       *int     `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+		Temperature      *int     `json:"temperature,omitempty" doc:"Color temperature, in Kelvin unless units is mired; on a color light this switches back to white"`
+		Units            string   `json:"units,omitempty" enum:"kelvin,mired" doc:"Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin"`
+		Hue              *float64 `json:"hue,omitempty" minimum:"0" maximum:"360" doc:"Hue in degrees, for lights that support color"`
+		Saturation       *float64 `json:"saturation,omitempty" minimum:"0" maximum:"100" doc:"Saturation percentage, for lights that support color"`
+		BrightnessDelta  *int     `json:"brightness_delta,omitempty" doc:"Change brightness by this many percentage points, relative to the current value and clamped to the valid range"`
+		TemperatureDelta *int     `json:"temperature_delta,omitempty" doc:"Change color temperature by this many Kelvin, relative to the current value and clamped to the valid range"`
+		TransitionMS     *int     `json:"transition_ms,omitempty" minimum:"0" doc:"Ramp brightness and temperature over this many milliseconds instead of applying instantly"`
 	}
 }
 
@@ -51,14 +65,139 @@ type SetLightStateOutput struct {
 	Body StatusResponse
 }
 
+// --- Set Lights State (batch) ---
+
+// LightStateUpdate is one entry of a batch light state request.
+type LightStateUpdate struct {
+	ID          string   `json:"id" doc:"Light identifier"`
+	On          *bool    `json:"on,omitempty" doc:"Power state"`
+	Brightness  *int     `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+	Temperature *int     `json:"temperature,omitempty" doc:"Color temperature, in Kelvin unless the request's units is mired"`
+	Hue         *float64 `json:"hue,omitempty" minimum:"0" maximum:"360" doc:"Hue in degrees, for lights that support color"`
+	Saturation  *float64 `json:"saturation,omitempty" minimum:"0" maximum:"100" doc:"Saturation percentage, for lights that support color"`
+}
+
+// SetLightsStateInput is the input for setting the state of several lights at once.
+type SetLightsStateInput struct {
+	Body struct {
+		Lights []LightStateUpdate `json:"lights" minItems:"1" doc:"Per-light state updates, applied concurrently"`
+		Units  string             `json:"units,omitempty" enum:"kelvin,mired" doc:"Unit of every update's temperature: kelvin (the default) or mired"`
+	}
+}
+
+// SetLightsStateOutput is the output for a batch light state request.
+// On success returns 200 with {"status": "ok"}.
+// On partial failure returns 207 with {"status": "partial", "errors": [...]}.
+type SetLightsStateOutput struct {
+	Status int
+	Body   BatchStatusResponse
+}
+
+// --- Toggle Light ---
+
+// ToggleLightInput is the input for toggling a light's power state.
+type ToggleLightInput struct {
+	ID string `path:"id" doc:"Light identifier"`
+}
+
+// ToggleLightOutput is the output for toggling a light's power state.
+type ToggleLightOutput struct {
+	Body ToggleResponse
+}
+
+// --- Set Light Name ---
+
+// SetLightNameInput is the input for setting a light's display name.
+type SetLightNameInput struct {
+	ID   string `path:"id" doc:"Light identifier"`
+	Body struct {
+		Name string `json:"name" maxLength:"64" doc:"Display name overriding the one reported by the device; empty restores the device's name"`
+	}
+}
+
+// SetLightNameOutput is the output for setting a light's display name.
+type SetLightNameOutput struct {
+	Body LightResponse
+}
+
+// --- Set Light Tags ---
+
+// SetLightTagsInput is the input for replacing a light's tags.
+type SetLightTagsInput struct {
+	ID   string `path:"id" doc:"Light identifier"`
+	Body struct {
+		Tags map[string]string `json:"tags" doc:"The light's tags, such as {\"location\": \"desk\", \"side\": \"left\"}, replacing any it has; empty removes them. Keys are 1-32 letters, digits, '_', '.' or '-'; values are at most 64 characters and cannot contain commas"`
+	}
+}
+
+// SetLightTagsOutput is the output for setting a light's tags.
+type SetLightTagsOutput struct {
+	Body LightResponse
+}
+
+// --- Set Device Name ---
+
+// SetDeviceNameInput is the input for changing the name stored on a light.
+type SetDeviceNameInput struct {
+	ID   string `path:"id" doc:"Light identifier"`
+	Body struct {
+		Name string `json:"name" minLength:"1" maxLength:"64" doc:"Name to store on the device"`
+	}
+}
+
+// SetDeviceNameOutput is the output for changing the name stored on a light.
+type SetDeviceNameOutput struct {
+	Body LightResponse
+}
+
+// --- Raw Request ---
+
+// RawRequestInput is the input for passing a request through to a light's own API.
+type RawRequestInput struct {
+	ID   string `path:"id" doc:"Light identifier"`
+	Body struct {
+		Method string          `json:"method" enum:"GET,PUT,POST" doc:"HTTP method to send to the device"`
+		Path   string          `json:"path" pattern:"^/elgato/" example:"/elgato/lights/settings" doc:"Device API path, under /elgato/"`
+		Body   json.RawMessage `json:"body,omitempty" doc:"JSON body to send to the device"`
+	}
+}
+
+// RawRequestOutput is the device's response to a raw request.
+type RawRequestOutput struct {
+	Body keylight.RawResponse
+}
+
+// --- Light Settings ---
+
+// GetLightSettingsInput is the input for reading the settings stored on a light.
+type GetLightSettingsInput struct {
+	ID string `path:"id" doc:"Light identifier"`
+}
+
+// SetLightSettingsInput is the input for changing the settings stored on a light.
+type SetLightSettingsInput struct {
+	ID   string `path:"id" doc:"Light identifier"`
+	Body struct {
+		PowerOnBehavior    *string `json:"power_on_behavior,omitempty" enum:"restore,default" doc:"What the light does when it gets power back: restore its last state, or turn on at the power-on brightness and temperature"`
+		PowerOnBrightness  *int    `json:"power_on_brightness,omitempty" minimum:"3" maximum:"100" doc:"Brightness (3-100) used when the power-on behavior is default"`
+		PowerOnTemperature *int    `json:"power_on_temperature,omitempty" minimum:"2900" maximum:"7000" doc:"Color temperature in Kelvin used when the power-on behavior is default"`
+	}
+}
+
+// LightSettingsOutput is the output for reading or changing a light's settings.
+type LightSettingsOutput struct {
+	Body keylight.LightSettings
+}
+
 // LightHandler implements light-related HTTP handlers.
 type LightHandler struct {
 	Lights keylight.LightManager
 }
 
-// ListLights returns all discovered lights as a map keyed by ID.
-func (h *LightHandler) ListLights(_ context.Context, _ *ListLightsInput) (*ListLightsOutput, error) {
-	lights := h.Lights.GetLights()
+// ListLights returns all discovered lights as a map keyed by ID, with their
+// states refreshed from the devices.
+func (h *LightHandler) ListLights(ctx context.Context, _ *ListLightsInput) (*ListLightsOutput, error) {
+	lights := h.Lights.RefreshLights(ctx)
 	return &ListLightsOutput{
 		Body: LightsMapFromKeylight(lights),
 	}, nil
@@ -71,40 +210,304 @@ func (h *LightHandler) GetLight(ctx context.Context, input *GetLightInput) (*Get
 		return nil, huma.Error404NotFound(fmt.Sprintf("Light not found: %s", err))
 	}
 	resp := LightFromKeylight(light)
-	return &GetLightOutput{Body: resp}, nil
+	return &GetLightOutput{ETag: versionETag(light.Version), Body: resp}, nil
 }
 
-// SetLightState sets one or more properties on a light.
+// SetLightState sets one or more properties on a light, or on every light
+// selected by "all", a glob pattern or a tag selector such as tag:side=left.
 func (h *LightHandler) SetLightState(ctx context.Context, input *SetLightStateInput) (*SetLightStateOutput, error) {
-	var errs []string
+	temperature, err := kelvinFromBody(input.Body.Temperature, input.Body.Units)
+	if err != nil {
+		return nil, err
+	}
+	input.Body.Temperature = temperature
+	adj, err := adjustmentFromBody(input.Body.Brightness, input.Body.Temperature,
+		input.Body.BrightnessDelta, input.Body.TemperatureDelta, input.Body.TransitionMS)
+	if err != nil {
+		return nil, err
+	}
+	version, strict, err := versionFromIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	ids, selected, err := keylight.TargetLights(h.Lights, input.ID)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(err.Error())
+		}
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if strict {
+		if selected {
+			return nil, huma.Error400BadRequest("If-Match can only be used to change a single light")
+		}
+		ctx = keylight.WithVersionCheck(ctx, keylight.ExpectVersion(version))
+	}
+
+	// A property the light doesn't support is the client's mistake, not the daemon's
+	var (
+		mu      sync.Mutex
+		errs    []string
+		invalid bool
+		first   error
+	)
+	fail := func(id string, err error) {
+		if selected {
+			err = fmt.Errorf("light %s: %w", id, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err.Error())
+		invalid = invalid || kerrors.IsInvalidInput(err)
+		first = cmp.Or(first, err)
+	}
 
+	// Selected lights are changed together
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Go(func() {
+			for _, err := range h.setLightState(ctx, id, input, adj) {
+				fail(id, err)
+			}
+		})
+	}
+	wg.Wait()
+	slices.Sort(errs)
+
+	if invalid {
+		return nil, huma.Error400BadRequest("Error(s) setting light state: " + joinStrings(errs))
+	}
+	if len(errs) > 0 {
+		return nil, errorResponse(first, "Error(s) setting light state: %s", joinStrings(errs))
+	}
+
+	return &SetLightStateOutput{
+		Body: StatusResponse{Status: "ok"},
+	}, nil
+}
+
+// setLightState applies the state of a set state request to one light.
+func (h *LightHandler) setLightState(ctx context.Context, id string, input *SetLightStateInput, adj keylight.Adjustment) []error {
+	if input.Body.TransitionMS != nil && *input.Body.TransitionMS > 0 {
+		change := keylight.StateChange{On: input.Body.On, Brightness: input.Body.Brightness, Temperature: input.Body.Temperature,
+			Hue: input.Body.Hue, Saturation: input.Body.Saturation}
+		if err := h.Lights.Transition(ctx, id, change, time.Duration(*input.Body.TransitionMS)*time.Millisecond); err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+
+	var errs []error
 	if input.Body.On != nil {
-		if err := h.Lights.SetLightState(ctx, input.ID, keylight.OnValue(*input.Body.On)); err != nil {
-			errs = append(errs, err.Error())
+		if err := h.Lights.SetLightState(ctx, id, keylight.OnValue(*input.Body.On)); err != nil {
+			errs = append(errs, err)
 		}
 	}
 	if input.Body.Brightness != nil {
-		if err := h.Lights.SetLightState(ctx, input.ID, keylight.BrightnessValue(*input.Body.Brightness)); err != nil {
-			errs = append(errs, err.Error())
+		if err := h.Lights.SetLightState(ctx, id, keylight.BrightnessValue(*input.Body.Brightness)); err != nil {
+			errs = append(errs, err)
 		}
 	}
 	if input.Body.Temperature != nil {
-		if err := h.Lights.SetLightState(ctx, input.ID, keylight.TemperatureValue(*input.Body.Temperature)); err != nil {
-			errs = append(errs, err.Error())
+		if err := h.Lights.SetLightState(ctx, id, keylight.TemperatureValue(*input.Body.Temperature)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if color, ok := keylight.ColorChange(input.Body.Hue, input.Body.Saturation); ok {
+		if err := h.Lights.SetLightState(ctx, id, color); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !adj.IsZero() {
+		if err := h.Lights.AdjustLight(ctx, id, adj); err != nil {
+			errs = append(errs, err)
 		}
 	}
+	return errs
+}
 
-	if len(errs) > 0 {
-		return nil, huma.Error500InternalServerError(
-			"Error(s) setting light state: " + joinStrings(errs),
-		)
+// SetLightsState applies state updates to several lights concurrently. The whole
+// batch is rejected if any update is invalid or names an unknown light.
+// Returns 200 on full success, 207 if some devices failed.
+func (h *LightHandler) SetLightsState(ctx context.Context, input *SetLightsStateInput) (*SetLightsStateOutput, error) {
+	updates := make([]keylight.LightUpdate, 0, len(input.Body.Lights))
+	for _, l := range input.Body.Lights {
+		temperature, err := kelvinFromBody(l.Temperature, input.Body.Units)
+		if err != nil {
+			return nil, err
+		}
+		l.Temperature = temperature
+		updates = append(updates, keylight.LightUpdate{
+			ID:          l.ID,
+			StateChange: keylight.StateChange{On: l.On, Brightness: l.Brightness, Temperature: l.Temperature, Hue: l.Hue, Saturation: l.Saturation},
+		})
 	}
 
-	return &SetLightStateOutput{
-		Body: StatusResponse{Status: "ok"},
+	errs, err := keylight.ApplyBatch(ctx, h.Lights, updates)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(err.Error())
+		}
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if len(errs) > 0 {
+		return &SetLightsStateOutput{
+			Status: http.StatusMultiStatus,
+			Body:   BatchStatusResponse{Status: "partial", Errors: keylight.BatchErrorMessages(errs)},
+		}, nil
+	}
+	return &SetLightsStateOutput{
+		Status: http.StatusOK,
+		Body:   BatchStatusResponse{Status: "ok"},
 	}, nil
 }
 
+// SetLightTags replaces the tags of a light.
+func (h *LightHandler) SetLightTags(ctx context.Context, input *SetLightTagsInput) (*SetLightTagsOutput, error) {
+	light, err := h.Lights.SetLightTags(ctx, input.ID, input.Body.Tags)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(fmt.Sprintf("Light not found: %s", err))
+		}
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Error setting light tags: %s", err)
+	}
+	return &SetLightTagsOutput{Body: LightFromKeylight(light)}, nil
+}
+
+// ToggleLight inverts a light's power state and returns the new state.
+func (h *LightHandler) ToggleLight(ctx context.Context, input *ToggleLightInput) (*ToggleLightOutput, error) {
+	on, err := h.Lights.ToggleLight(ctx, input.ID)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(fmt.Sprintf("Light not found: %s", err))
+		}
+		return nil, errorResponse(err, "Error toggling light: %s", err)
+	}
+	return &ToggleLightOutput{Body: ToggleResponse{Status: "ok", On: on}}, nil
+}
+
+// SetLightName stores a display name override for a light.
+func (h *LightHandler) SetLightName(ctx context.Context, input *SetLightNameInput) (*SetLightNameOutput, error) {
+	light, err := h.Lights.SetLightName(ctx, input.ID, input.Body.Name)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(fmt.Sprintf("Light not found: %s", err))
+		}
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Error setting light name: %s", err)
+	}
+	return &SetLightNameOutput{Body: LightFromKeylight(light)}, nil
+}
+
+// SetDeviceName writes a new display name to the light itself.
+func (h *LightHandler) SetDeviceName(ctx context.Context, input *SetDeviceNameInput) (*SetDeviceNameOutput, error) {
+	light, err := h.Lights.SetDeviceName(ctx, input.ID, input.Body.Name)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(fmt.Sprintf("Light not found: %s", err))
+		}
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Error setting device name: %s", err)
+	}
+	return &SetDeviceNameOutput{Body: LightFromKeylight(light)}, nil
+}
+
+// RawRequest passes a request straight through to a light's own API and
+// returns the device's response, whatever its status code.
+func (h *LightHandler) RawRequest(ctx context.Context, input *RawRequestInput) (*RawRequestOutput, error) {
+	resp, err := h.Lights.RawRequest(ctx, input.ID, input.Body.Method, input.Body.Path, input.Body.Body)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(fmt.Sprintf("Light not found: %s", err))
+		}
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Error sending raw request: %s", err)
+	}
+	return &RawRequestOutput{Body: *resp}, nil
+}
+
+// GetLightSettings returns the power-on settings stored on a light.
+func (h *LightHandler) GetLightSettings(ctx context.Context, input *GetLightSettingsInput) (*LightSettingsOutput, error) {
+	settings, err := h.Lights.GetLightSettings(ctx, input.ID)
+	if err != nil {
+		return nil, lightSettingsError(err)
+	}
+	return &LightSettingsOutput{Body: *settings}, nil
+}
+
+// SetLightSettings changes the power-on settings stored on a light. Settings
+// not given are left as they are.
+func (h *LightHandler) SetLightSettings(ctx context.Context, input *SetLightSettingsInput) (*LightSettingsOutput, error) {
+	settings, err := h.Lights.SetLightSettings(ctx, input.ID, keylight.LightSettingsUpdate{
+		PowerOnBehavior:    input.Body.PowerOnBehavior,
+		PowerOnBrightness:  input.Body.PowerOnBrightness,
+		PowerOnTemperature: input.Body.PowerOnTemperature,
+	})
+	if err != nil {
+		return nil, lightSettingsError(err)
+	}
+	return &LightSettingsOutput{Body: *settings}, nil
+}
+
+// lightSettingsError maps an error reading or changing light settings to an HTTP error.
+func lightSettingsError(err error) error {
+	if kerrors.IsNotFound(err) {
+		return huma.Error404NotFound(fmt.Sprintf("Light not found: %s", err))
+	}
+	if kerrors.IsInvalidInput(err) {
+		return huma.Error400BadRequest(err.Error())
+	}
+	return errorResponse(err, "Error accessing light settings: %s", err)
+}
+
+// kelvinFromBody converts the temperature of a request body from units to
+// Kelvin.
+func kelvinFromBody(temperature *int, units string) (*int, error) {
+	if err := keylight.ValidateTemperatureUnits(units); err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if temperature == nil {
+		return nil, nil
+	}
+	kelvin, err := keylight.TemperatureToKelvin(*temperature, units)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	return &kelvin, nil
+}
+
+// adjustmentFromBody builds a relative adjustment from the brightness_delta and
+// temperature_delta request fields. A property cannot be set both absolutely and
+// relatively, and relative changes cannot be combined with a transition.
+func adjustmentFromBody(brightness, temperature, brightnessDelta, temperatureDelta, transitionMS *int) (keylight.Adjustment, error) {
+	var adj keylight.Adjustment
+	if brightnessDelta != nil {
+		if brightness != nil {
+			return adj, huma.Error400BadRequest("brightness and brightness_delta cannot both be set")
+		}
+		adj.Brightness = *brightnessDelta
+	}
+	if temperatureDelta != nil {
+		if temperature != nil {
+			return adj, huma.Error400BadRequest("temperature and temperature_delta cannot both be set")
+		}
+		adj.Temperature = *temperatureDelta
+	}
+	if !adj.IsZero() && transitionMS != nil && *transitionMS > 0 {
+		return adj, huma.Error400BadRequest("relative changes cannot be combined with transition_ms")
+	}
+	return adj, nil
+}
+
 // joinStrings joins strings with "; " separator.
 func joinStrings(ss []string) string {
 	if len(ss) == 0 {
@@ -126,6 +529,14 @@ type LightHandlers interface {
 	ListLights(ctx context.Context, input *ListLightsInput) (*ListLightsOutput, error)
 	GetLight(ctx context.Context, input *GetLightInput) (*GetLightOutput, error)
 	SetLightState(ctx context.Context, input *SetLightStateInput) (*SetLightStateOutput, error)
+	SetLightsState(ctx context.Context, input *SetLightsStateInput) (*SetLightsStateOutput, error)
+	ToggleLight(ctx context.Context, input *ToggleLightInput) (*ToggleLightOutput, error)
+	SetLightName(ctx context.Context, input *SetLightNameInput) (*SetLightNameOutput, error)
+	SetLightTags(ctx context.Context, input *SetLightTagsInput) (*SetLightTagsOutput, error)
+	SetDeviceName(ctx context.Context, input *SetDeviceNameInput) (*SetDeviceNameOutput, error)
+	RawRequest(ctx context.Context, input *RawRequestInput) (*RawRequestOutput, error)
+	GetLightSettings(ctx context.Context, input *GetLightSettingsInput) (*LightSettingsOutput, error)
+	SetLightSettings(ctx context.Context, input *SetLightSettingsInput) (*LightSettingsOutput, error)
 }
 
 // Ensure SetLightStateOutput is valid for non-error responses.
@@ -55,6 +55,44 @@ type SetFiltersOutput struct {
 	}
 }
 
+// --- Add Filter ---
+
+// AddFilterInput is the input for adding a single log filter.
+type AddFilterInput struct {
+	Body LogFilterResponse
+}
+
+// AddFilterOutput is the output after adding a filter.
+type AddFilterOutput struct {
+	Body struct {
+		Level   string              `json:"level" doc:"Current global log level"`
+		Filters []LogFilterResponse `json:"filters" doc:"Active log filters"`
+	}
+}
+
+// --- Delete Filter ---
+
+// DeleteFilterInput identifies the log filter to remove.
+type DeleteFilterInput struct {
+	Type    string `query:"type" required:"true" doc:"Type of the filter to remove"`
+	Pattern string `query:"pattern" required:"true" doc:"Pattern of the filter to remove"`
+}
+
+// DeleteFilterOutput is the output after removing a filter (204 No Content).
+type DeleteFilterOutput struct{}
+
+// --- Get Level ---
+
+// GetLevelInput is the input for reading the global log level.
+type GetLevelInput struct{}
+
+// GetLevelOutput is the output for reading the global log level.
+type GetLevelOutput struct {
+	Body struct {
+		Level string `json:"level" doc:"Current global log level"`
+	}
+}
+
 // --- Set Level ---
 
 // SetLevelInput is the input for changing the global log level.
@@ -71,9 +109,34 @@ type SetLevelOutput struct {
 	}
 }
 
+// --- Get Logs ---
+
+// LogEntryResponse is the API representation of a recent log line.
+type LogEntryResponse struct {
+	Time    time.Time `json:"time" doc:"When the line was logged"`
+	Level   string    `json:"level" doc:"Log level (debug, info, warn, error)"`
+	Message string    `json:"message" doc:"Log message"`
+	Line    string    `json:"line" doc:"The line as written to the daemon's log, in its text or JSON format"`
+}
+
+// GetLogsInput selects recent log lines.
+type GetLogsInput struct {
+	Level string `query:"level" doc:"Only lines at or above this level (debug, info, warn, error); all lines if empty"`
+	Since string `query:"since" doc:"Only lines logged since this RFC 3339 time, or this long ago, such as 10m"`
+	Limit int    `query:"limit" minimum:"0" doc:"At most this many lines, the most recent; 0 for every line kept"`
+}
+
+// GetLogsOutput is the output for reading recent log lines.
+type GetLogsOutput struct {
+	Body struct {
+		Entries []LogEntryResponse `json:"entries" doc:"Log lines, oldest first"`
+	}
+}
+
 // LoggingHandler implements logging management HTTP handlers.
 type LoggingHandler struct {
 	Logger *slog.Logger
+	Buffer *logging.Buffer // recent log lines; GetLogs returns none if nil
 }
 
 // ListFilters returns the current log level and active filters.
@@ -106,6 +169,39 @@ func (h *LoggingHandler) SetFilters(_ context.Context, input *SetFiltersInput) (
 	return out, nil
 }
 
+// AddFilter validates and adds a single log filter. A filter with the same type
+// and pattern as an existing one replaces it.
+func (h *LoggingHandler) AddFilter(_ context.Context, input *AddFilterInput) (*AddFilterOutput, error) {
+	filter := responseToFilters([]LogFilterResponse{input.Body})[0]
+	if errs := logging.AddFilter(filter); len(errs) > 0 {
+		return nil, huma.Error400BadRequest(
+			"Invalid filter: " + logging.FormatErrors(errs))
+	}
+	h.Logger.Info("Log filter added via API", "type", filter.Type, "pattern", filter.Pattern, "level", filter.Level)
+
+	out := &AddFilterOutput{}
+	out.Body.Level = LevelToString(logfilter.GetLevel())
+	out.Body.Filters = filtersToResponse(logfilter.GetFilters())
+	return out, nil
+}
+
+// DeleteFilter removes the log filter with the given type and pattern.
+func (h *LoggingHandler) DeleteFilter(_ context.Context, input *DeleteFilterInput) (*DeleteFilterOutput, error) {
+	if !logging.RemoveFilter(input.Type, input.Pattern) {
+		return nil, huma.Error404NotFound(
+			fmt.Sprintf("No log filter with type %q and pattern %q", input.Type, input.Pattern))
+	}
+	h.Logger.Info("Log filter removed via API", "type", input.Type, "pattern", input.Pattern)
+	return &DeleteFilterOutput{}, nil
+}
+
+// GetLevel returns the current global log level.
+func (h *LoggingHandler) GetLevel(_ context.Context, _ *GetLevelInput) (*GetLevelOutput, error) {
+	out := &GetLevelOutput{}
+	out.Body.Level = LevelToString(logfilter.GetLevel())
+	return out, nil
+}
+
 // SetLevel validates and changes the global log level at runtime.
 func (h *LoggingHandler) SetLevel(_ context.Context, input *SetLevelInput) (*SetLevelOutput, error) {
 	validated := utils.ValidateLogLevel(input.Body.Level)
@@ -123,6 +219,20 @@ func (h *LoggingHandler) SetLevel(_ context.Context, input *SetLevelInput) (*Set
 	return out, nil
 }
 
+// GetLogs returns recent log lines kept in memory by the daemon.
+func (h *LoggingHandler) GetLogs(_ context.Context, input *GetLogsInput) (*GetLogsOutput, error) {
+	q, err := logging.ParseQuery(input.Level, input.Since, input.Limit, time.Now())
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	out := &GetLogsOutput{}
+	out.Body.Entries = []LogEntryResponse{}
+	if h.Buffer != nil {
+		out.Body.Entries = LogEntriesToResponse(h.Buffer.Entries(q))
+	}
+	return out, nil
+}
+
 // Ensure LoggingHandler implements the interface at compile time.
 var _ LoggingHandlers = (*LoggingHandler)(nil)
 
@@ -130,7 +240,11 @@ var _ LoggingHandlers = (*LoggingHandler)(nil)
 type LoggingHandlers interface {
 	ListFilters(ctx context.Context, input *ListFiltersInput) (*ListFiltersOutput, error)
 	SetFilters(ctx context.Context, input *SetFiltersInput) (*SetFiltersOutput, error)
+	AddFilter(ctx context.Context, input *AddFilterInput) (*AddFilterOutput, error)
+	DeleteFilter(ctx context.Context, input *DeleteFilterInput) (*DeleteFilterOutput, error)
+	GetLevel(ctx context.Context, input *GetLevelInput) (*GetLevelOutput, error)
 	SetLevel(ctx context.Context, input *SetLevelInput) (*SetLevelOutput, error)
+	GetLogs(ctx context.Context, input *GetLogsInput) (*GetLogsOutput, error)
 }
 
 // --- Conversion helpers ---
@@ -165,6 +279,25 @@ func responseToFilters(resp []LogFilterResponse) []logfilter.LogFilter {
 	return result
 }
 
+// LogEntryToResponse converts a buffered log line to its API representation.
+func LogEntryToResponse(e logging.Entry) LogEntryResponse {
+	return LogEntryResponse{
+		Time:    e.Time,
+		Level:   LevelToString(e.Level),
+		Message: e.Message,
+		Line:    e.Line,
+	}
+}
+
+// LogEntriesToResponse converts buffered log lines to their API representation.
+func LogEntriesToResponse(entries []logging.Entry) []LogEntryResponse {
+	result := make([]LogEntryResponse, len(entries))
+	for i, e := range entries {
+		result[i] = LogEntryToResponse(e)
+	}
+	return result
+}
+
 // LevelToString converts a slog.Level to its string representation.
 func LevelToString(level slog.Level) string {
 	switch {
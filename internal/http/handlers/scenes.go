@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/jmylchreest/keylightd/internal/scene"
+)
+
+// SceneRequest is the request body for creating or replacing a scene.
+type SceneRequest struct {
+	Name    string              `json:"name" doc:"Display name for the scene" minLength:"1"`
+	Entries []SceneEntryRequest `json:"entries" doc:"States the scene applies" minItems:"1"`
+}
+
+// SceneEntryRequest is the state a scene gives one light or group.
+type SceneEntryRequest struct {
+	Target       SceneTargetBody `json:"target" doc:"Light or group the entry acts upon"`
+	On           *bool           `json:"on,omitempty" doc:"Power state"`
+	Brightness   *int            `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+	Temperature  *int            `json:"temperature,omitempty" doc:"Color temperature"`
+	TransitionMS int             `json:"transition_ms,omitempty" minimum:"0" doc:"Time to ramp brightness and temperature over, in milliseconds"`
+	Order        int             `json:"order,omitempty" doc:"Step the entry is applied in. Steps are applied lowest first, each once the transitions of the one before have finished."`
+	DelayMS      int             `json:"delay_ms,omitempty" minimum:"0" doc:"Wait after the entry's step starts before applying it, in milliseconds"`
+}
+
+// SceneTargetBody identifies the light or group(s) a scene entry acts upon.
+type SceneTargetBody struct {
+	Type string `json:"type" enum:"light,group" doc:"Target type"`
+	ID   string `json:"id" doc:"Light ID, or group ID(s)/name(s) comma-separated"`
+}
+
+// toScene converts the request body into a scene definition.
+func (r SceneRequest) toScene() scene.Scene {
+	s := scene.Scene{Name: r.Name, Entries: make([]scene.Entry, len(r.Entries))}
+	for i, e := range r.Entries {
+		s.Entries[i] = scene.Entry{
+			Target:       scene.Target{Type: e.Target.Type, ID: e.Target.ID},
+			On:           e.On,
+			Brightness:   e.Brightness,
+			Temperature:  e.Temperature,
+			TransitionMS: e.TransitionMS,
+			Order:        e.Order,
+			DelayMS:      e.DelayMS,
+		}
+	}
+	return s
+}
+
+// SceneResponse is the API representation of a scene.
+type SceneResponse struct {
+	ID      string              `json:"id" doc:"Unique scene identifier"`
+	Name    string              `json:"name" doc:"Display name of the scene"`
+	Entries []SceneEntryRequest `json:"entries" doc:"States the scene applies"`
+}
+
+// SceneFromInternal converts a scene.Scene to a SceneResponse.
+func SceneFromInternal(s *scene.Scene) SceneResponse {
+	resp := SceneResponse{ID: s.ID, Name: s.Name, Entries: make([]SceneEntryRequest, len(s.Entries))}
+	for i, e := range s.Entries {
+		resp.Entries[i] = SceneEntryRequest{
+			Target:       SceneTargetBody{Type: e.Target.Type, ID: e.Target.ID},
+			On:           e.On,
+			Brightness:   e.Brightness,
+			Temperature:  e.Temperature,
+			TransitionMS: e.TransitionMS,
+			Order:        e.Order,
+			DelayMS:      e.DelayMS,
+		}
+	}
+	return resp
+}
+
+// ScenesFromInternal converts a slice of scene.Scene to SceneResponses.
+func ScenesFromInternal(scenes []*scene.Scene) []SceneResponse {
+	result := make([]SceneResponse, len(scenes))
+	for i, s := range scenes {
+		result[i] = SceneFromInternal(s)
+	}
+	return result
+}
+
+// --- List Scenes ---
+
+// ListScenesInput is the input for listing all scenes.
+type ListScenesInput struct{}
+
+// ListScenesOutput is the output for listing all scenes.
+type ListScenesOutput struct {
+	Body []SceneResponse
+}
+
+// --- Create Scene ---
+
+// CreateSceneInput is the input for creating a scene.
+type CreateSceneInput struct {
+	Body SceneRequest
+}
+
+// CreateSceneOutput is the output for creating a scene (HTTP 201).
+type CreateSceneOutput struct {
+	Body SceneResponse
+}
+
+// --- Get Scene ---
+
+// GetSceneInput is the input for getting a single scene.
+type GetSceneInput struct {
+	ID string `path:"id" doc:"Scene identifier or name"`
+}
+
+// GetSceneOutput is the output for getting a single scene.
+type GetSceneOutput struct {
+	Body SceneResponse
+}
+
+// --- Update Scene ---
+
+// UpdateSceneInput is the input for replacing a scene's definition.
+type UpdateSceneInput struct {
+	ID   string `path:"id" doc:"Scene identifier"`
+	Body SceneRequest
+}
+
+// UpdateSceneOutput is the output for replacing a scene.
+type UpdateSceneOutput struct {
+	Body SceneResponse
+}
+
+// --- Delete Scene ---
+
+// DeleteSceneInput is the input for deleting a scene.
+type DeleteSceneInput struct {
+	ID string `path:"id" doc:"Scene identifier"`
+}
+
+// DeleteSceneOutput is the output for deleting a scene (HTTP 204).
+type DeleteSceneOutput struct{}
+
+// --- Scene Runs ---
+
+// SceneRunInput is the input for applying a scene or following its run.
+type SceneRunInput struct {
+	ID string `path:"id" doc:"Scene identifier or name"`
+}
+
+// SceneHandler implements scene-related HTTP handlers.
+type SceneHandler struct {
+	Scenes *scene.Manager
+}
+
+// ListScenes returns all scenes.
+func (h *SceneHandler) ListScenes(_ context.Context, _ *ListScenesInput) (*ListScenesOutput, error) {
+	return &ListScenesOutput{Body: ScenesFromInternal(h.Scenes.GetScenes())}, nil
+}
+
+// CreateScene creates a new scene and returns it with HTTP 201.
+func (h *SceneHandler) CreateScene(_ context.Context, input *CreateSceneInput) (*CreateSceneOutput, error) {
+	s, err := h.Scenes.CreateScene(input.Body.toScene())
+	if err != nil {
+		return nil, errorResponse(err, "Failed to create scene: %s", err)
+	}
+	return &CreateSceneOutput{Body: SceneFromInternal(s)}, nil
+}
+
+// GetScene returns a single scene by ID or name.
+func (h *SceneHandler) GetScene(_ context.Context, input *GetSceneInput) (*GetSceneOutput, error) {
+	s, err := h.Scenes.GetScene(input.ID)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to get scene: %s", err)
+	}
+	return &GetSceneOutput{Body: SceneFromInternal(s)}, nil
+}
+
+// UpdateScene replaces a scene's definition.
+func (h *SceneHandler) UpdateScene(_ context.Context, input *UpdateSceneInput) (*UpdateSceneOutput, error) {
+	s, err := h.Scenes.UpdateScene(input.ID, input.Body.toScene())
+	if err != nil {
+		return nil, errorResponse(err, "Failed to update scene: %s", err)
+	}
+	return &UpdateSceneOutput{Body: SceneFromInternal(s)}, nil
+}
+
+// DeleteScene deletes a scene and returns HTTP 204.
+func (h *SceneHandler) DeleteScene(_ context.Context, input *DeleteSceneInput) (*DeleteSceneOutput, error) {
+	if err := h.Scenes.DeleteScene(input.ID); err != nil {
+		return nil, errorResponse(err, "Failed to delete scene: %s", err)
+	}
+	return &DeleteSceneOutput{}, nil
+}
+
+// ApplyScene starts applying a scene and returns its job with HTTP 202.
+func (h *SceneHandler) ApplyScene(ctx context.Context, input *SceneRunInput) (*JobOutput, error) {
+	job, err := h.Scenes.Apply(ctx, input.ID)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to apply scene: %s", err)
+	}
+	return &JobOutput{Body: JobFromInternal(job)}, nil
+}
+
+// GetSceneRun returns the job of a scene's latest run.
+func (h *SceneHandler) GetSceneRun(_ context.Context, input *SceneRunInput) (*JobOutput, error) {
+	job, err := h.Scenes.GetRun(input.ID)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to get scene run: %s", err)
+	}
+	return &JobOutput{Body: JobFromInternal(job)}, nil
+}
+
+// CancelSceneRun stops applying a scene and returns its job once it has stopped.
+func (h *SceneHandler) CancelSceneRun(ctx context.Context, input *SceneRunInput) (*JobOutput, error) {
+	job, err := h.Scenes.CancelRun(ctx, input.ID)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to cancel scene run: %s", err)
+	}
+	return &JobOutput{Body: JobFromInternal(job)}, nil
+}
+
+// Ensure SceneHandler implements the interface at compile time.
+var _ SceneHandlers = (*SceneHandler)(nil)
+
+// SceneHandlers defines the interface for scene operations.
+type SceneHandlers interface {
+	ListScenes(ctx context.Context, input *ListScenesInput) (*ListScenesOutput, error)
+	CreateScene(ctx context.Context, input *CreateSceneInput) (*CreateSceneOutput, error)
+	GetScene(ctx context.Context, input *GetSceneInput) (*GetSceneOutput, error)
+	UpdateScene(ctx context.Context, input *UpdateSceneInput) (*UpdateSceneOutput, error)
+	DeleteScene(ctx context.Context, input *DeleteSceneInput) (*DeleteSceneOutput, error)
+	ApplyScene(ctx context.Context, input *SceneRunInput) (*JobOutput, error)
+	GetSceneRun(ctx context.Context, input *SceneRunInput) (*JobOutput, error)
+	CancelSceneRun(ctx context.Context, input *SceneRunInput) (*JobOutput, error)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/schedule"
+)
+
+// Defaults for listing upcoming schedule runs.
+const (
+	DefaultUpcomingLimit = 10
+	DefaultUpcomingDays  = 7
+)
+
+// ScheduleRequest is the request body for creating or replacing a schedule.
+type ScheduleRequest struct {
+	Name      string                `json:"name" doc:"Display name for the schedule" minLength:"1"`
+	At        string                `json:"at,omitempty" doc:"Daily time of day in HH:MM (24h, in the schedule's time zone). Mutually exclusive with cron."`
+	Cron      string                `json:"cron,omitempty" doc:"Five-field cron expression (minute hour day-of-month month day-of-week) or @daily/@hourly etc. Mutually exclusive with at."`
+	Target    ScheduleTargetBody    `json:"target,omitzero" doc:"Light or group the schedule acts upon. Required with action; with scene, limits the scene to its lights."`
+	Action    ScheduleActionRequest `json:"action,omitzero" doc:"State to apply when the schedule fires. Mutually exclusive with scene."`
+	Scene     string                `json:"scene,omitempty" doc:"ID or name of a scene to apply when the schedule fires. Mutually exclusive with action."`
+	Timezone  string                `json:"timezone,omitempty" doc:"IANA time zone at and cron are in, such as Europe/London (default config.schedules.timezone, then the daemon's)"`
+	SkipDates []string              `json:"skip_dates,omitempty" doc:"Dates (YYYY-MM-DD, in the schedule's time zone) the schedule doesn't fire on, such as holidays"`
+	Enabled   *bool                 `json:"enabled,omitempty" doc:"Whether the schedule is active (default true)"`
+}
+
+// ScheduleTargetBody identifies the light or group(s) a schedule acts upon.
+type ScheduleTargetBody struct {
+	Type string `json:"type" enum:"light,group" doc:"Target type"`
+	ID   string `json:"id" doc:"Light ID, or group ID(s)/name(s) comma-separated"`
+}
+
+// ScheduleActionRequest is the light state applied when a schedule fires.
+type ScheduleActionRequest struct {
+	On          *bool `json:"on,omitempty" doc:"Power state"`
+	Brightness  *int  `json:"brightness,omitempty" doc:"Brightness level (0-100)"`
+	Temperature *int  `json:"temperature,omitempty" doc:"Color temperature"`
+}
+
+// toSchedule converts the request body into a schedule definition.
+func (r ScheduleRequest) toSchedule() schedule.Schedule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return schedule.Schedule{
+		Name:   r.Name,
+		At:     r.At,
+		Cron:   r.Cron,
+		Target: schedule.Target{Type: r.Target.Type, ID: r.Target.ID},
+		Action: schedule.Action{
+			On:          r.Action.On,
+			Brightness:  r.Action.Brightness,
+			Temperature: r.Action.Temperature,
+		},
+		Scene:     r.Scene,
+		Timezone:  r.Timezone,
+		SkipDates: r.SkipDates,
+		Enabled:   enabled,
+	}
+}
+
+// --- List Schedules ---
+
+// ListSchedulesInput is the input for listing all schedules.
+type ListSchedulesInput struct{}
+
+// ListSchedulesOutput is the output for listing all schedules.
+type ListSchedulesOutput struct {
+	Body []ScheduleResponse
+}
+
+// --- Upcoming Schedules ---
+
+// ListUpcomingSchedulesInput is the input for listing upcoming schedule runs.
+type ListUpcomingSchedulesInput struct {
+	Limit int `query:"limit" minimum:"0" maximum:"100" doc:"Most runs to return (default 10)"`
+	Days  int `query:"days" minimum:"0" maximum:"366" doc:"How many days ahead to look (default 7)"`
+}
+
+// ListUpcomingSchedulesOutput is the output for listing upcoming schedule runs.
+type ListUpcomingSchedulesOutput struct {
+	Body []ScheduleExecutionResponse
+}
+
+// --- Create Schedule ---
+
+// CreateScheduleInput is the input for creating a schedule.
+type CreateScheduleInput struct {
+	Body ScheduleRequest
+}
+
+// CreateScheduleOutput is the output for creating a schedule (HTTP 201).
+type CreateScheduleOutput struct {
+	Body ScheduleResponse
+}
+
+// --- Get Schedule ---
+
+// GetScheduleInput is the input for getting a single schedule.
+type GetScheduleInput struct {
+	ID string `path:"id" doc:"Schedule identifier"`
+}
+
+// GetScheduleOutput is the output for getting a single schedule.
+type GetScheduleOutput struct {
+	Body ScheduleResponse
+}
+
+// --- Update Schedule ---
+
+// UpdateScheduleInput is the input for replacing a schedule's definition.
+type UpdateScheduleInput struct {
+	ID   string `path:"id" doc:"Schedule identifier"`
+	Body ScheduleRequest
+}
+
+// UpdateScheduleOutput is the output for replacing a schedule.
+type UpdateScheduleOutput struct {
+	Body ScheduleResponse
+}
+
+// --- Delete Schedule ---
+
+// DeleteScheduleInput is the input for deleting a schedule.
+type DeleteScheduleInput struct {
+	ID string `path:"id" doc:"Schedule identifier"`
+}
+
+// DeleteScheduleOutput is the output for deleting a schedule (HTTP 204).
+type DeleteScheduleOutput struct{}
+
+// ScheduleHandler implements schedule-related HTTP handlers.
+type ScheduleHandler struct {
+	Schedules *schedule.Manager
+}
+
+// scheduleError maps schedule manager errors onto HTTP errors.
+func scheduleError(action string, err error) error {
+	switch {
+	case kerrors.IsInvalidInput(err):
+		return huma.Error400BadRequest(err.Error())
+	case kerrors.IsNotFound(err):
+		return huma.Error404NotFound(err.Error())
+	default:
+		return errorResponse(err, "Failed to %s schedule: %s", action, err)
+	}
+}
+
+// ListSchedules returns all schedules.
+func (h *ScheduleHandler) ListSchedules(_ context.Context, _ *ListSchedulesInput) (*ListSchedulesOutput, error) {
+	return &ListSchedulesOutput{Body: SchedulesFromInternal(h.Schedules.GetSchedules())}, nil
+}
+
+// ListUpcomingSchedules returns the next runs of enabled schedules, soonest first.
+func (h *ScheduleHandler) ListUpcomingSchedules(_ context.Context, input *ListUpcomingSchedulesInput) (*ListUpcomingSchedulesOutput, error) {
+	limit := input.Limit
+	if limit == 0 {
+		limit = DefaultUpcomingLimit
+	}
+	days := input.Days
+	if days == 0 {
+		days = DefaultUpcomingDays
+	}
+	executions := h.Schedules.Upcoming(limit, time.Duration(days)*24*time.Hour)
+	return &ListUpcomingSchedulesOutput{Body: ScheduleExecutionsFromInternal(executions)}, nil
+}
+
+// CreateSchedule creates a new schedule and returns it with HTTP 201.
+func (h *ScheduleHandler) CreateSchedule(_ context.Context, input *CreateScheduleInput) (*CreateScheduleOutput, error) {
+	sched, err := h.Schedules.CreateSchedule(input.Body.toSchedule())
+	if err != nil {
+		return nil, scheduleError("create", err)
+	}
+	return &CreateScheduleOutput{Body: ScheduleFromInternal(sched)}, nil
+}
+
+// GetSchedule returns a single schedule by ID.
+func (h *ScheduleHandler) GetSchedule(_ context.Context, input *GetScheduleInput) (*GetScheduleOutput, error) {
+	sched, err := h.Schedules.GetSchedule(input.ID)
+	if err != nil {
+		return nil, scheduleError("get", err)
+	}
+	return &GetScheduleOutput{Body: ScheduleFromInternal(sched)}, nil
+}
+
+// UpdateSchedule replaces a schedule's definition.
+func (h *ScheduleHandler) UpdateSchedule(_ context.Context, input *UpdateScheduleInput) (*UpdateScheduleOutput, error) {
+	sched, err := h.Schedules.UpdateSchedule(input.ID, input.Body.toSchedule())
+	if err != nil {
+		return nil, scheduleError("update", err)
+	}
+	return &UpdateScheduleOutput{Body: ScheduleFromInternal(sched)}, nil
+}
+
+// DeleteSchedule deletes a schedule and returns HTTP 204.
+func (h *ScheduleHandler) DeleteSchedule(_ context.Context, input *DeleteScheduleInput) (*DeleteScheduleOutput, error) {
+	if err := h.Schedules.DeleteSchedule(input.ID); err != nil {
+		return nil, scheduleError("delete", err)
+	}
+	return &DeleteScheduleOutput{}, nil
+}
+
+// Ensure ScheduleHandler implements the interface at compile time.
+var _ ScheduleHandlers = (*ScheduleHandler)(nil)
+
+// ScheduleHandlers defines the interface for schedule operations.
+type ScheduleHandlers interface {
+	ListSchedules(ctx context.Context, input *ListSchedulesInput) (*ListSchedulesOutput, error)
+	ListUpcomingSchedules(ctx context.Context, input *ListUpcomingSchedulesInput) (*ListUpcomingSchedulesOutput, error)
+	CreateSchedule(ctx context.Context, input *CreateScheduleInput) (*CreateScheduleOutput, error)
+	GetSchedule(ctx context.Context, input *GetScheduleInput) (*GetScheduleOutput, error)
+	UpdateSchedule(ctx context.Context, input *UpdateScheduleInput) (*UpdateScheduleOutput, error)
+	DeleteSchedule(ctx context.Context, input *DeleteScheduleInput) (*DeleteScheduleOutput, error)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/stats"
+)
+
+// LightStatsResponse is the API representation of a light's usage statistics.
+type LightStatsResponse struct {
+	ID         string     `json:"id" doc:"Light ID"`
+	On         bool       `json:"on" doc:"Whether the light is on now, as far as the daemon knows"`
+	OnSeconds  int64      `json:"on_seconds" doc:"Cumulative time the light has been on, in seconds"`
+	Toggles    int        `json:"toggles" doc:"Number of times the light was turned on or off"`
+	LastChange *time.Time `json:"last_change,omitempty" doc:"When the light's state last changed; absent if it hasn't since tracking began"`
+	LastToggle *time.Time `json:"last_toggle,omitempty" doc:"When the light was last turned on or off; absent if it hasn't been"`
+	Since      time.Time  `json:"since" doc:"When tracking of the light began"`
+}
+
+// GroupStatsResponse is the API representation of a group's usage statistics.
+type GroupStatsResponse struct {
+	ID         string               `json:"id" doc:"Group ID"`
+	OnSeconds  int64                `json:"on_seconds" doc:"Sum of the on-time of the group's lights, in seconds"`
+	Toggles    int                  `json:"toggles" doc:"Sum of the toggles of the group's lights"`
+	LastChange *time.Time           `json:"last_change,omitempty" doc:"Latest state change of any of the group's lights"`
+	LastToggle *time.Time           `json:"last_toggle,omitempty" doc:"Latest toggle of any of the group's lights"`
+	Lights     []LightStatsResponse `json:"lights" doc:"Statistics of each of the group's lights that has any"`
+}
+
+// LightStatsFromInternal converts stats.Stats to a LightStatsResponse.
+func LightStatsFromInternal(s stats.Stats) LightStatsResponse {
+	return LightStatsResponse{
+		ID:         s.ID,
+		On:         s.On,
+		OnSeconds:  int64(s.OnTime.Seconds()),
+		Toggles:    s.Toggles,
+		LastChange: optionalTime(s.LastChange),
+		LastToggle: optionalTime(s.LastToggle),
+		Since:      s.Since,
+	}
+}
+
+// GroupStatsFromInternal converts stats.GroupStats to a GroupStatsResponse.
+func GroupStatsFromInternal(id string, s stats.GroupStats) GroupStatsResponse {
+	resp := GroupStatsResponse{
+		ID:         id,
+		OnSeconds:  int64(s.OnTime.Seconds()),
+		Toggles:    s.Toggles,
+		LastChange: optionalTime(s.LastChange),
+		LastToggle: optionalTime(s.LastToggle),
+		Lights:     make([]LightStatsResponse, 0, len(s.Lights)),
+	}
+	for _, l := range s.Lights {
+		resp.Lights = append(resp.Lights, LightStatsFromInternal(l))
+	}
+	return resp
+}
+
+// optionalTime returns nil for the zero time, so it is left out of responses.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// --- Get Light Stats ---
+
+// GetLightStatsInput is the input for getting a light's usage statistics.
+type GetLightStatsInput struct {
+	ID string `path:"id" doc:"Light identifier"`
+}
+
+// GetLightStatsOutput is the output for getting a light's usage statistics.
+type GetLightStatsOutput struct {
+	Body LightStatsResponse
+}
+
+// --- Get Group Stats ---
+
+// GetGroupStatsInput is the input for getting a group's usage statistics.
+type GetGroupStatsInput struct {
+	ID string `path:"id" doc:"Group identifier"`
+}
+
+// GetGroupStatsOutput is the output for getting a group's usage statistics.
+type GetGroupStatsOutput struct {
+	Body GroupStatsResponse
+}
+
+// StatsHandler implements usage statistics HTTP handlers.
+type StatsHandler struct {
+	Stats  *stats.Tracker
+	Groups *group.Manager
+}
+
+// GetLightStats returns a light's usage statistics.
+func (h *StatsHandler) GetLightStats(_ context.Context, input *GetLightStatsInput) (*GetLightStatsOutput, error) {
+	s, err := h.Stats.Light(input.ID)
+	if err != nil {
+		return nil, errorResponse(err, "Light not found: %s", err)
+	}
+	return &GetLightStatsOutput{Body: LightStatsFromInternal(s)}, nil
+}
+
+// GetGroupStats returns the combined usage statistics of a group's lights.
+func (h *StatsHandler) GetGroupStats(_ context.Context, input *GetGroupStatsInput) (*GetGroupStatsOutput, error) {
+	grp, err := h.Groups.GetGroup(input.ID)
+	if err != nil {
+		return nil, huma.Error404NotFound(fmt.Sprintf("Group not found: %s", err))
+	}
+	return &GetGroupStatsOutput{Body: GroupStatsFromInternal(grp.ID, h.Stats.Group(grp.Lights))}, nil
+}
+
+// Ensure StatsHandler implements the interface at compile time.
+var _ StatsHandlers = (*StatsHandler)(nil)
+
+// StatsHandlers defines the interface for usage statistics operations.
+type StatsHandlers interface {
+	GetLightStats(ctx context.Context, input *GetLightStatsInput) (*GetLightStatsOutput, error)
+	GetGroupStats(ctx context.Context, input *GetGroupStatsInput) (*GetGroupStatsOutput, error)
+}
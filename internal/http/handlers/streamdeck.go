@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Stream Deck action names.
+const (
+	StreamDeckToggle      = "toggle"
+	StreamDeckOn          = "on"
+	StreamDeckOff         = "off"
+	StreamDeckBrightness  = "brightness"
+	StreamDeckTemperature = "temperature"
+)
+
+// StreamDeckItem is the compact state of a light or group, as shown on a
+// Stream Deck or Loupedeck button.
+type StreamDeckItem struct {
+	ID          string `json:"id" doc:"Light or group identifier"`
+	Name        string `json:"name" doc:"Display name"`
+	On          bool   `json:"on" doc:"Whether the light is on; for a group, whether any of its lights is on"`
+	Brightness  int    `json:"brightness" doc:"Brightness level (0-100); for a group, the average of its lights"`
+	Temperature int    `json:"temperature" doc:"Color temperature in Kelvin; for a group, the average of its lights"`
+	Status      string `json:"status,omitempty" enum:"online,degraded,offline" doc:"Whether the light is responding; absent for groups"`
+}
+
+// StreamDeckState is a compact snapshot of every light and group.
+type StreamDeckState struct {
+	Lights []StreamDeckItem `json:"lights" doc:"Lights, sorted by name"`
+	Groups []StreamDeckItem `json:"groups" doc:"Groups, sorted by name"`
+}
+
+// --- Get Stream Deck State ---
+
+// GetStreamDeckStateInput is the input for the Stream Deck state snapshot.
+type GetStreamDeckStateInput struct{}
+
+// GetStreamDeckStateOutput is the output for the Stream Deck state snapshot.
+type GetStreamDeckStateOutput struct {
+	Body StreamDeckState
+}
+
+// --- Stream Deck Action ---
+
+// StreamDeckActionInput is the input for a Stream Deck button or dial action.
+type StreamDeckActionInput struct {
+	Body struct {
+		Target string `json:"target" enum:"light,group" doc:"Whether id is a light or a group"`
+		ID     string `json:"id" minLength:"1" doc:"Light or group identifier"`
+		Action string `json:"action" enum:"toggle,on,off,brightness,temperature" doc:"Action to apply"`
+		Value  *int   `json:"value,omitempty" doc:"Brightness (0-100) or temperature (Kelvin) to set, for the brightness and temperature actions"`
+		Step   *int   `json:"step,omitempty" doc:"Amount to change brightness (percentage points) or temperature (Kelvin) by, for the brightness and temperature actions; negative to decrease"`
+	}
+}
+
+// StreamDeckActionOutput is the output for a Stream Deck action, the target's
+// state after the action.
+type StreamDeckActionOutput struct {
+	Body StreamDeckItem
+}
+
+// StreamDeckHandler implements the compact endpoints used by Stream Deck and
+// Loupedeck plugins, which only need to show and toggle or step lights and
+// groups.
+type StreamDeckHandler struct {
+	Groups *group.Manager
+	Lights keylight.LightManager
+}
+
+// GetState returns the cached state of every light and group. It does not
+// query the devices, so plugins can poll it cheaply.
+func (h *StreamDeckHandler) GetState(_ context.Context, _ *GetStreamDeckStateInput) (*GetStreamDeckStateOutput, error) {
+	lights := h.Lights.GetLights()
+	state := StreamDeckState{
+		Lights: make([]StreamDeckItem, 0, len(lights)),
+		Groups: []StreamDeckItem{},
+	}
+	for _, l := range lights {
+		state.Lights = append(state.Lights, streamDeckLight(l))
+	}
+	for _, grp := range h.Groups.GetGroups() {
+		state.Groups = append(state.Groups, streamDeckGroup(grp, lights))
+	}
+	byName := func(a, b StreamDeckItem) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	}
+	slices.SortFunc(state.Lights, byName)
+	slices.SortFunc(state.Groups, byName)
+	return &GetStreamDeckStateOutput{Body: state}, nil
+}
+
+// Action applies a toggle, power or step action to a light or group and
+// returns its new state.
+func (h *StreamDeckHandler) Action(ctx context.Context, input *StreamDeckActionInput) (*StreamDeckActionOutput, error) {
+	body := input.Body
+	switch body.Action {
+	case StreamDeckBrightness, StreamDeckTemperature:
+		if (body.Value == nil) == (body.Step == nil) {
+			return nil, huma.Error400BadRequest(fmt.Sprintf("The %s action needs either value or step", body.Action))
+		}
+	default:
+		if body.Value != nil || body.Step != nil {
+			return nil, huma.Error400BadRequest(fmt.Sprintf("The %s action takes no value or step", body.Action))
+		}
+	}
+
+	var err error
+	if body.Target == "group" {
+		err = h.groupAction(ctx, body.ID, body.Action, body.Value, body.Step)
+	} else {
+		err = h.lightAction(ctx, body.ID, body.Action, body.Value, body.Step)
+	}
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, huma.Error404NotFound(err.Error())
+		}
+		if kerrors.IsInvalidInput(err) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, errorResponse(err, "Failed to apply %s action: %s", body.Action, err)
+	}
+
+	item, err := h.item(ctx, body.Target, body.ID)
+	if err != nil {
+		return nil, huma.Error404NotFound(err.Error())
+	}
+	return &StreamDeckActionOutput{Body: item}, nil
+}
+
+// lightAction applies an action to a light.
+func (h *StreamDeckHandler) lightAction(ctx context.Context, id, action string, value, step *int) error {
+	if _, err := h.Lights.GetLight(ctx, id); err != nil {
+		return kerrors.NotFoundf("light %s not found", id)
+	}
+	switch action {
+	case StreamDeckToggle:
+		_, err := h.Lights.ToggleLight(ctx, id)
+		return err
+	case StreamDeckOn, StreamDeckOff:
+		return h.Lights.SetLightState(ctx, id, keylight.OnValue(action == StreamDeckOn))
+	case StreamDeckBrightness:
+		if value != nil {
+			return h.Lights.SetLightState(ctx, id, keylight.BrightnessValue(*value))
+		}
+		return h.Lights.AdjustLight(ctx, id, keylight.Adjustment{Brightness: *step})
+	case StreamDeckTemperature:
+		if value != nil {
+			return h.Lights.SetLightState(ctx, id, keylight.TemperatureValue(*value))
+		}
+		return h.Lights.AdjustLight(ctx, id, keylight.Adjustment{Temperature: *step})
+	}
+	return kerrors.InvalidInputf("unknown action %q", action)
+}
+
+// groupAction applies an action to every light in a group.
+func (h *StreamDeckHandler) groupAction(ctx context.Context, id, action string, value, step *int) error {
+	switch action {
+	case StreamDeckToggle:
+		_, err := h.Groups.ToggleGroup(ctx, id)
+		return err
+	case StreamDeckOn, StreamDeckOff:
+		return h.Groups.SetGroupState(ctx, id, action == StreamDeckOn)
+	case StreamDeckBrightness:
+		if value != nil {
+			return h.Groups.SetGroupBrightness(ctx, id, *value)
+		}
+		return h.Groups.AdjustGroup(ctx, id, keylight.Adjustment{Brightness: *step})
+	case StreamDeckTemperature:
+		if value != nil {
+			return h.Groups.SetGroupTemperature(ctx, id, *value)
+		}
+		return h.Groups.AdjustGroup(ctx, id, keylight.Adjustment{Temperature: *step})
+	}
+	return kerrors.InvalidInputf("unknown action %q", action)
+}
+
+// item returns the current compact state of a light or group.
+func (h *StreamDeckHandler) item(ctx context.Context, target, id string) (StreamDeckItem, error) {
+	if target == "group" {
+		grp, err := h.Groups.GetGroup(id)
+		if err != nil {
+			return StreamDeckItem{}, err
+		}
+		return streamDeckGroup(grp, h.Lights.GetLights()), nil
+	}
+	light, err := h.Lights.GetLight(ctx, id)
+	if err != nil {
+		return StreamDeckItem{}, err
+	}
+	return streamDeckLight(light), nil
+}
+
+// streamDeckLight converts a light to its compact state.
+func streamDeckLight(l *keylight.Light) StreamDeckItem {
+	return StreamDeckItem{
+		ID:          l.ID,
+		Name:        l.Name,
+		On:          l.On,
+		Brightness:  l.Brightness,
+		Temperature: keylight.ConvertDeviceToTemperature(l.Temperature),
+		Status:      string(l.Status),
+	}
+}
+
+// streamDeckGroup summarises a group from the state of its known lights. A
+// group is on if any of its lights is on, matching how groups are toggled.
+func streamDeckGroup(grp *group.Group, lights map[string]*keylight.Light) StreamDeckItem {
+	item := StreamDeckItem{ID: grp.ID, Name: grp.Name}
+	var brightness, temperature, n int
+	for _, id := range grp.Lights {
+		l, ok := lights[id]
+		if !ok {
+			continue
+		}
+		item.On = item.On || l.On
+		brightness += l.Brightness
+		temperature += keylight.ConvertDeviceToTemperature(l.Temperature)
+		n++
+	}
+	if n > 0 {
+		item.Brightness = brightness / n
+		item.Temperature = temperature / n
+	}
+	return item
+}
+
+// Ensure StreamDeckHandler implements the interface at compile time.
+var _ StreamDeckHandlers = (*StreamDeckHandler)(nil)
+
+// StreamDeckHandlers defines the interface for the Stream Deck endpoints.
+type StreamDeckHandlers interface {
+	GetState(ctx context.Context, input *GetStreamDeckStateInput) (*GetStreamDeckStateOutput, error)
+	Action(ctx context.Context, input *StreamDeckActionInput) (*StreamDeckActionOutput, error)
+}
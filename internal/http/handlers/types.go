@@ -3,9 +3,12 @@
 package handlers
 
 import (
+	"net"
 	"time"
 
+	"github.com/jmylchreest/keylightd/internal/config"
 	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/schedule"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
@@ -13,19 +16,31 @@ import (
 
 // LightResponse is the API representation of a discovered light.
 type LightResponse struct {
-	ID                string    `json:"id" doc:"Unique light identifier"`
-	Name              string    `json:"name" doc:"Display name of the light"`
-	IP                string    `json:"ip" doc:"IP address of the light"`
-	Port              int       `json:"port" doc:"Port number of the light"`
-	Temperature       int       `json:"temperature" doc:"Color temperature in mireds"`
-	Brightness        int       `json:"brightness" doc:"Brightness level (0-100)"`
-	On                bool      `json:"on" doc:"Whether the light is currently on"`
-	ProductName       string    `json:"productname" doc:"Product name"`
-	HardwareBoardType int       `json:"hardwareboardtype" doc:"Hardware board type identifier"`
-	FirmwareVersion   string    `json:"firmwareversion" doc:"Firmware version string"`
-	FirmwareBuild     int       `json:"firmwarebuild" doc:"Firmware build number"`
-	SerialNumber      string    `json:"serialnumber" doc:"Serial number"`
-	LastSeen          time.Time `json:"lastseen" doc:"Last time the light was seen on the network"`
+	ID                string                 `json:"id" doc:"Unique light identifier"`
+	Name              string                 `json:"name" doc:"Display name of the light"`
+	IP                string                 `json:"ip" doc:"IP address of the light"`
+	Addresses         []string               `json:"addresses,omitempty" doc:"Every address the light was found at, IPv4 first, when it has more than one; requests fall back to the others when ip can't be reached"`
+	Port              int                    `json:"port" doc:"Port number of the light"`
+	Driver            string                 `json:"driver,omitempty" doc:"Device driver used to control the light (elgato, wled)"`
+	Static            bool                   `json:"static,omitempty" doc:"Whether the light is declared in the config rather than discovered"`
+	Temperature       int                    `json:"temperature" doc:"Color temperature in mireds, as reported by the device"`
+	TemperatureKelvin int                    `json:"temperature_kelvin,omitempty" doc:"Color temperature in Kelvin; absent until the light's temperature is known"`
+	Hue               *float64               `json:"hue,omitempty" doc:"Hue in degrees (0-360), set while a color light is showing a color"`
+	Saturation        *float64               `json:"saturation,omitempty" doc:"Saturation percentage (0-100), set while a color light is showing a color"`
+	ColorMode         string                 `json:"colormode,omitempty" enum:"temperature,color" doc:"Whether a color light is showing white at a color temperature or a hue/saturation color"`
+	Capabilities      *keylight.Capabilities `json:"capabilities,omitempty" doc:"Optional properties the light supports, once its accessory info is known; clients can hide controls that don't apply. range is the brightness and temperature (Kelvin) range of the light's model, outside which requests are rejected"`
+	Brightness        int                    `json:"brightness" doc:"Brightness level (0-100)"`
+	On                bool                   `json:"on" doc:"Whether the light is currently on"`
+	ProductName       string                 `json:"productname" doc:"Product name"`
+	HardwareBoardType int                    `json:"hardwareboardtype" doc:"Hardware board type identifier"`
+	FirmwareVersion   string                 `json:"firmwareversion" doc:"Firmware version string"`
+	FirmwareBuild     int                    `json:"firmwarebuild" doc:"Firmware build number"`
+	SerialNumber      string                 `json:"serialnumber" doc:"Serial number"`
+	LastSeen          time.Time              `json:"lastseen" doc:"Last time the light was seen on the network"`
+	Status            string                 `json:"status,omitempty" enum:"online,degraded,offline" doc:"Whether the light is responding: online, degraded after a failed request, or offline when not seen for a while"`
+	Limits            *keylight.Limits       `json:"limits,omitempty" doc:"Brightness and temperature (Kelvin) range the light can be set to, within its model's range; values outside it are clamped"`
+	Tags              map[string]string      `json:"tags,omitempty" doc:"Tags assigned to the light, which tag selectors such as tag:side=left match"`
+	Version           uint64                 `json:"version" doc:"State version, which changes whenever the light's state does; send it in If-Match to only change the light if it hasn't changed since"`
 }
 
 // LightFromKeylight converts a keylight.Light to a LightResponse.
@@ -34,8 +49,16 @@ func LightFromKeylight(l *keylight.Light) LightResponse {
 		ID:                l.ID,
 		Name:              l.Name,
 		IP:                l.IP.String(),
+		Addresses:         addressStrings(l.Addresses),
 		Port:              l.Port,
+		Driver:            l.Driver,
+		Static:            l.Static,
 		Temperature:       l.Temperature,
+		TemperatureKelvin: kelvinOrZero(l.Temperature),
+		Hue:               l.Hue,
+		Saturation:        l.Saturation,
+		ColorMode:         l.ColorMode,
+		Capabilities:      l.Capabilities,
 		Brightness:        l.Brightness,
 		On:                l.On,
 		ProductName:       l.ProductName,
@@ -44,9 +67,34 @@ func LightFromKeylight(l *keylight.Light) LightResponse {
 		FirmwareBuild:     l.FirmwareBuild,
 		SerialNumber:      l.SerialNumber,
 		LastSeen:          l.LastSeen,
+		Status:            string(l.Status),
+		Limits:            l.Limits,
+		Tags:              l.Tags,
+		Version:           l.Version,
 	}
 }
 
+// addressStrings formats a light's addresses, leaving nil as it is.
+func addressStrings(addrs []net.IP) []string {
+	if addrs == nil {
+		return nil
+	}
+	out := make([]string, len(addrs))
+	for i, ip := range addrs {
+		out[i] = ip.String()
+	}
+	return out
+}
+
+// kelvinOrZero converts a temperature in mireds to Kelvin, leaving 0 (unknown)
+// as it is.
+func kelvinOrZero(mireds int) int {
+	if mireds == 0 {
+		return 0
+	}
+	return keylight.ConvertDeviceToTemperature(mireds)
+}
+
 // LightsMapFromKeylight converts the keylight manager's map to our API map.
 // The GNOME extension expects lights as map[string]*Light (object keyed by ID).
 func LightsMapFromKeylight(lights map[string]*keylight.Light) map[string]LightResponse {
@@ -61,9 +109,87 @@ func LightsMapFromKeylight(lights map[string]*keylight.Light) map[string]LightRe
 
 // GroupResponse is the API representation of a light group.
 type GroupResponse struct {
-	ID     string   `json:"id" doc:"Unique group identifier (UUID)"`
-	Name   string   `json:"name" doc:"Display name of the group"`
-	Lights []string `json:"lights" doc:"List of light IDs in this group"`
+	ID          string             `json:"id" doc:"Unique group identifier (UUID)"`
+	Name        string             `json:"name" doc:"Display name of the group"`
+	Lights      []string           `json:"lights" doc:"List of light IDs in this group"`
+	Defaults    *GroupDefaultsBody `json:"defaults,omitempty" doc:"Default state of the group's lights"`
+	Limits      *keylight.Limits   `json:"limits,omitempty" doc:"Brightness and temperature (Kelvin) range every light in the group can be set to"`
+	Description string             `json:"description,omitempty" doc:"Group description"`
+	Icon        string             `json:"icon,omitempty" doc:"Icon name shown for the group in UIs"`
+	Color       string             `json:"color,omitempty" doc:"Color shown for the group in UIs, as #rrggbb"`
+	LegacyID    string             `json:"legacy_id,omitempty" doc:"ID the group had before it was migrated to a UUID, still accepted in place of its ID"`
+	CreatedAt   *time.Time         `json:"created_at,omitempty" doc:"When the group was created; unset for groups created before it was recorded"`
+	UpdatedAt   *time.Time         `json:"updated_at,omitempty" doc:"When the group's name, metadata, lights or defaults last changed"`
+}
+
+// GroupStateResponse is the aggregate state of a group's lights, computed
+// from their cached states. Only reachable lights count towards it.
+type GroupStateResponse struct {
+	ID                   string   `json:"id" doc:"Unique group identifier (UUID)"`
+	Name                 string   `json:"name" doc:"Display name of the group"`
+	LightsTotal          int      `json:"lights_total" doc:"Number of lights in the group"`
+	LightsOn             int      `json:"lights_on" doc:"Number of reachable lights that are on"`
+	AllOn                bool     `json:"all_on" doc:"Whether every reachable light is on; false if none is reachable"`
+	AnyOn                bool     `json:"any_on" doc:"Whether any reachable light is on"`
+	Brightness           int      `json:"brightness" doc:"Average brightness of the reachable lights (0-100)"`
+	MinTemperature       int      `json:"min_temperature,omitempty" doc:"Lowest color temperature of the reachable lights, in Kelvin; absent if none has one"`
+	MaxTemperature       int      `json:"max_temperature,omitempty" doc:"Highest color temperature of the reachable lights, in Kelvin; absent if none has one"`
+	MinTemperatureKelvin int      `json:"min_temperature_kelvin,omitempty" doc:"Same as min_temperature, named for its unit"`
+	MaxTemperatureKelvin int      `json:"max_temperature_kelvin,omitempty" doc:"Same as max_temperature, named for its unit"`
+	Unreachable          []string `json:"unreachable" doc:"IDs of lights that are offline or haven't been discovered"`
+	Version              uint64   `json:"version" doc:"State version: the latest state version of any of the group's lights; send it in If-Match to only change the group if it hasn't changed since"`
+}
+
+// GroupStateFromInternal converts a group.Summary to a GroupStateResponse.
+func GroupStateFromInternal(s group.Summary) GroupStateResponse {
+	return GroupStateResponse{
+		ID:                   s.ID,
+		Name:                 s.Name,
+		LightsTotal:          s.Lights,
+		LightsOn:             s.On,
+		AllOn:                s.AllOn,
+		AnyOn:                s.AnyOn,
+		Brightness:           s.Brightness,
+		MinTemperature:       s.MinKelvin,
+		MaxTemperature:       s.MaxKelvin,
+		MinTemperatureKelvin: s.MinKelvin,
+		MaxTemperatureKelvin: s.MaxKelvin,
+		Unreachable:          s.Unreachable,
+		Version:              s.Version,
+	}
+}
+
+// GroupLightResult is what a change to a group did to one of its lights.
+type GroupLightResult struct {
+	Group  string `json:"group" doc:"Group identifier"`
+	Light  string `json:"light" doc:"Light identifier"`
+	Status string `json:"status" enum:"ok,failed,skipped,unsupported" doc:"ok, failed, skipped (not attempted, as another light failed under the fail-fast policy) or unsupported (the light can't be set to the requested state and was left unchanged)"`
+	Error  string `json:"error,omitempty" doc:"Why the light could not be changed"`
+}
+
+// GroupLightResultsFromInternal converts group.LightResults to
+// GroupLightResults.
+func GroupLightResultsFromInternal(results []group.LightResult) []GroupLightResult {
+	resp := make([]GroupLightResult, len(results))
+	for i, r := range results {
+		resp[i] = GroupLightResult{Group: r.Group, Light: r.Light, Status: string(r.Status), Error: r.Error}
+	}
+	return resp
+}
+
+// GroupDefaultsBody is the API representation of a group's default state.
+type GroupDefaultsBody struct {
+	Brightness  *int `json:"brightness,omitempty" minimum:"3" maximum:"100" doc:"Default brightness (3-100)"`
+	Temperature *int `json:"temperature,omitempty" minimum:"2900" maximum:"7000" doc:"Default color temperature in Kelvin"`
+	ApplyOnJoin bool `json:"apply_on_join,omitempty" doc:"Apply the defaults to member lights as soon as they are discovered"`
+}
+
+func (b GroupDefaultsBody) toInternal() *group.Defaults {
+	return &group.Defaults{
+		Brightness:  b.Brightness,
+		Temperature: b.Temperature,
+		ApplyOnJoin: b.ApplyOnJoin,
+	}
 }
 
 // GroupFromInternal converts a group.Group to a GroupResponse.
@@ -72,11 +198,29 @@ func GroupFromInternal(g *group.Group) GroupResponse {
 	if lights == nil {
 		lights = []string{}
 	}
-	return GroupResponse{
-		ID:     g.ID,
-		Name:   g.Name,
-		Lights: lights,
+	resp := GroupResponse{
+		ID:          g.ID,
+		Name:        g.Name,
+		Lights:      lights,
+		Description: g.Description,
+		Icon:        g.Icon,
+		Color:       g.Color,
+		LegacyID:    g.LegacyID,
+	}
+	if !g.CreatedAt.IsZero() {
+		resp.CreatedAt = &g.CreatedAt
+	}
+	if !g.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &g.UpdatedAt
+	}
+	if g.Defaults != nil {
+		resp.Defaults = &GroupDefaultsBody{
+			Brightness:  g.Defaults.Brightness,
+			Temperature: g.Defaults.Temperature,
+			ApplyOnJoin: g.Defaults.ApplyOnJoin,
+		}
 	}
+	return resp
 }
 
 // GroupsFromInternal converts a slice of group.Group to GroupResponses.
@@ -88,15 +232,177 @@ func GroupsFromInternal(groups []*group.Group) []GroupResponse {
 	return result
 }
 
+// --- Schedule types ---
+
+// ScheduleResponse is the API representation of a schedule.
+type ScheduleResponse struct {
+	ID        string                `json:"id" doc:"Unique schedule identifier"`
+	Name      string                `json:"name" doc:"Display name of the schedule"`
+	At        string                `json:"at,omitempty" doc:"Daily time of day in HH:MM"`
+	Cron      string                `json:"cron,omitempty" doc:"Cron expression"`
+	Target    ScheduleTargetBody    `json:"target,omitzero" doc:"Light or group the schedule acts upon, or the scene is limited to"`
+	Action    ScheduleActionRequest `json:"action,omitzero" doc:"State applied when the schedule fires"`
+	Scene     string                `json:"scene,omitempty" doc:"ID or name of the scene applied when the schedule fires"`
+	Timezone  string                `json:"timezone,omitempty" doc:"IANA time zone at and cron are in; empty uses the default"`
+	SkipDates []string              `json:"skip_dates,omitempty" doc:"Dates (YYYY-MM-DD) the schedule doesn't fire on"`
+	Enabled   bool                  `json:"enabled" doc:"Whether the schedule is active"`
+	LastRun   *time.Time            `json:"last_run,omitempty" doc:"When the schedule last fired"`
+	NextRun   *time.Time            `json:"next_run,omitempty" doc:"When the schedule will next fire"`
+}
+
+// ScheduleExecutionResponse is an upcoming run of a schedule.
+type ScheduleExecutionResponse struct {
+	ScheduleID string                `json:"schedule_id" doc:"Schedule identifier"`
+	Name       string                `json:"name" doc:"Display name of the schedule"`
+	At         time.Time             `json:"at" doc:"When the schedule fires, in its time zone"`
+	Target     ScheduleTargetBody    `json:"target,omitzero" doc:"Light or group the schedule acts upon, or the scene is limited to"`
+	Action     ScheduleActionRequest `json:"action,omitzero" doc:"State applied"`
+	Scene      string                `json:"scene,omitempty" doc:"ID or name of the scene applied"`
+}
+
+// ScheduleFromInternal converts a schedule.Schedule to a ScheduleResponse.
+func ScheduleFromInternal(s *schedule.Schedule) ScheduleResponse {
+	resp := ScheduleResponse{
+		ID:        s.ID,
+		Name:      s.Name,
+		At:        s.At,
+		Cron:      s.Cron,
+		Target:    ScheduleTargetBody{Type: s.Target.Type, ID: s.Target.ID},
+		Action:    ScheduleActionRequest{On: s.Action.On, Brightness: s.Action.Brightness, Temperature: s.Action.Temperature},
+		Scene:     s.Scene,
+		Timezone:  s.Timezone,
+		SkipDates: s.SkipDates,
+		Enabled:   s.Enabled,
+	}
+	if !s.LastRun.IsZero() {
+		resp.LastRun = &s.LastRun
+	}
+	if !s.NextRun.IsZero() {
+		resp.NextRun = &s.NextRun
+	}
+	return resp
+}
+
+// SchedulesFromInternal converts a slice of schedule.Schedule to ScheduleResponses.
+func SchedulesFromInternal(schedules []*schedule.Schedule) []ScheduleResponse {
+	result := make([]ScheduleResponse, len(schedules))
+	for i, s := range schedules {
+		result[i] = ScheduleFromInternal(s)
+	}
+	return result
+}
+
+// ScheduleExecutionsFromInternal converts upcoming schedule runs to ScheduleExecutionResponses.
+func ScheduleExecutionsFromInternal(executions []schedule.Execution) []ScheduleExecutionResponse {
+	result := make([]ScheduleExecutionResponse, len(executions))
+	for i, e := range executions {
+		result[i] = ScheduleExecutionResponse{
+			ScheduleID: e.ScheduleID,
+			Name:       e.Name,
+			At:         e.At,
+			Target:     ScheduleTargetBody{Type: e.Target.Type, ID: e.Target.ID},
+			Action:     ScheduleActionRequest{On: e.Action.On, Brightness: e.Action.Brightness, Temperature: e.Action.Temperature},
+			Scene:      e.Scene,
+		}
+	}
+	return result
+}
+
 // --- API Key types ---
 
 // APIKeyResponse is the API representation of an API key.
 type APIKeyResponse struct {
-	ID        string    `json:"id" doc:"Key identifier"`
-	Name      string    `json:"name" doc:"Display name of the key"`
-	Key       string    `json:"key,omitempty" doc:"Full key string (only present on creation)"`
-	CreatedAt time.Time `json:"created_at" doc:"When the key was created"`
-	ExpiresAt time.Time `json:"expires_at" doc:"When the key expires"`
+	ID         string    `json:"id" doc:"Key identifier"`
+	Name       string    `json:"name" doc:"Display name of the key"`
+	Key        string    `json:"key,omitempty" doc:"Full key string (only present on creation)"`
+	CreatedAt  time.Time `json:"created_at" doc:"When the key was created"`
+	ExpiresAt  time.Time `json:"expires_at" doc:"When the key expires"`
+	LastUsedAt time.Time `json:"last_used_at,omitzero" doc:"When the key was last used"`
+	Disabled   bool      `json:"disabled" doc:"Whether the key is disabled"`
+	Scopes     []string  `json:"scopes,omitempty" doc:"Permission scopes; absent if the key has every scope"`
+}
+
+// APIKeyFromConfig converts a config.APIKey to an APIKeyResponse, without the
+// full key string.
+func APIKeyFromConfig(k *config.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.Key,
+		Name:       k.Name,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		Disabled:   k.Disabled,
+		Scopes:     k.Scopes,
+	}
+}
+
+// --- Daemon types ---
+
+// DiscoveryScanResponse is the result of an on-demand discovery pass.
+type DiscoveryScanResponse struct {
+	Found      []LightResponse `json:"found" doc:"Lights that were not known before the scan"`
+	Lights     int             `json:"lights" doc:"Number of lights known after the scan"`
+	DurationMS int64           `json:"duration_ms" doc:"How long the scan took, in milliseconds"`
+}
+
+// PendingDeviceResponse is a device discovery found that isn't a supported
+// light, which can be adopted to use it anyway.
+type PendingDeviceResponse struct {
+	ID                string    `json:"id" doc:"Instance name, and the light ID it gets once adopted"`
+	Name              string    `json:"name" doc:"Name reported by the device"`
+	IP                string    `json:"ip" doc:"Address the device answered on"`
+	Port              int       `json:"port" doc:"Port of the device's API"`
+	Driver            string    `json:"driver,omitempty" doc:"Driver that would control it"`
+	ProductName       string    `json:"productname" doc:"Product name reported by the device"`
+	HardwareBoardType int       `json:"hardwareboardtype" doc:"Hardware board type reported by the device"`
+	FirmwareVersion   string    `json:"firmwareversion" doc:"Firmware version reported by the device"`
+	Source            string    `json:"source" doc:"Discovery backend that last found it"`
+	FirstSeen         time.Time `json:"firstseen" doc:"When discovery first found it"`
+	LastSeen          time.Time `json:"lastseen" doc:"When discovery last found it"`
+}
+
+// PendingDeviceFromKeylight converts a keylight.PendingDevice to a PendingDeviceResponse.
+func PendingDeviceFromKeylight(d keylight.PendingDevice) PendingDeviceResponse {
+	resp := PendingDeviceResponse{
+		ID:                d.ID,
+		Name:              d.Name,
+		Port:              d.Port,
+		Driver:            d.Driver,
+		ProductName:       d.ProductName,
+		HardwareBoardType: d.HardwareBoardType,
+		FirmwareVersion:   d.FirmwareVersion,
+		Source:            d.Source,
+		FirstSeen:         d.FirstSeen,
+		LastSeen:          d.LastSeen,
+	}
+	if d.IP != nil {
+		resp.IP = d.IP.String()
+	}
+	return resp
+}
+
+// DaemonInfoResponse describes the running daemon.
+type DaemonInfoResponse struct {
+	Version            string                   `json:"version" doc:"Semantic version string"`
+	Commit             string                   `json:"commit" doc:"Git commit SHA"`
+	BuildDate          string                   `json:"build_date" doc:"Build timestamp (ISO 8601 UTC)"`
+	StartedAt          time.Time                `json:"started_at" doc:"When the daemon started"`
+	UptimeSeconds      int64                    `json:"uptime_seconds" doc:"Seconds since the daemon started"`
+	SocketPath         string                   `json:"socket_path" doc:"Path of the Unix socket"`
+	APIListenAddress   string                   `json:"api_listen_address,omitempty" doc:"TCP address the HTTP API listens on, if enabled"`
+	APIListenAddresses []string                 `json:"api_listen_addresses,omitempty" doc:"Every address the HTTP API listens on, including unix:// sockets, when there is more than one"`
+	DiscoveryInterval  int                      `json:"discovery_interval_seconds" doc:"Seconds between discovery passes"`
+	Lights             int                      `json:"lights" doc:"Number of known lights"`
+	Groups             int                      `json:"groups" doc:"Number of groups"`
+	Clients            DaemonClients            `json:"clients" doc:"Connected API clients"`
+	Discovery          *keylight.DiscoveryStats `json:"discovery,omitempty" doc:"Light discovery statistics"`
+}
+
+// DaemonClients counts the API clients connected to the daemon.
+type DaemonClients struct {
+	Socket           int `json:"socket" doc:"Open Unix socket connections, including event subscribers"`
+	EventSubscribers int `json:"event_subscribers" doc:"Unix socket connections streaming events"`
+	WebSocket        int `json:"websocket" doc:"Connected WebSocket clients"`
 }
 
 // --- Common response types ---
@@ -106,8 +412,32 @@ type StatusResponse struct {
 	Status string `json:"status" doc:"Operation status"`
 }
 
-// PartialStatusResponse is returned when some operations in a batch succeed and others fail.
-type PartialStatusResponse struct {
-	Status string   `json:"status" doc:"Operation status (partial)"`
-	Errors []string `json:"errors" doc:"List of errors for failed operations"`
+// GroupChangeResponse is the response to setting the state of one or more
+// groups. Errors is only set when the status is "partial".
+type GroupChangeResponse struct {
+	Status string             `json:"status" enum:"ok,partial" doc:"Operation status"`
+	Errors []string           `json:"errors,omitempty" doc:"List of errors for failed groups"`
+	Lights []GroupLightResult `json:"lights" doc:"What the change did to each light of each group"`
+}
+
+// ToggleResponse is the response to toggling a light.
+type ToggleResponse struct {
+	Status string `json:"status" doc:"Operation status"`
+	On     bool   `json:"on" doc:"Power state after the toggle"`
+}
+
+// GroupToggleResponse is the response to toggling one or more groups. Errors is
+// only set when the status is "partial".
+type GroupToggleResponse struct {
+	Status string             `json:"status" enum:"ok,partial" doc:"Operation status"`
+	Groups map[string]bool    `json:"groups" doc:"Power state after the toggle, keyed by group ID"`
+	Errors []string           `json:"errors,omitempty" doc:"List of errors for failed groups"`
+	Lights []GroupLightResult `json:"lights" doc:"What the toggle did to each light of each group"`
+}
+
+// BatchStatusResponse is the response to a batch operation. Errors is only set
+// when the status is "partial".
+type BatchStatusResponse struct {
+	Status string   `json:"status" enum:"ok,partial" doc:"Operation status"`
+	Errors []string `json:"errors,omitempty" doc:"List of errors for failed operations"`
 }
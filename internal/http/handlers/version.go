@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// Lights and groups have state versions, which are sent as ETags. A client
+// that sends one back in If-Match only has its change made if the state
+// hasn't changed since; otherwise it gets 409 Conflict.
+
+// versionETag returns the entity tag of a state version.
+func versionETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// versionFromIfMatch returns the state version in an If-Match header, and
+// whether the header asks for one. "*" matches any version, as does no header.
+func versionFromIfMatch(header string) (uint64, bool, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, false, nil
+	}
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 64)
+	if err != nil {
+		return 0, false, kerrors.InvalidInputf("invalid If-Match %q, expected a state version", header)
+	}
+	return version, true, nil
+}
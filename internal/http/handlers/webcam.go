@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/jmylchreest/keylightd/internal/webcam"
+)
+
+// WebcamResponse is the API representation of the webcam watcher's state.
+type WebcamResponse struct {
+	Enabled   bool     `json:"enabled" doc:"Whether the webcam watcher is enabled in the config"`
+	Supported bool     `json:"supported" doc:"Whether webcam use can be detected on this platform"`
+	InUse     bool     `json:"in_use" doc:"Whether a webcam was in use at the last check"`
+	Override  string   `json:"override" enum:"auto,on,off" doc:"Override of the detected webcam state"`
+	Active    bool     `json:"active" doc:"Whether the groups are currently on because of the webcam or an override"`
+	Groups    []string `json:"groups" doc:"Groups turned on while the webcam is in use"`
+}
+
+// WebcamFromInternal converts a webcam.Status to a WebcamResponse.
+func WebcamFromInternal(s webcam.Status) WebcamResponse {
+	return WebcamResponse{
+		Enabled:   s.Enabled,
+		Supported: s.Supported,
+		InUse:     s.InUse,
+		Override:  s.Override,
+		Active:    s.Active,
+		Groups:    s.Groups,
+	}
+}
+
+// --- Get Webcam ---
+
+// GetWebcamInput is the input for getting the webcam watcher's state.
+type GetWebcamInput struct{}
+
+// GetWebcamOutput is the output for getting the webcam watcher's state.
+type GetWebcamOutput struct {
+	Body WebcamResponse
+}
+
+// --- Set Webcam Override ---
+
+// SetWebcamOverrideInput is the input for overriding the detected webcam state.
+type SetWebcamOverrideInput struct {
+	Body struct {
+		Override string `json:"override" enum:"auto,on,off" doc:"on or off to force the groups on or off, or auto to follow the webcam again"`
+	}
+}
+
+// SetWebcamOverrideOutput is the output for overriding the detected webcam state.
+type SetWebcamOverrideOutput struct {
+	Body WebcamResponse
+}
+
+// WebcamHandler implements webcam watcher HTTP handlers.
+type WebcamHandler struct {
+	Webcam *webcam.Watcher
+}
+
+// GetWebcam returns the webcam watcher's state.
+func (h *WebcamHandler) GetWebcam(_ context.Context, _ *GetWebcamInput) (*GetWebcamOutput, error) {
+	return &GetWebcamOutput{Body: WebcamFromInternal(h.Webcam.Status())}, nil
+}
+
+// SetWebcamOverride forces the webcam groups on or off, or back to following
+// the webcam.
+func (h *WebcamHandler) SetWebcamOverride(_ context.Context, input *SetWebcamOverrideInput) (*SetWebcamOverrideOutput, error) {
+	status, err := h.Webcam.SetOverride(input.Body.Override)
+	if err != nil {
+		return nil, errorResponse(err, "Failed to set webcam override: %s", err)
+	}
+	return &SetWebcamOverrideOutput{Body: WebcamFromInternal(status)}, nil
+}
+
+// Ensure WebcamHandler implements the interface at compile time.
+var _ WebcamHandlers = (*WebcamHandler)(nil)
+
+// WebcamHandlers defines the interface for webcam watcher operations.
+type WebcamHandlers interface {
+	GetWebcam(ctx context.Context, input *GetWebcamInput) (*GetWebcamOutput, error)
+	SetWebcamOverride(ctx context.Context, input *SetWebcamOverrideInput) (*SetWebcamOverrideOutput, error)
+}
@@ -0,0 +1,194 @@
+package mw
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/logging"
+	"github.com/jmylchreest/keylightd/internal/requestid"
+)
+
+// clfTime is the timestamp layout of the Common Log Format.
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLog writes a line for each HTTP API request, in the Common or
+// Combined Log Format or as JSON, naming the API key or client certificate
+// that made it so that requests can be traced to the integration that sent
+// them. It is written by RequestLogging.
+type AccessLog struct {
+	format string
+	mu     sync.Mutex
+	out    io.Writer
+	closer io.Closer
+}
+
+// NewAccessLog opens the access log cfg describes. It returns nil if the
+// access log is disabled. Failures to rotate its file are logged to logger.
+func NewAccessLog(logger *slog.Logger, cfg config.AccessLogConfig) (*AccessLog, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	switch cfg.Format {
+	case "", config.AccessLogCommon, config.AccessLogCombined, config.AccessLogJSON:
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s, %s or %s",
+			cfg.Format, config.AccessLogCommon, config.AccessLogCombined, config.AccessLogJSON)
+	}
+	format := cmp.Or(cfg.Format, config.AccessLogCombined)
+
+	switch cfg.Output {
+	case "", config.AccessLogStdout:
+		return newAccessLog(os.Stdout, format), nil
+	case config.AccessLogStderr:
+		return newAccessLog(os.Stderr, format), nil
+	}
+	backups := cmp.Or(cfg.MaxBackups, config.DefaultAccessLogMaxBackups)
+	f, err := logging.OpenRotatingFile(logger, cfg.Output, int64(cfg.MaxSizeMB)<<20, backups)
+	if err != nil {
+		return nil, err
+	}
+	a := newAccessLog(f, format)
+	a.closer = f
+	return a, nil
+}
+
+func newAccessLog(out io.Writer, format string) *AccessLog {
+	return &AccessLog{format: format, out: out}
+}
+
+// Close closes the access log's file, if it writes to one. It is safe to
+// call on a nil AccessLog.
+func (a *AccessLog) Close() error {
+	if a == nil || a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// accessEntry is what RequestLogging records about a finished request.
+type accessEntry struct {
+	start     time.Time
+	uri       string
+	status    int
+	bytes     int64
+	principal Principal
+}
+
+// accessJSON is an access log line in the json format.
+type accessJSON struct {
+	Time       time.Time `json:"time"`
+	RemoteAddr string    `json:"remote_addr"`
+	Method     string    `json:"method"`
+	URI        string    `json:"uri"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	DurationMS float64   `json:"duration_ms"`
+	RequestID  string    `json:"request_id,omitempty"`
+	APIKey     string    `json:"api_key,omitempty"`
+	ClientCert string    `json:"client_cert,omitempty"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// log writes e to the access log. It does nothing on a nil AccessLog.
+func (a *AccessLog) log(r *http.Request, e accessEntry) {
+	if a == nil {
+		return
+	}
+	var buf bytes.Buffer
+	if a.format == config.AccessLogJSON {
+		_ = json.NewEncoder(&buf).Encode(accessJSON{
+			Time:       e.start,
+			RemoteAddr: clientIP(r.RemoteAddr),
+			Method:     r.Method,
+			URI:        e.uri,
+			Proto:      r.Proto,
+			Status:     e.status,
+			Bytes:      e.bytes,
+			DurationMS: float64(time.Since(e.start).Microseconds()) / 1000,
+			RequestID:  requestid.FromContext(r.Context()),
+			APIKey:     e.principal.APIKey,
+			ClientCert: e.principal.ClientCert,
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+		})
+	} else {
+		size := "-"
+		if e.bytes > 0 {
+			size = strconv.FormatInt(e.bytes, 10)
+		}
+		fmt.Fprintf(&buf, "%s - %s [%s] \"%s %s %s\" %d %s",
+			clfField(clientIP(r.RemoteAddr)), clfField(e.principal.Name()), e.start.Format(clfTime),
+			r.Method, clfQuote(e.uri), r.Proto, e.status, size)
+		if a.format == config.AccessLogCombined {
+			fmt.Fprintf(&buf, " \"%s\" \"%s\"", clfQuote(cmp.Or(r.Referer(), "-")), clfQuote(cmp.Or(r.UserAgent(), "-")))
+		}
+		buf.WriteByte('\n')
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, _ = a.out.Write(buf.Bytes())
+}
+
+// clfField formats an unquoted field, "-" when empty. API key names may have
+// spaces, which would split the field, so they are escaped.
+func clfField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return url.PathEscape(s)
+}
+
+// clfQuote escapes a value for a quoted field.
+func clfQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// Principal is who made an HTTP API request, as authenticated by HumaAuth or
+// RawAPIKeyAuth. Both are empty for unauthenticated requests.
+type Principal struct {
+	APIKey     string // name of the API key
+	ClientCert string // principal of the client certificate
+}
+
+// Name returns the API key or client certificate that made the request.
+func (p Principal) Name() string {
+	if p.APIKey != "" {
+		return p.APIKey
+	}
+	return p.ClientCert
+}
+
+// principalKey carries a *Principal for the auth middlewares to fill in.
+type principalKey struct{}
+
+// withPrincipal returns a context whose requests' auth is recorded in the
+// returned Principal.
+func withPrincipal(ctx context.Context) (context.Context, *Principal) {
+	p := &Principal{}
+	return context.WithValue(ctx, principalKey{}, p), p
+}
+
+// setPrincipal records who authenticated a request, if RequestLogging is
+// listening for it.
+func setPrincipal(ctx context.Context, p Principal) {
+	if holder, ok := ctx.Value(principalKey{}).(*Principal); ok {
+		*holder = p
+	}
+}
@@ -0,0 +1,100 @@
+package mw
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/requestid"
+)
+
+func TestAccessLog_Common(t *testing.T) {
+	mgr, key := testSetup(t)
+	var out bytes.Buffer
+	handler := RequestLogging(testLogger(), newAccessLog(&out, config.AccessLogCommon))(
+		RawAPIKeyAuth(testLogger(), mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("hello"))
+		})))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/lights?on=true", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	req.Header.Set("X-API-Key", key.Key)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Regexp(t, regexp.MustCompile(`^192\.0\.2\.10 - test-key \[[^]]+\] "GET /api/v1/lights\?on=true HTTP/1\.1" 200 5\n$`), out.String())
+}
+
+func TestAccessLog_CombinedUnauthenticated(t *testing.T) {
+	mgr, _ := testSetup(t)
+	var out bytes.Buffer
+	handler := RequestLogging(testLogger(), newAccessLog(&out, config.AccessLogCombined))(
+		RawAPIKeyAuth(testLogger(), mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/groups", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	req.Header.Set("User-Agent", `curl "8"`)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Regexp(t, regexp.MustCompile(`^192\.0\.2\.10 - - \[[^]]+\] "POST /api/v1/groups HTTP/1\.1" 401 \d+ "-" "curl \\"8\\""\n$`), out.String())
+}
+
+func TestAccessLog_JSON(t *testing.T) {
+	mgr, key := testSetup(t)
+	var out bytes.Buffer
+	handler := RequestID(RequestLogging(testLogger(), newAccessLog(&out, config.AccessLogJSON))(
+		RawAPIKeyAuth(testLogger(), mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPut, "/api/v1/lights/a", nil)
+	req.RemoteAddr = "192.0.2.10:51234"
+	req.Header.Set("Authorization", "Bearer "+key.Key)
+	req.Header.Set(requestid.Header, "req-1234")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "192.0.2.10", entry["remote_addr"])
+	assert.Equal(t, "PUT", entry["method"])
+	assert.Equal(t, "/api/v1/lights/a", entry["uri"])
+	assert.InDelta(t, http.StatusAccepted, entry["status"], 0)
+	assert.Equal(t, "req-1234", entry["request_id"])
+	assert.Equal(t, "test-key", entry["api_key"])
+	assert.NotContains(t, entry, "client_cert")
+}
+
+func TestAccessLog_EscapesKeyNames(t *testing.T) {
+	assert.Equal(t, "Home%20Assistant", clfField("Home Assistant"))
+	assert.Equal(t, "-", clfField(""))
+}
+
+func TestNewAccessLog(t *testing.T) {
+	a, err := NewAccessLog(testLogger(), config.AccessLogConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, a)
+	require.NoError(t, a.Close())
+
+	_, err = NewAccessLog(testLogger(), config.AccessLogConfig{Enabled: true, Format: "apache"})
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "access.log")
+	a, err = NewAccessLog(testLogger(), config.AccessLogConfig{Enabled: true, Output: path})
+	require.NoError(t, err)
+	assert.Equal(t, config.AccessLogCombined, a.format)
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/healthz", nil)
+	a.log(req, accessEntry{uri: "/healthz", status: http.StatusOK})
+	require.NoError(t, a.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"GET /healthz HTTP/1.1" 200 -`)
+}
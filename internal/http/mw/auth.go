@@ -1,24 +1,31 @@
 package mw
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/go-chi/chi/v5"
 
 	"github.com/jmylchreest/keylightd/internal/apikey"
+	"github.com/jmylchreest/keylightd/internal/config"
 )
 
 // HumaAuth returns a Huma middleware that handles API key authentication.
 // It checks the operation's Security requirements to determine if auth is needed.
+// If certs is non-nil, a request without an API key may instead authenticate
+// with a verified TLS client certificate, limited to the scopes it is granted.
+// If limiter is non-nil, IPs that present too many invalid API keys are locked
+// out and each API key is rate limited; both are answered with 429.
 // Operations registered via PublicGet/HiddenGet have no Security set and pass through.
 // Operations registered via ProtectedGet/ProtectedPost/etc. have the SecurityScheme
 // set and require a valid API key.
 //
 // This approach naturally exempts Huma's auto-registered routes (/openapi.json,
 // /docs, /schemas/) since they have no Security set on their operations.
-func HumaAuth(api huma.API, logger *slog.Logger, apikeyManager *apikey.Manager) func(ctx huma.Context, next func(huma.Context)) {
+func HumaAuth(api huma.API, logger *slog.Logger, apikeyManager *apikey.Manager, certs *ClientCertAuth, limiter *RateLimiter) func(ctx huma.Context, next func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		op := ctx.Operation()
 		if op == nil {
@@ -27,11 +34,18 @@ func HumaAuth(api huma.API, logger *slog.Logger, apikeyManager *apikey.Manager)
 		}
 
 		// Check if this operation requires auth
-		if !operationRequiresAuth(op) {
+		if !operationRequiresAuth(op) || trustedListener(ctx.Context()) {
 			next(ctx)
 			return
 		}
 
+		ip := clientIP(ctx.RemoteAddr())
+		if wait := limiter.LockedOut(ip); wait > 0 {
+			ctx.SetHeader("Retry-After", retryAfterSeconds(wait))
+			_ = huma.WriteErr(api, ctx, http.StatusTooManyRequests, "Too many invalid API keys; try again later")
+			return
+		}
+
 		// Extract API key from headers
 		key := ctx.Header("Authorization")
 		const bearerPrefix = "Bearer "
@@ -41,8 +55,25 @@ func HumaAuth(api huma.API, logger *slog.Logger, apikeyManager *apikey.Manager)
 			key = ctx.Header("X-API-Key")
 		}
 
+		if principal, ok := certs.Principal(ctx.TLS()); ok && key == "" {
+			if !certs.Allowed(principal, ctx.Method(), op.Path, ctx.Header("Upgrade")) {
+				logger.WarnContext(ctx.Context(), "Client certificate lacks required scope",
+					"principal", principal,
+					"method", ctx.Method(),
+					"path", ctx.URL().Path,
+					"remote_addr", ctx.RemoteAddr(),
+				)
+				_ = huma.WriteErr(api, ctx, http.StatusForbidden, "Forbidden: client certificate lacks the required scope")
+				return
+			}
+			logger.DebugContext(ctx.Context(), "Authenticated client certificate", "principal", principal)
+			setPrincipal(ctx.Context(), Principal{ClientCert: principal})
+			next(huma.WithValue(ctx, grantedScopesKey{}, certs.Scopes(principal)))
+			return
+		}
+
 		if key == "" {
-			logger.Warn("API key missing",
+			logger.WarnContext(ctx.Context(), "API key missing",
 				"method", ctx.Method(),
 				"path", ctx.URL().Path,
 				"remote_addr", ctx.RemoteAddr(),
@@ -53,22 +84,43 @@ func HumaAuth(api huma.API, logger *slog.Logger, apikeyManager *apikey.Manager)
 
 		validKey, err := apikeyManager.ValidateAPIKey(key)
 		if err != nil {
-			logger.Warn("Invalid API key used",
+			logger.WarnContext(ctx.Context(), "Invalid API key used",
 				"key_prefix", keyPrefix(key),
 				"error", err,
 				"method", ctx.Method(),
 				"path", ctx.URL().Path,
 				"remote_addr", ctx.RemoteAddr(),
 			)
+			if limiter.AuthFailed(ip) {
+				logger.WarnContext(ctx.Context(), "Locking out IP after repeated invalid API keys", "remote_addr", ctx.RemoteAddr())
+			}
 			_ = huma.WriteErr(api, ctx, http.StatusUnauthorized, "Unauthorized: "+err.Error())
 			return
 		}
 
-		logger.Debug("Authenticated API key",
+		if ok, wait := limiter.AllowKey(validKey.Key); !ok {
+			ctx.SetHeader("Retry-After", retryAfterSeconds(wait))
+			_ = huma.WriteErr(api, ctx, http.StatusTooManyRequests, "Too many requests for this API key")
+			return
+		}
+
+		if !keyAllowed(validKey, ctx.Method(), op.Path, ctx.Header("Upgrade")) {
+			logger.WarnContext(ctx.Context(), "API key lacks required scope",
+				"name", validKey.Name,
+				"method", ctx.Method(),
+				"path", ctx.URL().Path,
+				"remote_addr", ctx.RemoteAddr(),
+			)
+			_ = huma.WriteErr(api, ctx, http.StatusForbidden, "Forbidden: API key lacks the required scope")
+			return
+		}
+
+		logger.DebugContext(ctx.Context(), "Authenticated API key",
 			"name", validKey.Name,
 			"key_prefix", keyPrefix(validKey.Key),
 		)
-		next(ctx)
+		setPrincipal(ctx.Context(), Principal{APIKey: validKey.Name})
+		next(huma.WithValue(ctx, grantedScopesKey{}, keyScopes(validKey)))
 	}
 }
 
@@ -85,10 +137,22 @@ func operationRequiresAuth(op *huma.Operation) bool {
 
 // RawAPIKeyAuth returns a Chi middleware for raw (non-Huma) handlers that need
 // API key authentication. Used for endpoints like the 207 Multi-Status group
-// state handler that bypass Huma's routing.
-func RawAPIKeyAuth(logger *slog.Logger, apikeyManager *apikey.Manager) func(http.Handler) http.Handler {
+// state handler that bypass Huma's routing. Client certificates and rate
+// limits are handled as in HumaAuth.
+func RawAPIKeyAuth(logger *slog.Logger, apikeyManager *apikey.Manager, certs *ClientCertAuth, limiter *RateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if trustedListener(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ip := clientIP(r.RemoteAddr)
+			if wait := limiter.LockedOut(ip); wait > 0 {
+				w.Header().Set("Retry-After", retryAfterSeconds(wait))
+				WriteError(w, http.StatusTooManyRequests, "Too many invalid API keys; try again later")
+				return
+			}
+
 			key := r.Header.Get("Authorization")
 			const bearerPrefix = "Bearer "
 			if strings.HasPrefix(key, bearerPrefix) {
@@ -97,38 +161,137 @@ func RawAPIKeyAuth(logger *slog.Logger, apikeyManager *apikey.Manager) func(http
 				key = r.Header.Get("X-API-Key")
 			}
 
+			if principal, ok := certs.Principal(r.TLS); ok && key == "" {
+				if !certs.Allowed(principal, r.Method, routePattern(r), r.Header.Get("Upgrade")) {
+					logger.WarnContext(r.Context(), "Client certificate lacks required scope",
+						"principal", principal,
+						"method", r.Method,
+						"path", r.URL.Path,
+						"remote_addr", r.RemoteAddr,
+					)
+					WriteError(w, http.StatusForbidden, "Forbidden: client certificate lacks the required scope")
+					return
+				}
+				logger.DebugContext(r.Context(), "Authenticated client certificate", "principal", principal)
+				setPrincipal(r.Context(), Principal{ClientCert: principal})
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), grantedScopesKey{}, certs.Scopes(principal))))
+				return
+			}
+
 			if key == "" {
-				logger.Warn("API key missing",
+				logger.WarnContext(r.Context(), "API key missing",
 					"method", r.Method,
 					"path", r.URL.Path,
 					"remote_addr", r.RemoteAddr,
 				)
-				http.Error(w, "Unauthorized: API key required", http.StatusUnauthorized)
+				WriteError(w, http.StatusUnauthorized, "Unauthorized: API key required")
 				return
 			}
 
 			validKey, err := apikeyManager.ValidateAPIKey(key)
 			if err != nil {
-				logger.Warn("Invalid API key used",
+				logger.WarnContext(r.Context(), "Invalid API key used",
 					"key_prefix", keyPrefix(key),
 					"error", err,
 					"method", r.Method,
 					"path", r.URL.Path,
 					"remote_addr", r.RemoteAddr,
 				)
-				http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+				if limiter.AuthFailed(ip) {
+					logger.WarnContext(r.Context(), "Locking out IP after repeated invalid API keys", "remote_addr", r.RemoteAddr)
+				}
+				WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
 				return
 			}
 
-			logger.Debug("Authenticated API key",
+			if ok, wait := limiter.AllowKey(validKey.Key); !ok {
+				w.Header().Set("Retry-After", retryAfterSeconds(wait))
+				WriteError(w, http.StatusTooManyRequests, "Too many requests for this API key")
+				return
+			}
+
+			if !keyAllowed(validKey, r.Method, routePattern(r), r.Header.Get("Upgrade")) {
+				logger.WarnContext(r.Context(), "API key lacks required scope",
+					"name", validKey.Name,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"remote_addr", r.RemoteAddr,
+				)
+				WriteError(w, http.StatusForbidden, "Forbidden: API key lacks the required scope")
+				return
+			}
+
+			logger.DebugContext(r.Context(), "Authenticated API key",
 				"name", validKey.Name,
 				"key_prefix", keyPrefix(validKey.Key),
 			)
-			next.ServeHTTP(w, r)
+			setPrincipal(r.Context(), Principal{APIKey: validKey.Name})
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), grantedScopesKey{}, keyScopes(validKey))))
 		})
 	}
 }
 
+// trustedListenerKey marks requests served by a listener that needs no
+// authentication, see TrustListener.
+type trustedListenerKey struct{}
+
+// TrustListener serves every request through next without authentication,
+// for listeners where connecting at all is proof enough, such as a Unix
+// socket only its owner can open.
+func TrustListener(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), trustedListenerKey{}, true)))
+	})
+}
+
+// trustedListener reports whether a request came through TrustListener.
+func trustedListener(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedListenerKey{}).(bool)
+	return trusted
+}
+
+// grantedScopesKey carries the scopes a request was authenticated with, see
+// RequestHasScope.
+type grantedScopesKey struct{}
+
+// RequestHasScope reports whether the request ctx belongs to was
+// authenticated with scope, for handlers where some options need more than
+// the route's own scope. Requests through a trusted listener have every
+// scope; requests that weren't authenticated have none.
+func RequestHasScope(ctx context.Context, scope string) bool {
+	if trustedListener(ctx) {
+		return true
+	}
+	scopes, ok := ctx.Value(grantedScopesKey{}).([]string)
+	return ok && HasScope(scopes, scope)
+}
+
+// keyScopes returns the scopes an API key grants. Keys without scopes have
+// every scope.
+func keyScopes(key *config.APIKey) []string {
+	if len(key.Scopes) == 0 {
+		return validScopes
+	}
+	return key.Scopes
+}
+
+// keyAllowed reports whether an API key has the scope required for a request.
+// Keys without scopes have every scope.
+func keyAllowed(key *config.APIKey, method, pattern, upgrade string) bool {
+	return len(key.Scopes) == 0 || scopesAllow(key.Scopes, method, pattern, upgrade)
+}
+
+// routePattern returns the chi route pattern a raw request matched, or its
+// path if it wasn't routed by chi.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
 // keyPrefix returns the first 4 characters of a key for safe logging.
 func keyPrefix(key string) string {
 	if len(key) >= 4 {
@@ -1,6 +1,10 @@
 package mw
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +13,8 @@ import (
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -43,7 +49,7 @@ func TestRawAPIKeyAuth_ValidBearerToken(t *testing.T) {
 	mgr, key := testSetup(t)
 	logger := testLogger()
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
 	}))
@@ -62,7 +68,7 @@ func TestRawAPIKeyAuth_ValidXAPIKeyHeader(t *testing.T) {
 	mgr, key := testSetup(t)
 	logger := testLogger()
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
 	}))
@@ -80,7 +86,7 @@ func TestRawAPIKeyAuth_MissingKey(t *testing.T) {
 	mgr, _ := testSetup(t)
 	logger := testLogger()
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Fatal("handler should not be called when key is missing")
 	}))
 
@@ -93,11 +99,26 @@ func TestRawAPIKeyAuth_MissingKey(t *testing.T) {
 	assert.Contains(t, rec.Body.String(), "API key required")
 }
 
+func TestRawAPIKeyAuth_TrustedListener(t *testing.T) {
+	mgr, _ := testSetup(t)
+
+	handler := TrustListener(RawAPIKeyAuth(testLogger(), mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPut, "/test", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "requests through a trusted listener need no API key")
+}
+
 func TestRawAPIKeyAuth_InvalidKey(t *testing.T) {
 	mgr, _ := testSetup(t)
 	logger := testLogger()
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Fatal("handler should not be called with invalid key")
 	}))
 
@@ -119,7 +140,7 @@ func TestRawAPIKeyAuth_DisabledKey(t *testing.T) {
 	_, err := mgr.SetAPIKeyDisabledStatus(key.Name, true)
 	require.NoError(t, err)
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Fatal("handler should not be called with disabled key")
 	}))
 
@@ -149,7 +170,7 @@ func TestRawAPIKeyAuth_ExpiredKey(t *testing.T) {
 	// Wait for expiration
 	time.Sleep(75 * time.Millisecond)
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Fatal("handler should not be called with expired key")
 	}))
 
@@ -167,7 +188,7 @@ func TestRawAPIKeyAuth_BearerPrefixPrecedence(t *testing.T) {
 	mgr, key := testSetup(t)
 	logger := testLogger()
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -186,7 +207,7 @@ func TestRawAPIKeyAuth_AuthorizationWithoutBearerFallsToXAPIKey(t *testing.T) {
 	mgr, key := testSetup(t)
 	logger := testLogger()
 
-	handler := RawAPIKeyAuth(logger, mgr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := RawAPIKeyAuth(logger, mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -201,6 +222,184 @@ func TestRawAPIKeyAuth_AuthorizationWithoutBearerFallsToXAPIKey(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
 
+// --- Client certificate tests ---
+
+// withClientCert attaches a verified client certificate with the given common name.
+func withClientCert(req *http.Request, commonName string) *http.Request {
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: commonName}}}},
+	}
+	return req
+}
+
+func TestRawAPIKeyAuth_ClientCertScopes(t *testing.T) {
+	mgr, _ := testSetup(t)
+	certs, err := NewClientCertAuth(config.TLSConfig{
+		ClientCAFile:        "ca.pem",
+		ClientScopes:        map[string][]string{"dashboard": {ScopeRead}, "panel": {ScopeRead, ScopeControl}},
+		DefaultClientScopes: []string{ScopeRead, ScopeWrite},
+	})
+	require.NoError(t, err)
+
+	handler := RawAPIKeyAuth(testLogger(), mgr, certs, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		commonName string
+		method     string
+		path       string
+		upgrade    string
+		want       int
+	}{
+		{"read scope allows GET", "dashboard", http.MethodGet, "/test", "", http.StatusOK},
+		{"read scope denies PUT", "dashboard", http.MethodPut, "/test", "", http.StatusForbidden},
+		{"read scope denies websocket", "dashboard", http.MethodGet, "/test", "websocket", http.StatusForbidden},
+		{"control scope allows state changes", "panel", http.MethodPost, "/api/v1/lights/light-1/state", "", http.StatusOK},
+		{"control scope allows websocket", "panel", http.MethodGet, "/api/v1/ws", "websocket", http.StatusOK},
+		{"control scope denies other changes", "panel", http.MethodDelete, "/api/v1/groups/group-1", "", http.StatusForbidden},
+		{"default scopes allow PUT", "desk", http.MethodPut, "/test", "", http.StatusOK},
+		{"write scope allows state changes", "desk", http.MethodPut, "/api/v1/groups/group-1/state", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withClientCert(httptest.NewRequestWithContext(t.Context(), tt.method, tt.path, nil), tt.commonName)
+			if tt.upgrade != "" {
+				req.Header.Set("Upgrade", tt.upgrade)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestRawAPIKeyAuth_APIKeyScopes(t *testing.T) {
+	mgr, _ := testSetup(t)
+	key, err := mgr.CreateScopedAPIKey("ui-tray", 0, []string{ScopeRead, ScopeControl})
+	require.NoError(t, err)
+
+	handler := RawAPIKeyAuth(testLogger(), mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"read", http.MethodGet, "/api/v1/lights", http.StatusOK},
+		{"light state", http.MethodPost, "/api/v1/lights/light-1/state", http.StatusOK},
+		{"group toggle", http.MethodPost, "/api/v1/groups/group-1/toggle", http.StatusOK},
+		{"api key management", http.MethodPost, "/api/v1/apikeys", http.StatusForbidden},
+		{"config import", http.MethodPost, "/api/v1/config/import", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), tt.method, tt.path, nil)
+			req.Header.Set("X-API-Key", key.Key)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestRawAPIKeyAuth_ScopeFromRoutePattern(t *testing.T) {
+	mgr, _ := testSetup(t)
+	key, err := mgr.CreateScopedAPIKey("ui-tray", 0, []string{ScopeRead, ScopeControl})
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.With(RawAPIKeyAuth(testLogger(), mgr, nil, nil)).Put("/api/v1/groups/{id}", ok)
+	router.With(RawAPIKeyAuth(testLogger(), mgr, nil, nil)).Put("/api/v1/groups/{id}/state", ok)
+
+	for path, want := range map[string]int{
+		"/api/v1/groups/state":       http.StatusForbidden,
+		"/api/v1/groups/state/state": http.StatusOK,
+	} {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodPut, path, nil)
+		req.Header.Set("X-API-Key", key.Key)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, want, rec.Code, path)
+	}
+}
+
+func TestHumaAuth_ScopeFromRoutePattern(t *testing.T) {
+	mgr, _ := testSetup(t)
+	key, err := mgr.CreateScopedAPIKey("ui-tray", 0, []string{ScopeRead, ScopeControl})
+	require.NoError(t, err)
+
+	type input struct {
+		ID string `path:"id"`
+	}
+	handler := func(context.Context, *input) (*struct{}, error) { return nil, nil }
+	_, api := humatest.New(t)
+	api.UseMiddleware(HumaAuth(api, testLogger(), mgr, nil, nil))
+	ProtectedPut(api, "/api/v1/groups/{id}", handler)
+	ProtectedPost(api, "/api/v1/groups/{id}/toggle", handler)
+
+	// A group whose ID is an action name is still changed with the write scope
+	resp := api.Put("/api/v1/groups/state", "X-API-Key: "+key.Key)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	resp = api.Post("/api/v1/groups/state/toggle", "X-API-Key: "+key.Key)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestRawAPIKeyAuth_ClientCertIgnoredWhenDisabled(t *testing.T) {
+	mgr, _ := testSetup(t)
+
+	handler := RawAPIKeyAuth(testLogger(), mgr, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := withClientCert(httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil), "dashboard")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRawAPIKeyAuth_APIKeyTakesPrecedenceOverClientCert(t *testing.T) {
+	mgr, _ := testSetup(t)
+	certs, err := NewClientCertAuth(config.TLSConfig{ClientCAFile: "ca.pem"})
+	require.NoError(t, err)
+
+	handler := RawAPIKeyAuth(testLogger(), mgr, certs, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := withClientCert(httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil), "dashboard")
+	req.Header.Set("X-API-Key", "invalid-key")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestNewClientCertAuth_UnknownScope(t *testing.T) {
+	_, err := NewClientCertAuth(config.TLSConfig{
+		ClientScopes: map[string][]string{"dashboard": {"admin"}},
+	})
+	assert.ErrorContains(t, err, `unknown client certificate scope "admin"`)
+
+	_, err = NewClientCertAuth(config.TLSConfig{DefaultClientScopes: []string{"admin"}})
+	assert.Error(t, err)
+}
+
 // --- operationRequiresAuth tests ---
 
 func TestOperationRequiresAuth_WithSecurity(t *testing.T) {
@@ -0,0 +1,148 @@
+package mw
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// Permission scopes granted to client certificates and API keys. API keys
+// without scopes have every scope.
+const (
+	// ScopeRead allows GET, HEAD and OPTIONS requests.
+	ScopeRead = "read"
+	// ScopeControl allows changing light and group state, applying scenes and
+	// WebSocket connections, whose commands only change state.
+	ScopeControl = "control"
+	// ScopeWrite allows all other requests, and everything ScopeControl allows.
+	ScopeWrite = "write"
+)
+
+// validScopes lists the scopes accepted in configuration.
+var validScopes = []string{ScopeRead, ScopeControl, ScopeWrite}
+
+// controlActions are the final segments of the route patterns ScopeControl
+// allows, such as /api/v1/groups/{id}/state.
+var controlActions = []string{"state", "toggle", "apply"}
+
+// ClientCertAuth authenticates requests by their verified TLS client
+// certificate and maps the certificate's common name to permission scopes.
+type ClientCertAuth struct {
+	scopes        map[string][]string
+	defaultScopes []string
+}
+
+// NewClientCertAuth creates a ClientCertAuth from the API TLS configuration.
+// Certificates whose common name is not in ClientScopes get DefaultClientScopes,
+// or every scope if that is empty.
+func NewClientCertAuth(cfg config.TLSConfig) (*ClientCertAuth, error) {
+	check := func(scopes []string) error {
+		for _, scope := range scopes {
+			if !ValidScope(scope) {
+				return fmt.Errorf("unknown client certificate scope %q; must be one of %s", scope, strings.Join(validScopes, ", "))
+			}
+		}
+		return nil
+	}
+	for _, scopes := range cfg.ClientScopes {
+		if err := check(scopes); err != nil {
+			return nil, err
+		}
+	}
+	if err := check(cfg.DefaultClientScopes); err != nil {
+		return nil, err
+	}
+
+	defaultScopes := cfg.DefaultClientScopes
+	if len(defaultScopes) == 0 {
+		defaultScopes = validScopes
+	}
+	return &ClientCertAuth{scopes: cfg.ClientScopes, defaultScopes: defaultScopes}, nil
+}
+
+// Principal returns the common name of the request's verified client
+// certificate, or false if the request did not present one.
+func (a *ClientCertAuth) Principal(state *tls.ConnectionState) (string, bool) {
+	if a == nil || state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return state.VerifiedChains[0][0].Subject.CommonName, true
+}
+
+// Allowed reports whether the named principal has the scope required for a
+// request with the given method, matched route pattern and Upgrade header.
+func (a *ClientCertAuth) Allowed(principal, method, pattern, upgrade string) bool {
+	return scopesAllow(a.Scopes(principal), method, pattern, upgrade)
+}
+
+// Scopes returns the scopes granted to the named principal.
+func (a *ClientCertAuth) Scopes(principal string) []string {
+	if scopes, ok := a.scopes[principal]; ok {
+		return scopes
+	}
+	return a.defaultScopes
+}
+
+// ValidScope reports whether scope is a known permission scope.
+func ValidScope(scope string) bool {
+	return slices.Contains(validScopes, scope)
+}
+
+// scopesAllow reports whether scopes include the scope required for a request.
+func scopesAllow(scopes []string, method, pattern, upgrade string) bool {
+	return HasScope(scopes, requiredScope(method, pattern, upgrade))
+}
+
+// HasScope reports whether scopes grant required. ScopeWrite includes
+// ScopeControl.
+func HasScope(scopes []string, required string) bool {
+	if required == ScopeControl && slices.Contains(scopes, ScopeWrite) {
+		return true
+	}
+	return slices.Contains(scopes, required)
+}
+
+// requiredScope returns the scope needed to perform a request. It goes by the
+// route pattern the request matched rather than its path, so a resource whose
+// ID is state or toggle is still written with ScopeWrite.
+func requiredScope(method, pattern, upgrade string) string {
+	if strings.EqualFold(upgrade, "websocket") {
+		return ScopeControl
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead
+	}
+	if slices.Contains(controlActions, path.Base(pattern)) {
+		return ScopeControl
+	}
+	return ScopeWrite
+}
+
+// ServerTLSConfig builds the HTTP server's TLS configuration. When a client CA
+// file is configured, clients must present a certificate signed by that CA.
+func ServerTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
@@ -0,0 +1,130 @@
+package mw
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// Defaults for the CORS settings left empty in the configuration.
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-ID"}
+)
+
+// corsExposedHeaders are response headers browser clients may read.
+const corsExposedHeaders = "Retry-After, X-Request-ID"
+
+// CORS answers cross-origin requests from browser clients such as dashboards.
+// A nil CORS adds no headers, so browsers refuse cross-origin responses.
+type CORS struct {
+	anyOrigin   bool
+	origins     []string
+	methods     string
+	headers     string
+	anyHeader   bool
+	credentials bool
+	maxAge      string
+}
+
+// NewCORS validates the api.cors configuration. It returns nil if no origins
+// are allowed.
+func NewCORS(cfg config.CORSConfig) (*CORS, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	c := &CORS{credentials: cfg.AllowCredentials}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return nil, fmt.Errorf("invalid CORS origin %q: must be scheme://host[:port] or *", origin)
+		}
+		c.origins = append(c.origins, strings.ToLower(u.Scheme+"://"+u.Host))
+	}
+	if c.anyOrigin && c.credentials {
+		return nil, errors.New("CORS allow_credentials cannot be used with the * origin; list the allowed origins instead")
+	}
+
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	c.methods = strings.ToUpper(strings.Join(methods, ", "))
+
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	c.anyHeader = slices.Contains(headers, "*")
+	c.headers = strings.Join(headers, ", ")
+
+	if cfg.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+	return c, nil
+}
+
+// Handler is a Chi middleware that adds CORS headers to responses for
+// allowed origins and answers preflight requests itself, as they carry no
+// API key. It must run before rate limiting so that limited responses can
+// be read by the browser.
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	if c == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !c.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		if c.anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Preflight
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", c.methods)
+		if c.anyHeader {
+			if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+		} else {
+			h.Set("Access-Control-Allow-Headers", c.headers)
+		}
+		if c.maxAge != "" {
+			h.Set("Access-Control-Max-Age", c.maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowed reports whether requests from origin are allowed.
+func (c *CORS) allowed(origin string) bool {
+	return c.anyOrigin || slices.Contains(c.origins, strings.ToLower(strings.TrimSuffix(origin, "/")))
+}
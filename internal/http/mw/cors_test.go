@@ -0,0 +1,96 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+func TestNewCORS(t *testing.T) {
+	cors, err := NewCORS(config.CORSConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, cors, "CORS is off without allowed origins")
+
+	_, err = NewCORS(config.CORSConfig{AllowedOrigins: []string{"example.com"}})
+	assert.Error(t, err, "origins need a scheme")
+
+	_, err = NewCORS(config.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	assert.Error(t, err)
+}
+
+func TestCORS_Handler(t *testing.T) {
+	cors, err := NewCORS(config.CORSConfig{
+		AllowedOrigins:   []string{"https://dash.example.com"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+	require.NoError(t, err)
+
+	called := false
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		called = false
+		req := httptest.NewRequestWithContext(t.Context(), method, "/api/v1/lights", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("allowed origin", func(t *testing.T) {
+		rec := do(http.MethodGet, "https://dash.example.com", nil)
+		assert.True(t, called)
+		assert.Equal(t, "https://dash.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "Retry-After, X-Request-ID", rec.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		rec := do(http.MethodGet, "https://evil.example.com", nil)
+		assert.True(t, called, "the request is served; the browser withholds the response")
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		rec := do(http.MethodOptions, "https://dash.example.com", map[string]string{
+			"Access-Control-Request-Method":  http.MethodPut,
+			"Access-Control-Request-Headers": "authorization, content-type",
+		})
+		assert.False(t, called, "preflights are answered without authentication")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://dash.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPut)
+		assert.Equal(t, "Authorization, Content-Type, X-API-Key, X-Request-ID", rec.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	})
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	cors, err := NewCORS(config.CORSConfig{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}})
+	require.NoError(t, err)
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodOptions, "/api/v1/lights", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	req.Header.Set("Access-Control-Request-Headers", "x-api-key")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "x-api-key", rec.Header().Get("Access-Control-Allow-Headers"))
+}
@@ -0,0 +1,63 @@
+package mw
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func init() {
+	// Every error Huma writes, including validation and auth errors, uses
+	// ErrorModel so HTTP clients always get a code.
+	huma.NewError = NewError
+}
+
+// ErrorModel is the body of HTTP error responses: Huma's RFC 9457 problem
+// details, plus the error code and details shared with the socket API.
+type ErrorModel struct {
+	huma.ErrorModel
+	Code    kerrors.Code   `json:"code" doc:"Machine-readable error code, shared with the socket API" example:"not_found"`
+	Details map[string]any `json:"details,omitempty" doc:"Additional information about the error"`
+}
+
+// NewError creates an error response. The code is taken from the first of errs
+// that carries one, and otherwise from the status.
+func NewError(status int, message string, errs ...error) huma.StatusError {
+	model := &ErrorModel{
+		ErrorModel: huma.ErrorModel{Status: status, Title: http.StatusText(status), Detail: message},
+		Code:       kerrors.CodeForStatus(status),
+	}
+	coded := false
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var detailer huma.ErrorDetailer
+		if errors.As(err, &detailer) {
+			model.Errors = append(model.Errors, detailer.ErrorDetail())
+			continue
+		}
+		var apiErr *kerrors.Error
+		if !coded && errors.As(err, &apiErr) {
+			model.Code, model.Details, coded = apiErr.Code, apiErr.Details, true
+		}
+		model.Errors = append(model.Errors, &huma.ErrorDetail{Message: err.Error()})
+	}
+	return model
+}
+
+// WriteError writes an error response from a raw (non-Huma) handler or
+// middleware, in the same format as Huma's error responses, so clients get the
+// same body and error code whichever route they hit.
+func WriteError(w http.ResponseWriter, status int, message string, errs ...error) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(NewError(status, message, errs...)); err != nil {
+		slog.Error("Failed to encode error response", "error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package mw
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestNewError(t *testing.T) {
+	// The code follows the status when no error carries one
+	model, ok := huma.Error404NotFound("Group not found").(*ErrorModel)
+	require.True(t, ok, "huma errors should use ErrorModel")
+	assert.Equal(t, kerrors.CodeNotFound, model.Code)
+	assert.Equal(t, "Group not found", model.Detail)
+
+	coded := kerrors.Errorf(kerrors.CodeNotFound, "no groups found").WithDetails(map[string]any{"not_found": []string{"g1"}})
+	model = NewError(http.StatusBadRequest, "Bad request", errors.New("plain"), coded).(*ErrorModel)
+	assert.Equal(t, kerrors.CodeNotFound, model.Code)
+	assert.Equal(t, []string{"g1"}, model.Details["not_found"])
+	assert.Len(t, model.Errors, 2)
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, http.StatusUnauthorized, "Unauthorized")
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "unauthorized", body["code"])
+	assert.Equal(t, "Unauthorized", body["detail"])
+	assert.EqualValues(t, 401, body["status"])
+}
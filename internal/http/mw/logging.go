@@ -1,19 +1,23 @@
 package mw
 
 import (
+	"bufio"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 )
 
-// loggingResponseWriter wraps http.ResponseWriter to capture the status code.
+// loggingResponseWriter wraps http.ResponseWriter to capture the status code
+// and the size of the body.
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int64
 }
 
 func newLoggingResponseWriter(w http.ResponseWriter) *loggingResponseWriter {
-	return &loggingResponseWriter{w, http.StatusOK}
+	return &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 }
 
 func (lrw *loggingResponseWriter) WriteHeader(code int) {
@@ -21,13 +25,42 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
-// RequestLogging returns a Chi middleware that logs HTTP requests and responses.
-func RequestLogging(logger *slog.Logger) func(http.Handler) http.Handler {
+func (lrw *loggingResponseWriter) Write(p []byte) (int, error) {
+	n, err := lrw.ResponseWriter.Write(p)
+	lrw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
+// Hijack supports WebSocket upgrades, which type-assert http.Hijacker
+// directly. The upgrade's response is written to the connection, so it is
+// recorded as 101 here.
+func (lrw *loggingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(lrw.ResponseWriter).Hijack()
+	if err == nil {
+		lrw.statusCode = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// RequestLogging returns a Chi middleware that logs HTTP requests and
+// responses. If access is non-nil, each request is also written to the access
+// log once it is done, with the API key or client certificate that the auth
+// middlewares authenticated it with.
+func RequestLogging(logger *slog.Logger, access *AccessLog) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			ctx, principal := withPrincipal(r.Context())
+			r = r.WithContext(ctx)
+			// Handlers under a base path may see a rewritten URL
+			uri := r.URL.RequestURI()
 
-			logger.Debug("HTTP Request Received",
+			logger.DebugContext(r.Context(), "HTTP Request Received",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"remote_addr", r.RemoteAddr,
@@ -37,12 +70,19 @@ func RequestLogging(logger *slog.Logger) func(http.Handler) http.Handler {
 			lrw := newLoggingResponseWriter(w)
 			next.ServeHTTP(lrw, r)
 
-			logger.Debug("HTTP Response Sent",
+			logger.DebugContext(r.Context(), "HTTP Response Sent",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", lrw.statusCode,
 				"duration", time.Since(start),
 			)
+			access.log(r, accessEntry{
+				start:     start,
+				uri:       uri,
+				status:    lrw.statusCode,
+				bytes:     lrw.bytes,
+				principal: *principal,
+			})
 		})
 	}
 }
@@ -0,0 +1,95 @@
+package mw
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies resolves the real client address of requests forwarded by
+// reverse proxies. Forwarding headers are only believed when the request
+// comes from a trusted proxy, as anyone else could set them. A nil
+// TrustedProxies trusts no one.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+}
+
+// NewTrustedProxies parses the api.trusted_proxies configuration, a list of
+// IP addresses and CIDR ranges. It returns nil if the list is empty.
+func NewTrustedProxies(proxies []string) (*TrustedProxies, error) {
+	if len(proxies) == 0 {
+		return nil, nil
+	}
+	t := &TrustedProxies{}
+	for _, p := range proxies {
+		if prefix, err := netip.ParsePrefix(p); err == nil {
+			t.prefixes = append(t.prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: must be an IP address or CIDR range", p)
+		}
+		t.prefixes = append(t.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return t, nil
+}
+
+// RealIP is a Chi middleware that replaces the remote address of requests
+// from a trusted proxy with the client address the proxy forwarded, so that
+// logging, rate limiting and lockouts apply to the client rather than the
+// proxy. It must run before those middlewares.
+func (t *TrustedProxies) RealIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := t.forwardedFor(r); ok {
+			r.RemoteAddr = ip
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedFor returns the client address forwarded with a request from a
+// trusted proxy. X-Forwarded-For is read right to left, skipping trusted
+// proxies, so that addresses a client prepended itself are ignored.
+// X-Real-IP is used when there is no X-Forwarded-For.
+func (t *TrustedProxies) forwardedFor(r *http.Request) (string, bool) {
+	if t == nil || !t.trusted(clientIP(r.RemoteAddr)) {
+		return "", false
+	}
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		var client string
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			client = hop
+			if !t.trusted(hop) {
+				break
+			}
+		}
+		return client, client != ""
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip, true
+	}
+	return "", false
+}
+
+// trusted reports whether ip is a trusted proxy.
+func (t *TrustedProxies) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range t.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
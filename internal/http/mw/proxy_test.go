@@ -0,0 +1,68 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTrustedProxies(t *testing.T) {
+	proxies, err := NewTrustedProxies(nil)
+	require.NoError(t, err)
+	assert.Nil(t, proxies)
+
+	_, err = NewTrustedProxies([]string{"10.0.0.0/8", "not-an-ip"})
+	assert.Error(t, err)
+}
+
+func TestTrustedProxies_RealIP(t *testing.T) {
+	proxies, err := NewTrustedProxies([]string{"10.0.0.1", "172.16.0.0/12"})
+	require.NoError(t, err)
+
+	var seen string
+	handler := proxies.RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.RemoteAddr
+	}))
+	do := func(remoteAddr string, headers map[string]string) string {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return seen
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"no headers", "10.0.0.1:5000", nil, "10.0.0.1:5000"},
+		{"forwarded by trusted proxy", "10.0.0.1:5000", map[string]string{"X-Forwarded-For": "192.168.1.20"}, "192.168.1.20"},
+		{"untrusted peer is ignored", "192.168.1.99:5000", map[string]string{"X-Forwarded-For": "192.168.1.20"}, "192.168.1.99:5000"},
+		{"spoofed hops are skipped", "10.0.0.1:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, 192.168.1.20, 172.16.0.5"}, "192.168.1.20"},
+		{"all hops trusted", "10.0.0.1:5000", map[string]string{"X-Forwarded-For": "172.16.0.5"}, "172.16.0.5"},
+		{"invalid hop", "10.0.0.1:5000", map[string]string{"X-Forwarded-For": "garbage"}, "10.0.0.1:5000"},
+		{"X-Real-IP", "10.0.0.1:5000", map[string]string{"X-Real-IP": "192.168.1.20"}, "192.168.1.20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, do(tt.remoteAddr, tt.headers))
+		})
+	}
+}
+
+func TestTrustedProxies_Nil(t *testing.T) {
+	var proxies *TrustedProxies
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "192.168.1.20")
+	proxies.RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "10.0.0.1:5000", r.RemoteAddr)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+}
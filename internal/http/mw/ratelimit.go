@@ -1,30 +1,197 @@
 package mw
 
 import (
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
-	"github.com/go-chi/httprate"
+	"github.com/jmylchreest/keylightd/internal/config"
 )
 
-// RateLimitConfig holds configuration for rate limiting.
-type RateLimitConfig struct {
-	// RequestsPerMinute is the maximum number of requests per minute per IP.
-	RequestsPerMinute int
+// sweepInterval is how often idle buckets and expired lockouts are discarded.
+const sweepInterval = time.Minute
+
+// RateLimiter enforces token bucket rate limits per client IP and per API key,
+// and locks out IPs that repeatedly present invalid API keys. A nil
+// RateLimiter allows everything.
+type RateLimiter struct {
+	cfg config.RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	ips       map[string]*bucket
+	keys      map[string]*bucket
+	failures  map[string]*authFailures
+	lastSweep time.Time
+}
+
+// bucket is a token bucket; tokens refill continuously up to the burst size.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// authFailures tracks invalid API key attempts from a single IP.
+type authFailures struct {
+	count       int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// NewRateLimiter creates a RateLimiter from the API rate limit configuration.
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:      cfg,
+		now:      time.Now,
+		ips:      make(map[string]*bucket),
+		keys:     make(map[string]*bucket),
+		failures: make(map[string]*authFailures),
+	}
+}
+
+// LimitByIP is a Chi middleware that rate limits requests by client IP.
+func (l *RateLimiter) LimitByIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, retryAfter := l.AllowIP(clientIP(r.RemoteAddr)); !ok {
+			w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+			WriteError(w, http.StatusTooManyRequests, "Too Many Requests")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AllowIP takes a token from the client IP's bucket. If none is available it
+// returns false and how long until one will be.
+func (l *RateLimiter) AllowIP(ip string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	return l.take(l.ips, ip, l.cfg.RequestsPerMinute)
+}
+
+// AllowKey takes a token from the API key's bucket. If none is available it
+// returns false and how long until one will be.
+func (l *RateLimiter) AllowKey(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	return l.take(l.keys, key, l.cfg.KeyRequestsPerMinute)
+}
+
+// LockedOut reports how much longer the IP is locked out for, or 0 if it is not.
+func (l *RateLimiter) LockedOut(ip string) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.failures[ip]
+	if !ok {
+		return 0
+	}
+	return max(f.lockedUntil.Sub(l.now()), 0)
 }
 
-// DefaultRateLimitConfig returns sensible defaults for rate limiting.
-func DefaultRateLimitConfig() RateLimitConfig {
-	return RateLimitConfig{
-		RequestsPerMinute: 120,
+// AuthFailed records an invalid API key from the IP and reports whether the
+// IP is now locked out.
+func (l *RateLimiter) AuthFailed(ip string) bool {
+	if l == nil || l.cfg.MaxFailedAuth <= 0 || l.cfg.LockoutDuration <= 0 {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	window := time.Duration(l.cfg.FailedAuthWindow) * time.Second
+
+	f, ok := l.failures[ip]
+	if !ok {
+		f = &authFailures{windowStart: now}
+		l.failures[ip] = f
+	} else if now.Sub(f.windowStart) > window {
+		f.count = 0
+		f.windowStart = now
+	}
+	f.count++
+	if f.count < l.cfg.MaxFailedAuth {
+		return false
 	}
+	f.count = 0
+	f.windowStart = now
+	f.lockedUntil = now.Add(time.Duration(l.cfg.LockoutDuration) * time.Second)
+	return true
 }
 
-// RateLimitByIP returns a Chi middleware that rate limits by IP address.
-func RateLimitByIP(cfg RateLimitConfig) func(http.Handler) http.Handler {
-	if cfg.RequestsPerMinute <= 0 {
-		// No rate limiting
-		return func(next http.Handler) http.Handler { return next }
+// take removes a token from the named bucket, creating it full if needed.
+func (l *RateLimiter) take(buckets map[string]*bucket, name string, perMinute int) (bool, time.Duration) {
+	if perMinute <= 0 {
+		return true, 0
 	}
-	return httprate.LimitByIP(cfg.RequestsPerMinute, time.Minute)
+	rate := float64(perMinute) / 60 // tokens per second
+	capacity := float64(max(l.cfg.Burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := buckets[name]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		buckets[name] = b
+	}
+	b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep discards buckets that have refilled and failure records that no
+// longer affect anything, so idle clients do not accumulate. Callers must
+// hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	burst := float64(max(l.cfg.Burst, 1))
+	for _, set := range []struct {
+		buckets   map[string]*bucket
+		perMinute int
+	}{{l.ips, l.cfg.RequestsPerMinute}, {l.keys, l.cfg.KeyRequestsPerMinute}} {
+		// Time for an empty bucket to refill completely
+		refill := time.Duration(burst / float64(max(set.perMinute, 1)) * float64(time.Minute))
+		for name, b := range set.buckets {
+			if now.Sub(b.last) >= refill {
+				delete(set.buckets, name)
+			}
+		}
+	}
+	window := time.Duration(l.cfg.FailedAuthWindow) * time.Second
+	for ip, f := range l.failures {
+		if now.After(f.lockedUntil) && now.Sub(f.windowStart) > window {
+			delete(l.failures, ip)
+		}
+	}
+}
+
+// clientIP returns the host part of a request's remote address.
+func clientIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// retryAfterSeconds formats a wait as a Retry-After header value, rounding up
+// to at least one second.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1))
 }
@@ -0,0 +1,137 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// testLimiter returns a RateLimiter whose clock is advanced by the returned function.
+func testLimiter(cfg config.RateLimitConfig) (*RateLimiter, func(time.Duration)) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(cfg)
+	l.now = func() time.Time { return now }
+	return l, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateLimiter_LimitByIP(t *testing.T) {
+	l, advance := testLimiter(config.RateLimitConfig{RequestsPerMinute: 60, Burst: 2})
+	handler := l.LimitByIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:1000").Code)
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:1001").Code)
+
+	rec := do("10.0.0.1:1002")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), `"code":"rate_limited"`)
+
+	// Other IPs have their own bucket
+	assert.Equal(t, http.StatusOK, do("10.0.0.2:1000").Code)
+
+	// One token refills per second at 60 requests per minute
+	advance(time.Second)
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:1003").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.1:1004").Code)
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	l, _ := testLimiter(config.RateLimitConfig{})
+	for range 100 {
+		ok, _ := l.AllowIP("10.0.0.1")
+		require.True(t, ok)
+	}
+	assert.False(t, l.AuthFailed("10.0.0.1"))
+
+	var nilLimiter *RateLimiter
+	ok, _ := nilLimiter.AllowKey("key")
+	assert.True(t, ok)
+	assert.Zero(t, nilLimiter.LockedOut("10.0.0.1"))
+}
+
+func TestRateLimiter_AuthFailureLockout(t *testing.T) {
+	l, advance := testLimiter(config.RateLimitConfig{MaxFailedAuth: 3, FailedAuthWindow: 60, LockoutDuration: 300})
+
+	assert.False(t, l.AuthFailed("10.0.0.1"))
+	assert.False(t, l.AuthFailed("10.0.0.1"))
+
+	// Failures outside the window start a new count
+	advance(2 * time.Minute)
+	assert.False(t, l.AuthFailed("10.0.0.1"))
+	assert.False(t, l.AuthFailed("10.0.0.1"))
+	assert.Zero(t, l.LockedOut("10.0.0.1"))
+
+	assert.True(t, l.AuthFailed("10.0.0.1"))
+	assert.Equal(t, 5*time.Minute, l.LockedOut("10.0.0.1"))
+	assert.Zero(t, l.LockedOut("10.0.0.2"))
+
+	advance(5 * time.Minute)
+	assert.Zero(t, l.LockedOut("10.0.0.1"))
+}
+
+func TestRawAPIKeyAuth_LockoutAfterInvalidKeys(t *testing.T) {
+	mgr, key := testSetup(t)
+	l, _ := testLimiter(config.RateLimitConfig{MaxFailedAuth: 2, FailedAuthWindow: 60, LockoutDuration: 60})
+
+	handler := RawAPIKeyAuth(testLogger(), mgr, nil, l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil)
+		req.RemoteAddr = "10.0.0.1:1000"
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do("wrong-1").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("wrong-2").Code)
+
+	// Locked out IPs are refused even with a valid key
+	rec := do(key.Key)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+}
+
+func TestRawAPIKeyAuth_KeyRateLimit(t *testing.T) {
+	mgr, key := testSetup(t)
+	l, _ := testLimiter(config.RateLimitConfig{KeyRequestsPerMinute: 30, Burst: 1})
+
+	handler := RawAPIKeyAuth(testLogger(), mgr, nil, l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+key.Key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, do("10.0.0.1:1000").Code)
+
+	// The key's limit applies across IPs
+	rec := do("10.0.0.2:1000")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+}
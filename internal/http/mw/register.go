@@ -117,6 +117,19 @@ func ProtectedPut[I, O any](api huma.API, path string, handler func(ctx context.
 	huma.Register(api, op, handler)
 }
 
+// ProtectedPatch registers a PATCH endpoint that requires API key auth.
+func ProtectedPatch[I, O any](api huma.API, path string, handler func(ctx context.Context, input *I) (*O, error), opts ...OperationOption) {
+	op := huma.Operation{
+		Method:   http.MethodPatch,
+		Path:     path,
+		Security: []map[string][]string{{SecurityScheme: {}}},
+	}
+	for _, opt := range opts {
+		opt(&op)
+	}
+	huma.Register(api, op, handler)
+}
+
 // ProtectedDelete registers a DELETE endpoint that requires API key auth.
 func ProtectedDelete[I, O any](api huma.API, path string, handler func(ctx context.Context, input *I) (*O, error), opts ...OperationOption) {
 	op := huma.Operation{
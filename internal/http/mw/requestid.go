@@ -0,0 +1,19 @@
+package mw
+
+import (
+	"net/http"
+
+	"github.com/jmylchreest/keylightd/internal/requestid"
+)
+
+// RequestID is a Chi middleware that gives each request an ID, taken from
+// the client's X-Request-ID header if it is valid or generated otherwise. The
+// ID is returned in the response's X-Request-ID header and carried by the
+// request's context, so lines logged while handling it include it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestid.Resolve(r.Header.Get(requestid.Header))
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithID(r.Context(), id)))
+	})
+}
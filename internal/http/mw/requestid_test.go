@@ -0,0 +1,39 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jmylchreest/keylightd/internal/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestid.FromContext(r.Context())
+	}))
+	do := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/test", nil)
+		if header != "" {
+			req.Header.Set(requestid.Header, header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("")
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, rec.Header().Get(requestid.Header))
+
+	rec = do("client-trace-1")
+	assert.Equal(t, "client-trace-1", seen)
+	assert.Equal(t, "client-trace-1", rec.Header().Get(requestid.Header))
+
+	rec = do("not valid")
+	assert.NotEqual(t, "not valid", seen)
+	assert.Equal(t, seen, rec.Header().Get(requestid.Header))
+}
@@ -9,17 +9,46 @@ import (
 // HealthCheckFunc is the type for health check handler functions.
 type HealthCheckFunc func(ctx context.Context, input *handlers.HealthInput) (*handlers.HealthOutput, error)
 
+// ReadyCheckFunc is the type for readiness handler functions.
+type ReadyCheckFunc func(ctx context.Context, input *handlers.ReadyInput) (*handlers.ReadyOutput, error)
+
 // VersionCheckFunc is the type for version handler functions.
 type VersionCheckFunc func(ctx context.Context, input *handlers.VersionInput) (*handlers.VersionOutput, error)
 
+// DaemonInfoFunc is the type for daemon info handler functions.
+type DaemonInfoFunc func(ctx context.Context, input *handlers.DaemonInfoInput) (*handlers.DaemonInfoOutput, error)
+
+// DiscoveryScanFunc is the type for discovery scan handler functions.
+type DiscoveryScanFunc func(ctx context.Context, input *handlers.DiscoveryScanInput) (*handlers.DiscoveryScanOutput, error)
+
+// PendingDevicesFunc is the type for pending device list handler functions.
+type PendingDevicesFunc func(ctx context.Context, input *handlers.PendingDevicesInput) (*handlers.PendingDevicesOutput, error)
+
+// AdoptDeviceFunc is the type for device adoption handler functions.
+type AdoptDeviceFunc func(ctx context.Context, input *handlers.AdoptDeviceInput) (*handlers.AdoptDeviceOutput, error)
+
 // Handlers aggregates all handler interfaces for route registration.
 // For the main server, pass real handler implementations.
 // For OpenAPI generation, pass stub implementations.
 type Handlers struct {
 	HealthCheck  HealthCheckFunc
+	ReadyCheck   ReadyCheckFunc
 	VersionCheck VersionCheckFunc
+	DaemonInfo   DaemonInfoFunc
+	Discover     DiscoveryScanFunc
+	Pending      PendingDevicesFunc
+	Adopt        AdoptDeviceFunc
 	Light        handlers.LightHandlers
 	Group        handlers.GroupHandlers
 	APIKey       handlers.APIKeyHandlers
 	Logging      handlers.LoggingHandlers
+	Schedule     handlers.ScheduleHandlers
+	Scene        handlers.SceneHandlers
+	Job          handlers.JobHandlers
+	Circadian    handlers.CircadianHandlers
+	DesiredState handlers.DesiredStateHandlers
+	Webcam       handlers.WebcamHandlers
+	Stats        handlers.StatsHandlers
+	StreamDeck   handlers.StreamDeckHandlers
+	Backup       handlers.BackupHandlers
 }
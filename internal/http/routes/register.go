@@ -18,6 +18,7 @@ func Register(api huma.API, h *Handlers) {
 		mw.WithOperationID("healthCheck"))
 
 	mw.HiddenGet(api, "/healthz", h.HealthCheck)
+	mw.HiddenGet(api, "/readyz", h.ReadyCheck)
 
 	// --- Version ---
 	mw.PublicGet(api, "/api/v1/version", h.VersionCheck,
@@ -26,6 +27,32 @@ func Register(api huma.API, h *Handlers) {
 		mw.WithDescription("Returns the running daemon's version, commit, and build date. This endpoint does not require authentication."),
 		mw.WithOperationID("getVersion"))
 
+	// --- Daemon Info ---
+	mw.ProtectedGet(api, "/api/v1/info", h.DaemonInfo,
+		mw.WithTags("Version"),
+		mw.WithSummary("Daemon info"),
+		mw.WithDescription("Returns the running daemon's version, uptime, socket path, API listen address and discovery statistics."),
+		mw.WithOperationID("getDaemonInfo"))
+
+	// --- Discovery ---
+	mw.ProtectedPost(api, "/api/v1/discovery/scan", h.Discover,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Discover lights now"),
+		mw.WithDescription("Runs a discovery pass straight away instead of waiting for the next discovery interval, and returns the lights it found that weren't known before. The request waits for the pass to finish, which takes several seconds."),
+		mw.WithOperationID("discoverLights"))
+
+	mw.ProtectedGet(api, "/api/v1/discovery/pending", h.Pending,
+		mw.WithTags("Lights"),
+		mw.WithSummary("List pending devices"),
+		mw.WithDescription("Returns the devices discovery found that answered but are not lights the validation rules accept, such as Elgato models newer than this daemon. Devices not seen for the offline retention period are dropped."),
+		mw.WithOperationID("listPendingDevices"))
+
+	mw.ProtectedPost(api, "/api/v1/discovery/pending/{id}/adopt", h.Adopt,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Adopt a pending device"),
+		mw.WithDescription("Adds a pending device as a light and remembers the adoption, so discovery accepts it from then on. Returns 404 if no pending device has the ID, and 409 if it is already a light."),
+		mw.WithOperationID("adoptDevice"))
+
 	// --- Lights ---
 	mw.ProtectedGet(api, "/api/v1/lights", h.Light.ListLights,
 		mw.WithTags("Lights"),
@@ -41,9 +68,57 @@ func Register(api huma.API, h *Handlers) {
 	mw.ProtectedPost(api, "/api/v1/lights/{id}/state", h.Light.SetLightState,
 		mw.WithTags("Lights"),
 		mw.WithSummary("Set light state"),
-		mw.WithDescription("Set one or more properties (on, brightness, temperature) on a light."),
+		mw.WithDescription("Set one or more properties (on, brightness, temperature) on a light. In place of the ID, \"all\" sets them on every light, a glob pattern such as Desk* on every light whose ID or name matches, and a tag selector such as tag:side=left on every light with those tags."),
 		mw.WithOperationID("setLightState"))
 
+	mw.ProtectedPost(api, "/api/v1/lights/state", h.Light.SetLightsState,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Set multiple lights' state"),
+		mw.WithDescription("Apply per-light state updates concurrently in one request. The batch is rejected if any update is invalid; device failures return 207 with per-light errors."),
+		mw.WithOperationID("setLightsState"))
+
+	mw.ProtectedPost(api, "/api/v1/lights/{id}/toggle", h.Light.ToggleLight,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Toggle a light"),
+		mw.WithDescription("Invert the light's current power state and return the new state."),
+		mw.WithOperationID("toggleLight"))
+
+	mw.ProtectedPut(api, "/api/v1/lights/{id}/name", h.Light.SetLightName,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Set a light's display name"),
+		mw.WithDescription("Store a display name that overrides the one reported by the device. It is kept in the daemon's state and survives restarts. An empty name restores the device's own name."),
+		mw.WithOperationID("setLightName"))
+
+	mw.ProtectedPut(api, "/api/v1/lights/{id}/tags", h.Light.SetLightTags,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Set a light's tags"),
+		mw.WithDescription("Replace the tags of a light, such as location=desk or side=left. Tags are kept in the daemon's state, and a tag selector such as tag:side=left can be used in place of a light ID to set the state of every light with those tags."),
+		mw.WithOperationID("setLightTags"))
+
+	mw.ProtectedPut(api, "/api/v1/lights/{id}/device-name", h.Light.SetDeviceName,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Rename the physical light"),
+		mw.WithDescription("Write a new display name to the device, where other apps will also see it. Only Elgato lights support this. A display name override set with PUT /api/v1/lights/{id}/name still takes precedence in keylightd."),
+		mw.WithOperationID("setDeviceName"))
+
+	mw.ProtectedPost(api, "/api/v1/lights/{id}/raw", h.Light.RawRequest,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Send a raw request to a light"),
+		mw.WithDescription("Pass a request straight through to the light's own API, for device settings keylightd doesn't model such as /elgato/lights/settings or /elgato/battery-info. Only GET, PUT and POST to paths under /elgato/ are allowed, and only Elgato lights support this. The device's status code and body are returned as is."),
+		mw.WithOperationID("rawLightRequest"))
+
+	mw.ProtectedGet(api, "/api/v1/lights/{id}/settings", h.Light.GetLightSettings,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Get a light's power-on settings"),
+		mw.WithDescription("Read the settings stored on the light that control what it does when it gets power back, such as after a power cut. Only Elgato lights support this."),
+		mw.WithOperationID("getLightSettings"))
+
+	mw.ProtectedPut(api, "/api/v1/lights/{id}/settings", h.Light.SetLightSettings,
+		mw.WithTags("Lights"),
+		mw.WithSummary("Change a light's power-on settings"),
+		mw.WithDescription("Change what the light does when it gets power back: restore its last state, or turn on at a default brightness and temperature. Settings not given are left as they are. Only Elgato lights support this."),
+		mw.WithOperationID("setLightSettings"))
+
 	// --- Groups ---
 	mw.ProtectedGet(api, "/api/v1/groups", h.Group.ListGroups,
 		mw.WithTags("Groups"),
@@ -61,6 +136,18 @@ func Register(api huma.API, h *Handlers) {
 		mw.WithSummary("Get a group"),
 		mw.WithOperationID("getGroup"))
 
+	mw.ProtectedPatch(api, "/api/v1/groups/{id}", h.Group.UpdateGroup,
+		mw.WithTags("Groups"),
+		mw.WithSummary("Update a group"),
+		mw.WithDescription("Rename a group or change its description, icon or color. Fields left out are unchanged; an empty description, icon or color clears it."),
+		mw.WithOperationID("updateGroup"))
+
+	mw.ProtectedGet(api, "/api/v1/groups/{id}/state", h.Group.GetGroupState,
+		mw.WithTags("Groups"),
+		mw.WithSummary("Get group state"),
+		mw.WithDescription("Returns the aggregate state of a group's lights: whether all or any are on, their average brightness, their color temperature range and which lights are unreachable. It is computed from the lights' cached states, so UIs can show a group without fetching each light."),
+		mw.WithOperationID("getGroupState"))
+
 	mw.ProtectedDelete(api, "/api/v1/groups/{id}", h.Group.DeleteGroup,
 		mw.WithTags("Groups"),
 		mw.WithSummary("Delete a group"),
@@ -73,16 +160,24 @@ func Register(api huma.API, h *Handlers) {
 		mw.WithDescription("Set which lights belong to a group."),
 		mw.WithOperationID("setGroupLights"))
 
-	// Note: SetGroupState is registered as a raw Chi route in server.go
-	// because it needs to return HTTP 207 Multi-Status on partial failures,
-	// which Huma doesn't natively support. We still register it here for
-	// OpenAPI documentation purposes.
+	mw.ProtectedPut(api, "/api/v1/groups/{id}/defaults", h.Group.SetGroupDefaults,
+		mw.WithTags("Groups"),
+		mw.WithSummary("Set group defaults"),
+		mw.WithDescription("Set the default brightness and temperature of a group. With apply_on_join, the defaults are applied to member lights when they are discovered. Omit both values to clear the defaults."),
+		mw.WithOperationID("setGroupDefaults"))
+
 	mw.ProtectedPut(api, "/api/v1/groups/{id}/state", h.Group.SetGroupState,
 		mw.WithTags("Groups"),
 		mw.WithSummary("Set group state"),
-		mw.WithDescription("Set state for one or more groups. The ID parameter supports comma-separated IDs or names for multi-group targeting. Returns 200 on success, 207 on partial failure."),
+		mw.WithDescription("Set state for one or more groups. The ID parameter supports comma-separated IDs or names for multi-group targeting, and \"all\" or glob patterns such as office-* to select every matching group. Returns 200 on success, 207 on partial failure."),
 		mw.WithOperationID("setGroupState"))
 
+	mw.ProtectedPost(api, "/api/v1/groups/{id}/toggle", h.Group.ToggleGroup,
+		mw.WithTags("Groups"),
+		mw.WithSummary("Toggle a group"),
+		mw.WithDescription("Toggle one or more groups (comma-separated IDs, names, \"all\" or glob patterns such as office-*). A group with any light on is turned off; otherwise all its lights are turned on. Returns 200 on success, 207 on partial failure."),
+		mw.WithOperationID("toggleGroup"))
+
 	// --- API Keys ---
 	mw.ProtectedPost(api, "/api/v1/apikeys", h.APIKey.CreateAPIKey,
 		mw.WithTags("API Keys"),
@@ -119,9 +214,218 @@ func Register(api huma.API, h *Handlers) {
 		mw.WithDescription("Validates and replaces all active log filters. Invalid filters are rejected entirely."),
 		mw.WithOperationID("setLogFilters"))
 
+	mw.ProtectedPost(api, "/api/v1/logging/filters", h.Logging.AddFilter,
+		mw.WithTags("Logging"),
+		mw.WithSummary("Add a log filter"),
+		mw.WithDescription("Validates and adds a single log filter. A filter with the same type and pattern as an existing one replaces it."),
+		mw.WithOperationID("addLogFilter"),
+		mw.WithDefaultStatus(201))
+
+	mw.ProtectedDelete(api, "/api/v1/logging/filters", h.Logging.DeleteFilter,
+		mw.WithTags("Logging"),
+		mw.WithSummary("Remove a log filter"),
+		mw.WithDescription("Removes the log filter identified by the type and pattern query parameters."),
+		mw.WithOperationID("deleteLogFilter"),
+		mw.WithDefaultStatus(204))
+
+	mw.ProtectedGet(api, "/api/v1/logging/level", h.Logging.GetLevel,
+		mw.WithTags("Logging"),
+		mw.WithSummary("Get global log level"),
+		mw.WithOperationID("getLogLevel"))
+
 	mw.ProtectedPut(api, "/api/v1/logging/level", h.Logging.SetLevel,
 		mw.WithTags("Logging"),
 		mw.WithSummary("Set global log level"),
 		mw.WithDescription("Changes the global log level at runtime. Valid values: debug, info, warn, error."),
 		mw.WithOperationID("setLogLevel"))
+
+	mw.ProtectedGet(api, "/api/v1/logs", h.Logging.GetLogs,
+		mw.WithTags("Logging"),
+		mw.WithSummary("Get recent log lines"),
+		mw.WithDescription("Returns the most recent lines of the daemon's log, which it keeps in memory (config.logging.buffer_size lines), oldest first."),
+		mw.WithOperationID("getLogs"))
+
+	// --- Schedules ---
+	mw.ProtectedGet(api, "/api/v1/schedules", h.Schedule.ListSchedules,
+		mw.WithTags("Schedules"),
+		mw.WithSummary("List all schedules"),
+		mw.WithOperationID("listSchedules"))
+
+	mw.ProtectedGet(api, "/api/v1/schedules/upcoming", h.Schedule.ListUpcomingSchedules,
+		mw.WithTags("Schedules"),
+		mw.WithSummary("List upcoming schedule runs"),
+		mw.WithDescription("Returns when enabled schedules will next fire within the coming days, soonest first, skipping their skip dates. Each time is in its schedule's time zone."),
+		mw.WithOperationID("listUpcomingSchedules"))
+
+	mw.ProtectedPost(api, "/api/v1/schedules", h.Schedule.CreateSchedule,
+		mw.WithTags("Schedules"),
+		mw.WithSummary("Create a schedule"),
+		mw.WithDescription("Create a schedule that applies a state to a light or group, or applies a scene (optionally limited to a light or group), at a daily time (at) or on a cron expression (cron), in an optional time zone and skipping any skip dates."),
+		mw.WithOperationID("createSchedule"),
+		mw.WithDefaultStatus(201))
+
+	mw.ProtectedGet(api, "/api/v1/schedules/{id}", h.Schedule.GetSchedule,
+		mw.WithTags("Schedules"),
+		mw.WithSummary("Get a schedule"),
+		mw.WithOperationID("getSchedule"))
+
+	mw.ProtectedPut(api, "/api/v1/schedules/{id}", h.Schedule.UpdateSchedule,
+		mw.WithTags("Schedules"),
+		mw.WithSummary("Replace a schedule"),
+		mw.WithOperationID("updateSchedule"))
+
+	mw.ProtectedDelete(api, "/api/v1/schedules/{id}", h.Schedule.DeleteSchedule,
+		mw.WithTags("Schedules"),
+		mw.WithSummary("Delete a schedule"),
+		mw.WithOperationID("deleteSchedule"),
+		mw.WithDefaultStatus(204))
+
+	// --- Scenes ---
+	mw.ProtectedGet(api, "/api/v1/scenes", h.Scene.ListScenes,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("List all scenes"),
+		mw.WithOperationID("listScenes"))
+
+	mw.ProtectedPost(api, "/api/v1/scenes", h.Scene.CreateScene,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("Create a scene"),
+		mw.WithDescription("Create a scene: states for lights and groups applied together, in steps ordered by each entry's order, with per-entry transitions and delays."),
+		mw.WithOperationID("createScene"),
+		mw.WithDefaultStatus(201))
+
+	mw.ProtectedGet(api, "/api/v1/scenes/{id}", h.Scene.GetScene,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("Get a scene"),
+		mw.WithOperationID("getScene"))
+
+	mw.ProtectedPut(api, "/api/v1/scenes/{id}", h.Scene.UpdateScene,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("Replace a scene"),
+		mw.WithOperationID("updateScene"))
+
+	mw.ProtectedDelete(api, "/api/v1/scenes/{id}", h.Scene.DeleteScene,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("Delete a scene"),
+		mw.WithOperationID("deleteScene"),
+		mw.WithDefaultStatus(204))
+
+	mw.ProtectedPost(api, "/api/v1/scenes/{id}/apply", h.Scene.ApplyScene,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("Apply a scene"),
+		mw.WithDescription("Starts applying a scene in the background and returns its job. Follow it with GET /api/v1/jobs/{id}. Applying a scene that is already being applied cancels the earlier job."),
+		mw.WithOperationID("applyScene"),
+		mw.WithDefaultStatus(202))
+
+	mw.ProtectedGet(api, "/api/v1/scenes/{id}/run", h.Scene.GetSceneRun,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("Get the job of a scene's latest run"),
+		mw.WithOperationID("getSceneRun"))
+
+	mw.ProtectedDelete(api, "/api/v1/scenes/{id}/run", h.Scene.CancelSceneRun,
+		mw.WithTags("Scenes"),
+		mw.WithSummary("Stop applying a scene"),
+		mw.WithDescription("Cancels a scene's run, leaving its lights where it got them to, and returns its job. Fails with 409 if the scene isn't being applied."),
+		mw.WithOperationID("cancelSceneRun"))
+
+	// --- Jobs ---
+	mw.ProtectedGet(api, "/api/v1/jobs", h.Job.ListJobs,
+		mw.WithTags("Jobs"),
+		mw.WithSummary("List jobs"),
+		mw.WithDescription("Lists running and recently finished jobs, most recently started first. Jobs are long-running operations, such as applying a scene, that are started by a request and carry on in the background."),
+		mw.WithOperationID("listJobs"))
+
+	mw.ProtectedGet(api, "/api/v1/jobs/{id}", h.Job.GetJob,
+		mw.WithTags("Jobs"),
+		mw.WithSummary("Get a job"),
+		mw.WithOperationID("getJob"))
+
+	mw.ProtectedDelete(api, "/api/v1/jobs/{id}", h.Job.CancelJob,
+		mw.WithTags("Jobs"),
+		mw.WithSummary("Cancel a job"),
+		mw.WithDescription("Cancels a running job and returns it once it has stopped. Fails with 409 if the job has already finished."),
+		mw.WithOperationID("cancelJob"))
+
+	// --- Circadian ---
+	mw.ProtectedGet(api, "/api/v1/circadian", h.Circadian.GetCircadian,
+		mw.WithTags("Circadian"),
+		mw.WithSummary("Get circadian mode"),
+		mw.WithDescription("Returns whether circadian mode is enabled, the state it currently sets lights to and the lights it applies to."),
+		mw.WithOperationID("getCircadian"))
+
+	mw.ProtectedPut(api, "/api/v1/circadian", h.Circadian.SetCircadian,
+		mw.WithTags("Circadian"),
+		mw.WithSummary("Enable or disable circadian mode"),
+		mw.WithDescription("Turns circadian mode on or off and saves the choice to the daemon config. The curve itself is configured in the config file."),
+		mw.WithOperationID("setCircadian"))
+
+	// --- Desired state ---
+	mw.ProtectedGet(api, "/api/v1/desired-state", h.DesiredState.GetDesiredState,
+		mw.WithTags("Desired State"),
+		mw.WithSummary("Get desired-state mode"),
+		mw.WithDescription("Returns whether desired-state mode is enabled, how often it checks lights and, for each target, the state it holds, the lights it applies to and how often they were put back."),
+		mw.WithOperationID("getDesiredState"))
+
+	mw.ProtectedPut(api, "/api/v1/desired-state", h.DesiredState.SetDesiredState,
+		mw.WithTags("Desired State"),
+		mw.WithSummary("Enable or disable desired-state mode"),
+		mw.WithDescription("Turns desired-state mode on or off and saves the choice to the daemon state. The targets themselves are configured in the config file."),
+		mw.WithOperationID("setDesiredState"))
+
+	mw.ProtectedPut(api, "/api/v1/desired-state/targets/{target}", h.DesiredState.SetDesiredStateTarget,
+		mw.WithTags("Desired State"),
+		mw.WithSummary("Enable or disable a desired-state target"),
+		mw.WithDescription("Starts or stops enforcing one target, given by its key such as light:<id> or group:<name>, and saves the choice to the daemon state. Fails with 404 if no target has that key."),
+		mw.WithOperationID("setDesiredStateTarget"))
+
+	// --- Webcam ---
+	mw.ProtectedGet(api, "/api/v1/webcam", h.Webcam.GetWebcam,
+		mw.WithTags("Webcam"),
+		mw.WithSummary("Get webcam automation"),
+		mw.WithDescription("Returns whether the webcam watcher is enabled and supported, whether a webcam is in use, the current override and whether the groups are on because of it."),
+		mw.WithOperationID("getWebcam"))
+
+	mw.ProtectedPut(api, "/api/v1/webcam/override", h.Webcam.SetWebcamOverride,
+		mw.WithTags("Webcam"),
+		mw.WithSummary("Override webcam automation"),
+		mw.WithDescription("Forces the webcam groups on or off regardless of the webcam, or with auto follows the webcam again. The override lasts until the daemon restarts. Fails with 400 if the watcher is not enabled in the config."),
+		mw.WithOperationID("setWebcamOverride"))
+
+	// --- Stats ---
+	mw.ProtectedGet(api, "/api/v1/lights/{id}/stats", h.Stats.GetLightStats,
+		mw.WithTags("Stats"),
+		mw.WithSummary("Get light usage statistics"),
+		mw.WithDescription("Returns how long a light has been on in total, how often it was turned on or off, and when its state last changed. Statistics are kept across restarts."),
+		mw.WithOperationID("getLightStats"))
+
+	mw.ProtectedGet(api, "/api/v1/groups/{id}/stats", h.Stats.GetGroupStats,
+		mw.WithTags("Stats"),
+		mw.WithSummary("Get group usage statistics"),
+		mw.WithDescription("Returns the combined usage statistics of a group's lights: their total on-time and toggles and their latest changes, along with each light's own statistics."),
+		mw.WithOperationID("getGroupStats"))
+
+	// --- Stream Deck ---
+	mw.ProtectedGet(api, "/api/v1/streamdeck/state", h.StreamDeck.GetState,
+		mw.WithTags("Stream Deck"),
+		mw.WithSummary("Get a compact state snapshot"),
+		mw.WithDescription("Returns the name, power, brightness and temperature (Kelvin) of every light and group, sorted by name, for Stream Deck and Loupedeck plugins. States are the daemon's cached ones, so this is cheap to poll."),
+		mw.WithOperationID("getStreamDeckState"))
+
+	mw.ProtectedPost(api, "/api/v1/streamdeck/action", h.StreamDeck.Action,
+		mw.WithTags("Stream Deck"),
+		mw.WithSummary("Apply a button or dial action"),
+		mw.WithDescription("Toggle, turn on or off, or set or step the brightness or temperature of a light or group, and return its new compact state. The brightness and temperature actions take either a value or a step."),
+		mw.WithOperationID("streamDeckAction"))
+
+	// --- Backup ---
+	mw.ProtectedGet(api, "/api/v1/config/export", h.Backup.ExportConfig,
+		mw.WithTags("Backup"),
+		mw.WithSummary("Export groups, schedules and API keys"),
+		mw.WithDescription("Returns groups, schedules, API key metadata and light names as a portable document, for backups or moving to another host. API key secrets are only included when include_secrets is set, which needs the write scope."),
+		mw.WithOperationID("exportConfig"))
+
+	mw.ProtectedPost(api, "/api/v1/config/import", h.Backup.ImportConfig,
+		mw.WithTags("Backup"),
+		mw.WithSummary("Import an exported document"),
+		mw.WithDescription("Merges an exported document into the daemon's state. Groups and schedules are created or replaced by ID; API keys and light names are added. API keys without a secret, keys whose name is already in use and names for undiscovered lights are skipped and listed in the response."),
+		mw.WithOperationID("importConfig"))
 }
@@ -2,13 +2,23 @@ package routes
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
 
 	"github.com/jmylchreest/keylightd/internal/http/handlers"
 )
 
+// StubSpec returns the OpenAPI spec of the API, built from the shared route
+// definitions with stub handlers.
+func StubSpec(version, baseURL string) *huma.OpenAPI {
+	// A minimal chi router; no requests are ever served
+	api := humachi.New(chi.NewRouter(), NewHumaConfig(version, baseURL))
+	Register(api, StubHandlers())
+	return api.OpenAPI()
+}
+
 // StubHandlers returns a Handlers instance with stub implementations.
 // All handlers return nil responses — these are only used for OpenAPI generation
 // where Huma extracts type information from function signatures.
@@ -17,13 +27,37 @@ func StubHandlers() *Handlers {
 		HealthCheck: func(_ context.Context, _ *handlers.HealthInput) (*handlers.HealthOutput, error) {
 			return nil, nil
 		},
+		ReadyCheck: func(_ context.Context, _ *handlers.ReadyInput) (*handlers.ReadyOutput, error) {
+			return nil, nil
+		},
 		VersionCheck: func(_ context.Context, _ *handlers.VersionInput) (*handlers.VersionOutput, error) {
 			return nil, nil
 		},
-		Light:   &stubLightHandlers{},
-		Group:   &stubGroupHandlers{},
-		APIKey:  &stubAPIKeyHandlers{},
-		Logging: &stubLoggingHandlers{},
+		DaemonInfo: func(_ context.Context, _ *handlers.DaemonInfoInput) (*handlers.DaemonInfoOutput, error) {
+			return nil, nil
+		},
+		Discover: func(_ context.Context, _ *handlers.DiscoveryScanInput) (*handlers.DiscoveryScanOutput, error) {
+			return nil, nil
+		},
+		Pending: func(_ context.Context, _ *handlers.PendingDevicesInput) (*handlers.PendingDevicesOutput, error) {
+			return nil, nil
+		},
+		Adopt: func(_ context.Context, _ *handlers.AdoptDeviceInput) (*handlers.AdoptDeviceOutput, error) {
+			return nil, nil
+		},
+		Light:        &stubLightHandlers{},
+		Group:        &stubGroupHandlers{},
+		APIKey:       &stubAPIKeyHandlers{},
+		Logging:      &stubLoggingHandlers{},
+		Schedule:     &stubScheduleHandlers{},
+		Scene:        &stubSceneHandlers{},
+		Job:          &stubJobHandlers{},
+		Circadian:    &stubCircadianHandlers{},
+		DesiredState: &stubDesiredStateHandlers{},
+		Webcam:       &stubWebcamHandlers{},
+		Stats:        &stubStatsHandlers{},
+		StreamDeck:   &stubStreamDeckHandlers{},
+		Backup:       &stubBackupHandlers{},
 	}
 }
 
@@ -43,6 +77,38 @@ func (s *stubLightHandlers) SetLightState(_ context.Context, _ *handlers.SetLigh
 	return nil, nil
 }
 
+func (s *stubLightHandlers) SetLightsState(_ context.Context, _ *handlers.SetLightsStateInput) (*handlers.SetLightsStateOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLightHandlers) ToggleLight(_ context.Context, _ *handlers.ToggleLightInput) (*handlers.ToggleLightOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLightHandlers) SetLightName(_ context.Context, _ *handlers.SetLightNameInput) (*handlers.SetLightNameOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLightHandlers) SetLightTags(_ context.Context, _ *handlers.SetLightTagsInput) (*handlers.SetLightTagsOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLightHandlers) SetDeviceName(_ context.Context, _ *handlers.SetDeviceNameInput) (*handlers.SetDeviceNameOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLightHandlers) RawRequest(_ context.Context, _ *handlers.RawRequestInput) (*handlers.RawRequestOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLightHandlers) GetLightSettings(_ context.Context, _ *handlers.GetLightSettingsInput) (*handlers.LightSettingsOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLightHandlers) SetLightSettings(_ context.Context, _ *handlers.SetLightSettingsInput) (*handlers.LightSettingsOutput, error) {
+	return nil, nil
+}
+
 // --- Group stubs ---
 
 type stubGroupHandlers struct{}
@@ -59,6 +125,14 @@ func (s *stubGroupHandlers) GetGroup(_ context.Context, _ *handlers.GetGroupInpu
 	return nil, nil
 }
 
+func (s *stubGroupHandlers) UpdateGroup(_ context.Context, _ *handlers.UpdateGroupInput) (*handlers.UpdateGroupOutput, error) {
+	return nil, nil
+}
+
+func (s *stubGroupHandlers) GetGroupState(_ context.Context, _ *handlers.GetGroupStateInput) (*handlers.GetGroupStateOutput, error) {
+	return nil, nil
+}
+
 func (s *stubGroupHandlers) DeleteGroup(_ context.Context, _ *handlers.DeleteGroupInput) (*handlers.DeleteGroupOutput, error) {
 	return nil, nil
 }
@@ -67,14 +141,16 @@ func (s *stubGroupHandlers) SetGroupLights(_ context.Context, _ *handlers.SetGro
 	return nil, nil
 }
 
+func (s *stubGroupHandlers) SetGroupDefaults(_ context.Context, _ *handlers.SetGroupDefaultsInput) (*handlers.SetGroupDefaultsOutput, error) {
+	return nil, nil
+}
+
 func (s *stubGroupHandlers) SetGroupState(_ context.Context, _ *handlers.SetGroupStateInput) (*handlers.SetGroupStateOutput, error) {
 	return nil, nil
 }
 
-func (s *stubGroupHandlers) SetGroupStateRaw(_ huma.API) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Stub — never called during OpenAPI generation
-	}
+func (s *stubGroupHandlers) ToggleGroup(_ context.Context, _ *handlers.ToggleGroupInput) (*handlers.ToggleGroupOutput, error) {
+	return nil, nil
 }
 
 // --- API Key stubs ---
@@ -105,6 +181,18 @@ func (s *stubLoggingHandlers) ListFilters(_ context.Context, _ *handlers.ListFil
 	return nil, nil
 }
 
+func (s *stubLoggingHandlers) AddFilter(_ context.Context, _ *handlers.AddFilterInput) (*handlers.AddFilterOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLoggingHandlers) DeleteFilter(_ context.Context, _ *handlers.DeleteFilterInput) (*handlers.DeleteFilterOutput, error) {
+	return nil, nil
+}
+
+func (s *stubLoggingHandlers) GetLevel(_ context.Context, _ *handlers.GetLevelInput) (*handlers.GetLevelOutput, error) {
+	return nil, nil
+}
+
 func (s *stubLoggingHandlers) SetFilters(_ context.Context, _ *handlers.SetFiltersInput) (*handlers.SetFiltersOutput, error) {
 	return nil, nil
 }
@@ -112,3 +200,163 @@ func (s *stubLoggingHandlers) SetFilters(_ context.Context, _ *handlers.SetFilte
 func (s *stubLoggingHandlers) SetLevel(_ context.Context, _ *handlers.SetLevelInput) (*handlers.SetLevelOutput, error) {
 	return nil, nil
 }
+
+func (s *stubLoggingHandlers) GetLogs(_ context.Context, _ *handlers.GetLogsInput) (*handlers.GetLogsOutput, error) {
+	return nil, nil
+}
+
+// --- Schedule stubs ---
+
+type stubScheduleHandlers struct{}
+
+func (s *stubScheduleHandlers) ListSchedules(_ context.Context, _ *handlers.ListSchedulesInput) (*handlers.ListSchedulesOutput, error) {
+	return nil, nil
+}
+
+func (s *stubScheduleHandlers) ListUpcomingSchedules(_ context.Context, _ *handlers.ListUpcomingSchedulesInput) (*handlers.ListUpcomingSchedulesOutput, error) {
+	return nil, nil
+}
+
+func (s *stubScheduleHandlers) CreateSchedule(_ context.Context, _ *handlers.CreateScheduleInput) (*handlers.CreateScheduleOutput, error) {
+	return nil, nil
+}
+
+func (s *stubScheduleHandlers) GetSchedule(_ context.Context, _ *handlers.GetScheduleInput) (*handlers.GetScheduleOutput, error) {
+	return nil, nil
+}
+
+func (s *stubScheduleHandlers) UpdateSchedule(_ context.Context, _ *handlers.UpdateScheduleInput) (*handlers.UpdateScheduleOutput, error) {
+	return nil, nil
+}
+
+func (s *stubScheduleHandlers) DeleteSchedule(_ context.Context, _ *handlers.DeleteScheduleInput) (*handlers.DeleteScheduleOutput, error) {
+	return nil, nil
+}
+
+// --- Scene stubs ---
+
+type stubSceneHandlers struct{}
+
+func (s *stubSceneHandlers) ListScenes(_ context.Context, _ *handlers.ListScenesInput) (*handlers.ListScenesOutput, error) {
+	return nil, nil
+}
+
+func (s *stubSceneHandlers) CreateScene(_ context.Context, _ *handlers.CreateSceneInput) (*handlers.CreateSceneOutput, error) {
+	return nil, nil
+}
+
+func (s *stubSceneHandlers) GetScene(_ context.Context, _ *handlers.GetSceneInput) (*handlers.GetSceneOutput, error) {
+	return nil, nil
+}
+
+func (s *stubSceneHandlers) UpdateScene(_ context.Context, _ *handlers.UpdateSceneInput) (*handlers.UpdateSceneOutput, error) {
+	return nil, nil
+}
+
+func (s *stubSceneHandlers) DeleteScene(_ context.Context, _ *handlers.DeleteSceneInput) (*handlers.DeleteSceneOutput, error) {
+	return nil, nil
+}
+
+func (s *stubSceneHandlers) ApplyScene(_ context.Context, _ *handlers.SceneRunInput) (*handlers.JobOutput, error) {
+	return nil, nil
+}
+
+func (s *stubSceneHandlers) GetSceneRun(_ context.Context, _ *handlers.SceneRunInput) (*handlers.JobOutput, error) {
+	return nil, nil
+}
+
+func (s *stubSceneHandlers) CancelSceneRun(_ context.Context, _ *handlers.SceneRunInput) (*handlers.JobOutput, error) {
+	return nil, nil
+}
+
+// --- Job stubs ---
+
+type stubJobHandlers struct{}
+
+func (s *stubJobHandlers) ListJobs(_ context.Context, _ *handlers.ListJobsInput) (*handlers.ListJobsOutput, error) {
+	return nil, nil
+}
+
+func (s *stubJobHandlers) GetJob(_ context.Context, _ *handlers.JobInput) (*handlers.JobOutput, error) {
+	return nil, nil
+}
+
+func (s *stubJobHandlers) CancelJob(_ context.Context, _ *handlers.JobInput) (*handlers.JobOutput, error) {
+	return nil, nil
+}
+
+// --- Circadian stubs ---
+
+type stubCircadianHandlers struct{}
+
+func (s *stubCircadianHandlers) GetCircadian(_ context.Context, _ *handlers.GetCircadianInput) (*handlers.GetCircadianOutput, error) {
+	return nil, nil
+}
+
+func (s *stubCircadianHandlers) SetCircadian(_ context.Context, _ *handlers.SetCircadianInput) (*handlers.SetCircadianOutput, error) {
+	return nil, nil
+}
+
+// --- Desired state stubs ---
+
+type stubDesiredStateHandlers struct{}
+
+func (s *stubDesiredStateHandlers) GetDesiredState(_ context.Context, _ *handlers.GetDesiredStateInput) (*handlers.GetDesiredStateOutput, error) {
+	return nil, nil
+}
+
+func (s *stubDesiredStateHandlers) SetDesiredState(_ context.Context, _ *handlers.SetDesiredStateInput) (*handlers.SetDesiredStateOutput, error) {
+	return nil, nil
+}
+
+func (s *stubDesiredStateHandlers) SetDesiredStateTarget(_ context.Context, _ *handlers.SetDesiredStateTargetInput) (*handlers.SetDesiredStateTargetOutput, error) {
+	return nil, nil
+}
+
+// --- Webcam stubs ---
+
+type stubWebcamHandlers struct{}
+
+func (s *stubWebcamHandlers) GetWebcam(_ context.Context, _ *handlers.GetWebcamInput) (*handlers.GetWebcamOutput, error) {
+	return nil, nil
+}
+
+func (s *stubWebcamHandlers) SetWebcamOverride(_ context.Context, _ *handlers.SetWebcamOverrideInput) (*handlers.SetWebcamOverrideOutput, error) {
+	return nil, nil
+}
+
+// --- Stats stubs ---
+
+type stubStatsHandlers struct{}
+
+func (s *stubStatsHandlers) GetLightStats(_ context.Context, _ *handlers.GetLightStatsInput) (*handlers.GetLightStatsOutput, error) {
+	return nil, nil
+}
+
+func (s *stubStatsHandlers) GetGroupStats(_ context.Context, _ *handlers.GetGroupStatsInput) (*handlers.GetGroupStatsOutput, error) {
+	return nil, nil
+}
+
+// --- Stream Deck stubs ---
+
+type stubStreamDeckHandlers struct{}
+
+func (s *stubStreamDeckHandlers) GetState(_ context.Context, _ *handlers.GetStreamDeckStateInput) (*handlers.GetStreamDeckStateOutput, error) {
+	return nil, nil
+}
+
+func (s *stubStreamDeckHandlers) Action(_ context.Context, _ *handlers.StreamDeckActionInput) (*handlers.StreamDeckActionOutput, error) {
+	return nil, nil
+}
+
+// --- Backup stubs ---
+
+type stubBackupHandlers struct{}
+
+func (s *stubBackupHandlers) ExportConfig(_ context.Context, _ *handlers.ExportConfigInput) (*handlers.ExportConfigOutput, error) {
+	return nil, nil
+}
+
+func (s *stubBackupHandlers) ImportConfig(_ context.Context, _ *handlers.ImportConfigInput) (*handlers.ImportConfigOutput, error) {
+	return nil, nil
+}
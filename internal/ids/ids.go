@@ -0,0 +1,38 @@
+// Package ids generates the IDs of objects kept in state: a type prefix and a
+// UUIDv7, such as group-01920c5e-7c2a-7d4e-9b1f-3a6c8e0d2f41. Version 7 UUIDs
+// are unique across machines and sort by when they were made.
+package ids
+
+import (
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// New returns a new ID with the given prefix.
+func New(prefix string) string {
+	return prefix + "-" + uuid.Must(uuid.NewV7()).String()
+}
+
+// NewAt returns a new ID with the given prefix, made as if at t, for objects
+// created before they were given an ID of this form.
+func NewAt(prefix string, t time.Time) string {
+	u := uuid.Must(uuid.NewV7())
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixMilli()))
+	copy(u[:6], ms[2:])
+	return prefix + "-" + u.String()
+}
+
+// Valid reports whether id is the given prefix followed by a UUID of any
+// version. IDs made before UUIDv7 was adopted are valid if they have a UUID.
+func Valid(prefix, id string) bool {
+	rest, ok := strings.CutPrefix(id, prefix+"-")
+	if !ok {
+		return false
+	}
+	_, err := uuid.Parse(rest)
+	return err == nil && len(rest) == 36
+}
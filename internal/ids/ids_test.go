@@ -0,0 +1,39 @@
+package ids
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	id := New("group")
+	require.True(t, strings.HasPrefix(id, "group-"))
+	u, err := uuid.Parse(strings.TrimPrefix(id, "group-"))
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), u.Version())
+	assert.True(t, Valid("group", id))
+	assert.NotEqual(t, id, New("group"))
+}
+
+func TestNewAt(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := NewAt("group", at)
+	u, err := uuid.Parse(strings.TrimPrefix(id, "group-"))
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), u.Version())
+	sec, nsec := u.Time().UnixTime()
+	assert.Equal(t, at, time.Unix(sec, nsec).UTC())
+	assert.Less(t, id, New("group"), "IDs sort by when they were made")
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("group", "group-"+uuid.New().String()), "UUIDv4 IDs stay valid")
+	assert.False(t, Valid("group", "group-1700000000000000000"))
+	assert.False(t, Valid("group", "scene-"+uuid.New().String()))
+	assert.False(t, Valid("group", "group-{"+uuid.New().String()+"}"))
+}
@@ -0,0 +1,164 @@
+// Package instance makes sure only one keylightd serves a socket at a time,
+// and lets a new daemon take over from the one that does.
+package instance
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRunning is returned by Acquire when another daemon holds the lock.
+var ErrRunning = errors.New("keylightd is already running")
+
+// errLocked is returned by lockFile when another process holds the lock.
+var errLocked = errors.New("locked by another process")
+
+// DefaultTakeoverTimeout is how long Takeover waits for the running daemon
+// to shut down, which includes turning off or parking lights.
+const DefaultTakeoverTimeout = 20 * time.Second
+
+// RunningError describes the daemon holding the lock. It wraps ErrRunning.
+type RunningError struct {
+	PID    int // 0 if the daemon didn't record it
+	Socket string
+}
+
+func (e *RunningError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("%s on %s", ErrRunning, e.Socket)
+	}
+	return fmt.Sprintf("%s on %s (pid %d)", ErrRunning, e.Socket, e.PID)
+}
+
+func (e *RunningError) Unwrap() error {
+	return ErrRunning
+}
+
+// Lock is held by the daemon serving a socket, in a file next to it
+// recording the daemon's PID.
+type Lock struct {
+	file *os.File
+}
+
+// LockPath returns the lock file for a socket.
+func LockPath(socket string) string {
+	return socket + ".lock"
+}
+
+// Acquire takes the lock for socket without waiting for it. It returns a
+// *RunningError if another daemon holds it.
+func Acquire(socket string) (*Lock, error) {
+	path := LockPath(socket)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec // G301: the socket dir needs to be accessible
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600) //nolint:gosec // G304: path is derived from the configured socket
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if err := lockFile(f); err != nil {
+		pid := readPID(f)
+		_ = f.Close()
+		if errors.Is(err, errLocked) {
+			return nil, &RunningError{PID: pid, Socket: socket}
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if err := f.Truncate(0); err == nil {
+		_, err = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	if err != nil {
+		_ = unlockFile(f)
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release gives the lock up. The file is left in place, as removing it could
+// let two daemons lock different files of the same name.
+func (l *Lock) Release() error {
+	_ = l.file.Truncate(0)
+	if err := unlockFile(l.file); err != nil {
+		_ = l.file.Close()
+		return fmt.Errorf("failed to unlock %s: %w", l.file.Name(), err)
+	}
+	return l.file.Close()
+}
+
+// readPID returns the PID recorded in a lock file, or 0.
+func readPID(f *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := f.ReadAt(buf, 0)
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	return pid
+}
+
+// Takeover asks the daemon running on socket to shut down, and returns the
+// lock once it has. A daemon that doesn't accept the request on its socket
+// is sent SIGTERM instead, if its PID is known. It gives up when ctx ends.
+func Takeover(ctx context.Context, logger *slog.Logger, running *RunningError) (*Lock, error) {
+	if err := requestShutdown(ctx, running.Socket); err != nil {
+		if running.PID == 0 {
+			return nil, fmt.Errorf("failed to ask the running keylightd to shut down: %w", err)
+		}
+		logger.Warn("Running keylightd did not accept a shutdown request, sending it SIGTERM", "pid", running.PID, "error", err)
+		if err := terminate(running.PID); err != nil {
+			return nil, fmt.Errorf("failed to stop keylightd (pid %d): %w", running.PID, err)
+		}
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		lock, err := Acquire(running.Socket)
+		if !errors.Is(err, ErrRunning) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: it did not shut down in time", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// requestShutdown sends the shutdown action to the daemon on socket.
+func requestShutdown(ctx context.Context, socket string) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write([]byte(`{"action":"shutdown"}` + "\n")); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Status != "ok" {
+		return fmt.Errorf("daemon refused: %s", resp.Error)
+	}
+	return nil
+}
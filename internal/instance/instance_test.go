@@ -0,0 +1,84 @@
+package instance
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketPath returns a socket path short enough for the platform's limit.
+func socketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "kl")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "keylightd.sock")
+}
+
+func TestAcquire(t *testing.T) {
+	socket := socketPath(t)
+	lock, err := Acquire(socket)
+	require.NoError(t, err)
+
+	_, err = Acquire(socket)
+	require.ErrorIs(t, err, ErrRunning)
+	var running *RunningError
+	require.ErrorAs(t, err, &running)
+	assert.Equal(t, os.Getpid(), running.PID)
+	assert.Equal(t, socket, running.Socket)
+	assert.Contains(t, err.Error(), socket)
+
+	require.NoError(t, lock.Release())
+	lock, err = Acquire(socket)
+	require.NoError(t, err, "a released lock can be taken again")
+	require.NoError(t, lock.Release())
+}
+
+func TestTakeover(t *testing.T) {
+	socket := socketPath(t)
+	old, err := Acquire(socket)
+	require.NoError(t, err)
+
+	// The running daemon releases its lock once asked to shut down
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer ln.Close()
+	requests := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		requests <- line
+		_, _ = conn.Write([]byte(`{"status":"ok","message":"shutting down"}` + "\n"))
+		time.Sleep(200 * time.Millisecond)
+		_ = old.Release()
+	}()
+
+	_, err = Acquire(socket)
+	var running *RunningError
+	require.ErrorAs(t, err, &running)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lock, err := Takeover(ctx, slog.Default(), running)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"shutdown"}`, <-requests)
+	require.NoError(t, lock.Release())
+}
+
+func TestTakeover_Unreachable(t *testing.T) {
+	socket := socketPath(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := Takeover(ctx, slog.Default(), &RunningError{Socket: socket})
+	assert.Error(t, err, "nothing answers and there's no PID to signal")
+}
@@ -0,0 +1,32 @@
+//go:build !unix
+
+package instance
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile treats f as locked while it records the PID of another process
+// that is still running, where advisory file locks aren't available.
+func lockFile(f *os.File) error {
+	pid := readPID(f)
+	if pid == 0 || pid == os.Getpid() {
+		return nil
+	}
+	if p, err := os.FindProcess(pid); err == nil {
+		_ = p.Release()
+		return errLocked
+	}
+	return nil
+}
+
+// unlockFile is a no-op; Release clears the recorded PID.
+func unlockFile(_ *os.File) error {
+	return nil
+}
+
+// terminate is not supported where SIGTERM isn't.
+func terminate(_ int) error {
+	return errors.New("stopping another process is not supported on this platform")
+}
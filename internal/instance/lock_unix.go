@@ -0,0 +1,30 @@
+//go:build unix
+
+package instance
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on f without waiting for it.
+func lockFile(f *os.File) error {
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return errLocked
+		}
+		return err
+	}
+	return nil
+}
+
+// unlockFile releases the lock taken by lockFile.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+// terminate asks the process to shut down.
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
@@ -0,0 +1,237 @@
+// Package jobs tracks long-running operations, such as applying a scene, that
+// are started by a request and carry on in the background.
+//
+// Starting an operation returns its job straight away. The job can then be
+// followed by ID, cancelled while it runs, and publishes a job.completed event
+// when it finishes. Jobs are kept in memory only; the most recent finished
+// jobs are retained, see config.MaxRetainedJobs.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// Status is where a job has got to.
+type Status string
+
+// Job statuses.
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Job is a long-running operation.
+type Job struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`              // What the job does, e.g. "scene.apply"
+	Subject    string    `json:"subject,omitempty"` // What the job acts upon, e.g. a scene ID
+	Status     Status    `json:"status"`
+	Progress   any       `json:"progress,omitempty"` // Kind-specific progress, set by the job as it runs
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// Done reports whether the job has finished, in whatever way.
+func (j Job) Done() bool {
+	return j.Status != StatusRunning
+}
+
+// Func is the work of a job. It runs until it returns or ctx is cancelled,
+// and reports its progress through t. A job that returns an error has failed,
+// unless it was cancelled.
+type Func func(ctx context.Context, t *Tracker) error
+
+// Tracker lets a running job report its progress.
+type Tracker struct {
+	m *Manager
+	j *job
+}
+
+// SetProgress replaces the job's progress. p must not be modified afterwards;
+// pass a copy.
+func (t *Tracker) SetProgress(p any) {
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+	t.j.Progress = p
+}
+
+// job is a job in progress or finished. Its Job is guarded by Manager.mu.
+type job struct {
+	Job
+	cancel context.CancelFunc
+	done   chan struct{} // closed when the job has finished
+}
+
+// Manager runs jobs and keeps track of them.
+type Manager struct {
+	logger   *slog.Logger
+	eventBus *events.Bus
+	jobs     map[string]*job
+	mu       sync.RWMutex
+}
+
+// NewManager creates a job manager.
+func NewManager(logger *slog.Logger) *Manager {
+	return &Manager{
+		logger: logger,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// SetEventBus sets the event bus for publishing job.completed events.
+func (m *Manager) SetEventBus(bus *events.Bus) {
+	m.eventBus = bus
+}
+
+// Start runs fn in the background as a job of the given kind and returns the
+// job straight away, with the initial progress given. The job keeps ctx's
+// values but carries on after ctx ends; use Cancel to stop it.
+func (m *Manager) Start(ctx context.Context, kind, subject string, progress any, fn Func) *Job {
+	jctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j := &job{
+		Job: Job{
+			ID:        "job-" + uuid.New().String(),
+			Kind:      kind,
+			Subject:   subject,
+			Status:    StatusRunning,
+			Progress:  progress,
+			StartedAt: time.Now().UTC(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.jobs[j.ID] = j
+	snapshot := j.Job
+	m.mu.Unlock()
+
+	m.logger.DebugContext(ctx, "started job", "id", j.ID, "kind", kind, "subject", subject)
+	go m.run(jctx, j, fn)
+	return &snapshot
+}
+
+// run runs a job's work and records how it finished. The job's context is
+// left to lapse rather than cancelled when it finishes, as work it handed on,
+// such as the last transitions of a scene, may still be running on it.
+func (m *Manager) run(ctx context.Context, j *job, fn Func) {
+	err := fn(ctx, &Tracker{m: m, j: j})
+
+	m.mu.Lock()
+	j.FinishedAt = time.Now().UTC()
+	switch {
+	case ctx.Err() != nil:
+		j.Status = StatusCancelled
+	case err != nil:
+		j.Status = StatusFailed
+		j.Error = err.Error()
+	default:
+		j.Status = StatusCompleted
+	}
+	snapshot := j.Job
+	close(j.done)
+	m.pruneLocked()
+	m.mu.Unlock()
+
+	m.logger.DebugContext(ctx, "job finished", "id", j.ID, "kind", j.Kind, "status", snapshot.Status, "error", snapshot.Error)
+	if m.eventBus != nil {
+		m.eventBus.Publish(events.NewEvent(events.JobCompleted, snapshot))
+	}
+}
+
+// pruneLocked forgets the oldest finished jobs beyond config.MaxRetainedJobs.
+// Caller must hold m.mu.
+func (m *Manager) pruneLocked() {
+	var finished []*job
+	for _, j := range m.jobs {
+		if j.Done() {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) <= config.MaxRetainedJobs {
+		return
+	}
+	slices.SortFunc(finished, func(a, b *job) int { return a.FinishedAt.Compare(b.FinishedAt) })
+	for _, j := range finished[:len(finished)-config.MaxRetainedJobs] {
+		delete(m.jobs, j.ID)
+	}
+}
+
+// Get returns a copy of a job by ID.
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, kerrors.NotFoundf("job %s not found", id)
+	}
+	c := j.Job
+	return &c, nil
+}
+
+// GetAll returns copies of all jobs, most recently started first.
+func (m *Manager) GetAll() []*Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		c := j.Job
+		result = append(result, &c)
+	}
+	slices.SortFunc(result, func(a, b *Job) int { return b.StartedAt.Compare(a.StartedAt) })
+	return result
+}
+
+// Cancel asks a running job to stop, without waiting for it to; use Wait for
+// that. It fails with ErrConflict if the job has already finished.
+func (m *Manager) Cancel(id string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return kerrors.NotFoundf("job %s not found", id)
+	}
+	if j.Done() {
+		return kerrors.Conflictf("job %s has already finished", id)
+	}
+	j.cancel()
+	return nil
+}
+
+// Wait blocks until a job has finished and returns it, or returns ctx's
+// error if ctx ends first.
+func (m *Manager) Wait(ctx context.Context, id string) (*Job, error) {
+	m.mu.RLock()
+	j, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, kerrors.NotFoundf("job %s not found", id)
+	}
+
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c := j.Job
+	return &c, nil
+}
@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+func newTestManager(t *testing.T) (*Manager, chan events.Event) {
+	t.Helper()
+	m := NewManager(slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil)))
+	bus := events.NewBus()
+	m.SetEventBus(bus)
+	completed := make(chan events.Event, 10)
+	t.Cleanup(bus.Subscribe(func(e events.Event) {
+		select {
+		case completed <- e:
+		default:
+		}
+	}))
+	return m, completed
+}
+
+func wait(t *testing.T, m *Manager, id string) *Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	job, err := m.Wait(ctx, id)
+	require.NoError(t, err)
+	return job
+}
+
+func TestJobLifecycle(t *testing.T) {
+	m, completed := newTestManager(t)
+
+	release := make(chan struct{})
+	started := m.Start(context.Background(), "test", "subject-1", 0, func(ctx context.Context, tr *Tracker) error {
+		tr.SetProgress(1)
+		<-release
+		tr.SetProgress(2)
+		return nil
+	})
+	assert.Equal(t, StatusRunning, started.Status)
+	assert.Equal(t, "test", started.Kind)
+	assert.Equal(t, "subject-1", started.Subject)
+	assert.Equal(t, 0, started.Progress)
+
+	got, err := m.Get(started.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Done())
+	assert.Len(t, m.GetAll(), 1)
+
+	close(release)
+	job := wait(t, m, started.ID)
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Equal(t, 2, job.Progress)
+	assert.False(t, job.FinishedAt.IsZero())
+
+	e := <-completed
+	assert.Equal(t, events.JobCompleted, e.Type)
+	var data Job
+	require.NoError(t, json.Unmarshal(e.Data, &data))
+	assert.Equal(t, started.ID, data.ID)
+	assert.Equal(t, StatusCompleted, data.Status)
+
+	assert.True(t, kerrors.IsConflict(m.Cancel(started.ID)))
+	_, err = m.Get("job-missing")
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestJobFailed(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	started := m.Start(context.Background(), "test", "", nil, func(context.Context, *Tracker) error {
+		return errors.New("light unreachable")
+	})
+	job := wait(t, m, started.ID)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, "light unreachable", job.Error)
+}
+
+func TestJobCancel(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	// The job outlives the context it was started with.
+	ctx, cancel := context.WithCancel(context.Background())
+	started := m.Start(ctx, "test", "", nil, func(ctx context.Context, _ *Tracker) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	got, err := m.Get(started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, got.Status)
+
+	require.NoError(t, m.Cancel(started.ID))
+	job := wait(t, m, started.ID)
+	assert.Equal(t, StatusCancelled, job.Status)
+	assert.Empty(t, job.Error)
+}
+
+func TestJobsPruned(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	var first string
+	for i := range config.MaxRetainedJobs + 1 {
+		job := m.Start(context.Background(), "test", "", nil, func(context.Context, *Tracker) error { return nil })
+		wait(t, m, job.ID)
+		if i == 0 {
+			first = job.ID
+		}
+	}
+	assert.Len(t, m.GetAll(), config.MaxRetainedJobs)
+	_, err := m.Get(first)
+	assert.True(t, kerrors.IsNotFound(err), "the oldest finished job is forgotten")
+}
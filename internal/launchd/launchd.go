@@ -0,0 +1,130 @@
+// Package launchd installs keylightd as a launchd agent on macOS, so the
+// daemon starts at login and is restarted if it crashes.
+//
+// The agent runs as the logged-in user, keeping its config, state and socket
+// under ~/Library/Application Support/keylightd, and logs to
+// ~/Library/Logs/keylightd.
+package launchd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// Label identifies the agent to launchd.
+const Label = "io.github.jmylchreest.keylightd"
+
+// Agent describes the launchd agent that runs the daemon.
+type Agent struct {
+	Program string   // absolute path of the keylightd binary
+	Args    []string // arguments after the program
+	LogPath string   // file receiving stdout and stderr
+}
+
+// AgentPath returns where the agent's plist is installed for the user with
+// the given home directory.
+func AgentPath(home string) string {
+	return filepath.Join(home, "Library", "LaunchAgents", Label+".plist")
+}
+
+// LogPath returns the daemon's log file for the user with the given home
+// directory.
+func LogPath(home string) string {
+	return filepath.Join(home, "Library", "Logs", "keylightd", "keylightd.log")
+}
+
+// Plist returns the agent's property list. The daemon is started at load and
+// login and restarted after a crash, but not after it exits cleanly, so
+// stopping it with launchctl kill or SIGTERM keeps it stopped.
+func (a Agent) Plist() []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	writeKey(&b, "Label", Label)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{a.Program}, a.Args...) {
+		b.WriteString("\t\t<string>" + escape(arg) + "</string>\n")
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>10</integer>\n")
+	writeKey(&b, "ProcessType", "Background")
+	if a.LogPath != "" {
+		writeKey(&b, "StandardOutPath", a.LogPath)
+		writeKey(&b, "StandardErrorPath", a.LogPath)
+	}
+	b.WriteString("</dict>\n</plist>\n")
+	return b.Bytes()
+}
+
+func writeKey(b *bytes.Buffer, key, value string) {
+	b.WriteString("\t<key>" + key + "</key>\n\t<string>" + escape(value) + "</string>\n")
+}
+
+func escape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// launchctl runs launchctl; replaced in tests.
+var launchctl = func(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s: %w: %s", args[0], err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// domain is the launchd domain of the current user's GUI session.
+func domain() string {
+	return "gui/" + strconv.Itoa(os.Getuid())
+}
+
+// Install writes the agent's plist to path and, with load, (re)loads it so
+// the daemon starts now rather than at the next login. An agent already
+// loaded from path is unloaded first, so installing again applies changes.
+func Install(agent Agent, path string, load bool) error {
+	if agent.LogPath != "" {
+		if err := os.MkdirAll(filepath.Dir(agent.LogPath), 0700); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: LaunchAgents must be readable by launchd
+		return fmt.Errorf("failed to create LaunchAgents directory: %w", err)
+	}
+	if load {
+		// Fails, harmlessly, when it isn't loaded yet
+		_ = launchctl("bootout", domain(), path)
+	}
+	if err := os.WriteFile(path, agent.Plist(), 0644); err != nil { //nolint:gosec // G306: launchd requires a readable plist
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if load {
+		return launchctl("bootstrap", domain(), path)
+	}
+	return nil
+}
+
+// Uninstall unloads the agent, stopping the daemon, and removes its plist.
+func Uninstall(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("launchd agent not installed: %s not found", path)
+	}
+	// Fails, harmlessly, if the user already unloaded it
+	_ = launchctl("bootout", domain(), path)
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package launchd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_Plist(t *testing.T) {
+	agent := Agent{
+		Program: "/Applications/Key & Light/keylightd",
+		Args:    []string{"--config", "/Users/me/<lights>.yaml"},
+		LogPath: "/Users/me/Library/Logs/keylightd/keylightd.log",
+	}
+	plist := string(agent.Plist())
+
+	assert.True(t, strings.HasPrefix(plist, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, plist, "<key>Label</key>\n\t<string>"+Label+"</string>")
+	assert.Contains(t, plist, "<string>/Applications/Key &amp; Light/keylightd</string>\n\t\t<string>--config</string>\n\t\t<string>/Users/me/&lt;lights&gt;.yaml</string>")
+	assert.Contains(t, plist, "<key>SuccessfulExit</key>\n\t\t<false/>")
+	assert.Contains(t, plist, "<key>StandardErrorPath</key>\n\t<string>/Users/me/Library/Logs/keylightd/keylightd.log</string>")
+
+	plist = string(Agent{Program: "/usr/local/bin/keylightd"}.Plist())
+	assert.NotContains(t, plist, "StandardOutPath")
+}
+
+func fakeLaunchctl(t *testing.T) *[][]string {
+	t.Helper()
+	var calls [][]string
+	old := launchctl
+	launchctl = func(args ...string) error {
+		calls = append(calls, args)
+		return nil
+	}
+	t.Cleanup(func() { launchctl = old })
+	return &calls
+}
+
+func TestInstall(t *testing.T) {
+	home := t.TempDir()
+	path := AgentPath(home)
+	agent := Agent{Program: "/usr/local/bin/keylightd", LogPath: LogPath(home)}
+
+	calls := fakeLaunchctl(t)
+	require.NoError(t, Install(agent, path, true))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, agent.Plist(), data)
+	assert.DirExists(t, filepath.Dir(agent.LogPath))
+	require.Len(t, *calls, 2)
+	assert.Equal(t, "bootout", (*calls)[0][0])
+	assert.Equal(t, []string{"bootstrap", domain(), path}, (*calls)[1])
+
+	*calls = nil
+	require.NoError(t, Install(agent, path, false))
+	assert.Empty(t, *calls, "nothing is loaded without load")
+}
+
+func TestUninstall(t *testing.T) {
+	home := t.TempDir()
+	path := AgentPath(home)
+	calls := fakeLaunchctl(t)
+
+	assert.Error(t, Uninstall(path), "not installed")
+
+	require.NoError(t, Install(Agent{Program: "/usr/local/bin/keylightd"}, path, false))
+	require.NoError(t, Uninstall(path))
+	assert.NoFileExists(t, path)
+	assert.Equal(t, [][]string{{"bootout", domain(), path}}, *calls)
+}
@@ -0,0 +1,272 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a log line kept by a Buffer.
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Line    string // the line as written to the log, in its text or JSON format
+}
+
+// Query selects entries from a Buffer.
+type Query struct {
+	MinLevel slog.Level // leave out entries below this level
+	Since    time.Time  // leave out entries logged before this
+	Limit    int        // at most this many, the newest; 0 for no limit
+}
+
+// Match reports whether e is selected by the query's level and time.
+func (q Query) Match(e Entry) bool {
+	return e.Level >= q.MinLevel && !e.Time.Before(q.Since)
+}
+
+// ParseQuery builds a query from its API parameters, any of which may be
+// empty: the lowest level to include, and how far back to go as an RFC 3339
+// time or a duration before now, such as "10m". With no level, entries of
+// every level are included.
+func ParseQuery(level, since string, limit int, now time.Time) (Query, error) {
+	q := Query{MinLevel: slog.LevelDebug, Limit: limit}
+	if limit < 0 {
+		return q, errors.New("limit must not be negative")
+	}
+	if level != "" {
+		if !validLevels[strings.ToLower(level)] {
+			return q, fmt.Errorf("invalid level %q, expected debug, info, warn or error", level)
+		}
+		if strings.EqualFold(level, "warning") {
+			level = "warn"
+		}
+		if err := q.MinLevel.UnmarshalText([]byte(level)); err != nil {
+			return q, fmt.Errorf("invalid level %q, expected debug, info, warn or error", level)
+		}
+	}
+	if since != "" {
+		if t, err := time.Parse(time.RFC3339Nano, since); err == nil {
+			q.Since = t
+		} else if d, err := time.ParseDuration(since); err == nil && d >= 0 {
+			q.Since = now.Add(-d)
+		} else {
+			return q, fmt.Errorf("invalid since %q, expected an RFC 3339 time or a duration such as 10m", since)
+		}
+	}
+	return q, nil
+}
+
+// subscriberBuffer is the number of entries held for a slow follower before
+// further entries are dropped for it.
+const subscriberBuffer = 256
+
+// Buffer keeps the most recent log lines in memory, so they can be read back
+// through the API without access to the daemon's log output. It is an
+// io.Writer to pass to the logger alongside its output; each write is one
+// line in slog's text or JSON format.
+type Buffer struct {
+	mu      sync.Mutex
+	entries []Entry // ring of up to cap(entries) entries
+	next    int     // index the next entry is written to once full
+	subs    map[chan Entry]Query
+}
+
+var (
+	defaultBufferMu sync.RWMutex
+	defaultBuffer   *Buffer
+)
+
+// SetDefaultBuffer sets the buffer the daemon's log is written to, for the
+// APIs that read it back.
+func SetDefaultBuffer(b *Buffer) {
+	defaultBufferMu.Lock()
+	defer defaultBufferMu.Unlock()
+	defaultBuffer = b
+}
+
+// DefaultBuffer returns the buffer set by SetDefaultBuffer, or nil if none
+// has been set.
+func DefaultBuffer() *Buffer {
+	defaultBufferMu.RLock()
+	defer defaultBufferMu.RUnlock()
+	return defaultBuffer
+}
+
+// NewBuffer returns a buffer keeping the last size lines.
+func NewBuffer(size int) *Buffer {
+	return &Buffer{
+		entries: make([]Entry, 0, max(size, 1)),
+		subs:    make(map[chan Entry]Query),
+	}
+}
+
+// Write adds a line to the buffer and passes it to followers. It never
+// fails, so it can't hold up logging.
+func (b *Buffer) Write(p []byte) (int, error) {
+	e := parseLine(p)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) < cap(b.entries) {
+		b.entries = append(b.entries, e)
+	} else {
+		b.entries[b.next] = e
+		b.next = (b.next + 1) % len(b.entries)
+	}
+	for ch, q := range b.subs {
+		if !q.Match(e) {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+			// The follower is too slow; logging about it would only add to the backlog
+		}
+	}
+	return len(p), nil
+}
+
+// Entries returns the entries selected by q, oldest first.
+func (b *Buffer) Entries(q Query) []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.entriesLocked(q)
+}
+
+func (b *Buffer) entriesLocked(q Query) []Entry {
+	var out []Entry
+	for i := range b.entries {
+		e := b.entries[(b.next+i)%len(b.entries)]
+		if q.Match(e) {
+			out = append(out, e)
+		}
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// Follow returns the entries selected by q, as Entries does, and a channel
+// receiving the entries it selects as they are logged, with none missed or
+// repeated in between. Entries are dropped if the channel isn't drained
+// quickly enough. Call cancel to stop following.
+func (b *Buffer) Follow(q Query) (backlog []Entry, entries <-chan Entry, cancel func()) {
+	ch := make(chan Entry, subscriberBuffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	backlog = b.entriesLocked(q)
+	b.subs[ch] = Query{MinLevel: q.MinLevel}
+
+	var once sync.Once
+	return backlog, ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// parseLine reads the time, level and message of a line written by slog's
+// text or JSON handler. Lines that can't be read are kept as info logged
+// now.
+func parseLine(p []byte) Entry {
+	line := bytes.TrimRight(p, "\n")
+	e := Entry{Level: slog.LevelInfo, Line: string(line)}
+
+	var timeStr, level string
+	if len(line) > 0 && line[0] == '{' {
+		var rec struct {
+			Time  string `json:"time"`
+			Level string `json:"level"`
+			Msg   string `json:"msg"`
+		}
+		if json.Unmarshal(line, &rec) == nil {
+			timeStr, level, e.Message = rec.Time, rec.Level, rec.Msg
+		}
+	} else {
+		for key, value := range textPairs(line) {
+			switch key {
+			case slog.TimeKey:
+				timeStr = value
+			case slog.LevelKey:
+				level = value
+			case slog.MessageKey:
+				e.Message = value
+			}
+			if key == slog.MessageKey {
+				// The built-in keys come first, so the rest can be skipped
+				break
+			}
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, timeStr); err == nil {
+		e.Time = t
+	} else {
+		e.Time = time.Now()
+	}
+	if level != "" {
+		_ = e.Level.UnmarshalText([]byte(level))
+	}
+	return e
+}
+
+// textPairs yields the key=value pairs of a line written by slog's text
+// handler, unquoting quoted values.
+func textPairs(line []byte) func(yield func(string, string) bool) {
+	return func(yield func(string, string) bool) {
+		s := string(line)
+		for len(s) > 0 {
+			eq := strings.IndexByte(s, '=')
+			if eq < 0 {
+				return
+			}
+			key := s[:eq]
+			s = s[eq+1:]
+
+			var value string
+			if len(s) > 0 && s[0] == '"' {
+				end := quotedEnd(s)
+				unquoted, err := strconv.Unquote(s[:end])
+				if err != nil {
+					return
+				}
+				value, s = unquoted, s[end:]
+			} else if sp := strings.IndexByte(s, ' '); sp >= 0 {
+				value, s = s[:sp], s[sp:]
+			} else {
+				value, s = s, ""
+			}
+			if !yield(key, value) {
+				return
+			}
+			if len(s) > 0 && s[0] == ' ' {
+				s = s[1:]
+			}
+		}
+	}
+}
+
+// quotedEnd returns the index just after the closing quote of the quoted
+// string s starts with, or len(s) if it isn't closed.
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer_KeepsLastLines(t *testing.T) {
+	b := NewBuffer(3)
+	logger := slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	for _, msg := range []string{"one", "two", "three", "four"} {
+		logger.Info(msg)
+	}
+
+	entries := b.Entries(Query{MinLevel: slog.LevelDebug})
+	require.Len(t, entries, 3)
+	assert.Equal(t, "two", entries[0].Message)
+	assert.Equal(t, "four", entries[2].Message)
+
+	entries = b.Entries(Query{MinLevel: slog.LevelDebug, Limit: 2})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "three", entries[0].Message)
+}
+
+func TestBuffer_ParsesFormats(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		handler func(*Buffer) slog.Handler
+	}{
+		{"text", func(b *Buffer) slog.Handler { return slog.NewTextHandler(b, nil) }},
+		{"json", func(b *Buffer) slog.Handler { return slog.NewJSONHandler(b, nil) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuffer(10)
+			before := time.Now().Add(-time.Second)
+			slog.New(tt.handler(b)).Warn(`light "one" offline`, "level_hint", "level=ERROR")
+
+			entries := b.Entries(Query{})
+			require.Len(t, entries, 1)
+			e := entries[0]
+			assert.Equal(t, slog.LevelWarn, e.Level)
+			assert.Equal(t, `light "one" offline`, e.Message)
+			assert.True(t, e.Time.After(before))
+			assert.NotContains(t, e.Line, "\n")
+			assert.Contains(t, e.Line, "level_hint")
+		})
+	}
+}
+
+func TestBuffer_UnparsedLine(t *testing.T) {
+	b := NewBuffer(10)
+	_, err := b.Write([]byte("not a log line\n"))
+	require.NoError(t, err)
+
+	entries := b.Entries(Query{})
+	require.Len(t, entries, 1)
+	assert.Equal(t, slog.LevelInfo, entries[0].Level)
+	assert.Equal(t, "not a log line", entries[0].Line)
+	assert.WithinDuration(t, time.Now(), entries[0].Time, time.Minute)
+}
+
+func TestBuffer_Query(t *testing.T) {
+	b := NewBuffer(10)
+	write := func(level slog.Level, msg string, at time.Time) {
+		_, _ = fmt.Fprintf(b, "time=%s level=%s msg=%q\n", at.Format(time.RFC3339Nano), level, msg)
+	}
+	now := time.Now()
+	write(slog.LevelDebug, "old debug", now.Add(-time.Hour))
+	write(slog.LevelError, "old error", now.Add(-time.Hour))
+	write(slog.LevelInfo, "new info", now)
+	write(slog.LevelWarn, "new warn", now)
+
+	messages := func(q Query) []string {
+		var msgs []string
+		for _, e := range b.Entries(q) {
+			msgs = append(msgs, e.Message)
+		}
+		return msgs
+	}
+	assert.Equal(t, []string{"old error", "new warn"}, messages(Query{MinLevel: slog.LevelWarn}))
+	assert.Equal(t, []string{"new info", "new warn"}, messages(Query{MinLevel: slog.LevelDebug, Since: now.Add(-time.Minute)}))
+}
+
+func TestBuffer_Follow(t *testing.T) {
+	b := NewBuffer(10)
+	logger := slog.New(slog.NewTextHandler(b, nil))
+	logger.Info("before")
+
+	backlog, entries, cancel := b.Follow(Query{MinLevel: slog.LevelInfo})
+	require.Len(t, backlog, 1)
+	assert.Equal(t, "before", backlog[0].Message)
+
+	logger.Warn("after")
+	select {
+	case e := <-entries:
+		assert.Equal(t, "after", e.Message)
+	case <-time.After(time.Second):
+		t.Fatal("followed entry not received")
+	}
+
+	cancel()
+	cancel()
+	logger.Info("cancelled")
+	assert.Empty(t, entries)
+}
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	q, err := ParseQuery("", "", 0, now)
+	require.NoError(t, err)
+	assert.Equal(t, Query{MinLevel: slog.LevelDebug}, q)
+
+	q, err = ParseQuery("WARNING", "10m", 5, now)
+	require.NoError(t, err)
+	assert.Equal(t, Query{MinLevel: slog.LevelWarn, Since: now.Add(-10 * time.Minute), Limit: 5}, q)
+
+	q, err = ParseQuery("error", "2026-10-16T11:00:00Z", 0, now)
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelError, q.MinLevel)
+	assert.Equal(t, now.Add(-time.Hour), q.Since)
+
+	for _, tt := range []struct {
+		level, since string
+		limit        int
+	}{
+		{level: "loud"},
+		{level: "info+2"},
+		{since: "yesterday"},
+		{since: "-5m"},
+		{limit: -1},
+	} {
+		_, err := ParseQuery(tt.level, tt.since, tt.limit, now)
+		assert.Error(t, err, "%+v", tt)
+	}
+}
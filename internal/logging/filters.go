@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"sync"
+
+	logfilter "github.com/jmylchreest/slog-logfilter"
+)
+
+// filtersMu serialises read-modify-write updates to the global filter list.
+var filtersMu sync.Mutex
+
+// AddFilter validates a single filter and adds it to the active filters. A
+// filter with the same type and pattern as an existing one replaces it in
+// place, so it keeps its precedence. Nothing is changed if validation fails.
+func AddFilter(filter logfilter.LogFilter) []FilterError {
+	if errs := ValidateFilters([]logfilter.LogFilter{filter}); len(errs) > 0 {
+		return errs
+	}
+
+	filtersMu.Lock()
+	defer filtersMu.Unlock()
+	filters := logfilter.GetFilters()
+	for i, f := range filters {
+		if f.Type == filter.Type && f.Pattern == filter.Pattern {
+			filters[i] = filter
+			logfilter.SetFilters(filters)
+			return nil
+		}
+	}
+	logfilter.SetFilters(append(filters, filter))
+	return nil
+}
+
+// RemoveFilter removes the active filter with the given type and pattern.
+// It reports whether a filter was removed.
+func RemoveFilter(filterType, pattern string) bool {
+	filtersMu.Lock()
+	defer filtersMu.Unlock()
+	filters := logfilter.GetFilters()
+	kept := make([]logfilter.LogFilter, 0, len(filters))
+	for _, f := range filters {
+		if f.Type != filterType || f.Pattern != pattern {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) == len(filters) {
+		return false
+	}
+	logfilter.SetFilters(kept)
+	return true
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"io"
+	"testing"
+
+	logfilter "github.com/jmylchreest/slog-logfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRemoveFilter(t *testing.T) {
+	logfilter.New(logfilter.WithOutput(io.Discard))
+	t.Cleanup(func() { logfilter.SetFilters(nil) })
+
+	require.Empty(t, AddFilter(logfilter.LogFilter{Type: "component", Pattern: "http", Level: "debug", Enabled: true}))
+	require.Empty(t, AddFilter(logfilter.LogFilter{Type: "source:file", Pattern: "pkg/*", Level: "warn", Enabled: true}))
+
+	// Same type and pattern replaces the existing filter in place
+	require.Empty(t, AddFilter(logfilter.LogFilter{Type: "component", Pattern: "http", Level: "error", Enabled: true}))
+	filters := logfilter.GetFilters()
+	require.Len(t, filters, 2)
+	assert.Equal(t, "error", filters[0].Level)
+	assert.Equal(t, "source:file", filters[1].Type)
+
+	errs := AddFilter(logfilter.LogFilter{Type: "component", Pattern: "", Level: "loud"})
+	assert.Len(t, errs, 2)
+	assert.Len(t, logfilter.GetFilters(), 2)
+
+	assert.True(t, RemoveFilter("component", "http"))
+	assert.False(t, RemoveFilter("component", "http"))
+	filters = logfilter.GetFilters()
+	require.Len(t, filters, 1)
+	assert.Equal(t, "pkg/*", filters[0].Pattern)
+}
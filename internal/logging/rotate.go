@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an append-only log file that is renamed aside once it
+// reaches a maximum size, keeping a number of older files as path.1 (the
+// newest) to path.N. It is safe for concurrent use; each Write lands in one
+// file, so writing a line at a time never splits a line across files.
+type RotatingFile struct {
+	logger     *slog.Logger
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	failing bool // the last rotation failed, and was logged
+}
+
+// OpenRotatingFile opens path for appending, creating it if needed. The file
+// is rotated once it reaches maxSize bytes, keeping maxBackups rotated files;
+// a maxSize of 0 never rotates. Rotation failures are logged to logger.
+func OpenRotatingFile(logger *slog.Logger, path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{logger: logger, path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) //nolint:gosec // G304: path is from the daemon's config
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat %s: %w", f.path, err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, first rotating the file if p would take it past its
+// maximum size. A write larger than the maximum goes into a file of its own.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate moves the current file aside and starts a new one. If the file
+// can't be moved, writing carries on in it and rotation is tried again on the
+// next write; only the first of a run of failures is logged.
+func (f *RotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err == nil {
+		err = f.moveAside()
+	}
+	if err != nil {
+		if !f.failing {
+			f.logger.Error("Failed to rotate log file, writing on to it", "path", f.path, "error", err)
+		}
+		f.failing = true
+	} else {
+		f.failing = false
+	}
+	return f.open()
+}
+
+// moveAside shifts the backups up by one, dropping the oldest, and moves the
+// current file to path.1, or removes it if no backups are kept.
+func (f *RotatingFile) moveAside() error {
+	if f.maxBackups == 0 {
+		return os.Remove(f.path)
+	}
+	_ = os.Remove(backupPath(f.path, f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(backupPath(f.path, i), backupPath(f.path, i+1))
+	}
+	return os.Rename(f.path, backupPath(f.path, 1))
+}
+
+// Close closes the file. Writes after Close fail.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
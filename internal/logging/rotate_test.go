@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_RotatesAndKeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	f, err := OpenRotatingFile(slog.New(slog.DiscardHandler), path, 10, 2)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "fourth\n", read(path))
+	assert.Equal(t, "third\n", read(path+".1"))
+	assert.Equal(t, "second\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestRotatingFile_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0600))
+
+	f, err := OpenRotatingFile(slog.New(slog.DiscardHandler), path, 0, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old\nnew\n", string(data))
+
+	_, err = f.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestRotatingFile_NoBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	f, err := OpenRotatingFile(slog.New(slog.DiscardHandler), path, 5, 0)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	for _, line := range []string{"one\n", "two\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "two\n", string(data))
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestRotatingFile_KeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	// A directory in the way of the backup stops the file being moved aside
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "blocked"), 0700))
+	var logs bytes.Buffer
+	f, err := OpenRotatingFile(slog.New(slog.NewTextHandler(&logs, nil)), path, 5, 1)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\n", string(data))
+	assert.Equal(t, 1, strings.Count(logs.String(), "Failed to rotate log file"))
+
+	// Rotation resumes once the way is clear
+	require.NoError(t, os.RemoveAll(path+".1"))
+	_, err = f.Write([]byte("four\n"))
+	require.NoError(t, err)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "four\n", string(data))
+	data, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\n", string(data))
+}
@@ -0,0 +1,279 @@
+// Package metrics collects daemon metrics and exposes them in the Prometheus
+// text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// contentType is the Prometheus text exposition format content type.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// latencyBuckets are the upper bounds, in seconds, of the API request latency histogram.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// LightSource provides the lights reported by the per-light gauges.
+type LightSource interface {
+	GetLights() map[string]*keylight.Light
+}
+
+// deviceErrorKey identifies a device error counter.
+type deviceErrorKey struct {
+	light     string
+	operation string
+}
+
+// requestKey identifies an API request latency histogram.
+type requestKey struct {
+	method string
+	route  string
+	status string
+}
+
+// histogram records request latencies over latencyBuckets.
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// Metrics collects daemon metrics. Counters are updated by the light manager
+// and HTTP middleware; light gauges are read from the LightSource at scrape time.
+// It implements keylight.MetricsRecorder.
+type Metrics struct {
+	lights LightSource
+
+	mu                sync.Mutex
+	discoveryAttempts uint64
+	deviceErrors      map[deviceErrorKey]uint64
+	droppedUpdates    map[string]uint64
+	requests          map[requestKey]*histogram
+}
+
+var _ keylight.MetricsRecorder = (*Metrics)(nil)
+
+// New creates a Metrics collector reporting gauges for the given lights.
+func New(lights LightSource) *Metrics {
+	return &Metrics{
+		lights:         lights,
+		deviceErrors:   make(map[deviceErrorKey]uint64),
+		droppedUpdates: make(map[string]uint64),
+		requests:       make(map[requestKey]*histogram),
+	}
+}
+
+// DiscoveryAttempt counts one mDNS discovery attempt.
+func (m *Metrics) DiscoveryAttempt() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.discoveryAttempts++
+}
+
+// DeviceError counts a failed request to a light.
+func (m *Metrics) DeviceError(lightID, operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deviceErrors[deviceErrorKey{light: lightID, operation: operation}]++
+}
+
+// UpdateDropped counts an update to a light that was coalesced into a later one.
+func (m *Metrics) UpdateDropped(lightID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.droppedUpdates[lightID]++
+}
+
+// ObserveRequest records the latency of an API request.
+func (m *Metrics) ObserveRequest(method, route string, status int, d time.Duration) {
+	key := requestKey{method: method, route: route, status: strconv.Itoa(status)}
+	seconds := d.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.requests[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.requests[key] = h
+	}
+	for i, upper := range latencyBuckets {
+		if seconds <= upper {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// statusRecorder wraps http.ResponseWriter to capture the status code.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Hijack supports WebSocket upgrades, which type-assert http.Hijacker directly.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// Middleware returns a Chi middleware that records request latencies. Requests
+// are labelled with the matched route pattern rather than the raw path to keep
+// label cardinality bounded.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+		m.ObserveRequest(r.Method, route, rec.status, time.Since(start))
+	})
+}
+
+// Handler returns an http.Handler serving the metrics in Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_ = m.Write(w)
+	})
+}
+
+// Write writes all metrics to w in Prometheus text format.
+func (m *Metrics) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	m.writeLights(bw)
+	m.writeCounters(bw)
+	return bw.Flush()
+}
+
+func (m *Metrics) writeLights(w io.Writer) {
+	var lights []*keylight.Light
+	if m.lights != nil {
+		for _, l := range m.lights.GetLights() {
+			lights = append(lights, l)
+		}
+	}
+	slices.SortFunc(lights, func(a, b *keylight.Light) int { return strings.Compare(a.ID, b.ID) })
+
+	writeHeader(w, "keylightd_lights", "gauge", "Number of known lights.")
+	fmt.Fprintf(w, "keylightd_lights %d\n", len(lights))
+
+	writeHeader(w, "keylightd_light_on", "gauge", "Whether the light is on (1) or off (0).")
+	for _, l := range lights {
+		fmt.Fprintf(w, "keylightd_light_on%s %d\n", lightLabels(l), boolToInt(l.On))
+	}
+	writeHeader(w, "keylightd_light_brightness_percent", "gauge", "Light brightness in percent.")
+	for _, l := range lights {
+		fmt.Fprintf(w, "keylightd_light_brightness_percent%s %d\n", lightLabels(l), l.Brightness)
+	}
+	writeHeader(w, "keylightd_light_temperature_kelvin", "gauge", "Light colour temperature in Kelvin.")
+	for _, l := range lights {
+		// Lights report mireds; skip those that haven't reported a temperature
+		if l.Temperature == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "keylightd_light_temperature_kelvin%s %d\n", lightLabels(l), keylight.ConvertDeviceToTemperature(l.Temperature))
+	}
+}
+
+func (m *Metrics) writeCounters(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeHeader(w, "keylightd_discovery_attempts_total", "counter", "Number of mDNS discovery attempts.")
+	fmt.Fprintf(w, "keylightd_discovery_attempts_total %d\n", m.discoveryAttempts)
+
+	writeHeader(w, "keylightd_device_errors_total", "counter", "Number of failed requests to lights.")
+	errKeys := make([]deviceErrorKey, 0, len(m.deviceErrors))
+	for k := range m.deviceErrors {
+		errKeys = append(errKeys, k)
+	}
+	slices.SortFunc(errKeys, func(a, b deviceErrorKey) int {
+		if c := strings.Compare(a.light, b.light); c != 0 {
+			return c
+		}
+		return strings.Compare(a.operation, b.operation)
+	})
+	for _, k := range errKeys {
+		fmt.Fprintf(w, "keylightd_device_errors_total{light=%s,operation=%s} %d\n",
+			quote(k.light), quote(k.operation), m.deviceErrors[k])
+	}
+
+	writeHeader(w, "keylightd_light_updates_dropped_total", "counter", "Number of light updates replaced by a later one before being sent.")
+	for _, light := range slices.Sorted(maps.Keys(m.droppedUpdates)) {
+		fmt.Fprintf(w, "keylightd_light_updates_dropped_total{light=%s} %d\n", quote(light), m.droppedUpdates[light])
+	}
+
+	writeHeader(w, "keylightd_http_request_duration_seconds", "histogram", "HTTP API request latencies.")
+	reqKeys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		reqKeys = append(reqKeys, k)
+	}
+	slices.SortFunc(reqKeys, func(a, b requestKey) int {
+		return strings.Compare(a.route+" "+a.method+" "+a.status, b.route+" "+b.method+" "+b.status)
+	})
+	for _, k := range reqKeys {
+		h := m.requests[k]
+		labels := fmt.Sprintf("method=%s,route=%s,status=%s", quote(k.method), quote(k.route), quote(k.status))
+		var cumulative uint64
+		for i, upper := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "keylightd_http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(upper, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "keylightd_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "keylightd_http_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "keylightd_http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+}
+
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func lightLabels(l *keylight.Light) string {
+	return fmt.Sprintf("{id=%s,name=%s}", quote(l.ID), quote(l.Name))
+}
+
+// labelEscaper escapes label values as required by the text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
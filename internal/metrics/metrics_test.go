@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type staticLights map[string]*keylight.Light
+
+func (s staticLights) GetLights() map[string]*keylight.Light { return s }
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	var b strings.Builder
+	require.NoError(t, m.Write(&b))
+	return b.String()
+}
+
+func TestMetrics_LightGauges(t *testing.T) {
+	m := New(staticLights{
+		"b": {ID: "b", Name: `Shelf "strip"`, On: false, Brightness: 10, Temperature: 333}, // mireds, as lights report
+		"a": {ID: "a", Name: "Desk", On: true, Brightness: 80, Temperature: 200},
+		"c": {ID: "c", Name: "Strip", On: true, Brightness: 50},
+	})
+	out := scrape(t, m)
+
+	assert.Contains(t, out, "# TYPE keylightd_lights gauge\nkeylightd_lights 3\n")
+	assert.Contains(t, out, `keylightd_light_on{id="a",name="Desk"} 1`)
+	assert.Contains(t, out, `keylightd_light_on{id="b",name="Shelf \"strip\""} 0`)
+	assert.Contains(t, out, `keylightd_light_brightness_percent{id="a",name="Desk"} 80`)
+	assert.Contains(t, out, `keylightd_light_temperature_kelvin{id="a",name="Desk"} 5000`)
+	assert.Contains(t, out, `keylightd_light_temperature_kelvin{id="b",name="Shelf \"strip\""} 3003`)
+	assert.NotContains(t, out, `keylightd_light_temperature_kelvin{id="c"`)
+	assert.Less(t, strings.Index(out, `keylightd_light_on{id="a"`), strings.Index(out, `keylightd_light_on{id="b"`))
+}
+
+func TestMetrics_Counters(t *testing.T) {
+	m := New(nil)
+	m.DiscoveryAttempt()
+	m.DiscoveryAttempt()
+	m.DeviceError("a", "set_light_state")
+	m.DeviceError("a", "set_light_state")
+	m.DeviceError("b", "get_light_state")
+	m.UpdateDropped("a")
+	m.UpdateDropped("a")
+	out := scrape(t, m)
+
+	assert.Contains(t, out, "keylightd_discovery_attempts_total 2\n")
+	assert.Contains(t, out, `keylightd_device_errors_total{light="a",operation="set_light_state"} 2`)
+	assert.Contains(t, out, `keylightd_device_errors_total{light="b",operation="get_light_state"} 1`)
+	assert.Contains(t, out, `keylightd_light_updates_dropped_total{light="a"} 2`)
+}
+
+func TestMetrics_RequestHistogram(t *testing.T) {
+	m := New(nil)
+	m.ObserveRequest("GET", "/api/v1/lights", 200, 20*time.Millisecond)
+	m.ObserveRequest("GET", "/api/v1/lights", 200, 2*time.Second)
+	out := scrape(t, m)
+
+	labels := `method="GET",route="/api/v1/lights",status="200"`
+	assert.Contains(t, out, "keylightd_http_request_duration_seconds_bucket{"+labels+`,le="0.01"} 0`)
+	assert.Contains(t, out, "keylightd_http_request_duration_seconds_bucket{"+labels+`,le="0.025"} 1`)
+	assert.Contains(t, out, "keylightd_http_request_duration_seconds_bucket{"+labels+`,le="2.5"} 2`)
+	assert.Contains(t, out, "keylightd_http_request_duration_seconds_bucket{"+labels+`,le="+Inf"} 2`)
+	assert.Contains(t, out, "keylightd_http_request_duration_seconds_count{"+labels+"} 2")
+}
+
+func TestMetrics_MiddlewareUsesRoutePattern(t *testing.T) {
+	m := New(nil)
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/api/v1/lights/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	r.Get("/metrics", m.Handler().ServeHTTP)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/lights/abc", nil))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `keylightd_http_request_duration_seconds_count{method="GET",route="/api/v1/lights/{id}",status="404"} 1`)
+	assert.NotContains(t, rec.Body.String(), "/api/v1/lights/abc")
+}
@@ -0,0 +1,297 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+const (
+	// keepAlive is the MQTT keepalive interval.
+	keepAlive = 30 * time.Second
+	// dialTimeout bounds connecting to the broker.
+	dialTimeout = 10 * time.Second
+	// minReconnectDelay and maxReconnectDelay bound the backoff between connection attempts.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+	// homeAssistantStatus is published by Home Assistant when it starts, so
+	// discovery configs are republished in case they were lost.
+	homeAssistantStatus = "homeassistant/status"
+)
+
+// Bridge publishes lights as Home Assistant MQTT light entities and applies
+// commands received from Home Assistant.
+//
+// Topics, relative to the topic prefix:
+//
+//	<prefix>/status             "online" or "offline" (retained, last will)
+//	<prefix>/<object id>/state  JSON light state (retained)
+//	<prefix>/<object id>/set    JSON commands from Home Assistant
+//
+// The discovery config for each light is published, retained, to
+// <discovery prefix>/light/<object id>/config.
+type Bridge struct {
+	logger *slog.Logger
+	cfg    config.MQTTConfig
+	lights keylight.LightManager
+	bus    *events.Bus
+
+	// objectIDs maps topic-safe object IDs to light IDs. It is only used by
+	// the goroutine running Run.
+	objectIDs map[string]string
+}
+
+// NewBridge creates a Bridge. Empty topic prefixes and client ID are given defaults.
+func NewBridge(logger *slog.Logger, cfg config.MQTTConfig, lights keylight.LightManager, bus *events.Bus) *Bridge {
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = config.DefaultMQTTTopicPrefix
+	}
+	if cfg.DiscoveryPrefix == "" {
+		cfg.DiscoveryPrefix = config.DefaultMQTTDiscoveryPrefix
+	}
+	if cfg.ClientID == "" {
+		hostname, _ := os.Hostname()
+		cfg.ClientID = "keylightd-" + hostname
+	}
+	return &Bridge{
+		logger:    logger,
+		cfg:       cfg,
+		lights:    lights,
+		bus:       bus,
+		objectIDs: make(map[string]string),
+	}
+}
+
+// Run keeps the bridge connected to the broker, reconnecting with backoff,
+// until ctx is cancelled.
+func (b *Bridge) Run(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		connected, err := b.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = minReconnectDelay
+		}
+		b.logger.Warn("MQTT bridge disconnected", "broker", b.cfg.Broker, "error", err, "retry_in", delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// session connects to the broker and serves it until the connection ends or
+// ctx is cancelled. It reports whether the connection was established.
+func (b *Bridge) session(ctx context.Context) (bool, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	client, err := Dial(dialCtx, Options{
+		Broker:    b.cfg.Broker,
+		ClientID:  b.cfg.ClientID,
+		Username:  b.cfg.Username,
+		Password:  b.cfg.Password,
+		KeepAlive: keepAlive,
+		Will:      &Message{Topic: b.statusTopic(), Payload: []byte("offline"), Retain: true},
+	})
+	if err != nil {
+		return false, err
+	}
+	b.logger.Info("Connected to MQTT broker", "broker", b.cfg.Broker)
+
+	// Buffer events so the bus is never blocked by a slow broker.
+	evCh := make(chan events.Event, 64)
+	unsub := b.bus.Subscribe(func(e events.Event) {
+		select {
+		case evCh <- e:
+		default:
+			b.logger.Warn("MQTT bridge dropped event, buffer full", "type", e.Type)
+		}
+	})
+	defer unsub()
+
+	if err := client.Subscribe(b.cfg.TopicPrefix+"/+/set", homeAssistantStatus); err != nil {
+		_ = client.Close()
+		return true, err
+	}
+	if err := b.publishAll(client); err != nil {
+		_ = client.Close()
+		return true, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = client.Publish(Message{Topic: b.statusTopic(), Payload: []byte("offline"), Retain: true})
+			_ = client.Close()
+			return true, ctx.Err()
+		case <-client.Done():
+			return true, client.Err()
+		case e := <-evCh:
+			if err := b.handleEvent(client, e); err != nil {
+				_ = client.Close()
+				return true, err
+			}
+		case msg, ok := <-client.Messages():
+			if !ok {
+				return true, client.Err()
+			}
+			if err := b.handleMessage(ctx, client, msg); err != nil {
+				_ = client.Close()
+				return true, err
+			}
+		}
+	}
+}
+
+// publishAll announces the bridge as online and publishes the discovery config
+// and state of every known light.
+func (b *Bridge) publishAll(client *Client) error {
+	if err := client.Publish(Message{Topic: b.statusTopic(), Payload: []byte("online"), Retain: true}); err != nil {
+		return err
+	}
+	for _, light := range b.lights.GetLights() {
+		if err := b.publishLight(client, light); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishLight publishes a light's discovery config and current state.
+func (b *Bridge) publishLight(client *Client, light *keylight.Light) error {
+	objectID := ObjectID(light.ID)
+	b.objectIDs[objectID] = light.ID
+
+	cfg, err := json.Marshal(b.discoveryConfig(light))
+	if err != nil {
+		return fmt.Errorf("failed to encode discovery config for light %s: %w", light.ID, err)
+	}
+	if err := client.Publish(Message{Topic: b.discoveryTopic(objectID), Payload: cfg, Retain: true}); err != nil {
+		return err
+	}
+	return b.publishState(client, light)
+}
+
+// publishState publishes a light's state.
+func (b *Bridge) publishState(client *Client, light *keylight.Light) error {
+	payload, err := json.Marshal(newLightState(light))
+	if err != nil {
+		return fmt.Errorf("failed to encode state for light %s: %w", light.ID, err)
+	}
+	return client.Publish(Message{Topic: b.lightTopic(ObjectID(light.ID), "state"), Payload: payload, Retain: true})
+}
+
+// handleEvent mirrors a light event to the broker.
+func (b *Bridge) handleEvent(client *Client, e events.Event) error {
+	switch e.Type {
+	case events.LightDiscovered, events.LightStateChanged, events.LightRemoved:
+	default:
+		return nil
+	}
+	var light keylight.Light
+	if err := json.Unmarshal(e.Data, &light); err != nil || light.ID == "" {
+		b.logger.Debug("Ignoring light event without a light", "type", e.Type)
+		return nil
+	}
+
+	switch e.Type {
+	case events.LightDiscovered:
+		// Lights are rediscovered on every discovery pass; only new ones need a config.
+		if _, known := b.objectIDs[ObjectID(light.ID)]; known {
+			return b.publishState(client, &light)
+		}
+		return b.publishLight(client, &light)
+	case events.LightStateChanged:
+		return b.publishState(client, &light)
+	default: // events.LightRemoved
+		objectID := ObjectID(light.ID)
+		delete(b.objectIDs, objectID)
+		// An empty retained config removes the entity from Home Assistant.
+		return client.Publish(Message{Topic: b.discoveryTopic(objectID), Retain: true})
+	}
+}
+
+// handleMessage handles a message on a subscribed topic.
+func (b *Bridge) handleMessage(ctx context.Context, client *Client, msg Message) error {
+	if msg.Topic == homeAssistantStatus {
+		if string(msg.Payload) == "online" {
+			b.logger.Debug("Home Assistant came online, republishing discovery configs")
+			return b.publishAll(client)
+		}
+		return nil
+	}
+
+	objectID, ok := strings.CutPrefix(msg.Topic, b.cfg.TopicPrefix+"/")
+	if !ok {
+		return nil
+	}
+	objectID, ok = strings.CutSuffix(objectID, "/set")
+	if !ok {
+		return nil
+	}
+	id, ok := b.objectIDs[objectID]
+	if !ok {
+		b.logger.Warn("MQTT command for unknown light", "topic", msg.Topic)
+		return nil
+	}
+
+	var cmd lightCommand
+	if err := json.Unmarshal(msg.Payload, &cmd); err != nil {
+		b.logger.Warn("Invalid MQTT light command", "topic", msg.Topic, "error", err)
+		return nil
+	}
+	change, duration := cmd.stateChange()
+	if change.IsEmpty() {
+		return nil
+	}
+	if err := b.lights.Transition(ctx, id, change, duration); err != nil {
+		b.logger.Warn("Failed to apply MQTT light command", "light", id, "error", err)
+		// Republish the actual state so Home Assistant does not show the requested one.
+		if light, ok := b.lights.GetLights()[id]; ok {
+			return b.publishState(client, light)
+		}
+	}
+	return nil
+}
+
+// statusTopic returns the bridge availability topic.
+func (b *Bridge) statusTopic() string {
+	return b.cfg.TopicPrefix + "/status"
+}
+
+// lightTopic returns a per-light topic.
+func (b *Bridge) lightTopic(objectID, suffix string) string {
+	return b.cfg.TopicPrefix + "/" + objectID + "/" + suffix
+}
+
+// discoveryTopic returns the Home Assistant discovery config topic for a light.
+func (b *Bridge) discoveryTopic(objectID string) string {
+	return b.cfg.DiscoveryPrefix + "/light/" + objectID + "/config"
+}
+
+// ObjectID converts a light ID to a string usable as an MQTT topic level and
+// Home Assistant object ID. Characters other than ASCII letters, digits,
+// hyphens and underscores are replaced with underscores.
+func ObjectID(lightID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, lightID)
+}
@@ -0,0 +1,170 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// mockLights implements the parts of keylight.LightManager used by the bridge.
+type mockLights struct {
+	keylight.LightManager
+
+	mu          sync.Mutex
+	lights      map[string]*keylight.Light
+	transitions chan transitionCall
+}
+
+type transitionCall struct {
+	id       string
+	change   keylight.StateChange
+	duration time.Duration
+}
+
+func (m *mockLights) GetLights() map[string]*keylight.Light {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lights
+}
+
+func (m *mockLights) Transition(_ context.Context, id string, change keylight.StateChange, duration time.Duration) error {
+	m.transitions <- transitionCall{id: id, change: change, duration: duration}
+	return nil
+}
+
+func TestObjectID(t *testing.T) {
+	assert.Equal(t, "Elgato_Key_Light_ABC1__elg__tcp_local_", ObjectID("Elgato Key Light ABC1._elg._tcp.local."))
+	assert.Equal(t, "desk-light_2", ObjectID("desk-light_2"))
+	assert.Equal(t, "a_b_c", ObjectID("a/b+c"))
+}
+
+func TestLightCommand_StateChange(t *testing.T) {
+	var cmd lightCommand
+	require.NoError(t, json.Unmarshal([]byte(`{"state":"ON","brightness":1,"color_temp":200,"transition":2.5}`), &cmd))
+
+	change, duration := cmd.stateChange()
+	require.NotNil(t, change.On)
+	assert.True(t, *change.On)
+	require.NotNil(t, change.Brightness)
+	assert.Equal(t, config.MinBrightness, *change.Brightness)
+	require.NotNil(t, change.Temperature)
+	assert.Equal(t, 5000, *change.Temperature)
+	assert.Equal(t, 2500*time.Millisecond, duration)
+
+	change, duration = lightCommand{State: "OFF"}.stateChange()
+	require.NotNil(t, change.On)
+	assert.False(t, *change.On)
+	assert.Nil(t, change.Brightness)
+	assert.Zero(t, duration)
+}
+
+func TestBridge_PublishesAndHandlesCommands(t *testing.T) {
+	broker := newFakeBroker(t)
+	lights := &mockLights{
+		lights: map[string]*keylight.Light{
+			"Desk Light": {ID: "Desk Light", Name: "Desk", On: true, Brightness: 40, Temperature: 200, ProductName: "Elgato Key Light"},
+		},
+		transitions: make(chan transitionCall, 1),
+	}
+	bus := events.NewBus()
+	bridge := NewBridge(slog.New(slog.DiscardHandler), config.MQTTConfig{Broker: broker.addr()}, lights, bus)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		bridge.Run(ctx)
+		close(done)
+	}()
+
+	assert.Equal(t, "online", string(broker.nextPublished("keylightd/status").Payload))
+
+	var discovery map[string]any
+	msg := broker.nextPublished("homeassistant/light/Desk_Light/config")
+	assert.True(t, msg.Retain)
+	require.NoError(t, json.Unmarshal(msg.Payload, &discovery))
+	assert.Equal(t, "keylightd_Desk_Light", discovery["unique_id"])
+	assert.Equal(t, "keylightd/Desk_Light/set", discovery["command_topic"])
+	assert.Equal(t, "Desk", discovery["device"].(map[string]any)["name"])
+
+	msg = broker.nextPublished("keylightd/Desk_Light/state")
+	assert.JSONEq(t, `{"state":"ON","brightness":40,"color_mode":"color_temp","color_temp":200}`, string(msg.Payload))
+
+	assert.Equal(t, []string{"keylightd/+/set", homeAssistantStatus}, <-broker.subscribed)
+
+	// Commands are applied through the light manager
+	broker.send(<-broker.conns, "keylightd/Desk_Light/set", []byte(`{"state":"OFF"}`))
+	select {
+	case call := <-lights.transitions:
+		assert.Equal(t, "Desk Light", call.id)
+		require.NotNil(t, call.change.On)
+		assert.False(t, *call.change.On)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for command")
+	}
+
+	// State changes are mirrored to the state topic
+	bus.Publish(events.NewEvent(events.LightStateChanged, &keylight.Light{ID: "Desk Light", Brightness: 40, Temperature: 200}))
+	msg = broker.nextPublished("keylightd/Desk_Light/state")
+	assert.JSONEq(t, `{"state":"OFF","brightness":40,"color_mode":"color_temp","color_temp":200}`, string(msg.Payload))
+
+	// Removed lights have their discovery config cleared
+	bus.Publish(events.NewEvent(events.LightRemoved, &keylight.Light{ID: "Desk Light"}))
+	msg = broker.nextPublished("homeassistant/light/Desk_Light/config")
+	assert.Empty(t, msg.Payload)
+	assert.True(t, msg.Retain)
+
+	cancel()
+	assert.Equal(t, "offline", string(broker.nextPublished("keylightd/status").Payload))
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("bridge did not stop")
+	}
+}
+
+func TestBridge_RediscoveredLightKeepsConfig(t *testing.T) {
+	broker := newFakeBroker(t)
+	lights := &mockLights{
+		lights: map[string]*keylight.Light{
+			"Desk Light": {ID: "Desk Light", Name: "Desk", On: true, Brightness: 40, Temperature: 200},
+		},
+	}
+	bus := events.NewBus()
+	bridge := NewBridge(slog.New(slog.DiscardHandler), config.MQTTConfig{Broker: broker.addr()}, lights, bus)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go bridge.Run(ctx)
+
+	broker.nextPublished("homeassistant/light/Desk_Light/config")
+	broker.nextPublished("keylightd/Desk_Light/state")
+	<-broker.subscribed
+
+	// Every discovery pass finds the known light again; only its state is
+	// republished, while a new light gets a discovery config
+	bus.Publish(events.NewEvent(events.LightDiscovered, &keylight.Light{ID: "Desk Light", Brightness: 60, Temperature: 200}))
+	bus.Publish(events.NewEvent(events.LightDiscovered, &keylight.Light{ID: "Shelf", Brightness: 10, Temperature: 300}))
+
+	var topics []string
+	timeout := time.After(2 * time.Second)
+	for !slices.Contains(topics, "homeassistant/light/Shelf/config") {
+		select {
+		case msg := <-broker.published:
+			topics = append(topics, msg.Topic)
+		case <-timeout:
+			t.Fatalf("timed out waiting for the new light's config, got %v", topics)
+		}
+	}
+	assert.Equal(t, []string{"keylightd/Desk_Light/state", "homeassistant/light/Shelf/config"}, topics)
+}
@@ -0,0 +1,421 @@
+// Package mqtt bridges keylightd to an MQTT broker so Home Assistant can
+// discover and control lights through its MQTT integration.
+//
+// The client implements the small subset of MQTT 3.1.1 the bridge needs:
+// QoS 0 publish and subscribe, retained messages, a last will and keepalive.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types, already shifted into the fixed header's high nibble.
+const (
+	packetConnect     byte = 0x10
+	packetConnack     byte = 0x20
+	packetPublish     byte = 0x30
+	packetPuback      byte = 0x40
+	packetSubscribe   byte = 0x82 // includes the reserved flag bits required by the spec
+	packetSuback      byte = 0x90
+	packetPingreq     byte = 0xC0
+	packetPingresp    byte = 0xD0
+	packetDisconnect  byte = 0xE0
+	maxRemainingBytes      = 4 // length of the longest remaining length encoding
+)
+
+// connackReasons describes the CONNACK return codes that refuse a connection.
+var connackReasons = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Message is an MQTT application message.
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Options configures a client connection.
+type Options struct {
+	// Broker is the broker address: tcp://host:port, mqtt://host:port,
+	// ssl://host:port, mqtts://host:port, or a bare host:port.
+	Broker    string
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	// Will is published by the broker if the connection is lost without a disconnect.
+	Will *Message
+}
+
+// Client is a connection to an MQTT broker.
+type Client struct {
+	conn      net.Conn
+	keepAlive time.Duration
+	messages  chan Message
+
+	writeMu  sync.Mutex
+	packetID uint16
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// Dial connects to the broker and completes the MQTT handshake. Incoming
+// messages for subscribed topics are delivered on Messages until the
+// connection ends.
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	network, addr, useTLS, err := parseBroker(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		conn, err = dialer.DialContext(ctx, network, addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker %s: %w", opts.Broker, err)
+	}
+
+	c := &Client{
+		conn:      conn,
+		keepAlive: opts.KeepAlive,
+		messages:  make(chan Message, 64),
+		done:      make(chan struct{}),
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	r := bufio.NewReader(conn)
+	if err := c.write(packetConnect, encodeConnect(opts)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	header, body, err := readPacket(r)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if header&0xF0 != packetConnack || len(body) != 2 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unexpected packet 0x%02x waiting for CONNACK", header)
+	}
+	if code := body[1]; code != 0 {
+		_ = conn.Close()
+		reason, ok := connackReasons[code]
+		if !ok {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+		return nil, fmt.Errorf("MQTT broker refused connection: %s", reason)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	go c.readLoop(r)
+	if c.keepAlive > 0 {
+		go c.pingLoop()
+	}
+	return c, nil
+}
+
+// parseBroker splits a broker address into a dial network and address, and
+// reports whether TLS should be used.
+func parseBroker(broker string) (network, addr string, useTLS bool, err error) {
+	if broker == "" {
+		return "", "", false, errors.New("MQTT broker address is empty")
+	}
+	u, err := url.Parse(broker)
+	if err != nil || u.Host == "" {
+		// Not a URL; treat it as host:port
+		u = &url.URL{Scheme: "tcp", Host: broker}
+	}
+	defaultPort := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS = true
+		defaultPort = "8883"
+	default:
+		return "", "", false, fmt.Errorf("unsupported MQTT broker scheme %q", u.Scheme)
+	}
+	addr = u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+	return "tcp", addr, useTLS, nil
+}
+
+// Messages returns the channel on which messages for subscribed topics are delivered.
+// It is closed when the connection ends.
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+// Done returns a channel that is closed when the connection ends.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that ended the connection, once Done is closed.
+func (c *Client) Err() error {
+	<-c.done
+	return c.err
+}
+
+// Publish sends a QoS 0 message.
+func (c *Client) Publish(msg Message) error {
+	flags := packetPublish
+	if msg.Retain {
+		flags |= 0x01
+	}
+	body := appendString(nil, msg.Topic)
+	body = append(body, msg.Payload...)
+	return c.write(flags, body)
+}
+
+// Subscribe requests QoS 0 delivery of messages matching the topic filters.
+// A rejected subscription is reported by ending the connection.
+func (c *Client) Subscribe(filters ...string) error {
+	c.writeMu.Lock()
+	c.packetID++
+	if c.packetID == 0 {
+		c.packetID = 1
+	}
+	body := binary.BigEndian.AppendUint16(nil, c.packetID)
+	c.writeMu.Unlock()
+
+	for _, f := range filters {
+		body = appendString(body, f)
+		body = append(body, 0) // requested QoS
+	}
+	return c.write(packetSubscribe, body)
+}
+
+// Close sends DISCONNECT and closes the connection. The will message is not published.
+func (c *Client) Close() error {
+	err := c.write(packetDisconnect, nil)
+	c.shutdown(nil)
+	return err
+}
+
+// shutdown closes the connection once, recording why it ended.
+func (c *Client) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		_ = c.conn.Close()
+		close(c.done)
+	})
+}
+
+// readLoop dispatches incoming packets until the connection fails.
+func (c *Client) readLoop(r *bufio.Reader) {
+	defer close(c.messages)
+	for {
+		if c.keepAlive > 0 {
+			// The broker answers our pings, so silence for two intervals means the connection is dead.
+			_ = c.conn.SetReadDeadline(time.Now().Add(2 * c.keepAlive))
+		}
+		header, body, err := readPacket(r)
+		if err != nil {
+			select {
+			case <-c.done:
+				// Closed locally
+			default:
+				c.shutdown(fmt.Errorf("MQTT connection lost: %w", err))
+			}
+			return
+		}
+
+		switch header & 0xF0 {
+		case packetPublish:
+			msg, packetID, err := decodePublish(header, body)
+			if err != nil {
+				c.shutdown(err)
+				return
+			}
+			if header&0x06 == 0x02 { // QoS 1 must be acknowledged
+				_ = c.write(packetPuback, binary.BigEndian.AppendUint16(nil, packetID))
+			}
+			select {
+			case c.messages <- msg:
+			case <-c.done:
+				return
+			}
+		case packetSuback:
+			for _, code := range body[min(2, len(body)):] {
+				if code == 0x80 {
+					c.shutdown(errors.New("MQTT broker rejected subscription"))
+					return
+				}
+			}
+		case packetPingresp, packetPuback:
+			// Nothing to do
+		default:
+			c.shutdown(fmt.Errorf("unexpected MQTT packet 0x%02x", header))
+			return
+		}
+	}
+}
+
+// pingLoop sends PINGREQ every keepalive interval.
+func (c *Client) pingLoop() {
+	ticker := time.NewTicker(c.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(packetPingreq, nil); err != nil {
+				c.shutdown(err)
+				return
+			}
+		}
+	}
+}
+
+// write sends a single packet with the given fixed header byte.
+func (c *Client) write(header byte, body []byte) error {
+	packet := make([]byte, 0, 1+maxRemainingBytes+len(body))
+	packet = append(packet, header)
+	packet = appendRemainingLength(packet, len(body))
+	packet = append(packet, body...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.conn.Write(packet); err != nil {
+		return fmt.Errorf("failed to write MQTT packet: %w", err)
+	}
+	return nil
+}
+
+// encodeConnect builds the variable header and payload of a CONNECT packet.
+func encodeConnect(opts Options) []byte {
+	flags := byte(0x02) // clean session
+	if opts.Will != nil {
+		flags |= 0x04
+		if opts.Will.Retain {
+			flags |= 0x20
+		}
+	}
+	if opts.Username != "" {
+		flags |= 0x80
+		if opts.Password != "" {
+			flags |= 0x40
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 is MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(min(opts.KeepAlive/time.Second, 0xFFFF)))
+	body = appendString(body, opts.ClientID)
+	if opts.Will != nil {
+		body = appendString(body, opts.Will.Topic)
+		body = appendString(body, string(opts.Will.Payload))
+	}
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+		if opts.Password != "" {
+			body = appendString(body, opts.Password)
+		}
+	}
+	return body
+}
+
+// decodePublish parses a PUBLISH packet, returning the packet ID for QoS 1 and 2.
+func decodePublish(header byte, body []byte) (Message, uint16, error) {
+	topic, rest, err := readString(body)
+	if err != nil {
+		return Message{}, 0, fmt.Errorf("malformed PUBLISH packet: %w", err)
+	}
+	var packetID uint16
+	if header&0x06 != 0 {
+		if len(rest) < 2 {
+			return Message{}, 0, errors.New("malformed PUBLISH packet: missing packet identifier")
+		}
+		packetID = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	return Message{Topic: topic, Payload: rest, Retain: header&0x01 != 0}, packetID, nil
+}
+
+// readPacket reads one packet, returning its fixed header byte and remaining bytes.
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == maxRemainingBytes {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// appendRemainingLength appends the variable length encoding of n.
+func appendRemainingLength(b []byte, n int) []byte {
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+// appendString appends a length-prefixed UTF-8 string.
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s))) //nolint:gosec // G115: topics and credentials are far shorter than 64KiB
+	return append(b, s...)
+}
+
+// readString reads a length-prefixed UTF-8 string, returning the remaining bytes.
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("string length missing")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("string truncated")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
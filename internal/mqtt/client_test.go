@@ -0,0 +1,237 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker accepts MQTT connections, records published messages and
+// subscriptions, and can send messages to the connected client.
+type fakeBroker struct {
+	t          *testing.T
+	ln         net.Listener
+	connack    byte
+	connects   chan []byte
+	published  chan Message
+	subscribed chan []string
+	conns      chan net.Conn
+
+	mu   sync.Mutex
+	open []net.Conn
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &fakeBroker{
+		t:          t,
+		ln:         ln,
+		connects:   make(chan []byte, 4),
+		published:  make(chan Message, 256),
+		subscribed: make(chan []string, 4),
+		conns:      make(chan net.Conn, 4),
+	}
+	t.Cleanup(func() {
+		_ = ln.Close()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for _, conn := range b.open {
+			_ = conn.Close()
+		}
+	})
+	go b.accept()
+	return b
+}
+
+func (b *fakeBroker) addr() string {
+	return "tcp://" + b.ln.Addr().String()
+}
+
+func (b *fakeBroker) accept() {
+	for {
+		conn, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.open = append(b.open, conn)
+		b.mu.Unlock()
+		go b.serve(conn)
+	}
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header & 0xF0 {
+		case packetConnect:
+			b.connects <- body
+			_, _ = conn.Write([]byte{packetConnack, 2, 0, b.connack})
+			b.conns <- conn
+		case packetPublish:
+			msg, _, err := decodePublish(header, body)
+			if err == nil {
+				b.published <- msg
+			}
+		case packetSubscribe & 0xF0:
+			var filters []string
+			rest := body[2:]
+			for len(rest) > 0 {
+				var f string
+				f, rest, _ = readString(rest)
+				rest = rest[1:] // requested QoS
+				filters = append(filters, f)
+			}
+			b.subscribed <- filters
+			suback := append([]byte{packetSuback, byte(2 + len(filters))}, body[:2]...)
+			_, _ = conn.Write(append(suback, make([]byte, len(filters))...))
+		case packetPingreq:
+			_, _ = conn.Write([]byte{packetPingresp, 0})
+		case packetDisconnect:
+			_ = conn.Close()
+			return
+		}
+	}
+}
+
+// send delivers a QoS 0 message to the client on conn.
+func (b *fakeBroker) send(conn net.Conn, topic string, payload []byte) {
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	packet := appendRemainingLength([]byte{packetPublish}, len(body))
+	_, err := conn.Write(append(packet, body...))
+	require.NoError(b.t, err)
+}
+
+// nextPublished waits for a published message on topic, skipping others.
+func (b *fakeBroker) nextPublished(topic string) Message {
+	b.t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg := <-b.published:
+			if msg.Topic == topic {
+				return msg
+			}
+		case <-timeout:
+			b.t.Fatalf("timed out waiting for a message on %s", topic)
+		}
+	}
+}
+
+func TestClient_ConnectPublishSubscribe(t *testing.T) {
+	broker := newFakeBroker(t)
+
+	client, err := Dial(t.Context(), Options{
+		Broker:    broker.addr(),
+		ClientID:  "test-client",
+		Username:  "user",
+		Password:  "secret",
+		KeepAlive: time.Minute,
+		Will:      &Message{Topic: "test/status", Payload: []byte("offline"), Retain: true},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	connect := <-broker.connects
+	protocol, rest, err := readString(connect)
+	require.NoError(t, err)
+	assert.Equal(t, "MQTT", protocol)
+	assert.Equal(t, byte(4), rest[0])
+	assert.Equal(t, byte(0x80|0x40|0x20|0x04|0x02), rest[1])
+	assert.Equal(t, uint16(60), binary.BigEndian.Uint16(rest[2:]))
+	clientID, _, _ := readString(rest[4:])
+	assert.Equal(t, "test-client", clientID)
+
+	require.NoError(t, client.Publish(Message{Topic: "test/state", Payload: []byte("hello"), Retain: true}))
+	msg := broker.nextPublished("test/state")
+	assert.Equal(t, "hello", string(msg.Payload))
+	assert.True(t, msg.Retain)
+
+	require.NoError(t, client.Subscribe("test/+/set", "other"))
+	assert.Equal(t, []string{"test/+/set", "other"}, <-broker.subscribed)
+
+	broker.send(<-broker.conns, "test/light/set", []byte(`{"state":"ON"}`))
+	select {
+	case msg := <-client.Messages():
+		assert.Equal(t, "test/light/set", msg.Topic)
+		assert.JSONEq(t, `{"state":"ON"}`, string(msg.Payload))
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	require.NoError(t, client.Close())
+	<-client.Done()
+	assert.NoError(t, client.Err())
+}
+
+func TestClient_ConnectRefused(t *testing.T) {
+	broker := newFakeBroker(t)
+	broker.connack = 5
+
+	_, err := Dial(t.Context(), Options{Broker: broker.addr(), ClientID: "test-client"})
+	assert.ErrorContains(t, err, "not authorized")
+}
+
+func TestParseBroker(t *testing.T) {
+	tests := []struct {
+		broker  string
+		addr    string
+		useTLS  bool
+		wantErr bool
+	}{
+		{"tcp://broker:1884", "broker:1884", false, false},
+		{"mqtt://broker", "broker:1883", false, false},
+		{"ssl://broker", "broker:8883", true, false},
+		{"mqtts://broker:9883", "broker:9883", true, false},
+		{"broker.local:1883", "broker.local:1883", false, false},
+		{"ws://broker", "", false, true},
+		{"", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.broker, func(t *testing.T) {
+			_, addr, useTLS, err := parseBroker(tt.broker)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.addr, addr)
+			assert.Equal(t, tt.useTLS, useTLS)
+		})
+	}
+}
+
+func TestRemainingLength(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, 2097151} {
+		encoded := appendRemainingLength([]byte{packetPublish}, n)
+		r := bufio.NewReader(&zeroReader{prefix: encoded})
+		_, body, err := readPacket(r)
+		require.NoError(t, err)
+		assert.Len(t, body, n)
+	}
+}
+
+// zeroReader returns prefix followed by zero bytes.
+type zeroReader struct {
+	prefix []byte
+}
+
+func (z *zeroReader) Read(p []byte) (int, error) {
+	n := copy(p, z.prefix)
+	z.prefix = z.prefix[n:]
+	clear(p[n:])
+	return len(p), nil
+}
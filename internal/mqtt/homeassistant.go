@@ -0,0 +1,139 @@
+package mqtt
+
+import (
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Device temperature limits in mireds, the unit Home Assistant uses for color_temp.
+const (
+	minMireds = 143 // 7000K
+	maxMireds = 344 // 2900K
+)
+
+// discoveryConfig is a Home Assistant MQTT light discovery config using the JSON schema.
+type discoveryConfig struct {
+	Name                *string         `json:"name"` // null names the entity after its device
+	UniqueID            string          `json:"unique_id"`
+	Schema              string          `json:"schema"`
+	CommandTopic        string          `json:"command_topic"`
+	StateTopic          string          `json:"state_topic"`
+	AvailabilityTopic   string          `json:"availability_topic"`
+	Brightness          bool            `json:"brightness"`
+	BrightnessScale     int             `json:"brightness_scale"`
+	SupportedColorModes []string        `json:"supported_color_modes"`
+	MinMireds           int             `json:"min_mireds"`
+	MaxMireds           int             `json:"max_mireds"`
+	Device              discoveryDevice `json:"device"`
+	Origin              discoveryOrigin `json:"origin"`
+}
+
+// discoveryDevice describes the physical light in the Home Assistant device registry.
+type discoveryDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+	SerialNumber string   `json:"serial_number,omitempty"`
+}
+
+// discoveryOrigin identifies keylightd as the source of the discovery config.
+type discoveryOrigin struct {
+	Name string `json:"name"`
+}
+
+// discoveryConfig builds the discovery config for a light.
+func (b *Bridge) discoveryConfig(light *keylight.Light) discoveryConfig {
+	objectID := ObjectID(light.ID)
+	manufacturer := "Elgato"
+	if light.Driver == "wled" {
+		manufacturer = "WLED"
+	}
+	name := light.Name
+	if name == "" {
+		name = light.ID
+	}
+	return discoveryConfig{
+		UniqueID:            "keylightd_" + objectID,
+		Schema:              "json",
+		CommandTopic:        b.lightTopic(objectID, "set"),
+		StateTopic:          b.lightTopic(objectID, "state"),
+		AvailabilityTopic:   b.statusTopic(),
+		Brightness:          true,
+		BrightnessScale:     config.MaxBrightness,
+		SupportedColorModes: []string{"color_temp"},
+		MinMireds:           minMireds,
+		MaxMireds:           maxMireds,
+		Device: discoveryDevice{
+			Identifiers:  []string{"keylightd_" + objectID},
+			Name:         name,
+			Manufacturer: manufacturer,
+			Model:        light.ProductName,
+			SWVersion:    light.FirmwareVersion,
+			SerialNumber: light.SerialNumber,
+		},
+		Origin: discoveryOrigin{Name: "keylightd"},
+	}
+}
+
+// lightState is a light's state in the Home Assistant JSON schema.
+type lightState struct {
+	State      string `json:"state"`
+	Brightness int    `json:"brightness"`
+	ColorMode  string `json:"color_mode"`
+	ColorTemp  int    `json:"color_temp"`
+}
+
+// newLightState converts a light to its published state. Light temperatures
+// are stored in mireds, matching color_temp.
+func newLightState(light *keylight.Light) lightState {
+	state := "OFF"
+	if light.On {
+		state = "ON"
+	}
+	return lightState{
+		State:      state,
+		Brightness: light.Brightness,
+		ColorMode:  "color_temp",
+		ColorTemp:  light.Temperature,
+	}
+}
+
+// lightCommand is a command from Home Assistant in the JSON schema.
+type lightCommand struct {
+	State      string   `json:"state"`
+	Brightness *int     `json:"brightness"`
+	ColorTemp  *int     `json:"color_temp"`
+	Transition *float64 `json:"transition"` // seconds
+}
+
+// stateChange converts the command to a state change and transition duration.
+// Values are clamped to the range the lights support.
+func (c lightCommand) stateChange() (keylight.StateChange, time.Duration) {
+	var change keylight.StateChange
+	switch c.State {
+	case "ON":
+		on := true
+		change.On = &on
+	case "OFF":
+		off := false
+		change.On = &off
+	}
+	if c.Brightness != nil {
+		brightness := min(max(*c.Brightness, config.MinBrightness), config.MaxBrightness)
+		change.Brightness = &brightness
+	}
+	if c.ColorTemp != nil {
+		kelvin := keylight.ConvertDeviceToTemperature(*c.ColorTemp)
+		change.Temperature = &kelvin
+	}
+
+	var duration time.Duration
+	if c.Transition != nil && *c.Transition > 0 {
+		duration = min(time.Duration(*c.Transition*float64(time.Second)), config.MaxTransitionDuration)
+	}
+	return change, duration
+}
@@ -0,0 +1,103 @@
+// Package requestid tracks the ID of the API request being handled, so every
+// log line written while handling it, down to the device calls it makes, can
+// be correlated with the client operation that caused it.
+package requestid
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	logfilter "github.com/jmylchreest/slog-logfilter"
+)
+
+// Header is the HTTP header carrying a request's ID, in both directions.
+const Header = "X-Request-ID"
+
+// LogKey is the log attribute holding a request's ID. Log filters of type
+// context:request_id match against it.
+const LogKey = "request_id"
+
+// maxLength is the length limit of client-supplied IDs.
+const maxLength = 128
+
+type contextKey struct{}
+
+func init() {
+	logfilter.RegisterContextExtractor(LogKey, func(ctx context.Context) (string, bool) {
+		id := FromContext(ctx)
+		return id, id != ""
+	})
+}
+
+// New returns a new request ID.
+func New() string {
+	return uuid.New().String()
+}
+
+// Resolve returns the ID a client supplied if it is valid, or a new one.
+func Resolve(supplied string) string {
+	if Valid(supplied) {
+		return supplied
+	}
+	return New()
+}
+
+// Valid reports whether a client-supplied ID can be used as is: it must be
+// non-empty, at most 128 characters, and made of letters, digits and . _ : -
+// so it can't inject anything into logs or headers.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == ':', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns a copy of ctx carrying a request ID.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID ctx carries, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// logHandler adds the request ID of a record's context to the record.
+type logHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler returns a handler that adds the request ID carried by the
+// context of each record, if any, before passing it to inner. Only the
+// *Context logging methods pass a context.
+func NewLogHandler(inner slog.Handler) slog.Handler {
+	return logHandler{inner}
+}
+
+func (h logHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String(LogKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{h.Handler.WithGroup(name)}
+}
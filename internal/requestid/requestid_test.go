@@ -0,0 +1,68 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	logfilter "github.com/jmylchreest/slog-logfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValid(t *testing.T) {
+	for _, id := range []string{"abc", "0192f4e1-7c1a-7b3e-9a2d-5f6e7d8c9b0a", "trace_1.2:3", strings.Repeat("a", 128)} {
+		assert.True(t, Valid(id), id)
+	}
+	for _, id := range []string{"", "has space", "line\nbreak", `quote"`, "ünïcode", strings.Repeat("a", 129)} {
+		assert.False(t, Valid(id), id)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	assert.Equal(t, "trace-1", Resolve("trace-1"))
+
+	generated := Resolve("bad id")
+	assert.True(t, Valid(generated))
+	assert.NotEqual(t, generated, Resolve(""))
+}
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Equal(t, "trace-1", FromContext(WithID(context.Background(), "trace-1")))
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+	decode := func() map[string]any {
+		t.Helper()
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		buf.Reset()
+		return line
+	}
+
+	logger.InfoContext(WithID(context.Background(), "trace-1"), "with ID")
+	line := decode()
+	assert.Equal(t, "trace-1", line[LogKey])
+	assert.Equal(t, "test", line["component"])
+
+	logger.Info("without ID")
+	assert.NotContains(t, decode(), LogKey)
+}
+
+func TestContextExtractor(t *testing.T) {
+	extract := logfilter.GetContextExtractor(LogKey)
+	require.NotNil(t, extract)
+
+	id, ok := extract(WithID(context.Background(), "trace-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "trace-1", id)
+
+	_, ok = extract(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,406 @@
+// Package scene implements scenes: named sets of light and group states that
+// are applied together, in order, with their own transitions.
+//
+// Scenes are persisted in the config state block. Applying a scene runs in the
+// background as a job, which can be followed and cancelled while it runs; see
+// Apply.
+package scene
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/internal/schedule"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Target types a scene entry can act upon.
+const (
+	TargetLight = schedule.TargetLight
+	TargetGroup = schedule.TargetGroup
+)
+
+// Target identifies the light or group(s) a scene entry acts upon, the same
+// way a schedule's target does.
+type Target = schedule.Target
+
+// Entry is the state a scene gives one light or group. Nil fields are left
+// unchanged.
+//
+// Entries are applied in steps: all entries with the same Order start
+// together, Delay after their step starts, and a step starts once the
+// transitions of the one before it have finished.
+type Entry struct {
+	Target       Target `json:"target"`
+	On           *bool  `json:"on,omitempty"`
+	Brightness   *int   `json:"brightness,omitempty"`
+	Temperature  *int   `json:"temperature,omitempty"`
+	TransitionMS int    `json:"transition_ms,omitempty"` // Time to ramp brightness and temperature over
+	Order        int    `json:"order,omitempty"`         // Step the entry is applied in, lowest first
+	DelayMS      int    `json:"delay_ms,omitempty"`      // Wait after the step starts before applying the entry
+}
+
+// Change returns the state change the entry applies.
+func (e Entry) Change() keylight.StateChange {
+	return keylight.StateChange{On: e.On, Brightness: e.Brightness, Temperature: e.Temperature}
+}
+
+// Transition returns how long the entry's transition takes.
+func (e Entry) Transition() time.Duration {
+	return time.Duration(e.TransitionMS) * time.Millisecond
+}
+
+// Delay returns how long after its step starts the entry is applied.
+func (e Entry) Delay() time.Duration {
+	return time.Duration(e.DelayMS) * time.Millisecond
+}
+
+// Scene is a named set of entries applied together.
+type Scene struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Entries []Entry `json:"entries"`
+}
+
+// Manager owns the set of scenes and applies them.
+// Concurrency contract mirrors group.Manager: all access to m.scenes and
+// m.runs is guarded by mu, and returned values are copies.
+type Manager struct {
+	logger *slog.Logger
+	cfg    *config.Config
+	lights keylight.LightManager
+	groups *group.Manager
+	jobs   *jobs.Manager
+	scenes map[string]*Scene
+	runs   map[string]string // job ID of the latest run of each scene, see Apply
+	mu     sync.RWMutex
+}
+
+// NewManager creates a scene manager and loads any scenes from config state.
+func NewManager(logger *slog.Logger, cfg *config.Config, lights keylight.LightManager, groups *group.Manager, jobs *jobs.Manager) *Manager {
+	m := &Manager{
+		logger: logger,
+		cfg:    cfg,
+		lights: lights,
+		groups: groups,
+		jobs:   jobs,
+		scenes: make(map[string]*Scene),
+		runs:   make(map[string]string),
+	}
+	if err := m.loadScenes(); err != nil {
+		logger.Error("failed to load scenes", "error", err)
+	}
+	return m
+}
+
+// loadScenes loads scenes from the configuration state.
+func (m *Manager) loadScenes() error {
+	if m.cfg.State.Scenes == nil {
+		return nil
+	}
+
+	scenes := make(map[string]*Scene)
+	for id, raw := range m.cfg.State.Scenes {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return fmt.Errorf("invalid scene data for %s: %w", id, err)
+		}
+		var s Scene
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("invalid scene data for %s: %w", id, err)
+		}
+		s.ID = id
+		scenes[id] = &s
+	}
+
+	m.mu.Lock()
+	m.scenes = scenes
+	m.mu.Unlock()
+
+	m.logger.Info("Loaded scenes from config", "count", len(scenes))
+	return nil
+}
+
+// saveScenesLocked persists scenes to config. Caller must hold m.mu.
+func (m *Manager) saveScenesLocked() error {
+	scenesMap := make(map[string]any, len(m.scenes))
+	for id, s := range m.scenes {
+		entries := make([]map[string]any, 0, len(s.Entries))
+		for _, e := range s.Entries {
+			entry := map[string]any{
+				"target": map[string]any{"type": e.Target.Type, "id": e.Target.ID},
+			}
+			if e.On != nil {
+				entry["on"] = *e.On
+			}
+			if e.Brightness != nil {
+				entry["brightness"] = *e.Brightness
+			}
+			if e.Temperature != nil {
+				entry["temperature"] = *e.Temperature
+			}
+			if e.TransitionMS > 0 {
+				entry["transition_ms"] = e.TransitionMS
+			}
+			if e.Order != 0 {
+				entry["order"] = e.Order
+			}
+			if e.DelayMS > 0 {
+				entry["delay_ms"] = e.DelayMS
+			}
+			entries = append(entries, entry)
+		}
+		scenesMap[id] = map[string]any{"name": s.Name, "entries": entries}
+	}
+
+	m.cfg.State.Scenes = scenesMap
+	if err := m.cfg.Save(); err != nil {
+		m.logger.Error("Failed to save scenes to config", "error", err)
+		return fmt.Errorf("failed to save scenes to config: %w", err)
+	}
+	return nil
+}
+
+// validate checks a scene definition, including that its targets exist.
+func (m *Manager) validate(s *Scene) error {
+	if err := validateDefinition(s); err != nil {
+		return err
+	}
+	lights := m.lights.GetLights()
+	for _, e := range s.Entries {
+		switch e.Target.Type {
+		case TargetLight:
+			if _, ok := lights[e.Target.ID]; !ok {
+				return kerrors.NotFoundf("light %s not found", e.Target.ID)
+			}
+		case TargetGroup:
+			if _, notFound := m.groups.GetGroupsByKeys(e.Target.ID); len(notFound) > 0 {
+				return kerrors.NotFoundf("group(s) not found: %s", strings.Join(notFound, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// validateDefinition checks a scene definition without looking up its
+// targets.
+func validateDefinition(s *Scene) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return kerrors.InvalidInputf("scene name is required")
+	}
+	if len(s.Entries) == 0 {
+		return kerrors.InvalidInputf("scene must have at least one entry")
+	}
+	for i := range s.Entries {
+		e := &s.Entries[i]
+		e.Target.ID = strings.TrimSpace(e.Target.ID)
+		if e.Target.ID == "" {
+			return kerrors.InvalidInputf("entry %d: target id is required", i+1)
+		}
+		if e.Target.Type != TargetLight && e.Target.Type != TargetGroup {
+			return kerrors.InvalidInputf("entry %d: invalid target type %q, must be %q or %q", i+1, e.Target.Type, TargetLight, TargetGroup)
+		}
+		change := e.Change()
+		if change.IsEmpty() {
+			return kerrors.InvalidInputf("entry %d: must set at least one of on, brightness, temperature", i+1)
+		}
+		if err := change.Validate(); err != nil {
+			return kerrors.InvalidInputf("entry %d: %s", i+1, err)
+		}
+		if e.TransitionMS < 0 || e.DelayMS < 0 {
+			return kerrors.InvalidInputf("entry %d: transition_ms and delay_ms cannot be negative", i+1)
+		}
+		if e.Transition() > config.MaxTransitionDuration {
+			return kerrors.InvalidInputf("entry %d: transition exceeds maximum of %s", i+1, config.MaxTransitionDuration)
+		}
+		if e.Delay() > config.MaxTransitionDuration {
+			return kerrors.InvalidInputf("entry %d: delay exceeds maximum of %s", i+1, config.MaxTransitionDuration)
+		}
+	}
+	return nil
+}
+
+// CreateScene validates and stores a new scene.
+func (m *Manager) CreateScene(s Scene) (*Scene, error) {
+	if err := m.validate(&s); err != nil {
+		return nil, err
+	}
+	s.ID = "scene-" + uuid.New().String()
+
+	m.mu.Lock()
+	m.scenes[s.ID] = &s
+	if err := m.saveScenesLocked(); err != nil {
+		delete(m.scenes, s.ID)
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to save scenes: %w", err)
+	}
+	result := cloneScene(&s)
+	m.mu.Unlock()
+
+	m.logger.Info("created scene", "id", s.ID, "name", s.Name)
+	return result, nil
+}
+
+// UpdateScene replaces an existing scene's definition. A run of the scene
+// already in progress carries on with the old definition.
+func (m *Manager) UpdateScene(id string, s Scene) (*Scene, error) {
+	if err := m.validate(&s); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	existing, ok := m.scenes[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, kerrors.NotFoundf("scene %s not found", id)
+	}
+	s.ID = id
+	m.scenes[id] = &s
+	if err := m.saveScenesLocked(); err != nil {
+		m.scenes[id] = existing
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to save scenes: %w", err)
+	}
+	result := cloneScene(&s)
+	m.mu.Unlock()
+
+	m.logger.Info("updated scene", "id", id, "name", s.Name)
+	return result, nil
+}
+
+// ImportScenes creates or replaces scenes by ID, for restoring a backup.
+// Unlike CreateScene, targets are not looked up, since their lights may not
+// have been discovered yet on this host. Scenes without an ID are given a new
+// one. All scenes are validated before any are stored.
+func (m *Manager) ImportScenes(scenes []Scene) error {
+	imported := make([]*Scene, 0, len(scenes))
+	for _, s := range scenes {
+		if err := validateDefinition(&s); err != nil {
+			return kerrors.InvalidInputf("scene %q: %s", s.Name, err)
+		}
+		if s.ID == "" {
+			s.ID = "scene-" + uuid.New().String()
+		}
+		imported = append(imported, &s)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous := maps.Clone(m.scenes)
+	for _, s := range imported {
+		m.scenes[s.ID] = s
+	}
+	if err := m.saveScenesLocked(); err != nil {
+		m.scenes = previous
+		return fmt.Errorf("failed to persist imported scenes: %w", err)
+	}
+
+	m.logger.Info("imported scenes", "count", len(imported))
+	return nil
+}
+
+// DeleteScene removes a scene, cancelling it if it is being applied.
+func (m *Manager) DeleteScene(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.scenes[id]
+	if !ok {
+		return kerrors.NotFoundf("scene %s not found", id)
+	}
+	delete(m.scenes, id)
+	if err := m.saveScenesLocked(); err != nil {
+		m.scenes[id] = existing
+		return fmt.Errorf("failed to persist scene deletion: %w", err)
+	}
+	if jobID, ok := m.runs[id]; ok {
+		_ = m.jobs.Cancel(jobID) // fails if it has already finished
+		delete(m.runs, id)
+	}
+
+	m.logger.Info("deleted scene", "id", id)
+	return nil
+}
+
+// GetScene returns a copy of a scene by ID, or by name if no scene has that
+// ID and exactly one has that name.
+func (m *Manager) GetScene(key string) (*Scene, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, err := m.findLocked(key)
+	if err != nil {
+		return nil, err
+	}
+	return cloneScene(s), nil
+}
+
+// ValidateScene returns an error if key is not the ID or name of a scene, or
+// names more than one.
+func (m *Manager) ValidateScene(key string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, err := m.findLocked(key)
+	return err
+}
+
+// findLocked returns the scene with ID or name key. Caller must hold m.mu.
+func (m *Manager) findLocked(key string) (*Scene, error) {
+	if s, ok := m.scenes[key]; ok {
+		return s, nil
+	}
+	var found *Scene
+	for _, s := range m.scenes {
+		if s.Name != key {
+			continue
+		}
+		if found != nil {
+			return nil, kerrors.InvalidInputf("more than one scene is named %q, use its ID", key)
+		}
+		found = s
+	}
+	if found == nil {
+		return nil, kerrors.NotFoundf("scene %s not found", key)
+	}
+	return found, nil
+}
+
+// GetScenes returns copies of all scenes ordered by name.
+func (m *Manager) GetScenes() []*Scene {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*Scene, 0, len(m.scenes))
+	for _, s := range m.scenes {
+		result = append(result, cloneScene(s))
+	}
+	slices.SortFunc(result, func(a, b *Scene) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return result
+}
+
+// cloneScene returns a copy of s that shares nothing with it.
+func cloneScene(s *Scene) *Scene {
+	c := *s
+	c.Entries = slices.Clone(s.Entries)
+	return &c
+}
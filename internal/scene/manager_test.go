@@ -0,0 +1,306 @@
+package scene
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type transitionCall struct {
+	id       string
+	change   keylight.StateChange
+	duration time.Duration
+	at       time.Time
+}
+
+type mockLightManager struct {
+	keylight.LightManager
+	mu     sync.Mutex
+	lights map[string]*keylight.Light
+	calls  []transitionCall
+}
+
+func (m *mockLightManager) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, keylight.ErrLightNotFound
+	}
+	return light, nil
+}
+
+func (m *mockLightManager) Transition(_ context.Context, id string, change keylight.StateChange, duration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.lights[id]; !ok {
+		return keylight.ErrLightNotFound
+	}
+	m.calls = append(m.calls, transitionCall{id: id, change: change, duration: duration, at: time.Now()})
+	return nil
+}
+
+func (m *mockLightManager) transitions() []transitionCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]transitionCall(nil), m.calls...)
+}
+
+func setupTestManager(t *testing.T) (*Manager, *mockLightManager, *config.Config) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "test.yaml")
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(configPath)
+	cfg := config.New(v)
+	require.NoError(t, cfg.Save())
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{
+		"key":  {ID: "key"},
+		"fill": {ID: "fill"},
+	}}
+	groups := group.NewManager(logger, lights, cfg)
+	return NewManager(logger, cfg, lights, groups, jobs.NewManager(logger)), lights, cfg
+}
+
+func boolPtr(b bool) *bool { return &b }
+func intPtr(i int) *int    { return &i }
+
+func waitForRun(t *testing.T, m *Manager, key string) (*jobs.Job, Progress) {
+	t.Helper()
+	job, err := m.GetRun(key)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	job, err = m.jobs.Wait(ctx, job.ID)
+	require.NoError(t, err)
+	return job, job.Progress.(Progress)
+}
+
+func TestCreateSceneValidation(t *testing.T) {
+	m, _, _ := setupTestManager(t)
+
+	tests := []struct {
+		name  string
+		scene Scene
+		check func(error) bool
+	}{
+		{"no name", Scene{Entries: []Entry{{Target: Target{Type: TargetLight, ID: "key"}, On: boolPtr(true)}}}, kerrors.IsInvalidInput},
+		{"no entries", Scene{Name: "empty"}, kerrors.IsInvalidInput},
+		{"empty entry", Scene{Name: "x", Entries: []Entry{{Target: Target{Type: TargetLight, ID: "key"}}}}, kerrors.IsInvalidInput},
+		{"bad target type", Scene{Name: "x", Entries: []Entry{{Target: Target{Type: "room", ID: "key"}, On: boolPtr(true)}}}, kerrors.IsInvalidInput},
+		{"bad brightness", Scene{Name: "x", Entries: []Entry{{Target: Target{Type: TargetLight, ID: "key"}, Brightness: intPtr(101)}}}, kerrors.IsInvalidInput},
+		{"negative delay", Scene{Name: "x", Entries: []Entry{{Target: Target{Type: TargetLight, ID: "key"}, On: boolPtr(true), DelayMS: -1}}}, kerrors.IsInvalidInput},
+		{"unknown light", Scene{Name: "x", Entries: []Entry{{Target: Target{Type: TargetLight, ID: "nope"}, On: boolPtr(true)}}}, kerrors.IsNotFound},
+		{"unknown group", Scene{Name: "x", Entries: []Entry{{Target: Target{Type: TargetGroup, ID: "nope"}, On: boolPtr(true)}}}, kerrors.IsNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.CreateScene(tt.scene)
+			assert.True(t, tt.check(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestScenePersistence(t *testing.T) {
+	m, lights, cfg := setupTestManager(t)
+
+	created, err := m.CreateScene(Scene{Name: "Streaming start", Entries: []Entry{
+		{Target: Target{Type: TargetLight, ID: "key"}, On: boolPtr(true), Brightness: intPtr(60), TransitionMS: 2000},
+		{Target: Target{Type: TargetLight, ID: "fill"}, On: boolPtr(true), Order: 1, DelayMS: 500},
+	}})
+	require.NoError(t, err)
+
+	reloaded := NewManager(m.logger, cfg, lights, m.groups, m.jobs)
+	got, err := reloaded.GetScene(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, got)
+
+	byName, err := reloaded.GetScene("Streaming start")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, byName.ID)
+
+	require.NoError(t, reloaded.DeleteScene(created.ID))
+	_, err = reloaded.GetScene(created.ID)
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestApply_OrderAndTransitions(t *testing.T) {
+	m, lights, _ := setupTestManager(t)
+
+	s, err := m.CreateScene(Scene{Name: "Streaming start", Entries: []Entry{
+		{Target: Target{Type: TargetLight, ID: "fill"}, On: boolPtr(true), Order: 1},
+		{Target: Target{Type: TargetLight, ID: "key"}, On: boolPtr(true), Brightness: intPtr(80), TransitionMS: 100},
+	}})
+	require.NoError(t, err)
+
+	started, err := m.Apply(context.Background(), s.Name)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusRunning, started.Status)
+	assert.Equal(t, JobKind, started.Kind)
+	assert.Equal(t, s.ID, started.Subject)
+	assert.Equal(t, 2, started.Progress.(Progress).Steps)
+
+	job, progress := waitForRun(t, m, s.ID)
+	assert.Equal(t, started.ID, job.ID)
+	assert.Equal(t, jobs.StatusCompleted, job.Status)
+	assert.Equal(t, 2, progress.Applied)
+	assert.Empty(t, progress.Errors)
+
+	calls := lights.transitions()
+	require.Len(t, calls, 2)
+	assert.Equal(t, "key", calls[0].id)
+	assert.Equal(t, 100*time.Millisecond, calls[0].duration)
+	assert.Equal(t, "fill", calls[1].id)
+	assert.GreaterOrEqual(t, calls[1].at.Sub(calls[0].at), 100*time.Millisecond, "the fill light waits for the key light's transition")
+}
+
+func TestApply_Errors(t *testing.T) {
+	m, lights, _ := setupTestManager(t)
+
+	s, err := m.CreateScene(Scene{Name: "desk", Entries: []Entry{
+		{Target: Target{Type: TargetLight, ID: "key"}, On: boolPtr(true)},
+		{Target: Target{Type: TargetLight, ID: "fill"}, On: boolPtr(true)},
+	}})
+	require.NoError(t, err)
+	delete(lights.lights, "fill") // forgotten since the scene was made
+
+	_, err = m.Apply(context.Background(), s.ID)
+	require.NoError(t, err)
+	job, progress := waitForRun(t, m, s.ID)
+	assert.Equal(t, jobs.StatusFailed, job.Status)
+	assert.Equal(t, "1 of 2 entries could not be applied", job.Error)
+	assert.Equal(t, 2, progress.Applied)
+	require.Len(t, progress.Errors, 1)
+	assert.Contains(t, progress.Errors[0], "light fill")
+}
+
+func TestCancelRun(t *testing.T) {
+	m, lights, _ := setupTestManager(t)
+
+	s, err := m.CreateScene(Scene{Name: "slow", Entries: []Entry{
+		{Target: Target{Type: TargetLight, ID: "key"}, On: boolPtr(true)},
+		{Target: Target{Type: TargetLight, ID: "fill"}, On: boolPtr(true), Order: 1, DelayMS: 60000},
+	}})
+	require.NoError(t, err)
+
+	_, err = m.GetRun(s.ID)
+	assert.True(t, kerrors.IsNotFound(err), "a scene that hasn't been applied has no run")
+	_, err = m.CancelRun(context.Background(), s.ID)
+	assert.True(t, kerrors.IsConflict(err))
+
+	_, err = m.Apply(context.Background(), s.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(lights.transitions()) == 1 }, time.Second, 5*time.Millisecond)
+
+	job, err := m.CancelRun(context.Background(), s.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusCancelled, job.Status)
+	progress := job.Progress.(Progress)
+	assert.Equal(t, 2, progress.Step)
+	assert.Equal(t, 1, progress.Applied)
+	assert.False(t, job.FinishedAt.IsZero())
+	assert.Len(t, lights.transitions(), 1, "the fill light must not be turned on")
+
+	_, err = m.CancelRun(context.Background(), s.ID)
+	assert.True(t, kerrors.IsConflict(err))
+}
+
+func TestApplyTo(t *testing.T) {
+	m, lights, _ := setupTestManager(t)
+	ctx := context.Background()
+
+	front, err := m.groups.CreateGroup(ctx, "front", []string{"key"})
+	require.NoError(t, err)
+	both, err := m.groups.CreateGroup(ctx, "both", []string{"key", "fill"})
+	require.NoError(t, err)
+
+	s, err := m.CreateScene(Scene{Name: "Evening warm", Entries: []Entry{
+		{Target: Target{Type: TargetLight, ID: "key"}, On: boolPtr(true)},
+		{Target: Target{Type: TargetLight, ID: "fill"}, Brightness: intPtr(40)},
+		{Target: Target{Type: TargetGroup, ID: "both"}, Temperature: intPtr(3000), Order: 1},
+		{Target: Target{Type: TargetGroup, ID: "front"}, Brightness: intPtr(90), Order: 2},
+	}})
+	require.NoError(t, err)
+
+	targets := func(entries []Entry) []Target {
+		var result []Target
+		for _, e := range entries {
+			result = append(result, e.Target)
+		}
+		return result
+	}
+
+	entries, err := m.scoped(s.Entries, Target{Type: TargetLight, ID: "fill"})
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{Type: TargetLight, ID: "fill"}, {Type: TargetLight, ID: "fill"}}, targets(entries))
+
+	// Groups outside the scope apply to the lights in it they include
+	entries, err = m.scoped(s.Entries, Target{Type: TargetGroup, ID: "front"})
+	require.NoError(t, err)
+	assert.Equal(t, []Target{
+		{Type: TargetLight, ID: "key"},
+		{Type: TargetLight, ID: "key"},
+		{Type: TargetGroup, ID: front.ID},
+	}, targets(entries))
+
+	entries, err = m.scoped(s.Entries, Target{Type: TargetGroup, ID: "both"})
+	require.NoError(t, err)
+	assert.Equal(t, []Target{
+		{Type: TargetLight, ID: "key"},
+		{Type: TargetLight, ID: "fill"},
+		{Type: TargetGroup, ID: both.ID},
+		{Type: TargetLight, ID: "key"},
+	}, targets(entries))
+
+	_, err = m.ApplyTo(ctx, s.ID, &Target{Type: TargetGroup, ID: "missing"})
+	assert.True(t, kerrors.IsNotFound(err))
+	_, err = m.ApplyTo(ctx, s.ID, &Target{Type: TargetLight, ID: "other"})
+	assert.True(t, kerrors.IsInvalidInput(err), "the scene has nothing for the light")
+
+	_, err = m.ApplyTo(ctx, s.Name, &Target{Type: TargetLight, ID: "fill"})
+	require.NoError(t, err)
+	job, progress := waitForRun(t, m, s.ID)
+	assert.Equal(t, jobs.StatusCompleted, job.Status)
+	assert.Equal(t, 2, progress.Entries)
+	calls := lights.transitions()
+	require.Len(t, calls, 2)
+	for _, c := range calls {
+		assert.Equal(t, "fill", c.id)
+	}
+}
+
+func TestSteps(t *testing.T) {
+	entries := []Entry{
+		{Target: Target{ID: "c"}, Order: 2},
+		{Target: Target{ID: "a"}},
+		{Target: Target{ID: "b"}, Order: 2},
+		{Target: Target{ID: "d"}, Order: -1},
+	}
+	var ids [][]string
+	for _, step := range steps(entries) {
+		var stepIDs []string
+		for _, e := range step {
+			stepIDs = append(stepIDs, e.Target.ID)
+		}
+		ids = append(ids, stepIDs)
+	}
+	assert.Equal(t, [][]string{{"d"}, {"a"}, {"c", "b"}}, ids)
+}
@@ -0,0 +1,297 @@
+package scene
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// JobKind is the kind of the jobs that apply scenes.
+const JobKind = "scene.apply"
+
+// Progress is how far a run of a scene has got, reported as its job's
+// progress.
+type Progress struct {
+	Step    int      `json:"step"`    // Steps started so far
+	Steps   int      `json:"steps"`   // Steps in the scene
+	Applied int      `json:"applied"` // Entries applied so far
+	Entries int      `json:"entries"` // Entries in the scene
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// run is a run of a scene in progress.
+type run struct {
+	mu       sync.Mutex
+	progress Progress
+	tracker  *jobs.Tracker
+}
+
+// update changes the run's progress and reports it to its job.
+func (r *run) update(fn func(p *Progress)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.progress)
+	p := r.progress
+	p.Errors = slices.Clone(r.progress.Errors)
+	r.tracker.SetProgress(p)
+}
+
+// steps groups a scene's entries into the steps they are applied in, lowest
+// order first.
+func steps(entries []Entry) [][]Entry {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b Entry) int { return cmp.Compare(a.Order, b.Order) })
+	var result [][]Entry
+	for i, e := range sorted {
+		if i == 0 || e.Order != sorted[i-1].Order {
+			result = append(result, nil)
+		}
+		result[len(result)-1] = append(result[len(result)-1], e)
+	}
+	return result
+}
+
+// Apply starts applying the scene with ID or name key as a job and returns
+// the job straight away. The job's progress is a Progress, and it fails if
+// any entries could not be applied. Applying a scene that is already being
+// applied cancels the earlier job, leaving its lights where it got them to.
+func (m *Manager) Apply(ctx context.Context, key string) (*jobs.Job, error) {
+	return m.ApplyTo(ctx, key, nil)
+}
+
+// ApplyTo is Apply limited to the lights of scope, a light or group(s) target,
+// unless scope is nil. Light entries for other lights are left out, and group
+// entries are limited to the groups in scope and to the lights in scope of
+// their other groups. It fails if the scene has nothing for scope.
+func (m *Manager) ApplyTo(ctx context.Context, key string, scope *Target) (*jobs.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, err := m.findLocked(key)
+	if err != nil {
+		return nil, err
+	}
+	scene := cloneScene(s)
+	if scope != nil {
+		entries, err := m.scoped(scene.Entries, *scope)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return nil, kerrors.InvalidInputf("scene %s has nothing for %s %s", scene.Name, scope.Type, scope.ID)
+		}
+		scene.Entries = entries
+	}
+	if prev, ok := m.runs[scene.ID]; ok {
+		_ = m.jobs.Cancel(prev) // fails if it has already finished
+	}
+
+	steps := steps(scene.Entries)
+	// Transitions end with the job, so cancelling it stops lights where they are.
+	r := &run{progress: Progress{Steps: len(steps), Entries: len(scene.Entries)}}
+	job := m.jobs.Start(keylight.WithBoundTransitions(ctx), JobKind, scene.ID, r.progress, func(ctx context.Context, t *jobs.Tracker) error {
+		r.tracker = t
+		return m.run(ctx, scene, steps, r)
+	})
+	m.runs[scene.ID] = job.ID
+
+	m.logger.InfoContext(ctx, "applying scene", "id", scene.ID, "name", scene.Name, "steps", len(steps), "job", job.ID)
+	return job, nil
+}
+
+// scoped returns the entries that act on the lights of scope, limited to them.
+func (m *Manager) scoped(entries []Entry, scope Target) ([]Entry, error) {
+	inScope := make(map[string]bool) // IDs of the groups in scope
+	lights := make(map[string]bool)  // IDs of the lights in scope
+	switch scope.Type {
+	case TargetLight:
+		lights[scope.ID] = true
+	case TargetGroup:
+		groups, notFound := m.groups.GetGroupsByKeys(scope.ID)
+		if len(notFound) > 0 {
+			return nil, kerrors.NotFoundf("group(s) not found: %s", strings.Join(notFound, ", "))
+		}
+		for _, g := range groups {
+			inScope[g.ID] = true
+			for _, id := range g.Lights {
+				lights[id] = true
+			}
+		}
+	default:
+		return nil, kerrors.InvalidInputf("invalid target type %q", scope.Type)
+	}
+
+	var result []Entry
+	for _, e := range entries {
+		switch e.Target.Type {
+		case TargetLight:
+			if lights[e.Target.ID] {
+				result = append(result, e)
+			}
+		case TargetGroup:
+			groups, _ := m.groups.GetGroupsByKeys(e.Target.ID)
+			var ids []string
+			covered := make(map[string]bool)
+			for _, g := range groups {
+				if inScope[g.ID] {
+					ids = append(ids, g.ID)
+					for _, id := range g.Lights {
+						covered[id] = true
+					}
+				}
+			}
+			if len(ids) > 0 {
+				ge := e
+				ge.Target = Target{Type: TargetGroup, ID: strings.Join(ids, ",")}
+				result = append(result, ge)
+			}
+			for _, g := range groups {
+				for _, id := range g.Lights {
+					if lights[id] && !covered[id] {
+						covered[id] = true
+						le := e
+						le.Target = Target{Type: TargetLight, ID: id}
+						result = append(result, le)
+					}
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// run applies a scene's steps one after another.
+func (m *Manager) run(ctx context.Context, scene *Scene, steps [][]Entry, r *run) error {
+	for i, step := range steps {
+		r.update(func(p *Progress) { p.Step = i + 1 })
+		m.applyStep(ctx, step, r)
+		if ctx.Err() != nil {
+			m.logger.InfoContext(ctx, "scene run cancelled", "id", scene.ID, "name", scene.Name)
+			return ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	failed := len(r.progress.Errors)
+	r.mu.Unlock()
+	m.logger.InfoContext(ctx, "scene run finished", "id", scene.ID, "name", scene.Name, "errors", failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entries could not be applied", failed, len(scene.Entries))
+	}
+	return nil
+}
+
+// applyStep applies a step's entries, each after its delay, and waits until
+// their transitions have finished or ctx ends.
+func (m *Manager) applyStep(ctx context.Context, step []Entry, r *run) {
+	start := time.Now()
+	var (
+		wg     sync.WaitGroup
+		length time.Duration
+	)
+	for _, e := range step {
+		length = max(length, e.Delay()+e.Transition())
+		wg.Go(func() {
+			if !sleep(ctx, e.Delay()) {
+				return
+			}
+			err := m.applyEntry(ctx, e)
+			if ctx.Err() != nil {
+				return
+			}
+			r.update(func(p *Progress) {
+				p.Applied++
+				if err != nil {
+					p.Errors = append(p.Errors, fmt.Sprintf("%s %s: %s", e.Target.Type, e.Target.ID, err))
+				}
+			})
+		})
+	}
+	wg.Wait()
+	sleep(ctx, time.Until(start.Add(length)))
+}
+
+// applyEntry applies an entry to its light or groups.
+func (m *Manager) applyEntry(ctx context.Context, e Entry) error {
+	switch e.Target.Type {
+	case TargetLight:
+		return m.lights.Transition(ctx, e.Target.ID, e.Change(), e.Transition())
+	case TargetGroup:
+		groups, notFound := m.groups.GetGroupsByKeys(e.Target.ID)
+		var errs []error
+		if len(notFound) > 0 {
+			errs = append(errs, kerrors.NotFoundf("group(s) not found: %s", strings.Join(notFound, ", ")))
+		}
+		for _, g := range groups {
+			errs = append(errs, m.groups.TransitionGroup(ctx, g.ID, e.Change(), e.Transition()))
+		}
+		return errors.Join(errs...)
+	default:
+		return kerrors.InvalidInputf("invalid target type %q", e.Target.Type)
+	}
+}
+
+// sleep waits for d, returning false if ctx ends first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// GetRun returns the job of the latest run of the scene with ID or name key.
+func (m *Manager) GetRun(key string) (*jobs.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, err := m.findLocked(key)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := m.runs[s.ID]
+	if !ok {
+		return nil, kerrors.NotFoundf("scene %s has not been applied", s.ID)
+	}
+	return m.jobs.Get(id)
+}
+
+// CancelRun stops the run of the scene with ID or name key, leaving its
+// lights where it got them to, and returns its job once it has stopped. It
+// fails with ErrConflict if the scene isn't being applied.
+func (m *Manager) CancelRun(ctx context.Context, key string) (*jobs.Job, error) {
+	m.mu.RLock()
+	s, err := m.findLocked(key)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+	id, ok := m.runs[s.ID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, kerrors.Conflictf("scene %s is not being applied", s.ID)
+	}
+
+	if err := m.jobs.Cancel(id); err != nil {
+		if kerrors.IsConflict(err) || kerrors.IsNotFound(err) {
+			return nil, kerrors.Conflictf("scene %s is not being applied", s.ID)
+		}
+		return nil, err
+	}
+	return m.jobs.Wait(ctx, id)
+}
@@ -0,0 +1,197 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSpec is a parsed five-field cron expression (minute hour day-of-month month day-of-week).
+// Each field is stored as a bitset of the values it matches.
+type cronSpec struct {
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// cronField describes the valid range of a cron field.
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day-of-month", 1, 31},
+	{"month", 1, 12},
+	{"day-of-week", 0, 7}, // 0 and 7 are both Sunday
+}
+
+// cronDescriptors maps the common @-shorthands to their five-field equivalents.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a standard five-field cron expression. Fields support
+// "*", single values, ranges ("1-5"), steps ("*/15", "0-30/10") and comma
+// separated lists of any of these.
+func parseCron(expr string) (*cronSpec, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields, got %d", expr, len(cronFields), len(parts))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+
+	// Fold day-of-week 7 into 0 so Sunday has a single representation.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+		bits[4] &^= 1 << 7
+	}
+
+	return &cronSpec{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: parts[2] == "*",
+		dowStar: parts[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a single comma-separated cron field into a bitset.
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for term := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(term, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*":
+			// full range
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", a, f.name)
+			}
+			if hi, err = strconv.Atoi(b); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", b, f.name)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", rangePart, f.name)
+			}
+			lo = n
+			if hasStep {
+				hi = f.max
+			} else {
+				hi = n
+			}
+		}
+
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%s field value %q out of range %d-%d", f.name, term, f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matches reports whether t falls within the minute described by the spec.
+func (c *cronSpec) matches(t time.Time) bool {
+	return c.minute&(1<<uint(t.Minute())) != 0 &&
+		c.hour&(1<<uint(t.Hour())) != 0 &&
+		c.dayMatches(t)
+}
+
+// dayMatches applies the usual cron rule: when both day-of-month and
+// day-of-week are restricted, a day matches if either field matches.
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	if c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dowMatch
+	case c.dowStar:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// next returns the first matching minute strictly after t, or the zero time if
+// nothing matches within the next five years (e.g. "0 0 31 2 *").
+func (c *cronSpec) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// atToCron converts a daily "HH:MM" time of day into a cron expression.
+func atToCron(at string) (string, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(at))
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q, expected HH:MM", at)
+	}
+	return fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour()), nil
+}
@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{"every minute", "* * * * *", false},
+		{"step", "*/15 * * * *", false},
+		{"range with step", "0-30/10 8-18 * * 1-5", false},
+		{"list", "0,30 9,17 * * *", false},
+		{"sunday as 7", "0 0 * * 7", false},
+		{"descriptor", "@daily", false},
+		{"too few fields", "* * * *", true},
+		{"minute out of range", "60 * * * *", true},
+		{"hour out of range", "0 24 * * *", true},
+		{"inverted range", "0 10-5 * * *", true},
+		{"bad step", "*/0 * * * *", true},
+		{"not a number", "a * * * *", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCron(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	// Monday 2026-03-02 08:30
+	monday := time.Date(2026, time.March, 2, 8, 30, 0, 0, time.UTC)
+
+	spec, err := parseCron("30 8 * * 1-5")
+	require.NoError(t, err)
+	assert.True(t, spec.matches(monday))
+	assert.False(t, spec.matches(monday.Add(time.Minute)))
+	assert.False(t, spec.matches(monday.AddDate(0, 0, 5))) // Saturday
+
+	sunday, err := parseCron("0 0 * * 7")
+	require.NoError(t, err)
+	assert.True(t, sunday.matches(time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)))
+
+	// Day-of-month and day-of-week are OR'd when both are restricted.
+	either, err := parseCron("0 12 1 * 1")
+	require.NoError(t, err)
+	assert.True(t, either.matches(time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC))) // 1st (Sunday)
+	assert.True(t, either.matches(time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC))) // Monday
+	assert.False(t, either.matches(time.Date(2026, time.March, 3, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestCronNext(t *testing.T) {
+	spec, err := parseCron("30 8 * * 1-5")
+	require.NoError(t, err)
+
+	// Friday evening -> next Monday morning
+	friday := time.Date(2026, time.March, 6, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, time.March, 9, 8, 30, 0, 0, time.UTC), spec.next(friday))
+
+	// Exactly on a match returns the following occurrence
+	monday := time.Date(2026, time.March, 9, 8, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, time.March, 10, 8, 30, 0, 0, time.UTC), spec.next(monday))
+
+	never, err := parseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.next(friday).IsZero())
+}
+
+func TestAtToCron(t *testing.T) {
+	expr, err := atToCron("07:05")
+	require.NoError(t, err)
+	assert.Equal(t, "5 7 * * *", expr)
+
+	_, err = atToCron("25:00")
+	assert.Error(t, err)
+}
@@ -0,0 +1,647 @@
+// Package schedule implements time-based automation for lights and groups.
+//
+// Schedules are persisted in the config state block and evaluated once a
+// minute by a background worker started alongside the server.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Target types a schedule can act upon.
+const (
+	TargetLight = "light"
+	TargetGroup = "group"
+)
+
+// Target identifies the light or group(s) a schedule acts upon.
+// For groups, ID may be a comma-separated list of group IDs or names.
+type Target struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Action is the light state applied when a schedule fires. Nil fields are left unchanged.
+type Action struct {
+	On          *bool `json:"on,omitempty"`
+	Brightness  *int  `json:"brightness,omitempty"`
+	Temperature *int  `json:"temperature,omitempty"`
+}
+
+// IsEmpty reports whether the action would change nothing.
+func (a Action) IsEmpty() bool {
+	return a.On == nil && a.Brightness == nil && a.Temperature == nil
+}
+
+// Schedule is a recurring action against a light or group, or a scene applied
+// to all of its lights or only those in Target.
+// Exactly one of At (daily "HH:MM") or Cron (five-field cron expression) is set,
+// and exactly one of Action or Scene.
+type Schedule struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	At        string    `json:"at,omitempty"`
+	Cron      string    `json:"cron,omitempty"`
+	Target    Target    `json:"target,omitzero"`
+	Action    Action    `json:"action,omitzero"`
+	Scene     string    `json:"scene,omitempty"`      // ID or name of the scene to apply
+	Timezone  string    `json:"timezone,omitempty"`   // IANA time zone At and Cron are in; empty uses the default
+	SkipDates []string  `json:"skip_dates,omitempty"` // Dates (YYYY-MM-DD, in the schedule's time zone) the schedule doesn't fire on
+	Enabled   bool      `json:"enabled"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	NextRun   time.Time `json:"next_run,omitzero"`
+
+	spec *cronSpec
+	loc  *time.Location // nil uses the manager's default
+}
+
+// Execution is a time a schedule will fire.
+type Execution struct {
+	ScheduleID string    `json:"schedule_id"`
+	Name       string    `json:"name"`
+	At         time.Time `json:"at"`
+	Target     Target    `json:"target,omitzero"`
+	Scene      string    `json:"scene,omitempty"`
+	Action     Action    `json:"action,omitzero"`
+}
+
+// SceneApplier applies the scenes schedules refer to. It is implemented by
+// scene.Manager, which imports this package.
+type SceneApplier interface {
+	// ValidateScene returns an error if key doesn't identify a scene.
+	ValidateScene(key string) error
+	// ApplyTo starts applying the scene with ID or name key, limited to the
+	// lights in scope unless it is nil.
+	ApplyTo(ctx context.Context, key string, scope *Target) (*jobs.Job, error)
+}
+
+// Manager owns the set of schedules and runs them.
+// Concurrency contract mirrors group.Manager: all access to m.schedules is
+// guarded by mu, and returned *Schedule values are copies.
+type Manager struct {
+	logger    *slog.Logger
+	cfg       *config.Config
+	lights    keylight.LightManager
+	groups    *group.Manager
+	scenes    SceneApplier
+	location  *time.Location // time zone of schedules that don't set one
+	schedules map[string]*Schedule
+	mu        sync.RWMutex
+	now       func() time.Time
+}
+
+// NewManager creates a schedule manager and loads any schedules from config state.
+func NewManager(logger *slog.Logger, cfg *config.Config, lights keylight.LightManager, groups *group.Manager) *Manager {
+	m := &Manager{
+		logger:    logger,
+		cfg:       cfg,
+		lights:    lights,
+		groups:    groups,
+		location:  time.Local,
+		schedules: make(map[string]*Schedule),
+		now:       time.Now,
+	}
+	if tz := cfg.Config.Schedules.Timezone; tz != "" {
+		if loc, err := time.LoadLocation(tz); err != nil {
+			logger.Warn("unknown schedules time zone, using the host's", "timezone", tz, "error", err)
+		} else {
+			m.location = loc
+		}
+	}
+	if err := m.loadSchedules(); err != nil {
+		logger.Error("failed to load schedules", "error", err)
+	}
+	return m
+}
+
+// SetSceneApplier sets what applies the scenes schedules refer to. Until it is
+// set, schedules can't use scenes.
+func (m *Manager) SetSceneApplier(scenes SceneApplier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scenes = scenes
+}
+
+// loadSchedules loads schedules from the configuration state.
+func (m *Manager) loadSchedules() error {
+	if m.cfg.State.Schedules == nil {
+		return nil
+	}
+
+	schedules := make(map[string]*Schedule)
+	for id, raw := range m.cfg.State.Schedules {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return fmt.Errorf("invalid schedule data for %s: %w", id, err)
+		}
+		var s Schedule
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("invalid schedule data for %s: %w", id, err)
+		}
+		s.ID = id
+		if err := s.compile(); err != nil {
+			return fmt.Errorf("invalid schedule %s: %w", id, err)
+		}
+		schedules[id] = &s
+	}
+
+	m.mu.Lock()
+	m.schedules = schedules
+	m.mu.Unlock()
+
+	m.logger.Info("Loaded schedules from config", "count", len(schedules))
+	return nil
+}
+
+// saveSchedulesLocked persists schedules to config. Caller must hold m.mu.
+func (m *Manager) saveSchedulesLocked() error {
+	schedulesMap := make(map[string]any, len(m.schedules))
+	for id, s := range m.schedules {
+		entry := map[string]any{
+			"name":    s.Name,
+			"enabled": s.Enabled,
+		}
+		if s.Target != (Target{}) {
+			entry["target"] = map[string]any{"type": s.Target.Type, "id": s.Target.ID}
+		}
+		if s.At != "" {
+			entry["at"] = s.At
+		} else {
+			entry["cron"] = s.Cron
+		}
+		if s.Scene != "" {
+			entry["scene"] = s.Scene
+		}
+		if s.Timezone != "" {
+			entry["timezone"] = s.Timezone
+		}
+		if len(s.SkipDates) > 0 {
+			entry["skip_dates"] = s.SkipDates
+		}
+		action := map[string]any{}
+		if s.Action.On != nil {
+			action["on"] = *s.Action.On
+		}
+		if s.Action.Brightness != nil {
+			action["brightness"] = *s.Action.Brightness
+		}
+		if s.Action.Temperature != nil {
+			action["temperature"] = *s.Action.Temperature
+		}
+		if len(action) > 0 {
+			entry["action"] = action
+		}
+		schedulesMap[id] = entry
+	}
+
+	m.cfg.State.Schedules = schedulesMap
+	if err := m.cfg.Save(); err != nil {
+		m.logger.Error("Failed to save schedules to config", "error", err)
+		return fmt.Errorf("failed to save schedules to config: %w", err)
+	}
+	return nil
+}
+
+// compile parses the schedule's time specification, time zone and skip dates.
+// Skip dates are normalised and sorted.
+func (s *Schedule) compile() error {
+	expr := s.Cron
+	if s.At != "" {
+		var err error
+		if expr, err = atToCron(s.At); err != nil {
+			return err
+		}
+	}
+	spec, err := parseCron(expr)
+	if err != nil {
+		return err
+	}
+
+	var loc *time.Location
+	if s.Timezone != "" {
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("unknown time zone %q", s.Timezone)
+		}
+	}
+
+	dates := make([]string, 0, len(s.SkipDates))
+	for _, d := range s.SkipDates {
+		t, err := time.Parse(time.DateOnly, strings.TrimSpace(d))
+		if err != nil {
+			return fmt.Errorf("invalid skip date %q, expected YYYY-MM-DD", d)
+		}
+		dates = append(dates, t.Format(time.DateOnly))
+	}
+	slices.Sort(dates)
+
+	s.spec = spec
+	s.loc = loc
+	s.SkipDates = slices.Compact(dates)
+	if len(s.SkipDates) == 0 {
+		s.SkipDates = nil
+	}
+	return nil
+}
+
+// location returns the time zone the schedule runs in, def if it doesn't set one.
+func (s *Schedule) location(def *time.Location) *time.Location {
+	if s.loc != nil {
+		return s.loc
+	}
+	return def
+}
+
+// skips reports whether t, in the schedule's time zone, is on a skip date.
+func (s *Schedule) skips(t time.Time) bool {
+	_, found := slices.BinarySearch(s.SkipDates, t.Format(time.DateOnly))
+	return found
+}
+
+// nextAfter returns the first minute after t the schedule fires, in its time
+// zone, or the zero time if it never does. def is the default time zone.
+func (s *Schedule) nextAfter(t time.Time, def *time.Location) time.Time {
+	loc := s.location(def)
+	next := s.spec.next(t.In(loc))
+	for !next.IsZero() && s.skips(next) {
+		// Carry on from the last minute of the skipped day.
+		next = s.spec.next(time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, loc).Add(-time.Minute))
+	}
+	return next
+}
+
+// validate checks a schedule definition, including that its target exists,
+// and compiles its time specification.
+func (m *Manager) validate(s *Schedule) error {
+	if err := validateDefinition(s); err != nil {
+		return err
+	}
+	if s.Scene != "" {
+		m.mu.RLock()
+		scenes := m.scenes
+		m.mu.RUnlock()
+		if scenes == nil {
+			return kerrors.InvalidInputf("scenes are not available")
+		}
+		if err := scenes.ValidateScene(s.Scene); err != nil {
+			return err
+		}
+	}
+	switch s.Target.Type {
+	case TargetLight:
+		if _, ok := m.lights.GetLights()[s.Target.ID]; !ok {
+			return kerrors.NotFoundf("light %s not found", s.Target.ID)
+		}
+	case TargetGroup:
+		if _, notFound := m.groups.GetGroupsByKeys(s.Target.ID); len(notFound) > 0 {
+			return kerrors.NotFoundf("group(s) not found: %s", strings.Join(notFound, ", "))
+		}
+	}
+	return nil
+}
+
+// validateDefinition checks a schedule definition without looking up its
+// target, and compiles its time specification.
+func validateDefinition(s *Schedule) error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.At = strings.TrimSpace(s.At)
+	s.Cron = strings.TrimSpace(s.Cron)
+	s.Scene = strings.TrimSpace(s.Scene)
+	s.Timezone = strings.TrimSpace(s.Timezone)
+
+	if s.Name == "" {
+		return kerrors.InvalidInputf("schedule name is required")
+	}
+	if (s.At == "") == (s.Cron == "") {
+		return kerrors.InvalidInputf("exactly one of at or cron must be set")
+	}
+	if err := s.compile(); err != nil {
+		return kerrors.InvalidInputf("%s", err)
+	}
+	if s.Scene != "" {
+		if !s.Action.IsEmpty() {
+			return kerrors.InvalidInputf("a schedule applies either a scene or an action, not both")
+		}
+		if s.Target == (Target{}) {
+			return nil // the whole scene
+		}
+	} else if s.Action.IsEmpty() {
+		return kerrors.InvalidInputf("schedule action must set at least one of on, brightness, temperature")
+	}
+	if s.Target.ID == "" {
+		return kerrors.InvalidInputf("schedule target id is required")
+	}
+	if s.Target.Type != TargetLight && s.Target.Type != TargetGroup {
+		return kerrors.InvalidInputf("invalid target type %q, must be %q or %q", s.Target.Type, TargetLight, TargetGroup)
+	}
+	return nil
+}
+
+// CreateSchedule validates and stores a new schedule.
+func (m *Manager) CreateSchedule(s Schedule) (*Schedule, error) {
+	if err := m.validate(&s); err != nil {
+		return nil, err
+	}
+	s.ID = "schedule-" + uuid.New().String()
+	s.LastRun = time.Time{}
+
+	m.mu.Lock()
+	m.schedules[s.ID] = &s
+	if err := m.saveSchedulesLocked(); err != nil {
+		delete(m.schedules, s.ID)
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to save schedules: %w", err)
+	}
+	result := m.cloneLocked(&s)
+	m.mu.Unlock()
+
+	m.logger.Info("created schedule", "id", s.ID, "name", s.Name)
+	return result, nil
+}
+
+// UpdateSchedule replaces an existing schedule's definition.
+func (m *Manager) UpdateSchedule(id string, s Schedule) (*Schedule, error) {
+	if err := m.validate(&s); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	existing, ok := m.schedules[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, kerrors.NotFoundf("schedule %s not found", id)
+	}
+	s.ID = id
+	s.LastRun = existing.LastRun
+	m.schedules[id] = &s
+	if err := m.saveSchedulesLocked(); err != nil {
+		m.schedules[id] = existing
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to save schedules: %w", err)
+	}
+	result := m.cloneLocked(&s)
+	m.mu.Unlock()
+
+	m.logger.Info("updated schedule", "id", id, "name", s.Name)
+	return result, nil
+}
+
+// ImportSchedules creates or replaces schedules by ID, for restoring a backup.
+// Unlike CreateSchedule, targets are not looked up, since their lights may not
+// have been discovered yet on this host. Schedules without an ID are given a
+// new one. All schedules are validated before any are stored.
+func (m *Manager) ImportSchedules(schedules []Schedule) error {
+	imported := make([]*Schedule, 0, len(schedules))
+	for _, s := range schedules {
+		if err := validateDefinition(&s); err != nil {
+			return kerrors.InvalidInputf("schedule %q: %s", s.Name, err)
+		}
+		if s.ID == "" {
+			s.ID = "schedule-" + uuid.New().String()
+		}
+		s.LastRun = time.Time{}
+		s.NextRun = time.Time{}
+		imported = append(imported, &s)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous := maps.Clone(m.schedules)
+	for _, s := range imported {
+		m.schedules[s.ID] = s
+	}
+	if err := m.saveSchedulesLocked(); err != nil {
+		m.schedules = previous
+		return fmt.Errorf("failed to persist imported schedules: %w", err)
+	}
+
+	m.logger.Info("imported schedules", "count", len(imported))
+	return nil
+}
+
+// DeleteSchedule removes a schedule.
+func (m *Manager) DeleteSchedule(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.schedules[id]
+	if !ok {
+		return kerrors.NotFoundf("schedule %s not found", id)
+	}
+	delete(m.schedules, id)
+	if err := m.saveSchedulesLocked(); err != nil {
+		m.schedules[id] = existing
+		return fmt.Errorf("failed to persist schedule deletion: %w", err)
+	}
+
+	m.logger.Info("deleted schedule", "id", id)
+	return nil
+}
+
+// GetSchedule returns a copy of a schedule by ID.
+func (m *Manager) GetSchedule(id string) (*Schedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.schedules[id]
+	if !ok {
+		return nil, kerrors.NotFoundf("schedule %s not found", id)
+	}
+	return m.cloneLocked(s), nil
+}
+
+// GetSchedules returns copies of all schedules ordered by name.
+func (m *Manager) GetSchedules() []*Schedule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*Schedule, 0, len(m.schedules))
+	for _, s := range m.schedules {
+		result = append(result, m.cloneLocked(s))
+	}
+	slices.SortFunc(result, func(a, b *Schedule) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return result
+}
+
+// cloneLocked returns a copy of s with NextRun populated. Caller must hold m.mu.
+func (m *Manager) cloneLocked(s *Schedule) *Schedule {
+	c := *s
+	c.SkipDates = slices.Clone(s.SkipDates)
+	if s.Enabled && s.spec != nil {
+		c.NextRun = s.nextAfter(m.now(), m.location)
+	}
+	return &c
+}
+
+// Upcoming returns the times enabled schedules fire within the given period
+// from now, soonest first, up to limit of them. Times are in each schedule's
+// time zone.
+func (m *Manager) Upcoming(limit int, within time.Duration) []Execution {
+	if limit <= 0 {
+		return nil
+	}
+	now := m.now()
+	end := now.Add(within)
+
+	m.mu.RLock()
+	var result []Execution
+	for _, s := range m.schedules {
+		if !s.Enabled || s.spec == nil {
+			continue
+		}
+		t := now
+		for range limit {
+			t = s.nextAfter(t, m.location)
+			if t.IsZero() || t.After(end) {
+				break
+			}
+			result = append(result, Execution{
+				ScheduleID: s.ID,
+				Name:       s.Name,
+				At:         t,
+				Target:     s.Target,
+				Scene:      s.Scene,
+				Action:     s.Action,
+			})
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(result, func(a, b Execution) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ScheduleID, b.ScheduleID)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// Run evaluates schedules at the start of every minute until ctx is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	m.logger.Info("Starting schedule worker")
+	for {
+		now := m.now()
+		wait := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			m.logger.Info("Schedule worker stopped")
+			return
+		case <-timer.C:
+			m.evaluate(ctx, m.now())
+		}
+	}
+}
+
+// evaluate fires every enabled schedule that matches the minute containing now.
+// Each schedule fires at most once per minute.
+func (m *Manager) evaluate(ctx context.Context, now time.Time) {
+	minute := now.Truncate(time.Minute)
+
+	m.mu.Lock()
+	var due []Schedule
+	for _, s := range m.schedules {
+		if !s.Enabled || s.spec == nil {
+			continue
+		}
+		if local := minute.In(s.location(m.location)); !s.spec.matches(local) || s.skips(local) {
+			continue
+		}
+		if !s.LastRun.IsZero() && !s.LastRun.Truncate(time.Minute).Before(minute) {
+			continue
+		}
+		s.LastRun = now
+		due = append(due, *s)
+	}
+	m.mu.Unlock()
+
+	for _, s := range due {
+		if err := m.apply(ctx, s); err != nil {
+			m.logger.Error("schedule failed", "id", s.ID, "name", s.Name, "error", err)
+			continue
+		}
+		m.logger.Info("schedule fired", "id", s.ID, "name", s.Name, "target", s.Target.ID, "scene", s.Scene)
+	}
+}
+
+// apply performs a schedule's action against its target, or starts applying
+// its scene.
+func (m *Manager) apply(ctx context.Context, s Schedule) error {
+	if s.Scene != "" {
+		m.mu.RLock()
+		scenes := m.scenes
+		m.mu.RUnlock()
+		if scenes == nil {
+			return kerrors.InvalidInputf("scenes are not available")
+		}
+		var scope *Target
+		if s.Target != (Target{}) {
+			scope = &s.Target
+		}
+		_, err := scenes.ApplyTo(ctx, s.Scene, scope)
+		return err
+	}
+
+	var errs []error
+	switch s.Target.Type {
+	case TargetLight:
+		id := s.Target.ID
+		if s.Action.On != nil {
+			errs = append(errs, m.lights.SetLightState(ctx, id, keylight.OnValue(*s.Action.On)))
+		}
+		if s.Action.Brightness != nil {
+			errs = append(errs, m.lights.SetLightBrightness(ctx, id, *s.Action.Brightness))
+		}
+		if s.Action.Temperature != nil {
+			errs = append(errs, m.lights.SetLightTemperature(ctx, id, *s.Action.Temperature))
+		}
+	case TargetGroup:
+		groups, notFound := m.groups.GetGroupsByKeys(s.Target.ID)
+		if len(notFound) > 0 {
+			errs = append(errs, kerrors.NotFoundf("group(s) not found: %s", strings.Join(notFound, ", ")))
+		}
+		for _, g := range groups {
+			if s.Action.On != nil {
+				errs = append(errs, m.groups.SetGroupState(ctx, g.ID, *s.Action.On))
+			}
+			if s.Action.Brightness != nil {
+				errs = append(errs, m.groups.SetGroupBrightness(ctx, g.ID, *s.Action.Brightness))
+			}
+			if s.Action.Temperature != nil {
+				errs = append(errs, m.groups.SetGroupTemperature(ctx, g.ID, *s.Action.Temperature))
+			}
+		}
+	default:
+		return kerrors.InvalidInputf("invalid target type %q", s.Target.Type)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,359 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type mockLightManager struct {
+	keylight.LightManager
+	mu     sync.Mutex
+	lights map[string]*keylight.Light
+	calls  []string
+}
+
+func (m *mockLightManager) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, keylight.ErrLightNotFound
+	}
+	return light, nil
+}
+
+func (m *mockLightManager) SetLightState(_ context.Context, id string, value keylight.LightPropertyValue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, id+":"+string(value.PropertyName()))
+	return nil
+}
+
+func (m *mockLightManager) SetLightBrightness(ctx context.Context, id string, brightness int) error {
+	return m.SetLightState(ctx, id, keylight.BrightnessValue(brightness))
+}
+
+func (m *mockLightManager) SetLightTemperature(ctx context.Context, id string, temperature int) error {
+	return m.SetLightState(ctx, id, keylight.TemperatureValue(temperature))
+}
+
+func setupTestManager(t *testing.T) (*Manager, *mockLightManager, *config.Config) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "test.yaml")
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(configPath)
+	cfg := config.New(v)
+	require.NoError(t, cfg.Save())
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{
+		"light1": {ID: "light1"},
+		"light2": {ID: "light2"},
+	}}
+	groups := group.NewManager(logger, lights, cfg)
+	return NewManager(logger, cfg, lights, groups), lights, cfg
+}
+
+func boolPtr(b bool) *bool { return &b }
+func intPtr(i int) *int    { return &i }
+
+func TestCreateScheduleValidation(t *testing.T) {
+	m, _, _ := setupTestManager(t)
+
+	valid := Schedule{
+		Name:    "morning",
+		At:      "08:00",
+		Target:  Target{Type: TargetLight, ID: "light1"},
+		Action:  Action{On: boolPtr(true)},
+		Enabled: true,
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(s *Schedule)
+		notFound bool
+	}{
+		{"missing name", func(s *Schedule) { s.Name = "" }, false},
+		{"both at and cron", func(s *Schedule) { s.Cron = "* * * * *" }, false},
+		{"neither at nor cron", func(s *Schedule) { s.At = "" }, false},
+		{"bad cron", func(s *Schedule) { s.At = ""; s.Cron = "nope" }, false},
+		{"empty action", func(s *Schedule) { s.Action = Action{} }, false},
+		{"bad target type", func(s *Schedule) { s.Target.Type = "room" }, false},
+		{"unknown light", func(s *Schedule) { s.Target.ID = "missing" }, true},
+		{"unknown group", func(s *Schedule) { s.Target = Target{Type: TargetGroup, ID: "missing"} }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.mutate(&s)
+			_, err := m.CreateSchedule(s)
+			require.Error(t, err)
+			if tt.notFound {
+				assert.True(t, kerrors.IsNotFound(err))
+			} else {
+				assert.True(t, kerrors.IsInvalidInput(err))
+			}
+		})
+	}
+
+	created, err := m.CreateSchedule(valid)
+	require.NoError(t, err)
+	assert.Contains(t, created.ID, "schedule-")
+	assert.False(t, created.NextRun.IsZero())
+}
+
+func TestSchedulePersistence(t *testing.T) {
+	m, lights, cfg := setupTestManager(t)
+
+	created, err := m.CreateSchedule(Schedule{
+		Name:    "evening",
+		Cron:    "0 18 * * 1-5",
+		Target:  Target{Type: TargetLight, ID: "light1"},
+		Action:  Action{On: boolPtr(false), Brightness: intPtr(20)},
+		Enabled: true,
+	})
+	require.NoError(t, err)
+
+	reloaded := NewManager(m.logger, cfg, lights, m.groups)
+	got, err := reloaded.GetSchedule(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "evening", got.Name)
+	assert.Equal(t, "0 18 * * 1-5", got.Cron)
+	require.NotNil(t, got.Action.On)
+	assert.False(t, *got.Action.On)
+	require.NotNil(t, got.Action.Brightness)
+	assert.Equal(t, 20, *got.Action.Brightness)
+
+	require.NoError(t, reloaded.DeleteSchedule(created.ID))
+	assert.Empty(t, reloaded.GetSchedules())
+	assert.True(t, kerrors.IsNotFound(reloaded.DeleteSchedule(created.ID)))
+}
+
+func TestImportSchedules(t *testing.T) {
+	m, lights, cfg := setupTestManager(t)
+
+	// Targets are not looked up, but definitions are validated
+	err := m.ImportSchedules([]Schedule{
+		{ID: "schedule-a", Name: "a", At: "07:00", Target: Target{Type: TargetLight, ID: "elsewhere"}, Action: Action{On: boolPtr(true)}},
+		{Name: "b", Cron: "nope", Target: Target{Type: TargetLight, ID: "light1"}, Action: Action{On: boolPtr(true)}},
+	})
+	assert.True(t, kerrors.IsInvalidInput(err))
+	assert.Empty(t, m.GetSchedules())
+
+	require.NoError(t, m.ImportSchedules([]Schedule{
+		{ID: "schedule-a", Name: "a", At: "07:00", Target: Target{Type: TargetLight, ID: "elsewhere"}, Action: Action{On: boolPtr(true)}, Enabled: true},
+		{Name: "b", Cron: "0 22 * * *", Target: Target{Type: TargetGroup, ID: "Office"}, Action: Action{On: boolPtr(false)}},
+	}))
+	got, err := m.GetSchedule("schedule-a")
+	require.NoError(t, err)
+	assert.Equal(t, "elsewhere", got.Target.ID)
+	assert.False(t, got.NextRun.IsZero())
+
+	reloaded := NewManager(m.logger, cfg, lights, m.groups)
+	assert.Len(t, reloaded.GetSchedules(), 2)
+}
+
+func TestScheduleEvaluate(t *testing.T) {
+	m, lights, _ := setupTestManager(t)
+	ctx := context.Background()
+
+	grp, err := m.groups.CreateGroup(ctx, "office", []string{"light1", "light2"})
+	require.NoError(t, err)
+
+	_, err = m.CreateSchedule(Schedule{
+		Name:    "office on",
+		At:      "09:00",
+		Target:  Target{Type: TargetGroup, ID: grp.Name},
+		Action:  Action{On: boolPtr(true)},
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	_, err = m.CreateSchedule(Schedule{
+		Name:    "disabled",
+		At:      "09:00",
+		Target:  Target{Type: TargetLight, ID: "light1"},
+		Action:  Action{Brightness: intPtr(10)},
+		Enabled: false,
+	})
+	require.NoError(t, err)
+
+	nine := time.Date(2026, time.March, 2, 9, 0, 5, 0, time.Local)
+	m.evaluate(ctx, nine)
+	assert.ElementsMatch(t, []string{"light1:on", "light2:on"}, lights.calls)
+
+	// A second evaluation within the same minute must not fire again.
+	m.evaluate(ctx, nine.Add(30*time.Second))
+	assert.Len(t, lights.calls, 2)
+
+	// Non-matching minute does nothing.
+	m.evaluate(ctx, nine.Add(time.Minute))
+	assert.Len(t, lights.calls, 2)
+}
+
+type fakeScenes struct {
+	mu      sync.Mutex
+	scenes  map[string]bool
+	applied []string
+}
+
+func (f *fakeScenes) ValidateScene(key string) error {
+	if !f.scenes[key] {
+		return kerrors.NotFoundf("scene %s not found", key)
+	}
+	return nil
+}
+
+func (f *fakeScenes) ApplyTo(_ context.Context, key string, scope *Target) (*jobs.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	applied := key
+	if scope != nil {
+		applied += "@" + scope.Type + ":" + scope.ID
+	}
+	f.applied = append(f.applied, applied)
+	return &jobs.Job{}, nil
+}
+
+func TestSceneSchedules(t *testing.T) {
+	m, lights, cfg := setupTestManager(t)
+	ctx := context.Background()
+
+	_, err := m.groups.CreateGroup(ctx, "Studio", []string{"light1"})
+	require.NoError(t, err)
+
+	studio := Schedule{
+		Name:    "studio evening",
+		Cron:    "0 18 * * 1-5",
+		Target:  Target{Type: TargetGroup, ID: "Studio"},
+		Scene:   "Evening warm",
+		Enabled: true,
+	}
+	_, err = m.CreateSchedule(studio)
+	assert.True(t, kerrors.IsInvalidInput(err), "scenes can't be used without a scene applier")
+
+	scenes := &fakeScenes{scenes: map[string]bool{"Evening warm": true}}
+	m.SetSceneApplier(scenes)
+
+	unknown := studio
+	unknown.Scene = "Morning"
+	_, err = m.CreateSchedule(unknown)
+	assert.True(t, kerrors.IsNotFound(err))
+
+	both := studio
+	both.Action = Action{On: boolPtr(true)}
+	_, err = m.CreateSchedule(both)
+	assert.True(t, kerrors.IsInvalidInput(err), "a scene and an action can't be combined")
+
+	partial := studio
+	partial.Target = Target{Type: TargetGroup}
+	_, err = m.CreateSchedule(partial)
+	assert.True(t, kerrors.IsInvalidInput(err))
+
+	created, err := m.CreateSchedule(studio)
+	require.NoError(t, err)
+	whole := studio
+	whole.Name = "everywhere"
+	whole.Target = Target{}
+	_, err = m.CreateSchedule(whole)
+	require.NoError(t, err)
+
+	reloaded := NewManager(m.logger, cfg, lights, m.groups)
+	got, err := reloaded.GetSchedule(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Evening warm", got.Scene)
+	assert.Equal(t, Target{Type: TargetGroup, ID: "Studio"}, got.Target)
+	assert.True(t, got.Action.IsEmpty())
+
+	m.evaluate(ctx, time.Date(2026, time.March, 2, 18, 0, 0, 0, time.Local)) // a Monday
+	assert.ElementsMatch(t, []string{"Evening warm@group:Studio", "Evening warm"}, scenes.applied)
+	assert.Empty(t, lights.calls)
+}
+
+func TestScheduleTimezoneAndSkipDates(t *testing.T) {
+	m, lights, cfg := setupTestManager(t)
+	ctx := context.Background()
+	m.now = func() time.Time { return time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC) }
+
+	evening := Schedule{
+		Name:      "evening",
+		At:        "18:00",
+		Timezone:  "America/New_York",
+		SkipDates: []string{"2026-03-03", " 2026-12-25", "2026-03-03"},
+		Target:    Target{Type: TargetLight, ID: "light1"},
+		Action:    Action{On: boolPtr(false)},
+		Enabled:   true,
+	}
+
+	badZone := evening
+	badZone.Timezone = "Mars/Olympus_Mons"
+	_, err := m.CreateSchedule(badZone)
+	assert.True(t, kerrors.IsInvalidInput(err))
+	badDate := evening
+	badDate.SkipDates = []string{"25/12/2026"}
+	_, err = m.CreateSchedule(badDate)
+	assert.True(t, kerrors.IsInvalidInput(err))
+
+	created, err := m.CreateSchedule(evening)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-03-03", "2026-12-25"}, created.SkipDates)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, time.March, 2, 18, 0, 0, 0, newYork).Equal(created.NextRun))
+
+	// 18:00 in New York is 23:00 UTC; the third is skipped
+	m.evaluate(ctx, time.Date(2026, time.March, 2, 18, 0, 0, 0, time.UTC))
+	assert.Empty(t, lights.calls)
+	m.evaluate(ctx, time.Date(2026, time.March, 2, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, []string{"light1:on"}, lights.calls)
+	m.evaluate(ctx, time.Date(2026, time.March, 3, 23, 0, 0, 0, time.UTC))
+	assert.Len(t, lights.calls, 1)
+
+	_, err = m.CreateSchedule(Schedule{
+		Name:    "morning",
+		At:      "08:00",
+		Target:  Target{Type: TargetLight, ID: "light2"},
+		Action:  Action{On: boolPtr(true)},
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	m.location = time.UTC
+
+	var upcoming []string
+	for _, e := range m.Upcoming(5, 3*24*time.Hour) {
+		upcoming = append(upcoming, e.Name+" "+e.At.UTC().Format("01-02 15:04"))
+	}
+	assert.Equal(t, []string{
+		"evening 03-02 23:00",
+		"morning 03-03 08:00",
+		"morning 03-04 08:00",
+		"evening 03-04 23:00",
+		"morning 03-05 08:00",
+	}, upcoming)
+	assert.Len(t, m.Upcoming(10, 24*time.Hour), 2)
+
+	cfg.Config.Schedules.Timezone = "Europe/London"
+	reloaded := NewManager(m.logger, cfg, lights, m.groups)
+	assert.Equal(t, "Europe/London", reloaded.location.String())
+	got, err := reloaded.GetSchedule(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", got.Timezone)
+	assert.Equal(t, []string{"2026-03-03", "2026-12-25"}, got.SkipDates)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// apiWriteTimeout is how long an HTTP API response may take to write once the
+// request has been handled.
+const apiWriteTimeout = 5 * time.Second
+
+// wsPath is the WebSocket endpoint, which stays open for as long as the
+// client is connected.
+const wsPath = "/api/v1/ws"
+
+// apiRequestTimeout returns how long an HTTP API request or WebSocket command
+// may take, from config.api.request_timeout.
+func (s *Server) apiRequestTimeout() time.Duration {
+	return cmp.Or(time.Duration(s.cfg.Config.API.RequestTimeout)*time.Second, config.DefaultAPIRequestTimeout)
+}
+
+// limitRequest is HTTP middleware that gives each API request
+// config.api.request_timeout to finish. Requests to lights made on its behalf
+// are abandoned when it runs out, or when the client goes away.
+func (s *Server) limitRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, wsPath) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), s.apiRequestTimeout())
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// cancelOnDisconnect cancels a socket request if its client hangs up while
+// it is being handled. The returned function stops watching, after which the
+// connection can be read again; a request the client pipelined meanwhile
+// stays buffered in reader.
+func cancelOnDisconnect(conn net.Conn, reader *bufio.Reader, cancel context.CancelFunc) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Blocks until the client sends more, hangs up or stop is called
+		_, err := reader.Peek(1)
+		var netErr net.Error
+		if err != nil && !(errors.As(err, &netErr) && netErr.Timeout()) {
+			cancel()
+		}
+	}()
+	return func() {
+		_ = conn.SetReadDeadline(time.Now())
+		<-done
+	}
+}
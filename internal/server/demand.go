@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// demandAware is implemented by light managers that slow their refreshes
+// down while no clients are using the daemon.
+type demandAware interface {
+	SetDemand(active func() bool, idleInterval time.Duration)
+}
+
+// statePoller is implemented by light managers that can poll lights for
+// changes made outside keylightd.
+type statePoller interface {
+	PollLights(ctx context.Context, interval time.Duration)
+}
+
+// unattendedPaths are HTTP endpoints polled by monitoring rather than by
+// clients, so requests to them don't keep the daemon active.
+var unattendedPaths = []string{"/healthz", "/readyz", "/metrics", "/api/v1/health"}
+
+// startDemandTracking has the light manager refresh lights at
+// config.discovery.idle_interval while clientsActive reports false.
+func (s *Server) startDemandTracking() {
+	d, ok := s.lights.(demandAware)
+	if !ok {
+		return
+	}
+	s.noteActivity()
+	d.SetDemand(s.clientsActive, time.Duration(s.cfg.Config.Discovery.IdleInterval)*time.Second)
+}
+
+// noteActivity records that a client made a request.
+func (s *Server) noteActivity() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// clientsActive reports whether clients are using the daemon: something is
+// streaming events over the socket, gRPC or WebSocket, or a request arrived
+// within config.discovery.idle_after.
+func (s *Server) clientsActive() bool {
+	if s.subscribers.Load() > 0 || s.grpcStreams.Load() > 0 {
+		return true
+	}
+	if s.wsHub != nil && s.wsHub.ClientCount() > 0 {
+		return true
+	}
+	idleAfter := time.Duration(s.cfg.Config.Discovery.IdleAfter) * time.Second
+	return time.Since(time.Unix(0, s.lastActivity.Load())) < idleAfter
+}
+
+// trackActivity is HTTP middleware that records API requests as client
+// activity.
+func (s *Server) trackActivity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isUnattended(r.URL.Path) {
+			s.noteActivity()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isUnattended reports whether path, which may carry the API base path, is
+// one of unattendedPaths.
+func isUnattended(path string) bool {
+	for _, p := range unattendedPaths {
+		if strings.HasSuffix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// activityOptions returns gRPC server options that record calls as client
+// activity, and count open streams as active clients.
+func (s *Server) activityOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			s.noteActivity()
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			s.grpcStreams.Add(1)
+			defer func() {
+				s.grpcStreams.Add(-1)
+				s.noteActivity()
+			}()
+			return handler(srv, ss)
+		}),
+	}
+}
+
+var (
+	_ demandAware = (*keylight.Manager)(nil)
+	_ statePoller = (*keylight.Manager)(nil)
+)
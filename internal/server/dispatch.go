@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/http/handlers"
+	"github.com/jmylchreest/keylightd/internal/http/mw"
+	"github.com/jmylchreest/keylightd/internal/http/routes"
+)
+
+// apiHandler picks the HTTP API handler for an operation out of the server's
+// handlers.
+type apiHandler[I, O any] func(*routes.Handlers) func(context.Context, *I) (*O, error)
+
+// handlerAction makes a socket action that dispatches to an HTTP API handler,
+// so the action validates its data, behaves and responds exactly as the HTTP
+// operation does rather than reimplementing it. New capabilities should be
+// added to the HTTP API and exposed over the socket this way.
+//
+// The action's data is the operation's input: fields with a path or query
+// tag take the data field of the same name, and the remaining data fields are
+// the request body. They are validated against the same schema as HTTP
+// requests. The response holds the output's body under key, or the body's
+// fields themselves if key is empty.
+func handlerAction[I, O any](key string, handler apiHandler[I, O]) socketActionHandler {
+	decoder := sync.OnceValue(func() *inputDecoder { return newInputDecoder(reflect.TypeFor[I]()) })
+	return func(s *Server, r socketRequest) socketActionResult {
+		var input I
+		if err := decoder().decode(r.data, &input); err != nil {
+			s.sendError(r, err)
+			return socketContinue
+		}
+		output, err := handler(s.api)(r.ctx, &input)
+		if err != nil {
+			s.sendError(r, socketError(err))
+			return socketContinue
+		}
+		response, err := outputResponse(key, output)
+		if err != nil {
+			s.sendError(r, err)
+			return socketContinue
+		}
+		s.sendResponse(r, response)
+		return socketContinue
+	}
+}
+
+// method picks a method of one of the HTTP API's handler interfaces, as in
+// method(sceneHandlers, handlers.SceneHandlers.GetScene).
+func method[H, I, O any](pick func(*routes.Handlers) H, m func(H, context.Context, *I) (*O, error)) apiHandler[I, O] {
+	return func(h *routes.Handlers) func(context.Context, *I) (*O, error) {
+		handler := pick(h)
+		return func(ctx context.Context, input *I) (*O, error) {
+			return m(handler, ctx, input)
+		}
+	}
+}
+
+func sceneHandlers(h *routes.Handlers) handlers.SceneHandlers       { return h.Scene }
+func scheduleHandlers(h *routes.Handlers) handlers.ScheduleHandlers { return h.Schedule }
+func jobHandlers(h *routes.Handlers) handlers.JobHandlers           { return h.Job }
+
+func discoverHandler(h *routes.Handlers) func(context.Context, *handlers.DiscoveryScanInput) (*handlers.DiscoveryScanOutput, error) {
+	return h.Discover
+}
+
+func pendingHandler(h *routes.Handlers) func(context.Context, *handlers.PendingDevicesInput) (*handlers.PendingDevicesOutput, error) {
+	return h.Pending
+}
+
+func adoptHandler(h *routes.Handlers) func(context.Context, *handlers.AdoptDeviceInput) (*handlers.AdoptDeviceOutput, error) {
+	return h.Adopt
+}
+
+// inputDecoder decodes socket action data into an HTTP operation's input.
+type inputDecoder struct {
+	registry huma.Registry
+	schema   *huma.Schema
+	params   map[string]int // data field name to index of the input field
+	body     int            // index of the input's Body field, or -1
+}
+
+// newInputDecoder builds the decoder for the input type t, whose schema
+// combines its path and query parameters with the properties of its body.
+func newInputDecoder(t reflect.Type) *inputDecoder {
+	d := &inputDecoder{
+		registry: huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer),
+		params:   map[string]int{},
+		body:     -1,
+	}
+	d.schema = &huma.Schema{Type: huma.TypeObject, Properties: map[string]*huma.Schema{}}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, required := f.Tag.Get("path"), true
+		if name == "" {
+			name, required = f.Tag.Get("query"), false
+		}
+		name, _, _ = strings.Cut(name, ",")
+		switch {
+		case name != "":
+			d.params[name] = i
+			d.schema.Properties[name] = huma.SchemaFromField(d.registry, f, t.Name()+f.Name)
+			if required {
+				d.schema.Required = append(d.schema.Required, name)
+			}
+		case f.Name == "Body":
+			if f.Type.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("%s: socket actions need an object body", t))
+			}
+			d.body = i
+			body := d.registry.Schema(f.Type, false, t.Name()+"Body")
+			maps.Copy(d.schema.Properties, body.Properties)
+			d.schema.Required = append(d.schema.Required, body.Required...)
+			d.schema.AdditionalProperties = body.AdditionalProperties
+		}
+	}
+	d.schema.PrecomputeMessages()
+	return d
+}
+
+// decode validates data and decodes it into input, a pointer to the input
+// type the decoder was built for.
+func (d *inputDecoder) decode(data map[string]any, input any) error {
+	if data == nil {
+		data = map[string]any{}
+	}
+	res := &huma.ValidateResult{}
+	huma.Validate(d.registry, d.schema, huma.NewPathBuffer(nil, 0), huma.ModeWriteToServer, data, res)
+	if len(res.Errors) > 0 {
+		problems := make([]string, len(res.Errors))
+		for i, err := range res.Errors {
+			problems[i] = err.Error()
+			var detail *huma.ErrorDetail
+			if errors.As(err, &detail) && detail.Location != "" {
+				problems[i] = detail.Location + ": " + detail.Message
+			}
+		}
+		return kerrors.Errorf(kerrors.CodeInvalidInput, "invalid data: %s", strings.Join(problems, "; "))
+	}
+
+	v := reflect.ValueOf(input).Elem()
+	body := maps.Clone(data)
+	for name, i := range d.params {
+		delete(body, name)
+		if value, ok := data[name]; ok {
+			if err := convert(value, v.Field(i).Addr().Interface()); err != nil {
+				return kerrors.Errorf(kerrors.CodeInvalidInput, "invalid %s: %s", name, err)
+			}
+		}
+	}
+	if d.body >= 0 {
+		if err := convert(body, v.Field(d.body).Addr().Interface()); err != nil {
+			return kerrors.Errorf(kerrors.CodeInvalidInput, "invalid data: %s", err)
+		}
+	}
+	return nil
+}
+
+// convert copies the decoded JSON value into the value target points to.
+func convert(value, target any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
+// outputResponse returns the socket response fields for an HTTP operation's
+// output: its body under key, or the body's own fields if key is empty.
+// Outputs without a body add nothing to the response.
+func outputResponse(key string, output any) (map[string]any, error) {
+	v := reflect.ValueOf(output)
+	if v.IsNil() {
+		return nil, nil
+	}
+	body := v.Elem().FieldByName("Body")
+	if !body.IsValid() {
+		return nil, nil
+	}
+	if key != "" {
+		return map[string]any{key: body.Interface()}, nil
+	}
+	var fields map[string]any
+	if err := convert(body.Interface(), &fields); err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return fields, nil
+}
+
+// socketError converts an error returned by an HTTP API handler into the
+// shared error taxonomy, so socket clients get the code, message and details
+// that HTTP clients do.
+func socketError(err error) error {
+	var model *mw.ErrorModel
+	if errors.As(err, &model) {
+		return &kerrors.Error{Code: model.Code, Message: model.Detail, Details: model.Details}
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/http/handlers"
+)
+
+func TestHandlerAction_ValidatesLikeHTTP(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	// Rules from the HTTP API's schema, which the socket used not to check
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "create_scene",
+		"data":   map[string]any{"name": "empty", "entries": []any{}},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+	assert.Contains(t, resp["error"], "entries")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "create_scene",
+		"data": map[string]any{
+			"name":    "lamp",
+			"entries": []any{map[string]any{"target": map[string]any{"type": "lamp", "id": "light-1"}}},
+		},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+	assert.Contains(t, resp["error"], "entries[0].target.type")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "get_schedule"})
+	assert.Equal(t, "invalid_input", resp["code"], "the path parameter is required")
+	assert.Contains(t, resp["error"], "id")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_scene",
+		"data":   map[string]any{"id": "missing"},
+	})
+	assert.Equal(t, "not_found", resp["code"], "handler errors keep their code")
+	assert.Contains(t, resp["error"], "Failed to get scene")
+}
+
+func TestHandlerAction_FlattensBody(t *testing.T) {
+	_, socketPath := setupSocketTestWith(t, &scanningLightManager{mockLightManager: &mockLightManager{}})
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "discover_now"})
+	assert.Equal(t, "ok", resp["status"])
+	found, ok := resp["found"].([]any)
+	require.True(t, ok)
+	require.Len(t, found, 1)
+	assert.Equal(t, "light-1", found[0].(map[string]any)["id"])
+	assert.InDelta(t, 1, resp["lights"], 0)
+	assert.Contains(t, resp, "duration_ms")
+}
+
+func TestInputDecoder(t *testing.T) {
+	d := newInputDecoder(reflect.TypeFor[handlers.UpdateScheduleInput]())
+	var input handlers.UpdateScheduleInput
+	require.NoError(t, d.decode(map[string]any{
+		"id":     "abc",
+		"name":   "evening",
+		"at":     "18:00",
+		"target": map[string]any{"type": "group", "id": "office"},
+		"action": map[string]any{"on": false},
+	}, &input))
+	assert.Equal(t, "abc", input.ID)
+	assert.Equal(t, "evening", input.Body.Name)
+	assert.Equal(t, "office", input.Body.Target.ID)
+	require.NotNil(t, input.Body.Action.On)
+	assert.False(t, *input.Body.Action.On)
+
+	upcoming := newInputDecoder(reflect.TypeFor[handlers.ListUpcomingSchedulesInput]())
+	var list handlers.ListUpcomingSchedulesInput
+	require.NoError(t, upcoming.decode(map[string]any{"limit": float64(5)}, &list))
+	assert.Equal(t, 5, list.Limit)
+	assert.Error(t, upcoming.decode(map[string]any{"days": float64(400)}, &list))
+	assert.Error(t, upcoming.decode(map[string]any{"limit": "five"}, &list))
+}
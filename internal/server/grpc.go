@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/jmylchreest/keylightd/internal/grpcapi"
+	"github.com/jmylchreest/keylightd/internal/http/mw"
+)
+
+// http2Preface is how every gRPC connection starts. JSON requests on the
+// socket always start with '{', so the two protocols can share the socket.
+const http2Preface = "PRI * HTTP/2.0"
+
+// isGRPC reports whether a socket connection is a gRPC client, without
+// consuming any of its data.
+func isGRPC(reader *bufio.Reader) bool {
+	// Check the first byte before waiting for the whole preface, so a JSON
+	// request shorter than the preface isn't held up
+	if first, err := reader.Peek(1); err != nil || first[0] != http2Preface[0] {
+		return false
+	}
+	prefix, _ := reader.Peek(len(http2Preface))
+	return string(prefix) == http2Preface
+}
+
+// bufferedConn is a connection whose first bytes have already been read into
+// a buffer.
+type bufferedConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// connListener hands connections accepted elsewhere to a gRPC server.
+type connListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{addr: addr, conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+// serve passes a connection to the gRPC server, or closes it if the server
+// has stopped.
+func (l *connListener) serve(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		_ = conn.Close()
+	}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *connListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.addr
+}
+
+// startGRPC serves the gRPC API on the Unix socket, and over TCP when
+// config.grpc.listen_address is set. TCP callers need an API key, and the
+// connection uses the api.tls certificate when one is configured.
+func (s *Server) startGRPC() error {
+	services := grpcapi.Services{Lights: s.lights, Groups: s.groups, APIKeys: s.apikeyManager, Events: s.eventBus}
+
+	s.grpcConns = newConnListener(s.listener.Addr())
+	s.grpcServer = grpcapi.NewServer(s.logger, services, s.activityOptions()...)
+	s.wg.Go(func() {
+		defer s.recoverPanic("gRPC server")
+		if err := s.grpcServer.Serve(s.grpcConns); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("gRPC server failed", "error", err)
+		}
+	})
+
+	addr := s.cfg.Config.GRPC.ListenAddress
+	if addr == "" {
+		return nil
+	}
+
+	opts := append(grpcapi.AuthOptions(s.logger, s.apikeyManager), s.activityOptions()...)
+	if tlsCfg := s.cfg.Config.API.TLS; tlsCfg.Enabled() {
+		tlsConfig, err := mw.ServerTLSConfig(tlsCfg)
+		if err != nil {
+			return fmt.Errorf("failed to configure gRPC TLS: %w", err)
+		}
+		cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	listener, err := (&net.ListenConfig{}).Listen(s.rootCtx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %w", addr, err)
+	}
+	s.logger.Info("Starting gRPC API server", "address", listener.Addr().String())
+	s.grpcTCPServer = grpcapi.NewServer(s.logger, services, opts...)
+	s.wg.Go(func() {
+		defer s.recoverPanic("gRPC TCP server")
+		if err := s.grpcTCPServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("gRPC TCP server failed", "error", err)
+		}
+		s.logger.Info("gRPC TCP server stopped")
+	})
+	return nil
+}
+
+// stopGRPC stops the gRPC servers, closing open calls and event streams.
+func (s *Server) stopGRPC() {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+	if s.grpcTCPServer != nil {
+		s.logger.Info("Shutting down gRPC TCP server")
+		s.grpcTCPServer.Stop()
+	}
+}
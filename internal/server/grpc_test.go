@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	keylightdv1 "github.com/jmylchreest/keylightd/pkg/proto/keylightd/v1"
+)
+
+// TestGRPCOverSocket checks that gRPC and JSON clients can share the socket.
+func TestGRPCOverSocket(t *testing.T) {
+	server, _, socketPath := setupIntegrationTest(t)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Stop() })
+
+	cc, err := grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := keylightdv1.NewLightServiceClient(cc).ListLights(ctx, &keylightdv1.ListLightsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetLights(), 1)
+	light := resp.GetLights()[0]
+	assert.Equal(t, "test-light-1", light.GetId())
+	assert.Equal(t, int32(50), light.GetBrightness())
+	assert.Equal(t, int32(5000), light.GetTemperature())
+
+	_, err = keylightdv1.NewLightServiceClient(cc).GetLight(ctx, &keylightdv1.GetLightRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// JSON clients are unaffected
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, json.NewEncoder(conn).Encode(map[string]string{"action": "ping"}))
+	var pong map[string]any
+	require.NoError(t, json.NewDecoder(conn).Decode(&pong))
+	assert.Equal(t, "pong", pong["message"])
+}
+
+func TestGRPCOverTCP_RequiresAPIKey(t *testing.T) {
+	server, apiKey, _ := setupHTTPIntegrationTest(t)
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+	server.cfg.Config.GRPC.ListenAddress = addr
+
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Stop() })
+
+	cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+	client := keylightdv1.NewGroupServiceClient(cc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.ListGroups(ctx, &keylightdv1.ListGroupsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.ListGroups(metadata.AppendToOutgoingContext(ctx, "x-api-key", "wrong"), &keylightdv1.ListGroupsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+apiKey)
+	created, err := client.CreateGroup(authCtx, &keylightdv1.CreateGroupRequest{Name: "desk", LightIds: []string{"test-light-1"}})
+	require.NoError(t, err)
+	groups, err := client.ListGroups(authCtx, &keylightdv1.ListGroupsRequest{})
+	require.NoError(t, err)
+	require.Len(t, groups.GetGroups(), 1)
+	assert.Equal(t, created.GetGroup().GetId(), groups.GetGroups()[0].GetId())
+}
@@ -30,8 +30,9 @@ func setupIntegrationTest(t *testing.T) (*Server, *config.Config, string) {
 	// Setup socket path in temp dir
 	socketPath := filepath.Join(tempDir, "keylightd.sock")
 
-	// Create a minimal config
-	v := config.New(nil)
+	// Create a minimal config, backed by a file so state can be saved
+	v, err := config.Load("config", filepath.Join(tempDir, "config.yaml"))
+	require.NoError(t, err)
 	v.Config.Server.UnixSocket = socketPath
 	v.Config.API.ListenAddress = "127.0.0.1:0" // Use random available port
 	v.Config.Logging.Level = "debug"
@@ -274,6 +275,129 @@ func TestHTTPCommunication(t *testing.T) {
 	})
 }
 
+// TestHTTPBasePath tests that every route moves under api.base_path
+func TestHTTPBasePath(t *testing.T) {
+	server, apiKey, baseURL := setupHTTPIntegrationTest(t)
+	server.cfg.Config.API.BasePath = "/keylight/"
+
+	err := server.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Stop() })
+
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	get := func(path string) int {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, baseURL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, get("/keylight/api/v1/lights"))
+	assert.Equal(t, http.StatusOK, get("/keylight/healthz"))
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/lights"))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, baseURL+"/keylight/openapi.json", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var spec struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	require.Len(t, spec.Servers, 1)
+	assert.Equal(t, "/keylight", spec.Servers[0].URL)
+}
+
+func TestHTTPListeners(t *testing.T) {
+	server, _, baseURL := setupHTTPIntegrationTest(t)
+	apiSocket := filepath.Join(filepath.Dir(server.socketPath), "api.sock")
+	server.cfg.Config.API.ListenAddresses = []config.APIListener{
+		{Address: strings.TrimPrefix(baseURL, "http://")},
+		{Address: "unix://" + apiSocket, Auth: config.ListenerAuthNone},
+	}
+
+	err := server.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Stop() })
+
+	time.Sleep(100 * time.Millisecond)
+
+	get := func(client *http.Client, url string) int {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tcp := &http.Client{Timeout: 5 * time.Second}
+	assert.Equal(t, http.StatusUnauthorized, get(tcp, baseURL+"/api/v1/lights"))
+
+	unix := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", apiSocket)
+		},
+	}}
+	assert.Equal(t, http.StatusOK, get(unix, "http://keylightd/api/v1/lights"), "the unix listener needs no API key")
+
+	fi, err := os.Stat(apiSocket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	info := server.daemonInfo()
+	assert.Equal(t, strings.TrimPrefix(baseURL, "http://"), info.APIListenAddress)
+	assert.Len(t, info.APIListenAddresses, 2)
+}
+
+// TestHTTPSetGroupState tests that group state changes are served by the
+// registered API operation, with its authentication and group selectors.
+func TestHTTPSetGroupState(t *testing.T) {
+	server, apiKey, baseURL := setupHTTPIntegrationTest(t)
+	_, err := server.groups.CreateGroup(context.Background(), "office-desk", []string{"test-light-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Stop() })
+
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	put := func(id, key string) (int, map[string]any) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, baseURL+"/api/v1/groups/"+id+"/state", strings.NewReader(`{"on": true}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var result map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	status, result := put("office-*", apiKey)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", result["status"])
+
+	status, result = put("no-such-group", apiKey)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "not_found", result["code"])
+
+	status, _ = put("office-*", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
 // TestHTTPSetLightState tests setting light state via HTTP
 func TestHTTPSetLightState(t *testing.T) {
 	server, apiKey, baseURL := setupHTTPIntegrationTest(t)
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"slices"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// SocketAction describes a socket action: what it does and the data fields it
+// reads.
+type SocketAction struct {
+	Name      string   `json:"name"`
+	Summary   string   `json:"summary"`
+	Required  []string `json:"required,omitempty"`
+	Optional  []string `json:"optional,omitempty"`
+	Operation string   `json:"operation,omitempty"` // ID of the HTTP API operation offering the same capability
+	WebSocket bool     `json:"websocket,omitempty"` // also accepted as a WebSocket command
+	Streaming bool     `json:"streaming,omitempty"` // the connection switches to streaming events
+}
+
+// SocketProtocol is a machine-readable description of the socket protocol,
+// generated by keylight-openapi for client authors.
+type SocketProtocol struct {
+	ProtocolVersion int            `json:"protocol_version"`
+	Features        []string       `json:"features"`
+	ErrorCodes      []kerrors.Code `json:"error_codes"`
+	Actions         []SocketAction `json:"actions"`
+}
+
+// lightStateFields are the data fields of set_light_state and set_group_state
+// besides the ID.
+var lightStateFields = []string{
+	"property", "value", "on", "brightness", "temperature", "units", "hue", "saturation", "transition_ms", "state_version",
+}
+
+// socketActionDocs describes each action in socketActions, in the order of
+// the socket API documentation. Each action names the HTTP API operation
+// offering the same capability, so both transports stay in step: a capability
+// added to one without the other fails TestProtocol_MatchesHTTPAPI unless it is
+// listed in socketOnlyActions or httpOnlyOperations. The scene, schedule, job
+// and discovery actions go further and run the operation's own handler through
+// handlerAction.
+var socketActionDocs = []SocketAction{
+	{Name: "hello", Summary: "Negotiate the protocol version and list supported actions and features", Optional: []string{"protocol_version"}},
+	{Name: "ping", Summary: "Check the connection"},
+	{Name: "health", Summary: "Check the daemon is healthy", Operation: "healthCheck"},
+	{Name: "version", Summary: "Get the daemon's version", Operation: "getVersion"},
+	{Name: "get_daemon_info", Summary: "Get the daemon's uptime and discovery statistics", Operation: "getDaemonInfo"},
+	{Name: "shutdown", Summary: "Stop the daemon, as a new one started with --takeover does"},
+	{Name: "discover_now", Summary: "Run a discovery pass now and return the lights it found", Operation: "discoverLights"},
+	{Name: "list_pending_devices", Summary: "List devices discovery found that are not supported lights", Operation: "listPendingDevices"},
+	{Name: "adopt_device", Summary: "Add a pending device as a light", Required: []string{"id"}, Operation: "adoptDevice"},
+
+	{Name: "list_lights", Summary: "List all lights", Operation: "listLights"},
+	{Name: "get_light", Summary: "Get a light", Required: []string{"id"}, Operation: "getLight"},
+	{Name: "set_light_state", Summary: "Set properties on a light, or on every light selected by \"all\", a glob pattern such as Desk* or a tag selector such as tag:side=left", Required: []string{"id"}, Optional: lightStateFields, Operation: "setLightState"},
+	{Name: "set_lights_state", Summary: "Set properties on several lights at once", Required: []string{"lights"}, Optional: []string{"units"}, Operation: "setLightsState"},
+	{Name: "toggle_light", Summary: "Invert a light's power state", Required: []string{"id"}, Operation: "toggleLight"},
+	{Name: "set_light_name", Summary: "Set or clear a light's display name", Required: []string{"id", "name"}, Operation: "setLightName"},
+	{Name: "set_light_tags", Summary: "Replace a light's tags", Required: []string{"id"}, Optional: []string{"tags"}, Operation: "setLightTags"},
+	{Name: "set_device_name", Summary: "Rename the physical light", Required: []string{"id", "name"}, Operation: "setDeviceName"},
+	{Name: "get_light_settings", Summary: "Get the power-on settings stored on a light", Required: []string{"id"}, Operation: "getLightSettings"},
+	{Name: "set_light_settings", Summary: "Change the power-on settings stored on a light", Required: []string{"id"}, Optional: []string{"power_on_behavior", "power_on_brightness", "power_on_temperature"}, Operation: "setLightSettings"},
+	{Name: "get_light_stats", Summary: "Get a light's usage statistics", Required: []string{"id"}, Operation: "getLightStats"},
+	{Name: "raw_request", Summary: "Pass a request through to a light's own API", Required: []string{"id", "method", "path"}, Optional: []string{"body"}, Operation: "rawLightRequest"},
+
+	{Name: "list_groups", Summary: "List all groups", Operation: "listGroups"},
+	{Name: "get_group", Summary: "Get a group", Required: []string{"id"}, Operation: "getGroup"},
+	{Name: "get_group_state", Summary: "Get the aggregate state of a group's lights", Required: []string{"id"}, Operation: "getGroupState"},
+	{Name: "update_group", Summary: "Rename a group or change its description, icon or color", Required: []string{"id"}, Optional: []string{"name", "description", "icon", "color"}, Operation: "updateGroup"},
+	{Name: "create_group", Summary: "Create a group", Required: []string{"name"}, Optional: []string{"lights"}, Operation: "createGroup"},
+	{Name: "delete_group", Summary: "Delete a group", Required: []string{"id"}, Operation: "deleteGroup"},
+	{Name: "set_group_lights", Summary: "Replace a group's lights", Required: []string{"id", "lights"}, Operation: "setGroupLights"},
+	{Name: "set_group_state", Summary: "Set properties on the lights of one or more groups, by ID, name, \"all\" or a glob pattern such as office-*", Required: []string{"id"}, Optional: lightStateFields, Operation: "setGroupState"},
+	{Name: "get_group_stats", Summary: "Get the combined usage statistics of a group's lights", Required: []string{"id"}, Operation: "getGroupStats"},
+	{Name: "toggle_group", Summary: "Toggle one or more groups on or off", Required: []string{"id"}, Operation: "toggleGroup"},
+	{Name: "set_group_defaults", Summary: "Set the state applied to lights when they join a group", Required: []string{"id"}, Optional: []string{"on", "brightness", "temperature", "apply_on_join"}, Operation: "setGroupDefaults"},
+
+	{Name: "apikey_add", Summary: "Create an API key", Required: []string{"name"}, Optional: []string{"expires_in"}, Operation: "createApiKey"},
+	{Name: "apikey_list", Summary: "List API keys", Operation: "listApiKeys"},
+	{Name: "apikey_delete", Summary: "Delete an API key", Required: []string{"key"}, Operation: "deleteApiKey"},
+	{Name: "apikey_set_disabled_status", Summary: "Enable or disable an API key", Required: []string{"key_or_name", "disabled"}, Operation: "setApiKeyDisabled"},
+	{Name: "apikey_bootstrap", Summary: "Issue a read and control API key for a local UI", Required: []string{"client"}},
+
+	{Name: "list_schedules", Summary: "List schedules", Operation: "listSchedules"},
+	{Name: "get_schedule", Summary: "Get a schedule", Required: []string{"id"}, Operation: "getSchedule"},
+	{Name: "list_upcoming_schedules", Summary: "List when enabled schedules next fire, soonest first", Optional: []string{"limit", "days"}, Operation: "listUpcomingSchedules"},
+	{Name: "create_schedule", Summary: "Create a schedule that applies an action or a scene", Required: []string{"name"}, Optional: []string{"id", "at", "cron", "target", "action", "scene", "timezone", "skip_dates", "enabled"}, Operation: "createSchedule"},
+	{Name: "update_schedule", Summary: "Replace a schedule", Required: []string{"id", "name"}, Optional: []string{"at", "cron", "target", "action", "scene", "timezone", "skip_dates", "enabled"}, Operation: "updateSchedule"},
+	{Name: "delete_schedule", Summary: "Delete a schedule", Required: []string{"id"}, Operation: "deleteSchedule"},
+
+	{Name: "list_scenes", Summary: "List scenes", Operation: "listScenes"},
+	{Name: "get_scene", Summary: "Get a scene by ID or name", Required: []string{"id"}, Operation: "getScene"},
+	{Name: "create_scene", Summary: "Create a scene", Required: []string{"name", "entries"}, Operation: "createScene"},
+	{Name: "update_scene", Summary: "Replace a scene", Required: []string{"id", "name", "entries"}, Operation: "updateScene"},
+	{Name: "delete_scene", Summary: "Delete a scene", Required: []string{"id"}, Operation: "deleteScene"},
+	{Name: "apply_scene", Summary: "Start applying a scene as a job", Required: []string{"id"}, Operation: "applyScene"},
+	{Name: "get_scene_run", Summary: "Get the job of a scene's latest run", Required: []string{"id"}, Operation: "getSceneRun"},
+	{Name: "cancel_scene_run", Summary: "Stop applying a scene", Required: []string{"id"}, Operation: "cancelSceneRun"},
+	{Name: "list_jobs", Summary: "List running and recently finished jobs", Operation: "listJobs"},
+	{Name: "get_job", Summary: "Get a job by ID", Required: []string{"id"}, Operation: "getJob"},
+	{Name: "cancel_job", Summary: "Cancel a running job", Required: []string{"id"}, Operation: "cancelJob"},
+
+	{Name: "get_circadian", Summary: "Get the state of circadian mode", Operation: "getCircadian"},
+	{Name: "set_circadian", Summary: "Turn circadian mode on or off", Required: []string{"enabled"}, Operation: "setCircadian"},
+	{Name: "get_desired_state", Summary: "Get the state of desired-state mode and its targets", Operation: "getDesiredState"},
+	{Name: "set_desired_state", Summary: "Turn desired-state mode on or off", Required: []string{"enabled"}, Operation: "setDesiredState"},
+	{Name: "set_desired_state_target", Summary: "Enable or disable enforcing one desired-state target", Required: []string{"target", "enabled"}, Operation: "setDesiredStateTarget"},
+	{Name: "get_webcam", Summary: "Get the state of webcam automation", Operation: "getWebcam"},
+	{Name: "set_webcam_override", Summary: "Force webcam groups on or off, or back to auto", Required: []string{"override"}, Operation: "setWebcamOverride"},
+
+	{Name: "export_config", Summary: "Export groups, schedules, scenes, API keys and light names", Optional: []string{"include_secrets"}, Operation: "exportConfig"},
+	{Name: "import_config", Summary: "Import an exported document", Required: []string{"document"}, Operation: "importConfig"},
+
+	{Name: "get_level", Summary: "Get the log level", Operation: "getLogLevel"},
+	{Name: "set_level", Summary: "Set the log level", Required: []string{"level"}, Operation: "setLogLevel"},
+	{Name: "list_filters", Summary: "List log filters", Operation: "listLogFilters"},
+	{Name: "set_filters", Summary: "Replace the log filters", Required: []string{"filters"}, Operation: "setLogFilters"},
+	{Name: "add_filter", Summary: "Add a log filter", Required: []string{"type", "pattern", "level"}, Optional: []string{"output_level", "expires_at", "enabled"}, Operation: "addLogFilter"},
+	{Name: "remove_filter", Summary: "Remove a log filter", Required: []string{"type", "pattern"}, Operation: "deleteLogFilter"},
+	{Name: "get_logs", Summary: "Get recent log lines, optionally only those at or above a level or since a time", Optional: []string{"level", "since", "limit"}, Operation: "getLogs"},
+	{Name: "follow_logs", Summary: "Stream recent log lines, then new ones as they are logged, on this connection", Optional: []string{"level", "since", "limit"}, Streaming: true},
+
+	{Name: "subscribe_events", Summary: "Stream events on this connection, optionally only those of some types or about some lights or groups", Optional: []string{"types", "lights", "groups"}, Streaming: true},
+}
+
+// secretActions carry API keys in their data. It is left out of the debug
+// log, whose recent lines anyone with the read scope can fetch.
+var secretActions = []string{"apikey_delete", "apikey_set_disabled_status", "import_config"}
+
+// loggedData returns a request's data as it may be logged.
+func loggedData(r socketRequest) any {
+	if slices.Contains(secretActions, r.action) {
+		return "[redacted]"
+	}
+	return r.data
+}
+
+// socketOnlyActions are the socket actions with no HTTP API operation: those
+// managing the connection itself, the key bootstrap that HTTP clients need to
+// have been through already, the event stream, which HTTP clients get from
+// the WebSocket API, the log stream, which HTTP clients can poll for, and
+// shutdown, which is only for local users.
+var socketOnlyActions = []string{"hello", "ping", "apikey_bootstrap", "subscribe_events", "follow_logs", "shutdown"}
+
+// httpOnlyOperations are the HTTP API operations with no socket action. The
+// Stream Deck operations wrap light and group actions for plugins that can
+// only speak HTTP.
+var httpOnlyOperations = []string{"getStreamDeckState", "streamDeckAction"}
+
+// Protocol describes the socket protocol spoken by this build.
+func Protocol() SocketProtocol {
+	actions := slices.Clone(socketActionDocs)
+	for i := range actions {
+		actions[i].WebSocket = slices.Contains(wsCommandActions, actions[i].Name)
+	}
+	return SocketProtocol{
+		ProtocolVersion: socketProtocolVersion,
+		Features:        slices.Clone(socketFeatures),
+		ErrorCodes:      kerrors.Codes(),
+		Actions:         actions,
+	}
+}
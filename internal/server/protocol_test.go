@@ -0,0 +1,67 @@
+package server
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jmylchreest/keylightd/internal/http/routes"
+)
+
+func TestProtocol_DescribesEveryAction(t *testing.T) {
+	proto := Protocol()
+
+	var names []string
+	for _, action := range proto.Actions {
+		assert.NotEmpty(t, action.Summary, action.Name)
+		names = append(names, action.Name)
+	}
+	slices.Sort(names)
+	assert.Equal(t, slices.Sorted(maps.Keys(socketActions)), names)
+
+	for _, action := range proto.Actions {
+		assert.Equal(t, slices.Contains(wsCommandActions, action.Name), action.WebSocket, action.Name)
+	}
+	assert.Equal(t, socketProtocolVersion, proto.ProtocolVersion)
+}
+
+func TestProtocol_MatchesHTTPAPI(t *testing.T) {
+	spec := routes.StubSpec("test", "")
+	operations := map[string]bool{}
+	for _, item := range spec.Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Patch, item.Delete} {
+			if op != nil && op.OperationID != "" {
+				operations[op.OperationID] = false
+			}
+		}
+	}
+
+	for _, action := range Protocol().Actions {
+		if slices.Contains(socketOnlyActions, action.Name) {
+			assert.Empty(t, action.Operation, "%s is listed as socket-only", action.Name)
+			continue
+		}
+		if assert.NotEmpty(t, action.Operation, "%s has no HTTP operation; add one or list it in socketOnlyActions", action.Name) {
+			_, ok := operations[action.Operation]
+			assert.True(t, ok, "%s names unknown HTTP operation %s", action.Name, action.Operation)
+			operations[action.Operation] = true
+		}
+	}
+
+	for id, covered := range operations {
+		assert.Equal(t, !slices.Contains(httpOnlyOperations, id), covered,
+			"HTTP operation %s has no socket action; add one or list it in httpOnlyOperations", id)
+	}
+}
+
+func TestLoggedData_RedactsKeys(t *testing.T) {
+	for _, action := range secretActions {
+		r := socketRequest{action: action, data: map[string]any{"key": "secret"}}
+		assert.Equal(t, "[redacted]", loggedData(r), action)
+	}
+	r := socketRequest{action: "set_light_state", data: map[string]any{"id": "light-1"}}
+	assert.Equal(t, r.data, loggedData(r))
+}
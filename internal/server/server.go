@@ -2,36 +2,60 @@ package server
 
 import (
 	"bufio"
+	"cmp"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"maps"
+	"math"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
 
 	logfilter "github.com/jmylchreest/slog-logfilter"
 
+	"github.com/jmylchreest/keylightd/internal/advertise"
 	"github.com/jmylchreest/keylightd/internal/apikey"
+	"github.com/jmylchreest/keylightd/internal/backup"
+	"github.com/jmylchreest/keylightd/internal/circadian"
 	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/crash"
+	"github.com/jmylchreest/keylightd/internal/desiredstate"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
 	"github.com/jmylchreest/keylightd/internal/events"
 	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/homekit"
+	"github.com/jmylchreest/keylightd/internal/hooks"
 	"github.com/jmylchreest/keylightd/internal/http/handlers"
 	"github.com/jmylchreest/keylightd/internal/http/mw"
 	"github.com/jmylchreest/keylightd/internal/http/routes"
+	"github.com/jmylchreest/keylightd/internal/jobs"
 	"github.com/jmylchreest/keylightd/internal/logging"
+	"github.com/jmylchreest/keylightd/internal/metrics"
+	"github.com/jmylchreest/keylightd/internal/mqtt"
+	"github.com/jmylchreest/keylightd/internal/requestid"
+	"github.com/jmylchreest/keylightd/internal/scene"
+	"github.com/jmylchreest/keylightd/internal/schedule"
+	"github.com/jmylchreest/keylightd/internal/stats"
+	"github.com/jmylchreest/keylightd/internal/systemd"
 	"github.com/jmylchreest/keylightd/internal/utils"
+	"github.com/jmylchreest/keylightd/internal/webcam"
 	"github.com/jmylchreest/keylightd/internal/ws"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
@@ -49,16 +73,41 @@ type Server struct {
 	cfg           *config.Config
 	lights        keylight.LightManager
 	groups        *group.Manager
+	schedules     *schedule.Manager
+	scenes        *scene.Manager
+	jobs          *jobs.Manager
+	circadian     *circadian.Manager
+	desired       *desiredstate.Manager
+	stats         *stats.Tracker
+	webcam        *webcam.Watcher
+	backup        *backup.Service
 	socketPath    string
 	listener      net.Listener
+	listening     atomic.Bool // set once the Unix socket is accepting connections
 	shutdown      chan struct{}
+	stopRequested chan struct{} // closed by the shutdown socket action
+	stopOnce      sync.Once
 	wg            sync.WaitGroup
 	apikeyManager *apikey.Manager
 	rootCtx       context.Context
 	rootCancel    context.CancelFunc
-	httpServer    *http.Server
+	httpServers   []*http.Server // one per API listener
+	accessLog     *mw.AccessLog  // nil unless config.api.access_log.enabled
+	grpcServer    *grpc.Server   // gRPC on the Unix socket
+	grpcConns     *connListener  // socket connections handed to grpcServer
+	grpcTCPServer *grpc.Server   // nil unless config.grpc.listen_address is set
 	eventBus      *events.Bus
+	metrics       *metrics.Metrics // nil unless config.api.metrics_enabled
+	mqttBridge    *mqtt.Bridge     // nil unless config.mqtt.broker is set
 	versionInfo   VersionInfo
+	startedAt     time.Time
+	wsHub         *ws.Hub      // nil unless the HTTP API is enabled
+	socketClients atomic.Int64 // open Unix socket connections, excluding gRPC
+	subscribers   atomic.Int64 // socket connections streaming events
+	grpcStreams   atomic.Int64 // open gRPC streams, see activityOptions
+	lastActivity  atomic.Int64 // UnixNano of the last client request, see clientsActive
+	logBuffer     *logging.Buffer
+	api           *routes.Handlers // HTTP API handlers, which socket actions also dispatch to
 }
 
 // New creates a new server instance.
@@ -70,23 +119,137 @@ func New(logger *slog.Logger, cfg *config.Config, lightManager keylight.LightMan
 	// Wire the event bus into managers so they emit state change events.
 	if lm, ok := lightManager.(*keylight.Manager); ok {
 		lm.SetEventBus(eventBus)
+		lm.SetLightAddedHandler(groupManager.HandleLightAdded)
+		lm.SetLimitsResolver(groupManager.LightLimits)
+		lm.SetOfflineRetention(time.Duration(cfg.Config.Discovery.OfflineRetention) * time.Second)
+		retry := cfg.Config.Lights.Retry
+		lm.SetRetryPolicy(keylight.RetryPolicy{
+			Attempts:         retry.Attempts,
+			Backoff:          time.Duration(retry.BackoffMS) * time.Millisecond,
+			MaxBackoff:       time.Duration(retry.MaxBackoffMS) * time.Millisecond,
+			Timeout:          time.Duration(retry.TimeoutMS) * time.Millisecond,
+			BreakerThreshold: retry.BreakerThreshold,
+			BreakerCooldown:  time.Duration(retry.BreakerCooldown) * time.Second,
+		})
+		lm.SetDebounceWindow(time.Duration(cfg.Config.Lights.DebounceMS) * time.Millisecond)
+		lm.SetDeviceTimeout(time.Duration(cfg.Config.Lights.TimeoutMS) * time.Millisecond)
+		lm.SetDeviceLogging(cfg.Config.Logging.DeviceRequests)
+		overrides := make(map[string]keylight.LightOverride, len(cfg.Config.Lights.Overrides))
+		for _, o := range cfg.Config.Lights.Overrides {
+			overrides[o.ID] = keylight.LightOverride{
+				Host:    o.Host,
+				Port:    o.Port,
+				Timeout: time.Duration(o.TimeoutMS) * time.Millisecond,
+			}
+		}
+		lm.SetLightOverrides(overrides)
+		lm.SetNameOverrides(cfg.GetLightNames(), func(id, name string) error {
+			cfg.SetLightName(id, name)
+			return cfg.Save()
+		})
+		lm.SetTagStore(cfg.GetLightTags(), func(id string, tags map[string]string) error {
+			cfg.SetLightTags(id, tags)
+			return cfg.Save()
+		})
+		lm.SetAdoptedDevices(cfg.GetAdoptedDevices(), func(ids []string) error {
+			cfg.SetAdoptedDevices(ids)
+			return cfg.Save()
+		})
+		// Lights turned off or parked on shutdown get their state back at the next start
+		if cfg.Config.Discovery.RestoreState || cfg.Config.Lights.Shutdown.ChangesLights() {
+			lm.EnableStateRestore(cfg.GetLightStates(), func(states map[string]config.SavedLightState) error {
+				cfg.SetLightStates(states)
+				return cfg.Save()
+			})
+		}
 	}
 	groupManager.SetEventBus(eventBus)
 
+	var m *metrics.Metrics
+	if cfg.Config.API.MetricsEnabled {
+		m = metrics.New(lightManager)
+		if lm, ok := lightManager.(*keylight.Manager); ok {
+			lm.SetMetrics(m)
+		}
+	}
+
+	var bridge *mqtt.Bridge
+	if cfg.Config.MQTT.Broker != "" {
+		bridge = mqtt.NewBridge(logger, cfg.Config.MQTT, lightManager, eventBus)
+	}
+
+	scheduleManager := schedule.NewManager(logger, cfg, lightManager, groupManager)
+	jobManager := jobs.NewManager(logger)
+	jobManager.SetEventBus(eventBus)
+	sceneManager := scene.NewManager(logger, cfg, lightManager, groupManager, jobManager)
+	scheduleManager.SetSceneApplier(sceneManager)
+	circadianManager := circadian.NewManager(logger, cfg, lightManager, groupManager)
+	webcamWatcher := webcam.NewWatcher(logger, cfg.Config.Webcam, groupManager)
+
 	rootCtx, rootCancel := context.WithCancel(context.Background())
 
-	return &Server{
+	// The daemon's log is kept in the default buffer; without one, such as
+	// in tests, the logs APIs return nothing
+	logBuffer := logging.DefaultBuffer()
+	if logBuffer == nil {
+		logBuffer = logging.NewBuffer(config.DefaultLogBufferSize)
+	}
+
+	s := &Server{
 		logger:        logger,
 		cfg:           cfg,
 		lights:        lightManager,
 		groups:        groupManager,
+		schedules:     scheduleManager,
+		scenes:        sceneManager,
+		jobs:          jobManager,
+		circadian:     circadianManager,
+		desired:       desiredstate.NewManager(logger, cfg, lightManager, groupManager),
+		stats:         stats.NewTracker(logger, cfg, eventBus, lightManager),
+		webcam:        webcamWatcher,
+		backup:        backup.NewService(cfg, lightManager, groupManager, scheduleManager, sceneManager),
 		socketPath:    cfg.Config.Server.UnixSocket,
 		shutdown:      make(chan struct{}),
+		stopRequested: make(chan struct{}),
 		apikeyManager: apikeyMgr,
 		rootCtx:       rootCtx,
 		rootCancel:    rootCancel,
 		eventBus:      eventBus,
+		metrics:       m,
+		mqttBridge:    bridge,
 		versionInfo:   vi,
+		startedAt:     time.Now(),
+		logBuffer:     logBuffer,
+	}
+	s.api = s.apiHandlers()
+	return s
+}
+
+// apiHandlers creates the HTTP API's handlers. They are created once and
+// shared by the HTTP API and the socket actions dispatched through
+// handlerAction, so both transports behave the same.
+func (s *Server) apiHandlers() *routes.Handlers {
+	return &routes.Handlers{
+		HealthCheck:  handlers.HealthCheck,
+		ReadyCheck:   handlers.NewReadyCheck(s.ready),
+		VersionCheck: handlers.NewVersionCheck(s.versionInfo.Version, s.versionInfo.Commit, s.versionInfo.BuildDate),
+		DaemonInfo:   handlers.NewDaemonInfo(s.daemonInfo),
+		Discover:     handlers.NewDiscoveryScan(s.discoveryScan),
+		Pending:      handlers.NewPendingDevices(s.pendingDevices),
+		Adopt:        handlers.NewAdoptDevice(s.adoptDevice),
+		Light:        &handlers.LightHandler{Lights: s.lights},
+		Group:        &handlers.GroupHandler{Groups: s.groups, Lights: s.lights},
+		APIKey:       &handlers.APIKeyHandler{Manager: s.apikeyManager},
+		Logging:      &handlers.LoggingHandler{Logger: s.logger, Buffer: s.logBuffer},
+		Schedule:     &handlers.ScheduleHandler{Schedules: s.schedules},
+		Scene:        &handlers.SceneHandler{Scenes: s.scenes},
+		Job:          &handlers.JobHandler{Jobs: s.jobs},
+		Circadian:    &handlers.CircadianHandler{Circadian: s.circadian},
+		DesiredState: &handlers.DesiredStateHandler{DesiredState: s.desired},
+		Webcam:       &handlers.WebcamHandler{Webcam: s.webcam},
+		Stats:        &handlers.StatsHandler{Stats: s.stats, Groups: s.groups},
+		StreamDeck:   &handlers.StreamDeckHandler{Groups: s.groups, Lights: s.lights},
+		Backup:       &handlers.BackupHandler{Backup: s.backup},
 	}
 }
 
@@ -96,11 +259,7 @@ func (s *Server) Start() error {
 
 	// Start cleanup worker for stale lights
 	s.wg.Go(func() {
-		defer func() {
-			if r := recover(); r != nil {
-				s.logger.Error("panic in cleanup worker", "recover", r)
-			}
-		}()
+		defer s.recoverPanic("cleanup worker")
 		// Create a cancellable context for the worker tied to rootCtx
 		workerCtx, cancelWorker := context.WithCancel(s.rootCtx) //nolint:gosec // G118: cancelWorker is called in the goroutine below
 		go func() {
@@ -112,6 +271,84 @@ func (s *Server) Start() error {
 			time.Duration(s.cfg.Config.Discovery.CleanupTimeout)*time.Second)
 	})
 
+	// Start the light state poller; it stops when rootCtx is cancelled in Stop().
+	if poller, ok := s.lights.(statePoller); ok && s.cfg.Config.Lights.PollInterval > 0 {
+		s.wg.Go(func() {
+			defer s.recoverPanic("light state poller")
+			poller.PollLights(s.rootCtx, time.Duration(s.cfg.Config.Lights.PollInterval)*time.Second)
+		})
+	}
+
+	// Start schedule worker; it stops when rootCtx is cancelled in Stop().
+	s.wg.Go(func() {
+		defer s.recoverPanic("schedule worker")
+		s.schedules.Run(s.rootCtx)
+	})
+
+	// Start circadian worker; it stops when rootCtx is cancelled in Stop().
+	s.wg.Go(func() {
+		defer s.recoverPanic("circadian worker")
+		s.circadian.Run(s.rootCtx)
+	})
+
+	// Start desired-state worker; it stops when rootCtx is cancelled in Stop().
+	s.wg.Go(func() {
+		defer s.recoverPanic("desired-state worker")
+		s.desired.Run(s.rootCtx)
+	})
+
+	// Start usage statistics tracking; it saves and stops when rootCtx is cancelled in Stop().
+	s.wg.Go(func() {
+		defer s.recoverPanic("stats tracker")
+		s.stats.Run(s.rootCtx)
+	})
+
+	// Start the MQTT bridge; it reconnects until rootCtx is cancelled in Stop().
+	if s.mqttBridge != nil {
+		s.logger.Info("Starting MQTT bridge", "broker", s.cfg.Config.MQTT.Broker)
+		s.wg.Go(func() {
+			defer s.recoverPanic("MQTT bridge")
+			s.mqttBridge.Run(s.rootCtx)
+		})
+	}
+
+	// Start the HomeKit bridge; it stops when rootCtx is cancelled in Stop().
+	if s.cfg.Config.HomeKit.Enabled {
+		bridge, err := homekit.NewBridge(s.logger, s.cfg.Config.HomeKit, s.lights, s.eventBus)
+		if err != nil {
+			return fmt.Errorf("failed to configure HomeKit bridge: %w", err)
+		}
+		s.wg.Go(func() {
+			defer s.recoverPanic("HomeKit bridge")
+			if err := bridge.Run(s.rootCtx); err != nil {
+				s.logger.Error("HomeKit bridge stopped", "error", err)
+			}
+		})
+	}
+
+	// Start the webcam watcher; it stops when rootCtx is cancelled in Stop().
+	if s.cfg.Config.Webcam.Enabled {
+		s.wg.Go(func() {
+			defer s.recoverPanic("webcam watcher")
+			if err := s.webcam.Run(s.rootCtx); err != nil {
+				s.logger.Error("Webcam watcher stopped", "error", err)
+			}
+		})
+	}
+
+	// Start the event hooks; they stop when rootCtx is cancelled in Stop().
+	if len(s.cfg.Config.Hooks) > 0 {
+		runner, err := hooks.NewRunner(s.logger, s.cfg.Config.Hooks, s.eventBus, s.groups)
+		if err != nil {
+			return fmt.Errorf("failed to configure hooks: %w", err)
+		}
+		s.logger.Info("Starting event hooks", "hooks", len(s.cfg.Config.Hooks))
+		s.wg.Go(func() {
+			defer s.recoverPanic("event hooks")
+			runner.Run(s.rootCtx)
+		})
+	}
+
 	// Ensure socket directory exists
 	sockDir := filepath.Dir(s.socketPath)
 	if err := os.MkdirAll(sockDir, 0755); err != nil { //nolint:gosec // G301: socket dir needs to be accessible
@@ -141,121 +378,429 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to listen on socket %s: %w", s.socketPath, err)
 	}
 	s.logger.Info("Listening on Unix socket", "path", s.socketPath)
+	s.listening.Store(true)
+
+	if err := s.startGRPC(); err != nil {
+		return err
+	}
 
 	s.wg.Add(1)
 	go s.acceptConnections()
 
 	// Start HTTP server if API is configured
-	if s.cfg.Config.API.ListenAddress != "" {
-		s.logger.Info("Starting HTTP API server", "address", s.cfg.Config.API.ListenAddress)
+	if listeners := s.cfg.Config.API.Listeners(); len(listeners) > 0 {
+		addresses := make([]string, len(listeners))
+		for i, l := range listeners {
+			if err := l.Validate(); err != nil {
+				return fmt.Errorf("invalid api.listen_addresses entry: %w", err)
+			}
+			addresses[i] = l.Address
+		}
+		s.logger.Info("Starting HTTP API server", "addresses", addresses)
+
+		// With api.tls configured the API is served over HTTPS. A client CA
+		// additionally requires client certificates, which authenticate
+		// requests in place of an API key.
+		tlsCfg := s.cfg.Config.API.TLS
+		var (
+			tlsConfig *tls.Config
+			certAuth  *mw.ClientCertAuth
+		)
+		if tlsCfg.Enabled() {
+			if tlsCfg.KeyFile == "" {
+				return errors.New("api.tls.key_file is required when api.tls.cert_file is set")
+			}
+			var err error
+			if tlsConfig, err = mw.ServerTLSConfig(tlsCfg); err != nil {
+				return fmt.Errorf("failed to configure API TLS: %w", err)
+			}
+			if tlsCfg.ClientCAFile != "" {
+				if certAuth, err = mw.NewClientCertAuth(tlsCfg); err != nil {
+					return fmt.Errorf("failed to configure client certificate auth: %w", err)
+				}
+			}
+		} else if tlsCfg.ClientCAFile != "" {
+			return errors.New("api.tls.client_ca_file requires api.tls.cert_file and api.tls.key_file")
+		}
 
-		// Create handler implementations
-		lightHandler := &handlers.LightHandler{Lights: s.lights}
-		groupHandler := &handlers.GroupHandler{Groups: s.groups, Lights: s.lights}
-		apiKeyHandler := &handlers.APIKeyHandler{Manager: s.apikeyManager}
-		loggingHandler := &handlers.LoggingHandler{Logger: s.logger}
+		proxies, err := mw.NewTrustedProxies(s.cfg.Config.API.TrustedProxies)
+		if err != nil {
+			return fmt.Errorf("failed to configure api.trusted_proxies: %w", err)
+		}
+		cors, err := mw.NewCORS(s.cfg.Config.API.CORS)
+		if err != nil {
+			return fmt.Errorf("failed to configure api.cors: %w", err)
+		}
+		if s.accessLog, err = mw.NewAccessLog(s.logger, s.cfg.Config.API.AccessLog); err != nil {
+			return fmt.Errorf("failed to configure api.access_log: %w", err)
+		}
+		basePath := s.cfg.Config.API.NormalizedBasePath()
 
 		// Create Chi router with global middleware.
 		// Rate limiting runs at Chi level (before auth) to protect against brute-force.
+		// CORS runs before it so browsers can read 429 responses.
 		router := chi.NewRouter()
-		router.Use(mw.RequestLogging(s.logger))
-		router.Use(mw.RateLimitByIP(mw.DefaultRateLimitConfig()))
+		router.Use(mw.RequestID)
+		router.Use(proxies.RealIP)
+		router.Use(mw.RequestLogging(s.logger, s.accessLog))
+		router.Use(s.trackActivity)
+		router.Use(s.limitRequest)
+		router.Use(cors.Handler)
+		limiter := mw.NewRateLimiter(s.cfg.Config.API.RateLimit)
+		router.Use(limiter.LimitByIP)
+		if s.metrics != nil {
+			// Unauthenticated, like /healthz, so Prometheus can scrape it without an API key.
+			router.Use(s.metrics.Middleware)
+			router.Get("/metrics", s.metrics.Handler().ServeHTTP)
+		}
 
 		// Create Huma API
-		humaConfig := routes.NewHumaConfig("dev", "")
+		// With a base path the spec lists it as the server URL, which also
+		// points the docs page at the prefixed spec.
+		humaConfig := routes.NewHumaConfig("dev", basePath)
 		api := humachi.New(router, humaConfig)
 
 		// Add Huma-level auth middleware. This checks each operation's Security
 		// field to determine if auth is needed. Public routes (health, OpenAPI
 		// spec, docs) have no Security set and pass through unauthenticated.
-		api.UseMiddleware(mw.HumaAuth(api, s.logger, s.apikeyManager))
+		api.UseMiddleware(mw.HumaAuth(api, s.logger, s.apikeyManager, certAuth, limiter))
 
 		// Register all routes via shared registration
-		routes.Register(api, &routes.Handlers{
-			HealthCheck:  handlers.HealthCheck,
-			VersionCheck: handlers.NewVersionCheck(s.versionInfo.Version, s.versionInfo.Commit, s.versionInfo.BuildDate),
-			Light:        lightHandler,
-			Group:        groupHandler,
-			APIKey:       apiKeyHandler,
-			Logging:      loggingHandler,
-		})
+		routes.Register(api, s.api)
 
-		// Override the group state route with a raw handler for 207 Multi-Status support.
-		// Huma doesn't natively support 207, so we use a raw Chi route.
-		// Auth is applied via router.With() since this bypasses Huma's middleware.
-		// The Huma registration above still provides OpenAPI documentation.
-		rawAuth := mw.RawAPIKeyAuth(s.logger, s.apikeyManager)
-		router.With(rawAuth).Put("/api/v1/groups/{id}/state", groupHandler.SetGroupStateRaw(api))
+		// The WebSocket endpoint is a raw Chi route outside the Huma API, so it
+		// authenticates with the raw middleware.
+		rawAuth := mw.RawAPIKeyAuth(s.logger, s.apikeyManager, certAuth, limiter)
 
 		// Start WebSocket hub and register the endpoint.
 		// The hub runs in a background goroutine and broadcasts events from the event bus.
 		wsHub := ws.NewHub(s.logger, s.eventBus)
+		wsHub.SetCommandHandler(s.wsCommand)
+		wsHub.SetGroupResolver(s.resolveEventGroups)
+		s.wsHub = wsHub
 		s.wg.Go(func() {
-			defer func() {
-				if r := recover(); r != nil {
-					s.logger.Error("panic in WebSocket hub", "recover", r)
-				}
-			}()
+			defer s.recoverPanic("WebSocket hub")
 			wsHub.Run(s.rootCtx)
 		})
-		router.With(rawAuth).Get("/api/v1/ws", ws.Handler(wsHub, s.logger))
+		router.With(rawAuth).Get(wsPath, ws.Handler(wsHub, s.logger))
+
+		// Behind a reverse proxy that routes by path, every route is served
+		// under the base path and anything outside it is not found.
+		var handler http.Handler = router
+		if basePath != "" {
+			root := chi.NewRouter()
+			root.Mount(basePath, router)
+			handler = root
+		}
+
+		// Each listener gets its own server, so listeners without
+		// authentication can mark their requests as trusted.
+		for _, l := range listeners {
+			srv := &http.Server{
+				Addr:         l.Address,
+				Handler:      handler,
+				ReadTimeout:  15 * time.Second,
+				WriteTimeout: s.apiRequestTimeout() + apiWriteTimeout,
+				IdleTimeout:  60 * time.Second,
+				// Requests are cancelled when the server shuts down
+				BaseContext: func(net.Listener) context.Context { return s.rootCtx },
+			}
+			if !l.RequiresAuth() {
+				srv.Handler = mw.TrustListener(handler)
+			}
+			if _, unix := l.UnixPath(); !unix {
+				srv.TLSConfig = tlsConfig
+			}
+			s.httpServers = append(s.httpServers, srv)
 
-		s.httpServer = &http.Server{
-			Addr:         s.cfg.Config.API.ListenAddress,
-			Handler:      router,
-			ReadTimeout:  15 * time.Second,
-			WriteTimeout: 15 * time.Second,
-			IdleTimeout:  60 * time.Second,
+			s.wg.Go(func() {
+				defer s.recoverPanic("HTTP server goroutine")
+				if err := s.serveAPI(srv, l, tlsCfg); err != nil && err != http.ErrServerClosed {
+					s.logger.Error("HTTP server failed", "address", l.Address, "error", err)
+				}
+				s.logger.Info("HTTP server stopped", "address", l.Address)
+			})
 		}
+	}
 
+	// Announce the API over mDNS; it stops when rootCtx is cancelled in Stop().
+	if s.cfg.Config.API.Advertise.Enabled {
+		advertiser, err := advertise.New(s.logger, &s.cfg.Config, s.versionInfo.Version)
+		if err != nil {
+			return fmt.Errorf("failed to configure mDNS announcement: %w", err)
+		}
 		s.wg.Go(func() {
-			defer func() {
-				if r := recover(); r != nil {
-					s.logger.Error("panic in HTTP server goroutine", "recover", r)
-				}
-			}()
-			if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				s.logger.Error("HTTP server failed", "error", err)
+			defer s.recoverPanic("mDNS announcement")
+			if err := advertiser.Run(s.rootCtx); err != nil {
+				s.logger.Error("mDNS announcement stopped", "error", err)
 			}
-			s.logger.Info("HTTP server stopped")
 		})
 	}
 
+	// Slow light refreshes down while no clients are using the daemon. This
+	// comes last so clientsActive sees the WebSocket hub.
+	s.startDemandTracking()
+
+	// Under a Type=notify systemd unit, the service counts as started once
+	// the socket is listening
+	s.notifySystemd(systemd.Ready, systemd.StatusText("Listening on "+s.socketPath))
+
 	return nil
 }
 
+// notifySystemd reports the daemon's state to systemd, doing nothing when it
+// wasn't started by a Type=notify unit.
+func (s *Server) notifySystemd(states ...string) {
+	sent, err := systemd.Notify(strings.Join(states, "\n"))
+	if err != nil {
+		s.logger.Warn("Failed to notify systemd", "error", err)
+	} else if sent {
+		s.logger.Debug("Notified systemd", "states", states)
+	}
+}
+
+// recoverPanic recovers a panic in one of the server's goroutines, logging it
+// and, if crash reports are enabled, writing a report with its stack. Defer
+// it directly, as recover only stops a panic when called by the deferred
+// function.
+func (s *Server) recoverPanic(where string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if report := crash.Recovered(where, r); report != "" {
+		s.logger.Error("panic in "+where, "recover", r, "report", report)
+		return
+	}
+	s.logger.Error("panic in "+where, "recover", r)
+}
+
+// serveAPI serves the HTTP API on one listener until it is shut down. Unix
+// sockets are served over plain HTTP and may only be opened by the daemon's
+// user.
+func (s *Server) serveAPI(srv *http.Server, l config.APIListener, tlsCfg config.TLSConfig) error {
+	path, unix := l.UnixPath()
+	if !unix {
+		if srv.TLSConfig != nil {
+			return srv.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.KeyFile)
+		}
+		return srv.ListenAndServe()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: socket dir needs to be accessible
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	// Remove a socket left behind by a previous run, but nothing else
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove existing socket file %s: %w", path, err)
+		}
+	}
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on socket %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return fmt.Errorf("failed to restrict socket %s: %w", path, err)
+	}
+	return srv.Serve(ln)
+}
+
+// discoveryStatus is implemented by light managers that report discovery progress.
+type discoveryStatus interface {
+	DiscoveryCompleted() bool
+}
+
+// discoveryReporter is implemented by light managers that keep discovery statistics.
+type discoveryReporter interface {
+	DiscoveryStats() keylight.DiscoveryStats
+}
+
+// discoveryScanner is implemented by light managers that can run a discovery
+// pass on demand.
+type discoveryScanner interface {
+	DiscoverNow(ctx context.Context) ([]*keylight.Light, error)
+}
+
+// discoverNow runs a discovery pass straight away, for the discover_now
+// action and the /api/v1/discovery/scan endpoint. It returns the lights that
+// weren't known before and how long the pass took.
+func (s *Server) discoverNow(ctx context.Context) ([]*keylight.Light, time.Duration, error) {
+	d, ok := s.lights.(discoveryScanner)
+	if !ok {
+		return nil, 0, kerrors.Errorf(kerrors.CodeUnknownAction, "this daemon can't run discovery on demand")
+	}
+	start := time.Now()
+	found, err := d.DiscoverNow(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("discovery failed: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Discovery scan completed", "found", len(found))
+	return found, time.Since(start), nil
+}
+
+// discoveryScan runs discoverNow for the HTTP API.
+func (s *Server) discoveryScan(ctx context.Context) (handlers.DiscoveryScanResponse, error) {
+	found, took, err := s.discoverNow(ctx)
+	if err != nil {
+		return handlers.DiscoveryScanResponse{}, err
+	}
+	resp := handlers.DiscoveryScanResponse{
+		Found:      make([]handlers.LightResponse, len(found)),
+		Lights:     len(s.lights.GetLights()),
+		DurationMS: took.Milliseconds(),
+	}
+	for i, light := range found {
+		resp.Found[i] = handlers.LightFromKeylight(light)
+	}
+	return resp, nil
+}
+
+// deviceAdopter is implemented by light managers that keep the devices
+// discovery rejected and can adopt them as lights.
+type deviceAdopter interface {
+	PendingDevices() []keylight.PendingDevice
+	AdoptDevice(ctx context.Context, id string) (*keylight.Light, error)
+}
+
+// pendingDevices lists the devices waiting to be adopted, for the
+// list_pending_devices action and the /api/v1/discovery/pending endpoint.
+func (s *Server) pendingDevices() ([]handlers.PendingDeviceResponse, error) {
+	a, ok := s.lights.(deviceAdopter)
+	if !ok {
+		return nil, kerrors.Errorf(kerrors.CodeUnknownAction, "this daemon doesn't keep pending devices")
+	}
+	pending := a.PendingDevices()
+	devices := make([]handlers.PendingDeviceResponse, len(pending))
+	for i, d := range pending {
+		devices[i] = handlers.PendingDeviceFromKeylight(d)
+	}
+	return devices, nil
+}
+
+// adoptDevice adds a pending device as a light, for the adopt_device action
+// and the /api/v1/discovery/pending/{id}/adopt endpoint.
+func (s *Server) adoptDevice(ctx context.Context, id string) (*keylight.Light, error) {
+	a, ok := s.lights.(deviceAdopter)
+	if !ok {
+		return nil, kerrors.Errorf(kerrors.CodeUnknownAction, "this daemon can't adopt devices")
+	}
+	return a.AdoptDevice(ctx, id)
+}
+
+// daemonInfo describes the running daemon for the get_daemon_info action and
+// the /api/v1/info endpoint.
+func (s *Server) daemonInfo() handlers.DaemonInfoResponse {
+	info := handlers.DaemonInfoResponse{
+		Version:           s.versionInfo.Version,
+		Commit:            s.versionInfo.Commit,
+		BuildDate:         s.versionInfo.BuildDate,
+		StartedAt:         s.startedAt,
+		UptimeSeconds:     int64(time.Since(s.startedAt).Seconds()),
+		SocketPath:        s.socketPath,
+		APIListenAddress:  s.apiTCPAddress(),
+		DiscoveryInterval: s.cfg.Config.Discovery.Interval,
+		Lights:            len(s.lights.GetLights()),
+		Groups:            len(s.groups.GetGroups()),
+		Clients: handlers.DaemonClients{
+			Socket:           int(s.socketClients.Load()),
+			EventSubscribers: int(s.subscribers.Load()),
+		},
+	}
+	if listeners := s.cfg.Config.API.Listeners(); len(listeners) > 1 {
+		for _, l := range listeners {
+			info.APIListenAddresses = append(info.APIListenAddresses, l.Address)
+		}
+	}
+	if s.wsHub != nil {
+		info.Clients.WebSocket = s.wsHub.ClientCount()
+	}
+	if d, ok := s.lights.(discoveryReporter); ok {
+		stats := d.DiscoveryStats()
+		info.Discovery = &stats
+	}
+	return info
+}
+
+// ready reports whether the daemon is ready to serve requests: the socket
+// listener is up and the first discovery pass has completed.
+func (s *Server) ready() (bool, string) {
+	if !s.listening.Load() {
+		return false, "socket listener is not running"
+	}
+	if d, ok := s.lights.(discoveryStatus); ok && !d.DiscoveryCompleted() {
+		return false, "initial discovery has not completed"
+	}
+	return true, ""
+}
+
 // Stop gracefully shuts down the server.
 func (s *Server) Stop() {
 	s.logger.Info("Shutting down keylightd server")
+	s.notifySystemd(systemd.Stopping)
 	s.rootCancel()    // Cancel root context first
 	close(s.shutdown) // Signal all goroutines to stop
+	s.listening.Store(false)
 
 	if s.listener != nil {
 		s.logger.Info("Closing Unix socket listener")
 		_ = s.listener.Close() // Close the socket listener to stop accepting new connections
 	}
+	s.stopGRPC()
 
-	if s.httpServer != nil {
+	if len(s.httpServers) > 0 {
 		s.logger.Info("Shutting down HTTP server")
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		if err := s.httpServer.Shutdown(ctx); err != nil {
-			s.logger.Error("HTTP server shutdown failed", "error", err)
+		for _, srv := range s.httpServers {
+			if err := srv.Shutdown(ctx); err != nil {
+				s.logger.Error("HTTP server shutdown failed", "address", srv.Addr, "error", err)
+			}
 		}
 	}
+	if err := s.accessLog.Close(); err != nil {
+		s.logger.Error("Failed to close access log", "error", err)
+	}
 
 	s.logger.Info("Waiting for services to stop...")
 	s.wg.Wait() // Wait for all goroutines to finish
+
+	if lm, ok := s.lights.(*keylight.Manager); ok {
+		if err := lm.SaveLightStates(); err != nil {
+			s.logger.Error("Failed to save light states", "error", err)
+		}
+		s.parkLights(lm)
+	}
 	s.logger.Info("Keylightd server shut down gracefully")
 }
 
+// shutdownParkTimeout is how long turning lights off or parking them may
+// delay shutdown.
+const shutdownParkTimeout = 5 * time.Second
+
+// parkLights turns lights off or parks them as set in
+// config.lights.shutdown. Their states must already be saved.
+func (s *Server) parkLights(lm *keylight.Manager) {
+	sd := s.cfg.Config.Lights.Shutdown
+	if !sd.ChangesLights() {
+		return
+	}
+	var state keylight.ParkState
+	if sd.Action == config.ShutdownPark {
+		state = keylight.ParkState{On: sd.On, Brightness: sd.Brightness, Temperature: sd.Temperature}
+	}
+	s.logger.Info("Setting lights for shutdown", "action", sd.Action)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownParkTimeout)
+	defer cancel()
+	if err := lm.ParkLights(ctx, state); err != nil {
+		s.logger.Warn("Failed to set some lights for shutdown", "action", sd.Action, "error", err)
+	}
+}
+
 func (s *Server) acceptConnections() {
 	defer s.wg.Done()
-	defer func() {
-		if r := recover(); r != nil {
-			s.logger.Error("panic in acceptConnections", "recover", r)
-		}
-	}()
+	defer s.recoverPanic("acceptConnections")
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
@@ -278,7 +823,7 @@ func (s *Server) acceptConnections() {
 
 // socketRequest holds the parsed fields of an incoming socket request.
 type socketRequest struct {
-	conn   net.Conn
+	conn   io.Writer
 	ctx    context.Context
 	id     string
 	data   map[string]any
@@ -293,42 +838,117 @@ const (
 	socketReturn                             // close connection
 )
 
+// socketWriteTimeout is how long a response may take to write once a request
+// has been processed, so a client that stops reading can't hold a connection.
+const socketWriteTimeout = 10 * time.Second
+
+// errRequestTooLarge is returned by readSocketRequest for a request over the
+// size limit.
+var errRequestTooLarge = errors.New("request too large")
+
 // socketActionHandler processes a single socket action and returns whether
 // the connection loop should continue or return.
 type socketActionHandler func(s *Server, r socketRequest) socketActionResult
 
+// socketProtocolVersion is the version of the socket protocol reported by
+// hello. Bump it when the meaning of an existing action changes; new actions
+// and features are advertised through hello instead. Daemons that predate
+// hello speak version 1.
+const socketProtocolVersion = 2
+
+// socketFeatures lists optional behaviour of existing actions reported by
+// hello, so clients can check for it instead of parsing daemon versions.
+var socketFeatures = []string{
+	"transitions",       // transition_ms on set_light_state and set_group_state
+	"relative_values",   // "+10" style brightness and temperature values
+	"multi_property",    // on/brightness/temperature together in one state request
+	"light_status",      // online/degraded/offline status on lights
+	"grpc",              // gRPC on the same socket
+	"color",             // hue/saturation on color lights
+	"request_id",        // request_id on requests and responses
+	"state_version",     // state_version on set_light_state and set_group_state
+	"temperature_units", // units on state requests, temperature_kelvin on lights
+	"event_filters",     // types/lights/groups filters on subscribe_events and the WebSocket API
+}
+
 // socketActions maps action names to their handler functions.
 var socketActions = map[string]socketActionHandler{
 	"ping":                       (*Server).handlePing,
 	"list_lights":                (*Server).handleListLights,
 	"get_light":                  (*Server).handleGetLight,
 	"set_light_state":            (*Server).handleSetLightState,
+	"set_lights_state":           (*Server).handleSetLightsState,
+	"toggle_light":               (*Server).handleToggleLight,
+	"set_light_name":             (*Server).handleSetLightName,
+	"set_light_tags":             (*Server).handleSetLightTags,
+	"set_device_name":            (*Server).handleSetDeviceName,
+	"raw_request":                (*Server).handleRawRequest,
+	"get_light_settings":         (*Server).handleGetLightSettings,
+	"set_light_settings":         (*Server).handleSetLightSettings,
+	"get_light_stats":            (*Server).handleGetLightStats,
 	"create_group":               (*Server).handleCreateGroup,
 	"delete_group":               (*Server).handleDeleteGroup,
 	"get_group":                  (*Server).handleGetGroup,
+	"get_group_state":            (*Server).handleGetGroupState,
+	"update_group":               (*Server).handleUpdateGroup,
 	"list_groups":                (*Server).handleListGroups,
 	"set_group_lights":           (*Server).handleSetGroupLights,
 	"set_group_state":            (*Server).handleSetGroupState,
+	"toggle_group":               (*Server).handleToggleGroup,
+	"set_group_defaults":         (*Server).handleSetGroupDefaults,
+	"get_group_stats":            (*Server).handleGetGroupStats,
 	"apikey_add":                 (*Server).handleAPIKeyAdd,
 	"apikey_list":                (*Server).handleAPIKeyList,
 	"apikey_delete":              (*Server).handleAPIKeyDelete,
 	"apikey_set_disabled_status": (*Server).handleAPIKeySetDisabledStatus,
+	"apikey_bootstrap":           (*Server).handleAPIKeyBootstrap,
 	"subscribe_events":           (*Server).handleSubscribeEvents,
 	"health":                     (*Server).handleHealth,
 	"list_filters":               (*Server).handleListFilters,
 	"set_filters":                (*Server).handleSetFilters,
+	"add_filter":                 (*Server).handleAddFilter,
+	"remove_filter":              (*Server).handleRemoveFilter,
+	"get_level":                  (*Server).handleGetLevel,
 	"set_level":                  (*Server).handleSetLevel,
+	"get_logs":                   (*Server).handleGetLogs,
+	"follow_logs":                (*Server).handleFollowLogs,
 	"version":                    (*Server).handleVersion,
+	"shutdown":                   (*Server).handleShutdown,
+	"get_daemon_info":            (*Server).handleGetDaemonInfo,
+	"discover_now":               handlerAction("", discoverHandler),
+	"list_pending_devices":       handlerAction("", pendingHandler),
+	"adopt_device":               handlerAction("light", adoptHandler),
+	"list_schedules":             handlerAction("schedules", method(scheduleHandlers, handlers.ScheduleHandlers.ListSchedules)),
+	"list_upcoming_schedules":    handlerAction("upcoming", method(scheduleHandlers, handlers.ScheduleHandlers.ListUpcomingSchedules)),
+	"get_schedule":               handlerAction("schedule", method(scheduleHandlers, handlers.ScheduleHandlers.GetSchedule)),
+	"create_schedule":            handlerAction("schedule", method(scheduleHandlers, handlers.ScheduleHandlers.CreateSchedule)),
+	"update_schedule":            handlerAction("schedule", method(scheduleHandlers, handlers.ScheduleHandlers.UpdateSchedule)),
+	"delete_schedule":            handlerAction("", method(scheduleHandlers, handlers.ScheduleHandlers.DeleteSchedule)),
+	"list_scenes":                handlerAction("scenes", method(sceneHandlers, handlers.SceneHandlers.ListScenes)),
+	"get_scene":                  handlerAction("scene", method(sceneHandlers, handlers.SceneHandlers.GetScene)),
+	"create_scene":               handlerAction("scene", method(sceneHandlers, handlers.SceneHandlers.CreateScene)),
+	"update_scene":               handlerAction("scene", method(sceneHandlers, handlers.SceneHandlers.UpdateScene)),
+	"delete_scene":               handlerAction("", method(sceneHandlers, handlers.SceneHandlers.DeleteScene)),
+	"apply_scene":                handlerAction("job", method(sceneHandlers, handlers.SceneHandlers.ApplyScene)),
+	"get_scene_run":              handlerAction("job", method(sceneHandlers, handlers.SceneHandlers.GetSceneRun)),
+	"cancel_scene_run":           handlerAction("job", method(sceneHandlers, handlers.SceneHandlers.CancelSceneRun)),
+	"list_jobs":                  handlerAction("jobs", method(jobHandlers, handlers.JobHandlers.ListJobs)),
+	"get_job":                    handlerAction("job", method(jobHandlers, handlers.JobHandlers.GetJob)),
+	"cancel_job":                 handlerAction("job", method(jobHandlers, handlers.JobHandlers.CancelJob)),
+	"get_circadian":              (*Server).handleGetCircadian,
+	"set_circadian":              (*Server).handleSetCircadian,
+	"get_desired_state":          (*Server).handleGetDesiredState,
+	"set_desired_state":          (*Server).handleSetDesiredState,
+	"set_desired_state_target":   (*Server).handleSetDesiredStateTarget,
+	"get_webcam":                 (*Server).handleGetWebcam,
+	"set_webcam_override":        (*Server).handleSetWebcamOverride,
+	"export_config":              (*Server).handleExportConfig,
+	"import_config":              (*Server).handleImportConfig,
 }
 
 func (s *Server) handleConnection(conn net.Conn) {
-	defer conn.Close()
 	defer s.wg.Done()
-	defer func() {
-		if r := recover(); r != nil {
-			s.logger.Error("panic in connection handler", "recover", r)
-		}
-	}()
+	defer s.recoverPanic("connection handler")
 
 	//nolint:misspell // British spelling intentional
 	// Create a context that is cancelled when the server shuts down
@@ -350,7 +970,26 @@ func (s *Server) handleConnection(conn net.Conn) {
 		}
 	}()
 
+	limits := s.cfg.Config.Server
+	maxSize := cmp.Or(limits.MaxRequestSize, config.DefaultSocketMaxRequestSize)
+	idleTimeout := cmp.Or(time.Duration(limits.IdleTimeout)*time.Second, config.DefaultSocketIdleTimeout)
+	requestTimeout := cmp.Or(time.Duration(limits.RequestTimeout)*time.Second, config.DefaultSocketRequestTimeout)
+
+	// A client has idleTimeout to send each request, so a half-open or
+	// stalled connection can't hold its handler forever
+	if err := conn.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+		s.logger.Warn("socket: SetReadDeadline failed", "error", err)
+	}
 	reader := bufio.NewReader(conn)
+	if s.grpcConns != nil && isGRPC(reader) {
+		// The gRPC server owns the connection from here on
+		_ = conn.SetReadDeadline(time.Time{})
+		s.grpcConns.serve(&bufferedConn{Conn: conn, reader: reader})
+		return
+	}
+	defer conn.Close()
+	s.socketClients.Add(1)
+	defer s.socketClients.Add(-1)
 
 	for {
 		select {
@@ -360,198 +999,606 @@ func (s *Server) handleConnection(conn net.Conn) {
 			// Proceed with reading
 		}
 
-		line, err := reader.ReadBytes('\n')
+		if err := conn.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+			s.logger.Warn("socket: SetReadDeadline failed", "error", err)
+		}
+		line, err := readSocketRequest(reader, maxSize)
 		if err != nil {
-			if errors.Is(err, io.EOF) || strings.Contains(err.Error(), "use of closed network connection") {
+			var netErr net.Error
+			switch {
+			case errors.Is(err, errRequestTooLarge):
+				// The rest of the line is never read, so the connection can't be reused
+				s.logger.Warn("Closing connection after oversized request", "limit", maxSize)
+				_ = conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+				s.sendError(newSocketRequest(ctx, conn, nil), kerrors.Errorf(kerrors.CodeRequestTooLarge, "request exceeds %d bytes", maxSize))
+			case errors.As(err, &netErr) && netErr.Timeout():
+				s.logger.Debug("Closing idle connection", "timeout", idleTimeout)
+			case errors.Is(err, io.EOF) || strings.Contains(err.Error(), "use of closed network connection"):
 				s.logger.Debug("Client disconnected")
-			} else {
+			default:
 				s.logger.Error("Failed to read from connection", "error", err)
 			}
 			return // Exit handler on read error or EOF
 		}
+		// Handlers that keep the connection open, such as event subscriptions,
+		// read from it themselves
+		_ = conn.SetReadDeadline(time.Time{})
+		_ = conn.SetWriteDeadline(time.Now().Add(requestTimeout + socketWriteTimeout))
 
 		var req map[string]any
 		if err := json.Unmarshal(line, &req); err != nil {
-			s.logger.Error("Failed to unmarshal request", "error", err, "request", string(line))
-			s.sendError(conn, "", fmt.Sprintf("invalid JSON request: %s", err))
+			r := newSocketRequest(ctx, conn, nil)
+			s.logger.ErrorContext(r.ctx, "Failed to unmarshal request", "error", err, "size", len(line))
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidRequest, "invalid JSON request: %s", err))
 			continue
 		}
+		r := newSocketRequest(ctx, conn, req)
 
-		action, _ := req["action"].(string)
-		id, _ := req["id"].(string)             // Optional request ID for client tracking
-		data, _ := req["data"].(map[string]any) // Data payload
-
-		s.logger.Debug("Received request", "action", action, "id", id, "data", data)
+		s.logger.DebugContext(r.ctx, "Received request", "action", r.action, "id", r.id, "data", loggedData(r))
 
-		r := socketRequest{conn: conn, ctx: ctx, id: id, data: data, action: action}
+		if r.action == "" {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidRequest, "missing action"))
+			continue
+		}
+		if raw, ok := req["data"]; ok && raw != nil && r.data == nil {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidRequest, "data must be an object"))
+			continue
+		}
 
-		handler, ok := socketActions[action]
+		handler, ok := socketActions[r.action]
 		if !ok {
-			s.logger.Warn("received unknown action", "action", action)
-			s.sendError(conn, id, "unknown action: "+action)
+			s.logger.WarnContext(r.ctx, "received unknown action", "action", r.action)
+			s.sendError(r, kerrors.Errorf(kerrors.CodeUnknownAction, "unknown action: %s", r.action))
 			continue
 		}
-		if result := handler(s, r); result == socketReturn {
+		s.noteActivity()
+
+		// Streaming actions run until the client or the server goes away; all
+		// others get requestTimeout to finish, and are cancelled if the client
+		// hangs up first
+		cancelReq, stopWatch := context.CancelFunc(func() {}), func() {}
+		if r.action != "subscribe_events" && r.action != "follow_logs" {
+			r.ctx, cancelReq = context.WithTimeout(r.ctx, requestTimeout)
+			stopWatch = cancelOnDisconnect(conn, reader, cancelReq)
+		}
+		result := handler(s, r)
+		stopWatch()
+		cancelReq()
+		if result == socketReturn {
 			return
 		}
 	}
 }
 
+// newSocketRequest builds the request for a decoded socket message, which may
+// be nil if it couldn't be decoded. The request's context carries its request
+// ID: the client's request_id if valid, or a new one.
+func newSocketRequest(ctx context.Context, conn io.Writer, req map[string]any) socketRequest {
+	action, _ := req["action"].(string)
+	id, _ := req["id"].(string)             // Optional request ID for client tracking
+	data, _ := req["data"].(map[string]any) // Data payload
+	requestID, _ := req["request_id"].(string)
+	return socketRequest{
+		conn:   conn,
+		ctx:    requestid.WithID(ctx, requestid.Resolve(requestID)),
+		id:     id,
+		data:   data,
+		action: action,
+	}
+}
+
+// readSocketRequest reads one newline-terminated request of at most limit
+// bytes, returning errRequestTooLarge as soon as the line exceeds the limit.
+func readSocketRequest(reader *bufio.Reader, limit int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(line)+len(chunk) > limit {
+			return nil, errRequestTooLarge
+		}
+		line = append(line, chunk...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, err
+		}
+	}
+}
+
+func init() {
+	// Registered here rather than in socketActions because hello lists socketActions
+	socketActions["hello"] = (*Server).handleHello
+}
+
+// handleHello reports the protocol version, daemon version and the actions
+// and features the daemon supports. Clients call it when they connect to
+// find out what they can use.
+func (s *Server) handleHello(r socketRequest) socketActionResult {
+	if v, ok := r.data["protocol_version"].(float64); ok && int(v) > socketProtocolVersion {
+		s.logger.DebugContext(r.ctx, "socket: client speaks a newer protocol", "client_version", int(v), "version", socketProtocolVersion)
+	}
+	s.sendResponse(r, map[string]any{
+		"protocol_version": socketProtocolVersion,
+		"version":          s.versionInfo.Version,
+		"commit":           s.versionInfo.Commit,
+		"actions":          slices.Sorted(maps.Keys(socketActions)),
+		"features":         socketFeatures,
+	})
+	return socketContinue
+}
+
 func (s *Server) handlePing(r socketRequest) socketActionResult {
-	s.sendResponse(r.conn, r.id, map[string]any{"message": "pong"})
+	s.sendResponse(r, map[string]any{"message": "pong"})
 	return socketContinue
 }
 
 func (s *Server) handleListLights(r socketRequest) socketActionResult {
-	lights := s.lights.GetLights()
+	lights := s.lights.RefreshLights(r.ctx)
 	result := make(map[string]any, len(lights))
 	for id, light := range lights {
 		b, err := json.Marshal(light)
 		if err != nil {
-			s.logger.Error("Failed to marshal light for socket response", "id", id, "error", err)
+			s.logger.ErrorContext(r.ctx, "Failed to marshal light for socket response", "id", id, "error", err)
 			continue
 		}
 		var m map[string]any
 		if err := json.Unmarshal(b, &m); err != nil {
-			s.logger.Error("Failed to unmarshal light for socket response", "id", id, "error", err)
+			s.logger.ErrorContext(r.ctx, "Failed to unmarshal light for socket response", "id", id, "error", err)
 			continue
 		}
 		result[id] = m
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"lights": result})
+	s.sendResponse(r, map[string]any{"lights": result})
 	return socketContinue
 }
 
 func (s *Server) handleGetLight(r socketRequest) socketActionResult {
 	lightID, _ := r.data["id"].(string)
 	if lightID == "" {
-		s.sendError(r.conn, r.id, "missing light ID for get_light")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing light ID for get_light"))
 		return socketContinue
 	}
 	light, err := s.lights.GetLight(r.ctx, lightID)
 	if err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to get light %s: %s", lightID, err))
+		s.sendError(r, fmt.Errorf("failed to get light %s: %w", lightID, err))
 		return socketContinue
 	}
 	b, err := json.Marshal(light)
 	if err != nil {
-		s.logger.Error("Failed to marshal light for socket response", "id", lightID, "error", err)
-		s.sendError(r.conn, r.id, "internal error marshaling light")
+		s.logger.ErrorContext(r.ctx, "Failed to marshal light for socket response", "id", lightID, "error", err)
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInternal, "internal error marshaling light"))
 		return socketContinue
 	}
 	var m map[string]any
 	if err := json.Unmarshal(b, &m); err != nil {
-		s.logger.Error("Failed to unmarshal light for socket response", "id", lightID, "error", err)
-		s.sendError(r.conn, r.id, "internal error unmarshaling light")
+		s.logger.ErrorContext(r.ctx, "Failed to unmarshal light for socket response", "id", lightID, "error", err)
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInternal, "internal error unmarshaling light"))
 		return socketContinue
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"light": m})
+	s.sendResponse(r, map[string]any{"light": m})
 	return socketContinue
 }
 
 func (s *Server) handleSetLightState(r socketRequest) socketActionResult {
-	lightID, _ := r.data["id"].(string)
-	if lightID == "" {
-		s.sendError(r.conn, r.id, "missing id for set_light_state")
+	target, _ := r.data["id"].(string)
+	if target == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id for set_light_state"))
+		return socketContinue
+	}
+	version, strict, err := versionFromData(r.data)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	transition, err := transitionFromData(r.data)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	if err := kelvinFromData(r.data, r.data); err != nil {
+		s.sendError(r, fmt.Errorf("%w for set_light_state", err))
+		return socketContinue
+	}
+	lightIDs, selected, err := keylight.TargetLights(s.lights, target)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	if strict {
+		if selected {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "state_version can only be used to change a single light"))
+			return socketContinue
+		}
+		r.ctx = keylight.WithVersionCheck(r.ctx, keylight.ExpectVersion(version))
+	}
+
+	if !selected {
+		if err := s.setLightState(r.ctx, target, r.data, transition); err != nil {
+			s.sendError(r, err)
+			return socketContinue
+		}
+		s.sendResponse(r, map[string]any{"status": "ok"})
+		return socketContinue
+	}
+
+	// Selected lights are changed together
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	for _, lightID := range lightIDs {
+		wg.Go(func() {
+			if err := s.setLightState(r.ctx, lightID, r.data, transition); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		// An invalid request fails the same way for every light
+		if i := slices.IndexFunc(errs, kerrors.IsInvalidInput); i >= 0 {
+			s.sendError(r, errs[i])
+			return socketContinue
+		}
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+		slices.Sort(msgs)
+		s.sendError(r, &kerrors.Error{Code: kerrors.CodeOf(errs[0]), Message: strings.Join(msgs, "; ")})
 		return socketContinue
 	}
+	s.sendResponse(r, map[string]any{"status": "ok", "lights": lightIDs})
+	return socketContinue
+}
+
+// setLightState applies the state in a set_light_state payload to one light.
+func (s *Server) setLightState(ctx context.Context, lightID string, data map[string]any, transition time.Duration) error {
+	if transition > 0 {
+		change, err := stateChangeFromData(data)
+		if err != nil {
+			return fmt.Errorf("%w for set_light_state", err)
+		}
+		if err := s.lights.Transition(ctx, lightID, change, transition); err != nil {
+			return fmt.Errorf("failed to set light %s state: %w", lightID, err)
+		}
+		return nil
+	}
 
 	// Support both single-property (property+value) and multi-property (on, brightness, temperature) modes.
-	property, _ := r.data["property"].(string)
-	value := r.data["value"]
+	property, _ := data["property"].(string)
+	value := data["value"]
 
-	var errs []string
+	var errs []error
 	if property != "" && value != nil {
 		// Legacy single-property mode
-		if err := s.setLightProperty(r.ctx, lightID, property, value); err != nil {
-			s.sendError(r.conn, r.id, fmt.Sprintf("failed to set light %s state %s: %s", lightID, property, err))
-			return socketContinue
+		if err := s.setLightProperty(ctx, lightID, property, value); err != nil {
+			return fmt.Errorf("failed to set light %s state %s: %w", lightID, property, err)
 		}
 	} else {
 		// Multi-property mode: check for on, brightness, temperature in data
 		set := false
-		if onVal, ok := r.data["on"]; ok {
+		if onVal, ok := data["on"]; ok {
+			set = true
+			if err := s.setLightProperty(ctx, lightID, "on", onVal); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if bVal, ok := data["brightness"]; ok {
 			set = true
-			if err := s.setLightProperty(r.ctx, lightID, "on", onVal); err != nil {
-				errs = append(errs, err.Error())
+			if err := s.setLightProperty(ctx, lightID, "brightness", bVal); err != nil {
+				errs = append(errs, err)
 			}
 		}
-		if bVal, ok := r.data["brightness"]; ok {
+		if tVal, ok := data["temperature"]; ok {
 			set = true
-			if err := s.setLightProperty(r.ctx, lightID, "brightness", bVal); err != nil {
-				errs = append(errs, err.Error())
+			if err := s.setLightProperty(ctx, lightID, "temperature", tVal); err != nil {
+				errs = append(errs, err)
 			}
 		}
-		if tVal, ok := r.data["temperature"]; ok {
+		hue, saturation, err := colorFromData(data)
+		if err != nil {
+			return fmt.Errorf("%w for set_light_state", err)
+		}
+		if color, ok := keylight.ColorChange(hue, saturation); ok {
 			set = true
-			if err := s.setLightProperty(r.ctx, lightID, "temperature", tVal); err != nil {
-				errs = append(errs, err.Error())
+			if err := s.lights.SetLightState(ctx, lightID, color); err != nil {
+				errs = append(errs, err)
 			}
 		}
 		if !set {
-			s.sendError(r.conn, r.id, "missing property/value or on/brightness/temperature for set_light_state")
-			return socketContinue
+			return kerrors.Errorf(kerrors.CodeInvalidInput, "missing property/value or on/brightness/temperature/hue/saturation for set_light_state")
 		}
 	}
 
 	if len(errs) > 0 {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to set light %s state: %s", lightID, strings.Join(errs, "; ")))
-		return socketContinue
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+		}
+		// The code is that of the first failure
+		return &kerrors.Error{
+			Code:    kerrors.CodeOf(errs[0]),
+			Message: fmt.Sprintf("failed to set light %s state: %s", lightID, strings.Join(msgs, "; ")),
+		}
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok"})
-	return socketContinue
+	return nil
 }
 
-func (s *Server) handleCreateGroup(r socketRequest) socketActionResult {
-	name, _ := r.data["name"].(string)
-	lightIDsReq, _ := r.data["lights"].([]any)
-	lightIDs := make([]string, len(lightIDsReq))
-	for i, v := range lightIDsReq {
-		lightIDs[i], _ = v.(string)
-	}
-	if name == "" {
-		s.sendError(r.conn, r.id, "missing name for create_group")
+// handleSetLightsState applies a batch of light updates concurrently. The
+// batch is rejected without changing anything if any update is invalid.
+func (s *Server) handleSetLightsState(r socketRequest) socketActionResult {
+	entries, _ := r.data["lights"].([]any)
+	if len(entries) == 0 {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing lights for set_lights_state"))
 		return socketContinue
 	}
-	grp, err := s.groups.CreateGroup(r.ctx, name, lightIDs)
-	if err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to create group: %s", err))
+	updates := make([]keylight.LightUpdate, 0, len(entries))
+	for _, entry := range entries {
+		data, ok := entry.(map[string]any)
+		if !ok {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid light update for set_lights_state, expected object"))
+			return socketContinue
+		}
+		lightID, _ := data["id"].(string)
+		if lightID == "" {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id in light update for set_lights_state"))
+			return socketContinue
+		}
+		if err := kelvinFromData(data, r.data); err != nil {
+			s.sendError(r, fmt.Errorf("light %s: %w for set_lights_state", lightID, err))
+			return socketContinue
+		}
+		change, err := stateChangeFromData(data)
+		if err != nil {
+			s.sendError(r, fmt.Errorf("light %s: %w for set_lights_state", lightID, err))
+			return socketContinue
+		}
+		updates = append(updates, keylight.LightUpdate{ID: lightID, StateChange: change})
+	}
+
+	errs, err := keylight.ApplyBatch(r.ctx, s.lights, updates)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	if len(errs) > 0 {
+		s.sendResponse(r, map[string]any{"status": "partial", "errors": keylight.BatchErrorMessages(errs)})
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok"})
+	return socketContinue
+}
+
+func (s *Server) handleToggleLight(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	if lightID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id for toggle_light"))
+		return socketContinue
+	}
+	on, err := s.lights.ToggleLight(r.ctx, lightID)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to toggle light %s: %w", lightID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok", "on": on})
+	return socketContinue
+}
+
+// handleSetLightName sets or, with an empty name, clears a light's display name override.
+func (s *Server) handleSetLightName(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	if lightID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id for set_light_name"))
+		return socketContinue
+	}
+	name, _ := r.data["name"].(string)
+	light, err := s.lights.SetLightName(r.ctx, lightID, name)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set name of light %s: %w", lightID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok", "name": light.Name})
+	return socketContinue
+}
+
+// handleSetLightTags replaces the tags of a light.
+func (s *Server) handleSetLightTags(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	if lightID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id for set_light_tags"))
+		return socketContinue
+	}
+	var tags map[string]string
+	if raw, ok := r.data["tags"]; ok && raw != nil {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid value type for 'tags', expected object"))
+			return socketContinue
+		}
+		tags = make(map[string]string, len(m))
+		for key, v := range m {
+			value, ok := v.(string)
+			if !ok {
+				s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid value for tag %q, expected string", key))
+				return socketContinue
+			}
+			tags[key] = value
+		}
+	}
+	light, err := s.lights.SetLightTags(r.ctx, lightID, tags)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set tags of light %s: %w", lightID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok", "light": light})
+	return socketContinue
+}
+
+// handleSetDeviceName writes a new display name to the light itself.
+func (s *Server) handleSetDeviceName(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	if lightID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id for set_device_name"))
+		return socketContinue
+	}
+	name, _ := r.data["name"].(string)
+	light, err := s.lights.SetDeviceName(r.ctx, lightID, name)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set device name of light %s: %w", lightID, err))
 		return socketContinue
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"group": grp})
+	s.sendResponse(r, map[string]any{"status": "ok", "name": light.Name})
+	return socketContinue
+}
+
+// handleRawRequest passes a request straight through to a light's own API.
+// The body, if any, is any JSON value and is sent to the device as is.
+func (s *Server) handleRawRequest(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	method, _ := r.data["method"].(string)
+	path, _ := r.data["path"].(string)
+	if lightID == "" || method == "" || path == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id, method or path for raw_request"))
+		return socketContinue
+	}
+	var body []byte
+	if v, ok := r.data["body"]; ok && v != nil {
+		var err error
+		if body, err = json.Marshal(v); err != nil {
+			s.sendError(r, fmt.Errorf("invalid body for raw_request: %w", err))
+			return socketContinue
+		}
+	}
+	resp, err := s.lights.RawRequest(r.ctx, lightID, method, path, body)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("raw request to light %s failed: %w", lightID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok", "response": resp})
+	return socketContinue
+}
+
+// handleGetLightSettings returns the power-on settings stored on a light.
+func (s *Server) handleGetLightSettings(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	if lightID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing light ID for get_light_settings"))
+		return socketContinue
+	}
+	settings, err := s.lights.GetLightSettings(r.ctx, lightID)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to get settings for light %s: %w", lightID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok", "settings": settings})
+	return socketContinue
+}
+
+// handleSetLightSettings changes the power-on settings stored on a light.
+// Settings not given are left as they are.
+func (s *Server) handleSetLightSettings(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	if lightID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing light ID for set_light_settings"))
+		return socketContinue
+	}
+	update, err := settingsUpdateFromData(r.data)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	settings, err := s.lights.SetLightSettings(r.ctx, lightID, update)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set settings for light %s: %w", lightID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok", "settings": settings})
+	return socketContinue
+}
+
+func (s *Server) handleCreateGroup(r socketRequest) socketActionResult {
+	name, _ := r.data["name"].(string)
+	lightIDsReq, _ := r.data["lights"].([]any)
+	lightIDs := make([]string, len(lightIDsReq))
+	for i, v := range lightIDsReq {
+		lightIDs[i], _ = v.(string)
+	}
+	if name == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing name for create_group"))
+		return socketContinue
+	}
+	grp, err := s.groups.CreateGroup(r.ctx, name, lightIDs)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to create group: %w", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"group": grp})
 	return socketContinue
 }
 
 func (s *Server) handleDeleteGroup(r socketRequest) socketActionResult {
 	groupID, _ := r.data["id"].(string)
 	if groupID == "" {
-		s.sendError(r.conn, r.id, "missing group ID for delete_group")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing group ID for delete_group"))
 		return socketContinue
 	}
 	if err := s.groups.DeleteGroup(groupID); err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to delete group %s: %s", groupID, err))
+		s.sendError(r, fmt.Errorf("failed to delete group %s: %w", groupID, err))
 		return socketContinue
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok"})
+	s.sendResponse(r, map[string]any{"status": "ok"})
 	return socketContinue
 }
 
 func (s *Server) handleGetGroup(r socketRequest) socketActionResult {
 	groupID, _ := r.data["id"].(string)
 	if groupID == "" {
-		s.sendError(r.conn, r.id, "missing group ID for get_group")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing group ID for get_group"))
 		return socketContinue
 	}
 	grp, err := s.groups.GetGroup(groupID)
 	if err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to get group %s: %s", groupID, err))
+		s.sendError(r, fmt.Errorf("failed to get group %s: %w", groupID, err))
 		return socketContinue
 	}
-	lights := grp.Lights
-	if lights == nil {
-		lights = []string{}
+	s.sendResponse(r, map[string]any{"group": s.groupWithLimits(grp)})
+	return socketContinue
+}
+
+func (s *Server) handleUpdateGroup(r socketRequest) socketActionResult {
+	groupID, _ := r.data["id"].(string)
+	if groupID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing group ID for update_group"))
+		return socketContinue
+	}
+	update, err := groupUpdateFromData(r.data)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"group": map[string]any{"id": grp.ID, "name": grp.Name, "lights": lights}})
+	grp, err := s.groups.UpdateGroup(groupID, update)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to update group %s: %w", groupID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"group": s.groupWithLimits(grp)})
+	return socketContinue
+}
+
+func (s *Server) handleGetGroupState(r socketRequest) socketActionResult {
+	groupID, _ := r.data["id"].(string)
+	if groupID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing group ID for get_group_state"))
+		return socketContinue
+	}
+	summary, err := s.groups.Summary(groupID)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to get group %s: %w", groupID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"state": handlers.GroupStateFromInternal(summary)})
 	return socketContinue
 }
 
@@ -559,17 +1606,9 @@ func (s *Server) handleListGroups(r socketRequest) socketActionResult {
 	groups := s.groups.GetGroups()
 	groupList := make([]map[string]any, 0, len(groups))
 	for _, g := range groups {
-		lights := g.Lights
-		if lights == nil {
-			lights = []string{}
-		}
-		groupList = append(groupList, map[string]any{
-			"id":     g.ID,
-			"name":   g.Name,
-			"lights": lights,
-		})
+		groupList = append(groupList, s.groupWithLimits(g))
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"groups": groupList})
+	s.sendResponse(r, map[string]any{"groups": groupList})
 	return socketContinue
 }
 
@@ -581,26 +1620,137 @@ func (s *Server) handleSetGroupLights(r socketRequest) socketActionResult {
 		lightIDs[i], _ = v.(string)
 	}
 	if groupID == "" {
-		s.sendError(r.conn, r.id, "missing group ID for set_group_lights")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing group ID for set_group_lights"))
 		return socketContinue
 	}
 	if err := s.groups.SetGroupLights(r.ctx, groupID, lightIDs); err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to set lights for group %s: %s", groupID, err))
+		s.sendError(r, fmt.Errorf("failed to set lights for group %s: %w", groupID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok"})
+	return socketContinue
+}
+
+func (s *Server) handleSetGroupDefaults(r socketRequest) socketActionResult {
+	groupID, _ := r.data["id"].(string)
+	if groupID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing group ID for set_group_defaults"))
+		return socketContinue
+	}
+	defaults := &group.Defaults{}
+	for property, dst := range map[string]**int{"brightness": &defaults.Brightness, "temperature": &defaults.Temperature} {
+		v, ok := r.data[property]
+		if !ok || v == nil {
+			continue
+		}
+		num, ok := v.(float64)
+		if !ok {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid value type for '%s', expected number", property))
+			return socketContinue
+		}
+		n := int(num)
+		*dst = &n
+	}
+	if v, ok := r.data["apply_on_join"]; ok && v != nil {
+		applyOnJoin, ok := v.(bool)
+		if !ok {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid value type for 'apply_on_join', expected boolean"))
+			return socketContinue
+		}
+		defaults.ApplyOnJoin = applyOnJoin
+	}
+	if err := s.groups.SetGroupDefaults(groupID, defaults); err != nil {
+		s.sendError(r, fmt.Errorf("failed to set defaults for group %s: %w", groupID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok"})
+	return socketContinue
+}
+
+// handleToggleGroup toggles each matched group independently and reports the
+// power state applied to each, keyed by group ID.
+func (s *Server) handleToggleGroup(r socketRequest) socketActionResult {
+	groupKeys, _ := r.data["id"].(string)
+	if groupKeys == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id for toggle_group"))
+		return socketContinue
+	}
+	matchedGroups, notFound := s.groups.GetGroupsByKeys(groupKeys)
+	if len(matchedGroups) == 0 {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeNotFound, "no groups found for: %s", strings.Join(notFound, ", ")).
+			WithDetails(map[string]any{"not_found": notFound}))
+		return socketContinue
+	}
+
+	ctx, results := group.WithResults(r.ctx)
+	states := make(map[string]bool, len(matchedGroups))
+	var errs []string
+	for _, grp := range matchedGroups {
+		on, err := s.groups.ToggleGroup(ctx, grp.ID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
+		}
+		states[grp.ID] = on
+	}
+	if len(errs) > 0 {
+		s.sendResponse(r, map[string]any{"status": "partial", "groups": states, "errors": errs, "lights": results.Lights()})
 		return socketContinue
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok"})
+	s.sendResponse(r, map[string]any{"status": "ok", "groups": states, "lights": results.Lights()})
 	return socketContinue
 }
 
 func (s *Server) handleSetGroupState(r socketRequest) socketActionResult {
 	groupKeys, _ := r.data["id"].(string)
 	if groupKeys == "" {
-		s.sendError(r.conn, r.id, "missing id for set_group_state")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing id for set_group_state"))
 		return socketContinue
 	}
 	matchedGroups, notFound := s.groups.GetGroupsByKeys(groupKeys)
 	if len(matchedGroups) == 0 {
-		s.sendError(r.conn, r.id, "no groups found for: "+strings.Join(notFound, ", "))
+		s.sendError(r, kerrors.Errorf(kerrors.CodeNotFound, "no groups found for: %s", strings.Join(notFound, ", ")).
+			WithDetails(map[string]any{"not_found": notFound}))
+		return socketContinue
+	}
+	version, strict, err := versionFromData(r.data)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	if strict {
+		if len(matchedGroups) != 1 {
+			s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "state_version requires a single group, not %d", len(matchedGroups)))
+			return socketContinue
+		}
+		if r.ctx, err = s.groups.WithVersionCheck(r.ctx, matchedGroups[0].ID, version); err != nil {
+			s.sendError(r, err)
+			return socketContinue
+		}
+	}
+
+	transition, err := transitionFromData(r.data)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	if err := kelvinFromData(r.data, r.data); err != nil {
+		s.sendError(r, fmt.Errorf("%w for set_group_state", err))
+		return socketContinue
+	}
+	ctx, results := group.WithResults(r.ctx)
+	if transition > 0 {
+		change, err := stateChangeFromData(r.data)
+		if err != nil {
+			s.sendError(r, fmt.Errorf("%w for set_group_state", err))
+			return socketContinue
+		}
+		var errs []string
+		for _, grp := range matchedGroups {
+			if err := s.groups.TransitionGroup(ctx, grp.ID, change, transition); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
+			}
+		}
+		s.sendGroupChange(r, errs, results)
 		return socketContinue
 	}
 
@@ -627,25 +1777,42 @@ func (s *Server) handleSetGroupState(r socketRequest) socketActionResult {
 			props = append(props, propVal{"temperature", v})
 		}
 	}
-	if len(props) == 0 {
-		s.sendError(r.conn, r.id, "missing property/value or on/brightness/temperature for set_group_state")
+	hue, saturation, err := colorFromData(r.data)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("%w for set_group_state", err))
+		return socketContinue
+	}
+	setColor := hue != nil || saturation != nil
+	if len(props) == 0 && !setColor {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing property/value or on/brightness/temperature/hue/saturation for set_group_state"))
 		return socketContinue
 	}
 
 	var errs []string
 	for _, grp := range matchedGroups {
 		for _, p := range props {
-			if err := s.setGroupProperty(r.ctx, grp.ID, p.name, p.value); err != nil {
+			if err := s.setGroupProperty(ctx, grp.ID, p.name, p.value); err != nil {
+				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
+			}
+		}
+		if setColor {
+			if err := s.groups.SetGroupColor(ctx, grp.ID, hue, saturation); err != nil {
 				errs = append(errs, fmt.Sprintf("group %s: %s", grp.ID, err))
 			}
 		}
 	}
+	s.sendGroupChange(r, errs, results)
+	return socketContinue
+}
+
+// sendGroupChange responds to a change to groups that failed with errs,
+// reporting what it did to each light.
+func (s *Server) sendGroupChange(r socketRequest, errs []string, results *group.Results) {
 	if len(errs) > 0 {
-		s.sendResponse(r.conn, r.id, map[string]any{"status": "partial", "errors": errs})
-		return socketContinue
+		s.sendResponse(r, map[string]any{"status": "partial", "errors": errs, "lights": results.Lights()})
+		return
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok"})
-	return socketContinue
+	s.sendResponse(r, map[string]any{"status": "ok", "lights": results.Lights()})
 }
 
 func (s *Server) handleAPIKeyAdd(r socketRequest) socketActionResult {
@@ -656,18 +1823,18 @@ func (s *Server) handleAPIKeyAdd(r socketRequest) socketActionResult {
 		// Backward compatibility: accept plain seconds from legacy socket clients.
 		expiresInSecs, err2 := strconv.ParseFloat(expiresInStr, 64)
 		if err2 != nil {
-			s.sendError(r.conn, r.id, fmt.Sprintf("invalid expires_in format (use duration like '720h', '30d', or seconds): %s", err))
+			s.sendError(r, fmt.Errorf("invalid expires_in format (use duration like '720h', '30d', or seconds): %w", err))
 			return socketContinue
 		}
 		expiresIn = time.Duration(expiresInSecs * float64(time.Second))
 	}
 	if name == "" {
-		s.sendError(r.conn, r.id, "missing name for apikey_add")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing name for apikey_add"))
 		return socketContinue
 	}
 	apiKey, err := s.apikeyManager.CreateAPIKey(name, expiresIn)
 	if err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to create API key: %s", err))
+		s.sendError(r, fmt.Errorf("failed to create API key: %w", err))
 		return socketContinue
 	}
 	// Construct a map with lowercase keys for the client
@@ -679,7 +1846,7 @@ func (s *Server) handleAPIKeyAdd(r socketRequest) socketActionResult {
 		"last_used_at": apiKey.LastUsedAt.Format(time.RFC3339Nano),
 		"disabled":     apiKey.IsDisabled(),
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok", "key": apiKeyResponse})
+	s.sendResponse(r, map[string]any{"status": "ok", "key": apiKeyResponse})
 	return socketContinue
 }
 
@@ -694,23 +1861,24 @@ func (s *Server) handleAPIKeyList(r socketRequest) socketActionResult {
 			"expires_at":   k.ExpiresAt.Format(time.RFC3339Nano),
 			"last_used_at": k.LastUsedAt.Format(time.RFC3339Nano),
 			"disabled":     k.IsDisabled(),
+			"scopes":       k.Scopes,
 		}
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok", "keys": responseKeys})
+	s.sendResponse(r, map[string]any{"status": "ok", "keys": responseKeys})
 	return socketContinue
 }
 
 func (s *Server) handleAPIKeyDelete(r socketRequest) socketActionResult {
 	key, _ := r.data["key"].(string)
 	if key == "" {
-		s.sendError(r.conn, r.id, "missing key for apikey_delete")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing key for apikey_delete"))
 		return socketContinue
 	}
 	if err := s.apikeyManager.DeleteAPIKey(key); err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to delete API key: %s", err))
+		s.sendError(r, fmt.Errorf("failed to delete API key: %w", err))
 		return socketContinue
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok"})
+	s.sendResponse(r, map[string]any{"status": "ok"})
 	return socketContinue
 }
 
@@ -718,7 +1886,7 @@ func (s *Server) handleAPIKeySetDisabledStatus(r socketRequest) socketActionResu
 	keyOrName, _ := r.data["key_or_name"].(string)
 
 	if keyOrName == "" {
-		s.sendError(r.conn, r.id, "missing key_or_name for apikey_set_disabled_status")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing key_or_name for apikey_set_disabled_status"))
 		return socketContinue
 	}
 
@@ -731,62 +1899,124 @@ func (s *Server) handleAPIKeySetDisabledStatus(r socketRequest) socketActionResu
 		var err error
 		disabled, err = strconv.ParseBool(v)
 		if err != nil {
-			s.sendError(r.conn, r.id, fmt.Sprintf("invalid boolean value for disabled state: %s", err))
+			s.sendError(r, fmt.Errorf("invalid boolean value for disabled state: %w", err))
 			return socketContinue
 		}
 	default:
-		s.sendError(r.conn, r.id, "missing or invalid disabled state for apikey_set_disabled_status")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing or invalid disabled state for apikey_set_disabled_status"))
 		return socketContinue
 	}
 
 	updatedKey, err := s.apikeyManager.SetAPIKeyDisabledStatus(keyOrName, disabled)
 	if err != nil {
-		s.sendError(r.conn, r.id, fmt.Sprintf("failed to set API key disabled status: %s", err))
+		s.sendError(r, fmt.Errorf("failed to set API key disabled status: %w", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"status": "ok", "key": updatedKey})
+	return socketContinue
+}
+
+// uiKeyScopes are the scopes of API keys issued to local UIs by
+// apikey_bootstrap: enough to show and control lights, but not to manage keys
+// or change configuration.
+var uiKeyScopes = []string{mw.ScopeRead, mw.ScopeControl}
+
+// uiClientPattern matches the client names accepted by apikey_bootstrap.
+var uiClientPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// handleAPIKeyBootstrap issues an API key for a local UI such as the tray or
+// GNOME extension, so it can use the HTTP API without the user copying a key.
+// Being able to connect to the socket is the authorization. Each client has
+// one key, named "ui-<client>", which is replaced on every request.
+func (s *Server) handleAPIKeyBootstrap(r socketRequest) socketActionResult {
+	clientName, _ := r.data["client"].(string)
+	if !uiClientPattern.MatchString(clientName) {
+		s.sendError(r, kerrors.InvalidInputf("client must be 1-32 lowercase letters, digits or dashes, got %q", clientName))
+		return socketContinue
+	}
+	apiKey, err := s.apikeyManager.ReissueAPIKey("ui-"+clientName, uiKeyScopes)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to create API key: %w", err))
 		return socketContinue
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{"status": "ok", "key": updatedKey})
+	s.sendResponse(r, map[string]any{
+		"status": "ok",
+		"key": map[string]any{
+			"name":         apiKey.Name,
+			"key":          apiKey.Key,
+			"created_at":   apiKey.CreatedAt.Format(time.RFC3339Nano),
+			"expires_at":   apiKey.ExpiresAt.Format(time.RFC3339Nano),
+			"last_used_at": apiKey.LastUsedAt.Format(time.RFC3339Nano),
+			"disabled":     apiKey.IsDisabled(),
+			"scopes":       apiKey.Scopes,
+		},
+		"api_url": s.localAPIURL(),
+	})
 	return socketContinue
 }
 
+// apiTCPAddress returns the first TCP address the HTTP API is served on, or
+// "" if it is only served on Unix sockets or not at all.
+func (s *Server) apiTCPAddress() string {
+	for _, l := range s.cfg.Config.API.Listeners() {
+		if _, unix := l.UnixPath(); !unix {
+			return l.Address
+		}
+	}
+	return ""
+}
+
+// localAPIURL returns the URL local clients reach the HTTP API on over TCP,
+// or "" if it is not served.
+func (s *Server) localAPIURL() string {
+	api := s.cfg.Config.API
+	address := s.apiTCPAddress()
+	if address == "" {
+		return ""
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	scheme := "http"
+	if api.TLS.Enabled() {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port) + api.NormalizedBasePath()
+}
+
 func (s *Server) handleSubscribeEvents(r socketRequest) socketActionResult {
 	// Acknowledge the subscription, then switch to streaming mode.
-	s.sendResponse(r.conn, r.id, map[string]any{"subscribed": true})
-	s.handleEventSubscription(r.ctx, r.conn)
+	conn, ok := r.conn.(net.Conn)
+	if !ok {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidRequest, "event subscription requires a socket connection"))
+		return socketContinue
+	}
+	filter, err := eventFilterFromData(r.data)
+	if err != nil {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid event filter: %s", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"subscribed": true})
+	s.handleEventSubscription(r.ctx, conn, filter)
 	return socketReturn // Connection is done after event streaming ends
 }
 
 func (s *Server) handleHealth(r socketRequest) socketActionResult {
-	s.sendResponse(r.conn, r.id, map[string]any{"health": "ok"})
+	s.sendResponse(r, map[string]any{"health": "ok"})
 	return socketContinue
 }
 
 func (s *Server) handleListFilters(r socketRequest) socketActionResult {
-	filters := logfilter.GetFilters()
-	level := logfilter.GetLevel()
-
-	filterList := make([]map[string]any, len(filters))
-	for i, f := range filters {
-		fm := map[string]any{
-			"type":    f.Type,
-			"pattern": f.Pattern,
-			"level":   f.Level,
-			"enabled": f.Enabled,
-		}
-		if f.OutputLevel != "" {
-			fm["output_level"] = f.OutputLevel
-		}
-		if f.ExpiresAt != nil {
-			fm["expires_at"] = f.ExpiresAt.Format(time.RFC3339Nano)
-		}
-		filterList[i] = fm
-	}
-
-	s.sendResponse(r.conn, r.id, map[string]any{
-		"level":   handlers.LevelToString(level),
-		"filters": filterList,
-	})
-	return socketContinue
-}
+	s.sendResponse(r, map[string]any{
+		"level":   handlers.LevelToString(logfilter.GetLevel()),
+		"filters": filterMaps(logfilter.GetFilters()),
+	})
+	return socketContinue
+}
 
 func (s *Server) handleSetFilters(r socketRequest) socketActionResult {
 	filtersRaw, _ := r.data["filters"].([]any)
@@ -796,33 +2026,121 @@ func (s *Server) handleSetFilters(r socketRequest) socketActionResult {
 		if !ok {
 			continue
 		}
-		f := logfilter.LogFilter{
-			Type:        stringFromMap(fm, "type"),
-			Pattern:     stringFromMap(fm, "pattern"),
-			Level:       stringFromMap(fm, "level"),
-			OutputLevel: stringFromMap(fm, "output_level"),
-			Enabled:     boolFromMap(fm, "enabled"),
-		}
-		if expiresStr := stringFromMap(fm, "expires_at"); expiresStr != "" {
-			if t, err := time.Parse(time.RFC3339Nano, expiresStr); err == nil {
-				f.ExpiresAt = &t
-			}
-		}
-		newFilters = append(newFilters, f)
+		newFilters = append(newFilters, filterFromMap(fm))
 	}
 
 	if errs := logging.ValidateFilters(newFilters); len(errs) > 0 {
-		s.sendError(r.conn, r.id, "invalid filters: "+logging.FormatErrors(errs))
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid filters: %s", logging.FormatErrors(errs)))
 		return socketContinue
 	}
 
 	logfilter.SetFilters(newFilters)
-	s.logger.Info("Log filters updated via socket", "count", len(newFilters))
+	s.logger.InfoContext(r.ctx, "Log filters updated via socket", "count", len(newFilters))
 
 	// Return updated state
-	updatedFilters := logfilter.GetFilters()
-	resultFilters := make([]map[string]any, len(updatedFilters))
-	for i, f := range updatedFilters {
+	s.sendResponse(r, map[string]any{
+		"level":   handlers.LevelToString(logfilter.GetLevel()),
+		"filters": filterMaps(logfilter.GetFilters()),
+	})
+	return socketContinue
+}
+
+func (s *Server) handleAddFilter(r socketRequest) socketActionResult {
+	filter := filterFromMap(r.data)
+	if errs := logging.AddFilter(filter); len(errs) > 0 {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid filter: %s", logging.FormatErrors(errs)))
+		return socketContinue
+	}
+	s.logger.InfoContext(r.ctx, "Log filter added via socket", "type", filter.Type, "pattern", filter.Pattern, "level", filter.Level)
+	s.sendResponse(r, map[string]any{
+		"level":   handlers.LevelToString(logfilter.GetLevel()),
+		"filters": filterMaps(logfilter.GetFilters()),
+	})
+	return socketContinue
+}
+
+func (s *Server) handleRemoveFilter(r socketRequest) socketActionResult {
+	filterType := stringFromMap(r.data, "type")
+	pattern := stringFromMap(r.data, "pattern")
+	if filterType == "" || pattern == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing type or pattern for remove_filter"))
+		return socketContinue
+	}
+	if !logging.RemoveFilter(filterType, pattern) {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeNotFound, "no log filter with type %q and pattern %q", filterType, pattern))
+		return socketContinue
+	}
+	s.logger.InfoContext(r.ctx, "Log filter removed via socket", "type", filterType, "pattern", pattern)
+	s.sendResponse(r, map[string]any{"status": "ok"})
+	return socketContinue
+}
+
+func (s *Server) handleGetLevel(r socketRequest) socketActionResult {
+	s.sendResponse(r, map[string]any{"level": handlers.LevelToString(logfilter.GetLevel())})
+	return socketContinue
+}
+
+func (s *Server) handleGetLogs(r socketRequest) socketActionResult {
+	q, err := logQueryFromData(r.data)
+	if err != nil {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "%s", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"entries": handlers.LogEntriesToResponse(s.logBuffer.Entries(q))})
+	return socketContinue
+}
+
+func (s *Server) handleFollowLogs(r socketRequest) socketActionResult {
+	conn, ok := r.conn.(net.Conn)
+	if !ok {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidRequest, "following logs requires a socket connection"))
+		return socketContinue
+	}
+	q, err := logQueryFromData(r.data)
+	if err != nil {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "%s", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"following": true})
+	s.handleLogFollow(r.ctx, conn, q)
+	return socketReturn // Connection is done after log streaming ends
+}
+
+// logQueryFromData decodes a log query from a get_logs or follow_logs
+// payload.
+func logQueryFromData(data map[string]any) (logging.Query, error) {
+	limit := 0
+	if v, ok := data["limit"]; ok {
+		f, ok := v.(float64)
+		if !ok || f != float64(int(f)) {
+			return logging.Query{}, errors.New("limit must be a whole number")
+		}
+		limit = int(f)
+	}
+	return logging.ParseQuery(stringFromMap(data, "level"), stringFromMap(data, "since"), limit, time.Now())
+}
+
+// filterFromMap builds a log filter from its socket representation.
+func filterFromMap(fm map[string]any) logfilter.LogFilter {
+	f := logfilter.LogFilter{
+		Type:        stringFromMap(fm, "type"),
+		Pattern:     stringFromMap(fm, "pattern"),
+		Level:       stringFromMap(fm, "level"),
+		OutputLevel: stringFromMap(fm, "output_level"),
+		Enabled:     boolFromMap(fm, "enabled"),
+	}
+	if expiresStr := stringFromMap(fm, "expires_at"); expiresStr != "" {
+		if t, err := time.Parse(time.RFC3339Nano, expiresStr); err == nil {
+			f.ExpiresAt = &t
+		}
+	}
+	return f
+}
+
+// filterMaps converts log filters to their socket representation.
+func filterMaps(filters []logfilter.LogFilter) []map[string]any {
+	filterList := make([]map[string]any, len(filters))
+	for i, f := range filters {
 		fm := map[string]any{
 			"type":    f.Type,
 			"pattern": f.Pattern,
@@ -835,35 +2153,31 @@ func (s *Server) handleSetFilters(r socketRequest) socketActionResult {
 		if f.ExpiresAt != nil {
 			fm["expires_at"] = f.ExpiresAt.Format(time.RFC3339Nano)
 		}
-		resultFilters[i] = fm
+		filterList[i] = fm
 	}
-	s.sendResponse(r.conn, r.id, map[string]any{
-		"level":   handlers.LevelToString(logfilter.GetLevel()),
-		"filters": resultFilters,
-	})
-	return socketContinue
+	return filterList
 }
 
 func (s *Server) handleSetLevel(r socketRequest) socketActionResult {
 	level, _ := r.data["level"].(string)
 	if level == "" {
-		s.sendError(r.conn, r.id, "missing level for set_level")
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing level for set_level"))
 		return socketContinue
 	}
 	validated := utils.ValidateLogLevel(level)
 	if validated != level {
-		s.sendError(r.conn, r.id, fmt.Sprintf("invalid log level %q; must be debug, info, warn, or error", level))
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid log level %q; must be debug, info, warn, or error", level))
 		return socketContinue
 	}
 	newLevel := utils.GetLogLevel(validated)
 	logfilter.SetLevel(newLevel)
-	s.logger.Info("Log level changed via socket", "level", validated)
-	s.sendResponse(r.conn, r.id, map[string]any{"level": validated})
+	s.logger.InfoContext(r.ctx, "Log level changed via socket", "level", validated)
+	s.sendResponse(r, map[string]any{"level": validated})
 	return socketContinue
 }
 
 func (s *Server) handleVersion(r socketRequest) socketActionResult {
-	s.sendResponse(r.conn, r.id, map[string]any{
+	s.sendResponse(r, map[string]any{
 		"version":    s.versionInfo.Version,
 		"commit":     s.versionInfo.Commit,
 		"build_date": s.versionInfo.BuildDate,
@@ -871,36 +2185,214 @@ func (s *Server) handleVersion(r socketRequest) socketActionResult {
 	return socketContinue
 }
 
-func (s *Server) sendResponse(conn net.Conn, id string, data map[string]any) {
-	response := map[string]any{"status": "ok"}
-	if id != "" {
-		response["id"] = id
+// StopRequested is closed when a client asks the daemon to shut down, such as
+// a new keylightd started with --takeover. The caller should then call Stop.
+func (s *Server) StopRequested() <-chan struct{} {
+	return s.stopRequested
+}
+
+func (s *Server) handleShutdown(r socketRequest) socketActionResult {
+	s.logger.InfoContext(r.ctx, "Shutdown requested over the socket")
+	s.sendResponse(r, map[string]any{"message": "shutting down"})
+	s.stopOnce.Do(func() { close(s.stopRequested) })
+	return socketReturn
+}
+
+func (s *Server) handleGetDaemonInfo(r socketRequest) socketActionResult {
+	s.sendResponse(r, map[string]any{"info": s.daemonInfo()})
+	return socketContinue
+}
+
+func (s *Server) handleGetLightStats(r socketRequest) socketActionResult {
+	lightID, _ := r.data["id"].(string)
+	if lightID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing light ID for get_light_stats"))
+		return socketContinue
+	}
+	st, err := s.stats.Light(lightID)
+	if err != nil {
+		s.sendError(r, err)
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"stats": handlers.LightStatsFromInternal(st)})
+	return socketContinue
+}
+
+func (s *Server) handleGetGroupStats(r socketRequest) socketActionResult {
+	groupID, _ := r.data["id"].(string)
+	if groupID == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing group ID for get_group_stats"))
+		return socketContinue
+	}
+	grp, err := s.groups.GetGroup(groupID)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to get group %s: %w", groupID, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"stats": handlers.GroupStatsFromInternal(grp.ID, s.stats.Group(grp.Lights))})
+	return socketContinue
+}
+
+func (s *Server) handleGetCircadian(r socketRequest) socketActionResult {
+	s.sendResponse(r, map[string]any{"circadian": s.circadian.Status()})
+	return socketContinue
+}
+
+func (s *Server) handleSetCircadian(r socketRequest) socketActionResult {
+	enabled, ok := r.data["enabled"].(bool)
+	if !ok {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing or invalid enabled value for set_circadian"))
+		return socketContinue
+	}
+	status, err := s.circadian.SetEnabled(enabled)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set circadian mode: %w", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"circadian": status})
+	return socketContinue
+}
+
+func (s *Server) handleGetDesiredState(r socketRequest) socketActionResult {
+	s.sendResponse(r, map[string]any{"desired_state": s.desired.Status()})
+	return socketContinue
+}
+
+func (s *Server) handleSetDesiredState(r socketRequest) socketActionResult {
+	enabled, ok := r.data["enabled"].(bool)
+	if !ok {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing or invalid enabled value for set_desired_state"))
+		return socketContinue
 	}
+	status, err := s.desired.SetEnabled(enabled)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set desired-state mode: %w", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"desired_state": status})
+	return socketContinue
+}
+
+func (s *Server) handleSetDesiredStateTarget(r socketRequest) socketActionResult {
+	target, _ := r.data["target"].(string)
+	if target == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing target for set_desired_state_target"))
+		return socketContinue
+	}
+	enabled, ok := r.data["enabled"].(bool)
+	if !ok {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing or invalid enabled value for set_desired_state_target"))
+		return socketContinue
+	}
+	status, err := s.desired.SetTargetEnabled(target, enabled)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set desired-state target %s: %w", target, err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"desired_state": status})
+	return socketContinue
+}
+
+func (s *Server) handleExportConfig(r socketRequest) socketActionResult {
+	doc := s.backup.Export(boolFromMap(r.data, "include_secrets"))
+	s.sendResponse(r, map[string]any{"document": doc})
+	return socketContinue
+}
+
+func (s *Server) handleImportConfig(r socketRequest) socketActionResult {
+	raw, ok := r.data["document"]
+	if !ok {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing document for import_config"))
+		return socketContinue
+	}
+	var doc backup.ExportDocument
+	b, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(b, &doc)
+	}
+	if err != nil {
+		s.sendError(r, fmt.Errorf("invalid document for import_config: %w", err))
+		return socketContinue
+	}
+	result, err := s.backup.Import(r.ctx, &doc)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to import config: %w", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"result": result})
+	return socketContinue
+}
+
+func (s *Server) handleGetWebcam(r socketRequest) socketActionResult {
+	s.sendResponse(r, map[string]any{"webcam": s.webcam.Status()})
+	return socketContinue
+}
+
+func (s *Server) handleSetWebcamOverride(r socketRequest) socketActionResult {
+	override, _ := r.data["override"].(string)
+	if override == "" {
+		s.sendError(r, kerrors.Errorf(kerrors.CodeInvalidInput, "missing override for set_webcam_override"))
+		return socketContinue
+	}
+	status, err := s.webcam.SetOverride(override)
+	if err != nil {
+		s.sendError(r, fmt.Errorf("failed to set webcam override: %w", err))
+		return socketContinue
+	}
+	s.sendResponse(r, map[string]any{"webcam": status})
+	return socketContinue
+}
+
+// sendResponse sends a successful response to a request, echoing its id and
+// request ID.
+func (s *Server) sendResponse(r socketRequest, data map[string]any) {
+	response := map[string]any{"status": "ok"}
+	s.addRequestIDs(r, response)
 	maps.Copy(response, data)
-	if err := json.NewEncoder(conn).Encode(response); err != nil {
-		s.logger.Error("Failed to send response", "error", err)
+	if err := json.NewEncoder(r.conn).Encode(response); err != nil {
+		s.logger.ErrorContext(r.ctx, "Failed to send response", "error", err)
 	}
 }
 
-func (s *Server) sendError(conn net.Conn, id string, message string) {
-	s.logger.Error("Sending error response to client", "id", id, "message", message)
-	response := map[string]any{"error": message}
-	if id != "" {
-		response["id"] = id
+// sendError sends an error response. The response carries the error's code
+// and details from the shared error taxonomy alongside its message.
+func (s *Server) sendError(r socketRequest, err error) {
+	apiErr := kerrors.FromError(err)
+	s.logger.ErrorContext(r.ctx, "Sending error response to client", "id", r.id, "code", apiErr.Code, "message", apiErr.Message)
+	response := map[string]any{"error": apiErr.Message, "code": apiErr.Code}
+	if len(apiErr.Details) > 0 {
+		response["details"] = apiErr.Details
 	}
-	if err := json.NewEncoder(conn).Encode(response); err != nil {
-		s.logger.Error("Failed to send error response", "error", err)
+	s.addRequestIDs(r, response)
+	if err := json.NewEncoder(r.conn).Encode(response); err != nil {
+		s.logger.ErrorContext(r.ctx, "Failed to send error response", "error", err)
+	}
+}
+
+// addRequestIDs adds the client's id, if it sent one, and the request ID to a
+// response.
+func (s *Server) addRequestIDs(r socketRequest, response map[string]any) {
+	if r.id != "" {
+		response["id"] = r.id
+	}
+	if requestID := requestid.FromContext(r.ctx); requestID != "" {
+		response["request_id"] = requestID
 	}
 }
 
 // handleEventSubscription streams events to a socket client until the connection
 // closes or the server shuts down. Events are sent as newline-delimited JSON,
 // using the same events.Event format as the WebSocket endpoint.
-func (s *Server) handleEventSubscription(ctx context.Context, conn net.Conn) {
+func (s *Server) handleEventSubscription(ctx context.Context, conn net.Conn, filter events.Filter) {
+	s.subscribers.Add(1)
+	defer s.subscribers.Add(-1)
 	eventCh := make(chan []byte, 64)
 
-	// Subscribe to the event bus
+	// Subscribe to the event bus, passing on only the events the client asked for
 	unsub := s.eventBus.Subscribe(func(e events.Event) {
+		if !filter.Match(e, s.resolveEventGroups) {
+			return
+		}
 		data, err := json.Marshal(e)
 		if err != nil {
 			s.logger.Error("socket events: failed to marshal event", "error", err)
@@ -930,7 +2422,7 @@ func (s *Server) handleEventSubscription(ctx context.Context, conn net.Conn) {
 		}
 	}()
 
-	s.logger.Info("socket events: client subscribed")
+	s.logger.Info("socket events: client subscribed", "filtered", !filter.IsZero())
 
 	for {
 		select {
@@ -951,8 +2443,60 @@ func (s *Server) handleEventSubscription(ctx context.Context, conn net.Conn) {
 	}
 }
 
-// setLightProperty sets a single property on a light by name.
+// handleLogFollow streams log entries to a socket client as newline-delimited
+// JSON: first the recent entries selected by q, then new ones as they are
+// logged, until the connection closes or the server shuts down. Nothing is
+// logged while following, as it would be streamed back to the client.
+func (s *Server) handleLogFollow(ctx context.Context, conn net.Conn, q logging.Query) {
+	backlog, entries, cancel := s.logBuffer.Follow(q)
+	defer cancel()
+
+	connCtx, connCancel := context.WithCancel(ctx)
+	defer connCancel()
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				connCancel()
+				return
+			}
+		}
+	}()
+
+	write := func(e logging.Entry) bool {
+		data, err := json.Marshal(handlers.LogEntryToResponse(e))
+		if err != nil {
+			return false
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		_, err = conn.Write(append(data, '\n'))
+		return err == nil
+	}
+	for _, e := range backlog {
+		if !write(e) {
+			return
+		}
+	}
+	for {
+		select {
+		case <-connCtx.Done():
+			return
+		case e := <-entries:
+			if !write(e) {
+				return
+			}
+		}
+	}
+}
+
+// setLightProperty sets a single property on a light by name. Brightness and
+// temperature also accept relative string values such as "+10" or "-200K".
 func (s *Server) setLightProperty(ctx context.Context, lightID, property string, value any) error {
+	if adj, relative, err := adjustmentFromValue(property, value); err != nil {
+		return err
+	} else if relative {
+		return s.lights.AdjustLight(ctx, lightID, adj)
+	}
 	switch property {
 	case "on":
 		onVal, ok := value.(bool)
@@ -972,13 +2516,26 @@ func (s *Server) setLightProperty(ctx context.Context, lightID, property string,
 			return errors.New("invalid value type for 'temperature', expected number")
 		}
 		return s.lights.SetLightTemperature(ctx, lightID, int(tVal))
+	case "hue", "saturation":
+		hue, saturation, err := colorFromData(map[string]any{property: value})
+		if err != nil {
+			return err
+		}
+		color, _ := keylight.ColorChange(hue, saturation)
+		return s.lights.SetLightState(ctx, lightID, color)
 	default:
 		return fmt.Errorf("unknown property: %s", property)
 	}
 }
 
-// setGroupProperty sets a single property on a group by name.
+// setGroupProperty sets a single property on a group by name. Brightness and
+// temperature also accept relative string values such as "+10" or "-200K".
 func (s *Server) setGroupProperty(ctx context.Context, groupID, property string, value any) error {
+	if adj, relative, err := adjustmentFromValue(property, value); err != nil {
+		return err
+	} else if relative {
+		return s.groups.AdjustGroup(ctx, groupID, adj)
+	}
 	switch property {
 	case "on":
 		onVal, ok := value.(bool)
@@ -998,11 +2555,264 @@ func (s *Server) setGroupProperty(ctx context.Context, groupID, property string,
 			return errors.New("invalid value type for 'temperature', expected number")
 		}
 		return s.groups.SetGroupTemperature(ctx, groupID, int(tVal))
+	case "hue", "saturation":
+		hue, saturation, err := colorFromData(map[string]any{property: value})
+		if err != nil {
+			return err
+		}
+		return s.groups.SetGroupColor(ctx, groupID, hue, saturation)
 	default:
 		return fmt.Errorf("unknown property: %s", property)
 	}
 }
 
+// adjustmentFromValue interprets a string brightness or temperature value as a
+// relative adjustment. relative is false for any other property or value type.
+func adjustmentFromValue(property string, value any) (adj keylight.Adjustment, relative bool, err error) {
+	str, ok := value.(string)
+	if !ok || (property != "brightness" && property != "temperature") {
+		return adj, false, nil
+	}
+	delta, relative, err := keylight.ParseDelta(str)
+	if err != nil {
+		return adj, false, err
+	}
+	if !relative {
+		return adj, false, fmt.Errorf("invalid value for '%s', expected number or relative value such as \"+10\"", property)
+	}
+	if property == "brightness" {
+		adj.Brightness = delta
+	} else {
+		adj.Temperature = delta
+	}
+	return adj, true, nil
+}
+
+// groupToMap converts a group to its socket response representation.
+func groupToMap(g *group.Group) map[string]any {
+	lights := g.Lights
+	if lights == nil {
+		lights = []string{}
+	}
+	m := map[string]any{"id": g.ID, "name": g.Name, "lights": lights}
+	if g.Defaults != nil {
+		m["defaults"] = g.Defaults
+	}
+	for key, value := range map[string]string{"description": g.Description, "icon": g.Icon, "color": g.Color, "legacy_id": g.LegacyID} {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	if !g.CreatedAt.IsZero() {
+		m["created_at"] = g.CreatedAt
+	}
+	if !g.UpdatedAt.IsZero() {
+		m["updated_at"] = g.UpdatedAt
+	}
+	return m
+}
+
+// groupWithLimits returns groupToMap(g) with the group's effective limits.
+func (s *Server) groupWithLimits(g *group.Group) map[string]any {
+	m := groupToMap(g)
+	if limits, err := s.groups.GroupLimits(g.ID); err == nil {
+		m["limits"] = limits
+	}
+	return m
+}
+
+// transitionFromData reads the optional transition_ms field from a socket request payload.
+func transitionFromData(data map[string]any) (time.Duration, error) {
+	v, ok := data["transition_ms"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	ms, ok := v.(float64)
+	if !ok || ms < 0 {
+		return 0, errors.New("invalid value for 'transition_ms', expected non-negative number")
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// kelvinFromData converts the temperature of a state payload to Kelvin, in
+// place, if the units field of request is mired. Relative values such as
+// "-200K" are always in Kelvin.
+func kelvinFromData(data, request map[string]any) error {
+	units, ok := request["units"].(string)
+	if !ok && request["units"] != nil {
+		return kerrors.Errorf(kerrors.CodeInvalidInput, "invalid value type for 'units', expected string")
+	}
+	if err := keylight.ValidateTemperatureUnits(units); err != nil {
+		return err
+	}
+	key := "temperature"
+	if property, _ := data["property"].(string); property != "" && data["value"] != nil {
+		if property != "temperature" {
+			return nil
+		}
+		key = "value"
+	}
+	value, ok := data[key].(float64)
+	if !ok {
+		return nil
+	}
+	kelvin, err := keylight.TemperatureToKelvin(int(value), units)
+	if err != nil {
+		return err
+	}
+	data[key] = float64(kelvin)
+	return nil
+}
+
+// versionFromData returns the state_version of a socket request payload, and
+// whether it has one. Changes are then only made if the light or group is
+// still at that version.
+func versionFromData(data map[string]any) (uint64, bool, error) {
+	v, ok := data["state_version"]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	version, ok := v.(float64)
+	if !ok || version < 0 || version != math.Trunc(version) {
+		return 0, false, kerrors.Errorf(kerrors.CodeInvalidInput, "invalid value for 'state_version', expected non-negative integer")
+	}
+	return uint64(version), true, nil
+}
+
+// stateChangeFromData builds a multi-property state change from either the
+// property/value or the on/brightness/temperature form of a socket request payload.
+func stateChangeFromData(data map[string]any) (keylight.StateChange, error) {
+	var change keylight.StateChange
+	fields := data
+	if property, _ := data["property"].(string); property != "" && data["value"] != nil {
+		fields = map[string]any{property: data["value"]}
+	}
+	for property, value := range fields {
+		switch property {
+		case "on":
+			onVal, ok := value.(bool)
+			if !ok {
+				return change, errors.New("invalid value type for 'on', expected boolean")
+			}
+			change.On = &onVal
+		case "brightness", "temperature":
+			num, ok := value.(float64)
+			if !ok {
+				return change, fmt.Errorf("invalid value type for '%s', expected number", property)
+			}
+			n := int(num)
+			if property == "brightness" {
+				change.Brightness = &n
+			} else {
+				change.Temperature = &n
+			}
+		}
+	}
+	hue, saturation, err := colorFromData(fields)
+	if err != nil {
+		return change, err
+	}
+	change.Hue, change.Saturation = hue, saturation
+	if change.IsEmpty() {
+		return change, errors.New("missing property/value or on/brightness/temperature/hue/saturation")
+	}
+	return change, nil
+}
+
+// colorFromData returns the hue and saturation in a socket request payload,
+// each nil if not given.
+func colorFromData(data map[string]any) (hue, saturation *float64, err error) {
+	for _, property := range []string{"hue", "saturation"} {
+		value, ok := data[property]
+		if !ok {
+			continue
+		}
+		num, ok := value.(float64)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid value type for '%s', expected number", property)
+		}
+		if property == "hue" {
+			hue = &num
+		} else {
+			saturation = &num
+		}
+	}
+	return hue, saturation, nil
+}
+
+// settingsUpdateFromData reads the power-on settings to change from a socket
+// request payload.
+func settingsUpdateFromData(data map[string]any) (keylight.LightSettingsUpdate, error) {
+	var update keylight.LightSettingsUpdate
+	if v, ok := data["power_on_behavior"]; ok && v != nil {
+		behavior, ok := v.(string)
+		if !ok {
+			return update, errors.New("invalid value type for 'power_on_behavior', expected string")
+		}
+		update.PowerOnBehavior = &behavior
+	}
+	for property, dst := range map[string]**int{"power_on_brightness": &update.PowerOnBrightness, "power_on_temperature": &update.PowerOnTemperature} {
+		v, ok := data[property]
+		if !ok || v == nil {
+			continue
+		}
+		num, ok := v.(float64)
+		if !ok {
+			return update, fmt.Errorf("invalid value type for '%s', expected number", property)
+		}
+		n := int(num)
+		*dst = &n
+	}
+	if update.IsEmpty() {
+		return update, errors.New("missing power_on_behavior, power_on_brightness or power_on_temperature")
+	}
+	return update, nil
+}
+
+// groupUpdateFromData reads a group's name and metadata to change from a
+// socket request payload.
+func groupUpdateFromData(data map[string]any) (group.Update, error) {
+	var update group.Update
+	for property, dst := range map[string]**string{"name": &update.Name, "description": &update.Description, "icon": &update.Icon, "color": &update.Color} {
+		v, ok := data[property]
+		if !ok || v == nil {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			return update, fmt.Errorf("invalid value type for '%s', expected string", property)
+		}
+		*dst = &s
+	}
+	if update.IsEmpty() {
+		return update, errors.New("missing name, description, icon or color")
+	}
+	return update, nil
+}
+
+// eventFilterFromData decodes an event filter from a subscribe_events payload.
+func eventFilterFromData(data map[string]any) (events.Filter, error) {
+	var filter events.Filter
+	b, err := json.Marshal(data)
+	if err != nil {
+		return filter, err
+	}
+	if err := json.Unmarshal(b, &filter); err != nil {
+		return filter, err
+	}
+	return filter, filter.Validate()
+}
+
+// resolveEventGroups resolves the groups of an event filter to their lights.
+func (s *Server) resolveEventGroups(keys []string) map[string][]string {
+	groups, _ := s.groups.GetGroupsByKeys(strings.Join(keys, ","))
+	resolved := make(map[string][]string, len(groups))
+	for _, g := range groups {
+		resolved[g.ID] = g.Lights
+	}
+	return resolved
+}
+
 // stringFromMap extracts a string from a map[string]any, returning "" if missing or wrong type.
 func stringFromMap(m map[string]any, key string) string {
 	v, _ := m[key].(string)
@@ -1,13 +1,20 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,11 +26,17 @@ import (
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
+// mockLightManager is an in-memory light manager. Like keylight.Manager it
+// hands out copies of its lights, so the server's background workers can read
+// them while requests change them.
 type mockLightManager struct {
+	mu     sync.Mutex
 	lights map[string]*keylight.Light
 }
 
 func (m *mockLightManager) AddLight(_ context.Context, light keylight.Light) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.lights == nil {
 		m.lights = make(map[string]*keylight.Light)
 	}
@@ -31,78 +44,196 @@ func (m *mockLightManager) AddLight(_ context.Context, light keylight.Light) {
 }
 
 func (m *mockLightManager) RemoveLight(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	delete(m.lights, id)
 }
 
 func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
-	light, ok := m.lights[id]
-	if !ok {
-		return nil, fmt.Errorf("light %s not found", id)
-	}
-	return light, nil
+	return m.update(id, func(*keylight.Light) error { return nil })
 }
 
 func (m *mockLightManager) GetLights() map[string]*keylight.Light {
-	return m.lights
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lights := make(map[string]*keylight.Light, len(m.lights))
+	for id, light := range m.lights {
+		l := *light
+		lights[id] = &l
+	}
+	return lights
+}
+
+func (m *mockLightManager) RefreshLights(_ context.Context) map[string]*keylight.Light {
+	return m.GetLights()
 }
 
 func (m *mockLightManager) GetDiscoveredLights() []*keylight.Light {
-	lights := make([]*keylight.Light, 0, len(m.lights))
-	for _, light := range m.lights {
-		lights = append(lights, light)
+	return slices.Collect(maps.Values(m.GetLights()))
+}
+
+// update applies change to the light with the given ID under the lock, and
+// returns a copy of the light as changed.
+func (m *mockLightManager) update(id string, change func(light *keylight.Light) error) (*keylight.Light, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", keylight.ErrLightNotFound, id)
 	}
-	return lights
+	if err := change(light); err != nil {
+		return nil, err
+	}
+	l := *light
+	return &l, nil
+}
+
+func (m *mockLightManager) SetLightBrightness(_ context.Context, id string, brightness int) error {
+	_, err := m.update(id, func(light *keylight.Light) error {
+		light.Brightness = brightness
+		return nil
+	})
+	return err
+}
+
+func (m *mockLightManager) SetLightTemperature(_ context.Context, id string, temperature int) error {
+	_, err := m.update(id, func(light *keylight.Light) error {
+		light.Temperature = temperature
+		return nil
+	})
+	return err
+}
+
+func (m *mockLightManager) SetLightPower(_ context.Context, id string, on bool) error {
+	_, err := m.update(id, func(light *keylight.Light) error {
+		light.On = on
+		return nil
+	})
+	return err
 }
 
-func (m *mockLightManager) SetLightBrightness(ctx context.Context, id string, brightness int) error {
-	light, err := m.GetLight(ctx, id)
+func (m *mockLightManager) SetLightState(_ context.Context, id string, propertyValue keylight.LightPropertyValue) error {
+	_, err := m.update(id, func(light *keylight.Light) error {
+		// First validate the property value
+		if err := propertyValue.Validate(); err != nil {
+			return err
+		}
+
+		switch propertyValue.PropertyName() {
+		case keylight.PropertyOn:
+			light.On = propertyValue.Value().(bool)
+		case keylight.PropertyBrightness:
+			light.Brightness = propertyValue.Value().(int)
+		case keylight.PropertyTemperature:
+			light.Temperature = propertyValue.Value().(int)
+		case keylight.PropertyHue:
+			hue := propertyValue.Value().(float64)
+			light.Hue = &hue
+		case keylight.PropertySaturation:
+			saturation := propertyValue.Value().(float64)
+			light.Saturation = &saturation
+		case keylight.PropertyColor:
+			color := propertyValue.(keylight.ColorValue)
+			hue, saturation := float64(color.Hue), float64(color.Saturation)
+			light.Hue, light.Saturation = &hue, &saturation
+		default:
+			return fmt.Errorf("unknown property: %s", propertyValue.PropertyName())
+		}
+		return nil
+	})
+	return err
+}
+
+func (m *mockLightManager) Transition(_ context.Context, id string, change keylight.StateChange, _ time.Duration) error {
+	_, err := m.update(id, func(light *keylight.Light) error {
+		if change.On != nil {
+			light.On = *change.On
+		}
+		if change.Brightness != nil {
+			light.Brightness = *change.Brightness
+		}
+		if change.Temperature != nil {
+			light.Temperature = *change.Temperature
+		}
+		return nil
+	})
+	return err
+}
+
+func (m *mockLightManager) ToggleLight(_ context.Context, id string) (bool, error) {
+	light, err := m.update(id, func(light *keylight.Light) error {
+		light.On = !light.On
+		return nil
+	})
 	if err != nil {
-		return err
+		return false, err
 	}
-	light.Brightness = brightness
-	return nil
+	return light.On, nil
 }
 
-func (m *mockLightManager) SetLightTemperature(ctx context.Context, id string, temperature int) error {
-	light, err := m.GetLight(ctx, id)
-	if err != nil {
-		return err
+func (m *mockLightManager) SetLightName(_ context.Context, id, name string) (*keylight.Light, error) {
+	return m.update(id, func(light *keylight.Light) error {
+		light.Name = name
+		return nil
+	})
+}
+
+func (m *mockLightManager) SetLightTags(_ context.Context, id string, tags map[string]string) (*keylight.Light, error) {
+	return m.update(id, func(light *keylight.Light) error {
+		if err := keylight.ValidateTags(tags); err != nil {
+			return err
+		}
+		light.Tags = tags
+		return nil
+	})
+}
+
+func (m *mockLightManager) SetDeviceName(ctx context.Context, id, name string) (*keylight.Light, error) {
+	return m.SetLightName(ctx, id, name)
+}
+
+func (m *mockLightManager) RawRequest(ctx context.Context, id, method, path string, body []byte) (*keylight.RawResponse, error) {
+	if _, err := m.GetLight(ctx, id); err != nil {
+		return nil, err
 	}
-	light.Temperature = temperature
-	return nil
+	echo, _ := json.Marshal(map[string]any{"method": method, "path": path, "body": string(body)})
+	return &keylight.RawResponse{StatusCode: 200, Body: echo}, nil
 }
 
-func (m *mockLightManager) SetLightPower(ctx context.Context, id string, on bool) error {
-	light, err := m.GetLight(ctx, id)
-	if err != nil {
-		return err
+func (m *mockLightManager) GetLightSettings(ctx context.Context, id string) (*keylight.LightSettings, error) {
+	if _, err := m.GetLight(ctx, id); err != nil {
+		return nil, err
 	}
-	light.On = on
-	return nil
+	return &keylight.LightSettings{PowerOnBehavior: keylight.PowerOnRestore, PowerOnBrightness: 20, PowerOnTemperature: 4700}, nil
 }
 
-func (m *mockLightManager) SetLightState(ctx context.Context, id string, propertyValue keylight.LightPropertyValue) error {
-	light, err := m.GetLight(ctx, id)
+func (m *mockLightManager) SetLightSettings(ctx context.Context, id string, update keylight.LightSettingsUpdate) (*keylight.LightSettings, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	settings, err := m.GetLightSettings(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// First validate the property value
-	if err := propertyValue.Validate(); err != nil {
-		return err
+	if update.PowerOnBehavior != nil {
+		settings.PowerOnBehavior = *update.PowerOnBehavior
 	}
-
-	switch propertyValue.PropertyName() {
-	case keylight.PropertyOn:
-		light.On = propertyValue.Value().(bool)
-	case keylight.PropertyBrightness:
-		light.Brightness = propertyValue.Value().(int)
-	case keylight.PropertyTemperature:
-		light.Temperature = propertyValue.Value().(int)
-	default:
-		return fmt.Errorf("unknown property: %s", propertyValue.PropertyName())
+	if update.PowerOnBrightness != nil {
+		settings.PowerOnBrightness = *update.PowerOnBrightness
+	}
+	if update.PowerOnTemperature != nil {
+		settings.PowerOnTemperature = *update.PowerOnTemperature
 	}
-	return nil
+	return settings, nil
+}
+
+func (m *mockLightManager) AdjustLight(_ context.Context, id string, adj keylight.Adjustment) error {
+	_, err := m.update(id, func(light *keylight.Light) error {
+		light.Brightness = max(config.MinBrightness, min(light.Brightness+adj.Brightness, config.MaxBrightness))
+		light.Temperature = max(config.MinTemperature, min(light.Temperature+adj.Temperature, config.MaxTemperature))
+		return nil
+	})
+	return err
 }
 
 func (m *mockLightManager) StartCleanupWorker(ctx context.Context, cleanupInterval time.Duration, timeout time.Duration) {
@@ -148,6 +279,143 @@ func TestNewServer(t *testing.T) {
 	assert.Equal(t, cfg, server.cfg)
 }
 
+// discoveringLightManager is a mockLightManager that reports discovery progress.
+type discoveringLightManager struct {
+	mockLightManager
+	completed bool
+}
+
+func (m *discoveringLightManager) DiscoveryCompleted() bool { return m.completed }
+
+func TestServerReady(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &discoveringLightManager{mockLightManager: mockLightManager{lights: make(map[string]*keylight.Light)}}
+	cfg := setupTestConfig(t)
+	server := New(logger, cfg, lights, VersionInfo{})
+
+	ok, reason := server.ready()
+	assert.False(t, ok)
+	assert.Contains(t, reason, "socket")
+
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	ok, reason = server.ready()
+	assert.False(t, ok)
+	assert.Contains(t, reason, "discovery")
+
+	lights.completed = true
+	ok, _ = server.ready()
+	assert.True(t, ok)
+}
+
+func TestServerClientsActive(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: make(map[string]*keylight.Light)}
+	cfg := setupTestConfig(t)
+	cfg.Config.Discovery.IdleAfter = 60
+	server := New(logger, cfg, lights, VersionInfo{})
+	assert.False(t, server.clientsActive())
+
+	server.noteActivity()
+	assert.True(t, server.clientsActive())
+
+	server.lastActivity.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	assert.False(t, server.clientsActive())
+
+	server.subscribers.Add(1)
+	assert.True(t, server.clientsActive(), "event subscribers keep the daemon active")
+	server.subscribers.Add(-1)
+
+	// Health checks don't count as activity
+	handler := server.trackActivity(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.False(t, server.clientsActive())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/lights", nil))
+	assert.True(t, server.clientsActive())
+}
+
+func TestServerLimitRequest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	cfg := setupTestConfig(t)
+	cfg.Config.API.RequestTimeout = 1
+	server := New(logger, cfg, &mockLightManager{}, VersionInfo{})
+
+	var deadline time.Time
+	var hasDeadline bool
+	handler := server.limitRequest(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/lights", nil))
+	require.True(t, hasDeadline)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 500*time.Millisecond)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/keylight/api/v1/ws", nil))
+	assert.False(t, hasDeadline, "the WebSocket stays open")
+}
+
+func TestServerParkLights(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	cfg := setupTestConfig(t)
+	lm := keylight.NewManager(logger)
+	_, err := lm.AddVirtualLights(context.Background(), keylight.VirtualLightOptions{Count: 2})
+	require.NoError(t, err)
+	require.NoError(t, lm.SetLightPower(context.Background(), "virtual-1", true))
+	server := New(logger, cfg, lm, VersionInfo{})
+
+	server.parkLights(lm)
+	assert.True(t, lm.GetLights()["virtual-1"].On, "lights are left alone by default")
+
+	cfg.Config.Lights.Shutdown = config.ShutdownConfig{Action: config.ShutdownPark, On: true, Brightness: 5}
+	server.parkLights(lm)
+	for id, light := range lm.GetLights() {
+		assert.True(t, light.On, id)
+		assert.Equal(t, 5, light.Brightness, id)
+	}
+
+	cfg.Config.Lights.Shutdown = config.ShutdownConfig{Action: config.ShutdownOff, Brightness: 50}
+	server.parkLights(lm)
+	for id, light := range lm.GetLights() {
+		assert.False(t, light.On, id)
+		assert.Equal(t, 5, light.Brightness, "off ignores the parked state")
+	}
+}
+
+func TestCancelOnDisconnect(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	reader := bufio.NewReader(serverConn)
+
+	// A request sent while another is handled is kept for the next read
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := cancelOnDisconnect(serverConn, reader, cancel)
+	_, err := clientConn.Write([]byte("next\n")) // returns once the watch has read it
+	require.NoError(t, err)
+	stop()
+	assert.NoError(t, ctx.Err())
+	_ = serverConn.SetReadDeadline(time.Time{})
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "next\n", line)
+
+	// Stopping leaves the request running
+	ctx, cancel = context.WithCancel(context.Background())
+	cancelOnDisconnect(serverConn, reader, cancel)()
+	assert.NoError(t, ctx.Err())
+	_ = serverConn.SetReadDeadline(time.Time{})
+
+	// Hanging up cancels it
+	ctx, cancel = context.WithCancel(context.Background())
+	stop = cancelOnDisconnect(serverConn, reader, cancel)
+	require.NoError(t, clientConn.Close())
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("request not cancelled after the client hung up")
+	}
+	stop()
+}
+
 func TestServerStartStop(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), &slog.HandlerOptions{
 		Level: slog.LevelInfo,
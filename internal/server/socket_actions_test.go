@@ -3,41 +3,32 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	logfilter "github.com/jmylchreest/slog-logfilter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
 	"github.com/jmylchreest/keylightd/internal/events"
 	"github.com/jmylchreest/keylightd/pkg/keylight"
 
 	"log/slog"
 )
 
-// setupSocketTest creates a server with lights and returns the socket path and cleanup.
-func setupSocketTest(t *testing.T) (*Server, string) {
+// setupSocketTest creates a server with lights and returns the socket path and
+// cleanup. Options can adjust the config before the server starts.
+func setupSocketTest(t *testing.T, opts ...func(*config.Config)) (*Server, string) {
 	t.Helper()
-
-	tempDir, err := os.MkdirTemp("", "keylight-socket-test")
-	require.NoError(t, err)
-	t.Cleanup(func() { os.RemoveAll(tempDir) })
-
-	socketPath := filepath.Join(tempDir, "keylightd.sock")
-	cfgPath := filepath.Join(tempDir, "config.yaml")
-
-	cfg, err := config.Load("config", cfgPath)
-	require.NoError(t, err)
-
-	cfg.Config.Server.UnixSocket = socketPath
-	cfg.Config.API.ListenAddress = "" // No HTTP for these tests
-	cfg.Config.Logging.Level = "debug"
-
-	lightManager := &mockLightManager{
+	return setupSocketTestWith(t, &mockLightManager{
 		lights: map[string]*keylight.Light{
 			"light-1": {
 				ID:          "light-1",
@@ -56,6 +47,28 @@ func setupSocketTest(t *testing.T) (*Server, string) {
 				LastSeen:    time.Now(),
 			},
 		},
+	}, opts...)
+}
+
+// setupSocketTestWith is setupSocketTest with the given light manager.
+func setupSocketTestWith(t *testing.T, lightManager keylight.LightManager, opts ...func(*config.Config)) (*Server, string) {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "keylight-socket-test")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	socketPath := filepath.Join(tempDir, "keylightd.sock")
+	cfgPath := filepath.Join(tempDir, "config.yaml")
+
+	cfg, err := config.Load("config", cfgPath)
+	require.NoError(t, err)
+
+	cfg.Config.Server.UnixSocket = socketPath
+	cfg.Config.API.ListenAddress = "" // No HTTP for these tests
+	cfg.Config.Logging.Level = "debug"
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
@@ -113,6 +126,22 @@ func TestSocketAction_Ping(t *testing.T) {
 	assert.Equal(t, "pong", resp["message"])
 }
 
+func TestSocketAction_Shutdown(t *testing.T) {
+	server, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "shutdown"})
+	assert.Equal(t, "ok", resp["status"])
+	select {
+	case <-server.StopRequested():
+	case <-time.After(time.Second):
+		t.Fatal("shutdown did not request a stop")
+	}
+
+	// Asking again is harmless
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "shutdown"})
+	assert.Equal(t, "ok", resp["status"])
+}
+
 func TestSocketAction_PingWithID(t *testing.T) {
 	_, socketPath := setupSocketTest(t)
 
@@ -122,8 +151,132 @@ func TestSocketAction_PingWithID(t *testing.T) {
 	assert.Equal(t, "req-123", resp["id"])
 }
 
+func TestSocketAction_RequestID(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	// A request ID is generated when the client doesn't send a valid one
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "ping"})
+	generated, _ := resp["request_id"].(string)
+	assert.NotEmpty(t, generated)
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "ping", "request_id": "bad id\n"})
+	assert.NotEqual(t, "bad id\n", resp["request_id"])
+	assert.NotEqual(t, generated, resp["request_id"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "ping", "request_id": "trace-42"})
+	assert.Equal(t, "trace-42", resp["request_id"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "get_light", "request_id": "trace-43", "data": map[string]any{"id": "missing"}})
+	assert.Equal(t, "not_found", resp["code"])
+	assert.Equal(t, "trace-43", resp["request_id"])
+}
+
+// --- Hello ---
+
+func TestSocketAction_Hello(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "hello", "data": map[string]any{"protocol_version": 2}})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, float64(socketProtocolVersion), resp["protocol_version"])
+	assert.Contains(t, resp["actions"], "hello")
+	assert.Contains(t, resp["actions"], "set_lights_state")
+	assert.Len(t, resp["actions"], len(socketActions))
+	assert.Contains(t, resp["features"], "transitions")
+}
+
+// --- Get Daemon Info ---
+
+func TestSocketAction_GetDaemonInfo(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "get_daemon_info"})
+	assert.Equal(t, "ok", resp["status"])
+	info, ok := resp["info"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "test", info["version"])
+	assert.Equal(t, "abc1234", info["commit"])
+	assert.Equal(t, socketPath, info["socket_path"])
+	assert.NotEmpty(t, info["started_at"])
+	assert.NotContains(t, info, "api_listen_address")
+	assert.Equal(t, float64(30), info["discovery_interval_seconds"])
+	assert.Equal(t, float64(2), info["lights"])
+	assert.Equal(t, float64(0), info["groups"])
+	// The requesting connection is the only client
+	assert.Equal(t, map[string]any{"socket": float64(1), "event_subscribers": float64(0), "websocket": float64(0)}, info["clients"])
+}
+
 // --- Get Light ---
 
+// scanningLightManager is a light manager that finds a new light on every
+// discovery pass.
+type scanningLightManager struct {
+	*mockLightManager
+}
+
+func (m *scanningLightManager) DiscoverNow(ctx context.Context) ([]*keylight.Light, error) {
+	light := keylight.Light{ID: fmt.Sprintf("light-%d", len(m.GetLights())+1), Name: "New Light"}
+	m.AddLight(ctx, light)
+	return []*keylight.Light{&light}, nil
+}
+
+func TestSocketAction_DiscoverNow(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	// The mock light manager can't run discovery on demand
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "discover_now"})
+	assert.Equal(t, "unknown_action", resp["code"])
+
+	lights := &scanningLightManager{mockLightManager: &mockLightManager{}}
+	lights.AddLight(context.Background(), keylight.Light{ID: "light-1"})
+	srv, _ := setupSocketTestWith(t, lights)
+
+	result, err := srv.discoveryScan(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Found, 1)
+	assert.Equal(t, "light-2", result.Found[0].ID)
+	assert.Equal(t, 2, result.Lights)
+}
+
+// adoptingLightManager is a light manager with one pending device.
+type adoptingLightManager struct {
+	*mockLightManager
+}
+
+func (m *adoptingLightManager) PendingDevices() []keylight.PendingDevice {
+	return []keylight.PendingDevice{{ID: "neo", ProductName: "Elgato Key Light Neo", IP: net.ParseIP("192.168.1.50"), Port: 9123}}
+}
+
+func (m *adoptingLightManager) AdoptDevice(ctx context.Context, id string) (*keylight.Light, error) {
+	if id != "neo" {
+		return nil, kerrors.NotFoundf("no pending device %s", id)
+	}
+	light := keylight.Light{ID: id, ProductName: "Elgato Key Light Neo"}
+	m.AddLight(ctx, light)
+	return &light, nil
+}
+
+func TestSocketAction_PendingDevices(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "list_pending_devices"})
+	assert.Equal(t, "unknown_action", resp["code"], "the mock light manager keeps no pending devices")
+
+	srv, socketPath := setupSocketTestWith(t, &adoptingLightManager{mockLightManager: &mockLightManager{}})
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "list_pending_devices"})
+	devices, ok := resp["devices"].([]any)
+	require.True(t, ok)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "192.168.1.50", devices[0].(map[string]any)["ip"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "adopt_device", "data": map[string]any{"id": "other"}})
+	assert.Equal(t, "not_found", resp["code"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "adopt_device", "data": map[string]any{"id": "neo"}})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, "neo", resp["light"].(map[string]any)["id"])
+	assert.Contains(t, srv.lights.GetLights(), "neo")
+}
+
 func TestSocketAction_GetLight(t *testing.T) {
 	_, socketPath := setupSocketTest(t)
 
@@ -146,6 +299,7 @@ func TestSocketAction_GetLight_NotFound(t *testing.T) {
 		"data":   map[string]any{"id": "no-such-light"},
 	})
 	assert.Contains(t, resp, "error")
+	assert.Equal(t, "not_found", resp["code"])
 }
 
 func TestSocketAction_GetLight_MissingID(t *testing.T) {
@@ -157,6 +311,7 @@ func TestSocketAction_GetLight_MissingID(t *testing.T) {
 	})
 	assert.Contains(t, resp, "error")
 	assert.Contains(t, resp["error"], "missing light ID")
+	assert.Equal(t, "invalid_input", resp["code"])
 }
 
 // --- Set Light State ---
@@ -189,6 +344,31 @@ func TestSocketAction_SetLightState_MultiProperty(t *testing.T) {
 	assert.Equal(t, "ok", resp["status"])
 }
 
+func TestSocketAction_SetLightState_Color(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data": map[string]any{
+			"id":         "light-1",
+			"hue":        float64(120),
+			"saturation": float64(40),
+		},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err := srv.lights.GetLight(t.Context(), "light-1")
+	require.NoError(t, err)
+	require.NotNil(t, light.Hue)
+	assert.InDelta(t, 120, *light.Hue, 0.001)
+	assert.InDelta(t, 40, *light.Saturation, 0.001)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "light-1", "property": "hue", "value": "red"},
+	})
+	assert.Contains(t, resp["error"], "invalid value type for 'hue'")
+}
+
 func TestSocketAction_SetLightState_MissingID(t *testing.T) {
 	_, socketPath := setupSocketTest(t)
 
@@ -211,8 +391,212 @@ func TestSocketAction_SetLightState_MissingProperties(t *testing.T) {
 	assert.Contains(t, resp["error"], "missing property")
 }
 
+func TestSocketAction_SetLightState_Transition(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data": map[string]any{
+			"id":            "light-1",
+			"brightness":    float64(70),
+			"transition_ms": float64(1500),
+		},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err := srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, 70, light.Brightness)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data": map[string]any{
+			"id":            "light-1",
+			"brightness":    float64(70),
+			"transition_ms": "slow",
+		},
+	})
+	assert.Contains(t, resp["error"], "transition_ms")
+}
+
+func TestSocketAction_SetLightState_Relative(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data": map[string]any{
+			"id":          "light-1",
+			"brightness":  "+10",
+			"temperature": "-500K",
+		},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err := srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, 60, light.Brightness)
+	assert.Equal(t, 4500, light.Temperature)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "light-1", "property": "brightness", "value": "-5"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err = srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, 55, light.Brightness)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "light-1", "brightness": "bright"},
+	})
+	assert.Contains(t, resp["error"], "relative value")
+}
+
+func TestSocketAction_SetLightsState(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_lights_state",
+		"data": map[string]any{
+			"lights": []any{
+				map[string]any{"id": "light-1", "on": false, "brightness": float64(20)},
+				map[string]any{"id": "light-2", "on": true},
+			},
+		},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light1, err := srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.False(t, light1.On)
+	assert.Equal(t, 20, light1.Brightness)
+	light2, err := srv.lights.GetLight(context.Background(), "light-2")
+	require.NoError(t, err)
+	assert.True(t, light2.On)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_lights_state",
+		"data": map[string]any{
+			"lights": []any{
+				map[string]any{"id": "light-1", "on": true},
+				map[string]any{"id": "nope", "on": true},
+			},
+		},
+	})
+	assert.Contains(t, resp["error"], "nope")
+	assert.False(t, light1.On, "rejected batch must not change any light")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_lights_state",
+		"data":   map[string]any{"lights": []any{map[string]any{"id": "light-1"}}},
+	})
+	assert.Contains(t, resp["error"], "missing property")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_lights_state",
+		"data":   map[string]any{},
+	})
+	assert.Contains(t, resp["error"], "missing lights")
+}
+
+func TestWSCommand(t *testing.T) {
+	srv, _ := setupSocketTest(t)
+	ctx := context.Background()
+
+	resp, err := srv.wsCommand(ctx, "list_lights", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp["status"])
+	assert.Len(t, resp["lights"], 2)
+
+	_, err = srv.wsCommand(ctx, "set_light_state", map[string]any{
+		"id": "light-1", "brightness": float64(20),
+	})
+	require.NoError(t, err)
+	light, err := srv.lights.GetLight(ctx, "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, 20, light.Brightness)
+
+	_, err = srv.wsCommand(ctx, "set_light_state", map[string]any{"id": "light-1"})
+	assert.ErrorContains(t, err, "missing property")
+
+	_, err = srv.wsCommand(ctx, "apikey_list", nil)
+	assert.ErrorContains(t, err, "unknown action")
+}
+
 // --- Groups ---
 
+func TestSocketAction_SetGroupDefaults(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	grp, err := srv.groups.CreateGroup(context.Background(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_defaults",
+		"data": map[string]any{
+			"id":            grp.ID,
+			"brightness":    float64(40),
+			"temperature":   float64(4500),
+			"apply_on_join": true,
+		},
+	})
+	assert.Equal(t, "ok", resp["status"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_group",
+		"data":   map[string]any{"id": grp.ID},
+	})
+	group, ok := resp["group"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"brightness": float64(40), "temperature": float64(4500), "apply_on_join": true}, group["defaults"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_defaults",
+		"data":   map[string]any{"id": grp.ID, "brightness": float64(500)},
+	})
+	assert.Contains(t, resp["error"], "brightness")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_defaults",
+		"data":   map[string]any{"id": grp.ID, "apply_on_join": "yes"},
+	})
+	assert.Contains(t, resp["error"], "apply_on_join")
+}
+
+func TestSocketAction_UpdateGroup(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	grp, err := srv.groups.CreateGroup(context.Background(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "update_group",
+		"data":   map[string]any{"id": grp.ID, "name": "Studio", "description": "Desk lights", "icon": "camera-web"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	group, ok := resp["group"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "Studio", group["name"])
+	assert.Equal(t, "Desk lights", group["description"])
+	assert.Equal(t, "camera-web", group["icon"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "update_group",
+		"data":   map[string]any{"id": grp.ID, "color": "orange"},
+	})
+	assert.Contains(t, resp["error"], "color")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "update_group",
+		"data":   map[string]any{"id": grp.ID, "name": float64(1)},
+	})
+	assert.Contains(t, resp["error"], "name")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "update_group",
+		"data":   map[string]any{"id": grp.ID},
+	})
+	assert.Contains(t, resp["error"], "missing")
+}
+
 func TestSocketAction_CreateAndListGroups(t *testing.T) {
 	_, socketPath := setupSocketTest(t)
 
@@ -317,63 +701,857 @@ func TestSocketAction_SetGroupState(t *testing.T) {
 		"data":   map[string]any{"id": groupID, "on": true, "brightness": float64(80)},
 	})
 	assert.Equal(t, "ok", multiResp["status"])
+	assert.Equal(t, []any{map[string]any{"group": groupID, "light": "light-1", "status": "ok"}}, multiResp["lights"])
 }
 
-// --- API Key actions ---
-
-func TestSocketAction_APIKeyLifecycle(t *testing.T) {
-	_, socketPath := setupSocketTest(t)
-
-	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
-	require.NoError(t, err)
-	defer conn.Close()
+func TestSocketAction_SetStateWildcards(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
 
-	// Create API key
-	addResp := socketRequestKeepConn(t, conn, map[string]any{
-		"action": "apikey_add",
-		"data":   map[string]any{"name": "test-socket-key"},
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "all", "on": false},
 	})
-	assert.Equal(t, "ok", addResp["status"])
-	keyData, ok := addResp["key"].(map[string]any)
-	require.True(t, ok)
-	keyStr := keyData["key"].(string)
-	assert.NotEmpty(t, keyStr)
-
-	// List API keys
-	listResp := socketRequestKeepConn(t, conn, map[string]any{"action": "apikey_list"})
-	assert.Equal(t, "ok", listResp["status"])
-	keys, ok := listResp["keys"].([]any)
-	require.True(t, ok)
-	assert.GreaterOrEqual(t, len(keys), 1)
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, []any{"light-1", "light-2"}, resp["lights"])
+	for _, light := range srv.lights.GetLights() {
+		assert.False(t, light.On, light.ID)
+	}
 
-	// Set disabled status
-	disableResp := socketRequestKeepConn(t, conn, map[string]any{
-		"action": "apikey_set_disabled_status",
-		"data":   map[string]any{"key_or_name": "test-socket-key", "disabled": true},
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "nothing-*", "on": true},
 	})
-	assert.Equal(t, "ok", disableResp["status"])
-
-	// Re-enable
-	enableResp := socketRequestKeepConn(t, conn, map[string]any{
-		"action": "apikey_set_disabled_status",
-		"data":   map[string]any{"key_or_name": "test-socket-key", "disabled": false},
+	assert.Equal(t, "not_found", resp["code"])
+
+	for _, name := range []string{"office-left", "office-right"} {
+		resp = sendSocketRequest(t, socketPath, map[string]any{
+			"action": "create_group",
+			"data":   map[string]any{"name": name, "lights": []any{"light-1"}},
+		})
+		require.Equal(t, "ok", resp["status"])
+	}
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_state",
+		"data":   map[string]any{"id": "office-*", "on": true},
 	})
-	assert.Equal(t, "ok", enableResp["status"])
+	assert.Equal(t, "ok", resp["status"])
+	assert.Len(t, resp["lights"], 2)
 
-	// Delete API key
-	deleteResp := socketRequestKeepConn(t, conn, map[string]any{
-		"action": "apikey_delete",
-		"data":   map[string]any{"key": keyStr},
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_state",
+		"data":   map[string]any{"id": "all", "on": false},
 	})
-	assert.Equal(t, "ok", deleteResp["status"])
+	assert.Equal(t, "ok", resp["status"])
 }
 
-func TestSocketAction_APIKeyAdd_DuplicateName(t *testing.T) {
-	_, socketPath := setupSocketTest(t)
+func TestSocketAction_SetLightState_Mireds(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
 
-	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "light-1", "temperature": float64(250), "units": "mired"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err := srv.lights.GetLight(context.Background(), "light-1")
 	require.NoError(t, err)
-	defer conn.Close()
+	assert.Equal(t, 4000, light.Temperature)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "light-1", "property": "temperature", "value": float64(200), "units": "mired"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err = srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, 5000, light.Temperature)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "light-1", "temperature": float64(4000), "units": "celsius"},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_lights_state",
+		"data":   map[string]any{"lights": []any{map[string]any{"id": "light-1", "temperature": float64(0)}}, "units": "mired"},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+}
+
+func TestSocketAction_SetGroupState_StateVersion(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "create_group",
+		"data":   map[string]any{"name": "studio", "lights": []any{"light-1"}},
+	})
+	groupID := resp["group"].(map[string]any)["id"].(string)
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_group_state",
+		"data":   map[string]any{"id": groupID},
+	})
+	version := resp["state"].(map[string]any)["version"].(float64)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_state",
+		"data":   map[string]any{"id": groupID, "on": true, "state_version": version + 1},
+	})
+	assert.Equal(t, "conflict", resp["code"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_state",
+		"data":   map[string]any{"id": groupID, "on": true, "state_version": version},
+	})
+	assert.Equal(t, "ok", resp["status"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_group_state",
+		"data":   map[string]any{"id": groupID, "on": true, "state_version": "latest"},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+}
+
+func TestSocketAction_SetLightName(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_name",
+		"data":   map[string]any{"id": "light-1", "name": "Desk Left"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, "Desk Left", resp["name"])
+	light, err := srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Desk Left", light.Name)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_name",
+		"data":   map[string]any{"name": "Desk Left"},
+	})
+	assert.Contains(t, resp["error"], "missing id")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_name",
+		"data":   map[string]any{"id": "nope", "name": "Desk"},
+	})
+	assert.Contains(t, resp["error"], "failed to set name of light")
+}
+
+func TestSocketAction_SetLightTags(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_tags",
+		"data":   map[string]any{"id": "light-1", "tags": map[string]any{"side": "left"}},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err := srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"side": "left"}, light.Tags)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "tag:side=left", "property": "brightness", "value": 20},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	light, err = srv.lights.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"light-1"}, resp["lights"])
+	assert.Equal(t, 20, light.Brightness)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_state",
+		"data":   map[string]any{"id": "tag:side=right", "property": "brightness", "value": 20},
+	})
+	assert.Equal(t, "not_found", resp["code"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_tags",
+		"data":   map[string]any{"id": "light-1", "tags": map[string]any{"side": 1}},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_tags",
+		"data":   map[string]any{"id": "nope"},
+	})
+	assert.Equal(t, "not_found", resp["code"])
+}
+
+func TestSocketAction_SetDeviceName(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_device_name",
+		"data":   map[string]any{"id": "light-1", "name": "Key Light Left"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, "Key Light Left", resp["name"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_device_name",
+		"data":   map[string]any{"name": "Key Light Left"},
+	})
+	assert.Contains(t, resp["error"], "missing id")
+}
+
+func TestSocketAction_RawRequest(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "raw_request",
+		"data": map[string]any{
+			"id":     "light-1",
+			"method": "PUT",
+			"path":   "/elgato/lights/settings",
+			"body":   map[string]any{"powerOnBehavior": 2},
+		},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	raw, ok := resp["response"].(map[string]any)
+	require.True(t, ok, "response should be an object")
+	assert.Equal(t, float64(200), raw["status_code"])
+	assert.Equal(t, map[string]any{
+		"method": "PUT",
+		"path":   "/elgato/lights/settings",
+		"body":   `{"powerOnBehavior":2}`,
+	}, raw["body"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "raw_request",
+		"data":   map[string]any{"id": "light-1", "method": "GET"},
+	})
+	assert.Contains(t, resp["error"], "missing id, method or path")
+}
+
+func TestSocketAction_LightSettings(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_light_settings",
+		"data":   map[string]any{"id": "light-1"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, map[string]any{
+		"power_on_behavior":    "restore",
+		"power_on_brightness":  float64(20),
+		"power_on_temperature": float64(4700),
+	}, resp["settings"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_settings",
+		"data":   map[string]any{"id": "light-1", "power_on_behavior": "default", "power_on_brightness": 60},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	settings, ok := resp["settings"].(map[string]any)
+	require.True(t, ok, "settings should be an object")
+	assert.Equal(t, "default", settings["power_on_behavior"])
+	assert.Equal(t, float64(60), settings["power_on_brightness"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_settings",
+		"data":   map[string]any{"id": "light-1", "power_on_behavior": "off"},
+	})
+	assert.Contains(t, resp["error"], "power-on behavior must be")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_light_settings",
+		"data":   map[string]any{"id": "light-1"},
+	})
+	assert.Contains(t, resp["error"], "missing power_on_behavior")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_light_settings",
+		"data":   map[string]any{"id": "nope"},
+	})
+	assert.Contains(t, resp["error"], "failed to get settings")
+}
+
+func TestSocketAction_Toggle(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "toggle_light",
+		"data":   map[string]any{"id": "light-2"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, true, resp["on"])
+	light2, err := srv.lights.GetLight(context.Background(), "light-2")
+	require.NoError(t, err)
+	assert.True(t, light2.On)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "toggle_light",
+		"data":   map[string]any{"id": "nope"},
+	})
+	assert.Contains(t, resp["error"], "failed to toggle light")
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	createResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "create_group",
+		"data":   map[string]any{"name": "studio", "lights": []any{"light-1", "light-2"}},
+	})
+	groupID := createResp["group"].(map[string]any)["id"].(string)
+
+	// Both lights are on, so the group is turned off
+	resp = socketRequestKeepConn(t, conn, map[string]any{
+		"action": "toggle_group",
+		"data":   map[string]any{"id": "studio"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, map[string]any{groupID: false}, resp["groups"])
+	light2, err = srv.lights.GetLight(context.Background(), "light-2")
+	require.NoError(t, err)
+	assert.Len(t, resp["lights"], 2)
+	assert.False(t, light2.On)
+
+	resp = socketRequestKeepConn(t, conn, map[string]any{
+		"action": "toggle_group",
+		"data":   map[string]any{"id": "missing"},
+	})
+	assert.Contains(t, resp["error"], "no groups found")
+}
+
+// --- Logging actions ---
+
+func TestSocketAction_LoggingFilters(t *testing.T) {
+	logfilter.New(logfilter.WithOutput(io.Discard))
+	t.Cleanup(func() { logfilter.SetFilters(nil) })
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "get_level"})
+	assert.NotEmpty(t, resp["level"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "add_filter",
+		"data":   map[string]any{"type": "component", "pattern": "http*", "level": "debug", "enabled": true},
+	})
+	require.Nil(t, resp["error"])
+	filters, _ := resp["filters"].([]any)
+	require.Len(t, filters, 1)
+	assert.Equal(t, "http*", filters[0].(map[string]any)["pattern"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "add_filter",
+		"data":   map[string]any{"type": "component", "pattern": "http*", "level": "loud"},
+	})
+	assert.Contains(t, resp["error"], "invalid filter")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "remove_filter",
+		"data":   map[string]any{"type": "component", "pattern": "http*"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Empty(t, logfilter.GetFilters())
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "remove_filter",
+		"data":   map[string]any{"type": "component", "pattern": "http*"},
+	})
+	assert.Contains(t, resp["error"], "no log filter")
+}
+
+// --- Schedule actions ---
+
+func TestSocketAction_Circadian(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "get_circadian"})
+	assert.Equal(t, "ok", resp["status"])
+	status, ok := resp["circadian"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, false, status["enabled"])
+	assert.NotContains(t, status, "mode")
+	assert.ElementsMatch(t, []any{"light-1", "light-2"}, status["lights"])
+
+	// Without a location or curve points there is nothing to follow
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_circadian",
+		"data":   map[string]any{"enabled": true},
+	})
+	assert.Contains(t, resp["error"], "needs a location or curve points")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "set_circadian"})
+	assert.Contains(t, resp["error"], "missing or invalid enabled value")
+}
+
+func TestSocketAction_GetGroupState(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "create_group",
+		"data":   map[string]any{"name": "desk", "lights": []string{"light-1", "light-2"}},
+	})
+	require.Equal(t, "ok", resp["status"])
+	groupID := resp["group"].(map[string]any)["id"]
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_group_state",
+		"data":   map[string]any{"id": groupID},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	state, ok := resp["state"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(2), state["lights_total"])
+	assert.Equal(t, float64(1), state["lights_on"])
+	assert.Equal(t, true, state["any_on"])
+	assert.Equal(t, false, state["all_on"])
+	assert.Equal(t, float64(62), state["brightness"])
+	assert.Equal(t, []any{}, state["unreachable"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_group_state",
+		"data":   map[string]any{"id": "missing"},
+	})
+	assert.Equal(t, "not_found", resp["code"])
+}
+
+func TestSocketAction_Stats(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_light_stats",
+		"data":   map[string]any{"id": "light-1"},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	st, ok := resp["stats"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "light-1", st["id"])
+	assert.Equal(t, true, st["on"])
+	assert.Equal(t, float64(0), st["toggles"])
+	assert.NotContains(t, st, "last_toggle")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_light_stats",
+		"data":   map[string]any{"id": "missing"},
+	})
+	assert.Equal(t, "not_found", resp["code"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "create_group",
+		"data":   map[string]any{"name": "desk", "lights": []string{"light-1", "light-2"}},
+	})
+	require.Equal(t, "ok", resp["status"])
+	groupID := resp["group"].(map[string]any)["id"]
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_group_stats",
+		"data":   map[string]any{"id": groupID},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	st, ok = resp["stats"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, groupID, st["id"])
+	assert.Len(t, st["lights"], 2)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "get_group_stats"})
+	assert.Contains(t, resp["error"], "missing group ID")
+}
+
+func TestSocketAction_ExportImportConfig(t *testing.T) {
+	server, socketPath := setupSocketTest(t)
+	grp, err := server.groups.CreateGroup(context.Background(), "Office", []string{"light-1"})
+	require.NoError(t, err)
+	_, err = server.apikeyManager.CreateAPIKey("tray", 0)
+	require.NoError(t, err)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "export_config"})
+	assert.Equal(t, "ok", resp["status"])
+	doc, ok := resp["document"].(map[string]any)
+	require.True(t, ok)
+	groups, ok := doc["groups"].([]any)
+	require.True(t, ok)
+	require.Len(t, groups, 1)
+	keys, ok := doc["api_keys"].([]any)
+	require.True(t, ok)
+	require.Len(t, keys, 1)
+	assert.NotContains(t, keys[0], "key", "secrets are left out by default")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "export_config",
+		"data":   map[string]any{"include_secrets": true},
+	})
+	keys = resp["document"].(map[string]any)["api_keys"].([]any)
+	assert.Contains(t, keys[0], "key")
+
+	groups[0].(map[string]any)["name"] = "Studio"
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "import_config",
+		"data":   map[string]any{"document": doc},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	result, ok := resp["result"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), result["groups"])
+	assert.Len(t, result["skipped"], 1, "the key was exported without its secret")
+	got, err := server.groups.GetGroup(grp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Studio", got.Name)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "import_config"})
+	assert.Contains(t, resp["error"], "missing document")
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "import_config",
+		"data":   map[string]any{"document": map[string]any{"version": 99}},
+	})
+	assert.Contains(t, resp["error"], "unsupported document version")
+}
+
+func TestSocketAction_DesiredState(t *testing.T) {
+	off := false
+	_, socketPath := setupSocketTest(t, func(cfg *config.Config) {
+		cfg.Config.DesiredState.Targets = []config.DesiredState{{Light: "light-2", On: &off, Disabled: true}}
+	})
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "get_desired_state"})
+	assert.Equal(t, "ok", resp["status"])
+	status, ok := resp["desired_state"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, false, status["enabled"])
+	targets, ok := status["targets"].([]any)
+	require.True(t, ok)
+	require.Len(t, targets, 1)
+	target := targets[0].(map[string]any)
+	assert.Equal(t, "light:light-2", target["target"])
+	assert.Equal(t, false, target["enabled"])
+	assert.Equal(t, []any{"light-2"}, target["lights"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_desired_state_target",
+		"data":   map[string]any{"target": "light:light-2", "enabled": true},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	target = resp["desired_state"].(map[string]any)["targets"].([]any)[0].(map[string]any)
+	assert.Equal(t, true, target["enabled"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_desired_state",
+		"data":   map[string]any{"enabled": true},
+	})
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, true, resp["desired_state"].(map[string]any)["enabled"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_desired_state_target",
+		"data":   map[string]any{"target": "light:missing", "enabled": true},
+	})
+	assert.Equal(t, "not_found", resp["code"])
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "set_desired_state"})
+	assert.Contains(t, resp["error"], "missing or invalid enabled value")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_desired_state_target",
+		"data":   map[string]any{"enabled": true},
+	})
+	assert.Contains(t, resp["error"], "missing target")
+}
+
+func TestSocketAction_Webcam(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "get_webcam"})
+	assert.Equal(t, "ok", resp["status"])
+	status, ok := resp["webcam"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, false, status["enabled"])
+	assert.Equal(t, "auto", status["override"])
+	assert.Equal(t, []any{}, status["groups"])
+
+	// Overrides need the watcher to be enabled
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "set_webcam_override",
+		"data":   map[string]any{"override": "on"},
+	})
+	assert.Contains(t, resp["error"], "not enabled")
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{"action": "set_webcam_override"})
+	assert.Contains(t, resp["error"], "missing override")
+}
+
+func TestSocketAction_ScheduleLifecycle(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	createResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "create_schedule",
+		"data": map[string]any{
+			"name":   "morning",
+			"at":     "08:30",
+			"target": map[string]any{"type": "light", "id": "light-1"},
+			"action": map[string]any{"on": true, "brightness": float64(60)},
+		},
+	})
+	assert.Equal(t, "ok", createResp["status"])
+	created, ok := createResp["schedule"].(map[string]any)
+	require.True(t, ok)
+	scheduleID := created["id"].(string)
+	assert.NotEmpty(t, scheduleID)
+	assert.Equal(t, true, created["enabled"])
+	assert.NotEmpty(t, created["next_run"])
+
+	listResp := socketRequestKeepConn(t, conn, map[string]any{"action": "list_schedules"})
+	schedules, ok := listResp["schedules"].([]any)
+	require.True(t, ok)
+	assert.Len(t, schedules, 1)
+
+	upcomingResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "list_upcoming_schedules",
+		"data":   map[string]any{"limit": float64(2), "days": float64(3)},
+	})
+	upcoming, ok := upcomingResp["upcoming"].([]any)
+	require.True(t, ok)
+	require.Len(t, upcoming, 2)
+	assert.Equal(t, scheduleID, upcoming[0].(map[string]any)["schedule_id"])
+	badResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "list_upcoming_schedules",
+		"data":   map[string]any{"limit": float64(1000)},
+	})
+	assert.Contains(t, badResp["error"], "limit")
+
+	updateResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "update_schedule",
+		"data": map[string]any{
+			"id":      scheduleID,
+			"name":    "weekday morning",
+			"cron":    "30 8 * * 1-5",
+			"target":  map[string]any{"type": "light", "id": "light-1"},
+			"action":  map[string]any{"on": true},
+			"enabled": false,
+		},
+	})
+	assert.Equal(t, "ok", updateResp["status"])
+	updated := updateResp["schedule"].(map[string]any)
+	assert.Equal(t, "weekday morning", updated["name"])
+	assert.Equal(t, false, updated["enabled"])
+
+	deleteResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "delete_schedule",
+		"data":   map[string]any{"id": scheduleID},
+	})
+	assert.Equal(t, "ok", deleteResp["status"])
+
+	getResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "get_schedule",
+		"data":   map[string]any{"id": scheduleID},
+	})
+	assert.Contains(t, getResp, "error")
+}
+
+func TestSocketAction_SceneLifecycle(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	createResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "create_scene",
+		"data": map[string]any{
+			"name": "streaming",
+			"entries": []any{
+				map[string]any{"target": map[string]any{"type": "light", "id": "light-1"}, "brightness": float64(80)},
+				map[string]any{"target": map[string]any{"type": "light", "id": "light-2"}, "on": true, "order": float64(1)},
+			},
+		},
+	})
+	assert.Equal(t, "ok", createResp["status"])
+	created, ok := createResp["scene"].(map[string]any)
+	require.True(t, ok)
+	sceneID := created["id"].(string)
+	assert.NotEmpty(t, sceneID)
+
+	listResp := socketRequestKeepConn(t, conn, map[string]any{"action": "list_scenes"})
+	scenes, ok := listResp["scenes"].([]any)
+	require.True(t, ok)
+	assert.Len(t, scenes, 1)
+
+	applyResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apply_scene",
+		"data":   map[string]any{"id": "streaming"},
+	})
+	assert.Equal(t, "ok", applyResp["status"])
+	job, ok := applyResp["job"].(map[string]any)
+	require.True(t, ok)
+	jobID := job["id"].(string)
+	assert.Equal(t, "scene.apply", job["kind"])
+	assert.Equal(t, sceneID, job["subject"])
+	assert.Equal(t, float64(2), job["progress"].(map[string]any)["steps"])
+
+	require.Eventually(t, func() bool {
+		resp := socketRequestKeepConn(t, conn, map[string]any{
+			"action": "get_job",
+			"data":   map[string]any{"id": jobID},
+		})
+		job, _ := resp["job"].(map[string]any)
+		return job["status"] == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	runResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "get_scene_run",
+		"data":   map[string]any{"id": "streaming"},
+	})
+	assert.Equal(t, jobID, runResp["job"].(map[string]any)["id"])
+
+	jobsResp := socketRequestKeepConn(t, conn, map[string]any{"action": "list_jobs"})
+	assert.Len(t, jobsResp["jobs"], 1)
+
+	cancelJobResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "cancel_job",
+		"data":   map[string]any{"id": jobID},
+	})
+	assert.Equal(t, "conflict", cancelJobResp["code"])
+
+	cancelResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "cancel_scene_run",
+		"data":   map[string]any{"id": sceneID},
+	})
+	assert.Contains(t, cancelResp, "error", "a finished run can't be cancelled")
+
+	deleteResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "delete_scene",
+		"data":   map[string]any{"id": sceneID},
+	})
+	assert.Equal(t, "ok", deleteResp["status"])
+
+	getResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "get_scene",
+		"data":   map[string]any{"id": sceneID},
+	})
+	assert.Contains(t, getResp, "error")
+}
+
+func TestSocketAction_CreateSchedule_Invalid(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "create_schedule",
+		"data": map[string]any{
+			"name":   "bad",
+			"cron":   "61 * * * *",
+			"target": map[string]any{"type": "light", "id": "light-1"},
+			"action": map[string]any{"on": true},
+		},
+	})
+	assert.Contains(t, resp, "error")
+	assert.Contains(t, resp["error"], "out of range")
+}
+
+// --- API Key actions ---
+
+func TestSocketAction_APIKeyLifecycle(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Create API key
+	addResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apikey_add",
+		"data":   map[string]any{"name": "test-socket-key"},
+	})
+	assert.Equal(t, "ok", addResp["status"])
+	keyData, ok := addResp["key"].(map[string]any)
+	require.True(t, ok)
+	keyStr := keyData["key"].(string)
+	assert.NotEmpty(t, keyStr)
+
+	// List API keys
+	listResp := socketRequestKeepConn(t, conn, map[string]any{"action": "apikey_list"})
+	assert.Equal(t, "ok", listResp["status"])
+	keys, ok := listResp["keys"].([]any)
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, len(keys), 1)
+
+	// Set disabled status
+	disableResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apikey_set_disabled_status",
+		"data":   map[string]any{"key_or_name": "test-socket-key", "disabled": true},
+	})
+	assert.Equal(t, "ok", disableResp["status"])
+
+	// Re-enable
+	enableResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apikey_set_disabled_status",
+		"data":   map[string]any{"key_or_name": "test-socket-key", "disabled": false},
+	})
+	assert.Equal(t, "ok", enableResp["status"])
+
+	// Delete API key
+	deleteResp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apikey_delete",
+		"data":   map[string]any{"key": keyStr},
+	})
+	assert.Equal(t, "ok", deleteResp["status"])
+}
+
+func TestSocketAction_APIKeyBootstrap(t *testing.T) {
+	srv, socketPath := setupSocketTest(t)
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	first := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apikey_bootstrap",
+		"data":   map[string]any{"client": "tray"},
+	})
+	require.Equal(t, "ok", first["status"], first)
+	firstKey := first["key"].(map[string]any)
+	assert.Equal(t, "ui-tray", firstKey["name"])
+	assert.Equal(t, []any{"read", "control"}, firstKey["scopes"])
+	assert.Empty(t, first["api_url"], "the HTTP API is not served in this test")
+
+	// Asking again replaces the key
+	second := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apikey_bootstrap",
+		"data":   map[string]any{"client": "tray"},
+	})
+	require.Equal(t, "ok", second["status"], second)
+	assert.NotEqual(t, firstKey["key"], second["key"].(map[string]any)["key"])
+	_, err = srv.apikeyManager.ValidateAPIKey(firstKey["key"].(string))
+	assert.Error(t, err)
+	assert.Len(t, srv.apikeyManager.ListAPIKeys(), 1)
+
+	resp := socketRequestKeepConn(t, conn, map[string]any{
+		"action": "apikey_bootstrap",
+		"data":   map[string]any{"client": "../Tray"},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+}
+
+func TestLocalAPIURL(t *testing.T) {
+	srv, _ := setupSocketTest(t)
+	api := &srv.cfg.Config.API
+
+	assert.Empty(t, srv.localAPIURL())
+
+	api.ListenAddress = ":9123"
+	assert.Equal(t, "http://localhost:9123", srv.localAPIURL())
+
+	api.ListenAddress = "0.0.0.0:9123"
+	api.BasePath = "/keylight/"
+	assert.Equal(t, "http://localhost:9123/keylight", srv.localAPIURL())
+
+	api.ListenAddress = "[::1]:8443"
+	api.BasePath = ""
+	api.TLS.CertFile, api.TLS.KeyFile = "cert.pem", "key.pem"
+	assert.Equal(t, "https://[::1]:8443", srv.localAPIURL())
+}
+
+func TestSocketAction_APIKeyAdd_DuplicateName(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
 
 	socketRequestKeepConn(t, conn, map[string]any{
 		"action": "apikey_add",
@@ -417,6 +1595,84 @@ func TestSocketAction_UnknownAction(t *testing.T) {
 	assert.Contains(t, resp["error"], "unknown action")
 }
 
+// --- Malformed and oversized requests ---
+
+func TestSocketAction_MalformedRequests(t *testing.T) {
+	_, socketPath := setupSocketTest(t)
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	dec := json.NewDecoder(conn)
+
+	// Each bad request gets a coded error and the connection stays usable
+	for _, tc := range []struct {
+		line, code, message string
+	}{
+		{"{not json\n", "invalid_request", "invalid JSON request"},
+		{"[1, 2]\n", "invalid_request", "invalid JSON request"},
+		{`{"id":"r1"}` + "\n", "invalid_request", "missing action"},
+		{`{"action":"ping","data":"x"}` + "\n", "invalid_request", "data must be an object"},
+		{`{"action":"foobar"}` + "\n", "unknown_action", "unknown action"},
+	} {
+		_, err := conn.Write([]byte(tc.line))
+		require.NoError(t, err)
+		var resp map[string]any
+		require.NoError(t, dec.Decode(&resp))
+		assert.Equal(t, tc.code, resp["code"], tc.line)
+		assert.Contains(t, resp["error"], tc.message, tc.line)
+	}
+	require.NoError(t, json.NewEncoder(conn).Encode(map[string]any{"action": "ping"}))
+	var resp map[string]any
+	require.NoError(t, dec.Decode(&resp))
+	assert.Equal(t, "pong", resp["message"])
+}
+
+func TestSocketAction_RequestTooLarge(t *testing.T) {
+	_, socketPath := setupSocketTest(t, func(cfg *config.Config) {
+		cfg.Config.Server.MaxRequestSize = 64
+	})
+
+	// Requests within the limit are served
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "ping"})
+	assert.Equal(t, "pong", resp["message"])
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	// An oversized line is refused without waiting for its end, and the
+	// connection is closed
+	_, err = conn.Write([]byte(`{"action":"ping","data":{"pad":"` + strings.Repeat("x", 4096)))
+	require.NoError(t, err)
+	dec := json.NewDecoder(conn)
+	require.NoError(t, dec.Decode(&resp))
+	assert.Equal(t, "request_too_large", resp["code"])
+	assert.Contains(t, resp["error"], "64 bytes")
+	assert.Error(t, dec.Decode(&resp), "connection is closed")
+}
+
+func TestSocketAction_IdleTimeout(t *testing.T) {
+	_, socketPath := setupSocketTest(t, func(cfg *config.Config) {
+		cfg.Config.Server.IdleTimeout = 1
+	})
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	// A half-written request that never completes is dropped after the idle timeout
+	_, err = conn.Write([]byte(`{"action":"pi`))
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Less(t, time.Since(start), 4*time.Second)
+}
+
 // --- Multiple requests on same connection ---
 
 func TestSocketAction_MultipleRequestsSameConnection(t *testing.T) {
@@ -464,3 +1720,104 @@ func TestSocketAction_SubscribeEvents(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "light.state_changed", evt["type"])
 }
+
+func TestSocketAction_SubscribeEventsFiltered(t *testing.T) {
+	server, socketPath := setupSocketTest(t)
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	err = json.NewEncoder(conn).Encode(map[string]any{
+		"action": "subscribe_events",
+		"data":   map[string]any{"types": []string{"light.state_changed"}, "lights": []string{"light-2"}},
+	})
+	require.NoError(t, err)
+	dec := json.NewDecoder(conn)
+	var ack map[string]any
+	require.NoError(t, dec.Decode(&ack))
+	assert.Equal(t, true, ack["subscribed"])
+
+	// Only the last event passes the filter
+	server.eventBus.Publish(events.NewEvent(events.LightStateChanged, map[string]string{"id": "light-1"}))
+	server.eventBus.Publish(events.NewEvent(events.LightDiscovered, map[string]string{"id": "light-2"}))
+	server.eventBus.Publish(events.NewEvent(events.LightStateChanged, map[string]string{"id": "light-2"}))
+
+	var evt struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	require.NoError(t, dec.Decode(&evt))
+	assert.Equal(t, "light.state_changed", evt.Type)
+	assert.Equal(t, "light-2", evt.Data["id"])
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{
+		"action": "subscribe_events",
+		"data":   map[string]any{"types": []string{"light.exploded"}},
+	})
+	assert.Equal(t, "invalid_input", resp["code"])
+	assert.Contains(t, resp["error"], "unknown event type")
+}
+
+func TestSocketAction_GetLogs(t *testing.T) {
+	server, socketPath := setupSocketTest(t)
+	logger := slog.New(slog.NewTextHandler(server.logBuffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger.Debug("debug line")
+	logger.Warn("warn line", "light", "light-1")
+	logger.Error("error line")
+
+	resp := sendSocketRequest(t, socketPath, map[string]any{"action": "get_logs"})
+	require.Equal(t, "ok", resp["status"])
+	assert.Len(t, resp["entries"], 3)
+
+	resp = sendSocketRequest(t, socketPath, map[string]any{
+		"action": "get_logs",
+		"data":   map[string]any{"level": "warn", "since": "1m", "limit": 1},
+	})
+	require.Equal(t, "ok", resp["status"])
+	entries, ok := resp["entries"].([]any)
+	require.True(t, ok)
+	require.Len(t, entries, 1)
+	entry := entries[0].(map[string]any)
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "error line", entry["message"])
+	assert.Contains(t, entry["line"], `msg="error line"`)
+
+	for _, data := range []map[string]any{{"level": "loud"}, {"since": "yesterday"}, {"limit": 1.5}} {
+		resp = sendSocketRequest(t, socketPath, map[string]any{"action": "get_logs", "data": data})
+		assert.Equal(t, "invalid_input", resp["code"], "%v", data)
+	}
+}
+
+func TestSocketAction_FollowLogs(t *testing.T) {
+	server, socketPath := setupSocketTest(t)
+	logger := slog.New(slog.NewTextHandler(server.logBuffer, nil))
+	logger.Info("before one")
+	logger.Info("before two")
+
+	conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	err = json.NewEncoder(conn).Encode(map[string]any{"action": "follow_logs", "data": map[string]any{"level": "info", "limit": 1}})
+	require.NoError(t, err)
+	dec := json.NewDecoder(conn)
+	var ack map[string]any
+	require.NoError(t, dec.Decode(&ack))
+	assert.Equal(t, true, ack["following"])
+
+	var entry struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	require.NoError(t, dec.Decode(&entry))
+	assert.Equal(t, "before two", entry.Message, "the backlog comes first")
+
+	logger.Debug("below the level")
+	logger.Warn("after")
+	require.NoError(t, dec.Decode(&entry))
+	assert.Equal(t, "warn", entry.Level)
+	assert.Equal(t, "after", entry.Message)
+}
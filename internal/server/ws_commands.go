@@ -0,0 +1,50 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// wsCommandActions lists the socket actions WebSocket clients may invoke.
+// They use the same request data and response fields as the Unix socket API.
+var wsCommandActions = []string{
+	"ping",
+	"list_lights",
+	"set_light_state",
+	"set_lights_state",
+	"toggle_light",
+	"set_group_state",
+	"toggle_group",
+}
+
+// wsCommand executes a command received over the WebSocket connection by
+// dispatching it to the matching socket action handler.
+func (s *Server) wsCommand(ctx context.Context, action string, data map[string]any) (map[string]any, error) {
+	if !slices.Contains(wsCommandActions, action) {
+		return nil, kerrors.Errorf(kerrors.CodeUnknownAction, "unknown action: %s", action)
+	}
+	// Commands get config.api.request_timeout, and stop when the server does
+	ctx, cancel := context.WithTimeout(ctx, s.apiRequestTimeout())
+	defer cancel()
+	stop := context.AfterFunc(s.rootCtx, cancel)
+	defer stop()
+
+	var buf bytes.Buffer
+	socketActions[action](s, socketRequest{conn: &buf, ctx: ctx, data: data, action: action})
+
+	var resp map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", action, err)
+	}
+	if msg, ok := resp["error"].(string); ok {
+		code, _ := resp["code"].(string)
+		details, _ := resp["details"].(map[string]any)
+		return nil, &kerrors.Error{Code: kerrors.Code(code), Message: msg, Details: details}
+	}
+	return resp, nil
+}
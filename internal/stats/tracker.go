@@ -0,0 +1,297 @@
+// Package stats tracks how lights are used: how long each has been on in
+// total, how often it was turned on or off, and when its state last changed.
+//
+// The tracker follows light events on the event bus and keeps the totals in
+// the daemon's state, saving them periodically and on shutdown so they
+// survive restarts. On-time only accrues while a light is on and reachable;
+// a light that goes offline stops accruing until it is seen again.
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Stats is the usage of a light.
+type Stats struct {
+	ID         string
+	On         bool          // Whether the light is on now, as far as the daemon knows
+	OnTime     time.Duration // Cumulative time on, including the current on period
+	Toggles    int           // Times the light was turned on or off
+	LastChange time.Time     // When its state last changed; zero if it hasn't since tracking began
+	LastToggle time.Time     // When it was last turned on or off; zero if it hasn't been
+	Since      time.Time     // When tracking began
+}
+
+// GroupStats is the combined usage of a group's lights.
+type GroupStats struct {
+	OnTime     time.Duration // Sum of the lights' on-time
+	Toggles    int           // Sum of the lights' toggles
+	LastChange time.Time     // Latest state change of any of the lights
+	LastToggle time.Time     // Latest toggle of any of the lights
+	Lights     []Stats       // Stats of the group's lights that have any, in group order
+}
+
+// lightState is the part of a light's state whose changes are tracked.
+type lightState struct {
+	on          bool
+	brightness  int
+	temperature int
+	colorMode   string
+	hue         float64
+	saturation  float64
+}
+
+func stateOf(light keylight.Light) lightState {
+	s := lightState{on: light.On, brightness: light.Brightness, temperature: light.Temperature, colorMode: light.ColorMode}
+	if light.Hue != nil {
+		s.hue = *light.Hue
+	}
+	if light.Saturation != nil {
+		s.saturation = *light.Saturation
+	}
+	return s
+}
+
+// entry is the tracked usage of a light.
+type entry struct {
+	saved config.LightStats
+	// known is set while the light is reachable and state holds its current
+	// state. Changes are only counted against a known state.
+	known bool
+	state lightState
+	// onSince is the start of the on period not yet added to saved.OnSeconds;
+	// zero while the light is off or unreachable.
+	onSince time.Time
+}
+
+// onTime returns the light's on-time at now.
+func (e *entry) onTime(now time.Time) time.Duration {
+	d := time.Duration(e.saved.OnSeconds * float64(time.Second))
+	if !e.onSince.IsZero() && now.After(e.onSince) {
+		d += now.Sub(e.onSince)
+	}
+	return d
+}
+
+// fold adds the current on period up to now to the saved on-time.
+func (e *entry) fold(now time.Time) {
+	if e.onSince.IsZero() {
+		return
+	}
+	if now.After(e.onSince) {
+		e.saved.OnSeconds += now.Sub(e.onSince).Seconds()
+	}
+	e.onSince = now
+}
+
+// Tracker tracks light usage from light events.
+// All access to lights and dirty is guarded by mu.
+type Tracker struct {
+	logger   *slog.Logger
+	cfg      *config.Config
+	bus      *events.Bus
+	source   keylight.LightManager
+	interval time.Duration
+	mu       sync.Mutex
+	lights   map[string]*entry
+	dirty    bool
+	now      func() time.Time
+}
+
+// NewTracker creates a tracker, starting from the statistics saved in the
+// daemon's state.
+func NewTracker(logger *slog.Logger, cfg *config.Config, bus *events.Bus, lights keylight.LightManager) *Tracker {
+	t := &Tracker{
+		logger:   logger,
+		cfg:      cfg,
+		bus:      bus,
+		source:   lights,
+		interval: config.DefaultStatsSaveInterval,
+		lights:   make(map[string]*entry),
+		now:      time.Now,
+	}
+	for id, saved := range cfg.GetLightStats() {
+		t.lights[id] = &entry{saved: saved}
+	}
+	return t
+}
+
+// Run tracks light events until ctx is cancelled, saving the statistics
+// periodically and once more before returning.
+func (t *Tracker) Run(ctx context.Context) {
+	// Light events are few and handling them is cheap, so they are handled
+	// on the bus rather than queued.
+	unsub := t.bus.Subscribe(t.handle)
+	defer unsub()
+
+	// Lights discovered before the subscription have had their events.
+	for _, light := range t.source.GetLights() {
+		t.observe(*light, t.now(), false)
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			t.save()
+			return
+		case <-ticker.C:
+			t.save()
+		}
+	}
+}
+
+// handle updates the statistics for a light event.
+func (t *Tracker) handle(e events.Event) {
+	switch e.Type {
+	case events.LightDiscovered, events.LightStateChanged, events.LightRemoved:
+	default:
+		return
+	}
+	var light keylight.Light
+	if err := json.Unmarshal(e.Data, &light); err != nil || light.ID == "" {
+		t.logger.Debug("stats: ignoring undecodable light event", "type", e.Type, "error", err)
+		return
+	}
+	if e.Type == events.LightRemoved {
+		light.Status = keylight.ReachabilityOffline
+	}
+	t.observe(light, e.Timestamp, e.Type == events.LightStateChanged)
+}
+
+// observe records the state of a light seen at ts. Changes from the last
+// known state count only if changed is set, as discovery reports a light's
+// state without it having changed.
+func (t *Tracker) observe(light keylight.Light, ts time.Time, changed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.lights[light.ID]
+	if !ok {
+		e = &entry{saved: config.LightStats{Since: ts}}
+		t.lights[light.ID] = e
+		t.dirty = true
+	}
+
+	if light.Status == keylight.ReachabilityOffline {
+		e.fold(ts)
+		e.onSince = time.Time{}
+		e.known = false
+		return
+	}
+
+	state := stateOf(light)
+	if !e.known {
+		// Seen for the first time since starting or coming back online:
+		// its state is taken as is.
+		e.known = true
+		e.state = state
+		if state.on {
+			e.onSince = ts
+		}
+		return
+	}
+	if !changed || state == e.state {
+		return
+	}
+
+	e.saved.LastChange = ts
+	if state.on != e.state.on {
+		e.saved.Toggles++
+		e.saved.LastToggle = ts
+		if state.on {
+			e.onSince = ts
+		} else {
+			e.fold(ts)
+			e.onSince = time.Time{}
+		}
+	}
+	e.state = state
+	t.dirty = true
+}
+
+// save saves the statistics with the daemon's state if they changed, or
+// any light is on and so has accrued on-time.
+func (t *Tracker) save() {
+	now := t.now()
+	t.mu.Lock()
+	all := make(map[string]config.LightStats, len(t.lights))
+	dirty := t.dirty
+	for id, e := range t.lights {
+		if !e.onSince.IsZero() {
+			e.fold(now)
+			dirty = true
+		}
+		all[id] = e.saved
+	}
+	t.dirty = false
+	t.mu.Unlock()
+
+	if !dirty {
+		return
+	}
+	t.cfg.SetLightStats(all)
+	if err := t.cfg.Save(); err != nil {
+		t.logger.Error("Failed to save light statistics", "error", err)
+	}
+}
+
+// Light returns the statistics of a light.
+func (t *Tracker) Light(id string) (Stats, error) {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.lights[id]
+	if !ok {
+		return Stats{}, kerrors.NotFoundf("no statistics for light %s", id)
+	}
+	return e.stats(id, now), nil
+}
+
+// Group returns the combined statistics of lightIDs. Lights without
+// statistics, such as ones never discovered, are left out.
+func (t *Tracker) Group(lightIDs []string) GroupStats {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	group := GroupStats{Lights: []Stats{}}
+	for _, id := range lightIDs {
+		e, ok := t.lights[id]
+		if !ok {
+			continue
+		}
+		s := e.stats(id, now)
+		group.OnTime += s.OnTime
+		group.Toggles += s.Toggles
+		if s.LastChange.After(group.LastChange) {
+			group.LastChange = s.LastChange
+		}
+		if s.LastToggle.After(group.LastToggle) {
+			group.LastToggle = s.LastToggle
+		}
+		group.Lights = append(group.Lights, s)
+	}
+	return group
+}
+
+func (e *entry) stats(id string, now time.Time) Stats {
+	return Stats{
+		ID:         id,
+		On:         e.known && e.state.on,
+		OnTime:     e.onTime(now),
+		Toggles:    e.saved.Toggles,
+		LastChange: e.saved.LastChange,
+		LastToggle: e.saved.LastToggle,
+		Since:      e.saved.Since,
+	}
+}
@@ -0,0 +1,169 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type mockLightManager struct {
+	keylight.LightManager
+	lights map[string]*keylight.Light
+}
+
+func (m *mockLightManager) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func setupTestTracker(t *testing.T) (*Tracker, *config.Config) {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(filepath.Join(t.TempDir(), "test.yaml"))
+	cfg := config.New(v)
+	require.NoError(t, cfg.Save())
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	tracker := NewTracker(logger, cfg, events.NewBus(), &mockLightManager{})
+	tracker.now = func() time.Time { return start.Add(time.Hour) }
+	return tracker, cfg
+}
+
+func lightEvent(t events.EventType, light keylight.Light, at time.Duration) events.Event {
+	e := events.NewEvent(t, light)
+	e.Timestamp = start.Add(at)
+	return e
+}
+
+func TestTracker_CountsTogglesAndOnTime(t *testing.T) {
+	tracker, _ := setupTestTracker(t)
+
+	tracker.handle(lightEvent(events.LightDiscovered, keylight.Light{ID: "light1", Brightness: 50}, 0))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", On: true, Brightness: 50}, 10*time.Minute))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", On: true, Brightness: 80}, 20*time.Minute))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", Brightness: 80}, 30*time.Minute))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", On: true, Brightness: 80}, 50*time.Minute))
+
+	s, err := tracker.Light("light1")
+	require.NoError(t, err)
+	assert.True(t, s.On)
+	assert.Equal(t, 3, s.Toggles)
+	// On from 10 to 30 minutes, and from 50 minutes to now (60 minutes).
+	assert.Equal(t, 30*time.Minute, s.OnTime)
+	assert.Equal(t, start.Add(50*time.Minute), s.LastChange)
+	assert.Equal(t, start.Add(50*time.Minute), s.LastToggle)
+	assert.Equal(t, start, s.Since)
+}
+
+func TestTracker_IgnoresNameChangesAndDiscovery(t *testing.T) {
+	tracker, _ := setupTestTracker(t)
+
+	tracker.handle(lightEvent(events.LightDiscovered, keylight.Light{ID: "light1", On: true}, 0))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", Name: "Desk", On: true}, time.Minute))
+	tracker.handle(lightEvent(events.LightDiscovered, keylight.Light{ID: "light1"}, 2*time.Minute))
+
+	s, err := tracker.Light("light1")
+	require.NoError(t, err)
+	assert.Zero(t, s.Toggles)
+	assert.True(t, s.LastChange.IsZero())
+}
+
+func TestTracker_OfflineStopsOnTime(t *testing.T) {
+	tracker, _ := setupTestTracker(t)
+
+	tracker.handle(lightEvent(events.LightDiscovered, keylight.Light{ID: "light1", On: true}, 0))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", On: true, Status: keylight.ReachabilityOffline}, 15*time.Minute))
+
+	s, err := tracker.Light("light1")
+	require.NoError(t, err)
+	assert.False(t, s.On)
+	assert.Equal(t, 15*time.Minute, s.OnTime)
+
+	// Coming back online starts a new on period without counting a toggle.
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", On: true, Status: keylight.ReachabilityOnline}, 45*time.Minute))
+	s, err = tracker.Light("light1")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, s.OnTime)
+	assert.Zero(t, s.Toggles)
+}
+
+func TestTracker_Group(t *testing.T) {
+	tracker, _ := setupTestTracker(t)
+
+	tracker.handle(lightEvent(events.LightDiscovered, keylight.Light{ID: "light1"}, 0))
+	tracker.handle(lightEvent(events.LightDiscovered, keylight.Light{ID: "light2"}, 0))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", On: true}, 40*time.Minute))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light2", On: true}, 50*time.Minute))
+
+	g := tracker.Group([]string{"light1", "light2", "missing"})
+	assert.Equal(t, 30*time.Minute, g.OnTime)
+	assert.Equal(t, 2, g.Toggles)
+	assert.Equal(t, start.Add(50*time.Minute), g.LastToggle)
+	require.Len(t, g.Lights, 2)
+	assert.Equal(t, "light1", g.Lights[0].ID)
+
+	_, err := tracker.Light("missing")
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestTracker_SavesAndRestores(t *testing.T) {
+	tracker, cfg := setupTestTracker(t)
+
+	tracker.handle(lightEvent(events.LightDiscovered, keylight.Light{ID: "light1"}, 0))
+	tracker.handle(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1", On: true}, 20*time.Minute))
+	tracker.save()
+
+	saved := cfg.GetLightStats()["light1"]
+	assert.Equal(t, 1, saved.Toggles)
+	assert.InDelta(t, (40 * time.Minute).Seconds(), saved.OnSeconds, 0.001)
+
+	// A new tracker picks up where the last one stopped; the light's state is
+	// unknown until it is seen again.
+	restored := NewTracker(tracker.logger, cfg, events.NewBus(), &mockLightManager{})
+	restored.now = func() time.Time { return start.Add(2 * time.Hour) }
+	s, err := restored.Light("light1")
+	require.NoError(t, err)
+	assert.False(t, s.On)
+	assert.Equal(t, 40*time.Minute, s.OnTime)
+	assert.Equal(t, 1, s.Toggles)
+	assert.Equal(t, start, s.Since)
+}
+
+func TestTracker_Run(t *testing.T) {
+	tracker, cfg := setupTestTracker(t)
+	tracker.source = &mockLightManager{lights: map[string]*keylight.Light{"light1": {ID: "light1", On: true}}}
+	bus := tracker.bus
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tracker.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := tracker.Light("light1")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	bus.Publish(lightEvent(events.LightStateChanged, keylight.Light{ID: "light1"}, 2*time.Hour))
+
+	cancel()
+	<-done
+	saved, ok := cfg.GetLightStats()["light1"]
+	require.True(t, ok)
+	assert.Equal(t, 1, saved.Toggles)
+}
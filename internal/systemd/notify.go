@@ -0,0 +1,46 @@
+// Package systemd integrates keylightd with systemd: it reports the
+// daemon's lifecycle to the service manager with sd_notify, and installs a
+// user-level service unit that runs the daemon at login.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// Notification states, as documented in sd_notify(3).
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+)
+
+// StatusText returns a notification setting the status text shown by
+// systemctl status.
+func StatusText(text string) string {
+	return "STATUS=" + text
+}
+
+// Notify sends state to the service manager. It reports false, without
+// error, when the daemon wasn't started by systemd with Type=notify, as is
+// the case outside systemd and for other service types.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// A leading @ names a socket in the abstract namespace
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to notify service manager: %w", err)
+	}
+	return true, nil
+}
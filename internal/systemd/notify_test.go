@@ -0,0 +1,39 @@
+package systemd
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify(Ready)
+	require.NoError(t, err)
+	assert.False(t, sent, "nothing is sent outside systemd")
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	sent, err = Notify(Ready + "\n" + StatusText("Listening"))
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "READY=1\nSTATUS=Listening", string(buf[:n]))
+}
+
+func TestNotify_MissingSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	sent, err := Notify(Stopping)
+	assert.Error(t, err)
+	assert.False(t, sent)
+}
@@ -0,0 +1,160 @@
+package systemd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// UnitName is the name of the user-level service unit.
+const UnitName = "keylightd.service"
+
+// Unit describes the user-level service unit that runs the daemon.
+type Unit struct {
+	Program string   // absolute path of the keylightd binary
+	Args    []string // arguments after the program
+}
+
+// UnitPath returns where the user's unit is installed:
+// $XDG_CONFIG_HOME/systemd/user, or ~/.config/systemd/user.
+func UnitPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "systemd", "user", UnitName), nil
+}
+
+// Content returns the unit file. The daemon notifies systemd once its
+// socket is listening (Type=notify), keeps the socket at the default path in
+// the user's runtime directory, and is restarted if it fails. User units
+// can't order themselves after the system's network-online.target; the
+// daemon keeps discovering lights as the network comes up.
+//
+// Only sandboxing that works without privileges is used, as most options
+// need user namespaces when run by a user's service manager: seccomp system
+// call and address family filters, and no privilege gain. The daemon reads
+// its config and state from the usual XDG directories, so the file system
+// is left writable.
+func (u Unit) Content() []byte {
+	args := make([]string, 0, 1+len(u.Args))
+	for _, arg := range append([]string{u.Program}, u.Args...) {
+		args = append(args, quote(arg))
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `[Unit]
+Description=Key Light Daemon (keylightd)
+Documentation=https://github.com/jmylchreest/keylightd
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%s
+Restart=on-failure
+RestartSec=10
+Environment=XDG_RUNTIME_DIR=%%t
+UMask=0077
+NoNewPrivileges=yes
+LockPersonality=yes
+MemoryDenyWriteExecute=yes
+RestrictRealtime=yes
+RestrictSUIDSGID=yes
+RestrictNamespaces=yes
+RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6 AF_NETLINK
+SystemCallArchitectures=native
+SystemCallFilter=@system-service
+SystemCallErrorNumber=EPERM
+
+[Install]
+WantedBy=default.target
+`, strings.Join(args, " "))
+	return b.Bytes()
+}
+
+// quote quotes a command line argument for ExecStart, escaping the
+// characters systemd would otherwise expand.
+func quote(arg string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$")
+	return `"` + r.Replace(arg) + `"`
+}
+
+// systemctl runs systemctl on the user's service manager, returning its
+// output; replaced in tests.
+var systemctl = func(args ...string) (string, error) {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	output := string(bytes.TrimSpace(out))
+	if err != nil {
+		return output, fmt.Errorf("systemctl --user %s: %w: %s", args[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return output, nil
+}
+
+// Install writes the unit to path, reloads the service manager and, with
+// start, enables the unit and (re)starts the daemon so changes apply now.
+func Install(unit Unit, path string, start bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // G301: as created by systemd
+		return fmt.Errorf("failed to create unit directory: %w", err)
+	}
+	if err := os.WriteFile(path, unit.Content(), 0644); err != nil { //nolint:gosec // G306: unit files are not secret
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if !start {
+		return nil
+	}
+	if _, err := systemctl("enable", UnitName); err != nil {
+		return err
+	}
+	_, err := systemctl("restart", UnitName)
+	return err
+}
+
+// Uninstall stops and disables the daemon and removes the unit at path.
+func Uninstall(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("systemd unit not installed: %s not found", path)
+	}
+	if _, err := systemctl("disable", "--now", UnitName); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	_, err := systemctl("daemon-reload")
+	return err
+}
+
+// UnitStatus is the state of the user-level unit.
+type UnitStatus struct {
+	Path      string // where the unit is installed
+	Installed bool   // whether the unit file exists at Path
+	Enabled   string // as reported by systemctl is-enabled, such as enabled or disabled
+	Active    string // as reported by systemctl is-active, such as active or inactive
+}
+
+// Status reports whether the unit at path is installed, enabled and running.
+func Status(path string) UnitStatus {
+	status := UnitStatus{Path: path}
+	if _, err := os.Stat(path); err == nil {
+		status.Installed = true
+	}
+	// Both exit with an error for anything but enabled and active, still
+	// printing the state
+	status.Enabled, _ = systemctl("is-enabled", UnitName)
+	status.Active, _ = systemctl("is-active", UnitName)
+	return status
+}
@@ -0,0 +1,78 @@
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Content(t *testing.T) {
+	unit := Unit{Program: "/opt/key lights/keylightd", Args: []string{"--config", `/home/me/100%"$HOME".yaml`}}
+	content := string(unit.Content())
+
+	assert.Contains(t, content, "\nType=notify\n")
+	assert.Contains(t, content, `ExecStart="/opt/key lights/keylightd" "--config" "/home/me/100%%\"$$HOME\".yaml"`+"\n")
+	assert.Contains(t, content, "\nEnvironment=XDG_RUNTIME_DIR=%t\n")
+	assert.True(t, strings.HasSuffix(content, "[Install]\nWantedBy=default.target\n"))
+}
+
+func TestUnitPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/me/.cfg")
+	path, err := UnitPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me/.cfg/systemd/user/keylightd.service", path)
+}
+
+// fakeSystemctl records systemctl calls, answering with the given output.
+func fakeSystemctl(t *testing.T, output map[string]string) *[]string {
+	t.Helper()
+	var calls []string
+	old := systemctl
+	systemctl = func(args ...string) (string, error) {
+		calls = append(calls, strings.Join(args, " "))
+		return output[args[0]], nil
+	}
+	t.Cleanup(func() { systemctl = old })
+	return &calls
+}
+
+func TestInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "systemd", "user", UnitName)
+	unit := Unit{Program: "/usr/bin/keylightd"}
+
+	calls := fakeSystemctl(t, nil)
+	require.NoError(t, Install(unit, path, true))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, unit.Content(), data)
+	assert.Equal(t, []string{"daemon-reload", "enable keylightd.service", "restart keylightd.service"}, *calls)
+
+	*calls = nil
+	require.NoError(t, Install(unit, path, false))
+	assert.Equal(t, []string{"daemon-reload"}, *calls)
+}
+
+func TestUninstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), UnitName)
+	calls := fakeSystemctl(t, nil)
+
+	assert.Error(t, Uninstall(path), "not installed")
+	assert.Empty(t, *calls)
+
+	require.NoError(t, os.WriteFile(path, []byte("[Unit]\n"), 0600))
+	require.NoError(t, Uninstall(path))
+	assert.NoFileExists(t, path)
+	assert.Equal(t, []string{"disable --now keylightd.service", "daemon-reload"}, *calls)
+}
+
+func TestStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), UnitName)
+	require.NoError(t, os.WriteFile(path, []byte("[Unit]\n"), 0600))
+	fakeSystemctl(t, map[string]string{"is-enabled": "enabled", "is-active": "inactive"})
+
+	assert.Equal(t, UnitStatus{Path: path, Installed: true, Enabled: "enabled", Active: "inactive"}, Status(path))
+}
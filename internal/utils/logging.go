@@ -1,12 +1,14 @@
 package utils
 
 import (
+	"io"
 	"log/slog"
 	"os"
 
 	logfilter "github.com/jmylchreest/slog-logfilter"
 
 	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/requestid"
 )
 
 // LogLevel defines log level types
@@ -67,22 +69,16 @@ func ValidateLogFormat(format string) string {
 
 // SetupLogger creates and returns a new logger backed by slog-logfilter.
 // The logger supports runtime level changes and log filter hot-reload via
-// the logfilter package-level functions (SetLevel, SetFilters, etc.).
+// the logfilter package-level functions (SetLevel, SetFilters, etc.), and
+// adds the request ID carried by the context to lines logged with one.
 func SetupLogger(level string, format string) *slog.Logger {
-	validLevel := ValidateLogLevel(level)
-	validFormat := ValidateLogFormat(format)
-	logLevel := GetLogLevel(validLevel)
-
-	return logfilter.New(
-		logfilter.WithLevel(logLevel),
-		logfilter.WithFormat(validFormat),
-		logfilter.WithSource(true),
-		logfilter.WithOutput(os.Stderr),
-	)
+	return SetupLoggerWithFilters(level, format, nil)
 }
 
-// SetupLoggerWithFilters creates a logger with initial filters applied.
-func SetupLoggerWithFilters(level string, format string, filters []logfilter.LogFilter) *slog.Logger {
+// SetupLoggerWithFilters creates a logger with initial filters applied. Lines
+// are written to stderr and to any extra outputs, such as the in-memory
+// buffer served by the logs API.
+func SetupLoggerWithFilters(level string, format string, filters []logfilter.LogFilter, outputs ...io.Writer) *slog.Logger {
 	validLevel := ValidateLogLevel(level)
 	validFormat := ValidateLogFormat(format)
 	logLevel := GetLogLevel(validLevel)
@@ -91,14 +87,20 @@ func SetupLoggerWithFilters(level string, format string, filters []logfilter.Log
 		logfilter.WithLevel(logLevel),
 		logfilter.WithFormat(validFormat),
 		logfilter.WithSource(true),
-		logfilter.WithOutput(os.Stderr),
+		logfilter.WithOutput(io.MultiWriter(append([]io.Writer{os.Stderr}, outputs...)...)),
 	}
 
 	if len(filters) > 0 {
 		opts = append(opts, logfilter.WithFilters(filters))
 	}
 
-	return logfilter.New(opts...)
+	return withRequestID(logfilter.New(opts...))
+}
+
+// withRequestID wraps a logger so it logs the request ID of contexts passed
+// to its *Context methods.
+func withRequestID(logger *slog.Logger) *slog.Logger {
+	return slog.New(requestid.NewLogHandler(logger.Handler()))
 }
 
 // SetupErrorLogger creates a simple text logger for reporting errors during startup.
@@ -0,0 +1,55 @@
+//go:build linux
+
+package webcam
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const supported = true
+
+// procDir is where processes are listed. Kept as a variable so tests can
+// point it at a fake tree.
+var procDir = "/proc"
+
+// webcamInUse reports whether any process has one of devices open, or any
+// /dev/video* device when devices is empty. Processes the daemon can't inspect
+// are skipped.
+func webcamInUse(devices []string) (bool, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return false, err
+	}
+	self := strconv.Itoa(os.Getpid())
+	for _, entry := range entries {
+		pid := entry.Name()
+		if pid == self || strings.Trim(pid, "0123456789") != "" {
+			continue
+		}
+		fdDir := filepath.Join(procDir, pid, "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue // Exited, or owned by another user
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err == nil && isWatchedDevice(target, devices) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// isWatchedDevice reports whether path is one of devices, or any /dev/video*
+// device when devices is empty.
+func isWatchedDevice(path string, devices []string) bool {
+	if len(devices) == 0 {
+		return strings.HasPrefix(path, "/dev/video")
+	}
+	return slices.Contains(devices, path)
+}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package webcam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebcamInUse(t *testing.T) {
+	dir := t.TempDir()
+	orig := procDir
+	procDir = dir
+	t.Cleanup(func() { procDir = orig })
+
+	addFD := func(pid, fd, target string) {
+		fdDir := filepath.Join(dir, pid, "fd")
+		require.NoError(t, os.MkdirAll(fdDir, 0o755))
+		require.NoError(t, os.Symlink(target, filepath.Join(fdDir, fd)))
+	}
+	addFD("100", "0", "/dev/null")
+	addFD("100", "3", "socket:[1234]")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "self"), 0o755))
+
+	inUse, err := webcamInUse(nil)
+	require.NoError(t, err)
+	assert.False(t, inUse)
+
+	addFD("200", "5", "/dev/video2")
+	inUse, err = webcamInUse(nil)
+	require.NoError(t, err)
+	assert.True(t, inUse)
+
+	// Only the listed devices are watched when some are given
+	inUse, err = webcamInUse([]string{"/dev/video0"})
+	require.NoError(t, err)
+	assert.False(t, inUse)
+	inUse, err = webcamInUse([]string{"/dev/video0", "/dev/video2"})
+	require.NoError(t, err)
+	assert.True(t, inUse)
+}
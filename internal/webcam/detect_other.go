@@ -0,0 +1,10 @@
+//go:build !linux
+
+package webcam
+
+const supported = false
+
+// webcamInUse reports that webcam detection is unavailable.
+func webcamInUse(_ []string) (bool, error) {
+	return false, ErrNotSupported
+}
@@ -0,0 +1,206 @@
+// Package webcam turns groups of lights on while a webcam is in use and off
+// once it is released.
+//
+// Detection polls which processes have a video device open, so it only sees
+// processes the daemon is allowed to inspect: those of the same user, or every
+// process when run as root. It is only supported on Linux; elsewhere Run
+// reports ErrNotSupported.
+package webcam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+)
+
+// ErrNotSupported is returned by Run on platforms where webcam use can't be detected.
+var ErrNotSupported = errors.New("webcam detection is only supported on Linux")
+
+// Overrides of the detected webcam state.
+const (
+	OverrideAuto = "auto" // Follow the webcam
+	OverrideOn   = "on"   // Act as if the webcam is in use
+	OverrideOff  = "off"  // Act as if the webcam is released
+)
+
+// Status is the current state of the webcam watcher.
+type Status struct {
+	Enabled   bool     `json:"enabled"`
+	Supported bool     `json:"supported"`
+	InUse     bool     `json:"in_use"`   // Whether a webcam was in use at the last check
+	Override  string   `json:"override"` // auto, on or off
+	Active    bool     `json:"active"`   // Whether the groups are currently powered for the webcam
+	Groups    []string `json:"groups"`
+}
+
+// Watcher powers the configured groups while a webcam is in use.
+// All access to inUse, override and active is guarded by mu.
+type Watcher struct {
+	logger *slog.Logger
+	cfg    config.WebcamConfig
+	groups *group.Manager
+	// detect reports whether any watched device is open. Kept as a field so
+	// tests can replace it.
+	detect   func(devices []string) (bool, error)
+	inUse    bool
+	override string
+	active   bool
+	// releasedAt is when the webcam was last seen released while the groups
+	// were on, for the off delay.
+	releasedAt time.Time
+	wake       chan struct{}
+	mu         sync.Mutex
+	now        func() time.Time
+}
+
+// NewWatcher creates a webcam watcher for the webcam config.
+func NewWatcher(logger *slog.Logger, cfg config.WebcamConfig, groups *group.Manager) *Watcher {
+	return &Watcher{
+		logger:   logger,
+		cfg:      cfg,
+		groups:   groups,
+		detect:   webcamInUse,
+		override: OverrideAuto,
+		wake:     make(chan struct{}, 1),
+		now:      time.Now,
+	}
+}
+
+// Status returns the current state of the watcher.
+func (w *Watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	groups := w.cfg.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+	return Status{
+		Enabled:   w.cfg.Enabled,
+		Supported: supported,
+		InUse:     w.inUse,
+		Override:  w.override,
+		Active:    w.active,
+		Groups:    groups,
+	}
+}
+
+// SetOverride forces the groups on or off regardless of the webcam, or with
+// OverrideAuto returns to following it. The override lasts until the daemon
+// restarts.
+func (w *Watcher) SetOverride(override string) (Status, error) {
+	switch override {
+	case OverrideAuto, OverrideOn, OverrideOff:
+	default:
+		return Status{}, kerrors.InvalidInputf("invalid override %q, must be %q, %q or %q", override, OverrideAuto, OverrideOn, OverrideOff)
+	}
+	if !w.cfg.Enabled {
+		return Status{}, kerrors.InvalidInputf("the webcam watcher is not enabled in the config")
+	}
+
+	w.mu.Lock()
+	w.override = override
+	w.mu.Unlock()
+	w.logger.Info("webcam override changed", "override", override)
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return w.Status(), nil
+}
+
+// Run checks the webcam every poll interval until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) error {
+	if !supported {
+		return ErrNotSupported
+	}
+	interval := time.Duration(w.cfg.PollInterval) * time.Second
+	if interval <= 0 {
+		interval = config.DefaultWebcamPollInterval
+	}
+
+	w.logger.Info("Starting webcam watcher", "interval", interval, "groups", w.cfg.Groups)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx)
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Webcam watcher stopped")
+			return nil
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// check detects whether the webcam is in use and turns the groups on or off
+// when that changes. Groups are only turned off once the webcam has stayed
+// released for the off delay, so an app reopening it doesn't flicker the lights.
+func (w *Watcher) check(ctx context.Context) {
+	inUse, err := w.detect(w.cfg.Devices)
+	if err != nil {
+		w.logger.Warn("webcam: failed to check for a webcam in use", "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	if inUse != w.inUse {
+		w.logger.Debug("webcam: use changed", "in_use", inUse)
+	}
+	w.inUse = inUse
+	want := inUse
+	switch w.override {
+	case OverrideOn:
+		want = true
+	case OverrideOff:
+		want = false
+	}
+	if want || !w.active {
+		w.releasedAt = time.Time{}
+	} else if w.override == OverrideAuto {
+		if w.releasedAt.IsZero() {
+			w.releasedAt = w.now()
+		}
+		if w.now().Sub(w.releasedAt) < time.Duration(w.cfg.OffDelay)*time.Second {
+			want = true
+		}
+	}
+	changed := want != w.active
+	w.active = want
+	w.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if err := w.setGroups(ctx, want); err != nil {
+		w.logger.Warn("webcam: failed to update groups", "on", want, "error", err)
+		return
+	}
+	w.logger.Info("webcam: updated groups", "on", want, "groups", w.cfg.Groups)
+}
+
+// setGroups turns every configured group on or off.
+func (w *Watcher) setGroups(ctx context.Context, on bool) error {
+	var errs []error
+	for _, key := range w.cfg.Groups {
+		groups, notFound := w.groups.GetGroupsByKeys(key)
+		if len(notFound) > 0 {
+			errs = append(errs, kerrors.NotFoundf("group(s) not found: %s", strings.Join(notFound, ", ")))
+		}
+		for _, g := range groups {
+			if err := w.groups.SetGroupState(ctx, g.ID, on); err != nil {
+				errs = append(errs, fmt.Errorf("group %s: %w", g.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
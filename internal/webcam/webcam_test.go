@@ -0,0 +1,134 @@
+package webcam
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+type mockLightManager struct {
+	keylight.LightManager
+	mu     sync.Mutex
+	lights map[string]*keylight.Light
+}
+
+func (m *mockLightManager) GetLights() map[string]*keylight.Light {
+	return m.lights
+}
+
+func (m *mockLightManager) GetLight(_ context.Context, id string) (*keylight.Light, error) {
+	light, ok := m.lights[id]
+	if !ok {
+		return nil, keylight.ErrLightNotFound
+	}
+	return light, nil
+}
+
+func (m *mockLightManager) SetLightState(_ context.Context, id string, value keylight.LightPropertyValue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if on, ok := value.(keylight.OnValue); ok {
+		m.lights[id].On = bool(on)
+	}
+	return nil
+}
+
+func (m *mockLightManager) isOn(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lights[id].On
+}
+
+func setupTestWatcher(t *testing.T) (*Watcher, *mockLightManager, *bool, *time.Time) {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(filepath.Join(t.TempDir(), "test.yaml"))
+	cfg := config.New(v)
+	require.NoError(t, cfg.Save())
+
+	logger := slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil))
+	lights := &mockLightManager{lights: map[string]*keylight.Light{
+		"light1": {ID: "light1"},
+	}}
+	groups := group.NewManager(logger, lights, cfg)
+	_, err := groups.CreateGroup(context.Background(), "desk", []string{"light1"})
+	require.NoError(t, err)
+
+	w := NewWatcher(logger, config.WebcamConfig{Enabled: true, Groups: []string{"desk"}, OffDelay: 5}, groups)
+	inUse := new(bool)
+	w.detect = func([]string) (bool, error) { return *inUse, nil }
+	now := new(time.Time)
+	*now = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return *now }
+	return w, lights, inUse, now
+}
+
+func TestCheck(t *testing.T) {
+	w, lights, inUse, now := setupTestWatcher(t)
+	ctx := context.Background()
+
+	w.check(ctx)
+	assert.False(t, lights.isOn("light1"))
+
+	*inUse = true
+	w.check(ctx)
+	assert.True(t, lights.isOn("light1"))
+	assert.True(t, w.Status().Active)
+
+	// Groups stay on until the webcam has been released for the off delay
+	*inUse = false
+	w.check(ctx)
+	assert.True(t, lights.isOn("light1"))
+	*now = now.Add(3 * time.Second)
+	w.check(ctx)
+	assert.True(t, lights.isOn("light1"))
+	*now = now.Add(3 * time.Second)
+	w.check(ctx)
+	assert.False(t, lights.isOn("light1"))
+	assert.False(t, w.Status().Active)
+}
+
+func TestSetOverride(t *testing.T) {
+	w, lights, inUse, _ := setupTestWatcher(t)
+	ctx := context.Background()
+
+	status, err := w.SetOverride(OverrideOn)
+	require.NoError(t, err)
+	assert.Equal(t, OverrideOn, status.Override)
+	w.check(ctx)
+	assert.True(t, lights.isOn("light1"))
+
+	// Forcing off takes effect straight away, even with the webcam in use
+	*inUse = true
+	_, err = w.SetOverride(OverrideOff)
+	require.NoError(t, err)
+	w.check(ctx)
+	assert.False(t, lights.isOn("light1"))
+	assert.True(t, w.Status().InUse)
+
+	_, err = w.SetOverride(OverrideAuto)
+	require.NoError(t, err)
+	w.check(ctx)
+	assert.True(t, lights.isOn("light1"))
+
+	_, err = w.SetOverride("sometimes")
+	assert.True(t, kerrors.IsInvalidInput(err))
+
+	w.cfg.Enabled = false
+	_, err = w.SetOverride(OverrideOn)
+	assert.True(t, kerrors.IsInvalidInput(err))
+}
@@ -3,8 +3,12 @@ package ws
 import (
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/jmylchreest/keylightd/internal/events"
 )
 
 var upgrader = websocket.Upgrader{
@@ -17,15 +21,24 @@ var upgrader = websocket.Upgrader{
 // Handler returns an http.HandlerFunc that upgrades connections to WebSocket
 // and registers the client with the hub. Auth is handled at the Chi middleware
 // layer (RawAPIKeyAuth) before this handler is called.
+//
+// The types, lights and groups query parameters filter the events the client
+// is sent; each takes a comma-separated list and may be repeated.
 func Handler(hub *Hub, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		filter := FilterFromQuery(r.URL.Query())
+		if err := filter.Validate(); err != nil {
+			http.Error(w, "invalid event filter: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			logger.Error("ws: upgrade failed", "error", err, "remote_addr", r.RemoteAddr)
 			return
 		}
 
-		client := hub.NewClient(conn)
+		client := hub.NewClient(conn, filter)
 		hub.Register(client)
 
 		// Start read/write pumps in separate goroutines.
@@ -33,3 +46,28 @@ func Handler(hub *Hub, logger *slog.Logger) http.HandlerFunc {
 		go client.ReadPump()
 	}
 }
+
+// FilterFromQuery builds an event filter from the types, lights and groups
+// query parameters of a WebSocket handshake.
+func FilterFromQuery(q url.Values) events.Filter {
+	var filter events.Filter
+	for _, t := range queryList(q, "types") {
+		filter.Types = append(filter.Types, events.EventType(t))
+	}
+	filter.Lights = queryList(q, "lights")
+	filter.Groups = queryList(q, "groups")
+	return filter
+}
+
+// queryList returns the values of a query parameter, splitting each on commas.
+func queryList(q url.Values, key string) []string {
+	var list []string
+	for _, v := range q[key] {
+		for item := range strings.SplitSeq(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
@@ -1,17 +1,20 @@
 // Package ws provides a WebSocket hub for broadcasting real-time events
-// to connected clients.
+// to connected clients and dispatching commands they send.
 package ws
 
 import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
 	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/requestid"
 )
 
 const (
@@ -24,18 +27,43 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer (clients only send pings/pongs).
-	maxMessageSize = 512
+	// Maximum message size allowed from peer (commands are small JSON objects).
+	maxMessageSize = 4096
 
 	// Size of the per-client send buffer.
 	sendBufferSize = 64
+
+	// Message type of command responses, distinguishing them from events.
+	responseType = "response"
 )
 
+// CommandFunc executes a command received from a WebSocket client. The returned
+// map is merged into the response; a non-nil error is sent back as the error message.
+type CommandFunc func(ctx context.Context, action string, data map[string]any) (map[string]any, error)
+
+// command is a request sent by a client over the WebSocket connection.
+type command struct {
+	ID        string         `json:"id,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Action    string         `json:"action"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+// broadcastEvent is an event queued for broadcast, with its JSON encoding.
+type broadcastEvent struct {
+	event events.Event
+	data  []byte
+}
+
 // Client represents a single WebSocket connection.
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan []byte
+	hub    *Hub
+	conn   *websocket.Conn
+	send   chan []byte
+	filter events.Filter // events the client asked for
+
+	sendMu sync.Mutex
+	closed bool // send has been closed by the hub
 }
 
 // Hub manages a set of active WebSocket clients and broadcasts events.
@@ -43,10 +71,12 @@ type Hub struct {
 	logger     *slog.Logger
 	clients    map[*Client]struct{}
 	mu         sync.RWMutex
-	broadcast  chan []byte
+	broadcast  chan broadcastEvent
 	register   chan *Client
 	unregister chan *Client
 	unsub      func() // unsubscribe from event bus
+	commands   CommandFunc
+	groups     events.GroupResolver
 }
 
 // NewHub creates a Hub and subscribes to the event bus.
@@ -54,7 +84,7 @@ func NewHub(logger *slog.Logger, bus *events.Bus) *Hub {
 	h := &Hub{
 		logger:     logger,
 		clients:    make(map[*Client]struct{}),
-		broadcast:  make(chan []byte, 256),
+		broadcast:  make(chan broadcastEvent, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 	}
@@ -68,7 +98,7 @@ func NewHub(logger *slog.Logger, bus *events.Bus) *Hub {
 		}
 		// Non-blocking send; if the broadcast channel is full, log and drop.
 		select {
-		case h.broadcast <- data:
+		case h.broadcast <- broadcastEvent{event: e, data: data}:
 		default:
 			logger.Warn("ws: broadcast channel full, dropping event", "type", e.Type)
 		}
@@ -77,6 +107,23 @@ func NewHub(logger *slog.Logger, bus *events.Bus) *Hub {
 	return h
 }
 
+// SetCommandHandler sets the function that executes commands sent by clients.
+// Without a handler, every command is answered with an error.
+func (h *Hub) SetCommandHandler(fn CommandFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.commands = fn
+}
+
+// SetGroupResolver sets the function that resolves the groups in clients'
+// event filters. Without a resolver, group filters only match group events
+// by the group's ID or name.
+func (h *Hub) SetGroupResolver(fn events.GroupResolver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.groups = fn
+}
+
 // Run starts the hub's main loop. It blocks until ctx is cancelled.
 //
 //nolint:misspell // British spelling intentional
@@ -89,7 +136,7 @@ func (h *Hub) Run(ctx context.Context) {
 		case <-ctx.Done():
 			h.mu.Lock()
 			for c := range h.clients {
-				close(c.send)
+				c.closeSend()
 				delete(h.clients, c)
 			}
 			h.mu.Unlock()
@@ -106,7 +153,7 @@ func (h *Hub) Run(ctx context.Context) {
 		case c := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.clients[c]; ok {
-				close(c.send)
+				c.closeSend()
 				delete(h.clients, c)
 			}
 			count := len(h.clients)
@@ -116,8 +163,11 @@ func (h *Hub) Run(ctx context.Context) {
 		case msg := <-h.broadcast:
 			h.mu.RLock()
 			for c := range h.clients {
+				if !c.filter.Match(msg.event, h.groups) {
+					continue
+				}
 				select {
-				case c.send <- msg:
+				case c.send <- msg.data:
 				default:
 					// Client buffer full — schedule disconnect.
 					go func(cl *Client) {
@@ -147,12 +197,14 @@ func (h *Hub) Unregister(c *Client) {
 	h.unregister <- c
 }
 
-// NewClient creates a new Client attached to this hub.
-func (h *Hub) NewClient(conn *websocket.Conn) *Client {
+// NewClient creates a new Client attached to this hub, which is sent the
+// events passing filter.
+func (h *Hub) NewClient(conn *websocket.Conn, filter events.Filter) *Client {
 	return &Client{
-		hub:  h,
-		conn: conn,
-		send: make(chan []byte, sendBufferSize),
+		hub:    h,
+		conn:   conn,
+		send:   make(chan []byte, sendBufferSize),
+		filter: filter,
 	}
 }
 
@@ -188,11 +240,39 @@ func (c *Client) WritePump() {
 	}
 }
 
-// ReadPump reads messages from the WebSocket connection.
-// We don't expect clients to send meaningful data, but we must read
-// to process control frames (ping/pong/close).
+// closeSend closes the client's send channel once. Called by the hub only.
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// trySend queues a message for the client without blocking. It reports false
+// if the client has been closed or its buffer is full.
+func (c *Client) trySend(msg []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadPump reads messages from the WebSocket connection and executes any
+// commands they contain. It also processes control frames (ping/pong/close).
+// Commands run sequentially, so responses arrive in request order.
 func (c *Client) ReadPump() {
+	ctx, cancel := context.WithCancel(context.Background())
 	defer func() {
+		cancel()
 		c.hub.Unregister(c)
 		_ = c.conn.Close()
 	}()
@@ -205,13 +285,68 @@ func (c *Client) ReadPump() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		msgType, msg, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
 				c.hub.logger.Debug("ws: read error", "error", err)
 			}
 			return
 		}
-		// Discard any client messages; this is a server-push-only endpoint.
+		if msgType != websocket.TextMessage {
+			continue
+		}
+		c.handleCommand(ctx, msg)
+	}
+}
+
+// handleCommand executes a single command and queues the response. Responses
+// have type "response" and echo the command's id so clients can correlate them.
+// Each command gets a request ID, the client's request_id if valid, which is
+// logged while it runs and returned in the response.
+func (c *Client) handleCommand(ctx context.Context, msg []byte) {
+	var cmd command
+	response := map[string]any{}
+	err := json.Unmarshal(msg, &cmd)
+	requestID := requestid.Resolve(cmd.RequestID)
+	ctx = requestid.WithID(ctx, requestID)
+	if err != nil {
+		response["error"] = "invalid command: " + err.Error()
+		response["code"] = kerrors.CodeInvalidRequest
+	} else if cmd.Action == "" {
+		response["error"] = "missing action"
+		response["code"] = kerrors.CodeInvalidRequest
+	} else {
+		c.hub.mu.RLock()
+		fn := c.hub.commands
+		c.hub.mu.RUnlock()
+
+		if fn == nil {
+			response["error"] = "commands are not supported"
+			response["code"] = kerrors.CodeUnknownAction
+		} else if result, err := fn(ctx, cmd.Action, cmd.Data); err != nil {
+			apiErr := kerrors.FromError(err)
+			response["error"] = apiErr.Message
+			response["code"] = apiErr.Code
+			if len(apiErr.Details) > 0 {
+				response["details"] = apiErr.Details
+			}
+		} else {
+			maps.Copy(response, result)
+			response["status"] = "ok"
+		}
+	}
+	response["type"] = responseType
+	response["request_id"] = requestID
+	if cmd.ID != "" {
+		response["id"] = cmd.ID
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		c.hub.logger.ErrorContext(ctx, "ws: failed to marshal response", "action", cmd.Action, "error", err)
+		return
+	}
+	if !c.trySend(data) {
+		c.hub.logger.WarnContext(ctx, "ws: dropping command response", "action", cmd.Action, "id", cmd.ID)
 	}
 }
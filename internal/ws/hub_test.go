@@ -3,9 +3,11 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"sync"
 	"testing"
@@ -16,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/jmylchreest/keylightd/internal/events"
+	"github.com/jmylchreest/keylightd/internal/requestid"
 )
 
 func testLogger() *slog.Logger {
@@ -205,8 +208,64 @@ func TestHub_MultipleEventsInSequence(t *testing.T) {
 	assert.Equal(t, eventTypes, received)
 }
 
+func TestHub_FiltersEvents(t *testing.T) {
+	hub, bus, cancel := startTestHub(t)
+	defer cancel()
+	hub.SetGroupResolver(func(keys []string) map[string][]string {
+		return map[string][]string{"group-1": {"light-2"}}
+	})
+
+	server := startTestServer(t, hub)
+	dialer := websocket.Dialer{}
+	conn, resp, err := dialer.Dial(wsURL(server)+"?types=light.state_changed,group.updated&lights=light-1&groups=office", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	defer conn.Close()
+	time.Sleep(20 * time.Millisecond)
+
+	bus.Publish(events.NewEvent(events.LightDiscovered, map[string]string{"id": "light-1"}))
+	bus.Publish(events.NewEvent(events.LightStateChanged, map[string]string{"id": "light-3"}))
+	bus.Publish(events.NewEvent(events.LightStateChanged, map[string]string{"id": "light-2"}))
+	bus.Publish(events.NewEvent(events.GroupUpdated, map[string]string{"id": "group-1"}))
+	bus.Publish(events.NewEvent(events.LightStateChanged, map[string]string{"id": "light-1"}))
+
+	var received []string
+	for range 3 {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var evt events.Event
+		require.NoError(t, json.Unmarshal(msg, &evt))
+		var data map[string]string
+		require.NoError(t, json.Unmarshal(evt.Data, &data))
+		received = append(received, string(evt.Type)+" "+data["id"])
+	}
+	assert.Equal(t, []string{"light.state_changed light-2", "group.updated group-1", "light.state_changed light-1"}, received)
+}
+
 // --- Handler tests ---
 
+func TestHandler_InvalidFilter(t *testing.T) {
+	hub, _, cancel := startTestHub(t)
+	defer cancel()
+
+	server := startTestServer(t, hub)
+	dialer := websocket.Dialer{}
+	_, resp, err := dialer.Dial(wsURL(server)+"?types=light.exploded", nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestFilterFromQuery(t *testing.T) {
+	q := url.Values{"types": {"light.state_changed, light.removed"}, "lights": {"a", "b,c"}}
+	filter := FilterFromQuery(q)
+	assert.Equal(t, []events.EventType{events.LightStateChanged, events.LightRemoved}, filter.Types)
+	assert.Equal(t, []string{"a", "b", "c"}, filter.Lights)
+	assert.Empty(t, filter.Groups)
+}
+
 func TestHandler_UpgradesConnection(t *testing.T) {
 	hub, _, cancel := startTestHub(t)
 	defer cancel()
@@ -265,6 +324,100 @@ func TestHub_ShutdownClosesClients(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// --- Command tests ---
+
+// readResponse reads the next message and decodes it as a command response.
+func readResponse(t *testing.T, conn *websocket.Conn) map[string]any {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(msg, &resp))
+	return resp
+}
+
+func TestClient_CommandRoundTrip(t *testing.T) {
+	hub, _, cancel := startTestHub(t)
+	defer cancel()
+
+	var gotAction, gotRequestID string
+	var gotData map[string]any
+	hub.SetCommandHandler(func(ctx context.Context, action string, data map[string]any) (map[string]any, error) {
+		gotAction, gotData, gotRequestID = action, data, requestid.FromContext(ctx)
+		return map[string]any{"lights": map[string]any{}}, nil
+	})
+
+	server := startTestServer(t, hub)
+	conn := dialWS(t, server)
+
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"id": "req-1", "action": "list_lights", "data": map[string]any{"verbose": true},
+	}))
+	resp := readResponse(t, conn)
+	assert.Equal(t, "response", resp["type"])
+	assert.Equal(t, "req-1", resp["id"])
+	assert.Equal(t, "ok", resp["status"])
+	assert.Contains(t, resp, "lights")
+	assert.Equal(t, "list_lights", gotAction)
+	assert.Equal(t, true, gotData["verbose"])
+	assert.NotEmpty(t, gotRequestID)
+	assert.Equal(t, gotRequestID, resp["request_id"])
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"action": "list_lights", "request_id": "trace-1"}))
+	assert.Equal(t, "trace-1", readResponse(t, conn)["request_id"])
+	assert.Equal(t, "trace-1", gotRequestID)
+}
+
+func TestClient_CommandError(t *testing.T) {
+	hub, _, cancel := startTestHub(t)
+	defer cancel()
+
+	hub.SetCommandHandler(func(_ context.Context, action string, _ map[string]any) (map[string]any, error) {
+		return nil, fmt.Errorf("unknown action: %s", action)
+	})
+
+	server := startTestServer(t, hub)
+	conn := dialWS(t, server)
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"id": "req-2", "action": "nope"}))
+	resp := readResponse(t, conn)
+	assert.Equal(t, "response", resp["type"])
+	assert.Equal(t, "req-2", resp["id"])
+	assert.Equal(t, "unknown action: nope", resp["error"])
+	assert.NotContains(t, resp, "status")
+}
+
+func TestClient_InvalidCommands(t *testing.T) {
+	hub, _, cancel := startTestHub(t)
+	defer cancel()
+
+	server := startTestServer(t, hub)
+	conn := dialWS(t, server)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	assert.Contains(t, readResponse(t, conn)["error"], "invalid command")
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"id": "req-3"}))
+	resp := readResponse(t, conn)
+	assert.Equal(t, "missing action", resp["error"])
+	assert.Equal(t, "req-3", resp["id"])
+
+	// No command handler configured
+	require.NoError(t, conn.WriteJSON(map[string]any{"id": "req-4", "action": "list_lights"}))
+	assert.Equal(t, "commands are not supported", readResponse(t, conn)["error"])
+}
+
+func TestClient_TrySendAfterClose(t *testing.T) {
+	hub := NewHub(testLogger(), events.NewBus())
+	client := hub.NewClient(nil, events.Filter{})
+
+	assert.True(t, client.trySend([]byte("a")))
+	client.closeSend()
+	client.closeSend() // idempotent
+	assert.False(t, client.trySend([]byte("b")))
+}
+
 // --- NewClient tests ---
 
 func TestNewClient(t *testing.T) {
@@ -273,7 +426,7 @@ func TestNewClient(t *testing.T) {
 	hub := NewHub(logger, bus)
 
 	// We can't create a real websocket.Conn easily, but we can test the factory
-	client := hub.NewClient(nil) // nil conn is okay for testing the struct fields
+	client := hub.NewClient(nil, events.Filter{}) // nil conn is okay for testing the struct fields
 	assert.Equal(t, hub, client.hub)
 	assert.Nil(t, client.conn)
 	assert.NotNil(t, client.send)
@@ -0,0 +1,6 @@
+// Package api holds the request and response bodies of the keylightd HTTP API,
+// for Go clients that call it directly. The types are generated from the
+// OpenAPI spec; regenerate them with go generate after changing the API.
+package api
+
+//go:generate go run ../../cmd/keylight-sdkgen -lang go -package api -output types.go
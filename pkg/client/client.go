@@ -7,9 +7,11 @@ import (
 	"log/slog"
 	"net"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
 var dial = func(network, address string) (net.Conn, error) {
@@ -21,25 +23,106 @@ var dial = func(network, address string) (net.Conn, error) {
 
 type ClientInterface interface {
 	GetVersion() (map[string]any, error)
-	GetLights() (map[string]any, error)
-	GetLight(id string) (map[string]any, error)
-	SetLightState(id string, property string, value any) error
+	GetDaemonInfo() (*DaemonInfo, error)
+	GetLights() (map[string]*Light, error)
+	GetLight(id string) (*Light, error)
+	SetLightState(id string, property string, value any, opts ...StateOption) error
+	SetLightsState(updates []LightStateUpdate) error
+	ToggleLight(id string) (bool, error)
+	SetLightName(id, name string) (string, error)
+	SetLightTags(id string, tags map[string]string) (map[string]string, error)
+	SetDeviceName(id, name string) (string, error)
+	RawRequest(id, method, path string, body json.RawMessage) (*RawResponse, error)
+	GetLightSettings(id string) (*LightSettings, error)
+	SetLightSettings(id string, update LightSettingsUpdate) (*LightSettings, error)
+	GetLightStats(id string) (*LightStats, error)
 	CreateGroup(name string) error
-	GetGroup(name string) (map[string]any, error)
-	GetGroups() ([]map[string]any, error)
-	SetGroupState(name string, property string, value any) error
+	GetGroup(name string) (*Group, error)
+	GetGroups() ([]*Group, error)
+	UpdateGroup(groupID string, update GroupUpdate) (*Group, error)
+	GetGroupState(groupID string) (*GroupState, error)
+	SetGroupState(name string, property string, value any, opts ...StateOption) error
+	ToggleGroup(name string) (map[string]bool, error)
 	DeleteGroup(name string) error
 	SetGroupLights(groupID string, lightIDs []string) error
-	AddAPIKey(name string, expiresInSeconds float64) (map[string]any, error)
-	ListAPIKeys() ([]map[string]any, error)
+	SetGroupDefaults(groupID string, brightness, temperature *int, applyOnJoin bool) error
+	GetGroupStats(groupID string) (*GroupStats, error)
+	DiscoverNow() (*DiscoveryResult, error)
+	ListPendingDevices() ([]PendingDevice, error)
+	AdoptDevice(id string) (*Light, error)
+	AddAPIKey(name string, expiresInSeconds float64) (*APIKey, error)
+	ListAPIKeys() ([]APIKey, error)
 	DeleteAPIKey(key string) error
-	SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (map[string]any, error)
+	SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (*APIKey, error)
+	ListSchedules() ([]map[string]any, error)
+	ListUpcomingSchedules(limit, days int) ([]ScheduleExecution, error)
+	CreateSchedule(schedule map[string]any) (map[string]any, error)
+	DeleteSchedule(id string) error
+	ListScenes() ([]Scene, error)
+	CreateScene(s Scene) (*Scene, error)
+	DeleteScene(id string) error
+	ApplyScene(id string) (*Job, error)
+	GetSceneRun(id string) (*Job, error)
+	CancelSceneRun(id string) (*Job, error)
+	ListJobs() ([]Job, error)
+	GetJob(id string) (*Job, error)
+	CancelJob(id string) (*Job, error)
+	GetCircadian() (*CircadianStatus, error)
+	SetCircadian(enabled bool) (*CircadianStatus, error)
+	GetDesiredState() (*DesiredStateStatus, error)
+	SetDesiredState(enabled bool) (*DesiredStateStatus, error)
+	SetDesiredStateTarget(target string, enabled bool) (*DesiredStateStatus, error)
+	ExportConfig(includeSecrets bool) (*ExportDocument, error)
+	ImportConfig(doc *ExportDocument) (*ImportResult, error)
+	GetLogLevel() (string, error)
+	SetLogLevel(level string) error
+	ListLogFilters() ([]map[string]any, error)
+	AddLogFilter(filter map[string]any) error
+	RemoveLogFilter(filterType, pattern string) error
+	GetLogs(q LogQuery) ([]LogEntry, error)
+}
+
+// StateOption modifies a light or group state request before it is sent.
+type StateOption func(data map[string]any)
+
+// WithTransition ramps brightness and temperature to the new value over d
+// instead of applying it instantly.
+func WithTransition(d time.Duration) StateOption {
+	return func(data map[string]any) {
+		if d > 0 {
+			data["transition_ms"] = d.Milliseconds()
+		}
+	}
+}
+
+// WithMireds sends the temperature in mireds instead of Kelvin. Relative
+// temperature values are always in Kelvin.
+func WithMireds() StateOption {
+	return func(data map[string]any) {
+		data["units"] = keylight.UnitMired
+	}
+}
+
+// LightStateUpdate is one light's entry in a batch state request. Nil fields are left unchanged.
+type LightStateUpdate struct {
+	ID          string   `json:"id"`
+	On          *bool    `json:"on,omitempty"`
+	Brightness  *int     `json:"brightness,omitempty"`
+	Temperature *int     `json:"temperature,omitempty"`
+	Hue         *float64 `json:"hue,omitempty"`
+	Saturation  *float64 `json:"saturation,omitempty"`
 }
 
 // Client represents a connection to keylightd
 type Client struct {
 	logger *slog.Logger
 	socket string
+
+	helloMu sync.Mutex
+	server  *ServerInfo // set once negotiated by Hello
+
+	connMu sync.Mutex
+	conn   *socketConn // persistent connection, redialed when it fails
 }
 
 // New creates a new client
@@ -71,71 +154,110 @@ func extractMap(resp any) map[string]any {
 	return nil
 }
 
-// request sends a request to keylightd and returns the response
+// request sends a request to keylightd and returns the response. The daemon
+// is asked what it supports before the first request, so requests it doesn't
+// support fail with an UnsupportedActionError.
 func (c *Client) request(req any, resp any) error {
-	c.logger.Debug("Connecting to socket", "socket", c.socket)
-	// Connect to socket
-	conn, err := dial("unix", c.socket)
-	if err != nil {
-		c.logger.Error("Failed to connect to socket", "error", err, "socket", c.socket)
-		return fmt.Errorf("failed to connect to socket: %w", err)
+	action := requestAction(req)
+	if err := c.checkSupported(action); err != nil {
+		return err
+	}
+	err := c.roundTrip(req, resp)
+	if isUnknownAction(err) {
+		info, _ := c.Hello()
+		return &UnsupportedActionError{Action: action, Server: info}
 	}
-	defer conn.Close()
-	if err := conn.SetDeadline(time.Now().Add(30 * time.Second)); err != nil {
-		return fmt.Errorf("failed to set connection deadline: %w", err)
+	return err
+}
+
+// roundTrip sends a single request to keylightd and decodes the response.
+func (c *Client) roundTrip(req any, resp any) error {
+	c.logger.Debug("Sending request", "request", req)
+	raw, err := c.send(req)
+	if err != nil {
+		c.logger.Error("Request failed", "error", err, "socket", c.socket)
+		return err
 	}
 
-	c.logger.Debug("Encoding request", "request", req)
-	// Encode request
-	if err := json.NewEncoder(conn).Encode(req); err != nil {
-		c.logger.Error("Failed to encode request", "error", err)
-		return fmt.Errorf("failed to encode request: %w", err)
+	// Decode into a map as well when resp is nil, so errors are still seen
+	if resp == nil {
+		var tempResp map[string]any
+		resp = &tempResp
 	}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		c.logger.Error("Failed to decode response", "error", err)
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	c.logger.Debug("Received response", "response", resp)
 
-	c.logger.Debug("Waiting for response")
-	// Decode response only if resp is not nil
-	if resp != nil {
-		if err := json.NewDecoder(conn).Decode(resp); err != nil {
-			c.logger.Error("Failed to decode response", "error", err)
-			return fmt.Errorf("failed to decode response: %w", err)
+	// Check for error in response.
+	// resp may be *map[string]any (pointer) or map[string]any (value);
+	// handle both so server errors are never silently ignored.
+	respMap := extractMap(resp)
+	if respMap != nil {
+		if errMsg, ok := respMap["error"].(string); ok {
+			c.logger.Error("Server returned error", "error", errMsg)
+			return fmt.Errorf("server error: %w", socketError(respMap, errMsg))
 		}
-		c.logger.Debug("Received response", "response", resp)
-
-		// Check for error in response.
-		// resp may be *map[string]any (pointer) or map[string]any (value);
-		// handle both so server errors are never silently ignored.
-		respMap := extractMap(resp)
-		if respMap != nil {
-			if errMsg, ok := respMap["error"].(string); ok {
-				c.logger.Error("Server returned error", "error", errMsg)
-				return fmt.Errorf("server error: %s", errMsg)
+		// Check for partial-success responses (e.g. multi-group set operations)
+		if status, _ := respMap["status"].(string); status == "partial" {
+			if errs, ok := respMap["errors"].([]any); ok && len(errs) > 0 {
+				c.logger.Error("Server returned partial errors", "errors", errs)
+				return fmt.Errorf("server error (partial): %v", errs)
 			}
-			// Check for partial-success responses (e.g. multi-group set operations)
-			if status, _ := respMap["status"].(string); status == "partial" {
-				if errs, ok := respMap["errors"].([]any); ok && len(errs) > 0 {
-					c.logger.Error("Server returned partial errors", "errors", errs)
-					return fmt.Errorf("server error (partial): %v", errs)
-				}
-			}
-			c.logger.Debug("Response processed successfully")
-		}
-	} else {
-		// When resp is nil, we still need to read and check for errors
-		var tempResp map[string]any
-		if err := json.NewDecoder(conn).Decode(&tempResp); err != nil {
-			c.logger.Error("Failed to decode response", "error", err)
-			return fmt.Errorf("failed to decode response: %w", err)
 		}
-		c.logger.Debug("Received response (nil target)", "response", tempResp)
+		c.logger.Debug("Response processed successfully")
+	}
+	return nil
+}
 
-		// Check for error in response
-		if errMsg, ok := tempResp["error"].(string); ok {
-			c.logger.Error("Server returned error", "error", errMsg)
-			return fmt.Errorf("server error: %s", errMsg)
+// send sends a request on the client's connection and returns the raw
+// response. A request that couldn't be sent, typically because the daemon
+// restarted and closed the old connection, is retried once on a new one.
+func (c *Client) send(req any) (json.RawMessage, error) {
+	for attempt := 0; ; attempt++ {
+		sc, err := c.connection()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := sc.do(req)
+		if errors.Is(err, errNotSent) && attempt == 0 {
+			c.logger.Debug("Reconnecting to socket", "error", err)
+			continue
 		}
-		c.logger.Debug("Response processed successfully (nil target)")
+		return raw, err
+	}
+}
+
+// connection returns the client's connection to the daemon, dialing a new one
+// if there is none or the last one failed.
+func (c *Client) connection() (*socketConn, error) {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	if c.conn != nil && c.conn.alive() {
+		return c.conn, nil
+	}
+
+	c.logger.Debug("Connecting to socket", "socket", c.socket)
+	conn, err := dial("unix", c.socket)
+	if err != nil {
+		c.logger.Error("Failed to connect to socket", "error", err, "socket", c.socket)
+		return nil, fmt.Errorf("failed to connect to socket: %w", err)
 	}
+	c.conn = newSocketConn(c.logger, conn, keepAliveInterval)
+	return c.conn, nil
+}
 
+// Close closes the client's connection to the daemon. The client reconnects
+// if it is used again.
+func (c *Client) Close() error {
+	c.connMu.Lock()
+	sc := c.conn
+	c.conn = nil
+	c.connMu.Unlock()
+	if sc != nil {
+		sc.fail(net.ErrClosed)
+	}
 	return nil
 }
 
@@ -148,41 +270,46 @@ func (c *Client) GetVersion() (map[string]any, error) {
 	return resp, nil
 }
 
-// GetLights returns all discovered lights
-func (c *Client) GetLights() (map[string]any, error) {
+// GetDaemonInfo returns information about the running daemon, such as its
+// uptime and discovery statistics.
+func (c *Client) GetDaemonInfo() (*DaemonInfo, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "get_daemon_info"}, &resp); err != nil {
+		return nil, err
+	}
+
+	infoField, ok := resp["info"]
+	if !ok {
+		return nil, errors.New("no info field in response")
+	}
+	var info DaemonInfo
+	if err := decodeInto(infoField, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetLights returns all discovered lights, keyed by ID
+func (c *Client) GetLights() (map[string]*Light, error) {
 	var resp map[string]any
 	if err := c.request(map[string]string{"action": "list_lights"}, &resp); err != nil {
 		return nil, err
 	}
 
-	// The server returns {"lights": {id: lightMap, ...}}, so extract the 'lights' field
+	// The server returns {"lights": {id: light, ...}}
 	lightsField, ok := resp["lights"]
 	if !ok {
 		return nil, errors.New("no lights field in response")
 	}
-	lightsMap, ok := lightsField.(map[string]any)
-	if !ok {
-		return nil, errors.New("invalid lights format in response")
-	}
-
-	// Iterate through lights and convert lastseen string to time.Time
-	for _, lightData := range lightsMap {
-		if lightMap, ok := lightData.(map[string]any); ok {
-			if lastSeenStr, ok := lightMap["lastseen"].(string); ok {
-				if t, err := time.Parse(time.RFC3339, lastSeenStr); err == nil {
-					lightMap["lastseen"] = t
-				} else {
-					c.logger.Error("Failed to parse lastseen time string", "error", err, "value", lastSeenStr)
-				}
-			}
-		}
+	var lights map[string]*Light
+	if err := decodeInto(lightsField, &lights); err != nil {
+		return nil, err
 	}
-
-	return lightsMap, nil
+	return lights, nil
 }
 
 // GetLight returns the state of a specific light
-func (c *Client) GetLight(id string) (map[string]any, error) {
+func (c *Client) GetLight(id string) (*Light, error) {
 	var resp map[string]any
 	if err := c.request(map[string]any{
 		"action": "get_light",
@@ -191,39 +318,196 @@ func (c *Client) GetLight(id string) (map[string]any, error) {
 		return nil, err
 	}
 
-	// If the response is wrapped in a "light" field, extract it
-	if light, ok := resp["light"].(map[string]any); ok {
-		resp = light
+	lightField, ok := resp["light"]
+	if !ok {
+		return nil, errors.New("no light field in response")
 	}
-
-	// Convert lastseen string to time.Time
-	if lastSeenStr, ok := resp["lastseen"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, lastSeenStr); err == nil {
-			resp["lastseen"] = t
-		} else {
-			c.logger.Error("Failed to parse lastseen time string", "error", err, "value", lastSeenStr)
-		}
+	var light Light
+	if err := decodeInto(lightField, &light); err != nil {
+		return nil, err
 	}
-
-	return resp, nil
+	return &light, nil
 }
 
 // SetLightState sets the state of a specific light
-func (c *Client) SetLightState(id string, property string, value any) error {
+func (c *Client) SetLightState(id string, property string, value any, opts ...StateOption) error {
+	data := map[string]any{
+		"id":       id,
+		"property": property,
+		"value":    value,
+	}
+	for _, opt := range opts {
+		opt(data)
+	}
 	var resp map[string]any
 	if err := c.request(map[string]any{
 		"action": "set_light_state",
-		"data": map[string]any{
-			"id":       id,
-			"property": property,
-			"value":    value,
-		},
+		"data":   data,
 	}, &resp); err != nil {
 		return err
 	}
 	return nil
 }
 
+// SetLightsState applies state updates to several lights at once. The daemon
+// applies them concurrently and rejects the whole batch if any update is invalid.
+func (c *Client) SetLightsState(updates []LightStateUpdate) error {
+	var resp map[string]any
+	return c.request(map[string]any{
+		"action": "set_lights_state",
+		"data":   map[string]any{"lights": updates},
+	}, &resp)
+}
+
+// ToggleLight inverts a light's power state and returns the new state.
+func (c *Client) ToggleLight(id string) (bool, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "toggle_light",
+		"data":   map[string]any{"id": id},
+	}, &resp); err != nil {
+		return false, err
+	}
+	on, _ := resp["on"].(bool)
+	return on, nil
+}
+
+// SetLightName sets a light's display name override and returns the light's
+// resulting name. An empty name restores the name reported by the device.
+func (c *Client) SetLightName(id, name string) (string, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "set_light_name",
+		"data":   map[string]any{"id": id, "name": name},
+	}, &resp); err != nil {
+		return "", err
+	}
+	newName, _ := resp["name"].(string)
+	return newName, nil
+}
+
+// SetLightTags replaces a light's tags and returns the tags it now has. Nil
+// or empty tags remove them.
+func (c *Client) SetLightTags(id string, tags map[string]string) (map[string]string, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "set_light_tags",
+		"data":   map[string]any{"id": id, "tags": tags},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	var light Light
+	if err := decodeInto(resp["light"], &light); err != nil {
+		return nil, err
+	}
+	return light.Tags, nil
+}
+
+// SetDeviceName writes a new display name to the light itself and returns
+// the name the light is now shown with.
+func (c *Client) SetDeviceName(id, name string) (string, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "set_device_name",
+		"data":   map[string]any{"id": id, "name": name},
+	}, &resp); err != nil {
+		return "", err
+	}
+	newName, _ := resp["name"].(string)
+	return newName, nil
+}
+
+// RawRequest passes a request straight through to a light's own API and
+// returns the device's response. body may be nil.
+func (c *Client) RawRequest(id, method, path string, body json.RawMessage) (*RawResponse, error) {
+	data := map[string]any{"id": id, "method": method, "path": path}
+	if len(body) > 0 {
+		data["body"] = body
+	}
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "raw_request",
+		"data":   data,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	field, ok := resp["response"]
+	if !ok {
+		return nil, errors.New("no response field in response")
+	}
+	var raw RawResponse
+	if err := decodeInto(field, &raw); err != nil {
+		return nil, err
+	}
+	return &raw, nil
+}
+
+// GetLightSettings returns the power-on settings stored on a light.
+func (c *Client) GetLightSettings(id string) (*LightSettings, error) {
+	return c.lightSettings("get_light_settings", map[string]any{"id": id})
+}
+
+// SetLightSettings changes the power-on settings stored on a light and
+// returns the settings as written. Nil fields are left as they are.
+func (c *Client) SetLightSettings(id string, update LightSettingsUpdate) (*LightSettings, error) {
+	data := map[string]any{"id": id}
+	if update.PowerOnBehavior != nil {
+		data["power_on_behavior"] = *update.PowerOnBehavior
+	}
+	if update.PowerOnBrightness != nil {
+		data["power_on_brightness"] = *update.PowerOnBrightness
+	}
+	if update.PowerOnTemperature != nil {
+		data["power_on_temperature"] = *update.PowerOnTemperature
+	}
+	return c.lightSettings("set_light_settings", data)
+}
+
+// lightSettings sends a light settings action and decodes the settings it returns.
+func (c *Client) lightSettings(action string, data map[string]any) (*LightSettings, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": action,
+		"data":   data,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	field, ok := resp["settings"]
+	if !ok {
+		return nil, errors.New("no settings field in response")
+	}
+	var settings LightSettings
+	if err := decodeInto(field, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// GetLightStats returns a light's usage statistics.
+func (c *Client) GetLightStats(id string) (*LightStats, error) {
+	var stats LightStats
+	if err := c.stats("get_light_stats", id, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// stats sends a stats action and decodes the stats it returns into v.
+func (c *Client) stats(action, id string, v any) error {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": action,
+		"data":   map[string]any{"id": id},
+	}, &resp); err != nil {
+		return err
+	}
+	field, ok := resp["stats"]
+	if !ok {
+		return errors.New("no stats field in response")
+	}
+	return decodeInto(field, v)
+}
+
 // CreateGroup creates a new group of lights
 func (c *Client) CreateGroup(name string) error {
 	var resp map[string]any
@@ -249,8 +533,8 @@ func (c *Client) CreateGroup(name string) error {
 	return nil
 }
 
-// GetGroup returns the state of all lights in a group
-func (c *Client) GetGroup(id string) (map[string]any, error) {
+// GetGroup returns a specific group
+func (c *Client) GetGroup(id string) (*Group, error) {
 	var resp map[string]any
 	if err := c.request(map[string]any{
 		"action": "get_group",
@@ -259,57 +543,103 @@ func (c *Client) GetGroup(id string) (map[string]any, error) {
 		return nil, err
 	}
 
-	// If the response is wrapped in a "group" field, extract it
-	if group, ok := resp["group"].(map[string]any); ok {
-		resp = group
+	groupField, ok := resp["group"]
+	if !ok {
+		return nil, errors.New("no group field in response")
 	}
-
-	return resp, nil
+	var grp Group
+	if err := decodeInto(groupField, &grp); err != nil {
+		return nil, err
+	}
+	return &grp, nil
 }
 
-// GetGroups returns all groups
-func (c *Client) GetGroups() ([]map[string]any, error) {
+// UpdateGroup renames a group or changes its description, icon or color, and
+// returns the group as updated.
+func (c *Client) UpdateGroup(groupID string, update GroupUpdate) (*Group, error) {
+	data := map[string]any{"id": groupID}
+	for property, v := range map[string]*string{"name": update.Name, "description": update.Description, "icon": update.Icon, "color": update.Color} {
+		if v != nil {
+			data[property] = *v
+		}
+	}
+
 	var resp map[string]any
-	if err := c.request(map[string]string{
-		"action": "list_groups",
+	if err := c.request(map[string]any{
+		"action": "update_group",
+		"data":   data,
 	}, &resp); err != nil {
 		return nil, err
 	}
 
-	groupsField, ok := resp["groups"]
+	groupField, ok := resp["group"]
 	if !ok {
-		return nil, nil
+		return nil, errors.New("no group field in response")
 	}
-	groupsSlice, ok := groupsField.([]any)
-	if !ok {
-		return nil, errors.New("invalid groups format in response")
+	var grp Group
+	if err := decodeInto(groupField, &grp); err != nil {
+		return nil, err
 	}
+	return &grp, nil
+}
 
-	groups := make([]map[string]any, 0, len(groupsSlice))
-	for _, g := range groupsSlice {
-		if groupMap, ok := g.(map[string]any); ok {
-			groups = append(groups, groupMap)
+// GetGroups returns all groups
+func (c *Client) GetGroups() ([]*Group, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{
+		"action": "list_groups",
+	}, &resp); err != nil {
+		return nil, err
+	}
+
+	groups := []*Group{}
+	if groupsField, ok := resp["groups"]; ok {
+		if err := decodeInto(groupsField, &groups); err != nil {
+			return nil, err
 		}
 	}
 	return groups, nil
 }
 
 // SetGroupState sets the state of all lights in a group
-func (c *Client) SetGroupState(id string, property string, value any) error {
+func (c *Client) SetGroupState(id string, property string, value any, opts ...StateOption) error {
+	data := map[string]any{
+		"id":       id,
+		"property": property,
+		"value":    value,
+	}
+	for _, opt := range opts {
+		opt(data)
+	}
 	var resp map[string]any
 	if err := c.request(map[string]any{
 		"action": "set_group_state",
-		"data": map[string]any{
-			"id":       id,
-			"property": property,
-			"value":    value,
-		},
+		"data":   data,
 	}, &resp); err != nil {
 		return err
 	}
 	return nil
 }
 
+// ToggleGroup toggles one or more groups (comma-separated IDs or names) and
+// returns the power state applied to each, keyed by group ID.
+func (c *Client) ToggleGroup(id string) (map[string]bool, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "toggle_group",
+		"data":   map[string]any{"id": id},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	states := make(map[string]bool)
+	if groups, ok := resp["groups"].(map[string]any); ok {
+		for groupID, on := range groups {
+			states[groupID], _ = on.(bool)
+		}
+	}
+	return states, nil
+}
+
 // DeleteGroup deletes a group of lights
 func (c *Client) DeleteGroup(id string) error {
 	var resp map[string]any
@@ -343,11 +673,119 @@ func (c *Client) SetGroupLights(groupID string, lightIDs []string) error {
 	return nil
 }
 
+// SetGroupDefaults sets the default brightness and temperature of a group.
+// Passing nil for both clears the defaults.
+func (c *Client) SetGroupDefaults(groupID string, brightness, temperature *int, applyOnJoin bool) error {
+	data := map[string]any{
+		"id":            groupID,
+		"apply_on_join": applyOnJoin,
+	}
+	if brightness != nil {
+		data["brightness"] = *brightness
+	}
+	if temperature != nil {
+		data["temperature"] = *temperature
+	}
+
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "set_group_defaults",
+		"data":   data,
+	}, &resp); err != nil {
+		return err
+	}
+
+	if err, ok := resp["error"].(string); ok {
+		return fmt.Errorf("server error: %s", err)
+	}
+
+	return nil
+}
+
+// GetGroupState returns the aggregate state of a group's lights.
+func (c *Client) GetGroupState(groupID string) (*GroupState, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "get_group_state",
+		"data":   map[string]any{"id": groupID},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	field, ok := resp["state"]
+	if !ok {
+		return nil, errors.New("no state field in response")
+	}
+	var state GroupState
+	if err := decodeInto(field, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetGroupStats returns the combined usage statistics of a group's lights.
+func (c *Client) GetGroupStats(groupID string) (*GroupStats, error) {
+	var stats GroupStats
+	if err := c.stats("get_group_stats", groupID, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// DiscoverNow runs a discovery pass straight away and returns the lights it
+// found that weren't known before. It waits for the pass, which takes several
+// seconds.
+func (c *Client) DiscoverNow() (*DiscoveryResult, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "discover_now"}, &resp); err != nil {
+		return nil, err
+	}
+	var result DiscoveryResult
+	if err := decodeInto(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPendingDevices returns the devices discovery found that aren't
+// supported lights, which can be adopted with AdoptDevice.
+func (c *Client) ListPendingDevices() ([]PendingDevice, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "list_pending_devices"}, &resp); err != nil {
+		return nil, err
+	}
+	var devices []PendingDevice
+	if err := decodeInto(resp["devices"], &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// AdoptDevice adds a pending device as a light and returns it. The daemon
+// remembers the adoption, so the device stays a light after restarts.
+func (c *Client) AdoptDevice(id string) (*Light, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "adopt_device",
+		"data":   map[string]any{"id": id},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	lightField, ok := resp["light"]
+	if !ok {
+		return nil, errors.New("no light field in response")
+	}
+	var light Light
+	if err := decodeInto(lightField, &light); err != nil {
+		return nil, err
+	}
+	return &light, nil
+}
+
 // API Key Management Methods
 
-// AddAPIKey tells keylightd to add a new API key.
-func (c *Client) AddAPIKey(name string, expiresInSeconds float64) (map[string]any, error) {
-	// Server expects: { "action": "apikey_add", "data": { "name": "...". "expires_in": "..." } }
+// AddAPIKey creates a new API key. The returned key is the only time the
+// secret is shown.
+func (c *Client) AddAPIKey(name string, expiresInSeconds float64) (*APIKey, error) {
 	reqData := map[string]any{
 		"name": name,
 	}
@@ -355,105 +793,65 @@ func (c *Client) AddAPIKey(name string, expiresInSeconds float64) (map[string]an
 		reqData["expires_in"] = fmt.Sprintf("%f", expiresInSeconds) // Server socket handler expects string seconds
 	}
 
-	apiRequest := map[string]any{
+	var resp map[string]any
+	if err := c.request(map[string]any{
 		"action": "apikey_add",
 		"data":   reqData,
-	}
-
-	var serverResponse map[string]any
-	if err := c.request(apiRequest, &serverResponse); err != nil {
+	}, &resp); err != nil {
 		return nil, err
 	}
 
-	// Server sends: {"status": "ok", "id": "req_id_optional", "key": APIKeyObject}
-	// The c.request method handles the general structure and potential top-level "error" field.
-	// If we are here, c.request did not return an error, implying basic success.
-
-	apiKeyData, ok := serverResponse["key"].(map[string]any)
+	// Server sends: {"status": "ok", "key": APIKeyObject}
+	keyField, ok := resp["key"]
 	if !ok {
-		// This case implies the server response was successful at a transport level,
-		// but the expected "key" field containing the APIKey details is missing.
-		// This indicates an unexpected response structure from the server for a successful apikey_add operation.
-		c.logger.Error("apikey_add response missing 'key' field", "response", serverResponse)
-		return nil, fmt.Errorf("server response for apikey_add missing 'key' field: %+v", serverResponse)
-	}
-
-	// Parse time strings in the returned key data, similar to ListAPIKeys
-	for _, field := range []string{"created_at", "expires_at", "last_used_at"} {
-		if valStr, ok := apiKeyData[field].(string); ok {
-			// Handle zero time explicitly
-			if valStr == "0001-01-01T00:00:00Z" {
-				apiKeyData[field] = time.Time{} // Set to zero time
-				continue
-			}
-			// Try parsing with RFC3339Nano first, then RFC3339
-			if t, err := time.Parse(time.RFC3339Nano, valStr); err == nil {
-				apiKeyData[field] = t
-			} else if t, err := time.Parse(time.RFC3339, valStr); err == nil {
-				apiKeyData[field] = t
-			} else if valStr != "" { // Only warn if it's not an empty string that failed parsing
-				c.logger.Warn("Failed to parse time string in AddAPIKey response", "field", field, "value", valStr, "error", err)
-				// If parsing fails for a non-empty string, keep the original string
-				apiKeyData[field] = valStr
-			}
-		}
+		return nil, fmt.Errorf("server response for apikey_add missing 'key' field: %+v", resp)
+	}
+	var key APIKey
+	if err := decodeInto(keyField, &key); err != nil {
+		return nil, err
 	}
+	return &key, nil
+}
 
-	return apiKeyData, nil
+// BootstrapUIKey asks the daemon to issue an API key for a local UI, such as
+// the tray or GNOME extension, so it can use the HTTP API. The key can read
+// and control lights but not manage keys or configuration. Asking again with
+// the same client name replaces the previous key. Only the socket API offers
+// this; connecting to the socket is the authorization.
+func (c *Client) BootstrapUIKey(clientName string) (*UIKey, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "apikey_bootstrap",
+		"data":   map[string]any{"client": clientName},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	var key UIKey
+	if err := decodeInto(resp, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
 }
 
 // ListAPIKeys lists all API keys
-func (c *Client) ListAPIKeys() ([]map[string]any, error) {
+func (c *Client) ListAPIKeys() ([]APIKey, error) {
 	// Expect the server's wrapper object { "status": "ok", "keys": [...] }
-	var serverResponse map[string]any
+	var resp map[string]any
 	if err := c.request(map[string]string{
 		"action": "apikey_list",
-	}, &serverResponse); err != nil {
+	}, &resp); err != nil {
 		return nil, err
 	}
 
-	// Extract the actual list of keys from the "keys" field
-	keysData, ok := serverResponse["keys"].([]any)
+	keysField, ok := resp["keys"]
 	if !ok {
-		if errMsg, hasErr := serverResponse["error"].(string); hasErr {
-			return nil, fmt.Errorf("server error: %s", errMsg)
-		}
-		return nil, fmt.Errorf("failed to parse 'keys' field from server response: %v", serverResponse)
+		return nil, fmt.Errorf("failed to parse 'keys' field from server response: %v", resp)
 	}
-
-	apiKeys := make([]map[string]any, 0, len(keysData))
-	for _, keyEntry := range keysData {
-		keyMap, ok := keyEntry.(map[string]any)
-		if !ok {
-			return nil, fmt.Errorf("invalid API key entry in server response: %v", keyEntry)
-		}
-		apiKeys = append(apiKeys, keyMap)
-	}
-
-	// Post-process response: parse time strings
-	for _, keyData := range apiKeys { // Iterate over the extracted apiKeys
-		for _, field := range []string{"created_at", "expires_at", "last_used_at"} {
-			if valStr, ok := keyData[field].(string); ok {
-				// Handle zero time explicitly
-				if valStr == "0001-01-01T00:00:00Z" {
-					keyData[field] = time.Time{} // Set to zero time
-					continue
-				}
-				// Try parsing with RFC3339Nano first, then RFC3339
-				if t, err := time.Parse(time.RFC3339Nano, valStr); err == nil {
-					keyData[field] = t
-				} else if t, err := time.Parse(time.RFC3339, valStr); err == nil {
-					keyData[field] = t
-				} else if valStr != "" { // Only warn if it's not an empty string that failed parsing
-					c.logger.Warn("Failed to parse time string for API key", "field", field, "value", valStr, "error", err)
-					// If parsing fails for a non-empty string, keep the original string
-					keyData[field] = valStr
-				}
-			}
-			// If it's already a time.Time (e.g. from AddAPIKey response processing), no action needed.
-		}
+	keys := []APIKey{}
+	if err := decodeInto(keysField, &keys); err != nil {
+		return nil, err
 	}
-	return apiKeys, nil
+	return keys, nil
 }
 
 // DeleteAPIKey deletes an API key by its value
@@ -469,7 +867,7 @@ func (c *Client) DeleteAPIKey(key string) error {
 }
 
 // SetAPIKeyDisabledStatus sends a request to enable or disable an API key.
-func (c *Client) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (map[string]any, error) {
+func (c *Client) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (*APIKey, error) {
 	payload := map[string]any{
 		"action": "apikey_set_disabled_status",
 		"data": map[string]any{
@@ -477,19 +875,367 @@ func (c *Client) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (map[s
 			"disabled":    strconv.FormatBool(disabled),
 		},
 	}
-	var respData map[string]any
-	if err := c.request(payload, &respData); err != nil {
+	var resp map[string]any
+	if err := c.request(payload, &resp); err != nil {
+		return nil, err
+	}
+
+	// The response is {"status": "ok", "key": APIKeyObject}
+	keyField, ok := resp["key"]
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format, missing 'key' field containing API key details in response data: %+v", resp)
+	}
+	var key APIKey
+	if err := decodeInto(keyField, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListSchedules returns all schedules
+func (c *Client) ListSchedules() ([]map[string]any, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "list_schedules"}, &resp); err != nil {
+		return nil, err
+	}
+
+	schedulesSlice, ok := resp["schedules"].([]any)
+	if !ok {
+		return []map[string]any{}, nil
+	}
+	schedules := make([]map[string]any, 0, len(schedulesSlice))
+	for _, s := range schedulesSlice {
+		if scheduleMap, ok := s.(map[string]any); ok {
+			schedules = append(schedules, scheduleMap)
+		}
+	}
+	return schedules, nil
+}
+
+// ListUpcomingSchedules returns when enabled schedules next fire within days
+// days, soonest first, up to limit of them. Zero uses the daemon's defaults.
+func (c *Client) ListUpcomingSchedules(limit, days int) ([]ScheduleExecution, error) {
+	data := map[string]any{}
+	if limit > 0 {
+		data["limit"] = limit
+	}
+	if days > 0 {
+		data["days"] = days
+	}
+	var resp map[string]any
+	if err := c.request(map[string]any{"action": "list_upcoming_schedules", "data": data}, &resp); err != nil {
+		return nil, err
+	}
+	var upcoming []ScheduleExecution
+	if err := decodeInto(resp["upcoming"], &upcoming); err != nil {
+		return nil, err
+	}
+	return upcoming, nil
+}
+
+// CreateSchedule creates a new schedule from the given definition
+func (c *Client) CreateSchedule(schedule map[string]any) (map[string]any, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "create_schedule",
+		"data":   schedule,
+	}, &resp); err != nil {
+		return nil, err
+	}
+
+	created, ok := resp["schedule"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("server response for create_schedule missing 'schedule' field: %+v", resp)
+	}
+	return created, nil
+}
+
+// DeleteSchedule deletes a schedule by ID
+func (c *Client) DeleteSchedule(id string) error {
+	var resp map[string]any
+	return c.request(map[string]any{
+		"action": "delete_schedule",
+		"data":   map[string]any{"id": id},
+	}, &resp)
+}
+
+// ListScenes returns all scenes
+func (c *Client) ListScenes() ([]Scene, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "list_scenes"}, &resp); err != nil {
+		return nil, err
+	}
+	scenes := []Scene{}
+	if field, ok := resp["scenes"]; ok {
+		if err := decodeInto(field, &scenes); err != nil {
+			return nil, err
+		}
+	}
+	return scenes, nil
+}
+
+// CreateScene creates a new scene from the given definition
+func (c *Client) CreateScene(s Scene) (*Scene, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "create_scene",
+		"data":   map[string]any{"name": s.Name, "entries": s.Entries},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	field, ok := resp["scene"]
+	if !ok {
+		return nil, fmt.Errorf("server response for create_scene missing 'scene' field: %+v", resp)
+	}
+	var created Scene
+	if err := decodeInto(field, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// DeleteScene deletes a scene by ID
+func (c *Client) DeleteScene(id string) error {
+	var resp map[string]any
+	return c.request(map[string]any{
+		"action": "delete_scene",
+		"data":   map[string]any{"id": id},
+	}, &resp)
+}
+
+// ApplyScene starts applying a scene, by ID or name, and returns its job
+func (c *Client) ApplyScene(id string) (*Job, error) {
+	return c.job("apply_scene", id)
+}
+
+// GetSceneRun returns the job of a scene's latest run
+func (c *Client) GetSceneRun(id string) (*Job, error) {
+	return c.job("get_scene_run", id)
+}
+
+// CancelSceneRun stops applying a scene and returns its job once it has stopped
+func (c *Client) CancelSceneRun(id string) (*Job, error) {
+	return c.job("cancel_scene_run", id)
+}
+
+// ListJobs returns running and recently finished jobs, most recent first
+func (c *Client) ListJobs() ([]Job, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "list_jobs"}, &resp); err != nil {
+		return nil, err
+	}
+	jobs := []Job{}
+	if err := decodeInto(resp["jobs"], &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetJob returns a job by ID
+func (c *Client) GetJob(id string) (*Job, error) {
+	return c.job("get_job", id)
+}
+
+// CancelJob cancels a running job and returns it once it has stopped
+func (c *Client) CancelJob(id string) (*Job, error) {
+	return c.job("cancel_job", id)
+}
+
+// job sends an action taking an ID and decodes the job it returns.
+func (c *Client) job(action, id string) (*Job, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": action,
+		"data":   map[string]any{"id": id},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	field, ok := resp["job"]
+	if !ok {
+		return nil, fmt.Errorf("server response for %s missing 'job' field: %+v", action, resp)
+	}
+	var job Job
+	if err := decodeInto(field, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// DecodeProgress decodes a job's progress, as returned by either client, into
+// v, such as a *SceneProgress for a scene.apply job.
+func DecodeProgress(job *Job, v any) error {
+	return decodeInto(job.Progress, v)
+}
+
+// GetCircadian returns the state of circadian mode
+func (c *Client) GetCircadian() (*CircadianStatus, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "get_circadian"}, &resp); err != nil {
+		return nil, err
+	}
+	return circadianFromResponse(resp)
+}
+
+// SetCircadian enables or disables circadian mode
+func (c *Client) SetCircadian(enabled bool) (*CircadianStatus, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "set_circadian",
+		"data":   map[string]any{"enabled": enabled},
+	}, &resp); err != nil {
 		return nil, err
 	}
+	return circadianFromResponse(resp)
+}
 
-	// Based on server.go, the response for this action is:
-	// s.sendResponse(conn, id, map[string]any{"status": "ok", "key": updatedKey})
-	// The c.request method handles the outer envelope, so respData here should be the map sent as the third arg to sendResponse.
-	// Thus, respData should be map[string]any{"status":"ok", "key": map[string]any{...}}
-	// We need to extract the nested "key" map which contains the actual APIKey fields.
-	updatedKeyData, ok := respData["key"].(map[string]any)
+func circadianFromResponse(resp map[string]any) (*CircadianStatus, error) {
+	field, ok := resp["circadian"]
 	if !ok {
-		return nil, fmt.Errorf("unexpected response format, missing 'key' field containing API key details in response data: %+v", respData)
+		return nil, errors.New("no circadian field in response")
+	}
+	var status CircadianStatus
+	if err := decodeInto(field, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetDesiredState returns the state of desired-state mode
+func (c *Client) GetDesiredState() (*DesiredStateStatus, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "get_desired_state"}, &resp); err != nil {
+		return nil, err
+	}
+	return desiredStateFromResponse(resp)
+}
+
+// SetDesiredState enables or disables desired-state mode
+func (c *Client) SetDesiredState(enabled bool) (*DesiredStateStatus, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "set_desired_state",
+		"data":   map[string]any{"enabled": enabled},
+	}, &resp); err != nil {
+		return nil, err
 	}
-	return updatedKeyData, nil
+	return desiredStateFromResponse(resp)
+}
+
+// SetDesiredStateTarget enables or disables enforcing one desired-state
+// target, given by its key such as light:<id> or group:<name>
+func (c *Client) SetDesiredStateTarget(target string, enabled bool) (*DesiredStateStatus, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "set_desired_state_target",
+		"data":   map[string]any{"target": target, "enabled": enabled},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return desiredStateFromResponse(resp)
+}
+
+func desiredStateFromResponse(resp map[string]any) (*DesiredStateStatus, error) {
+	field, ok := resp["desired_state"]
+	if !ok {
+		return nil, errors.New("no desired_state field in response")
+	}
+	var status DesiredStateStatus
+	if err := decodeInto(field, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ExportConfig returns the daemon's groups, schedules, API key metadata and
+// light names. API key secrets are only included when includeSecrets is set.
+func (c *Client) ExportConfig(includeSecrets bool) (*ExportDocument, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "export_config",
+		"data":   map[string]any{"include_secrets": includeSecrets},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	field, ok := resp["document"]
+	if !ok {
+		return nil, errors.New("no document field in response")
+	}
+	var doc ExportDocument
+	if err := decodeInto(field, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ImportConfig merges an exported document into the daemon's state.
+func (c *Client) ImportConfig(doc *ExportDocument) (*ImportResult, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{
+		"action": "import_config",
+		"data":   map[string]any{"document": doc},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	field, ok := resp["result"]
+	if !ok {
+		return nil, errors.New("no result field in response")
+	}
+	var result ImportResult
+	if err := decodeInto(field, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetLogLevel returns the daemon's global log level.
+func (c *Client) GetLogLevel() (string, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "get_level"}, &resp); err != nil {
+		return "", err
+	}
+	level, _ := resp["level"].(string)
+	return level, nil
+}
+
+// SetLogLevel changes the daemon's global log level at runtime.
+func (c *Client) SetLogLevel(level string) error {
+	var resp map[string]any
+	return c.request(map[string]any{
+		"action": "set_level",
+		"data":   map[string]any{"level": level},
+	}, &resp)
+}
+
+// ListLogFilters returns the daemon's active log filters.
+func (c *Client) ListLogFilters() ([]map[string]any, error) {
+	var resp map[string]any
+	if err := c.request(map[string]string{"action": "list_filters"}, &resp); err != nil {
+		return nil, err
+	}
+	filtersSlice, _ := resp["filters"].([]any)
+	filters := make([]map[string]any, 0, len(filtersSlice))
+	for _, f := range filtersSlice {
+		if filterMap, ok := f.(map[string]any); ok {
+			filters = append(filters, filterMap)
+		}
+	}
+	return filters, nil
+}
+
+// AddLogFilter adds a log filter, replacing any filter with the same type and pattern.
+func (c *Client) AddLogFilter(filter map[string]any) error {
+	var resp map[string]any
+	return c.request(map[string]any{
+		"action": "add_filter",
+		"data":   filter,
+	}, &resp)
+}
+
+// RemoveLogFilter removes the log filter with the given type and pattern.
+func (c *Client) RemoveLogFilter(filterType, pattern string) error {
+	var resp map[string]any
+	return c.request(map[string]any{
+		"action": "remove_filter",
+		"data":   map[string]any{"type": filterType, "pattern": pattern},
+	}, &resp)
 }
@@ -1,8 +1,10 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net"
 	"testing"
@@ -30,9 +32,45 @@ var _ = func() bool {
 	return true
 }()
 
+// mockDialer returns a dialer for a fake daemon that records each request in
+// conn.writeBuf and answers it with the next response in conn.readBuf, tagged
+// with the request's id. Hello is answered like a daemon that predates it.
+// The daemon hangs up once it runs out of responses.
 func mockDialer(conn *mockConn) func(network, address string) (net.Conn, error) {
 	return func(network, address string) (net.Conn, error) {
-		return conn, nil
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			reader := bufio.NewReader(server)
+			responses := json.NewDecoder(conn.readBuf)
+			for {
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					return
+				}
+				var req map[string]any
+				if err := json.Unmarshal(line, &req); err != nil {
+					return
+				}
+				var resp map[string]any
+				if req["action"] == "hello" {
+					resp = map[string]any{"error": "unknown action: hello"}
+				} else {
+					conn.writeBuf.Write(line)
+					if err := responses.Decode(&resp); err != nil {
+						return
+					}
+				}
+				resp["id"] = req["id"]
+				if err := json.NewEncoder(server).Encode(resp); err != nil {
+					return
+				}
+				if !responses.More() && req["action"] != "hello" {
+					return
+				}
+			}
+		}()
+		return client, nil
 	}
 }
 
@@ -62,11 +100,41 @@ func TestClient_AllMethods(t *testing.T) {
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if key["name"] != "test-key" || key["key"] != "abcd1234" {
+		if key.Name != "test-key" || key.Key != "abcd1234" || key.ExpiresAt.IsZero() {
 			t.Fatalf("unexpected result: %v", key)
 		}
 	})
 
+	t.Run("BootstrapUIKey", func(t *testing.T) {
+		resp := map[string]any{
+			"status": "ok",
+			"key": map[string]any{
+				"name":       "ui-tray",
+				"key":        "abcd1234",
+				"created_at": time.Now().Format(time.RFC3339Nano),
+				"scopes":     []string{"read", "control"},
+			},
+			"api_url": "http://localhost:9123",
+		}
+		buf := &bytes.Buffer{}
+		_ = json.NewEncoder(buf).Encode(resp)
+		conn := &mockConn{readBuf: buf, writeBuf: &bytes.Buffer{}}
+		oldDial := dial
+		dial = mockDialer(conn)
+		defer func() { dial = oldDial }()
+
+		key, err := c.BootstrapUIKey("tray")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if key.Key.Key != "abcd1234" || len(key.Key.Scopes) != 2 || key.APIURL != "http://localhost:9123" {
+			t.Fatalf("unexpected result: %+v", key)
+		}
+		if !bytes.Contains(conn.writeBuf.Bytes(), []byte(`"client":"tray"`)) {
+			t.Fatalf("request missing client: %s", conn.writeBuf.String())
+		}
+	})
+
 	t.Run("ListAPIKeys", func(t *testing.T) {
 		resp := map[string]any{
 			"keys": []any{
@@ -102,7 +170,7 @@ func TestClient_AllMethods(t *testing.T) {
 		if len(keys) != 2 {
 			t.Fatalf("expected 2 keys, got %d", len(keys))
 		}
-		if keys[0]["name"] != "key1" || keys[1]["name"] != "key2" {
+		if keys[0].Name != "key1" || keys[1].Name != "key2" || !keys[1].Disabled {
 			t.Fatalf("unexpected keys: %v", keys)
 		}
 	})
@@ -147,14 +215,14 @@ func TestClient_AllMethods(t *testing.T) {
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if key["name"] != "test-key" || key["disabled"] != true {
+		if key.Name != "test-key" || !key.Disabled {
 			t.Fatalf("unexpected result: %v", key)
 		}
 	})
 
 	t.Run("GetLights", func(t *testing.T) {
 		resp := map[string]any{
-			"lights": map[string]any{"light1": map[string]any{"id": "light1"}},
+			"lights": map[string]any{"light1": map[string]any{"id": "light1", "brightness": 40}},
 		}
 		buf := &bytes.Buffer{}
 		_ = json.NewEncoder(buf).Encode(resp)
@@ -164,13 +232,13 @@ func TestClient_AllMethods(t *testing.T) {
 		defer func() { dial = oldDial }()
 
 		lights, err := c.GetLights()
-		if err != nil || lights["light1"].(map[string]any)["id"] != "light1" {
+		if err != nil || lights["light1"].ID != "light1" || lights["light1"].Brightness != 40 {
 			t.Fatalf("unexpected result: %v %v", lights, err)
 		}
 	})
 
 	t.Run("GetLight", func(t *testing.T) {
-		resp := map[string]any{"id": "light1"}
+		resp := map[string]any{"light": map[string]any{"id": "light1", "on": true}}
 		buf := &bytes.Buffer{}
 		_ = json.NewEncoder(buf).Encode(resp)
 		conn := &mockConn{readBuf: buf, writeBuf: &bytes.Buffer{}}
@@ -179,7 +247,7 @@ func TestClient_AllMethods(t *testing.T) {
 		defer func() { dial = oldDial }()
 
 		light, err := c.GetLight("light1")
-		if err != nil || light["id"] != "light1" {
+		if err != nil || light.ID != "light1" || !light.On {
 			t.Fatalf("unexpected result: %v %v", light, err)
 		}
 	})
@@ -215,7 +283,7 @@ func TestClient_AllMethods(t *testing.T) {
 	})
 
 	t.Run("GetGroup", func(t *testing.T) {
-		resp := map[string]any{"id": "g1"}
+		resp := map[string]any{"group": map[string]any{"id": "g1", "lights": []any{"light1"}}}
 		buf := &bytes.Buffer{}
 		_ = json.NewEncoder(buf).Encode(resp)
 		conn := &mockConn{readBuf: buf, writeBuf: &bytes.Buffer{}}
@@ -224,7 +292,7 @@ func TestClient_AllMethods(t *testing.T) {
 		defer func() { dial = oldDial }()
 
 		group, err := c.GetGroup("g1")
-		if err != nil || group["id"] != "g1" {
+		if err != nil || group.ID != "g1" || len(group.Lights) != 1 {
 			t.Fatalf("unexpected result: %v %v", group, err)
 		}
 	})
@@ -244,7 +312,7 @@ func TestClient_AllMethods(t *testing.T) {
 		defer func() { dial = oldDial }()
 
 		groups, err := c.GetGroups()
-		if err != nil || len(groups) != 2 {
+		if err != nil || len(groups) != 2 || groups[1].Name != "G2" {
 			t.Fatalf("unexpected result: %v %v", groups, err)
 		}
 	})
@@ -294,3 +362,42 @@ func TestClient_AllMethods(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_ErrorCodes(t *testing.T) {
+	withFakeDaemon(t, func(action string) map[string]any {
+		switch action {
+		case "get_light":
+			return map[string]any{"error": "light not found: resource not found", "code": "not_found"}
+		case "get_group":
+			return map[string]any{
+				"error":   "no groups found",
+				"code":    "not_found",
+				"details": map[string]any{"not_found": []any{"office"}},
+			}
+		case "get_level":
+			// Daemons that predate error codes send only a message
+			return map[string]any{"error": "something went wrong"}
+		default:
+			return map[string]any{"error": "unknown action: " + action, "code": "unknown_action"}
+		}
+	})
+	c := New(slog.New(slog.DiscardHandler), "/tmp/fake.sock")
+
+	if _, err := c.GetLight("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	_, err := c.GetGroup("office")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "not_found" || apiErr.Message != "no groups found" {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if notFound, _ := apiErr.Details["not_found"].([]any); len(notFound) != 1 || notFound[0] != "office" {
+		t.Fatalf("unexpected details: %v", apiErr.Details)
+	}
+
+	_, err = c.GetLogLevel()
+	if !errors.As(err, &apiErr) || apiErr.Code != "internal" || err.Error() != "server error: something went wrong" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// requestTimeout bounds how long a request waits for its response.
+const requestTimeout = 30 * time.Second
+
+// keepAliveInterval is how often an idle connection is pinged, so a daemon
+// that has gone away is noticed before the next request.
+const keepAliveInterval = 30 * time.Second
+
+// errNotSent is wrapped by errors for requests that failed before the daemon
+// received them, which makes them safe to retry on a new connection.
+var errNotSent = errors.New("request not sent")
+
+// socketConn is a persistent connection to the daemon. Each request is tagged
+// with an id, so several can be in flight at once; a reader goroutine hands
+// each response to the request with the matching id.
+type socketConn struct {
+	conn      net.Conn
+	logger    *slog.Logger
+	keepAlive time.Duration
+	writeMu   sync.Mutex
+
+	mu       sync.Mutex
+	pending  map[string]chan socketResult
+	nextID   uint64
+	lastUsed time.Time
+	err      error // set once the connection has failed
+	done     chan struct{}
+}
+
+type socketResult struct {
+	resp json.RawMessage
+	err  error
+}
+
+func newSocketConn(logger *slog.Logger, conn net.Conn, keepAlive time.Duration) *socketConn {
+	sc := &socketConn{
+		conn:      conn,
+		logger:    logger,
+		keepAlive: keepAlive,
+		pending:   make(map[string]chan socketResult),
+		lastUsed:  time.Now(),
+		done:      make(chan struct{}),
+	}
+	go sc.readLoop()
+	go sc.pingLoop()
+	return sc
+}
+
+// alive reports whether the connection can still be used.
+func (sc *socketConn) alive() bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.err == nil
+}
+
+// do sends a request and waits for its response.
+func (sc *socketConn) do(req any) (json.RawMessage, error) {
+	sc.mu.Lock()
+	if sc.err != nil {
+		sc.mu.Unlock()
+		return nil, fmt.Errorf("%w: %w", errNotSent, sc.err)
+	}
+	sc.nextID++
+	id := strconv.FormatUint(sc.nextID, 10)
+	ch := make(chan socketResult, 1)
+	sc.pending[id] = ch
+	sc.lastUsed = time.Now()
+	sc.mu.Unlock()
+
+	line, err := json.Marshal(withRequestID(req, id))
+	if err != nil {
+		sc.forget(id)
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	sc.writeMu.Lock()
+	_ = sc.conn.SetWriteDeadline(time.Now().Add(requestTimeout))
+	_, err = sc.conn.Write(append(line, '\n'))
+	sc.writeMu.Unlock()
+	if err != nil {
+		sc.fail(fmt.Errorf("failed to send request: %w", err))
+		return nil, fmt.Errorf("%w: %w", errNotSent, err)
+	}
+
+	timer := time.NewTimer(requestTimeout)
+	defer timer.Stop()
+	select {
+	case res := <-ch:
+		return res.resp, res.err
+	case <-timer.C:
+		// The connection is in an unknown state, so don't reuse it
+		err := errors.New("timed out waiting for response")
+		sc.fail(err)
+		return nil, err
+	}
+}
+
+// forget drops a pending request that won't get a response.
+func (sc *socketConn) forget(id string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.pending, id)
+}
+
+// readLoop hands responses to the requests waiting for them until the
+// connection fails.
+func (sc *socketConn) readLoop() {
+	dec := json.NewDecoder(sc.conn)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			sc.fail(fmt.Errorf("connection closed: %w", err))
+			return
+		}
+		var envelope struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			sc.fail(fmt.Errorf("failed to decode response: %w", err))
+			return
+		}
+
+		sc.mu.Lock()
+		ch, ok := sc.pending[envelope.ID]
+		delete(sc.pending, envelope.ID)
+		sc.mu.Unlock()
+		if !ok {
+			sc.logger.Debug("Discarding response for unknown request", "id", envelope.ID)
+			continue
+		}
+		ch <- socketResult{resp: raw}
+	}
+}
+
+// pingLoop pings the daemon when the connection has been idle, until the
+// connection fails.
+func (sc *socketConn) pingLoop() {
+	ticker := time.NewTicker(sc.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sc.done:
+			return
+		case <-ticker.C:
+			sc.mu.Lock()
+			idle := time.Since(sc.lastUsed) >= sc.keepAlive
+			sc.mu.Unlock()
+			if !idle {
+				continue
+			}
+			if _, err := sc.do(map[string]string{"action": "ping"}); err != nil {
+				sc.logger.Debug("Keep-alive ping failed", "error", err)
+			}
+		}
+	}
+}
+
+// fail closes the connection and fails every pending request with err.
+func (sc *socketConn) fail(err error) {
+	sc.mu.Lock()
+	if sc.err != nil {
+		sc.mu.Unlock()
+		return
+	}
+	sc.err = err
+	pending := sc.pending
+	sc.pending = nil
+	sc.mu.Unlock()
+
+	close(sc.done)
+	_ = sc.conn.Close()
+	for _, ch := range pending {
+		ch <- socketResult{err: err}
+	}
+}
+
+// withRequestID returns a copy of a request built by the client methods with
+// its id set.
+func withRequestID(req any, id string) any {
+	switch r := req.(type) {
+	case map[string]string:
+		r = maps.Clone(r)
+		r["id"] = id
+		return r
+	case map[string]any:
+		r = maps.Clone(r)
+		r["id"] = id
+		return r
+	}
+	return req
+}
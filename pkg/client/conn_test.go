@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readRequest reads one request from the daemon side of a pipe.
+func readRequest(t *testing.T, reader *bufio.Reader) map[string]any {
+	t.Helper()
+	line, err := reader.ReadBytes('\n')
+	require.NoError(t, err)
+	var req map[string]any
+	require.NoError(t, json.Unmarshal(line, &req))
+	return req
+}
+
+func TestSocketConn_Multiplexes(t *testing.T) {
+	client, server := net.Pipe()
+	sc := newSocketConn(slog.New(slog.DiscardHandler), client, keepAliveInterval)
+	defer sc.fail(net.ErrClosed)
+
+	var wg sync.WaitGroup
+	results := make(map[string]string)
+	var mu sync.Mutex
+	for _, action := range []string{"first", "second"} {
+		wg.Go(func() {
+			raw, err := sc.do(map[string]string{"action": action})
+			assert.NoError(t, err)
+			var resp map[string]string
+			assert.NoError(t, json.Unmarshal(raw, &resp))
+			mu.Lock()
+			results[action] = resp["echo"]
+			mu.Unlock()
+		})
+	}
+
+	// Answer both requests in the opposite order to how they arrived
+	reader := bufio.NewReader(server)
+	reqs := []map[string]any{readRequest(t, reader), readRequest(t, reader)}
+	enc := json.NewEncoder(server)
+	for _, i := range []int{1, 0} {
+		require.NoError(t, enc.Encode(map[string]any{"id": reqs[i]["id"], "echo": reqs[i]["action"]}))
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]string{"first": "first", "second": "second"}, results)
+}
+
+func TestClient_ReusesAndReconnects(t *testing.T) {
+	var mu sync.Mutex
+	var servers []net.Conn
+	oldDial := dial
+	dial = func(network, address string) (net.Conn, error) {
+		client, server := net.Pipe()
+		mu.Lock()
+		servers = append(servers, server)
+		mu.Unlock()
+		go func() {
+			reader := bufio.NewReader(server)
+			enc := json.NewEncoder(server)
+			for {
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					return
+				}
+				var req map[string]any
+				if err := json.Unmarshal(line, &req); err != nil {
+					return
+				}
+				resp := map[string]any{"id": req["id"], "level": "info"}
+				if req["action"] == "hello" {
+					resp = map[string]any{"id": req["id"], "protocol_version": 2, "actions": []string{"get_level"}}
+				}
+				if err := enc.Encode(resp); err != nil {
+					return
+				}
+			}
+		}()
+		return client, nil
+	}
+	t.Cleanup(func() { dial = oldDial })
+
+	c := New(slog.New(slog.DiscardHandler), "/tmp/fake.sock")
+	defer c.Close()
+	for range 3 {
+		_, err := c.GetLogLevel()
+		require.NoError(t, err)
+	}
+	mu.Lock()
+	assert.Len(t, servers, 1, "requests should share one connection")
+	// Simulate the daemon restarting
+	servers[0].Close()
+	mu.Unlock()
+
+	require.Eventually(t, func() bool { return !c.conn.alive() }, time.Second, time.Millisecond)
+	level, err := c.GetLogLevel()
+	require.NoError(t, err)
+	assert.Equal(t, "info", level)
+	mu.Lock()
+	assert.Len(t, servers, 2)
+	mu.Unlock()
+}
+
+func TestSocketConn_KeepAlive(t *testing.T) {
+	client, server := net.Pipe()
+	sc := newSocketConn(slog.New(slog.DiscardHandler), client, 10*time.Millisecond)
+	defer sc.fail(net.ErrClosed)
+
+	req := readRequest(t, bufio.NewReader(server))
+	assert.Equal(t, "ping", req["action"])
+
+	// A daemon that stops responding is noticed once the ping times out, so
+	// close the pipe instead of waiting for that
+	server.Close()
+	require.Eventually(t, func() bool { return !sc.alive() }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grandcat/zeroconf"
+
+	"github.com/jmylchreest/keylightd/internal/advertise"
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// ErrDaemonNotFound is returned by Discover when no running daemon was found.
+var ErrDaemonNotFound = errors.New("no running keylightd found")
+
+// DefaultBrowseTimeout is how long Discover waits for mDNS announcements.
+const DefaultBrowseTimeout = 2 * time.Second
+
+// DiscoverOptions controls where Discover looks for a daemon.
+type DiscoverOptions struct {
+	// SocketPaths are tried before the default socket locations, such as a
+	// path from a flag or the client config.
+	SocketPaths []string
+	// APIKey authenticates with a daemon announced over mDNS. Without one
+	// the network isn't searched, as the daemon couldn't be used.
+	APIKey string
+	// BrowseTimeout is how long to wait for mDNS announcements; 0 uses
+	// DefaultBrowseTimeout.
+	BrowseTimeout time.Duration
+}
+
+// Announcement is a daemon announcing its HTTP API on the local network, see
+// config.api.advertise.
+type Announcement struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url"` // base URL of the API, including any base path
+	Version string   `json:"version,omitempty"`
+	Auth    string   `json:"auth,omitempty"` // api_key or client_cert
+	Caps    []string `json:"caps,omitempty"` // optional features, such as ws, metrics and grpc
+}
+
+// Discover finds a running daemon and returns a client connected to it. It
+// tries opts.SocketPaths, then the default socket in the user's runtime
+// directory and the system service's, and finally, given an API key, daemons
+// announcing their HTTP API over mDNS. The error wraps ErrDaemonNotFound and
+// says where it looked.
+func Discover(ctx context.Context, logger *slog.Logger, opts DiscoverOptions) (ClientInterface, error) {
+	var tried []string
+	for _, path := range socketCandidates(opts.SocketPaths) {
+		conn, err := dial("unix", path)
+		if err != nil {
+			logger.Debug("No daemon on socket", "socket", path, "error", err)
+			tried = append(tried, path)
+			continue
+		}
+		_ = conn.Close()
+		logger.Debug("Found daemon on socket", "socket", path)
+		return New(logger, path), nil
+	}
+
+	if opts.APIKey == "" {
+		return nil, fmt.Errorf("%w: nothing is listening on %s", ErrDaemonNotFound, strings.Join(tried, ", "))
+	}
+	announcements, err := Browse(ctx, cmp.Or(opts.BrowseTimeout, DefaultBrowseTimeout))
+	if err != nil {
+		logger.Debug("Failed to browse for daemons", "error", err)
+	}
+	for _, a := range announcements {
+		c := NewHTTP(logger, a.URL, opts.APIKey)
+		if _, err := c.GetVersion(); err != nil {
+			logger.Debug("Announced daemon is not reachable", "name", a.Name, "url", a.URL, "error", err)
+			continue
+		}
+		logger.Debug("Found daemon over mDNS", "name", a.Name, "url", a.URL)
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: nothing is listening on %s, and no daemon is announced on the network",
+		ErrDaemonNotFound, strings.Join(tried, ", "))
+}
+
+// socketCandidates returns the socket paths Discover tries, in order and
+// without duplicates.
+func socketCandidates(paths []string) []string {
+	var candidates []string
+	for _, path := range append(slices.Clone(paths), config.RuntimeSocketPaths()...) {
+		if path != "" && !slices.Contains(candidates, path) {
+			candidates = append(candidates, path)
+		}
+	}
+	return candidates
+}
+
+// Browse lists the daemons announcing their HTTP API on the local network,
+// waiting up to timeout for announcements.
+func Browse(ctx context.Context, timeout time.Duration) ([]Announcement, error) {
+	resolver, err := zeroconf.NewResolver(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mDNS resolver: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	entries := make(chan *zeroconf.ServiceEntry)
+	if err := resolver.Browse(ctx, advertise.ServiceType, advertise.Domain, entries); err != nil {
+		return nil, fmt.Errorf("failed to browse for %s: %w", advertise.ServiceType, err)
+	}
+
+	var announcements []Announcement
+	for {
+		select {
+		case <-ctx.Done():
+			return announcements, nil
+		case entry, ok := <-entries:
+			if !ok {
+				return announcements, nil
+			}
+			if a, ok := announcementFrom(entry); ok && !slices.ContainsFunc(announcements, func(b Announcement) bool { return b.URL == a.URL }) {
+				announcements = append(announcements, a)
+			}
+		}
+	}
+}
+
+// announcementFrom describes a resolved _keylightd._tcp service. It reports
+// false for entries without an address.
+func announcementFrom(entry *zeroconf.ServiceEntry) (Announcement, bool) {
+	var host string
+	switch {
+	case len(entry.AddrIPv4) > 0:
+		host = entry.AddrIPv4[0].String()
+	case len(entry.AddrIPv6) > 0:
+		host = entry.AddrIPv6[0].String()
+	default:
+		return Announcement{}, false
+	}
+
+	txt := make(map[string]string, len(entry.Text))
+	for _, record := range entry.Text {
+		key, value, _ := strings.Cut(record, "=")
+		txt[key] = value
+	}
+	scheme := "http"
+	if txt[advertise.TXTTLS] == "1" {
+		scheme = "https"
+	}
+	a := Announcement{
+		Name:    entry.Instance,
+		URL:     scheme + "://" + net.JoinHostPort(host, strconv.Itoa(entry.Port)) + txt[advertise.TXTPath],
+		Version: txt[advertise.TXTVersion],
+		Auth:    txt[advertise.TXTAuth],
+	}
+	if caps := txt[advertise.TXTCaps]; caps != "" {
+		a.Caps = strings.Split(caps, ",")
+	}
+	return a, true
+}
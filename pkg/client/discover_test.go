@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/grandcat/zeroconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover_Socket(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	_, err := Discover(context.Background(), logger, DiscoverOptions{SocketPaths: []string{filepath.Join(runtimeDir, "missing.sock")}})
+	require.ErrorIs(t, err, ErrDaemonNotFound)
+	assert.Contains(t, err.Error(), "missing.sock")
+
+	// The default socket in the runtime directory is found
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", filepath.Join(runtimeDir, "keylightd.sock"))
+	require.NoError(t, err)
+	defer ln.Close()
+
+	c, err := Discover(context.Background(), logger, DiscoverOptions{SocketPaths: []string{filepath.Join(runtimeDir, "missing.sock")}})
+	require.NoError(t, err)
+	require.IsType(t, &Client{}, c)
+	assert.Equal(t, filepath.Join(runtimeDir, "keylightd.sock"), c.(*Client).socket)
+}
+
+func TestSocketCandidates(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	candidates := socketCandidates([]string{"", "/tmp/custom.sock", "/run/user/1000/keylightd.sock"})
+	assert.Equal(t, []string{"/tmp/custom.sock", "/run/user/1000/keylightd.sock"}, candidates[:2])
+}
+
+func TestAnnouncementFrom(t *testing.T) {
+	entry := zeroconf.NewServiceEntry("keylightd on studio", "_keylightd._tcp", "local.")
+	entry.Port = 9123
+	entry.AddrIPv4 = []net.IP{net.ParseIP("192.168.1.5")}
+	entry.Text = []string{"version=1.2.3", "path=/keylight", "tls=1", "auth=api_key", "caps=ws,metrics"}
+
+	a, ok := announcementFrom(entry)
+	require.True(t, ok)
+	assert.Equal(t, Announcement{
+		Name:    "keylightd on studio",
+		URL:     "https://192.168.1.5:9123/keylight",
+		Version: "1.2.3",
+		Auth:    "api_key",
+		Caps:    []string{"ws", "metrics"},
+	}, a)
+
+	entry.AddrIPv4 = nil
+	_, ok = announcementFrom(entry)
+	assert.False(t, ok, "entries without an address are skipped")
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// Errors returned by the daemon wrap one of these, so they can be checked
+// with errors.Is whichever API the client uses.
+var (
+	ErrNotFound          = kerrors.ErrNotFound
+	ErrInvalidInput      = kerrors.ErrInvalidInput
+	ErrDeviceUnavailable = kerrors.ErrDeviceUnavailable
+	ErrUnauthorized      = kerrors.ErrUnauthorized
+	ErrForbidden         = kerrors.ErrForbidden
+	ErrRateLimited       = kerrors.ErrRateLimited
+	ErrConflict          = kerrors.ErrConflict
+)
+
+// APIError is an error returned by the daemon: a code, a message and optional
+// details. Use errors.As to get at the code and details.
+type APIError = kerrors.Error
+
+// ErrorCode identifies the kind of an APIError.
+type ErrorCode = kerrors.Code
+
+// socketError decodes the error in a socket response. Daemons that predate
+// error codes send only a message, which is treated as an internal error.
+func socketError(resp map[string]any, message string) *APIError {
+	code, _ := resp["code"].(string)
+	details, _ := resp["details"].(map[string]any)
+	return &APIError{Code: cmp.Or(ErrorCode(code), kerrors.CodeInternal), Message: message, Details: details}
+}
+
+// httpError decodes the problem details body of an HTTP error response,
+// falling back to the status for the code and the raw body for the message.
+func httpError(status int, body []byte) *APIError {
+	var problem struct {
+		Code    ErrorCode      `json:"code"`
+		Detail  string         `json:"detail"`
+		Details map[string]any `json:"details"`
+	}
+	if err := json.Unmarshal(body, &problem); err != nil || problem.Detail == "" {
+		problem.Detail = string(body)
+		if problem.Detail == "" {
+			problem.Detail = http.StatusText(status)
+		}
+	}
+	return &APIError{
+		Code:    cmp.Or(problem.Code, kerrors.CodeForStatus(status)),
+		Message: problem.Detail,
+		Details: problem.Details,
+	}
+}
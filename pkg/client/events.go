@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// Event is a state change event streamed by the daemon.
+type Event = events.Event
+
+// EventFilter selects the events a subscription receives; the zero value
+// receives every event. Daemons that predate filtering ignore it.
+type EventFilter = events.Filter
+
+// EventSubscriber is implemented by clients that can stream daemon events.
+type EventSubscriber interface {
+	SubscribeEvents(ctx context.Context, filter EventFilter) (<-chan Event, error)
+}
+
+var (
+	_ EventSubscriber = (*Client)(nil)
+	_ EventSubscriber = (*HTTPClient)(nil)
+)
+
+// subscribeTimeout bounds how long subscribing to events or following logs
+// waits for the daemon to acknowledge it.
+const subscribeTimeout = 10 * time.Second
+
+// SubscribeEvents streams the daemon's events passing filter until ctx is
+// cancelled or the connection is lost, at which point the returned channel is
+// closed. Events are streamed on a dedicated connection, as subscribing takes
+// it over.
+func (c *Client) SubscribeEvents(ctx context.Context, filter EventFilter) (<-chan Event, error) {
+	var data any
+	if !filter.IsZero() {
+		data = filter
+	}
+	conn, dec, err := c.openStream("subscribe_events", data)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug("Subscribed to events", "socket", c.socket)
+
+	ch := make(chan Event)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	go func() {
+		defer close(ch)
+		defer stop()
+		defer conn.Close()
+		for {
+			var event Event
+			if err := dec.Decode(&event); err != nil {
+				if ctx.Err() == nil {
+					c.logger.Debug("Event stream ended", "error", err)
+				}
+				return
+			}
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// SubscribeEvents streams the daemon's events passing filter over the
+// WebSocket API until ctx is cancelled or the connection is lost, at which
+// point the returned channel is closed.
+func (c *HTTPClient) SubscribeEvents(ctx context.Context, filter EventFilter) (<-chan Event, error) {
+	wsURL := "ws" + strings.TrimPrefix(c.baseURL, "http") + "/api/v1/ws"
+	if query := eventFilterQuery(filter); len(query) > 0 {
+		wsURL += "?" + query.Encode()
+	}
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("X-API-Key", c.apiKey)
+	}
+
+	dialer := &websocket.Dialer{HandshakeTimeout: subscribeTimeout}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, header)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("WebSocket events: %w", ErrUnsupported)
+		}
+		if resp != nil {
+			return nil, fmt.Errorf("failed to connect to %s: HTTP %d", wsURL, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to connect to %s: %w", wsURL, err)
+	}
+	c.logger.Debug("Subscribed to events", "url", wsURL)
+
+	ch := make(chan Event)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	go func() {
+		defer close(ch)
+		defer stop()
+		defer conn.Close()
+		for {
+			var event Event
+			if err := conn.ReadJSON(&event); err != nil {
+				if ctx.Err() == nil {
+					c.logger.Debug("Event stream ended", "error", err)
+				}
+				return
+			}
+			// Command responses share the connection; this client sends none
+			if event.Type == "response" {
+				continue
+			}
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// openStream sends a streaming action, with data if not nil, on a dedicated
+// connection and waits for the daemon to acknowledge it. What the daemon
+// streams next is read from the returned decoder.
+func (c *Client) openStream(action string, data any) (net.Conn, *json.Decoder, error) {
+	if err := c.checkSupported(action); err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := dial("unix", c.socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to socket: %w", err)
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(subscribeTimeout)); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+	req := map[string]any{"action": action}
+	if data != nil {
+		req["data"] = data
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	dec := json.NewDecoder(conn)
+	var resp map[string]any
+	if err := dec.Decode(&resp); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if errMsg, ok := resp["error"].(string); ok {
+		conn.Close()
+		err := fmt.Errorf("server error: %s", errMsg)
+		if isUnknownAction(err) {
+			info, _ := c.Hello()
+			return nil, nil, &UnsupportedActionError{Action: action, Server: info}
+		}
+		return nil, nil, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to clear deadline: %w", err)
+	}
+	return conn, dec, nil
+}
+
+// eventFilterQuery encodes an event filter as WebSocket handshake query
+// parameters.
+func eventFilterQuery(filter EventFilter) url.Values {
+	query := url.Values{}
+	set := func(key string, list []string) {
+		if len(list) > 0 {
+			query.Set(key, strings.Join(list, ","))
+		}
+	}
+	types := make([]string, len(filter.Types))
+	for i, t := range filter.Types {
+		types[i] = string(t)
+	}
+	set("types", types)
+	set("lights", filter.Lights)
+	set("groups", filter.Groups)
+	return query
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// fakeEventDaemon answers hello and, if subscribe is supported, streams the
+// given events to subscribers.
+func fakeEventDaemon(t *testing.T, subscribe bool, stream []Event) {
+	t.Helper()
+	oldDial := dial
+	dial = func(network, address string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			reader := bufio.NewReader(server)
+			enc := json.NewEncoder(server)
+			for {
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					return
+				}
+				var req map[string]any
+				if err := json.Unmarshal(line, &req); err != nil {
+					return
+				}
+				switch req["action"] {
+				case "hello":
+					actions := []string{"hello"}
+					if subscribe {
+						actions = append(actions, "subscribe_events")
+					}
+					enc.Encode(map[string]any{"id": req["id"], "protocol_version": 2, "version": "1.0.0", "actions": actions})
+				case "subscribe_events":
+					enc.Encode(map[string]any{"status": "ok", "subscribed": true})
+					for _, event := range stream {
+						if enc.Encode(event) != nil {
+							return
+						}
+					}
+				}
+			}
+		}()
+		return client, nil
+	}
+	t.Cleanup(func() { dial = oldDial })
+}
+
+func TestClient_SubscribeEvents(t *testing.T) {
+	stream := []Event{
+		events.NewEvent(events.LightStateChanged, map[string]any{"id": "light-1", "on": true}),
+		events.NewEvent(events.GroupCreated, map[string]any{"id": "group-1"}),
+	}
+	fakeEventDaemon(t, true, stream)
+
+	c := New(slog.New(slog.DiscardHandler), "/tmp/fake.sock")
+	defer c.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.SubscribeEvents(ctx, EventFilter{})
+	require.NoError(t, err)
+	for _, want := range stream {
+		select {
+		case got := <-ch:
+			assert.Equal(t, want.Type, got.Type)
+			assert.JSONEq(t, string(want.Data), string(got.Data))
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	// Cancelling the context ends the stream
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-ch
+		return !ok
+	}, time.Second, time.Millisecond)
+}
+
+func TestClient_SubscribeEvents_Unsupported(t *testing.T) {
+	fakeEventDaemon(t, false, nil)
+
+	c := New(slog.New(slog.DiscardHandler), "/tmp/fake.sock")
+	defer c.Close()
+	_, err := c.SubscribeEvents(context.Background(), EventFilter{})
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestHTTPClient_SubscribeEvents(t *testing.T) {
+	stream := []Event{
+		events.NewEvent(events.LightStateChanged, map[string]any{"id": "light-1", "on": true}),
+		events.NewEvent(events.LightRemoved, map[string]any{"id": "light-2"}),
+	}
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/ws" || r.Header.Get("X-API-Key") != "secret" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("types") != "light.state_changed,light.removed" || r.URL.Query().Get("lights") != "light-1,light-2" {
+			http.Error(w, "unexpected filter "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteJSON(map[string]any{"type": "response", "id": "other", "status": "ok"})
+		for _, event := range stream {
+			_ = conn.WriteJSON(event)
+		}
+		_, _, _ = conn.ReadMessage() // hold the connection open until the client closes it
+	}))
+	defer server.Close()
+
+	c := NewHTTP(slog.New(slog.DiscardHandler), server.URL, "secret")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.SubscribeEvents(ctx, EventFilter{Types: []events.EventType{events.LightStateChanged, events.LightRemoved}, Lights: []string{"light-1", "light-2"}})
+	require.NoError(t, err)
+	for _, want := range stream {
+		select {
+		case got := <-ch:
+			assert.Equal(t, want.Type, got.Type, "responses are skipped")
+			assert.JSONEq(t, string(want.Data), string(got.Data))
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-ch
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	_, err = NewHTTP(slog.New(slog.DiscardHandler), server.URL, "wrong").SubscribeEvents(context.Background(), EventFilter{})
+	assert.ErrorIs(t, err, ErrUnsupported, "a daemon without the endpoint answers 404")
+}
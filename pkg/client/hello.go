@@ -0,0 +1,147 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	kerrors "github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// ProtocolVersion is the newest socket protocol version this client speaks.
+const ProtocolVersion = 2
+
+// legacyProtocolVersion is the protocol spoken by daemons that predate hello.
+const legacyProtocolVersion = 1
+
+// ErrUnsupported is wrapped by errors for actions the daemon doesn't support.
+var ErrUnsupported = errors.New("not supported by this keylightd")
+
+// ServerInfo describes the daemon the client is connected to, as reported by
+// the hello action.
+type ServerInfo struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Version         string   `json:"version"`
+	Commit          string   `json:"commit"`
+	Actions         []string `json:"actions"`
+	Features        []string `json:"features"`
+}
+
+// Legacy reports whether the daemon predates hello, in which case the actions
+// and features it supports are unknown.
+func (i *ServerInfo) Legacy() bool {
+	return i.ProtocolVersion < 2
+}
+
+// Supports reports whether the daemon supports an action. Legacy daemons are
+// assumed to support everything; unsupported actions then fail with an
+// "unknown action" error, which the client also turns into ErrUnsupported.
+func (i *ServerInfo) Supports(action string) bool {
+	return i.Legacy() || slices.Contains(i.Actions, action)
+}
+
+// HasFeature reports whether the daemon advertises an optional feature.
+func (i *ServerInfo) HasFeature(feature string) bool {
+	return slices.Contains(i.Features, feature)
+}
+
+// UnsupportedActionError is returned for requests the daemon doesn't support,
+// typically because it is older than the client.
+type UnsupportedActionError struct {
+	Action string
+	Server *ServerInfo
+}
+
+func (e *UnsupportedActionError) Error() string {
+	daemon := "the running keylightd"
+	if e.Server != nil && e.Server.Version != "" {
+		daemon = "keylightd " + e.Server.Version
+	}
+	return fmt.Sprintf("%s does not support %q; upgrade the daemon to use this command", daemon, e.Action)
+}
+
+func (e *UnsupportedActionError) Unwrap() error {
+	return ErrUnsupported
+}
+
+// Hello returns what the daemon supports, asking it on first use. Later
+// calls return the cached answer.
+func (c *Client) Hello() (*ServerInfo, error) {
+	c.helloMu.Lock()
+	defer c.helloMu.Unlock()
+	if c.server != nil {
+		return c.server, nil
+	}
+
+	var resp map[string]any
+	err := c.roundTrip(map[string]any{
+		"action": "hello",
+		"data":   map[string]any{"protocol_version": ProtocolVersion},
+	}, &resp)
+	var info ServerInfo
+	if err == nil {
+		err = decodeInto(resp, &info)
+	}
+	switch {
+	case isUnknownAction(err):
+		c.logger.Debug("Daemon predates protocol negotiation")
+		info = ServerInfo{ProtocolVersion: legacyProtocolVersion}
+	case err != nil:
+		return nil, err
+	case info.ProtocolVersion > ProtocolVersion:
+		c.logger.Debug("Daemon speaks a newer protocol", "daemon", info.ProtocolVersion, "client", ProtocolVersion)
+	}
+	c.server = &info
+	return c.server, nil
+}
+
+// checkSupported returns an UnsupportedActionError if the daemon is known not
+// to support an action. Failing to negotiate isn't an error here; the request
+// itself will report why the daemon can't be reached.
+func (c *Client) checkSupported(action string) error {
+	if action == "" || action == "hello" {
+		return nil
+	}
+	info, err := c.Hello()
+	if err != nil || info.Supports(action) {
+		return nil
+	}
+	return &UnsupportedActionError{Action: action, Server: info}
+}
+
+// isUnknownAction reports whether a request failed because the daemon doesn't
+// know the action. Older daemons send no error code, only the message.
+func isUnknownAction(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == kerrors.CodeUnknownAction {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "unknown action")
+}
+
+// requestAction returns the action of a request built by the client methods.
+func requestAction(req any) string {
+	switch r := req.(type) {
+	case map[string]string:
+		return r["action"]
+	case map[string]any:
+		action, _ := r["action"].(string)
+		return action
+	}
+	return ""
+}
+
+// decodeInto converts a decoded JSON response, or a field of one, into a
+// typed value.
+func decodeInto(resp any, v any) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+)
+
+// fakeDaemon answers each request on a pipe with respond, tagged with the
+// request's id, and records the actions it was sent.
+type fakeDaemon struct {
+	mu      sync.Mutex
+	actions []string
+	respond func(action string) map[string]any
+}
+
+func (d *fakeDaemon) dial(network, address string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		reader := bufio.NewReader(server)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var req map[string]any
+			if err := json.Unmarshal(line, &req); err != nil {
+				return
+			}
+			action, _ := req["action"].(string)
+			d.mu.Lock()
+			d.actions = append(d.actions, action)
+			d.mu.Unlock()
+			resp := d.respond(action)
+			resp["id"] = req["id"]
+			if err := json.NewEncoder(server).Encode(resp); err != nil {
+				return
+			}
+		}
+	}()
+	return client, nil
+}
+
+func withFakeDaemon(t *testing.T, respond func(action string) map[string]any) *fakeDaemon {
+	t.Helper()
+	d := &fakeDaemon{respond: respond}
+	oldDial := dial
+	dial = d.dial
+	t.Cleanup(func() { dial = oldDial })
+	return d
+}
+
+func TestClient_Negotiates(t *testing.T) {
+	d := withFakeDaemon(t, func(action string) map[string]any {
+		if action == "hello" {
+			return map[string]any{
+				"protocol_version": 2,
+				"version":          "1.2.3",
+				"actions":          []string{"hello", "ping", "get_level"},
+				"features":         []string{"transitions"},
+			}
+		}
+		return map[string]any{"level": "info"}
+	})
+	c := New(slog.New(slog.DiscardHandler), "/tmp/fake.sock")
+
+	level, err := c.GetLogLevel()
+	if err != nil || level != "info" {
+		t.Fatalf("unexpected result: %q %v", level, err)
+	}
+	info, err := c.Hello()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Version != "1.2.3" || !info.HasFeature("transitions") || info.Legacy() {
+		t.Fatalf("unexpected server info: %+v", info)
+	}
+
+	// Actions the daemon doesn't list fail without being sent
+	err = c.SetLogLevel("debug")
+	var unsupported *UnsupportedActionError
+	if !errors.As(err, &unsupported) || !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected an unsupported action error, got %v", err)
+	}
+	if unsupported.Action != "set_level" {
+		t.Fatalf("unexpected action: %q", unsupported.Action)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.actions) != 2 || d.actions[0] != "hello" || d.actions[1] != "get_level" {
+		t.Fatalf("unexpected requests: %v", d.actions)
+	}
+}
+
+func TestClient_LegacyDaemon(t *testing.T) {
+	withFakeDaemon(t, func(action string) map[string]any {
+		switch action {
+		case "get_level":
+			return map[string]any{"level": "warn"}
+		default:
+			return map[string]any{"error": "unknown action: " + action}
+		}
+	})
+	c := New(slog.New(slog.DiscardHandler), "/tmp/fake.sock")
+
+	level, err := c.GetLogLevel()
+	if err != nil || level != "warn" {
+		t.Fatalf("unexpected result: %q %v", level, err)
+	}
+	info, err := c.Hello()
+	if err != nil || !info.Legacy() || !info.Supports("set_level") {
+		t.Fatalf("unexpected server info: %+v %v", info, err)
+	}
+
+	if err := c.SetLogLevel("debug"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected an unsupported action error, got %v", err)
+	}
+}
@@ -8,8 +8,12 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/jmylchreest/keylightd/pkg/keylight"
 )
 
 // HTTPClient represents an HTTP connection to keylightd
@@ -75,7 +79,7 @@ func (c *HTTPClient) request(method, path string, body any, resp any) error {
 	// Check for error status codes
 	if httpResp.StatusCode >= 400 {
 		c.logger.Error("HTTP error response", "status", httpResp.StatusCode, "body", string(respBody))
-		return fmt.Errorf("HTTP error %d: %s", httpResp.StatusCode, string(respBody))
+		return fmt.Errorf("HTTP error %d: %w", httpResp.StatusCode, httpError(httpResp.StatusCode, respBody))
 	}
 
 	// Decode response if needed
@@ -90,6 +94,21 @@ func (c *HTTPClient) request(method, path string, body any, resp any) error {
 	return nil
 }
 
+// stateBody builds a state request body for a single property. Relative string
+// values such as "+10" or "-200K" are sent as the property's _delta field.
+func stateBody(property string, value any) (map[string]any, error) {
+	if str, ok := value.(string); ok {
+		delta, relative, err := keylight.ParseDelta(str)
+		if err != nil {
+			return nil, err
+		}
+		if relative {
+			return map[string]any{property + "_delta": delta}, nil
+		}
+	}
+	return map[string]any{property: value}, nil
+}
+
 // GetVersion returns the running daemon's version information.
 func (c *HTTPClient) GetVersion() (map[string]any, error) {
 	var resp map[string]any
@@ -99,9 +118,19 @@ func (c *HTTPClient) GetVersion() (map[string]any, error) {
 	return resp, nil
 }
 
-// GetLights returns all lights
-func (c *HTTPClient) GetLights() (map[string]any, error) {
-	var resp map[string]any
+// GetDaemonInfo returns information about the running daemon, such as its
+// uptime and discovery statistics.
+func (c *HTTPClient) GetDaemonInfo() (*DaemonInfo, error) {
+	var info DaemonInfo
+	if err := c.request("GET", "/api/v1/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetLights returns all lights, keyed by ID
+func (c *HTTPClient) GetLights() (map[string]*Light, error) {
+	var resp map[string]*Light
 	err := c.request("GET", "/api/v1/lights", nil, &resp)
 	if err != nil {
 		return nil, err
@@ -110,23 +139,145 @@ func (c *HTTPClient) GetLights() (map[string]any, error) {
 }
 
 // GetLight returns a specific light
-func (c *HTTPClient) GetLight(id string) (map[string]any, error) {
-	var resp map[string]any
+func (c *HTTPClient) GetLight(id string) (*Light, error) {
+	var resp Light
 	err := c.request("GET", "/api/v1/lights/"+id, nil, &resp)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	return &resp, nil
 }
 
 // SetLightState sets a property on a light
-func (c *HTTPClient) SetLightState(id string, property string, value any) error {
-	body := map[string]any{
-		property: value,
+func (c *HTTPClient) SetLightState(id string, property string, value any, opts ...StateOption) error {
+	body, err := stateBody(property, value)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(body)
 	}
 	return c.request("POST", "/api/v1/lights/"+id+"/state", body, nil)
 }
 
+// SetLightsState applies state updates to several lights in one request.
+// A 207 partial response is returned as an error listing the failed lights.
+func (c *HTTPClient) SetLightsState(updates []LightStateUpdate) error {
+	body := map[string]any{
+		"lights": updates,
+	}
+	var resp struct {
+		Status string   `json:"status"`
+		Errors []string `json:"errors"`
+	}
+	if err := c.request("POST", "/api/v1/lights/state", body, &resp); err != nil {
+		return err
+	}
+	if resp.Status == "partial" && len(resp.Errors) > 0 {
+		return fmt.Errorf("server error (partial): %v", resp.Errors)
+	}
+	return nil
+}
+
+// ToggleLight inverts a light's power state and returns the new state.
+func (c *HTTPClient) ToggleLight(id string) (bool, error) {
+	var resp struct {
+		On bool `json:"on"`
+	}
+	if err := c.request("POST", "/api/v1/lights/"+id+"/toggle", nil, &resp); err != nil {
+		return false, err
+	}
+	return resp.On, nil
+}
+
+// SetLightName sets a light's display name override and returns the light's
+// resulting name. An empty name restores the name reported by the device.
+func (c *HTTPClient) SetLightName(id, name string) (string, error) {
+	body := map[string]any{
+		"name": name,
+	}
+	var resp struct {
+		Name string `json:"name"`
+	}
+	if err := c.request("PUT", "/api/v1/lights/"+id+"/name", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Name, nil
+}
+
+// SetLightTags replaces a light's tags and returns the tags it now has. Nil
+// or empty tags remove them.
+func (c *HTTPClient) SetLightTags(id string, tags map[string]string) (map[string]string, error) {
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	var resp Light
+	if err := c.request("PUT", "/api/v1/lights/"+id+"/tags", map[string]any{"tags": tags}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// SetDeviceName writes a new display name to the light itself and returns
+// the name the light is now shown with.
+func (c *HTTPClient) SetDeviceName(id, name string) (string, error) {
+	body := map[string]any{
+		"name": name,
+	}
+	var resp struct {
+		Name string `json:"name"`
+	}
+	if err := c.request("PUT", "/api/v1/lights/"+id+"/device-name", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Name, nil
+}
+
+// RawRequest passes a request straight through to a light's own API and
+// returns the device's response. body may be nil.
+func (c *HTTPClient) RawRequest(id, method, path string, body json.RawMessage) (*RawResponse, error) {
+	req := map[string]any{
+		"method": method,
+		"path":   path,
+	}
+	if len(body) > 0 {
+		req["body"] = body
+	}
+	var resp RawResponse
+	if err := c.request("POST", "/api/v1/lights/"+id+"/raw", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetLightSettings returns the power-on settings stored on a light.
+func (c *HTTPClient) GetLightSettings(id string) (*LightSettings, error) {
+	var resp LightSettings
+	if err := c.request("GET", "/api/v1/lights/"+id+"/settings", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetLightSettings changes the power-on settings stored on a light and
+// returns the settings as written. Nil fields are left as they are.
+func (c *HTTPClient) SetLightSettings(id string, update LightSettingsUpdate) (*LightSettings, error) {
+	var resp LightSettings
+	if err := c.request("PUT", "/api/v1/lights/"+id+"/settings", update, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetLightStats returns a light's usage statistics.
+func (c *HTTPClient) GetLightStats(id string) (*LightStats, error) {
+	var stats LightStats
+	if err := c.request("GET", "/api/v1/lights/"+id+"/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // CreateGroup creates a new group
 func (c *HTTPClient) CreateGroup(name string) error {
 	body := map[string]any{
@@ -136,37 +287,68 @@ func (c *HTTPClient) CreateGroup(name string) error {
 }
 
 // GetGroup returns a specific group
-func (c *HTTPClient) GetGroup(id string) (map[string]any, error) {
-	var resp map[string]any
+func (c *HTTPClient) GetGroup(id string) (*Group, error) {
+	var resp Group
 	err := c.request("GET", "/api/v1/groups/"+id, nil, &resp)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	return &resp, nil
+}
+
+// UpdateGroup renames a group or changes its description, icon or color, and
+// returns the group as updated.
+func (c *HTTPClient) UpdateGroup(groupID string, update GroupUpdate) (*Group, error) {
+	var resp Group
+	if err := c.request("PATCH", "/api/v1/groups/"+groupID, update, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
 // GetGroups returns all groups
-func (c *HTTPClient) GetGroups() ([]map[string]any, error) {
-	var resp []map[string]any
+func (c *HTTPClient) GetGroups() ([]*Group, error) {
+	var resp []*Group
 	err := c.request("GET", "/api/v1/groups", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
 	// Ensure we return an empty slice instead of nil
 	if resp == nil {
-		return []map[string]any{}, nil
+		return []*Group{}, nil
 	}
 	return resp, nil
 }
 
 // SetGroupState sets a property on all lights in a group
-func (c *HTTPClient) SetGroupState(id string, property string, value any) error {
-	body := map[string]any{
-		property: value,
+func (c *HTTPClient) SetGroupState(id string, property string, value any, opts ...StateOption) error {
+	body, err := stateBody(property, value)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(body)
 	}
 	return c.request("PUT", "/api/v1/groups/"+id+"/state", body, nil)
 }
 
+// ToggleGroup toggles one or more groups and returns the power state applied to
+// each, keyed by group ID. A 207 partial response is returned as an error.
+func (c *HTTPClient) ToggleGroup(id string) (map[string]bool, error) {
+	var resp struct {
+		Status string          `json:"status"`
+		Groups map[string]bool `json:"groups"`
+		Errors []string        `json:"errors"`
+	}
+	if err := c.request("POST", "/api/v1/groups/"+id+"/toggle", nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status == "partial" && len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("server error (partial): %v", resp.Errors)
+	}
+	return resp.Groups, nil
+}
+
 // DeleteGroup deletes a group
 func (c *HTTPClient) DeleteGroup(id string) error {
 	return c.request("DELETE", "/api/v1/groups/"+id, nil, nil)
@@ -180,30 +362,98 @@ func (c *HTTPClient) SetGroupLights(groupID string, lightIDs []string) error {
 	return c.request("PUT", "/api/v1/groups/"+groupID+"/lights", body, nil)
 }
 
+// SetGroupDefaults sets the default brightness and temperature of a group.
+// Passing nil for both clears the defaults.
+func (c *HTTPClient) SetGroupDefaults(groupID string, brightness, temperature *int, applyOnJoin bool) error {
+	body := map[string]any{
+		"apply_on_join": applyOnJoin,
+	}
+	if brightness != nil {
+		body["brightness"] = *brightness
+	}
+	if temperature != nil {
+		body["temperature"] = *temperature
+	}
+	return c.request("PUT", "/api/v1/groups/"+groupID+"/defaults", body, nil)
+}
+
+// GetGroupState returns the aggregate state of a group's lights.
+func (c *HTTPClient) GetGroupState(groupID string) (*GroupState, error) {
+	var state GroupState
+	if err := c.request("GET", "/api/v1/groups/"+groupID+"/state", nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetGroupStats returns the combined usage statistics of a group's lights.
+func (c *HTTPClient) GetGroupStats(groupID string) (*GroupStats, error) {
+	var stats GroupStats
+	if err := c.request("GET", "/api/v1/groups/"+groupID+"/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// DiscoverNow runs a discovery pass straight away and returns the lights it
+// found that weren't known before
+func (c *HTTPClient) DiscoverNow() (*DiscoveryResult, error) {
+	var result DiscoveryResult
+	if err := c.request("POST", "/api/v1/discovery/scan", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPendingDevices returns the devices discovery found that aren't
+// supported lights
+func (c *HTTPClient) ListPendingDevices() ([]PendingDevice, error) {
+	var resp struct {
+		Devices []PendingDevice `json:"devices"`
+	}
+	if err := c.request("GET", "/api/v1/discovery/pending", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Devices, nil
+}
+
+// AdoptDevice adds a pending device as a light and returns it
+func (c *HTTPClient) AdoptDevice(id string) (*Light, error) {
+	var light Light
+	if err := c.request("POST", "/api/v1/discovery/pending/"+url.PathEscape(id)+"/adopt", nil, &light); err != nil {
+		return nil, err
+	}
+	return &light, nil
+}
+
 // AddAPIKey creates a new API key
-func (c *HTTPClient) AddAPIKey(name string, expiresInSeconds float64) (map[string]any, error) {
+func (c *HTTPClient) AddAPIKey(name string, expiresInSeconds float64) (*APIKey, error) {
 	body := map[string]any{
 		"name": name,
 	}
 	if expiresInSeconds > 0 {
 		body["expires_in"] = fmt.Sprintf("%.0fs", expiresInSeconds)
 	}
-	var resp map[string]any
+	var resp httpAPIKey
 	err := c.request("POST", "/api/v1/apikeys", body, &resp)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	return resp.apiKey(), nil
 }
 
 // ListAPIKeys returns all API keys
-func (c *HTTPClient) ListAPIKeys() ([]map[string]any, error) {
-	var resp []map[string]any
+func (c *HTTPClient) ListAPIKeys() ([]APIKey, error) {
+	var resp []httpAPIKey
 	err := c.request("GET", "/api/v1/apikeys", nil, &resp)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	keys := make([]APIKey, 0, len(resp))
+	for _, k := range resp {
+		keys = append(keys, *k.apiKey())
+	}
+	return keys, nil
 }
 
 // DeleteAPIKey deletes an API key
@@ -212,14 +462,253 @@ func (c *HTTPClient) DeleteAPIKey(key string) error {
 }
 
 // SetAPIKeyDisabledStatus enables or disables an API key
-func (c *HTTPClient) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (map[string]any, error) {
+func (c *HTTPClient) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (*APIKey, error) {
 	body := map[string]any{
 		"disabled": disabled,
 	}
-	var resp map[string]any
+	var resp httpAPIKey
 	err := c.request("PUT", "/api/v1/apikeys/"+keyOrName+"/disabled", body, &resp)
 	if err != nil {
 		return nil, err
 	}
+	return resp.apiKey(), nil
+}
+
+// httpAPIKey is an API key as returned by the HTTP API, which identifies keys
+// by ID and only includes the key itself when it is created.
+type httpAPIKey struct {
+	APIKey
+	ID string `json:"id"`
+}
+
+func (k *httpAPIKey) apiKey() *APIKey {
+	key := k.APIKey
+	if key.Key == "" {
+		key.Key = k.ID
+	}
+	return &key
+}
+
+// ListSchedules returns all schedules
+func (c *HTTPClient) ListSchedules() ([]map[string]any, error) {
+	var resp []map[string]any
+	if err := c.request("GET", "/api/v1/schedules", nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return []map[string]any{}, nil
+	}
+	return resp, nil
+}
+
+// ListUpcomingSchedules returns when enabled schedules next fire within days
+// days, soonest first, up to limit of them. Zero uses the daemon's defaults.
+func (c *HTTPClient) ListUpcomingSchedules(limit, days int) ([]ScheduleExecution, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if days > 0 {
+		query.Set("days", strconv.Itoa(days))
+	}
+	path := "/api/v1/schedules/upcoming"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var resp []ScheduleExecution
+	if err := c.request("GET", path, nil, &resp); err != nil {
+		return nil, err
+	}
 	return resp, nil
 }
+
+// CreateSchedule creates a new schedule from the given definition
+func (c *HTTPClient) CreateSchedule(schedule map[string]any) (map[string]any, error) {
+	var resp map[string]any
+	if err := c.request("POST", "/api/v1/schedules", schedule, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteSchedule deletes a schedule by ID
+func (c *HTTPClient) DeleteSchedule(id string) error {
+	return c.request("DELETE", "/api/v1/schedules/"+id, nil, nil)
+}
+
+// ListScenes returns all scenes
+func (c *HTTPClient) ListScenes() ([]Scene, error) {
+	scenes := []Scene{}
+	if err := c.request("GET", "/api/v1/scenes", nil, &scenes); err != nil {
+		return nil, err
+	}
+	return scenes, nil
+}
+
+// CreateScene creates a new scene from the given definition
+func (c *HTTPClient) CreateScene(s Scene) (*Scene, error) {
+	var created Scene
+	body := map[string]any{"name": s.Name, "entries": s.Entries}
+	if err := c.request("POST", "/api/v1/scenes", body, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// DeleteScene deletes a scene by ID
+func (c *HTTPClient) DeleteScene(id string) error {
+	return c.request("DELETE", "/api/v1/scenes/"+url.PathEscape(id), nil, nil)
+}
+
+// ApplyScene starts applying a scene, by ID or name, and returns its job
+func (c *HTTPClient) ApplyScene(id string) (*Job, error) {
+	return c.job("POST", "/api/v1/scenes/"+url.PathEscape(id)+"/apply")
+}
+
+// GetSceneRun returns the job of a scene's latest run
+func (c *HTTPClient) GetSceneRun(id string) (*Job, error) {
+	return c.job("GET", "/api/v1/scenes/"+url.PathEscape(id)+"/run")
+}
+
+// CancelSceneRun stops applying a scene and returns its job once it has stopped
+func (c *HTTPClient) CancelSceneRun(id string) (*Job, error) {
+	return c.job("DELETE", "/api/v1/scenes/"+url.PathEscape(id)+"/run")
+}
+
+// ListJobs returns running and recently finished jobs, most recent first
+func (c *HTTPClient) ListJobs() ([]Job, error) {
+	jobs := []Job{}
+	if err := c.request("GET", "/api/v1/jobs", nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetJob returns a job by ID
+func (c *HTTPClient) GetJob(id string) (*Job, error) {
+	return c.job("GET", "/api/v1/jobs/"+url.PathEscape(id))
+}
+
+// CancelJob cancels a running job and returns it once it has stopped
+func (c *HTTPClient) CancelJob(id string) (*Job, error) {
+	return c.job("DELETE", "/api/v1/jobs/"+url.PathEscape(id))
+}
+
+// job sends a request that returns a job and decodes it.
+func (c *HTTPClient) job(method, path string) (*Job, error) {
+	var job Job
+	if err := c.request(method, path, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetCircadian returns the state of circadian mode
+func (c *HTTPClient) GetCircadian() (*CircadianStatus, error) {
+	var status CircadianStatus
+	if err := c.request("GET", "/api/v1/circadian", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetCircadian enables or disables circadian mode
+func (c *HTTPClient) SetCircadian(enabled bool) (*CircadianStatus, error) {
+	var status CircadianStatus
+	if err := c.request("PUT", "/api/v1/circadian", map[string]any{"enabled": enabled}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetDesiredState returns the state of desired-state mode
+func (c *HTTPClient) GetDesiredState() (*DesiredStateStatus, error) {
+	var status DesiredStateStatus
+	if err := c.request("GET", "/api/v1/desired-state", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetDesiredState enables or disables desired-state mode
+func (c *HTTPClient) SetDesiredState(enabled bool) (*DesiredStateStatus, error) {
+	var status DesiredStateStatus
+	if err := c.request("PUT", "/api/v1/desired-state", map[string]any{"enabled": enabled}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetDesiredStateTarget enables or disables enforcing one desired-state
+// target, given by its key such as light:<id> or group:<name>
+func (c *HTTPClient) SetDesiredStateTarget(target string, enabled bool) (*DesiredStateStatus, error) {
+	var status DesiredStateStatus
+	if err := c.request("PUT", "/api/v1/desired-state/targets/"+url.PathEscape(target), map[string]any{"enabled": enabled}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ExportConfig returns the daemon's groups, schedules, API key metadata and
+// light names. API key secrets are only included when includeSecrets is set.
+func (c *HTTPClient) ExportConfig(includeSecrets bool) (*ExportDocument, error) {
+	path := "/api/v1/config/export"
+	if includeSecrets {
+		path += "?include_secrets=true"
+	}
+	var doc ExportDocument
+	if err := c.request("GET", path, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ImportConfig merges an exported document into the daemon's state.
+func (c *HTTPClient) ImportConfig(doc *ExportDocument) (*ImportResult, error) {
+	var result ImportResult
+	if err := c.request("POST", "/api/v1/config/import", doc, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetLogLevel returns the daemon's global log level
+func (c *HTTPClient) GetLogLevel() (string, error) {
+	var resp struct {
+		Level string `json:"level"`
+	}
+	if err := c.request("GET", "/api/v1/logging/level", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Level, nil
+}
+
+// SetLogLevel changes the daemon's global log level at runtime
+func (c *HTTPClient) SetLogLevel(level string) error {
+	return c.request("PUT", "/api/v1/logging/level", map[string]any{"level": level}, nil)
+}
+
+// ListLogFilters returns the daemon's active log filters
+func (c *HTTPClient) ListLogFilters() ([]map[string]any, error) {
+	var resp struct {
+		Filters []map[string]any `json:"filters"`
+	}
+	if err := c.request("GET", "/api/v1/logging/filters", nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Filters == nil {
+		return []map[string]any{}, nil
+	}
+	return resp.Filters, nil
+}
+
+// AddLogFilter adds a log filter, replacing any filter with the same type and pattern
+func (c *HTTPClient) AddLogFilter(filter map[string]any) error {
+	return c.request("POST", "/api/v1/logging/filters", filter, nil)
+}
+
+// RemoveLogFilter removes the log filter with the given type and pattern
+func (c *HTTPClient) RemoveLogFilter(filterType, pattern string) error {
+	query := url.Values{"type": {filterType}, "pattern": {pattern}}
+	return c.request("DELETE", "/api/v1/logging/filters?"+query.Encode(), nil, nil)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -51,7 +52,8 @@ func TestHTTPClient_GetLights(t *testing.T) {
 
 	lights, err := client.GetLights()
 	require.NoError(t, err)
-	assert.Contains(t, lights, "light-1")
+	require.Contains(t, lights, "light-1")
+	assert.Equal(t, 50, lights["light-1"].Brightness)
 }
 
 func TestHTTPClient_GetLights_Error(t *testing.T) {
@@ -75,7 +77,53 @@ func TestHTTPClient_GetLight(t *testing.T) {
 
 	light, err := client.GetLight("light-1")
 	require.NoError(t, err)
-	assert.Equal(t, "light-1", light["id"])
+	assert.Equal(t, "light-1", light.ID)
+	assert.Equal(t, "Light 1", light.Name)
+}
+
+func TestHTTPClient_PendingDevices(t *testing.T) {
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"GET /api/v1/discovery/pending": jsonHandler(200, map[string]any{
+			"devices": []map[string]any{{"id": "Key Light Neo", "productname": "Elgato Key Light Neo", "ip": "192.168.1.50", "port": 9123}},
+		}),
+		"POST /api/v1/discovery/pending/Key%20Light%20Neo/adopt": jsonHandler(200, map[string]any{
+			"id": "Key Light Neo", "productname": "Elgato Key Light Neo",
+		}),
+	})
+
+	devices, err := client.ListPendingDevices()
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "Elgato Key Light Neo", devices[0].ProductName)
+	assert.Equal(t, 9123, devices[0].Port)
+
+	light, err := client.AdoptDevice("Key Light Neo")
+	require.NoError(t, err)
+	assert.Equal(t, "Key Light Neo", light.ID)
+}
+
+func TestHTTPClient_ListUpcomingSchedules(t *testing.T) {
+	var query string
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"GET /api/v1/schedules/upcoming": func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.RawQuery
+			jsonHandler(200, []map[string]any{{
+				"schedule_id": "schedule-1",
+				"name":        "studio evening",
+				"at":          "2026-03-02T18:00:00-05:00",
+				"target":      map[string]any{"type": "group", "id": "Studio"},
+				"scene":       "Evening warm",
+			}})(w, r)
+		},
+	})
+
+	upcoming, err := client.ListUpcomingSchedules(5, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "limit=5", query)
+	require.Len(t, upcoming, 1)
+	assert.Equal(t, "Evening warm", upcoming[0].Scene)
+	assert.Equal(t, "Studio", upcoming[0].Target["id"])
+	assert.Equal(t, 23, upcoming[0].At.UTC().Hour())
 }
 
 func TestHTTPClient_GetLight_NotFound(t *testing.T) {
@@ -86,6 +134,33 @@ func TestHTTPClient_GetLight_NotFound(t *testing.T) {
 	_, err := client.GetLight("no-such")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "404")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestHTTPClient_ErrorCodes(t *testing.T) {
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"GET /api/v1/lights/light-1": jsonHandler(503, map[string]any{
+			"status":  503,
+			"detail":  "Error getting light: light-1 did not respond",
+			"code":    "device_unavailable",
+			"details": map[string]any{"light": "light-1"},
+		}),
+	})
+
+	_, err := client.GetLight("light-1")
+	require.ErrorIs(t, err, ErrDeviceUnavailable)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, ErrorCode("device_unavailable"), apiErr.Code)
+	assert.Equal(t, "Error getting light: light-1 did not respond", apiErr.Message)
+	assert.Equal(t, "light-1", apiErr.Details["light"])
+
+	// Without a code, the status decides
+	_, client = newTestServer(t, map[string]http.HandlerFunc{
+		"GET /api/v1/lights": jsonHandler(401, map[string]any{"error": "unauthorized"}),
+	})
+	_, err = client.GetLights()
+	assert.ErrorIs(t, err, ErrUnauthorized)
 }
 
 // === SetLightState ===
@@ -105,6 +180,17 @@ func TestHTTPClient_SetLightState(t *testing.T) {
 	err := client.SetLightState("light-1", "brightness", 80)
 	require.NoError(t, err)
 	assert.Equal(t, float64(80), receivedBody["brightness"])
+	assert.NotContains(t, receivedBody, "transition_ms")
+
+	err = client.SetLightState("light-1", "brightness", 40, WithTransition(1500*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, float64(40), receivedBody["brightness"])
+	assert.Equal(t, float64(1500), receivedBody["transition_ms"])
+
+	err = client.SetLightState("light-1", "temperature", 200, WithMireds())
+	require.NoError(t, err)
+	assert.Equal(t, float64(200), receivedBody["temperature"])
+	assert.Equal(t, "mired", receivedBody["units"])
 }
 
 // === GetGroups ===
@@ -119,7 +205,8 @@ func TestHTTPClient_GetGroups(t *testing.T) {
 	groups, err := client.GetGroups()
 	require.NoError(t, err)
 	assert.Len(t, groups, 1)
-	assert.Equal(t, "Office", groups[0]["name"])
+	assert.Equal(t, "Office", groups[0].Name)
+	assert.Equal(t, []string{"l1"}, groups[0].Lights)
 }
 
 func TestHTTPClient_GetGroups_Empty(t *testing.T) {
@@ -163,7 +250,7 @@ func TestHTTPClient_GetGroup(t *testing.T) {
 
 	group, err := client.GetGroup("g1")
 	require.NoError(t, err)
-	assert.Equal(t, "Office", group["name"])
+	assert.Equal(t, "Office", group.Name)
 }
 
 // === DeleteGroup ===
@@ -227,6 +314,101 @@ func TestHTTPClient_SetGroupLights(t *testing.T) {
 	assert.Len(t, lightIDs, 2)
 }
 
+func TestHTTPClient_SetGroupDefaults(t *testing.T) {
+	var receivedBody map[string]any
+
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"PUT /api/v1/groups/g1/defaults": func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &receivedBody)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		},
+	})
+
+	brightness := 40
+	err := client.SetGroupDefaults("g1", &brightness, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, float64(40), receivedBody["brightness"])
+	assert.NotContains(t, receivedBody, "temperature")
+	assert.Equal(t, true, receivedBody["apply_on_join"])
+}
+
+func TestHTTPClient_SetLightState_Relative(t *testing.T) {
+	var receivedBody map[string]any
+
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"POST /api/v1/lights/light1/state": func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			receivedBody = nil
+			json.Unmarshal(body, &receivedBody)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		},
+	})
+
+	require.NoError(t, client.SetLightState("light1", "brightness", "+10"))
+	assert.Equal(t, map[string]any{"brightness_delta": float64(10)}, receivedBody)
+
+	require.NoError(t, client.SetLightState("light1", "temperature", "-200K"))
+	assert.Equal(t, map[string]any{"temperature_delta": float64(-200)}, receivedBody)
+
+	assert.Error(t, client.SetLightState("light1", "brightness", "+lots"))
+}
+
+func TestHTTPClient_Toggle(t *testing.T) {
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"POST /api/v1/lights/light1/toggle": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok", "on": true})
+		},
+		"POST /api/v1/groups/studio/toggle": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok", "groups": map[string]bool{"group-1": false}})
+		},
+	})
+
+	on, err := client.ToggleLight("light1")
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	states, err := client.ToggleGroup("studio")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"group-1": false}, states)
+}
+
+func TestHTTPClient_SetLightsState(t *testing.T) {
+	var receivedBody map[string]any
+	partial := false
+
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"POST /api/v1/lights/state": func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &receivedBody)
+			w.Header().Set("Content-Type", "application/json")
+			if partial {
+				w.WriteHeader(http.StatusMultiStatus)
+				json.NewEncoder(w).Encode(map[string]any{"status": "partial", "errors": []string{"light b: timeout"}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		},
+	})
+
+	on, brightness := true, 40
+	updates := []LightStateUpdate{{ID: "a", On: &on, Brightness: &brightness}, {ID: "b", On: &on}}
+	require.NoError(t, client.SetLightsState(updates))
+	lights, ok := receivedBody["lights"].([]any)
+	require.True(t, ok)
+	require.Len(t, lights, 2)
+	assert.Equal(t, map[string]any{"id": "a", "on": true, "brightness": float64(40)}, lights[0])
+
+	partial = true
+	err := client.SetLightsState(updates)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "light b: timeout")
+}
+
 // === API Key operations ===
 
 func TestHTTPClient_AddAPIKey(t *testing.T) {
@@ -238,7 +420,7 @@ func TestHTTPClient_AddAPIKey(t *testing.T) {
 
 	resp, err := client.AddAPIKey("test-key", 3600)
 	require.NoError(t, err)
-	assert.Equal(t, "abc123", resp["key"])
+	assert.Equal(t, "abc123", resp.Key)
 }
 
 func TestHTTPClient_AddAPIKey_NoExpiration(t *testing.T) {
@@ -264,13 +446,16 @@ func TestHTTPClient_AddAPIKey_NoExpiration(t *testing.T) {
 func TestHTTPClient_ListAPIKeys(t *testing.T) {
 	_, client := newTestServer(t, map[string]http.HandlerFunc{
 		"GET /api/v1/apikeys": jsonHandler(200, []map[string]any{
-			{"name": "key-a"}, {"name": "key-b"},
+			{"id": "abc", "name": "key-a"}, {"id": "def", "name": "key-b", "disabled": true},
 		}),
 	})
 
 	keys, err := client.ListAPIKeys()
 	require.NoError(t, err)
-	assert.Len(t, keys, 2)
+	require.Len(t, keys, 2)
+	// Keys are identified by id over HTTP
+	assert.Equal(t, "abc", keys[0].Key)
+	assert.True(t, keys[1].Disabled)
 }
 
 func TestHTTPClient_DeleteAPIKey(t *testing.T) {
@@ -293,7 +478,7 @@ func TestHTTPClient_SetAPIKeyDisabledStatus(t *testing.T) {
 
 	resp, err := client.SetAPIKeyDisabledStatus("test-key", true)
 	require.NoError(t, err)
-	assert.Equal(t, true, resp["disabled"])
+	assert.True(t, resp.Disabled)
 }
 
 // === API key header test ===
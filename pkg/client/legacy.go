@@ -0,0 +1,144 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LegacyClient wraps a client with the map-based light, group and API key
+// methods it had before responses were typed, for callers that haven't moved
+// to Light, Group and APIKey yet. Maps are built from the JSON encoding of
+// the typed values, except that timestamps are time.Time as before.
+type LegacyClient struct {
+	ClientInterface
+}
+
+// NewLegacy returns a LegacyClient wrapping c.
+func NewLegacy(c ClientInterface) *LegacyClient {
+	return &LegacyClient{ClientInterface: c}
+}
+
+// GetLights returns all lights as maps, keyed by ID.
+func (l *LegacyClient) GetLights() (map[string]any, error) {
+	lights, err := l.ClientInterface.GetLights()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]any, len(lights))
+	for id, light := range lights {
+		m, err := lightToMap(light)
+		if err != nil {
+			return nil, err
+		}
+		result[id] = m
+	}
+	return result, nil
+}
+
+// GetLight returns a light as a map.
+func (l *LegacyClient) GetLight(id string) (map[string]any, error) {
+	light, err := l.ClientInterface.GetLight(id)
+	if err != nil {
+		return nil, err
+	}
+	return lightToMap(light)
+}
+
+// GetGroup returns a group as a map.
+func (l *LegacyClient) GetGroup(id string) (map[string]any, error) {
+	grp, err := l.ClientInterface.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(grp)
+}
+
+// GetGroups returns all groups as maps.
+func (l *LegacyClient) GetGroups() ([]map[string]any, error) {
+	groups, err := l.ClientInterface.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]any, 0, len(groups))
+	for _, grp := range groups {
+		m, err := toMap(grp)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// AddAPIKey creates a new API key and returns it as a map.
+func (l *LegacyClient) AddAPIKey(name string, expiresInSeconds float64) (map[string]any, error) {
+	key, err := l.ClientInterface.AddAPIKey(name, expiresInSeconds)
+	if err != nil {
+		return nil, err
+	}
+	return apiKeyToMap(key)
+}
+
+// ListAPIKeys returns all API keys as maps.
+func (l *LegacyClient) ListAPIKeys() ([]map[string]any, error) {
+	keys, err := l.ClientInterface.ListAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]any, 0, len(keys))
+	for i := range keys {
+		m, err := apiKeyToMap(&keys[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// SetAPIKeyDisabledStatus enables or disables an API key and returns it as a map.
+func (l *LegacyClient) SetAPIKeyDisabledStatus(keyOrName string, disabled bool) (map[string]any, error) {
+	key, err := l.ClientInterface.SetAPIKeyDisabledStatus(keyOrName, disabled)
+	if err != nil {
+		return nil, err
+	}
+	return apiKeyToMap(key)
+}
+
+func lightToMap(light *Light) (map[string]any, error) {
+	m, err := toMap(light)
+	if err != nil {
+		return nil, err
+	}
+	m["lastseen"] = light.LastSeen
+	return m, nil
+}
+
+func apiKeyToMap(key *APIKey) (map[string]any, error) {
+	m, err := toMap(key)
+	if err != nil {
+		return nil, err
+	}
+	for field, t := range map[string]time.Time{
+		"created_at":   key.CreatedAt,
+		"expires_at":   key.ExpiresAt,
+		"last_used_at": key.LastUsedAt,
+	} {
+		m[field] = t
+	}
+	return m, nil
+}
+
+// toMap converts a typed value to the map its JSON encoding decodes to.
+func toMap(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T: %w", v, err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", v, err)
+	}
+	return m, nil
+}
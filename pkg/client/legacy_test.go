@@ -0,0 +1,49 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyClient(t *testing.T) {
+	lastSeen := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	_, c := newTestServer(t, map[string]http.HandlerFunc{
+		"GET /api/v1/lights": jsonHandler(200, map[string]any{
+			"light-1": map[string]any{"id": "light-1", "brightness": 50, "lastseen": lastSeen},
+		}),
+		"GET /api/v1/groups": jsonHandler(200, []map[string]any{
+			{"id": "g1", "name": "Office", "lights": []string{"light-1"}},
+		}),
+		"GET /api/v1/apikeys": jsonHandler(200, []map[string]any{
+			{"id": "abc", "name": "key-a", "created_at": lastSeen},
+		}),
+	})
+	legacy := NewLegacy(c)
+
+	lights, err := legacy.GetLights()
+	require.NoError(t, err)
+	light, ok := lights["light-1"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(50), light["brightness"])
+	assert.Equal(t, lastSeen, light["lastseen"])
+
+	groups, err := legacy.GetGroups()
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "Office", groups[0]["name"])
+	assert.Equal(t, []any{"light-1"}, groups[0]["lights"])
+
+	keys, err := legacy.ListAPIKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "abc", keys[0]["key"])
+	assert.Equal(t, lastSeen, keys[0]["created_at"])
+
+	// Methods that weren't map-based pass straight through
+	_, err = legacy.GetLogLevel()
+	assert.Error(t, err)
+}
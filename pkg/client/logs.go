@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// LogEntry is a recent line of the daemon's log.
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Line    string    `json:"line"` // the line as written to the daemon's log
+}
+
+// LogQuery selects recent log lines; the zero value selects every line the
+// daemon keeps.
+type LogQuery struct {
+	Level string // only lines at or above this level: debug, info, warn or error
+	Since string // only lines since this RFC 3339 time, or this long ago, such as "10m"
+	Limit int    // at most this many lines, the most recent; 0 for no limit
+}
+
+func (q LogQuery) data() map[string]any {
+	data := map[string]any{}
+	if q.Level != "" {
+		data["level"] = q.Level
+	}
+	if q.Since != "" {
+		data["since"] = q.Since
+	}
+	if q.Limit > 0 {
+		data["limit"] = q.Limit
+	}
+	return data
+}
+
+// LogFollower is implemented by clients that can stream the daemon's log.
+// Only the socket client can; HTTP clients poll GetLogs instead.
+type LogFollower interface {
+	FollowLogs(ctx context.Context, q LogQuery) (<-chan LogEntry, error)
+}
+
+var _ LogFollower = (*Client)(nil)
+
+// GetLogs returns the recent log lines selected by q, oldest first.
+func (c *Client) GetLogs(q LogQuery) ([]LogEntry, error) {
+	var resp map[string]any
+	if err := c.request(map[string]any{"action": "get_logs", "data": q.data()}, &resp); err != nil {
+		return nil, err
+	}
+	entriesField, ok := resp["entries"]
+	if !ok {
+		return nil, errors.New("no entries field in response")
+	}
+	var entries []LogEntry
+	if err := decodeInto(entriesField, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FollowLogs streams the recent log lines selected by q, then new lines at
+// or above q.Level as they are logged, until ctx is cancelled or the
+// connection is lost, at which point the returned channel is closed. Lines
+// are streamed on a dedicated connection, as following takes it over.
+func (c *Client) FollowLogs(ctx context.Context, q LogQuery) (<-chan LogEntry, error) {
+	conn, dec, err := c.openStream("follow_logs", q.data())
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan LogEntry)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	go func() {
+		defer close(ch)
+		defer stop()
+		defer conn.Close()
+		for {
+			var entry LogEntry
+			if err := dec.Decode(&entry); err != nil {
+				if ctx.Err() == nil {
+					c.logger.Debug("Log stream ended", "error", err)
+				}
+				return
+			}
+			select {
+			case ch <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// GetLogs returns the recent log lines selected by q, oldest first.
+func (c *HTTPClient) GetLogs(q LogQuery) ([]LogEntry, error) {
+	query := url.Values{}
+	if q.Level != "" {
+		query.Set("level", q.Level)
+	}
+	if q.Since != "" {
+		query.Set("since", q.Since)
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	path := "/api/v1/logs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var resp struct {
+		Entries []LogEntry `json:"entries"`
+	}
+	if err := c.request("GET", path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Entries, nil
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLogDaemon answers hello and get_logs, and streams the given entries to
+// followers, recording the data of the last request.
+func fakeLogDaemon(t *testing.T, stream []LogEntry) *map[string]any {
+	t.Helper()
+	var lastData map[string]any
+	oldDial := dial
+	dial = func(network, address string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			reader := bufio.NewReader(server)
+			enc := json.NewEncoder(server)
+			for {
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					return
+				}
+				var req map[string]any
+				if err := json.Unmarshal(line, &req); err != nil {
+					return
+				}
+				switch req["action"] {
+				case "hello":
+					actions := []string{"hello", "get_logs", "follow_logs"}
+					enc.Encode(map[string]any{"id": req["id"], "protocol_version": 2, "version": "1.0.0", "actions": actions})
+				case "get_logs":
+					lastData, _ = req["data"].(map[string]any)
+					enc.Encode(map[string]any{"id": req["id"], "status": "ok", "entries": stream})
+				case "follow_logs":
+					lastData, _ = req["data"].(map[string]any)
+					enc.Encode(map[string]any{"status": "ok", "following": true})
+					for _, entry := range stream {
+						if enc.Encode(entry) != nil {
+							return
+						}
+					}
+				}
+			}
+		}()
+		return client, nil
+	}
+	t.Cleanup(func() { dial = oldDial })
+	return &lastData
+}
+
+var testLogEntries = []LogEntry{
+	{Time: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), Level: "info", Message: "Starting keylightd", Line: `level=INFO msg="Starting keylightd"`},
+	{Time: time.Date(2026, 10, 16, 9, 0, 1, 0, time.UTC), Level: "warn", Message: "Light offline", Line: `level=WARN msg="Light offline"`},
+}
+
+func TestClient_GetLogs(t *testing.T) {
+	lastData := fakeLogDaemon(t, testLogEntries)
+
+	c := New(testLogger(), "/tmp/fake.sock")
+	defer c.Close()
+	entries, err := c.GetLogs(LogQuery{Level: "info", Since: "10m", Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, testLogEntries, entries)
+	assert.Equal(t, map[string]any{"level": "info", "since": "10m", "limit": float64(5)}, *lastData)
+}
+
+func TestClient_FollowLogs(t *testing.T) {
+	lastData := fakeLogDaemon(t, testLogEntries)
+
+	c := New(testLogger(), "/tmp/fake.sock")
+	defer c.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.FollowLogs(ctx, LogQuery{Level: "warn"})
+	require.NoError(t, err)
+	for _, want := range testLogEntries {
+		select {
+		case got := <-ch:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for log entry")
+		}
+	}
+	assert.Equal(t, map[string]any{"level": "warn"}, *lastData)
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok, "channel should close after cancel")
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestHTTPClient_GetLogs(t *testing.T) {
+	var query string
+	_, client := newTestServer(t, map[string]http.HandlerFunc{
+		"GET /api/v1/logs": func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.RawQuery
+			jsonHandler(200, map[string]any{"entries": testLogEntries})(w, r)
+		},
+	})
+
+	entries, err := client.GetLogs(LogQuery{Level: "warn", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, testLogEntries, entries)
+	assert.Equal(t, "level=warn&limit=10", query)
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/backup"
+	"github.com/jmylchreest/keylightd/internal/circadian"
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/desiredstate"
+	"github.com/jmylchreest/keylightd/internal/group"
+	"github.com/jmylchreest/keylightd/internal/jobs"
+	"github.com/jmylchreest/keylightd/internal/scene"
+	"github.com/jmylchreest/keylightd/pkg/keylight"
+)
+
+// Light is a light as reported by the daemon. Temperature is in device
+// mireds; use keylight.ConvertDeviceToTemperature for Kelvin.
+type Light = keylight.Light
+
+// Group is a named set of lights, identified by ID.
+type Group = group.Group
+
+// GroupDefaults is the default state applied to a group's lights.
+type GroupDefaults = group.Defaults
+
+// GroupUpdate changes a group's name, description, icon or color. Nil fields
+// are left as they are.
+type GroupUpdate = group.Update
+
+// APIKey is an API key as stored by the daemon.
+type APIKey = config.APIKey
+
+// UIKey is an API key issued to a local UI by BootstrapUIKey.
+type UIKey struct {
+	Key APIKey `json:"key"`
+	// APIURL is where the daemon serves the HTTP API, or "" if it doesn't.
+	APIURL string `json:"api_url"`
+}
+
+// CircadianStatus is the state of the daemon's circadian mode.
+type CircadianStatus = circadian.Status
+
+// CircadianTarget is the state circadian mode sets lights to.
+type CircadianTarget = circadian.Target
+
+// DesiredStateStatus is the state of the daemon's desired-state mode.
+type DesiredStateStatus = desiredstate.Status
+
+// DesiredStateTarget is the state of one desired-state target.
+type DesiredStateTarget = desiredstate.TargetStatus
+
+// ExportDocument is the daemon's groups, schedules, scenes, API keys and light names,
+// as exported for backups or moving to another host.
+type ExportDocument = backup.ExportDocument
+
+// ImportResult summarises what an import added and skipped.
+type ImportResult = backup.ImportResult
+
+// Scene is a named set of light and group states applied together.
+type Scene = scene.Scene
+
+// SceneEntry is the state a scene gives one light or group, with its order,
+// delay and transition.
+type SceneEntry = scene.Entry
+
+// SceneTarget is the light or group(s) a scene entry acts upon.
+type SceneTarget = scene.Target
+
+// SceneProgress is how far a scene.apply job has got. Jobs decoded by the
+// clients carry it as a map; see DecodeProgress.
+type SceneProgress = scene.Progress
+
+// Job is a long-running operation, such as applying a scene.
+type Job = jobs.Job
+
+// JobStatus is where a job has got to.
+type JobStatus = jobs.Status
+
+// LightSettings are the power-on settings stored on a light.
+type LightSettings = keylight.LightSettings
+
+// LightSettingsUpdate changes some of a light's power-on settings.
+type LightSettingsUpdate = keylight.LightSettingsUpdate
+
+// Capabilities lists the optional properties a light supports.
+type Capabilities = keylight.Capabilities
+
+// RawResponse is a light's response to a raw request.
+type RawResponse = keylight.RawResponse
+
+// DiscoveryStats summarises the daemon's light discovery passes.
+type DiscoveryStats = keylight.DiscoveryStats
+
+// DaemonInfo describes the running daemon.
+type DaemonInfo struct {
+	Version            string          `json:"version"`
+	Commit             string          `json:"commit"`
+	BuildDate          string          `json:"build_date"`
+	StartedAt          time.Time       `json:"started_at"`
+	UptimeSeconds      int64           `json:"uptime_seconds"`
+	SocketPath         string          `json:"socket_path"`
+	APIListenAddress   string          `json:"api_listen_address,omitempty"`
+	APIListenAddresses []string        `json:"api_listen_addresses,omitempty"`
+	DiscoveryInterval  int             `json:"discovery_interval_seconds"`
+	Lights             int             `json:"lights"`
+	Groups             int             `json:"groups"`
+	Clients            DaemonClients   `json:"clients"`
+	Discovery          *DiscoveryStats `json:"discovery,omitempty"`
+}
+
+// DiscoveryResult is the outcome of a discovery pass run by DiscoverNow.
+type DiscoveryResult struct {
+	Found      []*Light `json:"found"`  // lights that weren't known before
+	Lights     int      `json:"lights"` // lights known after the pass
+	DurationMS int64    `json:"duration_ms"`
+}
+
+// PendingDevice is a device discovery found that isn't a supported light. It
+// can be adopted with AdoptDevice.
+type PendingDevice struct {
+	ID                string    `json:"id"` // the light ID it gets once adopted
+	Name              string    `json:"name"`
+	IP                string    `json:"ip"`
+	Port              int       `json:"port"`
+	Driver            string    `json:"driver,omitempty"`
+	ProductName       string    `json:"productname"`
+	HardwareBoardType int       `json:"hardwareboardtype"`
+	FirmwareVersion   string    `json:"firmwareversion"`
+	Source            string    `json:"source"` // discovery backend that last found it
+	FirstSeen         time.Time `json:"firstseen"`
+	LastSeen          time.Time `json:"lastseen"`
+}
+
+// ScheduleExecution is a time a schedule will fire.
+type ScheduleExecution struct {
+	ScheduleID string         `json:"schedule_id"`
+	Name       string         `json:"name"`
+	At         time.Time      `json:"at"` // in the schedule's time zone
+	Target     map[string]any `json:"target,omitempty"`
+	Action     map[string]any `json:"action,omitempty"`
+	Scene      string         `json:"scene,omitempty"`
+}
+
+// DaemonClients counts the API clients connected to the daemon.
+type DaemonClients struct {
+	Socket           int `json:"socket"`
+	EventSubscribers int `json:"event_subscribers"`
+	WebSocket        int `json:"websocket"`
+}
+
+// Uptime returns how long the daemon has been running.
+func (i *DaemonInfo) Uptime() time.Duration {
+	return time.Duration(i.UptimeSeconds) * time.Second
+}
+
+// GroupState is the aggregate state of a group's lights, computed by the
+// daemon from their cached states. Only reachable lights count towards it.
+type GroupState struct {
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	LightsTotal          int      `json:"lights_total"`
+	LightsOn             int      `json:"lights_on"`
+	AllOn                bool     `json:"all_on"`
+	AnyOn                bool     `json:"any_on"`
+	Brightness           int      `json:"brightness"`
+	MinTemperature       int      `json:"min_temperature,omitempty"` // Kelvin
+	MaxTemperature       int      `json:"max_temperature,omitempty"` // Kelvin
+	MinTemperatureKelvin int      `json:"min_temperature_kelvin,omitempty"`
+	MaxTemperatureKelvin int      `json:"max_temperature_kelvin,omitempty"`
+	Unreachable          []string `json:"unreachable"`
+	Version              uint64   `json:"version"` // latest state version of any of the lights
+}
+
+// LightStats is the usage of a light, as tracked by the daemon.
+type LightStats struct {
+	ID         string     `json:"id"`
+	On         bool       `json:"on"`
+	OnSeconds  int64      `json:"on_seconds"`
+	Toggles    int        `json:"toggles"`
+	LastChange *time.Time `json:"last_change,omitempty"`
+	LastToggle *time.Time `json:"last_toggle,omitempty"`
+	Since      time.Time  `json:"since"`
+}
+
+// OnTime returns the light's cumulative on-time.
+func (s *LightStats) OnTime() time.Duration {
+	return time.Duration(s.OnSeconds) * time.Second
+}
+
+// GroupStats is the combined usage of a group's lights.
+type GroupStats struct {
+	ID         string       `json:"id"`
+	OnSeconds  int64        `json:"on_seconds"`
+	Toggles    int          `json:"toggles"`
+	LastChange *time.Time   `json:"last_change,omitempty"`
+	LastToggle *time.Time   `json:"last_toggle,omitempty"`
+	Lights     []LightStats `json:"lights"`
+}
+
+// OnTime returns the sum of the on-time of the group's lights.
+func (s *GroupStats) OnTime() time.Duration {
+	return time.Duration(s.OnSeconds) * time.Second
+}
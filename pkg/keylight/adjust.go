@@ -0,0 +1,140 @@
+package keylight
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// Adjustment is a relative change to a light's state. Results are clamped to
+// the supported ranges rather than rejected.
+type Adjustment struct {
+	// Brightness is added to the current brightness, in percentage points.
+	Brightness int
+	// Temperature is added to the current colour temperature, in Kelvin.
+	Temperature int
+}
+
+// IsZero reports whether the adjustment would change nothing.
+func (a Adjustment) IsZero() bool {
+	return a.Brightness == 0 && a.Temperature == 0
+}
+
+// ParseDelta parses a relative value such as "+10", "-5", "+10%" or "-200K".
+// relative is false when s has no leading sign, meaning it is an absolute value;
+// callers should then parse s themselves.
+func ParseDelta(s string) (delta int, relative bool, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '+' && s[0] != '-') {
+		return 0, false, nil
+	}
+	num := strings.TrimRight(s, "%Kk")
+	delta, err = strconv.Atoi(num)
+	if err != nil {
+		return 0, true, errors.InvalidInputf("invalid relative value %q", s)
+	}
+	return delta, true, nil
+}
+
+// AdjustLight changes a light's brightness and temperature relative to the
+// values currently reported by the device, clamping the result to the
+// supported range. Concurrent updates to the same light are serialised, so two
+// "+10" adjustments always add 20.
+func (m *Manager) AdjustLight(ctx context.Context, id string, adj Adjustment) error {
+	if adj.IsZero() {
+		return errors.InvalidInputf("adjustment for light %s changes nothing", id)
+	}
+	if adj.Temperature != 0 {
+		_, light, err := m.getOrCreateClient(id)
+		if err != nil {
+			return err
+		}
+		if !light.Supports(PropertyTemperature) {
+			return errors.InvalidInputf("light %s does not support color temperature", id)
+		}
+	}
+	_, err := m.modifyLightState(ctx, id, "adjust", func(state *LightState) {
+		current := &state.Lights[0]
+		if adj.Brightness != 0 {
+			current.Brightness = max(config.MinBrightness, min(current.Brightness+adj.Brightness, config.MaxBrightness))
+		}
+		if adj.Temperature != 0 {
+			kelvin := ConvertDeviceToTemperature(current.Temperature) + adj.Temperature
+			current.Temperature = convertTemperatureToDevice(max(config.MinTemperature, min(kelvin, config.MaxTemperature)))
+		}
+	})
+	return err
+}
+
+// ToggleLight inverts a light's power state as currently reported by the
+// device and returns the new state.
+func (m *Manager) ToggleLight(ctx context.Context, id string) (bool, error) {
+	updated, err := m.modifyLightState(ctx, id, "toggle", func(state *LightState) {
+		state.Lights[0].On = boolToInt(state.Lights[0].On != 1)
+	})
+	if err != nil {
+		return false, err
+	}
+	return updated.On, nil
+}
+
+// modifyLightState reads a light's state from the device, applies mutate to it and
+// writes the result back while holding the light's lock, so concurrent
+// read-modify-write updates cannot overwrite each other.
+func (m *Manager) modifyLightState(ctx context.Context, id, operation string, mutate func(state *LightState)) (*Light, error) {
+	unlock, err := m.lockLight(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := m.checkVersion(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// A direct state change supersedes any transition in progress
+	m.cancelTransition(id)
+
+	client, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return nil, err
+	}
+	state, err := m.fetchLightState(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.Lights) == 0 {
+		return nil, errors.InvalidInputf("invalid current state")
+	}
+
+	mutate(state)
+	state.Lights[0].Brightness, state.Lights[0].Temperature = m.clampToLimits(light, state.Lights[0].Brightness, state.Lights[0].Temperature)
+
+	if err := sendLightState(ctx, client, light, state.Lights[0]); err != nil {
+		if errors.IsInvalidInput(err) {
+			return nil, err
+		}
+		return nil, errors.LogErrorAndReturnContext(
+			ctx,
+			m.logger,
+			errors.DeviceUnavailablef("failed to send updated state: %w", err),
+			"failed to "+operation+" light state",
+			"id", id,
+		)
+	}
+
+	m.mu.Lock()
+	updatedLight, err := m.updateLightState(id, state)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, errors.NotFoundf("light %s removed during state update", id)
+	}
+	advanceVersion(ctx, updatedLight)
+
+	m.emit(events.LightStateChanged, updatedLight)
+	return updatedLight, nil
+}
@@ -0,0 +1,118 @@
+package keylight
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestParseDelta(t *testing.T) {
+	tests := []struct {
+		in       string
+		delta    int
+		relative bool
+		wantErr  bool
+	}{
+		{"+10", 10, true, false},
+		{"-10", -10, true, false},
+		{" +5% ", 5, true, false},
+		{"+200K", 200, true, false},
+		{"-150k", -150, true, false},
+		{"50", 0, false, false},
+		{"", 0, false, false},
+		{"+", 0, true, true},
+		{"+ten", 0, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			delta, relative, err := ParseDelta(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.True(t, errors.IsInvalidInput(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.delta, delta)
+			assert.Equal(t, tt.relative, relative)
+		})
+	}
+}
+
+func TestAdjustLight(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 50, convertTemperatureToDevice(4000))
+	ctx := context.Background()
+
+	require.NoError(t, m.AdjustLight(ctx, "light1", Adjustment{Brightness: 10, Temperature: 500}))
+	light, err := m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.Equal(t, 60, light.Brightness)
+	assert.Equal(t, convertTemperatureToDevice(4500), light.Temperature)
+	assert.True(t, light.On)
+
+	// Results are clamped to the supported range
+	require.NoError(t, m.AdjustLight(ctx, "light1", Adjustment{Brightness: -200, Temperature: 10000}))
+	updates := device.updates()
+	last := updates[len(updates)-1].Lights[0]
+	assert.Equal(t, 3, last.Brightness)
+	assert.Equal(t, convertTemperatureToDevice(7000), last.Temperature)
+
+	err = m.AdjustLight(ctx, "light1", Adjustment{})
+	assert.True(t, errors.IsInvalidInput(err))
+
+	err = m.AdjustLight(ctx, "missing", Adjustment{Brightness: 1})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestAdjustLight_Concurrent(t *testing.T) {
+	m, _ := newTransitionTestManager(t, 1, 20, 200)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			assert.NoError(t, m.AdjustLight(context.Background(), "light1", Adjustment{Brightness: 5}))
+		})
+	}
+	wg.Wait()
+
+	light, err := m.GetLight(context.Background(), "light1")
+	require.NoError(t, err)
+	assert.Equal(t, 70, light.Brightness)
+}
+
+func TestToggleLight(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 40, 200)
+	ctx := context.Background()
+
+	on, err := m.ToggleLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.False(t, on)
+	updates := device.updates()
+	last := updates[len(updates)-1].Lights[0]
+	assert.Equal(t, 0, last.On)
+	assert.Equal(t, 40, last.Brightness)
+
+	on, err = m.ToggleLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	// An even number of concurrent toggles leaves the light as it was
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			_, err := m.ToggleLight(ctx, "light1")
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+	light, err := m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.True(t, light.On)
+
+	_, err = m.ToggleLight(ctx, "missing")
+	assert.True(t, errors.IsNotFound(err))
+}
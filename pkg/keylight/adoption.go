@@ -0,0 +1,145 @@
+package keylight
+
+import (
+	"context"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// PendingDevice is a device that answered during discovery but is not a light
+// the validation rules accept. It becomes a light once adopted.
+type PendingDevice struct {
+	ID                string    `json:"id"` // the ID it has as a light once adopted
+	Name              string    `json:"name"`
+	IP                net.IP    `json:"ip"`
+	Port              int       `json:"port"`
+	Driver            string    `json:"driver,omitempty"`
+	ProductName       string    `json:"productname"`
+	HardwareBoardType int       `json:"hardwareboardtype"`
+	FirmwareVersion   string    `json:"firmwareversion"`
+	Source            string    `json:"source"` // discovery backend that last found it
+	FirstSeen         time.Time `json:"firstseen"`
+	LastSeen          time.Time `json:"lastseen"`
+
+	light Light // the light it describes, added when it is adopted
+}
+
+// SetAdoptedDevices loads the IDs of adopted devices, which discovery accepts
+// as lights whatever the validation rules say, and sets the function used to
+// persist the list when a device is adopted. It must be set before discovery
+// starts.
+func (m *Manager) SetAdoptedDevices(ids []string, persist func(ids []string) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.adopted = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		m.adopted[id] = true
+	}
+	m.persistAdopted = persist
+}
+
+// isAdopted reports whether the device with the given light ID was adopted.
+func (m *Manager) isAdopted(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.adopted[id]
+}
+
+// recordPendingDevice remembers a device that answered during discovery but
+// was not accepted as a light, so that it can be adopted.
+func (m *Manager) recordPendingDevice(light Light, source string) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prunePendingLocked(now)
+	if m.unvalidated == nil {
+		m.unvalidated = make(map[string]*PendingDevice)
+	}
+	dev, ok := m.unvalidated[light.ID]
+	if !ok {
+		dev = &PendingDevice{ID: light.ID, FirstSeen: now}
+		m.unvalidated[light.ID] = dev
+		m.logger.Info("discovery: found a device that is not a supported light; adopt it to use it anyway",
+			"id", light.ID, "productName", light.ProductName, "addr", light.IP, "source", source)
+	}
+	dev.Name = light.Name
+	dev.IP = light.IP
+	dev.Port = light.Port
+	dev.Driver = light.Driver
+	dev.ProductName = light.ProductName
+	dev.HardwareBoardType = light.HardwareBoardType
+	dev.FirmwareVersion = light.FirmwareVersion
+	dev.Source = source
+	dev.LastSeen = now
+	dev.light = light
+}
+
+// prunePendingLocked forgets pending devices not seen for the offline
+// retention period. The caller must hold m.mu.
+func (m *Manager) prunePendingLocked(now time.Time) {
+	for id, dev := range m.unvalidated {
+		if now.Sub(dev.LastSeen) > m.offlineRetention {
+			delete(m.unvalidated, id)
+		}
+	}
+}
+
+// PendingDevices returns the devices found by discovery that are not lights
+// the validation rules accept, sorted by ID. Devices not seen for the offline
+// retention period are left out.
+func (m *Manager) PendingDevices() []PendingDevice {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prunePendingLocked(time.Now())
+	devices := make([]PendingDevice, 0, len(m.unvalidated))
+	for _, dev := range m.unvalidated {
+		devices = append(devices, *dev)
+	}
+	slices.SortFunc(devices, func(a, b PendingDevice) int { return strings.Compare(a.ID, b.ID) })
+	return devices
+}
+
+// AdoptDevice adds a pending device as a light and persists its adoption, so
+// discovery accepts it from then on even though the validation rules don't.
+func (m *Manager) AdoptDevice(ctx context.Context, id string) (*Light, error) {
+	m.mu.Lock()
+	dev, ok := m.unvalidated[id]
+	if !ok {
+		_, known := m.lights[id]
+		m.mu.Unlock()
+		if known {
+			return nil, errors.Conflictf("light %s is already known", id)
+		}
+		return nil, errors.NotFoundf("no pending device %s", id)
+	}
+	delete(m.unvalidated, id)
+	if m.adopted == nil {
+		m.adopted = make(map[string]bool)
+	}
+	m.adopted[id] = true
+	ids := slices.Sorted(maps.Keys(m.adopted))
+	persist := m.persistAdopted
+	m.mu.Unlock()
+
+	if persist != nil {
+		if err := persist(ids); err != nil {
+			return nil, errors.Internalf("failed to save adoption of device %s: %w", id, err)
+		}
+	}
+
+	m.logger.InfoContext(ctx, "light: adopted device", "id", id, "productName", dev.ProductName)
+	m.addDiscoveredLight(ctx, dev.light)
+
+	m.mu.RLock()
+	light, ok := m.lights[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, errors.NotFoundf("light %s not found", id)
+	}
+	return m.withLimits(&light), nil
+}
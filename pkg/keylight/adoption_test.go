@@ -0,0 +1,78 @@
+package keylight
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// entriesBackend reports the same entries on every browse.
+type entriesBackend []*ServiceEntry
+
+func (b entriesBackend) Name() string { return "test" }
+
+func (b entriesBackend) Browse(_ context.Context, entries chan<- *ServiceEntry) error {
+	for _, e := range b {
+		entries <- e
+	}
+	return nil
+}
+
+func TestManager_AdoptDevice(t *testing.T) {
+	server := httptest.NewServer(newAccessoryInfoHandler("Elgato Key Light Neo", 300, "Neo", 0))
+	defer server.Close()
+	entry := makeServiceEntry(t, server, "Key Light Neo 1A2B")
+	entry.Source = "test"
+
+	m := NewManager(discardLogger())
+	var saved []string
+	m.SetAdoptedDevices(nil, func(ids []string) error {
+		saved = ids
+		return nil
+	})
+	m.backends = []DiscoveryBackend{entriesBackend{entry}}
+
+	found, err := m.DiscoverNow(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, found, "unknown models are not added")
+	pending := m.PendingDevices()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "Key Light Neo 1A2B", pending[0].ID)
+	assert.Equal(t, "Elgato Key Light Neo", pending[0].ProductName)
+	assert.Equal(t, "test", pending[0].Source)
+
+	light, err := m.AdoptDevice(context.Background(), "Key Light Neo 1A2B")
+	require.NoError(t, err)
+	assert.Equal(t, "Elgato Key Light Neo", light.ProductName)
+	assert.Equal(t, []string{"Key Light Neo 1A2B"}, saved)
+	assert.Empty(t, m.PendingDevices())
+	assert.Contains(t, m.GetLights(), "Key Light Neo 1A2B")
+
+	_, err = m.DiscoverNow(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, m.PendingDevices(), "adopted devices are accepted by discovery")
+
+	_, err = m.AdoptDevice(context.Background(), "Key Light Neo 1A2B")
+	assert.True(t, errors.IsConflict(err))
+	_, err = m.AdoptDevice(context.Background(), "missing")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestManager_SetAdoptedDevices(t *testing.T) {
+	server := httptest.NewServer(newAccessoryInfoHandler("Elgato Key Light Neo", 300, "Neo", 0))
+	defer server.Close()
+
+	m := NewManager(discardLogger())
+	m.SetAdoptedDevices([]string{"Key Light Neo 1A2B"}, nil)
+	m.backends = []DiscoveryBackend{entriesBackend{makeServiceEntry(t, server, "Key Light Neo 1A2B")}}
+
+	found, err := m.DiscoverNow(context.Background())
+	require.NoError(t, err)
+	require.Len(t, found, 1, "devices adopted before a restart are added again")
+	assert.Empty(t, m.PendingDevices())
+}
@@ -0,0 +1,226 @@
+package keylight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/grandcat/zeroconf"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// Discovery backend names, as used in config.discovery.backends.
+const (
+	BackendMDNS = "mdns"
+	BackendSSDP = "ssdp"
+)
+
+// DiscoveryBackend finds candidate lights on the network. Every entry it
+// finds is validated through the entry's driver before the light is added,
+// so backends may report devices that turn out not to be lights.
+type DiscoveryBackend interface {
+	// Name identifies the backend in logs.
+	Name() string
+	// Browse searches for lights until ctx is done or the search is
+	// finished, sending each entry found to entries. It must not close
+	// entries.
+	Browse(ctx context.Context, entries chan<- *ServiceEntry) error
+}
+
+// DiscoveryBackends returns the names of the backends SetDiscoveryBackends accepts.
+func DiscoveryBackends() []string {
+	return []string{BackendMDNS, BackendSSDP, BackendScan}
+}
+
+// SetDiscoveryBackends selects the backends used to find lights, by name.
+// Results from every backend are combined. An empty list uses mDNS only. It
+// must be called before discovery starts.
+func (m *Manager) SetDiscoveryBackends(names []string) error {
+	backends := make([]DiscoveryBackend, 0, len(names))
+	for _, name := range names {
+		var backend DiscoveryBackend
+		switch name {
+		case BackendMDNS:
+			backend = &mdnsBackend{manager: m}
+		case BackendSSDP:
+			backend = &ssdpBackend{logger: m.logger}
+		case BackendScan:
+			m.mu.RLock()
+			subnets := len(m.scan.subnets)
+			m.mu.RUnlock()
+			if subnets == 0 {
+				return errors.InvalidInputf("discovery backend %q needs at least one subnet to scan", name)
+			}
+			backend = &scanBackend{manager: m}
+		default:
+			return errors.InvalidInputf("unknown discovery backend %q, expected one of %v", name, DiscoveryBackends())
+		}
+		if slices.ContainsFunc(backends, func(b DiscoveryBackend) bool { return b.Name() == name }) {
+			return errors.InvalidInputf("discovery backend %q listed twice", name)
+		}
+		backends = append(backends, backend)
+	}
+
+	m.mu.Lock()
+	m.backends = backends
+	m.mu.Unlock()
+	return nil
+}
+
+// discoveryBackends returns the backends to browse with, defaulting to mDNS.
+func (m *Manager) discoveryBackends() []DiscoveryBackend {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.backends) == 0 {
+		return []DiscoveryBackend{&mdnsBackend{manager: m}}
+	}
+	return slices.Clone(m.backends)
+}
+
+// addressID is the ID given to lights found at an address without a service
+// name, the same as for static lights without an ID.
+func addressID(ip net.IP, port int) string {
+	return net.JoinHostPort(ip.String(), strconv.Itoa(port))
+}
+
+// addDiscoveredLight adds a validated light, merging it with a light already
+// known under another ID. Backends that only know a light's address, such as
+// SSDP, reuse the ID of a light with the same serial number found by mDNS,
+// and a light found by mDNS replaces one previously known only by address.
+func (m *Manager) addDiscoveredLight(ctx context.Context, light Light) {
+	var replaced *Light
+	if light.SerialNumber != "" {
+		m.mu.Lock()
+		for id, existing := range m.lights {
+			if id == light.ID || existing.Static || existing.SerialNumber != light.SerialNumber {
+				continue
+			}
+			if light.ID == addressID(light.IP, light.Port) {
+				light.ID = id
+				break
+			}
+			if id == addressID(existing.IP, existing.Port) {
+				delete(m.lights, id)
+				delete(m.clients, id)
+				delete(m.refreshedAt, id)
+				replaced = &existing
+				break
+			}
+		}
+		m.mu.Unlock()
+	}
+	if replaced != nil {
+		m.logger.Info("light: replacing light found by address with its mDNS entry", "old_id", replaced.ID, "id", light.ID)
+		m.emit(events.LightRemoved, replaced)
+	}
+	m.AddLight(ctx, light)
+}
+
+// mdnsBackend browses for the mDNS services of every registered driver on
+// each configured interface.
+type mdnsBackend struct {
+	manager *Manager
+}
+
+func (b *mdnsBackend) Name() string { return BackendMDNS }
+
+// Browse browses every service on every usable interface until ctx is done.
+// It returns an error only if no interface could be browsed.
+func (b *mdnsBackend) Browse(ctx context.Context, entries chan<- *ServiceEntry) error {
+	m := b.manager
+	// Resolve interfaces every cycle, as VPN and container interfaces come and go
+	targets := m.browseTargets()
+	serviceNames := discoveryServices()
+
+	// The zeroconf library closes the channel passed to Browse when it
+	// finishes, so each browse gets its own channel which is forwarded into
+	// entries.
+	var forwarders sync.WaitGroup
+	var resolverErr error
+	browsing := 0
+	for _, target := range targets {
+		resolver, err := zeroconf.NewResolver(target.options()...)
+		if err != nil {
+			resolverErr = errors.LogErrorAndReturn(
+				m.logger,
+				errors.Internalf("failed to create zeroconf resolver: %w", err),
+				"discovery resolver creation failed",
+				"interface", target.name,
+			)
+			continue
+		}
+		browsing++
+
+		for _, serviceName := range serviceNames {
+			serviceEntries := make(chan *zeroconf.ServiceEntry, 10)
+			if err := resolver.Browse(ctx, serviceName, domain, serviceEntries); err != nil {
+				_ = errors.LogErrorAndReturn(
+					m.logger,
+					err,
+					"Browse attempt failed",
+					"service", serviceName,
+					"interface", target.name,
+				)
+				continue
+			}
+			forwarders.Go(func() {
+				for entry := range serviceEntries {
+					if e := b.serviceEntry(entry, target.name); e != nil {
+						entries <- e
+					}
+				}
+			})
+		}
+	}
+
+	if browsing == 0 && resolverErr != nil {
+		return resolverErr
+	}
+	// zeroconf closes the service channels when ctx is done
+	forwarders.Wait()
+	return nil
+}
+
+// serviceEntry converts a zeroconf entry for a registered driver's service,
+// returning nil for other services.
+func (b *mdnsBackend) serviceEntry(entry *zeroconf.ServiceEntry, iface string) *ServiceEntry {
+	b.manager.logger.Debug("zeroconf: received entry",
+		"instance", entry.Instance,
+		"service", entry.Service,
+		"addrIPv4", entry.AddrIPv4,
+		"addrIPv6", entry.AddrIPv6,
+		"port", entry.Port,
+		"text", entry.Text,
+		"interface", iface)
+
+	driver, ok := driverForService(entry.Service)
+	if !ok {
+		return nil
+	}
+	var ipv4 net.IP
+	if len(entry.AddrIPv4) > 0 {
+		ipv4 = entry.AddrIPv4[0]
+	}
+	addrs := slices.Clone(entry.AddrIPv4)
+	for _, ip := range entry.AddrIPv6 {
+		// Link-local addresses can't be used without the interface's zone,
+		// which net.IP doesn't carry
+		if !ip.IsLinkLocalUnicast() {
+			addrs = append(addrs, ip)
+		}
+	}
+	return &ServiceEntry{
+		Name:   entry.Instance + "." + entry.Service + "." + entry.Domain,
+		AddrV4: ipv4,
+		Addrs:  addrs,
+		Port:   entry.Port,
+		Info:   fmt.Sprint(entry.Text),
+		Driver: driver,
+		Source: BackendMDNS + "/" + iface,
+	}
+}
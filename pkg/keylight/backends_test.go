@@ -0,0 +1,117 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestSetDiscoveryBackends(t *testing.T) {
+	m := NewManager(discardLogger())
+	backends := m.discoveryBackends()
+	require.Len(t, backends, 1)
+	assert.Equal(t, BackendMDNS, backends[0].Name())
+
+	require.NoError(t, m.SetDiscoveryBackends([]string{BackendSSDP, BackendMDNS}))
+	backends = m.discoveryBackends()
+	require.Len(t, backends, 2)
+	assert.Equal(t, BackendSSDP, backends[0].Name())
+
+	assert.True(t, errors.IsInvalidInput(m.SetDiscoveryBackends([]string{"bluetooth"})))
+	assert.True(t, errors.IsInvalidInput(m.SetDiscoveryBackends([]string{BackendSSDP, BackendSSDP})))
+}
+
+func TestSSDPBackend_Browse(t *testing.T) {
+	responder, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer responder.Close()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, from, err := responder.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(string(buf[:n]), "M-SEARCH") {
+				continue
+			}
+			// Answer twice, as devices do for each service they offer
+			for _, usn := range []string{"uuid:light::upnp:rootdevice", "uuid:light"} {
+				_, _ = responder.WriteToUDP([]byte("HTTP/1.1 200 OK\r\n"+
+					"CACHE-CONTROL: max-age=1800\r\n"+
+					"LOCATION: http://127.0.0.1:49152/description.xml\r\n"+
+					"SERVER: Linux UPnP/1.0\r\n"+
+					"ST: upnp:rootdevice\r\n"+
+					"USN: "+usn+"\r\n\r\n"), from)
+			}
+		}
+	}()
+
+	backend := &ssdpBackend{logger: discardLogger(), addr: responder.LocalAddr().(*net.UDPAddr)}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	entries := make(chan *ServiceEntry, 10)
+	require.NoError(t, backend.Browse(ctx, entries))
+	close(entries)
+
+	var got []*ServiceEntry
+	for entry := range entries {
+		got = append(got, entry)
+	}
+	require.Len(t, got, 1, "a device should be probed once per browse")
+	assert.Equal(t, "127.0.0.1", got[0].AddrV4.String())
+	assert.Equal(t, 9123, got[0].Port)
+	assert.Equal(t, DriverElgato, got[0].Driver)
+	assert.Equal(t, BackendSSDP, got[0].Source)
+	assert.Empty(t, got[0].Name)
+}
+
+func TestSSDPBackend_EntryWLED(t *testing.T) {
+	backend := &ssdpBackend{logger: discardLogger()}
+	entry := backend.entry([]byte("HTTP/1.1 200 OK\r\nSERVER: WLED/0.14\r\nUSN: uuid:wled\r\n\r\n"),
+		&net.UDPAddr{IP: net.IPv4(192, 168, 1, 30), Port: 1900})
+	require.NotNil(t, entry)
+	assert.Equal(t, DriverWLED, entry.Driver)
+	assert.Equal(t, 80, entry.Port)
+	assert.Equal(t, "192.168.1.30", entry.AddrV4.String())
+
+	assert.Nil(t, backend.entry([]byte("not http"), &net.UDPAddr{IP: net.IPv4(192, 168, 1, 30)}))
+}
+
+func TestAddDiscoveredLight_MergesBySerial(t *testing.T) {
+	srv := mockHTTPServer(t)
+	defer srv.Close()
+	host, port := hostPort(t, srv)
+	ip := net.ParseIP(host)
+	entry := &ServiceEntry{AddrV4: ip, Port: port, Source: BackendSSDP}
+	ctx := context.Background()
+
+	// Found by address first, then by mDNS: the mDNS entry replaces it
+	m := NewManager(discardLogger())
+	byAddress, ok := validateLight(ctx, entry, validationRules{}, discardLogger())
+	require.True(t, ok)
+	assert.Equal(t, addressID(ip, port), byAddress.ID)
+	m.addDiscoveredLight(ctx, byAddress)
+
+	mdnsEntry := *entry
+	mdnsEntry.Name = "Key Light._elg._tcp.local."
+	byName, ok := validateLight(ctx, &mdnsEntry, validationRules{}, discardLogger())
+	require.True(t, ok)
+	m.addDiscoveredLight(ctx, byName)
+	lights := m.GetLights()
+	assert.Len(t, lights, 1)
+	assert.Contains(t, lights, "Key Light._elg._tcp.local.")
+
+	// Found by mDNS first: a later address-only entry keeps the mDNS ID
+	m.addDiscoveredLight(ctx, byAddress)
+	lights = m.GetLights()
+	assert.Len(t, lights, 1)
+	assert.Contains(t, lights, "Key Light._elg._tcp.local.")
+}
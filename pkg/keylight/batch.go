@@ -0,0 +1,91 @@
+package keylight
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// LightUpdate is a state change targeted at a single light.
+type LightUpdate struct {
+	ID string
+	StateChange
+}
+
+// ValidateBatch checks a batch of updates without applying any of them.
+// Every update must name a distinct, known light and change at least one valid property.
+func ValidateBatch(lm LightManager, updates []LightUpdate) error {
+	if len(updates) == 0 {
+		return errors.InvalidInputf("no light updates given")
+	}
+	known := lm.GetLights()
+	seen := make(map[string]bool, len(updates))
+	for _, u := range updates {
+		if u.ID == "" {
+			return errors.InvalidInputf("light update is missing an id")
+		}
+		if seen[u.ID] {
+			return errors.InvalidInputf("light %s is updated more than once", u.ID)
+		}
+		seen[u.ID] = true
+		if u.IsEmpty() {
+			return errors.InvalidInputf("light %s: missing on/brightness/temperature/hue/saturation", u.ID)
+		}
+		if err := u.Validate(); err != nil {
+			return errors.InvalidInputf("light %s: %w", u.ID, err)
+		}
+		light, ok := known[u.ID]
+		if !ok {
+			return errors.NotFoundf("light %s not found", u.ID)
+		}
+		if err := checkSupported(*light, u.StateChange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyBatch validates a batch of updates and, if every update is valid, applies
+// them to their lights concurrently so the lights change together. Nothing is
+// applied when validation fails. Device failures do not stop the other updates;
+// they are returned keyed by light ID, and the map is empty when all succeed.
+func ApplyBatch(ctx context.Context, lm LightManager, updates []LightUpdate) (map[string]error, error) {
+	if err := ValidateBatch(lm, updates); err != nil {
+		return nil, err
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make(map[string]error)
+	)
+	for _, u := range updates {
+		wg.Go(func() {
+			if err := lm.Transition(ctx, u.ID, u.StateChange, 0); err != nil {
+				mu.Lock()
+				errs[u.ID] = err
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return errs, nil
+}
+
+// BatchErrorMessages formats the per-light errors returned by ApplyBatch as
+// "light <id>: <error>" messages, sorted by light ID.
+func BatchErrorMessages(errs map[string]error) []string {
+	ids := make([]string, 0, len(errs))
+	for id := range errs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("light %s: %s", id, errs[id]))
+	}
+	return msgs
+}
@@ -0,0 +1,119 @@
+package keylight
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// newBatchTestManager returns a manager with one fake device per ID.
+func newBatchTestManager(t *testing.T, ids ...string) (*Manager, map[string]*fakeDevice) {
+	t.Helper()
+	m := NewManager(slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil)))
+	devices := make(map[string]*fakeDevice, len(ids))
+	for _, id := range ids {
+		device := &fakeDevice{}
+		device.state.NumberOfLights = 1
+		device.state.Lights = append(device.state.Lights, LightStateEntry{On: 0, Brightness: 10, Temperature: 200})
+		srv := httptest.NewServer(device)
+		t.Cleanup(srv.Close)
+
+		host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+		require.NoError(t, err)
+		port, err := strconv.Atoi(portStr)
+		require.NoError(t, err)
+
+		m.lights[id] = Light{ID: id, IP: net.ParseIP(host), Port: port, Brightness: 10, Temperature: 5000}
+		devices[id] = device
+	}
+	return m, devices
+}
+
+func TestApplyBatch(t *testing.T) {
+	m, devices := newBatchTestManager(t, "left", "right")
+	on, brightness, temperature := true, 60, 4000
+
+	errs, err := ApplyBatch(context.Background(), m, []LightUpdate{
+		{ID: "left", StateChange: StateChange{On: &on, Brightness: &brightness}},
+		{ID: "right", StateChange: StateChange{Temperature: &temperature}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	left, err := m.GetLight(context.Background(), "left")
+	require.NoError(t, err)
+	assert.True(t, left.On)
+	assert.Equal(t, 60, left.Brightness)
+
+	right, err := m.GetLight(context.Background(), "right")
+	require.NoError(t, err)
+	assert.False(t, right.On)
+	assert.Equal(t, convertTemperatureToDevice(4000), right.Temperature)
+
+	assert.NotEmpty(t, devices["left"].updates())
+	assert.NotEmpty(t, devices["right"].updates())
+}
+
+func TestApplyBatch_InvalidBatchAppliesNothing(t *testing.T) {
+	m, devices := newBatchTestManager(t, "left", "right")
+	on, badBrightness := true, 150
+
+	tests := []struct {
+		name    string
+		updates []LightUpdate
+		check   func(error) bool
+	}{
+		{"empty", nil, errors.IsInvalidInput},
+		{"missing id", []LightUpdate{{StateChange: StateChange{On: &on}}}, errors.IsInvalidInput},
+		{"no change", []LightUpdate{{ID: "left"}}, errors.IsInvalidInput},
+		{"duplicate", []LightUpdate{
+			{ID: "left", StateChange: StateChange{On: &on}},
+			{ID: "left", StateChange: StateChange{On: &on}},
+		}, errors.IsInvalidInput},
+		{"invalid value", []LightUpdate{
+			{ID: "left", StateChange: StateChange{On: &on}},
+			{ID: "right", StateChange: StateChange{Brightness: &badBrightness}},
+		}, errors.IsInvalidInput},
+		{"unknown light", []LightUpdate{
+			{ID: "left", StateChange: StateChange{On: &on}},
+			{ID: "missing", StateChange: StateChange{On: &on}},
+		}, errors.IsNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := ApplyBatch(context.Background(), m, tt.updates)
+			require.Error(t, err)
+			assert.True(t, tt.check(err), "unexpected error type: %v", err)
+			assert.Nil(t, errs)
+		})
+	}
+	assert.Empty(t, devices["left"].updates())
+	assert.Empty(t, devices["right"].updates())
+}
+
+func TestApplyBatch_DeviceErrors(t *testing.T) {
+	m, _ := newBatchTestManager(t, "left")
+	m.lights["gone"] = Light{ID: "gone", IP: net.ParseIP("127.0.0.1"), Port: 1}
+	on := true
+
+	errs, err := ApplyBatch(context.Background(), m, []LightUpdate{
+		{ID: "left", StateChange: StateChange{On: &on}},
+		{ID: "gone", StateChange: StateChange{On: &on}},
+	})
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs, "gone")
+
+	msgs := BatchErrorMessages(errs)
+	require.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0], "light gone: ")
+}
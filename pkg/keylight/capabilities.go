@@ -0,0 +1,148 @@
+package keylight
+
+import (
+	"slices"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// Capabilities lists the optional properties a light supports, so clients can
+// hide controls that don't apply. Power and brightness are always supported.
+type Capabilities struct {
+	Temperature bool   `json:"temperature"`
+	Color       bool   `json:"color"`   // hue and saturation
+	Battery     bool   `json:"battery"` // the light runs on a battery
+	Range       Limits `json:"range"`   // brightness and temperature the model accepts
+}
+
+// Features reported in an Elgato light's accessory info.
+const (
+	FeatureLights  = "lights"  // a white light with a color temperature
+	FeatureBattery = "battery" // the light has a battery
+)
+
+// colorBoardTypes contains the hardware board types of Elgato lights that can
+// show a color, for firmware reporting product names keylightd doesn't know.
+var colorBoardTypes = []int{
+	70, // Elgato Light Strip
+}
+
+// modelRanges contains the brightness and color temperature range accepted by
+// each Elgato product's firmware. Products not listed, and lights of other
+// drivers, are given DefaultLimits.
+var modelRanges = map[string]Limits{
+	"Elgato Key Light":      {MinBrightness: 3, MaxBrightness: 100, MinTemperature: 2900, MaxTemperature: 7000},
+	"Elgato Key Light Air":  {MinBrightness: 3, MaxBrightness: 100, MinTemperature: 2900, MaxTemperature: 7000},
+	"Elgato Key Light MK.2": {MinBrightness: 3, MaxBrightness: 100, MinTemperature: 2900, MaxTemperature: 7000},
+	"Elgato Ring Light":     {MinBrightness: 3, MaxBrightness: 100, MinTemperature: 2900, MaxTemperature: 7000},
+	"Elgato Light Strip":    {MinBrightness: 3, MaxBrightness: 100, MinTemperature: 2900, MaxTemperature: 7000},
+}
+
+// ModelRange returns the brightness and color temperature range a product
+// accepts, or DefaultLimits for products without a known range.
+func ModelRange(productName string) Limits {
+	if r, ok := modelRanges[productName]; ok {
+		return r
+	}
+	return DefaultLimits()
+}
+
+// CapabilitiesFromInfo works out what a light supports from its accessory
+// info. Devices that report no features, such as WLED, are assumed to have a
+// color temperature.
+func CapabilitiesFromInfo(info *AccessoryInfo) Capabilities {
+	return Capabilities{
+		Temperature: len(info.Features) == 0 || slices.Contains(info.Features, FeatureLights),
+		Color:       slices.Contains(colorProductNames, info.ProductName) || slices.Contains(colorBoardTypes, info.HardwareBoardType),
+		Battery:     slices.Contains(info.Features, FeatureBattery),
+		Range:       ModelRange(info.ProductName),
+	}
+}
+
+// capabilities returns the light's capabilities, assuming a plain white light
+// with the default range while they aren't known.
+func (l Light) capabilities() Capabilities {
+	if l.Capabilities == nil {
+		return Capabilities{Temperature: true, Range: DefaultLimits()}
+	}
+	caps := *l.Capabilities
+	if caps.Range == (Limits{}) {
+		caps.Range = DefaultLimits()
+	}
+	return caps
+}
+
+// Range returns the brightness and color temperature range the light's model
+// accepts.
+func (l Light) Range() Limits {
+	return l.capabilities().Range
+}
+
+// Supports reports whether the light can be set to property.
+func (l Light) Supports(property PropertyName) bool {
+	caps := l.capabilities()
+	switch property {
+	case PropertyTemperature:
+		return caps.Temperature
+	case PropertyHue, PropertySaturation, PropertyColor:
+		return caps.Color
+	default:
+		return true
+	}
+}
+
+// SupportsColor reports whether the light can show a hue and saturation.
+func (l Light) SupportsColor() bool {
+	return l.Supports(PropertyColor)
+}
+
+// checkSupported returns an invalid input error if change sets a property the
+// light doesn't support, or a value outside its model's range.
+func checkSupported(light Light, change StateChange) error {
+	if change.Temperature != nil && !light.Supports(PropertyTemperature) {
+		return errors.InvalidInputf("light %s does not support color temperature", light.ID)
+	}
+	if change.HasColor() && !light.SupportsColor() {
+		return errors.InvalidInputf("light %s does not support color", light.ID)
+	}
+	return checkRange(light, change.Brightness, change.Temperature)
+}
+
+// checkRange returns an invalid input error if brightness or temperature, in
+// Kelvin, is outside the range of the light's model. Nil values are not checked.
+func checkRange(light Light, brightness, temperature *int) error {
+	r := light.Range()
+	if brightness != nil && (*brightness < r.MinBrightness || *brightness > r.MaxBrightness) {
+		return errors.InvalidInputf("light %s supports brightness between %d and %d, got %d",
+			light.ID, r.MinBrightness, r.MaxBrightness, *brightness)
+	}
+	if temperature != nil && (*temperature < r.MinTemperature || *temperature > r.MaxTemperature) {
+		return errors.InvalidInputf("light %s supports temperature between %dK and %dK, got %dK",
+			light.ID, r.MinTemperature, r.MaxTemperature, *temperature)
+	}
+	return nil
+}
+
+// checkPropertyRange is checkRange for a single brightness or temperature
+// property value.
+func checkPropertyRange(light Light, value LightPropertyValue) error {
+	switch v := value.(type) {
+	case BrightnessValue:
+		brightness := int(v)
+		return checkRange(light, &brightness, nil)
+	case TemperatureValue:
+		temperature := int(v)
+		return checkRange(light, nil, &temperature)
+	}
+	return nil
+}
+
+// setCapabilities records capabilities on light and updates its color mode.
+// A light reporting a hue can show a color whatever its product.
+func setCapabilities(light *Light, caps Capabilities) {
+	if light.Hue != nil {
+		caps.Color = true
+	}
+	light.Capabilities = &caps
+	light.ColorMode = colorMode(*light)
+}
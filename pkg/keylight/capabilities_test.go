@@ -0,0 +1,107 @@
+package keylight
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestCapabilitiesFromInfo(t *testing.T) {
+	r := DefaultLimits()
+	tests := []struct {
+		name string
+		info AccessoryInfo
+		want Capabilities
+	}{
+		{"key light", AccessoryInfo{ProductName: "Elgato Key Light", HardwareBoardType: 53, Features: []string{"lights"}}, Capabilities{Temperature: true, Range: r}},
+		{"light strip", AccessoryInfo{ProductName: "Elgato Light Strip", HardwareBoardType: 70, Features: []string{"lights"}}, Capabilities{Temperature: true, Color: true, Range: r}},
+		{"unknown color board", AccessoryInfo{ProductName: "Elgato Light Strip Pro", HardwareBoardType: 70, Features: []string{"lights"}}, Capabilities{Temperature: true, Color: true, Range: r}},
+		{"battery", AccessoryInfo{ProductName: "Elgato Key Light Mini", Features: []string{"lights", "battery"}}, Capabilities{Temperature: true, Battery: true, Range: r}},
+		{"no features", AccessoryInfo{ProductName: "WLED"}, Capabilities{Temperature: true, Range: r}},
+		{"no white light", AccessoryInfo{ProductName: "Elgato Accessory", Features: []string{"battery"}}, Capabilities{Battery: true, Range: r}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CapabilitiesFromInfo(&tt.info))
+		})
+	}
+}
+
+func TestLight_Supports(t *testing.T) {
+	unknown := Light{ID: "a"}
+	assert.True(t, unknown.Supports(PropertyTemperature), "lights are assumed to be white until known")
+	assert.False(t, unknown.SupportsColor())
+	assert.True(t, unknown.Supports(PropertyBrightness))
+
+	strip := Light{ID: "b", Capabilities: &Capabilities{Color: true}}
+	assert.False(t, strip.Supports(PropertyTemperature))
+	assert.True(t, strip.Supports(PropertyHue))
+	assert.True(t, strip.Supports(PropertyOn))
+}
+
+func TestSetLightState_UnsupportedTemperature(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 50, 200)
+	light := m.lights["light1"]
+	setCapabilities(&light, Capabilities{Color: true})
+	m.lights["light1"] = light
+	ctx := context.Background()
+
+	err := m.SetLightState(ctx, "light1", TemperatureValue(4000))
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+	temperature := 4000
+	err = m.Transition(ctx, "light1", StateChange{Temperature: &temperature}, transitionStepInterval*5)
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+	err = m.AdjustLight(ctx, "light1", Adjustment{Temperature: 200})
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+	assert.Empty(t, device.updates())
+
+	require.NoError(t, m.SetLightState(ctx, "light1", BrightnessValue(20)))
+}
+
+func TestModelRange(t *testing.T) {
+	assert.Equal(t, Limits{MinBrightness: 3, MaxBrightness: 100, MinTemperature: 2900, MaxTemperature: 7000}, ModelRange("Elgato Key Light MK.2"))
+	assert.Equal(t, DefaultLimits(), ModelRange("WLED"))
+
+	assert.Equal(t, DefaultLimits(), Light{ID: "a"}.Range(), "lights are given the default range until known")
+	assert.Equal(t, DefaultLimits(), Light{ID: "b", Capabilities: &Capabilities{Temperature: true}}.Range())
+}
+
+func TestSetLightState_ModelRange(t *testing.T) {
+	modelRanges["Elgato Test Light"] = Limits{MinBrightness: 10, MaxBrightness: 90, MinTemperature: 3000, MaxTemperature: 6000}
+	t.Cleanup(func() { delete(modelRanges, "Elgato Test Light") })
+
+	m, device := newTransitionTestManager(t, 1, 50, 200)
+	light := m.lights["light1"]
+	setCapabilities(&light, CapabilitiesFromInfo(&AccessoryInfo{ProductName: "Elgato Test Light", Features: []string{FeatureLights}}))
+	m.lights["light1"] = light
+	ctx := context.Background()
+
+	err := m.SetLightState(ctx, "light1", TemperatureValue(2900))
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+	assert.Contains(t, err.Error(), "between 3000K and 6000K")
+	err = m.SetLightState(ctx, "light1", BrightnessValue(95))
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+	brightness := 5
+	err = m.Transition(ctx, "light1", StateChange{Brightness: &brightness}, transitionStepInterval*5)
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+	assert.Empty(t, device.updates())
+
+	// Relative adjustments are clamped to the model's range
+	require.NoError(t, m.AdjustLight(ctx, "light1", Adjustment{Brightness: 60}))
+	assert.Equal(t, 90, m.lights["light1"].Brightness)
+
+	require.NoError(t, m.SetLightState(ctx, "light1", TemperatureValue(4000)))
+	assert.Equal(t, 250, m.lights["light1"].Temperature)
+}
+
+func TestSetCapabilities_ReportedHue(t *testing.T) {
+	hue, saturation := 30.0, 50.0
+	light := Light{Hue: &hue, Saturation: &saturation}
+	setCapabilities(&light, Capabilities{Temperature: true})
+	assert.True(t, light.SupportsColor(), "a light showing a hue supports color")
+	assert.Equal(t, ColorModeColor, light.ColorMode)
+}
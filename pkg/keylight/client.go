@@ -7,18 +7,27 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 // LightState represents the state of a Key Light
 type LightState struct {
-	NumberOfLights int `json:"numberOfLights"`
-	Lights         []struct {
-		On          int `json:"on"`
-		Brightness  int `json:"brightness"`
-		Temperature int `json:"temperature"`
-	} `json:"lights"`
+	NumberOfLights int               `json:"numberOfLights"`
+	Lights         []LightStateEntry `json:"lights"`
+}
+
+// LightStateEntry is the state of one light in a LightState. Lights that
+// support color, such as the Light Strip, report hue and saturation instead
+// of a temperature while showing a color.
+type LightStateEntry struct {
+	On          int      `json:"on"`
+	Brightness  int      `json:"brightness"`
+	Temperature int      `json:"temperature,omitempty"`
+	Hue         *float64 `json:"hue,omitempty"`
+	Saturation  *float64 `json:"saturation,omitempty"`
 }
 
 // AccessoryInfo represents the device information
@@ -32,6 +41,10 @@ type AccessoryInfo struct {
 	Features            []string `json:"features"`
 }
 
+// defaultRequestTimeout is how long a request to a light may take when the
+// driver is not given an HTTP client.
+const defaultRequestTimeout = 5 * time.Second
+
 // KeyLightClient handles HTTP communication with a Key Light device
 type KeyLightClient struct {
 	baseURL    string
@@ -48,10 +61,10 @@ func NewKeyLightClient(ip string, port int, logger *slog.Logger, httpClient ...*
 	if len(httpClient) > 0 && httpClient[0] != nil {
 		hc = httpClient[0]
 	} else {
-		hc = &http.Client{Timeout: 5 * time.Second}
+		hc = &http.Client{Timeout: defaultRequestTimeout}
 	}
 	return &KeyLightClient{
-		baseURL:    fmt.Sprintf("http://%s:%d/elgato", ip, port),
+		baseURL:    "http://" + net.JoinHostPort(ip, strconv.Itoa(port)) + "/elgato",
 		httpClient: hc,
 		logger:     logger,
 	}
@@ -66,23 +79,23 @@ func (c *KeyLightClient) doGet(ctx context.Context, path string, result any) err
 	}
 	resp, err := c.httpClient.Do(req) //nolint:gosec // G704: URL is from discovered light address
 	if err != nil {
-		c.logger.Error("light: request failed", "url", url, "error", err)
+		c.logger.ErrorContext(ctx, "light: request failed", "url", url, "error", err)
 		return fmt.Errorf("failed to get %s: %w", path, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-		c.logger.Error("light: request failed", "url", url, "error", err)
+		c.logger.ErrorContext(ctx, "light: request failed", "url", url, "error", err)
 		return err
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		c.logger.Error("light: decode failed", "url", url, "error", err)
+		c.logger.ErrorContext(ctx, "light: decode failed", "url", url, "error", err)
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	c.logger.Debug("light: response", "url", url, "result", result)
+	c.logger.DebugContext(ctx, "light: response", "url", url, "result", result)
 	return nil
 }
 
@@ -113,19 +126,29 @@ func (c *KeyLightClient) SetLightState(ctx context.Context, on bool, brightness,
 		brightness = 100
 	}
 
+	return c.putLights(ctx, LightStateEntry{
+		On:          boolToInt(on),
+		Brightness:  brightness,
+		Temperature: temperature,
+	})
+}
+
+// SetLightColor shows a color on lights that support it, such as the Light
+// Strip. Hue is in degrees (0-360) and saturation a 0-100 percentage.
+func (c *KeyLightClient) SetLightColor(ctx context.Context, on bool, brightness int, hue, saturation float64) error {
+	return c.putLights(ctx, LightStateEntry{
+		On:         boolToInt(on),
+		Brightness: max(3, min(brightness, 100)),
+		Hue:        &hue,
+		Saturation: &saturation,
+	})
+}
+
+// putLights sends a single light's state to the device.
+func (c *KeyLightClient) putLights(ctx context.Context, entry LightStateEntry) error {
 	payload := LightState{
 		NumberOfLights: 1,
-		Lights: []struct {
-			On          int `json:"on"`
-			Brightness  int `json:"brightness"`
-			Temperature int `json:"temperature"`
-		}{
-			{
-				On:          boolToInt(on),
-				Brightness:  brightness,
-				Temperature: temperature,
-			},
-		},
+		Lights:         []LightStateEntry{entry},
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -134,12 +157,7 @@ func (c *KeyLightClient) SetLightState(ctx context.Context, on bool, brightness,
 	}
 
 	url := c.baseURL + "/lights"
-	c.logger.Debug("setting light state",
-		"url", url,
-		"on", on,
-		"brightness", brightness,
-		"mireds", temperature,
-		"payload", string(jsonData))
+	c.logger.DebugContext(ctx, "setting light state", "url", url, "payload", string(jsonData))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -158,6 +176,103 @@ func (c *KeyLightClient) SetLightState(ctx context.Context, on bool, brightness,
 		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	c.logger.Debug("light state updated successfully")
+	c.logger.DebugContext(ctx, "light state updated successfully")
+	return nil
+}
+
+// SetDisplayName changes the name stored on the device, as shown in Elgato
+// Control Center and advertised to other apps.
+func (c *KeyLightClient) SetDisplayName(ctx context.Context, name string) error {
+	c.logger.DebugContext(ctx, "setting display name", "url", c.baseURL+"/accessory-info", "name", name)
+	if err := c.doPut(ctx, "/accessory-info", map[string]string{"displayName": name}); err != nil {
+		return fmt.Errorf("failed to set display name: %w", err)
+	}
+	return nil
+}
+
+// GetDeviceSettings retrieves the light's own settings, such as its power-on
+// behavior.
+func (c *KeyLightClient) GetDeviceSettings(ctx context.Context) (*DeviceSettings, error) {
+	var settings DeviceSettings
+	if err := c.doGet(ctx, "/lights/settings", &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SetDeviceSettings replaces the light's own settings.
+func (c *KeyLightClient) SetDeviceSettings(ctx context.Context, settings DeviceSettings) error {
+	c.logger.DebugContext(ctx, "setting device settings", "url", c.baseURL+"/lights/settings", "settings", settings)
+	if err := c.doPut(ctx, "/lights/settings", settings); err != nil {
+		return fmt.Errorf("failed to set device settings: %w", err)
+	}
 	return nil
 }
+
+// doPut JSON-encodes payload and PUTs it to the given path.
+func (c *KeyLightClient) doPut(ctx context.Context, path string, payload any) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.baseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec // G704: URL is from discovered light address
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// maxRawResponseSize caps how much of a device response RawRequest reads.
+const maxRawResponseSize = 1 << 20
+
+// RawRequest sends a request to an arbitrary path under the device's /elgato
+// API and returns the response as is, whatever its status code. Bodies that
+// are not JSON are returned as a JSON string.
+func (c *KeyLightClient) RawRequest(ctx context.Context, method, path string, body []byte) (*RawResponse, error) {
+	url := c.baseURL + path
+	var bodyReader io.Reader
+	if len(body) > 0 {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.logger.DebugContext(ctx, "light: raw request", "method", method, "url", url, "body", string(body))
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec // G704: URL is from discovered light address
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRawResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	raw := &RawResponse{StatusCode: resp.StatusCode}
+	switch {
+	case len(bytes.TrimSpace(data)) == 0:
+	case json.Valid(data):
+		raw.Body = data
+	default:
+		raw.Body, _ = json.Marshal(string(data))
+	}
+	return raw, nil
+}
@@ -58,6 +58,13 @@ func mockHTTPServer(t *testing.T) *httptest.Server {
 			json.NewEncoder(w).Encode(map[string]any{
 				"success": true,
 			})
+		case r.Method == http.MethodPut && r.URL.Path == "/elgato/accessory-info":
+			var reqBody map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil || reqBody["displayName"] == nil {
+				http.Error(w, "missing displayName", http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
 		default:
 			http.Error(w, "not found", http.StatusNotFound)
 		}
@@ -159,6 +166,19 @@ func TestSetLightState(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestSetDisplayName(t *testing.T) {
+	server := mockHTTPServer(t)
+	defer server.Close()
+
+	client := NewKeyLightClient(server.URL[7:], 0, slog.New(slog.DiscardHandler), server.Client())
+	client.baseURL = server.URL + "/elgato"
+
+	require.NoError(t, client.SetDisplayName(context.Background(), "Desk Left"))
+
+	badClient := NewKeyLightClient("invalid:url", 9123, slog.New(slog.DiscardHandler))
+	assert.Error(t, badClient.SetDisplayName(context.Background(), "Desk Left"))
+}
+
 func TestClientWithServerErrors(t *testing.T) {
 	// Server that always returns 500 error
 	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,125 @@
+package keylight
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestSetLightState_Color(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 50, 200)
+	light := m.lights["light1"]
+	setCapabilities(&light, CapabilitiesFromInfo(&AccessoryInfo{ProductName: "Elgato Light Strip", Features: []string{FeatureLights}}))
+	m.lights["light1"] = light
+	assert.Equal(t, ColorModeTemperature, light.ColorMode)
+	ctx := context.Background()
+
+	// Switching to a color starts from full saturation
+	require.NoError(t, m.SetLightState(ctx, "light1", HueValue(120)))
+	put := device.updates()[0].Lights[0]
+	require.NotNil(t, put.Hue)
+	assert.InDelta(t, 120, *put.Hue, 0.001)
+	assert.InDelta(t, 100, *put.Saturation, 0.001)
+	assert.Zero(t, put.Temperature)
+
+	got := m.GetLights()["light1"]
+	assert.Equal(t, ColorModeColor, got.ColorMode)
+	assert.InDelta(t, 120, *got.Hue, 0.001)
+	assert.Equal(t, 200, got.Temperature, "the last temperature is kept while showing a color")
+
+	// Other properties keep the color
+	require.NoError(t, m.SetLightState(ctx, "light1", SaturationValue(40)))
+	require.NoError(t, m.SetLightState(ctx, "light1", BrightnessValue(80)))
+	put = device.updates()[2].Lights[0]
+	assert.InDelta(t, 120, *put.Hue, 0.001)
+	assert.InDelta(t, 40, *put.Saturation, 0.001)
+	assert.Equal(t, 80, put.Brightness)
+
+	require.NoError(t, m.SetLightState(ctx, "light1", ColorValue{Hue: 240, Saturation: 60}))
+	put = device.updates()[3].Lights[0]
+	assert.InDelta(t, 240, *put.Hue, 0.001)
+	assert.InDelta(t, 60, *put.Saturation, 0.001)
+
+	// A temperature switches back to white
+	require.NoError(t, m.SetLightState(ctx, "light1", TemperatureValue(5000)))
+	put = device.updates()[4].Lights[0]
+	assert.Nil(t, put.Hue)
+	assert.Equal(t, 200, put.Temperature)
+	got = m.GetLights()["light1"]
+	assert.Equal(t, ColorModeTemperature, got.ColorMode)
+	assert.Nil(t, got.Hue)
+}
+
+func TestToggleLight_KeepsColor(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 50, 200)
+	hue, saturation := 120.0, 60.0
+	device.state.Lights[0].Hue, device.state.Lights[0].Saturation = &hue, &saturation
+	light := m.lights["light1"]
+	setCapabilities(&light, CapabilitiesFromInfo(&AccessoryInfo{ProductName: "Elgato Light Strip", Features: []string{FeatureLights}}))
+	m.lights["light1"] = light
+	ctx := context.Background()
+
+	on, err := m.ToggleLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.False(t, on)
+	put := device.updates()[0].Lights[0]
+	require.NotNil(t, put.Hue, "toggling must not switch the strip to white")
+	assert.InDelta(t, 120, *put.Hue, 0.001)
+	assert.InDelta(t, 60, *put.Saturation, 0.001)
+	assert.Zero(t, put.Temperature)
+
+	got := m.GetLights()["light1"]
+	assert.Equal(t, ColorModeColor, got.ColorMode)
+	assert.InDelta(t, 120, *got.Hue, 0.001)
+
+	// Parking at a temperature switches it to white
+	require.NoError(t, m.ParkLights(ctx, ParkState{On: true, Temperature: 5000}))
+	put = device.updates()[1].Lights[0]
+	assert.Nil(t, put.Hue)
+	assert.Equal(t, convertTemperatureToDevice(5000), put.Temperature)
+}
+
+func TestSetLightState_ColorUnsupported(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 50, 200)
+
+	err := m.SetLightState(context.Background(), "light1", HueValue(120))
+	assert.True(t, errors.IsInvalidInput(err))
+	assert.Empty(t, device.updates())
+
+	saturation := 50.0
+	err = m.Transition(context.Background(), "light1", StateChange{Saturation: &saturation}, 0)
+	assert.True(t, errors.IsInvalidInput(err))
+}
+
+func TestColorChange(t *testing.T) {
+	hue, saturation := 30.0, 70.0
+
+	v, ok := ColorChange(&hue, &saturation)
+	require.True(t, ok)
+	assert.Equal(t, ColorValue{Hue: 30, Saturation: 70}, v)
+
+	v, ok = ColorChange(&hue, nil)
+	require.True(t, ok)
+	assert.Equal(t, HueValue(30), v)
+
+	v, ok = ColorChange(nil, &saturation)
+	require.True(t, ok)
+	assert.Equal(t, SaturationValue(70), v)
+
+	_, ok = ColorChange(nil, nil)
+	assert.False(t, ok)
+}
+
+func TestColorValue_Validate(t *testing.T) {
+	assert.NoError(t, HueValue(0).Validate())
+	assert.NoError(t, HueValue(360).Validate())
+	assert.Error(t, HueValue(-1).Validate())
+	assert.Error(t, HueValue(361).Validate())
+	assert.NoError(t, SaturationValue(100).Validate())
+	assert.Error(t, SaturationValue(101).Validate())
+	assert.Error(t, ColorValue{Hue: 10, Saturation: 120}.Validate())
+}
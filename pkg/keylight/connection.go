@@ -0,0 +1,148 @@
+package keylight
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// LightOverride changes how the manager connects to a light, for lights
+// reached through a port-forward, proxy or VPN. Zero fields keep the
+// discovered address and the manager's device timeout.
+type LightOverride struct {
+	// Host is the host name or IP address to connect to instead of the
+	// light's own address.
+	Host string
+	// Port is the port to connect to instead of the light's own port.
+	Port int
+	// Timeout limits each request to the light, replacing both the device
+	// timeout and the retry policy's per-try timeout.
+	Timeout time.Duration
+}
+
+// SetDeviceTimeout sets how long a request to a light may take before it is
+// abandoned. It must be called before discovery starts. Zero leaves it to the
+// driver, which is 5 seconds for the built-in drivers.
+func (m *Manager) SetDeviceTimeout(d time.Duration) {
+	m.deviceTimeout = d
+}
+
+// SetLightOverrides sets per-light connection overrides keyed by light ID. It
+// must be called before discovery starts. Lights keep the address they were
+// discovered at in listings; only requests go to the overridden host and port.
+func (m *Manager) SetLightOverrides(overrides map[string]LightOverride) {
+	m.overrides = overrides
+}
+
+// endpoint is where and how requests to a light are sent.
+type endpoint struct {
+	host    string
+	port    int
+	timeout time.Duration
+	// fallbacks are the light's other addresses, dialled in turn when host
+	// can't be reached.
+	fallbacks []string
+	// log logs each request and response if not nil.
+	log *slog.Logger
+}
+
+// endpoint returns where requests to a light are sent, applying any override
+// for it. A light found at several addresses is reached at the one it
+// answered on, falling back to the others unless its host is overridden.
+func (m *Manager) endpoint(light Light) endpoint {
+	ep := endpoint{host: light.IP.String(), port: light.Port, timeout: m.deviceTimeout}
+	if m.deviceLog != nil {
+		ep.log = m.deviceLog.With("light", light.ID)
+	}
+	o, ok := m.overrides[light.ID]
+	if o.Host == "" {
+		for _, ip := range light.Addresses {
+			if !ip.Equal(light.IP) {
+				ep.fallbacks = append(ep.fallbacks, ip.String())
+			}
+		}
+	}
+	if !ok {
+		return ep
+	}
+	if o.Host != "" {
+		ep.host = o.Host
+	}
+	if o.Port != 0 {
+		ep.port = o.Port
+	}
+	if o.Timeout > 0 {
+		ep.timeout = o.Timeout
+	}
+	return ep
+}
+
+// httpClient returns the HTTP client for requests to the endpoint, or nil if
+// the driver's own client will do.
+func (ep endpoint) httpClient() *http.Client {
+	if ep.timeout <= 0 && len(ep.fallbacks) == 0 && ep.log == nil {
+		return nil
+	}
+	timeout := ep.timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	hc := &http.Client{Timeout: timeout}
+	if len(ep.fallbacks) > 0 {
+		hosts := append([]string{ep.host}, ep.fallbacks...)
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = (&failoverDialer{
+			hosts: hosts,
+			// Leave every address a share of the request's time, so an
+			// address that drops packets doesn't use it all up
+			dialer: net.Dialer{Timeout: timeout / time.Duration(len(hosts))},
+		}).DialContext
+		hc.Transport = transport
+	}
+	if ep.log != nil {
+		next := hc.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		hc.Transport = &deviceLogTransport{next: next, logger: ep.log}
+	}
+	return hc
+}
+
+// failoverDialer dials a light at each of its addresses in turn until one
+// answers, such as its IPv6 address when IPv4 isn't routed to it. The address
+// that last answered is tried first.
+type failoverDialer struct {
+	hosts  []string
+	dialer net.Dialer
+	last   atomic.Int32 // index into hosts of the address that last answered
+}
+
+// DialContext dials the port of addr at each of the light's addresses,
+// ignoring addr's host, and returns the first error if none answer.
+func (d *failoverDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	start := int(d.last.Load())
+	var firstErr error
+	for i := range d.hosts {
+		n := (start + i) % len(d.hosts)
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(d.hosts[n], port))
+		if err == nil {
+			d.last.Store(int32(n)) //nolint:gosec // G115: a light has a handful of addresses
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
@@ -0,0 +1,118 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_LightOverrides(t *testing.T) {
+	srv := mockHTTPServer(t)
+	defer srv.Close()
+	host, port := hostPort(t, srv)
+
+	// The light is discovered at an address that can't be reached directly
+	light := Light{ID: "desk", IP: net.ParseIP("192.0.2.1"), Port: 9123}
+	m := NewManager(discardLogger())
+	m.SetLightOverrides(map[string]LightOverride{"desk": {Host: host, Port: port, Timeout: time.Second}})
+
+	assert.Equal(t, endpoint{host: host, port: port, timeout: time.Second}, m.endpoint(light))
+
+	info, err := m.newClient(light).GetAccessoryInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Elgato Key Light", info.ProductName)
+
+	m.SetRetryPolicy(RetryPolicy{Attempts: 2, Timeout: 50 * time.Millisecond})
+	client, wrapped := m.newClient(light).(*resilientDriver)
+	require.True(t, wrapped)
+	assert.Equal(t, time.Second, client.policy.Timeout, "a light's timeout replaces the per-try timeout")
+
+	other := Light{ID: "shelf", IP: net.ParseIP("192.0.2.2"), Port: 9123}
+	assert.Equal(t, endpoint{host: "192.0.2.2", port: 9123}, m.endpoint(other))
+}
+
+func TestManager_DeviceTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.SetDeviceTimeout(50 * time.Millisecond)
+	start := time.Now()
+	_, err := m.newClient(Light{ID: "slow", IP: net.ParseIP(host), Port: port}).GetAccessoryInfo(context.Background())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+// listenIPv6 starts the mock light on the IPv6 loopback address only,
+// skipping the test where IPv6 isn't available.
+func listenIPv6(t *testing.T) *httptest.Server {
+	t.Helper()
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	mock := mockHTTPServer(t)
+	mock.Close()
+	srv := &httptest.Server{Listener: ln, Config: &http.Server{Handler: mock.Config.Handler}} //nolint:gosec // G112: test server
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestManager_AddressFallback(t *testing.T) {
+	srv := listenIPv6(t)
+	_, port := hostPort(t, srv)
+
+	// Nothing answers on the light's IPv4 address, so requests fall back to
+	// its IPv6 one
+	light := Light{
+		ID:        "desk",
+		IP:        net.ParseIP("127.0.0.1"),
+		Addresses: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		Port:      port,
+	}
+	m := NewManager(discardLogger())
+	ep := m.endpoint(light)
+	assert.Equal(t, []string{"::1"}, ep.fallbacks)
+
+	client := m.newClient(light)
+	for range 2 {
+		info, err := client.GetAccessoryInfo(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "Elgato Key Light", info.ProductName)
+	}
+
+	// A host override is used as it is
+	m.SetLightOverrides(map[string]LightOverride{"desk": {Host: "192.0.2.1"}})
+	assert.Empty(t, m.endpoint(light).fallbacks)
+}
+
+func TestValidateLight_IPv6(t *testing.T) {
+	srv := listenIPv6(t)
+	_, port := hostPort(t, srv)
+
+	entry := &ServiceEntry{
+		Name:  "Elgato Key Light ABC1._elg._tcp.local.",
+		Addrs: []net.IP{net.ParseIP("::1"), net.ParseIP("127.0.0.1")},
+		Port:  port,
+	}
+	assert.Equal(t, []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, entry.addresses(), "IPv4 addresses come first")
+
+	light, ok := validateLight(context.Background(), entry, validationRules{}, discardLogger())
+	require.True(t, ok)
+	assert.True(t, light.IP.Equal(net.ParseIP("::1")), "the light is reached at the address that answered")
+	assert.Len(t, light.Addresses, 2)
+
+	assert.Equal(t, "[::1]:9123", addressID(net.ParseIP("::1"), 9123))
+}
@@ -0,0 +1,107 @@
+package keylight
+
+import (
+	"context"
+	"time"
+)
+
+// Sliders in the tray and desktop extensions send a brightness or temperature
+// update for every step they are dragged through, far faster than a light can
+// apply them. With a debounce window set, the manager sends at most one
+// brightness and one temperature update per light per window: the first
+// update goes out straight away, and the ones that arrive within the window
+// are coalesced so that only the latest is sent when it ends. The updates it
+// replaces are dropped, and their callers get the result of the one sent.
+
+// debounceKey identifies the updates that are coalesced together.
+type debounceKey struct {
+	id       string
+	property PropertyName
+}
+
+// pendingUpdate is the update waiting for the end of a light's debounce
+// window. Guarded by Manager.debounceMu until done is closed.
+type pendingUpdate struct {
+	ctx   context.Context
+	value LightPropertyValue
+	done  chan struct{} // closed once the update has been sent
+	err   error
+}
+
+// SetDebounceWindow sets the shortest time between two brightness or two
+// temperature updates sent to a light; updates arriving faster are coalesced.
+// Zero or less sends every update. It must be called before the manager is
+// used.
+func (m *Manager) SetDebounceWindow(d time.Duration) {
+	m.debounceMu.Lock()
+	defer m.debounceMu.Unlock()
+	m.debounce = max(d, 0)
+}
+
+// debounced reports whether a change is coalesced with the ones around it.
+// Changes made under a version check are not, as they must fail or succeed
+// on their own.
+func (m *Manager) debounced(ctx context.Context, propertyValue LightPropertyValue) bool {
+	switch propertyValue.PropertyName() {
+	case PropertyBrightness, PropertyTemperature:
+	default:
+		return false
+	}
+	m.debounceMu.Lock()
+	window := m.debounce
+	m.debounceMu.Unlock()
+	return window > 0 && versionCheckFrom(ctx) == nil
+}
+
+// setDebounced sets a light's brightness or temperature, sending it now if
+// the light's debounce window has passed and at the end of the window
+// otherwise. An update still waiting to be sent is replaced, and counted as
+// dropped.
+func (m *Manager) setDebounced(ctx context.Context, id string, propertyValue LightPropertyValue) error {
+	key := debounceKey{id: id, property: propertyValue.PropertyName()}
+
+	m.debounceMu.Lock()
+	if m.pending == nil {
+		m.pending = make(map[debounceKey]*pendingUpdate)
+		m.lastSent = make(map[debounceKey]time.Time)
+	}
+	update, waiting := m.pending[key]
+	switch {
+	case waiting:
+		update.ctx, update.value = ctx, propertyValue
+		m.debounceMu.Unlock()
+		if m.metrics != nil {
+			m.metrics.UpdateDropped(id)
+		}
+	case time.Since(m.lastSent[key]) >= m.debounce:
+		m.lastSent[key] = time.Now()
+		m.debounceMu.Unlock()
+		return m.setLightState(ctx, id, propertyValue)
+	default:
+		update = &pendingUpdate{ctx: ctx, value: propertyValue, done: make(chan struct{})}
+		m.pending[key] = update
+		time.AfterFunc(time.Until(m.lastSent[key].Add(m.debounce)), func() { m.flushDebounced(key, update) })
+		m.debounceMu.Unlock()
+	}
+
+	select {
+	case <-update.done:
+		return update.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushDebounced sends an update at the end of its light's debounce window.
+// It is sent even if the caller that made it has gone, as the callers whose
+// updates it replaced have been told it will be.
+func (m *Manager) flushDebounced(key debounceKey, update *pendingUpdate) {
+	m.debounceMu.Lock()
+	delete(m.pending, key)
+	m.lastSent[key] = time.Now()
+	ctx, value := update.ctx, update.value
+	m.debounceMu.Unlock()
+
+	update.err = m.setLightState(context.WithoutCancel(ctx), key.id, value)
+	close(update.done)
+}
@@ -0,0 +1,50 @@
+package keylight
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebounce_CoalescesRapidUpdates(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 10, 200)
+	rec := &countingRecorder{errors: map[string]int{}, dropped: map[string]int{}}
+	m.SetMetrics(rec)
+	m.SetDebounceWindow(200 * time.Millisecond)
+	ctx := context.Background()
+
+	// The first update of a burst goes out straight away
+	require.NoError(t, m.SetLightState(ctx, "light1", BrightnessValue(20)))
+	require.Len(t, device.updates(), 1)
+
+	// The rest arrive within the window and only the latest is sent
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.SetLightState(ctx, "light1", BrightnessValue(30+i))
+		}()
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	puts := device.updates()
+	require.Len(t, puts, 2)
+	assert.Equal(t, 39, puts[1].Lights[0].Brightness)
+	rec.mu.Lock()
+	assert.Equal(t, 9, rec.dropped["light1"])
+	rec.mu.Unlock()
+
+	// Other properties aren't held back
+	require.NoError(t, m.SetLightState(ctx, "light1", OnValue(false)))
+	assert.Len(t, device.updates(), 3)
+}
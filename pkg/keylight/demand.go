@@ -0,0 +1,53 @@
+package keylight
+
+import (
+	"time"
+)
+
+// SetDemand makes discovery, which re-reads every light's accessory info and
+// state, adapt to whether clients are using the daemon. While active reports
+// false, passes run every idleInterval instead of the discovery interval, and
+// they go back to the usual interval once it reports true. A nil active or an
+// idleInterval no longer than the discovery interval keeps the usual interval.
+// It may be called while discovery runs.
+func (m *Manager) SetDemand(active func() bool, idleInterval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.demand = active
+	m.idleInterval = idleInterval
+}
+
+// clientsActive reports whether clients are using the daemon, which is
+// always the case without SetDemand.
+func (m *Manager) clientsActive() bool {
+	m.mu.RLock()
+	active := m.demand
+	m.mu.RUnlock()
+	return active == nil || active()
+}
+
+// discoveryWait returns how long after the last pass the next discovery pass
+// is due, given the usual interval, and records whether clients are idle.
+func (m *Manager) discoveryWait(interval time.Duration) time.Duration {
+	m.mu.RLock()
+	active, idleInterval := m.demand, m.idleInterval
+	m.mu.RUnlock()
+
+	idle := active != nil && idleInterval > interval && !active()
+	m.statsMu.Lock()
+	changed := idle != m.stats.Idle
+	m.stats.Idle = idle
+	m.statsMu.Unlock()
+
+	if changed {
+		if idle {
+			m.logger.Info("light: no active clients, slowing discovery", "interval", idleInterval)
+		} else {
+			m.logger.Info("light: clients active, resuming discovery interval", "interval", interval)
+		}
+	}
+	if idle {
+		return idleInterval
+	}
+	return interval
+}
@@ -0,0 +1,27 @@
+package keylight
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_DiscoveryWait(t *testing.T) {
+	m := NewManager(discardLogger())
+	assert.Equal(t, 30*time.Second, m.discoveryWait(30*time.Second), "without demand the interval is kept")
+
+	active := false
+	m.SetDemand(func() bool { return active }, 2*time.Minute)
+	assert.Equal(t, 2*time.Minute, m.discoveryWait(30*time.Second))
+	assert.True(t, m.DiscoveryStats().Idle)
+
+	active = true
+	assert.Equal(t, 30*time.Second, m.discoveryWait(30*time.Second))
+	assert.False(t, m.DiscoveryStats().Idle)
+
+	// An idle interval no longer than the interval doesn't slow discovery
+	active = false
+	assert.Equal(t, 5*time.Minute, m.discoveryWait(5*time.Minute))
+	assert.False(t, m.DiscoveryStats().Idle)
+}
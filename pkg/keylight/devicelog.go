@@ -0,0 +1,107 @@
+package keylight
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// maxLoggedBody is the most of a request or response body logged for a
+// device interaction.
+const maxLoggedBody = 512
+
+// redactedValue replaces identifying values in logged bodies.
+const redactedValue = "REDACTED"
+
+// identifyingFields matches the JSON fields of light responses that identify
+// the device it came from: Elgato serial and MAC addresses, and WLED's MAC.
+var identifyingFields = regexp.MustCompile(`("(?:serialNumber|macAddress|mac)"\s*:\s*)"[^"]*"`)
+
+// SetDeviceLogging logs every request to a light and its response: the
+// method, path, status, duration and the start of each body, tagged with
+// component=device so they can be filtered. Interactions are logged at info;
+// serial numbers and MAC addresses are redacted from the bodies unless debug
+// logging is enabled for them. It must be called before discovery starts.
+func (m *Manager) SetDeviceLogging(enabled bool) {
+	if enabled {
+		m.deviceLog = m.logger.With("component", "device")
+	} else {
+		m.deviceLog = nil
+	}
+}
+
+// deviceLogTransport logs the requests sent through it to a light.
+type deviceLogTransport struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+// RoundTrip sends the request and logs it with its response, leaving both
+// bodies readable by the caller.
+func (t *deviceLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("host", req.URL.Host),
+		slog.String("path", req.URL.Path),
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			_ = body.Close()
+			if len(data) > 0 {
+				attrs = append(attrs, slog.String("request", t.loggedBody(ctx, data)))
+			}
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	attrs = append(attrs, slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		t.logger.LogAttrs(ctx, slog.LevelInfo, "device request failed", attrs...)
+		return nil, err
+	}
+
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	data, readErr := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	// Hand the body on as read, along with any error reading the rest of it
+	resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{readErr}))
+	if len(data) > 0 {
+		attrs = append(attrs, slog.String("response", t.loggedBody(ctx, data)))
+	}
+	if readErr != nil {
+		attrs = append(attrs, slog.String("error", readErr.Error()))
+	}
+	t.logger.LogAttrs(ctx, slog.LevelInfo, "device request", attrs...)
+	return resp, nil
+}
+
+// loggedBody returns a body as it is logged: with identifying fields
+// redacted unless debug logging is enabled, and cut to maxLoggedBody bytes.
+func (t *deviceLogTransport) loggedBody(ctx context.Context, data []byte) string {
+	if !t.logger.Enabled(ctx, slog.LevelDebug) {
+		data = identifyingFields.ReplaceAll(data, []byte(`$1"`+redactedValue+`"`))
+	}
+	if len(data) > maxLoggedBody {
+		return string(data[:maxLoggedBody]) + "... (" + strconv.Itoa(len(data)) + " bytes)"
+	}
+	return string(data)
+}
+
+// errReader returns err, or io.EOF if it is nil, from every read.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
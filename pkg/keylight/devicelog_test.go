@@ -0,0 +1,87 @@
+package keylight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deviceLogLines returns the JSON lines logged by a manager logging device
+// requests at level after fetching a light's info and changing its state.
+func deviceLogLines(t *testing.T, level slog.Level) []map[string]any {
+	t.Helper()
+	srv := mockHTTPServer(t)
+	defer srv.Close()
+	host, port := hostPort(t, srv)
+
+	var out bytes.Buffer
+	m := NewManager(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: level})))
+	m.SetDeviceLogging(true)
+	client := m.newClient(Light{ID: "desk", IP: net.ParseIP(host), Port: port})
+
+	info, err := client.GetAccessoryInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "KL12345678", info.SerialNumber, "the caller gets the body unredacted")
+	require.NoError(t, client.SetLightState(context.Background(), true, 50, 200))
+
+	var lines []map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(out.String()), "\n") {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		if rec["component"] == "device" {
+			lines = append(lines, rec)
+		}
+	}
+	return lines
+}
+
+func TestManager_DeviceLogging(t *testing.T) {
+	lines := deviceLogLines(t, slog.LevelInfo)
+	require.GreaterOrEqual(t, len(lines), 2)
+
+	get := lines[0]
+	assert.Equal(t, "INFO", get["level"])
+	assert.Equal(t, "device request", get["msg"])
+	assert.Equal(t, "desk", get["light"])
+	assert.Equal(t, "GET", get["method"])
+	assert.Equal(t, "/elgato/accessory-info", get["path"])
+	assert.EqualValues(t, 200, get["status"])
+	assert.Contains(t, get, "duration_ms")
+	assert.Contains(t, get["response"], `"serialNumber":"REDACTED"`)
+	assert.NotContains(t, get["response"], "KL12345678")
+
+	put := lines[len(lines)-1]
+	assert.Equal(t, "PUT", put["method"])
+	assert.Contains(t, put["request"], `"on":1`)
+}
+
+func TestManager_DeviceLogging_Debug(t *testing.T) {
+	lines := deviceLogLines(t, slog.LevelDebug)
+	require.NotEmpty(t, lines)
+	assert.Contains(t, lines[0]["response"], "KL12345678", "serial numbers are kept when debugging")
+}
+
+func TestManager_DeviceLogging_Disabled(t *testing.T) {
+	m := NewManager(discardLogger())
+	m.SetDeviceLogging(false)
+	light := Light{ID: "desk", IP: net.ParseIP("192.0.2.1"), Port: 9123}
+	assert.Nil(t, m.endpoint(light).httpClient())
+}
+
+func TestDeviceLogTransport_LoggedBody(t *testing.T) {
+	tr := &deviceLogTransport{logger: discardLogger()}
+	body := tr.loggedBody(context.Background(), []byte(`{"serialNumber": "BW33J1A02345", "macAddress":"3C:6A:9D:00:00:01","mac":"3c6a9d000001"}`))
+	assert.Equal(t, `{"serialNumber": "REDACTED", "macAddress":"REDACTED","mac":"REDACTED"}`, body)
+
+	long := bytes.Repeat([]byte("a"), maxLoggedBody+10)
+	body = tr.loggedBody(context.Background(), long)
+	assert.True(t, strings.HasSuffix(body, "... (522 bytes)"))
+	assert.Len(t, body, maxLoggedBody+len("... (522 bytes)"))
+}
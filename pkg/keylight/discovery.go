@@ -2,15 +2,14 @@ package keylight
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"log/slog"
 
-	"github.com/grandcat/zeroconf"
-
 	"github.com/jmylchreest/keylightd/internal/errors"
 )
 
@@ -20,16 +19,18 @@ const (
 )
 
 var (
-	// Default service names to discover
-	serviceNames = []string{
-		"_elg._tcp", // Elgato Key Light
-	}
-
 	// validProductNames contains all valid Elgato Key Light product names
 	validProductNames = []string{
 		"Elgato Key Light",
 		"Elgato Key Light Air",
 		"Elgato Key Light MK.2",
+		"Elgato Ring Light",
+		"Elgato Light Strip",
+	}
+
+	// colorProductNames contains the Elgato products that can show a color
+	colorProductNames = []string{
+		"Elgato Light Strip",
 	}
 
 	// Discovery parameters - tuned for reliability across platforms.
@@ -41,6 +42,15 @@ var (
 		browseDelay:          500 * time.Millisecond,
 		validateTimeout:      5 * time.Second,
 	}
+
+	// scanDiscoveryParams are used by DiscoverNow, which browses once so
+	// the caller gets an answer quickly.
+	scanDiscoveryParams = DiscoveryParams{
+		browseAttempts:       1,
+		initialBrowseTimeout: defaultDiscoveryParams.initialBrowseTimeout,
+		browseDelay:          defaultDiscoveryParams.browseDelay,
+		validateTimeout:      defaultDiscoveryParams.validateTimeout,
+	}
 )
 
 // No helper functions needed, using slices.Contains directly
@@ -69,12 +79,27 @@ func (d DiscoveryParams) calculateMaxDiscoveryTime() time.Duration {
 	return total
 }
 
-// ServiceEntry represents a discovered mDNS service entry
+// DiscoveryStats summarises the discovery passes a Manager has run.
+type DiscoveryStats struct {
+	Completed      bool      `json:"completed"`       // the first pass has finished
+	Runs           int       `json:"runs"`            // passes finished
+	BrowseAttempts int       `json:"browse_attempts"` // browse attempts across all passes
+	LastRun        time.Time `json:"last_run,omitzero"`
+	LastDurationMS int64     `json:"last_duration_ms"`
+	LastError      string    `json:"last_error,omitempty"`
+	Idle           bool      `json:"idle"` // passes run at the idle interval as no clients are active, see SetDemand
+}
+
+// ServiceEntry is a candidate light found by a discovery backend. Entries
+// found by address alone, such as by SSDP, have no Name.
 type ServiceEntry struct {
 	Name   string
 	AddrV4 net.IP
+	Addrs  []net.IP // every address the entry was advertised at, including AddrV4
 	Port   int
 	Info   string
+	Driver string // driver handling the advertised service; empty means Elgato
+	Source string // backend, and for mDNS the interface, the entry was found by
 }
 
 // DiscoverLights discovers Key Light devices on the network periodically.
@@ -123,161 +148,238 @@ func (m *Manager) DiscoverLights(ctx context.Context, interval time.Duration) er
 			"minInterval", minInterval)
 	}
 
-	// Create a ticker for periodic discovery
+	// The ticker wakes the loop every interval; while clients are idle,
+	// passes are skipped until the idle interval has passed, so that a pass
+	// runs soon after clients come back.
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	discover := func() error {
-		for i := range params.browseAttempts {
-			attempt := i + 1 // convert to 1-based for logging
-
-			// If we already have lights from a previous attempt, skip retries
-			if attempt > 1 {
-				if len(m.GetLights()) > 0 {
-					m.logger.Debug("Skipping retry, lights already discovered",
-						"attempt", attempt,
-						"lightCount", len(m.GetLights()))
-					return nil
-				}
-				m.logger.Debug("Starting retry attempt", "attempt", attempt)
-				time.Sleep(params.browseDelay)
-			}
-
-			timeout := params.initialBrowseTimeout * time.Duration(1<<uint(i))
-			discoverCtx, cancel := context.WithTimeout(ctx, timeout)
+	last := time.Now()
+	err := m.runDiscovery(ctx, params)
+	m.discovered.Store(true)
+	if err != nil {
+		return errors.LogErrorAndReturn(
+			m.logger,
+			err,
+			"initial discovery failed",
+		)
+	}
 
-			entries := make(chan *zeroconf.ServiceEntry, 10)
-			resolver, err := zeroconf.NewResolver(nil)
-			if err != nil {
-				cancel()
-				return errors.LogErrorAndReturn(
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			// Allow for ticks arriving a little early
+			if time.Since(last) < m.discoveryWait(interval)-interval/2 {
+				continue
+			}
+			last = time.Now()
+			if err := m.runDiscovery(ctx, params); err != nil {
+				_ = errors.LogErrorAndReturn(
 					m.logger,
-					errors.Internalf("failed to create zeroconf resolver: %w", err),
-					"discovery resolver creation failed",
-					"attempt", attempt,
+					err,
+					"light: stopping discovery",
 				)
 			}
+		}
+	}
+}
 
-			entriesDone := make(chan struct{})
-			go func() {
-				defer close(entriesDone)
-				for entry := range entries {
-					m.logger.Debug("zeroconf: received entry",
-						"instance", entry.Instance,
-						"service", entry.Service,
-						"addrIPv4", entry.AddrIPv4,
-						"addrIPv6", entry.AddrIPv6,
-						"port", entry.Port,
-						"text", entry.Text,
-						"attempt", attempt)
+// runDiscovery runs a discovery pass, once any pass already running has
+// finished, and records it in the stats.
+func (m *Manager) runDiscovery(ctx context.Context, params DiscoveryParams) error {
+	select {
+	case m.discovering <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-m.discovering }()
+
+	start := time.Now()
+	err := m.discover(ctx, params)
+	m.statsMu.Lock()
+	m.stats.Runs++
+	m.stats.LastRun = start
+	m.stats.LastDurationMS = time.Since(start).Milliseconds()
+	m.stats.LastError = ""
+	if err != nil {
+		m.stats.LastError = err.Error()
+	}
+	m.statsMu.Unlock()
+	return err
+}
 
-					if !slices.Contains(serviceNames, entry.Service) {
-						continue
-					}
+// discover probes the static lights and browses with every discovery
+// backend, retrying with longer timeouts while no lights have been found.
+func (m *Manager) discover(ctx context.Context, params DiscoveryParams) error {
+	// Static lights are not advertised, so re-probe them directly
+	m.probeStaticLights(ctx)
 
-					var ipv4 net.IP
-					if len(entry.AddrIPv4) > 0 {
-						ipv4 = entry.AddrIPv4[0]
-					}
-					localEntry := &ServiceEntry{
-						Name:   entry.Instance + "." + entry.Service + "." + entry.Domain,
-						AddrV4: ipv4,
-						Port:   entry.Port,
-						Info:   fmt.Sprint(entry.Text),
-					}
+	backends := m.discoveryBackends()
+	for i := range params.browseAttempts {
+		attempt := i + 1 // convert to 1-based for logging
 
-					// Use the parent ctx for validation, NOT discoverCtx.
-					//nolint:misspell // British spelling intentional
-					// This ensures that cancelling the browse timeout does not
-					// kill in-flight HTTP validation requests for other lights.
-					validateCtx, validateCancel := context.WithTimeout(ctx, params.validateTimeout)
-					light, valid := validateLight(validateCtx, localEntry, m.logger)
-					validateCancel()
+		// If we already have lights from a previous attempt, skip retries
+		if attempt > 1 {
+			if count := m.discoveredCount(); count > 0 {
+				m.logger.Debug("Skipping retry, lights already discovered",
+					"attempt", attempt,
+					"lightCount", count)
+				return nil
+			}
+			m.logger.Debug("Starting retry attempt", "attempt", attempt)
+			time.Sleep(params.browseDelay)
+		}
 
+		m.statsMu.Lock()
+		m.stats.BrowseAttempts++
+		m.statsMu.Unlock()
+		if m.metrics != nil {
+			m.metrics.DiscoveryAttempt()
+		}
+
+		timeout := params.initialBrowseTimeout * time.Duration(1<<uint(i))
+		discoverCtx, cancel := context.WithTimeout(ctx, timeout)
+
+		entries := make(chan *ServiceEntry, 10)
+		received := make(map[string]int) // entries per backend and interface
+		found := make(map[string]int)    // validated lights per backend and interface
+
+		entriesDone := make(chan struct{})
+		go func() {
+			defer close(entriesDone)
+			for entry := range entries {
+				received[entry.Source]++
+
+				// Use the parent ctx for validation, NOT discoverCtx.
+				//nolint:misspell // British spelling intentional
+				// This ensures that cancelling the browse timeout does not
+				// kill in-flight HTTP validation requests for other lights.
+				validateCtx, validateCancel := context.WithTimeout(ctx, params.validateTimeout)
+				m.mu.RLock()
+				rules := m.validation
+				m.mu.RUnlock()
+				light, valid := validateLight(validateCtx, entry, rules, m.logger)
+				validateCancel()
+
+				if !valid && light.ID != "" {
+					// The device answered but isn't a light the rules accept
+					valid = m.isAdopted(light.ID)
 					if !valid {
-						m.logger.Debug("zeroconf: entry did not validate as key light",
-							"instance", entry.Instance,
-							"addrIPv4", entry.AddrIPv4,
-							"port", entry.Port,
-							"attempt", attempt)
-						continue
+						m.recordPendingDevice(light, entry.Source)
 					}
-					m.logger.Debug("light: validated Light",
-						"name", light.Name,
-						"id", light.ID,
-						"addr", light.IP,
-						"port", light.Port,
-						"attempt", attempt)
-					m.AddLight(ctx, light)
 				}
-			}()
-
-			// Browse for each service name
-			for _, serviceName := range serviceNames {
-				err = resolver.Browse(discoverCtx, serviceName, domain, entries)
-				if err != nil {
-					_ = errors.LogErrorAndReturn(
-						m.logger,
-						err,
-						"Browse attempt failed",
-						"attempt", attempt,
-						"service", serviceName,
-					)
+				if !valid {
+					m.logger.Debug("discovery: entry did not validate as a supported light",
+						"name", entry.Name,
+						"driver", entry.Driver,
+						"addrIPv4", entry.AddrV4,
+						"port", entry.Port,
+						"source", entry.Source,
+						"attempt", attempt)
+					continue
 				}
+				m.logger.Debug("light: validated Light",
+					"name", light.Name,
+					"id", light.ID,
+					"addr", light.IP,
+					"port", light.Port,
+					"source", entry.Source,
+					"attempt", attempt)
+				found[entry.Source]++
+				m.addDiscoveredLight(ctx, light)
 			}
+		}()
 
-			// Wait for the browse timeout to expire. The zeroconf library
-			//nolint:misspell // British spelling intentional
-			// closes the entries channel when discoverCtx is cancelled,
-			// which causes the entries goroutine to drain and exit.
-			<-discoverCtx.Done()
-			cancel()
+		// Browse with every backend at once; each returns when
+		// discoverCtx is done, or straight away if it cannot browse.
+		errs := make([]error, len(backends))
+		var browsers sync.WaitGroup
+		for j, backend := range backends {
+			browsers.Go(func() {
+				errs[j] = backend.Browse(discoverCtx, entries)
+			})
+		}
+		browsers.Wait()
+		cancel()
+		close(entries)
 
-			// Wait for all entry processing (including HTTP validations) to complete
-			<-entriesDone
+		// Wait for all entry processing (including HTTP validations) to complete
+		<-entriesDone
 
-			m.logger.Debug("Browse attempt completed",
-				"attempt", attempt,
-				"timeout", timeout,
-				"lightsFound", len(m.GetLights()))
+		failed := 0
+		for j, err := range errs {
+			if err != nil {
+				failed++
+				m.logger.Warn("discovery: backend failed", "backend", backends[j].Name(), "attempt", attempt, "error", err)
+			}
+		}
+		if failed == len(backends) && failed > 0 {
+			return errs[0]
 		}
 
-		return nil
+		for source, count := range received {
+			m.logger.Debug("Browse attempt completed for source",
+				"attempt", attempt,
+				"source", source,
+				"entries", count,
+				"lightsFound", found[source])
+		}
+		m.logger.Debug("Browse attempt completed",
+			"attempt", attempt,
+			"timeout", timeout,
+			"lightsFound", len(m.GetLights()))
 	}
 
-	if err := discover(); err != nil {
-		return errors.LogErrorAndReturn(
-			m.logger,
-			err,
-			"initial discovery failed",
-		)
-	}
+	return nil
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if err := discover(); err != nil {
-				_ = errors.LogErrorAndReturn(
-					m.logger,
-					err,
-					"light: stopping discovery",
-				)
-			}
+// DiscoverNow runs a single-attempt discovery pass straight away, after any
+// pass already running, instead of waiting for the next interval. It returns
+// the lights that weren't known when it was called.
+func (m *Manager) DiscoverNow(ctx context.Context) ([]*Light, error) {
+	known := m.GetLights()
+	if err := m.runDiscovery(ctx, scanDiscoveryParams); err != nil {
+		return nil, err
+	}
+	var found []*Light
+	for id, light := range m.GetLights() {
+		if _, ok := known[id]; !ok {
+			found = append(found, light)
 		}
 	}
+	slices.SortFunc(found, func(a, b *Light) int { return strings.Compare(a.ID, b.ID) })
+	return found, nil
+}
+
+// DiscoveryCompleted reports whether the first discovery pass has finished,
+// successfully or not.
+func (m *Manager) DiscoveryCompleted() bool {
+	return m.discovered.Load()
+}
+
+// DiscoveryStats returns statistics about the discovery passes run so far.
+func (m *Manager) DiscoveryStats() DiscoveryStats {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	stats := m.stats
+	stats.Completed = m.discovered.Load()
+	return stats
 }
 
-// validateLight checks if the mDNS entry is a valid Elgato Key Light by querying /elgato/accessory-info
-func validateLight(ctx context.Context, entry *ServiceEntry, logger *slog.Logger) (Light, bool) {
+// validateLight checks if the discovered entry is a supported light by querying its accessory info
+// through the entry's driver and checking it against rules. When the device answers but rules
+// don't accept it, the light it describes is returned with false, so it can be offered for adoption.
+func validateLight(ctx context.Context, entry *ServiceEntry, rules validationRules, logger *slog.Logger) (Light, bool) {
 	if entry == nil {
 		if logger != nil {
 			logger.Debug("validateLight: skipping nil service entry")
 		}
 		return Light{}, false
 	}
-	if entry.AddrV4 == nil || entry.Port == 0 {
+	addrs := entry.addresses()
+	if len(addrs) == 0 || entry.Port == 0 {
 		if logger != nil {
 			logger.Debug("validateLight: skipping invalid service entry",
 				"name", entry.Name,
@@ -287,34 +389,48 @@ func validateLight(ctx context.Context, entry *ServiceEntry, logger *slog.Logger
 		return Light{}, false
 	}
 
-	client := NewKeyLightClient(entry.AddrV4.String(), entry.Port, logger)
-	info, err := client.GetAccessoryInfo(ctx)
+	spec, ok := lookupDriver(entry.Driver)
+	if !ok {
+		if logger != nil {
+			logger.Debug("validateLight: unknown driver", "name", entry.Name, "driver", entry.Driver)
+		}
+		return Light{}, false
+	}
+	// Try each address in turn, IPv4 first, keeping the first that answers
+	var (
+		info *AccessoryInfo
+		addr net.IP
+		err  error
+	)
+	for _, addr = range addrs {
+		info, err = spec.factory(addr.String(), entry.Port, logger).GetAccessoryInfo(ctx)
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+	}
 	if err != nil {
 		if logger != nil {
 			_ = errors.LogErrorAndReturn(
 				logger,
 				errors.DeviceUnavailablef("failed to get accessory info: %w", err),
 				"validateLight: failed to get accessory info",
-				"ip", entry.AddrV4,
+				"ip", addr,
+				"addresses", addrs,
 				"port", entry.Port,
 			)
 		}
 		return Light{}, false
 	}
-	if !slices.Contains(validProductNames, info.ProductName) {
-		if logger != nil {
-			logger.Debug("validateLight: discovered device is not a valid Elgato Key Light",
-				"productName", info.ProductName,
-				"name", entry.Name,
-				"addr", entry.AddrV4)
-		}
-		return Light{}, false
-	}
 	// Build the Light struct with info
+	id := UnescapeRFC6763Label(entry.Name)
+	if id == "" {
+		id = addressID(addr, entry.Port)
+	}
 	light := Light{
-		ID:                UnescapeRFC6763Label(entry.Name),
-		IP:                entry.AddrV4,
+		ID:                id,
+		IP:                addr,
 		Port:              entry.Port,
+		Driver:            entry.Driver,
 		ProductName:       info.ProductName,
 		HardwareBoardType: info.HardwareBoardType,
 		FirmwareVersion:   info.FirmwareVersion,
@@ -322,5 +438,44 @@ func validateLight(ctx context.Context, entry *ServiceEntry, logger *slog.Logger
 		SerialNumber:      info.SerialNumber,
 		Name:              UnescapeRFC6763Label(info.DisplayName),
 	}
+	if len(addrs) > 1 {
+		light.Addresses = addrs
+	}
+	setCapabilities(&light, CapabilitiesFromInfo(info))
+	if spec.accepts != nil && !spec.accepts(info, rules) {
+		if logger != nil {
+			logger.Debug("validateLight: discovered device is not a supported light",
+				"productName", info.ProductName,
+				"features", info.Features,
+				"driver", entry.Driver,
+				"name", entry.Name,
+				"addr", addr,
+				"hint", "adopt it, or add its product name to config.discovery.validation, if it is a light")
+		}
+		return light, false
+	}
 	return light, true
 }
+
+// addresses returns the entry's addresses with IPv4 ones first, so lights
+// are reached over IPv4 where both are advertised.
+func (e *ServiceEntry) addresses() []net.IP {
+	addrs := make([]net.IP, 0, len(e.Addrs)+1)
+	seen := func(ip net.IP) bool {
+		return slices.ContainsFunc(addrs, ip.Equal)
+	}
+	if e.AddrV4 != nil {
+		addrs = append(addrs, e.AddrV4)
+	}
+	for _, ip := range e.Addrs {
+		if ip.To4() != nil && !seen(ip) {
+			addrs = append(addrs, ip)
+		}
+	}
+	for _, ip := range e.Addrs {
+		if ip.To4() == nil && !seen(ip) {
+			addrs = append(addrs, ip)
+		}
+	}
+	return addrs
+}
@@ -55,9 +55,9 @@ func newInvalidProductHandler() http.Handler {
 		if r.URL.Path == "/elgato/accessory-info" {
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(AccessoryInfo{
-				ProductName:  "Elgato Ring Light",
-				SerialNumber: "SN-RING",
-				DisplayName:  "My Ring Light",
+				ProductName:  "Elgato Wave:3",
+				SerialNumber: "SN-WAVE",
+				DisplayName:  "My Wave",
 			})
 			return
 		}
@@ -108,7 +108,7 @@ func TestValidateLight_ValidKeyLight(t *testing.T) {
 	defer server.Close()
 
 	entry := makeServiceEntry(t, server, "test._elg._tcp.local.")
-	light, valid := validateLight(context.Background(), entry, discardLogger())
+	light, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
 
 	assert.True(t, valid)
 	assert.Equal(t, "Elgato Key Light", light.ProductName)
@@ -122,7 +122,7 @@ func TestValidateLight_ValidKeyLightMK2(t *testing.T) {
 	defer server.Close()
 
 	entry := makeServiceEntry(t, server, "testmk2._elg._tcp.local.")
-	light, valid := validateLight(context.Background(), entry, discardLogger())
+	light, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
 
 	assert.True(t, valid)
 	assert.Equal(t, "Elgato Key Light MK.2", light.ProductName)
@@ -136,10 +136,32 @@ func TestValidateLight_InvalidProduct(t *testing.T) {
 	server := httptest.NewServer(newInvalidProductHandler())
 	defer server.Close()
 
-	entry := makeServiceEntry(t, server, "ring._elg._tcp.local.")
-	_, valid := validateLight(context.Background(), entry, discardLogger())
+	entry := makeServiceEntry(t, server, "wave._elg._tcp.local.")
+	_, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
 
-	assert.False(t, valid, "non-Key-Light product should not validate")
+	assert.False(t, valid, "non-light product should not validate")
+}
+
+func TestValidateLight_LightStripAndRingLight(t *testing.T) {
+	for _, tt := range []struct {
+		product   string
+		boardType int
+		color     bool
+	}{
+		{"Elgato Light Strip", 70, true},
+		{"Elgato Ring Light", 75, false},
+	} {
+		server := httptest.NewServer(newAccessoryInfoHandler(tt.product, tt.boardType, "Test "+tt.product, 0))
+		entry := makeServiceEntry(t, server, "test._elg._tcp.local.")
+		light, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
+		server.Close()
+
+		assert.True(t, valid, tt.product)
+		assert.Equal(t, tt.product, light.ProductName)
+		require.NotNil(t, light.Capabilities, tt.product)
+		assert.Equal(t, tt.color, light.Capabilities.Color, tt.product)
+		assert.True(t, light.Capabilities.Temperature, tt.product)
+	}
 }
 
 func TestValidateLight_ServerError(t *testing.T) {
@@ -147,25 +169,25 @@ func TestValidateLight_ServerError(t *testing.T) {
 	defer server.Close()
 
 	entry := makeServiceEntry(t, server, "error._elg._tcp.local.")
-	_, valid := validateLight(context.Background(), entry, discardLogger())
+	_, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
 
 	assert.False(t, valid, "server error should cause validation failure")
 }
 
 func TestValidateLight_NilEntry(t *testing.T) {
-	_, valid := validateLight(context.Background(), nil, discardLogger())
+	_, valid := validateLight(context.Background(), nil, validationRules{}, discardLogger())
 	assert.False(t, valid)
 }
 
 func TestValidateLight_MissingAddr(t *testing.T) {
 	entry := &ServiceEntry{Name: "test", AddrV4: nil, Port: 9123}
-	_, valid := validateLight(context.Background(), entry, discardLogger())
+	_, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
 	assert.False(t, valid)
 }
 
 func TestValidateLight_ZeroPort(t *testing.T) {
 	entry := &ServiceEntry{Name: "test", AddrV4: net.ParseIP("127.0.0.1"), Port: 0}
-	_, valid := validateLight(context.Background(), entry, discardLogger())
+	_, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
 	assert.False(t, valid)
 }
 
@@ -180,7 +202,7 @@ func TestValidateLight_ContextCancelled(t *testing.T) {
 	cancel() // Cancel immediately
 
 	entry := makeServiceEntry(t, server, "slow._elg._tcp.local.")
-	_, valid := validateLight(ctx, entry, discardLogger())
+	_, valid := validateLight(ctx, entry, validationRules{}, discardLogger())
 
 	assert.False(t, valid, "cancelled context should cause validation failure") //nolint:misspell
 }
@@ -194,7 +216,7 @@ func TestValidateLight_ContextTimeout(t *testing.T) {
 	defer cancel()
 
 	entry := makeServiceEntry(t, server, "slow._elg._tcp.local.")
-	_, valid := validateLight(ctx, entry, discardLogger())
+	_, valid := validateLight(ctx, entry, validationRules{}, discardLogger())
 
 	assert.False(t, valid, "timed-out context should cause validation failure")
 }
@@ -260,7 +282,7 @@ func TestValidateLight_IndependentContexts(t *testing.T) {
 	defer cancel2()
 
 	// Validate light 1
-	light1, valid1 := validateLight(ctx1, entry1, discardLogger())
+	light1, valid1 := validateLight(ctx1, entry1, validationRules{}, discardLogger())
 	assert.True(t, valid1)
 	assert.Equal(t, "Light 1", light1.Name)
 
@@ -268,7 +290,7 @@ func TestValidateLight_IndependentContexts(t *testing.T) {
 	cancel1()
 
 	// Validate light 2 — should still succeed
-	light2, valid2 := validateLight(ctx2, entry2, discardLogger())
+	light2, valid2 := validateLight(ctx2, entry2, validationRules{}, discardLogger())
 	assert.True(t, valid2, "cancelling ctx1 must not affect ctx2 validation") //nolint:misspell
 	assert.Equal(t, "Light 2", light2.Name)
 
@@ -295,13 +317,40 @@ func TestValidateLight_SharedContextCancelsAll(t *testing.T) {
 	sharedCtx, sharedCancel := context.WithCancel(context.Background())
 
 	// Validate light 1 with shared context, then cancel
-	_, valid1 := validateLight(sharedCtx, entry1, discardLogger())
+	_, valid1 := validateLight(sharedCtx, entry1, validationRules{}, discardLogger())
 	assert.True(t, valid1, "first validation should succeed before cancel")
 
 	sharedCancel()
 
 	//nolint:misspell // British spelling intentional
 	// With the shared context cancelled, second validation should fail
-	_, valid2 := validateLight(sharedCtx, entry2, discardLogger())
+	_, valid2 := validateLight(sharedCtx, entry2, validationRules{}, discardLogger())
 	assert.False(t, valid2, "second validation should fail with cancelled shared context") //nolint:misspell
 }
+
+func TestBrowseTargets(t *testing.T) {
+	m := NewManager(discardLogger())
+
+	targets := m.browseTargets()
+	require.Len(t, targets, 1)
+	assert.Equal(t, defaultInterfaceName, targets[0].name)
+	assert.Nil(t, targets[0].options())
+
+	m.SetDiscoveryInterfaces([]string{"does-not-exist0"})
+	assert.Empty(t, m.browseTargets())
+
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagMulticast == 0 {
+			continue
+		}
+		m.SetDiscoveryInterfaces([]string{"does-not-exist0", iface.Name})
+		targets = m.browseTargets()
+		require.Len(t, targets, 1)
+		assert.Equal(t, iface.Name, targets[0].name)
+		assert.Len(t, targets[0].options(), 1)
+		return
+	}
+	t.Log("no multicast-capable interface available, skipping named interface check")
+}
@@ -0,0 +1,183 @@
+package keylight
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// Driver names identify the protocol used to talk to a light.
+const (
+	// DriverElgato is the Elgato Key Light HTTP API. It is the default when a light has no driver set.
+	DriverElgato = "elgato"
+	// DriverWLED is the WLED JSON API.
+	DriverWLED = "wled"
+)
+
+// LightDriver is the device protocol the Manager uses to control a light.
+//
+// Temperatures passed to and returned from a driver are in device mireds (143-344);
+// drivers for hardware with a different native scale convert internally.
+// Brightness is always a 0-100 percentage.
+type LightDriver interface {
+	GetAccessoryInfo(ctx context.Context) (*AccessoryInfo, error)
+	GetLightState(ctx context.Context) (*LightState, error)
+	SetLightState(ctx context.Context, on bool, brightness, temperature int) error
+}
+
+// DisplayNameSetter is implemented by drivers that can change the name stored
+// on the device.
+type DisplayNameSetter interface {
+	SetDisplayName(ctx context.Context, name string) error
+}
+
+// ColorSetter is implemented by drivers that can show a color on lights that
+// support it. Hue is in degrees (0-360) and saturation a 0-100 percentage.
+type ColorSetter interface {
+	SetLightColor(ctx context.Context, on bool, brightness int, hue, saturation float64) error
+}
+
+// RawRequester is implemented by drivers that can pass requests straight
+// through to the device's own API.
+type RawRequester interface {
+	RawRequest(ctx context.Context, method, path string, body []byte) (*RawResponse, error)
+}
+
+// SettingsManager is implemented by drivers that can read and change the
+// device's own settings, such as what the light does after a power cut.
+type SettingsManager interface {
+	GetDeviceSettings(ctx context.Context) (*DeviceSettings, error)
+	SetDeviceSettings(ctx context.Context, settings DeviceSettings) error
+}
+
+// DriverFactory creates a driver for the device at ip:port.
+type DriverFactory func(ip string, port int, logger *slog.Logger) LightDriver
+
+// driverSpec describes how to create and discover lights of a given driver.
+type driverSpec struct {
+	factory DriverFactory
+	// port is the device's standard API port, used when a static light omits one.
+	port int
+	// service is the mDNS service type the driver's devices advertise, if any.
+	service string
+	// accepts reports whether accessory info returned during discovery belongs
+	// to a supported device, given the manager's validation rules. Nil accepts
+	// any device that answers.
+	accepts func(info *AccessoryInfo, rules validationRules) bool
+	// withClient creates the driver with the given HTTP client, so requests
+	// can be given a timeout other than the driver's default. Nil for drivers
+	// added with RegisterDriver.
+	withClient func(ip string, port int, logger *slog.Logger, hc *http.Client) LightDriver
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]driverSpec{
+		DriverElgato: {
+			factory: func(ip string, port int, logger *slog.Logger) LightDriver {
+				return NewKeyLightClient(ip, port, logger)
+			},
+			port:    9123,
+			service: "_elg._tcp",
+			accepts: acceptsElgato,
+			withClient: func(ip string, port int, logger *slog.Logger, hc *http.Client) LightDriver {
+				return NewKeyLightClient(ip, port, logger, hc)
+			},
+		},
+		DriverWLED: {
+			factory: func(ip string, port int, logger *slog.Logger) LightDriver {
+				return NewWLEDClient(ip, port, logger)
+			},
+			port:    80,
+			service: "_wled._tcp",
+			withClient: func(ip string, port int, logger *slog.Logger, hc *http.Client) LightDriver {
+				return NewWLEDClient(ip, port, logger, hc)
+			},
+		},
+	}
+)
+
+var (
+	_ LightDriver       = (*KeyLightClient)(nil)
+	_ LightDriver       = (*WLEDClient)(nil)
+	_ DisplayNameSetter = (*KeyLightClient)(nil)
+	_ RawRequester      = (*KeyLightClient)(nil)
+	_ ColorSetter       = (*KeyLightClient)(nil)
+	_ SettingsManager   = (*KeyLightClient)(nil)
+)
+
+// RegisterDriver adds or replaces a light driver. If service is non-empty,
+// devices advertising that mDNS service type are discovered and controlled
+// with the driver.
+func RegisterDriver(name, service string, factory DriverFactory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = driverSpec{factory: factory, service: service}
+}
+
+// Drivers returns the names of all registered drivers, sorted.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lookupDriver returns the spec for a driver name, treating "" as the Elgato driver.
+func lookupDriver(name string) (driverSpec, bool) {
+	if name == "" {
+		name = DriverElgato
+	}
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	spec, ok := drivers[name]
+	return spec, ok
+}
+
+// driverForService returns the name of the driver handling an mDNS service type.
+func driverForService(service string) (string, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	for name, spec := range drivers {
+		if spec.service == service {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// discoveryServices returns the mDNS service types of all registered drivers.
+func discoveryServices() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	var services []string
+	for _, spec := range drivers {
+		if spec.service != "" {
+			services = append(services, spec.service)
+		}
+	}
+	slices.Sort(services)
+	return services
+}
+
+// newDriver creates the driver for a light based on its Driver field,
+// sending requests to ep. The endpoint's timeout and fallback addresses are
+// only used by drivers that accept an HTTP client. Unknown drivers fall back
+// to the Elgato driver with a warning.
+func newDriver(light Light, ep endpoint, logger *slog.Logger) LightDriver {
+	spec, ok := lookupDriver(light.Driver)
+	if !ok {
+		logger.Warn("light: unknown driver, falling back to elgato", "id", light.ID, "driver", light.Driver)
+		spec, _ = lookupDriver(DriverElgato)
+	}
+	if hc := ep.httpClient(); hc != nil && spec.withClient != nil {
+		return spec.withClient(ep.host, ep.port, logger, hc)
+	}
+	return spec.factory(ep.host, ep.port, logger)
+}
@@ -0,0 +1,63 @@
+package keylight
+
+import (
+	"net"
+	"slices"
+
+	"github.com/grandcat/zeroconf"
+)
+
+// defaultInterfaceName labels discovery results when no interfaces are configured.
+const defaultInterfaceName = "default"
+
+// browseTarget is an interface browsed with its own resolver.
+type browseTarget struct {
+	name  string
+	iface *net.Interface // nil uses zeroconf's default interface selection
+}
+
+// options returns the resolver options selecting the target's interface.
+func (t browseTarget) options() []zeroconf.ClientOption {
+	if t.iface == nil {
+		return nil
+	}
+	return []zeroconf.ClientOption{zeroconf.SelectIfaces([]net.Interface{*t.iface})}
+}
+
+// SetDiscoveryInterfaces restricts mDNS discovery to the named network interfaces,
+// each browsed separately. An empty list lets zeroconf pick the interfaces.
+func (m *Manager) SetDiscoveryInterfaces(names []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.discoveryIfaces = slices.Clone(names)
+}
+
+// browseTargets resolves the configured discovery interfaces. Interfaces that do
+// not exist, are down or do not support multicast are skipped with a warning.
+func (m *Manager) browseTargets() []browseTarget {
+	m.mu.RLock()
+	names := slices.Clone(m.discoveryIfaces)
+	m.mu.RUnlock()
+
+	if len(names) == 0 {
+		return []browseTarget{{name: defaultInterfaceName}}
+	}
+
+	targets := make([]browseTarget, 0, len(names))
+	for _, name := range names {
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			m.logger.Warn("discovery: interface not found, skipping", "interface", name, "error", err)
+			continue
+		}
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagMulticast == 0 {
+			m.logger.Warn("discovery: interface is down or does not support multicast, skipping", "interface", name)
+			continue
+		}
+		targets = append(targets, browseTarget{name: name, iface: iface})
+	}
+	if len(targets) == 0 {
+		m.logger.Warn("discovery: none of the configured interfaces are usable, skipping mDNS browse", "interfaces", names)
+	}
+	return targets
+}
@@ -0,0 +1,124 @@
+package keylight
+
+import (
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// Limits is the range of brightness and color temperature a light may be set
+// to. Requests outside it are clamped rather than rejected.
+type Limits struct {
+	MinBrightness  int `json:"min_brightness"`
+	MaxBrightness  int `json:"max_brightness"`
+	MinTemperature int `json:"min_temperature"` // Kelvin
+	MaxTemperature int `json:"max_temperature"` // Kelvin
+}
+
+// DefaultLimits returns the full range supported by the lights.
+func DefaultLimits() Limits {
+	return Limits{
+		MinBrightness:  config.MinBrightness,
+		MaxBrightness:  config.MaxBrightness,
+		MinTemperature: config.MinTemperature,
+		MaxTemperature: config.MaxTemperature,
+	}
+}
+
+// LimitsFromConfig returns the limits set by a configured light limit, with
+// unset ends left at the full range.
+func LimitsFromConfig(l config.LightLimit) Limits {
+	limits := DefaultLimits()
+	if l.MinBrightness != 0 {
+		limits.MinBrightness = l.MinBrightness
+	}
+	if l.MaxBrightness != 0 {
+		limits.MaxBrightness = l.MaxBrightness
+	}
+	if l.MinTemperature != 0 {
+		limits.MinTemperature = l.MinTemperature
+	}
+	if l.MaxTemperature != 0 {
+		limits.MaxTemperature = l.MaxTemperature
+	}
+	return limits
+}
+
+// Intersect returns the limits that satisfy both l and o. Where the ranges
+// don't overlap, the higher minimum wins.
+func (l Limits) Intersect(o Limits) Limits {
+	r := Limits{
+		MinBrightness:  max(l.MinBrightness, o.MinBrightness),
+		MaxBrightness:  min(l.MaxBrightness, o.MaxBrightness),
+		MinTemperature: max(l.MinTemperature, o.MinTemperature),
+		MaxTemperature: min(l.MaxTemperature, o.MaxTemperature),
+	}
+	r.MaxBrightness = max(r.MaxBrightness, r.MinBrightness)
+	r.MaxTemperature = max(r.MaxTemperature, r.MinTemperature)
+	return r
+}
+
+// ClampBrightness returns brightness clamped to the limits.
+func (l Limits) ClampBrightness(brightness int) int {
+	return max(l.MinBrightness, min(brightness, l.MaxBrightness))
+}
+
+// ClampTemperature returns a temperature in Kelvin clamped to the limits.
+func (l Limits) ClampTemperature(kelvin int) int {
+	return max(l.MinTemperature, min(kelvin, l.MaxTemperature))
+}
+
+// clampDeviceTemperature clamps a temperature in device mireds to the limits.
+// Values already within them are returned unchanged, so converting to Kelvin
+// and back doesn't shift them.
+func (l Limits) clampDeviceTemperature(mireds int) int {
+	kelvin := ConvertDeviceToTemperature(mireds)
+	if clamped := l.ClampTemperature(kelvin); clamped != kelvin {
+		return convertTemperatureToDevice(clamped)
+	}
+	return mireds
+}
+
+// SetLimitsResolver sets the function that returns the limits for a light.
+// Every state sent to a light is clamped to them, and they are reported in
+// the light's Limits field. It must be set before discovery starts.
+func (m *Manager) SetLimitsResolver(fn func(id string) Limits) {
+	m.limits = fn
+}
+
+// LightLimits returns the limits for a light, or the full supported range if
+// no resolver is set.
+func (m *Manager) LightLimits(id string) Limits {
+	if m.limits == nil {
+		return DefaultLimits()
+	}
+	return m.limits(id)
+}
+
+// clampToLimits returns brightness and temperature, in device mireds, clamped
+// to the range of the light's model and its limits.
+func (m *Manager) clampToLimits(light *Light, brightness, temperature int) (int, int) {
+	id := light.ID
+	limits := light.Range()
+	if m.limits != nil {
+		limits = m.limits(id).Intersect(limits)
+	}
+	clampedBrightness := limits.ClampBrightness(brightness)
+	clampedTemperature := limits.clampDeviceTemperature(temperature)
+	if clampedBrightness != brightness || clampedTemperature != temperature {
+		m.logger.Debug("light: clamped state to limits", "id", id,
+			"brightness", brightness, "clamped_brightness", clampedBrightness,
+			"temperature", temperature, "clamped_temperature", clampedTemperature)
+	}
+	return clampedBrightness, clampedTemperature
+}
+
+// withLimits returns a copy of light with its limits, narrowed to the range of
+// its model, set if a resolver is set.
+func (m *Manager) withLimits(light *Light) *Light {
+	if light == nil || m.limits == nil {
+		return light
+	}
+	c := *light
+	limits := m.limits(light.ID).Intersect(light.Range())
+	c.Limits = &limits
+	return &c
+}
@@ -0,0 +1,58 @@
+package keylight
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+func TestLimitsIntersect(t *testing.T) {
+	light := LimitsFromConfig(config.LightLimit{Light: "light1", MinBrightness: 10, MaxTemperature: 5000})
+	group := LimitsFromConfig(config.LightLimit{Group: "desk", MaxBrightness: 60, MinTemperature: 3500})
+
+	assert.Equal(t, Limits{MinBrightness: 10, MaxBrightness: 60, MinTemperature: 3500, MaxTemperature: 5000}, light.Intersect(group))
+
+	// Ranges that don't overlap collapse to the higher minimum
+	high := LimitsFromConfig(config.LightLimit{MinBrightness: 80})
+	low := LimitsFromConfig(config.LightLimit{MaxBrightness: 40})
+	got := high.Intersect(low)
+	assert.Equal(t, 80, got.MinBrightness)
+	assert.Equal(t, 80, got.MaxBrightness)
+}
+
+func TestSetLightState_ClampsToLimits(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 50, convertTemperatureToDevice(4000))
+	m.SetLimitsResolver(func(id string) Limits {
+		return Limits{MinBrightness: 20, MaxBrightness: 60, MinTemperature: 3000, MaxTemperature: 5000}
+	})
+	ctx := context.Background()
+
+	require.NoError(t, m.SetLightState(ctx, "light1", BrightnessValue(90)))
+	require.NoError(t, m.SetLightState(ctx, "light1", TemperatureValue(6500)))
+	updates := device.updates()
+	last := updates[len(updates)-1].Lights[0]
+	assert.Equal(t, 60, last.Brightness)
+	assert.Equal(t, convertTemperatureToDevice(5000), last.Temperature)
+
+	require.NoError(t, m.AdjustLight(ctx, "light1", Adjustment{Brightness: -100}))
+	updates = device.updates()
+	assert.Equal(t, 20, updates[len(updates)-1].Lights[0].Brightness)
+
+	light, err := m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+	require.NotNil(t, light.Limits)
+	assert.Equal(t, 60, light.Limits.MaxBrightness)
+}
+
+func TestSetLightState_NoLimitsResolver(t *testing.T) {
+	m, _ := newTransitionTestManager(t, 1, 50, convertTemperatureToDevice(4000))
+
+	light, err := m.GetLight(context.Background(), "light1")
+	require.NoError(t, err)
+	assert.Nil(t, light.Limits)
+	assert.Equal(t, DefaultLimits(), m.LightLimits("light1"))
+}
@@ -2,12 +2,13 @@
 Concurrency Notes (audit):
 - Read operations (GetDiscoveredLights, GetLights) use RLock and return snapshots (map/slice copies or new structs) to avoid external mutation of internal state.
 - Mutations (AddLight, SetLightState*, cleanupStaleLights) perform network/device I/O outside the write lock; only the minimal in-memory updates are under Lock to reduce contention.
-- cleanupStaleLights uses a two-phase approach: RLock to collect stale IDs, then Lock to remove, minimizing time spent holding the write lock.
+- cleanupStaleLights uses a two-phase approach: RLock to collect stale IDs, then Lock to mark lights offline or remove them, minimizing time spent holding the write lock.
 - getOrCreateClient & state fetch patterns avoid holding locks during remote calls.
+- Commands that change a light wait their turn in the light's queue (lockLight), so they run one at a time and in arrival order per light, while different lights are changed in parallel.
 - Returned *Light pointers from GetLight should be treated as read-only by callers; direct mutation risks data races unless routed through manager methods.
+- Methods that talk to lights take the caller's context, and abandon requests, retries and their wait in the light's queue when it ends. The server ends it at the request timeout, when the client disconnects and on shutdown.
 Future considerations:
 - Enforce deep-copy semantics for GetLight to eliminate accidental external mutation risks.
-- Replace context.Background() usages with caller-provided contexts for cancellation propagation.
 - Consider a lightweight RW lock partitioning (e.g., sharded maps) only if contention is observed under profiling.
 */
 package keylight
@@ -16,6 +17,7 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmylchreest/keylightd/internal/config"
@@ -26,18 +28,90 @@ import (
 // Manager manages Key Light devices
 type Manager struct {
 	lights   map[string]Light
-	clients  map[string]*KeyLightClient
+	clients  map[string]LightDriver
 	mu       sync.RWMutex
 	logger   *slog.Logger
 	eventBus *events.Bus
+
+	transitions  map[string]*transitionHandle
+	transitionMu sync.Mutex
+
+	queues   map[string]*lightQueue // per-light command queues, see lockLight
+	queuesMu sync.Mutex
+
+	debounce   time.Duration                  // see SetDebounceWindow
+	pending    map[debounceKey]*pendingUpdate // updates waiting for the end of their window
+	lastSent   map[debounceKey]time.Time      // when each light's last coalesced update was sent
+	debounceMu sync.Mutex
+
+	static          []Light
+	virtual         map[string]*virtualLight // simulated devices keyed by light ID, see AddVirtualLights
+	virtualMu       sync.Mutex
+	discoveryIfaces []string
+	backends        []DiscoveryBackend        // see SetDiscoveryBackends
+	scan            scanSettings              // see SetSubnetScan
+	validation      validationRules           // see SetValidationRules
+	unvalidated     map[string]*PendingDevice // devices the validation rules rejected, see PendingDevices
+	adopted         map[string]bool           // IDs of devices accepted whatever the rules say, see SetAdoptedDevices
+	persistAdopted  func(ids []string) error
+	demand          func() bool   // reports whether clients are using the daemon, see SetDemand
+	idleInterval    time.Duration // discovery interval while they aren't
+
+	metrics MetricsRecorder
+
+	stateTTL    time.Duration           // how long a refreshed state is served from memory, see GetLight
+	refreshedAt map[string]time.Time    // when each light's state was last read from the device
+	refreshes   map[string]*refreshCall // refreshes in progress, shared by concurrent callers
+	refreshMu   sync.Mutex
+
+	retry         RetryPolicy              // see SetRetryPolicy
+	deviceTimeout time.Duration            // see SetDeviceTimeout
+	overrides     map[string]LightOverride // per-light connection overrides, see SetLightOverrides
+	deviceLog     *slog.Logger             // nil unless device requests are logged, see SetDeviceLogging
+	breakers      map[string]*breaker      // per-light circuit breakers, see breakerFor
+	breakersMu    sync.Mutex
+
+	onLightAdded func(ctx context.Context, light Light)
+
+	version uint64 // last state version given to a light, see updateLightState; guarded by mu
+
+	limits func(id string) Limits // see SetLimitsResolver
+
+	names       map[string]string // user-chosen display names, see SetNameOverrides
+	persistName func(id, name string) error
+
+	tags        map[string]map[string]string // tags keyed by light ID, see SetTagStore
+	persistTags func(id string, tags map[string]string) error
+
+	offlineRetention time.Duration
+
+	savedStates   map[string]config.SavedLightState // nil unless state restore is enabled, see EnableStateRestore
+	persistStates func(map[string]config.SavedLightState) error
+	savedMu       sync.Mutex
+
+	// discovered is set once the first discovery pass has finished.
+	discovered atomic.Bool
+	stats      DiscoveryStats // see DiscoveryStats
+	statsMu    sync.Mutex
+	// discovering holds a token while a discovery pass runs, so periodic
+	// passes and those requested by DiscoverNow don't overlap.
+	discovering chan struct{}
 }
 
 // NewManager creates a new manager
 func NewManager(logger *slog.Logger) *Manager {
 	return &Manager{
-		lights:  make(map[string]Light),
-		clients: make(map[string]*KeyLightClient),
-		logger:  logger,
+		lights:           make(map[string]Light),
+		clients:          make(map[string]LightDriver),
+		logger:           logger,
+		offlineRetention: config.DefaultOfflineRetention,
+		stateTTL:         config.DefaultStateCacheTTL,
+		refreshedAt:      make(map[string]time.Time),
+		discovering:      make(chan struct{}, 1),
+		// Start versions from the current time, so that a version from before
+		// a restart doesn't match a light after it. Milliseconds keep
+		// versions exact in JSON numbers.
+		version: uint64(time.Now().UnixMilli()),
 	}
 }
 
@@ -47,6 +121,13 @@ func (m *Manager) SetEventBus(bus *events.Bus) {
 	m.eventBus = bus
 }
 
+// SetLightAddedHandler sets a function called after a light is added that the
+// manager did not already know about. It is not called when a known light is
+// rediscovered. It must be set before discovery starts.
+func (m *Manager) SetLightAddedHandler(fn func(ctx context.Context, light Light)) {
+	m.onLightAdded = fn
+}
+
 // emit publishes an event if an event bus is configured.
 func (m *Manager) emit(t events.EventType, data any) {
 	if m.eventBus != nil {
@@ -57,18 +138,23 @@ func (m *Manager) emit(t events.EventType, data any) {
 // GetDiscoveredLights returns all discovered lights
 func (m *Manager) GetDiscoveredLights() []*Light {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
 
 	lights := make([]*Light, 0, len(m.lights))
 	for id := range m.lights {
 		light := m.lights[id]
 		lights = append(lights, &light)
 	}
+	m.mu.RUnlock()
+
+	for i, light := range lights {
+		lights[i] = m.withLimits(light)
+	}
 	return lights
 }
 
-// GetLight returns a light by ID and updates its state
-func (m *Manager) GetLight(ctx context.Context, id string) (*Light, error) {
+// fetchLight updates a light's state, and accessory info if missing, from the
+// device and returns the light.
+func (m *Manager) fetchLight(ctx context.Context, id string) (*Light, error) {
 	// Get client and light information
 	client, light, err := m.getOrCreateClient(id)
 	if err != nil {
@@ -101,11 +187,12 @@ func (m *Manager) GetLight(ctx context.Context, id string) (*Light, error) {
 
 	// Update both state and info if needed
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	before := m.lights[id]
 
 	// First update state
 	updatedLight, err := m.updateLightState(id, state)
 	if err != nil {
+		m.mu.Unlock()
 		return light, err
 	}
 
@@ -113,14 +200,23 @@ func (m *Manager) GetLight(ctx context.Context, id string) (*Light, error) {
 	if needsInfo && info != nil {
 		updatedLight, err = m.updateLightInfo(id, info)
 		if err != nil {
+			m.mu.Unlock()
 			return updatedLight, err
 		}
 	}
+	m.mu.Unlock()
+
+	// A state that differs from the one last seen was changed outside the
+	// manager, with the light's buttons or another app
+	if before.State != nil && updatedLight.Version != before.Version {
+		m.logger.DebugContext(ctx, "light: state changed on the device", slog.String("id", id))
+		m.emit(events.LightStateChanged, updatedLight)
+	}
 
 	// Log light information
-	m.logger.Debug("GetLight returning light", slog.String("id", id), slog.Any("light", *updatedLight))
+	m.logger.DebugContext(ctx, "fetchLight returning light", slog.String("id", id), slog.Any("light", *updatedLight))
 	if updatedLight.ProductName == "" || updatedLight.SerialNumber == "" || updatedLight.FirmwareVersion == "" {
-		m.logger.Warn("GetLight: missing key fields in returned light",
+		m.logger.WarnContext(ctx, "fetchLight: missing key fields in returned light",
 			slog.String("id", id),
 			slog.String("productname", updatedLight.ProductName),
 			slog.String("serialnumber", updatedLight.SerialNumber),
@@ -132,19 +228,45 @@ func (m *Manager) GetLight(ctx context.Context, id string) (*Light, error) {
 
 // SetLightState sets the state of a light using type-safe property values
 // It fetches the current state, updates the specified property, and sends the new state to the device.
+// Rapid brightness and temperature updates are coalesced, see SetDebounceWindow.
 func (m *Manager) SetLightState(ctx context.Context, id string, propertyValue LightPropertyValue) error {
 	// Validate the property value first
 	if err := propertyValue.Validate(); err != nil {
 		return errors.InvalidInputf("invalid property value: %w", err)
 	}
+	if m.debounced(ctx, propertyValue) {
+		return m.setDebounced(ctx, id, propertyValue)
+	}
+	return m.setLightState(ctx, id, propertyValue)
+}
 
-	// Get client for this light
-	client, _, err := m.getOrCreateClient(id)
+// setLightState sends a validated state change to a light.
+func (m *Manager) setLightState(ctx context.Context, id string, propertyValue LightPropertyValue) error {
+	unlock, err := m.lockLight(ctx, id)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	// Using passed-in ctx
+	if err := m.checkVersion(ctx, id); err != nil {
+		return err
+	}
+
+	// A direct state change supersedes any transition in progress
+	m.cancelTransition(id)
+
+	// Get client for this light
+	client, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return err
+	}
+	propertyName := propertyValue.PropertyName()
+	if !light.Supports(propertyName) {
+		return errors.InvalidInputf("light %s does not support %s", id, propertyName)
+	}
+	if err := checkPropertyRange(*light, propertyValue); err != nil {
+		return err
+	}
 
 	// Get current state from the device
 	state, err := m.fetchLightState(ctx, client, id)
@@ -153,19 +275,19 @@ func (m *Manager) SetLightState(ctx context.Context, id string, propertyValue Li
 	}
 
 	// Validate and prepare state update
-	propertyName := propertyValue.PropertyName()
 	if err := m.validateAndPrepareStateUpdate(string(propertyName), propertyValue.Value(), state); err != nil {
 		return err
 	}
+	current := &state.Lights[0]
+	current.Brightness, current.Temperature = m.clampToLimits(light, current.Brightness, current.Temperature)
 
 	// Send updated state to device
-	if err := client.SetLightState(
-		ctx,
-		state.Lights[0].On == 1,
-		state.Lights[0].Brightness,
-		state.Lights[0].Temperature,
-	); err != nil {
-		return errors.LogErrorAndReturn(
+	if err := sendLightState(ctx, client, light, *current); err != nil {
+		if errors.IsInvalidInput(err) {
+			return err
+		}
+		return errors.LogErrorAndReturnContext(
+			ctx,
 			m.logger,
 			errors.DeviceUnavailablef("failed to send updated state: %w", err),
 			"failed to set light state",
@@ -183,6 +305,8 @@ func (m *Manager) SetLightState(ctx context.Context, id string, propertyValue Li
 		return errors.NotFoundf("light %s removed during state update", id)
 	}
 
+	advanceVersion(ctx, updatedLight)
+
 	// Emit state change event
 	if updatedLight != nil {
 		m.emit(events.LightStateChanged, updatedLight)
@@ -209,7 +333,6 @@ func (m *Manager) SetLightPower(ctx context.Context, id string, on bool) error {
 // GetLights returns all discovered lights
 func (m *Manager) GetLights() map[string]*Light {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
 
 	// Create a copy of the map to avoid concurrent access issues
 	lights := make(map[string]*Light)
@@ -217,28 +340,37 @@ func (m *Manager) GetLights() map[string]*Light {
 		lightCopy := light // Create a copy to avoid pointer issues
 		lights[id] = &lightCopy
 	}
+	m.mu.RUnlock()
 
+	// Resolve limits without holding the lock, as the resolver may call back
+	for id, light := range lights {
+		lights[id] = m.withLimits(light)
+	}
 	return lights
 }
 
 // AddLight adds a light to the manager and fetches its initial state.
 func (m *Manager) AddLight(ctx context.Context, light Light) {
-	// Create client for this light - not blocking, can be done before lock
-	client := NewKeyLightClient(light.IP.String(), light.Port, m.logger)
+	// Create driver for this light - not blocking, can be done before lock
+	client := m.newClient(light)
 	// Using caller-provided ctx
 
+	// Note whether the light is new or coming back from offline before the
+	// state fetch below updates its reachability
+	m.mu.RLock()
+	existing, known := m.lights[light.ID]
+	m.mu.RUnlock()
+	returning := !known || existing.Status == ReachabilityOffline
+
 	// Get current state - happens OUTSIDE the lock
 	state, err := m.fetchLightState(ctx, client, light.ID)
 	if err != nil {
 		// Proceed adding the light even with error, error already logged
+		light.Status = ReachabilityDegraded
 	} else if state != nil {
+		light.Status = ReachabilityOnline
 		// Update light with state information
-		light.State = state
-		if len(state.Lights) > 0 {
-			light.Temperature = state.Lights[0].Temperature
-			light.Brightness = state.Lights[0].Brightness
-			light.On = state.Lights[0].On == 1
-		}
+		applyState(&light, state)
 	}
 
 	// Try to get accessory info if needed
@@ -250,19 +382,31 @@ func (m *Manager) AddLight(ctx context.Context, light Light) {
 			light.FirmwareVersion = info.FirmwareVersion
 			light.FirmwareBuild = info.FirmwareBuildNumber
 			light.SerialNumber = info.SerialNumber
-			light.Name = info.DisplayName
+			setCapabilities(&light, CapabilitiesFromInfo(info))
+			if !light.Static || light.Name == "" {
+				light.Name = info.DisplayName
+			}
 		}
 	}
 
-	// Set LastSeen timestamp
-	light.LastSeen = time.Now()
+	// Re-apply the last known state to lights that are new to the manager or
+	// were offline
+	if returning {
+		m.restoreState(ctx, client, &light)
+	}
+
+	// Set LastSeen timestamp. Static lights are added whether or not they
+	// respond, so they are only seen when they do.
+	if !light.Static || err == nil {
+		light.LastSeen = time.Now()
+	}
 
 	// Acquire write lock briefly to update the maps
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	// Check if light already exists
-	if existingLight, exists := m.lights[light.ID]; exists {
+	existingLight, exists := m.lights[light.ID]
+	if exists {
 		m.logger.Debug("light already exists, updating", slog.String("id", light.ID))
 
 		// Preserve any fields that might be missing in the new light
@@ -275,16 +419,35 @@ func (m *Manager) AddLight(ctx context.Context, light Light) {
 		if light.Name == "" {
 			light.Name = existingLight.Name
 		}
+		if existingLight.Static {
+			light.Static = true
+		}
+		if light.Capabilities == nil && existingLight.Capabilities != nil {
+			setCapabilities(&light, *existingLight.Capabilities)
+		}
+		if light.LastSeen.IsZero() {
+			light.LastSeen = existingLight.LastSeen
+		}
 	}
+	m.applyNameOverride(&light)
+	m.applyTags(&light)
 
 	m.clients[light.ID] = client
 	m.lights[light.ID] = light // Add or update the light with fetched state
+	if light.State != nil && err == nil {
+		m.refreshedAt[light.ID] = light.LastSeen
+	}
+	m.mu.Unlock()
 
 	// Log the light addition/update
 	m.logLightInfo(ctx, slog.LevelInfo, "light: added/updated", &light)
 
 	// Emit discovered event (covers both new discoveries and re-discoveries with updated state)
 	m.emit(events.LightDiscovered, &light)
+
+	if !exists && m.onLightAdded != nil {
+		m.onLightAdded(ctx, light)
+	}
 }
 
 // StartCleanupWorker starts a background goroutine to remove stale lights.
@@ -307,61 +470,12 @@ func (m *Manager) StartCleanupWorker(ctx context.Context, cleanupInterval time.D
 				m.logger.Info("light: cleanup worker stopped (context canceled)")
 				return
 			case <-ticker.C:
+				// Save states first so lights about to be removed keep their last state
+				if err := m.SaveLightStates(); err != nil {
+					m.logger.Warn("light: failed to save light states", "error", err)
+				}
 				m.cleanupStaleLights(timeout)
 			}
 		}
 	}()
 }
-
-// cleanupStaleLights removes lights that haven't been seen for a while
-func (m *Manager) cleanupStaleLights(timeout time.Duration) {
-	// Use default timeout if the provided one is invalid
-	if timeout <= 0 {
-		m.logger.Debug("Invalid cleanup timeout, using default",
-			"provided", timeout,
-			"default", config.DefaultStateTimeout)
-		timeout = config.DefaultStateTimeout
-	}
-
-	now := time.Now()
-
-	// First identify stale lights with read lock to minimize lock duration
-	m.mu.RLock()
-	staleLights := []string{}
-
-	for id, light := range m.lights {
-		if now.Sub(light.LastSeen) > timeout {
-			staleLights = append(staleLights, id)
-		}
-	}
-	m.mu.RUnlock()
-
-	// If no stale lights, return quickly without acquiring write lock
-	if len(staleLights) == 0 {
-		return
-	}
-
-	// Now remove the stale lights with write lock
-	m.mu.Lock()
-
-	// Double-check the lights are still stale after acquiring write lock
-	var removed []Light
-	for _, id := range staleLights {
-		if light, exists := m.lights[id]; exists {
-			// Re-check timeout condition to handle race condition
-			// where the light might have been updated while we were unlocked
-			if now.Sub(light.LastSeen) > timeout {
-				m.logger.Info("Removing stale light", "id", id)
-				removed = append(removed, light)
-				delete(m.lights, id)
-				delete(m.clients, id)
-			}
-		}
-	}
-	m.mu.Unlock()
-
-	// Emit removal events outside the lock
-	for i := range removed {
-		m.emit(events.LightRemoved, &removed[i])
-	}
-}
@@ -149,7 +149,8 @@ func TestCleanupStaleLights_EmitsLightRemovedEvent(t *testing.T) {
 
 	getEvents := collectEvents(bus)
 
-	// Run cleanup with 5-minute timeout
+	// Run cleanup with 5-minute timeout, past the offline retention
+	manager.SetOfflineRetention(time.Minute)
 	manager.cleanupStaleLights(5 * time.Minute)
 
 	evts := getEvents()
@@ -205,6 +206,7 @@ func TestMultipleStaleCleanup_EmitsMultipleEvents(t *testing.T) {
 	}
 
 	getEvents := collectEvents(bus)
+	manager.SetOfflineRetention(time.Minute)
 	manager.cleanupStaleLights(5 * time.Minute)
 
 	evts := getEvents()
@@ -9,9 +9,22 @@ import (
 	"github.com/jmylchreest/keylightd/internal/errors"
 )
 
-// getOrCreateClient retrieves an existing client or creates a new one for the given light ID.
+// sendLightState writes a light's state to the device, keeping a color
+// light's color unless the state has been switched back to a temperature.
+func sendLightState(ctx context.Context, client LightDriver, light *Light, state LightStateEntry) error {
+	if state.Hue != nil && state.Saturation != nil {
+		setter, ok := colorSetter(client)
+		if !ok {
+			return errors.InvalidInputf("light %s (driver %s) does not support color", light.ID, light.Driver)
+		}
+		return setter.SetLightColor(ctx, state.On == 1, state.Brightness, *state.Hue, *state.Saturation)
+	}
+	return client.SetLightState(ctx, state.On == 1, state.Brightness, state.Temperature)
+}
+
+// getOrCreateClient retrieves an existing driver or creates a new one for the given light ID.
 // It uses fine-grained locking to minimize lock contention.
-func (m *Manager) getOrCreateClient(id string) (*KeyLightClient, *Light, error) {
+func (m *Manager) getOrCreateClient(id string) (LightDriver, *Light, error) {
 	// First try with a read lock
 	m.mu.RLock()
 	light, exists := m.lights[id]
@@ -43,7 +56,7 @@ func (m *Manager) getOrCreateClient(id string) (*KeyLightClient, *Light, error)
 	}
 
 	// Create new client and store it
-	client = NewKeyLightClient(light.IP.String(), light.Port, m.logger)
+	client = m.newClient(light)
 	m.clients[id] = client
 
 	return client, &light, nil
@@ -57,16 +70,17 @@ func (m *Manager) updateLightState(id string, state *LightState) (*Light, error)
 		return nil, errors.NotFoundf("light %s not found", id)
 	}
 
-	// Update state fields
-	light.State = state
-	if state != nil && len(state.Lights) > 0 {
-		light.Temperature = state.Lights[0].Temperature
-		light.Brightness = state.Lights[0].Brightness
-		light.On = state.Lights[0].On == 1
+	// Update state fields, giving the light a new version if they changed
+	before := light
+	applyState(&light, state)
+	if !sameState(before, light) {
+		m.version++
+		light.Version = m.version
 	}
 
 	// Update last seen timestamp
 	light.LastSeen = time.Now()
+	m.refreshedAt[id] = light.LastSeen
 
 	// Store updated light back into the map
 	m.lights[id] = light
@@ -89,7 +103,11 @@ func (m *Manager) updateLightInfo(id string, info *AccessoryInfo) (*Light, error
 		light.FirmwareVersion = info.FirmwareVersion
 		light.FirmwareBuild = info.FirmwareBuildNumber
 		light.SerialNumber = info.SerialNumber
-		light.Name = info.DisplayName
+		setCapabilities(&light, CapabilitiesFromInfo(info))
+		if !light.Static || light.Name == "" {
+			light.Name = info.DisplayName
+		}
+		m.applyNameOverride(&light)
 	}
 
 	// Store updated light back into the map
@@ -98,12 +116,46 @@ func (m *Manager) updateLightInfo(id string, info *AccessoryInfo) (*Light, error
 	return &light, nil
 }
 
+// applyState copies the state of the first light in a device state onto light.
+// A light keeps its last temperature while it shows a color.
+func applyState(light *Light, state *LightState) {
+	light.State = state
+	if state == nil || len(state.Lights) == 0 {
+		return
+	}
+	entry := state.Lights[0]
+	if entry.Temperature != 0 {
+		light.Temperature = entry.Temperature
+	}
+	light.Brightness = entry.Brightness
+	light.On = entry.On == 1
+	light.Hue, light.Saturation = entry.Hue, entry.Saturation
+	if entry.Hue != nil && !light.SupportsColor() {
+		setCapabilities(light, light.capabilities())
+	}
+	light.ColorMode = colorMode(*light)
+}
+
+// colorMode returns the color mode of a light, or "" if it doesn't support color.
+func colorMode(light Light) string {
+	switch {
+	case light.Hue != nil:
+		return ColorModeColor
+	case light.SupportsColor():
+		return ColorModeTemperature
+	default:
+		return ""
+	}
+}
+
 // fetchLightState retrieves the current state of a light from the device.
-func (m *Manager) fetchLightState(ctx context.Context, client *KeyLightClient, id string) (*LightState, error) {
+func (m *Manager) fetchLightState(ctx context.Context, client LightDriver, id string) (*LightState, error) {
 
 	state, err := client.GetLightState(ctx)
+	m.noteRequest(id, err)
 	if err != nil {
-		return nil, errors.LogErrorAndReturn(
+		return nil, errors.LogErrorAndReturnContext(
+			ctx,
 			m.logger,
 			errors.DeviceUnavailablef("failed to get current state: %w", err),
 			"failed to get current state",
@@ -114,11 +166,12 @@ func (m *Manager) fetchLightState(ctx context.Context, client *KeyLightClient, i
 }
 
 // fetchAccessoryInfo retrieves accessory information for a light from the device.
-func (m *Manager) fetchAccessoryInfo(ctx context.Context, client *KeyLightClient, id string) (*AccessoryInfo, error) {
+func (m *Manager) fetchAccessoryInfo(ctx context.Context, client LightDriver, id string) (*AccessoryInfo, error) {
 
 	info, err := client.GetAccessoryInfo(ctx)
 	if err != nil {
-		return nil, errors.LogErrorAndReturn(
+		return nil, errors.LogErrorAndReturnContext(
+			ctx,
 			m.logger,
 			err,
 			"failed to get accessory info",
@@ -172,6 +225,29 @@ func (m *Manager) validateAndPrepareStateUpdate(property string, value any, curr
 			// Kelvin format (from API/user), convert to mireds
 			currentState.Lights[0].Temperature = convertTemperatureToDevice(temp)
 		}
+		// Setting a temperature switches a color light back to white
+		currentState.Lights[0].Hue, currentState.Lights[0].Saturation = nil, nil
+
+	case PropertyHue, PropertySaturation, PropertyColor:
+		entry := &currentState.Lights[0]
+		// A light switching from white to a color starts from fully saturated red
+		hue, saturation := 0.0, float64(config.MaxSaturation)
+		if entry.Hue != nil && entry.Saturation != nil {
+			hue, saturation = *entry.Hue, *entry.Saturation
+		}
+		switch v := value.(type) {
+		case float64:
+			if PropertyName(property) == PropertyHue {
+				hue = v
+			} else {
+				saturation = v
+			}
+		case ColorValue:
+			hue, saturation = float64(v.Hue), float64(v.Saturation)
+		default:
+			return errors.InvalidInputf("invalid value type for %s: %T", property, value)
+		}
+		entry.Hue, entry.Saturation = &hue, &saturation
 
 	default:
 		return errors.InvalidInputf("unknown property: %s", property)
@@ -53,7 +53,7 @@ func (m *mockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 func newTestManager(logger *slog.Logger) (*Manager, *http.Client) {
 	m := NewManager(logger)
 	mockClient := &http.Client{Transport: &mockRoundTripper{}}
-	m.clients = make(map[string]*KeyLightClient)
+	m.clients = make(map[string]LightDriver)
 	m.lights = make(map[string]Light)
 	return m, mockClient
 }
@@ -177,7 +177,8 @@ func TestCleanupStaleDevices(t *testing.T) {
 	manager.clients[staleLight.ID] = NewKeyLightClient(staleLight.IP.String(), staleLight.Port, logger, mockHTTP)
 	manager.clients[freshLight.ID] = NewKeyLightClient(freshLight.IP.String(), freshLight.Port, logger, mockHTTP)
 
-	// Run cleanup with 5 minute timeout
+	// Run cleanup with 5 minute timeout, forgetting lights offline for over a minute
+	manager.SetOfflineRetention(time.Minute)
 	manager.cleanupStaleLights(5 * time.Minute)
 
 	// Stale light should be removed, fresh light should remain
@@ -246,3 +247,19 @@ func TestStartCleanupWorker(t *testing.T) {
 	// Give it a moment to start
 	time.Sleep(20 * time.Millisecond)
 }
+
+func TestAddLight_CallsLightAddedHandlerForNewLightsOnly(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 64})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	var added []string
+	m.SetLightAddedHandler(func(_ context.Context, light Light) {
+		added = append(added, light.ID)
+	})
+
+	light := Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED}
+	m.AddLight(context.Background(), light)
+	m.AddLight(context.Background(), light) // rediscovery
+	assert.Equal(t, []string{"strip"}, added)
+}
@@ -0,0 +1,211 @@
+package keylight
+
+import "context"
+
+// MetricsRecorder receives operational counters from the Manager.
+type MetricsRecorder interface {
+	// DiscoveryAttempt is called once per mDNS browse attempt.
+	DiscoveryAttempt()
+	// DeviceError is called when a request to a light fails.
+	DeviceError(lightID, operation string)
+	// UpdateDropped is called when an update to a light is replaced by a
+	// later one before it was sent, see SetDebounceWindow.
+	UpdateDropped(lightID string)
+}
+
+// SetMetrics sets the recorder for discovery and device error counters.
+// It must be called before discovery starts. If not set, nothing is recorded.
+func (m *Manager) SetMetrics(r MetricsRecorder) {
+	m.metrics = r
+}
+
+// newClient creates the driver for a light, wrapped with the retry policy and
+// instrumented with device error counting when a metrics recorder is set.
+func (m *Manager) newClient(light Light) LightDriver {
+	driver, virtual := m.virtualDriver(light.ID)
+	policy := m.retry
+	if !virtual {
+		driver = newDriver(light, m.endpoint(light), m.logger)
+		if o, ok := m.overrides[light.ID]; ok && o.Timeout > 0 {
+			policy.Timeout = o.Timeout
+		}
+	}
+	if policy.enabled() {
+		rd := &resilientDriver{LightDriver: driver, id: light.ID, policy: policy, logger: m.logger}
+		if m.retry.BreakerThreshold > 0 {
+			rd.breaker = m.breakerFor(light.ID)
+		}
+		driver = rd
+	}
+	if m.metrics == nil {
+		return driver
+	}
+	return &instrumentedDriver{LightDriver: driver, id: light.ID, metrics: m.metrics}
+}
+
+// instrumentedDriver counts failed device requests.
+type instrumentedDriver struct {
+	LightDriver
+	id      string
+	metrics MetricsRecorder
+}
+
+func (d *instrumentedDriver) GetAccessoryInfo(ctx context.Context) (*AccessoryInfo, error) {
+	info, err := d.LightDriver.GetAccessoryInfo(ctx)
+	if err != nil {
+		d.metrics.DeviceError(d.id, "get_accessory_info")
+	}
+	return info, err
+}
+
+func (d *instrumentedDriver) GetLightState(ctx context.Context) (*LightState, error) {
+	state, err := d.LightDriver.GetLightState(ctx)
+	if err != nil {
+		d.metrics.DeviceError(d.id, "get_light_state")
+	}
+	return state, err
+}
+
+func (d *instrumentedDriver) SetLightState(ctx context.Context, on bool, brightness, temperature int) error {
+	err := d.LightDriver.SetLightState(ctx, on, brightness, temperature)
+	if err != nil {
+		d.metrics.DeviceError(d.id, "set_light_state")
+	}
+	return err
+}
+
+// displayNameSetter returns the driver's DisplayNameSetter, looking through
+// instrumentation and retries, with failures counted when the driver is
+// instrumented. Renames are not retried.
+func displayNameSetter(driver LightDriver) (DisplayNameSetter, bool) {
+	d, instrumented := driver.(*instrumentedDriver)
+	if !instrumented {
+		setter, ok := unwrapRetries(driver).(DisplayNameSetter)
+		return setter, ok
+	}
+	setter, ok := unwrapRetries(d.LightDriver).(DisplayNameSetter)
+	if !ok {
+		return nil, false
+	}
+	return instrumentedNameSetter{setter: setter, driver: d}, true
+}
+
+// instrumentedNameSetter counts failed display name updates.
+type instrumentedNameSetter struct {
+	setter DisplayNameSetter
+	driver *instrumentedDriver
+}
+
+func (s instrumentedNameSetter) SetDisplayName(ctx context.Context, name string) error {
+	err := s.setter.SetDisplayName(ctx, name)
+	if err != nil {
+		s.driver.metrics.DeviceError(s.driver.id, "set_display_name")
+	}
+	return err
+}
+
+// colorSetter returns the driver's ColorSetter, looking through
+// instrumentation and retries, with failures counted when the driver is
+// instrumented. Color changes are not retried.
+func colorSetter(driver LightDriver) (ColorSetter, bool) {
+	d, instrumented := driver.(*instrumentedDriver)
+	if !instrumented {
+		setter, ok := unwrapRetries(driver).(ColorSetter)
+		return setter, ok
+	}
+	setter, ok := unwrapRetries(d.LightDriver).(ColorSetter)
+	if !ok {
+		return nil, false
+	}
+	return instrumentedColorSetter{setter: setter, driver: d}, true
+}
+
+// instrumentedColorSetter counts failed color changes.
+type instrumentedColorSetter struct {
+	setter ColorSetter
+	driver *instrumentedDriver
+}
+
+func (s instrumentedColorSetter) SetLightColor(ctx context.Context, on bool, brightness int, hue, saturation float64) error {
+	err := s.setter.SetLightColor(ctx, on, brightness, hue, saturation)
+	if err != nil {
+		s.driver.metrics.DeviceError(s.driver.id, "set_light_color")
+	}
+	return err
+}
+
+// rawRequester returns the driver's RawRequester, looking through
+// instrumentation and retries, with failures counted when the driver is
+// instrumented. Raw requests are not retried.
+func rawRequester(driver LightDriver) (RawRequester, bool) {
+	d, instrumented := driver.(*instrumentedDriver)
+	if !instrumented {
+		requester, ok := unwrapRetries(driver).(RawRequester)
+		return requester, ok
+	}
+	requester, ok := unwrapRetries(d.LightDriver).(RawRequester)
+	if !ok {
+		return nil, false
+	}
+	return instrumentedRawRequester{requester: requester, driver: d}, true
+}
+
+// instrumentedRawRequester counts failed raw requests.
+type instrumentedRawRequester struct {
+	requester RawRequester
+	driver    *instrumentedDriver
+}
+
+func (r instrumentedRawRequester) RawRequest(ctx context.Context, method, path string, body []byte) (*RawResponse, error) {
+	resp, err := r.requester.RawRequest(ctx, method, path, body)
+	if err != nil {
+		r.driver.metrics.DeviceError(r.driver.id, "raw_request")
+	}
+	return resp, err
+}
+
+// settingsManager returns the driver's SettingsManager, looking through
+// instrumentation and retries, with failures counted when the driver is
+// instrumented. Settings changes are not retried.
+func settingsManager(driver LightDriver) (SettingsManager, bool) {
+	d, instrumented := driver.(*instrumentedDriver)
+	if !instrumented {
+		settings, ok := unwrapRetries(driver).(SettingsManager)
+		return settings, ok
+	}
+	settings, ok := unwrapRetries(d.LightDriver).(SettingsManager)
+	if !ok {
+		return nil, false
+	}
+	return instrumentedSettingsManager{settings: settings, driver: d}, true
+}
+
+// instrumentedSettingsManager counts failed settings requests.
+type instrumentedSettingsManager struct {
+	settings SettingsManager
+	driver   *instrumentedDriver
+}
+
+func (s instrumentedSettingsManager) GetDeviceSettings(ctx context.Context) (*DeviceSettings, error) {
+	settings, err := s.settings.GetDeviceSettings(ctx)
+	if err != nil {
+		s.driver.metrics.DeviceError(s.driver.id, "get_device_settings")
+	}
+	return settings, err
+}
+
+func (s instrumentedSettingsManager) SetDeviceSettings(ctx context.Context, settings DeviceSettings) error {
+	err := s.settings.SetDeviceSettings(ctx, settings)
+	if err != nil {
+		s.driver.metrics.DeviceError(s.driver.id, "set_device_settings")
+	}
+	return err
+}
+
+// unwrapRetries returns the driver wrapped for retries, or driver itself.
+func unwrapRetries(driver LightDriver) LightDriver {
+	if rd, ok := driver.(*resilientDriver); ok {
+		return rd.LightDriver
+	}
+	return driver
+}
@@ -0,0 +1,44 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingRecorder struct {
+	mu       sync.Mutex
+	attempts int
+	errors   map[string]int
+	dropped  map[string]int
+}
+
+func (r *countingRecorder) DiscoveryAttempt() { r.attempts++ }
+
+func (r *countingRecorder) DeviceError(lightID, operation string) {
+	r.errors[lightID+"/"+operation]++
+}
+
+func (r *countingRecorder) UpdateDropped(lightID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropped[lightID]++
+}
+
+func TestManager_RecordsDeviceErrors(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 64})
+	host, port := hostPort(t, srv)
+
+	rec := &countingRecorder{errors: map[string]int{}}
+	m := NewManager(discardLogger())
+	m.SetMetrics(rec)
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	assert.Empty(t, rec.errors)
+
+	srv.Close()
+	assert.Error(t, m.SetLightPower(context.Background(), "strip", false))
+	assert.Positive(t, rec.errors["strip/get_light_state"]+rec.errors["strip/set_light_state"])
+}
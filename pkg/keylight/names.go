@@ -0,0 +1,161 @@
+package keylight
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// MaxLightNameLength is the longest display name, in characters, that can be
+// set with SetLightName.
+const MaxLightNameLength = 64
+
+// SetNameOverrides loads user-chosen display names, keyed by light ID, and sets
+// the function used to persist changes made with SetLightName. persist is
+// called with an empty name when an override is removed. Overrides take
+// precedence over the name reported by the device. It must be set before
+// discovery starts.
+func (m *Manager) SetNameOverrides(names map[string]string, persist func(id, name string) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.names = maps.Clone(names)
+	m.persistName = persist
+}
+
+// SetLightName sets a display name for a light that overrides the name
+// reported by the device, and persists it. An empty name removes the override
+// and restores the device's own name.
+func (m *Manager) SetLightName(ctx context.Context, id, name string) (*Light, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > MaxLightNameLength {
+		return nil, errors.InvalidInputf("light name must be at most %d characters", MaxLightNameLength)
+	}
+
+	client, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := name
+	if name == "" {
+		// Device I/O happens before taking the lock
+		displayName = m.deviceName(ctx, client, light)
+	}
+
+	m.mu.Lock()
+	updated, exists := m.lights[id]
+	if !exists {
+		m.mu.Unlock()
+		return nil, errors.NotFoundf("light %s not found", id)
+	}
+	if name == "" {
+		delete(m.names, id)
+	} else {
+		if m.names == nil {
+			m.names = make(map[string]string)
+		}
+		m.names[id] = name
+	}
+	updated.Name = displayName
+	m.lights[id] = updated
+	persist := m.persistName
+	m.mu.Unlock()
+
+	if persist != nil {
+		if err := persist(id, name); err != nil {
+			return nil, errors.Internalf("failed to save name for light %s: %w", id, err)
+		}
+	}
+
+	m.logger.InfoContext(ctx, "light: display name changed", "id", id, "name", displayName, "override", name != "")
+	m.emit(events.LightStateChanged, &updated)
+	return &updated, nil
+}
+
+// SetDeviceName changes the display name stored on the light itself, for
+// drivers that support it. Unlike SetLightName the name is visible to other
+// apps controlling the light. A name override set with SetLightName still
+// takes precedence within keylightd.
+func (m *Manager) SetDeviceName(ctx context.Context, id, name string) (*Light, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.InvalidInputf("device name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > MaxLightNameLength {
+		return nil, errors.InvalidInputf("light name must be at most %d characters", MaxLightNameLength)
+	}
+
+	client, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return nil, err
+	}
+	setter, ok := displayNameSetter(client)
+	if !ok {
+		return nil, errors.InvalidInputf("light %s (driver %s) does not support changing the device name", id, light.Driver)
+	}
+
+	unlock, err := m.lockLight(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := setter.SetDisplayName(ctx, name); err != nil {
+		return nil, errors.LogErrorAndReturnContext(
+			ctx,
+			m.logger,
+			errors.DeviceUnavailablef("failed to set device name: %w", err),
+			"light: failed to set device name",
+			"id", id,
+		)
+	}
+
+	m.mu.Lock()
+	updated, exists := m.lights[id]
+	if !exists {
+		m.mu.Unlock()
+		return nil, errors.NotFoundf("light %s not found", id)
+	}
+	if !updated.Static || updated.Name == "" {
+		updated.Name = name
+	}
+	m.applyNameOverride(&updated)
+	m.lights[id] = updated
+	m.mu.Unlock()
+
+	m.logger.InfoContext(ctx, "light: device name changed", "id", id, "name", name)
+	m.emit(events.LightStateChanged, &updated)
+	return &updated, nil
+}
+
+// deviceName returns the name a light has without an override: the configured
+// name of a static light, otherwise the display name reported by the device.
+// It returns an empty name if the device cannot be reached; the name is then
+// filled in when the light is next discovered.
+func (m *Manager) deviceName(ctx context.Context, client LightDriver, light *Light) string {
+	m.mu.RLock()
+	for _, s := range m.static {
+		if s.ID == light.ID && s.Name != "" {
+			m.mu.RUnlock()
+			return s.Name
+		}
+	}
+	m.mu.RUnlock()
+
+	info, err := m.fetchAccessoryInfo(ctx, client, light.ID)
+	if err != nil || info == nil {
+		return ""
+	}
+	return UnescapeRFC6763Label(info.DisplayName)
+}
+
+// applyNameOverride replaces a light's name with its override, if it has one.
+// Requires m.mu to be held by the caller.
+func (m *Manager) applyNameOverride(light *Light) {
+	if name, ok := m.names[light.ID]; ok {
+		light.Name = name
+	}
+}
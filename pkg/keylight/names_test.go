@@ -0,0 +1,111 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+func TestSetLightName(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	saved := map[string]string{}
+	m := NewManager(discardLogger())
+	m.SetNameOverrides(nil, func(id, name string) error {
+		saved[id] = name
+		return nil
+	})
+	bus := events.NewBus()
+	m.SetEventBus(bus)
+	getEvents := collectEvents(bus)
+
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	require.Equal(t, "Desk Strip", m.GetLights()["strip"].Name)
+
+	light, err := m.SetLightName(context.Background(), "strip", "  Shelf  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Shelf", light.Name)
+	assert.Equal(t, "Shelf", saved["strip"])
+
+	// Rediscovery keeps the override
+	m.AddLight(context.Background(), Light{ID: "strip", Name: "Desk Strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	assert.Equal(t, "Shelf", m.GetLights()["strip"].Name)
+
+	// An empty name restores the device's name
+	light, err = m.SetLightName(context.Background(), "strip", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Desk Strip", light.Name)
+	assert.Contains(t, saved, "strip")
+	assert.Empty(t, saved["strip"])
+
+	var changed int
+	for _, e := range getEvents() {
+		if e.Type == events.LightStateChanged {
+			changed++
+		}
+	}
+	assert.Equal(t, 2, changed)
+}
+
+func TestSetLightName_Errors(t *testing.T) {
+	m := NewManager(discardLogger())
+	m.lights["light-1"] = Light{ID: "light-1", Name: "Light"}
+
+	_, err := m.SetLightName(context.Background(), "nope", "Desk")
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = m.SetLightName(context.Background(), "light-1", strings.Repeat("x", MaxLightNameLength+1))
+	assert.True(t, errors.IsInvalidInput(err))
+	assert.Equal(t, "Light", m.GetLights()["light-1"].Name)
+}
+
+func TestSetNameOverrides_AppliedOnDiscovery(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.SetNameOverrides(map[string]string{"strip": "Shelf"}, nil)
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	assert.Equal(t, "Shelf", m.GetLights()["strip"].Name)
+}
+
+func TestSetDeviceName(t *testing.T) {
+	srv := mockHTTPServer(t)
+	defer srv.Close()
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.lights["key"] = Light{ID: "key", Name: "Office Key Light", IP: net.ParseIP(host), Port: port}
+
+	light, err := m.SetDeviceName(context.Background(), "key", "Desk Left")
+	require.NoError(t, err)
+	assert.Equal(t, "Desk Left", light.Name)
+
+	// A local override still wins
+	m.SetNameOverrides(map[string]string{"key": "Mine"}, nil)
+	light, err = m.SetDeviceName(context.Background(), "key", "Desk Right")
+	require.NoError(t, err)
+	assert.Equal(t, "Mine", light.Name)
+
+	_, err = m.SetDeviceName(context.Background(), "key", " ")
+	assert.True(t, errors.IsInvalidInput(err))
+}
+
+func TestSetDeviceName_Unsupported(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.lights["strip"] = Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED}
+
+	_, err := m.SetDeviceName(context.Background(), "strip", "Shelf")
+	assert.True(t, errors.IsInvalidInput(err))
+}
@@ -0,0 +1,72 @@
+package keylight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ParkState is the state lights are left in when the daemon stops. Zero
+// brightness or temperature keep the light's own.
+type ParkState struct {
+	On          bool
+	Brightness  int
+	Temperature int // Kelvin
+}
+
+// ParkLights sets every light that isn't offline to state, in parallel, and
+// returns once all are done or ctx ends. It doesn't touch the saved states,
+// so with state restore enabled the lights go back to how they were before
+// parking the next time keylightd starts; call SaveLightStates first.
+func (m *Manager) ParkLights(ctx context.Context, state ParkState) error {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.lights))
+	for id, light := range m.lights {
+		if light.Status != ReachabilityOffline && light.State != nil {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, id := range ids {
+		wg.Go(func() {
+			if err := m.parkLight(ctx, id, state); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("light %s: %w", id, err))
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// parkLight sets one light to state, keeping its brightness and temperature
+// where state leaves them unset.
+func (m *Manager) parkLight(ctx context.Context, id string, state ParkState) error {
+	_, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return err
+	}
+	setTemperature := state.Temperature != 0 && light.Supports(PropertyTemperature)
+	_, err = m.modifyLightState(ctx, id, "park", func(current *LightState) {
+		l := &current.Lights[0]
+		l.On = boolToInt(state.On)
+		if state.Brightness != 0 {
+			l.Brightness = state.Brightness
+		}
+		if setTemperature {
+			// Like setting a temperature directly, this switches a color
+			// light back to white
+			l.Temperature = convertTemperatureToDevice(state.Temperature)
+			l.Hue, l.Saturation = nil, nil
+		}
+	})
+	return err
+}
@@ -0,0 +1,50 @@
+package keylight
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+func TestParkLights(t *testing.T) {
+	m := NewManager(discardLogger())
+	var persisted []map[string]config.SavedLightState
+	m.EnableStateRestore(nil, func(states map[string]config.SavedLightState) error {
+		persisted = append(persisted, states)
+		return nil
+	})
+	_, err := m.AddVirtualLights(context.Background(), VirtualLightOptions{Count: 2})
+	require.NoError(t, err)
+	require.NoError(t, m.SetLightState(context.Background(), "virtual-1", OnValue(true)))
+	require.NoError(t, m.SetLightBrightness(context.Background(), "virtual-1", 80))
+	require.NoError(t, m.SaveLightStates())
+	saved := persisted[len(persisted)-1]["virtual-1"]
+
+	require.NoError(t, m.ParkLights(context.Background(), ParkState{On: true, Brightness: 10, Temperature: 3000}))
+	for id, light := range m.GetLights() {
+		assert.True(t, light.On, id)
+		assert.Equal(t, 10, light.Brightness, id)
+		assert.Equal(t, convertTemperatureToDevice(3000), light.Temperature, id)
+	}
+
+	// Turning lights off keeps their brightness
+	require.NoError(t, m.ParkLights(context.Background(), ParkState{}))
+	light := m.GetLights()["virtual-2"]
+	assert.False(t, light.On)
+	assert.Equal(t, 10, light.Brightness)
+
+	got, ok := m.savedState("virtual-1")
+	require.True(t, ok)
+	assert.Equal(t, saved, got, "parking leaves the saved state to restore")
+}
+
+func TestParkLights_SkipsOfflineLights(t *testing.T) {
+	m := NewManager(discardLogger())
+	m.lights["gone"] = Light{ID: "gone", Status: ReachabilityOffline, State: &LightState{}}
+	m.lights["never"] = Light{ID: "never"}
+	assert.NoError(t, m.ParkLights(context.Background(), ParkState{}))
+}
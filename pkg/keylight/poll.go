@@ -0,0 +1,29 @@
+package keylight
+
+import (
+	"context"
+	"time"
+)
+
+// PollLights refreshes the state of every light that isn't offline each
+// interval until ctx is cancelled, so changes made with a light's buttons or
+// another app are noticed and published as light.state_changed events. Polls
+// are skipped while no clients are active, see SetDemand, leaving discovery
+// to pick up changes at its idle interval.
+func (m *Manager) PollLights(ctx context.Context, interval time.Duration) {
+	m.logger.Info("light: polling light state", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !m.clientsActive() {
+				continue
+			}
+			m.RefreshLights(ctx)
+		}
+	}
+}
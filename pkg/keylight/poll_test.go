@@ -0,0 +1,78 @@
+package keylight
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// buttonDriver reports a brightness that can be changed behind the manager's
+// back, as with a light's buttons.
+type buttonDriver struct {
+	LightDriver
+	brightness atomic.Int32
+}
+
+func (d *buttonDriver) GetLightState(context.Context) (*LightState, error) {
+	return &LightState{NumberOfLights: 1, Lights: []LightStateEntry{{On: 1, Brightness: int(d.brightness.Load()), Temperature: 200}}}, nil
+}
+
+func TestGetLight_EmitsExternalChanges(t *testing.T) {
+	m := NewManager(discardLogger())
+	m.stateTTL = 0
+	bus := events.NewBus()
+	m.SetEventBus(bus)
+	getEvents := collectEvents(bus)
+
+	driver := &buttonDriver{}
+	driver.brightness.Store(40)
+	addCountedLight(m, "light-1", driver)
+
+	// The first read only learns the state
+	_, err := m.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	_, err = m.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Empty(t, getEvents())
+
+	driver.brightness.Store(80)
+	light, err := m.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, 80, light.Brightness)
+	evts := getEvents()
+	require.Len(t, evts, 1)
+	assert.Equal(t, events.LightStateChanged, evts[0].Type)
+}
+
+func TestPollLights(t *testing.T) {
+	m := NewManager(discardLogger())
+	m.stateTTL = 0
+	driver := &flakyDriver{}
+	addCountedLight(m, "light-1", driver)
+
+	active := atomic.Bool{}
+	m.SetDemand(active.Load, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.PollLights(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+
+	// Nothing is polled while clients are idle
+	time.Sleep(30 * time.Millisecond)
+	assert.Zero(t, driver.calls.Load())
+
+	active.Store(true)
+	require.Eventually(t, func() bool { return driver.calls.Load() >= 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
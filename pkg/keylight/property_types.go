@@ -18,6 +18,15 @@ const (
 
 	// PropertyTemperature represents the light color temperature
 	PropertyTemperature PropertyName = "temperature"
+
+	// PropertyHue represents the hue of a color light
+	PropertyHue PropertyName = "hue"
+
+	// PropertySaturation represents the saturation of a color light
+	PropertySaturation PropertyName = "saturation"
+
+	// PropertyColor represents the hue and saturation of a color light together
+	PropertyColor PropertyName = "color"
 )
 
 // LightPropertyValue is an interface for all possible light property values
@@ -94,10 +103,93 @@ func (v TemperatureValue) Validate() error {
 	return nil
 }
 
+// HueValue represents the hue of a color in degrees. Setting it switches a
+// color light to showing a color.
+type HueValue float64
+
+// PropertyName returns the name of the property
+func (v HueValue) PropertyName() PropertyName {
+	return PropertyHue
+}
+
+// Value returns the underlying float64 value
+func (v HueValue) Value() any {
+	return float64(v)
+}
+
+// Validate ensures the hue is within valid range
+func (v HueValue) Validate() error {
+	if v < 0 || v > config.MaxHue {
+		return fmt.Errorf("hue must be between 0 and %d, got %g", config.MaxHue, float64(v))
+	}
+	return nil
+}
+
+// SaturationValue represents the saturation of a color as a percentage.
+// Setting it switches a color light to showing a color.
+type SaturationValue float64
+
+// PropertyName returns the name of the property
+func (v SaturationValue) PropertyName() PropertyName {
+	return PropertySaturation
+}
+
+// Value returns the underlying float64 value
+func (v SaturationValue) Value() any {
+	return float64(v)
+}
+
+// Validate ensures the saturation is within valid range
+func (v SaturationValue) Validate() error {
+	if v < 0 || v > config.MaxSaturation {
+		return fmt.Errorf("saturation must be between 0 and %d, got %g", config.MaxSaturation, float64(v))
+	}
+	return nil
+}
+
+// ColorValue sets the hue and saturation of a color light in one update.
+type ColorValue struct {
+	Hue        HueValue
+	Saturation SaturationValue
+}
+
+// PropertyName returns the name of the property
+func (v ColorValue) PropertyName() PropertyName {
+	return PropertyColor
+}
+
+// Value returns the ColorValue itself
+func (v ColorValue) Value() any {
+	return v
+}
+
+// Validate ensures the hue and saturation are within valid range
+func (v ColorValue) Validate() error {
+	if err := v.Hue.Validate(); err != nil {
+		return err
+	}
+	return v.Saturation.Validate()
+}
+
+// ColorChange returns the property value that sets whichever of hue and
+// saturation are non-nil, or false if both are nil.
+func ColorChange(hue, saturation *float64) (LightPropertyValue, bool) {
+	switch {
+	case hue != nil && saturation != nil:
+		return ColorValue{Hue: HueValue(*hue), Saturation: SaturationValue(*saturation)}, true
+	case hue != nil:
+		return HueValue(*hue), true
+	case saturation != nil:
+		return SaturationValue(*saturation), true
+	default:
+		return nil, false
+	}
+}
+
 // ValidateProperty validates if the provided property name is valid
 func ValidateProperty(property PropertyName) error {
 	switch property {
-	case PropertyOn, PropertyBrightness, PropertyTemperature:
+	case PropertyOn, PropertyBrightness, PropertyTemperature, PropertyHue, PropertySaturation, PropertyColor:
 		return nil
 	default:
 		return fmt.Errorf("unknown property: %s", property)
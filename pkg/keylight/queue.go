@@ -0,0 +1,77 @@
+package keylight
+
+import (
+	"context"
+	"slices"
+)
+
+// Commands that write to a light, or read its state to change it, go through
+// the light's queue, so they run one at a time and in the order they arrived.
+// Without it, concurrent read-modify-write cycles on the same light could
+// interleave and lose updates, and a transition step could land after a
+// direct change that superseded it. Different lights have separate queues and
+// are still changed in parallel.
+
+// lightQueue is the queue of commands for one light. Guarded by
+// Manager.queuesMu.
+type lightQueue struct {
+	busy    bool            // a command holds the light
+	waiting []chan struct{} // commands waiting for it, oldest first; closed to hand the light over
+}
+
+// lockLight waits until the light's earlier commands are done and returns the
+// function to call when this one is. It fails with ctx's error if ctx ends
+// first, leaving the command's place in the queue to the next one.
+func (m *Manager) lockLight(ctx context.Context, id string) (func(), error) {
+	m.queuesMu.Lock()
+	if m.queues == nil {
+		m.queues = make(map[string]*lightQueue)
+	}
+	q, ok := m.queues[id]
+	if !ok {
+		q = &lightQueue{}
+		m.queues[id] = q
+	}
+	unlock := func() { m.unlockLight(id, q) }
+	if !q.busy {
+		q.busy = true
+		m.queuesMu.Unlock()
+		return unlock, nil
+	}
+	ready := make(chan struct{})
+	q.waiting = append(q.waiting, ready)
+	m.queuesMu.Unlock()
+
+	select {
+	case <-ready:
+		return unlock, nil
+	case <-ctx.Done():
+	}
+
+	m.queuesMu.Lock()
+	select {
+	case <-ready:
+		// Handed the light just as ctx ended: pass it on.
+		m.queuesMu.Unlock()
+		unlock()
+	default:
+		q.waiting = slices.DeleteFunc(q.waiting, func(c chan struct{}) bool { return c == ready })
+		m.queuesMu.Unlock()
+	}
+	return nil, ctx.Err()
+}
+
+// unlockLight hands the light to the next command in its queue, or drops the
+// queue if there is none.
+func (m *Manager) unlockLight(id string, q *lightQueue) {
+	m.queuesMu.Lock()
+	defer m.queuesMu.Unlock()
+	if len(q.waiting) == 0 {
+		q.busy = false
+		delete(m.queues, id)
+		return
+	}
+	next := q.waiting[0]
+	q.waiting = q.waiting[1:]
+	close(next)
+}
@@ -0,0 +1,96 @@
+package keylight
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queued returns the number of commands waiting for a light.
+func (m *Manager) queued(id string) int {
+	m.queuesMu.Lock()
+	defer m.queuesMu.Unlock()
+	if q, ok := m.queues[id]; ok {
+		return len(q.waiting)
+	}
+	return 0
+}
+
+func TestLockLight_FIFO(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil)))
+	ctx := context.Background()
+
+	unlock, err := m.lockLight(ctx, "light1")
+	require.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i := range 5 {
+		wg.Go(func() {
+			unlock, err := m.lockLight(ctx, "light1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			unlock()
+		})
+		// Queue the commands one after another
+		require.Eventually(t, func() bool { return m.queued("light1") == i+1 }, time.Second, time.Millisecond)
+	}
+
+	// Other lights aren't held up
+	unlockOther, err := m.lockLight(ctx, "light2")
+	require.NoError(t, err)
+	unlockOther()
+
+	unlock()
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	assert.Empty(t, m.queues, "idle queues are dropped")
+}
+
+func TestLockLight_Cancelled(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil)))
+
+	unlock, err := m.lockLight(context.Background(), "light1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = m.lockLight(ctx, "light1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, m.queued("light1"), "a cancelled command leaves the queue")
+
+	unlock()
+	unlock, err = m.lockLight(context.Background(), "light1")
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestTransition_StepDoesNotOverwriteSupersedingChange(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 10, 200)
+	ctx := context.Background()
+
+	brightness := 90
+	require.NoError(t, m.Transition(ctx, "light1", StateChange{Brightness: &brightness}, 200*time.Millisecond))
+	require.Eventually(t, func() bool { return len(device.updates()) > 0 }, time.Second, time.Millisecond)
+	require.NoError(t, m.SetLightState(ctx, "light1", BrightnessValue(30)))
+
+	time.Sleep(50 * time.Millisecond)
+	updates := device.updates()
+	assert.Equal(t, 30, updates[len(updates)-1].Lights[0].Brightness, "the direct change must be the last write")
+	light, err := m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.Equal(t, 30, light.Brightness)
+}
@@ -0,0 +1,94 @@
+package keylight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// RawPathPrefix is the prefix every path passed to RawRequest must have.
+const RawPathPrefix = "/elgato/"
+
+// RawResponse is a device's response to a raw request.
+type RawResponse struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// RawRequest passes a request straight through to a light's own API, for
+// device settings keylightd doesn't model, such as /elgato/lights/settings or
+// /elgato/battery-info. Only GET, PUT and POST to paths under /elgato/ are
+// allowed, and a body must be JSON. The device's response is returned
+// whatever its status code.
+//
+// Changes made this way bypass keylightd, so the light's cached state is
+// marked stale after any request that isn't a GET.
+func (m *Manager) RawRequest(ctx context.Context, id, method, p string, body []byte) (*RawResponse, error) {
+	method = strings.ToUpper(method)
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodPost:
+	default:
+		return nil, errors.InvalidInputf("method must be GET, PUT or POST, got %q", method)
+	}
+	if err := validateRawPath(p); err != nil {
+		return nil, err
+	}
+	if len(body) > 0 {
+		if method == http.MethodGet {
+			return nil, errors.InvalidInputf("GET requests cannot have a body")
+		}
+		if !json.Valid(body) {
+			return nil, errors.InvalidInputf("request body must be valid JSON")
+		}
+	}
+
+	client, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return nil, err
+	}
+	requester, ok := rawRequester(client)
+	if !ok {
+		return nil, errors.InvalidInputf("light %s (driver %s) does not support raw requests", id, light.Driver)
+	}
+
+	// A raw request may change the light, so it waits its turn like any other.
+	unlock, err := m.lockLight(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	m.logger.InfoContext(ctx, "light: raw request", "id", id, "method", method, "path", p)
+	resp, err := requester.RawRequest(ctx, method, strings.TrimPrefix(p, "/elgato"), body)
+	if err != nil {
+		return nil, errors.LogErrorAndReturnContext(
+			ctx,
+			m.logger,
+			errors.DeviceUnavailablef("raw request failed: %w", err),
+			"light: raw request failed",
+			"id", id,
+		)
+	}
+
+	if method != http.MethodGet {
+		m.mu.Lock()
+		delete(m.refreshedAt, id)
+		m.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// validateRawPath checks that p is a clean path under /elgato/ with no query
+// or fragment, so a raw request can't reach anything else on the device.
+func validateRawPath(p string) error {
+	if !strings.HasPrefix(p, RawPathPrefix) || len(p) == len(RawPathPrefix) {
+		return errors.InvalidInputf("path must start with %s", RawPathPrefix)
+	}
+	if strings.ContainsAny(p, "?#\\ ") || path.Clean(p) != p {
+		return errors.InvalidInputf("invalid path %q", p)
+	}
+	return nil
+}
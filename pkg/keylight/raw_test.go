@@ -0,0 +1,106 @@
+package keylight
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestRawRequest(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotPath, gotBody = r.Method, r.URL.Path, string(body)
+		switch r.URL.Path {
+		case "/elgato/lights/settings":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"powerOnBehavior":1,"powerOnBrightness":20}`))
+		case "/elgato/identify":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.lights["key"] = Light{ID: "key", IP: net.ParseIP(host), Port: port}
+	m.refreshedAt["key"] = m.lights["key"].LastSeen
+
+	resp, err := m.RawRequest(context.Background(), "key", "get", "/elgato/lights/settings", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"powerOnBehavior":1,"powerOnBrightness":20}`, string(resp.Body))
+	assert.Equal(t, http.MethodGet, gotMethod)
+	assert.Contains(t, m.refreshedAt, "key", "GET should keep the cached state")
+
+	resp, err = m.RawRequest(context.Background(), "key", "PUT", "/elgato/lights/settings", []byte(`{"powerOnBehavior":2}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/elgato/lights/settings", gotPath)
+	assert.Equal(t, `{"powerOnBehavior":2}`, gotBody)
+	assert.NotContains(t, m.refreshedAt, "key", "writes should mark the cached state stale")
+
+	resp, err = m.RawRequest(context.Background(), "key", "POST", "/elgato/identify", nil)
+	require.NoError(t, err)
+	assert.Empty(t, resp.Body)
+
+	// Device errors are passed through rather than returned as errors
+	resp, err = m.RawRequest(context.Background(), "key", "GET", "/elgato/battery-info", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.JSONEq(t, `"not found\n"`, string(resp.Body))
+}
+
+func TestRawRequest_Invalid(t *testing.T) {
+	srv := mockHTTPServer(t)
+	defer srv.Close()
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.lights["key"] = Light{ID: "key", IP: net.ParseIP(host), Port: port}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"method", "DELETE", "/elgato/lights", ""},
+		{"outside elgato", "GET", "/status", ""},
+		{"bare prefix", "GET", "/elgato/", ""},
+		{"traversal", "GET", "/elgato/../status", ""},
+		{"query", "GET", "/elgato/lights?x=1", ""},
+		{"get with body", "GET", "/elgato/lights", `{}`},
+		{"invalid json", "PUT", "/elgato/lights", `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.RawRequest(context.Background(), "key", tt.method, tt.path, []byte(tt.body))
+			assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+		})
+	}
+
+	_, err := m.RawRequest(context.Background(), "nope", "GET", "/elgato/lights", nil)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestRawRequest_Unsupported(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.lights["strip"] = Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED}
+
+	_, err := m.RawRequest(context.Background(), "strip", "GET", "/elgato/lights", nil)
+	assert.True(t, errors.IsInvalidInput(err))
+}
@@ -0,0 +1,132 @@
+package keylight
+
+import (
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// Reachability describes whether a light is currently responding.
+//
+// A light is online while it answers requests. A failed request makes it
+// degraded, and a successful one brings it back online. A light that has not
+// been seen for the cleanup timeout is offline; it is still listed, so groups
+// and schedules keep it, until it has been offline for the offline retention
+// and is forgotten. Static lights go offline but are never forgotten.
+type Reachability string
+
+const (
+	ReachabilityOnline   Reachability = "online"
+	ReachabilityDegraded Reachability = "degraded"
+	ReachabilityOffline  Reachability = "offline"
+)
+
+// SetOfflineRetention sets how long an offline light is kept before it is
+// removed; zero or less uses the default. It must be called before the
+// cleanup worker starts.
+func (m *Manager) SetOfflineRetention(d time.Duration) {
+	if d <= 0 {
+		d = config.DefaultOfflineRetention
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.offlineRetention = d
+}
+
+// noteRequest updates a light's reachability after a device request. A
+// successful request brings the light online; a failure degrades an online
+// light and stops its cached state being reused.
+func (m *Manager) noteRequest(id string, err error) {
+	m.mu.Lock()
+	light, exists := m.lights[id]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+	status := light.Status
+	switch {
+	case err == nil:
+		status = ReachabilityOnline
+		light.LastSeen = time.Now()
+	case status == ReachabilityOnline || status == "":
+		status = ReachabilityDegraded
+	}
+	if err != nil {
+		delete(m.refreshedAt, id)
+	}
+	// Lights without a status yet have nothing to change from
+	changed := light.Status != "" && status != light.Status
+	light.Status = status
+	m.lights[id] = light
+	m.mu.Unlock()
+
+	if changed {
+		m.logger.Info("light: reachability changed", "id", id, "status", status)
+		m.emit(events.LightStateChanged, &light)
+	}
+}
+
+// cleanupStaleLights marks lights that haven't been seen within timeout as
+// offline, and removes non-static lights that have been offline for longer
+// than the offline retention.
+func (m *Manager) cleanupStaleLights(timeout time.Duration) {
+	// Use default timeout if the provided one is invalid
+	if timeout <= 0 {
+		m.logger.Debug("Invalid cleanup timeout, using default",
+			"provided", timeout,
+			"default", config.DefaultStateTimeout)
+		timeout = config.DefaultStateTimeout
+	}
+
+	now := time.Now()
+
+	// First identify stale lights with read lock to minimize lock duration
+	m.mu.RLock()
+	retention := m.offlineRetention
+	var stale []string
+	for id, light := range m.lights {
+		if now.Sub(light.LastSeen) > timeout {
+			stale = append(stale, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	// If no stale lights, return quickly without acquiring write lock
+	if len(stale) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	var offline, removed []Light
+	for _, id := range stale {
+		light, exists := m.lights[id]
+		// Re-check the timeout in case the light was seen while we were unlocked
+		if !exists || now.Sub(light.LastSeen) <= timeout {
+			continue
+		}
+		if !light.Static && now.Sub(light.LastSeen) > timeout+retention {
+			m.logger.Info("Removing light that has been offline too long", "id", id, "lastseen", light.LastSeen)
+			removed = append(removed, light)
+			delete(m.lights, id)
+			delete(m.clients, id)
+			delete(m.refreshedAt, id)
+			continue
+		}
+		if light.Status != ReachabilityOffline {
+			m.logger.Info("Light is offline", "id", id, "lastseen", light.LastSeen)
+			light.Status = ReachabilityOffline
+			m.lights[id] = light
+			offline = append(offline, light)
+		}
+	}
+	m.mu.Unlock()
+
+	// Emit events outside the lock
+	for i := range offline {
+		m.emit(events.LightStateChanged, &offline[i])
+	}
+	for i := range removed {
+		m.emit(events.LightRemoved, &removed[i])
+	}
+}
@@ -0,0 +1,102 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+func TestReachability_DegradedAndRecovered(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	require.Equal(t, ReachabilityOnline, m.GetLights()["strip"].Status)
+
+	bus := events.NewBus()
+	m.SetEventBus(bus)
+	getEvents := collectEvents(bus)
+
+	m.noteRequest("strip", assert.AnError)
+	assert.Equal(t, ReachabilityDegraded, m.GetLights()["strip"].Status)
+
+	// Further failures don't change the status again
+	m.noteRequest("strip", assert.AnError)
+
+	_, err := m.GetLight(context.Background(), "strip")
+	require.NoError(t, err)
+	assert.Equal(t, ReachabilityOnline, m.GetLights()["strip"].Status)
+
+	evts := getEvents()
+	require.Len(t, evts, 2)
+	assert.Equal(t, events.LightStateChanged, evts[0].Type)
+	assert.Equal(t, events.LightStateChanged, evts[1].Type)
+}
+
+func TestCleanupStaleLights_MarksOffline(t *testing.T) {
+	m := NewManager(discardLogger())
+	bus := events.NewBus()
+	m.SetEventBus(bus)
+	getEvents := collectEvents(bus)
+
+	m.lights["stale"] = Light{ID: "stale", Status: ReachabilityOnline, LastSeen: time.Now().Add(-10 * time.Minute)}
+	m.cleanupStaleLights(5 * time.Minute)
+
+	lights := m.GetLights()
+	require.Contains(t, lights, "stale", "offline lights are kept within the retention")
+	assert.Equal(t, ReachabilityOffline, lights["stale"].Status)
+	assert.Equal(t, 0, m.discoveredCount())
+
+	// Already offline lights are left alone
+	m.cleanupStaleLights(5 * time.Minute)
+	evts := getEvents()
+	require.Len(t, evts, 1)
+	assert.Equal(t, events.LightStateChanged, evts[0].Type)
+
+	// Once the retention has passed the light is removed
+	m.SetOfflineRetention(time.Minute)
+	m.cleanupStaleLights(5 * time.Minute)
+	assert.NotContains(t, m.GetLights(), "stale")
+}
+
+func TestAddLight_OfflineLightComesBack(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	strip := Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED}
+	m.AddLight(context.Background(), strip)
+
+	m.mu.Lock()
+	l := m.lights["strip"]
+	l.LastSeen = time.Now().Add(-time.Hour)
+	m.lights["strip"] = l
+	m.mu.Unlock()
+	m.cleanupStaleLights(time.Minute)
+	require.Equal(t, ReachabilityOffline, m.GetLights()["strip"].Status)
+
+	m.AddLight(context.Background(), strip)
+	light := m.GetLights()["strip"]
+	assert.Equal(t, ReachabilityOnline, light.Status)
+	assert.WithinDuration(t, time.Now(), light.LastSeen, time.Minute)
+}
+
+func TestSetOfflineRetention_ZeroUsesDefault(t *testing.T) {
+	m := NewManager(discardLogger())
+	m.SetOfflineRetention(0)
+	assert.Equal(t, config.DefaultOfflineRetention, m.offlineRetention)
+
+	// An unset retention doesn't remove lights as soon as they go offline
+	m.lights["stale"] = Light{ID: "stale", Status: ReachabilityOnline, LastSeen: time.Now().Add(-10 * time.Minute)}
+	m.cleanupStaleLights(5 * time.Minute)
+	require.Contains(t, m.GetLights(), "stale")
+	assert.Equal(t, ReachabilityOffline, m.GetLights()["stale"].Status)
+}
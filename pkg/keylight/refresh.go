@@ -0,0 +1,102 @@
+package keylight
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// refreshCall is a refresh of one light in progress.
+type refreshCall struct {
+	done  chan struct{}
+	light *Light
+	err   error
+}
+
+// GetLight returns a light by ID with its state refreshed from the device. A
+// state read within the last few seconds is reused, and concurrent calls for
+// the same light share a single device request, so rapid successive reads
+// don't hammer the device.
+func (m *Manager) GetLight(ctx context.Context, id string) (*Light, error) {
+	light, err := m.getLight(ctx, id)
+	return m.withLimits(light), err
+}
+
+func (m *Manager) getLight(ctx context.Context, id string) (*Light, error) {
+	m.mu.RLock()
+	light, exists := m.lights[id]
+	refreshed := m.refreshedAt[id]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, errors.NotFoundf("light %s not found", id)
+	}
+	if time.Since(refreshed) < m.stateTTL {
+		return &light, nil
+	}
+
+	m.refreshMu.Lock()
+	if m.refreshes == nil {
+		m.refreshes = make(map[string]*refreshCall)
+	}
+	if call, ok := m.refreshes[id]; ok {
+		m.refreshMu.Unlock()
+		select {
+		case <-call.done:
+			return call.light, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	m.refreshes[id] = call
+	m.refreshMu.Unlock()
+
+	call.light, call.err = m.fetchLight(ctx, id)
+
+	m.refreshMu.Lock()
+	delete(m.refreshes, id)
+	m.refreshMu.Unlock()
+	close(call.done)
+
+	return call.light, call.err
+}
+
+// RefreshLights refreshes the state of every light that isn't offline, several
+// at a time, and returns all lights. Lights that cannot be reached keep their
+// last known state.
+func (m *Manager) RefreshLights(ctx context.Context) map[string]*Light {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.lights))
+	for id, light := range m.lights {
+		if light.Status != ReachabilityOffline {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for range min(config.DefaultRefreshWorkers, len(ids)) {
+		wg.Go(func() {
+			for id := range queue {
+				// Errors are logged by fetchLight and leave the light as it was
+				_, _ = m.GetLight(ctx, id)
+			}
+		})
+	}
+send:
+	for _, id := range ids {
+		select {
+		case queue <- id:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return m.GetLights()
+}
@@ -0,0 +1,93 @@
+package keylight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingDriver holds state requests until release is closed.
+type blockingDriver struct {
+	flakyDriver
+	release chan struct{}
+}
+
+func (d *blockingDriver) GetLightState(ctx context.Context) (*LightState, error) {
+	<-d.release
+	return d.flakyDriver.GetLightState(ctx)
+}
+
+func addCountedLight(m *Manager, id string, driver LightDriver) {
+	m.lights[id] = Light{ID: id, ProductName: "Elgato Key Light", SerialNumber: id, Status: ReachabilityOnline}
+	m.clients[id] = driver
+}
+
+func TestGetLight_ReusesRecentState(t *testing.T) {
+	m := NewManager(discardLogger())
+	driver := &flakyDriver{}
+	addCountedLight(m, "light-1", driver)
+
+	for range 3 {
+		_, err := m.GetLight(context.Background(), "light-1")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), driver.calls.Load())
+
+	m.stateTTL = 0
+	_, err := m.GetLight(context.Background(), "light-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), driver.calls.Load())
+}
+
+func TestGetLight_SharesConcurrentRefreshes(t *testing.T) {
+	m := NewManager(discardLogger())
+	driver := &blockingDriver{release: make(chan struct{})}
+	addCountedLight(m, "light-1", driver)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, err := m.GetLight(context.Background(), "light-1")
+			assert.NoError(t, err)
+		})
+	}
+	// Let the callers pile up behind the first refresh
+	require.Eventually(t, func() bool {
+		m.refreshMu.Lock()
+		defer m.refreshMu.Unlock()
+		return len(m.refreshes) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(driver.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), driver.calls.Load())
+}
+
+func TestRefreshLights(t *testing.T) {
+	m := NewManager(discardLogger())
+	drivers := make(map[string]*flakyDriver)
+	for i := range 20 {
+		id := fmt.Sprintf("light-%d", i)
+		drivers[id] = &flakyDriver{}
+		addCountedLight(m, id, drivers[id])
+	}
+	offline := m.lights["light-0"]
+	offline.Status = ReachabilityOffline
+	m.lights["light-0"] = offline
+
+	lights := m.RefreshLights(context.Background())
+	assert.Len(t, lights, 20, "offline lights are still listed")
+	for id, driver := range drivers {
+		want := int32(1)
+		if id == "light-0" {
+			want = 0
+		}
+		assert.Equal(t, want, driver.calls.Load(), id)
+	}
+}
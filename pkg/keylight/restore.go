@@ -0,0 +1,102 @@
+package keylight
+
+import (
+	"context"
+	"maps"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// EnableStateRestore makes the manager remember each light's last known state
+// and re-apply it when a light it does not currently know is added, such as a
+// light that was power cycled and rediscovered or every light after keylightd
+// restarts. saved holds the states persisted by a previous run; persist is
+// called with all known states whenever they change. It must be called before
+// discovery starts.
+func (m *Manager) EnableStateRestore(saved map[string]config.SavedLightState, persist func(map[string]config.SavedLightState) error) {
+	m.savedMu.Lock()
+	defer m.savedMu.Unlock()
+	m.savedStates = maps.Clone(saved)
+	if m.savedStates == nil {
+		m.savedStates = make(map[string]config.SavedLightState)
+	}
+	m.persistStates = persist
+}
+
+// SaveLightStates records the current state of every known light and persists
+// the states if any changed. It does nothing unless state restore is enabled.
+// The cleanup worker calls it periodically; call it on shutdown to keep the
+// latest states.
+func (m *Manager) SaveLightStates() error {
+	m.mu.RLock()
+	lights := make([]Light, 0, len(m.lights))
+	for _, light := range m.lights {
+		lights = append(lights, light)
+	}
+	m.mu.RUnlock()
+	return m.recordStates(lights)
+}
+
+// recordStates updates the saved states from the given lights and persists
+// them if any changed.
+func (m *Manager) recordStates(lights []Light) error {
+	m.savedMu.Lock()
+	defer m.savedMu.Unlock()
+	if m.savedStates == nil {
+		return nil
+	}
+
+	changed := false
+	for _, light := range lights {
+		if light.State == nil {
+			// Never reached, so there is no state worth keeping
+			continue
+		}
+		s := config.SavedLightState{On: light.On, Brightness: light.Brightness, Temperature: light.Temperature}
+		if prev, ok := m.savedStates[light.ID]; !ok || prev != s {
+			m.savedStates[light.ID] = s
+			changed = true
+		}
+	}
+	if !changed || m.persistStates == nil {
+		return nil
+	}
+	return m.persistStates(maps.Clone(m.savedStates))
+}
+
+// savedState returns the saved state of a light, if state restore is enabled
+// and one was recorded.
+func (m *Manager) savedState(id string) (config.SavedLightState, bool) {
+	m.savedMu.Lock()
+	defer m.savedMu.Unlock()
+	s, ok := m.savedStates[id]
+	return s, ok
+}
+
+// restoreState re-applies a light's saved state if it differs from the state
+// the device reports, updating light to match. It is called for lights the
+// manager does not already know about.
+func (m *Manager) restoreState(ctx context.Context, client LightDriver, light *Light) {
+	saved, ok := m.savedState(light.ID)
+	if !ok || light.State == nil || len(light.State.Lights) == 0 {
+		return
+	}
+	saved.Brightness, saved.Temperature = m.clampToLimits(light, saved.Brightness, saved.Temperature)
+	if light.On == saved.On && light.Brightness == saved.Brightness && light.Temperature == saved.Temperature {
+		return
+	}
+
+	if err := client.SetLightState(ctx, saved.On, saved.Brightness, saved.Temperature); err != nil {
+		m.logger.Warn("light: failed to restore state", "id", light.ID, "error", err)
+		return
+	}
+	m.logger.Info("light: restored last known state", "id", light.ID,
+		"on", saved.On, "brightness", saved.Brightness, "temperature", saved.Temperature)
+
+	light.On = saved.On
+	light.Brightness = saved.Brightness
+	light.Temperature = saved.Temperature
+	light.State.Lights[0].On = boolToInt(saved.On)
+	light.State.Lights[0].Brightness = saved.Brightness
+	light.State.Lights[0].Temperature = saved.Temperature
+}
@@ -0,0 +1,78 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+func TestAddLight_RestoresSavedState(t *testing.T) {
+	srv, posted := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.EnableStateRestore(map[string]config.SavedLightState{
+		"strip": {On: false, Brightness: 40, Temperature: 250},
+	}, nil)
+
+	strip := Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED}
+	m.AddLight(context.Background(), strip)
+
+	require.Len(t, *posted, 1)
+	assert.False(t, (*posted)[0].On)
+	light := m.GetLights()["strip"]
+	assert.False(t, light.On)
+	assert.Equal(t, 40, light.Brightness)
+	assert.Equal(t, 250, light.Temperature)
+
+	// Known lights are not restored on rediscovery
+	m.AddLight(context.Background(), strip)
+	assert.Len(t, *posted, 1)
+}
+
+func TestAddLight_NoRestoreWithoutSavedState(t *testing.T) {
+	srv, posted := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	assert.Empty(t, *posted)
+
+	m = NewManager(discardLogger())
+	m.EnableStateRestore(nil, nil)
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	assert.Empty(t, *posted)
+}
+
+func TestSaveLightStates(t *testing.T) {
+	m := NewManager(discardLogger())
+	require.NoError(t, m.SaveLightStates(), "disabled restore saves nothing")
+
+	var persisted []map[string]config.SavedLightState
+	m.EnableStateRestore(nil, func(states map[string]config.SavedLightState) error {
+		persisted = append(persisted, states)
+		return nil
+	})
+	m.lights["a"] = Light{ID: "a", On: true, Brightness: 60, Temperature: 200, State: &LightState{}}
+	m.lights["b"] = Light{ID: "b"} // never reached
+
+	require.NoError(t, m.SaveLightStates())
+	require.Len(t, persisted, 1)
+	assert.Equal(t, map[string]config.SavedLightState{"a": {On: true, Brightness: 60, Temperature: 200}}, persisted[0])
+
+	// Unchanged states are not persisted again
+	require.NoError(t, m.SaveLightStates())
+	assert.Len(t, persisted, 1)
+
+	// Removed lights keep their saved state
+	delete(m.lights, "a")
+	m.lights["c"] = Light{ID: "c", Brightness: 10, Temperature: 300, State: &LightState{}}
+	require.NoError(t, m.SaveLightStates())
+	require.Len(t, persisted, 2)
+	assert.Len(t, persisted[1], 2)
+}
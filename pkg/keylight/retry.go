@@ -0,0 +1,217 @@
+package keylight
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// ErrCircuitOpen is returned without contacting the light while requests to a
+// light that keeps failing are paused.
+var ErrCircuitOpen = errors.DeviceUnavailablef("light keeps failing, requests are paused")
+
+// RetryPolicy controls how failed requests to lights are retried. The zero
+// value tries each request once with the driver's own timeout.
+type RetryPolicy struct {
+	// Attempts is the number of tries per request, including the first.
+	Attempts int
+	// Backoff is the wait before the first retry. It doubles for each
+	// further retry, up to MaxBackoff, and is randomised by up to half to
+	// avoid retrying every light in a group in lockstep.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout limits each try. Zero leaves it to the driver.
+	Timeout time.Duration
+	// BreakerThreshold is the number of failed requests in a row after which
+	// requests to the light fail immediately with ErrCircuitOpen, so a dead
+	// light doesn't slow down group operations. Zero disables the breaker.
+	BreakerThreshold int
+	// BreakerCooldown is how long requests are paused before a single request
+	// is let through to see whether the light has recovered.
+	BreakerCooldown time.Duration
+}
+
+// SetRetryPolicy sets how requests to lights are retried. It must be called
+// before discovery starts. If not set, each request is tried once.
+func (m *Manager) SetRetryPolicy(p RetryPolicy) {
+	m.retry = p
+}
+
+// enabled reports whether the policy changes anything about a request.
+func (p RetryPolicy) enabled() bool {
+	return p.Attempts > 1 || p.Timeout > 0 || p.BreakerThreshold > 0
+}
+
+// backoff returns the wait before the given retry, starting at 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, p.MaxBackoff)
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Equal jitter: wait between half and all of the backoff
+	return d/2 + rand.N(d/2+1) //nolint:gosec // G404: jitter doesn't need a secure source
+}
+
+// breakerFor returns the circuit breaker for a light, creating it if needed.
+// Breakers outlive the light's driver, which is replaced on every rediscovery.
+func (m *Manager) breakerFor(id string) *breaker {
+	m.breakersMu.Lock()
+	defer m.breakersMu.Unlock()
+	if m.breakers == nil {
+		m.breakers = make(map[string]*breaker)
+	}
+	b, ok := m.breakers[id]
+	if !ok {
+		b = &breaker{threshold: m.retry.BreakerThreshold, cooldown: m.retry.BreakerCooldown}
+		m.breakers[id] = b
+	}
+	return b
+}
+
+// breaker pauses requests to a light after a run of failures.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a request may be sent. Once the cooldown has passed a
+// single request is let through; its outcome closes or reopens the breaker.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || now.Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record updates the breaker with the outcome of a request and reports
+// whether it opened as a result.
+func (b *breaker) record(now time.Time, err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures < b.threshold {
+		return false
+	}
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+// release lets another request through after a request ended without telling
+// anything about the light, such as when the caller gave up.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// resilientDriver retries failed requests and pauses requests to a light that
+// keeps failing.
+type resilientDriver struct {
+	LightDriver
+	id      string
+	policy  RetryPolicy
+	breaker *breaker
+	logger  *slog.Logger
+}
+
+func (d *resilientDriver) GetAccessoryInfo(ctx context.Context) (*AccessoryInfo, error) {
+	var info *AccessoryInfo
+	err := d.do(ctx, "get_accessory_info", func(ctx context.Context) error {
+		var err error
+		info, err = d.LightDriver.GetAccessoryInfo(ctx)
+		return err
+	})
+	return info, err
+}
+
+func (d *resilientDriver) GetLightState(ctx context.Context) (*LightState, error) {
+	var state *LightState
+	err := d.do(ctx, "get_light_state", func(ctx context.Context) error {
+		var err error
+		state, err = d.LightDriver.GetLightState(ctx)
+		return err
+	})
+	return state, err
+}
+
+func (d *resilientDriver) SetLightState(ctx context.Context, on bool, brightness, temperature int) error {
+	// Setting the whole state is idempotent, so it is safe to retry
+	return d.do(ctx, "set_light_state", func(ctx context.Context) error {
+		return d.LightDriver.SetLightState(ctx, on, brightness, temperature)
+	})
+}
+
+// do runs a request with retries, unless the breaker is open.
+func (d *resilientDriver) do(ctx context.Context, operation string, request func(context.Context) error) error {
+	if d.breaker != nil && !d.breaker.allow(time.Now()) {
+		return ErrCircuitOpen
+	}
+
+	attempts := max(d.policy.Attempts, 1)
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = d.try(ctx, request)
+		if err == nil || attempt == attempts || ctx.Err() != nil {
+			break
+		}
+
+		wait := d.policy.backoff(attempt)
+		d.logger.DebugContext(ctx, "light: retrying request", "id", d.id, "operation", operation,
+			"attempt", attempt, "wait", wait, "error", err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if d.breaker != nil {
+		if ctx.Err() != nil {
+			d.breaker.release()
+		} else if d.breaker.record(time.Now(), err) {
+			d.logger.WarnContext(ctx, "light: not responding, pausing requests", "id", d.id,
+				"cooldown", d.policy.BreakerCooldown, "error", err)
+		}
+	}
+	return err
+}
+
+// try runs a single attempt of a request within the per-try timeout.
+func (d *resilientDriver) try(ctx context.Context, request func(context.Context) error) error {
+	if d.policy.Timeout <= 0 {
+		return request(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.policy.Timeout)
+	defer cancel()
+	return request(ctx)
+}
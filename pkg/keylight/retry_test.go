@@ -0,0 +1,127 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// flakyDriver fails the first failures requests, then succeeds.
+type flakyDriver struct {
+	LightDriver
+	failures int32
+	calls    atomic.Int32
+}
+
+func (d *flakyDriver) GetLightState(ctx context.Context) (*LightState, error) {
+	if d.calls.Add(1) <= d.failures {
+		return nil, assert.AnError
+	}
+	return &LightState{NumberOfLights: 1}, nil
+}
+
+func (d *flakyDriver) SetLightState(ctx context.Context, on bool, brightness, temperature int) error {
+	_, err := d.GetLightState(ctx)
+	return err
+}
+
+func TestResilientDriver_Retries(t *testing.T) {
+	flaky := &flakyDriver{failures: 2}
+	d := &resilientDriver{LightDriver: flaky, id: "light-1", policy: RetryPolicy{Attempts: 3, Backoff: time.Millisecond}, logger: discardLogger()}
+
+	state, err := d.GetLightState(context.Background())
+	require.NoError(t, err)
+	assert.NotNil(t, state)
+	assert.Equal(t, int32(3), flaky.calls.Load())
+
+	// Gives up after the configured number of attempts
+	flaky = &flakyDriver{failures: 5}
+	d.LightDriver = flaky
+	require.ErrorIs(t, d.SetLightState(context.Background(), true, 50, 200), assert.AnError)
+	assert.Equal(t, int32(3), flaky.calls.Load())
+}
+
+func TestResilientDriver_StopsWhenCancelled(t *testing.T) {
+	flaky := &flakyDriver{failures: 5}
+	d := &resilientDriver{LightDriver: flaky, id: "light-1", policy: RetryPolicy{Attempts: 5, Backoff: time.Hour}, logger: discardLogger()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := d.GetLightState(ctx)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), flaky.calls.Load())
+}
+
+func TestResilientDriver_Breaker(t *testing.T) {
+	flaky := &flakyDriver{failures: 4}
+	policy := RetryPolicy{Attempts: 1, BreakerThreshold: 2, BreakerCooldown: 20 * time.Millisecond}
+	d := &resilientDriver{
+		LightDriver: flaky,
+		id:          "light-1",
+		policy:      policy,
+		breaker:     &breaker{threshold: policy.BreakerThreshold, cooldown: policy.BreakerCooldown},
+		logger:      discardLogger(),
+	}
+
+	for range 2 {
+		_, err := d.GetLightState(context.Background())
+		require.ErrorIs(t, err, assert.AnError)
+	}
+
+	// Open: fails without contacting the light
+	_, err := d.GetLightState(context.Background())
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.True(t, errors.IsDeviceUnavailable(err))
+	assert.Equal(t, int32(2), flaky.calls.Load())
+
+	// After the cooldown one request is let through; it fails and reopens the breaker
+	time.Sleep(policy.BreakerCooldown)
+	_, err = d.GetLightState(context.Background())
+	require.ErrorIs(t, err, assert.AnError)
+	_, err = d.GetLightState(context.Background())
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// Skip the fourth failure, then a successful request closes the breaker
+	flaky.calls.Add(1)
+	time.Sleep(policy.BreakerCooldown)
+	_, err = d.GetLightState(context.Background())
+	require.NoError(t, err)
+	_, err = d.GetLightState(context.Background())
+	require.NoError(t, err)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for range 20 {
+		assert.InDelta(t, 75*time.Millisecond, p.backoff(1), float64(25*time.Millisecond))
+		assert.InDelta(t, 150*time.Millisecond, p.backoff(2), float64(50*time.Millisecond))
+		assert.InDelta(t, 225*time.Millisecond, p.backoff(5), float64(75*time.Millisecond))
+	}
+	assert.Zero(t, RetryPolicy{}.backoff(1))
+}
+
+func TestManager_RetryPolicy(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+	light := Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED}
+
+	m := NewManager(discardLogger())
+	_, wrapped := m.newClient(light).(*resilientDriver)
+	assert.False(t, wrapped, "requests are tried once by default")
+
+	m.SetRetryPolicy(RetryPolicy{Attempts: 3, BreakerThreshold: 1, BreakerCooldown: time.Minute})
+	client, wrapped := m.newClient(light).(*resilientDriver)
+	require.True(t, wrapped)
+	again := m.newClient(light).(*resilientDriver)
+	assert.Same(t, client.breaker, again.breaker, "breakers survive rediscovery")
+
+	m.AddLight(context.Background(), light)
+	assert.Equal(t, ReachabilityOnline, m.GetLights()["strip"].Status)
+}
@@ -0,0 +1,137 @@
+package keylight
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// BackendScan probes every address in the configured subnets, for networks
+// where multicast is blocked entirely.
+const BackendScan = "scan"
+
+// maxScanAddresses caps the number of addresses in a single scanned subnet.
+const maxScanAddresses = 1 << 12
+
+// scanSettings configures the subnet scan backend, see SetSubnetScan.
+type scanSettings struct {
+	subnets     []netip.Prefix
+	timeout     time.Duration
+	concurrency int
+}
+
+// SetSubnetScan sets the IPv4 CIDR ranges probed by the scan backend, how
+// long each address is given to answer and how many addresses are probed at
+// once. Zero values use the defaults. Subnets larger than a /20 are rejected.
+// It must be called before SetDiscoveryBackends selects the scan backend.
+func (m *Manager) SetSubnetScan(subnets []string, timeout time.Duration, concurrency int) error {
+	settings := scanSettings{timeout: timeout, concurrency: concurrency}
+	if settings.timeout <= 0 {
+		settings.timeout = config.DefaultScanTimeout
+	}
+	if settings.concurrency <= 0 {
+		settings.concurrency = config.DefaultScanConcurrency
+	}
+	for _, s := range subnets {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return errors.InvalidInputf("invalid scan subnet %q: %w", s, err)
+		}
+		if !prefix.Addr().Is4() {
+			return errors.InvalidInputf("scan subnet %q: only IPv4 subnets can be scanned", s)
+		}
+		if 1<<(32-prefix.Bits()) > maxScanAddresses {
+			return errors.InvalidInputf("scan subnet %q is too large, the largest allowed is a /20", s)
+		}
+		settings.subnets = append(settings.subnets, prefix.Masked())
+	}
+
+	m.mu.Lock()
+	m.scan = settings
+	m.mu.Unlock()
+	return nil
+}
+
+// scanBackend probes every address in the configured subnets for an Elgato
+// light, by requesting its accessory info on the Elgato API port.
+type scanBackend struct {
+	manager *Manager
+	port    int // probed port; 0 uses the Elgato driver's standard port
+}
+
+func (b *scanBackend) Name() string { return BackendScan }
+
+// Browse probes each address once, stopping early if ctx is done. Addresses
+// that answer with accessory info are sent as entries.
+func (b *scanBackend) Browse(ctx context.Context, entries chan<- *ServiceEntry) error {
+	m := b.manager
+	m.mu.RLock()
+	settings := m.scan
+	m.mu.RUnlock()
+	if len(settings.subnets) == 0 {
+		return errors.InvalidInputf("no subnets configured for the scan backend")
+	}
+	port := b.port
+	if port == 0 {
+		spec, _ := lookupDriver(DriverElgato)
+		port = spec.port
+	}
+	httpClient := &http.Client{Timeout: settings.timeout}
+	// Most addresses won't answer, which isn't worth logging
+	quiet := slog.New(slog.DiscardHandler)
+
+	sem := make(chan struct{}, settings.concurrency)
+	var probes sync.WaitGroup
+	defer probes.Wait()
+	for _, subnet := range settings.subnets {
+		for addr := range scanAddresses(subnet) {
+			select {
+			case <-ctx.Done():
+				return nil
+			case sem <- struct{}{}:
+			}
+			probes.Go(func() {
+				defer func() { <-sem }()
+				ip := net.IP(addr.AsSlice())
+				client := NewKeyLightClient(ip.String(), port, quiet, httpClient)
+				if _, err := client.GetAccessoryInfo(ctx); err != nil {
+					return
+				}
+				entries <- &ServiceEntry{
+					AddrV4: ip,
+					Port:   port,
+					Driver: DriverElgato,
+					Source: BackendScan + "/" + subnet.String(),
+				}
+			})
+		}
+	}
+	return nil
+}
+
+// scanAddresses yields the host addresses of subnet, leaving out the network
+// and broadcast addresses of subnets that have them.
+func scanAddresses(subnet netip.Prefix) iter.Seq[netip.Addr] {
+	return func(yield func(netip.Addr) bool) {
+		first, last := subnet.Addr(), subnet.Addr()
+		for next := last.Next(); next.IsValid() && subnet.Contains(next); next = next.Next() {
+			last = next
+		}
+		if subnet.Bits() < 31 {
+			first, last = first.Next(), last.Prev()
+		}
+		for addr := first; addr.Compare(last) <= 0; addr = addr.Next() {
+			if !yield(addr) {
+				return
+			}
+		}
+	}
+}
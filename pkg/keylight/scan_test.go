@@ -0,0 +1,98 @@
+package keylight
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestScanAddresses(t *testing.T) {
+	tests := []struct {
+		subnet string
+		want   []string
+	}{
+		{"192.168.1.0/30", []string{"192.168.1.1", "192.168.1.2"}},
+		{"192.168.1.4/31", []string{"192.168.1.4", "192.168.1.5"}},
+		{"192.168.1.9/32", []string{"192.168.1.9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subnet, func(t *testing.T) {
+			var got []string
+			for addr := range scanAddresses(netip.MustParsePrefix(tt.subnet)) {
+				got = append(got, addr.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Equal(t, 254, len(slices.Collect(scanAddresses(netip.MustParsePrefix("10.0.0.0/24")))))
+}
+
+func TestSetSubnetScan(t *testing.T) {
+	m := NewManager(discardLogger())
+	assert.True(t, errors.IsInvalidInput(m.SetDiscoveryBackends([]string{BackendScan})), "scan needs subnets")
+
+	for _, subnet := range []string{"192.168.1.0", "fd00::/64", "10.0.0.0/16"} {
+		assert.True(t, errors.IsInvalidInput(m.SetSubnetScan([]string{subnet}, 0, 0)), subnet)
+	}
+
+	require.NoError(t, m.SetSubnetScan([]string{"192.168.1.77/24"}, 0, 0))
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("192.168.1.0/24")}, m.scan.subnets)
+	assert.Positive(t, m.scan.timeout)
+	assert.Positive(t, m.scan.concurrency)
+	require.NoError(t, m.SetDiscoveryBackends([]string{BackendMDNS, BackendScan}))
+}
+
+func TestScanBackend_Browse(t *testing.T) {
+	srv := mockHTTPServer(t)
+	defer srv.Close()
+	_, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	require.NoError(t, m.SetSubnetScan([]string{"127.0.0.1/32", "127.0.0.2/32"}, 200*time.Millisecond, 2))
+	backend := &scanBackend{manager: m, port: port}
+
+	entries := make(chan *ServiceEntry, 10)
+	require.NoError(t, backend.Browse(context.Background(), entries))
+	close(entries)
+
+	var got []*ServiceEntry
+	for entry := range entries {
+		got = append(got, entry)
+	}
+	require.Len(t, got, 1, "only the address with a light should be reported")
+	assert.Equal(t, "127.0.0.1", got[0].AddrV4.String())
+	assert.Equal(t, port, got[0].Port)
+	assert.Equal(t, "scan/127.0.0.1/32", got[0].Source)
+
+	light, ok := validateLight(context.Background(), got[0], validationRules{}, discardLogger())
+	require.True(t, ok)
+	assert.Equal(t, addressID(got[0].AddrV4, port), light.ID)
+}
+
+func TestDiscoverNow(t *testing.T) {
+	srv := mockHTTPServer(t)
+	defer srv.Close()
+	_, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	require.NoError(t, m.SetSubnetScan([]string{"127.0.0.1/32"}, 200*time.Millisecond, 1))
+	m.backends = []DiscoveryBackend{&scanBackend{manager: m, port: port}}
+
+	found, err := m.DiscoverNow(context.Background())
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "127.0.0.1:"+strconv.Itoa(port), found[0].ID)
+
+	found, err = m.DiscoverNow(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, found, "lights already known are not reported again")
+	assert.Equal(t, 2, m.DiscoveryStats().Runs)
+}
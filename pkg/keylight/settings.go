@@ -0,0 +1,175 @@
+package keylight
+
+import (
+	"context"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// Power-on behaviors: what a light does when it gets power back.
+const (
+	// PowerOnRestore returns the light to the state it was in before power was lost.
+	PowerOnRestore = "restore"
+	// PowerOnDefault turns the light on at its power-on brightness and temperature.
+	PowerOnDefault = "default"
+)
+
+// Device values of the Elgato powerOnBehavior setting.
+const (
+	devicePowerOnRestore = 1
+	devicePowerOnDefault = 2
+)
+
+// DeviceSettings is the Elgato /elgato/lights/settings document. Temperatures
+// are in device mireds. Fields keylightd doesn't change are sent back as read.
+type DeviceSettings struct {
+	PowerOnBehavior       int `json:"powerOnBehavior"`
+	PowerOnBrightness     int `json:"powerOnBrightness"`
+	PowerOnTemperature    int `json:"powerOnTemperature"`
+	SwitchOnDurationMs    int `json:"switchOnDurationMs"`
+	SwitchOffDurationMs   int `json:"switchOffDurationMs"`
+	ColorChangeDurationMs int `json:"colorChangeDurationMs"`
+}
+
+// LightSettings are the power-on settings stored on a light.
+type LightSettings struct {
+	PowerOnBehavior    string `json:"power_on_behavior"`    // restore or default
+	PowerOnBrightness  int    `json:"power_on_brightness"`  // used when the behavior is default
+	PowerOnTemperature int    `json:"power_on_temperature"` // Kelvin, used when the behavior is default
+}
+
+// LightSettingsUpdate changes some of a light's settings. Nil fields are left
+// as they are.
+type LightSettingsUpdate struct {
+	PowerOnBehavior    *string `json:"power_on_behavior,omitempty"`
+	PowerOnBrightness  *int    `json:"power_on_brightness,omitempty"`
+	PowerOnTemperature *int    `json:"power_on_temperature,omitempty"` // Kelvin
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u LightSettingsUpdate) IsEmpty() bool {
+	return u.PowerOnBehavior == nil && u.PowerOnBrightness == nil && u.PowerOnTemperature == nil
+}
+
+// Validate checks the update's values are in range.
+func (u LightSettingsUpdate) Validate() error {
+	if u.IsEmpty() {
+		return errors.InvalidInputf("no settings to change")
+	}
+	if u.PowerOnBehavior != nil && *u.PowerOnBehavior != PowerOnRestore && *u.PowerOnBehavior != PowerOnDefault {
+		return errors.InvalidInputf("power-on behavior must be %s or %s, got %q", PowerOnRestore, PowerOnDefault, *u.PowerOnBehavior)
+	}
+	if u.PowerOnBrightness != nil {
+		if err := BrightnessValue(*u.PowerOnBrightness).Validate(); err != nil {
+			return errors.InvalidInputf("power-on %s", err)
+		}
+	}
+	if u.PowerOnTemperature != nil {
+		if err := TemperatureValue(*u.PowerOnTemperature).Validate(); err != nil {
+			return errors.InvalidInputf("power-on %s", err)
+		}
+	}
+	return nil
+}
+
+// settingsFromDevice converts device settings to their API form.
+func settingsFromDevice(s DeviceSettings) LightSettings {
+	behavior := PowerOnRestore
+	if s.PowerOnBehavior == devicePowerOnDefault {
+		behavior = PowerOnDefault
+	}
+	return LightSettings{
+		PowerOnBehavior:    behavior,
+		PowerOnBrightness:  s.PowerOnBrightness,
+		PowerOnTemperature: ConvertDeviceToTemperature(s.PowerOnTemperature),
+	}
+}
+
+// apply changes device settings s with the update.
+func (u LightSettingsUpdate) apply(s *DeviceSettings) {
+	if u.PowerOnBehavior != nil {
+		s.PowerOnBehavior = devicePowerOnRestore
+		if *u.PowerOnBehavior == PowerOnDefault {
+			s.PowerOnBehavior = devicePowerOnDefault
+		}
+	}
+	if u.PowerOnBrightness != nil {
+		s.PowerOnBrightness = *u.PowerOnBrightness
+	}
+	if u.PowerOnTemperature != nil {
+		s.PowerOnTemperature = convertTemperatureToDevice(*u.PowerOnTemperature)
+	}
+}
+
+// GetLightSettings reads the power-on settings stored on a light, for drivers
+// that support them.
+func (m *Manager) GetLightSettings(ctx context.Context, id string) (*LightSettings, error) {
+	settings, _, err := m.deviceSettings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s := settingsFromDevice(*settings)
+	return &s, nil
+}
+
+// SetLightSettings changes the power-on settings stored on a light and
+// returns the settings as written. The device's other settings are kept.
+func (m *Manager) SetLightSettings(ctx context.Context, id string, update LightSettingsUpdate) (*LightSettings, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	// The settings are read and written back whole, so concurrent updates
+	// must not interleave.
+	unlock, err := m.lockLight(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	settings, manager, err := m.deviceSettings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	update.apply(settings)
+	if err := manager.SetDeviceSettings(ctx, *settings); err != nil {
+		return nil, errors.LogErrorAndReturnContext(
+			ctx,
+			m.logger,
+			errors.DeviceUnavailablef("failed to set light settings: %w", err),
+			"light: failed to set settings",
+			"id", id,
+		)
+	}
+
+	s := settingsFromDevice(*settings)
+	m.logger.InfoContext(ctx, "light: settings changed", "id", id,
+		"power_on_behavior", s.PowerOnBehavior,
+		"power_on_brightness", s.PowerOnBrightness,
+		"power_on_temperature", s.PowerOnTemperature)
+	return &s, nil
+}
+
+// deviceSettings reads a light's device settings, returning the driver's
+// SettingsManager for writing them back.
+func (m *Manager) deviceSettings(ctx context.Context, id string) (*DeviceSettings, SettingsManager, error) {
+	client, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	manager, ok := settingsManager(client)
+	if !ok {
+		return nil, nil, errors.InvalidInputf("light %s (driver %s) does not support device settings", id, light.Driver)
+	}
+
+	settings, err := manager.GetDeviceSettings(ctx)
+	if err != nil {
+		return nil, nil, errors.LogErrorAndReturnContext(
+			ctx,
+			m.logger,
+			errors.DeviceUnavailablef("failed to get light settings: %w", err),
+			"light: failed to get settings",
+			"id", id,
+		)
+	}
+	return settings, manager, nil
+}
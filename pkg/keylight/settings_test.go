@@ -0,0 +1,114 @@
+package keylight
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// newSettingsTestServer serves /elgato/lights/settings backed by settings.
+func newSettingsTestServer(t *testing.T, settings *DeviceSettings) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/elgato/lights/settings" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPut {
+			if err := json.NewDecoder(r.Body).Decode(settings); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(settings)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLightSettings(t *testing.T) {
+	device := &DeviceSettings{
+		PowerOnBehavior:       devicePowerOnRestore,
+		PowerOnBrightness:     20,
+		PowerOnTemperature:    213,
+		SwitchOnDurationMs:    100,
+		SwitchOffDurationMs:   300,
+		ColorChangeDurationMs: 100,
+	}
+	host, port := hostPort(t, newSettingsTestServer(t, device))
+
+	m := NewManager(discardLogger())
+	m.lights["key"] = Light{ID: "key", IP: net.ParseIP(host), Port: port}
+	ctx := context.Background()
+
+	settings, err := m.GetLightSettings(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, LightSettings{PowerOnBehavior: PowerOnRestore, PowerOnBrightness: 20, PowerOnTemperature: 4694}, *settings)
+
+	behavior, brightness, temperature := PowerOnDefault, 60, 5000
+	settings, err = m.SetLightSettings(ctx, "key", LightSettingsUpdate{
+		PowerOnBehavior:    &behavior,
+		PowerOnBrightness:  &brightness,
+		PowerOnTemperature: &temperature,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, LightSettings{PowerOnBehavior: PowerOnDefault, PowerOnBrightness: 60, PowerOnTemperature: 5000}, *settings)
+	assert.Equal(t, DeviceSettings{
+		PowerOnBehavior:       devicePowerOnDefault,
+		PowerOnBrightness:     60,
+		PowerOnTemperature:    200,
+		SwitchOnDurationMs:    100,
+		SwitchOffDurationMs:   300,
+		ColorChangeDurationMs: 100,
+	}, *device, "settings keylightd doesn't change should be kept")
+}
+
+func TestSetLightSettings_Invalid(t *testing.T) {
+	host, port := hostPort(t, newSettingsTestServer(t, &DeviceSettings{}))
+	m := NewManager(discardLogger())
+	m.lights["key"] = Light{ID: "key", IP: net.ParseIP(host), Port: port}
+
+	behavior, brightness, temperature := "off", 101, 1000
+	tests := []struct {
+		name   string
+		update LightSettingsUpdate
+	}{
+		{"empty", LightSettingsUpdate{}},
+		{"behavior", LightSettingsUpdate{PowerOnBehavior: &behavior}},
+		{"brightness", LightSettingsUpdate{PowerOnBrightness: &brightness}},
+		{"temperature", LightSettingsUpdate{PowerOnTemperature: &temperature}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.SetLightSettings(context.Background(), "key", tt.update)
+			assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+		})
+	}
+
+	_, err := m.GetLightSettings(context.Background(), "nope")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestLightSettings_Unsupported(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.lights["strip"] = Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED}
+
+	_, err := m.GetLightSettings(context.Background(), "strip")
+	assert.True(t, errors.IsInvalidInput(err))
+}
@@ -0,0 +1,139 @@
+package keylight
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SSDP search parameters.
+var (
+	ssdpAddr = &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+	// ssdpSearchInterval is how often the M-SEARCH is repeated while
+	// browsing, as SSDP is sent over UDP and may be lost.
+	ssdpSearchInterval = 2 * time.Second
+)
+
+// ssdpMaxWait is the MX header of the search: the number of seconds devices
+// may wait before answering.
+const ssdpMaxWait = 2
+
+// ssdpBackend finds lights on networks that filter mDNS but pass SSDP, by
+// sending an SSDP M-SEARCH and probing every device that answers. Devices
+// are probed on the standard port of the Elgato driver, and of the WLED
+// driver when they identify themselves as WLED.
+type ssdpBackend struct {
+	logger *slog.Logger
+	addr   *net.UDPAddr // search address; nil uses the SSDP multicast group
+}
+
+func (b *ssdpBackend) Name() string { return BackendSSDP }
+
+// Browse repeats the search until ctx is done, sending an entry for each
+// device that answers, once per browse.
+func (b *ssdpBackend) Browse(ctx context.Context, entries chan<- *ServiceEntry) error {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return fmt.Errorf("failed to open SSDP socket: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	addr := b.addr
+	if addr == nil {
+		addr = ssdpAddr
+	}
+	search := []byte("M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + addr.String() + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		fmt.Sprintf("MX: %d\r\n", ssdpMaxWait) +
+		"ST: ssdp:all\r\n\r\n")
+
+	go func() {
+		ticker := time.NewTicker(ssdpSearchInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := conn.WriteToUDP(search, addr); err != nil {
+				if ctx.Err() == nil {
+					b.logger.Debug("ssdp: search failed", "error", err)
+				}
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read SSDP response: %w", err)
+		}
+		entry := b.entry(buf[:n], from)
+		if entry == nil {
+			continue
+		}
+		if key := addressID(entry.AddrV4, entry.Port); !seen[key] {
+			seen[key] = true
+			entries <- entry
+		}
+	}
+}
+
+// entry parses an SSDP response and returns the entry to probe for the device
+// that sent it, or nil if the response is malformed.
+func (b *ssdpBackend) entry(data []byte, from *net.UDPAddr) *ServiceEntry {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		b.logger.Debug("ssdp: ignoring malformed response", "from", from, "error", err)
+		return nil
+	}
+	resp.Body.Close()
+
+	ip := from.IP.To4()
+	if location, err := url.Parse(resp.Header.Get("Location")); err == nil {
+		if locIP := net.ParseIP(location.Hostname()).To4(); locIP != nil {
+			ip = locIP
+		}
+	}
+	if ip == nil {
+		return nil
+	}
+	b.logger.Debug("ssdp: received response",
+		"from", from,
+		"server", resp.Header.Get("Server"),
+		"st", resp.Header.Get("St"),
+		"usn", resp.Header.Get("Usn"))
+
+	driver := DriverElgato
+	if strings.Contains(strings.ToLower(resp.Header.Get("Server")+resp.Header.Get("Usn")), DriverWLED) {
+		driver = DriverWLED
+	}
+	spec, ok := lookupDriver(driver)
+	if !ok {
+		return nil
+	}
+	return &ServiceEntry{
+		AddrV4: ip,
+		Port:   spec.port,
+		Info:   resp.Header.Get("Usn"),
+		Driver: driver,
+		Source: BackendSSDP,
+	}
+}
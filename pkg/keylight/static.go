@@ -0,0 +1,80 @@
+package keylight
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// SetStaticLights registers lights at fixed addresses that are not found via mDNS.
+// Static lights are probed at the start of every discovery cycle and are never
+// removed by the cleanup worker. Missing IDs default to the name, then ip:port;
+// missing ports default to the driver's standard port.
+func (m *Manager) SetStaticLights(lights []Light) error {
+	static := make([]Light, 0, len(lights))
+	seen := make(map[string]bool, len(lights))
+	for _, light := range lights {
+		if light.IP == nil {
+			return errors.InvalidInputf("static light %q: missing or invalid ip", light.Name)
+		}
+		spec, ok := lookupDriver(light.Driver)
+		if !ok {
+			return errors.InvalidInputf("static light %q: unknown driver %q", light.Name, light.Driver)
+		}
+		if light.Port == 0 {
+			light.Port = spec.port
+		}
+		if light.Port <= 0 || light.Port > 65535 {
+			return errors.InvalidInputf("static light %q: invalid port %d", light.Name, light.Port)
+		}
+		if light.ID == "" {
+			light.ID = light.Name
+		}
+		if light.ID == "" {
+			light.ID = addressID(light.IP, light.Port)
+		}
+		if seen[light.ID] {
+			return errors.InvalidInputf("static light %q: duplicate id", light.ID)
+		}
+		seen[light.ID] = true
+		light.Static = true
+		static = append(static, light)
+	}
+
+	m.mu.Lock()
+	m.static = static
+	m.mu.Unlock()
+	return nil
+}
+
+// probeStaticLights refreshes every static light, adding it to the manager if it
+// is not already present. Unreachable lights are kept with their last known state.
+func (m *Manager) probeStaticLights(ctx context.Context) {
+	m.mu.RLock()
+	static := make([]Light, len(m.static))
+	copy(static, m.static)
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, light := range static {
+		wg.Go(func() {
+			m.AddLight(ctx, light)
+		})
+	}
+	wg.Wait()
+}
+
+// discoveredCount returns the number of lights found by discovery, excluding static
+// and offline lights.
+func (m *Manager) discoveredCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, light := range m.lights {
+		if !light.Static && light.Status != ReachabilityOffline {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,77 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestSetStaticLights_Defaults(t *testing.T) {
+	m := NewManager(discardLogger())
+	require.NoError(t, m.SetStaticLights([]Light{
+		{Name: "Desk", IP: net.ParseIP("192.168.10.20")},
+		{IP: net.ParseIP("192.168.10.21"), Driver: DriverWLED},
+	}))
+
+	require.Len(t, m.static, 2)
+	assert.Equal(t, "Desk", m.static[0].ID)
+	assert.Equal(t, 9123, m.static[0].Port)
+	assert.True(t, m.static[0].Static)
+	assert.Equal(t, "192.168.10.21:80", m.static[1].ID)
+	assert.Equal(t, 80, m.static[1].Port)
+}
+
+func TestSetStaticLights_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		lights []Light
+	}{
+		{"missing ip", []Light{{Name: "Desk"}}},
+		{"unknown driver", []Light{{Name: "Desk", IP: net.ParseIP("10.0.0.1"), Driver: "hue"}}},
+		{"bad port", []Light{{Name: "Desk", IP: net.ParseIP("10.0.0.1"), Port: 70000}}},
+		{"duplicate id", []Light{{Name: "Desk", IP: net.ParseIP("10.0.0.1")}, {Name: "Desk", IP: net.ParseIP("10.0.0.2")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(discardLogger())
+			err := m.SetStaticLights(tt.lights)
+			require.Error(t, err)
+			assert.True(t, errors.IsInvalidInput(err))
+		})
+	}
+}
+
+func TestProbeStaticLights(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	require.NoError(t, m.SetStaticLights([]Light{
+		{ID: "strip", Name: "Shelf", IP: net.ParseIP(host), Port: port, Driver: DriverWLED},
+	}))
+	m.probeStaticLights(context.Background())
+
+	lights := m.GetLights()
+	require.Contains(t, lights, "strip")
+	assert.True(t, lights["strip"].Static)
+	assert.Equal(t, "Shelf", lights["strip"].Name, "configured name must not be replaced by the device name")
+	assert.Equal(t, 100, lights["strip"].Brightness)
+	assert.Equal(t, 0, m.discoveredCount())
+
+	// Static lights are never removed by cleanup, even when not seen for a long time
+	m.SetOfflineRetention(time.Minute)
+	m.mu.Lock()
+	l := m.lights["strip"]
+	l.LastSeen = time.Now().Add(-time.Hour)
+	m.lights["strip"] = l
+	m.mu.Unlock()
+	m.cleanupStaleLights(time.Minute)
+	require.Contains(t, m.GetLights(), "strip")
+	assert.Equal(t, ReachabilityOffline, m.GetLights()["strip"].Status)
+}
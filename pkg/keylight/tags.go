@@ -0,0 +1,167 @@
+package keylight
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// Limits on light tags.
+const (
+	MaxLightTags      = 32
+	MaxTagValueLength = 64
+)
+
+// TagSelectorPrefix starts a light target that selects lights by tag rather
+// than naming one, see ParseTagSelector.
+const TagSelectorPrefix = "tag:"
+
+// tagKeyPattern is what tag keys may contain. Values may contain anything but
+// a comma, which separates the conditions of a tag selector.
+var tagKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,32}$`)
+
+// ValidateTags checks that tags have valid keys and values.
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxLightTags {
+		return errors.InvalidInputf("a light can have at most %d tags", MaxLightTags)
+	}
+	for key, value := range tags {
+		if !tagKeyPattern.MatchString(key) {
+			return errors.InvalidInputf("invalid tag key %q: keys are 1-32 letters, digits, '_', '.' or '-'", key)
+		}
+		if utf8.RuneCountInString(value) > MaxTagValueLength {
+			return errors.InvalidInputf("value of tag %q must be at most %d characters", key, MaxTagValueLength)
+		}
+		if strings.Contains(value, ",") {
+			return errors.InvalidInputf("value of tag %q cannot contain a comma", key)
+		}
+	}
+	return nil
+}
+
+// TagSelector selects lights by their tags: each key must be present and,
+// if its value isn't empty, have that value.
+type TagSelector map[string]string
+
+// ParseTagSelector parses a target such as "tag:side=left" or
+// "tag:location=desk,side=left". ok is false if target isn't a tag selector.
+// A condition without a value, such as "tag:favorite", matches lights that
+// have the tag with any value.
+func ParseTagSelector(target string) (sel TagSelector, ok bool, err error) {
+	rest, ok := strings.CutPrefix(target, TagSelectorPrefix)
+	if !ok {
+		return nil, false, nil
+	}
+	sel = TagSelector{}
+	for cond := range strings.SplitSeq(rest, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(cond), "=")
+		if !tagKeyPattern.MatchString(key) {
+			return nil, true, errors.InvalidInputf("invalid tag selector %q: bad tag key %q", target, key)
+		}
+		sel[key] = value
+	}
+	return sel, true, nil
+}
+
+// Matches reports whether a light with the given tags is selected.
+func (s TagSelector) Matches(tags map[string]string) bool {
+	for key, want := range s {
+		got, ok := tags[key]
+		if !ok || (want != "" && got != want) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the selector in the form ParseTagSelector accepts.
+func (s TagSelector) String() string {
+	conds := make([]string, 0, len(s))
+	for _, key := range slices.Sorted(maps.Keys(s)) {
+		if s[key] == "" {
+			conds = append(conds, key)
+		} else {
+			conds = append(conds, key+"="+s[key])
+		}
+	}
+	return TagSelectorPrefix + strings.Join(conds, ",")
+}
+
+// SelectLights returns the IDs of the lights the selector matches, sorted.
+func SelectLights(lights map[string]*Light, sel TagSelector) []string {
+	var ids []string
+	for id, light := range lights {
+		if sel.Matches(light.Tags) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// SetTagStore loads light tags, keyed by light ID, and sets the function used
+// to persist changes made with SetLightTags. persist is called with nil tags
+// when a light's tags are removed. It must be set before discovery starts.
+func (m *Manager) SetTagStore(tags map[string]map[string]string, persist func(id string, tags map[string]string) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tags = make(map[string]map[string]string, len(tags))
+	for id, t := range tags {
+		m.tags[id] = maps.Clone(t)
+	}
+	m.persistTags = persist
+}
+
+// SetLightTags replaces the tags of a light and persists them. Nil or empty
+// tags remove them.
+func (m *Manager) SetLightTags(ctx context.Context, id string, tags map[string]string) (*Light, error) {
+	if err := ValidateTags(tags); err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		tags = nil
+	} else {
+		tags = maps.Clone(tags)
+	}
+
+	m.mu.Lock()
+	updated, exists := m.lights[id]
+	if !exists {
+		m.mu.Unlock()
+		return nil, errors.NotFoundf("light %s not found", id)
+	}
+	if tags == nil {
+		delete(m.tags, id)
+	} else {
+		if m.tags == nil {
+			m.tags = make(map[string]map[string]string)
+		}
+		m.tags[id] = tags
+	}
+	updated.Tags = maps.Clone(tags)
+	m.lights[id] = updated
+	persist := m.persistTags
+	m.mu.Unlock()
+
+	if persist != nil {
+		if err := persist(id, tags); err != nil {
+			return nil, errors.Internalf("failed to save tags for light %s: %w", id, err)
+		}
+	}
+
+	m.logger.InfoContext(ctx, "light: tags changed", "id", id, "tags", tags)
+	m.emit(events.LightStateChanged, &updated)
+	return &updated, nil
+}
+
+// applyTags sets a light's tags to those stored for it.
+// Requires m.mu to be held by the caller.
+func (m *Manager) applyTags(light *Light) {
+	light.Tags = maps.Clone(m.tags[light.ID])
+}
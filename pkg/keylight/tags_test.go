@@ -0,0 +1,94 @@
+package keylight
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestParseTagSelector(t *testing.T) {
+	sel, ok, err := ParseTagSelector("tag:location=desk, side=left,favorite")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, TagSelector{"location": "desk", "side": "left", "favorite": ""}, sel)
+	assert.Equal(t, "tag:favorite,location=desk,side=left", sel.String())
+
+	_, ok, err = ParseTagSelector("Key Light Air")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, target := range []string{"tag:", "tag:=left", "tag:side=left,", "tag:bad key=1"} {
+		_, ok, err = ParseTagSelector(target)
+		assert.True(t, ok, target)
+		assert.True(t, errors.IsInvalidInput(err), target)
+	}
+}
+
+func TestTagSelectorMatches(t *testing.T) {
+	tags := map[string]string{"location": "desk", "side": "left"}
+	assert.True(t, TagSelector{"side": "left"}.Matches(tags))
+	assert.True(t, TagSelector{"location": "desk", "side": ""}.Matches(tags))
+	assert.False(t, TagSelector{"side": "right"}.Matches(tags))
+	assert.False(t, TagSelector{"favorite": ""}.Matches(tags))
+	assert.False(t, TagSelector{"side": "left"}.Matches(nil))
+}
+
+func TestValidateTags(t *testing.T) {
+	assert.NoError(t, ValidateTags(map[string]string{"location": "desk", "favorite": ""}))
+	assert.True(t, errors.IsInvalidInput(ValidateTags(map[string]string{"bad key": "x"})))
+	assert.True(t, errors.IsInvalidInput(ValidateTags(map[string]string{"side": "left,right"})))
+}
+
+func TestSetLightTags(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	saved := map[string]map[string]string{}
+	m := NewManager(discardLogger())
+	m.SetTagStore(nil, func(id string, tags map[string]string) error {
+		saved[id] = tags
+		return nil
+	})
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+
+	light, err := m.SetLightTags(context.Background(), "strip", map[string]string{"side": "left"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"side": "left"}, light.Tags)
+	assert.Equal(t, map[string]string{"side": "left"}, saved["strip"])
+
+	// Rediscovery keeps the tags
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	assert.Equal(t, map[string]string{"side": "left"}, m.GetLights()["strip"].Tags)
+
+	ids, selected, err := TargetLights(m, "tag:side=left")
+	require.NoError(t, err)
+	assert.True(t, selected)
+	assert.Equal(t, []string{"strip"}, ids)
+
+	_, _, err = TargetLights(m, "tag:side=right")
+	assert.True(t, errors.IsNotFound(err))
+
+	light, err = m.SetLightTags(context.Background(), "strip", nil)
+	require.NoError(t, err)
+	assert.Nil(t, light.Tags)
+	assert.Contains(t, saved, "strip")
+	assert.Nil(t, saved["strip"])
+
+	_, err = m.SetLightTags(context.Background(), "nope", map[string]string{"side": "left"})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSetTagStore_AppliedOnDiscovery(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 255})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.SetTagStore(map[string]map[string]string{"strip": {"location": "desk"}}, nil)
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+	assert.Equal(t, map[string]string{"location": "desk"}, m.GetLights()["strip"].Tags)
+}
@@ -0,0 +1,80 @@
+package keylight
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// TargetAll is the target that selects every light, or every group.
+const TargetAll = "all"
+
+// IsTargetPattern reports whether target is a glob pattern such as
+// "office-*", using the syntax of path.Match.
+func IsTargetPattern(target string) bool {
+	return strings.ContainsAny(target, "*?[")
+}
+
+// MatchTargetPattern reports whether any of names matches a glob pattern. A
+// malformed pattern matches nothing.
+func MatchTargetPattern(pattern string, names ...string) bool {
+	for _, name := range names {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTargetPattern checks that a glob pattern is well formed.
+func ValidateTargetPattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return errors.InvalidInputf("invalid pattern %q: %v", pattern, err)
+	}
+	return nil
+}
+
+// TargetLights returns the lights a target names, sorted: the light with
+// that ID; every light for "all"; every light whose ID or name matches a glob
+// pattern such as "Desk*"; or every light matched by a tag selector such as
+// "tag:side=left". A light whose ID is exactly target is preferred over
+// treating target as "all" or a pattern. selected reports whether target
+// selected lights rather than naming one. A target that selects no light is a
+// not found error.
+func TargetLights(lm LightManager, target string) (ids []string, selected bool, err error) {
+	sel, isTag, err := ParseTagSelector(target)
+	if err != nil {
+		return nil, true, err
+	}
+	if !isTag && target != TargetAll && !IsTargetPattern(target) {
+		return []string{target}, false, nil
+	}
+
+	lights := lm.GetLights()
+	switch {
+	case isTag:
+		ids = SelectLights(lights, sel)
+	case lights[target] != nil:
+		return []string{target}, false, nil
+	case target == TargetAll:
+		for id := range lights {
+			ids = append(ids, id)
+		}
+	default:
+		if err := ValidateTargetPattern(target); err != nil {
+			return nil, true, err
+		}
+		for id, light := range lights {
+			if MatchTargetPattern(target, id, light.Name) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, true, errors.NotFoundf("no lights match %s", target)
+	}
+	slices.Sort(ids)
+	return ids, true, nil
+}
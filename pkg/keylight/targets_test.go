@@ -0,0 +1,49 @@
+package keylight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestTargetLights(t *testing.T) {
+	m := NewManager(discardLogger())
+	m.lights["Key Light Left._elg._tcp.local."] = Light{ID: "Key Light Left._elg._tcp.local.", Name: "Desk Left"}
+	m.lights["Key Light Right._elg._tcp.local."] = Light{ID: "Key Light Right._elg._tcp.local.", Name: "Desk Right"}
+	m.lights["strip"] = Light{ID: "strip", Name: "Shelf", Tags: map[string]string{"side": "left"}}
+
+	tests := []struct {
+		target   string
+		want     []string
+		selected bool
+	}{
+		{"strip", []string{"strip"}, false},
+		{"unknown", []string{"unknown"}, false},
+		{"all", []string{"Key Light Left._elg._tcp.local.", "Key Light Right._elg._tcp.local.", "strip"}, true},
+		{"Desk*", []string{"Key Light Left._elg._tcp.local.", "Key Light Right._elg._tcp.local."}, true},
+		{"Key Light R*", []string{"Key Light Right._elg._tcp.local."}, true},
+		{"s?rip", []string{"strip"}, true},
+		{"tag:side=left", []string{"strip"}, true},
+	}
+	for _, tt := range tests {
+		ids, selected, err := TargetLights(m, tt.target)
+		require.NoError(t, err, tt.target)
+		assert.Equal(t, tt.want, ids, tt.target)
+		assert.Equal(t, tt.selected, selected, tt.target)
+	}
+
+	_, _, err := TargetLights(m, "Lab*")
+	assert.True(t, errors.IsNotFound(err))
+	_, _, err = TargetLights(m, "Desk[")
+	assert.True(t, errors.IsInvalidInput(err))
+
+	// A light whose ID looks like a pattern is targeted by its ID
+	m.lights["all"] = Light{ID: "all"}
+	ids, selected, err := TargetLights(m, "all")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"all"}, ids)
+	assert.False(t, selected)
+}
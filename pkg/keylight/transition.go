@@ -0,0 +1,270 @@
+package keylight
+
+import (
+	"context"
+	stderrors "errors"
+	"math"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+	"github.com/jmylchreest/keylightd/internal/errors"
+	"github.com/jmylchreest/keylightd/internal/events"
+)
+
+// transitionStepInterval is the delay between intermediate device updates while
+// a transition is running. Kept as a variable so tests can shorten it.
+var transitionStepInterval = 100 * time.Millisecond
+
+// StateChange describes a multi-property light update. Nil fields are left unchanged.
+// Hue and saturation only apply to lights that support color.
+type StateChange struct {
+	On          *bool
+	Brightness  *int
+	Temperature *int
+	Hue         *float64
+	Saturation  *float64
+}
+
+// IsEmpty reports whether the change would modify nothing.
+func (c StateChange) IsEmpty() bool {
+	return c.On == nil && c.Brightness == nil && c.Temperature == nil && !c.HasColor()
+}
+
+// HasColor reports whether the change sets a hue or saturation.
+func (c StateChange) HasColor() bool {
+	return c.Hue != nil || c.Saturation != nil
+}
+
+// Validate checks the brightness, temperature, hue and saturation of the change.
+func (c StateChange) Validate() error {
+	if c.Brightness != nil {
+		if err := BrightnessValue(*c.Brightness).Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Temperature != nil {
+		if err := TemperatureValue(*c.Temperature).Validate(); err != nil {
+			return err
+		}
+	}
+	if color, ok := ColorChange(c.Hue, c.Saturation); ok {
+		return color.Validate()
+	}
+	return nil
+}
+
+type boundTransitionsKey struct{}
+
+// WithBoundTransitions returns a copy of ctx under which transitions stop
+// when ctx ends, instead of running on after the request that started them.
+// It is for operations that run transitions as steps and can be cancelled.
+func WithBoundTransitions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, boundTransitionsKey{}, true)
+}
+
+// transitionHandle tracks an in-flight transition so it can be cancelled by a
+// newer command without a finishing transition removing its successor.
+type transitionHandle struct {
+	cancel context.CancelFunc
+}
+
+// cancelTransition stops any in-flight transition for the light.
+func (m *Manager) cancelTransition(id string) {
+	m.transitionMu.Lock()
+	defer m.transitionMu.Unlock()
+	if h, ok := m.transitions[id]; ok {
+		h.cancel()
+		delete(m.transitions, id)
+	}
+}
+
+// registerTransition records h as the active transition for the light,
+// cancelling any previous one.
+func (m *Manager) registerTransition(id string, h *transitionHandle) {
+	m.transitionMu.Lock()
+	defer m.transitionMu.Unlock()
+	if m.transitions == nil {
+		m.transitions = make(map[string]*transitionHandle)
+	}
+	if prev, ok := m.transitions[id]; ok {
+		prev.cancel()
+	}
+	m.transitions[id] = h
+}
+
+// finishTransition removes h if it is still the active transition for the light.
+func (m *Manager) finishTransition(id string, h *transitionHandle) {
+	m.transitionMu.Lock()
+	defer m.transitionMu.Unlock()
+	if m.transitions[id] == h {
+		delete(m.transitions, id)
+	}
+	h.cancel()
+}
+
+// Transition applies change to a light, ramping brightness and temperature in
+// steps over duration instead of jumping instantly. Turning a light on happens
+// at the start of the ramp; turning it off happens at the end.
+//
+// With a non-positive duration the change is applied immediately. Otherwise
+// Transition validates the request, starts the ramp in the background and
+// returns; any transition already running for the light is cancelled, as is
+// this one if another state change arrives before it completes.
+func (m *Manager) Transition(ctx context.Context, id string, change StateChange, duration time.Duration) error {
+	if err := change.Validate(); err != nil {
+		return errors.InvalidInputf("invalid property value: %w", err)
+	}
+	if duration > config.MaxTransitionDuration {
+		return errors.InvalidInputf("transition duration %s exceeds maximum of %s", duration, config.MaxTransitionDuration)
+	}
+
+	if duration <= 0 {
+		var errs []error
+		if change.On != nil {
+			errs = append(errs, m.SetLightState(ctx, id, OnValue(*change.On)))
+		}
+		if change.Brightness != nil {
+			errs = append(errs, m.SetLightState(ctx, id, BrightnessValue(*change.Brightness)))
+		}
+		if change.Temperature != nil {
+			errs = append(errs, m.SetLightState(ctx, id, TemperatureValue(*change.Temperature)))
+		}
+		if color, ok := ColorChange(change.Hue, change.Saturation); ok {
+			errs = append(errs, m.SetLightState(ctx, id, color))
+		}
+		return stderrors.Join(errs...)
+	}
+	if change.HasColor() {
+		return errors.InvalidInputf("hue and saturation cannot be combined with a transition")
+	}
+
+	// The ramp's steps don't change the light's version; it gets a new one
+	// when the ramp completes.
+	unlock, err := m.lockLight(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = m.checkVersion(ctx, id)
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	client, light, err := m.getOrCreateClient(id)
+	if err != nil {
+		return err
+	}
+	if err := checkSupported(*light, change); err != nil {
+		return err
+	}
+
+	m.cancelTransition(id)
+
+	state, err := m.fetchLightState(ctx, client, id)
+	if err != nil {
+		return err
+	}
+	if len(state.Lights) == 0 {
+		return errors.InvalidInputf("invalid current state")
+	}
+
+	start := state.Lights[0]
+	target := start
+	if change.On != nil && *change.On {
+		target.On = 1
+	}
+	if change.Brightness != nil {
+		target.Brightness = *change.Brightness
+	}
+	if change.Temperature != nil {
+		target.Temperature = convertTemperatureToDevice(*change.Temperature)
+	}
+	target.Brightness, target.Temperature = m.clampToLimits(light, target.Brightness, target.Temperature)
+	turnOff := change.On != nil && !*change.On
+
+	// Detach from the caller's context so the ramp outlives the request that
+	// started it, unless the caller bound its transitions to it.
+	parent := context.WithoutCancel(ctx)
+	if bound, _ := ctx.Value(boundTransitionsKey{}).(bool); bound {
+		parent = ctx
+	}
+	tctx, cancel := context.WithCancel(parent)
+	h := &transitionHandle{cancel: cancel}
+	m.registerTransition(id, h)
+
+	steps := max(int(duration/transitionStepInterval), 1)
+	m.logger.DebugContext(ctx, "light: starting transition", "id", id, "duration", duration, "steps", steps)
+
+	go func() {
+		defer m.finishTransition(id, h)
+
+		ticker := time.NewTicker(duration / time.Duration(steps))
+		defer ticker.Stop()
+
+		for i := 1; i <= steps; i++ {
+			select {
+			case <-tctx.Done():
+				m.logger.DebugContext(tctx, "light: transition cancelled", "id", id, "step", i, "steps", steps)
+				return
+			case <-ticker.C:
+			}
+
+			frac := float64(i) / float64(steps)
+			brightness := start.Brightness + int(math.Round(float64(target.Brightness-start.Brightness)*frac))
+			temperature := start.Temperature + int(math.Round(float64(target.Temperature-start.Temperature)*frac))
+			on := target.On == 1
+			if i == steps && turnOff {
+				on = false
+			}
+
+			if err := m.transitionStep(tctx, client, id, on, brightness, temperature); err != nil {
+				if tctx.Err() == nil {
+					m.logger.ErrorContext(tctx, "light: transition step failed", "id", id, "step", i, "error", err)
+				}
+				return
+			}
+		}
+
+		state.Lights[0].On = target.On
+		if turnOff {
+			state.Lights[0].On = 0
+		}
+		state.Lights[0].Brightness = target.Brightness
+		state.Lights[0].Temperature = target.Temperature
+
+		// A change queued behind the last step supersedes the ramp, and its
+		// state must not be overwritten by the ramp's target.
+		unlock, err := m.lockLight(tctx, id)
+		if err != nil {
+			return
+		}
+		defer unlock()
+		if tctx.Err() != nil {
+			return
+		}
+		m.mu.Lock()
+		updatedLight, err := m.updateLightState(id, state)
+		m.mu.Unlock()
+		if err == nil && updatedLight != nil {
+			m.emit(events.LightStateChanged, updatedLight)
+		}
+		m.logger.DebugContext(tctx, "light: transition complete", "id", id)
+	}()
+
+	return nil
+}
+
+// transitionStep sends one step of a transition through the light's queue.
+// The step is dropped if the transition was cancelled while it waited, as
+// the change that cancelled it may have run in the meantime.
+func (m *Manager) transitionStep(ctx context.Context, client LightDriver, id string, on bool, brightness, temperature int) error {
+	unlock, err := m.lockLight(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return client.SetLightState(ctx, on, brightness, temperature)
+}
@@ -0,0 +1,159 @@
+package keylight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// fakeDevice is a minimal Key Light HTTP endpoint that records every state update.
+type fakeDevice struct {
+	mu    sync.Mutex
+	state LightState
+	puts  []LightState
+}
+
+func (d *fakeDevice) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r.Method == http.MethodPut {
+		var s LightState
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.state = s
+		d.puts = append(d.puts, s)
+	}
+	_ = json.NewEncoder(w).Encode(d.state)
+}
+
+func (d *fakeDevice) updates() []LightState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]LightState(nil), d.puts...)
+}
+
+func newTransitionTestManager(t *testing.T, on, brightness, mireds int) (*Manager, *fakeDevice) {
+	t.Helper()
+	orig := transitionStepInterval
+	transitionStepInterval = 10 * time.Millisecond
+	t.Cleanup(func() { transitionStepInterval = orig })
+
+	device := &fakeDevice{}
+	device.state.NumberOfLights = 1
+	device.state.Lights = append(device.state.Lights, LightStateEntry{On: on, Brightness: brightness, Temperature: mireds})
+	srv := httptest.NewServer(device)
+	t.Cleanup(srv.Close)
+
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	m := NewManager(slog.New(slog.NewTextHandler(bytes.NewBuffer(nil), nil)))
+	m.lights["light1"] = Light{ID: "light1", IP: net.ParseIP(host), Port: port}
+	return m, device
+}
+
+func (m *Manager) transitionActive(id string) bool {
+	m.transitionMu.Lock()
+	defer m.transitionMu.Unlock()
+	_, ok := m.transitions[id]
+	return ok
+}
+
+func TestTransition_RampsToTarget(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 10, 200)
+
+	brightness := 50
+	require.NoError(t, m.Transition(context.Background(), "light1", StateChange{Brightness: &brightness}, 100*time.Millisecond))
+	require.Eventually(t, func() bool { return !m.transitionActive("light1") }, 2*time.Second, 5*time.Millisecond)
+
+	puts := device.updates()
+	require.Len(t, puts, 10)
+	prev := 10
+	for _, p := range puts {
+		assert.GreaterOrEqual(t, p.Lights[0].Brightness, prev)
+		assert.Equal(t, 200, p.Lights[0].Temperature)
+		prev = p.Lights[0].Brightness
+	}
+	assert.Equal(t, 50, puts[len(puts)-1].Lights[0].Brightness)
+
+	light, err := m.GetLight(context.Background(), "light1")
+	require.NoError(t, err)
+	assert.Equal(t, 50, light.Brightness)
+}
+
+func TestTransition_TurnOffAppliesAtEnd(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 80, 200)
+
+	off := false
+	brightness := 10
+	require.NoError(t, m.Transition(context.Background(), "light1", StateChange{On: &off, Brightness: &brightness}, 50*time.Millisecond))
+	require.Eventually(t, func() bool { return !m.transitionActive("light1") }, 2*time.Second, 5*time.Millisecond)
+
+	puts := device.updates()
+	require.NotEmpty(t, puts)
+	for _, p := range puts[:len(puts)-1] {
+		assert.Equal(t, 1, p.Lights[0].On)
+	}
+	assert.Equal(t, 0, puts[len(puts)-1].Lights[0].On)
+}
+
+func TestTransition_CancelledByNewCommand(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 10, 200)
+
+	brightness := 100
+	require.NoError(t, m.Transition(context.Background(), "light1", StateChange{Brightness: &brightness}, 5*time.Second))
+	require.True(t, m.transitionActive("light1"))
+
+	require.NoError(t, m.SetLightState(context.Background(), "light1", BrightnessValue(30)))
+	assert.False(t, m.transitionActive("light1"))
+
+	count := len(device.updates())
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, device.updates(), count, "cancelled transition must not keep sending updates")
+}
+
+func TestTransition_Validation(t *testing.T) {
+	m, _ := newTransitionTestManager(t, 1, 10, 200)
+
+	bad := 150
+	err := m.Transition(context.Background(), "light1", StateChange{Brightness: &bad}, time.Second)
+	assert.True(t, errors.IsInvalidInput(err))
+
+	ok := 50
+	err = m.Transition(context.Background(), "light1", StateChange{Brightness: &ok}, time.Hour)
+	assert.True(t, errors.IsInvalidInput(err))
+
+	err = m.Transition(context.Background(), "missing", StateChange{Brightness: &ok}, time.Second)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestTransition_BoundToContext(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 10, 200)
+
+	ctx, cancel := context.WithCancel(WithBoundTransitions(context.Background()))
+	brightness := 90
+	require.NoError(t, m.Transition(ctx, "light1", StateChange{Brightness: &brightness}, time.Second))
+	require.Eventually(t, func() bool { return len(device.updates()) > 0 }, time.Second, 5*time.Millisecond)
+	cancel()
+	require.Eventually(t, func() bool { return !m.transitionActive("light1") }, time.Second, 5*time.Millisecond)
+
+	puts := device.updates()
+	assert.Less(t, puts[len(puts)-1].Lights[0].Brightness, brightness, "the ramp must stop with its context")
+}
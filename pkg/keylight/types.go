@@ -2,34 +2,65 @@ package keylight
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
+	"fmt"
 	"net"
 	"time"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
 )
 
 // Common errors
 var (
-	ErrLightNotFound = errors.New("light not found")
+	ErrLightNotFound = fmt.Errorf("light not found: %w", errors.ErrNotFound)
 )
 
 // Light represents a Key Light device
 type Light struct {
-	ID                string      `json:"id"`
-	Name              string      `json:"name"`
-	IP                net.IP      `json:"ip"`
-	Port              int         `json:"port"`
-	Temperature       int         `json:"temperature"`
-	Brightness        int         `json:"brightness"`
-	On                bool        `json:"on"`
-	ProductName       string      `json:"productname"`
-	HardwareBoardType int         `json:"hardwareboardtype"`
-	FirmwareVersion   string      `json:"firmwareversion"`
-	FirmwareBuild     int         `json:"firmwarebuild"`
-	SerialNumber      string      `json:"serialnumber"`
-	State             *LightState `json:"state,omitempty"`
-	LastSeen          time.Time   `json:"lastseen"`
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	IP                net.IP            `json:"ip"`
+	Addresses         []net.IP          `json:"addresses,omitempty"` // every address the light was found at, IPv4 first; IP is the one it answered on
+	Port              int               `json:"port"`
+	Driver            string            `json:"driver,omitempty"`
+	Static            bool              `json:"static,omitempty"`
+	Temperature       int               `json:"temperature"`                  // device mireds
+	TemperatureKelvin int               `json:"temperature_kelvin,omitempty"` // Temperature in Kelvin, set when the light is marshaled
+	Brightness        int               `json:"brightness"`
+	On                bool              `json:"on"`
+	Hue               *float64          `json:"hue,omitempty"`          // set while a color light shows a color
+	Saturation        *float64          `json:"saturation,omitempty"`   // set while a color light shows a color
+	ColorMode         string            `json:"colormode,omitempty"`    // temperature or color, for lights that support color
+	Capabilities      *Capabilities     `json:"capabilities,omitempty"` // nil until the light's accessory info is known
+	ProductName       string            `json:"productname"`
+	HardwareBoardType int               `json:"hardwareboardtype"`
+	FirmwareVersion   string            `json:"firmwareversion"`
+	FirmwareBuild     int               `json:"firmwarebuild"`
+	SerialNumber      string            `json:"serialnumber"`
+	State             *LightState       `json:"state,omitempty"`
+	LastSeen          time.Time         `json:"lastseen"`
+	Status            Reachability      `json:"status,omitempty"`
+	Limits            *Limits           `json:"limits,omitempty"` // set on lights returned by a Manager with a limits resolver
+	Tags              map[string]string `json:"tags,omitempty"`   // user-assigned tags, see SetLightTags
+	Version           uint64            `json:"version"`          // state version, see VersionCheck
+}
+
+// MarshalJSON adds the light's temperature in Kelvin, so that clients don't
+// have to convert it from mireds.
+func (l Light) MarshalJSON() ([]byte, error) {
+	type plain Light
+	if l.Temperature != 0 {
+		l.TemperatureKelvin = ConvertDeviceToTemperature(l.Temperature)
+	}
+	return json.Marshal(plain(l))
 }
 
+// Color modes of lights that support color.
+const (
+	ColorModeTemperature = "temperature"
+	ColorModeColor       = "color"
+)
+
 // LightManager defines the interface for managing Keylight devices
 type LightManager interface {
 	GetDiscoveredLights() []*Light
@@ -38,7 +69,17 @@ type LightManager interface {
 	SetLightBrightness(ctx context.Context, id string, brightness int) error
 	SetLightTemperature(ctx context.Context, id string, temperature int) error
 	SetLightPower(ctx context.Context, id string, on bool) error
+	Transition(ctx context.Context, id string, change StateChange, duration time.Duration) error
+	AdjustLight(ctx context.Context, id string, adj Adjustment) error
+	ToggleLight(ctx context.Context, id string) (bool, error)
+	SetLightName(ctx context.Context, id, name string) (*Light, error)
+	SetDeviceName(ctx context.Context, id, name string) (*Light, error)
+	SetLightTags(ctx context.Context, id string, tags map[string]string) (*Light, error)
+	RawRequest(ctx context.Context, id, method, path string, body []byte) (*RawResponse, error)
+	GetLightSettings(ctx context.Context, id string) (*LightSettings, error)
+	SetLightSettings(ctx context.Context, id string, update LightSettingsUpdate) (*LightSettings, error)
 	GetLights() map[string]*Light
+	RefreshLights(ctx context.Context) map[string]*Light
 	AddLight(ctx context.Context, light Light)
 	StartCleanupWorker(ctx context.Context, cleanupInterval time.Duration, timeout time.Duration)
 }
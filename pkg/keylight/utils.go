@@ -1,8 +1,11 @@
 package keylight
 
 import (
+	"math"
 	"strconv"
 	"strings"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
 )
 
 // boolToInt converts a bool to int (true=1, false=0)
@@ -47,6 +50,40 @@ func ConvertDeviceToTemperature(mireds int) int {
 	return 1000000 / mireds
 }
 
+// Units of color temperature accepted by the APIs. Temperatures are in Kelvin
+// unless a request gives its units as mired.
+const (
+	UnitKelvin = "kelvin"
+	UnitMired  = "mired"
+)
+
+// ValidateTemperatureUnits returns an invalid input error if units is not a
+// unit of color temperature. An empty unit is Kelvin.
+func ValidateTemperatureUnits(units string) error {
+	switch units {
+	case "", UnitKelvin, UnitMired:
+		return nil
+	default:
+		return errors.InvalidInputf("invalid temperature units %q, expected %s or %s", units, UnitKelvin, UnitMired)
+	}
+}
+
+// TemperatureToKelvin converts a color temperature given in units to Kelvin.
+// Mireds are converted without clamping, so values outside the range of the
+// lights are still rejected when the Kelvin value is validated.
+func TemperatureToKelvin(value int, units string) (int, error) {
+	if err := ValidateTemperatureUnits(units); err != nil {
+		return 0, err
+	}
+	if units != UnitMired {
+		return value, nil
+	}
+	if value <= 0 {
+		return 0, errors.InvalidInputf("temperature must be a positive number of mireds, got %d", value)
+	}
+	return int(math.Round(1000000 / float64(value))), nil
+}
+
 // UnescapeRFC6763Label unescapes a DNS-SD label per RFC 6763 section 6.4
 func UnescapeRFC6763Label(s string) string {
 	var b strings.Builder
@@ -0,0 +1,39 @@
+package keylight
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestTemperatureToKelvin(t *testing.T) {
+	kelvin, err := TemperatureToKelvin(4500, "")
+	require.NoError(t, err)
+	assert.Equal(t, 4500, kelvin)
+
+	kelvin, err = TemperatureToKelvin(222, UnitMired)
+	require.NoError(t, err)
+	assert.Equal(t, 4505, kelvin)
+
+	_, err = TemperatureToKelvin(0, UnitMired)
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+	_, err = TemperatureToKelvin(4500, "celsius")
+	assert.True(t, errors.IsInvalidInput(err), "got %v", err)
+}
+
+func TestLight_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(Light{ID: "a", Temperature: 200})
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, 200.0, fields["temperature"])
+	assert.Equal(t, 5000.0, fields["temperature_kelvin"])
+
+	data, err = json.Marshal(&Light{ID: "b"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "temperature_kelvin", "an unknown temperature is left out")
+}
@@ -0,0 +1,60 @@
+package keylight
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// validationRules extends the Elgato products discovery accepts beyond
+// validProductNames, so new models can be used before keylightd knows them.
+type validationRules struct {
+	prefixes []string
+	patterns []*regexp.Regexp
+	// anyLight accepts any device reporting the lights feature, whatever its
+	// product name.
+	anyLight bool
+}
+
+// SetValidationRules adds to the Elgato products accepted during discovery:
+// those whose product name starts with one of prefixes or matches one of the
+// regular expressions in patterns, and, if anyLight is set, any device
+// advertising _elg._tcp whose accessory info reports the lights feature. The
+// models keylightd knows are always accepted. An invalid pattern is an error,
+// and nothing is changed.
+func (m *Manager) SetValidationRules(prefixes, patterns []string, anyLight bool) error {
+	rules := validationRules{prefixes: slices.Clone(prefixes), anyLight: anyLight}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return errors.InvalidInputf("invalid product name pattern %q: %w", p, err)
+		}
+		rules.patterns = append(rules.patterns, re)
+	}
+
+	m.mu.Lock()
+	m.validation = rules
+	m.mu.Unlock()
+	return nil
+}
+
+// acceptsElgato reports whether an Elgato device's accessory info belongs to
+// a light rules accept.
+func acceptsElgato(info *AccessoryInfo, rules validationRules) bool {
+	if slices.Contains(validProductNames, info.ProductName) {
+		return true
+	}
+	for _, prefix := range rules.prefixes {
+		if strings.HasPrefix(info.ProductName, prefix) {
+			return true
+		}
+	}
+	for _, re := range rules.patterns {
+		if re.MatchString(info.ProductName) {
+			return true
+		}
+	}
+	return rules.anyLight && slices.Contains(info.Features, FeatureLights)
+}
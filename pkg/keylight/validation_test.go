@@ -0,0 +1,64 @@
+package keylight
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestAcceptsElgato(t *testing.T) {
+	m := NewManager(discardLogger())
+	require.NoError(t, m.SetValidationRules([]string{"Elgato Key Light Neo"}, []string{`^Elgato (Panel|Beam) \d+$`}, false))
+	rules := m.validation
+
+	tests := []struct {
+		name string
+		info AccessoryInfo
+		want bool
+	}{
+		{"known model", AccessoryInfo{ProductName: "Elgato Key Light Air"}, true},
+		{"prefix", AccessoryInfo{ProductName: "Elgato Key Light Neo 2"}, true},
+		{"pattern", AccessoryInfo{ProductName: "Elgato Beam 40"}, true},
+		{"pattern must match", AccessoryInfo{ProductName: "Elgato Beam Pro"}, false},
+		{"unknown", AccessoryInfo{ProductName: "Elgato Stream Deck", Features: []string{FeatureLights}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, acceptsElgato(&tt.info, rules))
+		})
+	}
+}
+
+func TestAcceptsElgato_AnyLight(t *testing.T) {
+	rules := validationRules{anyLight: true}
+	assert.True(t, acceptsElgato(&AccessoryInfo{ProductName: "Elgato Light Panel", Features: []string{FeatureLights}}, rules))
+	assert.False(t, acceptsElgato(&AccessoryInfo{ProductName: "Elgato Wave Panel"}, rules), "devices without the lights feature are still rejected")
+}
+
+func TestManager_SetValidationRules_InvalidPattern(t *testing.T) {
+	m := NewManager(discardLogger())
+	require.NoError(t, m.SetValidationRules([]string{"Elgato Key"}, nil, false))
+
+	err := m.SetValidationRules(nil, []string{"Key Light ("}, true)
+	require.Error(t, err)
+	assert.True(t, errors.IsInvalidInput(err))
+	assert.Equal(t, []string{"Elgato Key"}, m.validation.prefixes, "rules are unchanged on error")
+}
+
+func TestValidateLight_ValidationRules(t *testing.T) {
+	server := httptest.NewServer(newAccessoryInfoHandler("Elgato Key Light Neo", 300, "Neo", 0))
+	defer server.Close()
+	entry := makeServiceEntry(t, server, "neo._elg._tcp.local.")
+
+	_, valid := validateLight(context.Background(), entry, validationRules{}, discardLogger())
+	assert.False(t, valid, "unknown models are rejected by default")
+
+	light, valid := validateLight(context.Background(), entry, validationRules{prefixes: []string{"Elgato Key Light"}}, discardLogger())
+	assert.True(t, valid)
+	assert.Equal(t, "Elgato Key Light Neo", light.ProductName)
+}
@@ -0,0 +1,65 @@
+package keylight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestVersion_BumpedOnStateChange(t *testing.T) {
+	m, _ := newTransitionTestManager(t, 1, 50, 200)
+	ctx := context.Background()
+
+	light, err := m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+	first := light.Version
+	assert.NotZero(t, first)
+
+	require.NoError(t, m.SetLightState(ctx, "light1", BrightnessValue(60)))
+	light, err = m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.Greater(t, light.Version, first)
+
+	// Setting the state the light already has isn't a change
+	second := light.Version
+	require.NoError(t, m.SetLightState(ctx, "light1", BrightnessValue(60)))
+	light, err = m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+	assert.Equal(t, second, light.Version)
+}
+
+func TestVersion_Check(t *testing.T) {
+	m, device := newTransitionTestManager(t, 1, 50, 200)
+	ctx := context.Background()
+
+	light, err := m.GetLight(ctx, "light1")
+	require.NoError(t, err)
+
+	// Several changes under one check succeed, as each moves it on
+	check := ExpectVersion(light.Version)
+	checked := WithVersionCheck(ctx, check)
+	require.NoError(t, m.SetLightState(checked, "light1", BrightnessValue(60)))
+	require.NoError(t, m.AdjustLight(checked, "light1", Adjustment{Brightness: 10}))
+
+	// Another controller changes the light
+	require.NoError(t, m.SetLightState(ctx, "light1", OnValue(false)))
+
+	sent := len(device.updates())
+	err = m.SetLightState(checked, "light1", BrightnessValue(80))
+	assert.True(t, errors.IsConflict(err))
+	_, err = m.ToggleLight(checked, "light1")
+	assert.True(t, errors.IsConflict(err))
+	brightness := 90
+	err = m.Transition(checked, "light1", StateChange{Brightness: &brightness}, 100*time.Millisecond)
+	assert.True(t, errors.IsConflict(err))
+	assert.Len(t, device.updates(), sent, "a stale change must not reach the device")
+
+	// Unknown lights are reported as such
+	err = m.SetLightState(WithVersionCheck(ctx, ExpectVersion(1)), "missing", OnValue(true))
+	assert.True(t, errors.IsNotFound(err))
+}
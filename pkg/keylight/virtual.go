@@ -0,0 +1,202 @@
+package keylight
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+// DriverVirtual marks lights simulated in memory by the Manager, see
+// AddVirtualLights. It cannot be used for static lights.
+const DriverVirtual = "virtual"
+
+// MaxVirtualLights is the most virtual lights a Manager can simulate.
+const MaxVirtualLights = 100
+
+// virtualProducts are the products virtual lights pretend to be, in turn, so
+// that a handful of them covers white and color lights.
+var virtualProducts = []string{
+	"Elgato Key Light",
+	"Elgato Key Light Air",
+	"Elgato Key Light MK.2",
+	"Elgato Light Strip",
+}
+
+// ErrSimulatedFailure is returned by requests to a virtual light that were
+// chosen to fail, see VirtualLightOptions.FailureRate.
+var ErrSimulatedFailure = errors.DeviceUnavailablef("simulated device failure")
+
+// VirtualLightOptions configures the lights created by AddVirtualLights.
+type VirtualLightOptions struct {
+	Count       int           // Lights to create, 1-MaxVirtualLights
+	Latency     time.Duration // Delay added to every request, as if sent over the network
+	FailureRate float64       // Fraction of requests, 0-1, that fail with ErrSimulatedFailure
+}
+
+// Validate checks that the options are in range.
+func (o VirtualLightOptions) Validate() error {
+	if o.Count < 1 || o.Count > MaxVirtualLights {
+		return errors.InvalidInputf("virtual light count must be between 1 and %d", MaxVirtualLights)
+	}
+	if o.Latency < 0 {
+		return errors.InvalidInputf("virtual light latency cannot be negative")
+	}
+	if o.FailureRate < 0 || o.FailureRate > 1 {
+		return errors.InvalidInputf("virtual light failure rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// AddVirtualLights adds lights that are simulated in memory instead of being
+// found on the network, for developing and testing clients without hardware.
+// They are named "Virtual Light 1" and so on, with IDs virtual-1, virtual-2,
+// ..., and behave like real lights: they keep their state, answer after
+// opts.Latency and fail at random at opts.FailureRate. Like static lights they
+// are never removed. It returns the IDs of the lights added.
+func (m *Manager) AddVirtualLights(ctx context.Context, opts VirtualLightOptions) ([]string, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, opts.Count)
+	for i := range opts.Count {
+		id := fmt.Sprintf("virtual-%d", i+1)
+		product, board := virtualProducts[i%len(virtualProducts)], 53
+		if slices.Contains(colorProductNames, product) {
+			board = colorBoardTypes[0]
+		}
+		m.virtualMu.Lock()
+		if m.virtual == nil {
+			m.virtual = make(map[string]*virtualLight)
+		}
+		m.virtual[id] = &virtualLight{
+			info: AccessoryInfo{
+				ProductName:         product,
+				HardwareBoardType:   board,
+				FirmwareBuildNumber: 218,
+				FirmwareVersion:     "1.0.3",
+				SerialNumber:        fmt.Sprintf("VIRTUAL%04d", i+1),
+				DisplayName:         fmt.Sprintf("Virtual Light %d", i+1),
+				Features:            []string{FeatureLights},
+			},
+			state:       LightStateEntry{Brightness: 50, Temperature: 213},
+			latency:     opts.Latency,
+			failureRate: opts.FailureRate,
+		}
+		m.virtualMu.Unlock()
+
+		m.AddLight(ctx, Light{ID: id, IP: net.IPv4(127, 0, 0, 1), Port: 9123 + i, Driver: DriverVirtual, Static: true})
+		ids = append(ids, id)
+	}
+	m.logger.InfoContext(ctx, "light: virtual lights added", "count", opts.Count,
+		"latency", opts.Latency, "failure_rate", opts.FailureRate)
+	return ids, nil
+}
+
+// virtualDriver returns the simulated device of a virtual light. It may be
+// called with m.mu held.
+func (m *Manager) virtualDriver(id string) (LightDriver, bool) {
+	m.virtualMu.Lock()
+	defer m.virtualMu.Unlock()
+	v, ok := m.virtual[id]
+	if !ok {
+		return nil, false
+	}
+	return v, true
+}
+
+// virtualLight is the in-memory device behind a virtual light.
+type virtualLight struct {
+	mu          sync.Mutex
+	info        AccessoryInfo
+	state       LightStateEntry
+	latency     time.Duration
+	failureRate float64
+}
+
+var (
+	_ LightDriver       = (*virtualLight)(nil)
+	_ ColorSetter       = (*virtualLight)(nil)
+	_ DisplayNameSetter = (*virtualLight)(nil)
+)
+
+// request simulates the round trip of a request to the device.
+func (v *virtualLight) request(ctx context.Context) error {
+	if v.latency > 0 {
+		timer := time.NewTimer(v.latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if v.failureRate > 0 && rand.Float64() < v.failureRate {
+		return ErrSimulatedFailure
+	}
+	return nil
+}
+
+func (v *virtualLight) GetAccessoryInfo(ctx context.Context) (*AccessoryInfo, error) {
+	if err := v.request(ctx); err != nil {
+		return nil, err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	info := v.info
+	return &info, nil
+}
+
+func (v *virtualLight) GetLightState(ctx context.Context) (*LightState, error) {
+	if err := v.request(ctx); err != nil {
+		return nil, err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return &LightState{NumberOfLights: 1, Lights: []LightStateEntry{v.state}}, nil
+}
+
+func (v *virtualLight) SetLightState(ctx context.Context, on bool, brightness, temperature int) error {
+	if err := v.request(ctx); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.state = LightStateEntry{
+		On:          boolToInt(on),
+		Brightness:  max(3, min(brightness, 100)),
+		Temperature: max(143, min(temperature, 344)),
+	}
+	return nil
+}
+
+func (v *virtualLight) SetLightColor(ctx context.Context, on bool, brightness int, hue, saturation float64) error {
+	if err := v.request(ctx); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.state = LightStateEntry{
+		On:         boolToInt(on),
+		Brightness: max(3, min(brightness, 100)),
+		Hue:        &hue,
+		Saturation: &saturation,
+	}
+	return nil
+}
+
+func (v *virtualLight) SetDisplayName(ctx context.Context, name string) error {
+	if err := v.request(ctx); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.info.DisplayName = name
+	return nil
+}
@@ -0,0 +1,59 @@
+package keylight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmylchreest/keylightd/internal/errors"
+)
+
+func TestAddVirtualLights(t *testing.T) {
+	m := NewManager(discardLogger())
+	ids, err := m.AddVirtualLights(context.Background(), VirtualLightOptions{Count: 4})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"virtual-1", "virtual-2", "virtual-3", "virtual-4"}, ids)
+
+	lights := m.GetLights()
+	require.Len(t, lights, 4)
+	light := lights["virtual-1"]
+	assert.Equal(t, "Virtual Light 1", light.Name)
+	assert.Equal(t, "Elgato Key Light", light.ProductName)
+	assert.Equal(t, ReachabilityOnline, light.Status)
+	assert.True(t, light.Static)
+	assert.False(t, light.Supports(PropertyHue))
+	assert.True(t, lights["virtual-4"].Supports(PropertyHue), "every fourth light is a Light Strip")
+
+	require.NoError(t, m.SetLightState(context.Background(), "virtual-1", OnValue(true)))
+	require.NoError(t, m.SetLightBrightness(context.Background(), "virtual-1", 80))
+	state, err := m.virtual["virtual-1"].GetLightState(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Lights[0].On)
+	assert.Equal(t, 80, state.Lights[0].Brightness)
+}
+
+func TestVirtualLightFailures(t *testing.T) {
+	v := &virtualLight{failureRate: 1}
+	_, err := v.GetLightState(context.Background())
+	assert.ErrorIs(t, err, ErrSimulatedFailure)
+
+	v = &virtualLight{latency: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, v.SetLightState(ctx, true, 50, 200), context.DeadlineExceeded)
+}
+
+func TestVirtualLightOptionsValidate(t *testing.T) {
+	for _, opts := range []VirtualLightOptions{
+		{Count: 0},
+		{Count: MaxVirtualLights + 1},
+		{Count: 1, Latency: -time.Second},
+		{Count: 1, FailureRate: 1.5},
+	} {
+		assert.True(t, errors.IsInvalidInput(opts.Validate()), opts)
+	}
+	assert.NoError(t, VirtualLightOptions{Count: 1, FailureRate: 0.5}.Validate())
+}
@@ -0,0 +1,193 @@
+package keylight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/jmylchreest/keylightd/internal/config"
+)
+
+// wledMaxValue is the top of WLED's 8-bit brightness and relative CCT scales.
+const wledMaxValue = 255
+
+// wledInfo is the subset of WLED's /json/info response used by the driver.
+type wledInfo struct {
+	Version string `json:"ver"`
+	Build   int    `json:"vid"`
+	Name    string `json:"name"`
+	MAC     string `json:"mac"`
+	Arch    string `json:"arch"`
+	Brand   string `json:"brand"`
+	Product string `json:"product"`
+}
+
+// wledSegment is the subset of a WLED segment used by the driver.
+type wledSegment struct {
+	CCT int `json:"cct"`
+}
+
+// wledState is the subset of WLED's /json/state used by the driver.
+type wledState struct {
+	On       bool          `json:"on"`
+	Bri      int           `json:"bri"`
+	Segments []wledSegment `json:"seg,omitempty"`
+}
+
+// WLEDClient controls a WLED device through its JSON API.
+// Brightness is mapped between 0-100% and WLED's 0-255 scale, and
+// temperature between device mireds and WLED's relative CCT (0 warm, 255 cool).
+type WLEDClient struct {
+	baseURL    string
+	httpClient *http.Client
+	logger     *slog.Logger
+}
+
+// NewWLEDClient creates a new client for a WLED device
+func NewWLEDClient(ip string, port int, logger *slog.Logger, httpClient ...*http.Client) *WLEDClient {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	var hc *http.Client
+	if len(httpClient) > 0 && httpClient[0] != nil {
+		hc = httpClient[0]
+	} else {
+		hc = &http.Client{Timeout: defaultRequestTimeout}
+	}
+	return &WLEDClient{
+		baseURL:    "http://" + net.JoinHostPort(ip, strconv.Itoa(port)) + "/json",
+		httpClient: hc,
+		logger:     logger,
+	}
+}
+
+// doGet performs a GET request to the given path and JSON-decodes the response into result.
+func (c *WLEDClient) doGet(ctx context.Context, path string, result any) error {
+	url := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req) //nolint:gosec // G704: URL is from discovered light address
+	if err != nil {
+		c.logger.ErrorContext(ctx, "wled: request failed", "url", url, "error", err)
+		return fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		c.logger.ErrorContext(ctx, "wled: request failed", "url", url, "error", err)
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		c.logger.ErrorContext(ctx, "wled: decode failed", "url", url, "error", err)
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// GetAccessoryInfo retrieves basic device information from /json/info
+func (c *WLEDClient) GetAccessoryInfo(ctx context.Context) (*AccessoryInfo, error) {
+	var info wledInfo
+	if err := c.doGet(ctx, "/info", &info); err != nil {
+		return nil, err
+	}
+	product := info.Brand
+	if product == "" {
+		product = "WLED"
+	}
+	if info.Product != "" {
+		product += " " + info.Product
+	}
+	return &AccessoryInfo{
+		ProductName:         product,
+		FirmwareVersion:     info.Version,
+		FirmwareBuildNumber: info.Build,
+		SerialNumber:        info.MAC,
+		DisplayName:         info.Name,
+	}, nil
+}
+
+// GetLightState retrieves the current state from /json/state
+func (c *WLEDClient) GetLightState(ctx context.Context) (*LightState, error) {
+	var ws wledState
+	if err := c.doGet(ctx, "/state", &ws); err != nil {
+		return nil, err
+	}
+
+	cct := wledMaxValue / 2
+	if len(ws.Segments) > 0 {
+		cct = ws.Segments[0].CCT
+	}
+
+	state := &LightState{NumberOfLights: 1}
+	state.Lights = append(state.Lights, LightStateEntry{
+		On:          boolToInt(ws.On),
+		Brightness:  int(math.Round(float64(ws.Bri) * 100 / wledMaxValue)),
+		Temperature: wledCCTToMireds(cct),
+	})
+	return state, nil
+}
+
+// SetLightState updates the state of the device via POST /json/state
+func (c *WLEDClient) SetLightState(ctx context.Context, on bool, brightness, temperature int) error {
+	brightness = max(0, min(brightness, 100))
+	payload := wledState{
+		On:       on,
+		Bri:      int(math.Round(float64(brightness) * wledMaxValue / 100)),
+		Segments: []wledSegment{{CCT: wledMiredsToCCT(temperature)}},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := c.baseURL + "/state"
+	c.logger.DebugContext(ctx, "wled: setting state", "url", url, "payload", string(jsonData))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec // G704: URL is from discovered light address
+	if err != nil {
+		return fmt.Errorf("failed to set light state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// wledCCTToMireds converts a WLED CCT value to device mireds. Values up to 255 are
+// relative (0 warmest, 255 coolest) and are spread over the supported Kelvin range;
+// larger values are absolute Kelvin.
+func wledCCTToMireds(cct int) int {
+	kelvin := cct
+	if cct <= wledMaxValue {
+		kelvin = config.MinTemperature + int(math.Round(float64(max(cct, 0))*(config.MaxTemperature-config.MinTemperature)/wledMaxValue))
+	}
+	return convertTemperatureToDevice(kelvin)
+}
+
+// wledMiredsToCCT converts device mireds to WLED's relative CCT scale.
+func wledMiredsToCCT(mireds int) int {
+	kelvin := ConvertDeviceToTemperature(mireds)
+	cct := math.Round(float64(kelvin-config.MinTemperature) * wledMaxValue / (config.MaxTemperature - config.MinTemperature))
+	return max(0, min(int(cct), wledMaxValue))
+}
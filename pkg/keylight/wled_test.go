@@ -0,0 +1,134 @@
+package keylight
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWLEDTestServer(t *testing.T, state *wledState) (*httptest.Server, *[]wledState) {
+	t.Helper()
+	var posted []wledState
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /json/info", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ver": "0.14.0", "vid": 2310130, "name": "Desk Strip",
+			"mac": "aabbccddeeff", "brand": "WLED", "product": "FOSS",
+		})
+	})
+	mux.HandleFunc("GET /json/state", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(state)
+	})
+	mux.HandleFunc("POST /json/state", func(w http.ResponseWriter, r *http.Request) {
+		var s wledState
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		posted = append(posted, s)
+		_, _ = w.Write([]byte(`{"success":true}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &posted
+}
+
+func hostPort(t *testing.T, srv *httptest.Server) (string, int) {
+	t.Helper()
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+	return host, port
+}
+
+func TestWLEDClient_GetAccessoryInfo(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{})
+	host, port := hostPort(t, srv)
+
+	info, err := NewWLEDClient(host, port, discardLogger()).GetAccessoryInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "WLED FOSS", info.ProductName)
+	assert.Equal(t, "0.14.0", info.FirmwareVersion)
+	assert.Equal(t, 2310130, info.FirmwareBuildNumber)
+	assert.Equal(t, "aabbccddeeff", info.SerialNumber)
+	assert.Equal(t, "Desk Strip", info.DisplayName)
+}
+
+func TestWLEDClient_GetLightState(t *testing.T) {
+	srv, _ := newWLEDTestServer(t, &wledState{On: true, Bri: 128, Segments: []wledSegment{{CCT: 0}}})
+	host, port := hostPort(t, srv)
+
+	state, err := NewWLEDClient(host, port, discardLogger()).GetLightState(context.Background())
+	require.NoError(t, err)
+	require.Len(t, state.Lights, 1)
+	assert.Equal(t, 1, state.Lights[0].On)
+	assert.Equal(t, 50, state.Lights[0].Brightness)
+	assert.Equal(t, 344, state.Lights[0].Temperature) // warmest
+}
+
+func TestWLEDClient_SetLightState(t *testing.T) {
+	srv, posted := newWLEDTestServer(t, &wledState{})
+	host, port := hostPort(t, srv)
+
+	err := NewWLEDClient(host, port, discardLogger()).SetLightState(context.Background(), true, 100, 143)
+	require.NoError(t, err)
+	require.Len(t, *posted, 1)
+	got := (*posted)[0]
+	assert.True(t, got.On)
+	assert.Equal(t, 255, got.Bri)
+	require.Len(t, got.Segments, 1)
+	assert.Equal(t, 255, got.Segments[0].CCT) // coolest
+}
+
+func TestWLEDCCTConversion(t *testing.T) {
+	for _, cct := range []int{0, 64, 128, 200, 255} {
+		assert.InDelta(t, cct, wledMiredsToCCT(wledCCTToMireds(cct)), 3, "cct %d", cct)
+	}
+	// Values above 255 are absolute Kelvin
+	assert.Equal(t, convertTemperatureToDevice(4000), wledCCTToMireds(4000))
+}
+
+func TestManager_UsesLightDriver(t *testing.T) {
+	srv, posted := newWLEDTestServer(t, &wledState{On: false, Bri: 64, Segments: []wledSegment{{CCT: 128}}})
+	host, port := hostPort(t, srv)
+
+	m := NewManager(discardLogger())
+	m.AddLight(context.Background(), Light{ID: "strip", IP: net.ParseIP(host), Port: port, Driver: DriverWLED})
+
+	light, err := m.GetLight(context.Background(), "strip")
+	require.NoError(t, err)
+	assert.Equal(t, DriverWLED, light.Driver)
+	assert.Equal(t, "Desk Strip", light.Name)
+	assert.Equal(t, 25, light.Brightness)
+
+	require.NoError(t, m.SetLightPower(context.Background(), "strip", true))
+	require.NotEmpty(t, *posted)
+	assert.True(t, (*posted)[len(*posted)-1].On)
+}
+
+func TestDriverRegistry(t *testing.T) {
+	assert.Contains(t, Drivers(), DriverElgato)
+	assert.Contains(t, Drivers(), DriverWLED)
+	assert.Contains(t, discoveryServices(), "_elg._tcp")
+	assert.Contains(t, discoveryServices(), "_wled._tcp")
+
+	name, ok := driverForService("_wled._tcp")
+	assert.True(t, ok)
+	assert.Equal(t, DriverWLED, name)
+
+	spec, ok := lookupDriver("")
+	require.True(t, ok)
+	_, isElgato := spec.factory("127.0.0.1", 9123, discardLogger()).(*KeyLightClient)
+	assert.True(t, isElgato)
+
+	_, ok = lookupDriver("nope")
+	assert.False(t, ok)
+}
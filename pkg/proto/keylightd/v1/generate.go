@@ -0,0 +1,6 @@
+// Package keylightdv1 contains the generated types and gRPC stubs for the
+// keylightd v1 API. Edit keylightd.proto and regenerate with go generate,
+// which needs protoc, protoc-gen-go and protoc-gen-go-grpc on the PATH.
+package keylightdv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative keylightd.proto
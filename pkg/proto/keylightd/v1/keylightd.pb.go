@@ -0,0 +1,1890 @@
+// gRPC API for keylightd.
+//
+// The daemon serves these services on its Unix socket, alongside the JSON line
+// protocol, and on config.grpc.listen_address when set. Requests over TCP must
+// carry an API key in the "authorization" ("Bearer <key>") or "x-api-key"
+// metadata.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: keylightd.proto
+
+package keylightdv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Light struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Ip    string                 `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
+	Port  int32                  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	// Device driver: elgato or wled.
+	Driver string `protobuf:"bytes,5,opt,name=driver,proto3" json:"driver,omitempty"`
+	// Whether the light is declared in the config rather than discovered.
+	Static bool `protobuf:"varint,6,opt,name=static,proto3" json:"static,omitempty"`
+	On     bool `protobuf:"varint,7,opt,name=on,proto3" json:"on,omitempty"`
+	// Brightness in percent (3-100).
+	Brightness int32 `protobuf:"varint,8,opt,name=brightness,proto3" json:"brightness,omitempty"`
+	// Colour temperature in device mireds.
+	Temperature       int32                  `protobuf:"varint,9,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	TemperatureKelvin int32                  `protobuf:"varint,10,opt,name=temperature_kelvin,json=temperatureKelvin,proto3" json:"temperature_kelvin,omitempty"`
+	ProductName       string                 `protobuf:"bytes,11,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	SerialNumber      string                 `protobuf:"bytes,12,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	FirmwareVersion   string                 `protobuf:"bytes,13,opt,name=firmware_version,json=firmwareVersion,proto3" json:"firmware_version,omitempty"`
+	FirmwareBuild     int32                  `protobuf:"varint,14,opt,name=firmware_build,json=firmwareBuild,proto3" json:"firmware_build,omitempty"`
+	LastSeen          *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	// Reachability: online, degraded or offline.
+	Status        string `protobuf:"bytes,16,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Light) Reset() {
+	*x = Light{}
+	mi := &file_keylightd_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Light) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Light) ProtoMessage() {}
+
+func (x *Light) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Light.ProtoReflect.Descriptor instead.
+func (*Light) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Light) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Light) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Light) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Light) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Light) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Light) GetStatic() bool {
+	if x != nil {
+		return x.Static
+	}
+	return false
+}
+
+func (x *Light) GetOn() bool {
+	if x != nil {
+		return x.On
+	}
+	return false
+}
+
+func (x *Light) GetBrightness() int32 {
+	if x != nil {
+		return x.Brightness
+	}
+	return 0
+}
+
+func (x *Light) GetTemperature() int32 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *Light) GetTemperatureKelvin() int32 {
+	if x != nil {
+		return x.TemperatureKelvin
+	}
+	return 0
+}
+
+func (x *Light) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Light) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *Light) GetFirmwareVersion() string {
+	if x != nil {
+		return x.FirmwareVersion
+	}
+	return ""
+}
+
+func (x *Light) GetFirmwareBuild() int32 {
+	if x != nil {
+		return x.FirmwareBuild
+	}
+	return 0
+}
+
+func (x *Light) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Light) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListLightsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLightsRequest) Reset() {
+	*x = ListLightsRequest{}
+	mi := &file_keylightd_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLightsRequest) ProtoMessage() {}
+
+func (x *ListLightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLightsRequest.ProtoReflect.Descriptor instead.
+func (*ListLightsRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{1}
+}
+
+type ListLightsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lights        []*Light               `protobuf:"bytes,1,rep,name=lights,proto3" json:"lights,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLightsResponse) Reset() {
+	*x = ListLightsResponse{}
+	mi := &file_keylightd_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLightsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLightsResponse) ProtoMessage() {}
+
+func (x *ListLightsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLightsResponse.ProtoReflect.Descriptor instead.
+func (*ListLightsResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListLightsResponse) GetLights() []*Light {
+	if x != nil {
+		return x.Lights
+	}
+	return nil
+}
+
+type GetLightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLightRequest) Reset() {
+	*x = GetLightRequest{}
+	mi := &file_keylightd_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLightRequest) ProtoMessage() {}
+
+func (x *GetLightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLightRequest.ProtoReflect.Descriptor instead.
+func (*GetLightRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetLightRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetLightResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Light         *Light                 `protobuf:"bytes,1,opt,name=light,proto3" json:"light,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLightResponse) Reset() {
+	*x = GetLightResponse{}
+	mi := &file_keylightd_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLightResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLightResponse) ProtoMessage() {}
+
+func (x *GetLightResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLightResponse.ProtoReflect.Descriptor instead.
+func (*GetLightResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetLightResponse) GetLight() *Light {
+	if x != nil {
+		return x.Light
+	}
+	return nil
+}
+
+type SetLightStateRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	On                *bool                  `protobuf:"varint,2,opt,name=on,proto3,oneof" json:"on,omitempty"`
+	Brightness        *int32                 `protobuf:"varint,3,opt,name=brightness,proto3,oneof" json:"brightness,omitempty"`
+	TemperatureKelvin *int32                 `protobuf:"varint,4,opt,name=temperature_kelvin,json=temperatureKelvin,proto3,oneof" json:"temperature_kelvin,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetLightStateRequest) Reset() {
+	*x = SetLightStateRequest{}
+	mi := &file_keylightd_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLightStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLightStateRequest) ProtoMessage() {}
+
+func (x *SetLightStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLightStateRequest.ProtoReflect.Descriptor instead.
+func (*SetLightStateRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SetLightStateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetLightStateRequest) GetOn() bool {
+	if x != nil && x.On != nil {
+		return *x.On
+	}
+	return false
+}
+
+func (x *SetLightStateRequest) GetBrightness() int32 {
+	if x != nil && x.Brightness != nil {
+		return *x.Brightness
+	}
+	return 0
+}
+
+func (x *SetLightStateRequest) GetTemperatureKelvin() int32 {
+	if x != nil && x.TemperatureKelvin != nil {
+		return *x.TemperatureKelvin
+	}
+	return 0
+}
+
+type SetLightStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Light         *Light                 `protobuf:"bytes,1,opt,name=light,proto3" json:"light,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLightStateResponse) Reset() {
+	*x = SetLightStateResponse{}
+	mi := &file_keylightd_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLightStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLightStateResponse) ProtoMessage() {}
+
+func (x *SetLightStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLightStateResponse.ProtoReflect.Descriptor instead.
+func (*SetLightStateResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SetLightStateResponse) GetLight() *Light {
+	if x != nil {
+		return x.Light
+	}
+	return nil
+}
+
+type Group struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	LightIds      []string               `protobuf:"bytes,3,rep,name=light_ids,json=lightIds,proto3" json:"light_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_keylightd_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Group) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetLightIds() []string {
+	if x != nil {
+		return x.LightIds
+	}
+	return nil
+}
+
+type ListGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsRequest) Reset() {
+	*x = ListGroupsRequest{}
+	mi := &file_keylightd_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsRequest) ProtoMessage() {}
+
+func (x *ListGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{8}
+}
+
+type ListGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*Group               `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResponse) Reset() {
+	*x = ListGroupsResponse{}
+	mi := &file_keylightd_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResponse) ProtoMessage() {}
+
+func (x *ListGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListGroupsResponse) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type GetGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupRequest) Reset() {
+	*x = GetGroupRequest{}
+	mi := &file_keylightd_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupRequest) ProtoMessage() {}
+
+func (x *GetGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupRequest.ProtoReflect.Descriptor instead.
+func (*GetGroupRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *Group                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupResponse) Reset() {
+	*x = GetGroupResponse{}
+	mi := &file_keylightd_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupResponse) ProtoMessage() {}
+
+func (x *GetGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupResponse.ProtoReflect.Descriptor instead.
+func (*GetGroupResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetGroupResponse) GetGroup() *Group {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+type CreateGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	LightIds      []string               `protobuf:"bytes,2,rep,name=light_ids,json=lightIds,proto3" json:"light_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGroupRequest) Reset() {
+	*x = CreateGroupRequest{}
+	mi := &file_keylightd_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGroupRequest) ProtoMessage() {}
+
+func (x *CreateGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGroupRequest.ProtoReflect.Descriptor instead.
+func (*CreateGroupRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateGroupRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateGroupRequest) GetLightIds() []string {
+	if x != nil {
+		return x.LightIds
+	}
+	return nil
+}
+
+type CreateGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *Group                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGroupResponse) Reset() {
+	*x = CreateGroupResponse{}
+	mi := &file_keylightd_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGroupResponse) ProtoMessage() {}
+
+func (x *CreateGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGroupResponse.ProtoReflect.Descriptor instead.
+func (*CreateGroupResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CreateGroupResponse) GetGroup() *Group {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+type DeleteGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteGroupRequest) Reset() {
+	*x = DeleteGroupRequest{}
+	mi := &file_keylightd_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteGroupRequest) ProtoMessage() {}
+
+func (x *DeleteGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteGroupRequest.ProtoReflect.Descriptor instead.
+func (*DeleteGroupRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteGroupResponse) Reset() {
+	*x = DeleteGroupResponse{}
+	mi := &file_keylightd_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteGroupResponse) ProtoMessage() {}
+
+func (x *DeleteGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteGroupResponse.ProtoReflect.Descriptor instead.
+func (*DeleteGroupResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{15}
+}
+
+type SetGroupLightsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	LightIds      []string               `protobuf:"bytes,2,rep,name=light_ids,json=lightIds,proto3" json:"light_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetGroupLightsRequest) Reset() {
+	*x = SetGroupLightsRequest{}
+	mi := &file_keylightd_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetGroupLightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetGroupLightsRequest) ProtoMessage() {}
+
+func (x *SetGroupLightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetGroupLightsRequest.ProtoReflect.Descriptor instead.
+func (*SetGroupLightsRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SetGroupLightsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetGroupLightsRequest) GetLightIds() []string {
+	if x != nil {
+		return x.LightIds
+	}
+	return nil
+}
+
+type SetGroupLightsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *Group                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetGroupLightsResponse) Reset() {
+	*x = SetGroupLightsResponse{}
+	mi := &file_keylightd_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetGroupLightsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetGroupLightsResponse) ProtoMessage() {}
+
+func (x *SetGroupLightsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetGroupLightsResponse.ProtoReflect.Descriptor instead.
+func (*SetGroupLightsResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SetGroupLightsResponse) GetGroup() *Group {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+type SetGroupStateRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	On                *bool                  `protobuf:"varint,2,opt,name=on,proto3,oneof" json:"on,omitempty"`
+	Brightness        *int32                 `protobuf:"varint,3,opt,name=brightness,proto3,oneof" json:"brightness,omitempty"`
+	TemperatureKelvin *int32                 `protobuf:"varint,4,opt,name=temperature_kelvin,json=temperatureKelvin,proto3,oneof" json:"temperature_kelvin,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetGroupStateRequest) Reset() {
+	*x = SetGroupStateRequest{}
+	mi := &file_keylightd_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetGroupStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetGroupStateRequest) ProtoMessage() {}
+
+func (x *SetGroupStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetGroupStateRequest.ProtoReflect.Descriptor instead.
+func (*SetGroupStateRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SetGroupStateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetGroupStateRequest) GetOn() bool {
+	if x != nil && x.On != nil {
+		return *x.On
+	}
+	return false
+}
+
+func (x *SetGroupStateRequest) GetBrightness() int32 {
+	if x != nil && x.Brightness != nil {
+		return *x.Brightness
+	}
+	return 0
+}
+
+func (x *SetGroupStateRequest) GetTemperatureKelvin() int32 {
+	if x != nil && x.TemperatureKelvin != nil {
+		return *x.TemperatureKelvin
+	}
+	return 0
+}
+
+type SetGroupStateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Failures for individual lights; empty when every light was updated.
+	Errors        []string `protobuf:"bytes,1,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetGroupStateResponse) Reset() {
+	*x = SetGroupStateResponse{}
+	mi := &file_keylightd_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetGroupStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetGroupStateResponse) ProtoMessage() {}
+
+func (x *SetGroupStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetGroupStateResponse.ProtoReflect.Descriptor instead.
+func (*SetGroupStateResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetGroupStateResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type APIKey struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Name      string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Key       string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Unset if the key never expires.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Unset if the key has never been used.
+	LastUsedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	Disabled      bool                   `protobuf:"varint,6,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_keylightd_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *APIKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *APIKey) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+type ListAPIKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_keylightd_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysRequest) ProtoMessage() {}
+
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{21}
+}
+
+type ListAPIKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*APIKey              `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_keylightd_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysResponse) ProtoMessage() {}
+
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListAPIKeysResponse) GetKeys() []*APIKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type CreateAPIKeyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Unset or zero for a key that never expires.
+	ExpiresIn     *durationpb.Duration `protobuf:"bytes,2,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_keylightd_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetExpiresIn() *durationpb.Duration {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return nil
+}
+
+type CreateAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *APIKey                `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_keylightd_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CreateAPIKeyResponse) GetKey() *APIKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+type DeleteAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAPIKeyRequest) Reset() {
+	*x = DeleteAPIKeyRequest{}
+	mi := &file_keylightd_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAPIKeyRequest) ProtoMessage() {}
+
+func (x *DeleteAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteAPIKeyRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DeleteAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAPIKeyResponse) Reset() {
+	*x = DeleteAPIKeyResponse{}
+	mi := &file_keylightd_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAPIKeyResponse) ProtoMessage() {}
+
+func (x *DeleteAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{26}
+}
+
+type SetAPIKeyDisabledRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The key itself or its name.
+	KeyOrName     string `protobuf:"bytes,1,opt,name=key_or_name,json=keyOrName,proto3" json:"key_or_name,omitempty"`
+	Disabled      bool   `protobuf:"varint,2,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAPIKeyDisabledRequest) Reset() {
+	*x = SetAPIKeyDisabledRequest{}
+	mi := &file_keylightd_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAPIKeyDisabledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAPIKeyDisabledRequest) ProtoMessage() {}
+
+func (x *SetAPIKeyDisabledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAPIKeyDisabledRequest.ProtoReflect.Descriptor instead.
+func (*SetAPIKeyDisabledRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SetAPIKeyDisabledRequest) GetKeyOrName() string {
+	if x != nil {
+		return x.KeyOrName
+	}
+	return ""
+}
+
+func (x *SetAPIKeyDisabledRequest) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+type SetAPIKeyDisabledResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *APIKey                `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAPIKeyDisabledResponse) Reset() {
+	*x = SetAPIKeyDisabledResponse{}
+	mi := &file_keylightd_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAPIKeyDisabledResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAPIKeyDisabledResponse) ProtoMessage() {}
+
+func (x *SetAPIKeyDisabledResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAPIKeyDisabledResponse.ProtoReflect.Descriptor instead.
+func (*SetAPIKeyDisabledResponse) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SetAPIKeyDisabledResponse) GetKey() *APIKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_keylightd_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{29}
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Event type, such as light.state_changed or group.created.
+	Type      string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// The event payload as JSON, as sent on the WebSocket endpoint.
+	DataJson      string `protobuf:"bytes,3,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_keylightd_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_keylightd_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_keylightd_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetDataJson() string {
+	if x != nil {
+		return x.DataJson
+	}
+	return ""
+}
+
+var File_keylightd_proto protoreflect.FileDescriptor
+
+const file_keylightd_proto_rawDesc = "" +
+	"\n" +
+	"\x0fkeylightd.proto\x12\fkeylightd.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xeb\x03\n" +
+	"\x05Light\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02ip\x18\x03 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04port\x18\x04 \x01(\x05R\x04port\x12\x16\n" +
+	"\x06driver\x18\x05 \x01(\tR\x06driver\x12\x16\n" +
+	"\x06static\x18\x06 \x01(\bR\x06static\x12\x0e\n" +
+	"\x02on\x18\a \x01(\bR\x02on\x12\x1e\n" +
+	"\n" +
+	"brightness\x18\b \x01(\x05R\n" +
+	"brightness\x12 \n" +
+	"\vtemperature\x18\t \x01(\x05R\vtemperature\x12-\n" +
+	"\x12temperature_kelvin\x18\n" +
+	" \x01(\x05R\x11temperatureKelvin\x12!\n" +
+	"\fproduct_name\x18\v \x01(\tR\vproductName\x12#\n" +
+	"\rserial_number\x18\f \x01(\tR\fserialNumber\x12)\n" +
+	"\x10firmware_version\x18\r \x01(\tR\x0ffirmwareVersion\x12%\n" +
+	"\x0efirmware_build\x18\x0e \x01(\x05R\rfirmwareBuild\x127\n" +
+	"\tlast_seen\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x16\n" +
+	"\x06status\x18\x10 \x01(\tR\x06status\"\x13\n" +
+	"\x11ListLightsRequest\"A\n" +
+	"\x12ListLightsResponse\x12+\n" +
+	"\x06lights\x18\x01 \x03(\v2\x13.keylightd.v1.LightR\x06lights\"!\n" +
+	"\x0fGetLightRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"=\n" +
+	"\x10GetLightResponse\x12)\n" +
+	"\x05light\x18\x01 \x01(\v2\x13.keylightd.v1.LightR\x05light\"\xc1\x01\n" +
+	"\x14SetLightStateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x13\n" +
+	"\x02on\x18\x02 \x01(\bH\x00R\x02on\x88\x01\x01\x12#\n" +
+	"\n" +
+	"brightness\x18\x03 \x01(\x05H\x01R\n" +
+	"brightness\x88\x01\x01\x122\n" +
+	"\x12temperature_kelvin\x18\x04 \x01(\x05H\x02R\x11temperatureKelvin\x88\x01\x01B\x05\n" +
+	"\x03_onB\r\n" +
+	"\v_brightnessB\x15\n" +
+	"\x13_temperature_kelvin\"B\n" +
+	"\x15SetLightStateResponse\x12)\n" +
+	"\x05light\x18\x01 \x01(\v2\x13.keylightd.v1.LightR\x05light\"H\n" +
+	"\x05Group\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tlight_ids\x18\x03 \x03(\tR\blightIds\"\x13\n" +
+	"\x11ListGroupsRequest\"A\n" +
+	"\x12ListGroupsResponse\x12+\n" +
+	"\x06groups\x18\x01 \x03(\v2\x13.keylightd.v1.GroupR\x06groups\"!\n" +
+	"\x0fGetGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"=\n" +
+	"\x10GetGroupResponse\x12)\n" +
+	"\x05group\x18\x01 \x01(\v2\x13.keylightd.v1.GroupR\x05group\"E\n" +
+	"\x12CreateGroupRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tlight_ids\x18\x02 \x03(\tR\blightIds\"@\n" +
+	"\x13CreateGroupResponse\x12)\n" +
+	"\x05group\x18\x01 \x01(\v2\x13.keylightd.v1.GroupR\x05group\"$\n" +
+	"\x12DeleteGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13DeleteGroupResponse\"D\n" +
+	"\x15SetGroupLightsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tlight_ids\x18\x02 \x03(\tR\blightIds\"C\n" +
+	"\x16SetGroupLightsResponse\x12)\n" +
+	"\x05group\x18\x01 \x01(\v2\x13.keylightd.v1.GroupR\x05group\"\xc1\x01\n" +
+	"\x14SetGroupStateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x13\n" +
+	"\x02on\x18\x02 \x01(\bH\x00R\x02on\x88\x01\x01\x12#\n" +
+	"\n" +
+	"brightness\x18\x03 \x01(\x05H\x01R\n" +
+	"brightness\x88\x01\x01\x122\n" +
+	"\x12temperature_kelvin\x18\x04 \x01(\x05H\x02R\x11temperatureKelvin\x88\x01\x01B\x05\n" +
+	"\x03_onB\r\n" +
+	"\v_brightnessB\x15\n" +
+	"\x13_temperature_kelvin\"/\n" +
+	"\x15SetGroupStateResponse\x12\x16\n" +
+	"\x06errors\x18\x01 \x03(\tR\x06errors\"\xfe\x01\n" +
+	"\x06APIKey\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12<\n" +
+	"\flast_used_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x12\x1a\n" +
+	"\bdisabled\x18\x06 \x01(\bR\bdisabled\"\x14\n" +
+	"\x12ListAPIKeysRequest\"?\n" +
+	"\x13ListAPIKeysResponse\x12(\n" +
+	"\x04keys\x18\x01 \x03(\v2\x14.keylightd.v1.APIKeyR\x04keys\"c\n" +
+	"\x13CreateAPIKeyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x128\n" +
+	"\n" +
+	"expires_in\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\texpiresIn\">\n" +
+	"\x14CreateAPIKeyResponse\x12&\n" +
+	"\x03key\x18\x01 \x01(\v2\x14.keylightd.v1.APIKeyR\x03key\"'\n" +
+	"\x13DeleteAPIKeyRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\x16\n" +
+	"\x14DeleteAPIKeyResponse\"V\n" +
+	"\x18SetAPIKeyDisabledRequest\x12\x1e\n" +
+	"\vkey_or_name\x18\x01 \x01(\tR\tkeyOrName\x12\x1a\n" +
+	"\bdisabled\x18\x02 \x01(\bR\bdisabled\"C\n" +
+	"\x19SetAPIKeyDisabledResponse\x12&\n" +
+	"\x03key\x18\x01 \x01(\v2\x14.keylightd.v1.APIKeyR\x03key\"\x15\n" +
+	"\x13StreamEventsRequest\"r\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1b\n" +
+	"\tdata_json\x18\x03 \x01(\tR\bdataJson2\x84\x02\n" +
+	"\fLightService\x12O\n" +
+	"\n" +
+	"ListLights\x12\x1f.keylightd.v1.ListLightsRequest\x1a .keylightd.v1.ListLightsResponse\x12I\n" +
+	"\bGetLight\x12\x1d.keylightd.v1.GetLightRequest\x1a\x1e.keylightd.v1.GetLightResponse\x12X\n" +
+	"\rSetLightState\x12\".keylightd.v1.SetLightStateRequest\x1a#.keylightd.v1.SetLightStateResponse2\x89\x04\n" +
+	"\fGroupService\x12O\n" +
+	"\n" +
+	"ListGroups\x12\x1f.keylightd.v1.ListGroupsRequest\x1a .keylightd.v1.ListGroupsResponse\x12I\n" +
+	"\bGetGroup\x12\x1d.keylightd.v1.GetGroupRequest\x1a\x1e.keylightd.v1.GetGroupResponse\x12R\n" +
+	"\vCreateGroup\x12 .keylightd.v1.CreateGroupRequest\x1a!.keylightd.v1.CreateGroupResponse\x12R\n" +
+	"\vDeleteGroup\x12 .keylightd.v1.DeleteGroupRequest\x1a!.keylightd.v1.DeleteGroupResponse\x12[\n" +
+	"\x0eSetGroupLights\x12#.keylightd.v1.SetGroupLightsRequest\x1a$.keylightd.v1.SetGroupLightsResponse\x12X\n" +
+	"\rSetGroupState\x12\".keylightd.v1.SetGroupStateRequest\x1a#.keylightd.v1.SetGroupStateResponse2\xf7\x02\n" +
+	"\rAPIKeyService\x12R\n" +
+	"\vListAPIKeys\x12 .keylightd.v1.ListAPIKeysRequest\x1a!.keylightd.v1.ListAPIKeysResponse\x12U\n" +
+	"\fCreateAPIKey\x12!.keylightd.v1.CreateAPIKeyRequest\x1a\".keylightd.v1.CreateAPIKeyResponse\x12U\n" +
+	"\fDeleteAPIKey\x12!.keylightd.v1.DeleteAPIKeyRequest\x1a\".keylightd.v1.DeleteAPIKeyResponse\x12d\n" +
+	"\x11SetAPIKeyDisabled\x12&.keylightd.v1.SetAPIKeyDisabledRequest\x1a'.keylightd.v1.SetAPIKeyDisabledResponse2X\n" +
+	"\fEventService\x12H\n" +
+	"\fStreamEvents\x12!.keylightd.v1.StreamEventsRequest\x1a\x13.keylightd.v1.Event0\x01BEZCgithub.com/jmylchreest/keylightd/pkg/proto/keylightd/v1;keylightdv1b\x06proto3"
+
+var (
+	file_keylightd_proto_rawDescOnce sync.Once
+	file_keylightd_proto_rawDescData []byte
+)
+
+func file_keylightd_proto_rawDescGZIP() []byte {
+	file_keylightd_proto_rawDescOnce.Do(func() {
+		file_keylightd_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_keylightd_proto_rawDesc), len(file_keylightd_proto_rawDesc)))
+	})
+	return file_keylightd_proto_rawDescData
+}
+
+var file_keylightd_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
+var file_keylightd_proto_goTypes = []any{
+	(*Light)(nil),                     // 0: keylightd.v1.Light
+	(*ListLightsRequest)(nil),         // 1: keylightd.v1.ListLightsRequest
+	(*ListLightsResponse)(nil),        // 2: keylightd.v1.ListLightsResponse
+	(*GetLightRequest)(nil),           // 3: keylightd.v1.GetLightRequest
+	(*GetLightResponse)(nil),          // 4: keylightd.v1.GetLightResponse
+	(*SetLightStateRequest)(nil),      // 5: keylightd.v1.SetLightStateRequest
+	(*SetLightStateResponse)(nil),     // 6: keylightd.v1.SetLightStateResponse
+	(*Group)(nil),                     // 7: keylightd.v1.Group
+	(*ListGroupsRequest)(nil),         // 8: keylightd.v1.ListGroupsRequest
+	(*ListGroupsResponse)(nil),        // 9: keylightd.v1.ListGroupsResponse
+	(*GetGroupRequest)(nil),           // 10: keylightd.v1.GetGroupRequest
+	(*GetGroupResponse)(nil),          // 11: keylightd.v1.GetGroupResponse
+	(*CreateGroupRequest)(nil),        // 12: keylightd.v1.CreateGroupRequest
+	(*CreateGroupResponse)(nil),       // 13: keylightd.v1.CreateGroupResponse
+	(*DeleteGroupRequest)(nil),        // 14: keylightd.v1.DeleteGroupRequest
+	(*DeleteGroupResponse)(nil),       // 15: keylightd.v1.DeleteGroupResponse
+	(*SetGroupLightsRequest)(nil),     // 16: keylightd.v1.SetGroupLightsRequest
+	(*SetGroupLightsResponse)(nil),    // 17: keylightd.v1.SetGroupLightsResponse
+	(*SetGroupStateRequest)(nil),      // 18: keylightd.v1.SetGroupStateRequest
+	(*SetGroupStateResponse)(nil),     // 19: keylightd.v1.SetGroupStateResponse
+	(*APIKey)(nil),                    // 20: keylightd.v1.APIKey
+	(*ListAPIKeysRequest)(nil),        // 21: keylightd.v1.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),       // 22: keylightd.v1.ListAPIKeysResponse
+	(*CreateAPIKeyRequest)(nil),       // 23: keylightd.v1.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),      // 24: keylightd.v1.CreateAPIKeyResponse
+	(*DeleteAPIKeyRequest)(nil),       // 25: keylightd.v1.DeleteAPIKeyRequest
+	(*DeleteAPIKeyResponse)(nil),      // 26: keylightd.v1.DeleteAPIKeyResponse
+	(*SetAPIKeyDisabledRequest)(nil),  // 27: keylightd.v1.SetAPIKeyDisabledRequest
+	(*SetAPIKeyDisabledResponse)(nil), // 28: keylightd.v1.SetAPIKeyDisabledResponse
+	(*StreamEventsRequest)(nil),       // 29: keylightd.v1.StreamEventsRequest
+	(*Event)(nil),                     // 30: keylightd.v1.Event
+	(*timestamppb.Timestamp)(nil),     // 31: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),       // 32: google.protobuf.Duration
+}
+var file_keylightd_proto_depIdxs = []int32{
+	31, // 0: keylightd.v1.Light.last_seen:type_name -> google.protobuf.Timestamp
+	0,  // 1: keylightd.v1.ListLightsResponse.lights:type_name -> keylightd.v1.Light
+	0,  // 2: keylightd.v1.GetLightResponse.light:type_name -> keylightd.v1.Light
+	0,  // 3: keylightd.v1.SetLightStateResponse.light:type_name -> keylightd.v1.Light
+	7,  // 4: keylightd.v1.ListGroupsResponse.groups:type_name -> keylightd.v1.Group
+	7,  // 5: keylightd.v1.GetGroupResponse.group:type_name -> keylightd.v1.Group
+	7,  // 6: keylightd.v1.CreateGroupResponse.group:type_name -> keylightd.v1.Group
+	7,  // 7: keylightd.v1.SetGroupLightsResponse.group:type_name -> keylightd.v1.Group
+	31, // 8: keylightd.v1.APIKey.created_at:type_name -> google.protobuf.Timestamp
+	31, // 9: keylightd.v1.APIKey.expires_at:type_name -> google.protobuf.Timestamp
+	31, // 10: keylightd.v1.APIKey.last_used_at:type_name -> google.protobuf.Timestamp
+	20, // 11: keylightd.v1.ListAPIKeysResponse.keys:type_name -> keylightd.v1.APIKey
+	32, // 12: keylightd.v1.CreateAPIKeyRequest.expires_in:type_name -> google.protobuf.Duration
+	20, // 13: keylightd.v1.CreateAPIKeyResponse.key:type_name -> keylightd.v1.APIKey
+	20, // 14: keylightd.v1.SetAPIKeyDisabledResponse.key:type_name -> keylightd.v1.APIKey
+	31, // 15: keylightd.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 16: keylightd.v1.LightService.ListLights:input_type -> keylightd.v1.ListLightsRequest
+	3,  // 17: keylightd.v1.LightService.GetLight:input_type -> keylightd.v1.GetLightRequest
+	5,  // 18: keylightd.v1.LightService.SetLightState:input_type -> keylightd.v1.SetLightStateRequest
+	8,  // 19: keylightd.v1.GroupService.ListGroups:input_type -> keylightd.v1.ListGroupsRequest
+	10, // 20: keylightd.v1.GroupService.GetGroup:input_type -> keylightd.v1.GetGroupRequest
+	12, // 21: keylightd.v1.GroupService.CreateGroup:input_type -> keylightd.v1.CreateGroupRequest
+	14, // 22: keylightd.v1.GroupService.DeleteGroup:input_type -> keylightd.v1.DeleteGroupRequest
+	16, // 23: keylightd.v1.GroupService.SetGroupLights:input_type -> keylightd.v1.SetGroupLightsRequest
+	18, // 24: keylightd.v1.GroupService.SetGroupState:input_type -> keylightd.v1.SetGroupStateRequest
+	21, // 25: keylightd.v1.APIKeyService.ListAPIKeys:input_type -> keylightd.v1.ListAPIKeysRequest
+	23, // 26: keylightd.v1.APIKeyService.CreateAPIKey:input_type -> keylightd.v1.CreateAPIKeyRequest
+	25, // 27: keylightd.v1.APIKeyService.DeleteAPIKey:input_type -> keylightd.v1.DeleteAPIKeyRequest
+	27, // 28: keylightd.v1.APIKeyService.SetAPIKeyDisabled:input_type -> keylightd.v1.SetAPIKeyDisabledRequest
+	29, // 29: keylightd.v1.EventService.StreamEvents:input_type -> keylightd.v1.StreamEventsRequest
+	2,  // 30: keylightd.v1.LightService.ListLights:output_type -> keylightd.v1.ListLightsResponse
+	4,  // 31: keylightd.v1.LightService.GetLight:output_type -> keylightd.v1.GetLightResponse
+	6,  // 32: keylightd.v1.LightService.SetLightState:output_type -> keylightd.v1.SetLightStateResponse
+	9,  // 33: keylightd.v1.GroupService.ListGroups:output_type -> keylightd.v1.ListGroupsResponse
+	11, // 34: keylightd.v1.GroupService.GetGroup:output_type -> keylightd.v1.GetGroupResponse
+	13, // 35: keylightd.v1.GroupService.CreateGroup:output_type -> keylightd.v1.CreateGroupResponse
+	15, // 36: keylightd.v1.GroupService.DeleteGroup:output_type -> keylightd.v1.DeleteGroupResponse
+	17, // 37: keylightd.v1.GroupService.SetGroupLights:output_type -> keylightd.v1.SetGroupLightsResponse
+	19, // 38: keylightd.v1.GroupService.SetGroupState:output_type -> keylightd.v1.SetGroupStateResponse
+	22, // 39: keylightd.v1.APIKeyService.ListAPIKeys:output_type -> keylightd.v1.ListAPIKeysResponse
+	24, // 40: keylightd.v1.APIKeyService.CreateAPIKey:output_type -> keylightd.v1.CreateAPIKeyResponse
+	26, // 41: keylightd.v1.APIKeyService.DeleteAPIKey:output_type -> keylightd.v1.DeleteAPIKeyResponse
+	28, // 42: keylightd.v1.APIKeyService.SetAPIKeyDisabled:output_type -> keylightd.v1.SetAPIKeyDisabledResponse
+	30, // 43: keylightd.v1.EventService.StreamEvents:output_type -> keylightd.v1.Event
+	30, // [30:44] is the sub-list for method output_type
+	16, // [16:30] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_keylightd_proto_init() }
+func file_keylightd_proto_init() {
+	if File_keylightd_proto != nil {
+		return
+	}
+	file_keylightd_proto_msgTypes[5].OneofWrappers = []any{}
+	file_keylightd_proto_msgTypes[18].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_keylightd_proto_rawDesc), len(file_keylightd_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   31,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_keylightd_proto_goTypes,
+		DependencyIndexes: file_keylightd_proto_depIdxs,
+		MessageInfos:      file_keylightd_proto_msgTypes,
+	}.Build()
+	File_keylightd_proto = out.File
+	file_keylightd_proto_goTypes = nil
+	file_keylightd_proto_depIdxs = nil
+}
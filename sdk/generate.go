@@ -0,0 +1,7 @@
+// Package sdk holds the TypeScript and Python clients for the keylightd HTTP
+// API. The clients are generated from the OpenAPI spec; regenerate them with
+// go generate after changing the API.
+package sdk
+
+//go:generate go run ../cmd/keylight-sdkgen -lang typescript -output typescript/src/client.ts
+//go:generate go run ../cmd/keylight-sdkgen -lang python -output python/keylightd_client/__init__.py
@@ -0,0 +1,975 @@
+# Code generated by keylight-sdkgen from the keylightd OpenAPI spec. DO NOT EDIT.
+
+"""Client for the keylightd HTTP API.
+
+Only the standard library is used. Errors returned by the daemon are raised
+as APIError, with the error code shared by all of keylightd's APIs.
+"""
+
+from __future__ import annotations
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Any, Dict, List, Literal, NotRequired, Optional, TypedDict
+
+class APIKeyResponse(TypedDict):
+    created_at: str
+    disabled: bool
+    expires_at: str
+    id: str
+    key: NotRequired[str]
+    last_used_at: NotRequired[str]
+    name: str
+    scopes: NotRequired[Optional[List[str]]]
+
+
+class Action(TypedDict):
+    brightness: NotRequired[int]
+    on: NotRequired[bool]
+    temperature: NotRequired[int]
+
+
+class AddFilterOutputBody(TypedDict):
+    filters: Optional[List[LogFilterResponse]]
+    level: str
+
+
+class BatchStatusResponse(TypedDict):
+    errors: NotRequired[Optional[List[str]]]
+    status: Literal["ok", "partial"]
+
+
+class Capabilities(TypedDict):
+    battery: bool
+    color: bool
+    range: Limits
+    temperature: bool
+
+
+class CircadianResponse(TypedDict):
+    enabled: bool
+    lights: Optional[List[str]]
+    mode: NotRequired[Literal["sun", "points"]]
+    sunrise: NotRequired[str]
+    sunset: NotRequired[str]
+    target: NotRequired[CircadianTarget]
+
+
+class CircadianTarget(TypedDict):
+    brightness: NotRequired[int]
+    temperature: int
+
+
+class CreateAPIKeyInputBody(TypedDict):
+    expires_in: NotRequired[str]
+    name: str
+
+
+class CreateGroupInputBody(TypedDict):
+    light_ids: NotRequired[Optional[List[str]]]
+    name: str
+
+
+class DaemonClients(TypedDict):
+    event_subscribers: int
+    socket: int
+    websocket: int
+
+
+class DaemonInfoResponse(TypedDict):
+    api_listen_address: NotRequired[str]
+    api_listen_addresses: NotRequired[Optional[List[str]]]
+    build_date: str
+    clients: DaemonClients
+    commit: str
+    discovery: NotRequired[DiscoveryStats]
+    discovery_interval_seconds: int
+    groups: int
+    lights: int
+    socket_path: str
+    started_at: str
+    uptime_seconds: int
+    version: str
+
+
+class Defaults(TypedDict):
+    apply_on_join: bool
+    brightness: NotRequired[int]
+    temperature: NotRequired[int]
+
+
+class DesiredStateResponse(TypedDict):
+    enabled: bool
+    interval: int
+    targets: Optional[List[DesiredStateTargetResponse]]
+
+
+class DesiredStateTargetResponse(TypedDict):
+    brightness: NotRequired[int]
+    corrections: int
+    enabled: bool
+    last_correction: NotRequired[str]
+    lights: Optional[List[str]]
+    on: NotRequired[bool]
+    target: str
+    temperature: NotRequired[int]
+
+
+class DiscoveryScanResponse(TypedDict):
+    duration_ms: int
+    found: Optional[List[LightResponse]]
+    lights: int
+
+
+class DiscoveryStats(TypedDict):
+    browse_attempts: int
+    completed: bool
+    idle: bool
+    last_duration_ms: int
+    last_error: NotRequired[str]
+    last_run: NotRequired[str]
+    runs: int
+
+
+class Entry(TypedDict):
+    brightness: NotRequired[int]
+    delay_ms: NotRequired[int]
+    on: NotRequired[bool]
+    order: NotRequired[int]
+    target: Target
+    temperature: NotRequired[int]
+    transition_ms: NotRequired[int]
+
+
+class ErrorDetail(TypedDict):
+    location: NotRequired[str]
+    message: NotRequired[str]
+    value: NotRequired[Any]
+
+
+class ErrorModel(TypedDict):
+    code: str
+    detail: NotRequired[str]
+    details: NotRequired[Dict[str, Any]]
+    errors: NotRequired[Optional[List[ErrorDetail]]]
+    instance: NotRequired[str]
+    status: NotRequired[int]
+    title: NotRequired[str]
+    type: NotRequired[str]
+
+
+class ExportDocument(TypedDict):
+    api_keys: NotRequired[Optional[List[ExportedAPIKey]]]
+    exported_at: NotRequired[str]
+    groups: NotRequired[Optional[List[ExportedGroup]]]
+    light_names: NotRequired[Dict[str, str]]
+    light_tags: NotRequired[Dict[str, Dict[str, str]]]
+    scenes: NotRequired[Optional[List[ExportedScene]]]
+    schedules: NotRequired[Optional[List[ExportedSchedule]]]
+    version: int
+
+
+class ExportedAPIKey(TypedDict):
+    created_at: NotRequired[str]
+    disabled: NotRequired[bool]
+    expires_at: NotRequired[str]
+    key: NotRequired[str]
+    name: str
+    scopes: NotRequired[Optional[List[str]]]
+
+
+class ExportedGroup(TypedDict):
+    color: NotRequired[str]
+    created_at: NotRequired[str]
+    defaults: NotRequired[Defaults]
+    description: NotRequired[str]
+    icon: NotRequired[str]
+    id: str
+    legacy_id: NotRequired[str]
+    lights: Optional[List[str]]
+    name: str
+    updated_at: NotRequired[str]
+
+
+class ExportedScene(TypedDict):
+    entries: Optional[List[Entry]]
+    id: str
+    name: str
+
+
+class ExportedSchedule(TypedDict):
+    action: NotRequired[Action]
+    at: NotRequired[str]
+    cron: NotRequired[str]
+    enabled: bool
+    id: str
+    name: str
+    scene: NotRequired[str]
+    skip_dates: NotRequired[Optional[List[str]]]
+    target: NotRequired[Target]
+    timezone: NotRequired[str]
+
+
+class GetLevelOutputBody(TypedDict):
+    level: str
+
+
+class GetLogsOutputBody(TypedDict):
+    entries: Optional[List[LogEntryResponse]]
+
+
+class GroupChangeResponse(TypedDict):
+    errors: NotRequired[Optional[List[str]]]
+    lights: Optional[List[GroupLightResult]]
+    status: Literal["ok", "partial"]
+
+
+class GroupDefaultsBody(TypedDict):
+    apply_on_join: NotRequired[bool]
+    brightness: NotRequired[int]
+    temperature: NotRequired[int]
+
+
+class GroupLightResult(TypedDict):
+    error: NotRequired[str]
+    group: str
+    light: str
+    status: Literal["ok", "failed", "skipped", "unsupported"]
+
+
+class GroupResponse(TypedDict):
+    color: NotRequired[str]
+    created_at: NotRequired[str]
+    defaults: NotRequired[GroupDefaultsBody]
+    description: NotRequired[str]
+    icon: NotRequired[str]
+    id: str
+    legacy_id: NotRequired[str]
+    lights: Optional[List[str]]
+    limits: NotRequired[Limits]
+    name: str
+    updated_at: NotRequired[str]
+
+
+class GroupStateResponse(TypedDict):
+    all_on: bool
+    any_on: bool
+    brightness: int
+    id: str
+    lights_on: int
+    lights_total: int
+    max_temperature: NotRequired[int]
+    max_temperature_kelvin: NotRequired[int]
+    min_temperature: NotRequired[int]
+    min_temperature_kelvin: NotRequired[int]
+    name: str
+    unreachable: Optional[List[str]]
+    version: int
+
+
+class GroupStatsResponse(TypedDict):
+    id: str
+    last_change: NotRequired[str]
+    last_toggle: NotRequired[str]
+    lights: Optional[List[LightStatsResponse]]
+    on_seconds: int
+    toggles: int
+
+
+class GroupToggleResponse(TypedDict):
+    errors: NotRequired[Optional[List[str]]]
+    groups: Dict[str, bool]
+    lights: Optional[List[GroupLightResult]]
+    status: Literal["ok", "partial"]
+
+
+class HealthOutputBody(TypedDict):
+    status: str
+
+
+class ImportResult(TypedDict):
+    api_keys: int
+    groups: int
+    light_names: int
+    light_tags: int
+    scenes: int
+    schedules: int
+    skipped: NotRequired[Optional[List[str]]]
+
+
+class JobResponse(TypedDict):
+    error: NotRequired[str]
+    finished_at: NotRequired[str]
+    id: str
+    kind: str
+    progress: NotRequired[Any]
+    started_at: str
+    status: Literal["running", "completed", "failed", "cancelled"]
+    subject: NotRequired[str]
+
+
+class LightResponse(TypedDict):
+    addresses: NotRequired[Optional[List[str]]]
+    brightness: int
+    capabilities: NotRequired[Capabilities]
+    colormode: NotRequired[Literal["temperature", "color"]]
+    driver: NotRequired[str]
+    firmwarebuild: int
+    firmwareversion: str
+    hardwareboardtype: int
+    hue: NotRequired[float]
+    id: str
+    ip: str
+    lastseen: str
+    limits: NotRequired[Limits]
+    name: str
+    on: bool
+    port: int
+    productname: str
+    saturation: NotRequired[float]
+    serialnumber: str
+    static: NotRequired[bool]
+    status: NotRequired[Literal["online", "degraded", "offline"]]
+    tags: NotRequired[Dict[str, str]]
+    temperature: int
+    temperature_kelvin: NotRequired[int]
+    version: int
+
+
+class LightSettings(TypedDict):
+    power_on_behavior: str
+    power_on_brightness: int
+    power_on_temperature: int
+
+
+class LightStateUpdate(TypedDict):
+    brightness: NotRequired[int]
+    hue: NotRequired[float]
+    id: str
+    on: NotRequired[bool]
+    saturation: NotRequired[float]
+    temperature: NotRequired[int]
+
+
+class LightStatsResponse(TypedDict):
+    id: str
+    last_change: NotRequired[str]
+    last_toggle: NotRequired[str]
+    on: bool
+    on_seconds: int
+    since: str
+    toggles: int
+
+
+class Limits(TypedDict):
+    max_brightness: int
+    max_temperature: int
+    min_brightness: int
+    min_temperature: int
+
+
+class ListFiltersOutputBody(TypedDict):
+    filters: Optional[List[LogFilterResponse]]
+    level: str
+
+
+class LogEntryResponse(TypedDict):
+    level: str
+    line: str
+    message: str
+    time: str
+
+
+class LogFilterResponse(TypedDict):
+    enabled: bool
+    expires_at: NotRequired[str]
+    level: str
+    output_level: NotRequired[str]
+    pattern: str
+    type: str
+
+
+class PendingDeviceResponse(TypedDict):
+    driver: NotRequired[str]
+    firmwareversion: str
+    firstseen: str
+    hardwareboardtype: int
+    id: str
+    ip: str
+    lastseen: str
+    name: str
+    port: int
+    productname: str
+    source: str
+
+
+class PendingDevicesOutputBody(TypedDict):
+    devices: Optional[List[PendingDeviceResponse]]
+
+
+class RawRequestInputBody(TypedDict):
+    body: NotRequired[Any]
+    method: Literal["GET", "PUT", "POST"]
+    path: str
+
+
+class RawResponse(TypedDict):
+    body: NotRequired[Any]
+    status_code: int
+
+
+class ReadyOutputBody(TypedDict):
+    reason: NotRequired[str]
+    status: str
+
+
+class SceneEntryRequest(TypedDict):
+    brightness: NotRequired[int]
+    delay_ms: NotRequired[int]
+    on: NotRequired[bool]
+    order: NotRequired[int]
+    target: SceneTargetBody
+    temperature: NotRequired[int]
+    transition_ms: NotRequired[int]
+
+
+class SceneRequest(TypedDict):
+    entries: Optional[List[SceneEntryRequest]]
+    name: str
+
+
+class SceneResponse(TypedDict):
+    entries: Optional[List[SceneEntryRequest]]
+    id: str
+    name: str
+
+
+class SceneTargetBody(TypedDict):
+    id: str
+    type: Literal["light", "group"]
+
+
+class ScheduleActionRequest(TypedDict):
+    brightness: NotRequired[int]
+    on: NotRequired[bool]
+    temperature: NotRequired[int]
+
+
+class ScheduleExecutionResponse(TypedDict):
+    action: NotRequired[ScheduleActionRequest]
+    at: str
+    name: str
+    scene: NotRequired[str]
+    schedule_id: str
+    target: NotRequired[ScheduleTargetBody]
+
+
+class ScheduleRequest(TypedDict):
+    action: NotRequired[ScheduleActionRequest]
+    at: NotRequired[str]
+    cron: NotRequired[str]
+    enabled: NotRequired[bool]
+    name: str
+    scene: NotRequired[str]
+    skip_dates: NotRequired[Optional[List[str]]]
+    target: NotRequired[ScheduleTargetBody]
+    timezone: NotRequired[str]
+
+
+class ScheduleResponse(TypedDict):
+    action: NotRequired[ScheduleActionRequest]
+    at: NotRequired[str]
+    cron: NotRequired[str]
+    enabled: bool
+    id: str
+    last_run: NotRequired[str]
+    name: str
+    next_run: NotRequired[str]
+    scene: NotRequired[str]
+    skip_dates: NotRequired[Optional[List[str]]]
+    target: NotRequired[ScheduleTargetBody]
+    timezone: NotRequired[str]
+
+
+class ScheduleTargetBody(TypedDict):
+    id: str
+    type: Literal["light", "group"]
+
+
+class SetAPIKeyDisabledInputBody(TypedDict):
+    disabled: bool
+
+
+class SetCircadianInputBody(TypedDict):
+    enabled: bool
+
+
+class SetDesiredStateInputBody(TypedDict):
+    enabled: bool
+
+
+class SetDesiredStateTargetInputBody(TypedDict):
+    enabled: bool
+
+
+class SetDeviceNameInputBody(TypedDict):
+    name: str
+
+
+class SetFiltersInputBody(TypedDict):
+    filters: Optional[List[LogFilterResponse]]
+
+
+class SetFiltersOutputBody(TypedDict):
+    filters: Optional[List[LogFilterResponse]]
+    level: str
+
+
+class SetGroupLightsInputBody(TypedDict):
+    light_ids: Optional[List[str]]
+
+
+class SetGroupStateInputBody(TypedDict):
+    brightness: NotRequired[int]
+    brightness_delta: NotRequired[int]
+    hue: NotRequired[float]
+    on: NotRequired[bool]
+    saturation: NotRequired[float]
+    temperature: NotRequired[int]
+    temperature_delta: NotRequired[int]
+    transition_ms: NotRequired[int]
+    units: NotRequired[Literal["kelvin", "mired"]]
+
+
+class SetLevelInputBody(TypedDict):
+    level: str
+
+
+class SetLevelOutputBody(TypedDict):
+    level: str
+
+
+class SetLightNameInputBody(TypedDict):
+    name: str
+
+
+class SetLightSettingsInputBody(TypedDict):
+    power_on_behavior: NotRequired[Literal["restore", "default"]]
+    power_on_brightness: NotRequired[int]
+    power_on_temperature: NotRequired[int]
+
+
+class SetLightStateInputBody(TypedDict):
+    brightness: NotRequired[int]
+    brightness_delta: NotRequired[int]
+    hue: NotRequired[float]
+    on: NotRequired[bool]
+    saturation: NotRequired[float]
+    temperature: NotRequired[int]
+    temperature_delta: NotRequired[int]
+    transition_ms: NotRequired[int]
+    units: NotRequired[Literal["kelvin", "mired"]]
+
+
+class SetLightTagsInputBody(TypedDict):
+    tags: Dict[str, str]
+
+
+class SetLightsStateInputBody(TypedDict):
+    lights: Optional[List[LightStateUpdate]]
+    units: NotRequired[Literal["kelvin", "mired"]]
+
+
+class SetWebcamOverrideInputBody(TypedDict):
+    override: Literal["auto", "on", "off"]
+
+
+class StatusResponse(TypedDict):
+    status: str
+
+
+class StreamDeckActionInputBody(TypedDict):
+    action: Literal["toggle", "on", "off", "brightness", "temperature"]
+    id: str
+    step: NotRequired[int]
+    target: Literal["light", "group"]
+    value: NotRequired[int]
+
+
+class StreamDeckItem(TypedDict):
+    brightness: int
+    id: str
+    name: str
+    on: bool
+    status: NotRequired[Literal["online", "degraded", "offline"]]
+    temperature: int
+
+
+class StreamDeckState(TypedDict):
+    groups: Optional[List[StreamDeckItem]]
+    lights: Optional[List[StreamDeckItem]]
+
+
+class Target(TypedDict):
+    id: str
+    type: str
+
+
+class ToggleResponse(TypedDict):
+    on: bool
+    status: str
+
+
+class UpdateGroupInputBody(TypedDict):
+    color: NotRequired[str]
+    description: NotRequired[str]
+    icon: NotRequired[str]
+    name: NotRequired[str]
+
+
+class VersionOutputBody(TypedDict):
+    build_date: str
+    commit: str
+    version: str
+
+
+class WebcamResponse(TypedDict):
+    active: bool
+    enabled: bool
+    groups: Optional[List[str]]
+    in_use: bool
+    override: Literal["auto", "on", "off"]
+    supported: bool
+
+
+
+class APIError(Exception):
+    """An error returned by the daemon."""
+
+    def __init__(self, status: int, code: str, message: str, details: Optional[Dict[str, Any]] = None):
+        super().__init__(message)
+        self.status = status
+        self.code = code
+        self.message = message
+        self.details = details
+
+
+class Client:
+    """A client for the keylightd HTTP API."""
+
+    def __init__(self, base_url: str, api_key: Optional[str] = None, timeout: float = 10.0):
+        self.base_url = base_url.rstrip("/")
+        self.api_key = api_key
+        self.timeout = timeout
+
+    def _request(self, method: str, path: str, query: Optional[Dict[str, Any]] = None, body: Any = None) -> Any:
+        url = self.base_url + path
+        params = {k: _query_value(v) for k, v in (query or {}).items() if v is not None}
+        if params:
+            url += "?" + urllib.parse.urlencode(params)
+        headers = {"Accept": "application/json"}
+        data = None
+        if body is not None:
+            headers["Content-Type"] = "application/json"
+            data = json.dumps(body).encode()
+        if self.api_key:
+            headers["X-API-Key"] = self.api_key
+        request = urllib.request.Request(url, data=data, headers=headers, method=method)
+        try:
+            with urllib.request.urlopen(request, timeout=self.timeout) as response:
+                raw = response.read()
+        except urllib.error.HTTPError as err:
+            raw = err.read()
+            try:
+                problem = json.loads(raw)
+            except ValueError:
+                problem = {}
+            if not isinstance(problem, dict):
+                problem = {}
+            message = problem.get("detail") or raw.decode(errors="replace") or f"HTTP error {err.code}"
+            raise APIError(err.code, problem.get("code", "internal"), message, problem.get("details")) from None
+        return json.loads(raw) if raw else None
+
+    def add_log_filter(self, body: LogFilterResponse) -> AddFilterOutputBody:
+        """Add a log filter"""
+        return self._request("POST", "/api/v1/logging/filters", None, body)
+
+    def adopt_device(self, id: str) -> LightResponse:
+        """Adopt a pending device"""
+        return self._request("POST", f"/api/v1/discovery/pending/{_quote(id)}/adopt", None, None)
+
+    def apply_scene(self, id: str) -> JobResponse:
+        """Apply a scene"""
+        return self._request("POST", f"/api/v1/scenes/{_quote(id)}/apply", None, None)
+
+    def cancel_job(self, id: str) -> JobResponse:
+        """Cancel a job"""
+        return self._request("DELETE", f"/api/v1/jobs/{_quote(id)}", None, None)
+
+    def cancel_scene_run(self, id: str) -> JobResponse:
+        """Stop applying a scene"""
+        return self._request("DELETE", f"/api/v1/scenes/{_quote(id)}/run", None, None)
+
+    def create_api_key(self, body: CreateAPIKeyInputBody) -> APIKeyResponse:
+        """Create an API key"""
+        return self._request("POST", "/api/v1/apikeys", None, body)
+
+    def create_group(self, body: CreateGroupInputBody) -> GroupResponse:
+        """Create a group"""
+        return self._request("POST", "/api/v1/groups", None, body)
+
+    def create_scene(self, body: SceneRequest) -> SceneResponse:
+        """Create a scene"""
+        return self._request("POST", "/api/v1/scenes", None, body)
+
+    def create_schedule(self, body: ScheduleRequest) -> ScheduleResponse:
+        """Create a schedule"""
+        return self._request("POST", "/api/v1/schedules", None, body)
+
+    def delete_api_key(self, key: str) -> None:
+        """Delete an API key"""
+        return self._request("DELETE", f"/api/v1/apikeys/{_quote(key)}", None, None)
+
+    def delete_group(self, id: str) -> None:
+        """Delete a group"""
+        return self._request("DELETE", f"/api/v1/groups/{_quote(id)}", None, None)
+
+    def delete_log_filter(self, *, type: Optional[str] = None, pattern: Optional[str] = None) -> None:
+        """Remove a log filter"""
+        return self._request("DELETE", "/api/v1/logging/filters", {"type": type, "pattern": pattern}, None)
+
+    def delete_scene(self, id: str) -> None:
+        """Delete a scene"""
+        return self._request("DELETE", f"/api/v1/scenes/{_quote(id)}", None, None)
+
+    def delete_schedule(self, id: str) -> None:
+        """Delete a schedule"""
+        return self._request("DELETE", f"/api/v1/schedules/{_quote(id)}", None, None)
+
+    def discover_lights(self) -> DiscoveryScanResponse:
+        """Discover lights now"""
+        return self._request("POST", "/api/v1/discovery/scan", None, None)
+
+    def export_config(self, *, include_secrets: Optional[bool] = None) -> ExportDocument:
+        """Export groups, schedules and API keys"""
+        return self._request("GET", "/api/v1/config/export", {"include_secrets": include_secrets}, None)
+
+    def get_circadian(self) -> CircadianResponse:
+        """Get circadian mode"""
+        return self._request("GET", "/api/v1/circadian", None, None)
+
+    def get_daemon_info(self) -> DaemonInfoResponse:
+        """Daemon info"""
+        return self._request("GET", "/api/v1/info", None, None)
+
+    def get_desired_state(self) -> DesiredStateResponse:
+        """Get desired-state mode"""
+        return self._request("GET", "/api/v1/desired-state", None, None)
+
+    def get_group(self, id: str) -> GroupResponse:
+        """Get a group"""
+        return self._request("GET", f"/api/v1/groups/{_quote(id)}", None, None)
+
+    def get_group_state(self, id: str) -> GroupStateResponse:
+        """Get group state"""
+        return self._request("GET", f"/api/v1/groups/{_quote(id)}/state", None, None)
+
+    def get_group_stats(self, id: str) -> GroupStatsResponse:
+        """Get group usage statistics"""
+        return self._request("GET", f"/api/v1/groups/{_quote(id)}/stats", None, None)
+
+    def get_job(self, id: str) -> JobResponse:
+        """Get a job"""
+        return self._request("GET", f"/api/v1/jobs/{_quote(id)}", None, None)
+
+    def get_light(self, id: str) -> LightResponse:
+        """Get a light"""
+        return self._request("GET", f"/api/v1/lights/{_quote(id)}", None, None)
+
+    def get_light_settings(self, id: str) -> LightSettings:
+        """Get a light's power-on settings"""
+        return self._request("GET", f"/api/v1/lights/{_quote(id)}/settings", None, None)
+
+    def get_light_stats(self, id: str) -> LightStatsResponse:
+        """Get light usage statistics"""
+        return self._request("GET", f"/api/v1/lights/{_quote(id)}/stats", None, None)
+
+    def get_log_level(self) -> GetLevelOutputBody:
+        """Get global log level"""
+        return self._request("GET", "/api/v1/logging/level", None, None)
+
+    def get_logs(self, *, level: Optional[str] = None, since: Optional[str] = None, limit: Optional[int] = None) -> GetLogsOutputBody:
+        """Get recent log lines"""
+        return self._request("GET", "/api/v1/logs", {"level": level, "since": since, "limit": limit}, None)
+
+    def get_scene(self, id: str) -> SceneResponse:
+        """Get a scene"""
+        return self._request("GET", f"/api/v1/scenes/{_quote(id)}", None, None)
+
+    def get_scene_run(self, id: str) -> JobResponse:
+        """Get the job of a scene's latest run"""
+        return self._request("GET", f"/api/v1/scenes/{_quote(id)}/run", None, None)
+
+    def get_schedule(self, id: str) -> ScheduleResponse:
+        """Get a schedule"""
+        return self._request("GET", f"/api/v1/schedules/{_quote(id)}", None, None)
+
+    def get_stream_deck_state(self) -> StreamDeckState:
+        """Get a compact state snapshot"""
+        return self._request("GET", "/api/v1/streamdeck/state", None, None)
+
+    def get_version(self) -> VersionOutputBody:
+        """Daemon version"""
+        return self._request("GET", "/api/v1/version", None, None)
+
+    def get_webcam(self) -> WebcamResponse:
+        """Get webcam automation"""
+        return self._request("GET", "/api/v1/webcam", None, None)
+
+    def health_check(self) -> HealthOutputBody:
+        """Health check"""
+        return self._request("GET", "/api/v1/health", None, None)
+
+    def import_config(self, body: ExportDocument) -> ImportResult:
+        """Import an exported document"""
+        return self._request("POST", "/api/v1/config/import", None, body)
+
+    def list_api_keys(self) -> Optional[List[APIKeyResponse]]:
+        """List API keys"""
+        return self._request("GET", "/api/v1/apikeys", None, None)
+
+    def list_groups(self) -> Optional[List[GroupResponse]]:
+        """List all groups"""
+        return self._request("GET", "/api/v1/groups", None, None)
+
+    def list_jobs(self) -> Optional[List[JobResponse]]:
+        """List jobs"""
+        return self._request("GET", "/api/v1/jobs", None, None)
+
+    def list_lights(self) -> Dict[str, LightResponse]:
+        """List all lights"""
+        return self._request("GET", "/api/v1/lights", None, None)
+
+    def list_log_filters(self) -> ListFiltersOutputBody:
+        """List log filters and current level"""
+        return self._request("GET", "/api/v1/logging/filters", None, None)
+
+    def list_pending_devices(self) -> PendingDevicesOutputBody:
+        """List pending devices"""
+        return self._request("GET", "/api/v1/discovery/pending", None, None)
+
+    def list_scenes(self) -> Optional[List[SceneResponse]]:
+        """List all scenes"""
+        return self._request("GET", "/api/v1/scenes", None, None)
+
+    def list_schedules(self) -> Optional[List[ScheduleResponse]]:
+        """List all schedules"""
+        return self._request("GET", "/api/v1/schedules", None, None)
+
+    def list_upcoming_schedules(self, *, limit: Optional[int] = None, days: Optional[int] = None) -> Optional[List[ScheduleExecutionResponse]]:
+        """List upcoming schedule runs"""
+        return self._request("GET", "/api/v1/schedules/upcoming", {"limit": limit, "days": days}, None)
+
+    def raw_light_request(self, id: str, body: RawRequestInputBody) -> RawResponse:
+        """Send a raw request to a light"""
+        return self._request("POST", f"/api/v1/lights/{_quote(id)}/raw", None, body)
+
+    def set_api_key_disabled(self, key: str, body: SetAPIKeyDisabledInputBody) -> APIKeyResponse:
+        """Enable or disable an API key"""
+        return self._request("PUT", f"/api/v1/apikeys/{_quote(key)}/disabled", None, body)
+
+    def set_circadian(self, body: SetCircadianInputBody) -> CircadianResponse:
+        """Enable or disable circadian mode"""
+        return self._request("PUT", "/api/v1/circadian", None, body)
+
+    def set_desired_state(self, body: SetDesiredStateInputBody) -> DesiredStateResponse:
+        """Enable or disable desired-state mode"""
+        return self._request("PUT", "/api/v1/desired-state", None, body)
+
+    def set_desired_state_target(self, target: str, body: SetDesiredStateTargetInputBody) -> DesiredStateResponse:
+        """Enable or disable a desired-state target"""
+        return self._request("PUT", f"/api/v1/desired-state/targets/{_quote(target)}", None, body)
+
+    def set_device_name(self, id: str, body: SetDeviceNameInputBody) -> LightResponse:
+        """Rename the physical light"""
+        return self._request("PUT", f"/api/v1/lights/{_quote(id)}/device-name", None, body)
+
+    def set_group_defaults(self, id: str, body: GroupDefaultsBody) -> StatusResponse:
+        """Set group defaults"""
+        return self._request("PUT", f"/api/v1/groups/{_quote(id)}/defaults", None, body)
+
+    def set_group_lights(self, id: str, body: SetGroupLightsInputBody) -> StatusResponse:
+        """Set group lights"""
+        return self._request("PUT", f"/api/v1/groups/{_quote(id)}/lights", None, body)
+
+    def set_group_state(self, id: str, body: SetGroupStateInputBody) -> GroupChangeResponse:
+        """Set group state"""
+        return self._request("PUT", f"/api/v1/groups/{_quote(id)}/state", None, body)
+
+    def set_light_name(self, id: str, body: SetLightNameInputBody) -> LightResponse:
+        """Set a light's display name"""
+        return self._request("PUT", f"/api/v1/lights/{_quote(id)}/name", None, body)
+
+    def set_light_settings(self, id: str, body: SetLightSettingsInputBody) -> LightSettings:
+        """Change a light's power-on settings"""
+        return self._request("PUT", f"/api/v1/lights/{_quote(id)}/settings", None, body)
+
+    def set_light_state(self, id: str, body: SetLightStateInputBody) -> StatusResponse:
+        """Set light state"""
+        return self._request("POST", f"/api/v1/lights/{_quote(id)}/state", None, body)
+
+    def set_light_tags(self, id: str, body: SetLightTagsInputBody) -> LightResponse:
+        """Set a light's tags"""
+        return self._request("PUT", f"/api/v1/lights/{_quote(id)}/tags", None, body)
+
+    def set_lights_state(self, body: SetLightsStateInputBody) -> BatchStatusResponse:
+        """Set multiple lights' state"""
+        return self._request("POST", "/api/v1/lights/state", None, body)
+
+    def set_log_filters(self, body: SetFiltersInputBody) -> SetFiltersOutputBody:
+        """Replace all log filters"""
+        return self._request("PUT", "/api/v1/logging/filters", None, body)
+
+    def set_log_level(self, body: SetLevelInputBody) -> SetLevelOutputBody:
+        """Set global log level"""
+        return self._request("PUT", "/api/v1/logging/level", None, body)
+
+    def set_webcam_override(self, body: SetWebcamOverrideInputBody) -> WebcamResponse:
+        """Override webcam automation"""
+        return self._request("PUT", "/api/v1/webcam/override", None, body)
+
+    def stream_deck_action(self, body: StreamDeckActionInputBody) -> StreamDeckItem:
+        """Apply a button or dial action"""
+        return self._request("POST", "/api/v1/streamdeck/action", None, body)
+
+    def toggle_group(self, id: str) -> GroupToggleResponse:
+        """Toggle a group"""
+        return self._request("POST", f"/api/v1/groups/{_quote(id)}/toggle", None, None)
+
+    def toggle_light(self, id: str) -> ToggleResponse:
+        """Toggle a light"""
+        return self._request("POST", f"/api/v1/lights/{_quote(id)}/toggle", None, None)
+
+    def update_group(self, id: str, body: UpdateGroupInputBody) -> GroupResponse:
+        """Update a group"""
+        return self._request("PATCH", f"/api/v1/groups/{_quote(id)}", None, body)
+
+    def update_scene(self, id: str, body: SceneRequest) -> SceneResponse:
+        """Replace a scene"""
+        return self._request("PUT", f"/api/v1/scenes/{_quote(id)}", None, body)
+
+    def update_schedule(self, id: str, body: ScheduleRequest) -> ScheduleResponse:
+        """Replace a schedule"""
+        return self._request("PUT", f"/api/v1/schedules/{_quote(id)}", None, body)
+
+
+def _quote(value: str) -> str:
+    return urllib.parse.quote(value, safe="")
+
+
+def _query_value(value: Any) -> str:
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    return str(value)
@@ -0,0 +1,1427 @@
+// Code generated by keylight-sdkgen from the keylightd OpenAPI spec. DO NOT EDIT.
+
+export interface APIKeyResponse {
+  /** When the key was created */
+  created_at: string;
+  /** Whether the key is disabled */
+  disabled: boolean;
+  /** When the key expires */
+  expires_at: string;
+  /** Key identifier */
+  id: string;
+  /** Full key string (only present on creation) */
+  key?: string;
+  /** When the key was last used */
+  last_used_at?: string;
+  /** Display name of the key */
+  name: string;
+  /** Permission scopes; absent if the key has every scope */
+  scopes?: Array<string> | null;
+}
+
+export interface Action {
+  brightness?: number;
+  on?: boolean;
+  temperature?: number;
+}
+
+export interface AddFilterOutputBody {
+  /** Active log filters */
+  filters: Array<LogFilterResponse> | null;
+  /** Current global log level */
+  level: string;
+}
+
+export interface BatchStatusResponse {
+  /** List of errors for failed operations */
+  errors?: Array<string> | null;
+  /** Operation status */
+  status: "ok" | "partial";
+}
+
+export interface Capabilities {
+  battery: boolean;
+  color: boolean;
+  range: Limits;
+  temperature: boolean;
+}
+
+export interface CircadianResponse {
+  /** Whether circadian mode is adjusting lights */
+  enabled: boolean;
+  /** IDs of the lights circadian mode applies to */
+  lights: Array<string> | null;
+  /** Whether the curve follows the sun or explicit curve points; absent if neither is configured */
+  mode?: "sun" | "points";
+  /** Today's sunrise, when following the sun */
+  sunrise?: string;
+  /** Today's sunset, when following the sun */
+  sunset?: string;
+  /** State lights are currently set to */
+  target?: CircadianTarget;
+}
+
+export interface CircadianTarget {
+  /** Brightness level (3-100); absent if brightness is left alone */
+  brightness?: number;
+  /** Color temperature in Kelvin */
+  temperature: number;
+}
+
+export interface CreateAPIKeyInputBody {
+  /** Duration string (e.g., '720h', '30d') */
+  expires_in?: string;
+  /** Display name for the API key */
+  name: string;
+}
+
+export interface CreateGroupInputBody {
+  /** Optional list of light IDs to include */
+  light_ids?: Array<string> | null;
+  /** Display name for the group */
+  name: string;
+}
+
+export interface DaemonClients {
+  /** Unix socket connections streaming events */
+  event_subscribers: number;
+  /** Open Unix socket connections, including event subscribers */
+  socket: number;
+  /** Connected WebSocket clients */
+  websocket: number;
+}
+
+export interface DaemonInfoResponse {
+  /** TCP address the HTTP API listens on, if enabled */
+  api_listen_address?: string;
+  /** Every address the HTTP API listens on, including unix:// sockets, when there is more than one */
+  api_listen_addresses?: Array<string> | null;
+  /** Build timestamp (ISO 8601 UTC) */
+  build_date: string;
+  /** Connected API clients */
+  clients: DaemonClients;
+  /** Git commit SHA */
+  commit: string;
+  /** Light discovery statistics */
+  discovery?: DiscoveryStats;
+  /** Seconds between discovery passes */
+  discovery_interval_seconds: number;
+  /** Number of groups */
+  groups: number;
+  /** Number of known lights */
+  lights: number;
+  /** Path of the Unix socket */
+  socket_path: string;
+  /** When the daemon started */
+  started_at: string;
+  /** Seconds since the daemon started */
+  uptime_seconds: number;
+  /** Semantic version string */
+  version: string;
+}
+
+export interface Defaults {
+  apply_on_join: boolean;
+  brightness?: number;
+  temperature?: number;
+}
+
+export interface DesiredStateResponse {
+  /** Whether desired-state mode is putting lights back */
+  enabled: boolean;
+  /** Seconds between checks of the targeted lights */
+  interval: number;
+  /** Configured desired-state targets */
+  targets: Array<DesiredStateTargetResponse> | null;
+}
+
+export interface DesiredStateTargetResponse {
+  /** Desired brightness (3-100); absent if brightness is left alone */
+  brightness?: number;
+  /** Times a light was put back since the daemon started */
+  corrections: number;
+  /** Whether the target is enforced */
+  enabled: boolean;
+  /** When a light was last put back */
+  last_correction?: string;
+  /** IDs of the lights the target currently applies to */
+  lights: Array<string> | null;
+  /** Desired power state; absent if power is left alone */
+  on?: boolean;
+  /** Target key, light:<id> or group:<id or name> */
+  target: string;
+  /** Desired color temperature in Kelvin; absent if temperature is left alone */
+  temperature?: number;
+}
+
+export interface DiscoveryScanResponse {
+  /** How long the scan took, in milliseconds */
+  duration_ms: number;
+  /** Lights that were not known before the scan */
+  found: Array<LightResponse> | null;
+  /** Number of lights known after the scan */
+  lights: number;
+}
+
+export interface DiscoveryStats {
+  browse_attempts: number;
+  completed: boolean;
+  idle: boolean;
+  last_duration_ms: number;
+  last_error?: string;
+  last_run?: string;
+  runs: number;
+}
+
+export interface Entry {
+  brightness?: number;
+  delay_ms?: number;
+  on?: boolean;
+  order?: number;
+  target: Target;
+  temperature?: number;
+  transition_ms?: number;
+}
+
+export interface ErrorDetail {
+  /** Where the error occurred, e.g. 'body.items[3].tags' or 'path.thing-id' */
+  location?: string;
+  /** Error message text */
+  message?: string;
+  /** The value at the given location */
+  value?: unknown;
+}
+
+export interface ErrorModel {
+  /** Machine-readable error code, shared with the socket API */
+  code: string;
+  /** A human-readable explanation specific to this occurrence of the problem. */
+  detail?: string;
+  /** Additional information about the error */
+  details?: Record<string, unknown>;
+  /** Optional list of individual error details */
+  errors?: Array<ErrorDetail> | null;
+  /** A URI reference that identifies the specific occurrence of the problem. */
+  instance?: string;
+  /** HTTP status code */
+  status?: number;
+  /** A short, human-readable summary of the problem type. This value should not change between occurrences of the error. */
+  title?: string;
+  /** A URI reference to human-readable documentation for the error. */
+  type?: string;
+}
+
+export interface ExportDocument {
+  /** API keys; secrets are only included when requested */
+  api_keys?: Array<ExportedAPIKey> | null;
+  /** When the document was exported */
+  exported_at?: string;
+  /** Light groups */
+  groups?: Array<ExportedGroup> | null;
+  /** Display names set for lights, keyed by light ID */
+  light_names?: Record<string, string>;
+  /** Tags set on lights, keyed by light ID */
+  light_tags?: Record<string, Record<string, string>>;
+  /** Scenes */
+  scenes?: Array<ExportedScene> | null;
+  /** Schedules */
+  schedules?: Array<ExportedSchedule> | null;
+  /** Document format version */
+  version: number;
+}
+
+export interface ExportedAPIKey {
+  /** When the key was created */
+  created_at?: string;
+  /** Whether the key is disabled */
+  disabled?: boolean;
+  /** When the key expires; absent if it never does */
+  expires_at?: string;
+  /** The key secret, if included */
+  key?: string;
+  /** Key name */
+  name: string;
+  /** Permission scopes; absent if the key has every scope */
+  scopes?: Array<string> | null;
+}
+
+export interface ExportedGroup {
+  /** Color shown for the group in UIs, as #rrggbb */
+  color?: string;
+  /** When the group was created */
+  created_at?: string;
+  /** Default state of the group's lights */
+  defaults?: Defaults;
+  /** Group description */
+  description?: string;
+  /** Icon name shown for the group in UIs */
+  icon?: string;
+  /** Group identifier */
+  id: string;
+  /** ID the group had before it was migrated to a UUID, still accepted in place of its ID */
+  legacy_id?: string;
+  /** Member light IDs */
+  lights: Array<string> | null;
+  /** Group name */
+  name: string;
+  /** When the group's name, metadata, lights or defaults last changed */
+  updated_at?: string;
+}
+
+export interface ExportedScene {
+  /** States the scene applies, with their order and transitions */
+  entries: Array<Entry> | null;
+  /** Scene identifier */
+  id: string;
+  /** Scene name */
+  name: string;
+}
+
+export interface ExportedSchedule {
+  /** State applied when the schedule fires */
+  action?: Action;
+  /** Daily time of day in HH:MM */
+  at?: string;
+  /** Five-field cron expression */
+  cron?: string;
+  /** Whether the schedule is active */
+  enabled: boolean;
+  /** Schedule identifier */
+  id: string;
+  /** Schedule name */
+  name: string;
+  /** ID or name of the scene applied when the schedule fires */
+  scene?: string;
+  /** Dates (YYYY-MM-DD) the schedule doesn't fire on */
+  skip_dates?: Array<string> | null;
+  /** Light or group the schedule acts upon, or the scene is limited to */
+  target?: Target;
+  /** IANA time zone at and cron are in */
+  timezone?: string;
+}
+
+export interface GetLevelOutputBody {
+  /** Current global log level */
+  level: string;
+}
+
+export interface GetLogsOutputBody {
+  /** Log lines, oldest first */
+  entries: Array<LogEntryResponse> | null;
+}
+
+export interface GroupChangeResponse {
+  /** List of errors for failed groups */
+  errors?: Array<string> | null;
+  /** What the change did to each light of each group */
+  lights: Array<GroupLightResult> | null;
+  /** Operation status */
+  status: "ok" | "partial";
+}
+
+export interface GroupDefaultsBody {
+  /** Apply the defaults to member lights as soon as they are discovered */
+  apply_on_join?: boolean;
+  /** Default brightness (3-100) */
+  brightness?: number;
+  /** Default color temperature in Kelvin */
+  temperature?: number;
+}
+
+export interface GroupLightResult {
+  /** Why the light could not be changed */
+  error?: string;
+  /** Group identifier */
+  group: string;
+  /** Light identifier */
+  light: string;
+  /** ok, failed, skipped (not attempted, as another light failed under the fail-fast policy) or unsupported (the light can't be set to the requested state and was left unchanged) */
+  status: "ok" | "failed" | "skipped" | "unsupported";
+}
+
+export interface GroupResponse {
+  /** Color shown for the group in UIs, as #rrggbb */
+  color?: string;
+  /** When the group was created; unset for groups created before it was recorded */
+  created_at?: string;
+  /** Default state of the group's lights */
+  defaults?: GroupDefaultsBody;
+  /** Group description */
+  description?: string;
+  /** Icon name shown for the group in UIs */
+  icon?: string;
+  /** Unique group identifier (UUID) */
+  id: string;
+  /** ID the group had before it was migrated to a UUID, still accepted in place of its ID */
+  legacy_id?: string;
+  /** List of light IDs in this group */
+  lights: Array<string> | null;
+  /** Brightness and temperature (Kelvin) range every light in the group can be set to */
+  limits?: Limits;
+  /** Display name of the group */
+  name: string;
+  /** When the group's name, metadata, lights or defaults last changed */
+  updated_at?: string;
+}
+
+export interface GroupStateResponse {
+  /** Whether every reachable light is on; false if none is reachable */
+  all_on: boolean;
+  /** Whether any reachable light is on */
+  any_on: boolean;
+  /** Average brightness of the reachable lights (0-100) */
+  brightness: number;
+  /** Unique group identifier (UUID) */
+  id: string;
+  /** Number of reachable lights that are on */
+  lights_on: number;
+  /** Number of lights in the group */
+  lights_total: number;
+  /** Highest color temperature of the reachable lights, in Kelvin; absent if none has one */
+  max_temperature?: number;
+  /** Same as max_temperature, named for its unit */
+  max_temperature_kelvin?: number;
+  /** Lowest color temperature of the reachable lights, in Kelvin; absent if none has one */
+  min_temperature?: number;
+  /** Same as min_temperature, named for its unit */
+  min_temperature_kelvin?: number;
+  /** Display name of the group */
+  name: string;
+  /** IDs of lights that are offline or haven't been discovered */
+  unreachable: Array<string> | null;
+  /** State version: the latest state version of any of the group's lights; send it in If-Match to only change the group if it hasn't changed since */
+  version: number;
+}
+
+export interface GroupStatsResponse {
+  /** Group ID */
+  id: string;
+  /** Latest state change of any of the group's lights */
+  last_change?: string;
+  /** Latest toggle of any of the group's lights */
+  last_toggle?: string;
+  /** Statistics of each of the group's lights that has any */
+  lights: Array<LightStatsResponse> | null;
+  /** Sum of the on-time of the group's lights, in seconds */
+  on_seconds: number;
+  /** Sum of the toggles of the group's lights */
+  toggles: number;
+}
+
+export interface GroupToggleResponse {
+  /** List of errors for failed groups */
+  errors?: Array<string> | null;
+  /** Power state after the toggle, keyed by group ID */
+  groups: Record<string, boolean>;
+  /** What the toggle did to each light of each group */
+  lights: Array<GroupLightResult> | null;
+  /** Operation status */
+  status: "ok" | "partial";
+}
+
+export interface HealthOutputBody {
+  /** Service health status */
+  status: string;
+}
+
+export interface ImportResult {
+  /** API keys added */
+  api_keys: number;
+  /** Groups created or replaced */
+  groups: number;
+  /** Light names set */
+  light_names: number;
+  /** Lights whose tags were set */
+  light_tags: number;
+  /** Scenes created or replaced */
+  scenes: number;
+  /** Schedules created or replaced */
+  schedules: number;
+  /** Entries that were not imported, and why */
+  skipped?: Array<string> | null;
+}
+
+export interface JobResponse {
+  /** Why the job failed */
+  error?: string;
+  /** When the job finished */
+  finished_at?: string;
+  /** Unique job identifier */
+  id: string;
+  /** What the job does, e.g. scene.apply */
+  kind: string;
+  /** Progress specific to the kind of job. For scene.apply jobs: step, steps, applied, entries and errors. */
+  progress?: unknown;
+  /** When the job started */
+  started_at: string;
+  /** Where the job has got to */
+  status: "running" | "completed" | "failed" | "cancelled";
+  /** What the job acts upon, e.g. a scene ID */
+  subject?: string;
+}
+
+export interface LightResponse {
+  /** Every address the light was found at, IPv4 first, when it has more than one; requests fall back to the others when ip can't be reached */
+  addresses?: Array<string> | null;
+  /** Brightness level (0-100) */
+  brightness: number;
+  /** Optional properties the light supports, once its accessory info is known; clients can hide controls that don't apply. range is the brightness and temperature (Kelvin) range of the light's model, outside which requests are rejected */
+  capabilities?: Capabilities;
+  /** Whether a color light is showing white at a color temperature or a hue/saturation color */
+  colormode?: "temperature" | "color";
+  /** Device driver used to control the light (elgato, wled) */
+  driver?: string;
+  /** Firmware build number */
+  firmwarebuild: number;
+  /** Firmware version string */
+  firmwareversion: string;
+  /** Hardware board type identifier */
+  hardwareboardtype: number;
+  /** Hue in degrees (0-360), set while a color light is showing a color */
+  hue?: number;
+  /** Unique light identifier */
+  id: string;
+  /** IP address of the light */
+  ip: string;
+  /** Last time the light was seen on the network */
+  lastseen: string;
+  /** Brightness and temperature (Kelvin) range the light can be set to, within its model's range; values outside it are clamped */
+  limits?: Limits;
+  /** Display name of the light */
+  name: string;
+  /** Whether the light is currently on */
+  on: boolean;
+  /** Port number of the light */
+  port: number;
+  /** Product name */
+  productname: string;
+  /** Saturation percentage (0-100), set while a color light is showing a color */
+  saturation?: number;
+  /** Serial number */
+  serialnumber: string;
+  /** Whether the light is declared in the config rather than discovered */
+  static?: boolean;
+  /** Whether the light is responding: online, degraded after a failed request, or offline when not seen for a while */
+  status?: "online" | "degraded" | "offline";
+  /** Tags assigned to the light, which tag selectors such as tag:side=left match */
+  tags?: Record<string, string>;
+  /** Color temperature in mireds, as reported by the device */
+  temperature: number;
+  /** Color temperature in Kelvin; absent until the light's temperature is known */
+  temperature_kelvin?: number;
+  /** State version, which changes whenever the light's state does; send it in If-Match to only change the light if it hasn't changed since */
+  version: number;
+}
+
+export interface LightSettings {
+  power_on_behavior: string;
+  power_on_brightness: number;
+  power_on_temperature: number;
+}
+
+export interface LightStateUpdate {
+  /** Brightness level (0-100) */
+  brightness?: number;
+  /** Hue in degrees, for lights that support color */
+  hue?: number;
+  /** Light identifier */
+  id: string;
+  /** Power state */
+  on?: boolean;
+  /** Saturation percentage, for lights that support color */
+  saturation?: number;
+  /** Color temperature, in Kelvin unless the request's units is mired */
+  temperature?: number;
+}
+
+export interface LightStatsResponse {
+  /** Light ID */
+  id: string;
+  /** When the light's state last changed; absent if it hasn't since tracking began */
+  last_change?: string;
+  /** When the light was last turned on or off; absent if it hasn't been */
+  last_toggle?: string;
+  /** Whether the light is on now, as far as the daemon knows */
+  on: boolean;
+  /** Cumulative time the light has been on, in seconds */
+  on_seconds: number;
+  /** When tracking of the light began */
+  since: string;
+  /** Number of times the light was turned on or off */
+  toggles: number;
+}
+
+export interface Limits {
+  max_brightness: number;
+  max_temperature: number;
+  min_brightness: number;
+  min_temperature: number;
+}
+
+export interface ListFiltersOutputBody {
+  /** Active log filters */
+  filters: Array<LogFilterResponse> | null;
+  /** Current global log level */
+  level: string;
+}
+
+export interface LogEntryResponse {
+  /** Log level (debug, info, warn, error) */
+  level: string;
+  /** The line as written to the daemon's log, in its text or JSON format */
+  line: string;
+  /** Log message */
+  message: string;
+  /** When the line was logged */
+  time: string;
+}
+
+export interface LogFilterResponse {
+  /** Whether the filter is active */
+  enabled: boolean;
+  /** Optional expiration time (nil = never) */
+  expires_at?: string;
+  /** Minimum log level threshold (debug, info, warn, error) */
+  level: string;
+  /** Optional output level transformation */
+  output_level?: string;
+  /** Glob pattern for matching (exact, prefix*, *suffix, *contains*) */
+  pattern: string;
+  /** Filter type: source:file, source:function, context:<key>, or a plain attribute key */
+  type: string;
+}
+
+export interface PendingDeviceResponse {
+  /** Driver that would control it */
+  driver?: string;
+  /** Firmware version reported by the device */
+  firmwareversion: string;
+  /** When discovery first found it */
+  firstseen: string;
+  /** Hardware board type reported by the device */
+  hardwareboardtype: number;
+  /** Instance name, and the light ID it gets once adopted */
+  id: string;
+  /** Address the device answered on */
+  ip: string;
+  /** When discovery last found it */
+  lastseen: string;
+  /** Name reported by the device */
+  name: string;
+  /** Port of the device's API */
+  port: number;
+  /** Product name reported by the device */
+  productname: string;
+  /** Discovery backend that last found it */
+  source: string;
+}
+
+export interface PendingDevicesOutputBody {
+  /** Devices that failed validation, sorted by ID */
+  devices: Array<PendingDeviceResponse> | null;
+}
+
+export interface RawRequestInputBody {
+  /** JSON body to send to the device */
+  body?: unknown;
+  /** HTTP method to send to the device */
+  method: "GET" | "PUT" | "POST";
+  /** Device API path, under /elgato/ */
+  path: string;
+}
+
+export interface RawResponse {
+  body?: unknown;
+  status_code: number;
+}
+
+export interface ReadyOutputBody {
+  /** Why the service is not ready */
+  reason?: string;
+  /** Service readiness status */
+  status: string;
+}
+
+export interface SceneEntryRequest {
+  /** Brightness level (0-100) */
+  brightness?: number;
+  /** Wait after the entry's step starts before applying it, in milliseconds */
+  delay_ms?: number;
+  /** Power state */
+  on?: boolean;
+  /** Step the entry is applied in. Steps are applied lowest first, each once the transitions of the one before have finished. */
+  order?: number;
+  /** Light or group the entry acts upon */
+  target: SceneTargetBody;
+  /** Color temperature */
+  temperature?: number;
+  /** Time to ramp brightness and temperature over, in milliseconds */
+  transition_ms?: number;
+}
+
+export interface SceneRequest {
+  /** States the scene applies */
+  entries: Array<SceneEntryRequest> | null;
+  /** Display name for the scene */
+  name: string;
+}
+
+export interface SceneResponse {
+  /** States the scene applies */
+  entries: Array<SceneEntryRequest> | null;
+  /** Unique scene identifier */
+  id: string;
+  /** Display name of the scene */
+  name: string;
+}
+
+export interface SceneTargetBody {
+  /** Light ID, or group ID(s)/name(s) comma-separated */
+  id: string;
+  /** Target type */
+  type: "light" | "group";
+}
+
+export interface ScheduleActionRequest {
+  /** Brightness level (0-100) */
+  brightness?: number;
+  /** Power state */
+  on?: boolean;
+  /** Color temperature */
+  temperature?: number;
+}
+
+export interface ScheduleExecutionResponse {
+  /** State applied */
+  action?: ScheduleActionRequest;
+  /** When the schedule fires, in its time zone */
+  at: string;
+  /** Display name of the schedule */
+  name: string;
+  /** ID or name of the scene applied */
+  scene?: string;
+  /** Schedule identifier */
+  schedule_id: string;
+  /** Light or group the schedule acts upon, or the scene is limited to */
+  target?: ScheduleTargetBody;
+}
+
+export interface ScheduleRequest {
+  /** State to apply when the schedule fires. Mutually exclusive with scene. */
+  action?: ScheduleActionRequest;
+  /** Daily time of day in HH:MM (24h, in the schedule's time zone). Mutually exclusive with cron. */
+  at?: string;
+  /** Five-field cron expression (minute hour day-of-month month day-of-week) or @daily/@hourly etc. Mutually exclusive with at. */
+  cron?: string;
+  /** Whether the schedule is active (default true) */
+  enabled?: boolean;
+  /** Display name for the schedule */
+  name: string;
+  /** ID or name of a scene to apply when the schedule fires. Mutually exclusive with action. */
+  scene?: string;
+  /** Dates (YYYY-MM-DD, in the schedule's time zone) the schedule doesn't fire on, such as holidays */
+  skip_dates?: Array<string> | null;
+  /** Light or group the schedule acts upon. Required with action; with scene, limits the scene to its lights. */
+  target?: ScheduleTargetBody;
+  /** IANA time zone at and cron are in, such as Europe/London (default config.schedules.timezone, then the daemon's) */
+  timezone?: string;
+}
+
+export interface ScheduleResponse {
+  /** State applied when the schedule fires */
+  action?: ScheduleActionRequest;
+  /** Daily time of day in HH:MM */
+  at?: string;
+  /** Cron expression */
+  cron?: string;
+  /** Whether the schedule is active */
+  enabled: boolean;
+  /** Unique schedule identifier */
+  id: string;
+  /** When the schedule last fired */
+  last_run?: string;
+  /** Display name of the schedule */
+  name: string;
+  /** When the schedule will next fire */
+  next_run?: string;
+  /** ID or name of the scene applied when the schedule fires */
+  scene?: string;
+  /** Dates (YYYY-MM-DD) the schedule doesn't fire on */
+  skip_dates?: Array<string> | null;
+  /** Light or group the schedule acts upon, or the scene is limited to */
+  target?: ScheduleTargetBody;
+  /** IANA time zone at and cron are in; empty uses the default */
+  timezone?: string;
+}
+
+export interface ScheduleTargetBody {
+  /** Light ID, or group ID(s)/name(s) comma-separated */
+  id: string;
+  /** Target type */
+  type: "light" | "group";
+}
+
+export interface SetAPIKeyDisabledInputBody {
+  /** Whether to disable the key */
+  disabled: boolean;
+}
+
+export interface SetCircadianInputBody {
+  /** Whether circadian mode should adjust lights */
+  enabled: boolean;
+}
+
+export interface SetDesiredStateInputBody {
+  /** Whether desired-state mode should put lights back */
+  enabled: boolean;
+}
+
+export interface SetDesiredStateTargetInputBody {
+  /** Whether the target should be enforced */
+  enabled: boolean;
+}
+
+export interface SetDeviceNameInputBody {
+  /** Name to store on the device */
+  name: string;
+}
+
+export interface SetFiltersInputBody {
+  /** New filter list to apply */
+  filters: Array<LogFilterResponse> | null;
+}
+
+export interface SetFiltersOutputBody {
+  /** Applied log filters */
+  filters: Array<LogFilterResponse> | null;
+  /** Current global log level */
+  level: string;
+}
+
+export interface SetGroupLightsInputBody {
+  /** List of light IDs to assign to the group */
+  light_ids: Array<string> | null;
+}
+
+export interface SetGroupStateInputBody {
+  /** Brightness level (0-100) for all lights */
+  brightness?: number;
+  /** Change each light's brightness by this many percentage points, relative to its current value and clamped to the valid range */
+  brightness_delta?: number;
+  /** Hue in degrees for the group's color lights; other lights are left unchanged */
+  hue?: number;
+  /** Power state for all lights in the group */
+  on?: boolean;
+  /** Saturation percentage for the group's color lights; other lights are left unchanged */
+  saturation?: number;
+  /** Color temperature for all lights, in Kelvin unless units is mired */
+  temperature?: number;
+  /** Change each light's color temperature by this many Kelvin, relative to its current value and clamped to the valid range */
+  temperature_delta?: number;
+  /** Ramp brightness and temperature over this many milliseconds instead of applying instantly */
+  transition_ms?: number;
+  /** Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin */
+  units?: "kelvin" | "mired";
+}
+
+export interface SetLevelInputBody {
+  /** New log level (debug, info, warn, error) */
+  level: string;
+}
+
+export interface SetLevelOutputBody {
+  /** Updated global log level */
+  level: string;
+}
+
+export interface SetLightNameInputBody {
+  /** Display name overriding the one reported by the device; empty restores the device's name */
+  name: string;
+}
+
+export interface SetLightSettingsInputBody {
+  /** What the light does when it gets power back: restore its last state, or turn on at the power-on brightness and temperature */
+  power_on_behavior?: "restore" | "default";
+  /** Brightness (3-100) used when the power-on behavior is default */
+  power_on_brightness?: number;
+  /** Color temperature in Kelvin used when the power-on behavior is default */
+  power_on_temperature?: number;
+}
+
+export interface SetLightStateInputBody {
+  /** Brightness level (0-100) */
+  brightness?: number;
+  /** Change brightness by this many percentage points, relative to the current value and clamped to the valid range */
+  brightness_delta?: number;
+  /** Hue in degrees, for lights that support color */
+  hue?: number;
+  /** Power state */
+  on?: boolean;
+  /** Saturation percentage, for lights that support color */
+  saturation?: number;
+  /** Color temperature, in Kelvin unless units is mired; on a color light this switches back to white */
+  temperature?: number;
+  /** Change color temperature by this many Kelvin, relative to the current value and clamped to the valid range */
+  temperature_delta?: number;
+  /** Ramp brightness and temperature over this many milliseconds instead of applying instantly */
+  transition_ms?: number;
+  /** Unit of temperature: kelvin (the default) or mired. temperature_delta is always in Kelvin */
+  units?: "kelvin" | "mired";
+}
+
+export interface SetLightTagsInputBody {
+  /** The light's tags, such as {"location": "desk", "side": "left"}, replacing any it has; empty removes them. Keys are 1-32 letters, digits, '_', '.' or '-'; values are at most 64 characters and cannot contain commas */
+  tags: Record<string, string>;
+}
+
+export interface SetLightsStateInputBody {
+  /** Per-light state updates, applied concurrently */
+  lights: Array<LightStateUpdate> | null;
+  /** Unit of every update's temperature: kelvin (the default) or mired */
+  units?: "kelvin" | "mired";
+}
+
+export interface SetWebcamOverrideInputBody {
+  /** on or off to force the groups on or off, or auto to follow the webcam again */
+  override: "auto" | "on" | "off";
+}
+
+export interface StatusResponse {
+  /** Operation status */
+  status: string;
+}
+
+export interface StreamDeckActionInputBody {
+  /** Action to apply */
+  action: "toggle" | "on" | "off" | "brightness" | "temperature";
+  /** Light or group identifier */
+  id: string;
+  /** Amount to change brightness (percentage points) or temperature (Kelvin) by, for the brightness and temperature actions; negative to decrease */
+  step?: number;
+  /** Whether id is a light or a group */
+  target: "light" | "group";
+  /** Brightness (0-100) or temperature (Kelvin) to set, for the brightness and temperature actions */
+  value?: number;
+}
+
+export interface StreamDeckItem {
+  /** Brightness level (0-100); for a group, the average of its lights */
+  brightness: number;
+  /** Light or group identifier */
+  id: string;
+  /** Display name */
+  name: string;
+  /** Whether the light is on; for a group, whether any of its lights is on */
+  on: boolean;
+  /** Whether the light is responding; absent for groups */
+  status?: "online" | "degraded" | "offline";
+  /** Color temperature in Kelvin; for a group, the average of its lights */
+  temperature: number;
+}
+
+export interface StreamDeckState {
+  /** Groups, sorted by name */
+  groups: Array<StreamDeckItem> | null;
+  /** Lights, sorted by name */
+  lights: Array<StreamDeckItem> | null;
+}
+
+export interface Target {
+  id: string;
+  type: string;
+}
+
+export interface ToggleResponse {
+  /** Power state after the toggle */
+  on: boolean;
+  /** Operation status */
+  status: string;
+}
+
+export interface UpdateGroupInputBody {
+  /** Color shown for the group in UIs, as #rrggbb; empty to clear it */
+  color?: string;
+  /** Group description; empty to clear it */
+  description?: string;
+  /** Icon name shown for the group in UIs; empty to clear it */
+  icon?: string;
+  /** New display name for the group */
+  name?: string;
+}
+
+export interface VersionOutputBody {
+  /** Build timestamp (ISO 8601 UTC) */
+  build_date: string;
+  /** Git commit SHA */
+  commit: string;
+  /** Semantic version string */
+  version: string;
+}
+
+export interface WebcamResponse {
+  /** Whether the groups are currently on because of the webcam or an override */
+  active: boolean;
+  /** Whether the webcam watcher is enabled in the config */
+  enabled: boolean;
+  /** Groups turned on while the webcam is in use */
+  groups: Array<string> | null;
+  /** Whether a webcam was in use at the last check */
+  in_use: boolean;
+  /** Override of the detected webcam state */
+  override: "auto" | "on" | "off";
+  /** Whether webcam use can be detected on this platform */
+  supported: boolean;
+}
+
+/** An HTTP request made by the client. */
+export interface HTTPRequest {
+  method: string;
+  url: string;
+  headers: Record<string, string>;
+  body?: string;
+}
+
+/** The response to an HTTPRequest. */
+export interface HTTPResponse {
+  status: number;
+  body: string;
+}
+
+/**
+ * Sends requests for the client. The default uses fetch; environments
+ * without it, such as GNOME Shell, can pass their own.
+ */
+export type Transport = (request: HTTPRequest) => Promise<HTTPResponse>;
+
+type FetchLike = (
+  url: string,
+  init: { method: string; headers: Record<string, string>; body?: string },
+) => Promise<{ status: number; text(): Promise<string> }>;
+
+/** Returns a transport that sends requests with fetch. */
+export function fetchTransport(fetchFn?: FetchLike): Transport {
+  const send = fetchFn ?? ((globalThis as unknown as { fetch: FetchLike }).fetch);
+  return async (request) => {
+    const response = await send(request.url, {
+      method: request.method,
+      headers: request.headers,
+      body: request.body,
+    });
+    return { status: response.status, body: await response.text() };
+  };
+}
+
+/** An error returned by the daemon, with the code shared by all its APIs. */
+export class APIError extends Error {
+  constructor(
+    readonly status: number,
+    readonly code: string,
+    message: string,
+    readonly details?: Record<string, unknown>,
+  ) {
+    super(message);
+    this.name = "APIError";
+  }
+}
+
+export interface ClientOptions {
+  /** Base URL of the API, e.g. http://localhost:9123 */
+  baseURL: string;
+  apiKey?: string;
+  transport?: Transport;
+}
+
+type Query = Record<string, string | number | boolean | undefined>;
+
+export class KeylightdClient {
+  private readonly baseURL: string;
+  private readonly apiKey?: string;
+  private readonly transport: Transport;
+
+  constructor(options: ClientOptions) {
+    this.baseURL = options.baseURL.replace(/\/+$/, "");
+    this.apiKey = options.apiKey;
+    this.transport = options.transport ?? fetchTransport();
+  }
+
+  private async request<T>(method: string, path: string, query?: Query, body?: unknown): Promise<T> {
+    let url = this.baseURL + path;
+    const params: string[] = [];
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined) {
+        params.push(encodeURIComponent(key) + "=" + encodeURIComponent(String(value)));
+      }
+    }
+    if (params.length > 0) {
+      url += "?" + params.join("&");
+    }
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    if (this.apiKey) {
+      headers["X-API-Key"] = this.apiKey;
+    }
+    const response = await this.transport({
+      method,
+      url,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (response.status >= 400) {
+      let problem: { code?: string; detail?: string; details?: Record<string, unknown> } = {};
+      try {
+        problem = JSON.parse(response.body);
+      } catch {
+        // Not a problem details body
+      }
+      throw new APIError(
+        response.status,
+        problem.code ?? "internal",
+        problem.detail ?? (response.body || "HTTP error " + response.status),
+        problem.details,
+      );
+    }
+    return (response.body ? JSON.parse(response.body) : undefined) as T;
+  }
+
+  /** Add a log filter */
+  addLogFilter(body: LogFilterResponse): Promise<AddFilterOutputBody> {
+    return this.request("POST", "/api/v1/logging/filters", undefined, body);
+  }
+
+  /** Adopt a pending device */
+  adoptDevice(id: string): Promise<LightResponse> {
+    return this.request("POST", "/api/v1/discovery/pending/" + encodeURIComponent(id) + "/adopt", undefined, undefined);
+  }
+
+  /** Apply a scene */
+  applyScene(id: string): Promise<JobResponse> {
+    return this.request("POST", "/api/v1/scenes/" + encodeURIComponent(id) + "/apply", undefined, undefined);
+  }
+
+  /** Cancel a job */
+  cancelJob(id: string): Promise<JobResponse> {
+    return this.request("DELETE", "/api/v1/jobs/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Stop applying a scene */
+  cancelSceneRun(id: string): Promise<JobResponse> {
+    return this.request("DELETE", "/api/v1/scenes/" + encodeURIComponent(id) + "/run", undefined, undefined);
+  }
+
+  /** Create an API key */
+  createApiKey(body: CreateAPIKeyInputBody): Promise<APIKeyResponse> {
+    return this.request("POST", "/api/v1/apikeys", undefined, body);
+  }
+
+  /** Create a group */
+  createGroup(body: CreateGroupInputBody): Promise<GroupResponse> {
+    return this.request("POST", "/api/v1/groups", undefined, body);
+  }
+
+  /** Create a scene */
+  createScene(body: SceneRequest): Promise<SceneResponse> {
+    return this.request("POST", "/api/v1/scenes", undefined, body);
+  }
+
+  /** Create a schedule */
+  createSchedule(body: ScheduleRequest): Promise<ScheduleResponse> {
+    return this.request("POST", "/api/v1/schedules", undefined, body);
+  }
+
+  /** Delete an API key */
+  deleteApiKey(key: string): Promise<void> {
+    return this.request("DELETE", "/api/v1/apikeys/" + encodeURIComponent(key), undefined, undefined);
+  }
+
+  /** Delete a group */
+  deleteGroup(id: string): Promise<void> {
+    return this.request("DELETE", "/api/v1/groups/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Remove a log filter */
+  deleteLogFilter(query: { type?: string; pattern?: string } = {}): Promise<void> {
+    return this.request("DELETE", "/api/v1/logging/filters", query, undefined);
+  }
+
+  /** Delete a scene */
+  deleteScene(id: string): Promise<void> {
+    return this.request("DELETE", "/api/v1/scenes/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Delete a schedule */
+  deleteSchedule(id: string): Promise<void> {
+    return this.request("DELETE", "/api/v1/schedules/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Discover lights now */
+  discoverLights(): Promise<DiscoveryScanResponse> {
+    return this.request("POST", "/api/v1/discovery/scan", undefined, undefined);
+  }
+
+  /** Export groups, schedules and API keys */
+  exportConfig(query: { include_secrets?: boolean } = {}): Promise<ExportDocument> {
+    return this.request("GET", "/api/v1/config/export", query, undefined);
+  }
+
+  /** Get circadian mode */
+  getCircadian(): Promise<CircadianResponse> {
+    return this.request("GET", "/api/v1/circadian", undefined, undefined);
+  }
+
+  /** Daemon info */
+  getDaemonInfo(): Promise<DaemonInfoResponse> {
+    return this.request("GET", "/api/v1/info", undefined, undefined);
+  }
+
+  /** Get desired-state mode */
+  getDesiredState(): Promise<DesiredStateResponse> {
+    return this.request("GET", "/api/v1/desired-state", undefined, undefined);
+  }
+
+  /** Get a group */
+  getGroup(id: string): Promise<GroupResponse> {
+    return this.request("GET", "/api/v1/groups/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Get group state */
+  getGroupState(id: string): Promise<GroupStateResponse> {
+    return this.request("GET", "/api/v1/groups/" + encodeURIComponent(id) + "/state", undefined, undefined);
+  }
+
+  /** Get group usage statistics */
+  getGroupStats(id: string): Promise<GroupStatsResponse> {
+    return this.request("GET", "/api/v1/groups/" + encodeURIComponent(id) + "/stats", undefined, undefined);
+  }
+
+  /** Get a job */
+  getJob(id: string): Promise<JobResponse> {
+    return this.request("GET", "/api/v1/jobs/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Get a light */
+  getLight(id: string): Promise<LightResponse> {
+    return this.request("GET", "/api/v1/lights/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Get a light's power-on settings */
+  getLightSettings(id: string): Promise<LightSettings> {
+    return this.request("GET", "/api/v1/lights/" + encodeURIComponent(id) + "/settings", undefined, undefined);
+  }
+
+  /** Get light usage statistics */
+  getLightStats(id: string): Promise<LightStatsResponse> {
+    return this.request("GET", "/api/v1/lights/" + encodeURIComponent(id) + "/stats", undefined, undefined);
+  }
+
+  /** Get global log level */
+  getLogLevel(): Promise<GetLevelOutputBody> {
+    return this.request("GET", "/api/v1/logging/level", undefined, undefined);
+  }
+
+  /** Get recent log lines */
+  getLogs(query: { level?: string; since?: string; limit?: number } = {}): Promise<GetLogsOutputBody> {
+    return this.request("GET", "/api/v1/logs", query, undefined);
+  }
+
+  /** Get a scene */
+  getScene(id: string): Promise<SceneResponse> {
+    return this.request("GET", "/api/v1/scenes/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Get the job of a scene's latest run */
+  getSceneRun(id: string): Promise<JobResponse> {
+    return this.request("GET", "/api/v1/scenes/" + encodeURIComponent(id) + "/run", undefined, undefined);
+  }
+
+  /** Get a schedule */
+  getSchedule(id: string): Promise<ScheduleResponse> {
+    return this.request("GET", "/api/v1/schedules/" + encodeURIComponent(id), undefined, undefined);
+  }
+
+  /** Get a compact state snapshot */
+  getStreamDeckState(): Promise<StreamDeckState> {
+    return this.request("GET", "/api/v1/streamdeck/state", undefined, undefined);
+  }
+
+  /** Daemon version */
+  getVersion(): Promise<VersionOutputBody> {
+    return this.request("GET", "/api/v1/version", undefined, undefined);
+  }
+
+  /** Get webcam automation */
+  getWebcam(): Promise<WebcamResponse> {
+    return this.request("GET", "/api/v1/webcam", undefined, undefined);
+  }
+
+  /** Health check */
+  healthCheck(): Promise<HealthOutputBody> {
+    return this.request("GET", "/api/v1/health", undefined, undefined);
+  }
+
+  /** Import an exported document */
+  importConfig(body: ExportDocument): Promise<ImportResult> {
+    return this.request("POST", "/api/v1/config/import", undefined, body);
+  }
+
+  /** List API keys */
+  listApiKeys(): Promise<Array<APIKeyResponse> | null> {
+    return this.request("GET", "/api/v1/apikeys", undefined, undefined);
+  }
+
+  /** List all groups */
+  listGroups(): Promise<Array<GroupResponse> | null> {
+    return this.request("GET", "/api/v1/groups", undefined, undefined);
+  }
+
+  /** List jobs */
+  listJobs(): Promise<Array<JobResponse> | null> {
+    return this.request("GET", "/api/v1/jobs", undefined, undefined);
+  }
+
+  /** List all lights */
+  listLights(): Promise<Record<string, LightResponse>> {
+    return this.request("GET", "/api/v1/lights", undefined, undefined);
+  }
+
+  /** List log filters and current level */
+  listLogFilters(): Promise<ListFiltersOutputBody> {
+    return this.request("GET", "/api/v1/logging/filters", undefined, undefined);
+  }
+
+  /** List pending devices */
+  listPendingDevices(): Promise<PendingDevicesOutputBody> {
+    return this.request("GET", "/api/v1/discovery/pending", undefined, undefined);
+  }
+
+  /** List all scenes */
+  listScenes(): Promise<Array<SceneResponse> | null> {
+    return this.request("GET", "/api/v1/scenes", undefined, undefined);
+  }
+
+  /** List all schedules */
+  listSchedules(): Promise<Array<ScheduleResponse> | null> {
+    return this.request("GET", "/api/v1/schedules", undefined, undefined);
+  }
+
+  /** List upcoming schedule runs */
+  listUpcomingSchedules(query: { limit?: number; days?: number } = {}): Promise<Array<ScheduleExecutionResponse> | null> {
+    return this.request("GET", "/api/v1/schedules/upcoming", query, undefined);
+  }
+
+  /** Send a raw request to a light */
+  rawLightRequest(id: string, body: RawRequestInputBody): Promise<RawResponse> {
+    return this.request("POST", "/api/v1/lights/" + encodeURIComponent(id) + "/raw", undefined, body);
+  }
+
+  /** Enable or disable an API key */
+  setApiKeyDisabled(key: string, body: SetAPIKeyDisabledInputBody): Promise<APIKeyResponse> {
+    return this.request("PUT", "/api/v1/apikeys/" + encodeURIComponent(key) + "/disabled", undefined, body);
+  }
+
+  /** Enable or disable circadian mode */
+  setCircadian(body: SetCircadianInputBody): Promise<CircadianResponse> {
+    return this.request("PUT", "/api/v1/circadian", undefined, body);
+  }
+
+  /** Enable or disable desired-state mode */
+  setDesiredState(body: SetDesiredStateInputBody): Promise<DesiredStateResponse> {
+    return this.request("PUT", "/api/v1/desired-state", undefined, body);
+  }
+
+  /** Enable or disable a desired-state target */
+  setDesiredStateTarget(target: string, body: SetDesiredStateTargetInputBody): Promise<DesiredStateResponse> {
+    return this.request("PUT", "/api/v1/desired-state/targets/" + encodeURIComponent(target), undefined, body);
+  }
+
+  /** Rename the physical light */
+  setDeviceName(id: string, body: SetDeviceNameInputBody): Promise<LightResponse> {
+    return this.request("PUT", "/api/v1/lights/" + encodeURIComponent(id) + "/device-name", undefined, body);
+  }
+
+  /** Set group defaults */
+  setGroupDefaults(id: string, body: GroupDefaultsBody): Promise<StatusResponse> {
+    return this.request("PUT", "/api/v1/groups/" + encodeURIComponent(id) + "/defaults", undefined, body);
+  }
+
+  /** Set group lights */
+  setGroupLights(id: string, body: SetGroupLightsInputBody): Promise<StatusResponse> {
+    return this.request("PUT", "/api/v1/groups/" + encodeURIComponent(id) + "/lights", undefined, body);
+  }
+
+  /** Set group state */
+  setGroupState(id: string, body: SetGroupStateInputBody): Promise<GroupChangeResponse> {
+    return this.request("PUT", "/api/v1/groups/" + encodeURIComponent(id) + "/state", undefined, body);
+  }
+
+  /** Set a light's display name */
+  setLightName(id: string, body: SetLightNameInputBody): Promise<LightResponse> {
+    return this.request("PUT", "/api/v1/lights/" + encodeURIComponent(id) + "/name", undefined, body);
+  }
+
+  /** Change a light's power-on settings */
+  setLightSettings(id: string, body: SetLightSettingsInputBody): Promise<LightSettings> {
+    return this.request("PUT", "/api/v1/lights/" + encodeURIComponent(id) + "/settings", undefined, body);
+  }
+
+  /** Set light state */
+  setLightState(id: string, body: SetLightStateInputBody): Promise<StatusResponse> {
+    return this.request("POST", "/api/v1/lights/" + encodeURIComponent(id) + "/state", undefined, body);
+  }
+
+  /** Set a light's tags */
+  setLightTags(id: string, body: SetLightTagsInputBody): Promise<LightResponse> {
+    return this.request("PUT", "/api/v1/lights/" + encodeURIComponent(id) + "/tags", undefined, body);
+  }
+
+  /** Set multiple lights' state */
+  setLightsState(body: SetLightsStateInputBody): Promise<BatchStatusResponse> {
+    return this.request("POST", "/api/v1/lights/state", undefined, body);
+  }
+
+  /** Replace all log filters */
+  setLogFilters(body: SetFiltersInputBody): Promise<SetFiltersOutputBody> {
+    return this.request("PUT", "/api/v1/logging/filters", undefined, body);
+  }
+
+  /** Set global log level */
+  setLogLevel(body: SetLevelInputBody): Promise<SetLevelOutputBody> {
+    return this.request("PUT", "/api/v1/logging/level", undefined, body);
+  }
+
+  /** Override webcam automation */
+  setWebcamOverride(body: SetWebcamOverrideInputBody): Promise<WebcamResponse> {
+    return this.request("PUT", "/api/v1/webcam/override", undefined, body);
+  }
+
+  /** Apply a button or dial action */
+  streamDeckAction(body: StreamDeckActionInputBody): Promise<StreamDeckItem> {
+    return this.request("POST", "/api/v1/streamdeck/action", undefined, body);
+  }
+
+  /** Toggle a group */
+  toggleGroup(id: string): Promise<GroupToggleResponse> {
+    return this.request("POST", "/api/v1/groups/" + encodeURIComponent(id) + "/toggle", undefined, undefined);
+  }
+
+  /** Toggle a light */
+  toggleLight(id: string): Promise<ToggleResponse> {
+    return this.request("POST", "/api/v1/lights/" + encodeURIComponent(id) + "/toggle", undefined, undefined);
+  }
+
+  /** Update a group */
+  updateGroup(id: string, body: UpdateGroupInputBody): Promise<GroupResponse> {
+    return this.request("PATCH", "/api/v1/groups/" + encodeURIComponent(id), undefined, body);
+  }
+
+  /** Replace a scene */
+  updateScene(id: string, body: SceneRequest): Promise<SceneResponse> {
+    return this.request("PUT", "/api/v1/scenes/" + encodeURIComponent(id), undefined, body);
+  }
+
+  /** Replace a schedule */
+  updateSchedule(id: string, body: ScheduleRequest): Promise<ScheduleResponse> {
+    return this.request("PUT", "/api/v1/schedules/" + encodeURIComponent(id), undefined, body);
+  }
+}